@@ -0,0 +1,103 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/samhotchkiss/otter-camp/internal/ottercli"
+)
+
+type agentReviewCommandClient interface {
+	ResolveAgent(query string) (ottercli.Agent, error)
+	AgentReview(agentID string, opts ottercli.AgentReviewOptions) (ottercli.AgentScorecard, error)
+	ExportAgentReview(agentID string, opts ottercli.AgentReviewOptions, format string, out io.Writer) (int64, error)
+}
+
+type agentReviewClientFactory func(orgOverride string) (agentReviewCommandClient, error)
+
+const agentReviewUsage = "usage: otter agent review <agent> [--since 30d] [--periods 2] [--format markdown|csv] [--out <file>] [--json]"
+
+func handleAgentReview(args []string) {
+	if err := runAgentReviewCommand(args, newAgentReviewCommandClient, os.Stdout); err != nil {
+		die(err.Error())
+	}
+}
+
+func runAgentReviewCommand(args []string, factory agentReviewClientFactory, out io.Writer) error {
+	if len(args) == 0 || strings.HasPrefix(args[0], "-") {
+		return errors.New(agentReviewUsage)
+	}
+	agentQuery := args[0]
+
+	flags := flag.NewFlagSet("agent review", flag.ContinueOnError)
+	flags.SetOutput(io.Discard)
+	org := flags.String("org", "", "org id override")
+	jsonOut := flags.Bool("json", false, "JSON output")
+	since := flags.String("since", "30d", "window size, e.g. 30d or 4w")
+	periods := flags.Int("periods", 2, "number of consecutive windows to compare")
+	format := flags.String("format", "markdown", "report format: markdown or csv")
+	outPath := flags.String("out", "", "report file (default stdout)")
+	if err := flags.Parse(args[1:]); err != nil {
+		return err
+	}
+	if len(flags.Args()) != 0 {
+		return errors.New(agentReviewUsage)
+	}
+	*format = strings.ToLower(strings.TrimSpace(*format))
+	if *format != "markdown" && *format != "csv" {
+		return errors.New("--format must be markdown or csv")
+	}
+	if *periods < 1 {
+		return errors.New("--periods must be at least 1")
+	}
+
+	client, err := factory(strings.TrimSpace(*org))
+	if err != nil {
+		return err
+	}
+	agent, err := client.ResolveAgent(agentQuery)
+	if err != nil {
+		return err
+	}
+	opts := ottercli.AgentReviewOptions{Since: *since, Periods: *periods}
+
+	if *jsonOut {
+		card, err := client.AgentReview(agent.ID, opts)
+		if err != nil {
+			return err
+		}
+		printJSONTo(out, card)
+		return nil
+	}
+
+	path := strings.TrimSpace(*outPath)
+	if path == "" {
+		_, err := client.ExportAgentReview(agent.ID, opts, *format, out)
+		return err
+	}
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	size, err := client.ExportAgentReview(agent.ID, opts, *format, file)
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(out, "Wrote %s (%d bytes)\n", path, size)
+	return nil
+}
+
+func newAgentReviewCommandClient(orgOverride string) (agentReviewCommandClient, error) {
+	cfg, err := ottercli.LoadConfig()
+	if err != nil {
+		return nil, err
+	}
+	return ottercli.NewClient(cfg, orgOverride)
+}
@@ -0,0 +1,99 @@
+package main
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/samhotchkiss/otter-camp/internal/ottercli"
+)
+
+type fakeAgentReviewCommandClient struct {
+	resolved string
+	agentID  string
+	opts     ottercli.AgentReviewOptions
+	format   string
+}
+
+func (f *fakeAgentReviewCommandClient) ResolveAgent(query string) (ottercli.Agent, error) {
+	f.resolved = query
+	return ottercli.Agent{ID: "a-1", Slug: "writer"}, nil
+}
+
+func (f *fakeAgentReviewCommandClient) AgentReview(agentID string, opts ottercli.AgentReviewOptions) (ottercli.AgentScorecard, error) {
+	f.agentID = agentID
+	f.opts = opts
+	card := ottercli.AgentScorecard{Days: 30}
+	card.Agent.Slug = "writer"
+	return card, nil
+}
+
+func (f *fakeAgentReviewCommandClient) ExportAgentReview(agentID string, opts ottercli.AgentReviewOptions, format string, out io.Writer) (int64, error) {
+	f.agentID = agentID
+	f.opts = opts
+	f.format = format
+	n, err := io.WriteString(out, "# Performance review: Writer (writer)\n")
+	return int64(n), err
+}
+
+func agentReviewTestFactory(client *fakeAgentReviewCommandClient) agentReviewClientFactory {
+	return func(string) (agentReviewCommandClient, error) { return client, nil }
+}
+
+func TestAgentReviewPrintsMarkdownByDefault(t *testing.T) {
+	client := &fakeAgentReviewCommandClient{}
+	var out bytes.Buffer
+	if err := runAgentReviewCommand([]string{"Writer", "--since", "14d", "--periods", "3"}, agentReviewTestFactory(client), &out); err != nil {
+		t.Fatalf("runAgentReviewCommand() error = %v", err)
+	}
+	if client.resolved != "Writer" || client.agentID != "a-1" || client.format != "markdown" {
+		t.Fatalf("resolved = %q agent = %q format = %q", client.resolved, client.agentID, client.format)
+	}
+	if client.opts.Since != "14d" || client.opts.Periods != 3 {
+		t.Fatalf("opts = %#v", client.opts)
+	}
+	if !strings.HasPrefix(out.String(), "# Performance review") {
+		t.Fatalf("output = %q", out.String())
+	}
+}
+
+func TestAgentReviewWritesCSVFileAndJSON(t *testing.T) {
+	client := &fakeAgentReviewCommandClient{}
+	path := filepath.Join(t.TempDir(), "review.csv")
+	var out bytes.Buffer
+	if err := runAgentReviewCommand([]string{"writer", "--format", "csv", "--out", path}, agentReviewTestFactory(client), &out); err != nil {
+		t.Fatalf("runAgentReviewCommand() error = %v", err)
+	}
+	if client.format != "csv" || !strings.Contains(out.String(), "Wrote "+path) {
+		t.Fatalf("format = %q output = %q", client.format, out.String())
+	}
+	if data, err := os.ReadFile(path); err != nil || len(data) == 0 {
+		t.Fatalf("report file = %q, %v", data, err)
+	}
+
+	out.Reset()
+	if err := runAgentReviewCommand([]string{"writer", "--json"}, agentReviewTestFactory(client), &out); err != nil {
+		t.Fatalf("runAgentReviewCommand() error = %v", err)
+	}
+	if !strings.Contains(out.String(), `"slug": "writer"`) || client.opts.Since != "30d" || client.opts.Periods != 2 {
+		t.Fatalf("json output = %q opts = %#v", out.String(), client.opts)
+	}
+}
+
+func TestAgentReviewRejectsBadArgs(t *testing.T) {
+	client := &fakeAgentReviewCommandClient{}
+	for _, args := range [][]string{
+		{},
+		{"--since", "30d"},
+		{"writer", "--format", "pdf"},
+		{"writer", "--periods", "0"},
+		{"writer", "extra"},
+	} {
+		if err := runAgentReviewCommand(args, agentReviewTestFactory(client), io.Discard); err == nil {
+			t.Fatalf("expected error for %v", args)
+		}
+	}
+}
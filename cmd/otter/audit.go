@@ -0,0 +1,160 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/samhotchkiss/otter-camp/internal/ottercli"
+)
+
+type auditCommandClient interface {
+	ListAudit(filter ottercli.AuditFilter) (ottercli.AuditListResponse, error)
+	ExportAudit(filter ottercli.AuditFilter, format string, out io.Writer) (int64, error)
+	VerifyAudit() (ottercli.AuditVerifyResult, error)
+}
+
+type auditClientFactory func(orgOverride string) (auditCommandClient, error)
+
+const auditUsage = "usage: otter audit <list|export|verify> ..."
+
+func handleAudit(args []string) {
+	if err := runAuditCommand(args, newAuditCommandClient, os.Stdout); err != nil {
+		die(err.Error())
+	}
+}
+
+func runAuditCommand(args []string, factory auditClientFactory, out io.Writer) error {
+	if len(args) == 0 {
+		return errors.New(auditUsage)
+	}
+
+	command := args[0]
+	flags := flag.NewFlagSet("audit "+command, flag.ContinueOnError)
+	flags.SetOutput(io.Discard)
+	org := flags.String("org", "", "org id override")
+	jsonOut := flags.Bool("json", false, "JSON output")
+	action := flags.String("action", "", "action, or namespace.* for a whole namespace")
+	actor := flags.String("actor", "", "actor id")
+	targetType := flags.String("target-type", "", "target type")
+	target := flags.String("target", "", "target id")
+	since := flags.String("since", "", "RFC3339 time or date")
+	until := flags.String("until", "", "RFC3339 time or date")
+	limit := flags.Int("limit", 50, "max entries")
+	cursor := flags.String("cursor", "", "page cursor from a previous list")
+	format := flags.String("format", "jsonl", "export format: jsonl or csv")
+	outPath := flags.String("out", "", "export file (default stdout)")
+
+	var usage string
+	switch command {
+	case "list":
+		usage = "usage: otter audit list [--action <a>] [--actor <id>] [--target-type <t>] [--target <id>] [--since <t>] [--until <t>] [--limit <n>] [--cursor <c>] [--json]"
+	case "export":
+		usage = "usage: otter audit export [--format jsonl|csv] [--out <file>] [filters]"
+	case "verify":
+		usage = "usage: otter audit verify [--json]"
+	default:
+		return errors.New(auditUsage)
+	}
+	if err := flags.Parse(args[1:]); err != nil {
+		return err
+	}
+	if len(flags.Args()) != 0 {
+		return errors.New(usage)
+	}
+	if command == "export" && *format != "jsonl" && *format != "csv" {
+		return errors.New("--format must be jsonl or csv")
+	}
+
+	client, err := factory(strings.TrimSpace(*org))
+	if err != nil {
+		return err
+	}
+
+	filter := ottercli.AuditFilter{
+		Action:     *action,
+		Actor:      *actor,
+		TargetType: *targetType,
+		TargetID:   *target,
+		Since:      *since,
+		Until:      *until,
+		Cursor:     *cursor,
+		Limit:      *limit,
+	}
+
+	switch command {
+	case "list":
+		entries, err := client.ListAudit(filter)
+		if err != nil {
+			return err
+		}
+		if *jsonOut {
+			printJSONTo(out, entries)
+			return nil
+		}
+		for _, entry := range entries.Items {
+			fmt.Fprintf(out, "%6d  %s  %-32s %s:%s  %s\n",
+				entry.Seq, entry.CreatedAt, entry.Action, entry.ActorType, entry.ActorID, auditTarget(entry))
+		}
+		if entries.NextCursor != "" {
+			fmt.Fprintf(out, "more: --cursor %s\n", entries.NextCursor)
+		}
+		return nil
+	case "export":
+		path := strings.TrimSpace(*outPath)
+		if path == "" {
+			_, err := client.ExportAudit(filter, *format, out)
+			return err
+		}
+		file, err := os.Create(path)
+		if err != nil {
+			return err
+		}
+		size, err := client.ExportAudit(filter, *format, file)
+		if closeErr := file.Close(); err == nil {
+			err = closeErr
+		}
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(out, "Wrote %s (%d bytes)\n", path, size)
+		return nil
+	case "verify":
+		result, err := client.VerifyAudit()
+		if err != nil {
+			return err
+		}
+		if *jsonOut {
+			printJSONTo(out, result)
+		} else if result.Valid {
+			fmt.Fprintf(out, "Audit chain intact (%d entries)\n", result.Checked)
+		}
+		if !result.Valid {
+			seq := int64(0)
+			if result.BrokenSeq != nil {
+				seq = *result.BrokenSeq
+			}
+			return fmt.Errorf("audit chain broken at seq %d: %s", seq, result.Reason)
+		}
+		return nil
+	}
+	return errors.New(auditUsage)
+}
+
+func auditTarget(entry ottercli.AuditEntry) string {
+	if entry.TargetID == "" {
+		return entry.TargetType
+	}
+	return entry.TargetType + ":" + entry.TargetID
+}
+
+func newAuditCommandClient(orgOverride string) (auditCommandClient, error) {
+	cfg, err := ottercli.LoadConfig()
+	if err != nil {
+		return nil, err
+	}
+	return ottercli.NewClient(cfg, orgOverride)
+}
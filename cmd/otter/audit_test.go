@@ -0,0 +1,103 @@
+package main
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/samhotchkiss/otter-camp/internal/ottercli"
+)
+
+type fakeAuditCommandClient struct {
+	filter ottercli.AuditFilter
+	format string
+	verify ottercli.AuditVerifyResult
+}
+
+func (f *fakeAuditCommandClient) ListAudit(filter ottercli.AuditFilter) (ottercli.AuditListResponse, error) {
+	f.filter = filter
+	return ottercli.AuditListResponse{
+		Items: []ottercli.AuditEntry{{
+			Seq:        7,
+			ActorType:  "user",
+			ActorID:    "u-1",
+			Action:     "role.update",
+			TargetType: "role",
+			TargetID:   "r-1",
+			CreatedAt:  "2026-10-18T12:00:00Z",
+		}},
+		Total:      1,
+		NextCursor: "7",
+	}, nil
+}
+
+func (f *fakeAuditCommandClient) ExportAudit(filter ottercli.AuditFilter, format string, out io.Writer) (int64, error) {
+	f.filter = filter
+	f.format = format
+	n, err := io.WriteString(out, "seq,action\n7,role.update\n")
+	return int64(n), err
+}
+
+func (f *fakeAuditCommandClient) VerifyAudit() (ottercli.AuditVerifyResult, error) {
+	return f.verify, nil
+}
+
+func auditTestFactory(client *fakeAuditCommandClient) auditClientFactory {
+	return func(string) (auditCommandClient, error) { return client, nil }
+}
+
+func TestAuditListPassesFiltersAndPrintsCursor(t *testing.T) {
+	client := &fakeAuditCommandClient{}
+	var out bytes.Buffer
+	err := runAuditCommand([]string{"list", "--action", "role.*", "--actor", "u-1", "--since", "2026-10-01", "--limit", "1"}, auditTestFactory(client), &out)
+	if err != nil {
+		t.Fatalf("runAuditCommand() error = %v", err)
+	}
+	if client.filter.Action != "role.*" || client.filter.Actor != "u-1" || client.filter.Since != "2026-10-01" || client.filter.Limit != 1 {
+		t.Fatalf("filter = %#v", client.filter)
+	}
+	if !strings.Contains(out.String(), "role.update") || !strings.Contains(out.String(), "role:r-1") || !strings.Contains(out.String(), "--cursor 7") {
+		t.Fatalf("output = %q", out.String())
+	}
+}
+
+func TestAuditExportWritesFile(t *testing.T) {
+	client := &fakeAuditCommandClient{}
+	path := filepath.Join(t.TempDir(), "audit.csv")
+	var out bytes.Buffer
+	if err := runAuditCommand([]string{"export", "--format", "csv", "--out", path, "--target-type", "role"}, auditTestFactory(client), &out); err != nil {
+		t.Fatalf("runAuditCommand() error = %v", err)
+	}
+	if client.format != "csv" || client.filter.TargetType != "role" {
+		t.Fatalf("format = %q filter = %#v", client.format, client.filter)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil || !strings.HasPrefix(string(data), "seq,action") {
+		t.Fatalf("export file = %q, %v", data, err)
+	}
+
+	if err := runAuditCommand([]string{"export", "--format", "xml"}, auditTestFactory(client), &out); err == nil {
+		t.Fatal("expected invalid format error")
+	}
+}
+
+func TestAuditVerifyReportsBrokenChain(t *testing.T) {
+	client := &fakeAuditCommandClient{verify: ottercli.AuditVerifyResult{Valid: true, Checked: 12}}
+	var out bytes.Buffer
+	if err := runAuditCommand([]string{"verify"}, auditTestFactory(client), &out); err != nil {
+		t.Fatalf("runAuditCommand() error = %v", err)
+	}
+	if !strings.Contains(out.String(), "12 entries") {
+		t.Fatalf("output = %q", out.String())
+	}
+
+	broken := int64(4)
+	client.verify = ottercli.AuditVerifyResult{Checked: 4, BrokenSeq: &broken, Reason: "hash mismatch"}
+	err := runAuditCommand([]string{"verify"}, auditTestFactory(client), &out)
+	if err == nil || !strings.Contains(err.Error(), "seq 4") || !strings.Contains(err.Error(), "hash mismatch") {
+		t.Fatalf("verify error = %v", err)
+	}
+}
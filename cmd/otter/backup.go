@@ -0,0 +1,278 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/samhotchkiss/otter-camp/internal/ottercli"
+)
+
+type backupCommandClient interface {
+	DownloadBackup(opts ottercli.BackupCreateOptions, out io.Writer) (int64, error)
+	ValidateBackup(archive io.Reader) (ottercli.BackupValidation, error)
+	RestoreBackup(archive io.Reader, remap, dryRun bool) (ottercli.BackupRestoreResult, error)
+}
+
+type backupClientFactory func(orgOverride string) (backupCommandClient, error)
+
+type backupCreateOptions struct {
+	Out       string
+	Since     string
+	AsOf      string
+	SkipRepos bool
+	Org       string
+}
+
+type backupRestoreOptions struct {
+	File   string
+	Remap  bool
+	DryRun bool
+	Org    string
+	JSON   bool
+}
+
+const backupUsage = "usage: otter backup <create|validate|restore> ..."
+
+func handleBackup(args []string) {
+	if err := runBackupCommand(args, newBackupCommandClient, os.Stdout); err != nil {
+		die(err.Error())
+	}
+}
+
+func runBackupCommand(args []string, factory backupClientFactory, out io.Writer) error {
+	if len(args) == 0 {
+		return errors.New(backupUsage)
+	}
+
+	switch args[0] {
+	case "create":
+		opts, err := parseBackupCreateOptions(args[1:])
+		if err != nil {
+			return err
+		}
+		client, err := factory(opts.Org)
+		if err != nil {
+			return err
+		}
+		return runBackupCreate(client, opts, out)
+	case "validate", "restore":
+		opts, err := parseBackupRestoreOptions(args[0], args[1:])
+		if err != nil {
+			return err
+		}
+		client, err := factory(opts.Org)
+		if err != nil {
+			return err
+		}
+		if args[0] == "validate" {
+			return runBackupValidate(client, opts, out)
+		}
+		return runBackupRestore(client, opts, out)
+	default:
+		return errors.New(backupUsage)
+	}
+}
+
+func parseBackupCreateOptions(args []string) (backupCreateOptions, error) {
+	flags := flag.NewFlagSet("backup create", flag.ContinueOnError)
+	flags.SetOutput(io.Discard)
+	outPath := flags.String("out", "", "archive path (default otter-camp-backup-<timestamp>.tar.gz)")
+	since := flags.String("since", "", "only include rows changed since this RFC3339 time")
+	asOf := flags.String("as-of", "", "drop rows created after this RFC3339 time")
+	skipRepos := flags.Bool("skip-repos", false, "leave project git repos out of the archive")
+	org := flags.String("org", "", "org id override")
+	if err := flags.Parse(args); err != nil {
+		return backupCreateOptions{}, err
+	}
+	if len(flags.Args()) != 0 {
+		return backupCreateOptions{}, errors.New("usage: otter backup create [--out <file>] [--since <time>] [--as-of <time>] [--skip-repos]")
+	}
+	for name, value := range map[string]string{"--since": *since, "--as-of": *asOf} {
+		if strings.TrimSpace(value) == "" {
+			continue
+		}
+		if _, err := time.Parse(time.RFC3339, strings.TrimSpace(value)); err != nil {
+			return backupCreateOptions{}, fmt.Errorf("%s must be an RFC3339 timestamp", name)
+		}
+	}
+	out := strings.TrimSpace(*outPath)
+	if out == "" {
+		out = fmt.Sprintf("otter-camp-backup-%s.tar.gz", time.Now().UTC().Format("20060102-150405"))
+	}
+	return backupCreateOptions{
+		Out:       out,
+		Since:     strings.TrimSpace(*since),
+		AsOf:      strings.TrimSpace(*asOf),
+		SkipRepos: *skipRepos,
+		Org:       strings.TrimSpace(*org),
+	}, nil
+}
+
+func parseBackupRestoreOptions(command string, args []string) (backupRestoreOptions, error) {
+	flags := flag.NewFlagSet("backup "+command, flag.ContinueOnError)
+	flags.SetOutput(io.Discard)
+	noRemap := flags.Bool("no-remap", false, "keep ids and upsert (for incremental archives of this org)")
+	dryRun := flags.Bool("dry-run", false, "run the restore and roll it back")
+	org := flags.String("org", "", "org id override (restore target)")
+	jsonOut := flags.Bool("json", false, "JSON output")
+
+	usage := "usage: otter backup " + command + " <file>"
+	if command == "restore" {
+		usage += " [--no-remap] [--dry-run]"
+	}
+	if err := flags.Parse(args); err != nil {
+		return backupRestoreOptions{}, err
+	}
+	// Allow flags after the file name too.
+	positional := flags.Args()
+	if len(positional) > 1 {
+		if err := flags.Parse(positional[1:]); err != nil {
+			return backupRestoreOptions{}, err
+		}
+		if len(flags.Args()) != 0 {
+			return backupRestoreOptions{}, errors.New(usage)
+		}
+		positional = positional[:1]
+	}
+	if len(positional) != 1 || strings.TrimSpace(positional[0]) == "" {
+		return backupRestoreOptions{}, errors.New(usage)
+	}
+	if command == "validate" && (*noRemap || *dryRun) {
+		return backupRestoreOptions{}, errors.New("--no-remap and --dry-run only apply to restore")
+	}
+	return backupRestoreOptions{
+		File:   strings.TrimSpace(positional[0]),
+		Remap:  !*noRemap,
+		DryRun: *dryRun,
+		Org:    strings.TrimSpace(*org),
+		JSON:   *jsonOut,
+	}, nil
+}
+
+func runBackupCreate(client backupCommandClient, opts backupCreateOptions, out io.Writer) error {
+	// Download next to the destination and rename, so a failed backup never
+	// leaves a truncated archive under the requested name.
+	dir := filepath.Dir(opts.Out)
+	tmp, err := os.CreateTemp(dir, ".otter-backup-*.tmp")
+	if err != nil {
+		return err
+	}
+	tmpName := tmp.Name()
+	defer os.Remove(tmpName)
+
+	size, err := client.DownloadBackup(ottercli.BackupCreateOptions{
+		Since:     opts.Since,
+		AsOf:      opts.AsOf,
+		SkipRepos: opts.SkipRepos,
+	}, tmp)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return err
+	}
+	if err := os.Rename(tmpName, opts.Out); err != nil {
+		return err
+	}
+	fmt.Fprintf(out, "Wrote %s (%d bytes)\n", opts.Out, size)
+	return nil
+}
+
+func runBackupValidate(client backupCommandClient, opts backupRestoreOptions, out io.Writer) error {
+	file, err := os.Open(opts.File)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	result, err := client.ValidateBackup(file)
+	if err != nil {
+		return err
+	}
+	if opts.JSON {
+		printJSONTo(out, result)
+	} else {
+		status := "valid"
+		if !result.Valid {
+			status = "INVALID"
+		}
+		kind := "full"
+		if result.Incremental {
+			kind = "incremental"
+		}
+		fmt.Fprintf(out, "Archive: %s (%s, v%d, org %s, %s)\n", status, kind, result.Version, result.OrgID, result.CreatedAt)
+		fmt.Fprintf(out, "Rows: %d across %d tables, %d repos\n", result.TotalRows, len(result.Tables), result.Repos)
+		printBackupMessages(out, "Error", result.Errors)
+		printBackupMessages(out, "Warning", result.Warnings)
+	}
+	if !result.Valid {
+		return errors.New("backup archive failed validation")
+	}
+	return nil
+}
+
+func runBackupRestore(client backupCommandClient, opts backupRestoreOptions, out io.Writer) error {
+	file, err := os.Open(opts.File)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	result, err := client.RestoreBackup(file, opts.Remap, opts.DryRun)
+	if err != nil {
+		return err
+	}
+	if opts.JSON {
+		printJSONTo(out, result)
+		return nil
+	}
+
+	verb := "Restored"
+	if result.DryRun {
+		verb = "Dry run: would restore"
+	}
+	total := 0
+	for _, count := range result.Tables {
+		total += count
+	}
+	mode := "new ids"
+	if !result.Remapped {
+		mode = "original ids"
+	}
+	fmt.Fprintf(out, "%s %d rows into org %s (%s), %d repos\n", verb, total, result.OrgID, mode, result.Repos)
+	names := make([]string, 0, len(result.Tables))
+	for name := range result.Tables {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		line := fmt.Sprintf("  %-36s %d", name, result.Tables[name])
+		if skipped := result.Skipped[name]; skipped > 0 {
+			line += fmt.Sprintf(" (%d skipped)", skipped)
+		}
+		fmt.Fprintln(out, line)
+	}
+	printBackupMessages(out, "Warning", result.Warnings)
+	return nil
+}
+
+func printBackupMessages(out io.Writer, label string, messages []string) {
+	for _, message := range messages {
+		fmt.Fprintf(out, "%s: %s\n", label, message)
+	}
+}
+
+func newBackupCommandClient(orgOverride string) (backupCommandClient, error) {
+	cfg, err := ottercli.LoadConfig()
+	if err != nil {
+		return nil, err
+	}
+	return ottercli.NewClient(cfg, orgOverride)
+}
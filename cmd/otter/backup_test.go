@@ -0,0 +1,180 @@
+package main
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/samhotchkiss/otter-camp/internal/ottercli"
+)
+
+type fakeBackupCommandClient struct {
+	createOpts  ottercli.BackupCreateOptions
+	archive     string
+	downloadErr error
+	uploaded    string
+	remap       bool
+	dryRun      bool
+	validation  ottercli.BackupValidation
+	restore     ottercli.BackupRestoreResult
+}
+
+func (f *fakeBackupCommandClient) DownloadBackup(opts ottercli.BackupCreateOptions, out io.Writer) (int64, error) {
+	f.createOpts = opts
+	if f.downloadErr != nil {
+		_, _ = out.Write([]byte("partial"))
+		return 0, f.downloadErr
+	}
+	n, err := io.WriteString(out, f.archive)
+	return int64(n), err
+}
+
+func (f *fakeBackupCommandClient) ValidateBackup(archive io.Reader) (ottercli.BackupValidation, error) {
+	body, _ := io.ReadAll(archive)
+	f.uploaded = string(body)
+	return f.validation, nil
+}
+
+func (f *fakeBackupCommandClient) RestoreBackup(archive io.Reader, remap, dryRun bool) (ottercli.BackupRestoreResult, error) {
+	body, _ := io.ReadAll(archive)
+	f.uploaded = string(body)
+	f.remap = remap
+	f.dryRun = dryRun
+	return f.restore, nil
+}
+
+func TestBackupCreateWritesArchiveToOutPath(t *testing.T) {
+	client := &fakeBackupCommandClient{archive: "tar-bytes"}
+	outPath := filepath.Join(t.TempDir(), "ws.tar.gz")
+
+	var out bytes.Buffer
+	err := runBackupCommand(
+		[]string{"create", "--out", outPath, "--since", "2026-10-01T00:00:00Z", "--skip-repos", "--org", "org-9"},
+		func(org string) (backupCommandClient, error) {
+			if org != "org-9" {
+				t.Fatalf("org override = %q, want org-9", org)
+			}
+			return client, nil
+		},
+		&out,
+	)
+	if err != nil {
+		t.Fatalf("runBackupCommand() error = %v", err)
+	}
+	if client.createOpts.Since != "2026-10-01T00:00:00Z" || !client.createOpts.SkipRepos {
+		t.Fatalf("create options = %#v", client.createOpts)
+	}
+	data, err := os.ReadFile(outPath)
+	if err != nil || string(data) != "tar-bytes" {
+		t.Fatalf("archive = %q, %v", data, err)
+	}
+	if !strings.Contains(out.String(), "Wrote "+outPath+" (9 bytes)") {
+		t.Fatalf("unexpected output %q", out.String())
+	}
+}
+
+func TestBackupCreateLeavesNoFileOnFailure(t *testing.T) {
+	dir := t.TempDir()
+	client := &fakeBackupCommandClient{downloadErr: io.ErrUnexpectedEOF}
+	err := runBackupCommand(
+		[]string{"create", "--out", filepath.Join(dir, "ws.tar.gz")},
+		func(string) (backupCommandClient, error) { return client, nil },
+		io.Discard,
+	)
+	if err == nil {
+		t.Fatalf("expected download error")
+	}
+	entries, _ := os.ReadDir(dir)
+	if len(entries) != 0 {
+		t.Fatalf("expected no files left behind, got %d", len(entries))
+	}
+}
+
+func TestBackupCreateRejectsBadTimestamp(t *testing.T) {
+	factoryCalled := false
+	err := runBackupCommand(
+		[]string{"create", "--as-of", "last week"},
+		func(string) (backupCommandClient, error) {
+			factoryCalled = true
+			return nil, nil
+		},
+		io.Discard,
+	)
+	if err == nil || !strings.Contains(err.Error(), "--as-of must be an RFC3339 timestamp") {
+		t.Fatalf("expected timestamp error, got %v", err)
+	}
+	if factoryCalled {
+		t.Fatalf("factory should not be called on parse failure")
+	}
+}
+
+func TestBackupRestoreUploadsArchiveWithFlagsAfterFile(t *testing.T) {
+	archivePath := filepath.Join(t.TempDir(), "ws.tar.gz")
+	if err := os.WriteFile(archivePath, []byte("tar-bytes"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	client := &fakeBackupCommandClient{restore: ottercli.BackupRestoreResult{
+		OrgID:    "org-2",
+		DryRun:   true,
+		Remapped: false,
+		Tables:   map[string]int{"projects": 2, "project_issues": 5},
+		Skipped:  map[string]int{"project_issues": 1},
+		Repos:    1,
+		Warnings: []string{"project_issues[3]: skipped: project_id references missing projects p9"},
+	}}
+
+	var out bytes.Buffer
+	err := runBackupCommand(
+		[]string{"restore", archivePath, "--no-remap", "--dry-run"},
+		func(string) (backupCommandClient, error) { return client, nil },
+		&out,
+	)
+	if err != nil {
+		t.Fatalf("runBackupCommand() error = %v", err)
+	}
+	if client.uploaded != "tar-bytes" || client.remap || !client.dryRun {
+		t.Fatalf("restore call = uploaded %q remap %v dryRun %v", client.uploaded, client.remap, client.dryRun)
+	}
+	for _, want := range []string{
+		"Dry run: would restore 7 rows into org org-2 (original ids), 1 repos",
+		"project_issues                       5 (1 skipped)",
+		"Warning: project_issues[3]: skipped",
+	} {
+		if !strings.Contains(out.String(), want) {
+			t.Fatalf("output %q missing %q", out.String(), want)
+		}
+	}
+}
+
+func TestBackupValidateFailsOnInvalidArchive(t *testing.T) {
+	archivePath := filepath.Join(t.TempDir(), "ws.tar.gz")
+	if err := os.WriteFile(archivePath, []byte("tar-bytes"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	client := &fakeBackupCommandClient{validation: ottercli.BackupValidation{
+		Valid:   false,
+		Version: 1,
+		Errors:  []string{"projects[0]: missing name"},
+	}}
+
+	var out bytes.Buffer
+	err := runBackupCommand(
+		[]string{"validate", archivePath},
+		func(string) (backupCommandClient, error) { return client, nil },
+		&out,
+	)
+	if err == nil || err.Error() != "backup archive failed validation" {
+		t.Fatalf("expected validation failure, got %v", err)
+	}
+	if !strings.Contains(out.String(), "Error: projects[0]: missing name") {
+		t.Fatalf("unexpected output %q", out.String())
+	}
+
+	err = runBackupCommand([]string{"validate", archivePath, "--dry-run"}, func(string) (backupCommandClient, error) { return client, nil }, io.Discard)
+	if err == nil || !strings.Contains(err.Error(), "only apply to restore") {
+		t.Fatalf("expected restore-only flag error, got %v", err)
+	}
+}
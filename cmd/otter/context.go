@@ -0,0 +1,162 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/samhotchkiss/otter-camp/internal/ottercli"
+)
+
+const contextUsage = "usage: otter context <list|current|use|add|remove> ..."
+
+func handleContext(args []string) {
+	if err := runContextCommand(args, os.Stdout); err != nil {
+		die(err.Error())
+	}
+}
+
+func runContextCommand(args []string, out io.Writer) error {
+	if len(args) == 0 {
+		return errors.New(contextUsage)
+	}
+	command := args[0]
+	var name string
+	var usage string
+	switch command {
+	case "list":
+		usage = "usage: otter context list [--json]"
+	case "current":
+		usage = "usage: otter context current"
+	case "use", "remove":
+		usage = "usage: otter context " + command + " <name>"
+	case "add":
+		usage = "usage: otter context add <name> [--api <url>] [--token <token>] [--org <org-id>] [--database-url <url>] [--credentials plaintext|keyring|file] [--use]"
+	default:
+		return errors.New(contextUsage)
+	}
+	rest := args[1:]
+	if command == "use" || command == "remove" || command == "add" {
+		if len(rest) == 0 || strings.HasPrefix(rest[0], "-") {
+			return errors.New(usage)
+		}
+		name, rest = rest[0], rest[1:]
+	}
+
+	flags := flag.NewFlagSet("context "+command, flag.ContinueOnError)
+	flags.SetOutput(io.Discard)
+	jsonOut := flags.Bool("json", false, "JSON output")
+	api := flags.String("api", "", "API base URL")
+	token := flags.String("token", "", "session token (oc_sess_*)")
+	org := flags.String("org", "", "default org id")
+	databaseURL := flags.String("database-url", "", "database URL for local migrations")
+	credentials := flags.String("credentials", ottercli.CredentialStorePlaintext, "where to keep the token: plaintext, keyring or file")
+	use := flags.Bool("use", false, "switch to the context after adding it")
+	if err := flags.Parse(rest); err != nil {
+		return err
+	}
+	if len(flags.Args()) != 0 {
+		return errors.New(usage)
+	}
+
+	switch command {
+	case "list":
+		contexts, active, err := ottercli.ListContexts()
+		if err != nil {
+			return err
+		}
+		if *jsonOut {
+			printJSONTo(out, map[string]any{"current": active, "contexts": contexts})
+			return nil
+		}
+		if len(contexts) == 0 {
+			fmt.Fprintln(out, "No contexts. Add one with `otter context add <name> --api <url>` or run `otter auth login`.")
+			return nil
+		}
+		for _, c := range contexts {
+			marker := " "
+			if c.Current {
+				marker = "*"
+			}
+			auth := "no token"
+			if c.HasToken {
+				auth = "token in " + c.CredentialStore
+			}
+			org := c.DefaultOrg
+			if org == "" {
+				org = "-"
+			}
+			fmt.Fprintf(out, "%s %-16s %-36s org %-36s %s\n", marker, c.Name, c.APIBaseURL, org, auth)
+		}
+		return nil
+	case "current":
+		_, active, err := ottercli.ListContexts()
+		if err != nil {
+			return err
+		}
+		fmt.Fprintln(out, active)
+		return nil
+	case "use":
+		if err := ottercli.UseContext(name); err != nil {
+			return err
+		}
+		fmt.Fprintf(out, "Switched to context %q\n", name)
+		return nil
+	case "add":
+		cfg := ottercli.Config{
+			APIBaseURL:      strings.TrimSpace(*api),
+			Token:           strings.TrimSpace(*token),
+			DefaultOrg:      strings.TrimSpace(*org),
+			DatabaseURL:     strings.TrimSpace(*databaseURL),
+			CredentialStore: strings.TrimSpace(*credentials),
+		}
+		if err := ottercli.AddContext(name, cfg, *use); err != nil {
+			return err
+		}
+		fmt.Fprintf(out, "Saved context %q to %s\n", name, mustConfigPath())
+		if *use {
+			fmt.Fprintf(out, "Switched to context %q\n", name)
+		}
+		return nil
+	case "remove":
+		if err := ottercli.RemoveContext(name); err != nil {
+			return err
+		}
+		fmt.Fprintf(out, "Removed context %q\n", name)
+		return nil
+	}
+	return errors.New(contextUsage)
+}
+
+// extractContextFlag removes a global --context <name> (or --context=<name>)
+// from args so every command accepts it without its own flag.
+func extractContextFlag(args []string) ([]string, string, error) {
+	out := make([]string, 0, len(args))
+	name := ""
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		if arg == "--" {
+			out = append(out, args[i:]...)
+			break
+		}
+		switch {
+		case arg == "--context" || arg == "-context":
+			if i+1 >= len(args) || strings.TrimSpace(args[i+1]) == "" {
+				return nil, "", errors.New("--context requires a context name")
+			}
+			name = strings.TrimSpace(args[i+1])
+			i++
+		case strings.HasPrefix(arg, "--context=") || strings.HasPrefix(arg, "-context="):
+			name = strings.TrimSpace(arg[strings.Index(arg, "=")+1:])
+			if name == "" {
+				return nil, "", errors.New("--context requires a context name")
+			}
+		default:
+			out = append(out, arg)
+		}
+	}
+	return out, name, nil
+}
@@ -0,0 +1,73 @@
+package main
+
+import (
+	"bytes"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/samhotchkiss/otter-camp/internal/ottercli"
+)
+
+func TestContextCommandAddUseListRemove(t *testing.T) {
+	tmp := t.TempDir()
+	t.Setenv("HOME", tmp)
+	t.Setenv("XDG_CONFIG_HOME", filepath.Join(tmp, ".config"))
+	t.Setenv(ottercli.ContextEnvVar, "")
+
+	var out bytes.Buffer
+	run := func(args ...string) string {
+		t.Helper()
+		out.Reset()
+		if err := runContextCommand(args, &out); err != nil {
+			t.Fatalf("runContextCommand(%v) error = %v", args, err)
+		}
+		return out.String()
+	}
+
+	run("add", "local", "--api", "http://localhost:4200", "--token", "tok-local", "--org", "org-local")
+	run("add", "hosted", "--token", "tok-hosted", "--org", "org-hosted")
+	if got := run("current"); strings.TrimSpace(got) != "local" {
+		t.Fatalf("current = %q", got)
+	}
+	if got := run("use", "hosted"); !strings.Contains(got, `Switched to context "hosted"`) {
+		t.Fatalf("use output = %q", got)
+	}
+	list := run("list")
+	if !strings.Contains(list, "* hosted") || !strings.Contains(list, "  local") || !strings.Contains(list, "token in plaintext") {
+		t.Fatalf("list output = %q", list)
+	}
+	cfg, err := ottercli.LoadConfig()
+	if err != nil || cfg.Token != "tok-hosted" {
+		t.Fatalf("LoadConfig() = %#v, %v", cfg, err)
+	}
+
+	run("remove", "local")
+	if err := runContextCommand([]string{"use", "local"}, &out); err == nil {
+		t.Fatalf("expected unknown context error")
+	}
+	for _, args := range [][]string{{}, {"add"}, {"add", "--api", "x"}, {"use"}, {"rename", "a"}, {"list", "extra"}} {
+		if err := runContextCommand(args, &out); err == nil {
+			t.Fatalf("expected error for %v", args)
+		}
+	}
+}
+
+func TestExtractContextFlag(t *testing.T) {
+	args, name, err := extractContextFlag([]string{"issue", "list", "--context", "staging", "--json"})
+	if err != nil || name != "staging" || !reflect.DeepEqual(args, []string{"issue", "list", "--json"}) {
+		t.Fatalf("extractContextFlag() = %v %q, %v", args, name, err)
+	}
+	args, name, err = extractContextFlag([]string{"--context=local", "whoami"})
+	if err != nil || name != "local" || !reflect.DeepEqual(args, []string{"whoami"}) {
+		t.Fatalf("extractContextFlag() = %v %q, %v", args, name, err)
+	}
+	args, name, err = extractContextFlag([]string{"memory", "write", "--", "--context", "x"})
+	if err != nil || name != "" || len(args) != 5 {
+		t.Fatalf("args after -- must pass through: %v %q, %v", args, name, err)
+	}
+	if _, _, err := extractContextFlag([]string{"whoami", "--context"}); err == nil {
+		t.Fatalf("expected missing value error")
+	}
+}
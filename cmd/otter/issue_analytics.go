@@ -0,0 +1,173 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/samhotchkiss/otter-camp/internal/ottercli"
+)
+
+type issueAnalyticsCommandClient interface {
+	FindProject(query string) (ottercli.Project, error)
+	ProjectAnalytics(projectID string, opts ottercli.ProjectAnalyticsOptions) (ottercli.IssueAnalyticsReport, error)
+	ExportProjectAnalytics(projectID string, opts ottercli.ProjectAnalyticsOptions, out io.Writer) (int64, error)
+}
+
+type issueAnalyticsClientFactory func(orgOverride string) (issueAnalyticsCommandClient, error)
+
+const issueAnalyticsUsage = "usage: otter issue analytics --project <project> [--since 30d] [--group-by agent|label|priority] [--bucket day|week] [--csv groups|steps|series|issues] [--out <file>] [--json]"
+
+func handleIssueAnalytics(args []string) {
+	if err := runIssueAnalyticsCommand(args, newIssueAnalyticsCommandClient, os.Stdout); err != nil {
+		die(err.Error())
+	}
+}
+
+func runIssueAnalyticsCommand(args []string, factory issueAnalyticsClientFactory, out io.Writer) error {
+	flags := flag.NewFlagSet("issue analytics", flag.ContinueOnError)
+	flags.SetOutput(io.Discard)
+	org := flags.String("org", "", "org id override")
+	jsonOut := flags.Bool("json", false, "JSON output")
+	projectRef := flags.String("project", "", "project name or id (required)")
+	since := flags.String("since", "30d", "window size, e.g. 30d or 4w")
+	groupBy := flags.String("group-by", "", "group metrics by agent, label or priority")
+	bucket := flags.String("bucket", "", "throughput and WIP bucket: day or week")
+	dataset := flags.String("csv", "", "export a dataset as csv: groups, steps, series or issues")
+	outPath := flags.String("out", "", "csv file (default stdout)")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if len(flags.Args()) != 0 {
+		return errors.New(issueAnalyticsUsage)
+	}
+	if strings.TrimSpace(*projectRef) == "" {
+		return errors.New("--project is required")
+	}
+	*groupBy = strings.ToLower(strings.TrimSpace(*groupBy))
+	switch *groupBy {
+	case "", "agent", "label", "priority":
+	default:
+		return errors.New("--group-by must be agent, label or priority")
+	}
+	*dataset = strings.ToLower(strings.TrimSpace(*dataset))
+	switch *dataset {
+	case "", "groups", "steps", "series", "issues":
+	default:
+		return errors.New("--csv must be groups, steps, series or issues")
+	}
+	if strings.TrimSpace(*outPath) != "" && *dataset == "" {
+		return errors.New("--out requires --csv")
+	}
+
+	client, err := factory(strings.TrimSpace(*org))
+	if err != nil {
+		return err
+	}
+	project, err := client.FindProject(*projectRef)
+	if err != nil {
+		return err
+	}
+	opts := ottercli.ProjectAnalyticsOptions{Since: *since, GroupBy: *groupBy, Bucket: *bucket, Dataset: *dataset}
+
+	if *dataset != "" {
+		path := strings.TrimSpace(*outPath)
+		if path == "" {
+			_, err := client.ExportProjectAnalytics(project.ID, opts, out)
+			return err
+		}
+		file, err := os.Create(path)
+		if err != nil {
+			return err
+		}
+		size, err := client.ExportProjectAnalytics(project.ID, opts, file)
+		if closeErr := file.Close(); err == nil {
+			err = closeErr
+		}
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(out, "Wrote %s (%d bytes)\n", path, size)
+		return nil
+	}
+
+	report, err := client.ProjectAnalytics(project.ID, opts)
+	if err != nil {
+		return err
+	}
+	if *jsonOut {
+		printJSONTo(out, report)
+		return nil
+	}
+
+	fmt.Fprintf(out, "Analytics for %s: %s to %s (%d days)\n", project.Name, issueAnalyticsDate(report.From, 0), issueAnalyticsDate(report.To, -1), report.Days)
+	printIssueAnalyticsGroup(out, report.Overall)
+	if len(report.Overall.Steps) > 0 {
+		fmt.Fprintln(out, "Time in step (completed issues):")
+		for _, step := range report.Overall.Steps {
+			fmt.Fprintf(out, "  %-8s %-24s visits %-4d issues %-4d p50 %-8s p85 %s\n",
+				step.Kind, step.Name, step.Visits, step.Issues,
+				issueAnalyticsDuration(step.Duration.P50Seconds), issueAnalyticsDuration(step.Duration.P85Seconds))
+		}
+	}
+	if len(report.Overall.Series) > 0 {
+		fmt.Fprintf(out, "Throughput / WIP per %s:\n", report.Bucket)
+		for _, bucket := range report.Overall.Series {
+			fmt.Fprintf(out, "  %s  done %-4d wip %d\n", issueAnalyticsDate(bucket.Start, 0), bucket.Throughput, bucket.WIP)
+		}
+	}
+	for _, group := range report.Groups {
+		fmt.Fprintf(out, "\n[%s] %s\n", report.GroupBy, group.Name)
+		printIssueAnalyticsGroup(out, group)
+	}
+	return nil
+}
+
+func printIssueAnalyticsGroup(out io.Writer, group ottercli.IssueAnalyticsGroup) {
+	fmt.Fprintf(out, "Completed: %d  Cancelled: %d  Open at end: %d\n", group.Completed, group.Cancelled, group.OpenAtEnd)
+	fmt.Fprintf(out, "Cycle time: p50 %s  p85 %s  avg %s\n",
+		issueAnalyticsDuration(group.CycleTime.P50Seconds), issueAnalyticsDuration(group.CycleTime.P85Seconds), issueAnalyticsDuration(group.CycleTime.AvgSeconds))
+	fmt.Fprintf(out, "Lead time:  p50 %s  p85 %s  avg %s\n",
+		issueAnalyticsDuration(group.LeadTime.P50Seconds), issueAnalyticsDuration(group.LeadTime.P85Seconds), issueAnalyticsDuration(group.LeadTime.AvgSeconds))
+	rate := "-"
+	if group.ReworkRate != nil {
+		rate = fmt.Sprintf("%.0f%%", *group.ReworkRate*100)
+	}
+	fmt.Fprintf(out, "Rework: %d bounce(s) across %d issue(s) (%s of completed)\n", group.ReworkBounces, group.IssuesWithRework, rate)
+}
+
+func issueAnalyticsDuration(seconds *float64) string {
+	if seconds == nil {
+		return "-"
+	}
+	d := time.Duration(*seconds * float64(time.Second)).Round(time.Minute)
+	switch {
+	case d >= 24*time.Hour:
+		return fmt.Sprintf("%.1fd", d.Hours()/24)
+	case d >= time.Hour:
+		return fmt.Sprintf("%dh%02dm", int(d.Hours()), int(d.Minutes())%60)
+	}
+	return fmt.Sprintf("%dm", int(d.Minutes()))
+}
+
+// issueAnalyticsDate formats an RFC3339 report timestamp as a date, shifted
+// by offsetDays so exclusive window ends read as the last included day.
+func issueAnalyticsDate(raw string, offsetDays int) string {
+	t, err := time.Parse(time.RFC3339, raw)
+	if err != nil {
+		return raw
+	}
+	return t.AddDate(0, 0, offsetDays).Format("2006-01-02")
+}
+
+func newIssueAnalyticsCommandClient(orgOverride string) (issueAnalyticsCommandClient, error) {
+	cfg, err := ottercli.LoadConfig()
+	if err != nil {
+		return nil, err
+	}
+	return ottercli.NewClient(cfg, orgOverride)
+}
@@ -0,0 +1,119 @@
+package main
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/samhotchkiss/otter-camp/internal/ottercli"
+)
+
+type fakeIssueAnalyticsCommandClient struct {
+	projectID  string
+	opts       ottercli.ProjectAnalyticsOptions
+	report     ottercli.IssueAnalyticsReport
+	exportBody string
+}
+
+func (f *fakeIssueAnalyticsCommandClient) FindProject(query string) (ottercli.Project, error) {
+	return ottercli.Project{ID: "p-1", Name: query}, nil
+}
+
+func (f *fakeIssueAnalyticsCommandClient) ProjectAnalytics(projectID string, opts ottercli.ProjectAnalyticsOptions) (ottercli.IssueAnalyticsReport, error) {
+	f.projectID, f.opts = projectID, opts
+	return f.report, nil
+}
+
+func (f *fakeIssueAnalyticsCommandClient) ExportProjectAnalytics(projectID string, opts ottercli.ProjectAnalyticsOptions, out io.Writer) (int64, error) {
+	f.projectID, f.opts = projectID, opts
+	n, err := io.WriteString(out, f.exportBody)
+	return int64(n), err
+}
+
+func issueAnalyticsTestFactory(client *fakeIssueAnalyticsCommandClient) issueAnalyticsClientFactory {
+	return func(string) (issueAnalyticsCommandClient, error) { return client, nil }
+}
+
+func TestIssueAnalyticsSummary(t *testing.T) {
+	p50, p85, rate := 5400.0, 2*86400.0, 0.25
+	client := &fakeIssueAnalyticsCommandClient{}
+	client.report = ottercli.IssueAnalyticsReport{
+		From:    "2026-10-05T00:00:00Z",
+		To:      "2026-10-19T00:00:00Z",
+		Days:    14,
+		Bucket:  "day",
+		GroupBy: "agent",
+		Overall: ottercli.IssueAnalyticsGroup{
+			Completed:        4,
+			OpenAtEnd:        2,
+			CycleTime:        ottercli.IssueDurationStats{Count: 4, P50Seconds: &p50, P85Seconds: &p85},
+			ReworkBounces:    3,
+			IssuesWithRework: 1,
+			ReworkRate:       &rate,
+			Steps:            []ottercli.IssueAnalyticsStep{{Kind: "pipeline", Name: "Review", Visits: 5, Issues: 4, Duration: ottercli.IssueDurationStats{P50Seconds: &p50}}},
+			Series:           []ottercli.IssueAnalyticsBucket{{Start: "2026-10-18T00:00:00Z", Throughput: 2, WIP: 3}},
+		},
+		Groups: []ottercli.IssueAnalyticsGroup{{Name: "Reviewer", Completed: 1}},
+	}
+
+	var out bytes.Buffer
+	err := runIssueAnalyticsCommand([]string{"--project", "Docs", "--since", "14d", "--group-by", "Agent"}, issueAnalyticsTestFactory(client), &out)
+	if err != nil {
+		t.Fatalf("runIssueAnalyticsCommand() error = %v", err)
+	}
+	if client.projectID != "p-1" || client.opts.Since != "14d" || client.opts.GroupBy != "agent" || client.opts.Dataset != "" {
+		t.Fatalf("unexpected request %s %#v", client.projectID, client.opts)
+	}
+	for _, want := range []string{
+		"Analytics for Docs: 2026-10-05 to 2026-10-18 (14 days)",
+		"Completed: 4  Cancelled: 0  Open at end: 2",
+		"Cycle time: p50 1h30m  p85 2.0d  avg -",
+		"Rework: 3 bounce(s) across 1 issue(s) (25% of completed)",
+		"pipeline Review",
+		"2026-10-18  done 2    wip 3",
+		"[agent] Reviewer",
+	} {
+		if !strings.Contains(out.String(), want) {
+			t.Fatalf("output missing %q:\n%s", want, out.String())
+		}
+	}
+}
+
+func TestIssueAnalyticsCSVExport(t *testing.T) {
+	client := &fakeIssueAnalyticsCommandClient{exportBody: "group,group_name\nall,All issues\n"}
+	path := filepath.Join(t.TempDir(), "steps.csv")
+
+	var out bytes.Buffer
+	err := runIssueAnalyticsCommand([]string{"--project", "Docs", "--csv", "steps", "--bucket", "week", "--out", path}, issueAnalyticsTestFactory(client), &out)
+	if err != nil {
+		t.Fatalf("runIssueAnalyticsCommand() error = %v", err)
+	}
+	if client.opts.Dataset != "steps" || client.opts.Bucket != "week" {
+		t.Fatalf("unexpected options %#v", client.opts)
+	}
+	written, err := os.ReadFile(path)
+	if err != nil || string(written) != client.exportBody {
+		t.Fatalf("written = %q, %v", written, err)
+	}
+	if !strings.Contains(out.String(), "Wrote "+path+" (32 bytes)") {
+		t.Fatalf("unexpected output %q", out.String())
+	}
+}
+
+func TestIssueAnalyticsValidatesFlags(t *testing.T) {
+	client := &fakeIssueAnalyticsCommandClient{}
+	for _, args := range [][]string{
+		{},
+		{"--project", "Docs", "--group-by", "team"},
+		{"--project", "Docs", "--csv", "people"},
+		{"--project", "Docs", "--out", "x.csv"},
+		{"--project", "Docs", "extra"},
+	} {
+		if err := runIssueAnalyticsCommand(args, issueAnalyticsTestFactory(client), io.Discard); err == nil {
+			t.Fatalf("expected error for %v", args)
+		}
+	}
+}
@@ -0,0 +1,187 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/samhotchkiss/otter-camp/internal/ottercli"
+)
+
+type issueBulkCommandClient interface {
+	issueLookupClient
+	ResolveAgent(query string) (ottercli.Agent, error)
+	BulkIssues(input ottercli.IssueBulkRequest) (ottercli.IssueBulkResult, error)
+}
+
+type issueBulkClientFactory func(orgOverride string) (issueBulkCommandClient, error)
+
+const issueBulkUsage = "usage: otter issue bulk [<issue-id-or-number>...] [--query '<query>' | --view <name>] [--project <project>] " +
+	"[--owner <agent>|none] [--priority <P0-P3>] [--status <work-status>] [--label a,b] [--unlabel a,b] " +
+	"[--flow <template-id>] [--close|--reopen] [--move-to <project>] [--dry-run] [--json]"
+
+func handleIssueBulk(args []string) {
+	if err := runIssueBulkCommand(args, newIssueBulkCommandClient, os.Stdout); err != nil {
+		die(err.Error())
+	}
+}
+
+func runIssueBulkCommand(args []string, factory issueBulkClientFactory, out io.Writer) error {
+	flags := flag.NewFlagSet("issue bulk", flag.ContinueOnError)
+	flags.SetOutput(io.Discard)
+	org := flags.String("org", "", "org id override")
+	jsonOut := flags.Bool("json", false, "JSON output")
+	projectRef := flags.String("project", "", "project name or id; scopes --query and resolves issue numbers")
+	query := flags.String("query", "", "issue query selecting the issues to change")
+	view := flags.String("view", "", "saved view selecting the issues to change")
+	owner := flags.String("owner", "", "owner agent id/name/slug, or none to unassign")
+	priority := flags.String("priority", "", "priority (P0-P3)")
+	status := flags.String("status", "", "work status")
+	addLabels := flags.String("label", "", "comma-separated labels to add")
+	removeLabels := flags.String("unlabel", "", "comma-separated labels to remove")
+	flowTemplateID := flags.String("flow", "", "flow template id to assign")
+	closeIssues := flags.Bool("close", false, "close the issues")
+	reopenIssues := flags.Bool("reopen", false, "reopen the issues")
+	moveTo := flags.String("move-to", "", "project name or id to move the issues to")
+	dryRun := flags.Bool("dry-run", false, "preview changes without applying them")
+	refs, err := parseInterspersedFlags(flags, args)
+	if err != nil {
+		return err
+	}
+
+	hasQuery := strings.TrimSpace(*query) != "" || strings.TrimSpace(*view) != ""
+	if len(refs) == 0 && !hasQuery {
+		return errors.New(issueBulkUsage)
+	}
+	if len(refs) > 0 && hasQuery {
+		return errors.New("pass issue references or --query/--view, not both")
+	}
+	if *closeIssues && *reopenIssues {
+		return errors.New("--close and --reopen cannot be combined")
+	}
+
+	ops := ottercli.IssueBulkOperations{
+		Priority:       strings.TrimSpace(*priority),
+		WorkStatus:     strings.TrimSpace(*status),
+		AddLabels:      splitIssueBulkList(*addLabels),
+		RemoveLabels:   splitIssueBulkList(*removeLabels),
+		FlowTemplateID: strings.TrimSpace(*flowTemplateID),
+	}
+	if *closeIssues {
+		ops.State = "closed"
+	}
+	if *reopenIssues {
+		ops.State = "open"
+	}
+	if ops.Priority == "" && ops.WorkStatus == "" && ops.State == "" && ops.FlowTemplateID == "" &&
+		len(ops.AddLabels) == 0 && len(ops.RemoveLabels) == 0 &&
+		strings.TrimSpace(*owner) == "" && strings.TrimSpace(*moveTo) == "" {
+		return errors.New("at least one change is required (--owner, --priority, --status, --label, --unlabel, --flow, --close, --reopen, --move-to)")
+	}
+
+	client, err := factory(strings.TrimSpace(*org))
+	if err != nil {
+		return err
+	}
+
+	if ownerRef := strings.TrimSpace(*owner); ownerRef != "" {
+		ownerID := ""
+		if !strings.EqualFold(ownerRef, "none") {
+			agent, err := client.ResolveAgent(ownerRef)
+			if err != nil {
+				return err
+			}
+			ownerID = agent.ID
+		}
+		ops.OwnerAgentID = &ownerID
+	}
+	if target := strings.TrimSpace(*moveTo); target != "" {
+		project, err := client.FindProject(target)
+		if err != nil {
+			return err
+		}
+		ops.MoveToProjectID = project.ID
+	}
+
+	input := ottercli.IssueBulkRequest{
+		Query:      strings.TrimSpace(*query),
+		View:       strings.TrimSpace(*view),
+		Operations: ops,
+		DryRun:     *dryRun,
+	}
+	if len(refs) > 0 {
+		for _, ref := range refs {
+			issueID, err := resolveIssueID(client, strings.TrimSpace(*projectRef), ref)
+			if err != nil {
+				return err
+			}
+			input.IssueIDs = append(input.IssueIDs, issueID)
+		}
+	} else if strings.TrimSpace(*projectRef) != "" {
+		project, err := client.FindProject(*projectRef)
+		if err != nil {
+			return err
+		}
+		input.ProjectID = project.ID
+	}
+
+	result, err := client.BulkIssues(input)
+	if err != nil {
+		return err
+	}
+	if *jsonOut {
+		printJSONTo(out, result)
+		return nil
+	}
+
+	switch {
+	case result.DryRun:
+		fmt.Fprintf(out, "Dry run: %d of %d issues would change\n", result.Updated, result.Matched)
+	case result.Applied:
+		fmt.Fprintf(out, "Updated %d of %d issues\n", result.Updated, result.Matched)
+	default:
+		fmt.Fprintf(out, "No changes applied: %d of %d issues failed\n", result.Failed, result.Matched)
+	}
+	for _, item := range result.Items {
+		label := item.IssueID
+		if item.IssueNumber > 0 {
+			label = fmt.Sprintf("#%d", item.IssueNumber)
+		}
+		switch item.Status {
+		case "failed":
+			fmt.Fprintf(out, "  %s failed: %s\n", label, item.Error)
+		case "unchanged":
+			fmt.Fprintf(out, "  %s unchanged\n", label)
+		default:
+			fmt.Fprintf(out, "  %s %s: %s\n", label, item.Title, strings.Join(item.Changes, ", "))
+		}
+	}
+	if !result.DryRun && !result.Applied {
+		return fmt.Errorf("bulk update rolled back: %d issue(s) failed", result.Failed)
+	}
+	return nil
+}
+
+func splitIssueBulkList(raw string) []string {
+	values := make([]string, 0)
+	for _, part := range strings.Split(raw, ",") {
+		if trimmed := strings.TrimSpace(part); trimmed != "" {
+			values = append(values, trimmed)
+		}
+	}
+	if len(values) == 0 {
+		return nil
+	}
+	return values
+}
+
+func newIssueBulkCommandClient(orgOverride string) (issueBulkCommandClient, error) {
+	cfg, err := ottercli.LoadConfig()
+	if err != nil {
+		return nil, err
+	}
+	return ottercli.NewClient(cfg, orgOverride)
+}
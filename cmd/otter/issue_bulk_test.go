@@ -0,0 +1,106 @@
+package main
+
+import (
+	"bytes"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/samhotchkiss/otter-camp/internal/ottercli"
+)
+
+type fakeIssueBulkCommandClient struct {
+	result ottercli.IssueBulkResult
+	got    ottercli.IssueBulkRequest
+}
+
+func (f *fakeIssueBulkCommandClient) FindProject(query string) (ottercli.Project, error) {
+	return ottercli.Project{ID: "p-" + strings.ToLower(query), Name: query}, nil
+}
+
+func (f *fakeIssueBulkCommandClient) ListIssues(projectID string, filters map[string]string) ([]ottercli.Issue, error) {
+	number, _ := strconv.ParseInt(filters["issue_number"], 10, 64)
+	return []ottercli.Issue{{ID: projectID + "-issue-" + filters["issue_number"], IssueNumber: number}}, nil
+}
+
+func (f *fakeIssueBulkCommandClient) ResolveAgent(query string) (ottercli.Agent, error) {
+	return ottercli.Agent{ID: "agent-" + query, Name: query}, nil
+}
+
+func (f *fakeIssueBulkCommandClient) BulkIssues(input ottercli.IssueBulkRequest) (ottercli.IssueBulkResult, error) {
+	f.got = input
+	return f.result, nil
+}
+
+func issueBulkTestFactory(client *fakeIssueBulkCommandClient) issueBulkClientFactory {
+	return func(string) (issueBulkCommandClient, error) { return client, nil }
+}
+
+func TestIssueBulkCommandBuildsQueryRequest(t *testing.T) {
+	client := &fakeIssueBulkCommandClient{result: ottercli.IssueBulkResult{
+		DryRun:  true,
+		Matched: 2,
+		Updated: 1,
+		Items: []ottercli.IssueBulkItemResult{
+			{IssueID: "i-1", IssueNumber: 7, Title: "Launch post", Status: "updated", Changes: []string{"priority: P2 -> P1", "+label triaged"}},
+			{IssueID: "i-2", IssueNumber: 8, Status: "unchanged"},
+		},
+	}}
+	var out bytes.Buffer
+	args := []string{"--query", "is:open label:triage", "--project", "Blog", "--priority", "P1", "--label", "triaged, ", "--owner", "writer", "--close", "--dry-run"}
+	if err := runIssueBulkCommand(args, issueBulkTestFactory(client), &out); err != nil {
+		t.Fatalf("runIssueBulkCommand() error = %v", err)
+	}
+	got := client.got
+	if got.Query != "is:open label:triage" || got.ProjectID != "p-blog" || !got.DryRun || len(got.IssueIDs) != 0 {
+		t.Fatalf("request = %#v", got)
+	}
+	ops := got.Operations
+	if ops.Priority != "P1" || ops.State != "closed" || ops.OwnerAgentID == nil || *ops.OwnerAgentID != "agent-writer" ||
+		strings.Join(ops.AddLabels, "|") != "triaged" {
+		t.Fatalf("operations = %#v", ops)
+	}
+	for _, want := range []string{"Dry run: 1 of 2 issues would change", "#7 Launch post: priority: P2 -> P1, +label triaged", "#8 unchanged"} {
+		if !strings.Contains(out.String(), want) {
+			t.Fatalf("output missing %q: %q", want, out.String())
+		}
+	}
+}
+
+func TestIssueBulkCommandResolvesIssueReferences(t *testing.T) {
+	client := &fakeIssueBulkCommandClient{result: ottercli.IssueBulkResult{
+		Matched: 2,
+		Failed:  1,
+		Items:   []ottercli.IssueBulkItemResult{{IssueID: "i-1", Status: "failed", Error: "invalid work_status transition"}},
+	}}
+	var out bytes.Buffer
+	args := []string{"12", "--project", "Blog", "345", "--owner", "none", "--move-to", "Docs"}
+	err := runIssueBulkCommand(args, issueBulkTestFactory(client), &out)
+	if err == nil || !strings.Contains(err.Error(), "rolled back") {
+		t.Fatalf("runIssueBulkCommand() error = %v", err)
+	}
+	if strings.Join(client.got.IssueIDs, ",") != "p-blog-issue-12,p-blog-issue-345" {
+		t.Fatalf("issue ids = %#v", client.got.IssueIDs)
+	}
+	ops := client.got.Operations
+	if ops.OwnerAgentID == nil || *ops.OwnerAgentID != "" || ops.MoveToProjectID != "p-docs" {
+		t.Fatalf("operations = %#v", ops)
+	}
+	if !strings.Contains(out.String(), "i-1 failed: invalid work_status transition") {
+		t.Fatalf("output = %q", out.String())
+	}
+
+	for _, tc := range []struct {
+		args []string
+		want string
+	}{
+		{[]string{"--priority", "P1"}, "usage"},
+		{[]string{"12", "--query", "is:open", "--priority", "P1"}, "not both"},
+		{[]string{"12", "--project", "Blog"}, "at least one change"},
+		{[]string{"12", "--project", "Blog", "--close", "--reopen"}, "cannot be combined"},
+	} {
+		if err := runIssueBulkCommand(tc.args, issueBulkTestFactory(client), &out); err == nil || !strings.Contains(err.Error(), tc.want) {
+			t.Fatalf("args %v error = %v, want %q", tc.args, err, tc.want)
+		}
+	}
+}
@@ -0,0 +1,160 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/samhotchkiss/otter-camp/internal/ottercli"
+)
+
+type issueImportCommandClient interface {
+	FindProject(query string) (ottercli.Project, error)
+	ImportIssues(projectID string, input ottercli.IssueImportRequest) (ottercli.IssueImportResult, error)
+}
+
+type issueImportClientFactory func(orgOverride string) (issueImportCommandClient, error)
+
+const issueImportUsage = "usage: otter issue import --project <project> --source jira|linear|csv --file <export> [--format json|csv] [--map field=Column ...] [--status \"Status=work_status\" ...] [--assignee \"Person=agent\" ...] [--comment-author <agent>] [--dry-run] [--json]"
+
+func handleIssueImport(args []string) {
+	if err := runIssueImportCommand(args, newIssueImportCommandClient, os.Stdout); err != nil {
+		die(err.Error())
+	}
+}
+
+func runIssueImportCommand(args []string, factory issueImportClientFactory, out io.Writer) error {
+	flags := flag.NewFlagSet("issue import", flag.ContinueOnError)
+	flags.SetOutput(io.Discard)
+	org := flags.String("org", "", "org id override")
+	jsonOut := flags.Bool("json", false, "JSON output")
+	projectRef := flags.String("project", "", "project name or id (required)")
+	source := flags.String("source", "", "export source: jira, linear or csv (required)")
+	file := flags.String("file", "", "path to the export file (required)")
+	format := flags.String("format", "", "export format: json or csv (default: from the file extension)")
+	commentAuthor := flags.String("comment-author", "", "agent that posts comments whose author is not an agent")
+	dryRun := flags.Bool("dry-run", false, "report what would be imported without writing")
+	var columns, statuses, assignees cliRepeatedFlag
+	flags.Var(&columns, "map", "generic CSV column as field=Column, e.g. title=Summary (repeatable)")
+	flags.Var(&statuses, "status", "status override as Status=work_status, e.g. \"QA=review\" (repeatable)")
+	flags.Var(&assignees, "assignee", "assignee mapping as Person=agent, e.g. \"Jane Doe=analyst-1\" (repeatable)")
+	positional, err := parseInterspersedFlags(flags, args)
+	if err != nil {
+		return err
+	}
+	if len(positional) > 0 {
+		return errors.New(issueImportUsage)
+	}
+	if strings.TrimSpace(*projectRef) == "" {
+		return errors.New("--project is required")
+	}
+	if strings.TrimSpace(*source) == "" || strings.TrimSpace(*file) == "" {
+		return errors.New(issueImportUsage)
+	}
+
+	input := ottercli.IssueImportRequest{
+		Source:        strings.ToLower(strings.TrimSpace(*source)),
+		Format:        strings.ToLower(strings.TrimSpace(*format)),
+		CommentAuthor: strings.TrimSpace(*commentAuthor),
+		DryRun:        *dryRun,
+	}
+	if input.Format == "" {
+		switch strings.ToLower(filepath.Ext(*file)) {
+		case ".json":
+			input.Format = "json"
+		case ".csv":
+			input.Format = "csv"
+		}
+	}
+	if input.Columns, err = parseIssueImportPairs("--map", columns); err != nil {
+		return err
+	}
+	if input.StatusMap, err = parseIssueImportPairs("--status", statuses); err != nil {
+		return err
+	}
+	if input.AssigneeMap, err = parseIssueImportPairs("--assignee", assignees); err != nil {
+		return err
+	}
+	content, err := os.ReadFile(*file)
+	if err != nil {
+		return fmt.Errorf("read %s: %w", *file, err)
+	}
+	input.Content = string(content)
+
+	client, err := factory(*org)
+	if err != nil {
+		return err
+	}
+	project, err := client.FindProject(*projectRef)
+	if err != nil {
+		return err
+	}
+	result, err := client.ImportIssues(project.ID, input)
+	if err != nil {
+		return err
+	}
+	if *jsonOut {
+		printJSONTo(out, result)
+		return nil
+	}
+
+	verb := "Imported"
+	if result.DryRun {
+		verb = "Dry run:"
+	}
+	fmt.Fprintf(out, "%s %d %s record(s) into %s: %d created, %d updated, %d unchanged, %d skipped, %d failed\n",
+		verb, result.Total, result.Source, project.Name, result.Created, result.Updated, result.Unchanged, result.Skipped, result.Failed)
+	fmt.Fprintf(out, "Parent links: %d  Labels: %d  Comments: %d imported, %d already imported, %d skipped\n",
+		result.ParentLinks, result.LabelsApplied, result.CommentsImported, result.CommentsExisting, result.CommentsSkipped)
+	for _, item := range result.Items {
+		if item.Action == "unchanged" {
+			continue
+		}
+		line := fmt.Sprintf("  %-9s %-12s %s", item.Action, item.Key, item.Title)
+		if item.IssueNumber != nil {
+			line += fmt.Sprintf(" (#%d)", *item.IssueNumber)
+		}
+		if item.Reason != "" {
+			line += " — " + item.Reason
+		}
+		fmt.Fprintln(out, line)
+	}
+	if len(result.Summary.UnmappedStatuses) > 0 {
+		fmt.Fprintf(out, "Unmapped statuses (imported as queued): %s\n", strings.Join(result.Summary.UnmappedStatuses, ", "))
+	}
+	if len(result.Summary.UnmappedAssignees) > 0 {
+		fmt.Fprintf(out, "Unmapped assignees (imported without owner): %s\n", strings.Join(result.Summary.UnmappedAssignees, ", "))
+	}
+	for _, warning := range result.Summary.Warnings {
+		fmt.Fprintf(out, "warning: %s\n", warning)
+	}
+	return nil
+}
+
+func parseIssueImportPairs(flagName string, values []string) (map[string]string, error) {
+	if len(values) == 0 {
+		return nil, nil
+	}
+	pairs := make(map[string]string, len(values))
+	for _, value := range values {
+		key, mapped, ok := strings.Cut(value, "=")
+		key, mapped = strings.TrimSpace(key), strings.TrimSpace(mapped)
+		if !ok || key == "" || mapped == "" {
+			return nil, fmt.Errorf("%s must be name=value, got %q", flagName, value)
+		}
+		pairs[key] = mapped
+	}
+	return pairs, nil
+}
+
+func newIssueImportCommandClient(orgOverride string) (issueImportCommandClient, error) {
+	cfg, err := ottercli.LoadConfig()
+	if err != nil {
+		return nil, err
+	}
+	return ottercli.NewClient(cfg, orgOverride)
+}
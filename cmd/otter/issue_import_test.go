@@ -0,0 +1,105 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/samhotchkiss/otter-camp/internal/ottercli"
+)
+
+type fakeIssueImportCommandClient struct {
+	projectID string
+	input     ottercli.IssueImportRequest
+	result    ottercli.IssueImportResult
+}
+
+func (f *fakeIssueImportCommandClient) FindProject(query string) (ottercli.Project, error) {
+	return ottercli.Project{ID: "p-1", Name: query}, nil
+}
+
+func (f *fakeIssueImportCommandClient) ImportIssues(projectID string, input ottercli.IssueImportRequest) (ottercli.IssueImportResult, error) {
+	f.projectID, f.input = projectID, input
+	return f.result, nil
+}
+
+func issueImportTestFactory(client *fakeIssueImportCommandClient) issueImportClientFactory {
+	return func(string) (issueImportCommandClient, error) { return client, nil }
+}
+
+func TestIssueImportDryRun(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "backlog.csv")
+	if err := os.WriteFile(path, []byte("Ref,Name\nT-1,Write docs\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	number := int64(7)
+	client := &fakeIssueImportCommandClient{}
+	client.result = ottercli.IssueImportResult{
+		DryRun:  true,
+		Source:  "csv",
+		Total:   2,
+		Created: 1,
+		Skipped: 1,
+		Items: []ottercli.IssueImportItem{
+			{Key: "T-1", Title: "Write docs", Action: "create"},
+			{Key: "T-2", Title: "Old", Action: "unchanged", IssueNumber: &number},
+			{Key: "", Title: "No key", Action: "skip", Reason: "missing key"},
+		},
+	}
+	client.result.Summary.UnmappedStatuses = []string{"Parked"}
+	client.result.Summary.Warnings = []string{"dry run: no issues, labels or comments were written"}
+
+	var out bytes.Buffer
+	err := runIssueImportCommand([]string{
+		"--project", "Docs",
+		"--source", "CSV",
+		"--file", path,
+		"--map", "key=Ref",
+		"--map", "title=Name",
+		"--status", "Parked=on_hold",
+		"--assignee", "Jane Doe=analyst-1",
+		"--comment-author", "analyst-1",
+		"--dry-run",
+	}, issueImportTestFactory(client), &out)
+	if err != nil {
+		t.Fatalf("import error = %v", err)
+	}
+	input := client.input
+	if client.projectID != "p-1" || input.Source != "csv" || input.Format != "csv" || !input.DryRun {
+		t.Fatalf("input = %#v", input)
+	}
+	if input.Columns["title"] != "Name" || input.StatusMap["Parked"] != "on_hold" || input.AssigneeMap["Jane Doe"] != "analyst-1" || input.CommentAuthor != "analyst-1" {
+		t.Fatalf("input = %#v", input)
+	}
+	if !strings.Contains(input.Content, "T-1,Write docs") {
+		t.Fatalf("content = %q", input.Content)
+	}
+	for _, want := range []string{
+		"Dry run: 2 csv record(s) into Docs: 1 created, 0 updated, 0 unchanged, 1 skipped, 0 failed",
+		"create    T-1",
+		"missing key",
+		"Unmapped statuses (imported as queued): Parked",
+		"warning: dry run",
+	} {
+		if !strings.Contains(out.String(), want) {
+			t.Fatalf("output = %q, missing %q", out.String(), want)
+		}
+	}
+	if strings.Contains(out.String(), "T-2") {
+		t.Fatalf("unchanged items should not be listed: %q", out.String())
+	}
+
+	for _, args := range [][]string{
+		{"--source", "jira", "--file", path},
+		{"--project", "Docs", "--file", path},
+		{"--project", "Docs", "--source", "jira"},
+		{"--project", "Docs", "--source", "jira", "--file", path, "--status", "Parked"},
+		{"--project", "Docs", "--source", "jira", "--file", filepath.Join(t.TempDir(), "missing.json")},
+	} {
+		if err := runIssueImportCommand(args, issueImportTestFactory(client), &out); err == nil {
+			t.Fatalf("%v: expected error", args)
+		}
+	}
+}
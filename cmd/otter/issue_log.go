@@ -0,0 +1,120 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/samhotchkiss/otter-camp/internal/ottercli"
+)
+
+type issueLogCommandClient interface {
+	issueLookupClient
+	GetIssueTimeline(issueID, cursor string, limit int, types []string) (ottercli.IssueTimelinePage, error)
+}
+
+type issueLogClientFactory func(orgOverride string) (issueLogCommandClient, error)
+
+func handleIssueLog(args []string) {
+	if err := runIssueLogCommand(args, newIssueLogCommandClient, os.Stdout); err != nil {
+		die(err.Error())
+	}
+}
+
+// runIssueLogCommand handles `otter issue log`. Without --all it prints one
+// page and the cursor for the next.
+func runIssueLogCommand(args []string, factory issueLogClientFactory, out io.Writer) error {
+	const usage = "usage: otter issue log <issue> [--project <project>] [--type <type,...>] [--limit N] [--cursor <cursor>] [--all] [--json]"
+	flags := flag.NewFlagSet("issue log", flag.ContinueOnError)
+	flags.SetOutput(io.Discard)
+	org := flags.String("org", "", "org id override")
+	jsonOut := flags.Bool("json", false, "JSON output")
+	projectRef := flags.String("project", "", "project name or id (required for issue numbers)")
+	typeFilter := flags.String("type", "", "comma-separated event types")
+	limit := flags.Int("limit", 50, "events per page")
+	cursor := flags.String("cursor", "", "cursor from a previous page")
+	all := flags.Bool("all", false, "fetch every page")
+	positional, err := parseInterspersedFlags(flags, args)
+	if err != nil {
+		return err
+	}
+	if len(positional) != 1 || *limit <= 0 {
+		return errors.New(usage)
+	}
+	var types []string
+	for _, part := range strings.Split(*typeFilter, ",") {
+		if part = strings.TrimSpace(part); part != "" {
+			types = append(types, part)
+		}
+	}
+
+	client, err := factory(strings.TrimSpace(*org))
+	if err != nil {
+		return err
+	}
+	issueID, err := resolveIssueID(client, strings.TrimSpace(*projectRef), positional[0])
+	if err != nil {
+		return err
+	}
+
+	page, err := client.GetIssueTimeline(issueID, strings.TrimSpace(*cursor), *limit, types)
+	if err != nil {
+		return err
+	}
+	for *all && page.NextCursor != "" {
+		next, err := client.GetIssueTimeline(issueID, page.NextCursor, *limit, types)
+		if err != nil {
+			return err
+		}
+		page.Items = append(page.Items, next.Items...)
+		page.NextCursor = next.NextCursor
+	}
+
+	if *jsonOut {
+		printJSONTo(out, page)
+		return nil
+	}
+	if len(page.Items) == 0 {
+		fmt.Fprintln(out, "No timeline events.")
+		return nil
+	}
+	for _, event := range page.Items {
+		fmt.Fprintf(out, "%s  %-20s %-20s %s\n",
+			formatIssueLogTime(event.OccurredAt), event.Type, issueLogActor(event), event.Summary)
+	}
+	if page.NextCursor != "" {
+		fmt.Fprintf(out, "More events: otter issue log %s --cursor %s\n", positional[0], page.NextCursor)
+	}
+	return nil
+}
+
+func formatIssueLogTime(raw string) string {
+	parsed, err := time.Parse(time.RFC3339Nano, raw)
+	if err != nil {
+		return raw
+	}
+	return parsed.Local().Format("2006-01-02 15:04")
+}
+
+func issueLogActor(event ottercli.IssueTimelineEvent) string {
+	switch {
+	case strings.TrimSpace(event.Actor.Name) != "":
+		return event.Actor.Name
+	case strings.TrimSpace(event.Actor.ID) != "":
+		return event.Actor.Type + ":" + event.Actor.ID
+	default:
+		return event.Actor.Type
+	}
+}
+
+func newIssueLogCommandClient(orgOverride string) (issueLogCommandClient, error) {
+	cfg, err := ottercli.LoadConfig()
+	if err != nil {
+		return nil, err
+	}
+	return ottercli.NewClient(cfg, orgOverride)
+}
@@ -0,0 +1,64 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/samhotchkiss/otter-camp/internal/ottercli"
+)
+
+type fakeIssueLogCommandClient struct {
+	cursors []string
+	types   []string
+}
+
+func (f *fakeIssueLogCommandClient) FindProject(query string) (ottercli.Project, error) {
+	return ottercli.Project{ID: "p-1", Name: query}, nil
+}
+
+func (f *fakeIssueLogCommandClient) ListIssues(string, map[string]string) ([]ottercli.Issue, error) {
+	return []ottercli.Issue{{ID: "issue-7", IssueNumber: 7}}, nil
+}
+
+func (f *fakeIssueLogCommandClient) GetIssueTimeline(issueID, cursor string, limit int, types []string) (ottercli.IssueTimelinePage, error) {
+	f.cursors = append(f.cursors, cursor)
+	f.types = types
+	event := ottercli.IssueTimelineEvent{ID: "e-" + cursor, Type: "comment", OccurredAt: "2026-10-18T09:00:00Z", Summary: "Event after " + cursor}
+	event.Actor.Type = "agent"
+	event.Actor.Name = "Derek"
+	page := ottercli.IssueTimelinePage{Items: []ottercli.IssueTimelineEvent{event}}
+	if cursor == "" {
+		page.NextCursor = "c2"
+	}
+	return page, nil
+}
+
+func TestRunIssueLogCommand(t *testing.T) {
+	fake := &fakeIssueLogCommandClient{}
+	factory := func(string) (issueLogCommandClient, error) { return fake, nil }
+
+	var out bytes.Buffer
+	if err := runIssueLogCommand([]string{"7", "--project", "web", "--type", "comment, commit"}, factory, &out); err != nil {
+		t.Fatalf("runIssueLogCommand() error = %v", err)
+	}
+	if len(fake.cursors) != 1 || strings.Join(fake.types, ",") != "comment,commit" {
+		t.Fatalf("cursors = %#v types = %#v", fake.cursors, fake.types)
+	}
+	if !strings.Contains(out.String(), "Derek") || !strings.Contains(out.String(), "--cursor c2") {
+		t.Fatalf("output = %q", out.String())
+	}
+
+	fake = &fakeIssueLogCommandClient{}
+	out.Reset()
+	if err := runIssueLogCommand([]string{"7", "--project", "web", "--all"}, factory, &out); err != nil {
+		t.Fatalf("runIssueLogCommand(--all) error = %v", err)
+	}
+	if strings.Join(fake.cursors, ",") != ",c2" || strings.Contains(out.String(), "--cursor") {
+		t.Fatalf("cursors = %#v output = %q", fake.cursors, out.String())
+	}
+
+	if err := runIssueLogCommand(nil, factory, &out); err == nil || !strings.Contains(err.Error(), "usage") {
+		t.Fatalf("expected usage error, got %v", err)
+	}
+}
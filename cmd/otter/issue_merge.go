@@ -0,0 +1,80 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/samhotchkiss/otter-camp/internal/ottercli"
+)
+
+type issueMergeCommandClient interface {
+	issueLookupClient
+	MergeIssue(issueID, intoIssueID string) (ottercli.IssueMergeResult, error)
+}
+
+type issueMergeClientFactory func(orgOverride string) (issueMergeCommandClient, error)
+
+func handleIssueMerge(args []string) {
+	if err := runIssueMergeCommand(args, newIssueMergeCommandClient, os.Stdout); err != nil {
+		die(err.Error())
+	}
+}
+
+// runIssueMergeCommand handles `otter issue merge`.
+func runIssueMergeCommand(args []string, factory issueMergeClientFactory, out io.Writer) error {
+	const usage = "usage: otter issue merge <duplicate> --into <canonical> [--project <project>] [--json]"
+	flags := flag.NewFlagSet("issue merge", flag.ContinueOnError)
+	flags.SetOutput(io.Discard)
+	org := flags.String("org", "", "org id override")
+	jsonOut := flags.Bool("json", false, "JSON output")
+	projectRef := flags.String("project", "", "project name or id (required for issue numbers)")
+	into := flags.String("into", "", "canonical issue id or number to merge into")
+	positional, err := parseInterspersedFlags(flags, args)
+	if err != nil {
+		return err
+	}
+	if len(positional) != 1 || strings.TrimSpace(*into) == "" {
+		return errors.New(usage)
+	}
+
+	client, err := factory(strings.TrimSpace(*org))
+	if err != nil {
+		return err
+	}
+	duplicateID, err := resolveIssueID(client, strings.TrimSpace(*projectRef), positional[0])
+	if err != nil {
+		return err
+	}
+	canonicalID, err := resolveIssueID(client, strings.TrimSpace(*projectRef), strings.TrimSpace(*into))
+	if err != nil {
+		return err
+	}
+
+	result, err := client.MergeIssue(duplicateID, canonicalID)
+	if err != nil {
+		return err
+	}
+	if *jsonOut {
+		printJSONTo(out, result)
+		return nil
+	}
+	fmt.Fprintf(out, "Merged issue #%d into #%d\n", result.Duplicate.IssueNumber, result.Canonical.IssueNumber)
+	fmt.Fprintf(out, "Moved %d comment(s), %d participant(s), %d label(s), %d sub-issue(s)\n",
+		result.MovedComments, result.MovedParticipants, result.MovedLabels, result.MovedSubIssues)
+	if result.MovedGitHubLink {
+		fmt.Fprintln(out, "Moved GitHub link")
+	}
+	return nil
+}
+
+func newIssueMergeCommandClient(orgOverride string) (issueMergeCommandClient, error) {
+	cfg, err := ottercli.LoadConfig()
+	if err != nil {
+		return nil, err
+	}
+	return ottercli.NewClient(cfg, orgOverride)
+}
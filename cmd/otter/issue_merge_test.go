@@ -0,0 +1,50 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/samhotchkiss/otter-camp/internal/ottercli"
+)
+
+type fakeIssueMergeCommandClient struct {
+	merged [2]string
+}
+
+func (f *fakeIssueMergeCommandClient) FindProject(query string) (ottercli.Project, error) {
+	return ottercli.Project{ID: "p-1", Name: query}, nil
+}
+
+func (f *fakeIssueMergeCommandClient) ListIssues(string, map[string]string) ([]ottercli.Issue, error) {
+	return []ottercli.Issue{{ID: "issue-7", IssueNumber: 7}, {ID: "issue-3", IssueNumber: 3}}, nil
+}
+
+func (f *fakeIssueMergeCommandClient) MergeIssue(issueID, intoIssueID string) (ottercli.IssueMergeResult, error) {
+	f.merged = [2]string{issueID, intoIssueID}
+	return ottercli.IssueMergeResult{
+		Duplicate:     ottercli.Issue{ID: issueID, IssueNumber: 7},
+		Canonical:     ottercli.Issue{ID: intoIssueID, IssueNumber: 3},
+		MovedComments: 2,
+	}, nil
+}
+
+func TestRunIssueMergeCommand(t *testing.T) {
+	fake := &fakeIssueMergeCommandClient{}
+	factory := func(string) (issueMergeCommandClient, error) { return fake, nil }
+
+	var out bytes.Buffer
+	if err := runIssueMergeCommand([]string{"7", "--into", "3", "--project", "web"}, factory, &out); err != nil {
+		t.Fatalf("runIssueMergeCommand() error = %v", err)
+	}
+	if fake.merged != [2]string{"issue-7", "issue-3"} {
+		t.Fatalf("merged = %#v", fake.merged)
+	}
+	if !strings.Contains(out.String(), "Merged issue #7 into #3") || !strings.Contains(out.String(), "Moved 2 comment(s)") {
+		t.Fatalf("output = %q", out.String())
+	}
+
+	if err := runIssueMergeCommand([]string{"7"}, factory, &out); err == nil || !strings.Contains(err.Error(), "usage") {
+		t.Fatalf("expected usage error, got %v", err)
+	}
+}
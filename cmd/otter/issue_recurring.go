@@ -0,0 +1,349 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/samhotchkiss/otter-camp/internal/ottercli"
+)
+
+type issueRecurringCommandClient interface {
+	FindProject(query string) (ottercli.Project, error)
+	ResolveAgent(query string) (ottercli.Agent, error)
+	ListRecurringIssues(projectID string) ([]ottercli.RecurringIssue, error)
+	GetRecurringIssue(projectID, recurringID string) (ottercli.RecurringIssue, error)
+	CreateRecurringIssue(projectID string, input map[string]any) (ottercli.RecurringIssue, error)
+	UpdateRecurringIssue(projectID, recurringID string, input map[string]any) (ottercli.RecurringIssue, error)
+	DeleteRecurringIssue(projectID, recurringID string) error
+	RunRecurringIssue(projectID, recurringID string) (ottercli.RecurringIssueRunResult, error)
+}
+
+type issueRecurringClientFactory func(orgOverride string) (issueRecurringCommandClient, error)
+
+const issueRecurringUsage = "usage: otter issue recurring <list|show|create|update|delete|run> --project <project> ..."
+
+func handleIssueRecurring(args []string) {
+	if err := runIssueRecurringCommand(args, newIssueRecurringCommandClient, os.Stdout); err != nil {
+		die(err.Error())
+	}
+}
+
+func runIssueRecurringCommand(args []string, factory issueRecurringClientFactory, out io.Writer) error {
+	command := "list"
+	if len(args) > 0 && !strings.HasPrefix(args[0], "-") {
+		command, args = args[0], args[1:]
+	}
+	var usage string
+	switch command {
+	case "list":
+		usage = "usage: otter issue recurring list --project <project> [--json]"
+	case "show":
+		usage = "usage: otter issue recurring show <name-or-id> --project <project> [--json]"
+	case "create":
+		usage = "usage: otter issue recurring create <name> --project <project> --title <template> (--schedule <cron>|--every <duration>|--at <rfc3339>) [--tz <zone>] [--body <template>] [--template <issue-template>] [--field key=value ...] [--owner <agent>] [--flow <flow>] [--priority P0-P3] [--skip-if-open] [--disabled] [--json]"
+	case "update":
+		usage = "usage: otter issue recurring update <name-or-id> --project <project> [--name <name>] [--title <template>] [--schedule <cron>|--every <duration>|--at <rfc3339>] [--tz <zone>] [--body <template>] [--template <issue-template>|none] [--field key=value ...] [--owner <agent>|none] [--flow <flow>|none] [--priority P0-P3] [--skip-if-open=true|false] [--enable|--disable] [--json]"
+	case "delete":
+		usage = "usage: otter issue recurring delete <name-or-id> --project <project>"
+	case "run":
+		usage = "usage: otter issue recurring run <name-or-id> --project <project> [--json]"
+	default:
+		return errors.New(issueRecurringUsage)
+	}
+
+	flags := flag.NewFlagSet("issue recurring "+command, flag.ContinueOnError)
+	flags.SetOutput(io.Discard)
+	org := flags.String("org", "", "org id override")
+	jsonOut := flags.Bool("json", false, "JSON output")
+	projectRef := flags.String("project", "", "project name or id (required)")
+	name := flags.String("name", "", "new definition name (update only)")
+	title := flags.String("title", "", "issue title template, e.g. \"Weekly metrics report — {{date}}\"")
+	body := flags.String("body", "", "issue body template")
+	templateRef := flags.String("template", "", "issue template name or id")
+	owner := flags.String("owner", "", "agent to own each generated issue")
+	flow := flags.String("flow", "", "flow template name or id")
+	priority := flags.String("priority", "", "issue priority (P0-P3)")
+	schedule := flags.String("schedule", "", "cron expression")
+	every := flags.String("every", "", "interval duration (for example: 24h)")
+	at := flags.String("at", "", "one-shot RFC3339 timestamp")
+	timezone := flags.String("tz", "", "IANA timezone for the schedule and date variables")
+	skipIfOpen := flags.Bool("skip-if-open", false, "skip a run while the previous issue is still open")
+	disabled := flags.Bool("disabled", false, "create the definition disabled")
+	enable := flags.Bool("enable", false, "enable the definition")
+	disable := flags.Bool("disable", false, "disable the definition")
+	var fieldValues cliRepeatedFlag
+	flags.Var(&fieldValues, "field", "template field value as key=value; values may use date variables (repeatable)")
+	positional, err := parseInterspersedFlags(flags, args)
+	if err != nil {
+		return err
+	}
+	set := map[string]bool{}
+	flags.Visit(func(f *flag.Flag) { set[f.Name] = true })
+
+	ref := ""
+	switch {
+	case len(positional) > 1:
+		return errors.New(usage)
+	case len(positional) == 1:
+		ref = strings.TrimSpace(positional[0])
+	}
+	if (command == "list") != (ref == "") {
+		return errors.New(usage)
+	}
+	if strings.TrimSpace(*projectRef) == "" {
+		return errors.New("--project is required")
+	}
+	if *enable && *disable {
+		return errors.New("--enable and --disable are mutually exclusive")
+	}
+
+	input := map[string]any{}
+	if command == "create" || command == "update" {
+		if command == "create" {
+			input["name"] = ref
+			if strings.TrimSpace(*title) == "" {
+				return errors.New("--title is required")
+			}
+			if *disabled {
+				input["enabled"] = false
+			}
+		} else if set["name"] {
+			input["name"] = strings.TrimSpace(*name)
+		}
+		if set["title"] {
+			input["title_template"] = *title
+		}
+		if set["body"] {
+			input["body_template"] = *body
+		}
+		if set["priority"] {
+			input["priority"] = strings.ToUpper(strings.TrimSpace(*priority))
+		}
+		if set["tz"] {
+			input["timezone"] = strings.TrimSpace(*timezone)
+		}
+		if set["skip-if-open"] {
+			input["skip_if_open"] = *skipIfOpen
+		}
+		if *enable {
+			input["enabled"] = true
+		}
+		if *disable {
+			input["enabled"] = false
+		}
+		if set["template"] {
+			input["template_id"] = recurringIssueClearableFlag(*templateRef)
+		}
+		if set["flow"] {
+			input["flow_template_id"] = recurringIssueClearableFlag(*flow)
+		}
+		if len(fieldValues) > 0 {
+			fields, err := parseIssueFieldFlags(fieldValues)
+			if err != nil {
+				return err
+			}
+			input["fields"] = fields
+		}
+		if command == "create" || set["schedule"] || set["every"] || set["at"] {
+			spec, err := resolveJobCreateSchedule(*schedule, *every, *at)
+			if err != nil {
+				return err
+			}
+			input["schedule_kind"] = spec.Kind
+			input["cron_expr"] = spec.CronExpr
+			input["interval_ms"] = spec.IntervalMS
+			input["run_at"] = spec.RunAt
+		}
+	}
+
+	client, err := factory(strings.TrimSpace(*org))
+	if err != nil {
+		return err
+	}
+	project, err := client.FindProject(*projectRef)
+	if err != nil {
+		return err
+	}
+	if ownerRef := strings.TrimSpace(*owner); set["owner"] && (command == "create" || command == "update") {
+		ownerID := ""
+		if ownerRef != "" && !strings.EqualFold(ownerRef, "none") {
+			ownerID = ownerRef
+			if !issueUUIDPattern.MatchString(ownerRef) {
+				agent, err := client.ResolveAgent(ownerRef)
+				if err != nil {
+					return err
+				}
+				ownerID = agent.ID
+			}
+		}
+		input["owner_agent_id"] = ownerID
+	}
+
+	if command == "list" {
+		items, err := client.ListRecurringIssues(project.ID)
+		if err != nil {
+			return err
+		}
+		if *jsonOut {
+			printJSONTo(out, map[string]any{"items": items, "total": len(items)})
+			return nil
+		}
+		if len(items) == 0 {
+			fmt.Fprintln(out, "No recurring issues. Create one with `otter issue recurring create <name> --title <template> --schedule <cron>`.")
+			return nil
+		}
+		for _, item := range items {
+			fmt.Fprintf(out, "%-24s %-8s %-28s next=%s\n", item.Name, recurringIssueState(item), recurringIssueScheduleLabel(item), recurringIssueText(item.NextRunAt, "-"))
+		}
+		return nil
+	}
+	if command == "create" {
+		created, err := client.CreateRecurringIssue(project.ID, input)
+		if err != nil {
+			return err
+		}
+		if *jsonOut {
+			printJSONTo(out, created)
+			return nil
+		}
+		fmt.Fprintf(out, "Created recurring issue %q (%s); next run %s\n", created.Name, created.ID, recurringIssueText(created.NextRunAt, "-"))
+		return nil
+	}
+
+	recurringID, err := resolveRecurringIssueRef(client, project.ID, ref)
+	if err != nil {
+		return err
+	}
+	switch command {
+	case "show":
+		item, err := client.GetRecurringIssue(project.ID, recurringID)
+		if err != nil {
+			return err
+		}
+		if *jsonOut {
+			printJSONTo(out, item)
+			return nil
+		}
+		fmt.Fprintf(out, "Recurring issue: %s (%s)\n", item.Name, item.ID)
+		fmt.Fprintf(out, "Status: %s\n", recurringIssueState(item))
+		fmt.Fprintf(out, "Schedule: %s (%s)\n", recurringIssueScheduleLabel(item), item.Timezone)
+		fmt.Fprintf(out, "Title: %s\n", item.TitleTemplate)
+		if item.OwnerAgentID != nil {
+			fmt.Fprintf(out, "Owner: %s\n", *item.OwnerAgentID)
+		}
+		if item.SkipIfOpen {
+			fmt.Fprintln(out, "Skips while the previous issue is open")
+		}
+		fmt.Fprintf(out, "Next run: %s\n", recurringIssueText(item.NextRunAt, "-"))
+		if item.LastRunStatus != nil {
+			fmt.Fprintf(out, "Last run: %s at %s", *item.LastRunStatus, recurringIssueText(item.LastRunAt, "-"))
+			if item.LastRunError != nil {
+				fmt.Fprintf(out, " (%s)", *item.LastRunError)
+			}
+			fmt.Fprintln(out)
+		}
+		fmt.Fprintf(out, "Issues opened: %d\n", item.RunCount)
+		return nil
+	case "update":
+		if len(input) == 0 {
+			return errors.New("at least one change is required")
+		}
+		updated, err := client.UpdateRecurringIssue(project.ID, recurringID, input)
+		if err != nil {
+			return err
+		}
+		if *jsonOut {
+			printJSONTo(out, updated)
+			return nil
+		}
+		fmt.Fprintf(out, "Updated recurring issue %q; next run %s\n", updated.Name, recurringIssueText(updated.NextRunAt, "-"))
+		return nil
+	case "delete":
+		if err := client.DeleteRecurringIssue(project.ID, recurringID); err != nil {
+			return err
+		}
+		fmt.Fprintf(out, "Deleted recurring issue %q\n", ref)
+		return nil
+	case "run":
+		result, err := client.RunRecurringIssue(project.ID, recurringID)
+		if err != nil {
+			return err
+		}
+		if *jsonOut {
+			printJSONTo(out, result)
+			return nil
+		}
+		if result.Outcome.Issue != nil {
+			fmt.Fprintf(out, "Opened #%d %s\n", result.Outcome.Issue.IssueNumber, result.Outcome.Issue.Title)
+			return nil
+		}
+		fmt.Fprintf(out, "Skipped: %s\n", result.Outcome.Reason)
+		return nil
+	}
+	return errors.New(issueRecurringUsage)
+}
+
+// resolveRecurringIssueRef maps a definition name to its id; ids pass through.
+func resolveRecurringIssueRef(client issueRecurringCommandClient, projectID, ref string) (string, error) {
+	if issueUUIDPattern.MatchString(ref) {
+		return ref, nil
+	}
+	items, err := client.ListRecurringIssues(projectID)
+	if err != nil {
+		return "", err
+	}
+	for _, item := range items {
+		if strings.EqualFold(item.Name, ref) {
+			return item.ID, nil
+		}
+	}
+	return "", fmt.Errorf("recurring issue %q not found", ref)
+}
+
+func recurringIssueClearableFlag(value string) string {
+	value = strings.TrimSpace(value)
+	if strings.EqualFold(value, "none") {
+		return ""
+	}
+	return value
+}
+
+func recurringIssueState(item ottercli.RecurringIssue) string {
+	if item.Enabled {
+		return "enabled"
+	}
+	return "disabled"
+}
+
+func recurringIssueScheduleLabel(item ottercli.RecurringIssue) string {
+	switch item.ScheduleKind {
+	case "cron":
+		return "cron " + recurringIssueText(item.CronExpr, "")
+	case "interval":
+		if item.IntervalMS != nil {
+			return fmt.Sprintf("every %dms", *item.IntervalMS)
+		}
+	case "once":
+		return "once " + recurringIssueText(item.RunAt, "")
+	}
+	return item.ScheduleKind
+}
+
+func recurringIssueText(value *string, fallback string) string {
+	if value == nil || strings.TrimSpace(*value) == "" {
+		return fallback
+	}
+	return *value
+}
+
+func newIssueRecurringCommandClient(orgOverride string) (issueRecurringCommandClient, error) {
+	cfg, err := ottercli.LoadConfig()
+	if err != nil {
+		return nil, err
+	}
+	return ottercli.NewClient(cfg, orgOverride)
+}
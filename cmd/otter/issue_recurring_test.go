@@ -0,0 +1,171 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/samhotchkiss/otter-camp/internal/ottercli"
+)
+
+type fakeIssueRecurringCommandClient struct {
+	items   []ottercli.RecurringIssue
+	created map[string]any
+	updated map[string]any
+	deleted string
+	ran     string
+}
+
+func (f *fakeIssueRecurringCommandClient) FindProject(query string) (ottercli.Project, error) {
+	return ottercli.Project{ID: "p-1", Name: query}, nil
+}
+
+func (f *fakeIssueRecurringCommandClient) ResolveAgent(query string) (ottercli.Agent, error) {
+	return ottercli.Agent{ID: "agent-" + query}, nil
+}
+
+func (f *fakeIssueRecurringCommandClient) ListRecurringIssues(string) ([]ottercli.RecurringIssue, error) {
+	return f.items, nil
+}
+
+func (f *fakeIssueRecurringCommandClient) GetRecurringIssue(_, recurringID string) (ottercli.RecurringIssue, error) {
+	for _, item := range f.items {
+		if item.ID == recurringID {
+			return item, nil
+		}
+	}
+	return ottercli.RecurringIssue{}, os.ErrNotExist
+}
+
+func (f *fakeIssueRecurringCommandClient) CreateRecurringIssue(_ string, input map[string]any) (ottercli.RecurringIssue, error) {
+	f.created = input
+	next := "2026-10-26T13:00:00Z"
+	return ottercli.RecurringIssue{ID: "r-new", Name: input["name"].(string), NextRunAt: &next}, nil
+}
+
+func (f *fakeIssueRecurringCommandClient) UpdateRecurringIssue(_, recurringID string, input map[string]any) (ottercli.RecurringIssue, error) {
+	f.updated = input
+	return ottercli.RecurringIssue{ID: recurringID, Name: "Weekly metrics"}, nil
+}
+
+func (f *fakeIssueRecurringCommandClient) DeleteRecurringIssue(_, recurringID string) error {
+	f.deleted = recurringID
+	return nil
+}
+
+func (f *fakeIssueRecurringCommandClient) RunRecurringIssue(_, recurringID string) (ottercli.RecurringIssueRunResult, error) {
+	f.ran = recurringID
+	var result ottercli.RecurringIssueRunResult
+	result.Outcome.Status = "skipped"
+	result.Outcome.Reason = "previous issue #12 is still open"
+	return result, nil
+}
+
+func issueRecurringTestFactory(client *fakeIssueRecurringCommandClient) issueRecurringClientFactory {
+	return func(string) (issueRecurringCommandClient, error) { return client, nil }
+}
+
+func TestIssueRecurringCreate(t *testing.T) {
+	client := &fakeIssueRecurringCommandClient{}
+	var out bytes.Buffer
+	err := runIssueRecurringCommand([]string{
+		"create", "Weekly metrics",
+		"--project", "Metrics",
+		"--title", "Weekly metrics report — {{date}}",
+		"--schedule", "0 9 * * 1",
+		"--tz", "America/New_York",
+		"--owner", "analyst-1",
+		"--flow", "Report",
+		"--template", "Metrics",
+		"--field", "period={{iso_week}}",
+		"--skip-if-open",
+	}, issueRecurringTestFactory(client), &out)
+	if err != nil {
+		t.Fatalf("create error = %v", err)
+	}
+	input := client.created
+	if input["name"] != "Weekly metrics" || input["schedule_kind"] != "cron" || *input["cron_expr"].(*string) != "0 9 * * 1" {
+		t.Fatalf("created = %#v", input)
+	}
+	if input["timezone"] != "America/New_York" || input["owner_agent_id"] != "agent-analyst-1" || input["flow_template_id"] != "Report" || input["skip_if_open"] != true {
+		t.Fatalf("created = %#v", input)
+	}
+	if input["fields"].(map[string]string)["period"] != "{{iso_week}}" {
+		t.Fatalf("fields = %#v", input["fields"])
+	}
+	if !strings.Contains(out.String(), `Created recurring issue "Weekly metrics" (r-new); next run 2026-10-26T13:00:00Z`) {
+		t.Fatalf("create output = %q", out.String())
+	}
+
+	for _, args := range [][]string{
+		{"create", "Weekly", "--project", "Metrics", "--schedule", "0 9 * * 1"},
+		{"create", "Weekly", "--project", "Metrics", "--title", "Weekly"},
+		{"create", "--project", "Metrics", "--title", "Weekly", "--every", "24h"},
+	} {
+		if err := runIssueRecurringCommand(args, issueRecurringTestFactory(client), &out); err == nil {
+			t.Fatalf("%v: expected error", args)
+		}
+	}
+}
+
+func TestIssueRecurringManageByName(t *testing.T) {
+	cronExpr := "0 9 * * 1"
+	status := "skipped"
+	client := &fakeIssueRecurringCommandClient{items: []ottercli.RecurringIssue{{
+		ID:            "r-1",
+		Name:          "Weekly metrics",
+		TitleTemplate: "Weekly metrics report — {{date}}",
+		ScheduleKind:  "cron",
+		CronExpr:      &cronExpr,
+		Timezone:      "America/New_York",
+		Enabled:       true,
+		LastRunStatus: &status,
+		RunCount:      3,
+	}}}
+	var out bytes.Buffer
+	if err := runIssueRecurringCommand([]string{"--project", "Metrics"}, issueRecurringTestFactory(client), &out); err != nil {
+		t.Fatalf("list error = %v", err)
+	}
+	if !strings.Contains(out.String(), "Weekly metrics") || !strings.Contains(out.String(), "cron 0 9 * * 1") {
+		t.Fatalf("list output = %q", out.String())
+	}
+
+	out.Reset()
+	if err := runIssueRecurringCommand([]string{"show", "weekly metrics", "--project", "Metrics"}, issueRecurringTestFactory(client), &out); err != nil {
+		t.Fatalf("show error = %v", err)
+	}
+	for _, want := range []string{"Recurring issue: Weekly metrics (r-1)", "Schedule: cron 0 9 * * 1 (America/New_York)", "Last run: skipped", "Issues opened: 3"} {
+		if !strings.Contains(out.String(), want) {
+			t.Fatalf("show output = %q, missing %q", out.String(), want)
+		}
+	}
+
+	if err := runIssueRecurringCommand([]string{"update", "Weekly metrics", "--project", "Metrics", "--disable", "--owner", "none", "--every", "168h"}, issueRecurringTestFactory(client), &out); err != nil {
+		t.Fatalf("update error = %v", err)
+	}
+	if client.updated["enabled"] != false || client.updated["owner_agent_id"] != "" || client.updated["schedule_kind"] != "interval" {
+		t.Fatalf("updated = %#v", client.updated)
+	}
+	if err := runIssueRecurringCommand([]string{"update", "Weekly metrics", "--project", "Metrics"}, issueRecurringTestFactory(client), &out); err == nil {
+		t.Fatal("update without changes should fail")
+	}
+
+	out.Reset()
+	if err := runIssueRecurringCommand([]string{"run", "Weekly metrics", "--project", "Metrics"}, issueRecurringTestFactory(client), &out); err != nil {
+		t.Fatalf("run error = %v", err)
+	}
+	if client.ran != "r-1" || !strings.Contains(out.String(), "Skipped: previous issue #12 is still open") {
+		t.Fatalf("ran = %q output = %q", client.ran, out.String())
+	}
+
+	if err := runIssueRecurringCommand([]string{"delete", "Weekly metrics", "--project", "Metrics"}, issueRecurringTestFactory(client), &out); err != nil {
+		t.Fatalf("delete error = %v", err)
+	}
+	if client.deleted != "r-1" {
+		t.Fatalf("deleted = %q", client.deleted)
+	}
+	if err := runIssueRecurringCommand([]string{"run", "Monthly", "--project", "Metrics"}, issueRecurringTestFactory(client), &out); err == nil || !strings.Contains(err.Error(), "not found") {
+		t.Fatalf("unknown definition error = %v", err)
+	}
+}
@@ -0,0 +1,161 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/samhotchkiss/otter-camp/internal/ottercli"
+)
+
+type issueReviewThreadsCommandClient interface {
+	issueLookupClient
+	ResolveAgent(query string) (ottercli.Agent, error)
+	ListIssueReviewThreads(issueID, status string) (ottercli.IssueReviewThreadList, error)
+	ReplyIssueReviewThread(issueID, threadID, authorAgentID, body string) (ottercli.IssueReviewThread, error)
+	SetIssueReviewThreadResolved(issueID, threadID, agentID string, resolved bool, note string) (ottercli.IssueReviewThread, error)
+}
+
+type issueReviewThreadsClientFactory func(orgOverride string) (issueReviewThreadsCommandClient, error)
+
+func handleIssueReviewThreads(args []string) {
+	if err := runIssueReviewThreadsCommand(args, newIssueReviewThreadsCommandClient, os.Stdout); err != nil {
+		die(err.Error())
+	}
+}
+
+// runIssueReviewThreadsCommand handles `otter issue threads`, which lists an
+// issue's line-anchored review threads and lets an agent reply to, resolve or
+// reopen them one at a time.
+func runIssueReviewThreadsCommand(args []string, factory issueReviewThreadsClientFactory, out io.Writer) error {
+	const usage = "usage: otter issue threads <list|reply|resolve|reopen> <issue> [thread-id] [--status open|resolved] [--body <text>] [--note <text>] [--agent <agent>] [--project <project>] [--json]"
+	if len(args) == 0 {
+		return errors.New(usage)
+	}
+	action, args := args[0], args[1:]
+	wantArgs := 2
+	switch action {
+	case "list":
+		wantArgs = 1
+	case "reply", "resolve", "reopen":
+	default:
+		return errors.New(usage)
+	}
+
+	flags := flag.NewFlagSet("issue threads", flag.ContinueOnError)
+	flags.SetOutput(io.Discard)
+	org := flags.String("org", "", "org id override")
+	jsonOut := flags.Bool("json", false, "JSON output")
+	projectRef := flags.String("project", "", "project name or id (required for issue numbers)")
+	status := flags.String("status", "", "list only open or resolved threads")
+	body := flags.String("body", "", "reply text")
+	note := flags.String("note", "", "resolution note")
+	agentRef := flags.String("agent", "", "acting agent id/name/slug (defaults to OTTER_AGENT_ID)")
+	positional, err := parseInterspersedFlags(flags, args)
+	if err != nil {
+		return err
+	}
+	if len(positional) != wantArgs || (action == "reply" && strings.TrimSpace(*body) == "") {
+		return errors.New(usage)
+	}
+
+	client, err := factory(strings.TrimSpace(*org))
+	if err != nil {
+		return err
+	}
+	issueID, err := resolveIssueID(client, strings.TrimSpace(*projectRef), positional[0])
+	if err != nil {
+		return err
+	}
+
+	if action == "list" {
+		list, err := client.ListIssueReviewThreads(issueID, *status)
+		if err != nil {
+			return err
+		}
+		if *jsonOut {
+			printJSONTo(out, list)
+			return nil
+		}
+		printIssueReviewThreads(out, list)
+		return nil
+	}
+
+	agent := strings.TrimSpace(*agentRef)
+	if agent == "" {
+		agent = strings.TrimSpace(os.Getenv("OTTER_AGENT_ID"))
+	}
+	if agent == "" {
+		return errors.New("--agent is required (or set OTTER_AGENT_ID)")
+	}
+	resolvedAgent, err := client.ResolveAgent(agent)
+	if err != nil {
+		return err
+	}
+	threadID := strings.TrimSpace(positional[1])
+
+	var thread ottercli.IssueReviewThread
+	switch action {
+	case "reply":
+		thread, err = client.ReplyIssueReviewThread(issueID, threadID, resolvedAgent.ID, *body)
+	case "resolve":
+		thread, err = client.SetIssueReviewThreadResolved(issueID, threadID, resolvedAgent.ID, true, *note)
+	case "reopen":
+		thread, err = client.SetIssueReviewThreadResolved(issueID, threadID, resolvedAgent.ID, false, "")
+	}
+	if err != nil {
+		return err
+	}
+	if *jsonOut {
+		printJSONTo(out, thread)
+		return nil
+	}
+	switch action {
+	case "reply":
+		fmt.Fprintf(out, "Replied to thread %s (%d comment(s))\n", thread.ID, len(thread.Comments))
+	case "resolve":
+		fmt.Fprintf(out, "Resolved thread %s\n", thread.ID)
+	case "reopen":
+		fmt.Fprintf(out, "Reopened thread %s\n", thread.ID)
+	}
+	return nil
+}
+
+func printIssueReviewThreads(out io.Writer, list ottercli.IssueReviewThreadList) {
+	if len(list.Items) == 0 {
+		fmt.Fprintln(out, "No review threads.")
+		return
+	}
+	fmt.Fprintf(out, "%d thread(s), %d open\n", list.Total, list.Open)
+	for _, thread := range list.Items {
+		lines := fmt.Sprintf("L%d", thread.AnchoredStartLine)
+		if thread.AnchoredEndLine != thread.AnchoredStartLine {
+			lines = fmt.Sprintf("L%d-%d", thread.AnchoredStartLine, thread.AnchoredEndLine)
+		}
+		if thread.Outdated {
+			lines += " (outdated)"
+		}
+		fmt.Fprintf(out, "\n%s  %s  %s\n", thread.ID, thread.Status, lines)
+		for _, comment := range thread.Comments {
+			author := "unknown"
+			if comment.AuthorAgentID != nil {
+				author = *comment.AuthorAgentID
+			}
+			fmt.Fprintf(out, "  %s: %s\n", author, comment.Body)
+		}
+		if thread.ResolutionNote != nil {
+			fmt.Fprintf(out, "  resolved: %s\n", *thread.ResolutionNote)
+		}
+	}
+}
+
+func newIssueReviewThreadsCommandClient(orgOverride string) (issueReviewThreadsCommandClient, error) {
+	cfg, err := ottercli.LoadConfig()
+	if err != nil {
+		return nil, err
+	}
+	return ottercli.NewClient(cfg, orgOverride)
+}
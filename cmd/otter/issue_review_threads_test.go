@@ -0,0 +1,88 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/samhotchkiss/otter-camp/internal/ottercli"
+)
+
+type fakeIssueReviewThreadsCommandClient struct {
+	resolved map[string]bool
+	replies  []string
+}
+
+func (f *fakeIssueReviewThreadsCommandClient) FindProject(query string) (ottercli.Project, error) {
+	return ottercli.Project{ID: "p-1", Name: query}, nil
+}
+
+func (f *fakeIssueReviewThreadsCommandClient) ListIssues(string, map[string]string) ([]ottercli.Issue, error) {
+	return []ottercli.Issue{{ID: "issue-7", IssueNumber: 7}}, nil
+}
+
+func (f *fakeIssueReviewThreadsCommandClient) ResolveAgent(query string) (ottercli.Agent, error) {
+	return ottercli.Agent{ID: "agent-" + query}, nil
+}
+
+func (f *fakeIssueReviewThreadsCommandClient) ListIssueReviewThreads(issueID, status string) (ottercli.IssueReviewThreadList, error) {
+	note := "cited"
+	return ottercli.IssueReviewThreadList{
+		Total: 2,
+		Open:  1,
+		Items: []ottercli.IssueReviewThread{
+			{ID: "t-1", Status: "open", AnchoredStartLine: 5, AnchoredEndLine: 6, Comments: []ottercli.IssueReviewThreadComment{{Body: "Needs a source"}}},
+			{ID: "t-2", Status: "resolved", AnchoredStartLine: 2, AnchoredEndLine: 2, Outdated: true, ResolutionNote: &note},
+		},
+	}, nil
+}
+
+func (f *fakeIssueReviewThreadsCommandClient) ReplyIssueReviewThread(issueID, threadID, authorAgentID, body string) (ottercli.IssueReviewThread, error) {
+	f.replies = append(f.replies, issueID+"/"+threadID+"/"+authorAgentID+"/"+body)
+	return ottercli.IssueReviewThread{ID: threadID, Comments: make([]ottercli.IssueReviewThreadComment, 2)}, nil
+}
+
+func (f *fakeIssueReviewThreadsCommandClient) SetIssueReviewThreadResolved(issueID, threadID, agentID string, resolved bool, note string) (ottercli.IssueReviewThread, error) {
+	if f.resolved == nil {
+		f.resolved = map[string]bool{}
+	}
+	f.resolved[threadID] = resolved
+	return ottercli.IssueReviewThread{ID: threadID}, nil
+}
+
+func TestRunIssueReviewThreadsCommand(t *testing.T) {
+	fake := &fakeIssueReviewThreadsCommandClient{}
+	factory := func(string) (issueReviewThreadsCommandClient, error) { return fake, nil }
+
+	var out bytes.Buffer
+	if err := runIssueReviewThreadsCommand([]string{"list", "7", "--project", "web"}, factory, &out); err != nil {
+		t.Fatalf("list error = %v", err)
+	}
+	if !strings.Contains(out.String(), "t-1  open  L5-6") || !strings.Contains(out.String(), "L2 (outdated)") || !strings.Contains(out.String(), "resolved: cited") {
+		t.Fatalf("list output = %q", out.String())
+	}
+
+	t.Setenv("OTTER_AGENT_ID", "derek")
+	out.Reset()
+	if err := runIssueReviewThreadsCommand([]string{"reply", "7", "t-1", "--project", "web", "--body", "Added a citation"}, factory, &out); err != nil {
+		t.Fatalf("reply error = %v", err)
+	}
+	if len(fake.replies) != 1 || fake.replies[0] != "issue-7/t-1/agent-derek/Added a citation" {
+		t.Fatalf("replies = %#v", fake.replies)
+	}
+	if err := runIssueReviewThreadsCommand([]string{"resolve", "7", "t-1", "--project", "web", "--note", "fixed"}, factory, &out); err != nil {
+		t.Fatalf("resolve error = %v", err)
+	}
+	if err := runIssueReviewThreadsCommand([]string{"reopen", "7", "t-2", "--project", "web"}, factory, &out); err != nil {
+		t.Fatalf("reopen error = %v", err)
+	}
+	if !fake.resolved["t-1"] || fake.resolved["t-2"] {
+		t.Fatalf("resolved = %#v", fake.resolved)
+	}
+
+	for _, args := range [][]string{nil, {"close", "7"}, {"reply", "7", "t-1"}, {"resolve", "7"}} {
+		if err := runIssueReviewThreadsCommand(args, factory, &out); err == nil || !strings.Contains(err.Error(), "usage") {
+			t.Fatalf("args %v: expected usage error, got %v", args, err)
+		}
+	}
+}
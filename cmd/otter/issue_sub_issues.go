@@ -0,0 +1,192 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/samhotchkiss/otter-camp/internal/ottercli"
+)
+
+type subIssuesCommandClient interface {
+	issueLookupClient
+	ResolveAgent(query string) (ottercli.Agent, error)
+	GetIssueTree(issueID string) (ottercli.IssueTreeNode, error)
+	AttachSubIssue(parentID, childID string) (ottercli.Issue, error)
+	DetachSubIssue(parentID, childID string) (ottercli.Issue, error)
+	DecomposeIssue(issueID string, agentID *string, children []ottercli.SubIssueInput) ([]ottercli.Issue, error)
+}
+
+type subIssuesClientFactory func(orgOverride string) (subIssuesCommandClient, error)
+
+func handleIssueSubIssues(command string, args []string) {
+	if err := runIssueSubIssuesCommand(command, args, newSubIssuesCommandClient, os.Stdout); err != nil {
+		die(err.Error())
+	}
+}
+
+// runIssueSubIssuesCommand handles `otter issue tree`, `otter issue children`
+// and `otter issue decompose`.
+func runIssueSubIssuesCommand(command string, args []string, factory subIssuesClientFactory, out io.Writer) error {
+	action := ""
+	var usage string
+	switch command {
+	case "tree":
+		usage = "usage: otter issue tree <issue-id-or-number> [--project <project>] [--json]"
+	case "children":
+		usage = "usage: otter issue children <add|remove> <parent> <child> [--project <project>] [--json]"
+		if len(args) == 0 || (args[0] != "add" && args[0] != "remove") {
+			return errors.New(usage)
+		}
+		action, args = args[0], args[1:]
+	case "decompose":
+		usage = "usage: otter issue decompose <issue-id-or-number> (--child <title>... | --file <children.json>) [--agent <planner>] [--project <project>] [--json]"
+	default:
+		return errors.New("usage: otter issue <tree|children|decompose> ...")
+	}
+
+	flags := flag.NewFlagSet("issue "+command, flag.ContinueOnError)
+	flags.SetOutput(io.Discard)
+	org := flags.String("org", "", "org id override")
+	jsonOut := flags.Bool("json", false, "JSON output")
+	projectRef := flags.String("project", "", "project name or id (required for issue numbers)")
+	agentRef := flags.String("agent", "", "planner agent id/name/slug decomposing on its own behalf")
+	file := flags.String("file", "", "JSON array of sub-issues (title, body, owner_agent_id, priority, work_status, due_at)")
+	var childTitles cliRepeatedFlag
+	flags.Var(&childTitles, "child", "sub-issue title (repeatable)")
+	positional, err := parseInterspersedFlags(flags, args)
+	if err != nil {
+		return err
+	}
+	wantArgs := 1
+	if command == "children" {
+		wantArgs = 2
+	}
+	if len(positional) != wantArgs {
+		return errors.New(usage)
+	}
+
+	var children []ottercli.SubIssueInput
+	if command == "decompose" {
+		if strings.TrimSpace(*file) != "" && len(childTitles) > 0 {
+			return errors.New("use either --child or --file, not both")
+		}
+		if strings.TrimSpace(*file) != "" {
+			raw, err := os.ReadFile(strings.TrimSpace(*file))
+			if err != nil {
+				return err
+			}
+			decoder := json.NewDecoder(bytes.NewReader(raw))
+			decoder.DisallowUnknownFields()
+			if err := decoder.Decode(&children); err != nil {
+				return fmt.Errorf("invalid sub-issue file: %w", err)
+			}
+		}
+		for _, title := range childTitles {
+			children = append(children, ottercli.SubIssueInput{Title: strings.TrimSpace(title)})
+		}
+		if len(children) == 0 {
+			return errors.New(usage)
+		}
+	}
+
+	client, err := factory(strings.TrimSpace(*org))
+	if err != nil {
+		return err
+	}
+	issueIDs := make([]string, 0, len(positional))
+	for _, ref := range positional {
+		issueID, err := resolveIssueID(client, strings.TrimSpace(*projectRef), ref)
+		if err != nil {
+			return err
+		}
+		issueIDs = append(issueIDs, issueID)
+	}
+
+	switch command {
+	case "tree":
+		tree, err := client.GetIssueTree(issueIDs[0])
+		if err != nil {
+			return err
+		}
+		if *jsonOut {
+			printJSONTo(out, tree)
+			return nil
+		}
+		printIssueTree(out, tree, 0)
+		return nil
+	case "children":
+		var child ottercli.Issue
+		if action == "add" {
+			child, err = client.AttachSubIssue(issueIDs[0], issueIDs[1])
+		} else {
+			child, err = client.DetachSubIssue(issueIDs[0], issueIDs[1])
+		}
+		if err != nil {
+			return err
+		}
+		if *jsonOut {
+			printJSONTo(out, child)
+			return nil
+		}
+		if action == "add" {
+			fmt.Fprintf(out, "Issue #%d is now a sub-issue of %s\n", child.IssueNumber, positional[0])
+		} else {
+			fmt.Fprintf(out, "Issue #%d is no longer a sub-issue of %s\n", child.IssueNumber, positional[0])
+		}
+		return nil
+	case "decompose":
+		var agentID *string
+		if strings.TrimSpace(*agentRef) != "" {
+			agent, err := client.ResolveAgent(strings.TrimSpace(*agentRef))
+			if err != nil {
+				return err
+			}
+			agentID = &agent.ID
+		}
+		created, err := client.DecomposeIssue(issueIDs[0], agentID, children)
+		if err != nil {
+			return err
+		}
+		if *jsonOut {
+			printJSONTo(out, map[string]any{"items": created, "total": len(created)})
+			return nil
+		}
+		fmt.Fprintf(out, "Created %d sub-issue(s) under %s\n", len(created), positional[0])
+		for _, issue := range created {
+			fmt.Fprintf(out, "  #%d %s\n", issue.IssueNumber, issue.Title)
+		}
+		return nil
+	}
+	return errors.New(usage)
+}
+
+func printIssueTree(out io.Writer, node ottercli.IssueTreeNode, depth int) {
+	line := fmt.Sprintf("%s#%d %s [%s]", strings.Repeat("  ", depth), node.Issue.IssueNumber, node.Issue.Title, node.Issue.State)
+	if node.Rollup.Total > 0 {
+		line += fmt.Sprintf(" %d%% of %d done", node.Rollup.PercentComplete, node.Rollup.Total)
+		if node.Rollup.Blocked > 0 {
+			line += fmt.Sprintf(", %d blocked", node.Rollup.Blocked)
+		}
+		if node.Rollup.EarliestDueAt != nil {
+			line += ", next due " + *node.Rollup.EarliestDueAt
+		}
+	}
+	fmt.Fprintln(out, line)
+	for _, child := range node.Children {
+		printIssueTree(out, child, depth+1)
+	}
+}
+
+func newSubIssuesCommandClient(orgOverride string) (subIssuesCommandClient, error) {
+	cfg, err := ottercli.LoadConfig()
+	if err != nil {
+		return nil, err
+	}
+	return ottercli.NewClient(cfg, orgOverride)
+}
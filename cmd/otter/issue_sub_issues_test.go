@@ -0,0 +1,142 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/samhotchkiss/otter-camp/internal/ottercli"
+)
+
+type fakeSubIssuesCommandClient struct {
+	tree       ottercli.IssueTreeNode
+	attached   [2]string
+	detached   [2]string
+	decomposed []ottercli.SubIssueInput
+	agentID    *string
+}
+
+func (f *fakeSubIssuesCommandClient) FindProject(query string) (ottercli.Project, error) {
+	return ottercli.Project{ID: "p-1", Name: query}, nil
+}
+
+func (f *fakeSubIssuesCommandClient) ListIssues(string, map[string]string) ([]ottercli.Issue, error) {
+	return []ottercli.Issue{{ID: "issue-7", IssueNumber: 7}}, nil
+}
+
+func (f *fakeSubIssuesCommandClient) ResolveAgent(query string) (ottercli.Agent, error) {
+	return ottercli.Agent{ID: "agent-" + query, Name: query}, nil
+}
+
+func (f *fakeSubIssuesCommandClient) GetIssueTree(string) (ottercli.IssueTreeNode, error) {
+	return f.tree, nil
+}
+
+func (f *fakeSubIssuesCommandClient) AttachSubIssue(parentID, childID string) (ottercli.Issue, error) {
+	f.attached = [2]string{parentID, childID}
+	return ottercli.Issue{ID: childID, IssueNumber: 2}, nil
+}
+
+func (f *fakeSubIssuesCommandClient) DetachSubIssue(parentID, childID string) (ottercli.Issue, error) {
+	f.detached = [2]string{parentID, childID}
+	return ottercli.Issue{ID: childID, IssueNumber: 2}, nil
+}
+
+func (f *fakeSubIssuesCommandClient) DecomposeIssue(_ string, agentID *string, children []ottercli.SubIssueInput) ([]ottercli.Issue, error) {
+	f.agentID = agentID
+	f.decomposed = children
+	created := make([]ottercli.Issue, 0, len(children))
+	for i, child := range children {
+		created = append(created, ottercli.Issue{IssueNumber: int64(10 + i), Title: child.Title})
+	}
+	return created, nil
+}
+
+func subIssuesTestFactory(client *fakeSubIssuesCommandClient) subIssuesClientFactory {
+	return func(string) (subIssuesCommandClient, error) { return client, nil }
+}
+
+func TestIssueTreePrintsRollups(t *testing.T) {
+	due := "2026-11-01T00:00:00Z"
+	client := &fakeSubIssuesCommandClient{tree: ottercli.IssueTreeNode{
+		Issue:  ottercli.Issue{IssueNumber: 7, Title: "Launch", State: "open"},
+		Rollup: ottercli.IssueTreeRollup{Total: 2, Closed: 1, PercentComplete: 50, Blocked: 1, EarliestDueAt: &due},
+		Children: []ottercli.IssueTreeNode{
+			{Issue: ottercli.Issue{IssueNumber: 8, Title: "Copy", State: "closed"}},
+			{Issue: ottercli.Issue{IssueNumber: 9, Title: "Assets", State: "open"}},
+		},
+	}}
+	var out bytes.Buffer
+	if err := runIssueSubIssuesCommand("tree", []string{"7", "--project", "Site"}, subIssuesTestFactory(client), &out); err != nil {
+		t.Fatalf("tree error = %v", err)
+	}
+	want := "#7 Launch [open] 50% of 2 done, 1 blocked, next due 2026-11-01T00:00:00Z\n  #8 Copy [closed]\n  #9 Assets [open]\n"
+	if out.String() != want {
+		t.Fatalf("tree output = %q, want %q", out.String(), want)
+	}
+}
+
+func TestIssueChildrenAddAndRemove(t *testing.T) {
+	client := &fakeSubIssuesCommandClient{}
+	parent := "11111111-1111-1111-1111-111111111111"
+	child := "22222222-2222-2222-2222-222222222222"
+	var out bytes.Buffer
+	if err := runIssueSubIssuesCommand("children", []string{"add", parent, child}, subIssuesTestFactory(client), &out); err != nil {
+		t.Fatalf("children add error = %v", err)
+	}
+	if client.attached != [2]string{parent, child} {
+		t.Fatalf("attached = %v", client.attached)
+	}
+	if err := runIssueSubIssuesCommand("children", []string{"remove", parent, child}, subIssuesTestFactory(client), &out); err != nil {
+		t.Fatalf("children remove error = %v", err)
+	}
+	if client.detached != [2]string{parent, child} {
+		t.Fatalf("detached = %v", client.detached)
+	}
+	if err := runIssueSubIssuesCommand("children", []string{"move", parent, child}, subIssuesTestFactory(client), &out); err == nil {
+		t.Fatal("expected usage error for unknown children action")
+	}
+	if err := runIssueSubIssuesCommand("children", []string{"add", parent}, subIssuesTestFactory(client), &out); err == nil {
+		t.Fatal("expected usage error for missing child")
+	}
+}
+
+func TestIssueDecompose(t *testing.T) {
+	client := &fakeSubIssuesCommandClient{}
+	parent := "11111111-1111-1111-1111-111111111111"
+	var out bytes.Buffer
+	err := runIssueSubIssuesCommand("decompose", []string{parent, "--child", "Copy", "--child", "Assets", "--agent", "planner"}, subIssuesTestFactory(client), &out)
+	if err != nil {
+		t.Fatalf("decompose error = %v", err)
+	}
+	if len(client.decomposed) != 2 || client.decomposed[1].Title != "Assets" {
+		t.Fatalf("decomposed = %#v", client.decomposed)
+	}
+	if client.agentID == nil || *client.agentID != "agent-planner" {
+		t.Fatalf("agentID = %v", client.agentID)
+	}
+	if !strings.Contains(out.String(), "Created 2 sub-issue(s)") || !strings.Contains(out.String(), "#11 Assets") {
+		t.Fatalf("decompose output = %q", out.String())
+	}
+
+	path := filepath.Join(t.TempDir(), "children.json")
+	if err := os.WriteFile(path, []byte(`[{"title":"Copy","priority":"P1"}]`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	client = &fakeSubIssuesCommandClient{}
+	if err := runIssueSubIssuesCommand("decompose", []string{parent, "--file", path}, subIssuesTestFactory(client), &out); err != nil {
+		t.Fatalf("decompose --file error = %v", err)
+	}
+	if client.decomposed[0].Priority != "P1" || client.agentID != nil {
+		t.Fatalf("decomposed = %#v agent = %v", client.decomposed, client.agentID)
+	}
+
+	if err := runIssueSubIssuesCommand("decompose", []string{parent}, subIssuesTestFactory(client), &out); err == nil {
+		t.Fatal("expected usage error without children")
+	}
+	if err := runIssueSubIssuesCommand("decompose", []string{parent, "--file", path, "--child", "x"}, subIssuesTestFactory(client), &out); err == nil {
+		t.Fatal("expected error for --file with --child")
+	}
+}
@@ -0,0 +1,205 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/samhotchkiss/otter-camp/internal/ottercli"
+)
+
+type issueTemplatesCommandClient interface {
+	FindProject(query string) (ottercli.Project, error)
+	ListIssueTemplates(projectID string) ([]ottercli.IssueTemplate, error)
+	GetIssueTemplate(projectID, ref string) (ottercli.IssueTemplate, error)
+	SaveIssueTemplate(projectID string, template ottercli.IssueTemplate) (ottercli.IssueTemplate, error)
+	DeleteIssueTemplate(projectID, ref string) error
+}
+
+type issueTemplatesClientFactory func(orgOverride string) (issueTemplatesCommandClient, error)
+
+const issueTemplatesUsage = "usage: otter issue templates <list|show|save|delete> --project <project> ..."
+
+func handleIssueTemplates(args []string) {
+	if err := runIssueTemplatesCommand(args, newIssueTemplatesCommandClient, os.Stdout); err != nil {
+		die(err.Error())
+	}
+}
+
+func runIssueTemplatesCommand(args []string, factory issueTemplatesClientFactory, out io.Writer) error {
+	command := "list"
+	if len(args) > 0 && !strings.HasPrefix(args[0], "-") {
+		command, args = args[0], args[1:]
+	}
+	var usage string
+	switch command {
+	case "list":
+		usage = "usage: otter issue templates list --project <project> [--json]"
+	case "show":
+		usage = "usage: otter issue templates show <name-or-id> --project <project> [--json]"
+	case "save":
+		usage = "usage: otter issue templates save [<name>] --file <template.json> --project <project> [--json]"
+	case "delete":
+		usage = "usage: otter issue templates delete <name-or-id> --project <project>"
+	default:
+		return errors.New(issueTemplatesUsage)
+	}
+
+	flags := flag.NewFlagSet("issue templates "+command, flag.ContinueOnError)
+	flags.SetOutput(io.Discard)
+	org := flags.String("org", "", "org id override")
+	jsonOut := flags.Bool("json", false, "JSON output")
+	projectRef := flags.String("project", "", "project name or id (required)")
+	file := flags.String("file", "", "template JSON file (name, description, body, fields, default_flow_template_id, default_labels)")
+	positional, err := parseInterspersedFlags(flags, args)
+	if err != nil {
+		return err
+	}
+	name := ""
+	switch {
+	case len(positional) > 1:
+		return errors.New(usage)
+	case len(positional) == 1:
+		name = strings.TrimSpace(positional[0])
+	}
+	if command == "list" && name != "" {
+		return errors.New(usage)
+	}
+	if (command == "show" || command == "delete") && name == "" {
+		return errors.New(usage)
+	}
+	if strings.TrimSpace(*projectRef) == "" {
+		return errors.New("--project is required")
+	}
+
+	var template ottercli.IssueTemplate
+	if command == "save" {
+		if strings.TrimSpace(*file) == "" {
+			return errors.New("--file is required")
+		}
+		raw, err := os.ReadFile(strings.TrimSpace(*file))
+		if err != nil {
+			return err
+		}
+		decoder := json.NewDecoder(bytes.NewReader(raw))
+		decoder.DisallowUnknownFields()
+		if err := decoder.Decode(&template); err != nil {
+			return fmt.Errorf("invalid template file: %w", err)
+		}
+		if name != "" {
+			template.Name = name
+		}
+		if strings.TrimSpace(template.Name) == "" {
+			return errors.New("template name is required (in the file or as an argument)")
+		}
+	}
+
+	client, err := factory(strings.TrimSpace(*org))
+	if err != nil {
+		return err
+	}
+	project, err := client.FindProject(*projectRef)
+	if err != nil {
+		return err
+	}
+
+	switch command {
+	case "list":
+		templates, err := client.ListIssueTemplates(project.ID)
+		if err != nil {
+			return err
+		}
+		if *jsonOut {
+			printJSONTo(out, map[string]any{"items": templates, "total": len(templates)})
+			return nil
+		}
+		if len(templates) == 0 {
+			fmt.Fprintln(out, "No issue templates. Save one with `otter issue templates save --file <template.json>`.")
+			return nil
+		}
+		for _, template := range templates {
+			keys := make([]string, 0, len(template.Fields))
+			for _, field := range template.Fields {
+				keys = append(keys, field.Key)
+			}
+			fmt.Fprintf(out, "%-24s %d field(s) %s\n", template.Name, len(template.Fields), strings.Join(keys, ","))
+		}
+		return nil
+	case "show":
+		template, err := client.GetIssueTemplate(project.ID, name)
+		if err != nil {
+			return err
+		}
+		if *jsonOut {
+			printJSONTo(out, template)
+			return nil
+		}
+		fmt.Fprintf(out, "Template: %s (%s)\n", template.Name, template.ID)
+		if template.Description != nil {
+			fmt.Fprintf(out, "Description: %s\n", *template.Description)
+		}
+		if len(template.DefaultLabels) > 0 {
+			fmt.Fprintf(out, "Labels: %s\n", strings.Join(template.DefaultLabels, ", "))
+		}
+		for _, field := range template.Fields {
+			line := fmt.Sprintf("  %s (%s)", field.Key, field.Type)
+			if field.Required {
+				line += " required"
+			}
+			if len(field.Options) > 0 {
+				line += " [" + strings.Join(field.Options, "|") + "]"
+			}
+			if field.Default != "" {
+				line += " default=" + field.Default
+			}
+			fmt.Fprintln(out, line)
+		}
+		return nil
+	case "save":
+		saved, err := client.SaveIssueTemplate(project.ID, template)
+		if err != nil {
+			return err
+		}
+		if *jsonOut {
+			printJSONTo(out, saved)
+			return nil
+		}
+		fmt.Fprintf(out, "Saved issue template %q for %s\n", saved.Name, project.Name)
+		return nil
+	case "delete":
+		if err := client.DeleteIssueTemplate(project.ID, name); err != nil {
+			return err
+		}
+		fmt.Fprintf(out, "Deleted issue template %q\n", name)
+		return nil
+	}
+	return errors.New(issueTemplatesUsage)
+}
+
+// parseIssueFieldFlags turns repeated --field key=value flags into the
+// fields map sent with a templated issue.
+func parseIssueFieldFlags(values []string) (map[string]string, error) {
+	fields := make(map[string]string, len(values))
+	for _, value := range values {
+		key, fieldValue, ok := strings.Cut(value, "=")
+		key = strings.TrimSpace(key)
+		if !ok || key == "" {
+			return nil, fmt.Errorf("--field must be key=value, got %q", value)
+		}
+		fields[key] = strings.TrimSpace(fieldValue)
+	}
+	return fields, nil
+}
+
+func newIssueTemplatesCommandClient(orgOverride string) (issueTemplatesCommandClient, error) {
+	cfg, err := ottercli.LoadConfig()
+	if err != nil {
+		return nil, err
+	}
+	return ottercli.NewClient(cfg, orgOverride)
+}
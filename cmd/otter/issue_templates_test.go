@@ -0,0 +1,132 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/samhotchkiss/otter-camp/internal/ottercli"
+)
+
+type fakeIssueTemplatesCommandClient struct {
+	templates []ottercli.IssueTemplate
+	saved     *ottercli.IssueTemplate
+	deleted   string
+}
+
+func (f *fakeIssueTemplatesCommandClient) FindProject(query string) (ottercli.Project, error) {
+	return ottercli.Project{ID: "p-1", Name: query}, nil
+}
+
+func (f *fakeIssueTemplatesCommandClient) ListIssueTemplates(string) ([]ottercli.IssueTemplate, error) {
+	return f.templates, nil
+}
+
+func (f *fakeIssueTemplatesCommandClient) GetIssueTemplate(_, ref string) (ottercli.IssueTemplate, error) {
+	for _, template := range f.templates {
+		if strings.EqualFold(template.Name, ref) {
+			return template, nil
+		}
+	}
+	return ottercli.IssueTemplate{}, os.ErrNotExist
+}
+
+func (f *fakeIssueTemplatesCommandClient) SaveIssueTemplate(_ string, template ottercli.IssueTemplate) (ottercli.IssueTemplate, error) {
+	f.saved = &template
+	template.ID = "t-new"
+	return template, nil
+}
+
+func (f *fakeIssueTemplatesCommandClient) DeleteIssueTemplate(_, ref string) error {
+	f.deleted = ref
+	return nil
+}
+
+func issueTemplatesTestFactory(client *fakeIssueTemplatesCommandClient) issueTemplatesClientFactory {
+	return func(string) (issueTemplatesCommandClient, error) { return client, nil }
+}
+
+func TestIssueTemplatesListAndShow(t *testing.T) {
+	client := &fakeIssueTemplatesCommandClient{templates: []ottercli.IssueTemplate{{
+		ID:            "t-1",
+		Name:          "Bug",
+		DefaultLabels: []string{"bug"},
+		Fields: []ottercli.IssueTemplateField{
+			{Key: "severity", Type: "select", Required: true, Options: []string{"Low", "High"}},
+			{Key: "estimate", Type: "number", Default: "1"},
+		},
+	}}}
+	var out bytes.Buffer
+	if err := runIssueTemplatesCommand([]string{"--project", "Blog"}, issueTemplatesTestFactory(client), &out); err != nil {
+		t.Fatalf("list error = %v", err)
+	}
+	if !strings.Contains(out.String(), "2 field(s) severity,estimate") {
+		t.Fatalf("list output = %q", out.String())
+	}
+
+	out.Reset()
+	if err := runIssueTemplatesCommand([]string{"show", "bug", "--project", "Blog"}, issueTemplatesTestFactory(client), &out); err != nil {
+		t.Fatalf("show error = %v", err)
+	}
+	for _, want := range []string{"Template: Bug (t-1)", "Labels: bug", "severity (select) required [Low|High]", "estimate (number) default=1"} {
+		if !strings.Contains(out.String(), want) {
+			t.Fatalf("show output = %q, missing %q", out.String(), want)
+		}
+	}
+
+	if err := runIssueTemplatesCommand([]string{"show", "bug"}, issueTemplatesTestFactory(client), &out); err == nil || !strings.Contains(err.Error(), "--project") {
+		t.Fatalf("show without project error = %v", err)
+	}
+}
+
+func TestIssueTemplatesSaveAndDelete(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "bug.json")
+	if err := os.WriteFile(path, []byte(`{"name":"Bug","body":"## Steps","fields":[{"key":"severity","type":"select","options":["Low","High"]}],"default_labels":["bug"]}`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	client := &fakeIssueTemplatesCommandClient{}
+	var out bytes.Buffer
+	if err := runIssueTemplatesCommand([]string{"save", "Bug report", "--file", path, "--project", "Blog"}, issueTemplatesTestFactory(client), &out); err != nil {
+		t.Fatalf("save error = %v", err)
+	}
+	if client.saved == nil || client.saved.Name != "Bug report" || client.saved.Body != "## Steps" || len(client.saved.Fields) != 1 {
+		t.Fatalf("saved = %#v", client.saved)
+	}
+	if !strings.Contains(out.String(), `Saved issue template "Bug report" for Blog`) {
+		t.Fatalf("save output = %q", out.String())
+	}
+
+	bad := filepath.Join(t.TempDir(), "bad.json")
+	if err := os.WriteFile(bad, []byte(`{"name":"Bug","colour":"red"}`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := runIssueTemplatesCommand([]string{"save", "--file", bad, "--project", "Blog"}, issueTemplatesTestFactory(client), &out); err == nil || !strings.Contains(err.Error(), "invalid template file") {
+		t.Fatalf("save bad file error = %v", err)
+	}
+
+	out.Reset()
+	if err := runIssueTemplatesCommand([]string{"delete", "Bug report", "--project", "Blog"}, issueTemplatesTestFactory(client), &out); err != nil {
+		t.Fatalf("delete error = %v", err)
+	}
+	if client.deleted != "Bug report" {
+		t.Fatalf("deleted = %q", client.deleted)
+	}
+	if err := runIssueTemplatesCommand([]string{"rename"}, issueTemplatesTestFactory(client), &out); err == nil || !strings.Contains(err.Error(), "usage") {
+		t.Fatalf("unknown command error = %v", err)
+	}
+}
+
+func TestParseIssueFieldFlags(t *testing.T) {
+	fields, err := parseIssueFieldFlags([]string{"severity=High", "spec = https://example.com/a=b"})
+	if err != nil {
+		t.Fatalf("parseIssueFieldFlags() error = %v", err)
+	}
+	if fields["severity"] != "High" || fields["spec"] != "https://example.com/a=b" {
+		t.Fatalf("fields = %#v", fields)
+	}
+	if _, err := parseIssueFieldFlags([]string{"severity"}); err == nil {
+		t.Fatal("expected error for missing =")
+	}
+}
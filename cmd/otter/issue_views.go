@@ -0,0 +1,151 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/samhotchkiss/otter-camp/internal/ottercli"
+)
+
+type issueViewsCommandClient interface {
+	FindProject(query string) (ottercli.Project, error)
+	ListIssueViews(projectID string) ([]ottercli.IssueSavedView, error)
+	SaveIssueView(name, query, projectID string) (ottercli.IssueSavedView, error)
+	DeleteIssueView(viewID string) error
+}
+
+type issueViewsClientFactory func(orgOverride string) (issueViewsCommandClient, error)
+
+const issueViewsUsage = "usage: otter issue views <list|save|delete> ..."
+
+func handleIssueViews(args []string) {
+	if err := runIssueViewsCommand(args, newIssueViewsCommandClient, os.Stdout); err != nil {
+		die(err.Error())
+	}
+}
+
+func runIssueViewsCommand(args []string, factory issueViewsClientFactory, out io.Writer) error {
+	command := "list"
+	if len(args) > 0 && !strings.HasPrefix(args[0], "-") {
+		command, args = args[0], args[1:]
+	}
+	var usage string
+	switch command {
+	case "list":
+		usage = "usage: otter issue views list [--project <project>] [--json]"
+	case "save":
+		usage = "usage: otter issue views save <name> --query '<query>' [--project <project>] [--json]"
+	case "delete":
+		usage = "usage: otter issue views delete <name-or-id> [--project <project>]"
+	default:
+		return errors.New(issueViewsUsage)
+	}
+	name := ""
+	if command != "list" {
+		if len(args) == 0 || strings.HasPrefix(args[0], "-") {
+			return errors.New(usage)
+		}
+		name, args = strings.TrimSpace(args[0]), args[1:]
+	}
+
+	flags := flag.NewFlagSet("issue views "+command, flag.ContinueOnError)
+	flags.SetOutput(io.Discard)
+	org := flags.String("org", "", "org id override")
+	jsonOut := flags.Bool("json", false, "JSON output")
+	projectRef := flags.String("project", "", "project name or id; saved views are shared with it")
+	query := flags.String("query", "", "issue query to save")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if len(flags.Args()) != 0 {
+		return errors.New(usage)
+	}
+	if command == "save" && strings.TrimSpace(*query) == "" {
+		return errors.New("--query is required")
+	}
+
+	client, err := factory(strings.TrimSpace(*org))
+	if err != nil {
+		return err
+	}
+	projectID := ""
+	if strings.TrimSpace(*projectRef) != "" {
+		project, err := client.FindProject(*projectRef)
+		if err != nil {
+			return err
+		}
+		projectID = project.ID
+	}
+
+	switch command {
+	case "list":
+		views, err := client.ListIssueViews(projectID)
+		if err != nil {
+			return err
+		}
+		if *jsonOut {
+			printJSONTo(out, map[string]any{"items": views, "total": len(views)})
+			return nil
+		}
+		if len(views) == 0 {
+			fmt.Fprintln(out, "No saved views. Save one with `otter issue views save <name> --query '...'`.")
+			return nil
+		}
+		for _, view := range views {
+			scope := "personal"
+			if view.ProjectID != nil {
+				scope = "project"
+			}
+			fmt.Fprintf(out, "%-24s %-8s %s\n", view.Name, scope, view.Query)
+		}
+		return nil
+	case "save":
+		view, err := client.SaveIssueView(name, strings.TrimSpace(*query), projectID)
+		if err != nil {
+			return err
+		}
+		if *jsonOut {
+			printJSONTo(out, view)
+			return nil
+		}
+		scope := "personal view"
+		if view.ProjectID != nil {
+			scope = "project view"
+		}
+		fmt.Fprintf(out, "Saved %s %q: %s\n", scope, view.Name, view.Query)
+		return nil
+	case "delete":
+		views, err := client.ListIssueViews(projectID)
+		if err != nil {
+			return err
+		}
+		var match *ottercli.IssueSavedView
+		for i := range views {
+			if views[i].ID == name || strings.EqualFold(views[i].Name, name) {
+				match = &views[i]
+				break
+			}
+		}
+		if match == nil {
+			return fmt.Errorf("view %q not found", name)
+		}
+		if err := client.DeleteIssueView(match.ID); err != nil {
+			return err
+		}
+		fmt.Fprintf(out, "Deleted view %q\n", match.Name)
+		return nil
+	}
+	return errors.New(issueViewsUsage)
+}
+
+func newIssueViewsCommandClient(orgOverride string) (issueViewsCommandClient, error) {
+	cfg, err := ottercli.LoadConfig()
+	if err != nil {
+		return nil, err
+	}
+	return ottercli.NewClient(cfg, orgOverride)
+}
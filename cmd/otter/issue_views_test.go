@@ -0,0 +1,93 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/samhotchkiss/otter-camp/internal/ottercli"
+)
+
+type fakeIssueViewsCommandClient struct {
+	views     []ottercli.IssueSavedView
+	listedFor string
+	saved     []string
+	deleted   string
+}
+
+func (f *fakeIssueViewsCommandClient) FindProject(query string) (ottercli.Project, error) {
+	return ottercli.Project{ID: "p-1", Name: query}, nil
+}
+
+func (f *fakeIssueViewsCommandClient) ListIssueViews(projectID string) ([]ottercli.IssueSavedView, error) {
+	f.listedFor = projectID
+	return f.views, nil
+}
+
+func (f *fakeIssueViewsCommandClient) SaveIssueView(name, query, projectID string) (ottercli.IssueSavedView, error) {
+	f.saved = []string{name, query, projectID}
+	view := ottercli.IssueSavedView{ID: "v-new", Name: name, Query: query}
+	if projectID != "" {
+		view.ProjectID = &projectID
+	}
+	return view, nil
+}
+
+func (f *fakeIssueViewsCommandClient) DeleteIssueView(viewID string) error {
+	f.deleted = viewID
+	return nil
+}
+
+func issueViewsTestFactory(client *fakeIssueViewsCommandClient) issueViewsClientFactory {
+	return func(string) (issueViewsCommandClient, error) { return client, nil }
+}
+
+func TestIssueViewsListDefaultsToListCommand(t *testing.T) {
+	projectID := "p-1"
+	client := &fakeIssueViewsCommandClient{views: []ottercli.IssueSavedView{
+		{ID: "v-1", Name: "mine", Query: "owner:@writer-2"},
+		{ID: "v-2", Name: "launch", Query: "label:launch", ProjectID: &projectID},
+	}}
+	var out bytes.Buffer
+	if err := runIssueViewsCommand([]string{"--project", "Blog"}, issueViewsTestFactory(client), &out); err != nil {
+		t.Fatalf("runIssueViewsCommand() error = %v", err)
+	}
+	if client.listedFor != "p-1" {
+		t.Fatalf("listed for %q", client.listedFor)
+	}
+	if !strings.Contains(out.String(), "mine") || !strings.Contains(out.String(), "personal") || !strings.Contains(out.String(), "project  label:launch") {
+		t.Fatalf("output = %q", out.String())
+	}
+}
+
+func TestIssueViewsSaveAndDelete(t *testing.T) {
+	client := &fakeIssueViewsCommandClient{views: []ottercli.IssueSavedView{{ID: "v-1", Name: "Launch", Query: "label:launch"}}}
+	var out bytes.Buffer
+	if err := runIssueViewsCommand([]string{"save", "launch", "--query", "is:open label:launch sort:due", "--project", "Blog"}, issueViewsTestFactory(client), &out); err != nil {
+		t.Fatalf("save error = %v", err)
+	}
+	if strings.Join(client.saved, "|") != "launch|is:open label:launch sort:due|p-1" {
+		t.Fatalf("saved = %#v", client.saved)
+	}
+	if !strings.Contains(out.String(), `Saved project view "launch"`) {
+		t.Fatalf("output = %q", out.String())
+	}
+
+	out.Reset()
+	if err := runIssueViewsCommand([]string{"delete", "launch"}, issueViewsTestFactory(client), &out); err != nil {
+		t.Fatalf("delete error = %v", err)
+	}
+	if client.deleted != "v-1" {
+		t.Fatalf("deleted = %q", client.deleted)
+	}
+
+	if err := runIssueViewsCommand([]string{"delete", "missing"}, issueViewsTestFactory(client), &out); err == nil || !strings.Contains(err.Error(), "not found") {
+		t.Fatalf("delete missing error = %v", err)
+	}
+	if err := runIssueViewsCommand([]string{"save", "launch"}, issueViewsTestFactory(client), &out); err == nil || !strings.Contains(err.Error(), "--query") {
+		t.Fatalf("save without query error = %v", err)
+	}
+	if err := runIssueViewsCommand([]string{"rename"}, issueViewsTestFactory(client), &out); err == nil || !strings.Contains(err.Error(), "usage") {
+		t.Fatalf("unknown command error = %v", err)
+	}
+}
@@ -33,6 +33,15 @@ const (
 )
 
 func main() {
+	args, contextName, err := extractContextFlag(os.Args[1:])
+	if err != nil {
+		die(err.Error())
+	}
+	if contextName != "" {
+		_ = os.Setenv(ottercli.ContextEnvVar, contextName)
+	}
+	os.Args = append(os.Args[:1], args...)
+
 	if len(os.Args) < 2 {
 		usage()
 		os.Exit(1)
@@ -42,6 +51,8 @@ func main() {
 	switch cmd {
 	case "auth":
 		handleAuth(os.Args[2:])
+	case "context":
+		handleContext(os.Args[2:])
 	case "init":
 		handleInit(os.Args[2:])
 	case "start":
@@ -86,6 +97,14 @@ func main() {
 		handlePipeline(os.Args[2:])
 	case "deploy":
 		handleDeploy(os.Args[2:])
+	case "backup":
+		handleBackup(os.Args[2:])
+	case "roles":
+		handleRoles(os.Args[2:])
+	case "audit":
+		handleAudit(os.Args[2:])
+	case "usage":
+		handleUsage(os.Args[2:])
 	case "version":
 		fmt.Println("otter dev")
 	default:
@@ -98,7 +117,8 @@ func usage() {
 	fmt.Println(`otter <command> [args]
 
 Commands:
-  auth login       Store API token + default org
+  auth login       Store API token + default org in the current context
+  context          List, add, remove and switch CLI contexts
   init             Run onboarding setup wizard
   start            Start local Otter Camp services
   stop             Stop local Otter Camp services
@@ -109,7 +129,7 @@ Commands:
   whoami           Validate token and show user
   release-gate     Run Spec 110 release gate checks
   project          Manage projects
-  agent            Manage agents
+  agent            Manage agents and review their performance
   memory           Manage agent memory
   jobs             Manage scheduled jobs
   knowledge        Manage shared knowledge
@@ -121,8 +141,15 @@ Commands:
   room             Manage room stats
   pipeline         Configure per-project pipeline settings and step chains
   deploy           Configure per-project deployment settings
+  backup           Create, validate and restore workspace archives
+  roles            Manage custom roles and role bindings
+  audit            Query, export and verify the audit log
+  usage            Report token usage and cost, manage pricing and budgets
   migrate          Run migration utilities
-  version          Show CLI version`)
+  version          Show CLI version
+
+Global flags:
+  --context <name> Use a named context for this command (or set OTTER_CONTEXT)`)
 }
 
 func handleAuth(args []string) {
@@ -161,7 +188,7 @@ func handleAuth(args []string) {
 		if err := ottercli.SaveConfig(cfg); err != nil {
 			dieIf(err)
 		}
-		fmt.Println("Saved config to", mustConfigPath())
+		fmt.Printf("Saved context %q to %s\n", cfg.Context, mustConfigPath())
 	default:
 		fmt.Println("usage: otter auth login [--token <token>] [--org <org-id>] [--api <url>]")
 		os.Exit(1)
@@ -280,7 +307,7 @@ func handleMigrateFromOpenClawCron(args []string) {
 }
 
 func handleAgent(args []string) {
-	const usageText = "usage: otter agent <whoami|list|create|edit|archive> ..."
+	const usageText = "usage: otter agent <whoami|list|create|edit|archive|review> ..."
 	if len(args) == 0 {
 		fmt.Println(usageText)
 		os.Exit(1)
@@ -502,6 +529,8 @@ func handleAgent(args []string) {
 			return
 		}
 		fmt.Printf("Archived agent: %s\n", agentArg)
+	case "review":
+		handleAgentReview(args[1:])
 	default:
 		fmt.Println(usageText)
 		os.Exit(1)
@@ -898,7 +927,7 @@ func handleMemory(args []string) {
 }
 
 func handleJobs(args []string) {
-	const usageText = "usage: otter jobs <list|create|pause|resume|run|history|delete> ..."
+	const usageText = "usage: otter jobs <list|create|pause|resume|run|history|reply|delete|blackouts> ..."
 	if len(args) == 0 {
 		fmt.Println(usageText)
 		os.Exit(1)
@@ -993,6 +1022,14 @@ func handleJobs(args []string) {
 		payloadKind := flags.String("payload-kind", "message", "payload kind (message|system_event)")
 		roomID := flags.String("room", "", "optional room id")
 		maxFailures := flags.Int("max-failures", 5, "auto-pause after this many consecutive failures")
+		overlap := flags.String("overlap", "", "when a previous run is still running: skip|queue|replace (default skip)")
+		catchUp := flags.String("catch-up", "", "after missed runs: run-once|run-all|skip (default run-once)")
+		jitter := flags.String("jitter", "", "random delay added to each run, up to this duration (e.g. 5m)")
+		maxAgentConcurrency := flags.Int("max-agent-concurrency", 0, "defer starting while the agent has this many job runs in flight")
+		expectRegex := flags.String("expect-regex", "", "fail the run unless the agent reply matches this regular expression")
+		expectJSONField := flags.String("expect-json-field", "", "fail the run unless the agent reply has this JSON field (dotted path)")
+		expectClassifier := flags.String("expect-classifier", "", "yes/no question an LLM answers about the agent reply to decide success")
+		replyTimeout := flags.String("reply-timeout", "", "how long to wait for the agent reply before timing out (e.g. 10m)")
 		enabled := flags.Bool("enabled", true, "enable job after creation")
 		sessionKey := flags.String("session", "", "optional session key for self-scoped creation")
 		org := flags.String("org", "", "org id override")
@@ -1021,6 +1058,10 @@ func handleJobs(args []string) {
 		}
 		scheduleSpec, err := resolveJobCreateSchedule(*schedule, *every, *at)
 		dieIf(err)
+		policy, err := resolveJobCreatePolicy(*overlap, *catchUp, *jitter, *maxAgentConcurrency)
+		dieIf(err)
+		criteria, err := resolveJobSuccessCriteria(*expectRegex, *expectJSONField, *expectClassifier, *replyTimeout)
+		dieIf(err)
 
 		cfg, err := ottercli.LoadConfig()
 		dieIf(err)
@@ -1061,6 +1102,12 @@ func handleJobs(args []string) {
 		if trimmedSessionKey := strings.TrimSpace(*sessionKey); trimmedSessionKey != "" {
 			payloadBody["session_key"] = trimmedSessionKey
 		}
+		for key, value := range policy {
+			payloadBody[key] = value
+		}
+		for key, value := range criteria {
+			payloadBody[key] = value
+		}
 
 		created, err := client.CreateJob(payloadBody)
 		dieIf(err)
@@ -1187,7 +1234,48 @@ func handleJobs(args []string) {
 		}
 		for _, run := range runs.Items {
 			fmt.Printf("%s  %-8s  %s\n", run.ID, run.Status, run.StartedAt)
+			if run.Error != nil && strings.TrimSpace(*run.Error) != "" {
+				fmt.Printf("    error: %s\n", strings.TrimSpace(*run.Error))
+			}
+			if run.Output != nil && strings.TrimSpace(*run.Output) != "" {
+				fmt.Printf("    output: %s\n", summarizeJobRunOutput(*run.Output, 120))
+			}
+		}
+	case "reply":
+		jobID, flagArgs := splitJobCommandArgs(args[1:])
+		flags := flag.NewFlagSet("jobs reply", flag.ExitOnError)
+		runID := flags.String("run", "", "run id awaiting a reply")
+		output := flags.String("output", "", "reply text to record as the run output")
+		org := flags.String("org", "", "org id override")
+		jsonOut := flags.Bool("json", false, "JSON output")
+		_ = flags.Parse(flagArgs)
+		if jobID == "" {
+			if len(flags.Args()) == 0 {
+				die("usage: otter jobs reply <job-id> --run <run-id> --output <text>")
+			}
+			jobID = strings.TrimSpace(flags.Args()[0])
+		}
+		if jobID == "" {
+			die("job id is required")
+		}
+		if strings.TrimSpace(*runID) == "" {
+			die("--run is required")
 		}
+		if strings.TrimSpace(*output) == "" {
+			die("--output is required")
+		}
+
+		cfg, err := ottercli.LoadConfig()
+		dieIf(err)
+		client, _ := ottercli.NewClient(cfg, *org)
+		response, err := client.ReplyToJobRun(jobID, *runID, *output)
+		dieIf(err)
+
+		if *jsonOut {
+			printJSON(response)
+			return
+		}
+		fmt.Printf("Recorded reply for run %s\n", strings.TrimSpace(*runID))
 	case "delete":
 		jobID, flagArgs := splitJobCommandArgs(args[1:])
 		flags := flag.NewFlagSet("jobs delete", flag.ExitOnError)
@@ -1215,6 +1303,98 @@ func handleJobs(args []string) {
 			return
 		}
 		fmt.Printf("Deleted job %s\n", jobID)
+	case "blackouts", "blackout":
+		handleJobBlackouts(args[1:])
+	default:
+		fmt.Println(usageText)
+		os.Exit(1)
+	}
+}
+
+func handleJobBlackouts(args []string) {
+	const usageText = "usage: otter jobs blackouts <list|add|remove> ..."
+	if len(args) == 0 {
+		fmt.Println(usageText)
+		os.Exit(1)
+	}
+
+	switch args[0] {
+	case "list":
+		flags := flag.NewFlagSet("jobs blackouts list", flag.ExitOnError)
+		org := flags.String("org", "", "org id override")
+		jsonOut := flags.Bool("json", false, "JSON output")
+		_ = flags.Parse(args[1:])
+
+		cfg, err := ottercli.LoadConfig()
+		dieIf(err)
+		client, _ := ottercli.NewClient(cfg, *org)
+		response, err := client.ListJobBlackouts()
+		dieIf(err)
+
+		if *jsonOut {
+			printJSON(response)
+			return
+		}
+		if len(response.Items) == 0 {
+			fmt.Println("No blackout windows configured.")
+			return
+		}
+		for _, window := range response.Items {
+			fmt.Printf("%s  %-20s  %s\n", window.ID, window.Name, describeJobBlackout(window))
+		}
+	case "add":
+		flags := flag.NewFlagSet("jobs blackouts add", flag.ExitOnError)
+		name := flags.String("name", "", "window name (e.g. Weekends, Thanksgiving)")
+		days := flags.String("days", "", "comma-separated weekdays (sun,mon,... or 0-6)")
+		from := flags.String("from", "", "first blackout date (YYYY-MM-DD)")
+		to := flags.String("to", "", "last blackout date (YYYY-MM-DD)")
+		start := flags.String("start", "", "daily start time (HH:MM, job timezone)")
+		end := flags.String("end", "", "daily end time (HH:MM, job timezone; 24:00 for end of day)")
+		org := flags.String("org", "", "org id override")
+		jsonOut := flags.Bool("json", false, "JSON output")
+		_ = flags.Parse(args[1:])
+
+		if strings.TrimSpace(*name) == "" {
+			die("--name is required")
+		}
+		payloadBody, err := buildJobBlackoutPayload(*name, *days, *from, *to, *start, *end)
+		dieIf(err)
+
+		cfg, err := ottercli.LoadConfig()
+		dieIf(err)
+		client, _ := ottercli.NewClient(cfg, *org)
+		created, err := client.CreateJobBlackout(payloadBody)
+		dieIf(err)
+
+		if *jsonOut {
+			printJSON(created)
+			return
+		}
+		fmt.Printf("Created blackout window %s (%s)\n", created.Name, created.ID)
+	case "remove", "delete":
+		blackoutID, flagArgs := splitJobCommandArgs(args[1:])
+		flags := flag.NewFlagSet("jobs blackouts remove", flag.ExitOnError)
+		org := flags.String("org", "", "org id override")
+		jsonOut := flags.Bool("json", false, "JSON output")
+		_ = flags.Parse(flagArgs)
+		if blackoutID == "" {
+			if len(flags.Args()) == 0 {
+				die("usage: otter jobs blackouts remove <blackout-id>")
+			}
+			blackoutID = strings.TrimSpace(flags.Args()[0])
+		}
+
+		cfg, err := ottercli.LoadConfig()
+		dieIf(err)
+		client, _ := ottercli.NewClient(cfg, *org)
+		response, err := client.DeleteJobBlackout(blackoutID)
+		dieIf(err)
+
+		if *jsonOut {
+			printJSON(response)
+			return
+		}
+		fmt.Printf("Removed blackout window %s\n", blackoutID)
 	default:
 		fmt.Println(usageText)
 		os.Exit(1)
@@ -2036,7 +2216,7 @@ func handleRepo(args []string) {
 
 func handleIssue(args []string) {
 	if len(args) == 0 {
-		fmt.Println("usage: otter issue <create|list|view|comment|ask|respond|assign|close|reopen|step-done|step-reject|pipeline-status> ...")
+		fmt.Println("usage: otter issue <create|list|views|templates|recurring|import|analytics|bulk|tree|children|decompose|merge|log|threads|view|comment|ask|respond|assign|close|reopen|step-done|step-reject|pipeline-status> ...")
 		os.Exit(1)
 	}
 
@@ -2048,6 +2228,10 @@ func handleIssue(args []string) {
 		assign := flags.String("assign", "", "owner agent id/name/slug")
 		priority := flags.String("priority", "", "priority (P0-P3)")
 		workStatus := flags.String("work-status", "", "work status")
+		template := flags.String("template", "", "issue template name or id (see `otter issue templates`)")
+		var fieldFlags cliRepeatedFlag
+		flags.Var(&fieldFlags, "field", "custom field value key=value (repeatable; needs --template)")
+		filingAgent := flags.String("agent", "", "agent filing the issue (defaults to OTTER_AGENT_ID)")
 		org := flags.String("org", "", "org id override")
 		jsonOut := flags.Bool("json", false, "JSON output")
 		_ = flags.Parse(args[1:])
@@ -2056,6 +2240,11 @@ func handleIssue(args []string) {
 		if len(titleArgs) == 0 {
 			die("issue title is required")
 		}
+		fields, err := parseIssueFieldFlags(fieldFlags)
+		dieIf(err)
+		if len(fields) > 0 && strings.TrimSpace(*template) == "" {
+			die("--field requires --template")
+		}
 		title := strings.Join(titleArgs, " ")
 		if strings.TrimSpace(*projectRef) == "" {
 			die("--project is required")
@@ -2077,11 +2266,26 @@ func handleIssue(args []string) {
 		if strings.TrimSpace(*workStatus) != "" {
 			payload["work_status"] = strings.TrimSpace(*workStatus)
 		}
+		if strings.TrimSpace(*template) != "" {
+			payload["template_id"] = strings.TrimSpace(*template)
+			if len(fields) > 0 {
+				payload["fields"] = fields
+			}
+		}
 		if strings.TrimSpace(*assign) != "" {
 			agent, err := client.ResolveAgent(*assign)
 			dieIf(err)
 			payload["owner_agent_id"] = agent.ID
 		}
+		filingAgentRef := strings.TrimSpace(*filingAgent)
+		if filingAgentRef == "" {
+			filingAgentRef = strings.TrimSpace(os.Getenv("OTTER_AGENT_ID"))
+		}
+		if filingAgentRef != "" {
+			agent, err := client.ResolveAgent(filingAgentRef)
+			dieIf(err)
+			payload["agent_id"] = agent.ID
+		}
 
 		issue, err := client.CreateIssue(project.ID, payload)
 		dieIf(err)
@@ -2097,6 +2301,15 @@ func handleIssue(args []string) {
 		}
 		fmt.Printf("Status: %s / %s\n", issue.State, issue.WorkStatus)
 		fmt.Printf("Priority: %s\n", issue.Priority)
+		for _, field := range issue.Fields {
+			fmt.Printf("%s: %s\n", field.Key, field.Value)
+		}
+		for _, duplicate := range issue.Duplicates {
+			fmt.Printf("Possible duplicate: #%d %s (%.0f%% similar)\n", duplicate.Issue.IssueNumber, duplicate.Issue.Title, duplicate.Similarity*100)
+		}
+		if len(issue.Duplicates) > 0 {
+			fmt.Printf("Merge with: otter issue merge %d --into <issue> --project %q\n", issue.IssueNumber, project.Name)
+		}
 
 	case "list":
 		flags := flag.NewFlagSet("issue list", flag.ExitOnError)
@@ -2107,21 +2320,36 @@ func handleIssue(args []string) {
 		priority := flags.String("priority", "", "priority filter (P0-P3)")
 		owner := flags.String("owner", "", "owner agent id/name/slug")
 		mine := flags.Bool("mine", false, "filter to current agent id (OTTER_AGENT_ID)")
+		query := flags.String("query", "", "issue query, e.g. 'is:open label:blog priority:P0,P1 sort:due'")
+		view := flags.String("view", "", "saved view name or id (see `otter issue views`)")
+		cursor := flags.String("cursor", "", "next_cursor from a previous page")
+		limit := flags.Int("limit", 0, "maximum issues per page")
 		org := flags.String("org", "", "org id override")
 		jsonOut := flags.Bool("json", false, "JSON output")
 		_ = flags.Parse(args[1:])
 
-		if strings.TrimSpace(*projectRef) == "" {
-			die("--project is required")
+		usesQuery := strings.TrimSpace(*query) != "" || strings.TrimSpace(*view) != ""
+		if strings.TrimSpace(*projectRef) == "" && !usesQuery {
+			die("--project is required (or use --query / --view)")
 		}
 
 		cfg, err := ottercli.LoadConfig()
 		dieIf(err)
 		client, _ := ottercli.NewClient(cfg, *org)
-		project, err := client.FindProject(*projectRef)
-		dieIf(err)
+		project := ottercli.Project{}
+		if strings.TrimSpace(*projectRef) != "" {
+			project, err = client.FindProject(*projectRef)
+			dieIf(err)
+		}
 
-		filters := map[string]string{}
+		filters := map[string]string{
+			"q":      strings.TrimSpace(*query),
+			"view":   strings.TrimSpace(*view),
+			"cursor": strings.TrimSpace(*cursor),
+		}
+		if *limit > 0 {
+			filters["limit"] = strconv.Itoa(*limit)
+		}
 		if strings.TrimSpace(*state) != "" {
 			filters["state"] = strings.TrimSpace(*state)
 		}
@@ -2151,20 +2379,26 @@ func handleIssue(args []string) {
 			filters["owner_agent_id"] = agent.ID
 		}
 
-		issues, err := client.ListIssues(project.ID, filters)
+		page, err := client.ListIssuesPage(project.ID, filters)
 		dieIf(err)
+		issues := page.Items
 
 		if *jsonOut {
 			printJSON(map[string]interface{}{
-				"project_id": project.ID,
-				"items":      issues,
-				"total":      len(issues),
+				"project_id":  project.ID,
+				"items":       issues,
+				"total":       len(issues),
+				"next_cursor": page.NextCursor,
 			})
 			return
 		}
 
 		if len(issues) == 0 {
-			fmt.Printf("No issues found for %s\n", project.Name)
+			if project.Name != "" {
+				fmt.Printf("No issues found for %s\n", project.Name)
+			} else {
+				fmt.Println("No issues found")
+			}
 			return
 		}
 
@@ -2182,6 +2416,39 @@ func handleIssue(args []string) {
 				ownerText,
 			)
 		}
+		if page.NextCursor != "" {
+			fmt.Printf("More results: rerun with --cursor %s\n", page.NextCursor)
+		}
+
+	case "views":
+		handleIssueViews(args[1:])
+
+	case "templates":
+		handleIssueTemplates(args[1:])
+
+	case "recurring":
+		handleIssueRecurring(args[1:])
+
+	case "import":
+		handleIssueImport(args[1:])
+
+	case "analytics":
+		handleIssueAnalytics(args[1:])
+
+	case "bulk":
+		handleIssueBulk(args[1:])
+
+	case "tree", "children", "decompose":
+		handleIssueSubIssues(args[0], args[1:])
+
+	case "merge":
+		handleIssueMerge(args[1:])
+
+	case "log":
+		handleIssueLog(args[1:])
+
+	case "threads":
+		handleIssueReviewThreads(args[1:])
 
 	case "view":
 		flags := flag.NewFlagSet("issue view", flag.ExitOnError)
@@ -2520,7 +2787,7 @@ func handleIssue(args []string) {
 		dieIf(err)
 
 	default:
-		fmt.Println("usage: otter issue <create|list|view|comment|ask|respond|assign|close|reopen|step-done|step-reject|pipeline-status> ...")
+		fmt.Println("usage: otter issue <create|list|views|templates|recurring|import|analytics|bulk|tree|children|decompose|merge|log|threads|view|comment|ask|respond|assign|close|reopen|step-done|step-reject|pipeline-status> ...")
 		os.Exit(1)
 	}
 }
@@ -2995,6 +3262,190 @@ func resolveJobCreateSchedule(schedule string, every string, at string) (jobCrea
 	}, nil
 }
 
+func resolveJobCreatePolicy(overlap, catchUp, jitter string, maxAgentConcurrency int) (map[string]any, error) {
+	policy := map[string]any{}
+
+	if trimmed := strings.TrimSpace(strings.ToLower(overlap)); trimmed != "" {
+		switch trimmed {
+		case "skip", "queue", "replace":
+			policy["overlap_policy"] = trimmed
+		default:
+			return nil, errors.New("--overlap must be skip, queue, or replace")
+		}
+	}
+	if trimmed := strings.TrimSpace(strings.ToLower(catchUp)); trimmed != "" {
+		normalized := strings.ReplaceAll(trimmed, "-", "_")
+		switch normalized {
+		case "run_once", "run_all", "skip":
+			policy["catch_up_policy"] = normalized
+		default:
+			return nil, errors.New("--catch-up must be run-once, run-all, or skip")
+		}
+	}
+	if trimmed := strings.TrimSpace(jitter); trimmed != "" {
+		duration, err := time.ParseDuration(trimmed)
+		if err != nil || duration < 0 {
+			return nil, errors.New("--jitter must be a non-negative duration (for example: 30s, 5m)")
+		}
+		policy["jitter_ms"] = duration.Milliseconds()
+	}
+	if maxAgentConcurrency < 0 {
+		return nil, errors.New("--max-agent-concurrency must be positive")
+	}
+	if maxAgentConcurrency > 0 {
+		policy["max_agent_concurrency"] = maxAgentConcurrency
+	}
+	return policy, nil
+}
+
+func resolveJobSuccessCriteria(expectRegex, expectJSONField, expectClassifier, replyTimeout string) (map[string]any, error) {
+	criteria := map[string]any{}
+
+	if trimmed := strings.TrimSpace(expectRegex); trimmed != "" {
+		if _, err := regexp.Compile(trimmed); err != nil {
+			return nil, fmt.Errorf("--expect-regex is invalid: %v", err)
+		}
+		criteria["success_pattern"] = trimmed
+	}
+	if trimmed := strings.TrimSpace(expectJSONField); trimmed != "" {
+		for _, segment := range strings.Split(trimmed, ".") {
+			if strings.TrimSpace(segment) == "" {
+				return nil, errors.New("--expect-json-field must be a dotted path like result.status")
+			}
+		}
+		criteria["success_json_field"] = trimmed
+	}
+	if trimmed := strings.TrimSpace(expectClassifier); trimmed != "" {
+		criteria["success_classifier_prompt"] = trimmed
+	}
+	if trimmed := strings.TrimSpace(replyTimeout); trimmed != "" {
+		duration, err := time.ParseDuration(trimmed)
+		if err != nil || duration < time.Millisecond {
+			return nil, errors.New("--reply-timeout must be a positive duration (for example: 30s, 10m)")
+		}
+		criteria["reply_timeout_ms"] = duration.Milliseconds()
+	}
+	return criteria, nil
+}
+
+func summarizeJobRunOutput(output string, maxRunes int) string {
+	collapsed := strings.Join(strings.Fields(output), " ")
+	runes := []rune(collapsed)
+	if maxRunes <= 0 || len(runes) <= maxRunes {
+		return collapsed
+	}
+	return string(runes[:maxRunes]) + "..."
+}
+
+var jobBlackoutWeekdays = map[string]int{
+	"sun": 0, "sunday": 0,
+	"mon": 1, "monday": 1,
+	"tue": 2, "tuesday": 2,
+	"wed": 3, "wednesday": 3,
+	"thu": 4, "thursday": 4,
+	"fri": 5, "friday": 5,
+	"sat": 6, "saturday": 6,
+}
+
+func buildJobBlackoutPayload(name, days, from, to, start, end string) (map[string]any, error) {
+	payload := map[string]any{"name": strings.TrimSpace(name)}
+
+	if parts := splitCSV(days); len(parts) > 0 {
+		parsedDays := make([]int, 0, len(parts))
+		for _, part := range parts {
+			normalized := strings.ToLower(strings.TrimSpace(part))
+			if day, ok := jobBlackoutWeekdays[normalized]; ok {
+				parsedDays = append(parsedDays, day)
+				continue
+			}
+			day, err := strconv.Atoi(normalized)
+			if err != nil || day < 0 || day > 6 {
+				return nil, fmt.Errorf("--days has invalid weekday %q", part)
+			}
+			parsedDays = append(parsedDays, day)
+		}
+		payload["days_of_week"] = parsedDays
+	}
+	for flagName, raw := range map[string]string{"from": from, "to": to} {
+		trimmed := strings.TrimSpace(raw)
+		if trimmed == "" {
+			continue
+		}
+		if _, err := time.Parse("2006-01-02", trimmed); err != nil {
+			return nil, fmt.Errorf("--%s must be a YYYY-MM-DD date", flagName)
+		}
+		if flagName == "from" {
+			payload["start_date"] = trimmed
+		} else {
+			payload["end_date"] = trimmed
+		}
+	}
+	if trimmed := strings.TrimSpace(start); trimmed != "" {
+		minute, err := parseClockMinute(trimmed)
+		if err != nil {
+			return nil, fmt.Errorf("--start %w", err)
+		}
+		payload["start_minute"] = minute
+	}
+	if trimmed := strings.TrimSpace(end); trimmed != "" {
+		minute, err := parseClockMinute(trimmed)
+		if err != nil {
+			return nil, fmt.Errorf("--end %w", err)
+		}
+		payload["end_minute"] = minute
+	}
+	return payload, nil
+}
+
+func parseClockMinute(raw string) (int, error) {
+	parts := strings.Split(strings.TrimSpace(raw), ":")
+	if len(parts) != 2 {
+		return 0, errors.New("must be HH:MM")
+	}
+	hour, hourErr := strconv.Atoi(parts[0])
+	minute, minuteErr := strconv.Atoi(parts[1])
+	if hourErr != nil || minuteErr != nil || hour < 0 || minute < 0 || minute > 59 {
+		return 0, errors.New("must be HH:MM")
+	}
+	total := hour*60 + minute
+	if total > 24*60 {
+		return 0, errors.New("must be at most 24:00")
+	}
+	return total, nil
+}
+
+func describeJobBlackout(window ottercli.AgentJobBlackout) string {
+	parts := []string{}
+	if len(window.DaysOfWeek) > 0 {
+		names := []string{"sun", "mon", "tue", "wed", "thu", "fri", "sat"}
+		days := make([]string, 0, len(window.DaysOfWeek))
+		for _, day := range window.DaysOfWeek {
+			if day >= 0 && day < len(names) {
+				days = append(days, names[day])
+			}
+		}
+		parts = append(parts, strings.Join(days, ","))
+	}
+	if window.StartDate != nil || window.EndDate != nil {
+		from, to := "…", "…"
+		if window.StartDate != nil {
+			from = *window.StartDate
+		}
+		if window.EndDate != nil {
+			to = *window.EndDate
+		}
+		parts = append(parts, from+" to "+to)
+	}
+	if window.StartMinute != 0 || window.EndMinute != 24*60 {
+		parts = append(parts, fmt.Sprintf(
+			"%02d:%02d-%02d:%02d",
+			window.StartMinute/60, window.StartMinute%60,
+			window.EndMinute/60, window.EndMinute%60,
+		))
+	}
+	return strings.Join(parts, "  ")
+}
+
 func splitJobCommandArgs(args []string) (string, []string) {
 	if len(args) == 0 {
 		return "", nil
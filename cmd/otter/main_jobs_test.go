@@ -46,6 +46,9 @@ func TestHandleJobs(t *testing.T) {
 			_, _ = w.Write([]byte(`{"id":"job-1","status":"active","org_id":"org-1","agent_id":"` + agentID + `","name":"Heartbeat","schedule_kind":"interval","payload_kind":"message","payload_text":"ping","enabled":true,"run_count":0,"error_count":0,"max_failures":5,"consecutive_failures":0,"timezone":"UTC","created_at":"2026-02-12T00:00:00Z","updated_at":"2026-02-12T00:00:00Z"}`))
 		case r.Method == http.MethodGet && r.URL.Path == "/api/v1/jobs/job-1/runs":
 			_, _ = w.Write([]byte(`{"items":[{"id":"run-1","job_id":"job-1","org_id":"org-1","status":"success","started_at":"2026-02-12T00:00:10Z","created_at":"2026-02-12T00:00:10Z","payload_text":"ping"}],"total":1}`))
+		case r.Method == http.MethodPost && r.URL.Path == "/api/v1/jobs/job-1/runs/run-1/reply":
+			w.WriteHeader(http.StatusAccepted)
+			_, _ = w.Write([]byte(`{"job_id":"job-1","run_id":"run-1","message_id":"msg-1"}`))
 		case r.Method == http.MethodDelete && r.URL.Path == "/api/v1/jobs/job-1":
 			_, _ = w.Write([]byte(`{"deleted":true,"id":"job-1"}`))
 		default:
@@ -75,6 +78,12 @@ func TestHandleJobs(t *testing.T) {
 		"--name", "Heartbeat",
 		"--every", "30s",
 		"--payload", "ping",
+		"--overlap", "queue",
+		"--catch-up", "run-all",
+		"--jitter", "5m",
+		"--max-agent-concurrency", "2",
+		"--expect-regex", "^ok",
+		"--reply-timeout", "10m",
 		"--json",
 	})
 	mu.Lock()
@@ -85,6 +94,12 @@ func TestHandleJobs(t *testing.T) {
 	require.Equal(t, float64(30000), gotBody["interval_ms"])
 	require.Equal(t, "message", gotBody["payload_kind"])
 	require.Equal(t, "ping", gotBody["payload_text"])
+	require.Equal(t, "queue", gotBody["overlap_policy"])
+	require.Equal(t, "run_all", gotBody["catch_up_policy"])
+	require.Equal(t, float64(300000), gotBody["jitter_ms"])
+	require.Equal(t, float64(2), gotBody["max_agent_concurrency"])
+	require.Equal(t, "^ok", gotBody["success_pattern"])
+	require.Equal(t, float64(600000), gotBody["reply_timeout_ms"])
 	mu.Unlock()
 
 	handleJobs([]string{"pause", "job-1", "--json"})
@@ -112,6 +127,13 @@ func TestHandleJobs(t *testing.T) {
 	require.Contains(t, gotPath, "limit=5")
 	mu.Unlock()
 
+	handleJobs([]string{"reply", "job-1", "--run", "run-1", "--output", "ok: done", "--json"})
+	mu.Lock()
+	require.Equal(t, http.MethodPost, gotMethod)
+	require.Equal(t, "/api/v1/jobs/job-1/runs/run-1/reply", gotPath)
+	require.Equal(t, "ok: done", gotBody["output"])
+	mu.Unlock()
+
 	handleJobs([]string{"delete", "job-1", "--json"})
 	mu.Lock()
 	require.Equal(t, http.MethodDelete, gotMethod)
@@ -154,3 +176,94 @@ func TestHandleJobsScheduleValidation(t *testing.T) {
 	require.Error(t, err)
 	require.Contains(t, err.Error(), "--at")
 }
+
+func TestHandleJobsPolicyValidation(t *testing.T) {
+	policy, err := resolveJobCreatePolicy("", "", "", 0)
+	require.NoError(t, err)
+	require.Empty(t, policy)
+
+	policy, err = resolveJobCreatePolicy("Replace", "run-once", "90s", 3)
+	require.NoError(t, err)
+	require.Equal(t, "replace", policy["overlap_policy"])
+	require.Equal(t, "run_once", policy["catch_up_policy"])
+	require.Equal(t, int64(90000), policy["jitter_ms"])
+	require.Equal(t, 3, policy["max_agent_concurrency"])
+
+	_, err = resolveJobCreatePolicy("parallel", "", "", 0)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "--overlap")
+
+	_, err = resolveJobCreatePolicy("", "sometimes", "", 0)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "--catch-up")
+
+	_, err = resolveJobCreatePolicy("", "", "-5m", 0)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "--jitter")
+
+	_, err = resolveJobCreatePolicy("", "", "", -1)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "--max-agent-concurrency")
+}
+
+func TestHandleJobsSuccessCriteriaValidation(t *testing.T) {
+	criteria, err := resolveJobSuccessCriteria("", "", "", "")
+	require.NoError(t, err)
+	require.Empty(t, criteria)
+
+	criteria, err = resolveJobSuccessCriteria(`(?i)backup ok`, "result.status", "Did the backup finish?", "90s")
+	require.NoError(t, err)
+	require.Equal(t, `(?i)backup ok`, criteria["success_pattern"])
+	require.Equal(t, "result.status", criteria["success_json_field"])
+	require.Equal(t, "Did the backup finish?", criteria["success_classifier_prompt"])
+	require.Equal(t, int64(90000), criteria["reply_timeout_ms"])
+
+	_, err = resolveJobSuccessCriteria("(unclosed", "", "", "")
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "--expect-regex")
+
+	_, err = resolveJobSuccessCriteria("", "result..status", "", "")
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "--expect-json-field")
+
+	_, err = resolveJobSuccessCriteria("", "", "", "0s")
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "--reply-timeout")
+
+	require.Equal(t, "a b c", summarizeJobRunOutput("a\n b\tc", 10))
+	require.Equal(t, "abc...", summarizeJobRunOutput("abcdef", 3))
+}
+
+func TestBuildJobBlackoutPayload(t *testing.T) {
+	payload, err := buildJobBlackoutPayload("Weekends", "sat,Sun", "", "", "", "")
+	require.NoError(t, err)
+	require.Equal(t, "Weekends", payload["name"])
+	require.Equal(t, []int{6, 0}, payload["days_of_week"])
+
+	payload, err = buildJobBlackoutPayload("Holidays", "", "2026-12-24", "2026-12-26", "", "")
+	require.NoError(t, err)
+	require.Equal(t, "2026-12-24", payload["start_date"])
+	require.Equal(t, "2026-12-26", payload["end_date"])
+
+	payload, err = buildJobBlackoutPayload("Maintenance", "1", "", "", "02:00", "24:00")
+	require.NoError(t, err)
+	require.Equal(t, []int{1}, payload["days_of_week"])
+	require.Equal(t, 120, payload["start_minute"])
+	require.Equal(t, 1440, payload["end_minute"])
+
+	_, err = buildJobBlackoutPayload("Bad", "funday", "", "", "", "")
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "--days")
+
+	_, err = buildJobBlackoutPayload("Bad", "", "12/24/2026", "", "", "")
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "--from")
+
+	_, err = buildJobBlackoutPayload("Bad", "", "", "", "2pm", "")
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "--start")
+
+	_, err = buildJobBlackoutPayload("Bad", "", "", "", "", "24:30")
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "--end")
+}
@@ -0,0 +1,279 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/samhotchkiss/otter-camp/internal/ottercli"
+)
+
+type rolesCommandClient interface {
+	ListCapabilities() (ottercli.CapabilityListResponse, error)
+	ListRoles() (ottercli.RoleListResponse, error)
+	CreateRole(input map[string]any) (ottercli.Role, error)
+	UpdateRole(roleID string, input map[string]any) (ottercli.Role, error)
+	DeleteRole(roleID string) error
+	ListRoleBindings(userID, projectID string) (ottercli.RoleBindingListResponse, error)
+	CreateRoleBinding(input map[string]any) (ottercli.RoleBinding, error)
+	DeleteRoleBinding(bindingID string) error
+}
+
+type rolesClientFactory func(orgOverride string) (rolesCommandClient, error)
+
+const rolesUsage = "usage: otter roles <list|capabilities|create|update|delete|bindings|grant|revoke> ..."
+
+func handleRoles(args []string) {
+	if err := runRolesCommand(args, newRolesCommandClient, os.Stdout); err != nil {
+		die(err.Error())
+	}
+}
+
+func runRolesCommand(args []string, factory rolesClientFactory, out io.Writer) error {
+	if len(args) == 0 {
+		return errors.New(rolesUsage)
+	}
+
+	command := args[0]
+	flags := flag.NewFlagSet("roles "+command, flag.ContinueOnError)
+	flags.SetOutput(io.Discard)
+	org := flags.String("org", "", "org id override")
+	jsonOut := flags.Bool("json", false, "JSON output")
+	description := flags.String("description", "", "role description")
+	capabilities := flags.String("capabilities", "", "comma-separated capabilities")
+	userID := flags.String("user", "", "user id")
+	projectID := flags.String("project", "", "project id")
+
+	var usage string
+	positionalCount := 0
+	switch command {
+	case "list", "capabilities":
+		usage = "usage: otter roles " + command + " [--json]"
+	case "create":
+		usage = "usage: otter roles create <name> --capabilities <a,b> [--description <text>]"
+		positionalCount = 1
+	case "update":
+		usage = "usage: otter roles update <name|id> [--capabilities <a,b>] [--description <text>]"
+		positionalCount = 1
+	case "delete":
+		usage = "usage: otter roles delete <name|id>"
+		positionalCount = 1
+	case "bindings":
+		usage = "usage: otter roles bindings [--user <id>] [--project <id>] [--json]"
+	case "grant":
+		usage = "usage: otter roles grant <role> --user <id> [--project <id>]"
+		positionalCount = 1
+	case "revoke":
+		usage = "usage: otter roles revoke <binding-id>"
+		positionalCount = 1
+	default:
+		return errors.New(rolesUsage)
+	}
+
+	positional, err := parseInterspersedFlags(flags, args[1:])
+	if err != nil {
+		return err
+	}
+	if len(positional) != positionalCount {
+		return errors.New(usage)
+	}
+	set := map[string]bool{}
+	flags.Visit(func(f *flag.Flag) { set[f.Name] = true })
+
+	client, err := factory(strings.TrimSpace(*org))
+	if err != nil {
+		return err
+	}
+
+	switch command {
+	case "list":
+		roles, err := client.ListRoles()
+		if err != nil {
+			return err
+		}
+		if *jsonOut {
+			printJSONTo(out, roles)
+			return nil
+		}
+		for _, role := range roles.Items {
+			kind := "custom"
+			if role.Builtin {
+				kind = "builtin"
+			}
+			fmt.Fprintf(out, "%-20s %-8s %s\n", role.Name, kind, strings.Join(role.Capabilities, ","))
+		}
+		return nil
+	case "capabilities":
+		caps, err := client.ListCapabilities()
+		if err != nil {
+			return err
+		}
+		if *jsonOut {
+			printJSONTo(out, caps)
+			return nil
+		}
+		for _, capability := range caps.Items {
+			fmt.Fprintf(out, "%-28s %s\n", capability.Name, capability.Description)
+		}
+		return nil
+	case "create":
+		if !set["capabilities"] {
+			return errors.New(usage)
+		}
+		role, err := client.CreateRole(map[string]any{
+			"name":         strings.TrimSpace(positional[0]),
+			"description":  strings.TrimSpace(*description),
+			"capabilities": roleCapabilityList(*capabilities),
+		})
+		if err != nil {
+			return err
+		}
+		return printRole(out, role, *jsonOut, "Created")
+	case "update":
+		input := map[string]any{}
+		if set["description"] {
+			input["description"] = strings.TrimSpace(*description)
+		}
+		if set["capabilities"] {
+			input["capabilities"] = roleCapabilityList(*capabilities)
+		}
+		if len(input) == 0 {
+			return errors.New(usage)
+		}
+		roleID, err := resolveCustomRoleID(client, positional[0])
+		if err != nil {
+			return err
+		}
+		role, err := client.UpdateRole(roleID, input)
+		if err != nil {
+			return err
+		}
+		return printRole(out, role, *jsonOut, "Updated")
+	case "delete":
+		roleID, err := resolveCustomRoleID(client, positional[0])
+		if err != nil {
+			return err
+		}
+		if err := client.DeleteRole(roleID); err != nil {
+			return err
+		}
+		fmt.Fprintf(out, "Deleted role %s\n", strings.TrimSpace(positional[0]))
+		return nil
+	case "bindings":
+		bindings, err := client.ListRoleBindings(*userID, *projectID)
+		if err != nil {
+			return err
+		}
+		if *jsonOut {
+			printJSONTo(out, bindings)
+			return nil
+		}
+		for _, binding := range bindings.Items {
+			fmt.Fprintf(out, "%s  %-20s user=%s scope=%s\n", binding.ID, binding.Role, binding.UserID, roleBindingScope(binding))
+		}
+		return nil
+	case "grant":
+		if strings.TrimSpace(*userID) == "" {
+			return errors.New(usage)
+		}
+		input := map[string]any{
+			"user_id": strings.TrimSpace(*userID),
+			"role":    strings.TrimSpace(positional[0]),
+		}
+		if project := strings.TrimSpace(*projectID); project != "" {
+			input["project_id"] = project
+		}
+		binding, err := client.CreateRoleBinding(input)
+		if err != nil {
+			return err
+		}
+		if *jsonOut {
+			printJSONTo(out, binding)
+			return nil
+		}
+		fmt.Fprintf(out, "Granted %s to %s (%s), binding %s\n", binding.Role, binding.UserID, roleBindingScope(binding), binding.ID)
+		return nil
+	case "revoke":
+		if err := client.DeleteRoleBinding(positional[0]); err != nil {
+			return err
+		}
+		fmt.Fprintf(out, "Revoked binding %s\n", strings.TrimSpace(positional[0]))
+		return nil
+	}
+	return errors.New(rolesUsage)
+}
+
+// parseInterspersedFlags allows flags before and after positional arguments.
+func parseInterspersedFlags(flags *flag.FlagSet, args []string) ([]string, error) {
+	positional := make([]string, 0, 1)
+	for {
+		if err := flags.Parse(args); err != nil {
+			return nil, err
+		}
+		rest := flags.Args()
+		if len(rest) == 0 {
+			return positional, nil
+		}
+		positional = append(positional, rest[0])
+		args = rest[1:]
+	}
+}
+
+// resolveCustomRoleID accepts a role name or id. Built-in roles cannot be
+// changed, so only custom roles match.
+func resolveCustomRoleID(client rolesCommandClient, nameOrID string) (string, error) {
+	nameOrID = strings.TrimSpace(nameOrID)
+	roles, err := client.ListRoles()
+	if err != nil {
+		return "", err
+	}
+	for _, role := range roles.Items {
+		if role.Builtin {
+			if role.Name == nameOrID {
+				return "", fmt.Errorf("%s is a built-in role and cannot be changed", nameOrID)
+			}
+			continue
+		}
+		if role.ID == nameOrID || role.Name == nameOrID {
+			return role.ID, nil
+		}
+	}
+	return "", fmt.Errorf("role %s not found", nameOrID)
+}
+
+// roleCapabilityList sends an empty list rather than null, so
+// --capabilities "" clears a role's capabilities.
+func roleCapabilityList(raw string) []string {
+	capabilities := splitCSV(raw)
+	if capabilities == nil {
+		return []string{}
+	}
+	return capabilities
+}
+
+func roleBindingScope(binding ottercli.RoleBinding) string {
+	if binding.ProjectID != nil && *binding.ProjectID != "" {
+		return "project " + *binding.ProjectID
+	}
+	return "org"
+}
+
+func printRole(out io.Writer, role ottercli.Role, jsonOut bool, verb string) error {
+	if jsonOut {
+		printJSONTo(out, role)
+		return nil
+	}
+	fmt.Fprintf(out, "%s role %s (%s): %s\n", verb, role.Name, role.ID, strings.Join(role.Capabilities, ","))
+	return nil
+}
+
+func newRolesCommandClient(orgOverride string) (rolesCommandClient, error) {
+	cfg, err := ottercli.LoadConfig()
+	if err != nil {
+		return nil, err
+	}
+	return ottercli.NewClient(cfg, orgOverride)
+}
@@ -0,0 +1,137 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/samhotchkiss/otter-camp/internal/ottercli"
+)
+
+type fakeRolesCommandClient struct {
+	roles        []ottercli.Role
+	createInput  map[string]any
+	updateID     string
+	updateInput  map[string]any
+	deletedRole  string
+	bindingInput map[string]any
+	revoked      string
+}
+
+func (f *fakeRolesCommandClient) ListCapabilities() (ottercli.CapabilityListResponse, error) {
+	return ottercli.CapabilityListResponse{Items: []ottercli.Capability{{Name: "issues.write", Description: "Issues"}}, Total: 1}, nil
+}
+
+func (f *fakeRolesCommandClient) ListRoles() (ottercli.RoleListResponse, error) {
+	return ottercli.RoleListResponse{Items: f.roles, Total: len(f.roles)}, nil
+}
+
+func (f *fakeRolesCommandClient) CreateRole(input map[string]any) (ottercli.Role, error) {
+	f.createInput = input
+	return ottercli.Role{ID: "role-1", Name: input["name"].(string), Capabilities: input["capabilities"].([]string)}, nil
+}
+
+func (f *fakeRolesCommandClient) UpdateRole(roleID string, input map[string]any) (ottercli.Role, error) {
+	f.updateID = roleID
+	f.updateInput = input
+	return ottercli.Role{ID: roleID, Name: "triager"}, nil
+}
+
+func (f *fakeRolesCommandClient) DeleteRole(roleID string) error {
+	f.deletedRole = roleID
+	return nil
+}
+
+func (f *fakeRolesCommandClient) ListRoleBindings(userID, projectID string) (ottercli.RoleBindingListResponse, error) {
+	return ottercli.RoleBindingListResponse{}, nil
+}
+
+func (f *fakeRolesCommandClient) CreateRoleBinding(input map[string]any) (ottercli.RoleBinding, error) {
+	f.bindingInput = input
+	projectID, _ := input["project_id"].(string)
+	return ottercli.RoleBinding{ID: "b-1", UserID: input["user_id"].(string), Role: input["role"].(string), ProjectID: &projectID}, nil
+}
+
+func (f *fakeRolesCommandClient) DeleteRoleBinding(bindingID string) error {
+	f.revoked = bindingID
+	return nil
+}
+
+func rolesTestFactory(client *fakeRolesCommandClient) rolesClientFactory {
+	return func(string) (rolesCommandClient, error) { return client, nil }
+}
+
+func TestRolesCreateSendsCapabilities(t *testing.T) {
+	client := &fakeRolesCommandClient{}
+	var out bytes.Buffer
+	err := runRolesCommand([]string{"create", "triager", "--capabilities", "issues.write, memory.write", "--description", "Triage"}, rolesTestFactory(client), &out)
+	if err != nil {
+		t.Fatalf("runRolesCommand() error = %v", err)
+	}
+	if got := client.createInput["capabilities"].([]string); strings.Join(got, ",") != "issues.write,memory.write" {
+		t.Fatalf("capabilities = %v", got)
+	}
+	if client.createInput["description"] != "Triage" {
+		t.Fatalf("description = %v", client.createInput["description"])
+	}
+	if !strings.Contains(out.String(), "Created role triager") {
+		t.Fatalf("output = %q", out.String())
+	}
+
+	if err := runRolesCommand([]string{"create", "triager"}, rolesTestFactory(client), &out); err == nil {
+		t.Fatalf("create without --capabilities should fail")
+	}
+}
+
+func TestRolesUpdateAndDeleteResolveRoleNames(t *testing.T) {
+	client := &fakeRolesCommandClient{roles: []ottercli.Role{
+		{Name: "owner", Builtin: true},
+		{ID: "role-1", Name: "triager"},
+	}}
+	var out bytes.Buffer
+	if err := runRolesCommand([]string{"update", "triager", "--capabilities", ""}, rolesTestFactory(client), &out); err != nil {
+		t.Fatalf("update error = %v", err)
+	}
+	if client.updateID != "role-1" {
+		t.Fatalf("update id = %q", client.updateID)
+	}
+	if got, ok := client.updateInput["capabilities"].([]string); !ok || got == nil || len(got) != 0 {
+		t.Fatalf("capabilities = %#v, want empty list", client.updateInput["capabilities"])
+	}
+	if _, ok := client.updateInput["description"]; ok {
+		t.Fatalf("description should not be sent when unset")
+	}
+
+	if err := runRolesCommand([]string{"delete", "owner"}, rolesTestFactory(client), &out); err == nil || !strings.Contains(err.Error(), "built-in") {
+		t.Fatalf("delete owner error = %v", err)
+	}
+	if err := runRolesCommand([]string{"delete", "role-1"}, rolesTestFactory(client), &out); err != nil {
+		t.Fatalf("delete error = %v", err)
+	}
+	if client.deletedRole != "role-1" {
+		t.Fatalf("deleted = %q", client.deletedRole)
+	}
+}
+
+func TestRolesGrantAndRevoke(t *testing.T) {
+	client := &fakeRolesCommandClient{}
+	var out bytes.Buffer
+	if err := runRolesCommand([]string{"grant", "triager", "--user", "u-1", "--project", "p-1", "--org", "org-1"}, rolesTestFactory(client), &out); err != nil {
+		t.Fatalf("grant error = %v", err)
+	}
+	if client.bindingInput["project_id"] != "p-1" || client.bindingInput["user_id"] != "u-1" {
+		t.Fatalf("binding input = %#v", client.bindingInput)
+	}
+	if !strings.Contains(out.String(), "project p-1") {
+		t.Fatalf("output = %q", out.String())
+	}
+	if err := runRolesCommand([]string{"grant", "triager"}, rolesTestFactory(client), &out); err == nil {
+		t.Fatalf("grant without --user should fail")
+	}
+	if err := runRolesCommand([]string{"revoke", "b-1"}, rolesTestFactory(client), &out); err != nil {
+		t.Fatalf("revoke error = %v", err)
+	}
+	if client.revoked != "b-1" {
+		t.Fatalf("revoked = %q", client.revoked)
+	}
+}
@@ -0,0 +1,335 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/samhotchkiss/otter-camp/internal/ottercli"
+)
+
+type usageCommandClient interface {
+	UsageReport(filter ottercli.UsageFilter) (ottercli.UsageReport, error)
+	ListModelPricing() ([]ottercli.ModelPrice, error)
+	SetModelPrice(model string, usdPerMillionTokens float64) (ottercli.ModelPrice, error)
+	DeleteModelPrice(model string) error
+	ListUsageBudgets() ([]ottercli.UsageBudget, error)
+	SetUsageBudget(input map[string]any) (ottercli.UsageBudget, error)
+	DeleteUsageBudget(budgetID string) (int, error)
+	FindProject(query string) (ottercli.Project, error)
+}
+
+type usageClientFactory func(orgOverride string) (usageCommandClient, error)
+
+const usageCommandUsage = "usage: otter usage [report|pricing|budgets] ..."
+
+func handleUsage(args []string) {
+	if err := runUsageCommand(args, newUsageCommandClient, os.Stdout); err != nil {
+		die(err.Error())
+	}
+}
+
+func runUsageCommand(args []string, factory usageClientFactory, out io.Writer) error {
+	if len(args) == 0 || strings.HasPrefix(args[0], "-") {
+		return runUsageReport(args, factory, out)
+	}
+	switch args[0] {
+	case "report":
+		return runUsageReport(args[1:], factory, out)
+	case "pricing":
+		return runUsagePricing(args[1:], factory, out)
+	case "budgets":
+		return runUsageBudgets(args[1:], factory, out)
+	}
+	return errors.New(usageCommandUsage)
+}
+
+func runUsageReport(args []string, factory usageClientFactory, out io.Writer) error {
+	const usage = "usage: otter usage report [--month YYYY-MM | --from YYYY-MM-DD [--to YYYY-MM-DD]] [--by agent|project|model|day] [--agent <slug>] [--project <name-or-id>] [--model <m>] [--json]"
+	flags := flag.NewFlagSet("usage report", flag.ContinueOnError)
+	flags.SetOutput(io.Discard)
+	org := flags.String("org", "", "org id override")
+	jsonOut := flags.Bool("json", false, "JSON output")
+	month := flags.String("month", "", "calendar month (YYYY-MM), default current")
+	from := flags.String("from", "", "first day (YYYY-MM-DD)")
+	to := flags.String("to", "", "day after the last day (YYYY-MM-DD)")
+	by := flags.String("by", "agent", "group by agent, project, model or day")
+	agent := flags.String("agent", "", "agent slug or id")
+	project := flags.String("project", "", "project name or id")
+	model := flags.String("model", "", "model name")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if len(flags.Args()) != 0 {
+		return errors.New(usage)
+	}
+
+	client, err := factory(strings.TrimSpace(*org))
+	if err != nil {
+		return err
+	}
+	filter := ottercli.UsageFilter{
+		Month:   *month,
+		From:    *from,
+		To:      *to,
+		GroupBy: *by,
+		AgentID: *agent,
+		Model:   *model,
+	}
+	if strings.TrimSpace(*project) != "" {
+		resolved, err := client.FindProject(*project)
+		if err != nil {
+			return err
+		}
+		filter.ProjectID = resolved.ID
+	}
+
+	report, err := client.UsageReport(filter)
+	if err != nil {
+		return err
+	}
+	if *jsonOut {
+		printJSONTo(out, report)
+		return nil
+	}
+	fmt.Fprintf(out, "Usage %s to %s by %s: %d tokens, $%.2f across %d events\n",
+		report.From, report.To, report.GroupBy, report.Tokens, report.CostUSD, report.Events)
+	for _, row := range report.Items {
+		label := row.Key
+		if row.Label != "" {
+			label = row.Label
+		}
+		if label == "" {
+			label = "(none)"
+		}
+		fmt.Fprintf(out, "  %-32s %12d tokens  $%10.4f  %6d events\n", label, row.Tokens, row.CostUSD, row.Events)
+	}
+	if report.UnpricedTokens > 0 {
+		fmt.Fprintf(out, "%d tokens have no model price; set one with `otter usage pricing set`\n", report.UnpricedTokens)
+	}
+	return nil
+}
+
+func runUsagePricing(args []string, factory usageClientFactory, out io.Writer) error {
+	const usage = "usage: otter usage pricing <list|set <model> <usd-per-million-tokens>|remove <model>> [--json]"
+	if len(args) == 0 {
+		return errors.New(usage)
+	}
+	command := args[0]
+	var positional []string
+	switch command {
+	case "list":
+	case "set":
+		if len(args) < 3 {
+			return errors.New(usage)
+		}
+		positional = args[1:3]
+	case "remove":
+		if len(args) < 2 {
+			return errors.New(usage)
+		}
+		positional = args[1:2]
+	default:
+		return errors.New(usage)
+	}
+
+	flags := flag.NewFlagSet("usage pricing "+command, flag.ContinueOnError)
+	flags.SetOutput(io.Discard)
+	org := flags.String("org", "", "org id override")
+	jsonOut := flags.Bool("json", false, "JSON output")
+	if err := flags.Parse(args[1+len(positional):]); err != nil {
+		return err
+	}
+	if len(flags.Args()) != 0 {
+		return errors.New(usage)
+	}
+
+	var price float64
+	if command == "set" {
+		parsed, err := strconv.ParseFloat(strings.TrimPrefix(strings.TrimSpace(positional[1]), "$"), 64)
+		if err != nil || parsed < 0 {
+			return errors.New("price must be a non-negative number of USD per million tokens")
+		}
+		price = parsed
+	}
+
+	client, err := factory(strings.TrimSpace(*org))
+	if err != nil {
+		return err
+	}
+
+	switch command {
+	case "list":
+		prices, err := client.ListModelPricing()
+		if err != nil {
+			return err
+		}
+		if *jsonOut {
+			printJSONTo(out, prices)
+			return nil
+		}
+		if len(prices) == 0 {
+			fmt.Fprintln(out, "No model prices set.")
+			return nil
+		}
+		for _, p := range prices {
+			fmt.Fprintf(out, "%-40s $%.4f / 1M tokens\n", p.Model, p.USDPerMillionTokens)
+		}
+		return nil
+	case "set":
+		saved, err := client.SetModelPrice(positional[0], price)
+		if err != nil {
+			return err
+		}
+		if *jsonOut {
+			printJSONTo(out, saved)
+			return nil
+		}
+		fmt.Fprintf(out, "Priced %s at $%.4f / 1M tokens\n", saved.Model, saved.USDPerMillionTokens)
+		return nil
+	case "remove":
+		if err := client.DeleteModelPrice(positional[0]); err != nil {
+			return err
+		}
+		fmt.Fprintf(out, "Removed price for %s\n", positional[0])
+		return nil
+	}
+	return errors.New(usage)
+}
+
+func runUsageBudgets(args []string, factory usageClientFactory, out io.Writer) error {
+	const usage = "usage: otter usage budgets <list|set (--agent <slug> | --project <name-or-id>) --limit <usd> [--period month|day] [--soft <percent>] [--soft-only]|delete <id>> [--json]"
+	if len(args) == 0 {
+		return errors.New(usage)
+	}
+	command := args[0]
+	var positional []string
+	switch command {
+	case "list", "set":
+	case "delete":
+		if len(args) < 2 {
+			return errors.New(usage)
+		}
+		positional = args[1:2]
+	default:
+		return errors.New(usage)
+	}
+
+	flags := flag.NewFlagSet("usage budgets "+command, flag.ContinueOnError)
+	flags.SetOutput(io.Discard)
+	org := flags.String("org", "", "org id override")
+	jsonOut := flags.Bool("json", false, "JSON output")
+	agent := flags.String("agent", "", "agent slug or id")
+	project := flags.String("project", "", "project name or id")
+	limit := flags.Float64("limit", 0, "limit in USD per period")
+	period := flags.String("period", "month", "budget period: month or day")
+	soft := flags.Int("soft", 0, "percent of the limit that triggers a warning (default 80)")
+	softOnly := flags.Bool("soft-only", false, "warn only; do not pause jobs or block dispatch")
+	if err := flags.Parse(args[1+len(positional):]); err != nil {
+		return err
+	}
+	if len(flags.Args()) != 0 {
+		return errors.New(usage)
+	}
+	if command == "set" {
+		if (strings.TrimSpace(*agent) == "") == (strings.TrimSpace(*project) == "") {
+			return errors.New("exactly one of --agent or --project is required")
+		}
+		if *limit <= 0 {
+			return errors.New("--limit must be greater than zero")
+		}
+	}
+
+	client, err := factory(strings.TrimSpace(*org))
+	if err != nil {
+		return err
+	}
+
+	switch command {
+	case "list":
+		budgets, err := client.ListUsageBudgets()
+		if err != nil {
+			return err
+		}
+		if *jsonOut {
+			printJSONTo(out, budgets)
+			return nil
+		}
+		if len(budgets) == 0 {
+			fmt.Fprintln(out, "No usage budgets.")
+			return nil
+		}
+		for _, b := range budgets {
+			fmt.Fprintf(out, "%s  %s:%s  $%.2f / $%.2f per %s  %s\n",
+				b.ID, b.Scope, b.ScopeID, b.SpentUSD, b.LimitUSD, b.Period, usageBudgetState(b))
+		}
+		return nil
+	case "set":
+		input := map[string]any{
+			"period":    strings.TrimSpace(*period),
+			"limit_usd": *limit,
+			"hard":      !*softOnly,
+		}
+		if *soft > 0 {
+			input["soft_percent"] = *soft
+		}
+		if strings.TrimSpace(*agent) != "" {
+			input["scope"] = "agent"
+			input["scope_id"] = strings.TrimSpace(*agent)
+		} else {
+			resolved, err := client.FindProject(*project)
+			if err != nil {
+				return err
+			}
+			input["scope"] = "project"
+			input["scope_id"] = resolved.ID
+		}
+		budget, err := client.SetUsageBudget(input)
+		if err != nil {
+			return err
+		}
+		if *jsonOut {
+			printJSONTo(out, budget)
+			return nil
+		}
+		fmt.Fprintf(out, "Budget %s: %s:%s $%.2f per %s (%s)\n",
+			budget.ID, budget.Scope, budget.ScopeID, budget.LimitUSD, budget.Period, usageBudgetState(budget))
+		return nil
+	case "delete":
+		resumed, err := client.DeleteUsageBudget(positional[0])
+		if err != nil {
+			return err
+		}
+		if *jsonOut {
+			printJSONTo(out, map[string]any{"deleted": true, "resumed_jobs": resumed})
+			return nil
+		}
+		fmt.Fprintf(out, "Deleted budget %s (resumed %d jobs)\n", positional[0], resumed)
+		return nil
+	}
+	return errors.New(usage)
+}
+
+func usageBudgetState(budget ottercli.UsageBudget) string {
+	switch {
+	case budget.HardTrippedAt != "":
+		return "exceeded, paused"
+	case budget.SoftNotifiedAt != "":
+		return "warning"
+	case !budget.Hard:
+		return fmt.Sprintf("ok, warn at %d%%", budget.SoftPercent)
+	}
+	return fmt.Sprintf("ok, warn at %d%%, pause at 100%%", budget.SoftPercent)
+}
+
+func newUsageCommandClient(orgOverride string) (usageCommandClient, error) {
+	cfg, err := ottercli.LoadConfig()
+	if err != nil {
+		return nil, err
+	}
+	return ottercli.NewClient(cfg, orgOverride)
+}
@@ -0,0 +1,150 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/samhotchkiss/otter-camp/internal/ottercli"
+)
+
+type fakeUsageCommandClient struct {
+	filter      ottercli.UsageFilter
+	priceModel  string
+	price       float64
+	removed     string
+	budgetInput map[string]any
+	deleted     string
+}
+
+func (f *fakeUsageCommandClient) UsageReport(filter ottercli.UsageFilter) (ottercli.UsageReport, error) {
+	f.filter = filter
+	return ottercli.UsageReport{
+		From:           "2026-10-01",
+		To:             "2026-11-01",
+		GroupBy:        "project",
+		Tokens:         1_200_000,
+		CostUSD:        12.5,
+		Events:         40,
+		UnpricedTokens: 5000,
+		Items:          []ottercli.UsageReportRow{{Key: "p-1", Label: "Blog", Tokens: 1_195_000, CostUSD: 12.5, Events: 39}},
+	}, nil
+}
+
+func (f *fakeUsageCommandClient) ListModelPricing() ([]ottercli.ModelPrice, error) {
+	return []ottercli.ModelPrice{{Model: "claude-*", USDPerMillionTokens: 9}}, nil
+}
+
+func (f *fakeUsageCommandClient) SetModelPrice(model string, usd float64) (ottercli.ModelPrice, error) {
+	f.priceModel = model
+	f.price = usd
+	return ottercli.ModelPrice{Model: model, USDPerMillionTokens: usd}, nil
+}
+
+func (f *fakeUsageCommandClient) DeleteModelPrice(model string) error {
+	f.removed = model
+	return nil
+}
+
+func (f *fakeUsageCommandClient) ListUsageBudgets() ([]ottercli.UsageBudget, error) {
+	return []ottercli.UsageBudget{{ID: "b-1", Scope: "agent", ScopeID: "writer", Period: "month", LimitUSD: 20, SpentUSD: 21, Hard: true, HardTrippedAt: "2026-10-18T12:00:00Z"}}, nil
+}
+
+func (f *fakeUsageCommandClient) SetUsageBudget(input map[string]any) (ottercli.UsageBudget, error) {
+	f.budgetInput = input
+	return ottercli.UsageBudget{ID: "b-2", Scope: input["scope"].(string), ScopeID: input["scope_id"].(string), Period: "month", LimitUSD: 50, SoftPercent: 80, Hard: true}, nil
+}
+
+func (f *fakeUsageCommandClient) DeleteUsageBudget(budgetID string) (int, error) {
+	f.deleted = budgetID
+	return 3, nil
+}
+
+func (f *fakeUsageCommandClient) FindProject(query string) (ottercli.Project, error) {
+	return ottercli.Project{ID: "p-1", Name: query}, nil
+}
+
+func usageTestFactory(client *fakeUsageCommandClient) usageClientFactory {
+	return func(string) (usageCommandClient, error) { return client, nil }
+}
+
+func TestUsageReportResolvesProjectAndPrintsRows(t *testing.T) {
+	client := &fakeUsageCommandClient{}
+	var out bytes.Buffer
+	if err := runUsageCommand([]string{"--project", "Blog", "--month", "2026-10", "--by", "project"}, usageTestFactory(client), &out); err != nil {
+		t.Fatalf("runUsageCommand() error = %v", err)
+	}
+	if client.filter.ProjectID != "p-1" || client.filter.Month != "2026-10" || client.filter.GroupBy != "project" {
+		t.Fatalf("filter = %#v", client.filter)
+	}
+	for _, want := range []string{"$12.50", "Blog", "5000 tokens have no model price"} {
+		if !strings.Contains(out.String(), want) {
+			t.Fatalf("output missing %q: %q", want, out.String())
+		}
+	}
+}
+
+func TestUsagePricingSetAndRemove(t *testing.T) {
+	client := &fakeUsageCommandClient{}
+	var out bytes.Buffer
+	if err := runUsageCommand([]string{"pricing", "set", "gpt-4o*", "$2.5"}, usageTestFactory(client), &out); err != nil {
+		t.Fatalf("pricing set error = %v", err)
+	}
+	if client.priceModel != "gpt-4o*" || client.price != 2.5 {
+		t.Fatalf("price = %q %v", client.priceModel, client.price)
+	}
+	if err := runUsageCommand([]string{"pricing", "remove", "gpt-4o*"}, usageTestFactory(client), &out); err != nil || client.removed != "gpt-4o*" {
+		t.Fatalf("pricing remove = %q, %v", client.removed, err)
+	}
+	if err := runUsageCommand([]string{"pricing", "set", "gpt-4o", "cheap"}, usageTestFactory(client), &out); err == nil {
+		t.Fatalf("expected error for non-numeric price")
+	}
+}
+
+func TestUsageBudgetsSetListAndDelete(t *testing.T) {
+	client := &fakeUsageCommandClient{}
+	var out bytes.Buffer
+	if err := runUsageCommand([]string{"budgets", "set", "--agent", "writer", "--limit", "50", "--soft", "60", "--soft-only"}, usageTestFactory(client), &out); err != nil {
+		t.Fatalf("budgets set error = %v", err)
+	}
+	if client.budgetInput["scope"] != "agent" || client.budgetInput["scope_id"] != "writer" ||
+		client.budgetInput["limit_usd"] != float64(50) || client.budgetInput["soft_percent"] != 60 || client.budgetInput["hard"] != false {
+		t.Fatalf("budget input = %#v", client.budgetInput)
+	}
+
+	if err := runUsageCommand([]string{"budgets", "set", "--project", "Blog", "--limit", "10"}, usageTestFactory(client), &out); err != nil {
+		t.Fatalf("budgets set project error = %v", err)
+	}
+	if client.budgetInput["scope"] != "project" || client.budgetInput["scope_id"] != "p-1" {
+		t.Fatalf("budget input = %#v", client.budgetInput)
+	}
+	if _, ok := client.budgetInput["soft_percent"]; ok {
+		t.Fatalf("soft_percent should be omitted when unset: %#v", client.budgetInput)
+	}
+
+	for _, args := range [][]string{
+		{"budgets", "set", "--limit", "10"},
+		{"budgets", "set", "--agent", "writer", "--project", "Blog", "--limit", "10"},
+		{"budgets", "set", "--agent", "writer"},
+	} {
+		if err := runUsageCommand(args, usageTestFactory(client), &out); err == nil {
+			t.Fatalf("expected error for %v", args)
+		}
+	}
+
+	out.Reset()
+	if err := runUsageCommand([]string{"budgets", "list"}, usageTestFactory(client), &out); err != nil {
+		t.Fatalf("budgets list error = %v", err)
+	}
+	if !strings.Contains(out.String(), "agent:writer") || !strings.Contains(out.String(), "exceeded, paused") {
+		t.Fatalf("list output = %q", out.String())
+	}
+
+	out.Reset()
+	if err := runUsageCommand([]string{"budgets", "delete", "b-1"}, usageTestFactory(client), &out); err != nil {
+		t.Fatalf("budgets delete error = %v", err)
+	}
+	if client.deleted != "b-1" || !strings.Contains(out.String(), "resumed 3 jobs") {
+		t.Fatalf("delete = %q output = %q", client.deleted, out.String())
+	}
+}
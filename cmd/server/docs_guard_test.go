@@ -113,7 +113,7 @@ func TestDocsUpdatedWhenCodeChangesOnPullRequest(t *testing.T) {
 	if codeChanged && !canonicalDocsChanged {
 		t.Fatalf(
 			"docs guard failed: code files changed but canonical docs were not updated.\n"+
-				"Update docs under docs/{memories,projects,agents} or docs/START-HERE.md/docs/ISSUES-AND-OLD-CODE.md/docs/SAM-QUESTIONS.md and add a Change Log entry.\n"+
+				"Update docs under docs/{memories,projects,agents,platform} or docs/START-HERE.md/docs/ISSUES-AND-OLD-CODE.md/docs/SAM-QUESTIONS.md and add a Change Log entry.\n"+
 				"For bug fixes with no behavior change, add a log entry like: '- YYYY-MM-DD: Bug fix in <area>; no behavior change.'",
 		)
 	}
@@ -148,7 +148,7 @@ func listCanonicalDocs(repoRoot string) ([]string, error) {
 		"docs/SAM-QUESTIONS.md",
 	}
 
-	for _, dir := range []string{"docs/memories", "docs/projects", "docs/agents"} {
+	for _, dir := range []string{"docs/memories", "docs/projects", "docs/agents", "docs/platform"} {
 		entries, err := os.ReadDir(filepath.Join(repoRoot, filepath.FromSlash(dir)))
 		if err != nil {
 			return nil, err
@@ -174,7 +174,8 @@ func isCanonicalDocPath(path string) bool {
 	case "docs/START-HERE.md", "docs/ISSUES-AND-OLD-CODE.md", "docs/SAM-QUESTIONS.md":
 		return true
 	}
-	if strings.HasPrefix(path, "docs/memories/") || strings.HasPrefix(path, "docs/projects/") || strings.HasPrefix(path, "docs/agents/") {
+	if strings.HasPrefix(path, "docs/memories/") || strings.HasPrefix(path, "docs/projects/") || strings.HasPrefix(path, "docs/agents/") ||
+		strings.HasPrefix(path, "docs/platform/") {
 		return strings.HasSuffix(strings.ToLower(path), ".md")
 	}
 	return false
@@ -216,6 +217,7 @@ func hasAddedCanonicalChangeLogEntry(repoRoot, originBaseRef string) (bool, erro
 		"docs/SAM-QUESTIONS.md",
 		"docs/agents",
 		"docs/memories",
+		"docs/platform",
 		"docs/projects",
 	)
 	if err != nil {
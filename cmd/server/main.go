@@ -21,6 +21,7 @@ import (
 	"github.com/samhotchkiss/otter-camp/internal/migration"
 	"github.com/samhotchkiss/otter-camp/internal/scheduler"
 	"github.com/samhotchkiss/otter-camp/internal/store"
+	"github.com/samhotchkiss/otter-camp/internal/tracing"
 )
 
 var (
@@ -51,6 +52,19 @@ func main() {
 	if err != nil {
 		log.Fatalf("invalid configuration: %v", err)
 	}
+	shutdownTracing, err := tracing.Setup(tracing.Config{
+		ServiceName:  cfg.Tracing.ServiceName,
+		OTLPEndpoint: cfg.Tracing.OTLPEndpoint,
+		OTLPHeaders:  cfg.Tracing.OTLPHeaders,
+		FilePath:     cfg.Tracing.FilePath,
+		SampleRatio:  cfg.Tracing.SampleRatio,
+	})
+	if err != nil {
+		log.Fatalf("invalid tracing configuration: %v", err)
+	}
+	if tracing.Enabled() {
+		log.Printf("🔭 Tracing enabled: otlp=%q file=%q sample_ratio=%v", cfg.Tracing.OTLPEndpoint, cfg.Tracing.FilePath, cfg.Tracing.SampleRatio)
+	}
 	signalCtx, stopSignals := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
 	defer stopSignals()
 
@@ -246,6 +260,7 @@ func main() {
 					},
 				)
 				startWorker(worker.Start)
+				api.RegisterIssueEmbedder(embedder)
 				log.Printf(
 					"✅ Conversation embedding worker started (provider=%s model=%s batch=%d interval=%s)",
 					cfg.ConversationEmbedding.Provider,
@@ -391,6 +406,15 @@ func main() {
 		} else if strings.TrimSpace(cfg.OrgID) == "" {
 			log.Printf("⚠️  Agent job scheduler worker disabled; OTTER_ORG_ID is not configured")
 		} else {
+			classifierCaller := memory.NewOpenClawGatewayCallerFromEnv()
+			if openClawHandler := api.OpenClawHandlerForRuntime(); openClawHandler != nil {
+				bridgeRunner, runnerErr := memory.NewOpenClawGatewayCallBridgeRunner(openClawHandler)
+				if runnerErr != nil {
+					log.Printf("⚠️  Agent job success classifier bridge runner disabled; init failed: %v", runnerErr)
+				} else {
+					classifierCaller.SetBridgeRunner(bridgeRunner)
+				}
+			}
 			worker := scheduler.NewAgentJobWorker(
 				store.NewAgentJobStore(db),
 				scheduler.AgentJobWorkerConfig{
@@ -399,6 +423,7 @@ func main() {
 					RunTimeout:    cfg.JobScheduler.RunTimeout,
 					MaxRunHistory: cfg.JobScheduler.MaxRunHistory,
 					WorkspaceID:   cfg.OrgID,
+					Classifier:    &scheduler.OpenClawRunClassifier{Caller: classifierCaller},
 				},
 			)
 			worker.Logf = log.Printf
@@ -410,6 +435,30 @@ func main() {
 				cfg.JobScheduler.RunTimeout,
 				cfg.JobScheduler.MaxRunHistory,
 			)
+
+			recurringWorker := scheduler.NewRecurringIssueWorker(
+				store.NewRecurringIssueStore(db),
+				&scheduler.RecurringIssueGenerator{
+					Issues:    store.NewProjectIssueStore(db),
+					Flows:     store.NewProjectFlowStore(db),
+					Templates: store.NewIssueTemplateStore(db),
+					Labels:    store.NewLabelStore(db),
+					Logf:      log.Printf,
+				},
+				scheduler.RecurringIssueWorkerConfig{
+					PollInterval: cfg.JobScheduler.PollInterval,
+					MaxPerPoll:   cfg.JobScheduler.MaxPerPoll,
+					RunTimeout:   cfg.JobScheduler.RunTimeout,
+					WorkspaceID:  cfg.OrgID,
+				},
+			)
+			recurringWorker.Logf = log.Printf
+			startWorker(recurringWorker.Start)
+			log.Printf(
+				"✅ Recurring issue worker started (interval=%s max_per_poll=%d)",
+				cfg.JobScheduler.PollInterval,
+				cfg.JobScheduler.MaxPerPoll,
+			)
 		}
 	}
 
@@ -437,6 +486,12 @@ func main() {
 	}
 	cancelWorkers()
 	workerWG.Wait()
+
+	flushCtx, cancelFlush := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancelFlush()
+	if err := shutdownTracing(flushCtx); err != nil {
+		log.Printf("⚠️  Trace flush failed: %v", err)
+	}
 }
 
 func startWorkerWithRecovery(
@@ -670,6 +670,20 @@ func (h *AdminAgentsHandler) Create(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	recordAudit(r, auditEvent{
+		OrgID:      workspaceID,
+		Action:     "agent.create",
+		TargetType: "agent",
+		TargetID:   createdAgent.ID,
+		After: map[string]any{
+			"slug":         createdAgent.Slug,
+			"display_name": createdAgent.DisplayName,
+			"model":        req.Model,
+			"is_ephemeral": createdAgent.IsEphemeral,
+			"project_id":   createdAgent.ProjectID,
+		},
+	})
+
 	// Agent created in DB + files written to Agent Files repo.
 	// Chameleon handles identity routing at runtime — no openclaw.json patch needed.
 	sendJSON(w, http.StatusCreated, map[string]interface{}{
@@ -717,6 +731,14 @@ func (h *AdminAgentsHandler) Retire(w http.ResponseWriter, r *http.Request) {
 		sendJSON(w, http.StatusInternalServerError, errorResponse{Error: "failed to update agent status"})
 		return
 	}
+	recordAudit(r, auditEvent{
+		OrgID:      workspaceID,
+		Action:     "agent.retire",
+		TargetType: "agent",
+		TargetID:   row.WorkspaceAgentID,
+		Before:     map[string]string{"slug": row.Slug, "status": row.WorkspaceStatus},
+		After:      map[string]string{"slug": row.Slug, "status": "retired"},
+	})
 	if !shouldDispatchOpenClawAgentConfigMutation(row.Slug) {
 		sendJSON(w, http.StatusOK, map[string]interface{}{
 			"ok":                       true,
@@ -770,7 +792,7 @@ func (h *AdminAgentsHandler) RetireByProject(w http.ResponseWriter, r *http.Requ
 
 	rows, err := h.DB.QueryContext(
 		r.Context(),
-		`SELECT slug
+		`SELECT id, slug
 		   FROM agents
 		  WHERE org_id = $1
 		    AND project_id = $2
@@ -793,8 +815,8 @@ func (h *AdminAgentsHandler) RetireByProject(w http.ResponseWriter, r *http.Requ
 	}
 
 	for rows.Next() {
-		var slug string
-		if err := rows.Scan(&slug); err != nil {
+		var agentID, slug string
+		if err := rows.Scan(&agentID, &slug); err != nil {
 			sendJSON(w, http.StatusInternalServerError, errorResponse{Error: "failed to read project agents"})
 			return
 		}
@@ -836,6 +858,13 @@ func (h *AdminAgentsHandler) RetireByProject(w http.ResponseWriter, r *http.Requ
 			continue
 		}
 
+		recordAudit(r, auditEvent{
+			OrgID:      workspaceID,
+			Action:     "agent.retire",
+			TargetType: "agent",
+			TargetID:   agentID,
+			After:      map[string]string{"slug": slug, "status": "retired", "project_id": projectID},
+		})
 		result.Status = "retired"
 		response.Retired++
 		response.Results = append(response.Results, result)
@@ -876,6 +905,14 @@ func (h *AdminAgentsHandler) Reactivate(w http.ResponseWriter, r *http.Request)
 		sendJSON(w, http.StatusInternalServerError, errorResponse{Error: "failed to update agent status"})
 		return
 	}
+	recordAudit(r, auditEvent{
+		OrgID:      workspaceID,
+		Action:     "agent.reactivate",
+		TargetType: "agent",
+		TargetID:   row.WorkspaceAgentID,
+		Before:     map[string]string{"slug": row.Slug, "status": row.WorkspaceStatus},
+		After:      map[string]string{"slug": row.Slug, "status": "active"},
+	})
 	if !shouldDispatchOpenClawAgentConfigMutation(row.Slug) {
 		sendJSON(w, http.StatusOK, map[string]interface{}{
 			"ok":                       true,
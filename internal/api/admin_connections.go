@@ -731,6 +731,7 @@ func (h *AdminConnectionsHandler) dispatchAdminCommand(
 
 	if h.OpenClawHandler == nil {
 		if queuedForRetry {
+			auditAdminCommand(r, workspaceID, event.Data, true)
 			h.logConnectionEventBestEffort(r.Context(), workspaceID, store.CreateConnectionEventInput{
 				EventType: "admin.command.queued",
 				Severity:  store.ConnectionEventSeverityWarning,
@@ -753,6 +754,7 @@ func (h *AdminConnectionsHandler) dispatchAdminCommand(
 	if err := h.OpenClawHandler.SendToOpenClaw(event); err != nil {
 		if queuedForRetry {
 			metadata["error"] = err.Error()
+			auditAdminCommand(r, workspaceID, event.Data, true)
 			h.logConnectionEventBestEffort(r.Context(), workspaceID, store.CreateConnectionEventInput{
 				EventType: "admin.command.queued",
 				Severity:  store.ConnectionEventSeverityWarning,
@@ -779,6 +781,7 @@ func (h *AdminConnectionsHandler) dispatchAdminCommand(
 	if queuedForRetry {
 		_ = markOpenClawDispatchDeliveredByKey(r.Context(), h.DB, dedupeKey)
 	}
+	auditAdminCommand(r, workspaceID, event.Data, false)
 	h.logConnectionEventBestEffort(r.Context(), workspaceID, store.CreateConnectionEventInput{
 		EventType: "admin.command.dispatched",
 		Severity:  store.ConnectionEventSeverityInfo,
@@ -795,6 +798,41 @@ func (h *AdminConnectionsHandler) dispatchAdminCommand(
 	})
 }
 
+// auditAdminCommand records an accepted command. Config payloads are
+// recorded by hash only; they can carry credentials.
+func auditAdminCommand(r *http.Request, workspaceID string, data openClawAdminCommandData, queued bool) {
+	event := auditEvent{OrgID: workspaceID, Action: data.Action}
+	after := map[string]interface{}{"command_id": data.CommandID, "queued": queued}
+	switch {
+	case data.AgentID != "":
+		event.TargetType, event.TargetID = "agent", data.AgentID
+	case data.JobID != "":
+		event.TargetType, event.TargetID = "cron_job", data.JobID
+	case data.ProcessID != "":
+		event.TargetType, event.TargetID = "process", data.ProcessID
+	case strings.HasPrefix(data.Action, "config."):
+		event.TargetType = "openclaw_config"
+	default:
+		event.TargetType = "gateway"
+	}
+	if data.Enabled != nil {
+		after["enabled"] = *data.Enabled
+	}
+	if data.ConfigHash != "" {
+		event.Before = map[string]string{"config_hash": data.ConfigHash}
+	}
+	for key, payload := range map[string]json.RawMessage{"patch_hash": data.ConfigPatch, "config_hash": data.ConfigFull} {
+		if len(payload) == 0 {
+			continue
+		}
+		if hash, err := hashCanonicalJSONRaw(payload); err == nil {
+			after[key] = hash
+		}
+	}
+	event.After = after
+	recordAudit(r, event)
+}
+
 func (h *AdminConnectionsHandler) logConnectionEventBestEffort(
 	ctx context.Context,
 	workspaceID string,
@@ -888,6 +926,10 @@ func (h *AdminConnectionsHandler) RunDiagnostics(w http.ResponseWriter, r *http.
 		}
 	}
 
+	if check, ok := h.rateLimitDiagnostic(r.Context()); ok {
+		checks = append(checks, check)
+	}
+
 	host := h.loadHostDiagnostics(r.Context())
 	if host == nil || host.MemoryTotalBytes <= 0 {
 		checks = append(checks, adminDiagnosticCheck{
@@ -955,6 +997,39 @@ func (h *AdminConnectionsHandler) RunDiagnostics(w http.ResponseWriter, r *http.
 	})
 }
 
+// rateLimitDiagnostic warns when any of the workspace's callers hit a rate
+// limit in the last hour.
+func (h *AdminConnectionsHandler) rateLimitDiagnostic(ctx context.Context) (adminDiagnosticCheck, bool) {
+	workspaceID := middleware.WorkspaceFromContext(ctx)
+	if h.DB == nil || workspaceID == "" {
+		return adminDiagnosticCheck{}, false
+	}
+	throttled, err := store.NewRateLimitStore(h.DB).ListThrottled(ctx, workspaceID, time.Now().Add(-time.Hour), 0)
+	if err != nil {
+		return adminDiagnosticCheck{
+			Key:     "api.rate_limits",
+			Status:  "warn",
+			Message: "Rate limit diagnostics unavailable",
+		}, true
+	}
+	if len(throttled) == 0 {
+		return adminDiagnosticCheck{
+			Key:     "api.rate_limits",
+			Status:  "pass",
+			Message: "No API callers throttled in the last hour",
+		}, true
+	}
+	latest := throttled[0]
+	return adminDiagnosticCheck{
+		Key:    "api.rate_limits",
+		Status: "warn",
+		Message: fmt.Sprintf(
+			"%d API caller buckets throttled in the last hour (latest: %s on %s, %d rejected)",
+			len(throttled), latest.Subject, latest.RouteGroup, latest.ThrottledTotal,
+		),
+	}, true
+}
+
 func (h *AdminConnectionsHandler) GetLogs(w http.ResponseWriter, r *http.Request) {
 	if h.EventStore == nil {
 		sendJSON(w, http.StatusServiceUnavailable, errorResponse{Error: "connection event store unavailable"})
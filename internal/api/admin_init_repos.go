@@ -69,6 +69,14 @@ func HandleAdminInitRepos(db *sql.DB) http.HandlerFunc {
 			results = append(results, result)
 		}
 
+		recordAudit(r, auditEvent{
+			OrgID:      orgID,
+			Action:     "admin.init_repos",
+			TargetType: "workspace",
+			TargetID:   orgID,
+			After:      map[string]any{"initialized": len(results)},
+		})
+
 		w.Header().Set("Content-Type", "application/json")
 		json.NewEncoder(w).Encode(map[string]any{
 			"initialized": len(results),
@@ -7,6 +7,7 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"log"
 	"net/http"
 	"net/url"
 	"regexp"
@@ -51,6 +52,9 @@ type AgentActivityHandler struct {
 	DB    *sql.DB
 	Store *store.AgentActivityEventStore
 	Hub   *ws.Hub
+	// UsageStore, when set, reprices the days ingested events fall on and
+	// re-evaluates usage budgets.
+	UsageStore *store.UsageStore
 
 	storeOnce sync.Once
 	storeErr  error
@@ -226,6 +230,8 @@ func (h *AgentActivityHandler) IngestEvents(w http.ResponseWriter, r *http.Reque
 		return
 	}
 
+	h.recordUsage(ctx, req.OrgID, createInputs)
+
 	h.broadcastRealtimeActivityEvents(req.OrgID, createInputs, time.Now().UTC())
 
 	sendJSON(w, http.StatusOK, ingestAgentActivityEventsResponse{
@@ -235,6 +241,34 @@ func (h *AgentActivityHandler) IngestEvents(w http.ResponseWriter, r *http.Reque
 	})
 }
 
+// recordUsage rebuilds the usage rollups for each day the events started on
+// and applies any budget thresholds they cross. The events are already
+// stored, so failures are logged rather than failing the ingest.
+func (h *AgentActivityHandler) recordUsage(ctx context.Context, orgID string, events []store.CreateAgentActivityEventInput) {
+	if h == nil || h.UsageStore == nil {
+		return
+	}
+	days := make([]time.Time, 0, 1)
+	seen := make(map[string]struct{})
+	for _, event := range events {
+		day := event.StartedAt.UTC().Truncate(24 * time.Hour)
+		key := day.Format(time.DateOnly)
+		if _, ok := seen[key]; ok {
+			continue
+		}
+		seen[key] = struct{}{}
+		days = append(days, day)
+	}
+	for _, day := range days {
+		if err := h.UsageStore.RefreshRollups(ctx, orgID, day, day); err != nil {
+			log.Printf("[usage] failed to refresh rollups for org %s on %s: %v", orgID, day.Format(time.DateOnly), err)
+		}
+	}
+	if err := evaluateUsageBudgets(ctx, h.UsageStore, h.DB, h.Hub, orgID); err != nil {
+		log.Printf("[usage] failed to evaluate budgets for org %s: %v", orgID, err)
+	}
+}
+
 type activityEventBroadcastEnvelope struct {
 	Type string                 `json:"type"`
 	Data map[string]interface{} `json:"data"`
@@ -0,0 +1,304 @@
+package api
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/samhotchkiss/otter-camp/internal/middleware"
+	"github.com/samhotchkiss/otter-camp/internal/store"
+)
+
+const (
+	agentReviewFormatJSON     = "json"
+	agentReviewFormatMarkdown = "markdown"
+	agentReviewFormatCSV      = "csv"
+)
+
+// AgentReviewHandler serves per-agent performance scorecards.
+type AgentReviewHandler struct {
+	Store *store.AgentReviewStore
+}
+
+// Get handles GET /api/agents/{id}/review?since=30d&periods=2&format=json|markdown|csv
+func (h *AgentReviewHandler) Get(w http.ResponseWriter, r *http.Request) {
+	if h.Store == nil {
+		sendJSON(w, http.StatusServiceUnavailable, errorResponse{Error: "database not available"})
+		return
+	}
+	orgID := middleware.WorkspaceFromContext(r.Context())
+	if orgID == "" {
+		sendJSON(w, http.StatusUnauthorized, errorResponse{Error: "authentication required"})
+		return
+	}
+
+	query := r.URL.Query()
+	days, err := parseAgentReviewSince(query.Get("since"))
+	if err != nil {
+		sendJSON(w, http.StatusBadRequest, errorResponse{Error: err.Error()})
+		return
+	}
+	periods := 0
+	if raw := strings.TrimSpace(query.Get("periods")); raw != "" {
+		if periods, err = strconv.Atoi(raw); err != nil || periods < 1 {
+			sendJSON(w, http.StatusBadRequest, errorResponse{Error: "periods must be a positive integer"})
+			return
+		}
+	}
+	format := strings.ToLower(strings.TrimSpace(query.Get("format")))
+	switch format {
+	case "", agentReviewFormatJSON:
+		format = agentReviewFormatJSON
+	case "md", agentReviewFormatMarkdown:
+		format = agentReviewFormatMarkdown
+	case agentReviewFormatCSV:
+	default:
+		sendJSON(w, http.StatusBadRequest, errorResponse{Error: "format must be json, markdown or csv"})
+		return
+	}
+
+	card, err := h.Store.Scorecard(r.Context(), orgID, store.AgentScorecardInput{
+		AgentID: chi.URLParam(r, "id"),
+		End:     time.Now(),
+		Days:    days,
+		Periods: periods,
+	})
+	if err != nil {
+		handleJobsStoreError(w, err)
+		return
+	}
+
+	switch format {
+	case agentReviewFormatMarkdown:
+		w.Header().Set("Content-Type", "text/markdown; charset=utf-8")
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write([]byte(renderAgentScorecardMarkdown(card)))
+	case agentReviewFormatCSV:
+		body, err := renderAgentScorecardCSV(card)
+		if err != nil {
+			sendJSON(w, http.StatusInternalServerError, errorResponse{Error: "failed to render report"})
+			return
+		}
+		filename := fmt.Sprintf("review-%s-%s.csv", card.Agent.Slug, card.Periods[0].To.AddDate(0, 0, -1).Format("20060102"))
+		w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+		w.Header().Set("Content-Type", "text/csv; charset=utf-8")
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write(body)
+	default:
+		sendJSON(w, http.StatusOK, card)
+	}
+}
+
+// parseAgentReviewSince reads a window such as "30d", "4w" or "14" (days).
+func parseAgentReviewSince(raw string) (int, error) {
+	raw = strings.ToLower(strings.TrimSpace(raw))
+	if raw == "" {
+		return 0, nil
+	}
+	multiplier := 1
+	if number, ok := strings.CutSuffix(raw, "w"); ok {
+		raw, multiplier = number, 7
+	} else {
+		raw = strings.TrimSuffix(raw, "d")
+	}
+	n, err := strconv.Atoi(raw)
+	if err != nil || n < 1 {
+		return 0, fmt.Errorf("since must look like 30d or 4w")
+	}
+	return n * multiplier, nil
+}
+
+type agentScorecardMetric struct {
+	key   string
+	label string
+	kind  string
+	value func(store.AgentScorecardPeriod) (float64, bool)
+}
+
+const (
+	agentMetricCount    = "count"
+	agentMetricPercent  = "percent"
+	agentMetricRatio    = "ratio"
+	agentMetricDuration = "duration"
+	agentMetricUSD      = "usd"
+)
+
+func agentMetricInt(get func(store.AgentScorecardPeriod) int) func(store.AgentScorecardPeriod) (float64, bool) {
+	return func(p store.AgentScorecardPeriod) (float64, bool) { return float64(get(p)), true }
+}
+
+func agentMetricOptional(get func(store.AgentScorecardPeriod) *float64) func(store.AgentScorecardPeriod) (float64, bool) {
+	return func(p store.AgentScorecardPeriod) (float64, bool) {
+		if v := get(p); v != nil {
+			return *v, true
+		}
+		return 0, false
+	}
+}
+
+var agentScorecardMetrics = []agentScorecardMetric{
+	{"issues_worked", "Issues worked", agentMetricCount, agentMetricInt(func(p store.AgentScorecardPeriod) int { return p.IssuesWorked })},
+	{"steps_completed", "Steps completed", agentMetricCount, agentMetricInt(func(p store.AgentScorecardPeriod) int { return p.StepsCompleted })},
+	{"issues_closed", "Owned issues closed", agentMetricCount, agentMetricInt(func(p store.AgentScorecardPeriod) int { return p.IssuesClosed })},
+	{"first_pass_acceptance_rate", "First-pass acceptance", agentMetricPercent, agentMetricOptional(func(p store.AgentScorecardPeriod) *float64 { return p.FirstPassAcceptanceRate })},
+	{"rejections", "Rejections", agentMetricCount, agentMetricInt(func(p store.AgentScorecardPeriod) int { return p.Rejections })},
+	{"avg_rejections_per_issue", "Rejections per issue", agentMetricRatio, func(p store.AgentScorecardPeriod) (float64, bool) {
+		return p.AvgRejectionsPerIssue, p.IssuesWorked > 0
+	}},
+	{"avg_step_seconds", "Avg time in step", agentMetricDuration, agentMetricOptional(func(p store.AgentScorecardPeriod) *float64 { return p.AvgStepSeconds })},
+	{"reviews_received", "Review rounds received", agentMetricCount, agentMetricInt(func(p store.AgentScorecardPeriod) int { return p.ReviewsReceived })},
+	{"reviews_addressed", "Review rounds addressed", agentMetricCount, agentMetricInt(func(p store.AgentScorecardPeriod) int { return p.ReviewsAddressed })},
+	{"avg_review_turnaround_seconds", "Review feedback turnaround", agentMetricDuration, agentMetricOptional(func(p store.AgentScorecardPeriod) *float64 { return p.AvgReviewTurnaroundSeconds })},
+	{"flow_blockers_raised", "Flow blockers raised", agentMetricCount, agentMetricInt(func(p store.AgentScorecardPeriod) int { return p.FlowBlockersRaised })},
+	{"compliance_failures", "Compliance failures", agentMetricCount, agentMetricInt(func(p store.AgentScorecardPeriod) int { return p.ComplianceFailures })},
+	{"runs", "Runs", agentMetricCount, agentMetricInt(func(p store.AgentScorecardPeriod) int { return p.Runs })},
+	{"failed_runs", "Failed runs", agentMetricCount, agentMetricInt(func(p store.AgentScorecardPeriod) int { return p.FailedRuns })},
+	{"avg_run_seconds", "Avg run duration", agentMetricDuration, agentMetricOptional(func(p store.AgentScorecardPeriod) *float64 { return p.AvgRunSeconds })},
+	{"tokens", "Tokens", agentMetricCount, func(p store.AgentScorecardPeriod) (float64, bool) { return float64(p.Tokens), true }},
+	{"cost_usd", "Cost", agentMetricUSD, func(p store.AgentScorecardPeriod) (float64, bool) { return p.CostUSD, true }},
+}
+
+func (m agentScorecardMetric) format(v float64) string {
+	switch m.kind {
+	case agentMetricPercent:
+		return fmt.Sprintf("%.0f%%", v*100)
+	case agentMetricRatio:
+		return fmt.Sprintf("%.2f", v)
+	case agentMetricDuration:
+		return formatAgentReviewDuration(v)
+	case agentMetricUSD:
+		return fmt.Sprintf("$%.2f", v)
+	}
+	return strconv.FormatInt(int64(math.Round(v)), 10)
+}
+
+func (m agentScorecardMetric) delta(current, previous store.AgentScorecardPeriod) string {
+	cur, okCur := m.value(current)
+	prev, okPrev := m.value(previous)
+	if !okCur || !okPrev {
+		return ""
+	}
+	diff := cur - prev
+	if math.Abs(diff) < 1e-9 {
+		return "="
+	}
+	sign := "+"
+	if diff < 0 {
+		sign = "-"
+	}
+	if m.kind == agentMetricPercent {
+		return fmt.Sprintf("%s%.0f pts", sign, math.Abs(diff)*100)
+	}
+	return sign + m.format(math.Abs(diff))
+}
+
+func formatAgentReviewDuration(seconds float64) string {
+	d := time.Duration(seconds * float64(time.Second)).Round(time.Second)
+	switch {
+	case d >= 24*time.Hour:
+		return fmt.Sprintf("%.1fd", d.Hours()/24)
+	case d >= time.Hour:
+		return fmt.Sprintf("%dh%02dm", int(d.Hours()), int(d.Minutes())%60)
+	case d >= time.Minute:
+		return fmt.Sprintf("%dm%02ds", int(d.Minutes()), int(d.Seconds())%60)
+	}
+	return fmt.Sprintf("%ds", int(d.Seconds()))
+}
+
+func agentReviewPeriodLabel(p store.AgentScorecardPeriod) string {
+	return p.From.Format("2006-01-02") + " to " + p.To.AddDate(0, 0, -1).Format("2006-01-02")
+}
+
+func renderAgentScorecardMarkdown(card *store.AgentScorecard) string {
+	var b strings.Builder
+	name := card.Agent.DisplayName
+	if name == "" {
+		name = card.Agent.Slug
+	}
+	fmt.Fprintf(&b, "# Performance review: %s (%s)\n\n", name, card.Agent.Slug)
+	if len(card.Periods) == 0 {
+		return b.String()
+	}
+	current := card.Periods[0]
+	fmt.Fprintf(&b, "Window: %s (%d days)", agentReviewPeriodLabel(current), card.Days)
+	if len(card.Periods) > 1 {
+		fmt.Fprintf(&b, ", compared with the %d previous window(s)", len(card.Periods)-1)
+	}
+	b.WriteString(".\n\n")
+
+	b.WriteString("| Metric |")
+	for _, p := range card.Periods {
+		fmt.Fprintf(&b, " %s |", agentReviewPeriodLabel(p))
+	}
+	if len(card.Periods) > 1 {
+		b.WriteString(" Change |")
+	}
+	b.WriteString("\n|---|")
+	for range card.Periods {
+		b.WriteString("---:|")
+	}
+	if len(card.Periods) > 1 {
+		b.WriteString("---:|")
+	}
+	b.WriteString("\n")
+	for _, metric := range agentScorecardMetrics {
+		fmt.Fprintf(&b, "| %s |", metric.label)
+		for _, p := range card.Periods {
+			value := "–"
+			if v, ok := metric.value(p); ok {
+				value = metric.format(v)
+			}
+			fmt.Fprintf(&b, " %s |", value)
+		}
+		if len(card.Periods) > 1 {
+			fmt.Fprintf(&b, " %s |", metric.delta(current, card.Periods[1]))
+		}
+		b.WriteString("\n")
+	}
+
+	if len(current.Steps) > 0 {
+		b.WriteString("\n## Time in step\n\n| Step | Type | Completed | Avg time |\n|---|---|---:|---:|\n")
+		for _, step := range current.Steps {
+			avg := "–"
+			if step.AvgSeconds != nil {
+				avg = formatAgentReviewDuration(*step.AvgSeconds)
+			}
+			fmt.Fprintf(&b, "| %s | %s | %d | %s |\n", step.StepName, step.StepType, step.Completed, avg)
+		}
+	}
+	return b.String()
+}
+
+// renderAgentScorecardCSV writes one row per period, most recent first.
+func renderAgentScorecardCSV(card *store.AgentScorecard) ([]byte, error) {
+	var buf bytes.Buffer
+	writer := csv.NewWriter(&buf)
+	header := []string{"agent", "from", "to"}
+	for _, metric := range agentScorecardMetrics {
+		header = append(header, metric.key)
+	}
+	if err := writer.Write(header); err != nil {
+		return nil, err
+	}
+	for _, p := range card.Periods {
+		record := []string{card.Agent.Slug, p.From.Format("2006-01-02"), p.To.Format("2006-01-02")}
+		for _, metric := range agentScorecardMetrics {
+			value := ""
+			if v, ok := metric.value(p); ok {
+				value = strconv.FormatFloat(v, 'f', -1, 64)
+			}
+			record = append(record, value)
+		}
+		if err := writer.Write(record); err != nil {
+			return nil, err
+		}
+	}
+	writer.Flush()
+	return buf.Bytes(), writer.Error()
+}
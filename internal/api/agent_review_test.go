@@ -0,0 +1,120 @@
+package api
+
+import (
+	"context"
+	"encoding/csv"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/samhotchkiss/otter-camp/internal/middleware"
+	"github.com/samhotchkiss/otter-camp/internal/store"
+	"github.com/stretchr/testify/require"
+)
+
+func agentReviewTestScorecard() *store.AgentScorecard {
+	rate := 0.75
+	previousRate := 0.5
+	stepSeconds := 5400.0
+	return &store.AgentScorecard{
+		Agent: store.AgentScorecardAgent{ID: "a-1", Slug: "writer", DisplayName: "Writer"},
+		Days:  30,
+		Periods: []store.AgentScorecardPeriod{
+			{
+				From:                    time.Date(2026, 9, 19, 0, 0, 0, 0, time.UTC),
+				To:                      time.Date(2026, 10, 19, 0, 0, 0, 0, time.UTC),
+				IssuesWorked:            4,
+				StepsCompleted:          6,
+				FirstPassAcceptanceRate: &rate,
+				Rejections:              1,
+				AvgRejectionsPerIssue:   0.25,
+				AvgStepSeconds:          &stepSeconds,
+				Steps:                   []store.AgentScorecardStep{{StepName: "Write", StepType: "agent_work", Completed: 6, AvgSeconds: &stepSeconds}},
+				CostUSD:                 12.5,
+			},
+			{
+				From:                    time.Date(2026, 8, 20, 0, 0, 0, 0, time.UTC),
+				To:                      time.Date(2026, 9, 19, 0, 0, 0, 0, time.UTC),
+				IssuesWorked:            2,
+				StepsCompleted:          2,
+				FirstPassAcceptanceRate: &previousRate,
+				Rejections:              1,
+				AvgRejectionsPerIssue:   0.5,
+				CostUSD:                 20,
+			},
+		},
+	}
+}
+
+func TestRenderAgentScorecardMarkdown(t *testing.T) {
+	report := renderAgentScorecardMarkdown(agentReviewTestScorecard())
+	for _, want := range []string{
+		"# Performance review: Writer (writer)",
+		"Window: 2026-09-19 to 2026-10-18 (30 days), compared with the 1 previous window(s).",
+		"| First-pass acceptance | 75% | 50% | +25 pts |",
+		"| Issues worked | 4 | 2 | +2 |",
+		"| Rejections | 1 | 1 | = |",
+		"| Avg time in step | 1h30m | – |  |",
+		"| Cost | $12.50 | $20.00 | -$7.50 |",
+		"| Write | agent_work | 6 | 1h30m |",
+	} {
+		require.Contains(t, report, want)
+	}
+}
+
+func TestRenderAgentScorecardCSV(t *testing.T) {
+	body, err := renderAgentScorecardCSV(agentReviewTestScorecard())
+	require.NoError(t, err)
+	records, err := csv.NewReader(strings.NewReader(string(body))).ReadAll()
+	require.NoError(t, err)
+	require.Len(t, records, 3)
+	require.Equal(t, []string{"agent", "from", "to", "issues_worked"}, records[0][:4])
+	require.Equal(t, len(records[0]), len(records[1]))
+
+	column := func(key string) int {
+		for i, name := range records[0] {
+			if name == key {
+				return i
+			}
+		}
+		t.Fatalf("missing column %s", key)
+		return -1
+	}
+	require.Equal(t, "2026-09-19", records[1][1])
+	require.Equal(t, "0.75", records[1][column("first_pass_acceptance_rate")])
+	require.Equal(t, "5400", records[1][column("avg_step_seconds")])
+	require.Equal(t, "", records[2][column("avg_step_seconds")])
+}
+
+func TestParseAgentReviewSince(t *testing.T) {
+	for raw, want := range map[string]int{"": 0, "30d": 30, "14": 14, "4w": 28, " 7D ": 7} {
+		got, err := parseAgentReviewSince(raw)
+		require.NoError(t, err, raw)
+		require.Equal(t, want, got, raw)
+	}
+	for _, raw := range []string{"0d", "-3d", "month", "3h"} {
+		_, err := parseAgentReviewSince(raw)
+		require.Error(t, err, raw)
+	}
+}
+
+func TestAgentReviewHandlerValidatesRequest(t *testing.T) {
+	rec := httptest.NewRecorder()
+	(&AgentReviewHandler{}).Get(rec, httptest.NewRequest(http.MethodGet, "/api/agents/writer/review", nil))
+	require.Equal(t, http.StatusServiceUnavailable, rec.Code)
+
+	handler := &AgentReviewHandler{Store: store.NewAgentReviewStore(nil)}
+	rec = httptest.NewRecorder()
+	handler.Get(rec, httptest.NewRequest(http.MethodGet, "/api/agents/writer/review", nil))
+	require.Equal(t, http.StatusUnauthorized, rec.Code)
+
+	for _, query := range []string{"since=soon", "periods=0", "format=pdf"} {
+		req := httptest.NewRequest(http.MethodGet, "/api/agents/writer/review?"+query, nil)
+		req = req.WithContext(context.WithValue(req.Context(), middleware.WorkspaceIDKey, "00000000-0000-0000-0000-000000000001"))
+		rec = httptest.NewRecorder()
+		handler.Get(rec, req)
+		require.Equal(t, http.StatusBadRequest, rec.Code, query)
+	}
+}
@@ -0,0 +1,340 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"io"
+	"log"
+	"net"
+	"net/http"
+	"os"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/samhotchkiss/otter-camp/internal/middleware"
+	"github.com/samhotchkiss/otter-camp/internal/store"
+)
+
+type apiKeyContextKey struct{}
+
+const apiKeyBodyPeekLimit = 1 << 20
+
+type apiKeyErrorResponse struct {
+	Error string `json:"error"`
+	Scope string `json:"scope,omitempty"`
+}
+
+// apiKeyScopeCapabilities maps write scopes to the capability a user needs
+// to mint a key carrying them. Read scopes need none.
+var apiKeyScopeCapabilities = map[string]string{
+	"projects:write":  CapabilityProjectsWrite,
+	"issues:write":    CapabilityIssuesWrite,
+	"pipelines:write": CapabilityPipelinesManage,
+	"memory:write":    CapabilityMemoryWrite,
+	"jobs:write":      CapabilityJobsManage,
+	"jobs:run":        CapabilityJobsManage,
+	"activity:write":  CapabilityAgentsManage,
+}
+
+// apiKeyRoute maps /api paths to a scope resource. The first match wins and
+// paths without a rule are closed to API keys. Submatch indexes name the
+// project, issue or agent a request acts on.
+type apiKeyRoute struct {
+	pattern  *regexp.Regexp
+	resource string
+	run      bool
+	project  int
+	issue    int
+	agent    int
+}
+
+var apiKeyRoutes = []apiKeyRoute{
+	{pattern: regexp.MustCompile(`^/v1/jobs/[^/]+/(run|pause|resume)$`), resource: "jobs", run: true},
+	{pattern: regexp.MustCompile(`^/v1/jobs/[^/]+/runs/[^/]+/reply$`), resource: "jobs", run: true},
+	{pattern: regexp.MustCompile(`^/v1/jobs(/.*)?$`), resource: "jobs"},
+	{pattern: regexp.MustCompile(`^/projects/([^/]+)/(issues|tasks)(/.*)?$`), resource: "issues", project: 1},
+	{pattern: regexp.MustCompile(`^/projects/([^/]+)/(pipeline-roles|pipeline-steps|pipeline-staffing|flow-templates|deploy-config)(/.*)?$`), resource: "pipelines", project: 1},
+	{pattern: regexp.MustCompile(`^/projects/([^/]+)(/.*)?$`), resource: "projects", project: 1},
+	{pattern: regexp.MustCompile(`^/projects$`), resource: "projects"},
+	{pattern: regexp.MustCompile(`^/issues/bulk$`), resource: "issues"},
+	{pattern: regexp.MustCompile(`^/(issues|project-tasks)/([^/]+)(/.*)?$`), resource: "issues", issue: 2},
+	{pattern: regexp.MustCompile(`^/(issues|project-tasks)$`), resource: "issues"},
+	{pattern: regexp.MustCompile(`^/workflows/([^/]+)(/.*)?$`), resource: "pipelines", project: 1},
+	{pattern: regexp.MustCompile(`^/workflows$`), resource: "pipelines"},
+	{pattern: regexp.MustCompile(`^/agents/([^/]+)/memory(/.*)?$`), resource: "memory", agent: 1},
+	{pattern: regexp.MustCompile(`^/agents/([^/]+)(/.*)?$`), resource: "agents", agent: 1},
+	{pattern: regexp.MustCompile(`^/agents$`), resource: "agents"},
+	{pattern: regexp.MustCompile(`^/(memory|knowledge|shared-knowledge)(/.*)?$`), resource: "memory"},
+	{pattern: regexp.MustCompile(`^/(activity|emissions)(/.*)?$`), resource: "activity"},
+}
+
+// Resources whose requests must name an allowed project (or agent) when the
+// key is restricted, so a restricted key cannot list across the org.
+var (
+	apiKeyProjectBoundResources = map[string]bool{"projects": true, "issues": true, "pipelines": true}
+	apiKeyAgentBoundResources   = map[string]bool{"agents": true, "memory": true}
+)
+
+// apiKeyRouteTarget is what a request acts on, as far as the path, query and
+// JSON body reveal.
+type apiKeyRouteTarget struct {
+	scope    string
+	resource string
+	projects []string
+	issueID  string
+	agents   []string
+}
+
+// APIKeyAuth authenticates requests carrying an integration API key
+// (Authorization: Bearer oc_key_… or X-API-Key). It enforces expiry, the IP
+// allowlist, the scope of the route and any project/agent restriction, then
+// pins the request to the key's workspace. Other requests pass unchanged.
+func APIKeyAuth(db *sql.DB) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			rawKey := apiKeyFromRequest(r)
+			if rawKey == "" {
+				next.ServeHTTP(w, r)
+				return
+			}
+			if db == nil {
+				sendJSON(w, http.StatusServiceUnavailable, errorResponse{Error: "database not available"})
+				return
+			}
+
+			keys := store.NewAPIKeyStore(db)
+			key, err := keys.Authenticate(r.Context(), rawKey)
+			if errors.Is(err, store.ErrNotFound) {
+				sendJSON(w, http.StatusUnauthorized, apiKeyErrorResponse{Error: "invalid api key"})
+				return
+			}
+			if err != nil {
+				sendJSON(w, http.StatusInternalServerError, errorResponse{Error: "failed to authenticate api key"})
+				return
+			}
+			if key.Expired(time.Now()) {
+				sendJSON(w, http.StatusUnauthorized, apiKeyErrorResponse{Error: "api key expired"})
+				return
+			}
+			clientIP := apiKeyClientIP(r)
+			if !key.AllowsIP(clientIP) {
+				sendJSON(w, http.StatusForbidden, apiKeyErrorResponse{Error: "api key not allowed from this address"})
+				return
+			}
+
+			target, ok := resolveAPIKeyRoute(r)
+			if !ok {
+				sendJSON(w, http.StatusForbidden, apiKeyErrorResponse{Error: "route not available to api keys"})
+				return
+			}
+			if !key.HasScope(target.scope) {
+				sendJSON(w, http.StatusForbidden, apiKeyErrorResponse{Error: "api key missing scope " + target.scope, Scope: target.scope})
+				return
+			}
+			if target.issueID != "" && len(key.ProjectIDs) > 0 {
+				projectID, err := keys.ProjectForIssue(r.Context(), key.OrgID, target.issueID)
+				if err != nil {
+					sendJSON(w, http.StatusInternalServerError, errorResponse{Error: "failed to resolve permission scope"})
+					return
+				}
+				if projectID != "" {
+					target.projects = append(target.projects, projectID)
+				}
+			}
+			if message := apiKeyRestrictionError(key, target); message != "" {
+				sendJSON(w, http.StatusForbidden, apiKeyErrorResponse{Error: message, Scope: target.scope})
+				return
+			}
+
+			if err := keys.TouchLastUsed(r.Context(), key.ID, clientIP); err != nil {
+				log.Printf("api key %s: failed to record use: %v", key.ID, err)
+			}
+
+			ctx := middleware.PinWorkspace(r.Context(), key.OrgID)
+			ctx = context.WithValue(ctx, apiKeyContextKey{}, key)
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
+
+// apiKeyFromContext returns the key that authenticated the request, if any.
+func apiKeyFromContext(ctx context.Context) *store.APIKey {
+	key, _ := ctx.Value(apiKeyContextKey{}).(*store.APIKey)
+	return key
+}
+
+// errAPIKeyCreatorMissing rejects keys whose creator has left the org; a
+// key never holds more than its creator currently does.
+var errAPIKeyCreatorMissing = errors.New("api key creator no longer has access")
+
+// apiKeyCreatorIdentity loads the current role of the user who minted key,
+// so capability checks follow later demotions and removals.
+func apiKeyCreatorIdentity(ctx context.Context, db *sql.DB, key *store.APIKey) (sessionIdentity, error) {
+	if key.CreatedBy == nil || strings.TrimSpace(*key.CreatedBy) == "" {
+		return sessionIdentity{}, errAPIKeyCreatorMissing
+	}
+	identity := sessionIdentity{OrgID: key.OrgID, UserID: *key.CreatedBy}
+	err := db.QueryRowContext(
+		ctx,
+		`SELECT COALESCE(role, 'owner') FROM users WHERE id = $1 AND org_id = $2`,
+		identity.UserID,
+		identity.OrgID,
+	).Scan(&identity.Role)
+	if errors.Is(err, sql.ErrNoRows) {
+		return sessionIdentity{}, errAPIKeyCreatorMissing
+	}
+	if err != nil {
+		return sessionIdentity{}, errAuthentication
+	}
+	return identity, nil
+}
+
+func apiKeyFromRequest(r *http.Request) string {
+	if auth := strings.TrimSpace(r.Header.Get("Authorization")); strings.HasPrefix(auth, "Bearer "+store.APIKeyPrefix) {
+		return strings.TrimSpace(strings.TrimPrefix(auth, "Bearer "))
+	}
+	if key := strings.TrimSpace(r.Header.Get("X-API-Key")); strings.HasPrefix(key, store.APIKeyPrefix) {
+		return key
+	}
+	return ""
+}
+
+// resolveAPIKeyRoute finds the scope a request needs and the projects,
+// issue and agents it names. It reports false for routes closed to keys.
+func resolveAPIKeyRoute(r *http.Request) (apiKeyRouteTarget, bool) {
+	path := strings.TrimSuffix(strings.TrimPrefix(r.URL.Path, "/api"), "/")
+	for _, route := range apiKeyRoutes {
+		match := route.pattern.FindStringSubmatch(path)
+		if match == nil {
+			continue
+		}
+
+		action := "write"
+		switch {
+		case r.Method == http.MethodGet || r.Method == http.MethodHead:
+			action = "read"
+		case route.run:
+			action = "run"
+		}
+		target := apiKeyRouteTarget{scope: route.resource + ":" + action, resource: route.resource}
+		if !store.IsAPIKeyScope(target.scope) {
+			return apiKeyRouteTarget{}, false
+		}
+		if route.project > 0 {
+			target.projects = append(target.projects, match[route.project])
+		}
+		if route.issue > 0 {
+			target.issueID = match[route.issue]
+		}
+		if route.agent > 0 {
+			target.agents = append(target.agents, match[route.agent])
+		}
+
+		query := r.URL.Query()
+		if value := strings.TrimSpace(query.Get("project_id")); value != "" {
+			target.projects = append(target.projects, value)
+		}
+		if value := strings.TrimSpace(query.Get("agent_id")); value != "" {
+			target.agents = append(target.agents, value)
+		}
+		body := peekAPIKeyRequestBody(r)
+		if value, ok := body["project_id"].(string); ok && strings.TrimSpace(value) != "" {
+			target.projects = append(target.projects, strings.TrimSpace(value))
+		}
+		if value, ok := body["agent_id"].(string); ok && strings.TrimSpace(value) != "" {
+			target.agents = append(target.agents, strings.TrimSpace(value))
+		}
+		if operations, ok := body["operations"].(map[string]any); ok {
+			if value, ok := operations["move_to_project_id"].(string); ok && strings.TrimSpace(value) != "" {
+				target.projects = append(target.projects, strings.TrimSpace(value))
+			}
+		}
+		return target, true
+	}
+	return apiKeyRouteTarget{}, false
+}
+
+// apiKeyRestrictionError checks project and agent restrictions and returns
+// a message when the request falls outside them.
+func apiKeyRestrictionError(key *store.APIKey, target apiKeyRouteTarget) string {
+	if len(key.ProjectIDs) > 0 {
+		if len(target.projects) == 0 && apiKeyProjectBoundResources[target.resource] {
+			return "api key is restricted to specific projects; the request must name one"
+		}
+		for _, projectID := range target.projects {
+			if !key.AllowsProject(projectID) {
+				return "api key not allowed for project " + projectID
+			}
+		}
+	}
+	if len(key.AgentIDs) > 0 {
+		if len(target.agents) == 0 && apiKeyAgentBoundResources[target.resource] {
+			return "api key is restricted to specific agents; the request must name one"
+		}
+		for _, agentID := range target.agents {
+			if !key.AllowsAgent(agentID) {
+				return "api key not allowed for agent " + agentID
+			}
+		}
+	}
+	return ""
+}
+
+// peekAPIKeyRequestBody decodes a JSON object body without consuming it.
+func peekAPIKeyRequestBody(r *http.Request) map[string]any {
+	if r.Body == nil || r.Method == http.MethodGet || r.Method == http.MethodHead {
+		return nil
+	}
+	if contentType := r.Header.Get("Content-Type"); contentType != "" && !strings.Contains(contentType, "json") {
+		return nil
+	}
+	peeked, err := io.ReadAll(io.LimitReader(r.Body, apiKeyBodyPeekLimit))
+	r.Body = struct {
+		io.Reader
+		io.Closer
+	}{io.MultiReader(bytes.NewReader(peeked), r.Body), r.Body}
+	if err != nil {
+		return nil
+	}
+	var body map[string]any
+	if json.Unmarshal(peeked, &body) != nil {
+		return nil
+	}
+	return body
+}
+
+// apiKeyClientIP honours X-Forwarded-For only behind a trusted proxy, so
+// clients cannot spoof their way past an allowlist.
+func apiKeyClientIP(r *http.Request) string {
+	if trustForwardedFor() {
+		if forwarded := strings.TrimSpace(r.Header.Get("X-Forwarded-For")); forwarded != "" {
+			return strings.TrimSpace(strings.Split(forwarded, ",")[0])
+		}
+	}
+	remote := strings.TrimSpace(r.RemoteAddr)
+	if host, _, err := net.SplitHostPort(remote); err == nil {
+		return host
+	}
+	return remote
+}
+
+func trustForwardedFor() bool {
+	raw := strings.ToLower(strings.TrimSpace(os.Getenv("TRUST_PROXY_HEADERS")))
+	if raw == "" {
+		raw = strings.ToLower(strings.TrimSpace(os.Getenv("OTTER_TRUST_PROXY_HEADERS")))
+	}
+	return raw == "1" || raw == "true" || raw == "yes"
+}
+
+// HandleSettingsAPIKeyScopes handles GET /api/settings/integrations/api-keys/scopes
+func HandleSettingsAPIKeyScopes(w http.ResponseWriter, r *http.Request) {
+	sendJSON(w, http.StatusOK, map[string]any{
+		"items":    store.APIKeyScopes,
+		"total":    len(store.APIKeyScopes),
+		"defaults": store.DefaultAPIKeyScopes,
+	})
+}
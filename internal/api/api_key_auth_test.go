@@ -0,0 +1,266 @@
+package api
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/samhotchkiss/otter-camp/internal/middleware"
+	"github.com/samhotchkiss/otter-camp/internal/store"
+	"github.com/stretchr/testify/require"
+)
+
+func TestResolveAPIKeyRouteMapsPathsToScopes(t *testing.T) {
+	const projectID = "11111111-1111-1111-1111-111111111111"
+	const agentID = "22222222-2222-2222-2222-222222222222"
+
+	tests := []struct {
+		method   string
+		path     string
+		scope    string
+		projects []string
+		agents   []string
+		issue    string
+	}{
+		{method: http.MethodGet, path: "/api/issues?project_id=" + projectID, scope: "issues:read", projects: []string{projectID}},
+		{method: http.MethodPost, path: "/api/issues/33333333-3333-3333-3333-333333333333/comments", scope: "issues:write", issue: "33333333-3333-3333-3333-333333333333"},
+		{method: http.MethodPost, path: "/api/projects/" + projectID + "/issues", scope: "issues:write", projects: []string{projectID}},
+		{method: http.MethodPut, path: "/api/projects/" + projectID + "/pipeline-steps/reorder", scope: "pipelines:write", projects: []string{projectID}},
+		{method: http.MethodGet, path: "/api/projects/" + projectID + "/commits", scope: "projects:read", projects: []string{projectID}},
+		{method: http.MethodPost, path: "/api/agents/" + agentID + "/memory", scope: "memory:write", agents: []string{agentID}},
+		{method: http.MethodGet, path: "/api/memory/search?agent_id=" + agentID, scope: "memory:read", agents: []string{agentID}},
+		{method: http.MethodPost, path: "/api/v1/jobs/job-1/run", scope: "jobs:run"},
+		{method: http.MethodPatch, path: "/api/v1/jobs/job-1", scope: "jobs:write"},
+		{method: http.MethodGet, path: "/api/v1/jobs/", scope: "jobs:read"},
+		{method: http.MethodPost, path: "/api/emissions", scope: "activity:write"},
+	}
+	for _, tc := range tests {
+		req := httptest.NewRequest(tc.method, tc.path, nil)
+		target, ok := resolveAPIKeyRoute(req)
+		require.True(t, ok, tc.path)
+		require.Equal(t, tc.scope, target.scope, tc.path)
+		require.Equal(t, tc.projects, target.projects, tc.path)
+		require.Equal(t, tc.agents, target.agents, tc.path)
+		require.Equal(t, tc.issue, target.issueID, tc.path)
+	}
+
+	for _, closed := range []struct{ method, path string }{
+		{http.MethodPost, "/api/admin/roles"},
+		{http.MethodGet, "/api/settings/integrations"},
+		{http.MethodPost, "/api/agents/" + agentID + "/retire"},
+		{http.MethodPost, "/api/backup/restore"},
+	} {
+		_, ok := resolveAPIKeyRoute(httptest.NewRequest(closed.method, closed.path, nil))
+		require.False(t, ok, closed.path)
+	}
+}
+
+func TestResolveAPIKeyRouteReadsBodyWithoutConsumingIt(t *testing.T) {
+	body := `{"agent_id":"22222222-2222-2222-2222-222222222222","kind":"fact"}`
+	req := httptest.NewRequest(http.MethodPost, "/api/memory/entries", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+
+	target, ok := resolveAPIKeyRoute(req)
+	require.True(t, ok)
+	require.Equal(t, []string{"22222222-2222-2222-2222-222222222222"}, target.agents)
+
+	remaining, err := io.ReadAll(req.Body)
+	require.NoError(t, err)
+	require.Equal(t, body, string(remaining))
+}
+
+func TestResolveAPIKeyRouteCollectsBulkIssueProjects(t *testing.T) {
+	body := `{"project_id":"11111111-1111-1111-1111-111111111111","issue_ids":["33333333-3333-3333-3333-333333333333"],"operations":{"move_to_project_id":"44444444-4444-4444-4444-444444444444"}}`
+	req := httptest.NewRequest(http.MethodPost, "/api/issues/bulk", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+
+	target, ok := resolveAPIKeyRoute(req)
+	require.True(t, ok)
+	require.Equal(t, "issues:write", target.scope)
+	require.Empty(t, target.issueID)
+	require.Equal(t, []string{"11111111-1111-1111-1111-111111111111", "44444444-4444-4444-4444-444444444444"}, target.projects)
+
+	key := &store.APIKey{ProjectIDs: []string{"11111111-1111-1111-1111-111111111111"}}
+	require.Equal(t, "api key not allowed for project 44444444-4444-4444-4444-444444444444", apiKeyRestrictionError(key, target))
+
+	projectID, err := bodyProjectScope("project_id")(req, nil, "")
+	require.NoError(t, err)
+	require.Equal(t, "11111111-1111-1111-1111-111111111111", projectID)
+	remaining, err := io.ReadAll(req.Body)
+	require.NoError(t, err)
+	require.Equal(t, body, string(remaining))
+}
+
+func TestAPIKeyRestrictionError(t *testing.T) {
+	key := &store.APIKey{ProjectIDs: []string{"p-1"}, AgentIDs: []string{"a-1"}}
+
+	require.Empty(t, apiKeyRestrictionError(key, apiKeyRouteTarget{resource: "issues", projects: []string{"p-1"}}))
+	require.Contains(t, apiKeyRestrictionError(key, apiKeyRouteTarget{resource: "issues"}), "must name one")
+	require.Contains(t, apiKeyRestrictionError(key, apiKeyRouteTarget{resource: "issues", projects: []string{"p-1", "p-2"}}), "project p-2")
+	require.Empty(t, apiKeyRestrictionError(key, apiKeyRouteTarget{resource: "jobs"}))
+	require.Contains(t, apiKeyRestrictionError(key, apiKeyRouteTarget{resource: "memory"}), "specific agents")
+	require.Contains(t, apiKeyRestrictionError(key, apiKeyRouteTarget{resource: "memory", agents: []string{"a-2"}}), "agent a-2")
+	require.Empty(t, apiKeyRestrictionError(&store.APIKey{}, apiKeyRouteTarget{resource: "issues"}))
+}
+
+func TestAPIKeyClientIPTrustsForwardedForOnlyBehindProxy(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "/api/issues", nil)
+	req.RemoteAddr = "203.0.113.9:5123"
+	req.Header.Set("X-Forwarded-For", "10.0.0.1, 203.0.113.9")
+
+	t.Setenv("TRUST_PROXY_HEADERS", "")
+	t.Setenv("OTTER_TRUST_PROXY_HEADERS", "")
+	require.Equal(t, "203.0.113.9", apiKeyClientIP(req))
+
+	t.Setenv("TRUST_PROXY_HEADERS", "true")
+	require.Equal(t, "10.0.0.1", apiKeyClientIP(req))
+}
+
+func TestAPIKeyAuthPassesRequestsWithoutKey(t *testing.T) {
+	handler := APIKeyAuth(nil)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}))
+
+	req := httptest.NewRequest(http.MethodGet, "/api/issues", nil)
+	req.Header.Set("Authorization", "Bearer oc_sess_abc")
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	require.Equal(t, http.StatusNoContent, rec.Code)
+
+	req = httptest.NewRequest(http.MethodGet, "/api/issues", nil)
+	req.Header.Set("X-API-Key", "oc_key_abc")
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	require.Equal(t, http.StatusServiceUnavailable, rec.Code)
+}
+
+func TestAPIKeyAuthEnforcesScopesRestrictionsAndWorkspace(t *testing.T) {
+	db := setupMessageTestDB(t)
+	orgID := insertMessageTestOrganization(t, db, "api-key-auth-org")
+	otherOrgID := insertMessageTestOrganization(t, db, "api-key-auth-other")
+	projectID := insertTestProject(t, db, orgID, "Key Project")
+	otherProjectID := insertTestProject(t, db, orgID, "Other Key Project")
+
+	keys := store.NewAPIKeyStore(db)
+	_, rawKey, err := keys.Create(context.Background(), orgID, store.CreateAPIKeyInput{
+		Name:       "bridge",
+		Scopes:     []string{"issues:read"},
+		ProjectIDs: []string{projectID},
+		AllowedIPs: []string{"192.0.2.0/24"},
+	})
+	require.NoError(t, err)
+	expired := time.Now().Add(time.Hour)
+	_, expiringKey, err := keys.Create(context.Background(), orgID, store.CreateAPIKeyInput{Name: "soon", ExpiresAt: &expired})
+	require.NoError(t, err)
+	_, err = db.Exec(`UPDATE api_keys SET expires_at = NOW() - interval '1 minute' WHERE name = 'soon'`)
+	require.NoError(t, err)
+
+	var gotWorkspace string
+	handler := APIKeyAuth(db)(middleware.OptionalWorkspace(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotWorkspace = middleware.WorkspaceFromContext(r.Context())
+		w.WriteHeader(http.StatusNoContent)
+	})))
+	call := func(method, path, key string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, nil)
+		req.RemoteAddr = "192.0.2.10:4000"
+		req.Header.Set("Authorization", "Bearer "+key)
+		req.Header.Set("X-Org-ID", otherOrgID)
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		return rec
+	}
+
+	rec := call(http.MethodGet, "/api/issues?project_id="+projectID, rawKey)
+	require.Equal(t, http.StatusNoContent, rec.Code, rec.Body.String())
+	require.Equal(t, orgID, gotWorkspace)
+
+	rec = call(http.MethodPost, "/api/projects/"+projectID+"/issues", rawKey)
+	require.Equal(t, http.StatusForbidden, rec.Code)
+	require.JSONEq(t, `{"error":"api key missing scope issues:write","scope":"issues:write"}`, rec.Body.String())
+
+	rec = call(http.MethodGet, "/api/issues?project_id="+otherProjectID, rawKey)
+	require.Equal(t, http.StatusForbidden, rec.Code)
+	rec = call(http.MethodGet, "/api/issues", rawKey)
+	require.Equal(t, http.StatusForbidden, rec.Code)
+	rec = call(http.MethodGet, "/api/settings/integrations", rawKey)
+	require.Equal(t, http.StatusForbidden, rec.Code)
+	rec = call(http.MethodGet, "/api/issues", "oc_key_unknown")
+	require.Equal(t, http.StatusUnauthorized, rec.Code)
+	rec = call(http.MethodGet, "/api/issues", expiringKey)
+	require.Equal(t, http.StatusUnauthorized, rec.Code)
+	require.Contains(t, rec.Body.String(), "expired")
+
+	req := httptest.NewRequest(http.MethodGet, "/api/issues?project_id="+projectID, nil)
+	req.RemoteAddr = "198.51.100.1:4000"
+	req.Header.Set("Authorization", "Bearer "+rawKey)
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	require.Equal(t, http.StatusForbidden, rec.Code)
+
+	var lastUsed *time.Time
+	require.NoError(t, db.QueryRow(`SELECT last_used_at FROM api_keys WHERE name = 'bridge'`).Scan(&lastUsed))
+	require.NotNil(t, lastUsed)
+}
+
+func TestAuthorizeCapabilityChecksAPIKeyCreatorCapabilities(t *testing.T) {
+	handler := AuthorizeCapability(nil, CapabilityProjectsManage, nil)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	req := httptest.NewRequest(http.MethodDelete, "/api/projects/p-1", nil)
+	req = req.WithContext(context.WithValue(req.Context(), apiKeyContextKey{}, &store.APIKey{ID: "k-1", OrgID: "org-1"}))
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	require.Equal(t, http.StatusServiceUnavailable, rec.Code)
+
+	db := setupMessageTestDB(t)
+	orgID := insertMessageTestOrganization(t, db, "api-key-capability-org")
+	memberID := insertTestUserWithRole(t, db, orgID, "member-key-creator", RoleMember)
+	projectID := insertTestProject(t, db, orgID, "Key Capability Project")
+
+	_, rawKey, err := store.NewAPIKeyStore(db).Create(context.Background(), orgID, store.CreateAPIKeyInput{
+		Name:      "member-key",
+		Scopes:    []string{"projects:write"},
+		CreatedBy: &memberID,
+	})
+	require.NoError(t, err)
+
+	router := chi.NewRouter()
+	router.Use(APIKeyAuth(db))
+	router.With(AuthorizeCapability(db, CapabilityProjectsManage, projectScope("id"))).Delete("/api/projects/{id}", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	})
+	router.With(AuthorizeCapability(db, CapabilityProjectsWrite, projectScope("id"))).Post("/api/projects/{id}/commits", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	})
+	call := func(method, path string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, nil)
+		req.Header.Set("Authorization", "Bearer "+rawKey)
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+		return rec
+	}
+
+	rec = call(http.MethodPost, "/api/projects/"+projectID+"/commits")
+	require.Equal(t, http.StatusNoContent, rec.Code, rec.Body.String())
+
+	// projects:write does not stretch to routes needing projects.manage.
+	rec = call(http.MethodDelete, "/api/projects/"+projectID)
+	require.Equal(t, http.StatusForbidden, rec.Code)
+	require.Contains(t, rec.Body.String(), CapabilityProjectsManage)
+
+	_, err = db.Exec(`UPDATE users SET role = $1 WHERE id = $2`, RoleViewer, memberID)
+	require.NoError(t, err)
+	rec = call(http.MethodPost, "/api/projects/"+projectID+"/commits")
+	require.Equal(t, http.StatusForbidden, rec.Code)
+
+	_, err = db.Exec(`UPDATE api_keys SET created_by = NULL WHERE name = 'member-key'`)
+	require.NoError(t, err)
+	rec = call(http.MethodPost, "/api/projects/"+projectID+"/commits")
+	require.Equal(t, http.StatusForbidden, rec.Code)
+	require.Contains(t, rec.Body.String(), "api key creator no longer has access")
+}
@@ -0,0 +1,340 @@
+package api
+
+import (
+	"context"
+	"database/sql"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/samhotchkiss/otter-camp/internal/middleware"
+	"github.com/samhotchkiss/otter-camp/internal/store"
+)
+
+const (
+	defaultAuditPageSize = 100
+	maxAuditPageSize     = 1000
+)
+
+// AuditHandler serves the audit log query, export and verify endpoints.
+type AuditHandler struct {
+	Store *store.AuditLogStore
+}
+
+type auditListResponse struct {
+	Items      []store.AuditEntry `json:"items"`
+	Total      int                `json:"total"`
+	NextCursor string             `json:"next_cursor,omitempty"`
+}
+
+// auditEvent describes one audited change. OrgID defaults to the request's
+// workspace and UserID to the request's authenticated user; Before and After
+// are marshalled to JSON and diffed.
+type auditEvent struct {
+	OrgID      string
+	UserID     string
+	Action     string
+	TargetType string
+	TargetID   string
+	Before     any
+	After      any
+}
+
+type auditRecorderContextKey struct{}
+
+type auditRecorder struct {
+	db    *sql.DB
+	store *store.AuditLogStore
+}
+
+// AuditRecorder makes recordAudit available to every handler below it.
+func AuditRecorder(db *sql.DB) func(http.Handler) http.Handler {
+	var recorder *auditRecorder
+	if db != nil {
+		recorder = &auditRecorder{db: db, store: store.NewAuditLogStore(db)}
+	}
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if recorder != nil {
+				r = r.WithContext(context.WithValue(r.Context(), auditRecorderContextKey{}, recorder))
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// recordAudit appends event to the audit log, attributing it to whoever
+// authenticated the request. Failures are logged, never returned: the change
+// it describes has already happened.
+func recordAudit(r *http.Request, event auditEvent) {
+	recorder, _ := r.Context().Value(auditRecorderContextKey{}).(*auditRecorder)
+	if recorder == nil {
+		return
+	}
+
+	if err := recorder.record(r, event); err != nil {
+		log.Printf("[audit] failed to record %s on %s %s: %v", event.Action, event.TargetType, event.TargetID, err)
+	}
+}
+
+func (a *auditRecorder) record(r *http.Request, event auditEvent) error {
+	orgID := strings.TrimSpace(event.OrgID)
+	if orgID == "" {
+		orgID = middleware.WorkspaceFromContext(r.Context())
+	}
+	if orgID == "" {
+		return fmt.Errorf("no workspace")
+	}
+	before, err := auditJSON(event.Before)
+	if err != nil {
+		return err
+	}
+	after, err := auditJSON(event.After)
+	if err != nil {
+		return err
+	}
+	actorType, actorID := a.actor(r, orgID, event.UserID)
+	_, err = a.store.Append(r.Context(), orgID, store.AppendAuditEntryInput{
+		ActorType:  actorType,
+		ActorID:    actorID,
+		Action:     event.Action,
+		TargetType: event.TargetType,
+		TargetID:   event.TargetID,
+		Before:     before,
+		After:      after,
+		IP:         apiKeyClientIP(r),
+		UserAgent:  r.UserAgent(),
+	})
+	return err
+}
+
+// actor prefers an API key, then a user the handler or middleware already
+// resolved, then the request's session token. Anything else is the
+// workspace itself.
+func (a *auditRecorder) actor(r *http.Request, orgID, userID string) (string, string) {
+	if key := apiKeyFromContext(r.Context()); key != nil {
+		return store.AuditActorAPIKey, key.ID
+	}
+	if userID = strings.TrimSpace(userID); userID != "" {
+		return store.AuditActorUser, userID
+	}
+	if userID := middleware.UserFromContext(r.Context()); userID != "" {
+		return store.AuditActorUser, userID
+	}
+	if isUserCredential(extractSessionToken(r)) {
+		if identity, err := requireSessionIdentity(r.Context(), a.db, r); err == nil && identity.OrgID == orgID {
+			return store.AuditActorUser, identity.UserID
+		}
+	}
+	return store.AuditActorWorkspace, orgID
+}
+
+func auditJSON(value any) (json.RawMessage, error) {
+	switch typed := value.(type) {
+	case nil:
+		return nil, nil
+	case json.RawMessage:
+		return typed, nil
+	case []byte:
+		return json.RawMessage(typed), nil
+	}
+	encoded, err := json.Marshal(value)
+	if err != nil {
+		return nil, err
+	}
+	if string(encoded) == "null" {
+		return nil, nil
+	}
+	return encoded, nil
+}
+
+// List handles GET /api/admin/audit
+func (h *AuditHandler) List(w http.ResponseWriter, r *http.Request) {
+	if h.Store == nil {
+		sendJSON(w, http.StatusServiceUnavailable, errorResponse{Error: "database not available"})
+		return
+	}
+	orgID := middleware.WorkspaceFromContext(r.Context())
+	if orgID == "" {
+		sendJSON(w, http.StatusUnauthorized, errorResponse{Error: "authentication required"})
+		return
+	}
+	filter, err := parseAuditFilter(r)
+	if err != nil {
+		sendJSON(w, http.StatusBadRequest, errorResponse{Error: err.Error()})
+		return
+	}
+	limit, err := parseLimit(r.URL.Query().Get("limit"), defaultAuditPageSize, maxAuditPageSize)
+	if err != nil {
+		sendJSON(w, http.StatusBadRequest, errorResponse{Error: err.Error()})
+		return
+	}
+	filter.Limit = limit
+
+	entries, err := h.Store.List(r.Context(), orgID, filter)
+	if err != nil {
+		handleJobsStoreError(w, err)
+		return
+	}
+	response := auditListResponse{Items: entries, Total: len(entries)}
+	if len(entries) == limit {
+		response.NextCursor = strconv.FormatInt(entries[len(entries)-1].Seq, 10)
+	}
+	sendJSON(w, http.StatusOK, response)
+}
+
+// Export handles GET /api/admin/audit/export?format=jsonl|csv. It streams
+// every entry matching the filters, newest first.
+func (h *AuditHandler) Export(w http.ResponseWriter, r *http.Request) {
+	if h.Store == nil {
+		sendJSON(w, http.StatusServiceUnavailable, errorResponse{Error: "database not available"})
+		return
+	}
+	orgID := middleware.WorkspaceFromContext(r.Context())
+	if orgID == "" {
+		sendJSON(w, http.StatusUnauthorized, errorResponse{Error: "authentication required"})
+		return
+	}
+	filter, err := parseAuditFilter(r)
+	if err != nil {
+		sendJSON(w, http.StatusBadRequest, errorResponse{Error: err.Error()})
+		return
+	}
+	format := strings.ToLower(strings.TrimSpace(r.URL.Query().Get("format")))
+	if format == "" {
+		format = "jsonl"
+	}
+	if format != "jsonl" && format != "csv" {
+		sendJSON(w, http.StatusBadRequest, errorResponse{Error: "format must be jsonl or csv"})
+		return
+	}
+	filter.Limit = maxAuditPageSize
+
+	// Read the first page before writing headers so errors can still be JSON.
+	entries, err := h.Store.List(r.Context(), orgID, filter)
+	if err != nil {
+		handleJobsStoreError(w, err)
+		return
+	}
+
+	filename := fmt.Sprintf("audit-%s.%s", time.Now().UTC().Format("20060102T150405Z"), format)
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+	var writeEntry func(store.AuditEntry) error
+	var flush func() error
+	if format == "csv" {
+		w.Header().Set("Content-Type", "text/csv; charset=utf-8")
+		w.WriteHeader(http.StatusOK)
+		writer := csv.NewWriter(w)
+		_ = writer.Write(auditCSVHeader)
+		writeEntry = func(entry store.AuditEntry) error { return writer.Write(auditCSVRecord(entry)) }
+		flush = func() error { writer.Flush(); return writer.Error() }
+	} else {
+		w.Header().Set("Content-Type", "application/x-ndjson")
+		w.WriteHeader(http.StatusOK)
+		encoder := json.NewEncoder(w)
+		writeEntry = func(entry store.AuditEntry) error { return encoder.Encode(entry) }
+		flush = func() error { return nil }
+	}
+
+	for len(entries) > 0 {
+		for _, entry := range entries {
+			if err := writeEntry(entry); err != nil {
+				return
+			}
+		}
+		if len(entries) < filter.Limit {
+			break
+		}
+		filter.BeforeSeq = entries[len(entries)-1].Seq
+		if entries, err = h.Store.List(r.Context(), orgID, filter); err != nil {
+			log.Printf("[audit] export for %s stopped early: %v", orgID, err)
+			break
+		}
+	}
+	_ = flush()
+}
+
+// Verify handles GET /api/admin/audit/verify
+func (h *AuditHandler) Verify(w http.ResponseWriter, r *http.Request) {
+	if h.Store == nil {
+		sendJSON(w, http.StatusServiceUnavailable, errorResponse{Error: "database not available"})
+		return
+	}
+	orgID := middleware.WorkspaceFromContext(r.Context())
+	if orgID == "" {
+		sendJSON(w, http.StatusUnauthorized, errorResponse{Error: "authentication required"})
+		return
+	}
+	result, err := h.Store.Verify(r.Context(), orgID)
+	if err != nil {
+		handleJobsStoreError(w, err)
+		return
+	}
+	sendJSON(w, http.StatusOK, result)
+}
+
+var auditCSVHeader = []string{
+	"seq", "created_at", "actor_type", "actor_id", "action", "target_type", "target_id",
+	"ip", "user_agent", "diff", "before", "after", "prev_hash", "hash",
+}
+
+func auditCSVRecord(entry store.AuditEntry) []string {
+	return []string{
+		strconv.FormatInt(entry.Seq, 10),
+		entry.CreatedAt.UTC().Format(time.RFC3339Nano),
+		entry.ActorType,
+		entry.ActorID,
+		entry.Action,
+		entry.TargetType,
+		entry.TargetID,
+		entry.IP,
+		entry.UserAgent,
+		string(entry.Diff),
+		string(entry.Before),
+		string(entry.After),
+		entry.PrevHash,
+		entry.Hash,
+	}
+}
+
+func parseAuditFilter(r *http.Request) (store.AuditLogFilter, error) {
+	query := r.URL.Query()
+	filter := store.AuditLogFilter{
+		Action:     strings.TrimSpace(query.Get("action")),
+		ActorID:    strings.TrimSpace(query.Get("actor")),
+		TargetType: strings.TrimSpace(query.Get("target_type")),
+		TargetID:   strings.TrimSpace(query.Get("target_id")),
+	}
+	for name, dest := range map[string]**time.Time{"since": &filter.Since, "until": &filter.Until} {
+		raw := strings.TrimSpace(query.Get(name))
+		if raw == "" {
+			continue
+		}
+		parsed, err := parseAuditTime(raw)
+		if err != nil {
+			return store.AuditLogFilter{}, fmt.Errorf("invalid %s", name)
+		}
+		*dest = &parsed
+	}
+	if raw := strings.TrimSpace(query.Get("cursor")); raw != "" {
+		seq, err := strconv.ParseInt(raw, 10, 64)
+		if err != nil || seq <= 0 {
+			return store.AuditLogFilter{}, fmt.Errorf("invalid cursor")
+		}
+		filter.BeforeSeq = seq
+	}
+	return filter, nil
+}
+
+// parseAuditTime accepts RFC 3339 timestamps and plain dates.
+func parseAuditTime(raw string) (time.Time, error) {
+	if parsed, err := time.Parse(time.RFC3339, raw); err == nil {
+		return parsed, nil
+	}
+	return time.Parse("2006-01-02", raw)
+}
@@ -0,0 +1,122 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/samhotchkiss/otter-camp/internal/middleware"
+	"github.com/samhotchkiss/otter-camp/internal/store"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseAuditFilter(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "/api/admin/audit?action=role.*&actor=u1&target_type=role&target_id=r1&since=2026-10-01&until=2026-10-18T12:00:00Z&cursor=42", nil)
+	filter, err := parseAuditFilter(req)
+	require.NoError(t, err)
+	require.Equal(t, "role.*", filter.Action)
+	require.Equal(t, "u1", filter.ActorID)
+	require.Equal(t, "role", filter.TargetType)
+	require.Equal(t, "r1", filter.TargetID)
+	require.Equal(t, time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC), *filter.Since)
+	require.Equal(t, time.Date(2026, 10, 18, 12, 0, 0, 0, time.UTC), *filter.Until)
+	require.Equal(t, int64(42), filter.BeforeSeq)
+
+	for _, query := range []string{"since=yesterday", "until=10/18/2026", "cursor=abc", "cursor=-1"} {
+		_, err := parseAuditFilter(httptest.NewRequest(http.MethodGet, "/api/admin/audit?"+query, nil))
+		require.Error(t, err, query)
+	}
+}
+
+func TestAuditJSONTreatsNilAsAbsent(t *testing.T) {
+	var binding *store.ProjectRepoBinding
+	for _, value := range []any{nil, binding} {
+		encoded, err := auditJSON(value)
+		require.NoError(t, err)
+		require.Nil(t, encoded)
+	}
+	encoded, err := auditJSON(map[string]bool{"sso_only": true})
+	require.NoError(t, err)
+	require.JSONEq(t, `{"sso_only":true}`, string(encoded))
+}
+
+func TestRecordAuditWithoutRecorderIsNoop(t *testing.T) {
+	req := httptest.NewRequest(http.MethodPost, "/api/admin/roles", nil)
+	require.NotPanics(t, func() {
+		recordAudit(req, auditEvent{Action: "role.create", TargetType: "role", TargetID: "r1"})
+	})
+}
+
+func TestAuditHandlerRequiresDatabase(t *testing.T) {
+	handler := &AuditHandler{}
+	for _, serve := range []http.HandlerFunc{handler.List, handler.Export, handler.Verify} {
+		rec := httptest.NewRecorder()
+		serve(rec, httptest.NewRequest(http.MethodGet, "/api/admin/audit", nil))
+		require.Equal(t, http.StatusServiceUnavailable, rec.Code)
+	}
+}
+
+func TestAuditRecorderAttributesEntriesAndHandlerExports(t *testing.T) {
+	db := setupMessageTestDB(t)
+	orgID := insertMessageTestOrganization(t, db, "audit-api-org")
+	userID := insertTestUser(t, db, orgID, "audit-admin")
+
+	mutate := AuditRecorder(db)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := context.WithValue(r.Context(), middleware.WorkspaceIDKey, orgID)
+		ctx = context.WithValue(ctx, middleware.UserIDKey, userID)
+		recordAudit(r.WithContext(ctx), auditEvent{
+			Action:     "compliance_rule.update",
+			TargetType: "compliance_rule",
+			TargetID:   "rule-1",
+			Before:     map[string]any{"severity": "low", "title": "Tests"},
+			After:      map[string]any{"severity": "high", "title": "Tests"},
+		})
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	for i := 0; i < 2; i++ {
+		req := httptest.NewRequest(http.MethodPatch, "/api/compliance/rules/rule-1", nil)
+		req.RemoteAddr = "192.0.2.7:5000"
+		req.Header.Set("User-Agent", "audit-test")
+		mutate.ServeHTTP(httptest.NewRecorder(), req)
+	}
+
+	handler := &AuditHandler{Store: store.NewAuditLogStore(db)}
+	withWorkspace := func(target string) *http.Request {
+		req := httptest.NewRequest(http.MethodGet, target, nil)
+		return req.WithContext(context.WithValue(req.Context(), middleware.WorkspaceIDKey, orgID))
+	}
+
+	rec := httptest.NewRecorder()
+	handler.List(rec, withWorkspace("/api/admin/audit?action=compliance_rule.*&limit=1"))
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	var listed auditListResponse
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &listed))
+	require.Len(t, listed.Items, 1)
+	require.Equal(t, "2", listed.NextCursor)
+	entry := listed.Items[0]
+	require.Equal(t, store.AuditActorUser, entry.ActorType)
+	require.Equal(t, userID, entry.ActorID)
+	require.Equal(t, "192.0.2.7", entry.IP)
+	require.Equal(t, "audit-test", entry.UserAgent)
+	require.JSONEq(t, `{"severity":{"before":"low","after":"high"}}`, string(entry.Diff))
+
+	rec = httptest.NewRecorder()
+	handler.Export(rec, withWorkspace("/api/admin/audit/export?format=csv"))
+	require.Equal(t, http.StatusOK, rec.Code)
+	lines := strings.Split(strings.TrimSpace(rec.Body.String()), "\n")
+	require.Len(t, lines, 3)
+	require.True(t, strings.HasPrefix(lines[0], "seq,created_at,actor_type"))
+
+	rec = httptest.NewRecorder()
+	handler.Export(rec, withWorkspace("/api/admin/audit/export?format=xml"))
+	require.Equal(t, http.StatusBadRequest, rec.Code)
+
+	rec = httptest.NewRecorder()
+	handler.Verify(rec, withWorkspace("/api/admin/audit/verify"))
+	require.Equal(t, http.StatusOK, rec.Code)
+	require.JSONEq(t, `{"valid":true,"checked":2}`, rec.Body.String())
+}
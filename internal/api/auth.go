@@ -106,6 +106,9 @@ func HandleLogin(w http.ResponseWriter, r *http.Request) {
 		sendJSON(w, http.StatusServiceUnavailable, errorResponse{Error: err.Error()})
 		return
 	}
+	if !requireNonSSOLoginAllowed(w, r, db, orgID) {
+		return
+	}
 
 	state, err := generateRandomToken(24)
 	if err != nil {
@@ -210,6 +213,9 @@ func HandleAuthExchange(w http.ResponseWriter, r *http.Request) {
 		sendJSON(w, http.StatusUnauthorized, errorResponse{Error: err.Error()})
 		return
 	}
+	if !requireNonSSOLoginAllowed(w, r, db, authReq.OrgID) {
+		return
+	}
 
 	userID, displayName, email, err := upsertAuthUser(r.Context(), db, authReq.OrgID, claims)
 	if err != nil {
@@ -652,6 +658,16 @@ func HandleMagicLink(w http.ResponseWriter, r *http.Request) {
 	authToken := "oc_magic_" + token
 	expiresAt := time.Now().UTC().Add(sessionTTL())
 
+	enforced, err := orgSlugSSOEnforced(r.Context(), db, orgSlug)
+	if err != nil {
+		sendJSON(w, http.StatusInternalServerError, errorResponse{Error: "failed to load organization"})
+		return
+	}
+	if enforced {
+		sendJSON(w, http.StatusForbidden, errorResponse{Error: "sso required"})
+		return
+	}
+
 	var orgID string
 	if err := db.QueryRowContext(
 		r.Context(),
@@ -252,6 +252,9 @@ func TestHandleMagicLinkSupportsCustomOrgSlugAndEmail(t *testing.T) {
 	defer func() { _ = db.Close() }()
 	setAuthDBMock(t, db)
 
+	mock.ExpectQuery(`SELECT sso_enforced FROM organizations WHERE slug`).
+		WithArgs("acme-sandbox").
+		WillReturnRows(sqlmock.NewRows([]string{"sso_enforced"}).AddRow(false))
 	mock.ExpectQuery(`INSERT INTO organizations`).
 		WithArgs("Acme Labs", "acme-sandbox").
 		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow("org-1"))
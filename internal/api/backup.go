@@ -0,0 +1,195 @@
+package api
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/samhotchkiss/otter-camp/internal/backup"
+	"github.com/samhotchkiss/otter-camp/internal/middleware"
+	"github.com/samhotchkiss/otter-camp/internal/store"
+)
+
+// maxBackupUploadBytes bounds archives posted to validate/restore.
+const maxBackupUploadBytes = 2 << 30
+
+// BackupHandler serves full workspace archives (see internal/backup). Unlike
+// /api/export it covers every org-scoped table and the project git repos.
+type BackupHandler struct {
+	DB           *sql.DB
+	ProjectStore *store.ProjectStore
+}
+
+// Create handles GET /api/backup?since=&as_of=&skip_repos=
+func (h *BackupHandler) Create(w http.ResponseWriter, r *http.Request) {
+	if h.DB == nil {
+		sendJSON(w, http.StatusServiceUnavailable, errorResponse{Error: "database not available"})
+		return
+	}
+	orgID := middleware.WorkspaceFromContext(r.Context())
+	if orgID == "" {
+		sendJSON(w, http.StatusBadRequest, errorResponse{Error: "org_id is required"})
+		return
+	}
+
+	opts := backup.ExportOptions{OrgID: orgID}
+	var err error
+	if opts.Since, err = parseBackupTime(r.URL.Query().Get("since")); err != nil {
+		sendJSON(w, http.StatusBadRequest, errorResponse{Error: "since must be an RFC3339 timestamp"})
+		return
+	}
+	if opts.AsOf, err = parseBackupTime(r.URL.Query().Get("as_of")); err != nil {
+		sendJSON(w, http.StatusBadRequest, errorResponse{Error: "as_of must be an RFC3339 timestamp"})
+		return
+	}
+	if raw := strings.TrimSpace(r.URL.Query().Get("skip_repos")); raw != "" {
+		if opts.SkipRepos, err = strconv.ParseBool(raw); err != nil {
+			sendJSON(w, http.StatusBadRequest, errorResponse{Error: "skip_repos must be a boolean"})
+			return
+		}
+	}
+
+	// Export to a temp file first so a failure part way through is reported
+	// as an error instead of a truncated download.
+	file, err := os.CreateTemp("", "otter-backup-*.tar.gz")
+	if err != nil {
+		sendJSON(w, http.StatusInternalServerError, errorResponse{Error: "failed to create backup"})
+		return
+	}
+	defer os.Remove(file.Name())
+	defer file.Close()
+
+	manifest, err := backup.Export(r.Context(), h.DB, file, opts)
+	if err != nil {
+		sendJSON(w, http.StatusInternalServerError, errorResponse{Error: fmt.Sprintf("backup failed: %v", err)})
+		return
+	}
+	size, err := file.Seek(0, io.SeekCurrent)
+	if err != nil {
+		sendJSON(w, http.StatusInternalServerError, errorResponse{Error: "failed to create backup"})
+		return
+	}
+	if _, err := file.Seek(0, io.SeekStart); err != nil {
+		sendJSON(w, http.StatusInternalServerError, errorResponse{Error: "failed to create backup"})
+		return
+	}
+
+	filename := fmt.Sprintf("otter-camp-%s-%s.tar.gz", orgID, manifest.CreatedAt.Format("20060102-150405"))
+	w.Header().Set("Content-Type", "application/gzip")
+	w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="%s"`, filename))
+	w.Header().Set("Content-Length", strconv.FormatInt(size, 10))
+	w.WriteHeader(http.StatusOK)
+	_, _ = io.Copy(w, file)
+}
+
+// Validate handles POST /api/backup/validate with the archive as the body.
+func (h *BackupHandler) Validate(w http.ResponseWriter, r *http.Request) {
+	archive, ok := readBackupArchive(w, r)
+	if !ok {
+		return
+	}
+	defer archive.Close()
+	sendJSON(w, http.StatusOK, backup.Validate(archive))
+}
+
+// Restore handles POST /api/backup/restore?remap=&dry_run= with the archive as
+// the body. Rows are restored into the caller's workspace; remap (the
+// default) gives them new ids so the source org is left untouched.
+func (h *BackupHandler) Restore(w http.ResponseWriter, r *http.Request) {
+	if h.DB == nil {
+		sendJSON(w, http.StatusServiceUnavailable, errorResponse{Error: "database not available"})
+		return
+	}
+	orgID := middleware.WorkspaceFromContext(r.Context())
+	if orgID == "" {
+		sendJSON(w, http.StatusBadRequest, errorResponse{Error: "org_id is required"})
+		return
+	}
+
+	opts := backup.RestoreOptions{OrgID: orgID, Remap: true}
+	query := r.URL.Query()
+	var err error
+	if raw := strings.TrimSpace(query.Get("remap")); raw != "" {
+		if opts.Remap, err = strconv.ParseBool(raw); err != nil {
+			sendJSON(w, http.StatusBadRequest, errorResponse{Error: "remap must be a boolean"})
+			return
+		}
+	}
+	if raw := strings.TrimSpace(query.Get("dry_run")); raw != "" {
+		if opts.DryRun, err = strconv.ParseBool(raw); err != nil {
+			sendJSON(w, http.StatusBadRequest, errorResponse{Error: "dry_run must be a boolean"})
+			return
+		}
+	}
+	if h.ProjectStore != nil {
+		opts.PrepareRepo = func(ctx context.Context, projectID string) (string, error) {
+			if err := h.ProjectStore.InitProjectRepo(ctx, projectID); err != nil {
+				return "", err
+			}
+			return h.ProjectStore.GetRepoPath(ctx, projectID)
+		}
+	}
+
+	archive, ok := readBackupArchive(w, r)
+	if !ok {
+		return
+	}
+	defer archive.Close()
+
+	result, err := backup.Restore(r.Context(), h.DB, archive, opts)
+	if err != nil {
+		if errors.Is(err, backup.ErrInvalidArchive) {
+			sendJSON(w, http.StatusBadRequest, errorResponse{Error: err.Error()})
+			return
+		}
+		sendJSON(w, http.StatusInternalServerError, errorResponse{Error: fmt.Sprintf("restore failed: %v", err)})
+		return
+	}
+	if !opts.DryRun {
+		recordAudit(r, auditEvent{
+			OrgID:      orgID,
+			Action:     "workspace.restore",
+			TargetType: "workspace",
+			TargetID:   orgID,
+			After:      map[string]any{"remapped": result.Remapped, "tables": result.Tables, "repos": result.Repos},
+		})
+	}
+	sendJSON(w, http.StatusOK, result)
+}
+
+func readBackupArchive(w http.ResponseWriter, r *http.Request) (*backup.Archive, bool) {
+	if r.Body == nil {
+		sendJSON(w, http.StatusBadRequest, errorResponse{Error: "archive body is required"})
+		return nil, false
+	}
+	archive, err := backup.ReadArchive(http.MaxBytesReader(w, r.Body, maxBackupUploadBytes))
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			sendJSON(w, http.StatusRequestEntityTooLarge, errorResponse{Error: "archive too large"})
+			return nil, false
+		}
+		sendJSON(w, http.StatusBadRequest, errorResponse{Error: fmt.Sprintf("invalid archive: %v", err)})
+		return nil, false
+	}
+	return archive, true
+}
+
+func parseBackupTime(raw string) (*time.Time, error) {
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return nil, nil
+	}
+	parsed, err := time.Parse(time.RFC3339, raw)
+	if err != nil {
+		return nil, err
+	}
+	return &parsed, nil
+}
@@ -0,0 +1,93 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/samhotchkiss/otter-camp/internal/backup"
+	"github.com/samhotchkiss/otter-camp/internal/middleware"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBackupHandlerValidateReportsArchiveProblems(t *testing.T) {
+	orgID := "11111111-1111-1111-1111-111111111111"
+	var buf bytes.Buffer
+	writer := backup.NewWriter(&buf)
+	require.NoError(t, writer.WriteManifest(backup.Manifest{
+		Format:    backup.FormatName,
+		Version:   backup.FormatVersion,
+		OrgID:     orgID,
+		CreatedAt: time.Date(2026, 10, 18, 0, 0, 0, 0, time.UTC),
+		Tables: []backup.TableManifest{{
+			Name:       "labels",
+			File:       "tables/labels.jsonl",
+			Rows:       1,
+			OrgScoped:  true,
+			PrimaryKey: []string{"id"},
+			Columns:    []backup.Column{{Name: "id"}, {Name: "org_id"}, {Name: "name"}},
+		}},
+	}))
+	require.NoError(t, writer.WriteFile("tables/labels.jsonl", []byte(`{"id":"l1","org_id":"`+orgID+`"}`+"\n")))
+	require.NoError(t, writer.Close())
+
+	handler := &BackupHandler{}
+	req := httptest.NewRequest(http.MethodPost, "/api/backup/validate", &buf)
+	rec := httptest.NewRecorder()
+	handler.Validate(rec, req)
+
+	require.Equal(t, http.StatusOK, rec.Code)
+	var result backup.ValidationResult
+	require.NoError(t, json.NewDecoder(rec.Body).Decode(&result))
+	require.False(t, result.Valid)
+	require.Equal(t, []string{"labels[0]: missing name"}, result.Errors)
+	require.Equal(t, map[string]int{"labels": 1}, result.Tables)
+}
+
+func TestBackupHandlerRejectsNonArchiveBody(t *testing.T) {
+	handler := &BackupHandler{}
+	req := httptest.NewRequest(http.MethodPost, "/api/backup/validate", strings.NewReader(`{"tasks":[]}`))
+	rec := httptest.NewRecorder()
+	handler.Validate(rec, req)
+
+	require.Equal(t, http.StatusBadRequest, rec.Code)
+	require.Contains(t, rec.Body.String(), "invalid archive")
+}
+
+func TestBackupHandlerRequiresDatabaseAndWorkspace(t *testing.T) {
+	handler := &BackupHandler{}
+	rec := httptest.NewRecorder()
+	handler.Create(rec, httptest.NewRequest(http.MethodGet, "/api/backup", nil))
+	require.Equal(t, http.StatusServiceUnavailable, rec.Code)
+
+	rec = httptest.NewRecorder()
+	handler.Restore(rec, httptest.NewRequest(http.MethodPost, "/api/backup/restore", nil))
+	require.Equal(t, http.StatusServiceUnavailable, rec.Code)
+}
+
+func TestBackupHandlerCreateRejectsBadTimestamps(t *testing.T) {
+	// Parameters are checked before the database is touched.
+	db, err := sql.Open("postgres", "postgres://localhost/unused?sslmode=disable")
+	require.NoError(t, err)
+	defer db.Close()
+	handler := &BackupHandler{DB: db}
+	ctx := context.WithValue(context.Background(), middleware.WorkspaceIDKey, "11111111-1111-1111-1111-111111111111")
+
+	req := httptest.NewRequest(http.MethodGet, "/api/backup?since=yesterday", nil).WithContext(ctx)
+	rec := httptest.NewRecorder()
+	handler.Create(rec, req)
+	require.Equal(t, http.StatusBadRequest, rec.Code)
+	require.Contains(t, rec.Body.String(), "since must be an RFC3339 timestamp")
+
+	req = httptest.NewRequest(http.MethodPost, "/api/backup/restore?remap=maybe", nil).WithContext(ctx)
+	rec = httptest.NewRecorder()
+	handler.Restore(rec, req)
+	require.Equal(t, http.StatusBadRequest, rec.Code)
+	require.Contains(t, rec.Body.String(), "remap must be a boolean")
+}
@@ -10,31 +10,96 @@ const (
 )
 
 const (
-	CapabilityGitHubManualSync       = "github.sync.manual"
-	CapabilityGitHubConflictResolve  = "github.conflict.resolve"
-	CapabilityGitHubPublish          = "github.publish"
-	CapabilityGitHubIntegrationAdmin = "github.integration.manage"
-	CapabilityAdminConfigManage      = "admin.config.manage"
+	CapabilityGitHubManualSync        = "github.sync.manual"
+	CapabilityGitHubConflictResolve   = "github.conflict.resolve"
+	CapabilityGitHubPublish           = "github.publish"
+	CapabilityGitHubIntegrationAdmin  = "github.integration.manage"
+	CapabilityAdminConfigManage       = "admin.config.manage"
 	CapabilityOpenClawMigrationManage = "openclaw.migration.manage"
+	CapabilityWorkspaceBackup         = "workspace.backup"
+	CapabilityProjectsManage          = "projects.manage"
+	CapabilityProjectsWrite           = "projects.write"
+	CapabilityIssuesWrite             = "issues.write"
+	CapabilityPipelinesManage         = "pipelines.manage"
+	CapabilityAgentsManage            = "agents.manage"
+	CapabilityMemoryWrite             = "memory.write"
+	CapabilityJobsManage              = "jobs.manage"
+	CapabilityDeploysManage           = "deploys.manage"
+	CapabilityRolesManage             = "roles.manage"
+	CapabilityAuditRead               = "audit.read"
+	CapabilityUsageManage             = "usage.manage"
+	CapabilityExecApprovalsManage     = "exec_approvals.manage"
 )
 
+type capabilityDefinition struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+}
+
+// capabilityDefinitions is the catalog custom roles may draw from. Every
+// capability checked by a route must be listed here.
+var capabilityDefinitions = []capabilityDefinition{
+	{Name: CapabilityProjectsManage, Description: "Create, configure, archive and delete projects"},
+	{Name: CapabilityProjectsWrite, Description: "Commit content, chat and open pull requests in projects"},
+	{Name: CapabilityIssuesWrite, Description: "Create and update issues, comments, reviews and approvals"},
+	{Name: CapabilityPipelinesManage, Description: "Edit pipeline steps, roles, staffing, flow templates and workflows"},
+	{Name: CapabilityDeploysManage, Description: "Change project deploy configuration"},
+	{Name: CapabilityAgentsManage, Description: "Create, retire, reactivate, ping and reset agents"},
+	{Name: CapabilityMemoryWrite, Description: "Write and delete memory, shared knowledge and evaluations"},
+	{Name: CapabilityJobsManage, Description: "Create, change and run scheduled jobs and blackouts"},
+	{Name: CapabilityGitHubManualSync, Description: "Trigger GitHub syncs and issue imports"},
+	{Name: CapabilityGitHubConflictResolve, Description: "Resolve GitHub sync conflicts"},
+	{Name: CapabilityGitHubPublish, Description: "Publish projects to GitHub"},
+	{Name: CapabilityGitHubIntegrationAdmin, Description: "Connect GitHub and change integration settings"},
+	{Name: CapabilityOpenClawMigrationManage, Description: "Run and reset OpenClaw migrations"},
+	{Name: CapabilityWorkspaceBackup, Description: "Create and restore workspace backups"},
+	{Name: CapabilityAdminConfigManage, Description: "Change workspace configuration and SSO"},
+	{Name: CapabilityRolesManage, Description: "Manage custom roles and role bindings"},
+	{Name: CapabilityAuditRead, Description: "Read, export and verify the audit log"},
+	{Name: CapabilityUsageManage, Description: "Set model pricing and agent and project usage budgets"},
+	{Name: CapabilityExecApprovalsManage, Description: "Create, change, delete and dry-run exec approval rules"},
+}
+
 var roleCapabilityMatrix = map[string]map[string]struct{}{
 	RoleOwner: {
-		CapabilityGitHubManualSync:       {},
-		CapabilityGitHubConflictResolve:  {},
-		CapabilityGitHubPublish:          {},
-		CapabilityGitHubIntegrationAdmin: {},
-		CapabilityAdminConfigManage:      {},
+		CapabilityGitHubManualSync:        {},
+		CapabilityGitHubConflictResolve:   {},
+		CapabilityGitHubPublish:           {},
+		CapabilityGitHubIntegrationAdmin:  {},
+		CapabilityAdminConfigManage:       {},
 		CapabilityOpenClawMigrationManage: {},
+		CapabilityWorkspaceBackup:         {},
+		CapabilityProjectsManage:          {},
+		CapabilityProjectsWrite:           {},
+		CapabilityIssuesWrite:             {},
+		CapabilityPipelinesManage:         {},
+		CapabilityAgentsManage:            {},
+		CapabilityMemoryWrite:             {},
+		CapabilityJobsManage:              {},
+		CapabilityDeploysManage:           {},
+		CapabilityRolesManage:             {},
+		CapabilityAuditRead:               {},
+		CapabilityUsageManage:             {},
+		CapabilityExecApprovalsManage:     {},
 	},
 	RoleMaintainer: {
-		CapabilityGitHubManualSync:      {},
-		CapabilityGitHubConflictResolve: {},
-		CapabilityGitHubPublish:         {},
+		CapabilityGitHubManualSync:        {},
+		CapabilityGitHubConflictResolve:   {},
+		CapabilityGitHubPublish:           {},
 		CapabilityOpenClawMigrationManage: {},
+		CapabilityProjectsManage:          {},
+		CapabilityProjectsWrite:           {},
+		CapabilityIssuesWrite:             {},
+		CapabilityPipelinesManage:         {},
+		CapabilityMemoryWrite:             {},
+		CapabilityJobsManage:              {},
+		CapabilityDeploysManage:           {},
 	},
 	RoleMember: {
 		CapabilityGitHubManualSync: {},
+		CapabilityProjectsWrite:    {},
+		CapabilityIssuesWrite:      {},
+		CapabilityMemoryWrite:      {},
 	},
 	RoleViewer: {},
 }
@@ -60,3 +125,25 @@ func roleAllowsCapability(role, capability string) bool {
 	_, ok := caps[capability]
 	return ok
 }
+
+func isKnownCapability(capability string) bool {
+	for _, definition := range capabilityDefinitions {
+		if definition.Name == capability {
+			return true
+		}
+	}
+	return false
+}
+
+// builtinRoleCapabilities lists a built-in role's capabilities in catalog
+// order.
+func builtinRoleCapabilities(role string) []string {
+	caps := roleCapabilityMatrix[role]
+	out := make([]string, 0, len(caps))
+	for _, definition := range capabilityDefinitions {
+		if _, ok := caps[definition.Name]; ok {
+			out = append(out, definition.Name)
+		}
+	}
+	return out
+}
@@ -16,17 +16,31 @@ func TestRoleAllowsCapabilityMatrix(t *testing.T) {
 		{name: "owner can publish", role: RoleOwner, capability: CapabilityGitHubPublish, allowed: true},
 		{name: "owner can manage integration", role: RoleOwner, capability: CapabilityGitHubIntegrationAdmin, allowed: true},
 		{name: "owner can manage admin config", role: RoleOwner, capability: CapabilityAdminConfigManage, allowed: true},
+		{name: "owner can back up workspace", role: RoleOwner, capability: CapabilityWorkspaceBackup, allowed: true},
 		{name: "maintainer can run manual sync", role: RoleMaintainer, capability: CapabilityGitHubManualSync, allowed: true},
 		{name: "maintainer can resolve conflicts", role: RoleMaintainer, capability: CapabilityGitHubConflictResolve, allowed: true},
 		{name: "maintainer can publish", role: RoleMaintainer, capability: CapabilityGitHubPublish, allowed: true},
 		{name: "maintainer cannot manage integration", role: RoleMaintainer, capability: CapabilityGitHubIntegrationAdmin, allowed: false},
 		{name: "maintainer cannot manage admin config", role: RoleMaintainer, capability: CapabilityAdminConfigManage, allowed: false},
+		{name: "maintainer cannot back up workspace", role: RoleMaintainer, capability: CapabilityWorkspaceBackup, allowed: false},
 		{name: "member can run manual sync", role: RoleMember, capability: CapabilityGitHubManualSync, allowed: true},
 		{name: "member cannot resolve conflicts", role: RoleMember, capability: CapabilityGitHubConflictResolve, allowed: false},
 		{name: "member cannot publish", role: RoleMember, capability: CapabilityGitHubPublish, allowed: false},
 		{name: "member cannot manage admin config", role: RoleMember, capability: CapabilityAdminConfigManage, allowed: false},
 		{name: "viewer cannot run manual sync", role: RoleViewer, capability: CapabilityGitHubManualSync, allowed: false},
 		{name: "viewer cannot manage admin config", role: RoleViewer, capability: CapabilityAdminConfigManage, allowed: false},
+		{name: "maintainer cannot manage agents", role: RoleMaintainer, capability: CapabilityAgentsManage, allowed: false},
+		{name: "maintainer cannot manage roles", role: RoleMaintainer, capability: CapabilityRolesManage, allowed: false},
+		{name: "owner can read audit log", role: RoleOwner, capability: CapabilityAuditRead, allowed: true},
+		{name: "maintainer cannot read audit log", role: RoleMaintainer, capability: CapabilityAuditRead, allowed: false},
+		{name: "owner can manage usage budgets", role: RoleOwner, capability: CapabilityUsageManage, allowed: true},
+		{name: "maintainer cannot manage usage budgets", role: RoleMaintainer, capability: CapabilityUsageManage, allowed: false},
+		{name: "owner can manage exec approval rules", role: RoleOwner, capability: CapabilityExecApprovalsManage, allowed: true},
+		{name: "maintainer cannot manage exec approval rules", role: RoleMaintainer, capability: CapabilityExecApprovalsManage, allowed: false},
+		{name: "member can write issues", role: RoleMember, capability: CapabilityIssuesWrite, allowed: true},
+		{name: "member cannot manage pipelines", role: RoleMember, capability: CapabilityPipelinesManage, allowed: false},
+		{name: "member cannot manage jobs", role: RoleMember, capability: CapabilityJobsManage, allowed: false},
+		{name: "viewer cannot write issues", role: RoleViewer, capability: CapabilityIssuesWrite, allowed: false},
 		{name: "unknown role denied", role: "nobody", capability: CapabilityGitHubManualSync, allowed: false},
 		{name: "empty capability denied", role: RoleOwner, capability: "", allowed: false},
 	}
@@ -41,3 +55,28 @@ func TestRoleAllowsCapabilityMatrix(t *testing.T) {
 		})
 	}
 }
+
+func TestCapabilityCatalogCoversRoleMatrix(t *testing.T) {
+	t.Parallel()
+
+	seen := map[string]bool{}
+	for _, definition := range capabilityDefinitions {
+		if seen[definition.Name] {
+			t.Fatalf("capability %q listed twice", definition.Name)
+		}
+		seen[definition.Name] = true
+	}
+	for role, caps := range roleCapabilityMatrix {
+		for capability := range caps {
+			if !isKnownCapability(capability) {
+				t.Fatalf("role %q grants %q, which is missing from the catalog", role, capability)
+			}
+		}
+	}
+	if got := builtinRoleCapabilities(RoleOwner); len(got) != len(capabilityDefinitions) {
+		t.Fatalf("owner has %d capabilities, want all %d", len(got), len(capabilityDefinitions))
+	}
+	if got := builtinRoleCapabilities(RoleViewer); len(got) != 0 {
+		t.Fatalf("viewer capabilities = %v, want none", got)
+	}
+}
@@ -7,7 +7,9 @@ import (
 	"net/http"
 	"strings"
 
+	"github.com/go-chi/chi/v5"
 	"github.com/samhotchkiss/otter-camp/internal/middleware"
+	"github.com/samhotchkiss/otter-camp/internal/store"
 )
 
 type forbiddenCapabilityResponse struct {
@@ -15,12 +17,47 @@ type forbiddenCapabilityResponse struct {
 	Capability string `json:"capability"`
 }
 
+// capabilityScope names the project a request acts on, so project-scoped
+// role bindings apply. An empty result means an org-wide check.
+type capabilityScope func(r *http.Request, db *sql.DB, orgID string) (string, error)
+
 // RequireCapability enforces session authentication and role-based capability checks.
 func RequireCapability(db *sql.DB, capability string) func(http.Handler) http.Handler {
+	return requireCapability(db, capability, nil, false)
+}
+
+// RequireProjectCapability is RequireCapability with project-scoped role
+// bindings taken into account.
+func RequireProjectCapability(db *sql.DB, capability string, scope capabilityScope) func(http.Handler) http.Handler {
+	return requireCapability(db, capability, scope, false)
+}
+
+// AuthorizeCapability is RequireProjectCapability for routes agents and the
+// bridge also call. Besides a user session it accepts an API key, checked
+// against the current capabilities of the user who minted it, and the
+// OpenClaw sync secret. Anything else is rejected.
+func AuthorizeCapability(db *sql.DB, capability string, scope capabilityScope) func(http.Handler) http.Handler {
+	return requireCapability(db, capability, scope, true)
+}
+
+func requireCapability(db *sql.DB, capability string, scope capabilityScope, allowAgentCredentials bool) func(http.Handler) http.Handler {
 	capability = strings.TrimSpace(capability)
 
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			var key *store.APIKey
+			if allowAgentCredentials {
+				key = apiKeyFromContext(r.Context())
+				if key == nil && hasOpenClawBridgeCredential(r) {
+					next.ServeHTTP(w, r)
+					return
+				}
+			}
+			if key == nil && extractSessionToken(r) == "" {
+				handleCapabilityAuthError(w, errMissingAuthentication, capability)
+				return
+			}
+
 			if db == nil {
 				sendJSON(w, http.StatusServiceUnavailable, errorResponse{Error: "database not available"})
 				return
@@ -31,13 +68,33 @@ func RequireCapability(db *sql.DB, capability string) func(http.Handler) http.Ha
 				return
 			}
 
-			identity, err := requireSessionIdentity(r.Context(), db, r)
+			var identity sessionIdentity
+			var err error
+			if key != nil {
+				identity, err = apiKeyCreatorIdentity(r.Context(), db, key)
+			} else {
+				identity, err = requireSessionIdentity(r.Context(), db, r)
+			}
 			if err != nil {
 				handleCapabilityAuthError(w, err, capability)
 				return
 			}
 
-			if !roleAllowsCapability(identity.Role, capability) {
+			projectID := ""
+			if scope != nil {
+				projectID, err = scope(r, db, identity.OrgID)
+				if err != nil {
+					sendJSON(w, http.StatusInternalServerError, errorResponse{Error: "failed to resolve permission scope"})
+					return
+				}
+			}
+
+			allowed, err := identityHasCapability(r.Context(), db, identity, capability, projectID)
+			if err != nil {
+				sendJSON(w, http.StatusInternalServerError, errorResponse{Error: "failed to check permissions"})
+				return
+			}
+			if !allowed {
 				sendJSON(w, http.StatusForbidden, forbiddenCapabilityResponse{
 					Error:      "forbidden",
 					Capability: capability,
@@ -45,6 +102,11 @@ func RequireCapability(db *sql.DB, capability string) func(http.Handler) http.Ha
 				return
 			}
 
+			if key != nil {
+				next.ServeHTTP(w, r)
+				return
+			}
+
 			ctx := context.WithValue(r.Context(), middleware.WorkspaceIDKey, identity.OrgID)
 			ctx = context.WithValue(ctx, middleware.UserIDKey, identity.UserID)
 			next.ServeHTTP(w, r.WithContext(ctx))
@@ -52,13 +114,122 @@ func RequireCapability(db *sql.DB, capability string) func(http.Handler) http.Ha
 	}
 }
 
+// identityHasCapability checks the user's base role first and only then the
+// role bindings (org-wide, plus projectID's when set).
+func identityHasCapability(ctx context.Context, db *sql.DB, identity sessionIdentity, capability, projectID string) (bool, error) {
+	if roleAllowsCapability(identity.Role, capability) {
+		return true, nil
+	}
+	grants, err := store.NewRoleStore(db).GrantsForUser(ctx, identity.OrgID, identity.UserID, projectID)
+	if err != nil {
+		return false, err
+	}
+	for _, grant := range grants {
+		if grant.Builtin {
+			if roleAllowsCapability(grant.Role, capability) {
+				return true, nil
+			}
+			continue
+		}
+		for _, granted := range grant.Capabilities {
+			if granted == capability {
+				return true, nil
+			}
+		}
+	}
+	return false, nil
+}
+
+// projectScope reads the project id from a route parameter.
+func projectScope(param string) capabilityScope {
+	return func(r *http.Request, _ *sql.DB, _ string) (string, error) {
+		projectID := strings.TrimSpace(chi.URLParam(r, param))
+		if !uuidRegex.MatchString(projectID) {
+			return "", nil
+		}
+		return projectID, nil
+	}
+}
+
+// bodyProjectScope reads the project id from a field of the JSON body,
+// leaving the body for the handler.
+func bodyProjectScope(field string) capabilityScope {
+	return func(r *http.Request, _ *sql.DB, _ string) (string, error) {
+		projectID, _ := peekAPIKeyRequestBody(r)[field].(string)
+		projectID = strings.TrimSpace(projectID)
+		if !uuidRegex.MatchString(projectID) {
+			return "", nil
+		}
+		return projectID, nil
+	}
+}
+
+// requestHasProjectCapability checks one more project for a request that
+// already passed AuthorizeCapability, such as the destination of a move.
+// API keys must also be allowed on the project; the bridge is trusted.
+func requestHasProjectCapability(r *http.Request, db *sql.DB, capability, projectID string) (bool, error) {
+	var identity sessionIdentity
+	var err error
+	if key := apiKeyFromContext(r.Context()); key != nil {
+		if !key.AllowsProject(projectID) {
+			return false, nil
+		}
+		identity, err = apiKeyCreatorIdentity(r.Context(), db, key)
+	} else if hasOpenClawBridgeCredential(r) {
+		return true, nil
+	} else {
+		identity, err = requireSessionIdentity(r.Context(), db, r)
+	}
+	if err != nil {
+		return false, err
+	}
+	return identityHasCapability(r.Context(), db, identity, capability, projectID)
+}
+
+// issueProjectScope finds the project of the issue named by a route
+// parameter. Unknown issues fall back to an org-wide check; the handler
+// reports the 404.
+func issueProjectScope(param string) capabilityScope {
+	return func(r *http.Request, db *sql.DB, orgID string) (string, error) {
+		issueID := strings.TrimSpace(chi.URLParam(r, param))
+		if !uuidRegex.MatchString(issueID) {
+			return "", nil
+		}
+		conn, err := store.WithWorkspaceID(r.Context(), db, orgID)
+		if err != nil {
+			return "", err
+		}
+		defer conn.Close()
+
+		var projectID string
+		err = conn.QueryRowContext(
+			r.Context(),
+			`SELECT project_id::text FROM project_issues WHERE id = $1`,
+			issueID,
+		).Scan(&projectID)
+		if errors.Is(err, sql.ErrNoRows) {
+			return "", nil
+		}
+		return projectID, err
+	}
+}
+
+func isUserCredential(token string) bool {
+	for _, prefix := range []string{"oc_sess_", "oc_magic_", "oc_local_", "oc_git_"} {
+		if strings.HasPrefix(token, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
 func handleCapabilityAuthError(w http.ResponseWriter, err error, capability string) {
 	switch {
 	case errors.Is(err, errMissingAuthentication),
 		errors.Is(err, errInvalidSessionToken),
 		errors.Is(err, errAuthentication):
 		sendJSON(w, http.StatusUnauthorized, errorResponse{Error: err.Error()})
-	case errors.Is(err, errWorkspaceMismatch):
+	case errors.Is(err, errWorkspaceMismatch), errors.Is(err, errAPIKeyCreatorMissing):
 		sendJSON(w, http.StatusForbidden, forbiddenCapabilityResponse{
 			Error:      err.Error(),
 			Capability: capability,
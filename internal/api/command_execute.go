@@ -1,6 +1,8 @@
 package api
 
 import (
+	"bytes"
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -8,6 +10,10 @@ import (
 	"net/http/httptest"
 	"net/url"
 	"strings"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/samhotchkiss/otter-camp/internal/middleware"
+	"github.com/samhotchkiss/otter-camp/internal/store"
 )
 
 type CommandShortcutModifiers struct {
@@ -263,6 +269,17 @@ func executeCreateCommand(r *http.Request, params json.RawMessage) (CommandExecu
 			RedirectURL: fmt.Sprintf("/tasks/%d", task.Number),
 			Result:      task,
 		}, nil
+	case "issue":
+		issue, createErr := executeCreateIssueCommand(r, params)
+		if createErr != nil {
+			return CommandExecuteResponse{}, createErr
+		}
+		return CommandExecuteResponse{
+			OK:          true,
+			Type:        "create",
+			RedirectURL: fmt.Sprintf("/projects/%s/issues/%s", issue.ProjectID, issue.ID),
+			Result:      issue,
+		}, nil
 	default:
 		return CommandExecuteResponse{}, &commandExecuteError{status: http.StatusBadRequest, msg: "unsupported resource"}
 	}
@@ -382,6 +399,80 @@ func executeCreateTaskCommand(r *http.Request, params json.RawMessage) (Task, *c
 	return task, nil
 }
 
+// executeCreateIssueCommand creates a project issue through the issues
+// handler so templates, flows and default labels apply exactly as they do for
+// POST /api/projects/{id}/issues.
+func executeCreateIssueCommand(r *http.Request, params json.RawMessage) (issueSummaryPayload, *commandExecuteError) {
+	rawParams, err := parseParamsObject(params)
+	if err != nil {
+		return issueSummaryPayload{}, &commandExecuteError{status: http.StatusBadRequest, msg: "invalid parameters"}
+	}
+
+	orgID, err := parseRequiredTrimmedStringFieldAny(rawParams, "org_id", "orgId")
+	if err != nil {
+		return issueSummaryPayload{}, &commandExecuteError{status: http.StatusBadRequest, msg: err.Error()}
+	}
+	if !uuidRegex.MatchString(orgID) {
+		return issueSummaryPayload{}, &commandExecuteError{status: http.StatusBadRequest, msg: "invalid org_id"}
+	}
+	projectID, err := parseRequiredTrimmedStringFieldAny(rawParams, "project_id", "projectId")
+	if err != nil {
+		return issueSummaryPayload{}, &commandExecuteError{status: http.StatusBadRequest, msg: err.Error()}
+	}
+	if !uuidRegex.MatchString(projectID) {
+		return issueSummaryPayload{}, &commandExecuteError{status: http.StatusBadRequest, msg: "invalid project_id"}
+	}
+
+	body := map[string]json.RawMessage{}
+	for _, key := range []string{"title", "body", "priority", "owner_agent_id", "template_id", "fields"} {
+		if value, ok := rawParams[key]; ok {
+			body[key] = value
+		}
+	}
+	if value, ok := rawParams["templateId"]; ok {
+		if _, set := body["template_id"]; !set {
+			body["template_id"] = value
+		}
+	}
+	payload, err := json.Marshal(body)
+	if err != nil {
+		return issueSummaryPayload{}, &commandExecuteError{status: http.StatusBadRequest, msg: "invalid parameters"}
+	}
+
+	db, dbErr := getTasksDB()
+	if dbErr != nil {
+		return issueSummaryPayload{}, &commandExecuteError{status: http.StatusServiceUnavailable, msg: dbErr.Error()}
+	}
+	handler := &IssuesHandler{
+		IssueStore:    store.NewProjectIssueStore(db),
+		ProjectStore:  store.NewProjectStore(db),
+		FlowStore:     store.NewProjectFlowStore(db),
+		TemplateStore: store.NewIssueTemplateStore(db),
+		DB:            db,
+	}
+
+	routeCtx := chi.NewRouteContext()
+	routeCtx.URLParams.Add("id", projectID)
+	ctx := context.WithValue(r.Context(), middleware.WorkspaceIDKey, orgID)
+	ctx = context.WithValue(ctx, chi.RouteCtxKey, routeCtx)
+	req := httptest.NewRequest(http.MethodPost, "/api/projects/"+projectID+"/issues", bytes.NewReader(payload)).WithContext(ctx)
+	rec := httptest.NewRecorder()
+	handler.CreateIssue(rec, req)
+
+	if rec.Code != http.StatusCreated {
+		msg := extractErrorMessage(rec.Body.Bytes())
+		if msg == "" {
+			msg = "failed to create issue"
+		}
+		return issueSummaryPayload{}, &commandExecuteError{status: rec.Code, msg: msg}
+	}
+	var issue issueSummaryPayload
+	if err := json.Unmarshal(rec.Body.Bytes(), &issue); err != nil {
+		return issueSummaryPayload{}, &commandExecuteError{status: http.StatusInternalServerError, msg: "failed to create issue"}
+	}
+	return issue, nil
+}
+
 func executeSearchCommand(r *http.Request, params json.RawMessage) (CommandExecuteResponse, *commandExecuteError) {
 	rawParams, err := parseParamsObject(params)
 	if err != nil {
@@ -11,6 +11,7 @@ import (
 	"time"
 
 	_ "github.com/lib/pq"
+	"github.com/samhotchkiss/otter-camp/internal/store"
 	"github.com/stretchr/testify/require"
 )
 
@@ -235,6 +236,18 @@ func TestCommandExecuteCreateValidation(t *testing.T) {
 			status: http.StatusBadRequest,
 			errMsg: "missing title",
 		},
+		{
+			name:   "issue missing project_id",
+			body:   `{"type":"create","parameters":{"resource":"issue","org_id":"00000000-0000-0000-0000-000000000000","title":"hello"}}`,
+			status: http.StatusBadRequest,
+			errMsg: "missing project_id",
+		},
+		{
+			name:   "issue invalid project_id",
+			body:   `{"type":"create","parameters":{"resource":"issue","org_id":"00000000-0000-0000-0000-000000000000","project_id":"blog"}}`,
+			status: http.StatusBadRequest,
+			errMsg: "invalid project_id",
+		},
 	} {
 		tc := tc
 		t.Run(tc.name, func(t *testing.T) {
@@ -351,6 +364,79 @@ func TestCommandExecuteCreateTaskIntegration(t *testing.T) {
 	require.Equal(t, "queued", created.Status)
 }
 
+func TestCommandExecuteCreateIssueFromTemplateIntegration(t *testing.T) {
+	connStr := feedTestDatabaseURL(t)
+	resetFeedDatabase(t, connStr)
+	t.Setenv("DATABASE_URL", connStr)
+
+	tasksDBOnce = sync.Once{}
+	tasksDBErr = nil
+	if tasksDB != nil {
+		_ = tasksDB.Close()
+		tasksDB = nil
+	}
+	searchDBOnce = sync.Once{}
+	searchDBErr = nil
+	if searchDB != nil {
+		_ = searchDB.Close()
+		searchDB = nil
+	}
+
+	db, err := sql.Open("postgres", connStr)
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		_ = db.Close()
+	})
+
+	orgID := insertFeedOrganization(t, db, "command-execute-issue")
+	projectID := insertProjectTestProject(t, db, orgID, "Command Issue Project")
+	template, err := store.NewIssueTemplateStore(db).Save(issueTestCtx(orgID), orgID, store.SaveIssueTemplateInput{
+		ProjectID: projectID,
+		Name:      "Bug report",
+		Body:      "Severity: {{severity}}",
+		Fields:    []store.IssueTemplateField{{Key: "severity", Type: "select", Options: []string{"Low", "High"}, Required: true}},
+	})
+	require.NoError(t, err)
+
+	router := NewRouter()
+	req := httptest.NewRequest(http.MethodPost, "/api/commands/execute", bytes.NewBufferString(`{
+		"type": "create",
+		"parameters": {
+			"resource": "issue",
+			"org_id": "`+orgID+`",
+			"project_id": "`+projectID+`",
+			"title": "Palette bug",
+			"template_id": "bug report",
+			"fields": {"severity": "low"}
+		}
+	}`))
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+
+	var payload commandExecuteTestResponse
+	require.NoError(t, json.NewDecoder(rec.Body).Decode(&payload))
+	var created issueSummaryPayload
+	require.NoError(t, json.Unmarshal(payload.Result, &created))
+	require.Equal(t, "/projects/"+projectID+"/issues/"+created.ID, payload.RedirectURL)
+	require.NotNil(t, created.Body)
+	require.Equal(t, "Severity: Low", *created.Body)
+
+	req = httptest.NewRequest(http.MethodGet, "/api/commands/search?q=bug&org_id="+orgID, nil)
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+	require.Equal(t, http.StatusOK, rec.Code)
+	var searchResp CommandSearchResponse
+	require.NoError(t, json.NewDecoder(rec.Body).Decode(&searchResp))
+	found := false
+	for _, result := range searchResp.Results {
+		if result.Type == "issue_template" && result.ID == template.ID {
+			found = true
+		}
+	}
+	require.True(t, found)
+}
+
 func TestCommandExecuteSearchCommandsIntegration(t *testing.T) {
 	connStr := feedTestDatabaseURL(t)
 	resetFeedDatabase(t, connStr)
@@ -8,6 +8,8 @@ import (
 	"sort"
 	"strings"
 	"time"
+
+	"github.com/samhotchkiss/otter-camp/internal/store"
 )
 
 const (
@@ -266,6 +268,35 @@ func CommandSearchHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// Issue templates are few per org and sit behind row-level security, so
+	// load them through the store and score them here.
+	templates, err := store.NewIssueTemplateStore(db).List(ctx, orgID, "")
+	if err != nil {
+		sendJSON(w, http.StatusInternalServerError, errorResponse{Error: "search query failed"})
+		return
+	}
+	for _, template := range templates {
+		desc := "Issue template"
+		if template.Description != nil && strings.TrimSpace(*template.Description) != "" {
+			desc = strings.TrimSpace(*template.Description)
+		}
+		result := CommandSearchResult{
+			ID:          template.ID,
+			Type:        "issue_template",
+			Title:       template.Name,
+			Description: desc,
+			Action:      fmt.Sprintf("/projects/%s?issue_template=%s", template.ProjectID, template.ID),
+		}
+		score := commandSearchScore(result, q)
+		if score >= 0 {
+			candidates = append(candidates, commandSearchCandidate{
+				result:   result,
+				score:    score,
+				sortTime: template.UpdatedAt,
+			})
+		}
+	}
+
 	recentRows, err := db.QueryContext(ctx, commandSearchRecentSQL, orgID, pattern, commandSearchCandidateLimit)
 	if err != nil {
 		sendJSON(w, http.StatusInternalServerError, errorResponse{Error: "search query failed"})
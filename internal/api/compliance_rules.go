@@ -113,7 +113,15 @@ func (h *ComplianceRulesHandler) Create(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
-	sendJSON(w, http.StatusCreated, toComplianceRulePayload(*created))
+	payload := toComplianceRulePayload(*created)
+	recordAudit(r, auditEvent{
+		OrgID:      orgID,
+		Action:     "compliance_rule.create",
+		TargetType: "compliance_rule",
+		TargetID:   created.ID,
+		After:      payload,
+	})
+	sendJSON(w, http.StatusCreated, payload)
 }
 
 func (h *ComplianceRulesHandler) Patch(w http.ResponseWriter, r *http.Request) {
@@ -148,6 +156,12 @@ func (h *ComplianceRulesHandler) Patch(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	var before *complianceRulePayload
+	if existing, err := h.Store.GetByID(r.Context(), orgID, ruleID); err == nil {
+		payload := toComplianceRulePayload(*existing)
+		before = &payload
+	}
+
 	updated, err := h.Store.Update(r.Context(), orgID, ruleID, store.UpdateComplianceRuleInput{
 		Title:            req.Title,
 		Description:      req.Description,
@@ -160,7 +174,16 @@ func (h *ComplianceRulesHandler) Patch(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	sendJSON(w, http.StatusOK, toComplianceRulePayload(*updated))
+	payload := toComplianceRulePayload(*updated)
+	recordAudit(r, auditEvent{
+		OrgID:      orgID,
+		Action:     "compliance_rule.update",
+		TargetType: "compliance_rule",
+		TargetID:   ruleID,
+		Before:     before,
+		After:      payload,
+	})
+	sendJSON(w, http.StatusOK, payload)
 }
 
 func (h *ComplianceRulesHandler) Disable(w http.ResponseWriter, r *http.Request) {
@@ -181,6 +204,11 @@ func (h *ComplianceRulesHandler) Disable(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
+	existing, err := h.Store.GetByID(r.Context(), orgID, ruleID)
+	if err != nil {
+		handleComplianceRuleStoreError(w, err)
+		return
+	}
 	if err := h.Store.SetEnabled(r.Context(), orgID, ruleID, false); err != nil {
 		handleComplianceRuleStoreError(w, err)
 		return
@@ -191,7 +219,16 @@ func (h *ComplianceRulesHandler) Disable(w http.ResponseWriter, r *http.Request)
 		handleComplianceRuleStoreError(w, err)
 		return
 	}
-	sendJSON(w, http.StatusOK, toComplianceRulePayload(*updated))
+	payload := toComplianceRulePayload(*updated)
+	recordAudit(r, auditEvent{
+		OrgID:      orgID,
+		Action:     "compliance_rule.disable",
+		TargetType: "compliance_rule",
+		TargetID:   ruleID,
+		Before:     toComplianceRulePayload(*existing),
+		After:      payload,
+	})
+	sendJSON(w, http.StatusOK, payload)
 }
 
 func toComplianceRulePayload(rule store.ComplianceRule) complianceRulePayload {
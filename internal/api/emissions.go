@@ -1,10 +1,13 @@
 package api
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log"
 	"net/http"
+	"os"
 	"strconv"
 	"strings"
 	"sync"
@@ -12,6 +15,7 @@ import (
 	"time"
 
 	"github.com/samhotchkiss/otter-camp/internal/middleware"
+	"github.com/samhotchkiss/otter-camp/internal/store"
 	"github.com/samhotchkiss/otter-camp/internal/ws"
 )
 
@@ -22,6 +26,11 @@ const (
 	maxEmissionDetailLength   = 5000
 )
 
+var (
+	emissionStreamPollInterval      = time.Second
+	emissionStreamKeepaliveInterval = 15 * time.Second
+)
+
 var emissionIDSequence uint64
 var emissionEventJSONMarshal = json.Marshal
 
@@ -51,10 +60,12 @@ type EmissionProgress struct {
 }
 
 type EmissionFilter struct {
-	OrgID     string
-	ProjectID string
-	IssueID   string
-	SourceID  string
+	OrgID      string
+	ProjectID  string
+	IssueID    string
+	SourceID   string
+	SourceType string
+	Kind       string
 }
 
 type EmissionBuffer struct {
@@ -94,36 +105,76 @@ func (b *EmissionBuffer) Recent(limit int, filter EmissionFilter) []Emission {
 		limit = 25
 	}
 
-	projectID := strings.TrimSpace(filter.ProjectID)
-	issueID := strings.TrimSpace(filter.IssueID)
-	sourceID := strings.TrimSpace(filter.SourceID)
-	orgID := strings.TrimSpace(filter.OrgID)
-
 	b.mu.RLock()
 	defer b.mu.RUnlock()
 
 	out := make([]Emission, 0, min(limit, len(b.emissions)))
 	for i := len(b.emissions) - 1; i >= 0 && len(out) < limit; i-- {
-		emission := b.emissions[i]
-		if orgID != "" && strings.TrimSpace(emission.OrgID) != orgID {
-			continue
-		}
-		if sourceID != "" && strings.TrimSpace(emission.SourceID) != sourceID {
-			continue
+		if filter.matches(b.emissions[i]) {
+			out = append(out, b.emissions[i])
 		}
-		if projectID != "" {
-			if emission.Scope == nil || emission.Scope.ProjectID == nil || strings.TrimSpace(*emission.Scope.ProjectID) != projectID {
-				continue
+	}
+	return out
+}
+
+// After returns buffered emissions pushed after afterID, oldest first. The
+// second result is false when afterID is no longer in the buffer.
+func (b *EmissionBuffer) After(afterID string, limit int, filter EmissionFilter) ([]Emission, bool) {
+	if limit <= 0 {
+		limit = 25
+	}
+	afterID = strings.TrimSpace(afterID)
+
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	start := 0
+	if afterID != "" {
+		start = -1
+		for i := len(b.emissions) - 1; i >= 0; i-- {
+			if b.emissions[i].ID == afterID {
+				start = i + 1
+				break
 			}
 		}
-		if issueID != "" {
-			if emission.Scope == nil || emission.Scope.IssueID == nil || strings.TrimSpace(*emission.Scope.IssueID) != issueID {
-				continue
-			}
+		if start < 0 {
+			return nil, false
 		}
-		out = append(out, emission)
 	}
-	return out
+
+	out := make([]Emission, 0)
+	for i := start; i < len(b.emissions) && len(out) < limit; i++ {
+		if filter.matches(b.emissions[i]) {
+			out = append(out, b.emissions[i])
+		}
+	}
+	return out, true
+}
+
+func (f EmissionFilter) matches(emission Emission) bool {
+	if orgID := strings.TrimSpace(f.OrgID); orgID != "" && strings.TrimSpace(emission.OrgID) != orgID {
+		return false
+	}
+	if sourceID := strings.TrimSpace(f.SourceID); sourceID != "" && strings.TrimSpace(emission.SourceID) != sourceID {
+		return false
+	}
+	if sourceType := strings.TrimSpace(strings.ToLower(f.SourceType)); sourceType != "" && emission.SourceType != sourceType {
+		return false
+	}
+	if kind := strings.TrimSpace(strings.ToLower(f.Kind)); kind != "" && emission.Kind != kind {
+		return false
+	}
+	if projectID := strings.TrimSpace(f.ProjectID); projectID != "" {
+		if emission.Scope == nil || emission.Scope.ProjectID == nil || strings.TrimSpace(*emission.Scope.ProjectID) != projectID {
+			return false
+		}
+	}
+	if issueID := strings.TrimSpace(f.IssueID); issueID != "" {
+		if emission.Scope == nil || emission.Scope.IssueID == nil || strings.TrimSpace(*emission.Scope.IssueID) != issueID {
+			return false
+		}
+	}
+	return true
 }
 
 func (b *EmissionBuffer) LatestBySource(orgID string, sourceID string) *Emission {
@@ -155,7 +206,10 @@ func min(a, b int) int {
 
 type EmissionsHandler struct {
 	Buffer *EmissionBuffer
-	Hub    emissionBroadcaster
+	// Store persists emissions when a database is configured; Buffer is the
+	// fallback for local runs without one.
+	Store *store.EmissionStore
+	Hub   emissionBroadcaster
 }
 
 type emissionBroadcaster interface {
@@ -172,8 +226,47 @@ type emissionListResponse struct {
 	Total int        `json:"total"`
 }
 
+var errEmissionCursorNotFound = errors.New("after cursor not found")
+
+// emissionStreamNotifications wakes SSE streams on this replica as soon as an
+// emission is recorded; streams also poll so they see other replicas' writes.
+var emissionStreamNotifications = newEmissionNotifier()
+
+type emissionNotifier struct {
+	mu      sync.Mutex
+	waiters map[string]chan struct{}
+}
+
+func newEmissionNotifier() *emissionNotifier {
+	return &emissionNotifier{waiters: make(map[string]chan struct{})}
+}
+
+func (n *emissionNotifier) wait(orgID string) <-chan struct{} {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	ch, ok := n.waiters[orgID]
+	if !ok {
+		ch = make(chan struct{})
+		n.waiters[orgID] = ch
+	}
+	return ch
+}
+
+func (n *emissionNotifier) notify(orgID string) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	if ch, ok := n.waiters[orgID]; ok {
+		close(ch)
+		delete(n.waiters, orgID)
+	}
+}
+
+func (h *EmissionsHandler) available() bool {
+	return h.Store != nil || h.Buffer != nil
+}
+
 func (h *EmissionsHandler) Recent(w http.ResponseWriter, r *http.Request) {
-	if h.Buffer == nil {
+	if !h.available() {
 		sendJSON(w, http.StatusServiceUnavailable, errorResponse{Error: "emission buffer not available"})
 		return
 	}
@@ -196,17 +289,158 @@ func (h *EmissionsHandler) Recent(w http.ResponseWriter, r *http.Request) {
 		limit = maxEmissionRecentLimit
 	}
 
-	records := h.Buffer.Recent(limit, EmissionFilter{
-		OrgID:     workspaceID,
-		ProjectID: r.URL.Query().Get("project_id"),
-		IssueID:   r.URL.Query().Get("issue_id"),
-		SourceID:  r.URL.Query().Get("source_id"),
-	})
+	filter := emissionFilterFromRequest(r, workspaceID)
+	records, err := listEmissions(r.Context(), h.Store, h.Buffer, filter, r.URL.Query().Get("after"), limit)
+	if err != nil {
+		handleEmissionListError(w, err)
+		return
+	}
 	sendJSON(w, http.StatusOK, emissionListResponse{Items: records, Total: len(records)})
 }
 
+// Latest returns the most recent emission from one source.
+func (h *EmissionsHandler) Latest(w http.ResponseWriter, r *http.Request) {
+	if !h.available() {
+		sendJSON(w, http.StatusServiceUnavailable, errorResponse{Error: "emission buffer not available"})
+		return
+	}
+	workspaceID := middleware.WorkspaceFromContext(r.Context())
+	if workspaceID == "" {
+		sendJSON(w, http.StatusUnauthorized, errorResponse{Error: "missing workspace"})
+		return
+	}
+	sourceID := strings.TrimSpace(r.URL.Query().Get("source_id"))
+	if sourceID == "" {
+		sendJSON(w, http.StatusBadRequest, errorResponse{Error: "source_id is required"})
+		return
+	}
+
+	var latest *Emission
+	if h.Store != nil {
+		record, err := h.Store.LatestBySource(r.Context(), sourceID)
+		if err != nil {
+			sendJSON(w, http.StatusInternalServerError, errorResponse{Error: "failed to load latest emission"})
+			return
+		}
+		if record != nil {
+			converted := emissionFromStore(*record)
+			latest = &converted
+		}
+	} else {
+		latest = h.Buffer.LatestBySource(workspaceID, sourceID)
+	}
+	if latest == nil {
+		sendJSON(w, http.StatusNotFound, errorResponse{Error: "not found"})
+		return
+	}
+	sendJSON(w, http.StatusOK, latest)
+}
+
+// Stream serves emissions as Server-Sent Events. It resumes after the `after`
+// query parameter or Last-Event-ID header, and otherwise starts at the tail.
+func (h *EmissionsHandler) Stream(w http.ResponseWriter, r *http.Request) {
+	if !h.available() {
+		sendJSON(w, http.StatusServiceUnavailable, errorResponse{Error: "emission buffer not available"})
+		return
+	}
+	workspaceID := middleware.WorkspaceFromContext(r.Context())
+	if workspaceID == "" {
+		sendJSON(w, http.StatusUnauthorized, errorResponse{Error: "missing workspace"})
+		return
+	}
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		sendJSON(w, http.StatusInternalServerError, errorResponse{Error: "streaming not supported"})
+		return
+	}
+
+	ctx := r.Context()
+	filter := emissionFilterFromRequest(r, workspaceID)
+	cursor := strings.TrimSpace(r.URL.Query().Get("after"))
+	if cursor == "" {
+		cursor = strings.TrimSpace(r.Header.Get("Last-Event-ID"))
+	}
+	if cursor != "" {
+		if _, err := listEmissions(ctx, h.Store, h.Buffer, filter, cursor, 1); err != nil {
+			handleEmissionListError(w, err)
+			return
+		}
+	} else {
+		tail, err := listEmissions(ctx, h.Store, h.Buffer, EmissionFilter{OrgID: workspaceID}, "", 1)
+		if err != nil {
+			handleEmissionListError(w, err)
+			return
+		}
+		if len(tail) > 0 {
+			cursor = tail[0].ID
+		}
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+	_, _ = fmt.Fprint(w, ": connected\n\n")
+	flusher.Flush()
+
+	poll := time.NewTicker(emissionStreamPollInterval)
+	defer poll.Stop()
+	keepalive := time.NewTicker(emissionStreamKeepaliveInterval)
+	defer keepalive.Stop()
+
+	for {
+		wake := emissionStreamNotifications.wait(workspaceID)
+
+		var (
+			batch []Emission
+			err   error
+		)
+		if cursor == "" {
+			// Nothing existed when the stream opened, so everything now is new.
+			batch, err = listEmissions(ctx, h.Store, h.Buffer, filter, "", maxEmissionRecentLimit)
+			for i, j := 0, len(batch)-1; i < j; i, j = i+1, j-1 {
+				batch[i], batch[j] = batch[j], batch[i]
+			}
+		} else {
+			batch, err = listEmissions(ctx, h.Store, h.Buffer, filter, cursor, maxEmissionRecentLimit)
+		}
+		if err != nil {
+			if ctx.Err() == nil {
+				_, _ = fmt.Fprintf(w, "event: error\ndata: %q\n\n", err.Error())
+				flusher.Flush()
+			}
+			return
+		}
+		for _, emission := range batch {
+			payload, marshalErr := emissionEventJSONMarshal(emission)
+			if marshalErr != nil {
+				continue
+			}
+			_, _ = fmt.Fprintf(w, "id: %s\nevent: emission\ndata: %s\n\n", emission.ID, payload)
+			cursor = emission.ID
+		}
+		if len(batch) > 0 {
+			flusher.Flush()
+		}
+		if len(batch) == maxEmissionRecentLimit {
+			continue
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-wake:
+		case <-poll.C:
+		case <-keepalive.C:
+			_, _ = fmt.Fprint(w, ": keepalive\n\n")
+			flusher.Flush()
+		}
+	}
+}
+
 func (h *EmissionsHandler) Ingest(w http.ResponseWriter, r *http.Request) {
-	if h.Buffer == nil {
+	if !h.available() {
 		sendJSON(w, http.StatusServiceUnavailable, errorResponse{Error: "emission buffer not available"})
 		return
 	}
@@ -242,20 +476,200 @@ func (h *EmissionsHandler) Ingest(w http.ResponseWriter, r *http.Request) {
 		normalized = append(normalized, record)
 	}
 
-	for _, emission := range normalized {
-		emission.OrgID = workspaceID
-		h.Buffer.Push(emission)
+	recorded, err := recordEmissions(r.Context(), h.Store, h.Buffer, workspaceID, normalized)
+	if err != nil {
+		sendJSON(w, http.StatusInternalServerError, errorResponse{Error: "failed to record emissions"})
+		return
+	}
+	for _, emission := range recorded {
 		h.broadcastEmission(workspaceID, emission)
 	}
 
 	sendJSON(w, http.StatusAccepted, map[string]any{
 		"ok":          true,
 		"org_id":      workspaceID,
-		"ingested":    len(normalized),
+		"ingested":    len(recorded),
 		"ingested_at": time.Now().UTC().Format(time.RFC3339),
 	})
 }
 
+func emissionFilterFromRequest(r *http.Request, workspaceID string) EmissionFilter {
+	query := r.URL.Query()
+	return EmissionFilter{
+		OrgID:      workspaceID,
+		ProjectID:  query.Get("project_id"),
+		IssueID:    query.Get("issue_id"),
+		SourceID:   query.Get("source_id"),
+		SourceType: query.Get("source_type"),
+		Kind:       query.Get("kind"),
+	}
+}
+
+func handleEmissionListError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, errEmissionCursorNotFound):
+		sendJSON(w, http.StatusGone, errorResponse{Error: err.Error()})
+	case errors.Is(err, store.ErrNoWorkspace):
+		sendJSON(w, http.StatusUnauthorized, errorResponse{Error: "missing workspace"})
+	default:
+		sendJSON(w, http.StatusInternalServerError, errorResponse{Error: "failed to list emissions"})
+	}
+}
+
+// recordEmissions stores normalized emissions for orgID and returns the ones
+// that were new; IDs already persisted are dropped so retries don't rebroadcast.
+func recordEmissions(
+	ctx context.Context,
+	emissionStore *store.EmissionStore,
+	buffer *EmissionBuffer,
+	orgID string,
+	emissions []Emission,
+) ([]Emission, error) {
+	if len(emissions) == 0 {
+		return []Emission{}, nil
+	}
+	for i := range emissions {
+		emissions[i].OrgID = orgID
+	}
+
+	recorded := emissions
+	if emissionStore != nil {
+		ctx = context.WithValue(ctx, middleware.WorkspaceIDKey, orgID)
+		records := make([]store.Emission, 0, len(emissions))
+		for _, emission := range emissions {
+			records = append(records, emissionToStore(emission))
+		}
+		inserted, err := emissionStore.Append(ctx, records)
+		if err != nil {
+			return nil, err
+		}
+		recorded = make([]Emission, 0, len(inserted))
+		for _, record := range inserted {
+			recorded = append(recorded, emissionFromStore(record))
+		}
+	}
+	// The buffer doubles as a hot cache in front of the store.
+	if buffer != nil {
+		for _, emission := range recorded {
+			buffer.Push(emission)
+		}
+	}
+	if len(recorded) > 0 {
+		emissionStreamNotifications.notify(orgID)
+	}
+	return recorded, nil
+}
+
+// listEmissions reads from the store when configured and the buffer
+// otherwise. A non-empty afterID switches to oldest-first replay.
+func listEmissions(
+	ctx context.Context,
+	emissionStore *store.EmissionStore,
+	buffer *EmissionBuffer,
+	filter EmissionFilter,
+	afterID string,
+	limit int,
+) ([]Emission, error) {
+	afterID = strings.TrimSpace(afterID)
+	if emissionStore == nil {
+		if buffer == nil {
+			return nil, fmt.Errorf("emission buffer not available")
+		}
+		if afterID == "" {
+			return buffer.Recent(limit, filter), nil
+		}
+		records, ok := buffer.After(afterID, limit, filter)
+		if !ok {
+			return nil, errEmissionCursorNotFound
+		}
+		return records, nil
+	}
+
+	records, err := emissionStore.List(ctx, store.EmissionFilter{
+		AfterID:    afterID,
+		SourceID:   filter.SourceID,
+		SourceType: filter.SourceType,
+		Kind:       filter.Kind,
+		ProjectID:  filter.ProjectID,
+		IssueID:    filter.IssueID,
+		Limit:      limit,
+	})
+	if err != nil {
+		if errors.Is(err, store.ErrNotFound) {
+			return nil, errEmissionCursorNotFound
+		}
+		return nil, err
+	}
+	out := make([]Emission, 0, len(records))
+	for _, record := range records {
+		out = append(out, emissionFromStore(record))
+	}
+	return out, nil
+}
+
+func emissionToStore(emission Emission) store.Emission {
+	record := store.Emission{
+		ID:         emission.ID,
+		OrgID:      emission.OrgID,
+		SourceType: emission.SourceType,
+		SourceID:   emission.SourceID,
+		Kind:       emission.Kind,
+		Summary:    emission.Summary,
+		Detail:     emission.Detail,
+		Timestamp:  emission.Timestamp,
+	}
+	if emission.Scope != nil {
+		record.ProjectID = emission.Scope.ProjectID
+		record.IssueID = emission.Scope.IssueID
+		record.IssueNumber = emission.Scope.IssueNumber
+	}
+	if emission.Progress != nil {
+		current := emission.Progress.Current
+		total := emission.Progress.Total
+		record.ProgressCurrent = &current
+		record.ProgressTotal = &total
+		record.ProgressUnit = emission.Progress.Unit
+	}
+	return record
+}
+
+func emissionFromStore(record store.Emission) Emission {
+	emission := Emission{
+		ID:         record.ID,
+		OrgID:      record.OrgID,
+		SourceType: record.SourceType,
+		SourceID:   record.SourceID,
+		Kind:       record.Kind,
+		Summary:    record.Summary,
+		Detail:     record.Detail,
+		Timestamp:  record.Timestamp,
+	}
+	if record.ProjectID != nil || record.IssueID != nil || record.IssueNumber != nil {
+		emission.Scope = &EmissionScope{
+			ProjectID:   record.ProjectID,
+			IssueID:     record.IssueID,
+			IssueNumber: record.IssueNumber,
+		}
+	}
+	if record.ProgressCurrent != nil && record.ProgressTotal != nil {
+		emission.Progress = &EmissionProgress{
+			Current: *record.ProgressCurrent,
+			Total:   *record.ProgressTotal,
+			Unit:    record.ProgressUnit,
+		}
+	}
+	return emission
+}
+
+func emissionRetention() time.Duration {
+	if value := strings.TrimSpace(os.Getenv("EMISSION_RETENTION")); value != "" {
+		if d, err := time.ParseDuration(value); err == nil && d > 0 {
+			return d
+		}
+	}
+	return store.DefaultEmissionRetention
+}
+
 func normalizeEmission(input Emission) (Emission, error) {
 	input.ID = strings.TrimSpace(input.ID)
 	if input.ID == "" {
@@ -1,7 +1,9 @@
 package api
 
 import (
+	"bufio"
 	"bytes"
+	"context"
 	"encoding/json"
 	"errors"
 	"log"
@@ -22,6 +24,8 @@ import (
 func newEmissionsTestRouter(handler *EmissionsHandler) http.Handler {
 	router := chi.NewRouter()
 	router.With(middleware.OptionalWorkspace).Get("/api/emissions/recent", handler.Recent)
+	router.With(middleware.OptionalWorkspace).Get("/api/emissions/latest", handler.Latest)
+	router.With(middleware.OptionalWorkspace).Get("/api/emissions/stream", handler.Stream)
 	router.With(middleware.OptionalWorkspace).Post("/api/emissions", handler.Ingest)
 	return router
 }
@@ -485,3 +489,151 @@ func TestEmissionBufferOrgIsolation(t *testing.T) {
 	require.NotNil(t, orgBLatest)
 	require.Equal(t, "org-b-1", orgBLatest.ID)
 }
+
+func TestEmissionBufferAfterReplaysInOrder(t *testing.T) {
+	buffer := NewEmissionBuffer(10)
+	for i, kind := range []string{"status", "log", "status", "milestone"} {
+		buffer.Push(Emission{
+			ID:         "em-" + strconv.Itoa(i+1),
+			OrgID:      "org-1",
+			SourceType: "agent",
+			SourceID:   "agent-a",
+			Kind:       kind,
+			Summary:    kind,
+			Timestamp:  time.Now(),
+		})
+	}
+
+	after, ok := buffer.After("em-1", 10, EmissionFilter{OrgID: "org-1"})
+	require.True(t, ok)
+	require.Len(t, after, 3)
+	require.Equal(t, "em-2", after[0].ID)
+	require.Equal(t, "em-4", after[2].ID)
+
+	statusOnly, ok := buffer.After("em-1", 10, EmissionFilter{OrgID: "org-1", Kind: "status"})
+	require.True(t, ok)
+	require.Len(t, statusOnly, 1)
+	require.Equal(t, "em-3", statusOnly[0].ID)
+
+	limited, ok := buffer.After("em-1", 1, EmissionFilter{})
+	require.True(t, ok)
+	require.Len(t, limited, 1)
+	require.Equal(t, "em-2", limited[0].ID)
+
+	_, ok = buffer.After("em-missing", 10, EmissionFilter{})
+	require.False(t, ok)
+}
+
+func TestEmissionHandlerRecentAfterCursorAndFilters(t *testing.T) {
+	buffer := NewEmissionBuffer(10)
+	handler := &EmissionsHandler{Buffer: buffer}
+	router := newEmissionsTestRouter(handler)
+	orgID := "550e8400-e29b-41d4-a716-446655440000"
+
+	for i, sourceType := range []string{"agent", "bridge", "agent"} {
+		buffer.Push(Emission{
+			ID:         "em-" + strconv.Itoa(i+1),
+			OrgID:      orgID,
+			SourceType: sourceType,
+			SourceID:   sourceType + "-1",
+			Kind:       "status",
+			Summary:    "update",
+			Timestamp:  time.Now(),
+		})
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/api/emissions/recent?org_id="+orgID+"&after=em-1", nil)
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+	require.Equal(t, http.StatusOK, rec.Code)
+	var resp emissionListResponse
+	require.NoError(t, json.NewDecoder(rec.Body).Decode(&resp))
+	require.Len(t, resp.Items, 2)
+	require.Equal(t, "em-2", resp.Items[0].ID)
+	require.Equal(t, "em-3", resp.Items[1].ID)
+
+	req = httptest.NewRequest(http.MethodGet, "/api/emissions/recent?org_id="+orgID+"&source_type=bridge", nil)
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+	require.Equal(t, http.StatusOK, rec.Code)
+	resp = emissionListResponse{}
+	require.NoError(t, json.NewDecoder(rec.Body).Decode(&resp))
+	require.Len(t, resp.Items, 1)
+	require.Equal(t, "em-2", resp.Items[0].ID)
+
+	req = httptest.NewRequest(http.MethodGet, "/api/emissions/recent?org_id="+orgID+"&after=em-gone", nil)
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+	require.Equal(t, http.StatusGone, rec.Code)
+
+	req = httptest.NewRequest(http.MethodGet, "/api/emissions/latest?org_id="+orgID+"&source_id=agent-1", nil)
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+	require.Equal(t, http.StatusOK, rec.Code)
+	var latest Emission
+	require.NoError(t, json.NewDecoder(rec.Body).Decode(&latest))
+	require.Equal(t, "em-3", latest.ID)
+
+	req = httptest.NewRequest(http.MethodGet, "/api/emissions/latest?org_id="+orgID+"&source_id=nobody", nil)
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+	require.Equal(t, http.StatusNotFound, rec.Code)
+}
+
+func TestEmissionHandlerStreamResumesAndFollows(t *testing.T) {
+	buffer := NewEmissionBuffer(10)
+	handler := &EmissionsHandler{Buffer: buffer}
+	server := httptest.NewServer(newEmissionsTestRouter(handler))
+	defer server.Close()
+	orgID := "550e8400-e29b-41d4-a716-446655440000"
+
+	push := func(id string) {
+		_, err := recordEmissions(context.Background(), nil, buffer, orgID, []Emission{{
+			ID:         id,
+			SourceType: "agent",
+			SourceID:   "agent-1",
+			Kind:       "log",
+			Summary:    "line " + id,
+			Timestamp:  time.Now(),
+		}})
+		require.NoError(t, err)
+	}
+	push("em-1")
+	push("em-2")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, server.URL+"/api/emissions/stream?org_id="+orgID, nil)
+	require.NoError(t, err)
+	req.Header.Set("Last-Event-ID", "em-1")
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	require.Equal(t, "text/event-stream", resp.Header.Get("Content-Type"))
+
+	reader := bufio.NewReader(resp.Body)
+	nextID := func() string {
+		for {
+			line, readErr := reader.ReadString('\n')
+			require.NoError(t, readErr)
+			if strings.HasPrefix(line, "id: ") {
+				return strings.TrimSpace(strings.TrimPrefix(line, "id: "))
+			}
+		}
+	}
+
+	require.Equal(t, "em-2", nextID())
+	push("em-3")
+	require.Equal(t, "em-3", nextID())
+}
+
+func TestEmissionHandlerStreamRejectsUnknownCursor(t *testing.T) {
+	handler := &EmissionsHandler{Buffer: NewEmissionBuffer(10)}
+	router := newEmissionsTestRouter(handler)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/emissions/stream?org_id=550e8400-e29b-41d4-a716-446655440000&after=em-missing", nil)
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+	require.Equal(t, http.StatusGone, rec.Code)
+}
@@ -0,0 +1,189 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"regexp"
+	"strings"
+
+	"github.com/samhotchkiss/otter-camp/internal/store"
+)
+
+// execApprovalPolicyInput is the part of an exec approval request that rules
+// match against.
+type execApprovalPolicyInput struct {
+	AgentID   string   `json:"agent_id,omitempty"`
+	ProjectID string   `json:"project_id,omitempty"`
+	Command   string   `json:"command"`
+	Cwd       string   `json:"cwd,omitempty"`
+	RiskTags  []string `json:"risk_tags,omitempty"`
+}
+
+// execApprovalPolicyDecision is the outcome of evaluating the rule set. Rule
+// is nil when nothing matched and the request falls back to one human approver.
+type execApprovalPolicyDecision struct {
+	Rule              *store.ExecApprovalRule
+	Action            string
+	RequiredApprovals int
+}
+
+func (d execApprovalPolicyDecision) automated() bool {
+	return d.Action == store.ExecApprovalRuleActionAutoApprove || d.Action == store.ExecApprovalRuleActionAutoDeny
+}
+
+func execApprovalPolicyInputFromRequest(approval ExecApprovalRequest) execApprovalPolicyInput {
+	return execApprovalPolicyInput{
+		AgentID:   pointerString(approval.AgentID),
+		ProjectID: pointerString(approval.ProjectID),
+		Command:   approval.Command,
+		Cwd:       pointerString(approval.Cwd),
+		RiskTags:  approval.RiskTags,
+	}
+}
+
+// evaluateExecApprovalPolicy returns the decision of the first matching rule.
+// rules must already be in evaluation order (see ExecApprovalRuleStore.ListApplicable);
+// rules scoped to another project are skipped.
+func evaluateExecApprovalPolicy(rules []store.ExecApprovalRule, input execApprovalPolicyInput) execApprovalPolicyDecision {
+	for i := range rules {
+		rule := rules[i]
+		if !rule.Enabled {
+			continue
+		}
+		if rule.ProjectID != nil && *rule.ProjectID != strings.TrimSpace(input.ProjectID) {
+			continue
+		}
+		if rule.Action == store.ExecApprovalRuleActionAutoApprove && execApprovalCommandHasShellControl(input.Command) {
+			continue
+		}
+		if !execApprovalRuleMatches(rule, input) {
+			continue
+		}
+		required := rule.RequiredApprovals
+		if required < 1 {
+			required = 1
+		}
+		return execApprovalPolicyDecision{Rule: &rule, Action: rule.Action, RequiredApprovals: required}
+	}
+	return execApprovalPolicyDecision{Action: store.ExecApprovalRuleActionRequireApprovers, RequiredApprovals: 1}
+}
+
+func execApprovalRuleMatches(rule store.ExecApprovalRule, input execApprovalPolicyInput) bool {
+	if rule.AgentID != nil && !strings.EqualFold(*rule.AgentID, strings.TrimSpace(input.AgentID)) {
+		return false
+	}
+	if rule.CommandPattern != nil {
+		command := strings.TrimSpace(input.Command)
+		if rule.CommandMatch == store.ExecApprovalRuleMatchRegex {
+			pattern, err := compileExecApprovalRegex(*rule.CommandPattern)
+			if err != nil || !pattern.MatchString(command) {
+				return false
+			}
+		} else if !matchExecApprovalGlob(*rule.CommandPattern, command) {
+			return false
+		}
+	}
+	if rule.CwdPattern != nil && !matchExecApprovalGlob(*rule.CwdPattern, strings.TrimSpace(input.Cwd)) {
+		return false
+	}
+	if len(rule.RiskTags) > 0 {
+		present := make(map[string]bool, len(input.RiskTags))
+		for _, tag := range input.RiskTags {
+			present[strings.ToLower(strings.TrimSpace(tag))] = true
+		}
+		for _, tag := range rule.RiskTags {
+			if !present[tag] {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// execApprovalShellControl lists the characters that chain, pipe, redirect
+// or substitute shell commands. Globs never match across them.
+const execApprovalShellControl = ";&|`$<>\n\r"
+
+// execApprovalCommandHasShellControl reports whether command chains, pipes,
+// redirects or substitutes anything. Such commands are never auto-approved:
+// a rule vouches for one command, not whatever is attached to it.
+func execApprovalCommandHasShellControl(command string) bool {
+	return strings.ContainsAny(command, execApprovalShellControl)
+}
+
+// compileExecApprovalRegex anchors a regex rule so it has to match the whole
+// command rather than any substring of it.
+func compileExecApprovalRegex(pattern string) (*regexp.Regexp, error) {
+	return regexp.Compile(`^(?:` + pattern + `)$`)
+}
+
+// matchExecApprovalGlob matches value in full against a shell-style glob where
+// `*` spans any characters (including `/` and spaces) other than shell
+// control characters, and `?` matches one such character.
+func matchExecApprovalGlob(pattern, value string) bool {
+	plain := "[^" + regexp.QuoteMeta(execApprovalShellControl) + "]"
+	var b strings.Builder
+	b.WriteString("^")
+	for _, r := range pattern {
+		switch r {
+		case '*':
+			b.WriteString(plain + "*")
+		case '?':
+			b.WriteString(plain)
+		default:
+			b.WriteString(regexp.QuoteMeta(string(r)))
+		}
+	}
+	b.WriteString("$")
+	compiled, err := regexp.Compile(b.String())
+	if err != nil {
+		return false
+	}
+	return compiled.MatchString(value)
+}
+
+// applyExecApprovalPolicy evaluates the org's rules against a newly stored
+// request. Automated decisions go through the same callback and resolution
+// path as a human response; approver quorums are recorded on the request.
+func applyExecApprovalPolicy(
+	ctx context.Context,
+	q store.Querier,
+	rules []store.ExecApprovalRule,
+	approval ExecApprovalRequest,
+) (ExecApprovalRequest, execApprovalPolicyDecision, error) {
+	decision := evaluateExecApprovalPolicy(rules, execApprovalPolicyInputFromRequest(approval))
+	if decision.Rule == nil {
+		return approval, decision, nil
+	}
+
+	updated, err := setExecApprovalRule(ctx, q, approval.OrgID, approval.ID, decision.Rule.ID, decision.RequiredApprovals)
+	if err != nil {
+		return approval, decision, err
+	}
+	if !decision.automated() {
+		return updated, decision, nil
+	}
+
+	verdict := "deny"
+	comment := fmt.Sprintf("auto-denied by rule %q", decision.Rule.Name)
+	if decision.Action == store.ExecApprovalRuleActionAutoApprove {
+		verdict = "approve"
+		comment = fmt.Sprintf("auto-approved by rule %q", decision.Rule.Name)
+	}
+
+	callbackURL := strings.TrimSpace(pointerString(updated.CallbackURL))
+	if callbackURL != "" {
+		if err := postExecApprovalCallback(ctx, callbackURL, updated, verdict, comment); err != nil {
+			// Leave the request pending so a human can still answer it.
+			log.Printf("exec approval %s: rule %s callback failed: %v", updated.ID, decision.Rule.ID, err)
+			return updated, decision, nil
+		}
+	}
+
+	resolved, err := resolveExecApprovalAs(ctx, q, approval.OrgID, approval.ID, verdict, comment, execApprovalDecidedByRule, &decision.Rule.ID)
+	if err != nil {
+		return updated, decision, err
+	}
+	return resolved, decision, nil
+}
@@ -0,0 +1,353 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/samhotchkiss/otter-camp/internal/middleware"
+	"github.com/samhotchkiss/otter-camp/internal/store"
+)
+
+// ExecApprovalRulesHandler manages the exec approval policy rule set.
+type ExecApprovalRulesHandler struct {
+	Store *store.ExecApprovalRuleStore
+}
+
+type execApprovalRulePayload struct {
+	ID                string   `json:"id"`
+	OrgID             string   `json:"org_id"`
+	ProjectID         *string  `json:"project_id,omitempty"`
+	Name              string   `json:"name"`
+	Priority          int      `json:"priority"`
+	Enabled           bool     `json:"enabled"`
+	AgentID           *string  `json:"agent_id,omitempty"`
+	CommandPattern    *string  `json:"command_pattern,omitempty"`
+	CommandMatch      string   `json:"command_match"`
+	CwdPattern        *string  `json:"cwd_pattern,omitempty"`
+	RiskTags          []string `json:"risk_tags"`
+	Action            string   `json:"action"`
+	RequiredApprovals int      `json:"required_approvals"`
+	CreatedAt         string   `json:"created_at"`
+	UpdatedAt         string   `json:"updated_at"`
+}
+
+type execApprovalRuleListResponse struct {
+	Items []execApprovalRulePayload `json:"items"`
+	Total int                       `json:"total"`
+}
+
+type execApprovalRuleDryRunResponse struct {
+	Matched           bool                     `json:"matched"`
+	Action            string                   `json:"action"`
+	Decision          string                   `json:"decision"`
+	RequiredApprovals int                      `json:"required_approvals"`
+	Rule              *execApprovalRulePayload `json:"rule,omitempty"`
+	RulesEvaluated    int                      `json:"rules_evaluated"`
+}
+
+// List handles GET /api/approvals/exec/rules
+func (h *ExecApprovalRulesHandler) List(w http.ResponseWriter, r *http.Request) {
+	if h.Store == nil {
+		sendJSON(w, http.StatusServiceUnavailable, errorResponse{Error: "database not available"})
+		return
+	}
+
+	rules, err := h.Store.List(r.Context())
+	if err != nil {
+		handleJobsStoreError(w, err)
+		return
+	}
+	items := make([]execApprovalRulePayload, 0, len(rules))
+	for _, rule := range rules {
+		items = append(items, toExecApprovalRulePayload(rule))
+	}
+	sendJSON(w, http.StatusOK, execApprovalRuleListResponse{Items: items, Total: len(items)})
+}
+
+// Create handles POST /api/approvals/exec/rules
+func (h *ExecApprovalRulesHandler) Create(w http.ResponseWriter, r *http.Request) {
+	if h.Store == nil {
+		sendJSON(w, http.StatusServiceUnavailable, errorResponse{Error: "database not available"})
+		return
+	}
+
+	var req struct {
+		ProjectID         *string  `json:"project_id"`
+		Name              string   `json:"name"`
+		Priority          *int     `json:"priority"`
+		Enabled           *bool    `json:"enabled"`
+		AgentID           *string  `json:"agent_id"`
+		CommandPattern    *string  `json:"command_pattern"`
+		CommandMatch      string   `json:"command_match"`
+		CwdPattern        *string  `json:"cwd_pattern"`
+		RiskTags          []string `json:"risk_tags"`
+		Action            string   `json:"action"`
+		RequiredApprovals *int     `json:"required_approvals"`
+	}
+	decoder := json.NewDecoder(r.Body)
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&req); err != nil {
+		sendJSON(w, http.StatusBadRequest, errorResponse{Error: "invalid JSON"})
+		return
+	}
+
+	created, err := h.Store.Create(r.Context(), store.CreateExecApprovalRuleInput{
+		ProjectID:         req.ProjectID,
+		Name:              req.Name,
+		Priority:          req.Priority,
+		Enabled:           req.Enabled,
+		AgentID:           req.AgentID,
+		CommandPattern:    req.CommandPattern,
+		CommandMatch:      req.CommandMatch,
+		CwdPattern:        req.CwdPattern,
+		RiskTags:          req.RiskTags,
+		Action:            req.Action,
+		RequiredApprovals: req.RequiredApprovals,
+	})
+	if err != nil {
+		handleJobsStoreError(w, err)
+		return
+	}
+	payload := toExecApprovalRulePayload(*created)
+	recordAudit(r, auditEvent{
+		Action:     "exec_approval_rule.create",
+		TargetType: "exec_approval_rule",
+		TargetID:   created.ID,
+		After:      payload,
+	})
+	sendJSON(w, http.StatusCreated, payload)
+}
+
+// Patch handles PATCH /api/approvals/exec/rules/{id}
+func (h *ExecApprovalRulesHandler) Patch(w http.ResponseWriter, r *http.Request) {
+	if h.Store == nil {
+		sendJSON(w, http.StatusServiceUnavailable, errorResponse{Error: "database not available"})
+		return
+	}
+
+	var req struct {
+		Name              *string   `json:"name"`
+		Priority          *int      `json:"priority"`
+		Enabled           *bool     `json:"enabled"`
+		AgentID           *string   `json:"agent_id"`
+		CommandPattern    *string   `json:"command_pattern"`
+		CommandMatch      *string   `json:"command_match"`
+		CwdPattern        *string   `json:"cwd_pattern"`
+		RiskTags          *[]string `json:"risk_tags"`
+		Action            *string   `json:"action"`
+		RequiredApprovals *int      `json:"required_approvals"`
+	}
+	decoder := json.NewDecoder(r.Body)
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&req); err != nil {
+		sendJSON(w, http.StatusBadRequest, errorResponse{Error: "invalid JSON"})
+		return
+	}
+
+	ruleID := chi.URLParam(r, "id")
+	var before *execApprovalRulePayload
+	if existing, err := h.Store.Get(r.Context(), ruleID); err == nil {
+		payload := toExecApprovalRulePayload(*existing)
+		before = &payload
+	}
+
+	updated, err := h.Store.Update(r.Context(), ruleID, store.UpdateExecApprovalRuleInput{
+		Name:              req.Name,
+		Priority:          req.Priority,
+		Enabled:           req.Enabled,
+		AgentID:           req.AgentID,
+		CommandPattern:    req.CommandPattern,
+		CommandMatch:      req.CommandMatch,
+		CwdPattern:        req.CwdPattern,
+		RiskTags:          req.RiskTags,
+		Action:            req.Action,
+		RequiredApprovals: req.RequiredApprovals,
+	})
+	if err != nil {
+		handleJobsStoreError(w, err)
+		return
+	}
+	payload := toExecApprovalRulePayload(*updated)
+	recordAudit(r, auditEvent{
+		Action:     "exec_approval_rule.update",
+		TargetType: "exec_approval_rule",
+		TargetID:   updated.ID,
+		Before:     before,
+		After:      payload,
+	})
+	sendJSON(w, http.StatusOK, payload)
+}
+
+// Delete handles DELETE /api/approvals/exec/rules/{id}
+func (h *ExecApprovalRulesHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	if h.Store == nil {
+		sendJSON(w, http.StatusServiceUnavailable, errorResponse{Error: "database not available"})
+		return
+	}
+
+	ruleID := chi.URLParam(r, "id")
+	var before *execApprovalRulePayload
+	if existing, err := h.Store.Get(r.Context(), ruleID); err == nil {
+		payload := toExecApprovalRulePayload(*existing)
+		before = &payload
+	}
+	if err := h.Store.Delete(r.Context(), ruleID); err != nil {
+		handleJobsStoreError(w, err)
+		return
+	}
+	recordAudit(r, auditEvent{
+		Action:     "exec_approval_rule.delete",
+		TargetType: "exec_approval_rule",
+		TargetID:   ruleID,
+		Before:     before,
+	})
+	sendJSON(w, http.StatusOK, map[string]bool{"ok": true})
+}
+
+// DryRun handles POST /api/approvals/exec/rules/dry-run. It evaluates a
+// command against the workspace's enabled rules without storing anything.
+// Passing `rules` evaluates that rule set instead, which is useful for
+// checking a rule before creating it.
+func (h *ExecApprovalRulesHandler) DryRun(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		execApprovalPolicyInput
+		Rules []struct {
+			ProjectID         *string  `json:"project_id"`
+			Name              string   `json:"name"`
+			Priority          *int     `json:"priority"`
+			AgentID           *string  `json:"agent_id"`
+			CommandPattern    *string  `json:"command_pattern"`
+			CommandMatch      string   `json:"command_match"`
+			CwdPattern        *string  `json:"cwd_pattern"`
+			RiskTags          []string `json:"risk_tags"`
+			Action            string   `json:"action"`
+			RequiredApprovals *int     `json:"required_approvals"`
+		} `json:"rules"`
+	}
+	decoder := json.NewDecoder(r.Body)
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&req); err != nil {
+		sendJSON(w, http.StatusBadRequest, errorResponse{Error: "invalid JSON"})
+		return
+	}
+	input := req.execApprovalPolicyInput
+	if strings.TrimSpace(input.Command) == "" {
+		sendJSON(w, http.StatusBadRequest, errorResponse{Error: "command is required"})
+		return
+	}
+	if strings.TrimSpace(middleware.WorkspaceFromContext(r.Context())) == "" {
+		sendJSON(w, http.StatusBadRequest, errorResponse{Error: "org_id is required"})
+		return
+	}
+
+	var rules []store.ExecApprovalRule
+	if req.Rules != nil {
+		rules = make([]store.ExecApprovalRule, 0, len(req.Rules))
+		for _, candidate := range req.Rules {
+			rule := store.ExecApprovalRule{
+				ProjectID:         candidate.ProjectID,
+				Name:              candidate.Name,
+				Priority:          100,
+				Enabled:           true,
+				AgentID:           candidate.AgentID,
+				CommandPattern:    candidate.CommandPattern,
+				CommandMatch:      candidate.CommandMatch,
+				CwdPattern:        candidate.CwdPattern,
+				RiskTags:          candidate.RiskTags,
+				Action:            candidate.Action,
+				RequiredApprovals: 1,
+			}
+			if candidate.Priority != nil {
+				rule.Priority = *candidate.Priority
+			}
+			if candidate.RequiredApprovals != nil {
+				rule.RequiredApprovals = *candidate.RequiredApprovals
+			}
+			if err := store.ValidateExecApprovalRule(&rule); err != nil {
+				handleJobsStoreError(w, err)
+				return
+			}
+			rules = append(rules, rule)
+		}
+		sortExecApprovalRules(rules)
+	} else {
+		if h.Store == nil {
+			sendJSON(w, http.StatusServiceUnavailable, errorResponse{Error: "database not available"})
+			return
+		}
+		var projectID *string
+		if trimmed := strings.TrimSpace(input.ProjectID); trimmed != "" {
+			projectID = &trimmed
+		}
+		loaded, err := h.Store.ListApplicable(r.Context(), projectID)
+		if err != nil {
+			handleJobsStoreError(w, err)
+			return
+		}
+		rules = loaded
+	}
+
+	decision := evaluateExecApprovalPolicy(rules, input)
+	resp := execApprovalRuleDryRunResponse{
+		Matched:           decision.Rule != nil,
+		Action:            decision.Action,
+		Decision:          string(ExecApprovalStatusPending),
+		RequiredApprovals: decision.RequiredApprovals,
+		RulesEvaluated:    len(rules),
+	}
+	switch decision.Action {
+	case store.ExecApprovalRuleActionAutoApprove:
+		resp.Decision = string(ExecApprovalStatusApproved)
+	case store.ExecApprovalRuleActionAutoDeny:
+		resp.Decision = string(ExecApprovalStatusDenied)
+	}
+	if decision.Rule != nil {
+		payload := toExecApprovalRulePayload(*decision.Rule)
+		resp.Rule = &payload
+	}
+	sendJSON(w, http.StatusOK, resp)
+}
+
+// sortExecApprovalRules orders rules the way ListApplicable does: project
+// rules before org-wide ones, then by priority.
+func sortExecApprovalRules(rules []store.ExecApprovalRule) {
+	sort.SliceStable(rules, func(i, j int) bool {
+		iProject, jProject := rules[i].ProjectID != nil, rules[j].ProjectID != nil
+		if iProject != jProject {
+			return iProject
+		}
+		return rules[i].Priority < rules[j].Priority
+	})
+}
+
+func toExecApprovalRulePayload(rule store.ExecApprovalRule) execApprovalRulePayload {
+	payload := execApprovalRulePayload{
+		ID:                rule.ID,
+		OrgID:             rule.OrgID,
+		ProjectID:         rule.ProjectID,
+		Name:              rule.Name,
+		Priority:          rule.Priority,
+		Enabled:           rule.Enabled,
+		AgentID:           rule.AgentID,
+		CommandPattern:    rule.CommandPattern,
+		CommandMatch:      rule.CommandMatch,
+		CwdPattern:        rule.CwdPattern,
+		RiskTags:          rule.RiskTags,
+		Action:            rule.Action,
+		RequiredApprovals: rule.RequiredApprovals,
+	}
+	if payload.RiskTags == nil {
+		payload.RiskTags = []string{}
+	}
+	if !rule.CreatedAt.IsZero() {
+		payload.CreatedAt = rule.CreatedAt.UTC().Format(time.RFC3339)
+	}
+	if !rule.UpdatedAt.IsZero() {
+		payload.UpdatedAt = rule.UpdatedAt.UTC().Format(time.RFC3339)
+	}
+	return payload
+}
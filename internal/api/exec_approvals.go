@@ -14,7 +14,8 @@ import (
 	"time"
 
 	"github.com/go-chi/chi/v5"
-	_ "github.com/lib/pq"
+	"github.com/lib/pq"
+	"github.com/samhotchkiss/otter-camp/internal/middleware"
 	"github.com/samhotchkiss/otter-camp/internal/store"
 	"github.com/samhotchkiss/otter-camp/internal/ws"
 )
@@ -60,8 +61,23 @@ type ExecApprovalRequest struct {
 	Response    json.RawMessage `json:"response,omitempty"`
 	CreatedAt   time.Time       `json:"created_at"`
 	ResolvedAt  *time.Time      `json:"resolved_at,omitempty"`
+
+	ProjectID         *string  `json:"project_id,omitempty"`
+	RiskTags          []string `json:"risk_tags,omitempty"`
+	RuleID            *string  `json:"rule_id,omitempty"`
+	DecidedBy         *string  `json:"decided_by,omitempty"`
+	RequiredApprovals int      `json:"required_approvals"`
+	ApprovalCount     int      `json:"approval_count"`
 }
 
+const execApprovalRequestColumns = `id, org_id, external_id, agent_id, task_id, status, command, cwd, shell, args, env, message, callback_url, request, response, created_at, resolved_at,
+		project_id::text, risk_tags, rule_id::text, decided_by, required_approvals, approval_count`
+
+const (
+	execApprovalDecidedByRule  = "rule"
+	execApprovalDecidedByHuman = "human"
+)
+
 type ExecApprovalListResponse struct {
 	OrgID    string                `json:"org_id"`
 	Status   string                `json:"status,omitempty"`
@@ -158,7 +174,7 @@ func (h *ExecApprovalsHandler) List(w http.ResponseWriter, r *http.Request) {
 
 	rows, err := conn.QueryContext(
 		r.Context(),
-		`SELECT id, org_id, external_id, agent_id, task_id, status, command, cwd, shell, args, env, message, callback_url, request, response, created_at, resolved_at
+		`SELECT `+execApprovalRequestColumns+`
 		 FROM exec_approval_requests
 		 WHERE org_id = $1 AND status = $2
 		 ORDER BY created_at DESC
@@ -262,6 +278,51 @@ func (h *ExecApprovalsHandler) Respond(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// Requests held by a require_approvers rule need N distinct approvals; a
+	// single deny still resolves them immediately.
+	if current.RequiredApprovals > 1 && decision == "approve" {
+		userID := strings.TrimSpace(middleware.UserFromContext(r.Context()))
+		if !uuidRegex.MatchString(userID) {
+			sendJSON(w, http.StatusUnauthorized, errorResponse{Error: "sign in to approve this request"})
+			return
+		}
+		counted, err := recordExecApprovalVote(r.Context(), conn, orgID, requestID, userID, decision, req.Comment)
+		if err != nil {
+			if errors.Is(err, errExecApprovalDuplicateVote) {
+				sendJSON(w, http.StatusConflict, errorResponse{Error: "you already approved this request"})
+				return
+			}
+			if errors.Is(err, sql.ErrNoRows) {
+				sendJSON(w, http.StatusConflict, errorResponse{Error: "approval request already resolved"})
+				return
+			}
+			sendJSON(w, http.StatusInternalServerError, errorResponse{Error: "failed to record approval"})
+			return
+		}
+		if counted.ApprovalCount < counted.RequiredApprovals {
+			recordAudit(r, auditEvent{
+				OrgID:      orgID,
+				Action:     "exec_approval.vote",
+				TargetType: "exec_approval",
+				TargetID:   requestID,
+				Before:     execApprovalAuditState(current),
+				After:      execApprovalAuditState(counted),
+			})
+			if h != nil && h.Hub != nil {
+				broadcastExecApprovalUpdated(h.Hub, orgID, counted)
+			}
+			sendJSON(w, http.StatusOK, ExecApprovalRespondResponse{OK: true, Request: counted})
+			return
+		}
+		current = counted
+	} else if userID := strings.TrimSpace(middleware.UserFromContext(r.Context())); uuidRegex.MatchString(userID) {
+		if _, err := recordExecApprovalVote(r.Context(), conn, orgID, requestID, userID, decision, req.Comment); err != nil &&
+			!errors.Is(err, errExecApprovalDuplicateVote) {
+			sendJSON(w, http.StatusInternalServerError, errorResponse{Error: "failed to record approval"})
+			return
+		}
+	}
+
 	callbackURL := strings.TrimSpace(pointerString(current.CallbackURL))
 	if callbackURL != "" {
 		if err := postExecApprovalCallback(r.Context(), callbackURL, current, decision, req.Comment); err != nil {
@@ -280,6 +341,15 @@ func (h *ExecApprovalsHandler) Respond(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	recordAudit(r, auditEvent{
+		OrgID:      orgID,
+		Action:     "exec_approval." + decision,
+		TargetType: "exec_approval",
+		TargetID:   requestID,
+		Before:     execApprovalAuditState(current),
+		After:      execApprovalAuditState(updated),
+	})
+
 	if h != nil && h.Hub != nil {
 		broadcastExecApprovalResolved(h.Hub, orgID, updated)
 	}
@@ -287,6 +357,20 @@ func (h *ExecApprovalsHandler) Respond(w http.ResponseWriter, r *http.Request) {
 	sendJSON(w, http.StatusOK, ExecApprovalRespondResponse{OK: true, Request: updated})
 }
 
+// execApprovalAuditState is the part of a request worth auditing; the env
+// and raw request payload are left out because they can hold secrets.
+func execApprovalAuditState(req ExecApprovalRequest) map[string]interface{} {
+	return map[string]interface{}{
+		"status":             req.Status,
+		"command":            req.Command,
+		"agent_id":           req.AgentID,
+		"rule_id":            req.RuleID,
+		"decided_by":         req.DecidedBy,
+		"approval_count":     req.ApprovalCount,
+		"required_approvals": req.RequiredApprovals,
+	}
+}
+
 func isValidExecApprovalStatus(status string) bool {
 	switch status {
 	case string(ExecApprovalStatusPending),
@@ -341,6 +425,10 @@ func scanExecApprovalRequest(scanner interface{ Scan(...any) error }) (ExecAppro
 	var requestBytes []byte
 	var responseBytes []byte
 	var resolvedAt sql.NullTime
+	var projectID sql.NullString
+	var riskTags pq.StringArray
+	var ruleID sql.NullString
+	var decidedBy sql.NullString
 
 	err := scanner.Scan(
 		&out.ID,
@@ -360,6 +448,12 @@ func scanExecApprovalRequest(scanner interface{ Scan(...any) error }) (ExecAppro
 		&responseBytes,
 		&out.CreatedAt,
 		&resolvedAt,
+		&projectID,
+		&riskTags,
+		&ruleID,
+		&decidedBy,
+		&out.RequiredApprovals,
+		&out.ApprovalCount,
 	)
 	if err != nil {
 		return out, err
@@ -402,6 +496,18 @@ func scanExecApprovalRequest(scanner interface{ Scan(...any) error }) (ExecAppro
 		t := resolvedAt.Time
 		out.ResolvedAt = &t
 	}
+	if projectID.Valid {
+		out.ProjectID = &projectID.String
+	}
+	if len(riskTags) > 0 {
+		out.RiskTags = []string(riskTags)
+	}
+	if ruleID.Valid {
+		out.RuleID = &ruleID.String
+	}
+	if decidedBy.Valid {
+		out.DecidedBy = &decidedBy.String
+	}
 
 	return out, nil
 }
@@ -409,7 +515,7 @@ func scanExecApprovalRequest(scanner interface{ Scan(...any) error }) (ExecAppro
 func getExecApprovalByID(ctx context.Context, q store.Querier, orgID, id string) (ExecApprovalRequest, error) {
 	row := q.QueryRowContext(
 		ctx,
-		`SELECT id, org_id, external_id, agent_id, task_id, status, command, cwd, shell, args, env, message, callback_url, request, response, created_at, resolved_at
+		`SELECT `+execApprovalRequestColumns+`
 		 FROM exec_approval_requests
 		 WHERE org_id = $1 AND id = $2`,
 		orgID,
@@ -419,6 +525,17 @@ func getExecApprovalByID(ctx context.Context, q store.Querier, orgID, id string)
 }
 
 func resolveExecApproval(ctx context.Context, q store.Querier, orgID, id, decision, comment string) (ExecApprovalRequest, error) {
+	return resolveExecApprovalAs(ctx, q, orgID, id, decision, comment, execApprovalDecidedByHuman, nil)
+}
+
+// resolveExecApprovalAs resolves a pending request and records who decided it;
+// ruleID is set for automated decisions.
+func resolveExecApprovalAs(
+	ctx context.Context,
+	q store.Querier,
+	orgID, id, decision, comment, decidedBy string,
+	ruleID *string,
+) (ExecApprovalRequest, error) {
 	status := string(ExecApprovalStatusDenied)
 	if decision == "approve" {
 		status = string(ExecApprovalStatusApproved)
@@ -427,23 +544,102 @@ func resolveExecApproval(ctx context.Context, q store.Querier, orgID, id, decisi
 	responsePayload := map[string]interface{}{
 		"decision":     decision,
 		"comment":      strings.TrimSpace(comment),
+		"decided_by":   decidedBy,
 		"responded_at": time.Now().UTC().Format(time.RFC3339),
 	}
 	if responsePayload["comment"] == "" {
 		delete(responsePayload, "comment")
 	}
+	if ruleID != nil {
+		responsePayload["rule_id"] = *ruleID
+	}
 	responseBytes, _ := json.Marshal(responsePayload)
 
 	row := q.QueryRowContext(
 		ctx,
 		`UPDATE exec_approval_requests
-		 SET status = $1, response = $2, resolved_at = NOW()
+		 SET status = $1, response = $2, resolved_at = NOW(), decided_by = $5, rule_id = COALESCE($6, rule_id)
 		 WHERE org_id = $3 AND id = $4 AND status = 'pending'
-		 RETURNING id, org_id, external_id, agent_id, task_id, status, command, cwd, shell, args, env, message, callback_url, request, response, created_at, resolved_at`,
+		 RETURNING `+execApprovalRequestColumns,
 		status,
 		json.RawMessage(responseBytes),
 		orgID,
 		id,
+		decidedBy,
+		ruleID,
+	)
+	return scanExecApprovalRequest(row)
+}
+
+// setExecApprovalRule records the matching policy rule and the number of
+// approvals it requires on a pending request.
+func setExecApprovalRule(ctx context.Context, q store.Querier, orgID, id, ruleID string, requiredApprovals int) (ExecApprovalRequest, error) {
+	row := q.QueryRowContext(
+		ctx,
+		`UPDATE exec_approval_requests
+		 SET rule_id = $3, required_approvals = $4
+		 WHERE org_id = $1 AND id = $2 AND status = 'pending'
+		 RETURNING `+execApprovalRequestColumns,
+		orgID,
+		id,
+		ruleID,
+		requiredApprovals,
+	)
+	return scanExecApprovalRequest(row)
+}
+
+var errExecApprovalDuplicateVote = errors.New("duplicate exec approval vote")
+
+// recordExecApprovalVote stores one user's decision and, for approvals, bumps
+// the request's approval count. It returns sql.ErrNoRows once the request is
+// no longer pending.
+func recordExecApprovalVote(
+	ctx context.Context,
+	q store.Querier,
+	orgID, id, userID, decision, comment string,
+) (ExecApprovalRequest, error) {
+	var voteID string
+	err := q.QueryRowContext(
+		ctx,
+		`INSERT INTO exec_approval_votes (org_id, request_id, user_id, decision, comment)
+		 SELECT $1, id, $3, $4, NULLIF($5, '')
+		   FROM exec_approval_requests
+		  WHERE org_id = $1 AND id = $2 AND status = 'pending'
+		 ON CONFLICT (request_id, user_id) DO NOTHING
+		 RETURNING id`,
+		orgID,
+		id,
+		userID,
+		decision,
+		strings.TrimSpace(comment),
+	).Scan(&voteID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			current, getErr := getExecApprovalByID(ctx, q, orgID, id)
+			if getErr != nil {
+				return ExecApprovalRequest{}, getErr
+			}
+			if current.Status != string(ExecApprovalStatusPending) {
+				return ExecApprovalRequest{}, sql.ErrNoRows
+			}
+			return ExecApprovalRequest{}, errExecApprovalDuplicateVote
+		}
+		return ExecApprovalRequest{}, err
+	}
+
+	increment := 0
+	if decision == "approve" {
+		increment = 1
+	}
+	row := q.QueryRowContext(
+		ctx,
+		`UPDATE exec_approval_requests
+		 SET approval_count = approval_count + $3
+		 WHERE org_id = $1 AND id = $2
+		 RETURNING `+execApprovalRequestColumns,
+		orgID,
+		id,
+		increment,
 	)
 	return scanExecApprovalRequest(row)
 }
@@ -512,3 +708,19 @@ func broadcastExecApprovalResolved(hub *ws.Hub, orgID string, approval ExecAppro
 
 	hub.Broadcast(orgID, payload)
 }
+
+func broadcastExecApprovalUpdated(hub *ws.Hub, orgID string, approval ExecApprovalRequest) {
+	if hub == nil {
+		return
+	}
+
+	payload, err := json.Marshal(map[string]interface{}{
+		"type": "ExecApprovalUpdated",
+		"data": approval,
+	})
+	if err != nil {
+		return
+	}
+
+	hub.Broadcast(orgID, payload)
+}
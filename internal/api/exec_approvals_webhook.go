@@ -5,8 +5,11 @@ import (
 	"database/sql"
 	"encoding/json"
 	"errors"
+	"log"
 	"strings"
 
+	"github.com/lib/pq"
+	"github.com/samhotchkiss/otter-camp/internal/middleware"
 	"github.com/samhotchkiss/otter-camp/internal/store"
 	"github.com/samhotchkiss/otter-camp/internal/ws"
 )
@@ -23,6 +26,8 @@ type execApprovalWebhookFields struct {
 	Message     *string
 	CallbackURL *string
 	Request     json.RawMessage
+	ProjectID   *string
+	RiskTags    []string
 }
 
 func isExecApprovalEvent(event string) bool {
@@ -55,12 +60,32 @@ func handleExecApprovalWebhook(ctx context.Context, db *sql.DB, hub *ws.Hub, org
 	if err != nil {
 		return nil, err
 	}
+	if inserted == nil {
+		return nil, nil
+	}
 
-	if inserted != nil && hub != nil {
-		broadcastExecApprovalRequested(hub, orgID, *inserted)
+	rules, err := store.NewExecApprovalRuleStore(db).ListApplicable(
+		context.WithValue(ctx, middleware.WorkspaceIDKey, orgID),
+		inserted.ProjectID,
+	)
+	if err != nil {
+		log.Printf("exec approval %s: failed to load approval rules: %v", inserted.ID, err)
+		rules = nil
+	}
+	decided, decision, err := applyExecApprovalPolicy(ctx, conn, rules, *inserted)
+	if err != nil {
+		log.Printf("exec approval %s: failed to apply approval rule: %v", inserted.ID, err)
 	}
 
-	return inserted, nil
+	if hub != nil {
+		if decided.Status == string(ExecApprovalStatusPending) {
+			broadcastExecApprovalRequested(hub, orgID, decided)
+		} else if decision.automated() {
+			broadcastExecApprovalResolved(hub, orgID, decided)
+		}
+	}
+
+	return &decided, nil
 }
 
 func insertExecApprovalRequest(ctx context.Context, q store.Querier, orgID string, fields execApprovalWebhookFields) (*ExecApprovalRequest, error) {
@@ -79,6 +104,18 @@ func insertExecApprovalRequest(ctx context.Context, q store.Querier, orgID strin
 		taskArg = strings.TrimSpace(*fields.TaskID)
 	}
 
+	var projectArg interface{}
+	if fields.ProjectID != nil && uuidRegex.MatchString(*fields.ProjectID) {
+		projectArg = strings.TrimSpace(*fields.ProjectID)
+	}
+
+	riskTags := make([]string, 0, len(fields.RiskTags))
+	for _, tag := range fields.RiskTags {
+		if tag = strings.ToLower(strings.TrimSpace(tag)); tag != "" {
+			riskTags = append(riskTags, tag)
+		}
+	}
+
 	command := strings.TrimSpace(fields.Command)
 	if command == "" {
 		command = "(unknown command)"
@@ -87,12 +124,12 @@ func insertExecApprovalRequest(ctx context.Context, q store.Querier, orgID strin
 	row := q.QueryRowContext(
 		ctx,
 		`INSERT INTO exec_approval_requests (
-			org_id, external_id, agent_id, task_id, status, command, cwd, shell, args, env, message, callback_url, request
+			org_id, external_id, agent_id, task_id, status, command, cwd, shell, args, env, message, callback_url, request, project_id, risk_tags
 		) VALUES (
-			$1, $2, $3, $4, 'pending', $5, $6, $7, $8, $9, $10, $11, $12
+			$1, $2, $3, $4, 'pending', $5, $6, $7, $8, $9, $10, $11, $12, $13, $14
 		)
 		ON CONFLICT (org_id, external_id) WHERE external_id IS NOT NULL DO NOTHING
-		RETURNING id, org_id, external_id, agent_id, task_id, status, command, cwd, shell, args, env, message, callback_url, request, response, created_at, resolved_at`,
+		RETURNING `+execApprovalRequestColumns,
 		orgID,
 		externalArg,
 		agentArg,
@@ -105,6 +142,8 @@ func insertExecApprovalRequest(ctx context.Context, q store.Querier, orgID strin
 		fields.Message,
 		fields.CallbackURL,
 		nullRawMessage(fields.Request),
+		projectArg,
+		pq.Array(riskTags),
 	)
 
 	approval, err := scanExecApprovalRequest(row)
@@ -166,6 +205,10 @@ func parseExecApprovalWebhookPayload(body []byte) (execApprovalWebhookFields, er
 	fields.Cwd = nonEmptyPtr(findStringIn(candidates, "cwd", "working_dir", "working_directory", "workdir"))
 	fields.Shell = nonEmptyPtr(findStringIn(candidates, "shell"))
 	fields.Message = nonEmptyPtr(findStringIn(candidates, "message", "reason", "explanation", "summary", "context"))
+	fields.ProjectID = nonEmptyPtr(findStringIn([]map[string]interface{}{root, request, data, exec, approval}, "project_id", "projectId"))
+	if tagsValue, ok := findValueIn(candidates, "risk_tags", "riskTags", "risk"); ok {
+		fields.RiskTags = normalizeRiskTags(tagsValue)
+	}
 
 	commandValue, commandOK := findValueIn(candidates, "command", "cmd")
 	if commandOK {
@@ -265,3 +308,25 @@ func nonEmptyPtr(value string) *string {
 	}
 	return &trimmed
 }
+
+// normalizeRiskTags accepts risk tags as a JSON array or a comma-separated string.
+func normalizeRiskTags(value interface{}) []string {
+	var raw []string
+	switch typed := value.(type) {
+	case string:
+		raw = strings.Split(typed, ",")
+	case []interface{}:
+		for _, item := range typed {
+			if s, ok := item.(string); ok {
+				raw = append(raw, s)
+			}
+		}
+	}
+	tags := make([]string, 0, len(raw))
+	for _, tag := range raw {
+		if tag = strings.ToLower(strings.TrimSpace(tag)); tag != "" {
+			tags = append(tags, tag)
+		}
+	}
+	return tags
+}
@@ -163,6 +163,15 @@ func HandleGitTokensCreate(w http.ResponseWriter, r *http.Request) {
 		sendJSON(w, http.StatusInternalServerError, errorResponse{Error: "failed to create token"})
 		return
 	}
+	// Recorded before the raw token is attached.
+	recordAudit(r, auditEvent{
+		OrgID:      identity.OrgID,
+		UserID:     identity.UserID,
+		Action:     "git_token.create",
+		TargetType: "git_token",
+		TargetID:   created.ID,
+		After:      created,
+	})
 	created.Token = token
 
 	sendJSON(w, http.StatusOK, created)
@@ -207,6 +216,14 @@ func HandleGitTokensRevoke(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	recordAudit(r, auditEvent{
+		OrgID:      identity.OrgID,
+		UserID:     identity.UserID,
+		Action:     "git_token.revoke",
+		TargetType: "git_token",
+		TargetID:   id,
+		After:      token,
+	})
 	sendJSON(w, http.StatusOK, token)
 }
 
@@ -294,6 +311,14 @@ func HandleGitSSHKeysCreate(w http.ResponseWriter, r *http.Request) {
 		sendJSON(w, http.StatusInternalServerError, errorResponse{Error: "failed to create key"})
 		return
 	}
+	recordAudit(r, auditEvent{
+		OrgID:      identity.OrgID,
+		UserID:     identity.UserID,
+		Action:     "ssh_key.create",
+		TargetType: "ssh_key",
+		TargetID:   created.ID,
+		After:      created,
+	})
 
 	sendJSON(w, http.StatusOK, created)
 }
@@ -337,6 +362,14 @@ func HandleGitSSHKeysRevoke(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	recordAudit(r, auditEvent{
+		OrgID:      identity.OrgID,
+		UserID:     identity.UserID,
+		Action:     "ssh_key.revoke",
+		TargetType: "ssh_key",
+		TargetID:   id,
+		After:      key,
+	})
 	sendJSON(w, http.StatusOK, key)
 }
 
@@ -577,6 +577,7 @@ func (h *GitHubIntegrationHandler) Disconnect(w http.ResponseWriter, r *http.Req
 		sendJSON(w, http.StatusInternalServerError, errorResponse{Error: "failed to disconnect github integration"})
 		return
 	}
+	recordAudit(r, auditEvent{OrgID: orgID, Action: "github_integration.disconnect", TargetType: "github_integration", TargetID: orgID})
 
 	sendJSON(w, http.StatusOK, map[string]any{
 		"disconnected": true,
@@ -908,6 +909,14 @@ func (h *GitHubIntegrationHandler) UpdateSettings(w http.ResponseWriter, r *http
 		return
 	}
 
+	recordAudit(r, auditEvent{
+		OrgID:      orgID,
+		Action:     "github_integration.settings.update",
+		TargetType: "project",
+		TargetID:   projectID,
+		Before:     existing,
+		After:      upserted,
+	})
 	sendJSON(w, http.StatusOK, map[string]any{
 		"binding":         upserted,
 		"active_branches": activeBranches,
@@ -2,6 +2,7 @@ package api
 
 import (
 	"database/sql"
+	"errors"
 	"net/http"
 	"sort"
 	"strings"
@@ -212,6 +213,19 @@ func HandleInbox(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
+	if ref := strings.TrimSpace(r.URL.Query().Get("view")); ref != "" {
+		viewItems, err := inboxViewItems(r, db, orgID, ref, limit)
+		if err != nil {
+			if errors.Is(err, store.ErrNotFound) || errors.Is(err, store.ErrConflict) || errors.Is(err, store.ErrValidation) {
+				handleIssueViewError(w, err)
+				return
+			}
+			sendJSON(w, http.StatusInternalServerError, errorResponse{Error: "failed to load inbox view"})
+			return
+		}
+		items = append(items, viewItems...)
+	}
+
 	sort.Slice(items, func(i, j int) bool {
 		return items[i].CreatedAt.After(items[j].CreatedAt)
 	})
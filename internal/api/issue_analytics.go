@@ -0,0 +1,217 @@
+package api
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/samhotchkiss/otter-camp/internal/middleware"
+	"github.com/samhotchkiss/otter-camp/internal/store"
+)
+
+const (
+	issueAnalyticsDatasetGroups = "groups"
+	issueAnalyticsDatasetSteps  = "steps"
+	issueAnalyticsDatasetSeries = "series"
+	issueAnalyticsDatasetIssues = "issues"
+)
+
+// issueAnalyticsStatuses orders the time-in-status CSV columns.
+var issueAnalyticsStatuses = []string{
+	store.IssueWorkStatusQueued,
+	store.IssueWorkStatusInProgress,
+	store.IssueWorkStatusReview,
+	store.IssueWorkStatusBlocked,
+	store.IssueWorkStatusOnHold,
+}
+
+// IssueAnalyticsHandler serves project flow metrics: cycle and lead time,
+// time in step, rework, WIP and throughput.
+type IssueAnalyticsHandler struct {
+	Store *store.IssueAnalyticsStore
+}
+
+// Get handles GET /api/projects/{id}/analytics?since=30d&group_by=agent|label|priority&bucket=day|week&format=json|csv&dataset=groups|steps|series|issues
+//
+// The CSV export flattens one dataset; groups is the default and always
+// starts with the "all" row.
+func (h *IssueAnalyticsHandler) Get(w http.ResponseWriter, r *http.Request) {
+	if h.Store == nil {
+		sendJSON(w, http.StatusServiceUnavailable, errorResponse{Error: "database not available"})
+		return
+	}
+	orgID := middleware.WorkspaceFromContext(r.Context())
+	if orgID == "" {
+		sendJSON(w, http.StatusUnauthorized, errorResponse{Error: "authentication required"})
+		return
+	}
+
+	query := r.URL.Query()
+	days, err := parseAgentReviewSince(query.Get("since"))
+	if err != nil {
+		sendJSON(w, http.StatusBadRequest, errorResponse{Error: err.Error()})
+		return
+	}
+	format := strings.ToLower(strings.TrimSpace(query.Get("format")))
+	switch format {
+	case "":
+		format = agentReviewFormatJSON
+	case agentReviewFormatJSON, agentReviewFormatCSV:
+	default:
+		sendJSON(w, http.StatusBadRequest, errorResponse{Error: "format must be json or csv"})
+		return
+	}
+	dataset := strings.ToLower(strings.TrimSpace(query.Get("dataset")))
+	switch dataset {
+	case "":
+		dataset = issueAnalyticsDatasetGroups
+	case issueAnalyticsDatasetGroups, issueAnalyticsDatasetSteps, issueAnalyticsDatasetSeries, issueAnalyticsDatasetIssues:
+	default:
+		sendJSON(w, http.StatusBadRequest, errorResponse{Error: "dataset must be groups, steps, series or issues"})
+		return
+	}
+
+	report, err := h.Store.ProjectAnalytics(r.Context(), orgID, store.IssueAnalyticsInput{
+		ProjectID: chi.URLParam(r, "id"),
+		End:       time.Now(),
+		Days:      days,
+		GroupBy:   query.Get("group_by"),
+		Bucket:    query.Get("bucket"),
+	})
+	if err != nil {
+		handleJobsStoreError(w, err)
+		return
+	}
+
+	if format != agentReviewFormatCSV {
+		sendJSON(w, http.StatusOK, report)
+		return
+	}
+	body, err := renderIssueAnalyticsCSV(report, dataset)
+	if err != nil {
+		sendJSON(w, http.StatusInternalServerError, errorResponse{Error: "failed to render report"})
+		return
+	}
+	filename := fmt.Sprintf("analytics-%s-%s.csv", dataset, report.To.AddDate(0, 0, -1).Format("20060102"))
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+	w.Header().Set("Content-Type", "text/csv; charset=utf-8")
+	w.WriteHeader(http.StatusOK)
+	_, _ = w.Write(body)
+}
+
+func formatIssueAnalyticsSeconds(v *float64) string {
+	if v == nil {
+		return ""
+	}
+	return strconv.FormatFloat(*v, 'f', -1, 64)
+}
+
+func formatIssueAnalyticsTime(v *time.Time) string {
+	if v == nil {
+		return ""
+	}
+	return v.UTC().Format(time.RFC3339)
+}
+
+func issueDurationStatsCSV(stats store.IssueDurationStats) []string {
+	return []string{
+		strconv.Itoa(stats.Count),
+		formatIssueAnalyticsSeconds(stats.AvgSeconds),
+		formatIssueAnalyticsSeconds(stats.P50Seconds),
+		formatIssueAnalyticsSeconds(stats.P85Seconds),
+		formatIssueAnalyticsSeconds(stats.MaxSeconds),
+	}
+}
+
+func issueDurationStatsCSVHeader(prefix string) []string {
+	return []string{prefix + "_count", prefix + "_avg_seconds", prefix + "_p50_seconds", prefix + "_p85_seconds", prefix + "_max_seconds"}
+}
+
+// renderIssueAnalyticsCSV writes one dataset of the report. Durations are
+// seconds; group rows repeat the overall totals first as group "all".
+func renderIssueAnalyticsCSV(report *store.IssueAnalyticsReport, dataset string) ([]byte, error) {
+	groups := append([]store.IssueAnalyticsGroup{report.Overall}, report.Groups...)
+	var rows [][]string
+
+	switch dataset {
+	case issueAnalyticsDatasetSteps:
+		header := []string{"group", "group_name", "kind", "step_key", "step_name", "issues", "visits"}
+		rows = append(rows, append(header, issueDurationStatsCSVHeader("time")...))
+		for _, group := range groups {
+			for _, step := range group.Steps {
+				row := []string{group.Key, group.Name, step.Kind, step.Key, step.Name, strconv.Itoa(step.Issues), strconv.Itoa(step.Visits)}
+				rows = append(rows, append(row, issueDurationStatsCSV(step.Duration)...))
+			}
+		}
+	case issueAnalyticsDatasetSeries:
+		rows = append(rows, []string{"group", "group_name", "start", "end", "throughput", "wip"})
+		for _, group := range groups {
+			for _, bucket := range group.Series {
+				rows = append(rows, []string{
+					group.Key, group.Name, bucket.Start.Format("2006-01-02"), bucket.End.Format("2006-01-02"),
+					strconv.Itoa(bucket.Throughput), strconv.Itoa(bucket.WIP),
+				})
+			}
+		}
+	case issueAnalyticsDatasetIssues:
+		header := []string{
+			"issue_number", "title", "priority", "work_status", "owner", "labels",
+			"created_at", "started_at", "closed_at", "lead_seconds", "cycle_seconds", "rework_bounces",
+		}
+		for _, status := range issueAnalyticsStatuses {
+			header = append(header, status+"_seconds")
+		}
+		rows = append(rows, header)
+		for _, issue := range report.Issues {
+			row := []string{
+				strconv.FormatInt(issue.IssueNumber, 10), issue.Title, issue.Priority, issue.WorkStatus,
+				issue.OwnerName, strings.Join(issue.Labels, ";"),
+				formatIssueAnalyticsTime(&issue.CreatedAt), formatIssueAnalyticsTime(issue.StartedAt), formatIssueAnalyticsTime(issue.ClosedAt),
+				formatIssueAnalyticsSeconds(issue.LeadSeconds), formatIssueAnalyticsSeconds(issue.CycleSeconds),
+				strconv.Itoa(issue.ReworkBounces),
+			}
+			for _, status := range issueAnalyticsStatuses {
+				value := ""
+				if seconds, ok := issue.StatusSeconds[status]; ok {
+					value = strconv.FormatFloat(seconds, 'f', -1, 64)
+				}
+				row = append(row, value)
+			}
+			rows = append(rows, row)
+		}
+	default:
+		header := []string{"group_by", "group", "group_name", "from", "to", "completed", "cancelled", "open_at_end"}
+		header = append(header, issueDurationStatsCSVHeader("cycle")...)
+		header = append(header, issueDurationStatsCSVHeader("lead")...)
+		header = append(header, "rework_bounces", "issues_with_rework", "rework_rate")
+		for _, status := range issueAnalyticsStatuses {
+			header = append(header, status+"_avg_seconds")
+		}
+		rows = append(rows, header)
+		for _, group := range groups {
+			row := []string{
+				report.GroupBy, group.Key, group.Name, report.From.Format("2006-01-02"), report.To.Format("2006-01-02"),
+				strconv.Itoa(group.Completed), strconv.Itoa(group.Cancelled), strconv.Itoa(group.OpenAtEnd),
+			}
+			row = append(row, issueDurationStatsCSV(group.CycleTime)...)
+			row = append(row, issueDurationStatsCSV(group.LeadTime)...)
+			row = append(row, strconv.Itoa(group.ReworkBounces), strconv.Itoa(group.IssuesWithRework), formatIssueAnalyticsSeconds(group.ReworkRate))
+			for _, status := range issueAnalyticsStatuses {
+				row = append(row, formatIssueAnalyticsSeconds(group.TimeInStatus[status].AvgSeconds))
+			}
+			rows = append(rows, row)
+		}
+	}
+
+	var buf bytes.Buffer
+	writer := csv.NewWriter(&buf)
+	if err := writer.WriteAll(rows); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,130 @@
+package api
+
+import (
+	"context"
+	"encoding/csv"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/samhotchkiss/otter-camp/internal/middleware"
+	"github.com/samhotchkiss/otter-camp/internal/store"
+	"github.com/stretchr/testify/require"
+)
+
+func issueAnalyticsTestReport() *store.IssueAnalyticsReport {
+	cycle := 7200.0
+	lead := 86400.0
+	rate := 0.5
+	closed := time.Date(2026, 10, 10, 12, 0, 0, 0, time.UTC)
+	stats := func(v *float64) store.IssueDurationStats {
+		return store.IssueDurationStats{Count: 1, AvgSeconds: v, P50Seconds: v, P85Seconds: v, MaxSeconds: v}
+	}
+	group := func(key, name string) store.IssueAnalyticsGroup {
+		return store.IssueAnalyticsGroup{
+			Key:          key,
+			Name:         name,
+			Completed:    2,
+			OpenAtEnd:    1,
+			CycleTime:    stats(&cycle),
+			LeadTime:     stats(&lead),
+			TimeInStatus: map[string]store.IssueDurationStats{store.IssueWorkStatusReview: stats(&cycle)},
+			Steps: []store.IssueAnalyticsStep{
+				{Kind: store.IssueAnalyticsStepPipeline, Key: "s-1", Name: "Review", Issues: 2, Visits: 3, Duration: stats(&cycle)},
+			},
+			ReworkBounces:    1,
+			IssuesWithRework: 1,
+			ReworkRate:       &rate,
+			Series: []store.IssueAnalyticsBucket{
+				{Start: time.Date(2026, 10, 10, 0, 0, 0, 0, time.UTC), End: time.Date(2026, 10, 11, 0, 0, 0, 0, time.UTC), Throughput: 2, WIP: 1},
+			},
+		}
+	}
+	return &store.IssueAnalyticsReport{
+		ProjectID: "p-1",
+		From:      time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC),
+		To:        time.Date(2026, 10, 19, 0, 0, 0, 0, time.UTC),
+		Days:      18,
+		Bucket:    store.IssueAnalyticsBucketDay,
+		GroupBy:   store.IssueAnalyticsGroupAgent,
+		Overall:   group("all", "All issues"),
+		Groups:    []store.IssueAnalyticsGroup{group("a-1", "Reviewer")},
+		Issues: []store.IssueAnalyticsIssue{{
+			IssueNumber:   7,
+			Title:         "Ship it",
+			Priority:      store.IssuePriorityP1,
+			WorkStatus:    store.IssueWorkStatusDone,
+			OwnerName:     "Reviewer",
+			Labels:        []string{"api", "ui"},
+			CreatedAt:     closed.Add(-24 * time.Hour),
+			ClosedAt:      &closed,
+			LeadSeconds:   &lead,
+			CycleSeconds:  &cycle,
+			StatusSeconds: map[string]float64{store.IssueWorkStatusReview: 3600},
+		}},
+	}
+}
+
+func TestRenderIssueAnalyticsCSV(t *testing.T) {
+	report := issueAnalyticsTestReport()
+	read := func(dataset string) [][]string {
+		body, err := renderIssueAnalyticsCSV(report, dataset)
+		require.NoError(t, err)
+		records, err := csv.NewReader(strings.NewReader(string(body))).ReadAll()
+		require.NoError(t, err)
+		return records
+	}
+	column := func(records [][]string, key string) int {
+		for i, name := range records[0] {
+			if name == key {
+				return i
+			}
+		}
+		t.Fatalf("missing column %s", key)
+		return -1
+	}
+
+	groups := read(issueAnalyticsDatasetGroups)
+	require.Len(t, groups, 3)
+	require.Equal(t, []string{"agent", "all", "All issues", "2026-10-01", "2026-10-19"}, groups[1][:5])
+	require.Equal(t, "a-1", groups[2][1])
+	require.Equal(t, "7200", groups[1][column(groups, "cycle_p85_seconds")])
+	require.Equal(t, "0.5", groups[1][column(groups, "rework_rate")])
+	require.Equal(t, "7200", groups[1][column(groups, "review_avg_seconds")])
+	require.Equal(t, "", groups[1][column(groups, "queued_avg_seconds")])
+
+	steps := read(issueAnalyticsDatasetSteps)
+	require.Len(t, steps, 3)
+	require.Equal(t, []string{"all", "All issues", "pipeline", "s-1", "Review", "2", "3"}, steps[1][:7])
+
+	series := read(issueAnalyticsDatasetSeries)
+	require.Equal(t, []string{"a-1", "Reviewer", "2026-10-10", "2026-10-11", "2", "1"}, series[2])
+
+	issues := read(issueAnalyticsDatasetIssues)
+	require.Len(t, issues, 2)
+	require.Equal(t, "api;ui", issues[1][column(issues, "labels")])
+	require.Equal(t, "", issues[1][column(issues, "started_at")])
+	require.Equal(t, "2026-10-10T12:00:00Z", issues[1][column(issues, "closed_at")])
+	require.Equal(t, "3600", issues[1][column(issues, "review_seconds")])
+}
+
+func TestIssueAnalyticsHandlerValidatesRequest(t *testing.T) {
+	rec := httptest.NewRecorder()
+	(&IssueAnalyticsHandler{}).Get(rec, httptest.NewRequest(http.MethodGet, "/api/projects/p/analytics", nil))
+	require.Equal(t, http.StatusServiceUnavailable, rec.Code)
+
+	handler := &IssueAnalyticsHandler{Store: store.NewIssueAnalyticsStore(nil)}
+	rec = httptest.NewRecorder()
+	handler.Get(rec, httptest.NewRequest(http.MethodGet, "/api/projects/p/analytics", nil))
+	require.Equal(t, http.StatusUnauthorized, rec.Code)
+
+	for _, query := range []string{"since=soon", "format=xlsx", "dataset=people"} {
+		req := httptest.NewRequest(http.MethodGet, "/api/projects/p/analytics?"+query, nil)
+		req = req.WithContext(context.WithValue(req.Context(), middleware.WorkspaceIDKey, "00000000-0000-0000-0000-000000000001"))
+		rec = httptest.NewRecorder()
+		handler.Get(rec, req)
+		require.Equal(t, http.StatusBadRequest, rec.Code, query)
+	}
+}
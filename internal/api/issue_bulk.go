@@ -0,0 +1,164 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/samhotchkiss/otter-camp/internal/middleware"
+	"github.com/samhotchkiss/otter-camp/internal/store"
+)
+
+type issueBulkRequest struct {
+	IssueIDs   []string                  `json:"issue_ids"`
+	Query      string                    `json:"query"`
+	View       string                    `json:"view"`
+	ProjectID  string                    `json:"project_id"`
+	Operations store.IssueBulkOperations `json:"operations"`
+	DryRun     bool                      `json:"dry_run"`
+}
+
+// Bulk handles POST /api/issues/bulk. It applies one operation set to an
+// explicit issue list or to every issue matching query/view (the same
+// language as GET /api/issues), all or nothing. Agent kickoff and close
+// compliance reviews are not run for bulk edits.
+func (h *IssuesHandler) Bulk(w http.ResponseWriter, r *http.Request) {
+	if h.IssueStore == nil {
+		sendJSON(w, http.StatusServiceUnavailable, errorResponse{Error: "database not available"})
+		return
+	}
+	orgID := middleware.WorkspaceFromContext(r.Context())
+	if orgID == "" {
+		sendJSON(w, http.StatusUnauthorized, errorResponse{Error: "authentication required"})
+		return
+	}
+
+	var req issueBulkRequest
+	decoder := json.NewDecoder(r.Body)
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&req); err != nil {
+		sendJSON(w, http.StatusBadRequest, errorResponse{Error: "invalid JSON"})
+		return
+	}
+
+	if destination := strings.TrimSpace(req.Operations.MoveToProjectID); destination != "" && h.DB != nil {
+		allowed, err := requestHasProjectCapability(r, h.DB, CapabilityIssuesWrite, destination)
+		if err != nil {
+			handleCapabilityAuthError(w, err, CapabilityIssuesWrite)
+			return
+		}
+		if !allowed {
+			sendJSON(w, http.StatusForbidden, forbiddenCapabilityResponse{Error: "forbidden", Capability: CapabilityIssuesWrite})
+			return
+		}
+	}
+
+	userID := sessionUserID(r, h.DB, orgID)
+	input := store.BulkUpdateIssuesInput{
+		IssueIDs:   req.IssueIDs,
+		ProjectID:  strings.TrimSpace(req.ProjectID),
+		Now:        time.Now().UTC(),
+		Operations: req.Operations,
+		DryRun:     req.DryRun,
+		ActorID:    userID,
+	}
+	if strings.TrimSpace(req.Query) != "" || strings.TrimSpace(req.View) != "" {
+		if len(req.IssueIDs) > 0 {
+			sendJSON(w, http.StatusBadRequest, errorResponse{Error: "pass either issue_ids or query/view, not both"})
+			return
+		}
+		values := url.Values{}
+		values.Set("q", req.Query)
+		values.Set("view", req.View)
+		values.Set("project_id", input.ProjectID)
+		query, raw, view, err := resolveIssueQuery(r.Context(), h.SavedViewStore, values, orgID, userID)
+		if err != nil {
+			if errors.Is(err, errIssueHandlerDatabaseUnavailable) {
+				sendJSON(w, http.StatusServiceUnavailable, errorResponse{Error: "database not available"})
+				return
+			}
+			handleIssueViewError(w, err)
+			return
+		}
+		if input.ProjectID == "" && view != nil && view.ProjectID != nil {
+			input.ProjectID = *view.ProjectID
+		}
+		input.Query = &query
+		input.RawQuery = raw
+	}
+
+	if templateID := strings.TrimSpace(req.Operations.FlowTemplateID); templateID != "" {
+		flow, ok := h.resolveIssueBulkFlow(w, r, templateID)
+		if !ok {
+			return
+		}
+		input.Flow = flow
+	}
+
+	result, err := h.IssueStore.BulkUpdateIssues(r.Context(), input)
+	if err != nil {
+		handleJobsStoreError(w, err)
+		return
+	}
+
+	if result.Applied {
+		for _, item := range result.Items {
+			if item.Status != store.IssueBulkItemUpdated || item.After == nil {
+				continue
+			}
+			h.applyAutomaticIssueLabelsBestEffort(r.Context(), item.After)
+			if h.ChatThreadStore != nil &&
+				!strings.EqualFold(item.Before.State, "closed") &&
+				strings.EqualFold(item.After.State, "closed") {
+				if _, archiveErr := h.ChatThreadStore.AutoArchiveByIssue(r.Context(), item.IssueID); archiveErr != nil {
+					log.Printf("issues: failed auto-archive for closed issue %s: %v", item.IssueID, archiveErr)
+				}
+			}
+		}
+		if result.Updated > 0 {
+			recordAudit(r, auditEvent{
+				Action:     "issue.bulk_update",
+				TargetType: "issue",
+				TargetID:   *result.ActivityID,
+				After:      map[string]any{"operations": req.Operations, "updated": result.Updated, "query": input.RawQuery},
+			})
+			log.Printf("[issues] bulk update applied to %d of %d issues org=%s", result.Updated, result.Matched, orgID)
+		}
+	}
+	sendJSON(w, http.StatusOK, result)
+}
+
+// resolveIssueBulkFlow loads a flow template and its first step the same way
+// AssignFlow does, so bulk assignment picks the same step owner.
+func (h *IssuesHandler) resolveIssueBulkFlow(w http.ResponseWriter, r *http.Request, templateID string) (*store.IssueBulkFlow, bool) {
+	if h.FlowStore == nil {
+		sendJSON(w, http.StatusServiceUnavailable, errorResponse{Error: "flow system unavailable"})
+		return nil, false
+	}
+	template, err := h.FlowStore.GetTemplateByID(r.Context(), templateID)
+	if err != nil {
+		handleFlowTemplateStoreError(w, err)
+		return nil, false
+	}
+	steps, err := h.FlowStore.ListTemplateSteps(r.Context(), template.ID)
+	if err != nil {
+		handleFlowTemplateStoreError(w, err)
+		return nil, false
+	}
+	if len(steps) == 0 {
+		sendJSON(w, http.StatusBadRequest, errorResponse{Error: "flow template has no steps"})
+		return nil, false
+	}
+	first := steps[0]
+	return &store.IssueBulkFlow{
+		TemplateID:   template.ID,
+		ProjectID:    template.ProjectID,
+		StepKey:      first.StepKey,
+		StepIndex:    first.StepOrder,
+		OwnerAgentID: h.resolveFlowStepOwnerAgentID(r.Context(), template.ProjectID, first),
+	}, true
+}
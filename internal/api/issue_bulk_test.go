@@ -0,0 +1,42 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/samhotchkiss/otter-camp/internal/middleware"
+	"github.com/samhotchkiss/otter-camp/internal/store"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIssueBulkRejectsInvalidRequests(t *testing.T) {
+	rec := httptest.NewRecorder()
+	(&IssuesHandler{}).Bulk(rec, httptest.NewRequest(http.MethodPost, "/api/issues/bulk", strings.NewReader(`{}`)))
+	require.Equal(t, http.StatusServiceUnavailable, rec.Code)
+
+	handler := &IssuesHandler{IssueStore: store.NewProjectIssueStore(nil)}
+	rec = httptest.NewRecorder()
+	handler.Bulk(rec, httptest.NewRequest(http.MethodPost, "/api/issues/bulk", strings.NewReader(`{}`)))
+	require.Equal(t, http.StatusUnauthorized, rec.Code)
+
+	orgCtx := context.WithValue(context.Background(), middleware.WorkspaceIDKey, "00000000-0000-0000-0000-000000000001")
+	for body, want := range map[string]string{
+		`{"issue_ids":["a"],"operations":{"colour":"red"}}`:                                              "invalid JSON",
+		`{"issue_ids":["00000000-0000-0000-0000-0000000000aa"],"query":"is:open","operations":{}}`:       "not both",
+		`{"query":"priority:P9","operations":{"priority":"P1"}}`:                                         "invalid priority",
+		`{"issue_ids":["00000000-0000-0000-0000-0000000000aa"],"operations":{}}`:                         "at least one operation",
+		`{"issue_ids":["00000000-0000-0000-0000-0000000000aa"],"operations":{"state":"archived"}}`:       "state must be open or closed",
+		`{"operations":{"priority":"P1"}}`:                                                               "either issue_ids or a query",
+		`{"issue_ids":["12"],"operations":{"priority":"P1"}}`:                                            "invalid issue id",
+		`{"issue_ids":["00000000-0000-0000-0000-0000000000aa"],"operations":{"flow_template_id":"f-1"}}`: "flow system unavailable",
+	} {
+		rec := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodPost, "/api/issues/bulk", strings.NewReader(body)).WithContext(orgCtx)
+		handler.Bulk(rec, req)
+		require.NotEqual(t, http.StatusOK, rec.Code, body)
+		require.Contains(t, rec.Body.String(), want, body)
+	}
+}
@@ -0,0 +1,207 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/samhotchkiss/otter-camp/internal/memory"
+	"github.com/samhotchkiss/otter-camp/internal/store"
+)
+
+const (
+	issueDuplicateThresholdEnv   = "OTTER_ISSUE_DUPLICATE_THRESHOLD"
+	issueDuplicateBlockAgentsEnv = "OTTER_ISSUE_DUPLICATE_BLOCK_AGENTS"
+)
+
+var (
+	issueEmbedderRegistryMu sync.RWMutex
+	issueEmbedderRegistry   memory.Embedder
+)
+
+// RegisterIssueEmbedder sets the embedder used to check new issues for
+// duplicates. The server registers the conversation embedder at startup;
+// without one, issues are created without a duplicate check.
+func RegisterIssueEmbedder(embedder memory.Embedder) {
+	issueEmbedderRegistryMu.Lock()
+	issueEmbedderRegistry = embedder
+	issueEmbedderRegistryMu.Unlock()
+}
+
+type issueDuplicatePayload struct {
+	Issue      issueSummaryPayload `json:"issue"`
+	Similarity float64             `json:"similarity"`
+}
+
+type issueCreateResponse struct {
+	issueSummaryPayload
+	Duplicates []issueDuplicatePayload `json:"duplicates,omitempty"`
+}
+
+type issueDuplicateConflictResponse struct {
+	Error      string                  `json:"error"`
+	Duplicates []issueDuplicatePayload `json:"duplicates"`
+}
+
+type issueMergeRequest struct {
+	IntoIssueID string `json:"into_issue_id"`
+}
+
+type issueMergeResponse struct {
+	Duplicate         issueSummaryPayload `json:"duplicate"`
+	Canonical         issueSummaryPayload `json:"canonical"`
+	MovedComments     int                 `json:"moved_comments"`
+	MovedParticipants int                 `json:"moved_participants"`
+	MovedLabels       int                 `json:"moved_labels"`
+	MovedSubIssues    int                 `json:"moved_sub_issues"`
+	MovedGitHubLink   bool                `json:"moved_github_link"`
+}
+
+// issueDuplicateCheck is the result of embedding a new issue's text. The
+// embedding is kept so it can be stored once the issue exists.
+type issueDuplicateCheck struct {
+	embedding  []float64
+	duplicates []store.IssueDuplicateCandidate
+}
+
+func (h *IssuesHandler) issueEmbedder() memory.Embedder {
+	if h.Embedder != nil {
+		return h.Embedder
+	}
+	issueEmbedderRegistryMu.RLock()
+	defer issueEmbedderRegistryMu.RUnlock()
+	return issueEmbedderRegistry
+}
+
+// checkIssueDuplicates embeds the issue text and looks for similar issues in
+// the project. It is best-effort: embedder or search failures are logged and
+// leave the check empty.
+func (h *IssuesHandler) checkIssueDuplicates(ctx context.Context, projectID, title string, body *string) issueDuplicateCheck {
+	embedder := h.issueEmbedder()
+	if embedder == nil || h.IssueStore == nil {
+		return issueDuplicateCheck{}
+	}
+	vectors, err := embedder.Embed(ctx, []string{store.IssueEmbeddingText(title, body)})
+	if err != nil || len(vectors) != 1 {
+		log.Printf("[issue-duplicates] embed failed project=%s: %v", projectID, err)
+		return issueDuplicateCheck{}
+	}
+	check := issueDuplicateCheck{embedding: vectors[0]}
+	check.duplicates, err = h.IssueStore.FindDuplicateIssues(ctx, store.FindDuplicateIssuesInput{
+		ProjectID: projectID,
+		Embedding: check.embedding,
+		Threshold: issueDuplicateThreshold(),
+	})
+	if err != nil {
+		log.Printf("[issue-duplicates] search failed project=%s: %v", projectID, err)
+		check.duplicates = nil
+	}
+	return check
+}
+
+// storeIssueEmbeddingBestEffort saves the embedding computed during the
+// duplicate check so the worker does not have to embed the issue again.
+func (h *IssuesHandler) storeIssueEmbeddingBestEffort(ctx context.Context, issueID string, embedding []float64) {
+	if len(embedding) == 0 || h.IssueStore == nil {
+		return
+	}
+	if err := h.IssueStore.SetIssueEmbedding(ctx, issueID, embedding); err != nil {
+		log.Printf("[issue-duplicates] failed to store embedding for issue %s: %v", issueID, err)
+	}
+}
+
+// MergeIssue folds the issue in the URL into into_issue_id and leaves the
+// merged issue closed with a redirect to the canonical one.
+func (h *IssuesHandler) MergeIssue(w http.ResponseWriter, r *http.Request) {
+	if h.IssueStore == nil {
+		sendJSON(w, http.StatusServiceUnavailable, errorResponse{Error: "database not available"})
+		return
+	}
+	var req issueMergeRequest
+	decoder := json.NewDecoder(r.Body)
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&req); err != nil {
+		sendJSON(w, http.StatusBadRequest, errorResponse{Error: "invalid JSON"})
+		return
+	}
+	if strings.TrimSpace(req.IntoIssueID) == "" {
+		sendJSON(w, http.StatusBadRequest, errorResponse{Error: "into_issue_id is required"})
+		return
+	}
+
+	result, err := h.IssueStore.MergeIssue(r.Context(), chi.URLParam(r, "id"), req.IntoIssueID)
+	if err != nil {
+		handleSubIssueStoreError(w, err)
+		return
+	}
+	recordAudit(r, auditEvent{
+		Action:     "issue.merge",
+		TargetType: "issue",
+		TargetID:   result.Duplicate.ID,
+		After: map[string]any{
+			"merged_into_issue_id": result.Canonical.ID,
+			"moved_comments":       result.MovedComments,
+			"moved_participants":   result.MovedParticipants,
+			"moved_labels":         result.MovedLabels,
+			"moved_sub_issues":     result.MovedSubIssues,
+			"moved_github_link":    result.MovedGitHubLink,
+		},
+	})
+	log.Printf("[issue-duplicates] merged issue %s into %s", result.Duplicate.ID, result.Canonical.ID)
+	sendJSON(w, http.StatusOK, issueMergeResponse{
+		Duplicate:         toIssueSummaryPayload(result.Duplicate, nil, nil),
+		Canonical:         toIssueSummaryPayload(result.Canonical, nil, nil),
+		MovedComments:     result.MovedComments,
+		MovedParticipants: result.MovedParticipants,
+		MovedLabels:       result.MovedLabels,
+		MovedSubIssues:    result.MovedSubIssues,
+		MovedGitHubLink:   result.MovedGitHubLink,
+	})
+}
+
+func toIssueDuplicatePayloads(candidates []store.IssueDuplicateCandidate) []issueDuplicatePayload {
+	if len(candidates) == 0 {
+		return nil
+	}
+	payloads := make([]issueDuplicatePayload, 0, len(candidates))
+	for _, candidate := range candidates {
+		payloads = append(payloads, issueDuplicatePayload{
+			Issue:      toIssueSummaryPayload(candidate.Issue, nil, nil),
+			Similarity: candidate.Similarity,
+		})
+	}
+	return payloads
+}
+
+func issueDuplicateConflictMessage(candidates []store.IssueDuplicateCandidate) string {
+	best := candidates[0]
+	return fmt.Sprintf("likely duplicate of issue #%d %q (similarity %.2f)", best.Issue.IssueNumber, best.Issue.Title, best.Similarity)
+}
+
+func issueDuplicateThreshold() float64 {
+	raw := strings.TrimSpace(os.Getenv(issueDuplicateThresholdEnv))
+	if raw == "" {
+		return store.DefaultIssueDuplicateThreshold
+	}
+	value, err := strconv.ParseFloat(raw, 64)
+	if err != nil || value <= 0 || value > 1 {
+		return store.DefaultIssueDuplicateThreshold
+	}
+	return value
+}
+
+func shouldBlockAgentIssueDuplicates() bool {
+	switch strings.ToLower(strings.TrimSpace(os.Getenv(issueDuplicateBlockAgentsEnv))) {
+	case "1", "true", "yes", "on":
+		return true
+	default:
+		return false
+	}
+}
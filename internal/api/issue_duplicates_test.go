@@ -0,0 +1,133 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/samhotchkiss/otter-camp/internal/middleware"
+	"github.com/samhotchkiss/otter-camp/internal/store"
+	"github.com/stretchr/testify/require"
+)
+
+// keywordIssueEmbedder maps text to a 768-dimension vector with one axis per
+// known keyword, so texts sharing a keyword are identical and others are
+// orthogonal.
+type keywordIssueEmbedder struct {
+	keywords []string
+}
+
+func (e keywordIssueEmbedder) Dimension() int { return 768 }
+
+func (e keywordIssueEmbedder) Embed(_ context.Context, inputs []string) ([][]float64, error) {
+	out := make([][]float64, 0, len(inputs))
+	for _, input := range inputs {
+		vector := make([]float64, 768)
+		vector[len(e.keywords)] = 1
+		for i, keyword := range e.keywords {
+			if strings.Contains(strings.ToLower(input), keyword) {
+				vector = make([]float64, 768)
+				vector[i] = 1
+				break
+			}
+		}
+		out = append(out, vector)
+	}
+	return out, nil
+}
+
+func TestIssueDuplicateThresholdFromEnv(t *testing.T) {
+	t.Setenv(issueDuplicateThresholdEnv, "")
+	require.Equal(t, store.DefaultIssueDuplicateThreshold, issueDuplicateThreshold())
+	t.Setenv(issueDuplicateThresholdEnv, "0.75")
+	require.Equal(t, 0.75, issueDuplicateThreshold())
+	t.Setenv(issueDuplicateThresholdEnv, "1.5")
+	require.Equal(t, store.DefaultIssueDuplicateThreshold, issueDuplicateThreshold())
+
+	t.Setenv(issueDuplicateBlockAgentsEnv, "true")
+	require.True(t, shouldBlockAgentIssueDuplicates())
+	t.Setenv(issueDuplicateBlockAgentsEnv, "")
+	require.False(t, shouldBlockAgentIssueDuplicates())
+}
+
+func TestMergeIssueRequiresStoreAndValidBody(t *testing.T) {
+	handler := &IssuesHandler{}
+	rec := httptest.NewRecorder()
+	handler.MergeIssue(rec, httptest.NewRequest(http.MethodPost, "/api/issues/x/merge", strings.NewReader(`{}`)))
+	require.Equal(t, http.StatusServiceUnavailable, rec.Code)
+
+	handler = &IssuesHandler{IssueStore: store.NewProjectIssueStore(nil)}
+	for body, want := range map[string]string{
+		`{"into":"x"}`: "invalid JSON",
+		`{}`:           "into_issue_id is required",
+	} {
+		rec := httptest.NewRecorder()
+		handler.MergeIssue(rec, httptest.NewRequest(http.MethodPost, "/", strings.NewReader(body)))
+		require.Equal(t, http.StatusBadRequest, rec.Code, body)
+		require.Contains(t, rec.Body.String(), want, body)
+	}
+}
+
+func TestIssuesHandlerCreateIssueReportsAndBlocksDuplicatesThenMerges(t *testing.T) {
+	db := setupMessageTestDB(t)
+	orgID := insertMessageTestOrganization(t, db, "issues-api-duplicates-org")
+	projectID := insertProjectTestProject(t, db, orgID, "Issue Duplicates Project")
+	agentID := insertMessageTestAgent(t, db, orgID, "issue-duplicates-agent")
+
+	issueStore := store.NewProjectIssueStore(db)
+	handler := &IssuesHandler{
+		IssueStore:   issueStore,
+		ProjectStore: store.NewProjectStore(db),
+		Embedder:     keywordIssueEmbedder{keywords: []string{"login"}},
+	}
+	router := chi.NewRouter()
+	router.With(middleware.OptionalWorkspace).Post("/api/projects/{id}/issues", handler.CreateIssue)
+	router.With(middleware.OptionalWorkspace).Post("/api/issues/{id}/merge", handler.MergeIssue)
+
+	create := func(body string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/api/projects/"+projectID+"/issues?org_id="+orgID, strings.NewReader(body)))
+		return rec
+	}
+
+	rec := create(`{"title":"Login page times out"}`)
+	require.Equal(t, http.StatusCreated, rec.Code, rec.Body.String())
+	var original issueCreateResponse
+	require.NoError(t, json.NewDecoder(rec.Body).Decode(&original))
+	require.Empty(t, original.Duplicates)
+
+	rec = create(`{"title":"Cannot login, request hangs"}`)
+	require.Equal(t, http.StatusCreated, rec.Code, rec.Body.String())
+	var duplicate issueCreateResponse
+	require.NoError(t, json.NewDecoder(rec.Body).Decode(&duplicate))
+	require.Len(t, duplicate.Duplicates, 1)
+	require.Equal(t, original.ID, duplicate.Duplicates[0].Issue.ID)
+
+	t.Setenv(issueDuplicateBlockAgentsEnv, "true")
+	rec = create(`{"title":"Login broken again","agent_id":"` + agentID + `"}`)
+	require.Equal(t, http.StatusConflict, rec.Code)
+	require.Contains(t, rec.Body.String(), "likely duplicate of issue #")
+	rec = create(`{"title":"Update billing copy","agent_id":"` + agentID + `"}`)
+	require.Equal(t, http.StatusCreated, rec.Code)
+
+	_, err := issueStore.CreateComment(issueTestCtx(orgID), store.CreateProjectIssueCommentInput{
+		IssueID:       duplicate.ID,
+		AuthorAgentID: agentID,
+		Body:          "Seeing this on staging too",
+	})
+	require.NoError(t, err)
+
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/api/issues/"+duplicate.ID+"/merge?org_id="+orgID,
+		strings.NewReader(`{"into_issue_id":"`+original.ID+`"}`)))
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	var merged issueMergeResponse
+	require.NoError(t, json.NewDecoder(rec.Body).Decode(&merged))
+	require.Equal(t, 1, merged.MovedComments)
+	require.Equal(t, "closed", merged.Duplicate.State)
+	require.Equal(t, original.ID, *merged.Duplicate.MergedIntoIssueID)
+}
@@ -0,0 +1,264 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/samhotchkiss/otter-camp/internal/middleware"
+	"github.com/samhotchkiss/otter-camp/internal/store"
+)
+
+type issueTreeNodePayload struct {
+	Issue    issueSummaryPayload    `json:"issue"`
+	Rollup   store.IssueTreeRollup  `json:"rollup"`
+	Children []issueTreeNodePayload `json:"children"`
+}
+
+type subIssueAttachRequest struct {
+	ChildIssueID string `json:"child_issue_id"`
+}
+
+type subIssueDecomposeChild struct {
+	Title        string  `json:"title"`
+	Body         *string `json:"body"`
+	OwnerAgentID *string `json:"owner_agent_id"`
+	Priority     string  `json:"priority"`
+	WorkStatus   string  `json:"work_status"`
+	DueAt        *string `json:"due_at"`
+}
+
+// subIssueDecomposeRequest splits a parent issue into children. AgentID is
+// set when an agent decomposes on its own behalf and must be the project's
+// planner.
+type subIssueDecomposeRequest struct {
+	AgentID  *string                  `json:"agent_id"`
+	Children []subIssueDecomposeChild `json:"children"`
+}
+
+type subIssueDecomposeResponse struct {
+	ParentIssueID string                `json:"parent_issue_id"`
+	Items         []issueSummaryPayload `json:"items"`
+	Total         int                   `json:"total"`
+}
+
+// GetIssueTree returns an issue with all of its sub-issues and rollups.
+func (h *IssuesHandler) GetIssueTree(w http.ResponseWriter, r *http.Request) {
+	if h.IssueStore == nil {
+		sendJSON(w, http.StatusServiceUnavailable, errorResponse{Error: "database not available"})
+		return
+	}
+	tree, err := h.IssueStore.GetIssueTree(r.Context(), strings.TrimSpace(chi.URLParam(r, "id")))
+	if err != nil {
+		handleSubIssueStoreError(w, err)
+		return
+	}
+	sendJSON(w, http.StatusOK, toIssueTreeNodePayload(*tree))
+}
+
+// AttachSubIssue makes an existing issue a child of the issue in the URL.
+func (h *IssuesHandler) AttachSubIssue(w http.ResponseWriter, r *http.Request) {
+	if h.IssueStore == nil {
+		sendJSON(w, http.StatusServiceUnavailable, errorResponse{Error: "database not available"})
+		return
+	}
+	var req subIssueAttachRequest
+	decoder := json.NewDecoder(r.Body)
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&req); err != nil {
+		sendJSON(w, http.StatusBadRequest, errorResponse{Error: "invalid JSON"})
+		return
+	}
+	if strings.TrimSpace(req.ChildIssueID) == "" {
+		sendJSON(w, http.StatusBadRequest, errorResponse{Error: "child_issue_id is required"})
+		return
+	}
+
+	parentID := strings.TrimSpace(chi.URLParam(r, "id"))
+	child, err := h.IssueStore.AttachSubIssue(r.Context(), parentID, req.ChildIssueID)
+	if err != nil {
+		handleSubIssueStoreError(w, err)
+		return
+	}
+	recordAudit(r, auditEvent{Action: "issue.sub_issue.attach", TargetType: "issue", TargetID: child.ID, After: map[string]any{"parent_issue_id": parentID}})
+	sendJSON(w, http.StatusOK, toIssueSummaryPayload(*child, nil, nil))
+}
+
+// DetachSubIssue turns a child back into a top-level issue.
+func (h *IssuesHandler) DetachSubIssue(w http.ResponseWriter, r *http.Request) {
+	if h.IssueStore == nil {
+		sendJSON(w, http.StatusServiceUnavailable, errorResponse{Error: "database not available"})
+		return
+	}
+	parentID := strings.TrimSpace(chi.URLParam(r, "id"))
+	child, err := h.IssueStore.DetachSubIssue(r.Context(), parentID, chi.URLParam(r, "childID"))
+	if err != nil {
+		handleSubIssueStoreError(w, err)
+		return
+	}
+	recordAudit(r, auditEvent{Action: "issue.sub_issue.detach", TargetType: "issue", TargetID: child.ID, Before: map[string]any{"parent_issue_id": parentID}})
+	sendJSON(w, http.StatusOK, toIssueSummaryPayload(*child, nil, nil))
+}
+
+// DecomposeIssue creates several sub-issues under the issue in one call. All
+// children are created or none are.
+func (h *IssuesHandler) DecomposeIssue(w http.ResponseWriter, r *http.Request) {
+	if h.IssueStore == nil {
+		sendJSON(w, http.StatusServiceUnavailable, errorResponse{Error: "database not available"})
+		return
+	}
+	var req subIssueDecomposeRequest
+	decoder := json.NewDecoder(r.Body)
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&req); err != nil {
+		sendJSON(w, http.StatusBadRequest, errorResponse{Error: "invalid JSON"})
+		return
+	}
+	if len(req.Children) == 0 {
+		sendJSON(w, http.StatusBadRequest, errorResponse{Error: "children are required"})
+		return
+	}
+
+	parentID := strings.TrimSpace(chi.URLParam(r, "id"))
+	parent, err := h.IssueStore.GetIssueByID(r.Context(), parentID)
+	if err != nil {
+		handleSubIssueStoreError(w, err)
+		return
+	}
+	if req.AgentID != nil && strings.TrimSpace(*req.AgentID) != "" {
+		if !h.isProjectPlanner(w, r, parent.ProjectID, strings.TrimSpace(*req.AgentID)) {
+			return
+		}
+	}
+
+	defaultOwner := h.resolveDefaultIssueOwnerAgentID(r.Context(), parent.ProjectID)
+	inputs := make([]store.CreateProjectIssueInput, 0, len(req.Children))
+	for _, child := range req.Children {
+		dueAt, err := parseOptionalRFC3339(child.DueAt, "due_at")
+		if err != nil {
+			sendJSON(w, http.StatusBadRequest, errorResponse{Error: err.Error()})
+			return
+		}
+		ownerAgentID := child.OwnerAgentID
+		if ownerAgentID == nil {
+			ownerAgentID = defaultOwner
+		}
+		inputs = append(inputs, store.CreateProjectIssueInput{
+			Title:        child.Title,
+			Body:         child.Body,
+			Origin:       "local",
+			OwnerAgentID: ownerAgentID,
+			WorkStatus:   child.WorkStatus,
+			Priority:     child.Priority,
+			DueAt:        dueAt,
+		})
+	}
+
+	created, err := h.IssueStore.CreateSubIssues(r.Context(), parent.ID, inputs)
+	if err != nil {
+		handleSubIssueStoreError(w, err)
+		return
+	}
+
+	items := make([]issueSummaryPayload, 0, len(created))
+	for i := range created {
+		issue := &created[i]
+		if issue.OwnerAgentID != nil {
+			if _, err := h.IssueStore.AddParticipant(r.Context(), store.AddProjectIssueParticipantInput{
+				IssueID: issue.ID,
+				AgentID: *issue.OwnerAgentID,
+				Role:    "owner",
+			}); err != nil {
+				log.Printf("issues: failed to add owner to sub-issue %s: %v", issue.ID, err)
+			}
+		}
+		payload := toIssueSummaryPayload(*issue, nil, nil)
+		h.dispatchIssueKickoffBestEffort(r.Context(), *issue)
+		h.broadcastIssueCreated(r.Context(), payload)
+		items = append(items, payload)
+	}
+	recordAudit(r, auditEvent{Action: "issue.decompose", TargetType: "issue", TargetID: parent.ID, After: map[string]any{"children": len(items)}})
+	log.Printf("[sub-issues] decomposed issue %s into %d children org=%s", parent.ID, len(items), middleware.WorkspaceFromContext(r.Context()))
+	sendJSON(w, http.StatusCreated, subIssueDecomposeResponse{ParentIssueID: parent.ID, Items: items, Total: len(items)})
+}
+
+// GetSubIssuePolicy returns the project's sub-issue close rules.
+func (h *IssuesHandler) GetSubIssuePolicy(w http.ResponseWriter, r *http.Request) {
+	if h.IssueStore == nil {
+		sendJSON(w, http.StatusServiceUnavailable, errorResponse{Error: "database not available"})
+		return
+	}
+	policy, err := h.IssueStore.GetSubIssuePolicy(r.Context(), chi.URLParam(r, "id"))
+	if err != nil {
+		handleSubIssueStoreError(w, err)
+		return
+	}
+	sendJSON(w, http.StatusOK, policy)
+}
+
+// SetSubIssuePolicy replaces the project's sub-issue close rules.
+func (h *IssuesHandler) SetSubIssuePolicy(w http.ResponseWriter, r *http.Request) {
+	if h.IssueStore == nil {
+		sendJSON(w, http.StatusServiceUnavailable, errorResponse{Error: "database not available"})
+		return
+	}
+	var req store.SubIssuePolicy
+	decoder := json.NewDecoder(r.Body)
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&req); err != nil {
+		sendJSON(w, http.StatusBadRequest, errorResponse{Error: "invalid JSON"})
+		return
+	}
+	projectID := strings.TrimSpace(chi.URLParam(r, "id"))
+	policy, err := h.IssueStore.SetSubIssuePolicy(r.Context(), projectID, req)
+	if err != nil {
+		handleSubIssueStoreError(w, err)
+		return
+	}
+	recordAudit(r, auditEvent{Action: "project.sub_issue_policy.update", TargetType: "project", TargetID: projectID, After: policy})
+	sendJSON(w, http.StatusOK, policy)
+}
+
+// isProjectPlanner checks that agentID holds the planner pipeline role for
+// the project. It writes the error response itself.
+func (h *IssuesHandler) isProjectPlanner(w http.ResponseWriter, r *http.Request, projectID, agentID string) bool {
+	if h.PipelineRoleStore == nil {
+		sendJSON(w, http.StatusServiceUnavailable, errorResponse{Error: "database not available"})
+		return false
+	}
+	assignments, err := h.PipelineRoleStore.ListByProject(r.Context(), projectID)
+	if err != nil {
+		handleSubIssueStoreError(w, err)
+		return false
+	}
+	for _, assignment := range assignments {
+		if assignment.Role == store.PipelineRolePlanner && assignment.AgentID != nil && *assignment.AgentID == agentID {
+			return true
+		}
+	}
+	sendJSON(w, http.StatusForbidden, errorResponse{Error: "agent is not the project planner"})
+	return false
+}
+
+func handleSubIssueStoreError(w http.ResponseWriter, err error) {
+	if errors.Is(err, store.ErrValidation) {
+		sendJSON(w, http.StatusBadRequest, errorResponse{Error: err.Error()})
+		return
+	}
+	handleIssueStoreError(w, err)
+}
+
+func toIssueTreeNodePayload(node store.IssueTreeNode) issueTreeNodePayload {
+	payload := issueTreeNodePayload{
+		Issue:    toIssueSummaryPayload(node.Issue, nil, nil),
+		Rollup:   node.Rollup,
+		Children: make([]issueTreeNodePayload, 0, len(node.Children)),
+	}
+	for _, child := range node.Children {
+		payload.Children = append(payload.Children, toIssueTreeNodePayload(child))
+	}
+	return payload
+}
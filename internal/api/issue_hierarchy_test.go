@@ -0,0 +1,111 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/samhotchkiss/otter-camp/internal/middleware"
+	"github.com/samhotchkiss/otter-camp/internal/store"
+	"github.com/stretchr/testify/require"
+)
+
+func TestToIssueTreeNodePayload(t *testing.T) {
+	parentID := "parent"
+	payload := toIssueTreeNodePayload(store.BuildIssueTree(
+		store.ProjectIssue{ID: parentID, State: "open"},
+		[]store.ProjectIssue{{ID: "child", ParentIssueID: &parentID, State: "closed", WorkStatus: store.IssueWorkStatusDone}},
+	))
+	require.Equal(t, "parent", payload.Issue.ID)
+	require.Equal(t, 100, payload.Rollup.PercentComplete)
+	require.Len(t, payload.Children, 1)
+	require.Equal(t, "parent", *payload.Children[0].Issue.ParentIssueID)
+	require.NotNil(t, payload.Children[0].Children)
+}
+
+func TestSubIssueHandlersRequireStoreAndValidBody(t *testing.T) {
+	handler := &IssuesHandler{}
+	rec := httptest.NewRecorder()
+	handler.GetIssueTree(rec, httptest.NewRequest(http.MethodGet, "/api/issues/x/tree", nil))
+	require.Equal(t, http.StatusServiceUnavailable, rec.Code)
+
+	handler = &IssuesHandler{IssueStore: store.NewProjectIssueStore(nil)}
+	for _, tc := range []struct {
+		name    string
+		handler http.HandlerFunc
+		body    string
+		want    string
+	}{
+		{"attach unknown field", handler.AttachSubIssue, `{"child":"x"}`, "invalid JSON"},
+		{"attach missing child", handler.AttachSubIssue, `{}`, "child_issue_id is required"},
+		{"decompose empty", handler.DecomposeIssue, `{"children":[]}`, "children are required"},
+		{"policy unknown field", handler.SetSubIssuePolicy, `{"block":true}`, "invalid JSON"},
+	} {
+		rec := httptest.NewRecorder()
+		tc.handler(rec, httptest.NewRequest(http.MethodPost, "/", strings.NewReader(tc.body)))
+		require.Equal(t, http.StatusBadRequest, rec.Code, tc.name)
+		require.Contains(t, rec.Body.String(), tc.want, tc.name)
+	}
+}
+
+func TestIssuesHandlerDecomposeAndTree(t *testing.T) {
+	db := setupMessageTestDB(t)
+	orgID := insertMessageTestOrganization(t, db, "issues-api-sub-issue-org")
+	projectID := insertProjectTestProject(t, db, orgID, "Sub Issue API Project")
+	plannerID := insertMessageTestAgent(t, db, orgID, "sub-issue-planner")
+	workerID := insertMessageTestAgent(t, db, orgID, "sub-issue-worker")
+
+	issueStore := store.NewProjectIssueStore(db)
+	roleStore := store.NewPipelineRoleStore(db)
+	_, err := roleStore.Upsert(issueTestCtx(orgID), store.UpsertPipelineRoleAssignmentInput{
+		ProjectID: projectID,
+		Role:      store.PipelineRolePlanner,
+		AgentID:   &plannerID,
+	})
+	require.NoError(t, err)
+	parent, err := issueStore.CreateIssue(issueTestCtx(orgID), store.CreateProjectIssueInput{ProjectID: projectID, Title: "Launch", Origin: "local"})
+	require.NoError(t, err)
+
+	handler := &IssuesHandler{IssueStore: issueStore, PipelineRoleStore: roleStore, DB: db}
+	router := chi.NewRouter()
+	router.With(middleware.OptionalWorkspace).Post("/api/issues/{id}/decompose", handler.DecomposeIssue)
+	router.With(middleware.OptionalWorkspace).Get("/api/issues/{id}/tree", handler.GetIssueTree)
+	router.With(middleware.OptionalWorkspace).Patch("/api/issues/{id}", handler.PatchIssue)
+	router.With(middleware.OptionalWorkspace).Put("/api/projects/{id}/sub-issue-policy", handler.SetSubIssuePolicy)
+
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/api/issues/"+parent.ID+"/decompose?org_id="+orgID,
+		strings.NewReader(`{"agent_id":"`+workerID+`","children":[{"title":"Copy"}]}`)))
+	require.Equal(t, http.StatusForbidden, rec.Code)
+
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/api/issues/"+parent.ID+"/decompose?org_id="+orgID,
+		strings.NewReader(`{"agent_id":"`+plannerID+`","children":[{"title":"Copy","owner_agent_id":"`+workerID+`"},{"title":"Assets","work_status":"blocked","due_at":"2026-11-01T00:00:00Z"}]}`)))
+	require.Equal(t, http.StatusCreated, rec.Code, rec.Body.String())
+	var decomposed subIssueDecomposeResponse
+	require.NoError(t, json.NewDecoder(rec.Body).Decode(&decomposed))
+	require.Equal(t, 2, decomposed.Total)
+
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/issues/"+parent.ID+"/tree?org_id="+orgID, nil))
+	require.Equal(t, http.StatusOK, rec.Code)
+	var tree issueTreeNodePayload
+	require.NoError(t, json.NewDecoder(rec.Body).Decode(&tree))
+	require.Len(t, tree.Children, 2)
+	require.Equal(t, 1, tree.Rollup.Blocked)
+	require.NotNil(t, tree.Rollup.EarliestDueAt)
+
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodPut, "/api/projects/"+projectID+"/sub-issue-policy?org_id="+orgID,
+		strings.NewReader(`{"block_parent_close":true}`)))
+	require.Equal(t, http.StatusOK, rec.Code)
+
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodPatch, "/api/issues/"+parent.ID+"?org_id="+orgID,
+		strings.NewReader(`{"state":"closed"}`)))
+	require.Equal(t, http.StatusConflict, rec.Code)
+	require.Contains(t, rec.Body.String(), "open sub-issues")
+}
@@ -0,0 +1,96 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+	importer "github.com/samhotchkiss/otter-camp/internal/import"
+	"github.com/samhotchkiss/otter-camp/internal/middleware"
+	"github.com/samhotchkiss/otter-camp/internal/store"
+)
+
+const maxIssueImportBodyBytes = 32 << 20
+
+// issueImportRequest carries a Jira, Linear or generic CSV export inline.
+// Columns only applies to source "csv"; StatusMap and AssigneeMap override
+// the built-in status mapping and agent matching.
+type issueImportRequest struct {
+	Source        string            `json:"source"`
+	Format        string            `json:"format"`
+	Content       string            `json:"content"`
+	Columns       map[string]string `json:"columns"`
+	StatusMap     map[string]string `json:"status_map"`
+	AssigneeMap   map[string]string `json:"assignee_map"`
+	CommentAuthor string            `json:"comment_author"`
+	DryRun        bool              `json:"dry_run"`
+}
+
+// ImportIssues maps an export onto the project's issues. Re-running the same
+// export updates previously imported issues instead of duplicating them, and
+// dry_run reports what would change without writing anything.
+func (h *IssuesHandler) ImportIssues(w http.ResponseWriter, r *http.Request) {
+	if h.IssueImporter == nil {
+		sendJSON(w, http.StatusServiceUnavailable, errorResponse{Error: "database not available"})
+		return
+	}
+	projectID := strings.TrimSpace(chi.URLParam(r, "id"))
+	orgID := strings.TrimSpace(middleware.WorkspaceFromContext(r.Context()))
+	if orgID == "" {
+		sendJSON(w, http.StatusBadRequest, errorResponse{Error: "org_id is required"})
+		return
+	}
+
+	var req issueImportRequest
+	decoder := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxIssueImportBodyBytes))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&req); err != nil {
+		sendJSON(w, http.StatusBadRequest, errorResponse{Error: "invalid JSON"})
+		return
+	}
+	source := strings.TrimSpace(strings.ToLower(req.Source))
+	if !store.IsValidIssueImportSource(source) {
+		sendJSON(w, http.StatusBadRequest, errorResponse{Error: "source must be jira, linear or csv"})
+		return
+	}
+	if strings.TrimSpace(req.Content) == "" {
+		sendJSON(w, http.StatusBadRequest, errorResponse{Error: "content is required"})
+		return
+	}
+	issues, err := importer.ParseIssueExport(source, req.Format, []byte(req.Content), req.Columns)
+	if err != nil {
+		sendJSON(w, http.StatusBadRequest, errorResponse{Error: err.Error()})
+		return
+	}
+
+	result, err := h.IssueImporter.Run(r.Context(), importer.RunIssueImportInput{
+		OrgID:         orgID,
+		ProjectID:     projectID,
+		Source:        source,
+		Issues:        issues,
+		StatusMap:     req.StatusMap,
+		AssigneeMap:   req.AssigneeMap,
+		CommentAuthor: req.CommentAuthor,
+		DryRun:        req.DryRun,
+	})
+	if err != nil {
+		if errors.Is(err, store.ErrNotFound) {
+			sendJSON(w, http.StatusNotFound, errorResponse{Error: "project not found"})
+			return
+		}
+		handleJobsStoreError(w, err)
+		return
+	}
+
+	if !result.DryRun {
+		recordAudit(r, auditEvent{Action: "issue_import.run", TargetType: "project", TargetID: projectID, After: result.Summary})
+	}
+	log.Printf(
+		"[issue-import] source=%s project=%s dry_run=%t total=%d created=%d updated=%d failed=%d",
+		source, projectID, result.DryRun, result.Total, result.Created, result.Updated, result.Failed,
+	)
+	sendJSON(w, http.StatusOK, result)
+}
@@ -0,0 +1,92 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	importer "github.com/samhotchkiss/otter-camp/internal/import"
+	"github.com/samhotchkiss/otter-camp/internal/middleware"
+	"github.com/stretchr/testify/require"
+)
+
+func TestImportIssuesValidatesRequest(t *testing.T) {
+	router := chi.NewRouter()
+	handler := &IssuesHandler{}
+	router.With(middleware.OptionalWorkspace).Post("/api/projects/{id}/issue-imports", handler.ImportIssues)
+
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/api/projects/p/issue-imports?org_id=00000000-0000-0000-0000-000000000001", strings.NewReader(`{}`)))
+	require.Equal(t, http.StatusServiceUnavailable, rec.Code)
+
+	handler.IssueImporter = importer.NewIssueImportRunner(nil)
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/api/projects/p/issue-imports", strings.NewReader(`{}`)))
+	require.Equal(t, http.StatusBadRequest, rec.Code)
+	require.Contains(t, rec.Body.String(), "org_id is required")
+
+	for body, want := range map[string]string{
+		`{"source":"trello","content":"[]"}`:                                          "source must be jira, linear or csv",
+		`{"source":"jira"}`:                                                           "content is required",
+		`{"source":"jira","format":"xml","content":"<issues/>"}`:                      "format must be json or csv",
+		`{"source":"jira","format":"csv","content":"a,b\n1,2","columns":{"key":"a"}}`: "column mapping only applies to csv imports",
+		`{"source":"jira","content":"[]","mapping":{}}`:                               "invalid JSON",
+	} {
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/api/projects/p/issue-imports?org_id=00000000-0000-0000-0000-000000000001", strings.NewReader(body)))
+		require.Equal(t, http.StatusBadRequest, rec.Code, body)
+		require.Contains(t, rec.Body.String(), want, body)
+	}
+}
+
+func TestImportIssuesDryRunThenImport(t *testing.T) {
+	db := setupMessageTestDB(t)
+	orgID := insertMessageTestOrganization(t, db, "issue-imports-api-org")
+	projectID := insertProjectTestProject(t, db, orgID, "Issue Imports Project")
+	insertMessageTestAgent(t, db, orgID, "analyst-1")
+
+	handler := &IssuesHandler{IssueImporter: importer.NewIssueImportRunner(db)}
+	router := chi.NewRouter()
+	router.With(middleware.OptionalWorkspace).Post("/api/projects/{id}/issue-imports", handler.ImportIssues)
+
+	content := "ID,Title,Status,Priority,Assignee,Parent issue\n" +
+		"ENG-1,Ship search,In Progress,Urgent,analyst-1,\n" +
+		"ENG-2,Tune ranking,Parked,Low,Jane Doe,ENG-1\n"
+	post := func(dryRun bool) importer.IssueImportResult {
+		payload, err := json.Marshal(map[string]any{"source": "linear", "content": content, "dry_run": dryRun})
+		require.NoError(t, err)
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/api/projects/"+projectID+"/issue-imports?org_id="+orgID, strings.NewReader(string(payload))))
+		require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+		var result importer.IssueImportResult
+		require.NoError(t, json.NewDecoder(rec.Body).Decode(&result))
+		return result
+	}
+
+	dryRun := post(true)
+	require.True(t, dryRun.DryRun)
+	require.Equal(t, 2, dryRun.Created)
+	require.Equal(t, []string{"Parked"}, dryRun.Summary.UnmappedStatuses)
+	require.Equal(t, []string{"Jane Doe"}, dryRun.Summary.UnmappedAssignees)
+
+	imported := post(false)
+	require.Equal(t, 2, imported.Created)
+	require.Equal(t, 1, imported.ParentLinks)
+	require.Equal(t, "P0", imported.Items[0].Priority)
+
+	again := post(false)
+	require.Equal(t, 0, again.Created)
+	require.Equal(t, 2, again.Unchanged)
+
+	var count int
+	require.NoError(t, db.QueryRow(`SELECT COUNT(*) FROM project_issues WHERE project_id = $1`, projectID).Scan(&count))
+	require.Equal(t, 2, count)
+
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/api/projects/00000000-0000-0000-0000-000000000000/issue-imports?org_id="+orgID,
+		strings.NewReader(`{"source":"linear","content":"ID,Title\nENG-9,Missing project\n"}`)))
+	require.Equal(t, http.StatusNotFound, rec.Code, rec.Body.String())
+}
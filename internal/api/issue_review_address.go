@@ -100,6 +100,15 @@ func (h *IssuesHandler) AddressReview(w http.ResponseWriter, r *http.Request) {
 		handleIssueStoreError(w, err)
 		return
 	}
+	openThreads, err := h.IssueStore.CountOpenReviewThreads(r.Context(), issue.ID)
+	if err != nil {
+		handleIssueStoreError(w, err)
+		return
+	}
+	if openThreads > 0 {
+		sendJSON(w, http.StatusConflict, errorResponse{Error: issueReviewOpenThreadsError(openThreads).Error()})
+		return
+	}
 
 	commitType := browserCommitTypeWriting
 	authorName := req.AuthorName
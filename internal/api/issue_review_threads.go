@@ -0,0 +1,463 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/samhotchkiss/otter-camp/internal/store"
+)
+
+var issueReviewHunkHeaderPattern = regexp.MustCompile(`^@@ -(\d+)(?:,(\d+))? \+(\d+)(?:,(\d+))? @@`)
+
+type issueReviewThreadCreateRequest struct {
+	AuthorAgentID string `json:"author_agent_id"`
+	CommitSHA     string `json:"commit_sha,omitempty"`
+	StartLine     int    `json:"start_line"`
+	EndLine       int    `json:"end_line,omitempty"`
+	Body          string `json:"body"`
+}
+
+type issueReviewThreadCommentRequest struct {
+	AuthorAgentID string `json:"author_agent_id"`
+	Body          string `json:"body"`
+}
+
+type issueReviewThreadResolveRequest struct {
+	AgentID string  `json:"agent_id"`
+	Note    *string `json:"note,omitempty"`
+}
+
+type issueReviewThreadsResponse struct {
+	IssueID      string                           `json:"issue_id"`
+	DocumentPath string                           `json:"document_path"`
+	HeadSHA      string                           `json:"head_sha"`
+	Items        []store.ProjectIssueReviewThread `json:"items"`
+	Total        int                              `json:"total"`
+	Open         int                              `json:"open"`
+}
+
+// ListReviewThreads returns the issue's review threads with anchors moved to
+// the latest commit of the linked document.
+func (h *IssuesHandler) ListReviewThreads(w http.ResponseWriter, r *http.Request) {
+	if h.IssueStore == nil || h.CommitStore == nil || h.ProjectRepos == nil {
+		sendJSON(w, http.StatusServiceUnavailable, errorResponse{Error: "database not available"})
+		return
+	}
+	issueID := strings.TrimSpace(chi.URLParam(r, "id"))
+	if issueID == "" {
+		sendJSON(w, http.StatusBadRequest, errorResponse{Error: "issue id is required"})
+		return
+	}
+	var status *string
+	if raw := strings.TrimSpace(r.URL.Query().Get("status")); raw != "" {
+		status = &raw
+	}
+
+	issue, documentPath, touchingCommits, _, repoPath, repoRelativePath, err := h.loadIssueReviewContext(r, issueID)
+	if err != nil {
+		h.handleIssueReviewError(w, err)
+		return
+	}
+	threads, err := h.IssueStore.ListReviewThreads(r.Context(), issue.ID, status)
+	if err != nil {
+		handleSubIssueStoreError(w, err)
+		return
+	}
+	headSHA := ""
+	if len(touchingCommits) > 0 {
+		headSHA = strings.TrimSpace(touchingCommits[0].SHA)
+		h.remapIssueReviewThreads(r.Context(), threads, repoPath, repoRelativePath, headSHA)
+	}
+
+	open := 0
+	for _, thread := range threads {
+		if thread.Status == store.IssueReviewThreadStatusOpen {
+			open++
+		}
+	}
+	sendJSON(w, http.StatusOK, issueReviewThreadsResponse{
+		IssueID:      issue.ID,
+		DocumentPath: documentPath,
+		HeadSHA:      headSHA,
+		Items:        threads,
+		Total:        len(threads),
+		Open:         open,
+	})
+}
+
+// CreateReviewThread anchors a new thread to a line range of the linked
+// document at commit_sha, defaulting to the latest commit that touched it.
+func (h *IssuesHandler) CreateReviewThread(w http.ResponseWriter, r *http.Request) {
+	if h.IssueStore == nil || h.CommitStore == nil || h.ProjectRepos == nil {
+		sendJSON(w, http.StatusServiceUnavailable, errorResponse{Error: "database not available"})
+		return
+	}
+	issueID := strings.TrimSpace(chi.URLParam(r, "id"))
+	if issueID == "" {
+		sendJSON(w, http.StatusBadRequest, errorResponse{Error: "issue id is required"})
+		return
+	}
+
+	var req issueReviewThreadCreateRequest
+	decoder := json.NewDecoder(r.Body)
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&req); err != nil {
+		sendJSON(w, http.StatusBadRequest, errorResponse{Error: "invalid JSON"})
+		return
+	}
+	authorAgentID := strings.TrimSpace(req.AuthorAgentID)
+	if !uuidRegex.MatchString(authorAgentID) {
+		sendJSON(w, http.StatusBadRequest, errorResponse{Error: "author_agent_id is required"})
+		return
+	}
+	if strings.TrimSpace(req.Body) == "" {
+		sendJSON(w, http.StatusBadRequest, errorResponse{Error: "body is required"})
+		return
+	}
+	if req.EndLine == 0 {
+		req.EndLine = req.StartLine
+	}
+	if req.StartLine <= 0 || req.EndLine < req.StartLine {
+		sendJSON(w, http.StatusBadRequest, errorResponse{Error: "start_line and end_line must form a valid range"})
+		return
+	}
+
+	issue, documentPath, touchingCommits, _, repoPath, repoRelativePath, err := h.loadIssueReviewContext(r, issueID)
+	if err != nil {
+		h.handleIssueReviewError(w, err)
+		return
+	}
+	if !h.requireActiveReviewParticipant(w, r, issue.ID, authorAgentID) {
+		return
+	}
+	if len(touchingCommits) == 0 {
+		sendJSON(w, http.StatusNotFound, errorResponse{Error: "no commits found for linked document"})
+		return
+	}
+
+	commitSHA := strings.TrimSpace(req.CommitSHA)
+	if commitSHA == "" {
+		commitSHA = strings.TrimSpace(touchingCommits[0].SHA)
+	}
+	found := false
+	for _, commit := range touchingCommits {
+		if strings.TrimSpace(commit.SHA) == commitSHA {
+			found = true
+			break
+		}
+	}
+	if !found {
+		sendJSON(w, http.StatusNotFound, errorResponse{Error: "commit is not in linked document history"})
+		return
+	}
+	content, err := runGitInRepo(r.Context(), repoPath, "show", commitSHA+":"+filepath.ToSlash(repoRelativePath))
+	if err != nil {
+		sendJSON(w, http.StatusNotFound, errorResponse{Error: "document version not found in commit"})
+		return
+	}
+	if lineCount := strings.Count(content, "\n") + 1; req.EndLine > lineCount {
+		sendJSON(w, http.StatusBadRequest, errorResponse{Error: fmt.Sprintf("end_line exceeds document length (%d lines)", lineCount)})
+		return
+	}
+
+	thread, err := h.IssueStore.CreateReviewThread(r.Context(), store.CreateProjectIssueReviewThreadInput{
+		IssueID:       issue.ID,
+		DocumentPath:  documentPath,
+		CommitSHA:     commitSHA,
+		StartLine:     req.StartLine,
+		EndLine:       req.EndLine,
+		AuthorAgentID: authorAgentID,
+		Body:          req.Body,
+	})
+	if err != nil {
+		handleSubIssueStoreError(w, err)
+		return
+	}
+	recordAudit(r, auditEvent{
+		Action:     "issue.review_thread_create",
+		TargetType: "issue",
+		TargetID:   issue.ID,
+		After: map[string]any{
+			"thread_id":  thread.ID,
+			"commit_sha": thread.CommitSHA,
+			"start_line": thread.StartLine,
+			"end_line":   thread.EndLine,
+		},
+	})
+	sendJSON(w, http.StatusCreated, thread)
+}
+
+// ReplyReviewThread adds a comment to a thread, typically an author reporting
+// back on the fix for that point.
+func (h *IssuesHandler) ReplyReviewThread(w http.ResponseWriter, r *http.Request) {
+	if h.IssueStore == nil {
+		sendJSON(w, http.StatusServiceUnavailable, errorResponse{Error: "database not available"})
+		return
+	}
+	var req issueReviewThreadCommentRequest
+	decoder := json.NewDecoder(r.Body)
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&req); err != nil {
+		sendJSON(w, http.StatusBadRequest, errorResponse{Error: "invalid JSON"})
+		return
+	}
+	authorAgentID := strings.TrimSpace(req.AuthorAgentID)
+	if !uuidRegex.MatchString(authorAgentID) {
+		sendJSON(w, http.StatusBadRequest, errorResponse{Error: "author_agent_id is required"})
+		return
+	}
+	if strings.TrimSpace(req.Body) == "" {
+		sendJSON(w, http.StatusBadRequest, errorResponse{Error: "body is required"})
+		return
+	}
+
+	issueID := strings.TrimSpace(chi.URLParam(r, "id"))
+	if !h.requireActiveReviewParticipant(w, r, issueID, authorAgentID) {
+		return
+	}
+	if _, err := h.IssueStore.AddReviewThreadComment(r.Context(), issueID, chi.URLParam(r, "threadID"), authorAgentID, req.Body); err != nil {
+		handleSubIssueStoreError(w, err)
+		return
+	}
+	thread, err := h.IssueStore.GetReviewThread(r.Context(), issueID, chi.URLParam(r, "threadID"))
+	if err != nil {
+		handleSubIssueStoreError(w, err)
+		return
+	}
+	sendJSON(w, http.StatusCreated, thread)
+}
+
+func (h *IssuesHandler) ResolveReviewThread(w http.ResponseWriter, r *http.Request) {
+	h.setReviewThreadResolved(w, r, true)
+}
+
+func (h *IssuesHandler) ReopenReviewThread(w http.ResponseWriter, r *http.Request) {
+	h.setReviewThreadResolved(w, r, false)
+}
+
+func (h *IssuesHandler) setReviewThreadResolved(w http.ResponseWriter, r *http.Request, resolved bool) {
+	if h.IssueStore == nil {
+		sendJSON(w, http.StatusServiceUnavailable, errorResponse{Error: "database not available"})
+		return
+	}
+	var req issueReviewThreadResolveRequest
+	decoder := json.NewDecoder(r.Body)
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&req); err != nil {
+		sendJSON(w, http.StatusBadRequest, errorResponse{Error: "invalid JSON"})
+		return
+	}
+	agentID := strings.TrimSpace(req.AgentID)
+	if !uuidRegex.MatchString(agentID) {
+		sendJSON(w, http.StatusBadRequest, errorResponse{Error: "agent_id is required"})
+		return
+	}
+
+	issueID := strings.TrimSpace(chi.URLParam(r, "id"))
+	if !h.requireActiveReviewParticipant(w, r, issueID, agentID) {
+		return
+	}
+	thread, err := h.IssueStore.SetReviewThreadResolved(r.Context(), issueID, chi.URLParam(r, "threadID"), resolved, agentID, req.Note)
+	if err != nil {
+		handleSubIssueStoreError(w, err)
+		return
+	}
+	action := "issue.review_thread_resolve"
+	if !resolved {
+		action = "issue.review_thread_reopen"
+	}
+	recordAudit(r, auditEvent{
+		Action:     action,
+		TargetType: "issue",
+		TargetID:   thread.IssueID,
+		After: map[string]any{
+			"thread_id": thread.ID,
+			"status":    thread.Status,
+			"agent_id":  agentID,
+		},
+	})
+	sendJSON(w, http.StatusOK, thread)
+}
+
+func (h *IssuesHandler) requireActiveReviewParticipant(w http.ResponseWriter, r *http.Request, issueID, agentID string) bool {
+	participants, err := h.IssueStore.ListParticipants(r.Context(), issueID, false)
+	if err != nil {
+		handleIssueStoreError(w, err)
+		return false
+	}
+	if !isActiveIssueParticipant(agentID, participants) {
+		sendJSON(w, http.StatusForbidden, errorResponse{Error: "agent must be an active issue participant"})
+		return false
+	}
+	return true
+}
+
+// remapIssueReviewThreads moves each thread's anchor from its last known
+// commit to headSHA by walking the document diff between them, and saves the
+// new position. Failures leave the thread at its previous anchor.
+func (h *IssuesHandler) remapIssueReviewThreads(
+	ctx context.Context,
+	threads []store.ProjectIssueReviewThread,
+	repoPath string,
+	repoRelativePath string,
+	headSHA string,
+) {
+	patches := make(map[string]string)
+	for i := range threads {
+		thread := &threads[i]
+		if thread.Outdated || strings.TrimSpace(thread.AnchoredCommitSHA) == headSHA {
+			continue
+		}
+		patch, ok := patches[thread.AnchoredCommitSHA]
+		if !ok {
+			files, err := buildIssueReviewDiffFiles(ctx, repoPath, thread.AnchoredCommitSHA, headSHA, repoRelativePath)
+			if err != nil {
+				log.Printf("[issue-review-threads] diff %s..%s failed: %v", thread.AnchoredCommitSHA, headSHA, err)
+				continue
+			}
+			if len(files) > 0 && files[0].Patch != nil {
+				patch = *files[0].Patch
+			}
+			patches[thread.AnchoredCommitSHA] = patch
+		}
+
+		startLine, endLine, ok := remapIssueReviewLineRange(patch, thread.AnchoredStartLine, thread.AnchoredEndLine)
+		if !ok {
+			startLine, endLine = thread.AnchoredStartLine, thread.AnchoredEndLine
+		}
+		if err := h.IssueStore.UpdateReviewThreadAnchor(ctx, thread.ID, headSHA, startLine, endLine, !ok); err != nil {
+			log.Printf("[issue-review-threads] failed to save anchor for thread %s: %v", thread.ID, err)
+			continue
+		}
+		thread.AnchoredCommitSHA = headSHA
+		thread.AnchoredStartLine = startLine
+		thread.AnchoredEndLine = endLine
+		thread.Outdated = !ok
+	}
+}
+
+type issueReviewDiffHunk struct {
+	oldStart int
+	oldCount int
+	newStart int
+	newCount int
+	lines    []string
+}
+
+// remapIssueReviewLineRange maps an old-side line range through a unified
+// diff. The result spans the surviving lines of the range; ok is false when
+// every line in it was deleted.
+func remapIssueReviewLineRange(patch string, startLine, endLine int) (int, int, bool) {
+	hunks := parseIssueReviewDiffHunks(patch)
+	newStart, newEnd := 0, 0
+	for line := startLine; line <= endLine; line++ {
+		mapped, ok := mapIssueReviewLine(hunks, line)
+		if !ok {
+			continue
+		}
+		if newStart == 0 || mapped < newStart {
+			newStart = mapped
+		}
+		if mapped > newEnd {
+			newEnd = mapped
+		}
+	}
+	if newStart == 0 {
+		return 0, 0, false
+	}
+	return newStart, newEnd, true
+}
+
+func mapIssueReviewLine(hunks []issueReviewDiffHunk, line int) (int, bool) {
+	offset := 0
+	for _, hunk := range hunks {
+		// A zero count means the hunk sits after the given line rather than on it.
+		oldFirst, newFirst := hunk.oldStart, hunk.newStart
+		if hunk.oldCount == 0 {
+			oldFirst++
+		}
+		if hunk.newCount == 0 {
+			newFirst++
+		}
+		if line < oldFirst {
+			return line + offset, true
+		}
+		if line < oldFirst+hunk.oldCount {
+			oldLine, newLine := oldFirst, newFirst
+			for _, diffLine := range hunk.lines {
+				switch {
+				case strings.HasPrefix(diffLine, "+"):
+					newLine++
+				case strings.HasPrefix(diffLine, "-"):
+					if oldLine == line {
+						return 0, false
+					}
+					oldLine++
+				case strings.HasPrefix(diffLine, "\\"):
+				default:
+					if oldLine == line {
+						return newLine, true
+					}
+					oldLine++
+					newLine++
+				}
+			}
+			return 0, false
+		}
+		offset += hunk.newCount - hunk.oldCount
+	}
+	return line + offset, true
+}
+
+func parseIssueReviewDiffHunks(patch string) []issueReviewDiffHunk {
+	hunks := make([]issueReviewDiffHunk, 0)
+	var current *issueReviewDiffHunk
+	for _, line := range strings.Split(patch, "\n") {
+		if match := issueReviewHunkHeaderPattern.FindStringSubmatch(line); match != nil {
+			hunks = append(hunks, issueReviewDiffHunk{
+				oldStart: issueReviewHunkNumber(match[1], 0),
+				oldCount: issueReviewHunkNumber(match[2], 1),
+				newStart: issueReviewHunkNumber(match[3], 0),
+				newCount: issueReviewHunkNumber(match[4], 1),
+			})
+			current = &hunks[len(hunks)-1]
+			continue
+		}
+		if current == nil {
+			continue
+		}
+		if strings.HasPrefix(line, "diff --git ") {
+			current = nil
+			continue
+		}
+		current.lines = append(current.lines, line)
+	}
+	return hunks
+}
+
+func issueReviewHunkNumber(raw string, fallback int) int {
+	if raw == "" {
+		return fallback
+	}
+	value, err := strconv.Atoi(raw)
+	if err != nil {
+		return fallback
+	}
+	return value
+}
+
+func issueReviewOpenThreadsError(count int) error {
+	if count == 1 {
+		return errors.New("1 review thread is unresolved")
+	}
+	return fmt.Errorf("%d review threads are unresolved", count)
+}
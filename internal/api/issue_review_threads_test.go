@@ -0,0 +1,191 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/samhotchkiss/otter-camp/internal/middleware"
+	"github.com/samhotchkiss/otter-camp/internal/store"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRemapIssueReviewLineRange(t *testing.T) {
+	patch := strings.Join([]string{
+		"diff --git a/doc.md b/doc.md",
+		"--- a/doc.md",
+		"+++ b/doc.md",
+		"@@ -1,3 +1,4 @@",
+		"+# Title",
+		" one",
+		"-two",
+		"+TWO",
+		" three",
+		"@@ -8,2 +9,0 @@",
+		"-eight",
+		"-nine",
+		"@@ -12,0 +12,2 @@",
+		"+new a",
+		"+new b",
+	}, "\n")
+
+	cases := []struct {
+		name        string
+		start, end  int
+		wantStart   int
+		wantEnd     int
+		wantAnchors bool
+	}{
+		{"context line shifts with insert", 1, 1, 2, 2, true},
+		{"deleted line alone is outdated", 2, 2, 0, 0, false},
+		{"range drops deleted line", 1, 3, 2, 4, true},
+		{"between hunks carries offset", 5, 6, 6, 7, true},
+		{"fully deleted hunk", 8, 9, 0, 0, false},
+		{"after deletion", 10, 10, 9, 9, true},
+		{"at insertion point", 12, 12, 11, 11, true},
+		{"after insertion", 13, 14, 14, 15, true},
+	}
+	for _, tc := range cases {
+		start, end, ok := remapIssueReviewLineRange(patch, tc.start, tc.end)
+		require.Equal(t, tc.wantAnchors, ok, tc.name)
+		require.Equal(t, tc.wantStart, start, tc.name)
+		require.Equal(t, tc.wantEnd, end, tc.name)
+	}
+
+	start, end, ok := remapIssueReviewLineRange("", 4, 6)
+	require.True(t, ok)
+	require.Equal(t, 4, start)
+	require.Equal(t, 6, end)
+}
+
+func TestReviewThreadHandlersValidateRequests(t *testing.T) {
+	handler := &IssuesHandler{}
+	rec := httptest.NewRecorder()
+	handler.CreateReviewThread(rec, httptest.NewRequest(http.MethodPost, "/", strings.NewReader(`{}`)))
+	require.Equal(t, http.StatusServiceUnavailable, rec.Code)
+
+	handler = &IssuesHandler{IssueStore: store.NewProjectIssueStore(nil)}
+	for body, want := range map[string]string{
+		`{"agent":"x"}`: "invalid JSON",
+		`{}`:            "agent_id is required",
+	} {
+		rec := httptest.NewRecorder()
+		handler.ResolveReviewThread(rec, httptest.NewRequest(http.MethodPost, "/", strings.NewReader(body)))
+		require.Equal(t, http.StatusBadRequest, rec.Code, body)
+		require.Contains(t, rec.Body.String(), want, body)
+	}
+	rec = httptest.NewRecorder()
+	handler.ReplyReviewThread(rec, httptest.NewRequest(http.MethodPost, "/", strings.NewReader(`{"author_agent_id":"00000000-0000-0000-0000-000000000001","body":" "}`)))
+	require.Equal(t, http.StatusBadRequest, rec.Code)
+	require.Contains(t, rec.Body.String(), "body is required")
+}
+
+func TestIssuesHandlerReviewThreadsRemapAndGateAddress(t *testing.T) {
+	db := setupMessageTestDB(t)
+	orgID := insertMessageTestOrganization(t, db, "issues-review-threads-org")
+	projectID := insertProjectTestProject(t, db, orgID, "Issue Review Threads")
+	ownerID := insertMessageTestAgent(t, db, orgID, "issue-review-threads-owner")
+	reviewerID := insertMessageTestAgent(t, db, orgID, "issue-review-threads-reviewer")
+	fixture := newPublishRepoFixture(t)
+	seedProjectCommitRepoBinding(t, db, orgID, projectID, fixture.LocalPath)
+
+	documentPath := "/posts/2026-10-18-threads.md"
+	issueStore := store.NewProjectIssueStore(db)
+	issue, err := issueStore.CreateIssue(testCtxWithWorkspace(orgID), store.CreateProjectIssueInput{
+		ProjectID:    projectID,
+		Title:        "Threaded review",
+		Origin:       "local",
+		DocumentPath: issueReviewStringPtr(documentPath),
+	})
+	require.NoError(t, err)
+	for agentID, role := range map[string]string{ownerID: "owner", reviewerID: "collaborator"} {
+		_, err = issueStore.AddParticipant(testCtxWithWorkspace(orgID), store.AddProjectIssueParticipantInput{IssueID: issue.ID, AgentID: agentID, Role: role})
+		require.NoError(t, err)
+	}
+
+	commitRouter := newProjectCommitsTestRouter(&ProjectCommitsHandler{
+		ProjectStore: store.NewProjectStore(db),
+		CommitStore:  store.NewProjectCommitStore(db),
+		ProjectRepos: store.NewProjectRepoStore(db),
+	})
+	firstSHA := createIssueReviewCommit(t, commitRouter, projectID, orgID, map[string]any{
+		"path":           documentPath,
+		"content":        "# Draft\n\nintro\nweak claim\nconclusion",
+		"commit_subject": "First threaded draft",
+		"commit_body":    "Adds the first draft of the post so reviewers can leave inline notes.",
+	})
+
+	handler := &IssuesHandler{
+		IssueStore:   issueStore,
+		ProjectStore: store.NewProjectStore(db),
+		CommitStore:  store.NewProjectCommitStore(db),
+		ProjectRepos: store.NewProjectRepoStore(db),
+	}
+	router := chi.NewRouter()
+	router.With(middleware.OptionalWorkspace).Get("/api/issues/{id}/review/threads", handler.ListReviewThreads)
+	router.With(middleware.OptionalWorkspace).Post("/api/issues/{id}/review/threads", handler.CreateReviewThread)
+	router.With(middleware.OptionalWorkspace).Post("/api/issues/{id}/review/threads/{threadID}/comments", handler.ReplyReviewThread)
+	router.With(middleware.OptionalWorkspace).Post("/api/issues/{id}/review/threads/{threadID}/resolve", handler.ResolveReviewThread)
+	router.With(middleware.OptionalWorkspace).Post("/api/issues/{id}/review/address", handler.AddressReview)
+	call := func(method, path, body string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, httptest.NewRequest(method, "/api/issues/"+issue.ID+path+"?org_id="+orgID, strings.NewReader(body)))
+		return rec
+	}
+
+	rec := call(http.MethodPost, "/review/threads", `{"author_agent_id":"`+reviewerID+`","start_line":4,"body":"Needs a source"}`)
+	require.Equal(t, http.StatusCreated, rec.Code, rec.Body.String())
+	var claimThread store.ProjectIssueReviewThread
+	require.NoError(t, json.NewDecoder(rec.Body).Decode(&claimThread))
+	require.Equal(t, firstSHA, claimThread.CommitSHA)
+	require.Len(t, claimThread.Comments, 1)
+
+	rec = call(http.MethodPost, "/review/threads", `{"author_agent_id":"`+reviewerID+`","start_line":3,"end_line":3,"body":"Cut this"}`)
+	require.Equal(t, http.StatusCreated, rec.Code, rec.Body.String())
+	var introThread store.ProjectIssueReviewThread
+	require.NoError(t, json.NewDecoder(rec.Body).Decode(&introThread))
+
+	rec = call(http.MethodPost, "/review/threads", `{"author_agent_id":"`+reviewerID+`","start_line":4,"end_line":40,"body":"Too far"}`)
+	require.Equal(t, http.StatusBadRequest, rec.Code)
+
+	secondSHA := createIssueReviewCommit(t, commitRouter, projectID, orgID, map[string]any{
+		"path":           documentPath,
+		"content":        "# Draft\n\nsummary line\nsecond summary\nweak claim\nconclusion",
+		"commit_subject": "Rework the intro",
+		"commit_body":    "Replaces the intro with a two line summary ahead of the main claim.",
+	})
+	_, err = issueStore.UpsertReviewVersion(testCtxWithWorkspace(orgID), issue.ID, documentPath, firstSHA, &reviewerID)
+	require.NoError(t, err)
+
+	rec = call(http.MethodGet, "/review/threads", "")
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	var listed issueReviewThreadsResponse
+	require.NoError(t, json.NewDecoder(rec.Body).Decode(&listed))
+	require.Equal(t, secondSHA, listed.HeadSHA)
+	require.Equal(t, 2, listed.Open)
+	byID := map[string]store.ProjectIssueReviewThread{}
+	for _, thread := range listed.Items {
+		byID[thread.ID] = thread
+	}
+	require.Equal(t, 5, byID[claimThread.ID].AnchoredStartLine)
+	require.False(t, byID[claimThread.ID].Outdated)
+	require.True(t, byID[introThread.ID].Outdated)
+
+	addressBody := `{"author_agent_id":"` + ownerID + `","content":"# Draft\n\nsummary line\nsecond summary\nsourced claim\nconclusion"}`
+	rec = call(http.MethodPost, "/review/address", addressBody)
+	require.Equal(t, http.StatusConflict, rec.Code)
+	require.Contains(t, rec.Body.String(), "2 review threads are unresolved")
+
+	rec = call(http.MethodPost, "/review/threads/"+claimThread.ID+"/comments", `{"author_agent_id":"`+ownerID+`","body":"Added a citation"}`)
+	require.Equal(t, http.StatusCreated, rec.Code, rec.Body.String())
+	for _, threadID := range []string{claimThread.ID, introThread.ID} {
+		rec = call(http.MethodPost, "/review/threads/"+threadID+"/resolve", `{"agent_id":"`+ownerID+`","note":"done"}`)
+		require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	}
+
+	rec = call(http.MethodPost, "/review/address", addressBody)
+	require.Equal(t, http.StatusCreated, rec.Code, rec.Body.String())
+}
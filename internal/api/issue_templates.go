@@ -0,0 +1,288 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/samhotchkiss/otter-camp/internal/middleware"
+	"github.com/samhotchkiss/otter-camp/internal/store"
+)
+
+type issueTemplateListResponse struct {
+	Items []store.IssueTemplate `json:"items"`
+	Total int                   `json:"total"`
+}
+
+type issueTemplateSaveRequest struct {
+	Name                  string                     `json:"name"`
+	Description           *string                    `json:"description"`
+	Body                  string                     `json:"body"`
+	Fields                []store.IssueTemplateField `json:"fields"`
+	DefaultFlowTemplateID *string                    `json:"default_flow_template_id"`
+	DefaultLabels         []string                   `json:"default_labels"`
+}
+
+// issueTemplateSelection is a template chosen at issue creation together with
+// the checked field values to store on the new issue.
+type issueTemplateSelection struct {
+	Template *store.IssueTemplate
+	Values   []store.IssueFieldValue
+}
+
+func (h *IssuesHandler) ListTemplates(w http.ResponseWriter, r *http.Request) {
+	if h.TemplateStore == nil {
+		sendJSON(w, http.StatusServiceUnavailable, errorResponse{Error: "database not available"})
+		return
+	}
+	orgID := middleware.WorkspaceFromContext(r.Context())
+	if orgID == "" {
+		sendJSON(w, http.StatusUnauthorized, errorResponse{Error: "authentication required"})
+		return
+	}
+	templates, err := h.TemplateStore.List(r.Context(), orgID, strings.TrimSpace(chi.URLParam(r, "id")))
+	if err != nil {
+		handleJobsStoreError(w, err)
+		return
+	}
+	sendJSON(w, http.StatusOK, issueTemplateListResponse{Items: templates, Total: len(templates)})
+}
+
+// GetTemplate returns one template by id or name.
+func (h *IssuesHandler) GetTemplate(w http.ResponseWriter, r *http.Request) {
+	if h.TemplateStore == nil {
+		sendJSON(w, http.StatusServiceUnavailable, errorResponse{Error: "database not available"})
+		return
+	}
+	orgID := middleware.WorkspaceFromContext(r.Context())
+	if orgID == "" {
+		sendJSON(w, http.StatusUnauthorized, errorResponse{Error: "authentication required"})
+		return
+	}
+	template, err := h.TemplateStore.Resolve(
+		r.Context(),
+		orgID,
+		strings.TrimSpace(chi.URLParam(r, "id")),
+		chi.URLParam(r, "templateID"),
+	)
+	if err != nil {
+		handleIssueTemplateError(w, err)
+		return
+	}
+	sendJSON(w, http.StatusOK, template)
+}
+
+// SaveTemplate creates or replaces a template by name within the project.
+func (h *IssuesHandler) SaveTemplate(w http.ResponseWriter, r *http.Request) {
+	if h.TemplateStore == nil {
+		sendJSON(w, http.StatusServiceUnavailable, errorResponse{Error: "database not available"})
+		return
+	}
+	orgID := middleware.WorkspaceFromContext(r.Context())
+	if orgID == "" {
+		sendJSON(w, http.StatusUnauthorized, errorResponse{Error: "authentication required"})
+		return
+	}
+
+	var req issueTemplateSaveRequest
+	decoder := json.NewDecoder(r.Body)
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&req); err != nil {
+		sendJSON(w, http.StatusBadRequest, errorResponse{Error: "invalid JSON"})
+		return
+	}
+
+	template, err := h.TemplateStore.Save(r.Context(), orgID, store.SaveIssueTemplateInput{
+		ProjectID:             strings.TrimSpace(chi.URLParam(r, "id")),
+		Name:                  req.Name,
+		Description:           req.Description,
+		Body:                  req.Body,
+		Fields:                req.Fields,
+		DefaultFlowTemplateID: req.DefaultFlowTemplateID,
+		DefaultLabels:         req.DefaultLabels,
+	})
+	if err != nil {
+		if errors.Is(err, store.ErrNotFound) {
+			sendJSON(w, http.StatusNotFound, errorResponse{Error: "project not found"})
+			return
+		}
+		handleJobsStoreError(w, err)
+		return
+	}
+	recordAudit(r, auditEvent{Action: "issue_template.save", TargetType: "issue_template", TargetID: template.ID, After: template})
+	log.Printf("[issue-templates] saved template %q (%s) org=%s", template.Name, template.ID, orgID)
+	sendJSON(w, http.StatusOK, template)
+}
+
+func (h *IssuesHandler) DeleteTemplate(w http.ResponseWriter, r *http.Request) {
+	if h.TemplateStore == nil {
+		sendJSON(w, http.StatusServiceUnavailable, errorResponse{Error: "database not available"})
+		return
+	}
+	orgID := middleware.WorkspaceFromContext(r.Context())
+	if orgID == "" {
+		sendJSON(w, http.StatusUnauthorized, errorResponse{Error: "authentication required"})
+		return
+	}
+	template, err := h.TemplateStore.Resolve(
+		r.Context(),
+		orgID,
+		strings.TrimSpace(chi.URLParam(r, "id")),
+		chi.URLParam(r, "templateID"),
+	)
+	if err != nil {
+		handleIssueTemplateError(w, err)
+		return
+	}
+	if err := h.TemplateStore.Delete(r.Context(), orgID, template.ID); err != nil {
+		handleIssueTemplateError(w, err)
+		return
+	}
+	recordAudit(r, auditEvent{Action: "issue_template.delete", TargetType: "issue_template", TargetID: template.ID, Before: template})
+	sendJSON(w, http.StatusOK, map[string]any{"deleted": true, "id": template.ID})
+}
+
+func handleIssueTemplateError(w http.ResponseWriter, err error) {
+	if errors.Is(err, store.ErrNotFound) {
+		sendJSON(w, http.StatusNotFound, errorResponse{Error: "issue template not found"})
+		return
+	}
+	handleJobsStoreError(w, err)
+}
+
+// resolveIssueTemplateSelection loads the requested template and checks the
+// submitted field values against it. It writes the error response itself and
+// returns ok=false when creation should stop.
+func (h *IssuesHandler) resolveIssueTemplateSelection(
+	w http.ResponseWriter,
+	r *http.Request,
+	projectID string,
+	templateRef *string,
+	fields map[string]any,
+) (*issueTemplateSelection, bool) {
+	ref := ""
+	if templateRef != nil {
+		ref = strings.TrimSpace(*templateRef)
+	}
+	if ref == "" {
+		if len(fields) > 0 {
+			sendJSON(w, http.StatusBadRequest, errorResponse{Error: "fields require template_id"})
+			return nil, false
+		}
+		return nil, true
+	}
+	if h.TemplateStore == nil {
+		sendJSON(w, http.StatusServiceUnavailable, errorResponse{Error: "database not available"})
+		return nil, false
+	}
+	orgID := middleware.WorkspaceFromContext(r.Context())
+	if orgID == "" {
+		sendJSON(w, http.StatusUnauthorized, errorResponse{Error: "authentication required"})
+		return nil, false
+	}
+
+	template, err := h.TemplateStore.Resolve(r.Context(), orgID, projectID, ref)
+	if err != nil {
+		handleIssueTemplateError(w, err)
+		return nil, false
+	}
+	provided, err := issueTemplateFieldInput(fields)
+	if err != nil {
+		sendJSON(w, http.StatusBadRequest, errorResponse{Error: err.Error()})
+		return nil, false
+	}
+	values, err := template.ResolveFieldValues(provided)
+	if err != nil {
+		handleJobsStoreError(w, err)
+		return nil, false
+	}
+	return &issueTemplateSelection{Template: template, Values: values}, true
+}
+
+// issueTemplateFieldInput accepts JSON strings, numbers and booleans so
+// clients can send typed values for number fields.
+func issueTemplateFieldInput(fields map[string]any) (map[string]string, error) {
+	provided := make(map[string]string, len(fields))
+	keys := make([]string, 0, len(fields))
+	for key := range fields {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		switch value := fields[key].(type) {
+		case nil:
+		case string:
+			provided[key] = value
+		case float64:
+			provided[key] = strconv.FormatFloat(value, 'f', -1, 64)
+		case bool:
+			provided[key] = strconv.FormatBool(value)
+		default:
+			return nil, fmt.Errorf("field %s must be a string or number", key)
+		}
+	}
+	return provided, nil
+}
+
+// body returns the request body, or the template body rendered with the
+// field values when the request left it empty.
+func (s *issueTemplateSelection) body(requested *string) *string {
+	if requested != nil && strings.TrimSpace(*requested) != "" {
+		return requested
+	}
+	if s == nil || strings.TrimSpace(s.Template.Body) == "" {
+		return requested
+	}
+	rendered := s.Template.RenderBody(s.Values)
+	return &rendered
+}
+
+// flowTemplateID prefers an explicit flow and falls back to the template's
+// default flow.
+func (s *issueTemplateSelection) flowTemplateID(requested *string) *string {
+	if requested != nil && strings.TrimSpace(*requested) != "" {
+		return requested
+	}
+	if s == nil || s.Template.DefaultFlowTemplateID == nil {
+		return requested
+	}
+	return s.Template.DefaultFlowTemplateID
+}
+
+// applyIssueTemplate stores field values on a newly created issue and applies
+// the template's default labels. Labels are best effort; field values are
+// not, since they were part of the request.
+func (h *IssuesHandler) applyIssueTemplate(
+	ctx context.Context,
+	issue *store.ProjectIssue,
+	selection *issueTemplateSelection,
+) ([]store.IssueFieldValue, error) {
+	if selection == nil || issue == nil {
+		return nil, nil
+	}
+	orgID := middleware.WorkspaceFromContext(ctx)
+	if err := h.TemplateStore.SetFieldValues(ctx, orgID, issue.ID, selection.Template.ID, selection.Values); err != nil {
+		return nil, err
+	}
+	if len(selection.Template.DefaultLabels) > 0 && h.DB != nil {
+		labelStore := store.NewLabelStore(h.DB)
+		for _, name := range selection.Template.DefaultLabels {
+			label, err := labelStore.EnsureByName(ctx, name, "")
+			if err != nil {
+				log.Printf("issues: failed to resolve template label %q for issue %s: %v", name, issue.ID, err)
+				continue
+			}
+			if err := labelStore.AddToIssue(ctx, issue.ID, label.ID); err != nil {
+				log.Printf("issues: failed to apply template label %q to issue %s: %v", name, issue.ID, err)
+			}
+		}
+	}
+	return selection.Values, nil
+}
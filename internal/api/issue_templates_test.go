@@ -0,0 +1,138 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/samhotchkiss/otter-camp/internal/middleware"
+	"github.com/samhotchkiss/otter-camp/internal/store"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIssueTemplateFieldInput(t *testing.T) {
+	provided, err := issueTemplateFieldInput(map[string]any{"estimate": 2.5, "severity": "High", "flag": true, "skip": nil})
+	require.NoError(t, err)
+	require.Equal(t, map[string]string{"estimate": "2.5", "severity": "High", "flag": "true"}, provided)
+
+	_, err = issueTemplateFieldInput(map[string]any{"tags": []any{"a"}})
+	require.ErrorContains(t, err, "field tags must be a string or number")
+}
+
+func TestIssueTemplateSelectionDefaults(t *testing.T) {
+	flowID := "flow-1"
+	selection := &issueTemplateSelection{
+		Template: &store.IssueTemplate{
+			Body:                  "Severity: {{severity}}",
+			Fields:                []store.IssueTemplateField{{Key: "severity", Type: "text"}},
+			DefaultFlowTemplateID: &flowID,
+		},
+		Values: []store.IssueFieldValue{{Key: "severity", Type: "text", Value: "High"}},
+	}
+	require.Equal(t, "Severity: High", *selection.body(nil))
+	explicit := "Custom body"
+	require.Equal(t, "Custom body", *selection.body(&explicit))
+	require.Equal(t, "flow-1", *selection.flowTemplateID(nil))
+	otherFlow := "flow-2"
+	require.Equal(t, "flow-2", *selection.flowTemplateID(&otherFlow))
+
+	var none *issueTemplateSelection
+	require.Nil(t, none.body(nil))
+	require.Nil(t, none.flowTemplateID(nil))
+}
+
+func TestIssuesHandlerCreateIssueRejectsFieldsWithoutTemplate(t *testing.T) {
+	handler := &IssuesHandler{}
+	rec := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodPost, "/api/projects/p/issues", nil)
+	_, ok := handler.resolveIssueTemplateSelection(rec, req, "p", nil, map[string]any{"severity": "High"})
+	require.False(t, ok)
+	require.Equal(t, http.StatusBadRequest, rec.Code)
+	require.Contains(t, rec.Body.String(), "fields require template_id")
+
+	template := "Bug"
+	rec = httptest.NewRecorder()
+	_, ok = handler.resolveIssueTemplateSelection(rec, req, "p", &template, nil)
+	require.False(t, ok)
+	require.Equal(t, http.StatusServiceUnavailable, rec.Code)
+
+	selection, ok := handler.resolveIssueTemplateSelection(httptest.NewRecorder(), req, "p", nil, nil)
+	require.True(t, ok)
+	require.Nil(t, selection)
+}
+
+func TestIssuesHandlerCreateIssueFromTemplate(t *testing.T) {
+	db := setupMessageTestDB(t)
+	orgID := insertMessageTestOrganization(t, db, "issues-api-template-org")
+	projectID := insertProjectTestProject(t, db, orgID, "Issue Template Project")
+
+	handler := &IssuesHandler{
+		IssueStore:    store.NewProjectIssueStore(db),
+		ProjectStore:  store.NewProjectStore(db),
+		TemplateStore: store.NewIssueTemplateStore(db),
+		DB:            db,
+	}
+	router := chi.NewRouter()
+	router.With(middleware.OptionalWorkspace).Post("/api/projects/{id}/issue-templates", handler.SaveTemplate)
+	router.With(middleware.OptionalWorkspace).Get("/api/projects/{id}/issue-templates", handler.ListTemplates)
+	router.With(middleware.OptionalWorkspace).Post("/api/projects/{id}/issues", handler.CreateIssue)
+	router.With(middleware.OptionalWorkspace).Get("/api/issues/{id}", handler.Get)
+
+	templateBody := `{
+		"name":"Bug",
+		"body":"## Severity\n{{severity}}\n\n## Spec\n{{spec}}",
+		"fields":[
+			{"key":"severity","type":"select","options":["Low","High"],"required":true},
+			{"key":"spec","type":"url"},
+			{"key":"estimate","type":"number","default":"1"}
+		],
+		"default_labels":["bug"]
+	}`
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/api/projects/"+projectID+"/issue-templates?org_id="+orgID, strings.NewReader(templateBody)))
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/projects/"+projectID+"/issue-templates?org_id="+orgID, nil))
+	require.Equal(t, http.StatusOK, rec.Code)
+	var listed issueTemplateListResponse
+	require.NoError(t, json.NewDecoder(rec.Body).Decode(&listed))
+	require.Equal(t, 1, listed.Total)
+
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/api/projects/"+projectID+"/issues?org_id="+orgID,
+		bytes.NewReader([]byte(`{"title":"Crash on save","template_id":"bug","fields":{"spec":"notaurl","severity":"high"}}`))))
+	require.Equal(t, http.StatusBadRequest, rec.Code)
+	require.Contains(t, rec.Body.String(), "http(s) URL")
+
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/api/projects/"+projectID+"/issues?org_id="+orgID,
+		bytes.NewReader([]byte(`{"title":"Crash on save","template_id":"bug","fields":{"severity":"high","estimate":3}}`))))
+	require.Equal(t, http.StatusCreated, rec.Code, rec.Body.String())
+	var created issueSummaryPayload
+	require.NoError(t, json.NewDecoder(rec.Body).Decode(&created))
+	require.NotNil(t, created.Body)
+	require.Equal(t, "## Severity\nHigh\n\n## Spec\n", *created.Body)
+	require.Len(t, created.Fields, 2)
+
+	labels, err := store.NewLabelStore(db).ListForIssue(issueTestCtx(orgID), created.ID)
+	require.NoError(t, err)
+	names := make([]string, 0, len(labels))
+	for _, label := range labels {
+		names = append(names, label.Name)
+	}
+	require.Contains(t, names, "bug")
+
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/issues/"+created.ID+"?org_id="+orgID, nil))
+	require.Equal(t, http.StatusOK, rec.Code)
+	var detail issueDetailPayload
+	require.NoError(t, json.NewDecoder(rec.Body).Decode(&detail))
+	require.Len(t, detail.Issue.Fields, 2)
+	require.Equal(t, "estimate", detail.Issue.Fields[0].Key)
+	require.Equal(t, "3", detail.Issue.Fields[0].Value)
+}
@@ -0,0 +1,53 @@
+package api
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/samhotchkiss/otter-camp/internal/store"
+)
+
+// GetIssueTimeline returns one page of the issue's merged event stream,
+// oldest first. Pass next_cursor back as cursor for the following page and
+// types as a comma-separated filter.
+func (h *IssuesHandler) GetIssueTimeline(w http.ResponseWriter, r *http.Request) {
+	if h.IssueStore == nil {
+		sendJSON(w, http.StatusServiceUnavailable, errorResponse{Error: "database not available"})
+		return
+	}
+	query := r.URL.Query()
+
+	limit := 50
+	if raw := strings.TrimSpace(query.Get("limit")); raw != "" {
+		parsed, err := strconv.Atoi(raw)
+		if err != nil || parsed <= 0 {
+			sendJSON(w, http.StatusBadRequest, errorResponse{Error: "limit must be a positive integer"})
+			return
+		}
+		limit = parsed
+	}
+	cursor, err := store.DecodeIssueTimelineCursor(query.Get("cursor"))
+	if err != nil {
+		sendJSON(w, http.StatusBadRequest, errorResponse{Error: "invalid cursor"})
+		return
+	}
+	types, err := store.ParseIssueTimelineTypes(query.Get("types"))
+	if err != nil {
+		sendJSON(w, http.StatusBadRequest, errorResponse{Error: err.Error()})
+		return
+	}
+
+	page, err := h.IssueStore.ListIssueTimeline(r.Context(), store.IssueTimelineInput{
+		IssueID: chi.URLParam(r, "id"),
+		Types:   types,
+		Cursor:  cursor,
+		Limit:   limit,
+	})
+	if err != nil {
+		handleIssueStoreError(w, err)
+		return
+	}
+	sendJSON(w, http.StatusOK, page)
+}
@@ -0,0 +1,67 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/samhotchkiss/otter-camp/internal/middleware"
+	"github.com/samhotchkiss/otter-camp/internal/store"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetIssueTimelineValidatesQuery(t *testing.T) {
+	handler := &IssuesHandler{}
+	rec := httptest.NewRecorder()
+	handler.GetIssueTimeline(rec, httptest.NewRequest(http.MethodGet, "/api/issues/x/timeline", nil))
+	require.Equal(t, http.StatusServiceUnavailable, rec.Code)
+
+	handler = &IssuesHandler{IssueStore: store.NewProjectIssueStore(nil)}
+	for query, want := range map[string]string{
+		"limit=0":       "limit must be a positive integer",
+		"cursor=bad!":   "invalid cursor",
+		"types=deploys": "unknown timeline type",
+	} {
+		rec := httptest.NewRecorder()
+		handler.GetIssueTimeline(rec, httptest.NewRequest(http.MethodGet, "/api/issues/x/timeline?"+query, nil))
+		require.Equal(t, http.StatusBadRequest, rec.Code, query)
+		require.Contains(t, rec.Body.String(), want, query)
+	}
+}
+
+func TestIssuesHandlerGetIssueTimeline(t *testing.T) {
+	db := setupMessageTestDB(t)
+	orgID := insertMessageTestOrganization(t, db, "issues-api-timeline-org")
+	projectID := insertProjectTestProject(t, db, orgID, "Issue Timeline Project")
+	agentID := insertMessageTestAgent(t, db, orgID, "issue-timeline-agent")
+
+	issueStore := store.NewProjectIssueStore(db)
+	issue, err := issueStore.CreateIssue(issueTestCtx(orgID), store.CreateProjectIssueInput{ProjectID: projectID, Title: "Timeline", Origin: "local"})
+	require.NoError(t, err)
+	_, err = issueStore.CreateComment(issueTestCtx(orgID), store.CreateProjectIssueCommentInput{IssueID: issue.ID, AuthorAgentID: agentID, Body: "First pass done"})
+	require.NoError(t, err)
+
+	handler := &IssuesHandler{IssueStore: issueStore}
+	router := chi.NewRouter()
+	router.With(middleware.OptionalWorkspace).Get("/api/issues/{id}/timeline", handler.GetIssueTimeline)
+
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/issues/"+issue.ID+"/timeline?org_id="+orgID+"&limit=1", nil))
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	var page store.IssueTimelinePage
+	require.NoError(t, json.NewDecoder(rec.Body).Decode(&page))
+	require.Len(t, page.Items, 1)
+	require.Equal(t, store.IssueTimelineIssueCreated, page.Items[0].Type)
+	require.NotEmpty(t, page.NextCursor)
+
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/issues/"+issue.ID+"/timeline?org_id="+orgID+"&cursor="+page.NextCursor, nil))
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	require.NoError(t, json.NewDecoder(rec.Body).Decode(&page))
+	require.Len(t, page.Items, 1)
+	require.Equal(t, store.IssueTimelineComment, page.Items[0].Type)
+	require.Equal(t, store.IssueTimelineActorAgent, page.Items[0].Actor.Type)
+	require.Empty(t, page.NextCursor)
+}
@@ -0,0 +1,316 @@
+package api
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/samhotchkiss/otter-camp/internal/middleware"
+	"github.com/samhotchkiss/otter-camp/internal/store"
+)
+
+type issueSavedViewListResponse struct {
+	Items []store.IssueSavedView `json:"items"`
+	Total int                    `json:"total"`
+}
+
+// issueListQueryParams are the single-value filters GET /api/issues has always
+// accepted. With q or view they become qualifiers of the same query.
+var issueListQueryParams = []struct {
+	param     string
+	qualifier string
+}{
+	{"state", store.IssueQueryFieldState},
+	{"origin", store.IssueQueryFieldOrigin},
+	{"kind", store.IssueQueryFieldKind},
+	{"issue_number", store.IssueQueryFieldNumber},
+	{"owner_agent_id", store.IssueQueryFieldOwner},
+	{"work_status", store.IssueQueryFieldStatus},
+	{"priority", store.IssueQueryFieldPriority},
+	{"parent_issue_id", store.IssueQueryFieldParent},
+}
+
+func isIssueQueryRequest(values url.Values) bool {
+	return strings.TrimSpace(values.Get("q")) != "" ||
+		strings.TrimSpace(values.Get("view")) != "" ||
+		strings.TrimSpace(values.Get("cursor")) != ""
+}
+
+// sessionUserID is the signed-in user behind the request, or "" for agent
+// and API key callers.
+func sessionUserID(r *http.Request, db *sql.DB, orgID string) string {
+	if userID := strings.TrimSpace(middleware.UserFromContext(r.Context())); userID != "" {
+		return userID
+	}
+	if db == nil || !isUserCredential(extractSessionToken(r)) {
+		return ""
+	}
+	identity, err := requireSessionIdentity(r.Context(), db, r)
+	if err != nil || identity.OrgID != orgID {
+		return ""
+	}
+	return identity.UserID
+}
+
+// resolveIssueQuery combines a saved view (when given), the legacy filter
+// parameters and q into one parsed query. Later sort: qualifiers win, so q
+// can re-sort a view.
+func resolveIssueQuery(
+	ctx context.Context,
+	views *store.IssueSavedViewStore,
+	values url.Values,
+	orgID string,
+	userID string,
+) (store.IssueQuery, string, *store.IssueSavedView, error) {
+	projectID := strings.TrimSpace(values.Get("project_id"))
+	parts := make([]string, 0, 3)
+
+	var view *store.IssueSavedView
+	if ref := strings.TrimSpace(values.Get("view")); ref != "" {
+		if views == nil {
+			return store.IssueQuery{}, "", nil, errIssueHandlerDatabaseUnavailable
+		}
+		resolved, err := views.Resolve(ctx, orgID, ref, userID, projectID)
+		if err != nil {
+			return store.IssueQuery{}, "", nil, err
+		}
+		view = resolved
+		parts = append(parts, view.Query)
+	}
+	for _, param := range issueListQueryParams {
+		if raw := strings.TrimSpace(values.Get(param.param)); raw != "" {
+			parts = append(parts, param.qualifier+":"+strconv.Quote(raw))
+		}
+	}
+	if raw := strings.TrimSpace(values.Get("q")); raw != "" {
+		parts = append(parts, raw)
+	}
+
+	combined := strings.Join(parts, " ")
+	query, err := store.ParseIssueQuery(combined)
+	if err != nil {
+		return store.IssueQuery{}, "", nil, err
+	}
+	return query, combined, view, nil
+}
+
+// listByQuery serves GET /api/issues?q=...|view=...|cursor=... . project_id is
+// optional here; a project view scopes to its own project.
+func (h *IssuesHandler) listByQuery(w http.ResponseWriter, r *http.Request) {
+	orgID := middleware.WorkspaceFromContext(r.Context())
+	if orgID == "" {
+		sendJSON(w, http.StatusUnauthorized, errorResponse{Error: "authentication required"})
+		return
+	}
+	values := r.URL.Query()
+
+	limit := 100
+	if raw := strings.TrimSpace(values.Get("limit")); raw != "" {
+		parsed, err := strconv.Atoi(raw)
+		if err != nil || parsed <= 0 {
+			sendJSON(w, http.StatusBadRequest, errorResponse{Error: "limit must be a positive integer"})
+			return
+		}
+		limit = parsed
+	}
+	cursor, err := store.DecodeIssueQueryCursor(values.Get("cursor"))
+	if err != nil {
+		handleJobsStoreError(w, err)
+		return
+	}
+
+	query, raw, view, err := resolveIssueQuery(r.Context(), h.SavedViewStore, values, orgID, sessionUserID(r, h.DB, orgID))
+	if err != nil {
+		if errors.Is(err, errIssueHandlerDatabaseUnavailable) {
+			sendJSON(w, http.StatusServiceUnavailable, errorResponse{Error: "database not available"})
+			return
+		}
+		handleIssueViewError(w, err)
+		return
+	}
+	projectID := strings.TrimSpace(values.Get("project_id"))
+	if projectID == "" && view != nil && view.ProjectID != nil {
+		projectID = *view.ProjectID
+	}
+
+	page, err := h.IssueStore.QueryIssues(r.Context(), store.IssueQueryInput{
+		ProjectID: projectID,
+		Query:     query,
+		Cursor:    cursor,
+		Limit:     limit,
+	})
+	if err != nil {
+		handleJobsStoreError(w, err)
+		return
+	}
+	items, err := h.issueSummaries(r.Context(), page.Items)
+	if err != nil {
+		handleIssueStoreError(w, err)
+		return
+	}
+	sendJSON(w, http.StatusOK, issueListResponse{
+		Items:      items,
+		Total:      len(items),
+		NextCursor: page.NextCursor,
+		Query:      raw,
+		View:       view,
+	})
+}
+
+func (h *IssuesHandler) ListViews(w http.ResponseWriter, r *http.Request) {
+	if h.SavedViewStore == nil {
+		sendJSON(w, http.StatusServiceUnavailable, errorResponse{Error: "database not available"})
+		return
+	}
+	orgID := middleware.WorkspaceFromContext(r.Context())
+	if orgID == "" {
+		sendJSON(w, http.StatusUnauthorized, errorResponse{Error: "authentication required"})
+		return
+	}
+	views, err := h.SavedViewStore.List(
+		r.Context(),
+		orgID,
+		sessionUserID(r, h.DB, orgID),
+		strings.TrimSpace(r.URL.Query().Get("project_id")),
+	)
+	if err != nil {
+		handleJobsStoreError(w, err)
+		return
+	}
+	sendJSON(w, http.StatusOK, issueSavedViewListResponse{Items: views, Total: len(views)})
+}
+
+// SaveView creates or replaces a view by name. With project_id the view is
+// shared with the project; without it the view is personal to the caller.
+func (h *IssuesHandler) SaveView(w http.ResponseWriter, r *http.Request) {
+	if h.SavedViewStore == nil {
+		sendJSON(w, http.StatusServiceUnavailable, errorResponse{Error: "database not available"})
+		return
+	}
+	orgID := middleware.WorkspaceFromContext(r.Context())
+	if orgID == "" {
+		sendJSON(w, http.StatusUnauthorized, errorResponse{Error: "authentication required"})
+		return
+	}
+
+	var req struct {
+		Name      string  `json:"name"`
+		Query     string  `json:"query"`
+		ProjectID *string `json:"project_id"`
+	}
+	decoder := json.NewDecoder(r.Body)
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&req); err != nil {
+		sendJSON(w, http.StatusBadRequest, errorResponse{Error: "invalid JSON"})
+		return
+	}
+
+	userID := sessionUserID(r, h.DB, orgID)
+	input := store.SaveIssueSavedViewInput{Name: req.Name, Query: req.Query, CreatedBy: userID}
+	if req.ProjectID != nil && strings.TrimSpace(*req.ProjectID) != "" {
+		input.ProjectID = strings.TrimSpace(*req.ProjectID)
+	} else if userID == "" {
+		sendJSON(w, http.StatusBadRequest, errorResponse{Error: "personal views need a signed-in user; pass project_id to share the view with a project"})
+		return
+	} else {
+		input.UserID = userID
+	}
+
+	view, err := h.SavedViewStore.Save(r.Context(), orgID, input)
+	if err != nil {
+		handleJobsStoreError(w, err)
+		return
+	}
+	recordAudit(r, auditEvent{Action: "issue_view.save", TargetType: "issue_saved_view", TargetID: view.ID, After: view})
+	log.Printf("[issue-views] saved view %q (%s) org=%s", view.Name, view.ID, orgID)
+	sendJSON(w, http.StatusOK, view)
+}
+
+func (h *IssuesHandler) DeleteView(w http.ResponseWriter, r *http.Request) {
+	if h.SavedViewStore == nil {
+		sendJSON(w, http.StatusServiceUnavailable, errorResponse{Error: "database not available"})
+		return
+	}
+	orgID := middleware.WorkspaceFromContext(r.Context())
+	if orgID == "" {
+		sendJSON(w, http.StatusUnauthorized, errorResponse{Error: "authentication required"})
+		return
+	}
+	viewID := strings.TrimSpace(chi.URLParam(r, "viewID"))
+	if err := h.SavedViewStore.Delete(r.Context(), orgID, viewID, sessionUserID(r, h.DB, orgID)); err != nil {
+		handleIssueViewError(w, err)
+		return
+	}
+	recordAudit(r, auditEvent{Action: "issue_view.delete", TargetType: "issue_saved_view", TargetID: viewID})
+	sendJSON(w, http.StatusOK, map[string]any{"deleted": true, "id": viewID})
+}
+
+func handleIssueViewError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, store.ErrNotFound):
+		sendJSON(w, http.StatusNotFound, errorResponse{Error: "view not found"})
+	case errors.Is(err, store.ErrConflict):
+		sendJSON(w, http.StatusConflict, errorResponse{Error: err.Error()})
+	default:
+		handleJobsStoreError(w, err)
+	}
+}
+
+// inboxViewItems lists the issues matching a saved view as inbox entries.
+func inboxViewItems(r *http.Request, db *sql.DB, orgID, ref string, limit int) ([]InboxItem, error) {
+	ctx := context.WithValue(r.Context(), middleware.WorkspaceIDKey, orgID)
+	view, err := store.NewIssueSavedViewStore(db).Resolve(ctx, orgID, ref, sessionUserID(r, db, orgID), "")
+	if err != nil {
+		return nil, err
+	}
+	query, err := store.ParseIssueQuery(view.Query)
+	if err != nil {
+		return nil, err
+	}
+	projectID := ""
+	if view.ProjectID != nil {
+		projectID = *view.ProjectID
+	}
+	page, err := store.NewProjectIssueStore(db).QueryIssues(ctx, store.IssueQueryInput{
+		ProjectID: projectID,
+		Query:     query,
+		Limit:     limit,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	items := make([]InboxItem, 0, len(page.Items))
+	for _, issue := range page.Items {
+		issueID := issue.ID
+		issueProjectID := issue.ProjectID
+		issueNumber := issue.IssueNumber
+		title := issue.Title
+		agent := "Unassigned"
+		if issue.OwnerAgentID != nil {
+			agent = *issue.OwnerAgentID
+		}
+		summary := fmt.Sprintf("%s issue in view %q", issue.Priority, view.Name)
+		items = append(items, InboxItem{
+			ID:         "issue:" + issue.ID,
+			Type:       "issue",
+			Agent:      agent,
+			Status:     issue.WorkStatus,
+			CreatedAt:  issue.UpdatedAt,
+			TaskID:     &issueID,
+			ProjectID:  &issueProjectID,
+			TaskNumber: &issueNumber,
+			TaskTitle:  &title,
+			Summary:    &summary,
+		})
+	}
+	return items, nil
+}
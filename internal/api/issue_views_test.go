@@ -0,0 +1,87 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/samhotchkiss/otter-camp/internal/middleware"
+	"github.com/samhotchkiss/otter-camp/internal/store"
+	"github.com/stretchr/testify/require"
+)
+
+func TestResolveIssueQueryFoldsLegacyFiltersIntoQuery(t *testing.T) {
+	values := url.Values{}
+	values.Set("state", "open")
+	values.Set("priority", "p1")
+	values.Set("owner_agent_id", "00000000-0000-0000-0000-0000000000aa")
+	values.Set("q", `label:blog "launch post" sort:updated`)
+
+	query, raw, view, err := resolveIssueQuery(context.Background(), nil, values, "org-1", "")
+	require.NoError(t, err)
+	require.Nil(t, view)
+	require.Equal(t, `state:"open" owner:"00000000-0000-0000-0000-0000000000aa" priority:"p1" label:blog "launch post" sort:updated`, raw)
+
+	fields := make([]string, 0, len(query.Terms))
+	for _, term := range query.Terms {
+		fields = append(fields, term.Field)
+	}
+	require.Equal(t, []string{"state", "owner", "priority", "label", "text"}, fields)
+	require.Equal(t, []string{"P1"}, query.Terms[2].Values)
+	require.Equal(t, store.IssueQuerySort{Field: "updated", Desc: true}, query.Sort)
+}
+
+func TestIssuesListQueryRejectsInvalidQueries(t *testing.T) {
+	handler := &IssuesHandler{IssueStore: store.NewProjectIssueStore(nil)}
+	orgCtx := context.WithValue(context.Background(), middleware.WorkspaceIDKey, "00000000-0000-0000-0000-000000000001")
+
+	rec := httptest.NewRecorder()
+	handler.List(rec, httptest.NewRequest(http.MethodGet, "/api/issues?q=priority:P9", nil))
+	require.Equal(t, http.StatusUnauthorized, rec.Code)
+
+	for rawQuery, want := range map[string]string{
+		"q=" + url.QueryEscape("priority:P9"):        "invalid priority",
+		"q=" + url.QueryEscape(`"launch`):            "unterminated quote",
+		"q=is:open&cursor=bogus":                     "invalid cursor",
+		"q=is:open&limit=0":                          "limit must be a positive integer",
+		"view=launch":                                "database not available",
+		"state=open&q=" + url.QueryEscape("sort:no"): "sort must be",
+	} {
+		rec := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodGet, "/api/issues?"+rawQuery, nil).WithContext(orgCtx)
+		handler.List(rec, req)
+
+		var resp errorResponse
+		require.NoError(t, json.NewDecoder(rec.Body).Decode(&resp), rawQuery)
+		require.Contains(t, resp.Error, want, rawQuery)
+		require.NotEqual(t, http.StatusOK, rec.Code, rawQuery)
+	}
+}
+
+func TestIssueViewHandlersRequireDatabaseWorkspaceAndUser(t *testing.T) {
+	rec := httptest.NewRecorder()
+	(&IssuesHandler{}).ListViews(rec, httptest.NewRequest(http.MethodGet, "/api/issues/views", nil))
+	require.Equal(t, http.StatusServiceUnavailable, rec.Code)
+
+	handler := &IssuesHandler{SavedViewStore: store.NewIssueSavedViewStore(nil)}
+	rec = httptest.NewRecorder()
+	handler.SaveView(rec, httptest.NewRequest(http.MethodPost, "/api/issues/views", strings.NewReader(`{}`)))
+	require.Equal(t, http.StatusUnauthorized, rec.Code)
+
+	orgCtx := context.WithValue(context.Background(), middleware.WorkspaceIDKey, "00000000-0000-0000-0000-000000000001")
+	rec = httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodPost, "/api/issues/views", strings.NewReader(`{"name":"mine","query":"is:open"}`)).WithContext(orgCtx)
+	handler.SaveView(rec, req)
+	require.Equal(t, http.StatusBadRequest, rec.Code)
+	require.Contains(t, rec.Body.String(), "personal views need a signed-in user")
+
+	rec = httptest.NewRecorder()
+	req = httptest.NewRequest(http.MethodPost, "/api/issues/views", strings.NewReader(`{"name":"x","scope":"team"}`)).WithContext(orgCtx)
+	handler.SaveView(rec, req)
+	require.Equal(t, http.StatusBadRequest, rec.Code)
+	require.Contains(t, rec.Body.String(), "invalid JSON")
+}
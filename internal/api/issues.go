@@ -18,8 +18,11 @@ import (
 	"unicode/utf8"
 
 	"github.com/go-chi/chi/v5"
+	importer "github.com/samhotchkiss/otter-camp/internal/import"
+	"github.com/samhotchkiss/otter-camp/internal/memory"
 	"github.com/samhotchkiss/otter-camp/internal/middleware"
 	"github.com/samhotchkiss/otter-camp/internal/store"
+	"github.com/samhotchkiss/otter-camp/internal/tracing"
 	"github.com/samhotchkiss/otter-camp/internal/ws"
 )
 
@@ -41,36 +44,44 @@ type IssuesHandler struct {
 	DB                  *sql.DB
 	Hub                 *ws.Hub
 	OpenClawDispatcher  openClawMessageDispatcher
+	SavedViewStore      *store.IssueSavedViewStore
+	TemplateStore       *store.IssueTemplateStore
+	RecurringStore      *store.RecurringIssueStore
+	IssueImporter       *importer.IssueImportRunner
+	Embedder            memory.Embedder
 }
 
 var errIssueHandlerDatabaseUnavailable = errors.New("database not available")
 
 type issueSummaryPayload struct {
-	ID                       string  `json:"id"`
-	ProjectID                string  `json:"project_id"`
-	IssueNumber              int64   `json:"issue_number"`
-	Title                    string  `json:"title"`
-	Body                     *string `json:"body,omitempty"`
-	State                    string  `json:"state"`
-	Origin                   string  `json:"origin"`
-	DocumentPath             *string `json:"document_path,omitempty"`
-	DocumentContent          *string `json:"document_content,omitempty"`
-	ApprovalState            string  `json:"approval_state"`
-	Kind                     string  `json:"kind"`
-	OwnerAgentID             *string `json:"owner_agent_id,omitempty"`
-	WorkStatus               string  `json:"work_status"`
-	Priority                 string  `json:"priority"`
-	DueAt                    *string `json:"due_at,omitempty"`
-	NextStep                 *string `json:"next_step,omitempty"`
-	NextStepDueAt            *string `json:"next_step_due_at,omitempty"`
-	FlowTemplateID           *string `json:"flow_template_id,omitempty"`
-	FlowStepKey              *string `json:"flow_step_key,omitempty"`
-	FlowStepIndex            *int    `json:"flow_step_index,omitempty"`
-	LastActivityAt           string  `json:"last_activity_at"`
-	GitHubNumber             *int64  `json:"github_number,omitempty"`
-	GitHubURL                *string `json:"github_url,omitempty"`
-	GitHubState              *string `json:"github_state,omitempty"`
-	GitHubRepositoryFullName *string `json:"github_repository_full_name,omitempty"`
+	ID                       string                  `json:"id"`
+	ProjectID                string                  `json:"project_id"`
+	IssueNumber              int64                   `json:"issue_number"`
+	Title                    string                  `json:"title"`
+	Body                     *string                 `json:"body,omitempty"`
+	State                    string                  `json:"state"`
+	Origin                   string                  `json:"origin"`
+	DocumentPath             *string                 `json:"document_path,omitempty"`
+	DocumentContent          *string                 `json:"document_content,omitempty"`
+	ApprovalState            string                  `json:"approval_state"`
+	Kind                     string                  `json:"kind"`
+	OwnerAgentID             *string                 `json:"owner_agent_id,omitempty"`
+	WorkStatus               string                  `json:"work_status"`
+	Priority                 string                  `json:"priority"`
+	DueAt                    *string                 `json:"due_at,omitempty"`
+	NextStep                 *string                 `json:"next_step,omitempty"`
+	NextStepDueAt            *string                 `json:"next_step_due_at,omitempty"`
+	FlowTemplateID           *string                 `json:"flow_template_id,omitempty"`
+	FlowStepKey              *string                 `json:"flow_step_key,omitempty"`
+	FlowStepIndex            *int                    `json:"flow_step_index,omitempty"`
+	ParentIssueID            *string                 `json:"parent_issue_id,omitempty"`
+	MergedIntoIssueID        *string                 `json:"merged_into_issue_id,omitempty"`
+	LastActivityAt           string                  `json:"last_activity_at"`
+	GitHubNumber             *int64                  `json:"github_number,omitempty"`
+	GitHubURL                *string                 `json:"github_url,omitempty"`
+	GitHubState              *string                 `json:"github_state,omitempty"`
+	GitHubRepositoryFullName *string                 `json:"github_repository_full_name,omitempty"`
+	Fields                   []store.IssueFieldValue `json:"fields,omitempty"`
 }
 
 type issueParticipantPayload struct {
@@ -117,6 +128,8 @@ type openClawIssueCommentDispatchEvent struct {
 	Timestamp time.Time                        `json:"timestamp"`
 	OrgID     string                           `json:"org_id"`
 	Data      openClawIssueCommentDispatchData `json:"data"`
+	// Traceparent carries W3C trace context to the bridge.
+	Traceparent string `json:"traceparent,omitempty"`
 }
 
 type openClawIssueCommentDispatchData struct {
@@ -147,8 +160,11 @@ type issueCommentDispatchTarget struct {
 }
 
 type issueListResponse struct {
-	Items []issueSummaryPayload `json:"items"`
-	Total int                   `json:"total"`
+	Items      []issueSummaryPayload `json:"items"`
+	Total      int                   `json:"total"`
+	NextCursor string                `json:"next_cursor,omitempty"`
+	Query      string                `json:"query,omitempty"`
+	View       *store.IssueSavedView `json:"view,omitempty"`
 }
 
 type issuePatchRequest struct {
@@ -187,6 +203,10 @@ func (h *IssuesHandler) List(w http.ResponseWriter, r *http.Request) {
 		sendJSON(w, http.StatusServiceUnavailable, errorResponse{Error: "database not available"})
 		return
 	}
+	if isIssueQueryRequest(r.URL.Query()) {
+		h.listByQuery(w, r)
+		return
+	}
 
 	projectID := strings.TrimSpace(r.URL.Query().Get("project_id"))
 	if projectID == "" {
@@ -259,27 +279,34 @@ func (h *IssuesHandler) List(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	items, err := h.issueSummaries(r.Context(), issues)
+	if err != nil {
+		handleIssueStoreError(w, err)
+		return
+	}
+
+	sendJSON(w, http.StatusOK, issueListResponse{Items: items, Total: len(items)})
+}
+
+func (h *IssuesHandler) issueSummaries(ctx context.Context, issues []store.ProjectIssue) ([]issueSummaryPayload, error) {
 	issueIDs := make([]string, 0, len(issues))
 	for _, issue := range issues {
 		issueIDs = append(issueIDs, issue.ID)
 	}
-	linksByIssueID, err := h.IssueStore.ListGitHubLinksByIssueIDs(r.Context(), issueIDs)
+	linksByIssueID, err := h.IssueStore.ListGitHubLinksByIssueIDs(ctx, issueIDs)
 	if err != nil {
-		handleIssueStoreError(w, err)
-		return
+		return nil, err
 	}
 
 	items := make([]issueSummaryPayload, 0, len(issues))
 	for _, issue := range issues {
-		participants, err := h.IssueStore.ListParticipants(r.Context(), issue.ID, false)
+		participants, err := h.IssueStore.ListParticipants(ctx, issue.ID, false)
 		if err != nil {
-			handleIssueStoreError(w, err)
-			return
+			return nil, err
 		}
 		items = append(items, toIssueSummaryPayload(issue, participants, findIssueLink(linksByIssueID, issue.ID)))
 	}
-
-	sendJSON(w, http.StatusOK, issueListResponse{Items: items, Total: len(items)})
+	return items, nil
 }
 
 func (h *IssuesHandler) CreateIssue(w http.ResponseWriter, r *http.Request) {
@@ -299,17 +326,22 @@ func (h *IssuesHandler) CreateIssue(w http.ResponseWriter, r *http.Request) {
 	}
 
 	var req struct {
-		Title          string  `json:"title"`
-		Body           *string `json:"body"`
-		OwnerAgentID   *string `json:"owner_agent_id"`
-		Priority       string  `json:"priority"`
-		WorkStatus     string  `json:"work_status"`
-		State          string  `json:"state"`
-		ApprovalState  string  `json:"approval_state"`
-		DueAt          *string `json:"due_at"`
-		NextStep       *string `json:"next_step"`
-		NextStepDueAt  *string `json:"next_step_due_at"`
-		FlowTemplateID *string `json:"flow_template_id"`
+		Title          string         `json:"title"`
+		Body           *string        `json:"body"`
+		OwnerAgentID   *string        `json:"owner_agent_id"`
+		Priority       string         `json:"priority"`
+		WorkStatus     string         `json:"work_status"`
+		State          string         `json:"state"`
+		ApprovalState  string         `json:"approval_state"`
+		DueAt          *string        `json:"due_at"`
+		NextStep       *string        `json:"next_step"`
+		NextStepDueAt  *string        `json:"next_step_due_at"`
+		FlowTemplateID *string        `json:"flow_template_id"`
+		TemplateID     *string        `json:"template_id"`
+		Fields         map[string]any `json:"fields"`
+		// AgentID is set when an agent files the issue itself. Agent-filed
+		// duplicates are rejected when OTTER_ISSUE_DUPLICATE_BLOCK_AGENTS is on.
+		AgentID *string `json:"agent_id"`
 	}
 	decoder := json.NewDecoder(r.Body)
 	decoder.DisallowUnknownFields()
@@ -323,6 +355,20 @@ func (h *IssuesHandler) CreateIssue(w http.ResponseWriter, r *http.Request) {
 		sendJSON(w, http.StatusBadRequest, errorResponse{Error: "title is required"})
 		return
 	}
+	selection, ok := h.resolveIssueTemplateSelection(w, r, projectID, req.TemplateID, req.Fields)
+	if !ok {
+		return
+	}
+
+	duplicateCheck := h.checkIssueDuplicates(r.Context(), projectID, title, selection.body(req.Body))
+	agentFiled := req.AgentID != nil && strings.TrimSpace(*req.AgentID) != ""
+	if agentFiled && len(duplicateCheck.duplicates) > 0 && shouldBlockAgentIssueDuplicates() {
+		sendJSON(w, http.StatusConflict, issueDuplicateConflictResponse{
+			Error:      issueDuplicateConflictMessage(duplicateCheck.duplicates),
+			Duplicates: toIssueDuplicatePayloads(duplicateCheck.duplicates),
+		})
+		return
+	}
 
 	dueAt, err := parseOptionalRFC3339(req.DueAt, "due_at")
 	if err != nil {
@@ -344,7 +390,7 @@ func (h *IssuesHandler) CreateIssue(w http.ResponseWriter, r *http.Request) {
 	var flowStepKey *string
 	var flowStepIndex *int
 	if h.FlowStore != nil {
-		template, steps, err := h.resolveIssueFlowTemplate(r.Context(), projectID, req.FlowTemplateID)
+		template, steps, err := h.resolveIssueFlowTemplate(r.Context(), projectID, selection.flowTemplateID(req.FlowTemplateID))
 		if err != nil {
 			sendJSON(w, http.StatusBadRequest, errorResponse{Error: err.Error()})
 			return
@@ -364,7 +410,7 @@ func (h *IssuesHandler) CreateIssue(w http.ResponseWriter, r *http.Request) {
 	issue, err := h.IssueStore.CreateIssue(r.Context(), store.CreateProjectIssueInput{
 		ProjectID:      projectID,
 		Title:          title,
-		Body:           req.Body,
+		Body:           selection.body(req.Body),
 		State:          req.State,
 		Origin:         "local",
 		DocumentPath:   nil,
@@ -385,6 +431,11 @@ func (h *IssuesHandler) CreateIssue(w http.ResponseWriter, r *http.Request) {
 	}
 	issue = h.maybeAutoReadyForReviewFromWorkStatus(r.Context(), issue)
 	h.applyAutomaticIssueLabelsBestEffort(r.Context(), issue)
+	fieldValues, err := h.applyIssueTemplate(r.Context(), issue, selection)
+	if err != nil {
+		handleIssueStoreError(w, err)
+		return
+	}
 
 	if issue.OwnerAgentID != nil {
 		if _, err := h.IssueStore.AddParticipant(r.Context(), store.AddProjectIssueParticipantInput{
@@ -403,10 +454,16 @@ func (h *IssuesHandler) CreateIssue(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	h.storeIssueEmbeddingBestEffort(r.Context(), issue.ID, duplicateCheck.embedding)
+
 	createdPayload := toIssueSummaryPayload(*issue, participants, nil)
+	createdPayload.Fields = fieldValues
 	h.dispatchIssueKickoffBestEffort(r.Context(), *issue)
 	h.broadcastIssueCreated(r.Context(), createdPayload)
-	sendJSON(w, http.StatusCreated, createdPayload)
+	sendJSON(w, http.StatusCreated, issueCreateResponse{
+		issueSummaryPayload: createdPayload,
+		Duplicates:          toIssueDuplicatePayloads(duplicateCheck.duplicates),
+	})
 }
 
 func (h *IssuesHandler) CreateLinkedIssue(w http.ResponseWriter, r *http.Request) {
@@ -426,9 +483,11 @@ func (h *IssuesHandler) CreateLinkedIssue(w http.ResponseWriter, r *http.Request
 	}
 
 	var req struct {
-		DocumentPath  string `json:"document_path"`
-		Title         string `json:"title"`
-		ApprovalState string `json:"approval_state"`
+		DocumentPath  string         `json:"document_path"`
+		Title         string         `json:"title"`
+		ApprovalState string         `json:"approval_state"`
+		TemplateID    *string        `json:"template_id"`
+		Fields        map[string]any `json:"fields"`
 	}
 	decoder := json.NewDecoder(r.Body)
 	decoder.DisallowUnknownFields()
@@ -446,13 +505,17 @@ func (h *IssuesHandler) CreateLinkedIssue(w http.ResponseWriter, r *http.Request
 	if title == "" {
 		title = defaultLinkedIssueTitle(documentPath)
 	}
+	selection, ok := h.resolveIssueTemplateSelection(w, r, projectID, req.TemplateID, req.Fields)
+	if !ok {
+		return
+	}
 
 	defaultOwnerAgentID := h.resolveDefaultIssueOwnerAgentID(r.Context(), projectID)
 	var flowTemplateID *string
 	var flowStepKey *string
 	var flowStepIndex *int
 	if h.FlowStore != nil {
-		template, steps, err := h.resolveIssueFlowTemplate(r.Context(), projectID, nil)
+		template, steps, err := h.resolveIssueFlowTemplate(r.Context(), projectID, selection.flowTemplateID(nil))
 		if err == nil && template != nil && len(steps) > 0 {
 			flowTemplateID = &template.ID
 			step := steps[0]
@@ -468,6 +531,7 @@ func (h *IssuesHandler) CreateLinkedIssue(w http.ResponseWriter, r *http.Request
 	issue, err := h.IssueStore.CreateIssue(r.Context(), store.CreateProjectIssueInput{
 		ProjectID:      projectID,
 		Title:          title,
+		Body:           selection.body(nil),
 		State:          "open",
 		Origin:         "local",
 		DocumentPath:   &documentPath,
@@ -483,6 +547,11 @@ func (h *IssuesHandler) CreateLinkedIssue(w http.ResponseWriter, r *http.Request
 	}
 	issue = h.maybeAutoReadyForReviewFromWorkStatus(r.Context(), issue)
 	h.applyAutomaticIssueLabelsBestEffort(r.Context(), issue)
+	fieldValues, err := h.applyIssueTemplate(r.Context(), issue, selection)
+	if err != nil {
+		handleIssueStoreError(w, err)
+		return
+	}
 
 	if issue.OwnerAgentID != nil {
 		if _, err := h.IssueStore.AddParticipant(r.Context(), store.AddProjectIssueParticipantInput{
@@ -502,6 +571,7 @@ func (h *IssuesHandler) CreateLinkedIssue(w http.ResponseWriter, r *http.Request
 	}
 
 	createdPayload := toIssueSummaryPayload(*issue, participants, nil)
+	createdPayload.Fields = fieldValues
 	h.dispatchIssueKickoffBestEffort(r.Context(), *issue)
 	h.broadcastIssueCreated(r.Context(), createdPayload)
 	sendJSON(w, http.StatusCreated, createdPayload)
@@ -560,6 +630,14 @@ func (h *IssuesHandler) Get(w http.ResponseWriter, r *http.Request) {
 	}
 	payload := toIssueSummaryPayload(*issue, participants, findIssueLink(linksByIssueID, issue.ID))
 	payload.DocumentContent = linkedDocumentContent
+	if h.TemplateStore != nil {
+		fields, err := h.TemplateStore.ListFieldValues(r.Context(), issue.OrgID, issue.ID)
+		if err != nil {
+			handleIssueStoreError(w, err)
+			return
+		}
+		payload.Fields = fields
+	}
 
 	sendJSON(w, http.StatusOK, issueDetailPayload{
 		Issue:          payload,
@@ -607,6 +685,13 @@ func (h *IssuesHandler) CreateComment(w http.ResponseWriter, r *http.Request) {
 		sendJSON(w, http.StatusInternalServerError, errorResponse{Error: "failed to resolve issue chat target"})
 		return
 	}
+	if shouldDispatch {
+		orgID := middleware.WorkspaceFromContext(r.Context())
+		if warning := usageBudgetDispatchWarning(r.Context(), h.DB, orgID, target.AgentSlug, target.ProjectID); warning != "" {
+			shouldDispatch = false
+			dispatchWarning = warning
+		}
+	}
 
 	comment, err := h.IssueStore.CreateComment(r.Context(), store.CreateProjectIssueCommentInput{
 		IssueID:       issueID,
@@ -1605,24 +1690,26 @@ func toIssueSummaryPayload(
 	link *store.ProjectIssueGitHubLink,
 ) issueSummaryPayload {
 	payload := issueSummaryPayload{
-		ID:             issue.ID,
-		ProjectID:      issue.ProjectID,
-		IssueNumber:    issue.IssueNumber,
-		Title:          issue.Title,
-		Body:           issue.Body,
-		State:          issue.State,
-		Origin:         issue.Origin,
-		DocumentPath:   issue.DocumentPath,
-		ApprovalState:  issue.ApprovalState,
-		Kind:           inferIssueKind(link),
-		OwnerAgentID:   issue.OwnerAgentID,
-		WorkStatus:     issue.WorkStatus,
-		Priority:       issue.Priority,
-		NextStep:       issue.NextStep,
-		FlowTemplateID: issue.FlowTemplateID,
-		FlowStepKey:    issue.FlowStepKey,
-		FlowStepIndex:  issue.FlowStepIndex,
-		LastActivityAt: issue.UpdatedAt.UTC().Format(time.RFC3339),
+		ID:                issue.ID,
+		ProjectID:         issue.ProjectID,
+		IssueNumber:       issue.IssueNumber,
+		Title:             issue.Title,
+		Body:              issue.Body,
+		State:             issue.State,
+		Origin:            issue.Origin,
+		DocumentPath:      issue.DocumentPath,
+		ApprovalState:     issue.ApprovalState,
+		Kind:              inferIssueKind(link),
+		OwnerAgentID:      issue.OwnerAgentID,
+		WorkStatus:        issue.WorkStatus,
+		Priority:          issue.Priority,
+		NextStep:          issue.NextStep,
+		FlowTemplateID:    issue.FlowTemplateID,
+		FlowStepKey:       issue.FlowStepKey,
+		FlowStepIndex:     issue.FlowStepIndex,
+		ParentIssueID:     issue.ParentIssueID,
+		MergedIntoIssueID: issue.MergedIntoID,
+		LastActivityAt:    issue.UpdatedAt.UTC().Format(time.RFC3339),
 	}
 	if payload.OwnerAgentID == nil {
 		payload.OwnerAgentID = ownerAgentIDFromParticipants(participants)
@@ -2054,6 +2141,9 @@ func (h *IssuesHandler) dispatchIssueKickoffBestEffort(
 	if err != nil || !shouldDispatch {
 		return
 	}
+	if usageBudgetDispatchWarning(ctx, h.DB, issue.OrgID, target.AgentSlug, target.ProjectID) != "" {
+		return
+	}
 	if !h.runEllieContextGateBeforeKickoff(ctx, issue) {
 		return
 	}
@@ -2264,9 +2354,10 @@ func (h *IssuesHandler) buildIssueCommentDispatchEvent(
 	}
 
 	event := openClawIssueCommentDispatchEvent{
-		Type:      "issue.comment.message",
-		Timestamp: time.Now().UTC(),
-		OrgID:     workspaceID,
+		Type:        "issue.comment.message",
+		Timestamp:   time.Now().UTC(),
+		OrgID:       workspaceID,
+		Traceparent: tracing.Traceparent(ctx),
 		Data: openClawIssueCommentDispatchData{
 			MessageID:        comment.ID,
 			IssueID:          strings.TrimSpace(issueID),
@@ -2457,6 +2548,8 @@ func handleIssueStoreError(w http.ResponseWriter, err error) {
 		sendJSON(w, http.StatusNotFound, errorResponse{Error: "not found"})
 	case errors.Is(err, store.ErrValidation):
 		sendJSON(w, http.StatusBadRequest, errorResponse{Error: "invalid request"})
+	case errors.Is(err, store.ErrOpenSubIssues):
+		sendJSON(w, http.StatusConflict, errorResponse{Error: err.Error()})
 	case errors.Is(err, store.ErrConflict):
 		sendJSON(w, http.StatusConflict, errorResponse{Error: "invalid state transition"})
 	default:
@@ -56,9 +56,19 @@ type jobPayload struct {
 	ErrorCount          int     `json:"error_count"`
 	MaxFailures         int     `json:"max_failures"`
 	ConsecutiveFailures int     `json:"consecutive_failures"`
-	CreatedBy           *string `json:"created_by,omitempty"`
-	CreatedAt           string  `json:"created_at"`
-	UpdatedAt           string  `json:"updated_at"`
+	OverlapPolicy       string  `json:"overlap_policy"`
+	CatchUpPolicy       string  `json:"catch_up_policy"`
+	JitterMS            int64   `json:"jitter_ms"`
+	MaxAgentConcurrency *int    `json:"max_agent_concurrency,omitempty"`
+
+	SuccessPattern          *string `json:"success_pattern,omitempty"`
+	SuccessJSONField        *string `json:"success_json_field,omitempty"`
+	SuccessClassifierPrompt *string `json:"success_classifier_prompt,omitempty"`
+	ReplyTimeoutMS          *int64  `json:"reply_timeout_ms,omitempty"`
+
+	CreatedBy *string `json:"created_by,omitempty"`
+	CreatedAt string  `json:"created_at"`
+	UpdatedAt string  `json:"updated_at"`
 }
 
 type jobRunPayload struct {
@@ -73,6 +83,10 @@ type jobRunPayload struct {
 	PayloadText string  `json:"payload_text"`
 	MessageID   *string `json:"message_id,omitempty"`
 	CreatedAt   string  `json:"created_at"`
+
+	Output          *string `json:"output,omitempty"`
+	ReplyMessageID  *string `json:"reply_message_id,omitempty"`
+	ReplyDeadlineAt *string `json:"reply_deadline_at,omitempty"`
 }
 
 type createJobRequest struct {
@@ -90,6 +104,16 @@ type createJobRequest struct {
 	Enabled      *bool   `json:"enabled"`
 	MaxFailures  *int    `json:"max_failures"`
 	SessionKey   *string `json:"session_key"`
+
+	OverlapPolicy       *string `json:"overlap_policy"`
+	CatchUpPolicy       *string `json:"catch_up_policy"`
+	JitterMS            *int64  `json:"jitter_ms"`
+	MaxAgentConcurrency *int    `json:"max_agent_concurrency"`
+
+	SuccessPattern          *string `json:"success_pattern"`
+	SuccessJSONField        *string `json:"success_json_field"`
+	SuccessClassifierPrompt *string `json:"success_classifier_prompt"`
+	ReplyTimeoutMS          *int64  `json:"reply_timeout_ms"`
 }
 
 type patchJobRequest struct {
@@ -105,6 +129,51 @@ type patchJobRequest struct {
 	RoomID       *string `json:"room_id"`
 	Enabled      *bool   `json:"enabled"`
 	MaxFailures  *int    `json:"max_failures"`
+
+	OverlapPolicy       *string `json:"overlap_policy"`
+	CatchUpPolicy       *string `json:"catch_up_policy"`
+	JitterMS            *int64  `json:"jitter_ms"`
+	MaxAgentConcurrency *int    `json:"max_agent_concurrency"`
+	// ClearMaxAgentConcurrency removes a previously configured per-agent limit.
+	ClearMaxAgentConcurrency bool `json:"clear_max_agent_concurrency"`
+
+	// Empty success criteria strings and a zero reply_timeout_ms clear them.
+	SuccessPattern          *string `json:"success_pattern"`
+	SuccessJSONField        *string `json:"success_json_field"`
+	SuccessClassifierPrompt *string `json:"success_classifier_prompt"`
+	ReplyTimeoutMS          *int64  `json:"reply_timeout_ms"`
+}
+
+type replyJobRunRequest struct {
+	Output     string  `json:"output"`
+	SessionKey *string `json:"session_key"`
+}
+
+type jobBlackoutPayload struct {
+	ID          string  `json:"id"`
+	OrgID       string  `json:"org_id"`
+	Name        string  `json:"name"`
+	DaysOfWeek  []int   `json:"days_of_week"`
+	StartDate   *string `json:"start_date,omitempty"`
+	EndDate     *string `json:"end_date,omitempty"`
+	StartMinute int     `json:"start_minute"`
+	EndMinute   int     `json:"end_minute"`
+	CreatedAt   string  `json:"created_at"`
+	UpdatedAt   string  `json:"updated_at"`
+}
+
+type jobBlackoutsResponse struct {
+	Items []jobBlackoutPayload `json:"items"`
+	Total int                  `json:"total"`
+}
+
+type createJobBlackoutRequest struct {
+	Name        string  `json:"name"`
+	DaysOfWeek  []int   `json:"days_of_week"`
+	StartDate   *string `json:"start_date"`
+	EndDate     *string `json:"end_date"`
+	StartMinute *int    `json:"start_minute"`
+	EndMinute   *int    `json:"end_minute"`
 }
 
 func (h *JobsHandler) Create(w http.ResponseWriter, r *http.Request) {
@@ -153,8 +222,21 @@ func (h *JobsHandler) Create(w http.ResponseWriter, r *http.Request) {
 		sendJSON(w, http.StatusBadRequest, errorResponse{Error: err.Error()})
 		return
 	}
+	if req.JitterMS != nil && *req.JitterMS < 0 {
+		sendJSON(w, http.StatusBadRequest, errorResponse{Error: "jitter_ms must not be negative"})
+		return
+	}
+	blackouts, err := h.Store.ListBlackoutWindows(r.Context())
+	if err != nil {
+		handleJobsStoreError(w, err)
+		return
+	}
+	policy := scheduler.RunPolicy{Blackouts: blackouts}
+	if req.JitterMS != nil {
+		policy.Jitter = time.Duration(*req.JitterMS) * time.Millisecond
+	}
 	now := time.Now().UTC()
-	nextRunAt, err := scheduler.ComputeNextRun(spec, now, nil)
+	nextRunAt, err := scheduler.ComputeNextRunWithPolicy(spec, policy, now, nil)
 	if err != nil {
 		sendJSON(w, http.StatusBadRequest, errorResponse{Error: err.Error()})
 		return
@@ -182,6 +264,16 @@ func (h *JobsHandler) Create(w http.ResponseWriter, r *http.Request) {
 		NextRunAt:    nextRunAt,
 		MaxFailures:  req.MaxFailures,
 		CreatedBy:    createdByPtr,
+
+		OverlapPolicy:       req.OverlapPolicy,
+		CatchUpPolicy:       req.CatchUpPolicy,
+		JitterMS:            req.JitterMS,
+		MaxAgentConcurrency: req.MaxAgentConcurrency,
+
+		SuccessPattern:          req.SuccessPattern,
+		SuccessJSONField:        req.SuccessJSONField,
+		SuccessClassifierPrompt: req.SuccessClassifierPrompt,
+		ReplyTimeoutMS:          req.ReplyTimeoutMS,
 	})
 	if err != nil {
 		handleJobsStoreError(w, err)
@@ -282,7 +374,16 @@ func (h *JobsHandler) Patch(w http.ResponseWriter, r *http.Request) {
 		req.PayloadText == nil &&
 		req.RoomID == nil &&
 		req.Enabled == nil &&
-		req.MaxFailures == nil {
+		req.MaxFailures == nil &&
+		req.OverlapPolicy == nil &&
+		req.CatchUpPolicy == nil &&
+		req.JitterMS == nil &&
+		req.MaxAgentConcurrency == nil &&
+		!req.ClearMaxAgentConcurrency &&
+		req.SuccessPattern == nil &&
+		req.SuccessJSONField == nil &&
+		req.SuccessClassifierPrompt == nil &&
+		req.ReplyTimeoutMS == nil {
 		sendJSON(w, http.StatusBadRequest, errorResponse{Error: "at least one field is required"})
 		return
 	}
@@ -306,6 +407,17 @@ func (h *JobsHandler) Patch(w http.ResponseWriter, r *http.Request) {
 		RoomID:       req.RoomID,
 		Enabled:      req.Enabled,
 		MaxFailures:  req.MaxFailures,
+
+		OverlapPolicy:            req.OverlapPolicy,
+		CatchUpPolicy:            req.CatchUpPolicy,
+		JitterMS:                 req.JitterMS,
+		MaxAgentConcurrency:      req.MaxAgentConcurrency,
+		ClearMaxAgentConcurrency: req.ClearMaxAgentConcurrency,
+
+		SuccessPattern:          req.SuccessPattern,
+		SuccessJSONField:        req.SuccessJSONField,
+		SuccessClassifierPrompt: req.SuccessClassifierPrompt,
+		ReplyTimeoutMS:          req.ReplyTimeoutMS,
 	})
 	if err != nil {
 		handleJobsStoreError(w, err)
@@ -321,7 +433,7 @@ func (h *JobsHandler) Patch(w http.ResponseWriter, r *http.Request) {
 			updated.Timezone,
 		)
 		if specErr == nil {
-			nextRunAt, computeErr := scheduler.ComputeNextRun(spec, time.Now().UTC(), nil)
+			nextRunAt, computeErr := h.computeNextRun(r.Context(), *updated, spec)
 			if computeErr == nil {
 				if refreshed, refreshErr := h.Store.Update(r.Context(), updated.ID, store.UpdateAgentJobInput{
 					NextRunAt: nextRunAt,
@@ -407,6 +519,64 @@ func (h *JobsHandler) ListRuns(w http.ResponseWriter, r *http.Request) {
 	sendJSON(w, http.StatusOK, jobRunsResponse{Items: payload, Total: len(payload)})
 }
 
+// ReplyToRun posts an agent's reply to a run that is waiting on one. The reply
+// lands in the job room like any agent message; the scheduler worker then
+// records it as the run's output and checks the job's success criteria.
+func (h *JobsHandler) ReplyToRun(w http.ResponseWriter, r *http.Request) {
+	if h.Store == nil || h.DB == nil {
+		sendJSON(w, http.StatusServiceUnavailable, errorResponse{Error: "database not available"})
+		return
+	}
+
+	var req replyJobRunRequest
+	decoder := json.NewDecoder(r.Body)
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&req); err != nil {
+		sendJSON(w, http.StatusBadRequest, errorResponse{Error: "invalid JSON"})
+		return
+	}
+	if strings.TrimSpace(req.Output) == "" {
+		sendJSON(w, http.StatusBadRequest, errorResponse{Error: "output is required"})
+		return
+	}
+
+	scopedAgentID, err := h.resolveScopedAgentID(r, req.SessionKey)
+	if err != nil {
+		sendJSON(w, http.StatusBadRequest, errorResponse{Error: err.Error()})
+		return
+	}
+	jobID := strings.TrimSpace(chi.URLParam(r, "id"))
+	runID := strings.TrimSpace(chi.URLParam(r, "runID"))
+	if jobID == "" || runID == "" {
+		sendJSON(w, http.StatusBadRequest, errorResponse{Error: "job id and run id are required"})
+		return
+	}
+	job, err := h.Store.GetByID(r.Context(), jobID)
+	if err != nil {
+		handleJobsStoreError(w, err)
+		return
+	}
+	if scopedAgentID != nil && job.AgentID != *scopedAgentID {
+		sendJSON(w, http.StatusForbidden, errorResponse{Error: "forbidden"})
+		return
+	}
+
+	messageID, err := h.Store.CreateJobReply(r.Context(), store.CreateAgentJobReplyInput{
+		JobID: job.ID,
+		RunID: runID,
+		Body:  req.Output,
+	})
+	if err != nil {
+		handleJobsStoreError(w, err)
+		return
+	}
+	sendJSON(w, http.StatusAccepted, map[string]any{
+		"job_id":     job.ID,
+		"run_id":     runID,
+		"message_id": messageID,
+	})
+}
+
 func (h *JobsHandler) Pause(w http.ResponseWriter, r *http.Request) {
 	job, scopedAgentID, ok := h.getAuthorizedJob(w, r)
 	if !ok {
@@ -452,7 +622,7 @@ func (h *JobsHandler) Resume(w http.ResponseWriter, r *http.Request) {
 		sendJSON(w, http.StatusBadRequest, errorResponse{Error: err.Error()})
 		return
 	}
-	nextRunAt, err := scheduler.ComputeNextRun(spec, time.Now().UTC(), nil)
+	nextRunAt, err := h.computeNextRun(r.Context(), *job, spec)
 	if err != nil {
 		sendJSON(w, http.StatusBadRequest, errorResponse{Error: err.Error()})
 		return
@@ -469,6 +639,91 @@ func (h *JobsHandler) Resume(w http.ResponseWriter, r *http.Request) {
 	sendJSON(w, http.StatusOK, toJobPayload(*updated))
 }
 
+func (h *JobsHandler) ListBlackouts(w http.ResponseWriter, r *http.Request) {
+	if h.Store == nil || h.DB == nil {
+		sendJSON(w, http.StatusServiceUnavailable, errorResponse{Error: "database not available"})
+		return
+	}
+	if strings.TrimSpace(middleware.WorkspaceFromContext(r.Context())) == "" {
+		sendJSON(w, http.StatusBadRequest, errorResponse{Error: "org_id is required"})
+		return
+	}
+
+	windows, err := h.Store.ListBlackoutWindows(r.Context())
+	if err != nil {
+		handleJobsStoreError(w, err)
+		return
+	}
+	payload := make([]jobBlackoutPayload, 0, len(windows))
+	for _, window := range windows {
+		payload = append(payload, toJobBlackoutPayload(window))
+	}
+	sendJSON(w, http.StatusOK, jobBlackoutsResponse{Items: payload, Total: len(payload)})
+}
+
+func (h *JobsHandler) CreateBlackout(w http.ResponseWriter, r *http.Request) {
+	if h.Store == nil || h.DB == nil {
+		sendJSON(w, http.StatusServiceUnavailable, errorResponse{Error: "database not available"})
+		return
+	}
+	if strings.TrimSpace(middleware.WorkspaceFromContext(r.Context())) == "" {
+		sendJSON(w, http.StatusBadRequest, errorResponse{Error: "org_id is required"})
+		return
+	}
+
+	var req createJobBlackoutRequest
+	decoder := json.NewDecoder(r.Body)
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&req); err != nil {
+		sendJSON(w, http.StatusBadRequest, errorResponse{Error: "invalid JSON"})
+		return
+	}
+
+	window, err := h.Store.CreateBlackoutWindow(r.Context(), store.CreateAgentJobBlackoutWindowInput{
+		Name:        req.Name,
+		DaysOfWeek:  req.DaysOfWeek,
+		StartDate:   req.StartDate,
+		EndDate:     req.EndDate,
+		StartMinute: req.StartMinute,
+		EndMinute:   req.EndMinute,
+	})
+	if err != nil {
+		handleJobsStoreError(w, err)
+		return
+	}
+	sendJSON(w, http.StatusCreated, toJobBlackoutPayload(*window))
+}
+
+func (h *JobsHandler) DeleteBlackout(w http.ResponseWriter, r *http.Request) {
+	if h.Store == nil || h.DB == nil {
+		sendJSON(w, http.StatusServiceUnavailable, errorResponse{Error: "database not available"})
+		return
+	}
+	if strings.TrimSpace(middleware.WorkspaceFromContext(r.Context())) == "" {
+		sendJSON(w, http.StatusBadRequest, errorResponse{Error: "org_id is required"})
+		return
+	}
+
+	windowID := strings.TrimSpace(chi.URLParam(r, "id"))
+	if err := h.Store.DeleteBlackoutWindow(r.Context(), windowID); err != nil {
+		handleJobsStoreError(w, err)
+		return
+	}
+	sendJSON(w, http.StatusOK, map[string]any{"deleted": true, "id": windowID})
+}
+
+func (h *JobsHandler) computeNextRun(
+	ctx context.Context,
+	job store.AgentJob,
+	spec scheduler.ScheduleSpec,
+) (*time.Time, error) {
+	blackouts, err := h.Store.ListBlackoutWindows(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return scheduler.ComputeNextRunWithPolicy(spec, scheduler.RunPolicyForJob(job, blackouts), time.Now().UTC(), nil)
+}
+
 func (h *JobsHandler) ImportOpenClawCron(w http.ResponseWriter, r *http.Request) {
 	if h.DB == nil {
 		sendJSON(w, http.StatusServiceUnavailable, errorResponse{Error: "database not available"})
@@ -607,9 +862,19 @@ func toJobPayload(job store.AgentJob) jobPayload {
 		ErrorCount:          job.ErrorCount,
 		MaxFailures:         job.MaxFailures,
 		ConsecutiveFailures: job.ConsecutiveFailures,
-		CreatedBy:           job.CreatedBy,
-		CreatedAt:           job.CreatedAt.UTC().Format(time.RFC3339),
-		UpdatedAt:           job.UpdatedAt.UTC().Format(time.RFC3339),
+		OverlapPolicy:       job.OverlapPolicy,
+		CatchUpPolicy:       job.CatchUpPolicy,
+		JitterMS:            job.JitterMS,
+		MaxAgentConcurrency: job.MaxAgentConcurrency,
+
+		SuccessPattern:          job.SuccessPattern,
+		SuccessJSONField:        job.SuccessJSONField,
+		SuccessClassifierPrompt: job.SuccessClassifierPrompt,
+		ReplyTimeoutMS:          job.ReplyTimeoutMS,
+
+		CreatedBy: job.CreatedBy,
+		CreatedAt: job.CreatedAt.UTC().Format(time.RFC3339),
+		UpdatedAt: job.UpdatedAt.UTC().Format(time.RFC3339),
 	}
 }
 
@@ -626,6 +891,29 @@ func toJobRunPayload(run store.AgentJobRun) jobRunPayload {
 		PayloadText: run.PayloadText,
 		MessageID:   run.MessageID,
 		CreatedAt:   run.CreatedAt.UTC().Format(time.RFC3339),
+
+		Output:          run.Output,
+		ReplyMessageID:  run.ReplyMessageID,
+		ReplyDeadlineAt: formatOptionalTime(run.ReplyDeadlineAt),
+	}
+}
+
+func toJobBlackoutPayload(window store.AgentJobBlackoutWindow) jobBlackoutPayload {
+	days := window.DaysOfWeek
+	if days == nil {
+		days = []int{}
+	}
+	return jobBlackoutPayload{
+		ID:          window.ID,
+		OrgID:       window.OrgID,
+		Name:        window.Name,
+		DaysOfWeek:  days,
+		StartDate:   window.StartDate,
+		EndDate:     window.EndDate,
+		StartMinute: window.StartMinute,
+		EndMinute:   window.EndMinute,
+		CreatedAt:   window.CreatedAt.UTC().Format(time.RFC3339),
+		UpdatedAt:   window.UpdatedAt.UTC().Format(time.RFC3339),
 	}
 }
 
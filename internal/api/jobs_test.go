@@ -2,10 +2,12 @@ package api
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
 	"github.com/go-chi/chi/v5"
 	"github.com/samhotchkiss/otter-camp/internal/middleware"
@@ -17,11 +19,15 @@ func jobsTestRouter(handler *JobsHandler) http.Handler {
 	r := chi.NewRouter()
 	r.With(middleware.OptionalWorkspace).Post("/api/v1/jobs", handler.Create)
 	r.With(middleware.OptionalWorkspace).Get("/api/v1/jobs", handler.List)
+	r.With(middleware.OptionalWorkspace).Get("/api/v1/jobs/blackouts", handler.ListBlackouts)
+	r.With(middleware.OptionalWorkspace).Post("/api/v1/jobs/blackouts", handler.CreateBlackout)
+	r.With(middleware.OptionalWorkspace).Delete("/api/v1/jobs/blackouts/{id}", handler.DeleteBlackout)
 	r.With(middleware.OptionalWorkspace).Get("/api/v1/jobs/{id}", handler.Get)
 	r.With(middleware.OptionalWorkspace).Patch("/api/v1/jobs/{id}", handler.Patch)
 	r.With(middleware.OptionalWorkspace).Delete("/api/v1/jobs/{id}", handler.Delete)
 	r.With(middleware.OptionalWorkspace).Post("/api/v1/jobs/{id}/run", handler.RunNow)
 	r.With(middleware.OptionalWorkspace).Get("/api/v1/jobs/{id}/runs", handler.ListRuns)
+	r.With(middleware.OptionalWorkspace).Post("/api/v1/jobs/{id}/runs/{runID}/reply", handler.ReplyToRun)
 	r.With(middleware.OptionalWorkspace).Post("/api/v1/jobs/{id}/pause", handler.Pause)
 	r.With(middleware.OptionalWorkspace).Post("/api/v1/jobs/{id}/resume", handler.Resume)
 	return r
@@ -120,6 +126,224 @@ func TestJobsHandlerCreateListGetPatchPauseResumeRunDelete(t *testing.T) {
 	require.Equal(t, http.StatusNotFound, getAfterDeleteRec.Code)
 }
 
+func TestJobsHandlerPoliciesAndBlackouts(t *testing.T) {
+	db := setupMessageTestDB(t)
+	orgID := insertMessageTestOrganization(t, db, "jobs-handler-policies")
+	agentID := insertMessageTestAgent(t, db, orgID, "jobs-policy-agent")
+
+	handler := &JobsHandler{Store: store.NewAgentJobStore(db), DB: db}
+	router := jobsTestRouter(handler)
+
+	blackoutBody := []byte(`{"name":"Weekends","days_of_week":[6,0]}`)
+	blackoutReq := httptest.NewRequest(http.MethodPost, "/api/v1/jobs/blackouts?org_id="+orgID, bytes.NewReader(blackoutBody))
+	blackoutReq.Header.Set("Content-Type", "application/json")
+	blackoutRec := httptest.NewRecorder()
+	router.ServeHTTP(blackoutRec, blackoutReq)
+	require.Equal(t, http.StatusCreated, blackoutRec.Code)
+	var blackout jobBlackoutPayload
+	require.NoError(t, json.NewDecoder(blackoutRec.Body).Decode(&blackout))
+	require.Equal(t, []int{0, 6}, blackout.DaysOfWeek)
+
+	invalidBlackoutReq := httptest.NewRequest(http.MethodPost, "/api/v1/jobs/blackouts?org_id="+orgID, bytes.NewReader([]byte(`{"name":"Unbounded"}`)))
+	invalidBlackoutReq.Header.Set("Content-Type", "application/json")
+	invalidBlackoutRec := httptest.NewRecorder()
+	router.ServeHTTP(invalidBlackoutRec, invalidBlackoutReq)
+	require.Equal(t, http.StatusBadRequest, invalidBlackoutRec.Code)
+
+	listBlackoutsReq := httptest.NewRequest(http.MethodGet, "/api/v1/jobs/blackouts?org_id="+orgID, nil)
+	listBlackoutsRec := httptest.NewRecorder()
+	router.ServeHTTP(listBlackoutsRec, listBlackoutsReq)
+	require.Equal(t, http.StatusOK, listBlackoutsRec.Code)
+	var blackouts jobBlackoutsResponse
+	require.NoError(t, json.NewDecoder(listBlackoutsRec.Body).Decode(&blackouts))
+	require.Len(t, blackouts.Items, 1)
+
+	createBody := []byte(`{
+		"agent_id":"` + agentID + `",
+		"name":"Weekday digest",
+		"schedule_kind":"cron",
+		"cron_expr":"0 9 * * *",
+		"timezone":"UTC",
+		"payload_kind":"message",
+		"payload_text":"digest",
+		"overlap_policy":"queue",
+		"catch_up_policy":"skip",
+		"jitter_ms":1000,
+		"max_agent_concurrency":2
+	}`)
+	createReq := httptest.NewRequest(http.MethodPost, "/api/v1/jobs?org_id="+orgID, bytes.NewReader(createBody))
+	createReq.Header.Set("Content-Type", "application/json")
+	createRec := httptest.NewRecorder()
+	router.ServeHTTP(createRec, createReq)
+	require.Equal(t, http.StatusCreated, createRec.Code)
+	var created jobPayload
+	require.NoError(t, json.NewDecoder(createRec.Body).Decode(&created))
+	require.Equal(t, "queue", created.OverlapPolicy)
+	require.Equal(t, "skip", created.CatchUpPolicy)
+	require.Equal(t, int64(1000), created.JitterMS)
+	require.NotNil(t, created.MaxAgentConcurrency)
+	require.Equal(t, 2, *created.MaxAgentConcurrency)
+	require.NotNil(t, created.NextRunAt)
+	nextRunAt, err := time.Parse(time.RFC3339, *created.NextRunAt)
+	require.NoError(t, err)
+	require.NotEqual(t, time.Saturday, nextRunAt.UTC().Weekday())
+	require.NotEqual(t, time.Sunday, nextRunAt.UTC().Weekday())
+
+	invalidPolicyBody := []byte(`{
+		"agent_id":"` + agentID + `",
+		"name":"Bad policy",
+		"schedule_kind":"interval",
+		"interval_ms":60000,
+		"timezone":"UTC",
+		"payload_kind":"message",
+		"payload_text":"bad",
+		"overlap_policy":"parallel"
+	}`)
+	invalidPolicyReq := httptest.NewRequest(http.MethodPost, "/api/v1/jobs?org_id="+orgID, bytes.NewReader(invalidPolicyBody))
+	invalidPolicyReq.Header.Set("Content-Type", "application/json")
+	invalidPolicyRec := httptest.NewRecorder()
+	router.ServeHTTP(invalidPolicyRec, invalidPolicyReq)
+	require.Equal(t, http.StatusBadRequest, invalidPolicyRec.Code)
+
+	deleteBlackoutReq := httptest.NewRequest(http.MethodDelete, "/api/v1/jobs/blackouts/"+blackout.ID+"?org_id="+orgID, nil)
+	deleteBlackoutRec := httptest.NewRecorder()
+	router.ServeHTTP(deleteBlackoutRec, deleteBlackoutReq)
+	require.Equal(t, http.StatusOK, deleteBlackoutRec.Code)
+
+	deleteAgainRec := httptest.NewRecorder()
+	router.ServeHTTP(deleteAgainRec, httptest.NewRequest(http.MethodDelete, "/api/v1/jobs/blackouts/"+blackout.ID+"?org_id="+orgID, nil))
+	require.Equal(t, http.StatusNotFound, deleteAgainRec.Code)
+}
+
+func TestJobsHandlerSuccessCriteriaAndRunReply(t *testing.T) {
+	db := setupMessageTestDB(t)
+	orgID := insertMessageTestOrganization(t, db, "jobs-handler-reply")
+	agentID := insertMessageTestAgent(t, db, orgID, "jobs-reply-agent")
+	otherAgentID := insertMessageTestAgent(t, db, orgID, "jobs-reply-other")
+
+	jobStore := store.NewAgentJobStore(db)
+	handler := &JobsHandler{Store: jobStore, DB: db}
+	router := jobsTestRouter(handler)
+
+	createBody := []byte(`{
+		"agent_id":"` + agentID + `",
+		"name":"Nightly backup",
+		"schedule_kind":"interval",
+		"interval_ms":3600000,
+		"timezone":"UTC",
+		"payload_kind":"message",
+		"payload_text":"run the backup",
+		"success_pattern":"backup (ok|complete)",
+		"success_json_field":"result.files",
+		"reply_timeout_ms":600000
+	}`)
+	createReq := httptest.NewRequest(http.MethodPost, "/api/v1/jobs?org_id="+orgID, bytes.NewReader(createBody))
+	createReq.Header.Set("Content-Type", "application/json")
+	createRec := httptest.NewRecorder()
+	router.ServeHTTP(createRec, createReq)
+	require.Equal(t, http.StatusCreated, createRec.Code)
+	var created jobPayload
+	require.NoError(t, json.NewDecoder(createRec.Body).Decode(&created))
+	require.NotNil(t, created.SuccessPattern)
+	require.Equal(t, "backup (ok|complete)", *created.SuccessPattern)
+	require.NotNil(t, created.SuccessJSONField)
+	require.Equal(t, "result.files", *created.SuccessJSONField)
+	require.NotNil(t, created.ReplyTimeoutMS)
+	require.Equal(t, int64(600000), *created.ReplyTimeoutMS)
+
+	invalidPatternReq := httptest.NewRequest(http.MethodPatch, "/api/v1/jobs/"+created.ID+"?org_id="+orgID, bytes.NewReader([]byte(`{"success_pattern":"(unclosed"}`)))
+	invalidPatternReq.Header.Set("Content-Type", "application/json")
+	invalidPatternRec := httptest.NewRecorder()
+	router.ServeHTTP(invalidPatternRec, invalidPatternReq)
+	require.Equal(t, http.StatusBadRequest, invalidPatternRec.Code)
+
+	clearReq := httptest.NewRequest(http.MethodPatch, "/api/v1/jobs/"+created.ID+"?org_id="+orgID, bytes.NewReader([]byte(`{"success_json_field":""}`)))
+	clearReq.Header.Set("Content-Type", "application/json")
+	clearRec := httptest.NewRecorder()
+	router.ServeHTTP(clearRec, clearReq)
+	require.Equal(t, http.StatusOK, clearRec.Code)
+	var cleared jobPayload
+	require.NoError(t, json.NewDecoder(clearRec.Body).Decode(&cleared))
+	require.Nil(t, cleared.SuccessJSONField)
+	require.NotNil(t, cleared.SuccessPattern)
+
+	ctx := context.WithValue(context.Background(), middleware.WorkspaceIDKey, orgID)
+	roomID, err := jobStore.EnsureRoomForJob(ctx, created.ID)
+	require.NoError(t, err)
+	startedAt := time.Now().UTC()
+	run, err := jobStore.StartRun(ctx, store.StartAgentJobRunInput{
+		JobID:       created.ID,
+		PayloadText: "run the backup",
+		StartedAt:   startedAt,
+	})
+	require.NoError(t, err)
+	promptID, err := jobStore.CreateJobMessage(ctx, store.CreateAgentJobMessageInput{
+		JobID:       created.ID,
+		OrgID:       orgID,
+		RoomID:      roomID,
+		PayloadKind: store.AgentJobPayloadMessage,
+		PayloadText: "run the backup",
+		CreatedAt:   startedAt,
+	})
+	require.NoError(t, err)
+	require.NoError(t, jobStore.MarkRunAwaitingReply(ctx, store.AwaitAgentJobReplyInput{
+		JobID:           created.ID,
+		RunID:           run.ID,
+		MessageID:       promptID,
+		ReplyDeadlineAt: startedAt.Add(10 * time.Minute),
+	}))
+
+	replyPath := "/api/v1/jobs/" + created.ID + "/runs/" + run.ID + "/reply?org_id=" + orgID
+
+	emptyReq := httptest.NewRequest(http.MethodPost, replyPath, bytes.NewReader([]byte(`{"output":"  "}`)))
+	emptyReq.Header.Set("Content-Type", "application/json")
+	emptyRec := httptest.NewRecorder()
+	router.ServeHTTP(emptyRec, emptyReq)
+	require.Equal(t, http.StatusBadRequest, emptyRec.Code)
+
+	otherSession := "agent:chameleon:oc:" + otherAgentID
+	forbiddenReq := httptest.NewRequest(http.MethodPost, replyPath, bytes.NewReader([]byte(`{"output":"backup ok","session_key":"`+otherSession+`"}`)))
+	forbiddenReq.Header.Set("Content-Type", "application/json")
+	forbiddenRec := httptest.NewRecorder()
+	router.ServeHTTP(forbiddenRec, forbiddenReq)
+	require.Equal(t, http.StatusForbidden, forbiddenRec.Code)
+
+	session := "agent:chameleon:oc:" + agentID
+	replyReq := httptest.NewRequest(http.MethodPost, replyPath, bytes.NewReader([]byte(`{"output":"backup ok","session_key":"`+session+`"}`)))
+	replyReq.Header.Set("Content-Type", "application/json")
+	replyRec := httptest.NewRecorder()
+	router.ServeHTTP(replyRec, replyReq)
+	require.Equal(t, http.StatusAccepted, replyRec.Code)
+
+	replies, err := jobStore.ListRunReplies(ctx, 10)
+	require.NoError(t, err)
+	require.Len(t, replies, 1)
+	require.Equal(t, run.ID, replies[0].Run.ID)
+	require.Equal(t, "backup ok", replies[0].Reply.Body)
+
+	runsReq := httptest.NewRequest(http.MethodGet, "/api/v1/jobs/"+created.ID+"/runs?org_id="+orgID, nil)
+	runsRec := httptest.NewRecorder()
+	router.ServeHTTP(runsRec, runsReq)
+	require.Equal(t, http.StatusOK, runsRec.Code)
+	var runs jobRunsResponse
+	require.NoError(t, json.NewDecoder(runsRec.Body).Decode(&runs))
+	require.Len(t, runs.Items, 1)
+	require.Equal(t, store.AgentJobRunStatusRunning, runs.Items[0].Status)
+	require.NotNil(t, runs.Items[0].ReplyDeadlineAt)
+
+	_, err = jobStore.CompleteRun(ctx, store.CompleteAgentJobRunInput{
+		JobID:     created.ID,
+		RunID:     run.ID,
+		RunStatus: store.AgentJobRunStatusSuccess,
+	})
+	require.NoError(t, err)
+	closedRec := httptest.NewRecorder()
+	closedReq := httptest.NewRequest(http.MethodPost, replyPath, bytes.NewReader([]byte(`{"output":"late"}`)))
+	closedReq.Header.Set("Content-Type", "application/json")
+	router.ServeHTTP(closedRec, closedReq)
+	require.Equal(t, http.StatusConflict, closedRec.Code)
+}
+
 func TestJobsHandlerAgentSelfScoping(t *testing.T) {
 	db := setupMessageTestDB(t)
 	orgID := insertMessageTestOrganization(t, db, "jobs-handler-scope")
@@ -250,8 +474,12 @@ func TestRouterRegistersJobsRoutes(t *testing.T) {
 		{method: http.MethodDelete, path: "/api/v1/jobs/" + jobID + "?org_id=" + orgID},
 		{method: http.MethodPost, path: "/api/v1/jobs/" + jobID + "/run?org_id=" + orgID},
 		{method: http.MethodGet, path: "/api/v1/jobs/" + jobID + "/runs?org_id=" + orgID},
+		{method: http.MethodPost, path: "/api/v1/jobs/" + jobID + "/runs/" + jobID + "/reply?org_id=" + orgID, body: []byte(`{}`)},
 		{method: http.MethodPost, path: "/api/v1/jobs/" + jobID + "/pause?org_id=" + orgID},
 		{method: http.MethodPost, path: "/api/v1/jobs/" + jobID + "/resume?org_id=" + orgID},
+		{method: http.MethodGet, path: "/api/v1/jobs/blackouts?org_id=" + orgID},
+		{method: http.MethodPost, path: "/api/v1/jobs/blackouts?org_id=" + orgID, body: []byte(`{}`)},
+		{method: http.MethodDelete, path: "/api/v1/jobs/blackouts/" + jobID + "?org_id=" + orgID},
 	}
 
 	for _, tc := range reqs {
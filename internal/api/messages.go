@@ -18,6 +18,7 @@ import (
 	"github.com/go-chi/chi/v5"
 	"github.com/samhotchkiss/otter-camp/internal/middleware"
 	"github.com/samhotchkiss/otter-camp/internal/store"
+	"github.com/samhotchkiss/otter-camp/internal/tracing"
 	"github.com/samhotchkiss/otter-camp/internal/ws"
 )
 
@@ -80,6 +81,8 @@ type openClawDMDispatchEvent struct {
 	Timestamp time.Time              `json:"timestamp"`
 	OrgID     string                 `json:"org_id"`
 	Data      openClawDMDispatchData `json:"data"`
+	// Traceparent carries W3C trace context to the bridge.
+	Traceparent string `json:"traceparent,omitempty"`
 }
 
 type openClawDMDispatchAttachment struct {
@@ -220,6 +223,12 @@ func (h *MessageHandler) CreateMessage(w http.ResponseWriter, r *http.Request) {
 		sendJSON(w, statusCode, errorResponse{Error: dispatchErr.Error()})
 		return
 	}
+	if shouldDispatch {
+		if warning := usageBudgetDispatchWarning(r.Context(), db, orgID, dispatchTarget.AgentID, ""); warning != "" {
+			shouldDispatch = false
+			dispatchWarning = warning
+		}
+	}
 
 	// Server-side dedup: reject duplicate agent messages (same thread + content within 30s)
 	if req.ThreadID != nil && req.SenderType != nil && *req.SenderType == "agent" {
@@ -1451,9 +1460,10 @@ func (h *MessageHandler) buildDMDispatchEvent(
 	}
 
 	event := openClawDMDispatchEvent{
-		Type:      "dm.message",
-		Timestamp: time.Now().UTC(),
-		OrgID:     orgID,
+		Type:        "dm.message",
+		Timestamp:   time.Now().UTC(),
+		OrgID:       orgID,
+		Traceparent: tracing.Traceparent(ctx),
 		Data: openClawDMDispatchData{
 			MessageID:  messageID,
 			ThreadID:   threadID,
@@ -0,0 +1,195 @@
+package api
+
+import (
+	"context"
+	"crypto/subtle"
+	"database/sql"
+	"log"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	chimiddleware "github.com/go-chi/chi/v5/middleware"
+	"github.com/samhotchkiss/otter-camp/internal/metrics"
+	"github.com/samhotchkiss/otter-camp/internal/store"
+	"github.com/samhotchkiss/otter-camp/internal/syncmetrics"
+	"github.com/samhotchkiss/otter-camp/internal/ws"
+)
+
+const metricsCollectTimeout = 2 * time.Second
+
+// HTTPMetrics records latency and status for every request, labelled by the
+// matched route pattern so IDs in paths don't explode cardinality.
+func HTTPMetrics(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		started := time.Now()
+		ww := chimiddleware.NewWrapResponseWriter(w, r.ProtoMajor)
+		next.ServeHTTP(ww, r)
+
+		status := ww.Status()
+		if status == 0 {
+			status = http.StatusOK
+		}
+		metrics.ObserveHTTPRequest(r.Method, metricsRoutePattern(r), status, time.Since(started))
+	})
+}
+
+func metricsRoutePattern(r *http.Request) string {
+	if rctx := chi.RouteContext(r.Context()); rctx != nil {
+		if pattern := rctx.RoutePattern(); pattern != "" {
+			return pattern
+		}
+	}
+	return "unmatched"
+}
+
+// metricsHandler serves /metrics. When OTTER_METRICS_TOKEN is set scrapers
+// must send it as a bearer token.
+func metricsHandler() http.HandlerFunc {
+	serve := metrics.Handler()
+	return func(w http.ResponseWriter, r *http.Request) {
+		if token := strings.TrimSpace(os.Getenv("OTTER_METRICS_TOKEN")); token != "" {
+			presented := strings.TrimSpace(strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer "))
+			if subtle.ConstantTimeCompare([]byte(presented), []byte(token)) != 1 {
+				w.Header().Set("WWW-Authenticate", `Bearer realm="metrics"`)
+				http.Error(w, "unauthorized", http.StatusUnauthorized)
+				return
+			}
+		}
+		serve.ServeHTTP(w, r)
+	}
+}
+
+// registerRuntimeMetrics adds the gauges that are read at scrape time:
+// queue depths, websocket clients, bridge state and GitHub sync counters.
+func registerRuntimeMetrics(db *sql.DB, hub *ws.Hub, bridge *ws.OpenClawHandler) {
+	registry := metrics.Default
+
+	registry.RegisterFunc("otter_websocket_clients", "Connected websocket clients.", metrics.KindGauge, nil, func() []metrics.Sample {
+		if hub == nil {
+			return nil
+		}
+		return []metrics.Sample{{Value: float64(hub.ClientCount())}}
+	})
+
+	registry.RegisterFunc("otter_openclaw_bridge_connected", "1 when the OpenClaw bridge websocket is connected.", metrics.KindGauge, nil, func() []metrics.Sample {
+		connected := 0.0
+		if bridge != nil && bridge.IsConnected() {
+			connected = 1
+		}
+		return []metrics.Sample{{Value: connected}}
+	})
+	registry.RegisterFunc("otter_openclaw_bridge_last_sync_age_seconds", "Seconds since the bridge last pushed a sync.", metrics.KindGauge, nil, func() []metrics.Sample {
+		last := memoryLastSyncSnapshot()
+		if last == nil {
+			return nil
+		}
+		return []metrics.Sample{{Value: time.Since(*last).Seconds()}}
+	})
+
+	registerSyncJobMetrics(registry)
+
+	if db == nil {
+		return
+	}
+	registry.RegisterFunc("otter_github_sync_queue_depth", "GitHub sync jobs by type and status.", metrics.KindGauge, []string{"job_type", "status"}, func() []metrics.Sample {
+		return collectGroupedCounts(db, "github sync queue", `SELECT job_type, status, COUNT(*)
+			 FROM github_sync_jobs
+			 WHERE status IN ('queued', 'retrying', 'in_progress', 'dead_letter')
+			 GROUP BY job_type, status`)
+	})
+	registry.RegisterFunc("otter_openclaw_dispatch_queue_depth", "OpenClaw dispatch queue events awaiting delivery, by status.", metrics.KindGauge, []string{"status"}, func() []metrics.Sample {
+		return collectGroupedCounts(db, "openclaw dispatch queue", `SELECT status, COUNT(*)
+			 FROM openclaw_dispatch_queue
+			 WHERE status IN ('pending', 'processing')
+			 GROUP BY status`)
+	})
+	ingestionStore := store.NewEllieIngestionStore(db)
+	registry.RegisterFunc("otter_ellie_ingestion_backlog_rooms", "Rooms with messages past their Ellie ingestion cursor.", metrics.KindGauge, nil, func() []metrics.Sample {
+		ctx, cancel := context.WithTimeout(context.Background(), metricsCollectTimeout)
+		defer cancel()
+		count, err := ingestionStore.CountRoomsForIngestionAllOrgs(ctx)
+		if err != nil {
+			log.Printf("[metrics] ingestion backlog: %v", err)
+			return nil
+		}
+		return []metrics.Sample{{Value: float64(count)}}
+	})
+}
+
+func registerSyncJobMetrics(registry *metrics.Registry) {
+	registry.RegisterFunc("otter_github_sync_jobs_total", "GitHub sync job lifecycle events by job type.", metrics.KindCounter, []string{"job_type", "event"}, func() []metrics.Sample {
+		snapshot := syncmetrics.SnapshotNow()
+		samples := make([]metrics.Sample, 0, len(snapshot.Jobs)*6)
+		for jobType, job := range snapshot.Jobs {
+			for event, value := range map[string]int64{
+				"picked":      job.PickedTotal,
+				"success":     job.SuccessTotal,
+				"failure":     job.FailureTotal,
+				"retry":       job.RetryTotal,
+				"dead_letter": job.DeadLetterTotal,
+				"replay":      job.ReplayTotal,
+			} {
+				samples = append(samples, metrics.Sample{Labels: []string{jobType, event}, Value: float64(value)})
+			}
+		}
+		return samples
+	})
+	registry.RegisterFunc("otter_github_quota_remaining", "Last reported GitHub API quota remaining by job type.", metrics.KindGauge, []string{"job_type"}, func() []metrics.Sample {
+		snapshot := syncmetrics.SnapshotNow()
+		samples := make([]metrics.Sample, 0, len(snapshot.Quota))
+		for jobType, quota := range snapshot.Quota {
+			samples = append(samples, metrics.Sample{Labels: []string{jobType}, Value: float64(quota.Remaining)})
+		}
+		return samples
+	})
+	registry.RegisterFunc("otter_github_throttle_events_total", "GitHub rate-limit throttles by job type.", metrics.KindCounter, []string{"job_type"}, func() []metrics.Sample {
+		snapshot := syncmetrics.SnapshotNow()
+		samples := make([]metrics.Sample, 0, len(snapshot.Quota))
+		for jobType, quota := range snapshot.Quota {
+			samples = append(samples, metrics.Sample{Labels: []string{jobType}, Value: float64(quota.ThrottleEvents)})
+		}
+		return samples
+	})
+}
+
+// collectGroupedCounts runs a query whose last column is a count and whose
+// leading columns are label values.
+func collectGroupedCounts(db *sql.DB, name, query string) []metrics.Sample {
+	ctx, cancel := context.WithTimeout(context.Background(), metricsCollectTimeout)
+	defer cancel()
+
+	rows, err := db.QueryContext(ctx, query)
+	if err != nil {
+		log.Printf("[metrics] %s: %v", name, err)
+		return nil
+	}
+	defer rows.Close()
+
+	columns, err := rows.Columns()
+	if err != nil || len(columns) == 0 {
+		return nil
+	}
+	var samples []metrics.Sample
+	for rows.Next() {
+		labels := make([]string, len(columns)-1)
+		var count int64
+		dest := make([]any, 0, len(columns))
+		for i := range labels {
+			dest = append(dest, &labels[i])
+		}
+		dest = append(dest, &count)
+		if err := rows.Scan(dest...); err != nil {
+			log.Printf("[metrics] %s: %v", name, err)
+			return nil
+		}
+		samples = append(samples, metrics.Sample{Labels: labels, Value: float64(count)})
+	}
+	if err := rows.Err(); err != nil {
+		log.Printf("[metrics] %s: %v", name, err)
+		return nil
+	}
+	return samples
+}
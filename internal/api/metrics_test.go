@@ -0,0 +1,60 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/samhotchkiss/otter-camp/internal/ws"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHTTPMetricsLabelsByRoutePattern(t *testing.T) {
+	r := chi.NewRouter()
+	r.Use(HTTPMetrics)
+	r.Route("/api", func(r chi.Router) {
+		r.Get("/metrics-test/{id}", func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusTeapot)
+		})
+	})
+	r.Get("/metrics", metricsHandler())
+
+	r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/api/metrics-test/123", nil))
+	r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/api/metrics-test/456", nil))
+	r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/nope", nil))
+
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	require.Equal(t, http.StatusOK, rec.Code)
+	body := rec.Body.String()
+	require.Contains(t, body, `otter_http_request_duration_seconds_count{method="GET",route="/api/metrics-test/{id}",status="418"} 2`)
+	require.Contains(t, body, `route="unmatched",status="404"`)
+	require.NotContains(t, body, "/api/metrics-test/123")
+}
+
+func TestMetricsHandlerRequiresConfiguredToken(t *testing.T) {
+	t.Setenv("OTTER_METRICS_TOKEN", "scrape-secret")
+
+	rec := httptest.NewRecorder()
+	metricsHandler()(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	require.Equal(t, http.StatusUnauthorized, rec.Code)
+
+	req := httptest.NewRequest(http.MethodGet, "/metrics", nil)
+	req.Header.Set("Authorization", "Bearer scrape-secret")
+	rec = httptest.NewRecorder()
+	metricsHandler()(rec, req)
+	require.Equal(t, http.StatusOK, rec.Code)
+}
+
+func TestRuntimeMetricsReportHubAndBridgeWithoutDatabase(t *testing.T) {
+	hub := ws.NewHub()
+	registerRuntimeMetrics(nil, hub, ws.NewOpenClawHandler(hub, nil))
+
+	rec := httptest.NewRecorder()
+	metricsHandler()(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	body := rec.Body.String()
+	require.True(t, strings.Contains(body, "otter_websocket_clients 0\n"), body)
+	require.Contains(t, body, "otter_openclaw_bridge_connected 0\n")
+}
@@ -8,6 +8,9 @@ import (
 	"fmt"
 	"strings"
 	"sync"
+	"time"
+
+	"github.com/samhotchkiss/otter-camp/internal/tracing"
 )
 
 const (
@@ -20,11 +23,12 @@ const (
 )
 
 type openClawDispatchJob struct {
-	ID         int64
-	EventType  string
-	Payload    json.RawMessage
-	ClaimToken string
-	Attempts   int
+	ID          int64
+	EventType   string
+	Payload     json.RawMessage
+	ClaimToken  string
+	Attempts    int
+	Traceparent string
 }
 
 type openClawDispatchAckResult struct {
@@ -73,6 +77,8 @@ func ensureOpenClawDispatchQueueSchema(ctx context.Context, db *sql.DB) error {
 			`ALTER TABLE openclaw_dispatch_queue
 				ADD CONSTRAINT openclaw_dispatch_queue_status_check
 				CHECK (status IN ('pending', 'processing', 'delivered', 'failed'))`,
+			`ALTER TABLE openclaw_dispatch_queue
+				ADD COLUMN IF NOT EXISTS traceparent TEXT`,
 		}
 		for _, statement := range statements {
 			if _, err := db.ExecContext(ctx, statement); err != nil {
@@ -109,6 +115,9 @@ func enqueueOpenClawDispatchEvent(
 	if err := ensureOpenClawDispatchQueueSchema(ctx, db); err != nil {
 		return false, err
 	}
+	ctx, span := tracing.StartKind(ctx, "openclaw.dispatch_queue.enqueue", tracing.SpanKindProducer)
+	defer span.End()
+	span.SetAttribute("openclaw.event_type", strings.TrimSpace(eventType))
 
 	trimmedOrgID := strings.TrimSpace(orgID)
 	trimmedEventType := strings.TrimSpace(eventType)
@@ -132,15 +141,18 @@ func enqueueOpenClawDispatchEvent(
 			status,
 			available_at,
 			created_at,
-			updated_at
-		) VALUES ($1, $2, $3, $4, 'pending', NOW(), NOW(), NOW())
+			updated_at,
+			traceparent
+		) VALUES ($1, $2, $3, $4, 'pending', NOW(), NOW(), NOW(), NULLIF($5, ''))
 		ON CONFLICT (dedupe_key) DO NOTHING`,
 		trimmedOrgID,
 		trimmedEventType,
 		trimmedDedupeKey,
 		payload,
+		tracing.Traceparent(ctx),
 	)
 	if err != nil {
+		span.RecordError(err)
 		return false, err
 	}
 
@@ -215,7 +227,8 @@ func claimOpenClawDispatchJobs(
 		    updated_at = NOW()
 		FROM candidates c
 		WHERE q.id = c.id
-		RETURNING q.id, q.event_type, q.payload, q.claim_token, q.attempts`,
+		RETURNING q.id, q.event_type, q.payload, q.claim_token, q.attempts,
+		          COALESCE(q.traceparent, ''), q.created_at`,
 		limit,
 		openClawDispatchClaimTTLSeconds,
 	)
@@ -227,9 +240,11 @@ func claimOpenClawDispatchJobs(
 	jobs := make([]openClawDispatchJob, 0, limit)
 	for rows.Next() {
 		var job openClawDispatchJob
-		if err := rows.Scan(&job.ID, &job.EventType, &job.Payload, &job.ClaimToken, &job.Attempts); err != nil {
+		var createdAt time.Time
+		if err := rows.Scan(&job.ID, &job.EventType, &job.Payload, &job.ClaimToken, &job.Attempts, &job.Traceparent, &createdAt); err != nil {
 			return nil, err
 		}
+		traceOpenClawDispatchWait(ctx, job, createdAt)
 		jobs = append(jobs, job)
 	}
 	if err := rows.Err(); err != nil {
@@ -260,6 +275,8 @@ func ackOpenClawDispatchJob(
 	}
 
 	var err error
+	var traceparent string
+	defer func() { traceOpenClawDispatchAck(ctx, traceparent, id, success, err) }()
 
 	if success {
 		err = db.QueryRowContext(
@@ -274,10 +291,10 @@ func ackOpenClawDispatchJob(
 			 WHERE id = $1
 			   AND claim_token = $2
 			   AND status = 'processing'
-			 RETURNING org_id, event_type, payload, status`,
+			 RETURNING org_id, event_type, payload, status, COALESCE(traceparent, '')`,
 			id,
 			claimToken,
-		).Scan(&result.OrgID, &result.EventType, &result.Payload, &result.Status)
+		).Scan(&result.OrgID, &result.EventType, &result.Payload, &result.Status, &traceparent)
 	} else {
 		err = db.QueryRowContext(
 			ctx,
@@ -300,12 +317,12 @@ func ackOpenClawDispatchJob(
 			 WHERE id = $1
 			   AND claim_token = $2
 			   AND status = 'processing'
-			 RETURNING org_id, event_type, payload, status`,
+			 RETURNING org_id, event_type, payload, status, COALESCE(traceparent, '')`,
 			id,
 			claimToken,
 			strings.TrimSpace(lastError),
 			openClawDispatchMaxAttempts,
-		).Scan(&result.OrgID, &result.EventType, &result.Payload, &result.Status)
+		).Scan(&result.OrgID, &result.EventType, &result.Payload, &result.Status, &traceparent)
 	}
 	if errors.Is(err, sql.ErrNoRows) {
 		return result, nil
@@ -317,3 +334,28 @@ func ackOpenClawDispatchJob(
 	result.Acknowledged = true
 	return result, nil
 }
+
+// traceOpenClawDispatchWait records the time a job sat in the queue as a
+// span in the trace of the request that enqueued it.
+func traceOpenClawDispatchWait(ctx context.Context, job openClawDispatchJob, enqueuedAt time.Time) {
+	if job.Traceparent == "" {
+		return
+	}
+	ctx = tracing.ContextWithTraceparent(ctx, job.Traceparent)
+	_, span := tracing.StartAt(ctx, "openclaw.dispatch_queue.wait", tracing.SpanKindConsumer, enqueuedAt)
+	span.SetAttribute("openclaw.event_type", job.EventType)
+	span.SetAttribute("openclaw.dispatch_attempt", job.Attempts)
+	span.End()
+}
+
+func traceOpenClawDispatchAck(ctx context.Context, traceparent string, id int64, success bool, err error) {
+	if traceparent == "" {
+		return
+	}
+	ctx = tracing.ContextWithTraceparent(ctx, traceparent)
+	_, span := tracing.StartKind(ctx, "openclaw.dispatch_queue.ack", tracing.SpanKindConsumer)
+	span.SetAttribute("openclaw.dispatch_id", id)
+	span.SetAttribute("openclaw.delivered", success)
+	span.RecordError(err)
+	span.End()
+}
@@ -6,6 +6,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/samhotchkiss/otter-camp/internal/tracing"
 	"github.com/stretchr/testify/require"
 )
 
@@ -193,3 +194,26 @@ func TestOpenClawDispatchQueueAckFailureMarksFailedAtMaxAttempts(t *testing.T) {
 	require.NoError(t, err)
 	require.Empty(t, jobs, "failed dispatch jobs must not be claimable")
 }
+
+func TestOpenClawDispatchQueuePersistsTraceparent(t *testing.T) {
+	db := setupMessageTestDB(t)
+	orgID := insertMessageTestOrganization(t, db, "dispatch-queue-trace-org")
+
+	traceparent := "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"
+	ctx := tracing.ContextWithTraceparent(context.Background(), traceparent)
+	queued, err := enqueueOpenClawDispatchEvent(
+		ctx,
+		db,
+		orgID,
+		"dm.message",
+		"dm.message:msg-trace",
+		map[string]any{"type": "dm.message"},
+	)
+	require.NoError(t, err)
+	require.True(t, queued)
+
+	jobs, err := claimOpenClawDispatchJobs(context.Background(), db, 10)
+	require.NoError(t, err)
+	require.Len(t, jobs, 1)
+	require.Equal(t, traceparent, jobs[0].Traceparent)
+}
@@ -763,6 +763,13 @@ func (h *OpenClawMigrationControlPlaneHandler) Run(w http.ResponseWriter, r *htt
 		return
 	}
 
+	recordAudit(r, auditEvent{
+		OrgID:      orgID,
+		Action:     "openclaw_migration.run",
+		TargetType: "openclaw_migration",
+		TargetID:   orgID,
+		After:      request,
+	})
 	sendJSON(w, http.StatusAccepted, response)
 }
 
@@ -782,6 +789,7 @@ func (h *OpenClawMigrationControlPlaneHandler) Pause(w http.ResponseWriter, r *h
 		sendJSON(w, http.StatusInternalServerError, errorResponse{Error: "failed to pause migration"})
 		return
 	}
+	recordAudit(r, auditEvent{OrgID: orgID, Action: "openclaw_migration.pause", TargetType: "openclaw_migration", TargetID: orgID})
 	sendJSON(w, http.StatusOK, response)
 }
 
@@ -801,6 +809,7 @@ func (h *OpenClawMigrationControlPlaneHandler) Resume(w http.ResponseWriter, r *
 		sendJSON(w, http.StatusInternalServerError, errorResponse{Error: "failed to resume migration"})
 		return
 	}
+	recordAudit(r, auditEvent{OrgID: orgID, Action: "openclaw_migration.resume", TargetType: "openclaw_migration", TargetID: orgID})
 	sendJSON(w, http.StatusOK, response)
 }
 
@@ -845,6 +854,13 @@ func (h *OpenClawMigrationControlPlaneHandler) Reset(w http.ResponseWriter, r *h
 		response.TotalDeleted,
 		response.Deleted,
 	)
+	recordAudit(r, auditEvent{
+		OrgID:      orgID,
+		Action:     "openclaw_migration.reset",
+		TargetType: "openclaw_migration",
+		TargetID:   orgID,
+		After:      response,
+	})
 
 	sendJSON(w, http.StatusOK, response)
 }
@@ -891,6 +907,13 @@ func (h *OpenClawMigrationControlPlaneHandler) ResetMemoryExtraction(w http.Resp
 		response.DeletedDedupReviewed,
 		response.DeletedDedupCursors,
 	)
+	recordAudit(r, auditEvent{
+		OrgID:      orgID,
+		Action:     "openclaw_migration.reset_memory_extraction",
+		TargetType: "openclaw_migration",
+		TargetID:   orgID,
+		After:      response,
+	})
 
 	sendJSON(w, http.StatusOK, response)
 }
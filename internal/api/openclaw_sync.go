@@ -33,6 +33,7 @@ type OpenClawSyncHandler struct {
 	Hub                 *ws.Hub
 	DB                  *sql.DB
 	EmissionBuffer      *EmissionBuffer
+	EmissionStore       *store.EmissionStore
 	EmissionBroadcaster emissionBroadcaster
 }
 
@@ -276,11 +277,12 @@ type openClawDispatchQueuePullResponse struct {
 }
 
 type openClawDispatchQueueJobPayload struct {
-	ID         int64           `json:"id"`
-	EventType  string          `json:"event_type"`
-	Payload    json.RawMessage `json:"payload"`
-	ClaimToken string          `json:"claim_token"`
-	Attempts   int             `json:"attempts"`
+	ID          int64           `json:"id"`
+	EventType   string          `json:"event_type"`
+	Payload     json.RawMessage `json:"payload"`
+	ClaimToken  string          `json:"claim_token"`
+	Attempts    int             `json:"attempts"`
+	Traceparent string          `json:"traceparent,omitempty"`
 }
 
 type openClawDispatchQueueAckRequest struct {
@@ -401,7 +403,9 @@ func (h *OpenClawSyncHandler) Handle(w http.ResponseWriter, r *http.Request) {
 	now := time.Now()
 	syncBroadcaster := h.resolveEmissionBroadcaster()
 
-	if h.EmissionBuffer != nil && len(payload.Emissions) > 0 {
+	emissionsEnabled := h.EmissionBuffer != nil || h.EmissionStore != nil
+	syncEmissions := make([]Emission, 0, len(payload.Emissions)+len(payload.ProgressLogLines))
+	if emissionsEnabled && len(payload.Emissions) > 0 {
 		if workspaceID == "" {
 			log.Printf("openclaw sync emissions dropped: missing workspace context")
 		} else {
@@ -411,13 +415,11 @@ func (h *OpenClawSyncHandler) Handle(w http.ResponseWriter, r *http.Request) {
 					log.Printf("openclaw sync emission dropped: %v", err)
 					continue
 				}
-				emission.OrgID = workspaceID
-				h.EmissionBuffer.Push(emission)
-				broadcastEmissionEvent(syncBroadcaster, workspaceID, emission)
+				syncEmissions = append(syncEmissions, emission)
 			}
 		}
 	}
-	if h.EmissionBuffer != nil && len(payload.ProgressLogLines) > 0 {
+	if emissionsEnabled && len(payload.ProgressLogLines) > 0 {
 		if workspaceID == "" {
 			log.Printf("openclaw sync progress-log emissions dropped: missing workspace context")
 		} else {
@@ -429,12 +431,19 @@ func (h *OpenClawSyncHandler) Handle(w http.ResponseWriter, r *http.Request) {
 				if !markProgressLogEmissionSeen(emission.ID) {
 					continue
 				}
-				emission.OrgID = workspaceID
-				h.EmissionBuffer.Push(emission)
-				broadcastEmissionEvent(syncBroadcaster, workspaceID, emission)
+				syncEmissions = append(syncEmissions, emission)
 			}
 		}
 	}
+	if len(syncEmissions) > 0 {
+		recorded, err := recordEmissions(r.Context(), h.EmissionStore, h.EmissionBuffer, workspaceID, syncEmissions)
+		if err != nil {
+			log.Printf("openclaw sync emissions dropped: %v", err)
+		}
+		for _, emission := range recorded {
+			broadcastEmissionEvent(syncBroadcaster, workspaceID, emission)
+		}
+	}
 
 	// Process sessions into agent states
 	for _, session := range payload.Sessions {
@@ -692,11 +701,12 @@ func (h *OpenClawSyncHandler) PullDispatchQueue(w http.ResponseWriter, r *http.R
 	payload := make([]openClawDispatchQueueJobPayload, 0, len(jobs))
 	for _, job := range jobs {
 		payload = append(payload, openClawDispatchQueueJobPayload{
-			ID:         job.ID,
-			EventType:  job.EventType,
-			Payload:    job.Payload,
-			ClaimToken: job.ClaimToken,
-			Attempts:   job.Attempts,
+			ID:          job.ID,
+			EventType:   job.EventType,
+			Payload:     job.Payload,
+			ClaimToken:  job.ClaimToken,
+			Attempts:    job.Attempts,
+			Traceparent: job.Traceparent,
 		})
 	}
 
@@ -824,17 +834,7 @@ func requireOpenClawSyncAuth(ctx context.Context, db *sql.DB, r *http.Request) (
 		return http.StatusServiceUnavailable, fmt.Errorf("sync authentication is not configured")
 	}
 
-	token := strings.TrimSpace(r.Header.Get("X-OpenClaw-Token"))
-	if token == "" {
-		token = strings.TrimSpace(r.Header.Get("X-Sync-Token"))
-	}
-	if token == "" {
-		auth := strings.TrimSpace(r.Header.Get("Authorization"))
-		if strings.HasPrefix(auth, "Bearer ") {
-			token = strings.TrimSpace(strings.TrimPrefix(auth, "Bearer "))
-		}
-	}
-
+	token := openClawSyncToken(r)
 	if token == "" {
 		return http.StatusUnauthorized, fmt.Errorf("missing authentication")
 	}
@@ -871,6 +871,33 @@ func requireOpenClawSyncAuth(ctx context.Context, db *sql.DB, r *http.Request) (
 	return http.StatusOK, nil
 }
 
+// openClawSyncToken reads the bridge credential from X-OpenClaw-Token,
+// X-Sync-Token or a bearer token, in that order.
+func openClawSyncToken(r *http.Request) string {
+	token := strings.TrimSpace(r.Header.Get("X-OpenClaw-Token"))
+	if token == "" {
+		token = strings.TrimSpace(r.Header.Get("X-Sync-Token"))
+	}
+	if token == "" {
+		auth := strings.TrimSpace(r.Header.Get("Authorization"))
+		if strings.HasPrefix(auth, "Bearer ") {
+			token = strings.TrimSpace(strings.TrimPrefix(auth, "Bearer "))
+		}
+	}
+	return token
+}
+
+// hasOpenClawBridgeCredential reports whether the request carries the
+// configured OpenClaw sync secret.
+func hasOpenClawBridgeCredential(r *http.Request) bool {
+	secret := resolveOpenClawSyncSecret()
+	token := openClawSyncToken(r)
+	if secret == "" || token == "" {
+		return false
+	}
+	return subtle.ConstantTimeCompare([]byte(token), []byte(secret)) == 1
+}
+
 func resolveOpenClawSyncSecret() string {
 	// Preferred variable name.
 	secret := strings.TrimSpace(os.Getenv("OPENCLAW_SYNC_SECRET"))
@@ -17,6 +17,7 @@ import (
 	"github.com/go-chi/chi/v5"
 	"github.com/samhotchkiss/otter-camp/internal/middleware"
 	"github.com/samhotchkiss/otter-camp/internal/store"
+	"github.com/samhotchkiss/otter-camp/internal/tracing"
 	"github.com/samhotchkiss/otter-camp/internal/ws"
 )
 
@@ -96,6 +97,8 @@ type openClawProjectChatDispatchEvent struct {
 	Timestamp time.Time                       `json:"timestamp"`
 	OrgID     string                          `json:"org_id"`
 	Data      openClawProjectChatDispatchData `json:"data"`
+	// Traceparent carries W3C trace context to the bridge.
+	Traceparent string `json:"traceparent,omitempty"`
 }
 
 type openClawProjectChatDispatchData struct {
@@ -277,6 +280,12 @@ func (h *ProjectChatHandler) Create(w http.ResponseWriter, r *http.Request) {
 		sendJSON(w, http.StatusInternalServerError, errorResponse{Error: "failed to resolve project chat target"})
 		return
 	}
+	if shouldDispatch {
+		if warning := usageBudgetDispatchWarning(r.Context(), h.DB, workspaceID, target.AgentID, projectID); warning != "" {
+			shouldDispatch = false
+			dispatchWarning = warning
+		}
+	}
 
 	message, err := h.ChatStore.Create(r.Context(), store.CreateProjectChatMessageInput{
 		ProjectID: projectID,
@@ -665,9 +674,10 @@ func (h *ProjectChatHandler) buildProjectChatDispatchEvent(
 	}
 
 	event := openClawProjectChatDispatchEvent{
-		Type:      "project.chat.message",
-		Timestamp: time.Now().UTC(),
-		OrgID:     workspaceID,
+		Type:        "project.chat.message",
+		Timestamp:   time.Now().UTC(),
+		OrgID:       workspaceID,
+		Traceparent: tracing.Traceparent(ctx),
 		Data: openClawProjectChatDispatchData{
 			MessageID:   message.ID,
 			ProjectID:   message.ProjectID,
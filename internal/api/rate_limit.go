@@ -0,0 +1,547 @@
+package api
+
+import (
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"net/http"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	chimiddleware "github.com/go-chi/chi/v5/middleware"
+	"github.com/samhotchkiss/otter-camp/internal/metrics"
+	"github.com/samhotchkiss/otter-camp/internal/middleware"
+	"github.com/samhotchkiss/otter-camp/internal/store"
+)
+
+const (
+	rateLimitGroupRead     = "read"
+	rateLimitGroupWrite    = "write"
+	rateLimitGroupComments = "comments"
+	rateLimitGroupMemory   = "memory"
+	// rateLimitGroupAuthFailures counts failed authentications per client IP.
+	// It is not a route group: requests never take from it directly.
+	rateLimitGroupAuthFailures = "auth_failures"
+
+	rateLimitIdentityTTL    = time.Minute
+	rateLimitCacheMaxSize   = 10000
+	rateLimitPruneInterval  = 10 * time.Minute
+	rateLimitBucketIdleTime = 24 * time.Hour
+)
+
+// rateLimitRule allows Requests per Window on average, with bursts of up to
+// Burst requests.
+type rateLimitRule struct {
+	Requests int
+	Window   time.Duration
+	Burst    int
+}
+
+func (r rateLimitRule) refillPerSecond() float64 {
+	return float64(r.Requests) / r.Window.Seconds()
+}
+
+func (r rateLimitRule) policyHeader() string {
+	return fmt.Sprintf("%d;w=%d;burst=%d", r.Requests, int(r.Window.Seconds()), r.Burst)
+}
+
+// rateLimitPolicy maps plan (the org's tier) to route group to rule.
+type rateLimitPolicy map[string]map[string]rateLimitRule
+
+var defaultRateLimitPolicy = rateLimitPolicy{
+	"free": {
+		rateLimitGroupRead:     {Requests: 600, Window: time.Minute, Burst: 120},
+		rateLimitGroupWrite:    {Requests: 120, Window: time.Minute, Burst: 30},
+		rateLimitGroupComments: {Requests: 30, Window: time.Minute, Burst: 10},
+		rateLimitGroupMemory:   {Requests: 60, Window: time.Minute, Burst: 20},
+		// Failed authentications are anonymous, so only the free plan's
+		// rule is ever used.
+		rateLimitGroupAuthFailures: {Requests: 20, Window: time.Minute, Burst: 10},
+	},
+	"paid": {
+		rateLimitGroupRead:     {Requests: 3000, Window: time.Minute, Burst: 600},
+		rateLimitGroupWrite:    {Requests: 600, Window: time.Minute, Burst: 120},
+		rateLimitGroupComments: {Requests: 120, Window: time.Minute, Burst: 30},
+		rateLimitGroupMemory:   {Requests: 300, Window: time.Minute, Burst: 60},
+	},
+}
+
+// rule returns the limit for plan and group, falling back to the free plan
+// for tiers without their own limits.
+func (p rateLimitPolicy) rule(plan, group string) (rateLimitRule, bool) {
+	if rules, ok := p[plan]; ok {
+		if rule, ok := rules[group]; ok {
+			return rule, true
+		}
+	}
+	rule, ok := p[store.RateLimitPlanFree][group]
+	return rule, ok
+}
+
+func (p rateLimitPolicy) clone() rateLimitPolicy {
+	out := make(rateLimitPolicy, len(p))
+	for plan, rules := range p {
+		out[plan] = make(map[string]rateLimitRule, len(rules))
+		for group, rule := range rules {
+			out[plan][group] = rule
+		}
+	}
+	return out
+}
+
+var rateLimitOverridePattern = regexp.MustCompile(`^([a-z0-9_-]+)\.([a-z0-9_-]+)=(\d+)/(\d*[a-z]+)(?::(\d+))?$`)
+
+// parseRateLimitPolicy applies OTTER_RATE_LIMITS overrides to the defaults.
+// Entries look like "free.comments=20/1m:5" (20 requests per minute, bursts
+// of 5); the burst defaults to a quarter of the request count.
+func parseRateLimitPolicy(raw string) (rateLimitPolicy, error) {
+	policy := defaultRateLimitPolicy.clone()
+	for _, entry := range strings.Split(raw, ",") {
+		entry = strings.ToLower(strings.TrimSpace(entry))
+		if entry == "" {
+			continue
+		}
+		match := rateLimitOverridePattern.FindStringSubmatch(entry)
+		if match == nil {
+			return nil, fmt.Errorf("invalid rate limit %q: want plan.group=requests/window[:burst]", entry)
+		}
+		group := match[2]
+		switch group {
+		case rateLimitGroupRead, rateLimitGroupWrite, rateLimitGroupComments, rateLimitGroupMemory, rateLimitGroupAuthFailures:
+		default:
+			return nil, fmt.Errorf("invalid rate limit %q: unknown route group %q", entry, group)
+		}
+		requests, _ := strconv.Atoi(match[3])
+		window := match[4]
+		if window == "s" || window == "m" || window == "h" {
+			window = "1" + window
+		}
+		duration, err := time.ParseDuration(window)
+		if err != nil || duration < time.Second || requests < 1 {
+			return nil, fmt.Errorf("invalid rate limit %q: requests and window must be positive", entry)
+		}
+		burst := requests / 4
+		if match[5] != "" {
+			burst, _ = strconv.Atoi(match[5])
+		}
+		if burst < 1 {
+			burst = 1
+		}
+		if policy[match[1]] == nil {
+			policy[match[1]] = defaultRateLimitPolicy.clone()[store.RateLimitPlanFree]
+		}
+		policy[match[1]][group] = rateLimitRule{Requests: requests, Window: duration, Burst: burst}
+	}
+	return policy, nil
+}
+
+// Bridge traffic, webhooks and endpoints with their own limiter are exempt.
+var rateLimitExemptPrefixes = []string{
+	"/api/sync/",
+	"/api/openclaw/",
+	"/api/webhooks/",
+	"/api/waitlist",
+	"/api/feed",
+}
+
+var (
+	rateLimitCommentsPattern = regexp.MustCompile(`^/api/(issues|project-tasks)/[^/]+/comments$|^/api/projects/[^/]+/chat/messages$|^/api/messages$`)
+	rateLimitMemoryPattern   = regexp.MustCompile(`^/api/(memory|knowledge|shared-knowledge)(/|$)|^/api/agents/[^/]+/memory(/|$)`)
+)
+
+// rateLimitRouteGroup buckets a request. Comment and memory writes get their
+// own, tighter groups because runaway agent loops hit them hardest.
+func rateLimitRouteGroup(r *http.Request) string {
+	read := r.Method == http.MethodGet || r.Method == http.MethodHead
+	switch {
+	case read:
+		return rateLimitGroupRead
+	case rateLimitCommentsPattern.MatchString(r.URL.Path):
+		return rateLimitGroupComments
+	case rateLimitMemoryPattern.MatchString(r.URL.Path):
+		return rateLimitGroupMemory
+	default:
+		return rateLimitGroupWrite
+	}
+}
+
+type rateLimitBucketStore interface {
+	Take(ctx context.Context, input store.TakeRateLimitTokenInput) (store.RateLimitDecision, error)
+	Peek(ctx context.Context, input store.TakeRateLimitTokenInput) (store.RateLimitDecision, error)
+	PlanForOrg(ctx context.Context, orgID string) (string, error)
+	PruneIdle(ctx context.Context, before time.Time) (int, error)
+}
+
+type rateLimitCacheEntry struct {
+	value   string
+	expires time.Time
+}
+
+// rateLimiter enforces rateLimitPolicy with buckets keyed by org, caller
+// (API key, session or client IP) and route group.
+type rateLimiter struct {
+	store  rateLimitBucketStore
+	policy rateLimitPolicy
+	now    func() time.Time
+	// sessionOrg resolves a session token to its org. It is cached briefly
+	// because it runs on every request.
+	sessionOrg func(r *http.Request) string
+
+	mu        sync.Mutex
+	sessions  map[string]rateLimitCacheEntry
+	plans     map[string]rateLimitCacheEntry
+	lastPrune atomic.Int64
+	proxyWarn sync.Once
+}
+
+// RateLimit throttles /api requests per OTTER_RATE_LIMITS (see
+// parseRateLimitPolicy). Set OTTER_RATE_LIMITS=off to disable it. When the
+// bucket store fails, requests are let through.
+func RateLimit(db *sql.DB) func(http.Handler) http.Handler {
+	raw := strings.TrimSpace(os.Getenv("OTTER_RATE_LIMITS"))
+	if db == nil || strings.EqualFold(raw, "off") {
+		return func(next http.Handler) http.Handler { return next }
+	}
+	policy, err := parseRateLimitPolicy(raw)
+	if err != nil {
+		log.Printf("[rate-limit] %v; using default limits", err)
+		policy = defaultRateLimitPolicy
+	}
+	limiter := newRateLimiter(store.NewRateLimitStore(db), policy)
+	limiter.sessionOrg = func(r *http.Request) string {
+		identity, err := requireSessionIdentity(r.Context(), db, r)
+		if err != nil {
+			return ""
+		}
+		return identity.OrgID
+	}
+	return limiter.middleware
+}
+
+// AuthFailureRateLimit throttles credential guessing. It runs ahead of
+// APIKeyAuth and counts each 401 answer to a request that carried an API key
+// or session token against the client IP; while that IP's bucket is empty,
+// its credentialed requests are refused without being checked. Limits come
+// from free.auth_failures in OTTER_RATE_LIMITS.
+func AuthFailureRateLimit(db *sql.DB) func(http.Handler) http.Handler {
+	if db == nil || strings.EqualFold(strings.TrimSpace(os.Getenv("OTTER_RATE_LIMITS")), "off") {
+		return func(next http.Handler) http.Handler { return next }
+	}
+	return newRateLimiter(store.NewRateLimitStore(db), configuredRateLimitPolicy()).authFailureMiddleware
+}
+
+func newRateLimiter(buckets rateLimitBucketStore, policy rateLimitPolicy) *rateLimiter {
+	return &rateLimiter{
+		store:    buckets,
+		policy:   policy,
+		now:      time.Now,
+		sessions: map[string]rateLimitCacheEntry{},
+		plans:    map[string]rateLimitCacheEntry{},
+	}
+}
+
+func (l *rateLimiter) middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodOptions || rateLimitExempt(r.URL.Path) {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		group := rateLimitRouteGroup(r)
+		orgID, subject := l.identify(r)
+		plan := l.planFor(r.Context(), orgID)
+		rule, ok := l.policy.rule(plan, group)
+		if !ok {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		orgKey := orgID
+		if orgKey == "" {
+			orgKey = "-"
+		}
+		decision, err := l.store.Take(r.Context(), store.TakeRateLimitTokenInput{
+			BucketKey:       orgKey + "|" + subject + "|" + group,
+			OrgID:           orgID,
+			Subject:         subject,
+			RouteGroup:      group,
+			Plan:            plan,
+			Capacity:        float64(rule.Burst),
+			RefillPerSecond: rule.refillPerSecond(),
+		})
+		if err != nil {
+			log.Printf("[rate-limit] %s %s: %v", r.Method, r.URL.Path, err)
+			next.ServeHTTP(w, r)
+			return
+		}
+		l.maybePrune()
+
+		rate := rule.refillPerSecond()
+		header := w.Header()
+		header.Set("RateLimit-Limit", strconv.Itoa(rule.Burst))
+		header.Set("RateLimit-Remaining", strconv.Itoa(int(math.Max(0, math.Floor(decision.Tokens)))))
+		header.Set("RateLimit-Reset", strconv.Itoa(int(math.Ceil(math.Max(0, float64(rule.Burst)-decision.Tokens)/rate))))
+		header.Set("RateLimit-Policy", rule.policyHeader())
+		if !decision.Allowed {
+			retryAfter := int(math.Max(1, math.Ceil((1-decision.Tokens)/rate)))
+			header.Set("Retry-After", strconv.Itoa(retryAfter))
+			metrics.ObserveRateLimitThrottle(plan, group)
+			sendJSON(w, http.StatusTooManyRequests, rateLimitErrorResponse{
+				Error:             "rate limit exceeded",
+				RouteGroup:        group,
+				RetryAfterSeconds: retryAfter,
+			})
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+func (l *rateLimiter) authFailureMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodOptions || rateLimitExempt(r.URL.Path) ||
+			(apiKeyFromRequest(r) == "" && extractSessionToken(r) == "") {
+			next.ServeHTTP(w, r)
+			return
+		}
+		rule, ok := l.policy.rule(store.RateLimitPlanFree, rateLimitGroupAuthFailures)
+		if !ok {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		subject := "ip:" + l.clientIP(r)
+		input := store.TakeRateLimitTokenInput{
+			BucketKey:       "-|" + subject + "|" + rateLimitGroupAuthFailures,
+			Subject:         subject,
+			RouteGroup:      rateLimitGroupAuthFailures,
+			Plan:            store.RateLimitPlanFree,
+			Capacity:        float64(rule.Burst),
+			RefillPerSecond: rule.refillPerSecond(),
+		}
+		decision, err := l.store.Peek(r.Context(), input)
+		if err != nil {
+			log.Printf("[rate-limit] %s %s: %v", r.Method, r.URL.Path, err)
+			next.ServeHTTP(w, r)
+			return
+		}
+		if !decision.Allowed {
+			retryAfter := int(math.Max(1, math.Ceil((1-decision.Tokens)/rule.refillPerSecond())))
+			w.Header().Set("Retry-After", strconv.Itoa(retryAfter))
+			metrics.ObserveRateLimitThrottle(store.RateLimitPlanFree, rateLimitGroupAuthFailures)
+			sendJSON(w, http.StatusTooManyRequests, rateLimitErrorResponse{
+				Error:             "too many failed authentication attempts",
+				RouteGroup:        rateLimitGroupAuthFailures,
+				RetryAfterSeconds: retryAfter,
+			})
+			return
+		}
+
+		ww := chimiddleware.NewWrapResponseWriter(w, r.ProtoMajor)
+		next.ServeHTTP(ww, r)
+		if ww.Status() != http.StatusUnauthorized {
+			return
+		}
+		if _, err := l.store.Take(context.WithoutCancel(r.Context()), input); err != nil {
+			log.Printf("[rate-limit] %s %s: %v", r.Method, r.URL.Path, err)
+		}
+	})
+}
+
+type rateLimitErrorResponse struct {
+	Error             string `json:"error"`
+	RouteGroup        string `json:"route_group"`
+	RetryAfterSeconds int    `json:"retry_after_seconds"`
+}
+
+func rateLimitExempt(path string) bool {
+	for _, prefix := range rateLimitExemptPrefixes {
+		if strings.HasPrefix(path, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// identify returns the org (when known from a credential, never from
+// request hints) and the caller the bucket belongs to. Session tokens are
+// hashed so they never reach the database. Anonymous callers are keyed by
+// client IP alone, so behind a reverse proxy TRUST_PROXY_HEADERS must be set
+// or they all share the proxy's bucket.
+func (l *rateLimiter) identify(r *http.Request) (string, string) {
+	if key := apiKeyFromContext(r.Context()); key != nil {
+		return key.OrgID, "api_key:" + key.ID
+	}
+	if token := extractSessionToken(r); token != "" {
+		sum := sha256.Sum256([]byte(token))
+		tokenHash := hex.EncodeToString(sum[:8])
+		orgID := ""
+		if isUserCredential(token) && l.sessionOrg != nil {
+			orgID = l.cached(l.sessions, tokenHash, func() string { return l.sessionOrg(r) })
+		}
+		return orgID, "session:" + tokenHash
+	}
+	return "", "ip:" + l.clientIP(r)
+}
+
+// clientIP is apiKeyClientIP, plus a one-time warning when requests arrive
+// through a proxy whose X-Forwarded-For is not trusted.
+func (l *rateLimiter) clientIP(r *http.Request) string {
+	if !trustForwardedFor() && r.Header.Get("X-Forwarded-For") != "" {
+		l.proxyWarn.Do(func() {
+			log.Printf("[rate-limit] requests carry X-Forwarded-For but TRUST_PROXY_HEADERS is off; anonymous callers share the proxy's rate limit bucket")
+		})
+	}
+	return apiKeyClientIP(r)
+}
+
+func (l *rateLimiter) planFor(ctx context.Context, orgID string) string {
+	if orgID == "" {
+		return store.RateLimitPlanFree
+	}
+	plan := l.cached(l.plans, orgID, func() string {
+		plan, err := l.store.PlanForOrg(ctx, orgID)
+		if err != nil {
+			if !errors.Is(err, store.ErrNotFound) {
+				log.Printf("[rate-limit] plan for org %s: %v", orgID, err)
+			}
+			return ""
+		}
+		return plan
+	})
+	if plan == "" {
+		return store.RateLimitPlanFree
+	}
+	return plan
+}
+
+func (l *rateLimiter) cached(cache map[string]rateLimitCacheEntry, key string, load func() string) string {
+	now := l.now()
+	l.mu.Lock()
+	entry, ok := cache[key]
+	l.mu.Unlock()
+	if ok && now.Before(entry.expires) {
+		return entry.value
+	}
+
+	value := load()
+	l.mu.Lock()
+	if len(cache) >= rateLimitCacheMaxSize {
+		clear(cache)
+	}
+	cache[key] = rateLimitCacheEntry{value: value, expires: now.Add(rateLimitIdentityTTL)}
+	l.mu.Unlock()
+	return value
+}
+
+// maybePrune drops long-idle buckets at most once per interval per process.
+func (l *rateLimiter) maybePrune() {
+	now := l.now()
+	last := l.lastPrune.Load()
+	if now.Sub(time.Unix(0, last)) < rateLimitPruneInterval || !l.lastPrune.CompareAndSwap(last, now.UnixNano()) {
+		return
+	}
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		defer cancel()
+		if _, err := l.store.PruneIdle(ctx, now.Add(-rateLimitBucketIdleTime)); err != nil {
+			log.Printf("[rate-limit] prune: %v", err)
+		}
+	}()
+}
+
+// RateLimitsHandler reports an org's limits and recently throttled callers.
+type RateLimitsHandler struct {
+	Store *store.RateLimitStore
+}
+
+type rateLimitsResponse struct {
+	Plan   string                          `json:"plan"`
+	Limits map[string]rateLimitRuleSummary `json:"limits"`
+	Items  []store.RateLimitThrottle       `json:"items"`
+	Total  int                             `json:"total"`
+}
+
+type rateLimitRuleSummary struct {
+	Requests      int `json:"requests"`
+	WindowSeconds int `json:"window_seconds"`
+	Burst         int `json:"burst"`
+}
+
+// Get handles GET /api/admin/rate-limits?since=<RFC3339>&limit=N. Throttled
+// buckets default to the last 24 hours.
+func (h *RateLimitsHandler) Get(w http.ResponseWriter, r *http.Request) {
+	if h.Store == nil {
+		sendJSON(w, http.StatusServiceUnavailable, errorResponse{Error: "database not available"})
+		return
+	}
+	orgID := middleware.WorkspaceFromContext(r.Context())
+	if orgID == "" {
+		sendJSON(w, http.StatusUnauthorized, errorResponse{Error: "authentication required"})
+		return
+	}
+	since := time.Now().Add(-24 * time.Hour)
+	if raw := strings.TrimSpace(r.URL.Query().Get("since")); raw != "" {
+		parsed, err := time.Parse(time.RFC3339, raw)
+		if err != nil {
+			sendJSON(w, http.StatusBadRequest, errorResponse{Error: "since must be an RFC3339 timestamp"})
+			return
+		}
+		since = parsed
+	}
+	limit, err := parseLimit(r.URL.Query().Get("limit"), 100, 1000)
+	if err != nil {
+		sendJSON(w, http.StatusBadRequest, errorResponse{Error: err.Error()})
+		return
+	}
+
+	plan, err := h.Store.PlanForOrg(r.Context(), orgID)
+	if err != nil {
+		handleJobsStoreError(w, err)
+		return
+	}
+	items, err := h.Store.ListThrottled(r.Context(), orgID, since, limit)
+	if err != nil {
+		handleJobsStoreError(w, err)
+		return
+	}
+	sendJSON(w, http.StatusOK, rateLimitsResponse{
+		Plan:   plan,
+		Limits: rateLimitSummaries(configuredRateLimitPolicy(), plan),
+		Items:  items,
+		Total:  len(items),
+	})
+}
+
+func configuredRateLimitPolicy() rateLimitPolicy {
+	policy, err := parseRateLimitPolicy(os.Getenv("OTTER_RATE_LIMITS"))
+	if err != nil {
+		return defaultRateLimitPolicy
+	}
+	return policy
+}
+
+func rateLimitSummaries(policy rateLimitPolicy, plan string) map[string]rateLimitRuleSummary {
+	groups := []string{rateLimitGroupRead, rateLimitGroupWrite, rateLimitGroupComments, rateLimitGroupMemory}
+	out := make(map[string]rateLimitRuleSummary, len(groups))
+	for _, group := range groups {
+		if rule, ok := policy.rule(plan, group); ok {
+			out[group] = rateLimitRuleSummary{
+				Requests:      rule.Requests,
+				WindowSeconds: int(rule.Window.Seconds()),
+				Burst:         rule.Burst,
+			}
+		}
+	}
+	return out
+}
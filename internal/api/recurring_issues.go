@@ -0,0 +1,452 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/samhotchkiss/otter-camp/internal/middleware"
+	"github.com/samhotchkiss/otter-camp/internal/scheduler"
+	"github.com/samhotchkiss/otter-camp/internal/store"
+)
+
+type recurringIssueListResponse struct {
+	Items []store.RecurringIssue `json:"items"`
+	Total int                    `json:"total"`
+}
+
+type recurringIssueRunResponse struct {
+	Outcome        scheduler.RecurringIssueOutcome `json:"outcome"`
+	RecurringIssue *store.RecurringIssue           `json:"recurring_issue"`
+}
+
+// recurringIssueRequest is shared by create and patch; patch leaves nil
+// fields unchanged. TemplateID and FlowTemplateID accept an id or a name.
+type recurringIssueRequest struct {
+	Name           *string        `json:"name"`
+	TitleTemplate  *string        `json:"title_template"`
+	BodyTemplate   *string        `json:"body_template"`
+	TemplateID     *string        `json:"template_id"`
+	Fields         map[string]any `json:"fields"`
+	OwnerAgentID   *string        `json:"owner_agent_id"`
+	FlowTemplateID *string        `json:"flow_template_id"`
+	Priority       *string        `json:"priority"`
+	ScheduleKind   *string        `json:"schedule_kind"`
+	CronExpr       *string        `json:"cron_expr"`
+	IntervalMS     *int64         `json:"interval_ms"`
+	RunAt          *string        `json:"run_at"`
+	Timezone       *string        `json:"timezone"`
+	SkipIfOpen     *bool          `json:"skip_if_open"`
+	Enabled        *bool          `json:"enabled"`
+}
+
+func (req recurringIssueRequest) touchesSchedule() bool {
+	return req.ScheduleKind != nil ||
+		req.CronExpr != nil ||
+		req.IntervalMS != nil ||
+		req.RunAt != nil ||
+		req.Timezone != nil ||
+		req.Enabled != nil
+}
+
+func (h *IssuesHandler) ListRecurringIssues(w http.ResponseWriter, r *http.Request) {
+	if h.RecurringStore == nil {
+		sendJSON(w, http.StatusServiceUnavailable, errorResponse{Error: "database not available"})
+		return
+	}
+	items, err := h.RecurringStore.List(r.Context(), strings.TrimSpace(chi.URLParam(r, "id")))
+	if err != nil {
+		handleRecurringIssueError(w, err)
+		return
+	}
+	sendJSON(w, http.StatusOK, recurringIssueListResponse{Items: items, Total: len(items)})
+}
+
+func (h *IssuesHandler) GetRecurringIssue(w http.ResponseWriter, r *http.Request) {
+	record, ok := h.loadRecurringIssue(w, r)
+	if !ok {
+		return
+	}
+	sendJSON(w, http.StatusOK, record)
+}
+
+// CreateRecurringIssue stores a definition and schedules its first
+// occurrence.
+func (h *IssuesHandler) CreateRecurringIssue(w http.ResponseWriter, r *http.Request) {
+	if h.RecurringStore == nil {
+		sendJSON(w, http.StatusServiceUnavailable, errorResponse{Error: "database not available"})
+		return
+	}
+	projectID := strings.TrimSpace(chi.URLParam(r, "id"))
+
+	var req recurringIssueRequest
+	decoder := json.NewDecoder(r.Body)
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&req); err != nil {
+		sendJSON(w, http.StatusBadRequest, errorResponse{Error: "invalid JSON"})
+		return
+	}
+	if req.ScheduleKind == nil {
+		sendJSON(w, http.StatusBadRequest, errorResponse{Error: "schedule_kind is required"})
+		return
+	}
+	runAt, err := parseOptionalRFC3339(req.RunAt, "run_at")
+	if err != nil {
+		sendJSON(w, http.StatusBadRequest, errorResponse{Error: "invalid run_at"})
+		return
+	}
+	timezone := derefString(req.Timezone, "UTC")
+	nextRunAt, err := nextRecurringIssueRun(*req.ScheduleKind, req.CronExpr, req.IntervalMS, runAt, timezone)
+	if err != nil {
+		sendJSON(w, http.StatusBadRequest, errorResponse{Error: err.Error()})
+		return
+	}
+	templateID, flowTemplateID, fields, ok := h.resolveRecurringIssueRefs(w, r, projectID, req)
+	if !ok {
+		return
+	}
+
+	input := store.CreateRecurringIssueInput{
+		ProjectID:       projectID,
+		Name:            derefString(req.Name, ""),
+		TitleTemplate:   derefString(req.TitleTemplate, ""),
+		BodyTemplate:    derefString(req.BodyTemplate, ""),
+		IssueTemplateID: templateID,
+		Fields:          fields,
+		OwnerAgentID:    req.OwnerAgentID,
+		FlowTemplateID:  flowTemplateID,
+		Priority:        derefString(req.Priority, ""),
+		ScheduleKind:    *req.ScheduleKind,
+		CronExpr:        req.CronExpr,
+		IntervalMS:      req.IntervalMS,
+		RunAt:           runAt,
+		Timezone:        timezone,
+		SkipIfOpen:      req.SkipIfOpen != nil && *req.SkipIfOpen,
+		Enabled:         req.Enabled,
+		NextRunAt:       nextRunAt,
+	}
+	record, err := h.RecurringStore.Create(r.Context(), input)
+	if err != nil {
+		if errors.Is(err, store.ErrNotFound) {
+			sendJSON(w, http.StatusNotFound, errorResponse{Error: "project not found"})
+			return
+		}
+		handleRecurringIssueError(w, err)
+		return
+	}
+	recordAudit(r, auditEvent{Action: "recurring_issue.create", TargetType: "recurring_issue", TargetID: record.ID, After: record})
+	log.Printf("[recurring-issues] created %q (%s) project=%s", record.Name, record.ID, projectID)
+	sendJSON(w, http.StatusCreated, record)
+}
+
+// PatchRecurringIssue updates a definition. Schedule changes and re-enabling
+// reschedule the next occurrence from now; disabling clears it.
+func (h *IssuesHandler) PatchRecurringIssue(w http.ResponseWriter, r *http.Request) {
+	existing, ok := h.loadRecurringIssue(w, r)
+	if !ok {
+		return
+	}
+
+	var req recurringIssueRequest
+	decoder := json.NewDecoder(r.Body)
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&req); err != nil {
+		sendJSON(w, http.StatusBadRequest, errorResponse{Error: "invalid JSON"})
+		return
+	}
+	runAt, err := parseOptionalRFC3339(req.RunAt, "run_at")
+	if err != nil {
+		sendJSON(w, http.StatusBadRequest, errorResponse{Error: "invalid run_at"})
+		return
+	}
+
+	input := store.UpdateRecurringIssueInput{
+		Name:          req.Name,
+		TitleTemplate: req.TitleTemplate,
+		BodyTemplate:  req.BodyTemplate,
+		OwnerAgentID:  req.OwnerAgentID,
+		Priority:      req.Priority,
+		ScheduleKind:  req.ScheduleKind,
+		CronExpr:      req.CronExpr,
+		IntervalMS:    req.IntervalMS,
+		RunAt:         runAt,
+		Timezone:      req.Timezone,
+		SkipIfOpen:    req.SkipIfOpen,
+		Enabled:       req.Enabled,
+	}
+	if req.TemplateID != nil || req.FlowTemplateID != nil || req.Fields != nil {
+		merged := req
+		if merged.TemplateID == nil && existing.IssueTemplateID != nil {
+			merged.TemplateID = existing.IssueTemplateID
+		}
+		if merged.Fields == nil && derefString(merged.TemplateID, "") != "" {
+			merged.Fields = make(map[string]any, len(existing.Fields))
+			for key, value := range existing.Fields {
+				merged.Fields[key] = value
+			}
+		}
+		templateID, flowTemplateID, fields, ok := h.resolveRecurringIssueRefs(w, r, existing.ProjectID, merged)
+		if !ok {
+			return
+		}
+		input.IssueTemplateID = recurringIssueClearableRef(templateID)
+		input.Fields = fields
+		if req.FlowTemplateID != nil {
+			input.FlowTemplateID = recurringIssueClearableRef(flowTemplateID)
+		}
+	}
+
+	if req.touchesSchedule() {
+		enabled := existing.Enabled
+		if req.Enabled != nil {
+			enabled = *req.Enabled
+		}
+		kind := existing.ScheduleKind
+		if req.ScheduleKind != nil {
+			kind = *req.ScheduleKind
+		}
+		cronExpr := existing.CronExpr
+		if req.CronExpr != nil {
+			cronExpr = req.CronExpr
+		}
+		intervalMS := existing.IntervalMS
+		if req.IntervalMS != nil {
+			intervalMS = req.IntervalMS
+		}
+		if runAt == nil {
+			runAt = existing.RunAt
+		}
+		timezone := existing.Timezone
+		if req.Timezone != nil {
+			timezone = derefString(req.Timezone, "UTC")
+		}
+		nextRunAt, err := nextRecurringIssueRun(kind, cronExpr, intervalMS, runAt, timezone)
+		if err != nil {
+			sendJSON(w, http.StatusBadRequest, errorResponse{Error: err.Error()})
+			return
+		}
+		if enabled && nextRunAt != nil {
+			input.NextRunAt = nextRunAt
+		} else {
+			input.ClearNextRunAt = true
+		}
+	}
+
+	updated, err := h.RecurringStore.Update(r.Context(), existing.ID, input)
+	if err != nil {
+		handleRecurringIssueError(w, err)
+		return
+	}
+	recordAudit(r, auditEvent{Action: "recurring_issue.update", TargetType: "recurring_issue", TargetID: updated.ID, Before: existing, After: updated})
+	sendJSON(w, http.StatusOK, updated)
+}
+
+func (h *IssuesHandler) DeleteRecurringIssue(w http.ResponseWriter, r *http.Request) {
+	record, ok := h.loadRecurringIssue(w, r)
+	if !ok {
+		return
+	}
+	if err := h.RecurringStore.Delete(r.Context(), record.ID); err != nil {
+		handleRecurringIssueError(w, err)
+		return
+	}
+	recordAudit(r, auditEvent{Action: "recurring_issue.delete", TargetType: "recurring_issue", TargetID: record.ID, Before: record})
+	sendJSON(w, http.StatusOK, map[string]any{"deleted": true, "id": record.ID})
+}
+
+// RunRecurringIssue opens the next instance immediately. The skip_if_open
+// check still applies and the scheduled next run is left alone.
+func (h *IssuesHandler) RunRecurringIssue(w http.ResponseWriter, r *http.Request) {
+	record, ok := h.loadRecurringIssue(w, r)
+	if !ok {
+		return
+	}
+	now := time.Now().UTC()
+	outcome, err := h.recurringIssueGenerator().Generate(r.Context(), *record, now)
+	if err != nil {
+		message := err.Error()
+		_, _ = h.RecurringStore.RecordRun(r.Context(), store.RecordRecurringIssueRunInput{
+			ID:                record.ID,
+			Status:            store.RecurringIssueRunError,
+			Error:             &message,
+			RanAt:             now,
+			PreserveNextRunAt: true,
+		})
+		if errors.Is(err, store.ErrValidation) || errors.Is(err, store.ErrNotFound) || errors.Is(err, store.ErrForbidden) {
+			handleRecurringIssueError(w, err)
+			return
+		}
+		log.Printf("[recurring-issues] run %s failed: %v", record.ID, err)
+		sendJSON(w, http.StatusInternalServerError, errorResponse{Error: "failed to create recurring issue"})
+		return
+	}
+
+	input := store.RecordRecurringIssueRunInput{
+		ID:                record.ID,
+		Status:            outcome.Status,
+		RanAt:             now,
+		PreserveNextRunAt: true,
+	}
+	if outcome.Issue != nil {
+		input.IssueID = &outcome.Issue.ID
+	}
+	if outcome.Reason != "" {
+		input.Error = &outcome.Reason
+	}
+	updated, err := h.RecurringStore.RecordRun(r.Context(), input)
+	if err != nil {
+		handleRecurringIssueError(w, err)
+		return
+	}
+	status := http.StatusOK
+	if outcome.Issue != nil {
+		status = http.StatusCreated
+	}
+	sendJSON(w, status, recurringIssueRunResponse{Outcome: outcome, RecurringIssue: updated})
+}
+
+func (h *IssuesHandler) recurringIssueGenerator() *scheduler.RecurringIssueGenerator {
+	generator := &scheduler.RecurringIssueGenerator{
+		Issues:    h.IssueStore,
+		Flows:     h.FlowStore,
+		Templates: h.TemplateStore,
+		Logf:      log.Printf,
+	}
+	if h.DB != nil {
+		generator.Labels = store.NewLabelStore(h.DB)
+	}
+	return generator
+}
+
+// loadRecurringIssue fetches the definition named in the URL and checks it
+// belongs to the URL's project.
+func (h *IssuesHandler) loadRecurringIssue(w http.ResponseWriter, r *http.Request) (*store.RecurringIssue, bool) {
+	if h.RecurringStore == nil {
+		sendJSON(w, http.StatusServiceUnavailable, errorResponse{Error: "database not available"})
+		return nil, false
+	}
+	record, err := h.RecurringStore.GetByID(r.Context(), chi.URLParam(r, "recurringID"))
+	if err != nil {
+		handleRecurringIssueError(w, err)
+		return nil, false
+	}
+	if record.ProjectID != strings.TrimSpace(chi.URLParam(r, "id")) {
+		sendJSON(w, http.StatusNotFound, errorResponse{Error: "recurring issue not found"})
+		return nil, false
+	}
+	return record, true
+}
+
+// resolveRecurringIssueRefs turns template and flow names into ids and checks
+// the field values against the issue template, rendering date variables as
+// of now. It writes the error response itself and returns ok=false on error.
+func (h *IssuesHandler) resolveRecurringIssueRefs(
+	w http.ResponseWriter,
+	r *http.Request,
+	projectID string,
+	req recurringIssueRequest,
+) (*string, *string, map[string]string, bool) {
+	fields, err := issueTemplateFieldInput(req.Fields)
+	if err != nil {
+		sendJSON(w, http.StatusBadRequest, errorResponse{Error: err.Error()})
+		return nil, nil, nil, false
+	}
+
+	var templateID *string
+	if ref := derefString(req.TemplateID, ""); ref != "" {
+		if h.TemplateStore == nil {
+			sendJSON(w, http.StatusServiceUnavailable, errorResponse{Error: "database not available"})
+			return nil, nil, nil, false
+		}
+		template, err := h.TemplateStore.Resolve(r.Context(), middleware.WorkspaceFromContext(r.Context()), projectID, ref)
+		if err != nil {
+			handleIssueTemplateError(w, err)
+			return nil, nil, nil, false
+		}
+		vars := scheduler.RecurringIssueVars(time.Now().UTC(), 1)
+		rendered := make(map[string]string, len(fields))
+		for key, value := range fields {
+			rendered[key] = scheduler.RenderRecurringIssueText(value, vars)
+		}
+		if _, err := template.ResolveFieldValues(rendered); err != nil {
+			handleJobsStoreError(w, err)
+			return nil, nil, nil, false
+		}
+		templateID = &template.ID
+	} else if len(fields) > 0 {
+		sendJSON(w, http.StatusBadRequest, errorResponse{Error: "fields require template_id"})
+		return nil, nil, nil, false
+	}
+
+	var flowTemplateID *string
+	if ref := derefString(req.FlowTemplateID, ""); ref != "" {
+		resolved, err := h.resolveFlowTemplateRef(r.Context(), projectID, ref)
+		if err != nil {
+			handleFlowTemplateStoreError(w, err)
+			return nil, nil, nil, false
+		}
+		flowTemplateID = &resolved
+	}
+	return templateID, flowTemplateID, fields, true
+}
+
+// resolveFlowTemplateRef finds a project flow template by id or
+// case-insensitive name.
+func (h *IssuesHandler) resolveFlowTemplateRef(ctx context.Context, projectID, ref string) (string, error) {
+	if h.FlowStore == nil {
+		return "", errIssueHandlerDatabaseUnavailable
+	}
+	templates, err := h.FlowStore.ListTemplatesByProject(ctx, projectID)
+	if err != nil {
+		return "", err
+	}
+	for _, template := range templates {
+		if template.ID == ref || strings.EqualFold(template.Name, ref) {
+			return template.ID, nil
+		}
+	}
+	return "", store.ErrNotFound
+}
+
+// nextRecurringIssueRun validates a schedule and returns its first
+// occurrence from now.
+func nextRecurringIssueRun(
+	kind string,
+	cronExpr *string,
+	intervalMS *int64,
+	runAt *time.Time,
+	timezone string,
+) (*time.Time, error) {
+	spec, err := scheduler.NormalizeScheduleSpec(kind, cronExpr, intervalMS, runAt, timezone)
+	if err != nil {
+		return nil, err
+	}
+	return scheduler.ComputeNextRun(spec, time.Now().UTC(), nil)
+}
+
+// recurringIssueClearableRef maps a resolved reference to the store's update
+// form, where an empty string clears the column.
+func recurringIssueClearableRef(ref *string) *string {
+	if ref == nil {
+		empty := ""
+		return &empty
+	}
+	return ref
+}
+
+func handleRecurringIssueError(w http.ResponseWriter, err error) {
+	if errors.Is(err, store.ErrNotFound) {
+		sendJSON(w, http.StatusNotFound, errorResponse{Error: "recurring issue not found"})
+		return
+	}
+	if errors.Is(err, store.ErrConflict) {
+		sendJSON(w, http.StatusConflict, errorResponse{Error: "a recurring issue with that name already exists"})
+		return
+	}
+	handleJobsStoreError(w, err)
+}
@@ -0,0 +1,139 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/samhotchkiss/otter-camp/internal/middleware"
+	"github.com/samhotchkiss/otter-camp/internal/store"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCreateRecurringIssueValidatesSchedule(t *testing.T) {
+	router := chi.NewRouter()
+	handler := &IssuesHandler{}
+	router.Post("/api/projects/{id}/recurring-issues", handler.CreateRecurringIssue)
+
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/api/projects/p/recurring-issues", strings.NewReader(`{}`)))
+	require.Equal(t, http.StatusServiceUnavailable, rec.Code)
+
+	handler.RecurringStore = store.NewRecurringIssueStore(nil)
+	for body, want := range map[string]string{
+		`{"name":"Weekly"}`: "schedule_kind is required",
+		`{"name":"Weekly","schedule_kind":"cron","cron_expr":"every monday"}`:                        "invalid cron expression",
+		`{"name":"Weekly","schedule_kind":"cron","cron_expr":"0 9 * * 1","timezone":"Mars/Olympus"}`: "invalid timezone",
+		`{"name":"Weekly","schedule_kind":"interval","interval_ms":0}`:                               "interval_ms must be greater than zero",
+		`{"name":"Weekly","schedule":"weekly"}`:                                                      "invalid JSON",
+	} {
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/api/projects/p/recurring-issues", strings.NewReader(body)))
+		require.Equal(t, http.StatusBadRequest, rec.Code, body)
+		require.Contains(t, rec.Body.String(), want, body)
+	}
+}
+
+func TestRecurringIssueRoutesCreateAndRun(t *testing.T) {
+	db := setupMessageTestDB(t)
+	orgID := insertMessageTestOrganization(t, db, "recurring-issues-api-org")
+	projectID := insertProjectTestProject(t, db, orgID, "Recurring Issues Project")
+	agentID := insertMessageTestAgent(t, db, orgID, "analyst-1")
+	ctx := issueTestCtx(orgID)
+
+	flows := store.NewProjectFlowStore(db)
+	flow, err := flows.CreateTemplate(ctx, store.CreateProjectFlowTemplateInput{ProjectID: projectID, Name: "Report"})
+	require.NoError(t, err)
+	_, err = flows.ReplaceTemplateSteps(ctx, flow.ID, []store.CreateProjectFlowTemplateStepInput{
+		{StepKey: "draft", Label: "Draft", Role: store.FlowRoleWorker},
+	})
+	require.NoError(t, err)
+	templates := store.NewIssueTemplateStore(db)
+	_, err = templates.Save(ctx, orgID, store.SaveIssueTemplateInput{
+		ProjectID: projectID,
+		Name:      "Metrics",
+		Body:      "Period: {{period}}",
+		Fields:    []store.IssueTemplateField{{Key: "period", Type: "text", Required: true}},
+	})
+	require.NoError(t, err)
+
+	handler := &IssuesHandler{
+		IssueStore:     store.NewProjectIssueStore(db),
+		ProjectStore:   store.NewProjectStore(db),
+		FlowStore:      flows,
+		TemplateStore:  templates,
+		RecurringStore: store.NewRecurringIssueStore(db),
+		DB:             db,
+	}
+	router := chi.NewRouter()
+	router.With(middleware.OptionalWorkspace).Post("/api/projects/{id}/recurring-issues", handler.CreateRecurringIssue)
+	router.With(middleware.OptionalWorkspace).Get("/api/projects/{id}/recurring-issues", handler.ListRecurringIssues)
+	router.With(middleware.OptionalWorkspace).Post("/api/projects/{id}/recurring-issues/{recurringID}/run", handler.RunRecurringIssue)
+	router.With(middleware.OptionalWorkspace).Patch("/api/projects/{id}/recurring-issues/{recurringID}", handler.PatchRecurringIssue)
+
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/api/projects/"+projectID+"/recurring-issues?org_id="+orgID,
+		strings.NewReader(`{"name":"Weekly metrics","title_template":"Weekly metrics report — {{date}}","template_id":"metrics","fields":{}}`)))
+	require.Equal(t, http.StatusBadRequest, rec.Code)
+	require.Contains(t, rec.Body.String(), "schedule_kind is required")
+
+	createBody := `{
+		"name":"Weekly metrics",
+		"title_template":"Weekly metrics report — {{date}}",
+		"template_id":"metrics",
+		"fields":{"period":"{{iso_week}}"},
+		"owner_agent_id":"` + agentID + `",
+		"flow_template_id":"report",
+		"schedule_kind":"cron",
+		"cron_expr":"0 9 * * 1",
+		"timezone":"America/New_York",
+		"skip_if_open":true
+	}`
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/api/projects/"+projectID+"/recurring-issues?org_id="+orgID, strings.NewReader(createBody)))
+	require.Equal(t, http.StatusCreated, rec.Code, rec.Body.String())
+	var created store.RecurringIssue
+	require.NoError(t, json.NewDecoder(rec.Body).Decode(&created))
+	require.Equal(t, flow.ID, *created.FlowTemplateID)
+	require.NotNil(t, created.IssueTemplateID)
+	require.NotNil(t, created.NextRunAt)
+
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/api/projects/"+projectID+"/recurring-issues/"+created.ID+"/run?org_id="+orgID, nil))
+	require.Equal(t, http.StatusCreated, rec.Code, rec.Body.String())
+	var run recurringIssueRunResponse
+	require.NoError(t, json.NewDecoder(rec.Body).Decode(&run))
+	require.Equal(t, store.RecurringIssueRunCreated, run.Outcome.Status)
+	require.NotNil(t, run.Outcome.Issue)
+	require.True(t, strings.HasPrefix(run.Outcome.Issue.Title, "Weekly metrics report — 20"))
+	require.Contains(t, *run.Outcome.Issue.Body, "Period: 20")
+	require.Equal(t, agentID, *run.Outcome.Issue.OwnerAgentID)
+	require.Equal(t, "draft", *run.Outcome.Issue.FlowStepKey)
+	require.Equal(t, 1, run.RecurringIssue.RunCount)
+	require.True(t, created.NextRunAt.Equal(*run.RecurringIssue.NextRunAt))
+
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/api/projects/"+projectID+"/recurring-issues/"+created.ID+"/run?org_id="+orgID, nil))
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	run = recurringIssueRunResponse{}
+	require.NoError(t, json.NewDecoder(rec.Body).Decode(&run))
+	require.Equal(t, store.RecurringIssueRunSkipped, run.Outcome.Status)
+	require.Contains(t, run.Outcome.Reason, "still open")
+
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodPatch, "/api/projects/"+projectID+"/recurring-issues/"+created.ID+"?org_id="+orgID,
+		strings.NewReader(`{"enabled":false}`)))
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	var patched store.RecurringIssue
+	require.NoError(t, json.NewDecoder(rec.Body).Decode(&patched))
+	require.False(t, patched.Enabled)
+	require.Nil(t, patched.NextRunAt)
+
+	otherProjectID := insertProjectTestProject(t, db, orgID, "Other Recurring Project")
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/api/projects/"+otherProjectID+"/recurring-issues/"+created.ID+"/run?org_id="+orgID, nil))
+	require.Equal(t, http.StatusNotFound, rec.Code)
+}
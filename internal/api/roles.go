@@ -0,0 +1,307 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/samhotchkiss/otter-camp/internal/middleware"
+	"github.com/samhotchkiss/otter-camp/internal/store"
+)
+
+// RolesHandler manages custom roles and role bindings.
+type RolesHandler struct {
+	Store *store.RoleStore
+}
+
+type rolePayload struct {
+	ID           string   `json:"id,omitempty"`
+	Name         string   `json:"name"`
+	Description  string   `json:"description"`
+	Builtin      bool     `json:"builtin"`
+	Capabilities []string `json:"capabilities"`
+	CreatedAt    string   `json:"created_at,omitempty"`
+	UpdatedAt    string   `json:"updated_at,omitempty"`
+}
+
+type roleListResponse struct {
+	Items []rolePayload `json:"items"`
+	Total int           `json:"total"`
+}
+
+type roleBindingPayload struct {
+	ID        string  `json:"id"`
+	OrgID     string  `json:"org_id"`
+	UserID    string  `json:"user_id"`
+	Role      string  `json:"role"`
+	ProjectID *string `json:"project_id,omitempty"`
+	CreatedBy *string `json:"created_by,omitempty"`
+	CreatedAt string  `json:"created_at"`
+}
+
+type roleBindingListResponse struct {
+	Items []roleBindingPayload `json:"items"`
+	Total int                  `json:"total"`
+}
+
+var builtinRoleDescriptions = map[string]string{
+	RoleOwner:      "Full control of the workspace",
+	RoleMaintainer: "Manage projects, pipelines, agents, jobs and deploys",
+	RoleMember:     "Work on issues, project content and memory",
+	RoleViewer:     "Read-only access",
+}
+
+// Capabilities handles GET /api/admin/capabilities
+func (h *RolesHandler) Capabilities(w http.ResponseWriter, r *http.Request) {
+	sendJSON(w, http.StatusOK, map[string]any{
+		"items": capabilityDefinitions,
+		"total": len(capabilityDefinitions),
+	})
+}
+
+// ListRoles handles GET /api/admin/roles. Built-in roles come first.
+func (h *RolesHandler) ListRoles(w http.ResponseWriter, r *http.Request) {
+	if h.Store == nil {
+		sendJSON(w, http.StatusServiceUnavailable, errorResponse{Error: "database not available"})
+		return
+	}
+	roles, err := h.Store.ListRoles(r.Context())
+	if err != nil {
+		handleJobsStoreError(w, err)
+		return
+	}
+	items := make([]rolePayload, 0, len(roles)+len(roleCapabilityMatrix))
+	for _, name := range []string{RoleOwner, RoleMaintainer, RoleMember, RoleViewer} {
+		items = append(items, rolePayload{
+			Name:         name,
+			Description:  builtinRoleDescriptions[name],
+			Builtin:      true,
+			Capabilities: builtinRoleCapabilities(name),
+		})
+	}
+	for _, role := range roles {
+		items = append(items, toRolePayload(role))
+	}
+	sendJSON(w, http.StatusOK, roleListResponse{Items: items, Total: len(items)})
+}
+
+// CreateRole handles POST /api/admin/roles
+func (h *RolesHandler) CreateRole(w http.ResponseWriter, r *http.Request) {
+	if h.Store == nil {
+		sendJSON(w, http.StatusServiceUnavailable, errorResponse{Error: "database not available"})
+		return
+	}
+
+	var req struct {
+		Name         string   `json:"name"`
+		Description  string   `json:"description"`
+		Capabilities []string `json:"capabilities"`
+	}
+	decoder := json.NewDecoder(r.Body)
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&req); err != nil {
+		sendJSON(w, http.StatusBadRequest, errorResponse{Error: "invalid JSON"})
+		return
+	}
+	if err := validateRoleCapabilities(req.Capabilities); err != nil {
+		sendJSON(w, http.StatusBadRequest, errorResponse{Error: err.Error()})
+		return
+	}
+
+	created, err := h.Store.CreateRole(r.Context(), store.CreateOrgRoleInput{
+		Name:         req.Name,
+		Description:  req.Description,
+		Capabilities: req.Capabilities,
+	})
+	if err != nil {
+		handleJobsStoreError(w, err)
+		return
+	}
+	payload := toRolePayload(*created)
+	recordAudit(r, auditEvent{Action: "role.create", TargetType: "role", TargetID: created.ID, After: payload})
+	sendJSON(w, http.StatusCreated, payload)
+}
+
+// PatchRole handles PATCH /api/admin/roles/{id}
+func (h *RolesHandler) PatchRole(w http.ResponseWriter, r *http.Request) {
+	if h.Store == nil {
+		sendJSON(w, http.StatusServiceUnavailable, errorResponse{Error: "database not available"})
+		return
+	}
+
+	var req struct {
+		Description  *string   `json:"description"`
+		Capabilities *[]string `json:"capabilities"`
+	}
+	decoder := json.NewDecoder(r.Body)
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&req); err != nil {
+		sendJSON(w, http.StatusBadRequest, errorResponse{Error: "invalid JSON"})
+		return
+	}
+	if req.Capabilities != nil {
+		if err := validateRoleCapabilities(*req.Capabilities); err != nil {
+			sendJSON(w, http.StatusBadRequest, errorResponse{Error: err.Error()})
+			return
+		}
+	}
+
+	roleID := chi.URLParam(r, "id")
+	var before *rolePayload
+	if existing, err := h.Store.GetRole(r.Context(), roleID); err == nil {
+		payload := toRolePayload(*existing)
+		before = &payload
+	}
+
+	updated, err := h.Store.UpdateRole(r.Context(), roleID, store.UpdateOrgRoleInput{
+		Description:  req.Description,
+		Capabilities: req.Capabilities,
+	})
+	if err != nil {
+		handleJobsStoreError(w, err)
+		return
+	}
+	payload := toRolePayload(*updated)
+	recordAudit(r, auditEvent{Action: "role.update", TargetType: "role", TargetID: updated.ID, Before: before, After: payload})
+	sendJSON(w, http.StatusOK, payload)
+}
+
+// DeleteRole handles DELETE /api/admin/roles/{id}
+func (h *RolesHandler) DeleteRole(w http.ResponseWriter, r *http.Request) {
+	if h.Store == nil {
+		sendJSON(w, http.StatusServiceUnavailable, errorResponse{Error: "database not available"})
+		return
+	}
+	roleID := chi.URLParam(r, "id")
+	var before *rolePayload
+	if existing, err := h.Store.GetRole(r.Context(), roleID); err == nil {
+		payload := toRolePayload(*existing)
+		before = &payload
+	}
+	if err := h.Store.DeleteRole(r.Context(), roleID); err != nil {
+		handleJobsStoreError(w, err)
+		return
+	}
+	recordAudit(r, auditEvent{Action: "role.delete", TargetType: "role", TargetID: roleID, Before: before})
+	sendJSON(w, http.StatusOK, map[string]bool{"ok": true})
+}
+
+// ListBindings handles GET /api/admin/role-bindings?user_id=&project_id=
+func (h *RolesHandler) ListBindings(w http.ResponseWriter, r *http.Request) {
+	if h.Store == nil {
+		sendJSON(w, http.StatusServiceUnavailable, errorResponse{Error: "database not available"})
+		return
+	}
+	bindings, err := h.Store.ListBindings(r.Context(), store.RoleBindingFilter{
+		UserID:    r.URL.Query().Get("user_id"),
+		ProjectID: r.URL.Query().Get("project_id"),
+	})
+	if err != nil {
+		handleJobsStoreError(w, err)
+		return
+	}
+	items := make([]roleBindingPayload, 0, len(bindings))
+	for _, binding := range bindings {
+		items = append(items, toRoleBindingPayload(binding))
+	}
+	sendJSON(w, http.StatusOK, roleBindingListResponse{Items: items, Total: len(items)})
+}
+
+// CreateBinding handles POST /api/admin/role-bindings
+func (h *RolesHandler) CreateBinding(w http.ResponseWriter, r *http.Request) {
+	if h.Store == nil {
+		sendJSON(w, http.StatusServiceUnavailable, errorResponse{Error: "database not available"})
+		return
+	}
+
+	var req struct {
+		UserID    string  `json:"user_id"`
+		Role      string  `json:"role"`
+		ProjectID *string `json:"project_id"`
+	}
+	decoder := json.NewDecoder(r.Body)
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&req); err != nil {
+		sendJSON(w, http.StatusBadRequest, errorResponse{Error: "invalid JSON"})
+		return
+	}
+
+	var createdBy *string
+	if userID := middleware.UserFromContext(r.Context()); userID != "" {
+		createdBy = &userID
+	}
+	created, err := h.Store.CreateBinding(r.Context(), store.CreateRoleBindingInput{
+		UserID:    req.UserID,
+		Role:      req.Role,
+		ProjectID: req.ProjectID,
+		CreatedBy: createdBy,
+	})
+	if err != nil {
+		handleJobsStoreError(w, err)
+		return
+	}
+	payload := toRoleBindingPayload(*created)
+	recordAudit(r, auditEvent{Action: "role_binding.create", TargetType: "role_binding", TargetID: created.ID, After: payload})
+	sendJSON(w, http.StatusCreated, payload)
+}
+
+// DeleteBinding handles DELETE /api/admin/role-bindings/{id}
+func (h *RolesHandler) DeleteBinding(w http.ResponseWriter, r *http.Request) {
+	if h.Store == nil {
+		sendJSON(w, http.StatusServiceUnavailable, errorResponse{Error: "database not available"})
+		return
+	}
+	bindingID := chi.URLParam(r, "id")
+	if err := h.Store.DeleteBinding(r.Context(), bindingID); err != nil {
+		handleJobsStoreError(w, err)
+		return
+	}
+	recordAudit(r, auditEvent{Action: "role_binding.delete", TargetType: "role_binding", TargetID: bindingID})
+	sendJSON(w, http.StatusOK, map[string]bool{"ok": true})
+}
+
+func validateRoleCapabilities(capabilities []string) error {
+	unknown := make([]string, 0)
+	for _, capability := range capabilities {
+		capability = strings.TrimSpace(strings.ToLower(capability))
+		if capability != "" && !isKnownCapability(capability) {
+			unknown = append(unknown, capability)
+		}
+	}
+	if len(unknown) == 0 {
+		return nil
+	}
+	sort.Strings(unknown)
+	return fmt.Errorf("unknown capabilities: %s", strings.Join(unknown, ", "))
+}
+
+func toRolePayload(role store.OrgRole) rolePayload {
+	capabilities := role.Capabilities
+	if capabilities == nil {
+		capabilities = []string{}
+	}
+	return rolePayload{
+		ID:           role.ID,
+		Name:         role.Name,
+		Description:  role.Description,
+		Capabilities: capabilities,
+		CreatedAt:    role.CreatedAt.UTC().Format(time.RFC3339),
+		UpdatedAt:    role.UpdatedAt.UTC().Format(time.RFC3339),
+	}
+}
+
+func toRoleBindingPayload(binding store.RoleBinding) roleBindingPayload {
+	return roleBindingPayload{
+		ID:        binding.ID,
+		OrgID:     binding.OrgID,
+		UserID:    binding.UserID,
+		Role:      binding.Role,
+		ProjectID: binding.ProjectID,
+		CreatedBy: binding.CreatedBy,
+		CreatedAt: binding.CreatedAt.UTC().Format(time.RFC3339),
+	}
+}
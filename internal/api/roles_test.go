@@ -0,0 +1,29 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidateRoleCapabilitiesRejectsUnknownCapabilities(t *testing.T) {
+	require.NoError(t, validateRoleCapabilities([]string{CapabilityIssuesWrite, " Projects.Write ", ""}))
+
+	err := validateRoleCapabilities([]string{CapabilityIssuesWrite, "issues.delete", "billing.manage"})
+	require.EqualError(t, err, "unknown capabilities: billing.manage, issues.delete")
+}
+
+func TestRolesHandlerRequiresDatabase(t *testing.T) {
+	handler := &RolesHandler{}
+	rec := httptest.NewRecorder()
+	handler.CreateRole(rec, httptest.NewRequest(http.MethodPost, "/api/admin/roles", strings.NewReader(`{}`)))
+	require.Equal(t, http.StatusServiceUnavailable, rec.Code)
+
+	rec = httptest.NewRecorder()
+	handler.Capabilities(rec, httptest.NewRequest(http.MethodGet, "/api/admin/capabilities", nil))
+	require.Equal(t, http.StatusOK, rec.Code)
+	require.Contains(t, rec.Body.String(), CapabilityRolesManage)
+}
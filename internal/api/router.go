@@ -17,7 +17,9 @@ import (
 	"github.com/go-chi/cors"
 	"github.com/samhotchkiss/otter-camp/internal/automigrate"
 	"github.com/samhotchkiss/otter-camp/internal/gitserver"
+	importer "github.com/samhotchkiss/otter-camp/internal/import"
 	"github.com/samhotchkiss/otter-camp/internal/middleware"
+	"github.com/samhotchkiss/otter-camp/internal/sso"
 	"github.com/samhotchkiss/otter-camp/internal/store"
 	"github.com/samhotchkiss/otter-camp/internal/ws"
 )
@@ -35,6 +37,8 @@ type HealthResponse struct {
 
 func NewRouter() http.Handler {
 	r := chi.NewRouter()
+	r.Use(HTTPMetrics)
+	r.Use(HTTPTracing)
 
 	// Compress responses (gzip/deflate) — reduces static asset sizes and helps Railway proxy
 	r.Use(chimiddleware.Compress(5, "text/html", "text/css", "application/javascript", "application/json", "image/svg+xml"))
@@ -73,7 +77,7 @@ func NewRouter() http.Handler {
 		},
 		AllowedMethods:   []string{"GET", "POST", "PUT", "PATCH", "DELETE", "OPTIONS"},
 		AllowedHeaders:   []string{"Accept", "Authorization", "Content-Type", "X-CSRF-Token", "X-Org-ID", "X-Otter-Org", "X-Workspace-ID", "X-Session-Token"},
-		ExposedHeaders:   []string{"Link"},
+		ExposedHeaders:   []string{"Link", "RateLimit-Limit", "RateLimit-Remaining", "RateLimit-Reset", "RateLimit-Policy", "Retry-After"},
 		AllowCredentials: true,
 		MaxAge:           300,
 	}))
@@ -90,14 +94,23 @@ func NewRouter() http.Handler {
 	})
 
 	r.Get("/health", handleHealthWithDB(db))
+	r.Get("/metrics", metricsHandler())
 	r.Get("/api/feed", FeedHandlerV2)
 
 	webhookHandler := &WebhookHandler{Hub: hub}
 	feedPushHandler := NewFeedPushHandler(hub)
 	execApprovalsHandler := &ExecApprovalsHandler{Hub: hub}
+	execApprovalRulesHandler := &ExecApprovalRulesHandler{}
+	backupHandler := &BackupHandler{DB: db}
+	ssoHandler := &SSOHandler{DB: db}
+	rolesHandler := &RolesHandler{}
+	auditHandler := &AuditHandler{}
+	rateLimitsHandler := &RateLimitsHandler{}
+	usageHandler := &UsageHandler{DB: db, Hub: hub}
 	taskHandler := &TaskHandler{Hub: hub}
 	openClawWSHandler := ws.NewOpenClawHandler(hub, db)
 	registerOpenClawHandler(openClawWSHandler)
+	registerRuntimeMetrics(db, hub, openClawWSHandler)
 	emissionBuffer := NewEmissionBuffer(defaultEmissionBufferSize)
 	emissionsHandler := &EmissionsHandler{Buffer: emissionBuffer, Hub: hub}
 	messageHandler := &MessageHandler{OpenClawDispatcher: openClawWSHandler, Hub: hub}
@@ -130,6 +143,8 @@ func NewRouter() http.Handler {
 	labelsHandler := &LabelsHandler{}
 	taxonomyHandler := &TaxonomyHandler{}
 	agentActivityHandler := &AgentActivityHandler{DB: db, Hub: hub}
+	agentReviewHandler := &AgentReviewHandler{}
+	issueAnalyticsHandler := &IssueAnalyticsHandler{}
 	flowTemplatesHandler := &FlowTemplatesHandler{}
 	conversationTokenHandler := &ConversationTokenHandler{}
 	waitlistHandler := NewWaitlistHandler(db)
@@ -159,6 +174,15 @@ func NewRouter() http.Handler {
 		activityStore = store.NewActivityStore(db)
 		chatThreadStore = store.NewChatThreadStore(db)
 		chatsHandler.ChatThreadStore = chatThreadStore
+		emissionsHandler.Store = store.NewEmissionStore(db, emissionRetention())
+		execApprovalRulesHandler.Store = store.NewExecApprovalRuleStore(db)
+		ssoHandler.Store = store.NewSSOProviderStore(db)
+		ssoHandler.OIDC = sso.NewOIDC(nil)
+		rolesHandler.Store = store.NewRoleStore(db)
+		auditHandler.Store = store.NewAuditLogStore(db)
+		rateLimitsHandler.Store = store.NewRateLimitStore(db)
+		usageHandler.Store = store.NewUsageStore(db)
+		openclawSyncHandler.EmissionStore = emissionsHandler.Store
 		adminConnectionsHandler.EventStore = store.NewConnectionEventStore(db)
 		adminConfigHandler.EventStore = adminConnectionsHandler.EventStore
 		githubSyncDeadLettersHandler.Store = githubSyncJobStore
@@ -172,6 +196,10 @@ func NewRouter() http.Handler {
 		projectChatHandler.QuestionnaireStore = store.NewQuestionnaireStore(db)
 		projectChatHandler.DB = db
 		issuesHandler.IssueStore = store.NewProjectIssueStore(db)
+		issuesHandler.SavedViewStore = store.NewIssueSavedViewStore(db)
+		issuesHandler.TemplateStore = store.NewIssueTemplateStore(db)
+		issuesHandler.RecurringStore = store.NewRecurringIssueStore(db)
+		issuesHandler.IssueImporter = importer.NewIssueImportRunner(db)
 		issuesHandler.AgentStore = agentStore
 		issuesHandler.ChatThreadStore = chatThreadStore
 		issuesHandler.QuestionnaireStore = store.NewQuestionnaireStore(db)
@@ -199,6 +227,9 @@ func NewRouter() http.Handler {
 		labelsHandler.DB = db
 		taxonomyHandler.Store = store.NewEllieTaxonomyStore(db)
 		agentActivityHandler.Store = store.NewAgentActivityEventStore(db)
+		agentActivityHandler.UsageStore = usageHandler.Store
+		agentReviewHandler.Store = store.NewAgentReviewStore(db)
+		issueAnalyticsHandler.Store = store.NewIssueAnalyticsStore(db)
 		conversationTokenHandler.Store = store.NewConversationTokenStore(db)
 		pipelineRolesHandler.Store = store.NewPipelineRoleStore(db)
 		pipelineStepsHandler.Store = store.NewPipelineStepStore(db)
@@ -216,6 +247,7 @@ func NewRouter() http.Handler {
 		jobsHandler.DB = db
 		adminAgentsHandler.Store = agentStore
 		adminAgentsHandler.ProjectStore = projectStore
+		backupHandler.ProjectStore = projectStore
 		adminAgentsHandler.ProjectRepos = projectRepoStore
 		adminAgentsHandler.EventStore = store.NewConnectionEventStore(db)
 		projectIssueSyncHandler.ProjectRepos = githubIntegrationHandler.ProjectRepos
@@ -293,6 +325,10 @@ func NewRouter() http.Handler {
 
 	// All API routes under /api prefix
 	r.Route("/api", func(r chi.Router) {
+		r.Use(AuthFailureRateLimit(db))
+		r.Use(APIKeyAuth(db))
+		r.Use(RateLimit(db))
+		r.Use(AuditRecorder(db))
 		r.Post("/waitlist", waitlistHandler.Handle)
 		r.Get("/search", SearchHandler)
 		r.Get("/commands/search", CommandSearchHandler)
@@ -304,6 +340,11 @@ func NewRouter() http.Handler {
 		r.Get("/auth/exchange", HandleAuthExchange)
 		r.Post("/auth/magic", HandleMagicLink)
 		r.Get("/auth/validate", HandleValidateToken)
+		r.Get("/auth/sso/oidc/callback", ssoHandler.OIDCCallback)
+		r.Post("/auth/sso/saml/acs", ssoHandler.SAMLACS)
+		r.Get("/auth/sso/{org}/providers", ssoHandler.Providers)
+		r.Get("/auth/sso/{org}/start", ssoHandler.Start)
+		r.Get("/auth/sso/{org}/saml/{id}/metadata", ssoHandler.SAMLMetadata)
 		r.Post("/onboarding/bootstrap", HandleOnboardingBootstrap)
 		r.Get("/orgs", HandleOrgsList(db))
 		r.Get("/git/tokens", HandleGitTokensList)
@@ -317,154 +358,191 @@ func NewRouter() http.Handler {
 		r.Delete("/user/prefixes/{id}", HandleUserCommandPrefixesDelete)
 		r.Post("/webhooks/openclaw", webhookHandler.OpenClawHandler)
 		r.With(middleware.OptionalWorkspace).Get("/emissions/recent", emissionsHandler.Recent)
+		r.With(middleware.OptionalWorkspace).Get("/emissions/latest", emissionsHandler.Latest)
+		r.With(middleware.OptionalWorkspace).Get("/emissions/stream", emissionsHandler.Stream)
 		r.With(middleware.OptionalWorkspace).Post("/emissions", emissionsHandler.Ingest)
 		r.Get("/approvals/exec", execApprovalsHandler.List)
-		r.Post("/approvals/exec/{id}/respond", execApprovalsHandler.Respond)
+		r.With(middleware.OptionalWorkspace).Post("/approvals/exec/{id}/respond", execApprovalsHandler.Respond)
+		r.With(middleware.OptionalWorkspace).Get("/approvals/exec/rules", execApprovalRulesHandler.List)
+		r.With(middleware.OptionalWorkspace, RequireCapability(db, CapabilityExecApprovalsManage)).Post("/approvals/exec/rules", execApprovalRulesHandler.Create)
+		r.With(middleware.OptionalWorkspace, RequireCapability(db, CapabilityExecApprovalsManage)).Post("/approvals/exec/rules/dry-run", execApprovalRulesHandler.DryRun)
+		r.With(middleware.OptionalWorkspace, RequireCapability(db, CapabilityExecApprovalsManage)).Patch("/approvals/exec/rules/{id}", execApprovalRulesHandler.Patch)
+		r.With(middleware.OptionalWorkspace, RequireCapability(db, CapabilityExecApprovalsManage)).Delete("/approvals/exec/rules/{id}", execApprovalRulesHandler.Delete)
 		r.With(middleware.OptionalWorkspace).Get("/inbox", HandleInbox)
 		r.Get("/tasks", taskHandler.ListTasks)
-		r.Post("/tasks", taskHandler.CreateTask)
+		r.With(AuthorizeCapability(db, CapabilityIssuesWrite, nil)).Post("/tasks", taskHandler.CreateTask)
 		r.With(middleware.OptionalWorkspace).Get("/agents", agentsHandler.List)
 		r.With(middleware.OptionalWorkspace).Get("/agents/{id}/whoami", agentsHandler.WhoAmI)
 		r.With(middleware.OptionalWorkspace).Get("/agents/{id}/memory", agentsHandler.GetMemory)
-		r.With(middleware.OptionalWorkspace).Post("/agents/{id}/memory", agentsHandler.CreateMemory)
+		r.With(middleware.OptionalWorkspace, AuthorizeCapability(db, CapabilityMemoryWrite, nil)).Post("/agents/{id}/memory", agentsHandler.CreateMemory)
 		r.With(middleware.OptionalWorkspace).Get("/agents/{id}/memory/search", agentsHandler.SearchMemory)
 		r.With(middleware.OptionalWorkspace).Get("/compliance/rules", complianceRulesHandler.List)
 		r.With(middleware.OptionalWorkspace).Post("/compliance/rules", complianceRulesHandler.Create)
 		r.With(middleware.OptionalWorkspace).Patch("/compliance/rules/{id}", complianceRulesHandler.Patch)
 		r.With(middleware.OptionalWorkspace).Post("/compliance/rules/{id}/disable", complianceRulesHandler.Disable)
 		r.With(middleware.OptionalWorkspace).Get("/workflows", workflowsHandler.List)
-		r.With(middleware.OptionalWorkspace).Patch("/workflows/{id}", workflowsHandler.Toggle)
-		r.With(middleware.OptionalWorkspace).Post("/workflows/{id}/run", workflowsHandler.Run)
+		r.With(middleware.OptionalWorkspace, AuthorizeCapability(db, CapabilityPipelinesManage, projectScope("id"))).Patch("/workflows/{id}", workflowsHandler.Toggle)
+		r.With(middleware.OptionalWorkspace, AuthorizeCapability(db, CapabilityPipelinesManage, projectScope("id"))).Post("/workflows/{id}/run", workflowsHandler.Run)
 		r.With(middleware.OptionalWorkspace).Get("/projects", projectsHandler.List)
 		r.With(middleware.OptionalWorkspace).Get("/projects/{id}", projectsHandler.Get)
-		r.With(middleware.OptionalWorkspace).Patch("/projects/{id}", projectsHandler.Patch)
-		r.With(middleware.OptionalWorkspace).Delete("/projects/{id}", projectsHandler.Delete)
+		r.With(middleware.OptionalWorkspace, AuthorizeCapability(db, CapabilityProjectsManage, projectScope("id"))).Patch("/projects/{id}", projectsHandler.Patch)
+		r.With(middleware.OptionalWorkspace, AuthorizeCapability(db, CapabilityProjectsManage, projectScope("id"))).Delete("/projects/{id}", projectsHandler.Delete)
 		r.With(middleware.OptionalWorkspace).Get("/projects/{id}/runs", projectsHandler.ListRuns)
 		r.With(middleware.OptionalWorkspace).Get("/projects/{id}/runs/latest", projectsHandler.GetLatestRun)
-		r.With(middleware.OptionalWorkspace).Post("/projects/{id}/runs/trigger", projectsHandler.TriggerRun)
-		r.With(middleware.OptionalWorkspace).Patch("/projects/{id}/settings", projectsHandler.UpdateSettings)
+		r.With(middleware.OptionalWorkspace, AuthorizeCapability(db, CapabilityProjectsManage, projectScope("id"))).Post("/projects/{id}/runs/trigger", projectsHandler.TriggerRun)
+		r.With(middleware.OptionalWorkspace, AuthorizeCapability(db, CapabilityProjectsManage, projectScope("id"))).Patch("/projects/{id}/settings", projectsHandler.UpdateSettings)
 		r.With(middleware.OptionalWorkspace).Get("/projects/{id}/pipeline-roles", pipelineRolesHandler.Get)
-		r.With(middleware.OptionalWorkspace).Put("/projects/{id}/pipeline-roles", pipelineRolesHandler.Put)
+		r.With(middleware.OptionalWorkspace, AuthorizeCapability(db, CapabilityPipelinesManage, projectScope("id"))).Put("/projects/{id}/pipeline-roles", pipelineRolesHandler.Put)
 		r.With(middleware.OptionalWorkspace).Get("/projects/{id}/flow-templates", flowTemplatesHandler.List)
-		r.With(middleware.OptionalWorkspace).Post("/projects/{id}/flow-templates", flowTemplatesHandler.Create)
+		r.With(middleware.OptionalWorkspace, AuthorizeCapability(db, CapabilityPipelinesManage, projectScope("id"))).Post("/projects/{id}/flow-templates", flowTemplatesHandler.Create)
 		r.With(middleware.OptionalWorkspace).Get("/projects/{id}/flow-templates/{flowID}", flowTemplatesHandler.Get)
-		r.With(middleware.OptionalWorkspace).Patch("/projects/{id}/flow-templates/{flowID}", flowTemplatesHandler.Update)
-		r.With(middleware.OptionalWorkspace).Delete("/projects/{id}/flow-templates/{flowID}", flowTemplatesHandler.Delete)
-		r.With(middleware.OptionalWorkspace).Put("/projects/{id}/flow-templates/{flowID}/steps", flowTemplatesHandler.UpdateSteps)
+		r.With(middleware.OptionalWorkspace, AuthorizeCapability(db, CapabilityPipelinesManage, projectScope("id"))).Patch("/projects/{id}/flow-templates/{flowID}", flowTemplatesHandler.Update)
+		r.With(middleware.OptionalWorkspace, AuthorizeCapability(db, CapabilityPipelinesManage, projectScope("id"))).Delete("/projects/{id}/flow-templates/{flowID}", flowTemplatesHandler.Delete)
+		r.With(middleware.OptionalWorkspace, AuthorizeCapability(db, CapabilityPipelinesManage, projectScope("id"))).Put("/projects/{id}/flow-templates/{flowID}/steps", flowTemplatesHandler.UpdateSteps)
+		r.With(middleware.OptionalWorkspace).Get("/projects/{id}/issue-templates", issuesHandler.ListTemplates)
+		r.With(middleware.OptionalWorkspace, AuthorizeCapability(db, CapabilityProjectsWrite, projectScope("id"))).Post("/projects/{id}/issue-templates", issuesHandler.SaveTemplate)
+		r.With(middleware.OptionalWorkspace).Get("/projects/{id}/issue-templates/{templateID}", issuesHandler.GetTemplate)
+		r.With(middleware.OptionalWorkspace, AuthorizeCapability(db, CapabilityProjectsWrite, projectScope("id"))).Delete("/projects/{id}/issue-templates/{templateID}", issuesHandler.DeleteTemplate)
+		r.With(middleware.OptionalWorkspace).Get("/projects/{id}/recurring-issues", issuesHandler.ListRecurringIssues)
+		r.With(middleware.OptionalWorkspace, AuthorizeCapability(db, CapabilityProjectsWrite, projectScope("id"))).Post("/projects/{id}/recurring-issues", issuesHandler.CreateRecurringIssue)
+		r.With(middleware.OptionalWorkspace).Get("/projects/{id}/recurring-issues/{recurringID}", issuesHandler.GetRecurringIssue)
+		r.With(middleware.OptionalWorkspace, AuthorizeCapability(db, CapabilityProjectsWrite, projectScope("id"))).Patch("/projects/{id}/recurring-issues/{recurringID}", issuesHandler.PatchRecurringIssue)
+		r.With(middleware.OptionalWorkspace, AuthorizeCapability(db, CapabilityProjectsWrite, projectScope("id"))).Delete("/projects/{id}/recurring-issues/{recurringID}", issuesHandler.DeleteRecurringIssue)
+		r.With(middleware.OptionalWorkspace, AuthorizeCapability(db, CapabilityProjectsWrite, projectScope("id"))).Post("/projects/{id}/recurring-issues/{recurringID}/run", issuesHandler.RunRecurringIssue)
+		r.With(middleware.OptionalWorkspace, AuthorizeCapability(db, CapabilityProjectsWrite, projectScope("id"))).Post("/projects/{id}/issue-imports", issuesHandler.ImportIssues)
+		r.With(middleware.OptionalWorkspace).Get("/projects/{id}/analytics", issueAnalyticsHandler.Get)
+		r.With(middleware.OptionalWorkspace).Get("/projects/{id}/sub-issue-policy", issuesHandler.GetSubIssuePolicy)
+		r.With(middleware.OptionalWorkspace, AuthorizeCapability(db, CapabilityProjectsWrite, projectScope("id"))).Put("/projects/{id}/sub-issue-policy", issuesHandler.SetSubIssuePolicy)
 		r.With(middleware.OptionalWorkspace).Get("/projects/{id}/pipeline-steps", pipelineStepsHandler.List)
-		r.With(middleware.OptionalWorkspace).Post("/projects/{id}/pipeline-steps", pipelineStepsHandler.Create)
-		r.With(middleware.OptionalWorkspace).Put("/projects/{id}/pipeline-steps/reorder", pipelineStepsHandler.Reorder)
-		r.With(middleware.OptionalWorkspace).Patch("/projects/{id}/pipeline-steps/{stepID}", pipelineStepsHandler.Patch)
-		r.With(middleware.OptionalWorkspace).Delete("/projects/{id}/pipeline-steps/{stepID}", pipelineStepsHandler.Delete)
-		r.With(middleware.OptionalWorkspace).Post("/projects/{id}/pipeline-staffing", pipelineStaffingHandler.Apply)
+		r.With(middleware.OptionalWorkspace, AuthorizeCapability(db, CapabilityPipelinesManage, projectScope("id"))).Post("/projects/{id}/pipeline-steps", pipelineStepsHandler.Create)
+		r.With(middleware.OptionalWorkspace, AuthorizeCapability(db, CapabilityPipelinesManage, projectScope("id"))).Put("/projects/{id}/pipeline-steps/reorder", pipelineStepsHandler.Reorder)
+		r.With(middleware.OptionalWorkspace, AuthorizeCapability(db, CapabilityPipelinesManage, projectScope("id"))).Patch("/projects/{id}/pipeline-steps/{stepID}", pipelineStepsHandler.Patch)
+		r.With(middleware.OptionalWorkspace, AuthorizeCapability(db, CapabilityPipelinesManage, projectScope("id"))).Delete("/projects/{id}/pipeline-steps/{stepID}", pipelineStepsHandler.Delete)
+		r.With(middleware.OptionalWorkspace, AuthorizeCapability(db, CapabilityPipelinesManage, projectScope("id"))).Post("/projects/{id}/pipeline-staffing", pipelineStaffingHandler.Apply)
 		r.With(middleware.OptionalWorkspace).Get("/projects/{id}/deploy-config", deployConfigHandler.Get)
-		r.With(middleware.OptionalWorkspace).Put("/projects/{id}/deploy-config", deployConfigHandler.Put)
+		r.With(middleware.OptionalWorkspace, AuthorizeCapability(db, CapabilityDeploysManage, projectScope("id"))).Put("/projects/{id}/deploy-config", deployConfigHandler.Put)
 		r.With(middleware.OptionalWorkspace).Get("/projects/{id}/chat", projectChatHandler.List)
 		r.With(middleware.OptionalWorkspace).Get("/projects/{id}/chat/search", projectChatHandler.Search)
-		r.With(middleware.OptionalWorkspace).Post("/projects/{id}/chat/messages", projectChatHandler.Create)
-		r.With(middleware.OptionalWorkspace).Post("/projects/{id}/chat/questionnaire", questionnaireHandler.CreateProjectChatQuestionnaire)
-		r.With(middleware.OptionalWorkspace).Post("/projects/{id}/chat/reset", projectChatHandler.ResetSession)
-		r.With(middleware.OptionalWorkspace).Post("/projects/{id}/chat/messages/{messageID}/save-to-notes", projectChatHandler.SaveToNotes)
-		r.With(middleware.OptionalWorkspace).Post("/projects/{id}/content/bootstrap", projectChatHandler.BootstrapContent)
-		r.With(middleware.OptionalWorkspace).Post("/projects/{id}/content/assets", projectChatHandler.UploadContentAsset)
-		r.With(middleware.OptionalWorkspace).Post("/projects/{id}/content/rename", projectChatHandler.RenameContent)
-		r.With(middleware.OptionalWorkspace).Post("/projects/{id}/content/delete", projectChatHandler.DeleteContent)
+		r.With(middleware.OptionalWorkspace, AuthorizeCapability(db, CapabilityProjectsWrite, projectScope("id"))).Post("/projects/{id}/chat/messages", projectChatHandler.Create)
+		r.With(middleware.OptionalWorkspace, AuthorizeCapability(db, CapabilityProjectsWrite, projectScope("id"))).Post("/projects/{id}/chat/questionnaire", questionnaireHandler.CreateProjectChatQuestionnaire)
+		r.With(middleware.OptionalWorkspace, AuthorizeCapability(db, CapabilityProjectsWrite, projectScope("id"))).Post("/projects/{id}/chat/reset", projectChatHandler.ResetSession)
+		r.With(middleware.OptionalWorkspace, AuthorizeCapability(db, CapabilityProjectsWrite, projectScope("id"))).Post("/projects/{id}/chat/messages/{messageID}/save-to-notes", projectChatHandler.SaveToNotes)
+		r.With(middleware.OptionalWorkspace, AuthorizeCapability(db, CapabilityProjectsWrite, projectScope("id"))).Post("/projects/{id}/content/bootstrap", projectChatHandler.BootstrapContent)
+		r.With(middleware.OptionalWorkspace, AuthorizeCapability(db, CapabilityProjectsWrite, projectScope("id"))).Post("/projects/{id}/content/assets", projectChatHandler.UploadContentAsset)
+		r.With(middleware.OptionalWorkspace, AuthorizeCapability(db, CapabilityProjectsWrite, projectScope("id"))).Post("/projects/{id}/content/rename", projectChatHandler.RenameContent)
+		r.With(middleware.OptionalWorkspace, AuthorizeCapability(db, CapabilityProjectsWrite, projectScope("id"))).Post("/projects/{id}/content/delete", projectChatHandler.DeleteContent)
 		r.With(middleware.OptionalWorkspace).Get("/projects/{id}/content/metadata", projectChatHandler.GetContentMetadata)
 		r.With(middleware.OptionalWorkspace).Get("/projects/{id}/content/search", projectChatHandler.SearchContent)
-		r.With(middleware.OptionalWorkspace).Post("/projects/{id}/commits", projectCommitsHandler.Create)
+		r.With(middleware.OptionalWorkspace, AuthorizeCapability(db, CapabilityProjectsWrite, projectScope("id"))).Post("/projects/{id}/commits", projectCommitsHandler.Create)
 		r.With(middleware.OptionalWorkspace).Get("/projects/{id}/commits", projectCommitsHandler.List)
 		r.With(middleware.OptionalWorkspace).Get("/projects/{id}/commits/{sha}", projectCommitsHandler.Get)
 		r.With(middleware.OptionalWorkspace).Get("/projects/{id}/commits/{sha}/diff", projectCommitsHandler.Diff)
 		r.With(middleware.OptionalWorkspace).Get("/projects/{id}/tree", projectTreeHandler.GetTree)
 		r.With(middleware.OptionalWorkspace).Get("/projects/{id}/blob", projectTreeHandler.GetBlob)
 		r.With(middleware.RequireWorkspace).Get("/knowledge", knowledgeHandler.List)
-		r.With(middleware.RequireWorkspace).Post("/knowledge/import", knowledgeHandler.Import)
+		r.With(middleware.RequireWorkspace, AuthorizeCapability(db, CapabilityMemoryWrite, nil)).Post("/knowledge/import", knowledgeHandler.Import)
 		r.With(middleware.RequireWorkspace).Get("/shared-knowledge", sharedKnowledgeHandler.ListForAgent)
 		r.With(middleware.RequireWorkspace).Get("/shared-knowledge/search", sharedKnowledgeHandler.Search)
-		r.With(middleware.RequireWorkspace).Post("/shared-knowledge", sharedKnowledgeHandler.Create)
-		r.With(middleware.RequireWorkspace).Post("/shared-knowledge/{id}/confirm", sharedKnowledgeHandler.Confirm)
-		r.With(middleware.RequireWorkspace).Post("/shared-knowledge/{id}/contradict", sharedKnowledgeHandler.Contradict)
-		r.With(middleware.RequireWorkspace).Post("/memory/entries", memoryHandler.Create)
+		r.With(middleware.RequireWorkspace, AuthorizeCapability(db, CapabilityMemoryWrite, nil)).Post("/shared-knowledge", sharedKnowledgeHandler.Create)
+		r.With(middleware.RequireWorkspace, AuthorizeCapability(db, CapabilityMemoryWrite, nil)).Post("/shared-knowledge/{id}/confirm", sharedKnowledgeHandler.Confirm)
+		r.With(middleware.RequireWorkspace, AuthorizeCapability(db, CapabilityMemoryWrite, nil)).Post("/shared-knowledge/{id}/contradict", sharedKnowledgeHandler.Contradict)
+		r.With(middleware.RequireWorkspace, AuthorizeCapability(db, CapabilityMemoryWrite, nil)).Post("/memory/entries", memoryHandler.Create)
 		r.With(middleware.RequireWorkspace).Get("/memory/entries", memoryHandler.List)
-		r.With(middleware.RequireWorkspace).Delete("/memory/entries/{id}", memoryHandler.Delete)
+		r.With(middleware.RequireWorkspace, AuthorizeCapability(db, CapabilityMemoryWrite, nil)).Delete("/memory/entries/{id}", memoryHandler.Delete)
 		r.With(middleware.RequireWorkspace).Get("/memory/search", memoryHandler.Search)
 		r.With(middleware.RequireWorkspace).Get("/memory/recall", memoryHandler.Recall)
 		r.With(middleware.RequireWorkspace).Get("/memory/evaluations/latest", memoryHandler.LatestEvaluation)
 		r.With(middleware.RequireWorkspace).Get("/memory/evaluations/runs", memoryHandler.ListEvaluations)
-		r.With(middleware.RequireWorkspace).Post("/memory/evaluations/run", memoryHandler.RunEvaluation)
-		r.With(middleware.RequireWorkspace).Post("/memory/evaluations/tune", memoryHandler.TuneEvaluation)
+		r.With(middleware.RequireWorkspace, AuthorizeCapability(db, CapabilityMemoryWrite, nil)).Post("/memory/evaluations/run", memoryHandler.RunEvaluation)
+		r.With(middleware.RequireWorkspace, AuthorizeCapability(db, CapabilityMemoryWrite, nil)).Post("/memory/evaluations/tune", memoryHandler.TuneEvaluation)
 		r.With(middleware.OptionalWorkspace).Get("/memory/events", memoryEventsHandler.List)
 		r.With(middleware.OptionalWorkspace).Get("/projects/{id}/pull-requests", githubPullRequestsHandler.ListByProject)
-		r.With(middleware.OptionalWorkspace).Post("/projects/{id}/pull-requests", githubPullRequestsHandler.CreateForProject)
+		r.With(middleware.OptionalWorkspace, AuthorizeCapability(db, CapabilityProjectsWrite, projectScope("id"))).Post("/projects/{id}/pull-requests", githubPullRequestsHandler.CreateForProject)
 		r.With(middleware.OptionalWorkspace).Get("/issues", issuesHandler.List)
+		r.With(middleware.OptionalWorkspace).Get("/issues/views", issuesHandler.ListViews)
+		r.With(middleware.OptionalWorkspace, AuthorizeCapability(db, CapabilityIssuesWrite, nil)).Post("/issues/views", issuesHandler.SaveView)
+		r.With(middleware.OptionalWorkspace, AuthorizeCapability(db, CapabilityIssuesWrite, nil)).Delete("/issues/views/{viewID}", issuesHandler.DeleteView)
+		r.With(middleware.OptionalWorkspace, AuthorizeCapability(db, CapabilityIssuesWrite, bodyProjectScope("project_id"))).Post("/issues/bulk", issuesHandler.Bulk)
 		r.With(middleware.OptionalWorkspace).Get("/project-tasks", issuesHandler.List)
 		r.With(middleware.OptionalWorkspace).Get("/issues/{id}", issuesHandler.Get)
 		r.With(middleware.OptionalWorkspace).Get("/project-tasks/{id}", issuesHandler.Get)
-		r.With(middleware.OptionalWorkspace).Post("/issues/{id}/pipeline/step-complete", issuePipelineActionsHandler.StepComplete)
-		r.With(middleware.OptionalWorkspace).Post("/project-tasks/{id}/pipeline/step-complete", issuePipelineActionsHandler.StepComplete)
-		r.With(middleware.OptionalWorkspace).Post("/issues/{id}/pipeline/step-reject", issuePipelineActionsHandler.StepReject)
-		r.With(middleware.OptionalWorkspace).Post("/project-tasks/{id}/pipeline/step-reject", issuePipelineActionsHandler.StepReject)
+		r.With(middleware.OptionalWorkspace, AuthorizeCapability(db, CapabilityIssuesWrite, issueProjectScope("id"))).Post("/issues/{id}/pipeline/step-complete", issuePipelineActionsHandler.StepComplete)
+		r.With(middleware.OptionalWorkspace, AuthorizeCapability(db, CapabilityIssuesWrite, issueProjectScope("id"))).Post("/project-tasks/{id}/pipeline/step-complete", issuePipelineActionsHandler.StepComplete)
+		r.With(middleware.OptionalWorkspace, AuthorizeCapability(db, CapabilityIssuesWrite, issueProjectScope("id"))).Post("/issues/{id}/pipeline/step-reject", issuePipelineActionsHandler.StepReject)
+		r.With(middleware.OptionalWorkspace, AuthorizeCapability(db, CapabilityIssuesWrite, issueProjectScope("id"))).Post("/project-tasks/{id}/pipeline/step-reject", issuePipelineActionsHandler.StepReject)
 		r.With(middleware.OptionalWorkspace).Get("/issues/{id}/pipeline/status", issuePipelineActionsHandler.Status)
 		r.With(middleware.OptionalWorkspace).Get("/project-tasks/{id}/pipeline/status", issuePipelineActionsHandler.Status)
-		r.With(middleware.OptionalWorkspace).Post("/issues/{id}/comments", issuesHandler.CreateComment)
-		r.With(middleware.OptionalWorkspace).Post("/project-tasks/{id}/comments", issuesHandler.CreateComment)
-		r.With(middleware.OptionalWorkspace).Post("/issues/{id}/questionnaire", questionnaireHandler.CreateIssueQuestionnaire)
-		r.With(middleware.OptionalWorkspace).Post("/project-tasks/{id}/questionnaire", questionnaireHandler.CreateIssueQuestionnaire)
-		r.With(middleware.OptionalWorkspace).Post("/questionnaires/{id}/response", questionnaireHandler.Respond)
-		r.With(middleware.OptionalWorkspace).Post("/issues/{id}/approval-state", issuesHandler.TransitionApprovalState)
-		r.With(middleware.OptionalWorkspace).Post("/project-tasks/{id}/approval-state", issuesHandler.TransitionApprovalState)
-		r.With(middleware.OptionalWorkspace).Post("/issues/{id}/approve", issuesHandler.Approve)
-		r.With(middleware.OptionalWorkspace).Post("/project-tasks/{id}/approve", issuesHandler.Approve)
-		r.With(middleware.OptionalWorkspace).Patch("/issues/{id}", issuesHandler.PatchIssue)
-		r.With(middleware.OptionalWorkspace).Patch("/project-tasks/{id}", issuesHandler.PatchIssue)
-		r.With(middleware.OptionalWorkspace).Post("/issues/{id}/review/save", issuesHandler.SaveReview)
-		r.With(middleware.OptionalWorkspace).Post("/project-tasks/{id}/review/save", issuesHandler.SaveReview)
-		r.With(middleware.OptionalWorkspace).Post("/issues/{id}/review/address", issuesHandler.AddressReview)
-		r.With(middleware.OptionalWorkspace).Post("/project-tasks/{id}/review/address", issuesHandler.AddressReview)
+		r.With(middleware.OptionalWorkspace, AuthorizeCapability(db, CapabilityIssuesWrite, issueProjectScope("id"))).Post("/issues/{id}/comments", issuesHandler.CreateComment)
+		r.With(middleware.OptionalWorkspace, AuthorizeCapability(db, CapabilityIssuesWrite, issueProjectScope("id"))).Post("/project-tasks/{id}/comments", issuesHandler.CreateComment)
+		r.With(middleware.OptionalWorkspace, AuthorizeCapability(db, CapabilityIssuesWrite, issueProjectScope("id"))).Post("/issues/{id}/questionnaire", questionnaireHandler.CreateIssueQuestionnaire)
+		r.With(middleware.OptionalWorkspace, AuthorizeCapability(db, CapabilityIssuesWrite, issueProjectScope("id"))).Post("/project-tasks/{id}/questionnaire", questionnaireHandler.CreateIssueQuestionnaire)
+		r.With(middleware.OptionalWorkspace, AuthorizeCapability(db, CapabilityIssuesWrite, nil)).Post("/questionnaires/{id}/response", questionnaireHandler.Respond)
+		r.With(middleware.OptionalWorkspace, AuthorizeCapability(db, CapabilityIssuesWrite, issueProjectScope("id"))).Post("/issues/{id}/approval-state", issuesHandler.TransitionApprovalState)
+		r.With(middleware.OptionalWorkspace, AuthorizeCapability(db, CapabilityIssuesWrite, issueProjectScope("id"))).Post("/project-tasks/{id}/approval-state", issuesHandler.TransitionApprovalState)
+		r.With(middleware.OptionalWorkspace, AuthorizeCapability(db, CapabilityIssuesWrite, issueProjectScope("id"))).Post("/issues/{id}/approve", issuesHandler.Approve)
+		r.With(middleware.OptionalWorkspace, AuthorizeCapability(db, CapabilityIssuesWrite, issueProjectScope("id"))).Post("/project-tasks/{id}/approve", issuesHandler.Approve)
+		r.With(middleware.OptionalWorkspace, AuthorizeCapability(db, CapabilityIssuesWrite, issueProjectScope("id"))).Patch("/issues/{id}", issuesHandler.PatchIssue)
+		r.With(middleware.OptionalWorkspace, AuthorizeCapability(db, CapabilityIssuesWrite, issueProjectScope("id"))).Patch("/project-tasks/{id}", issuesHandler.PatchIssue)
+		r.With(middleware.OptionalWorkspace).Get("/issues/{id}/tree", issuesHandler.GetIssueTree)
+		r.With(middleware.OptionalWorkspace).Get("/issues/{id}/timeline", issuesHandler.GetIssueTimeline)
+		r.With(middleware.OptionalWorkspace, AuthorizeCapability(db, CapabilityIssuesWrite, issueProjectScope("id"))).Post("/issues/{id}/children", issuesHandler.AttachSubIssue)
+		r.With(middleware.OptionalWorkspace, AuthorizeCapability(db, CapabilityIssuesWrite, issueProjectScope("id"))).Delete("/issues/{id}/children/{childID}", issuesHandler.DetachSubIssue)
+		r.With(middleware.OptionalWorkspace, AuthorizeCapability(db, CapabilityIssuesWrite, issueProjectScope("id"))).Post("/issues/{id}/decompose", issuesHandler.DecomposeIssue)
+		r.With(middleware.OptionalWorkspace, AuthorizeCapability(db, CapabilityIssuesWrite, issueProjectScope("id"))).Post("/issues/{id}/merge", issuesHandler.MergeIssue)
+		r.With(middleware.OptionalWorkspace, AuthorizeCapability(db, CapabilityIssuesWrite, issueProjectScope("id"))).Post("/issues/{id}/review/save", issuesHandler.SaveReview)
+		r.With(middleware.OptionalWorkspace, AuthorizeCapability(db, CapabilityIssuesWrite, issueProjectScope("id"))).Post("/project-tasks/{id}/review/save", issuesHandler.SaveReview)
+		r.With(middleware.OptionalWorkspace, AuthorizeCapability(db, CapabilityIssuesWrite, issueProjectScope("id"))).Post("/issues/{id}/review/address", issuesHandler.AddressReview)
+		r.With(middleware.OptionalWorkspace, AuthorizeCapability(db, CapabilityIssuesWrite, issueProjectScope("id"))).Post("/project-tasks/{id}/review/address", issuesHandler.AddressReview)
 		r.With(middleware.OptionalWorkspace).Get("/issues/{id}/review/changes", issuesHandler.ReviewChanges)
 		r.With(middleware.OptionalWorkspace).Get("/project-tasks/{id}/review/changes", issuesHandler.ReviewChanges)
 		r.With(middleware.OptionalWorkspace).Get("/issues/{id}/review/history", issuesHandler.ReviewHistory)
 		r.With(middleware.OptionalWorkspace).Get("/project-tasks/{id}/review/history", issuesHandler.ReviewHistory)
 		r.With(middleware.OptionalWorkspace).Get("/issues/{id}/review/history/{sha}", issuesHandler.ReviewVersion)
 		r.With(middleware.OptionalWorkspace).Get("/project-tasks/{id}/review/history/{sha}", issuesHandler.ReviewVersion)
-		r.With(middleware.OptionalWorkspace).Post("/issues/{id}/participants", issuesHandler.AddParticipant)
-		r.With(middleware.OptionalWorkspace).Post("/project-tasks/{id}/participants", issuesHandler.AddParticipant)
-		r.With(middleware.OptionalWorkspace).Delete("/issues/{id}/participants/{agentID}", issuesHandler.RemoveParticipant)
-		r.With(middleware.OptionalWorkspace).Delete("/project-tasks/{id}/participants/{agentID}", issuesHandler.RemoveParticipant)
-		r.With(middleware.OptionalWorkspace).Post("/issues/{id}/flow/assign", issuesHandler.AssignFlow)
-		r.With(middleware.OptionalWorkspace).Post("/project-tasks/{id}/flow/assign", issuesHandler.AssignFlow)
-		r.With(middleware.OptionalWorkspace).Post("/issues/{id}/flow/advance", issuesHandler.AdvanceFlow)
-		r.With(middleware.OptionalWorkspace).Post("/project-tasks/{id}/flow/advance", issuesHandler.AdvanceFlow)
-		r.With(middleware.OptionalWorkspace).Post("/issues/{id}/flow/blockers", issuesHandler.RaiseFlowBlocker)
-		r.With(middleware.OptionalWorkspace).Post("/project-tasks/{id}/flow/blockers", issuesHandler.RaiseFlowBlocker)
-		r.With(middleware.OptionalWorkspace).Post("/issues/{id}/flow/blockers/{blockerID}/escalate-human", issuesHandler.EscalateFlowBlockerToHuman)
-		r.With(middleware.OptionalWorkspace).Post("/project-tasks/{id}/flow/blockers/{blockerID}/escalate-human", issuesHandler.EscalateFlowBlockerToHuman)
-		r.With(middleware.OptionalWorkspace).Post("/issues/{id}/flow/blockers/{blockerID}/resolve", issuesHandler.ResolveFlowBlocker)
-		r.With(middleware.OptionalWorkspace).Post("/project-tasks/{id}/flow/blockers/{blockerID}/resolve", issuesHandler.ResolveFlowBlocker)
-		r.With(middleware.OptionalWorkspace).Post("/projects/{id}/issues", issuesHandler.CreateIssue)
-		r.With(middleware.OptionalWorkspace).Post("/projects/{id}/tasks", issuesHandler.CreateIssue)
-		r.With(middleware.OptionalWorkspace).Post("/projects/{id}/issues/link", issuesHandler.CreateLinkedIssue)
-		r.With(middleware.OptionalWorkspace).Post("/projects/{id}/tasks/link", issuesHandler.CreateLinkedIssue)
+		r.With(middleware.OptionalWorkspace).Get("/issues/{id}/review/threads", issuesHandler.ListReviewThreads)
+		r.With(middleware.OptionalWorkspace, AuthorizeCapability(db, CapabilityIssuesWrite, issueProjectScope("id"))).Post("/issues/{id}/review/threads", issuesHandler.CreateReviewThread)
+		r.With(middleware.OptionalWorkspace, AuthorizeCapability(db, CapabilityIssuesWrite, issueProjectScope("id"))).Post("/issues/{id}/review/threads/{threadID}/comments", issuesHandler.ReplyReviewThread)
+		r.With(middleware.OptionalWorkspace, AuthorizeCapability(db, CapabilityIssuesWrite, issueProjectScope("id"))).Post("/issues/{id}/review/threads/{threadID}/resolve", issuesHandler.ResolveReviewThread)
+		r.With(middleware.OptionalWorkspace, AuthorizeCapability(db, CapabilityIssuesWrite, issueProjectScope("id"))).Post("/issues/{id}/review/threads/{threadID}/reopen", issuesHandler.ReopenReviewThread)
+		r.With(middleware.OptionalWorkspace, AuthorizeCapability(db, CapabilityIssuesWrite, issueProjectScope("id"))).Post("/issues/{id}/participants", issuesHandler.AddParticipant)
+		r.With(middleware.OptionalWorkspace, AuthorizeCapability(db, CapabilityIssuesWrite, issueProjectScope("id"))).Post("/project-tasks/{id}/participants", issuesHandler.AddParticipant)
+		r.With(middleware.OptionalWorkspace, AuthorizeCapability(db, CapabilityIssuesWrite, issueProjectScope("id"))).Delete("/issues/{id}/participants/{agentID}", issuesHandler.RemoveParticipant)
+		r.With(middleware.OptionalWorkspace, AuthorizeCapability(db, CapabilityIssuesWrite, issueProjectScope("id"))).Delete("/project-tasks/{id}/participants/{agentID}", issuesHandler.RemoveParticipant)
+		r.With(middleware.OptionalWorkspace, AuthorizeCapability(db, CapabilityIssuesWrite, issueProjectScope("id"))).Post("/issues/{id}/flow/assign", issuesHandler.AssignFlow)
+		r.With(middleware.OptionalWorkspace, AuthorizeCapability(db, CapabilityIssuesWrite, issueProjectScope("id"))).Post("/project-tasks/{id}/flow/assign", issuesHandler.AssignFlow)
+		r.With(middleware.OptionalWorkspace, AuthorizeCapability(db, CapabilityIssuesWrite, issueProjectScope("id"))).Post("/issues/{id}/flow/advance", issuesHandler.AdvanceFlow)
+		r.With(middleware.OptionalWorkspace, AuthorizeCapability(db, CapabilityIssuesWrite, issueProjectScope("id"))).Post("/project-tasks/{id}/flow/advance", issuesHandler.AdvanceFlow)
+		r.With(middleware.OptionalWorkspace, AuthorizeCapability(db, CapabilityIssuesWrite, issueProjectScope("id"))).Post("/issues/{id}/flow/blockers", issuesHandler.RaiseFlowBlocker)
+		r.With(middleware.OptionalWorkspace, AuthorizeCapability(db, CapabilityIssuesWrite, issueProjectScope("id"))).Post("/project-tasks/{id}/flow/blockers", issuesHandler.RaiseFlowBlocker)
+		r.With(middleware.OptionalWorkspace, AuthorizeCapability(db, CapabilityIssuesWrite, issueProjectScope("id"))).Post("/issues/{id}/flow/blockers/{blockerID}/escalate-human", issuesHandler.EscalateFlowBlockerToHuman)
+		r.With(middleware.OptionalWorkspace, AuthorizeCapability(db, CapabilityIssuesWrite, issueProjectScope("id"))).Post("/project-tasks/{id}/flow/blockers/{blockerID}/escalate-human", issuesHandler.EscalateFlowBlockerToHuman)
+		r.With(middleware.OptionalWorkspace, AuthorizeCapability(db, CapabilityIssuesWrite, issueProjectScope("id"))).Post("/issues/{id}/flow/blockers/{blockerID}/resolve", issuesHandler.ResolveFlowBlocker)
+		r.With(middleware.OptionalWorkspace, AuthorizeCapability(db, CapabilityIssuesWrite, issueProjectScope("id"))).Post("/project-tasks/{id}/flow/blockers/{blockerID}/resolve", issuesHandler.ResolveFlowBlocker)
+		r.With(middleware.OptionalWorkspace, AuthorizeCapability(db, CapabilityIssuesWrite, projectScope("id"))).Post("/projects/{id}/issues", issuesHandler.CreateIssue)
+		r.With(middleware.OptionalWorkspace, AuthorizeCapability(db, CapabilityIssuesWrite, projectScope("id"))).Post("/projects/{id}/tasks", issuesHandler.CreateIssue)
+		r.With(middleware.OptionalWorkspace, AuthorizeCapability(db, CapabilityIssuesWrite, projectScope("id"))).Post("/projects/{id}/issues/link", issuesHandler.CreateLinkedIssue)
+		r.With(middleware.OptionalWorkspace, AuthorizeCapability(db, CapabilityIssuesWrite, projectScope("id"))).Post("/projects/{id}/tasks/link", issuesHandler.CreateLinkedIssue)
 		r.With(middleware.OptionalWorkspace).Get("/chats", chatsHandler.List)
 		r.With(middleware.OptionalWorkspace).Get("/chats/{id}", chatsHandler.Get)
 		r.With(middleware.OptionalWorkspace).Post("/chats/{id}/archive", chatsHandler.Archive)
 		r.With(middleware.OptionalWorkspace).Post("/chats/{id}/unarchive", chatsHandler.Unarchive)
-		r.With(RequireCapability(db, CapabilityGitHubManualSync)).Post("/projects/{id}/issues/import", projectIssueSyncHandler.ManualImport)
-		r.With(RequireCapability(db, CapabilityGitHubManualSync)).Post("/projects/{id}/tasks/import", projectIssueSyncHandler.ManualImport)
+		r.With(RequireProjectCapability(db, CapabilityGitHubManualSync, projectScope("id"))).Post("/projects/{id}/issues/import", projectIssueSyncHandler.ManualImport)
+		r.With(RequireProjectCapability(db, CapabilityGitHubManualSync, projectScope("id"))).Post("/projects/{id}/tasks/import", projectIssueSyncHandler.ManualImport)
 		r.With(middleware.OptionalWorkspace).Get("/projects/{id}/issues/status", projectIssueSyncHandler.Status)
 		r.With(middleware.OptionalWorkspace).Get("/projects/{id}/tasks/status", projectIssueSyncHandler.Status)
-		r.With(RequireCapability(db, CapabilityGitHubIntegrationAdmin)).Get("/projects/{id}/repo/branches", githubIntegrationHandler.GetProjectBranches)
-		r.With(RequireCapability(db, CapabilityGitHubIntegrationAdmin)).Put("/projects/{id}/repo/branches", githubIntegrationHandler.UpdateProjectBranches)
-		r.With(RequireCapability(db, CapabilityGitHubManualSync)).Post("/projects/{id}/repo/conflicts/resolve", githubIntegrationHandler.ResolveProjectConflict)
-		r.With(RequireCapability(db, CapabilityGitHubManualSync)).Post("/projects/{id}/repo/sync", githubIntegrationHandler.ManualRepoSync)
-		r.With(RequireCapability(db, CapabilityGitHubPublish)).Post("/projects/{id}/publish", githubIntegrationHandler.PublishProject)
-		r.With(middleware.OptionalWorkspace).Post("/projects", projectsHandler.Create)
+		r.With(RequireProjectCapability(db, CapabilityGitHubIntegrationAdmin, projectScope("id"))).Get("/projects/{id}/repo/branches", githubIntegrationHandler.GetProjectBranches)
+		r.With(RequireProjectCapability(db, CapabilityGitHubIntegrationAdmin, projectScope("id"))).Put("/projects/{id}/repo/branches", githubIntegrationHandler.UpdateProjectBranches)
+		r.With(RequireProjectCapability(db, CapabilityGitHubManualSync, projectScope("id"))).Post("/projects/{id}/repo/conflicts/resolve", githubIntegrationHandler.ResolveProjectConflict)
+		r.With(RequireProjectCapability(db, CapabilityGitHubManualSync, projectScope("id"))).Post("/projects/{id}/repo/sync", githubIntegrationHandler.ManualRepoSync)
+		r.With(RequireProjectCapability(db, CapabilityGitHubPublish, projectScope("id"))).Post("/projects/{id}/publish", githubIntegrationHandler.PublishProject)
+		r.With(middleware.OptionalWorkspace, AuthorizeCapability(db, CapabilityProjectsManage, nil)).Post("/projects", projectsHandler.Create)
 
 		// Agent Activity
 		r.With(middleware.OptionalWorkspace).Get("/activity/recent", agentActivityHandler.ListRecent)
 		r.With(middleware.OptionalWorkspace).Post("/activity/ingest", agentActivityHandler.IngestEvents)
 		r.With(middleware.OptionalWorkspace).Post("/activity/events", agentActivityHandler.IngestEvents)
 		r.With(middleware.OptionalWorkspace).Get("/agents/{id}/activity", agentActivityHandler.ListByAgent)
+		r.With(middleware.OptionalWorkspace).Get("/agents/{id}/review", agentReviewHandler.Get)
 
 		// Labels
 		r.With(middleware.OptionalWorkspace).Get("/labels", labelsHandler.List)
@@ -478,14 +556,14 @@ func NewRouter() http.Handler {
 		r.With(middleware.OptionalWorkspace).Delete("/taxonomy/nodes/{id}", taxonomyHandler.DeleteNode)
 		r.With(middleware.OptionalWorkspace).Get("/taxonomy/nodes/{id}/memories", taxonomyHandler.ListSubtreeMemories)
 		r.With(middleware.OptionalWorkspace).Get("/projects/{id}/labels", labelsHandler.ListProjectLabels)
-		r.With(middleware.OptionalWorkspace).Post("/projects/{id}/labels", labelsHandler.AddProjectLabels)
-		r.With(middleware.OptionalWorkspace).Delete("/projects/{id}/labels/{lid}", labelsHandler.RemoveProjectLabel)
+		r.With(middleware.OptionalWorkspace, AuthorizeCapability(db, CapabilityProjectsManage, projectScope("id"))).Post("/projects/{id}/labels", labelsHandler.AddProjectLabels)
+		r.With(middleware.OptionalWorkspace, AuthorizeCapability(db, CapabilityProjectsManage, projectScope("id"))).Delete("/projects/{id}/labels/{lid}", labelsHandler.RemoveProjectLabel)
 		r.With(middleware.OptionalWorkspace).Get("/projects/{pid}/issues/{iid}/labels", labelsHandler.ListIssueLabels)
 		r.With(middleware.OptionalWorkspace).Get("/projects/{pid}/tasks/{iid}/labels", labelsHandler.ListIssueLabels)
-		r.With(middleware.OptionalWorkspace).Post("/projects/{pid}/issues/{iid}/labels", labelsHandler.AddIssueLabels)
-		r.With(middleware.OptionalWorkspace).Post("/projects/{pid}/tasks/{iid}/labels", labelsHandler.AddIssueLabels)
-		r.With(middleware.OptionalWorkspace).Delete("/projects/{pid}/issues/{iid}/labels/{lid}", labelsHandler.RemoveIssueLabel)
-		r.With(middleware.OptionalWorkspace).Delete("/projects/{pid}/tasks/{iid}/labels/{lid}", labelsHandler.RemoveIssueLabel)
+		r.With(middleware.OptionalWorkspace, AuthorizeCapability(db, CapabilityIssuesWrite, projectScope("pid"))).Post("/projects/{pid}/issues/{iid}/labels", labelsHandler.AddIssueLabels)
+		r.With(middleware.OptionalWorkspace, AuthorizeCapability(db, CapabilityIssuesWrite, projectScope("pid"))).Post("/projects/{pid}/tasks/{iid}/labels", labelsHandler.AddIssueLabels)
+		r.With(middleware.OptionalWorkspace, AuthorizeCapability(db, CapabilityIssuesWrite, projectScope("pid"))).Delete("/projects/{pid}/issues/{iid}/labels/{lid}", labelsHandler.RemoveIssueLabel)
+		r.With(middleware.OptionalWorkspace, AuthorizeCapability(db, CapabilityIssuesWrite, projectScope("pid"))).Delete("/projects/{pid}/tasks/{iid}/labels/{lid}", labelsHandler.RemoveIssueLabel)
 
 		r.With(middleware.OptionalWorkspace).Get("/settings/profile", HandleSettingsProfileGet)
 		r.With(middleware.OptionalWorkspace).Put("/settings/profile", HandleSettingsProfilePut)
@@ -512,6 +590,7 @@ func NewRouter() http.Handler {
 		r.With(middleware.OptionalWorkspace).Put("/settings/workspace", HandleSettingsWorkspacePut)
 		r.With(middleware.OptionalWorkspace).Get("/settings/integrations", HandleSettingsIntegrationsGet)
 		r.With(middleware.OptionalWorkspace).Put("/settings/integrations", HandleSettingsIntegrationsPut)
+		r.Get("/settings/integrations/api-keys/scopes", HandleSettingsAPIKeyScopes)
 		r.With(middleware.OptionalWorkspace).Post("/settings/integrations/api-keys", HandleSettingsAPIKeyCreate)
 		r.With(middleware.OptionalWorkspace).Delete("/settings/integrations/api-keys/{id}", HandleSettingsAPIKeyDelete)
 
@@ -546,8 +625,8 @@ func NewRouter() http.Handler {
 		r.With(middleware.RequireWorkspace, RequireCapability(db, CapabilityOpenClawMigrationManage)).Post("/migrations/openclaw/reset/memory_extraction", openClawMigrationHandler.ResetMemoryExtraction)
 		r.With(middleware.RequireWorkspace, RequireCapability(db, CapabilityOpenClawMigrationManage)).Post("/migrations/openclaw/import/agents", openClawMigrationImportHandler.ImportAgents)
 		r.With(middleware.RequireWorkspace, RequireCapability(db, CapabilityOpenClawMigrationManage)).Post("/migrations/openclaw/import/history/batch", openClawMigrationImportHandler.ImportHistoryBatch)
-		r.Patch("/tasks/{id}", taskHandler.UpdateTask)
-		r.Patch("/tasks/{id}/status", taskHandler.UpdateTaskStatus)
+		r.With(AuthorizeCapability(db, CapabilityIssuesWrite, nil)).Patch("/tasks/{id}", taskHandler.UpdateTask)
+		r.With(AuthorizeCapability(db, CapabilityIssuesWrite, nil)).Patch("/tasks/{id}/status", taskHandler.UpdateTaskStatus)
 		r.Get("/messages", messageHandler.ListMessages)
 		r.Post("/messages", messageHandler.CreateMessage)
 		r.Get("/messages/{id}", messageHandler.GetMessage)
@@ -559,37 +638,70 @@ func NewRouter() http.Handler {
 		r.Get("/export", HandleExport)
 		r.Post("/import", HandleImport)
 		r.Post("/import/validate", HandleImportValidate)
+		r.With(RequireCapability(db, CapabilityWorkspaceBackup)).Get("/backup", backupHandler.Create)
+		r.With(RequireCapability(db, CapabilityWorkspaceBackup)).Post("/backup/validate", backupHandler.Validate)
+		r.With(RequireCapability(db, CapabilityWorkspaceBackup)).Post("/backup/restore", backupHandler.Restore)
 
-		r.With(middleware.OptionalWorkspace).Post("/v1/jobs", jobsHandler.Create)
+		r.With(middleware.OptionalWorkspace, AuthorizeCapability(db, CapabilityJobsManage, nil)).Post("/v1/jobs", jobsHandler.Create)
 		r.With(middleware.OptionalWorkspace).Get("/v1/jobs", jobsHandler.List)
 		r.With(middleware.OptionalWorkspace).Get("/v1/jobs/{id}", jobsHandler.Get)
-		r.With(middleware.OptionalWorkspace).Patch("/v1/jobs/{id}", jobsHandler.Patch)
-		r.With(middleware.OptionalWorkspace).Delete("/v1/jobs/{id}", jobsHandler.Delete)
-		r.With(middleware.OptionalWorkspace).Post("/v1/jobs/{id}/run", jobsHandler.RunNow)
+		r.With(middleware.OptionalWorkspace, AuthorizeCapability(db, CapabilityJobsManage, nil)).Patch("/v1/jobs/{id}", jobsHandler.Patch)
+		r.With(middleware.OptionalWorkspace, AuthorizeCapability(db, CapabilityJobsManage, nil)).Delete("/v1/jobs/{id}", jobsHandler.Delete)
+		r.With(middleware.OptionalWorkspace, AuthorizeCapability(db, CapabilityJobsManage, nil)).Post("/v1/jobs/{id}/run", jobsHandler.RunNow)
 		r.With(middleware.OptionalWorkspace).Get("/v1/jobs/{id}/runs", jobsHandler.ListRuns)
-		r.With(middleware.OptionalWorkspace).Post("/v1/jobs/{id}/pause", jobsHandler.Pause)
-		r.With(middleware.OptionalWorkspace).Post("/v1/jobs/{id}/resume", jobsHandler.Resume)
-		r.With(middleware.OptionalWorkspace).Post("/v1/jobs/import/openclaw-cron", jobsHandler.ImportOpenClawCron)
+		r.With(middleware.OptionalWorkspace, AuthorizeCapability(db, CapabilityJobsManage, nil)).Post("/v1/jobs/{id}/runs/{runID}/reply", jobsHandler.ReplyToRun)
+		r.With(middleware.OptionalWorkspace, AuthorizeCapability(db, CapabilityJobsManage, nil)).Post("/v1/jobs/{id}/pause", jobsHandler.Pause)
+		r.With(middleware.OptionalWorkspace, AuthorizeCapability(db, CapabilityJobsManage, nil)).Post("/v1/jobs/{id}/resume", jobsHandler.Resume)
+		r.With(middleware.OptionalWorkspace, AuthorizeCapability(db, CapabilityJobsManage, nil)).Post("/v1/jobs/import/openclaw-cron", jobsHandler.ImportOpenClawCron)
+		r.With(middleware.OptionalWorkspace).Get("/v1/jobs/blackouts", jobsHandler.ListBlackouts)
+		r.With(middleware.OptionalWorkspace, AuthorizeCapability(db, CapabilityJobsManage, nil)).Post("/v1/jobs/blackouts", jobsHandler.CreateBlackout)
+		r.With(middleware.OptionalWorkspace, AuthorizeCapability(db, CapabilityJobsManage, nil)).Delete("/v1/jobs/blackouts/{id}", jobsHandler.DeleteBlackout)
 
 		// Admin endpoints
 		r.With(RequireCapability(db, CapabilityAdminConfigManage)).Post("/admin/init-repos", HandleAdminInitRepos(db))
 		r.Post("/admin/migrate", handleAdminMigrate(db)) // no auth — safe idempotent operation
+		r.With(RequireCapability(db, CapabilityRolesManage)).Get("/admin/capabilities", rolesHandler.Capabilities)
+		r.With(RequireCapability(db, CapabilityRolesManage)).Get("/admin/roles", rolesHandler.ListRoles)
+		r.With(RequireCapability(db, CapabilityRolesManage)).Post("/admin/roles", rolesHandler.CreateRole)
+		r.With(RequireCapability(db, CapabilityRolesManage)).Patch("/admin/roles/{id}", rolesHandler.PatchRole)
+		r.With(RequireCapability(db, CapabilityRolesManage)).Delete("/admin/roles/{id}", rolesHandler.DeleteRole)
+		r.With(RequireCapability(db, CapabilityRolesManage)).Get("/admin/role-bindings", rolesHandler.ListBindings)
+		r.With(RequireCapability(db, CapabilityRolesManage)).Post("/admin/role-bindings", rolesHandler.CreateBinding)
+		r.With(RequireCapability(db, CapabilityRolesManage)).Delete("/admin/role-bindings/{id}", rolesHandler.DeleteBinding)
+		r.With(RequireCapability(db, CapabilityAdminConfigManage)).Get("/admin/sso/providers", ssoHandler.ListProviders)
+		r.With(RequireCapability(db, CapabilityAdminConfigManage)).Post("/admin/sso/providers", ssoHandler.CreateProvider)
+		r.With(RequireCapability(db, CapabilityAdminConfigManage)).Patch("/admin/sso/providers/{id}", ssoHandler.PatchProvider)
+		r.With(RequireCapability(db, CapabilityAdminConfigManage)).Delete("/admin/sso/providers/{id}", ssoHandler.DeleteProvider)
+		r.With(RequireCapability(db, CapabilityAdminConfigManage)).Get("/admin/sso/enforcement", ssoHandler.GetEnforcement)
+		r.With(RequireCapability(db, CapabilityAdminConfigManage)).Put("/admin/sso/enforcement", ssoHandler.PutEnforcement)
+		r.With(RequireCapability(db, CapabilityAuditRead)).Get("/admin/audit", auditHandler.List)
+		r.With(RequireCapability(db, CapabilityAuditRead)).Get("/admin/audit/export", auditHandler.Export)
+		r.With(RequireCapability(db, CapabilityAuditRead)).Get("/admin/audit/verify", auditHandler.Verify)
+		r.With(middleware.OptionalWorkspace).Get("/usage", usageHandler.Report)
+		r.With(middleware.OptionalWorkspace).Get("/usage/pricing", usageHandler.ListPricing)
+		r.With(RequireCapability(db, CapabilityUsageManage)).Put("/usage/pricing", usageHandler.SetPrice)
+		r.With(RequireCapability(db, CapabilityUsageManage)).Delete("/usage/pricing", usageHandler.DeletePrice)
+		r.With(middleware.OptionalWorkspace).Get("/usage/budgets", usageHandler.ListBudgets)
+		r.With(RequireCapability(db, CapabilityUsageManage)).Post("/usage/budgets", usageHandler.SetBudget)
+		r.With(RequireCapability(db, CapabilityUsageManage)).Patch("/usage/budgets/{id}", usageHandler.PatchBudget)
+		r.With(RequireCapability(db, CapabilityUsageManage)).Delete("/usage/budgets/{id}", usageHandler.DeleteBudget)
 		r.With(middleware.OptionalWorkspace).Get("/admin/connections", adminConnectionsHandler.Get)
 		r.With(middleware.OptionalWorkspace).Get("/admin/events", adminConnectionsHandler.GetEvents)
 		r.With(RequireCapability(db, CapabilityAdminConfigManage)).Post("/admin/gateway/restart", adminConnectionsHandler.RestartGateway)
-		r.With(RequireCapability(db, CapabilityAdminConfigManage)).Post("/admin/agents", adminAgentsHandler.Create)
+		r.With(RequireCapability(db, CapabilityAgentsManage)).Post("/admin/agents", adminAgentsHandler.Create)
 		r.With(middleware.OptionalWorkspace).Get("/admin/agents", adminAgentsHandler.List)
 		r.With(middleware.OptionalWorkspace).Get("/admin/agents/{id}", adminAgentsHandler.Get)
 		r.With(middleware.OptionalWorkspace).Get("/admin/agents/{id}/files", adminAgentsHandler.ListFiles)
 		r.With(middleware.OptionalWorkspace).Get("/admin/agents/{id}/files/{path:.*}", adminAgentsHandler.GetFile)
 		r.With(middleware.OptionalWorkspace).Get("/admin/agents/{id}/memory", adminAgentsHandler.ListMemoryFiles)
 		r.With(middleware.OptionalWorkspace).Get("/admin/agents/{id}/memory/{date}", adminAgentsHandler.GetMemoryFileByDate)
-		r.With(RequireCapability(db, CapabilityAdminConfigManage)).Post("/admin/agents/{id}/retire", adminAgentsHandler.Retire)
-		r.With(RequireCapability(db, CapabilityAdminConfigManage)).Post("/admin/agents/retire/project/{projectID}", adminAgentsHandler.RetireByProject)
-		r.With(RequireCapability(db, CapabilityAdminConfigManage)).Post("/admin/agents/{id}/reactivate", adminAgentsHandler.Reactivate)
-		r.With(RequireCapability(db, CapabilityAdminConfigManage)).Post("/admin/agents/{id}/ping", adminConnectionsHandler.PingAgent)
-		r.With(RequireCapability(db, CapabilityAdminConfigManage)).Post("/admin/agents/{id}/reset", adminConnectionsHandler.ResetAgent)
+		r.With(RequireCapability(db, CapabilityAgentsManage)).Post("/admin/agents/{id}/retire", adminAgentsHandler.Retire)
+		r.With(RequireCapability(db, CapabilityAgentsManage)).Post("/admin/agents/retire/project/{projectID}", adminAgentsHandler.RetireByProject)
+		r.With(RequireCapability(db, CapabilityAgentsManage)).Post("/admin/agents/{id}/reactivate", adminAgentsHandler.Reactivate)
+		r.With(RequireCapability(db, CapabilityAgentsManage)).Post("/admin/agents/{id}/ping", adminConnectionsHandler.PingAgent)
+		r.With(RequireCapability(db, CapabilityAgentsManage)).Post("/admin/agents/{id}/reset", adminConnectionsHandler.ResetAgent)
 		r.With(RequireCapability(db, CapabilityAdminConfigManage)).Post("/admin/diagnostics", adminConnectionsHandler.RunDiagnostics)
+		r.With(RequireCapability(db, CapabilityAdminConfigManage)).Get("/admin/rate-limits", rateLimitsHandler.Get)
 		r.With(middleware.OptionalWorkspace).Get("/admin/logs", adminConnectionsHandler.GetLogs)
 		r.With(middleware.OptionalWorkspace).Get("/admin/cron/jobs", adminConnectionsHandler.GetCronJobs)
 		r.With(RequireCapability(db, CapabilityAdminConfigManage)).Post("/admin/cron/jobs/{id}/run", adminConnectionsHandler.RunCronJob)
@@ -409,8 +409,8 @@ func TestWorkflowRoutesUseOptionalWorkspaceMiddleware(t *testing.T) {
 	source := string(content)
 	requiredLines := []string{
 		`r.With(middleware.OptionalWorkspace).Get("/workflows", workflowsHandler.List)`,
-		`r.With(middleware.OptionalWorkspace).Patch("/workflows/{id}", workflowsHandler.Toggle)`,
-		`r.With(middleware.OptionalWorkspace).Post("/workflows/{id}/run", workflowsHandler.Run)`,
+		`r.With(middleware.OptionalWorkspace, AuthorizeCapability(db, CapabilityPipelinesManage, projectScope("id"))).Patch("/workflows/{id}", workflowsHandler.Toggle)`,
+		`r.With(middleware.OptionalWorkspace, AuthorizeCapability(db, CapabilityPipelinesManage, projectScope("id"))).Post("/workflows/{id}/run", workflowsHandler.Run)`,
 	}
 	for _, line := range requiredLines {
 		if !strings.Contains(source, line) {
@@ -451,12 +451,12 @@ func TestAdminMutationRoutesRequireCapability(t *testing.T) {
 	requiredLines := []string{
 		`r.With(RequireCapability(db, CapabilityAdminConfigManage)).Post("/admin/init-repos", HandleAdminInitRepos(db))`,
 		`r.With(RequireCapability(db, CapabilityAdminConfigManage)).Post("/admin/gateway/restart", adminConnectionsHandler.RestartGateway)`,
-		`r.With(RequireCapability(db, CapabilityAdminConfigManage)).Post("/admin/agents", adminAgentsHandler.Create)`,
-		`r.With(RequireCapability(db, CapabilityAdminConfigManage)).Post("/admin/agents/{id}/retire", adminAgentsHandler.Retire)`,
-		`r.With(RequireCapability(db, CapabilityAdminConfigManage)).Post("/admin/agents/retire/project/{projectID}", adminAgentsHandler.RetireByProject)`,
-		`r.With(RequireCapability(db, CapabilityAdminConfigManage)).Post("/admin/agents/{id}/reactivate", adminAgentsHandler.Reactivate)`,
-		`r.With(RequireCapability(db, CapabilityAdminConfigManage)).Post("/admin/agents/{id}/ping", adminConnectionsHandler.PingAgent)`,
-		`r.With(RequireCapability(db, CapabilityAdminConfigManage)).Post("/admin/agents/{id}/reset", adminConnectionsHandler.ResetAgent)`,
+		`r.With(RequireCapability(db, CapabilityAgentsManage)).Post("/admin/agents", adminAgentsHandler.Create)`,
+		`r.With(RequireCapability(db, CapabilityAgentsManage)).Post("/admin/agents/{id}/retire", adminAgentsHandler.Retire)`,
+		`r.With(RequireCapability(db, CapabilityAgentsManage)).Post("/admin/agents/retire/project/{projectID}", adminAgentsHandler.RetireByProject)`,
+		`r.With(RequireCapability(db, CapabilityAgentsManage)).Post("/admin/agents/{id}/reactivate", adminAgentsHandler.Reactivate)`,
+		`r.With(RequireCapability(db, CapabilityAgentsManage)).Post("/admin/agents/{id}/ping", adminConnectionsHandler.PingAgent)`,
+		`r.With(RequireCapability(db, CapabilityAgentsManage)).Post("/admin/agents/{id}/reset", adminConnectionsHandler.ResetAgent)`,
 		`r.With(RequireCapability(db, CapabilityAdminConfigManage)).Post("/admin/diagnostics", adminConnectionsHandler.RunDiagnostics)`,
 		`r.With(RequireCapability(db, CapabilityAdminConfigManage)).Post("/admin/cron/jobs/{id}/run", adminConnectionsHandler.RunCronJob)`,
 		`r.With(RequireCapability(db, CapabilityAdminConfigManage)).Patch("/admin/cron/jobs/{id}", adminConnectionsHandler.ToggleCronJob)`,
@@ -465,6 +465,11 @@ func TestAdminMutationRoutesRequireCapability(t *testing.T) {
 		`r.With(RequireCapability(db, CapabilityAdminConfigManage)).Post("/admin/config/release-gate", adminConfigHandler.ReleaseGate)`,
 		`r.With(RequireCapability(db, CapabilityAdminConfigManage)).Post("/admin/config/cutover", adminConfigHandler.Cutover)`,
 		`r.With(RequireCapability(db, CapabilityAdminConfigManage)).Post("/admin/config/rollback", adminConfigHandler.Rollback)`,
+		`r.With(RequireCapability(db, CapabilityRolesManage)).Post("/admin/roles", rolesHandler.CreateRole)`,
+		`r.With(RequireCapability(db, CapabilityRolesManage)).Patch("/admin/roles/{id}", rolesHandler.PatchRole)`,
+		`r.With(RequireCapability(db, CapabilityRolesManage)).Delete("/admin/roles/{id}", rolesHandler.DeleteRole)`,
+		`r.With(RequireCapability(db, CapabilityRolesManage)).Post("/admin/role-bindings", rolesHandler.CreateBinding)`,
+		`r.With(RequireCapability(db, CapabilityRolesManage)).Delete("/admin/role-bindings/{id}", rolesHandler.DeleteBinding)`,
 	}
 
 	for _, line := range requiredLines {
@@ -474,6 +479,73 @@ func TestAdminMutationRoutesRequireCapability(t *testing.T) {
 	}
 }
 
+func TestAuditRoutesRequireAuditReadCapability(t *testing.T) {
+	t.Parallel()
+
+	content, err := os.ReadFile(filepath.Join("router.go"))
+	if err != nil {
+		t.Fatalf("failed to read router.go: %v", err)
+	}
+
+	source := string(content)
+	requiredLines := []string{
+		`r.Use(AuditRecorder(db))`,
+		`r.With(RequireCapability(db, CapabilityAuditRead)).Get("/admin/audit", auditHandler.List)`,
+		`r.With(RequireCapability(db, CapabilityAuditRead)).Get("/admin/audit/export", auditHandler.Export)`,
+		`r.With(RequireCapability(db, CapabilityAuditRead)).Get("/admin/audit/verify", auditHandler.Verify)`,
+	}
+	for _, line := range requiredLines {
+		if !strings.Contains(source, line) {
+			t.Fatalf("expected audit route wiring: %s", line)
+		}
+	}
+}
+
+func TestExecApprovalRuleMutationRoutesRequireCapability(t *testing.T) {
+	t.Parallel()
+
+	content, err := os.ReadFile(filepath.Join("router.go"))
+	if err != nil {
+		t.Fatalf("failed to read router.go: %v", err)
+	}
+
+	source := string(content)
+	requiredLines := []string{
+		`r.With(middleware.OptionalWorkspace, RequireCapability(db, CapabilityExecApprovalsManage)).Post("/approvals/exec/rules", execApprovalRulesHandler.Create)`,
+		`r.With(middleware.OptionalWorkspace, RequireCapability(db, CapabilityExecApprovalsManage)).Post("/approvals/exec/rules/dry-run", execApprovalRulesHandler.DryRun)`,
+		`r.With(middleware.OptionalWorkspace, RequireCapability(db, CapabilityExecApprovalsManage)).Patch("/approvals/exec/rules/{id}", execApprovalRulesHandler.Patch)`,
+		`r.With(middleware.OptionalWorkspace, RequireCapability(db, CapabilityExecApprovalsManage)).Delete("/approvals/exec/rules/{id}", execApprovalRulesHandler.Delete)`,
+	}
+	for _, line := range requiredLines {
+		if !strings.Contains(source, line) {
+			t.Fatalf("expected exec approval rule route to include capability middleware: %s", line)
+		}
+	}
+}
+
+func TestUsageMutationRoutesRequireUsageManageCapability(t *testing.T) {
+	t.Parallel()
+
+	content, err := os.ReadFile(filepath.Join("router.go"))
+	if err != nil {
+		t.Fatalf("failed to read router.go: %v", err)
+	}
+
+	source := string(content)
+	requiredLines := []string{
+		`r.With(RequireCapability(db, CapabilityUsageManage)).Put("/usage/pricing", usageHandler.SetPrice)`,
+		`r.With(RequireCapability(db, CapabilityUsageManage)).Delete("/usage/pricing", usageHandler.DeletePrice)`,
+		`r.With(RequireCapability(db, CapabilityUsageManage)).Post("/usage/budgets", usageHandler.SetBudget)`,
+		`r.With(RequireCapability(db, CapabilityUsageManage)).Patch("/usage/budgets/{id}", usageHandler.PatchBudget)`,
+		`r.With(RequireCapability(db, CapabilityUsageManage)).Delete("/usage/budgets/{id}", usageHandler.DeleteBudget)`,
+	}
+	for _, line := range requiredLines {
+		if !strings.Contains(source, line) {
+			t.Fatalf("expected usage route wiring: %s", line)
+		}
+	}
+}
+
 func TestSettingsRoutesAreRegisteredExactlyOnce(t *testing.T) {
 	t.Parallel()
 
@@ -707,3 +779,187 @@ func TestUploadsRouteMissingFileReturnsNotFound(t *testing.T) {
 		t.Fatalf("expected non-HTML 404 for missing upload path, got SPA HTML fallback")
 	}
 }
+
+func TestIssueViewRoutesAreWired(t *testing.T) {
+	t.Parallel()
+
+	content, err := os.ReadFile(filepath.Join("router.go"))
+	if err != nil {
+		t.Fatalf("failed to read router.go: %v", err)
+	}
+
+	source := string(content)
+	requiredLines := []string{
+		`r.With(middleware.OptionalWorkspace).Get("/issues/views", issuesHandler.ListViews)`,
+		`r.With(middleware.OptionalWorkspace, AuthorizeCapability(db, CapabilityIssuesWrite, nil)).Post("/issues/views", issuesHandler.SaveView)`,
+		`r.With(middleware.OptionalWorkspace, AuthorizeCapability(db, CapabilityIssuesWrite, nil)).Delete("/issues/views/{viewID}", issuesHandler.DeleteView)`,
+	}
+	for _, line := range requiredLines {
+		if !strings.Contains(source, line) {
+			t.Fatalf("expected issue view route wiring: %s", line)
+		}
+	}
+}
+
+func TestIssueBulkRouteIsWired(t *testing.T) {
+	t.Parallel()
+
+	content, err := os.ReadFile(filepath.Join("router.go"))
+	if err != nil {
+		t.Fatalf("failed to read router.go: %v", err)
+	}
+
+	line := `r.With(middleware.OptionalWorkspace, AuthorizeCapability(db, CapabilityIssuesWrite, bodyProjectScope("project_id"))).Post("/issues/bulk", issuesHandler.Bulk)`
+	if !strings.Contains(string(content), line) {
+		t.Fatalf("expected issue bulk route wiring: %s", line)
+	}
+}
+
+func TestIssueTemplateRoutesAreWired(t *testing.T) {
+	t.Parallel()
+
+	content, err := os.ReadFile(filepath.Join("router.go"))
+	if err != nil {
+		t.Fatalf("failed to read router.go: %v", err)
+	}
+
+	for _, line := range []string{
+		`r.With(middleware.OptionalWorkspace).Get("/projects/{id}/issue-templates", issuesHandler.ListTemplates)`,
+		`r.With(middleware.OptionalWorkspace, AuthorizeCapability(db, CapabilityProjectsWrite, projectScope("id"))).Post("/projects/{id}/issue-templates", issuesHandler.SaveTemplate)`,
+		`r.With(middleware.OptionalWorkspace).Get("/projects/{id}/issue-templates/{templateID}", issuesHandler.GetTemplate)`,
+		`r.With(middleware.OptionalWorkspace, AuthorizeCapability(db, CapabilityProjectsWrite, projectScope("id"))).Delete("/projects/{id}/issue-templates/{templateID}", issuesHandler.DeleteTemplate)`,
+	} {
+		if !strings.Contains(string(content), line) {
+			t.Fatalf("expected issue template route wiring: %s", line)
+		}
+	}
+}
+
+func TestSubIssueRoutesAreWired(t *testing.T) {
+	t.Parallel()
+
+	content, err := os.ReadFile(filepath.Join("router.go"))
+	if err != nil {
+		t.Fatalf("failed to read router.go: %v", err)
+	}
+
+	for _, line := range []string{
+		`r.With(middleware.OptionalWorkspace).Get("/projects/{id}/sub-issue-policy", issuesHandler.GetSubIssuePolicy)`,
+		`r.With(middleware.OptionalWorkspace, AuthorizeCapability(db, CapabilityProjectsWrite, projectScope("id"))).Put("/projects/{id}/sub-issue-policy", issuesHandler.SetSubIssuePolicy)`,
+		`r.With(middleware.OptionalWorkspace).Get("/issues/{id}/tree", issuesHandler.GetIssueTree)`,
+		`r.With(middleware.OptionalWorkspace, AuthorizeCapability(db, CapabilityIssuesWrite, issueProjectScope("id"))).Post("/issues/{id}/children", issuesHandler.AttachSubIssue)`,
+		`r.With(middleware.OptionalWorkspace, AuthorizeCapability(db, CapabilityIssuesWrite, issueProjectScope("id"))).Delete("/issues/{id}/children/{childID}", issuesHandler.DetachSubIssue)`,
+		`r.With(middleware.OptionalWorkspace, AuthorizeCapability(db, CapabilityIssuesWrite, issueProjectScope("id"))).Post("/issues/{id}/decompose", issuesHandler.DecomposeIssue)`,
+	} {
+		if !strings.Contains(string(content), line) {
+			t.Fatalf("expected sub-issue route wiring: %s", line)
+		}
+	}
+}
+
+func TestIssueMergeRouteIsWired(t *testing.T) {
+	t.Parallel()
+
+	content, err := os.ReadFile(filepath.Join("router.go"))
+	if err != nil {
+		t.Fatalf("failed to read router.go: %v", err)
+	}
+
+	line := `r.With(middleware.OptionalWorkspace, AuthorizeCapability(db, CapabilityIssuesWrite, issueProjectScope("id"))).Post("/issues/{id}/merge", issuesHandler.MergeIssue)`
+	if !strings.Contains(string(content), line) {
+		t.Fatalf("expected issue merge route wiring: %s", line)
+	}
+}
+
+func TestIssueTimelineRouteIsWired(t *testing.T) {
+	t.Parallel()
+
+	content, err := os.ReadFile(filepath.Join("router.go"))
+	if err != nil {
+		t.Fatalf("failed to read router.go: %v", err)
+	}
+
+	line := `r.With(middleware.OptionalWorkspace).Get("/issues/{id}/timeline", issuesHandler.GetIssueTimeline)`
+	if !strings.Contains(string(content), line) {
+		t.Fatalf("expected issue timeline route wiring: %s", line)
+	}
+}
+
+func TestIssueReviewThreadRoutesAreWired(t *testing.T) {
+	t.Parallel()
+
+	content, err := os.ReadFile(filepath.Join("router.go"))
+	if err != nil {
+		t.Fatalf("failed to read router.go: %v", err)
+	}
+
+	for _, line := range []string{
+		`r.With(middleware.OptionalWorkspace).Get("/issues/{id}/review/threads", issuesHandler.ListReviewThreads)`,
+		`r.With(middleware.OptionalWorkspace, AuthorizeCapability(db, CapabilityIssuesWrite, issueProjectScope("id"))).Post("/issues/{id}/review/threads", issuesHandler.CreateReviewThread)`,
+		`r.With(middleware.OptionalWorkspace, AuthorizeCapability(db, CapabilityIssuesWrite, issueProjectScope("id"))).Post("/issues/{id}/review/threads/{threadID}/comments", issuesHandler.ReplyReviewThread)`,
+		`r.With(middleware.OptionalWorkspace, AuthorizeCapability(db, CapabilityIssuesWrite, issueProjectScope("id"))).Post("/issues/{id}/review/threads/{threadID}/resolve", issuesHandler.ResolveReviewThread)`,
+		`r.With(middleware.OptionalWorkspace, AuthorizeCapability(db, CapabilityIssuesWrite, issueProjectScope("id"))).Post("/issues/{id}/review/threads/{threadID}/reopen", issuesHandler.ReopenReviewThread)`,
+	} {
+		if !strings.Contains(string(content), line) {
+			t.Fatalf("expected review thread route wiring: %s", line)
+		}
+	}
+}
+
+func TestRecurringIssueRoutesAreWired(t *testing.T) {
+	t.Parallel()
+
+	content, err := os.ReadFile(filepath.Join("router.go"))
+	if err != nil {
+		t.Fatalf("failed to read router.go: %v", err)
+	}
+
+	for _, line := range []string{
+		`r.With(middleware.OptionalWorkspace).Get("/projects/{id}/recurring-issues", issuesHandler.ListRecurringIssues)`,
+		`r.With(middleware.OptionalWorkspace, AuthorizeCapability(db, CapabilityProjectsWrite, projectScope("id"))).Post("/projects/{id}/recurring-issues", issuesHandler.CreateRecurringIssue)`,
+		`r.With(middleware.OptionalWorkspace).Get("/projects/{id}/recurring-issues/{recurringID}", issuesHandler.GetRecurringIssue)`,
+		`r.With(middleware.OptionalWorkspace, AuthorizeCapability(db, CapabilityProjectsWrite, projectScope("id"))).Patch("/projects/{id}/recurring-issues/{recurringID}", issuesHandler.PatchRecurringIssue)`,
+		`r.With(middleware.OptionalWorkspace, AuthorizeCapability(db, CapabilityProjectsWrite, projectScope("id"))).Delete("/projects/{id}/recurring-issues/{recurringID}", issuesHandler.DeleteRecurringIssue)`,
+		`r.With(middleware.OptionalWorkspace, AuthorizeCapability(db, CapabilityProjectsWrite, projectScope("id"))).Post("/projects/{id}/recurring-issues/{recurringID}/run", issuesHandler.RunRecurringIssue)`,
+	} {
+		if !strings.Contains(string(content), line) {
+			t.Fatalf("expected recurring issue route wiring: %s", line)
+		}
+	}
+}
+
+func TestIssueImportRouteIsWired(t *testing.T) {
+	t.Parallel()
+
+	content, err := os.ReadFile(filepath.Join("router.go"))
+	if err != nil {
+		t.Fatalf("failed to read router.go: %v", err)
+	}
+
+	for _, line := range []string{
+		`issuesHandler.IssueImporter = importer.NewIssueImportRunner(db)`,
+		`r.With(middleware.OptionalWorkspace, AuthorizeCapability(db, CapabilityProjectsWrite, projectScope("id"))).Post("/projects/{id}/issue-imports", issuesHandler.ImportIssues)`,
+	} {
+		if !strings.Contains(string(content), line) {
+			t.Fatalf("expected issue import route wiring: %s", line)
+		}
+	}
+}
+
+func TestIssueAnalyticsRouteIsWired(t *testing.T) {
+	t.Parallel()
+
+	content, err := os.ReadFile(filepath.Join("router.go"))
+	if err != nil {
+		t.Fatalf("failed to read router.go: %v", err)
+	}
+
+	for _, line := range []string{
+		`issueAnalyticsHandler.Store = store.NewIssueAnalyticsStore(db)`,
+		`r.With(middleware.OptionalWorkspace).Get("/projects/{id}/analytics", issueAnalyticsHandler.Get)`,
+	} {
+		if !strings.Contains(string(content), line) {
+			t.Fatalf("expected issue analytics route wiring: %s", line)
+		}
+	}
+}
@@ -2,10 +2,7 @@ package api
 
 import (
 	"context"
-	"crypto/rand"
-	"crypto/sha256"
 	"database/sql"
-	"encoding/hex"
 	"encoding/json"
 	"errors"
 	"net/http"
@@ -14,6 +11,7 @@ import (
 	"time"
 
 	"github.com/go-chi/chi/v5"
+	"github.com/samhotchkiss/otter-camp/internal/store"
 )
 
 type ProfileResponse struct {
@@ -39,11 +37,18 @@ type WorkspaceResponse struct {
 }
 
 type IntegrationAPIKey struct {
-	ID        string    `json:"id"`
-	Name      string    `json:"name"`
-	Prefix    string    `json:"prefix"`
-	CreatedAt time.Time `json:"createdAt"`
-	Key       string    `json:"key,omitempty"`
+	ID         string     `json:"id"`
+	Name       string     `json:"name"`
+	Prefix     string     `json:"prefix"`
+	Scopes     []string   `json:"scopes"`
+	ProjectIDs []string   `json:"projectIds"`
+	AgentIDs   []string   `json:"agentIds"`
+	AllowedIPs []string   `json:"allowedIps"`
+	ExpiresAt  *time.Time `json:"expiresAt,omitempty"`
+	LastUsedAt *time.Time `json:"lastUsedAt,omitempty"`
+	LastUsedIP *string    `json:"lastUsedIp,omitempty"`
+	CreatedAt  time.Time  `json:"createdAt"`
+	Key        string     `json:"key,omitempty"`
 }
 
 type IntegrationsResponse struct {
@@ -58,7 +63,13 @@ type settingsWorkspaceMemberResponse = WorkspaceMember
 type settingsIntegrationsResponse = IntegrationsResponse
 
 type apiKeyCreateRequest struct {
-	Name string `json:"name"`
+	Name          string     `json:"name"`
+	Scopes        []string   `json:"scopes"`
+	ProjectIDs    []string   `json:"projectIds"`
+	AgentIDs      []string   `json:"agentIds"`
+	AllowedIPs    []string   `json:"allowedIps"`
+	ExpiresAt     *time.Time `json:"expiresAt"`
+	ExpiresInDays int        `json:"expiresInDays"`
 }
 
 var validNotificationEvents = map[string]bool{
@@ -294,6 +305,14 @@ func HandleSettingsWorkspacePut(w http.ResponseWriter, r *http.Request) {
 		sendJSON(w, http.StatusInternalServerError, errorResponse{Error: "failed to update workspace"})
 		return
 	}
+	recordAudit(r, auditEvent{
+		OrgID:      identity.OrgID,
+		UserID:     identity.UserID,
+		Action:     "workspace.update",
+		TargetType: "workspace",
+		TargetID:   identity.OrgID,
+		After:      updated,
+	})
 
 	sendJSON(w, http.StatusOK, updated)
 }
@@ -356,6 +375,14 @@ func HandleSettingsIntegrationsPut(w http.ResponseWriter, r *http.Request) {
 		sendJSON(w, http.StatusInternalServerError, errorResponse{Error: "failed to update integrations"})
 		return
 	}
+	recordAudit(r, auditEvent{
+		OrgID:      identity.OrgID,
+		UserID:     identity.UserID,
+		Action:     "integrations.update",
+		TargetType: "workspace",
+		TargetID:   identity.OrgID,
+		After:      map[string]string{"openclaw_webhook_url": strings.TrimSpace(req.OpenClawWebhookURL)},
+	})
 
 	sendJSON(w, http.StatusOK, updated)
 }
@@ -391,12 +418,62 @@ func HandleSettingsAPIKeyCreate(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	key, err := createAPIKey(r.Context(), db, identity.OrgID, name)
+	expiresAt := req.ExpiresAt
+	if req.ExpiresInDays < 0 || (req.ExpiresInDays > 0 && expiresAt != nil) {
+		sendJSON(w, http.StatusBadRequest, errorResponse{Error: "use either expiresAt or a positive expiresInDays"})
+		return
+	}
+	if req.ExpiresInDays > 0 {
+		at := time.Now().UTC().AddDate(0, 0, req.ExpiresInDays)
+		expiresAt = &at
+	}
+
+	// A key may not carry more than its creator could do directly.
+	for _, scope := range req.Scopes {
+		capability, ok := apiKeyScopeCapabilities[strings.TrimSpace(strings.ToLower(scope))]
+		if !ok {
+			continue
+		}
+		allowed, err := identityHasCapability(r.Context(), db, identity, capability, "")
+		if err != nil {
+			sendJSON(w, http.StatusInternalServerError, errorResponse{Error: "failed to check permissions"})
+			return
+		}
+		if !allowed {
+			sendJSON(w, http.StatusForbidden, forbiddenCapabilityResponse{Error: "forbidden", Capability: capability})
+			return
+		}
+	}
+
+	createdBy := identity.UserID
+	created, rawKey, err := store.NewAPIKeyStore(db).Create(r.Context(), identity.OrgID, store.CreateAPIKeyInput{
+		Name:       name,
+		Scopes:     req.Scopes,
+		ProjectIDs: req.ProjectIDs,
+		AgentIDs:   req.AgentIDs,
+		AllowedIPs: req.AllowedIPs,
+		ExpiresAt:  expiresAt,
+		CreatedBy:  &createdBy,
+	})
+	if errors.Is(err, store.ErrValidation) {
+		sendJSON(w, http.StatusBadRequest, errorResponse{Error: err.Error()})
+		return
+	}
 	if err != nil {
 		sendJSON(w, http.StatusInternalServerError, errorResponse{Error: "failed to create api key"})
 		return
 	}
 
+	key := toIntegrationAPIKey(*created)
+	recordAudit(r, auditEvent{
+		OrgID:      identity.OrgID,
+		UserID:     identity.UserID,
+		Action:     "api_key.create",
+		TargetType: "api_key",
+		TargetID:   created.ID,
+		After:      key,
+	})
+	key.Key = rawKey
 	sendJSON(w, http.StatusOK, key)
 }
 
@@ -429,15 +506,22 @@ func HandleSettingsAPIKeyDelete(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	deleted, err := deleteAPIKey(r.Context(), db, identity.OrgID, id)
-	if err != nil {
-		sendJSON(w, http.StatusInternalServerError, errorResponse{Error: "failed to delete api key"})
+	err = store.NewAPIKeyStore(db).Delete(r.Context(), identity.OrgID, id)
+	if errors.Is(err, store.ErrNotFound) {
+		sendJSON(w, http.StatusNotFound, errorResponse{Error: "api key not found"})
 		return
 	}
-	if !deleted {
-		sendJSON(w, http.StatusNotFound, errorResponse{Error: "api key not found"})
+	if err != nil {
+		sendJSON(w, http.StatusInternalServerError, errorResponse{Error: "failed to delete api key"})
 		return
 	}
+	recordAudit(r, auditEvent{
+		OrgID:      identity.OrgID,
+		UserID:     identity.UserID,
+		Action:     "api_key.delete",
+		TargetType: "api_key",
+		TargetID:   id,
+	})
 
 	w.WriteHeader(http.StatusNoContent)
 }
@@ -658,29 +742,13 @@ func fetchIntegrations(ctx context.Context, db *sql.DB, orgID string) (Integrati
 		}
 	}
 
-	rows, err := db.QueryContext(
-		ctx,
-		`SELECT id::text, name, prefix, created_at
-		 FROM api_keys
-		 WHERE org_id = $1
-		 ORDER BY created_at DESC`,
-		orgID,
-	)
+	stored, err := store.NewAPIKeyStore(db).List(ctx, orgID)
 	if err != nil {
 		return IntegrationsResponse{}, err
 	}
-	defer rows.Close()
-
-	keys := make([]IntegrationAPIKey, 0)
-	for rows.Next() {
-		var key IntegrationAPIKey
-		if err := rows.Scan(&key.ID, &key.Name, &key.Prefix, &key.CreatedAt); err != nil {
-			return IntegrationsResponse{}, err
-		}
-		keys = append(keys, key)
-	}
-	if err := rows.Err(); err != nil {
-		return IntegrationsResponse{}, err
+	keys := make([]IntegrationAPIKey, 0, len(stored))
+	for _, key := range stored {
+		keys = append(keys, toIntegrationAPIKey(key))
 	}
 
 	resp := IntegrationsResponse{
@@ -710,66 +778,25 @@ func upsertIntegrations(ctx context.Context, db *sql.DB, orgID, webhook string)
 	return fetchIntegrations(ctx, db, orgID)
 }
 
-func createAPIKey(ctx context.Context, db *sql.DB, orgID, name string) (IntegrationAPIKey, error) {
-	rawKey, err := generateAPIKey()
-	if err != nil {
-		return IntegrationAPIKey{}, err
-	}
-
-	hash := sha256.Sum256([]byte(rawKey))
-	keyHash := hex.EncodeToString(hash[:])
-
-	prefix := rawKey
-	if len(prefix) > 8 {
-		prefix = prefix[:8]
-	}
-
-	var key IntegrationAPIKey
-	key.Name = name
-	key.Prefix = prefix
-	key.Key = rawKey
-
-	err = db.QueryRowContext(
-		ctx,
-		`INSERT INTO api_keys (org_id, name, prefix, key_hash)
-		 VALUES ($1, $2, $3, $4)
-		 RETURNING id::text, created_at`,
-		orgID,
-		name,
-		prefix,
-		keyHash,
-	).Scan(&key.ID, &key.CreatedAt)
-	if err != nil {
-		return IntegrationAPIKey{}, err
-	}
-
-	return key, nil
-}
-
-func deleteAPIKey(ctx context.Context, db *sql.DB, orgID, id string) (bool, error) {
-	var deletedID string
-	err := db.QueryRowContext(
-		ctx,
-		`DELETE FROM api_keys
-		 WHERE id = $1 AND org_id = $2
-		 RETURNING id::text`,
-		id,
-		orgID,
-	).Scan(&deletedID)
-	if err != nil {
-		if errors.Is(err, sql.ErrNoRows) {
-			return false, nil
-		}
-		return false, err
+func toIntegrationAPIKey(key store.APIKey) IntegrationAPIKey {
+	return IntegrationAPIKey{
+		ID:         key.ID,
+		Name:       key.Name,
+		Prefix:     key.Prefix,
+		Scopes:     nonNilStrings(key.Scopes),
+		ProjectIDs: nonNilStrings(key.ProjectIDs),
+		AgentIDs:   nonNilStrings(key.AgentIDs),
+		AllowedIPs: nonNilStrings(key.AllowedIPs),
+		ExpiresAt:  key.ExpiresAt,
+		LastUsedAt: key.LastUsedAt,
+		LastUsedIP: key.LastUsedIP,
+		CreatedAt:  key.CreatedAt,
 	}
-	return true, nil
 }
 
-func generateAPIKey() (string, error) {
-	buf := make([]byte, 32)
-	if _, err := rand.Read(buf); err != nil {
-		return "", err
+func nonNilStrings(values []string) []string {
+	if values == nil {
+		return []string{}
 	}
-
-	return "oc_key_" + hex.EncodeToString(buf), nil
+	return values
 }
@@ -291,3 +291,43 @@ func TestPatchIntegrationsSettings(t *testing.T) {
 	require.NoError(t, err)
 	require.Equal(t, "https://example.com/openclaw/webhook", webhookURL)
 }
+
+func TestCreateScopedAPIKeyChecksCreatorCapabilities(t *testing.T) {
+	connStr := feedTestDatabaseURL(t)
+	resetFeedDatabase(t, connStr)
+	t.Setenv("DATABASE_URL", connStr)
+
+	db := openFeedDatabase(t, connStr)
+	orgID := insertFeedOrganization(t, db, "settings-api-keys")
+	ownerID := insertTestUser(t, db, orgID, "settings-api-keys-owner")
+	viewerID := insertTestUserWithRole(t, db, orgID, "settings-api-keys-viewer", RoleViewer)
+	insertTestSession(t, db, orgID, ownerID, "oc_sess_api_keys_owner", time.Now().UTC().Add(time.Hour))
+	insertTestSession(t, db, orgID, viewerID, "oc_sess_api_keys_viewer", time.Now().UTC().Add(time.Hour))
+
+	router := NewRouter()
+	create := func(token, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/api/settings/integrations/api-keys", bytes.NewBufferString(body))
+		req.Header.Set("Authorization", "Bearer "+token)
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+		return rec
+	}
+
+	rec := create("oc_sess_api_keys_viewer", `{"name":"Bridge","scopes":["issues:write"]}`)
+	require.Equal(t, http.StatusForbidden, rec.Code)
+
+	rec = create("oc_sess_api_keys_owner", `{"name":"Bridge","scopes":["nope:all"]}`)
+	require.Equal(t, http.StatusBadRequest, rec.Code)
+
+	rec = create("oc_sess_api_keys_owner", `{"name":"Bridge","scopes":["issues:write","jobs:run"],"allowedIps":["10.0.0.1"],"expiresInDays":30}`)
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	var key IntegrationAPIKey
+	require.NoError(t, json.NewDecoder(rec.Body).Decode(&key))
+	require.Equal(t, []string{"issues:write", "jobs:run"}, key.Scopes)
+	require.Equal(t, []string{"10.0.0.1/32"}, key.AllowedIPs)
+	require.NotNil(t, key.ExpiresAt)
+	require.Contains(t, key.Key, "oc_key_")
+
+	rec = create("oc_sess_api_keys_viewer", `{"name":"Read only"}`)
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+}
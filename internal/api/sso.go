@@ -0,0 +1,821 @@
+package api
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/samhotchkiss/otter-camp/internal/middleware"
+	"github.com/samhotchkiss/otter-camp/internal/sso"
+	"github.com/samhotchkiss/otter-camp/internal/store"
+)
+
+const ssoLoginStateTTL = 10 * time.Minute
+
+// ssoRoleRank orders roles so a user in several mapped groups gets the most
+// privileged one.
+var ssoRoleRank = map[string]int{
+	RoleViewer:     1,
+	RoleMember:     2,
+	RoleMaintainer: 3,
+	RoleOwner:      4,
+}
+
+// SSOHandler runs OIDC and SAML logins against each org's configured identity
+// providers and manages those providers.
+type SSOHandler struct {
+	DB    *sql.DB
+	Store *store.SSOProviderStore
+	OIDC  *sso.OIDC
+	Now   func() time.Time
+}
+
+type ssoProviderPayload struct {
+	ID                  string            `json:"id"`
+	OrgID               string            `json:"org_id"`
+	Kind                string            `json:"kind"`
+	Name                string            `json:"name"`
+	Enabled             bool              `json:"enabled"`
+	OIDCIssuer          string            `json:"oidc_issuer,omitempty"`
+	OIDCClientID        string            `json:"oidc_client_id,omitempty"`
+	OIDCClientSecretSet bool              `json:"oidc_client_secret_set"`
+	OIDCScopes          []string          `json:"oidc_scopes,omitempty"`
+	SAMLIdPEntityID     string            `json:"saml_idp_entity_id,omitempty"`
+	SAMLIdPSSOURL       string            `json:"saml_idp_sso_url,omitempty"`
+	SAMLIdPCertificate  string            `json:"saml_idp_certificate,omitempty"`
+	SAMLSPEntityID      string            `json:"saml_sp_entity_id,omitempty"`
+	SAMLACSURL          string            `json:"saml_acs_url,omitempty"`
+	OIDCRedirectURL     string            `json:"oidc_redirect_url,omitempty"`
+	EmailClaim          string            `json:"email_claim"`
+	NameClaim           string            `json:"name_claim"`
+	GroupsClaim         string            `json:"groups_claim"`
+	RoleMappings        map[string]string `json:"role_mappings"`
+	DefaultRole         string            `json:"default_role"`
+	AllowedDomains      []string          `json:"allowed_domains"`
+	CreatedAt           string            `json:"created_at"`
+	UpdatedAt           string            `json:"updated_at"`
+}
+
+type ssoPublicProvider struct {
+	ID       string `json:"id"`
+	Name     string `json:"name"`
+	Kind     string `json:"kind"`
+	LoginURL string `json:"login_url"`
+}
+
+func (h *SSOHandler) now() time.Time {
+	if h.Now != nil {
+		return h.Now()
+	}
+	return time.Now()
+}
+
+func (h *SSOHandler) ready(w http.ResponseWriter) bool {
+	if h.Store == nil || h.DB == nil {
+		sendJSON(w, http.StatusServiceUnavailable, errorResponse{Error: "database not available"})
+		return false
+	}
+	if h.OIDC == nil {
+		h.OIDC = sso.NewOIDC(nil)
+	}
+	return true
+}
+
+// Providers handles GET /api/auth/sso/{org}/providers. It is public so the
+// login page can offer the org's IdPs.
+func (h *SSOHandler) Providers(w http.ResponseWriter, r *http.Request) {
+	if !h.ready(w) {
+		return
+	}
+	orgID, enforced, err := resolveSSOOrg(r.Context(), h.DB, chi.URLParam(r, "org"))
+	if err != nil {
+		sendSSOOrgError(w, err)
+		return
+	}
+	providers, err := h.Store.ListEnabledForOrg(r.Context(), orgID)
+	if err != nil {
+		handleJobsStoreError(w, err)
+		return
+	}
+	base := strings.TrimSuffix(getPublicBaseURL(r), "/")
+	items := make([]ssoPublicProvider, 0, len(providers))
+	for _, provider := range providers {
+		items = append(items, ssoPublicProvider{
+			ID:       provider.ID,
+			Name:     provider.Name,
+			Kind:     provider.Kind,
+			LoginURL: base + "/api/auth/sso/" + url.PathEscape(chi.URLParam(r, "org")) + "/start?provider=" + provider.ID,
+		})
+	}
+	sendJSON(w, http.StatusOK, map[string]any{
+		"org_id":       orgID,
+		"sso_enforced": enforced,
+		"providers":    items,
+	})
+}
+
+// Start handles GET /api/auth/sso/{org}/start?provider=&return_to=. It
+// records a single-use login state and redirects the browser to the IdP.
+func (h *SSOHandler) Start(w http.ResponseWriter, r *http.Request) {
+	if !h.ready(w) {
+		return
+	}
+	orgID, _, err := resolveSSOOrg(r.Context(), h.DB, chi.URLParam(r, "org"))
+	if err != nil {
+		sendSSOOrgError(w, err)
+		return
+	}
+	returnTo, ok := sanitizeSSOReturnTo(r.URL.Query().Get("return_to"))
+	if !ok {
+		sendJSON(w, http.StatusBadRequest, errorResponse{Error: "return_to must be a relative path"})
+		return
+	}
+
+	provider, err := h.startProvider(r.Context(), orgID, strings.TrimSpace(r.URL.Query().Get("provider")))
+	if err != nil {
+		handleJobsStoreError(w, err)
+		return
+	}
+
+	stateToken, err := sso.RandomToken(32)
+	if err != nil {
+		sendJSON(w, http.StatusInternalServerError, errorResponse{Error: "failed to start sso login"})
+		return
+	}
+	loginState := store.SSOLoginState{
+		OrgID:      orgID,
+		ProviderID: provider.ID,
+		State:      stateToken,
+		ReturnTo:   returnTo,
+		ExpiresAt:  h.now().UTC().Add(ssoLoginStateTTL),
+	}
+
+	var redirectURL string
+	switch provider.Kind {
+	case store.SSOProviderKindOIDC:
+		nonce, err := sso.RandomToken(24)
+		if err != nil {
+			sendJSON(w, http.StatusInternalServerError, errorResponse{Error: "failed to start sso login"})
+			return
+		}
+		verifier, err := sso.NewPKCEVerifier()
+		if err != nil {
+			sendJSON(w, http.StatusInternalServerError, errorResponse{Error: "failed to start sso login"})
+			return
+		}
+		loginState.Nonce = nonce
+		loginState.CodeVerifier = verifier
+		redirectURL, err = h.OIDC.AuthCodeURL(r.Context(), ssoOIDCConfig(r, *provider), stateToken, nonce, verifier)
+		if err != nil {
+			sendJSON(w, http.StatusBadGateway, errorResponse{Error: err.Error()})
+			return
+		}
+	case store.SSOProviderKindSAML:
+		request, err := sso.NewSAMLRequest(ssoSAMLConfig(r, *provider), stateToken, h.now())
+		if err != nil {
+			sendJSON(w, http.StatusInternalServerError, errorResponse{Error: err.Error()})
+			return
+		}
+		loginState.SAMLRequestID = request.ID
+		redirectURL = request.URL
+	}
+
+	if err := h.Store.CreateLoginState(r.Context(), loginState); err != nil {
+		sendJSON(w, http.StatusInternalServerError, errorResponse{Error: "failed to start sso login"})
+		return
+	}
+	http.Redirect(w, r, redirectURL, http.StatusFound)
+}
+
+func (h *SSOHandler) startProvider(ctx context.Context, orgID, providerID string) (*store.SSOProvider, error) {
+	if providerID != "" {
+		provider, err := h.Store.GetForOrg(ctx, orgID, providerID)
+		if err != nil {
+			return nil, err
+		}
+		if !provider.Enabled {
+			return nil, store.ErrNotFound
+		}
+		return provider, nil
+	}
+	providers, err := h.Store.ListEnabledForOrg(ctx, orgID)
+	if err != nil {
+		return nil, err
+	}
+	switch len(providers) {
+	case 0:
+		return nil, store.ErrNotFound
+	case 1:
+		return &providers[0], nil
+	default:
+		return nil, fmt.Errorf("%w: provider is required when an org has several sso providers", store.ErrValidation)
+	}
+}
+
+// OIDCCallback handles GET /api/auth/sso/oidc/callback.
+func (h *SSOHandler) OIDCCallback(w http.ResponseWriter, r *http.Request) {
+	if !h.ready(w) {
+		return
+	}
+	query := r.URL.Query()
+	loginState, provider, ok := h.consumeLoginState(w, r, query.Get("state"), store.SSOProviderKindOIDC)
+	if !ok {
+		return
+	}
+	if idpError := strings.TrimSpace(query.Get("error")); idpError != "" {
+		sendJSON(w, http.StatusUnauthorized, errorResponse{Error: "identity provider error: " + idpError})
+		return
+	}
+	code := strings.TrimSpace(query.Get("code"))
+	if code == "" {
+		sendJSON(w, http.StatusBadRequest, errorResponse{Error: "missing code"})
+		return
+	}
+
+	identity, err := h.OIDC.Exchange(r.Context(), ssoOIDCConfig(r, *provider), code, loginState.CodeVerifier, loginState.Nonce)
+	if err != nil {
+		sendJSON(w, http.StatusUnauthorized, errorResponse{Error: err.Error()})
+		return
+	}
+	h.completeLogin(w, r, loginState, provider, identity)
+}
+
+// SAMLACS handles POST /api/auth/sso/saml/acs (HTTP-POST binding). The
+// RelayState carries the login state created by Start.
+func (h *SSOHandler) SAMLACS(w http.ResponseWriter, r *http.Request) {
+	if !h.ready(w) {
+		return
+	}
+	r.Body = http.MaxBytesReader(w, r.Body, 2<<20)
+	if err := r.ParseForm(); err != nil {
+		sendJSON(w, http.StatusBadRequest, errorResponse{Error: "invalid form body"})
+		return
+	}
+	loginState, provider, ok := h.consumeLoginState(w, r, r.PostForm.Get("RelayState"), store.SSOProviderKindSAML)
+	if !ok {
+		return
+	}
+	identity, err := sso.ParseSAMLResponse(ssoSAMLConfig(r, *provider), r.PostForm.Get("SAMLResponse"), loginState.SAMLRequestID, h.now())
+	if err != nil {
+		sendJSON(w, http.StatusUnauthorized, errorResponse{Error: err.Error()})
+		return
+	}
+	h.completeLogin(w, r, loginState, provider, identity)
+}
+
+// SAMLMetadata handles GET /api/auth/sso/{org}/saml/{id}/metadata. The URL
+// doubles as the SP entity ID registered with the IdP.
+func (h *SSOHandler) SAMLMetadata(w http.ResponseWriter, r *http.Request) {
+	if !h.ready(w) {
+		return
+	}
+	orgID, _, err := resolveSSOOrg(r.Context(), h.DB, chi.URLParam(r, "org"))
+	if err != nil {
+		sendSSOOrgError(w, err)
+		return
+	}
+	provider, err := h.Store.GetForOrg(r.Context(), orgID, strings.TrimSpace(chi.URLParam(r, "id")))
+	if err != nil {
+		handleJobsStoreError(w, err)
+		return
+	}
+	if provider.Kind != store.SSOProviderKindSAML {
+		sendJSON(w, http.StatusNotFound, errorResponse{Error: "not found"})
+		return
+	}
+	w.Header().Set("Content-Type", "application/samlmetadata+xml")
+	w.WriteHeader(http.StatusOK)
+	_, _ = w.Write(sso.SAMLMetadata(ssoSAMLConfig(r, *provider)))
+}
+
+func (h *SSOHandler) consumeLoginState(
+	w http.ResponseWriter,
+	r *http.Request,
+	state string,
+	kind string,
+) (*store.SSOLoginState, *store.SSOProvider, bool) {
+	loginState, err := h.Store.ConsumeLoginState(r.Context(), state)
+	if err != nil {
+		if errors.Is(err, store.ErrNotFound) {
+			sendJSON(w, http.StatusUnauthorized, errorResponse{Error: "sso login expired or already used"})
+			return nil, nil, false
+		}
+		sendJSON(w, http.StatusInternalServerError, errorResponse{Error: "failed to load sso login"})
+		return nil, nil, false
+	}
+	provider, err := h.Store.GetForOrg(r.Context(), loginState.OrgID, loginState.ProviderID)
+	if err != nil {
+		handleJobsStoreError(w, err)
+		return nil, nil, false
+	}
+	if provider.Kind != kind || !provider.Enabled {
+		sendJSON(w, http.StatusUnauthorized, errorResponse{Error: "sso provider is not available"})
+		return nil, nil, false
+	}
+	return loginState, provider, true
+}
+
+// completeLogin provisions the user just in time, applies group role
+// mappings, opens a session and sends the browser back to the app.
+func (h *SSOHandler) completeLogin(
+	w http.ResponseWriter,
+	r *http.Request,
+	loginState *store.SSOLoginState,
+	provider *store.SSOProvider,
+	identity sso.Identity,
+) {
+	if strings.TrimSpace(identity.Subject) == "" {
+		sendJSON(w, http.StatusUnauthorized, errorResponse{Error: "identity provider did not return a subject"})
+		return
+	}
+	if identity.EmailVerified != nil && !*identity.EmailVerified {
+		sendJSON(w, http.StatusForbidden, errorResponse{Error: "email address is not verified"})
+		return
+	}
+	if !ssoEmailDomainAllowed(identity.Email, provider.AllowedDomains) {
+		sendJSON(w, http.StatusForbidden, errorResponse{Error: "email domain is not allowed for this org"})
+		return
+	}
+
+	role, mapped := resolveSSORole(*provider, identity.Groups)
+	userID, err := upsertSSOUser(r.Context(), h.DB, loginState.OrgID, identity, role, mapped)
+	if err != nil {
+		sendJSON(w, http.StatusInternalServerError, errorResponse{Error: "failed to create user"})
+		return
+	}
+	sessionToken, _, err := createSession(r.Context(), h.DB, loginState.OrgID, userID)
+	if err != nil {
+		sendJSON(w, http.StatusInternalServerError, errorResponse{Error: "failed to create session"})
+		return
+	}
+
+	returnTo := loginState.ReturnTo
+	if returnTo == "" {
+		returnTo = "/"
+	}
+	target, err := url.Parse(ssoFrontendBaseURL(r) + returnTo)
+	if err != nil {
+		sendJSON(w, http.StatusInternalServerError, errorResponse{Error: "invalid return_to"})
+		return
+	}
+	query := target.Query()
+	query.Set("auth", sessionToken)
+	target.RawQuery = query.Encode()
+	http.Redirect(w, r, target.String(), http.StatusFound)
+}
+
+// ListProviders handles GET /api/admin/sso/providers
+func (h *SSOHandler) ListProviders(w http.ResponseWriter, r *http.Request) {
+	if !h.ready(w) {
+		return
+	}
+	providers, err := h.Store.List(r.Context())
+	if err != nil {
+		handleJobsStoreError(w, err)
+		return
+	}
+	items := make([]ssoProviderPayload, 0, len(providers))
+	for _, provider := range providers {
+		items = append(items, toSSOProviderPayload(r, provider))
+	}
+	sendJSON(w, http.StatusOK, map[string]any{"items": items, "total": len(items)})
+}
+
+// CreateProvider handles POST /api/admin/sso/providers
+func (h *SSOHandler) CreateProvider(w http.ResponseWriter, r *http.Request) {
+	if !h.ready(w) {
+		return
+	}
+
+	var req struct {
+		Kind               string            `json:"kind"`
+		Name               string            `json:"name"`
+		Enabled            *bool             `json:"enabled"`
+		OIDCIssuer         string            `json:"oidc_issuer"`
+		OIDCClientID       string            `json:"oidc_client_id"`
+		OIDCClientSecret   string            `json:"oidc_client_secret"`
+		OIDCScopes         []string          `json:"oidc_scopes"`
+		SAMLIdPEntityID    string            `json:"saml_idp_entity_id"`
+		SAMLIdPSSOURL      string            `json:"saml_idp_sso_url"`
+		SAMLIdPCertificate string            `json:"saml_idp_certificate"`
+		EmailClaim         string            `json:"email_claim"`
+		NameClaim          string            `json:"name_claim"`
+		GroupsClaim        string            `json:"groups_claim"`
+		RoleMappings       map[string]string `json:"role_mappings"`
+		DefaultRole        string            `json:"default_role"`
+		AllowedDomains     []string          `json:"allowed_domains"`
+	}
+	decoder := json.NewDecoder(r.Body)
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&req); err != nil {
+		sendJSON(w, http.StatusBadRequest, errorResponse{Error: "invalid JSON"})
+		return
+	}
+
+	created, err := h.Store.Create(r.Context(), store.CreateSSOProviderInput{
+		Kind:               req.Kind,
+		Name:               req.Name,
+		Enabled:            req.Enabled,
+		OIDCIssuer:         req.OIDCIssuer,
+		OIDCClientID:       req.OIDCClientID,
+		OIDCClientSecret:   req.OIDCClientSecret,
+		OIDCScopes:         req.OIDCScopes,
+		SAMLIdPEntityID:    req.SAMLIdPEntityID,
+		SAMLIdPSSOURL:      req.SAMLIdPSSOURL,
+		SAMLIdPCertificate: req.SAMLIdPCertificate,
+		EmailClaim:         req.EmailClaim,
+		NameClaim:          req.NameClaim,
+		GroupsClaim:        req.GroupsClaim,
+		RoleMappings:       req.RoleMappings,
+		DefaultRole:        req.DefaultRole,
+		AllowedDomains:     req.AllowedDomains,
+	})
+	if err != nil {
+		handleJobsStoreError(w, err)
+		return
+	}
+	payload := toSSOProviderPayload(r, *created)
+	recordAudit(r, auditEvent{Action: "sso_provider.create", TargetType: "sso_provider", TargetID: created.ID, After: payload})
+	sendJSON(w, http.StatusCreated, payload)
+}
+
+// PatchProvider handles PATCH /api/admin/sso/providers/{id}
+func (h *SSOHandler) PatchProvider(w http.ResponseWriter, r *http.Request) {
+	if !h.ready(w) {
+		return
+	}
+
+	var req struct {
+		Name               *string            `json:"name"`
+		Enabled            *bool              `json:"enabled"`
+		OIDCIssuer         *string            `json:"oidc_issuer"`
+		OIDCClientID       *string            `json:"oidc_client_id"`
+		OIDCClientSecret   *string            `json:"oidc_client_secret"`
+		OIDCScopes         *[]string          `json:"oidc_scopes"`
+		SAMLIdPEntityID    *string            `json:"saml_idp_entity_id"`
+		SAMLIdPSSOURL      *string            `json:"saml_idp_sso_url"`
+		SAMLIdPCertificate *string            `json:"saml_idp_certificate"`
+		EmailClaim         *string            `json:"email_claim"`
+		NameClaim          *string            `json:"name_claim"`
+		GroupsClaim        *string            `json:"groups_claim"`
+		RoleMappings       *map[string]string `json:"role_mappings"`
+		DefaultRole        *string            `json:"default_role"`
+		AllowedDomains     *[]string          `json:"allowed_domains"`
+	}
+	decoder := json.NewDecoder(r.Body)
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&req); err != nil {
+		sendJSON(w, http.StatusBadRequest, errorResponse{Error: "invalid JSON"})
+		return
+	}
+
+	providerID := chi.URLParam(r, "id")
+	var before *ssoProviderPayload
+	if existing, err := h.Store.Get(r.Context(), providerID); err == nil {
+		payload := toSSOProviderPayload(r, *existing)
+		before = &payload
+	}
+
+	updated, err := h.Store.Update(r.Context(), providerID, store.UpdateSSOProviderInput{
+		Name:               req.Name,
+		Enabled:            req.Enabled,
+		OIDCIssuer:         req.OIDCIssuer,
+		OIDCClientID:       req.OIDCClientID,
+		OIDCClientSecret:   req.OIDCClientSecret,
+		OIDCScopes:         req.OIDCScopes,
+		SAMLIdPEntityID:    req.SAMLIdPEntityID,
+		SAMLIdPSSOURL:      req.SAMLIdPSSOURL,
+		SAMLIdPCertificate: req.SAMLIdPCertificate,
+		EmailClaim:         req.EmailClaim,
+		NameClaim:          req.NameClaim,
+		GroupsClaim:        req.GroupsClaim,
+		RoleMappings:       req.RoleMappings,
+		DefaultRole:        req.DefaultRole,
+		AllowedDomains:     req.AllowedDomains,
+	})
+	if err != nil {
+		handleJobsStoreError(w, err)
+		return
+	}
+	payload := toSSOProviderPayload(r, *updated)
+	recordAudit(r, auditEvent{Action: "sso_provider.update", TargetType: "sso_provider", TargetID: updated.ID, Before: before, After: payload})
+	sendJSON(w, http.StatusOK, payload)
+}
+
+// DeleteProvider handles DELETE /api/admin/sso/providers/{id}
+func (h *SSOHandler) DeleteProvider(w http.ResponseWriter, r *http.Request) {
+	if !h.ready(w) {
+		return
+	}
+	providerID := chi.URLParam(r, "id")
+	var before *ssoProviderPayload
+	if existing, err := h.Store.Get(r.Context(), providerID); err == nil {
+		payload := toSSOProviderPayload(r, *existing)
+		before = &payload
+	}
+	if err := h.Store.Delete(r.Context(), providerID); err != nil {
+		handleJobsStoreError(w, err)
+		return
+	}
+	recordAudit(r, auditEvent{Action: "sso_provider.delete", TargetType: "sso_provider", TargetID: providerID, Before: before})
+	sendJSON(w, http.StatusOK, map[string]bool{"ok": true})
+}
+
+// GetEnforcement handles GET /api/admin/sso/enforcement
+func (h *SSOHandler) GetEnforcement(w http.ResponseWriter, r *http.Request) {
+	if !h.ready(w) {
+		return
+	}
+	enforced, err := h.Store.SSOEnforced(r.Context(), middleware.WorkspaceFromContext(r.Context()))
+	if err != nil {
+		handleJobsStoreError(w, err)
+		return
+	}
+	sendJSON(w, http.StatusOK, map[string]bool{"sso_only": enforced})
+}
+
+// PutEnforcement handles PUT /api/admin/sso/enforcement. Turning SSO-only on
+// revokes existing non-SSO sessions except the caller's own.
+func (h *SSOHandler) PutEnforcement(w http.ResponseWriter, r *http.Request) {
+	if !h.ready(w) {
+		return
+	}
+	var req struct {
+		SSOOnly *bool `json:"sso_only"`
+	}
+	decoder := json.NewDecoder(r.Body)
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&req); err != nil || req.SSOOnly == nil {
+		sendJSON(w, http.StatusBadRequest, errorResponse{Error: "invalid JSON"})
+		return
+	}
+	orgID := middleware.WorkspaceFromContext(r.Context())
+	wasEnforced, err := h.Store.SSOEnforced(r.Context(), orgID)
+	if err != nil {
+		handleJobsStoreError(w, err)
+		return
+	}
+	revoked, err := h.Store.SetSSOEnforced(r.Context(), *req.SSOOnly, extractSessionToken(r))
+	if err != nil {
+		handleJobsStoreError(w, err)
+		return
+	}
+	recordAudit(r, auditEvent{
+		OrgID:      orgID,
+		Action:     "sso.enforcement.update",
+		TargetType: "workspace",
+		TargetID:   orgID,
+		Before:     map[string]bool{"sso_only": wasEnforced},
+		After:      map[string]any{"sso_only": *req.SSOOnly, "revoked_sessions": revoked},
+	})
+	sendJSON(w, http.StatusOK, map[string]any{"sso_only": *req.SSOOnly, "revoked_sessions": revoked})
+}
+
+func toSSOProviderPayload(r *http.Request, provider store.SSOProvider) ssoProviderPayload {
+	payload := ssoProviderPayload{
+		ID:                  provider.ID,
+		OrgID:               provider.OrgID,
+		Kind:                provider.Kind,
+		Name:                provider.Name,
+		Enabled:             provider.Enabled,
+		OIDCIssuer:          provider.OIDCIssuer,
+		OIDCClientID:        provider.OIDCClientID,
+		OIDCClientSecretSet: provider.OIDCClientSecret != "",
+		OIDCScopes:          provider.OIDCScopes,
+		SAMLIdPEntityID:     provider.SAMLIdPEntityID,
+		SAMLIdPSSOURL:       provider.SAMLIdPSSOURL,
+		SAMLIdPCertificate:  provider.SAMLIdPCertificate,
+		EmailClaim:          provider.EmailClaim,
+		NameClaim:           provider.NameClaim,
+		GroupsClaim:         provider.GroupsClaim,
+		RoleMappings:        provider.RoleMappings,
+		DefaultRole:         provider.DefaultRole,
+		AllowedDomains:      provider.AllowedDomains,
+		CreatedAt:           provider.CreatedAt.UTC().Format(time.RFC3339),
+		UpdatedAt:           provider.UpdatedAt.UTC().Format(time.RFC3339),
+	}
+	if payload.RoleMappings == nil {
+		payload.RoleMappings = map[string]string{}
+	}
+	if payload.AllowedDomains == nil {
+		payload.AllowedDomains = []string{}
+	}
+	switch provider.Kind {
+	case store.SSOProviderKindOIDC:
+		payload.OIDCRedirectURL = ssoOIDCRedirectURL(r)
+	case store.SSOProviderKindSAML:
+		payload.SAMLSPEntityID = ssoSAMLEntityID(r, provider.OrgID, provider.ID)
+		payload.SAMLACSURL = ssoSAMLACSURL(r)
+	}
+	return payload
+}
+
+// resolveSSORole picks the highest role among the user's mapped groups. When
+// the provider has mappings, the role is authoritative (mapped=true) and is
+// re-applied on every login; otherwise DefaultRole only seeds new users.
+func resolveSSORole(provider store.SSOProvider, groups []string) (string, bool) {
+	if len(provider.RoleMappings) == 0 {
+		return provider.DefaultRole, false
+	}
+	best := ""
+	for _, group := range groups {
+		role, ok := provider.RoleMappings[strings.TrimSpace(group)]
+		if ok && ssoRoleRank[role] > ssoRoleRank[best] {
+			best = role
+		}
+	}
+	if best == "" {
+		best = provider.DefaultRole
+	}
+	return best, true
+}
+
+func upsertSSOUser(ctx context.Context, db *sql.DB, orgID string, identity sso.Identity, role string, updateRole bool) (string, error) {
+	displayName := strings.TrimSpace(identity.Name)
+	if displayName == "" {
+		displayName = strings.TrimSpace(identity.Email)
+	}
+	if displayName == "" {
+		displayName = identity.Subject
+	}
+	var email any
+	if trimmed := strings.TrimSpace(identity.Email); trimmed != "" {
+		email = trimmed
+	}
+
+	var userID string
+	err := db.QueryRowContext(
+		ctx,
+		`INSERT INTO users (org_id, subject, issuer, display_name, email, role)
+		 VALUES ($1, $2, $3, $4, $5, $6)
+		 ON CONFLICT (org_id, issuer, subject)
+		 DO UPDATE SET display_name = EXCLUDED.display_name,
+		               email = COALESCE(EXCLUDED.email, users.email),
+		               role = CASE WHEN $7 THEN EXCLUDED.role ELSE users.role END,
+		               updated_at = NOW()
+		 RETURNING id::text`,
+		orgID,
+		identity.Subject,
+		identity.Issuer,
+		displayName,
+		email,
+		role,
+		updateRole,
+	).Scan(&userID)
+	return userID, err
+}
+
+func ssoEmailDomainAllowed(email string, allowed []string) bool {
+	if len(allowed) == 0 {
+		return true
+	}
+	at := strings.LastIndex(email, "@")
+	if at < 0 {
+		return false
+	}
+	domain := strings.ToLower(email[at+1:])
+	for _, candidate := range allowed {
+		if domain == candidate {
+			return true
+		}
+	}
+	return false
+}
+
+// sanitizeSSOReturnTo only accepts same-origin paths, so the session token
+// can never be redirected to another host.
+func sanitizeSSOReturnTo(value string) (string, bool) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return "", true
+	}
+	if !strings.HasPrefix(value, "/") || strings.HasPrefix(value, "//") || strings.ContainsAny(value, "\\\r\n") {
+		return "", false
+	}
+	parsed, err := url.Parse(value)
+	if err != nil || parsed.Scheme != "" || parsed.Host != "" {
+		return "", false
+	}
+	return value, true
+}
+
+// ssoFrontendBaseURL deliberately ignores Origin and Referer: on an IdP
+// callback those name the IdP, not the app.
+func ssoFrontendBaseURL(r *http.Request) string {
+	if base := strings.TrimSpace(os.Getenv("OTTER_FRONTEND_BASE_URL")); base != "" {
+		return strings.TrimRight(base, "/")
+	}
+	return strings.TrimRight(getPublicBaseURL(r), "/")
+}
+
+func ssoOIDCConfig(r *http.Request, provider store.SSOProvider) sso.OIDCConfig {
+	return sso.OIDCConfig{
+		Issuer:       provider.OIDCIssuer,
+		ClientID:     provider.OIDCClientID,
+		ClientSecret: provider.OIDCClientSecret,
+		RedirectURL:  ssoOIDCRedirectURL(r),
+		Scopes:       provider.OIDCScopes,
+		Claims:       ssoClaimMapping(provider),
+	}
+}
+
+func ssoSAMLConfig(r *http.Request, provider store.SSOProvider) sso.SAMLConfig {
+	return sso.SAMLConfig{
+		IdPEntityID:       provider.SAMLIdPEntityID,
+		IdPSSOURL:         provider.SAMLIdPSSOURL,
+		IdPCertificatePEM: provider.SAMLIdPCertificate,
+		SPEntityID:        ssoSAMLEntityID(r, provider.OrgID, provider.ID),
+		ACSURL:            ssoSAMLACSURL(r),
+		Claims:            ssoClaimMapping(provider),
+	}
+}
+
+func ssoClaimMapping(provider store.SSOProvider) sso.ClaimMapping {
+	return sso.ClaimMapping{Email: provider.EmailClaim, Name: provider.NameClaim, Groups: provider.GroupsClaim}
+}
+
+func ssoOIDCRedirectURL(r *http.Request) string {
+	return strings.TrimSuffix(getPublicBaseURL(r), "/") + "/api/auth/sso/oidc/callback"
+}
+
+func ssoSAMLACSURL(r *http.Request) string {
+	return strings.TrimSuffix(getPublicBaseURL(r), "/") + "/api/auth/sso/saml/acs"
+}
+
+func ssoSAMLEntityID(r *http.Request, orgID, providerID string) string {
+	return strings.TrimSuffix(getPublicBaseURL(r), "/") + "/api/auth/sso/" + orgID + "/saml/" + providerID + "/metadata"
+}
+
+var errSSOOrgNotFound = errors.New("org not found")
+
+// resolveSSOOrg accepts an org id or slug.
+func resolveSSOOrg(ctx context.Context, db *sql.DB, ref string) (string, bool, error) {
+	ref = strings.TrimSpace(ref)
+	if ref == "" {
+		return "", false, errSSOOrgNotFound
+	}
+	query := `SELECT id::text, sso_enforced FROM organizations WHERE slug = $1`
+	if uuidRegex.MatchString(ref) {
+		query = `SELECT id::text, sso_enforced FROM organizations WHERE id = $1::uuid`
+	}
+	var orgID string
+	var enforced bool
+	if err := db.QueryRowContext(ctx, query, ref).Scan(&orgID, &enforced); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return "", false, errSSOOrgNotFound
+		}
+		return "", false, err
+	}
+	return orgID, enforced, nil
+}
+
+func sendSSOOrgError(w http.ResponseWriter, err error) {
+	if errors.Is(err, errSSOOrgNotFound) {
+		sendJSON(w, http.StatusNotFound, errorResponse{Error: "org not found"})
+		return
+	}
+	sendJSON(w, http.StatusInternalServerError, errorResponse{Error: "failed to load org"})
+}
+
+// requireNonSSOLoginAllowed rejects password-less and OpenClaw logins into
+// orgs that only accept SSO.
+func requireNonSSOLoginAllowed(w http.ResponseWriter, r *http.Request, db *sql.DB, orgID string) bool {
+	enforced, err := orgSSOEnforced(r.Context(), db, orgID)
+	if err != nil {
+		sendJSON(w, http.StatusInternalServerError, errorResponse{Error: "failed to load organization"})
+		return false
+	}
+	if enforced {
+		sendJSON(w, http.StatusForbidden, errorResponse{Error: "sso required"})
+		return false
+	}
+	return true
+}
+
+// orgSSOEnforced reports whether an org only accepts SSO logins.
+func orgSSOEnforced(ctx context.Context, db *sql.DB, orgID string) (bool, error) {
+	var enforced bool
+	err := db.QueryRowContext(ctx, `SELECT sso_enforced FROM organizations WHERE id = $1`, orgID).Scan(&enforced)
+	if errors.Is(err, sql.ErrNoRows) {
+		return false, nil
+	}
+	return enforced, err
+}
+
+// orgSlugSSOEnforced is orgSSOEnforced for callers that only know the slug.
+func orgSlugSSOEnforced(ctx context.Context, db *sql.DB, slug string) (bool, error) {
+	var enforced bool
+	err := db.QueryRowContext(ctx, `SELECT sso_enforced FROM organizations WHERE slug = $1`, slug).Scan(&enforced)
+	if errors.Is(err, sql.ErrNoRows) {
+		return false, nil
+	}
+	return enforced, err
+}
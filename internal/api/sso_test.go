@@ -0,0 +1,144 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/samhotchkiss/otter-camp/internal/sso/ssotest"
+	"github.com/samhotchkiss/otter-camp/internal/store"
+	"github.com/stretchr/testify/require"
+)
+
+func TestResolveSSORolePicksHighestMappedRole(t *testing.T) {
+	provider := store.SSOProvider{
+		DefaultRole:  RoleViewer,
+		RoleMappings: map[string]string{"eng": RoleMember, "leads": RoleMaintainer, "admins": RoleOwner},
+	}
+
+	role, mapped := resolveSSORole(provider, []string{"eng", "leads"})
+	require.Equal(t, RoleMaintainer, role)
+	require.True(t, mapped)
+
+	role, mapped = resolveSSORole(provider, []string{"sales"})
+	require.Equal(t, RoleViewer, role)
+	require.True(t, mapped)
+
+	role, mapped = resolveSSORole(store.SSOProvider{DefaultRole: RoleMember}, []string{"admins"})
+	require.Equal(t, RoleMember, role)
+	require.False(t, mapped)
+}
+
+func TestSanitizeSSOReturnToOnlyAllowsLocalPaths(t *testing.T) {
+	for _, value := range []string{"", "/", "/projects/abc?tab=issues"} {
+		got, ok := sanitizeSSOReturnTo(value)
+		require.True(t, ok, value)
+		require.Equal(t, value, got)
+	}
+	for _, value := range []string{"https://evil.example", "//evil.example", "/\\evil.example", "projects", "/a\r\nLocation: x"} {
+		_, ok := sanitizeSSOReturnTo(value)
+		require.False(t, ok, value)
+	}
+}
+
+func TestSSOEmailDomainAllowed(t *testing.T) {
+	require.True(t, ssoEmailDomainAllowed("sam@example.com", nil))
+	require.True(t, ssoEmailDomainAllowed("sam@Example.COM", []string{"example.com"}))
+	require.False(t, ssoEmailDomainAllowed("sam@example.com.evil", []string{"example.com"}))
+	require.False(t, ssoEmailDomainAllowed("", []string{"example.com"}))
+}
+
+func TestSSOHandlerRequiresDatabase(t *testing.T) {
+	handler := &SSOHandler{}
+	rec := httptest.NewRecorder()
+	handler.Start(rec, httptest.NewRequest(http.MethodGet, "/api/auth/sso/acme/start", nil))
+	require.Equal(t, http.StatusServiceUnavailable, rec.Code)
+}
+
+func TestSSOOIDCLoginProvisionsUserAndEnforcesSSOOnly(t *testing.T) {
+	db := setupMessageTestDB(t)
+	t.Setenv("OTTER_PUBLIC_BASE_URL", "https://otter.test")
+	t.Setenv("OTTER_FRONTEND_BASE_URL", "")
+	orgID := insertMessageTestOrganization(t, db, "sso-oidc-org")
+	adminID := insertTestUser(t, db, orgID, "sso-admin")
+	_, err := db.Exec(`UPDATE users SET role = 'owner' WHERE id = $1`, adminID)
+	require.NoError(t, err)
+	insertTestSession(t, db, orgID, adminID, "oc_sess_sso_admin", time.Now().Add(time.Hour))
+
+	idp, err := ssotest.New()
+	require.NoError(t, err)
+	defer idp.Close()
+	idp.SetUser(ssotest.User{Subject: "okta-42", Email: "dev@example.com", Name: "Dev", Groups: []string{"eng"}})
+
+	router := NewRouter()
+	serve := func(req *http.Request) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+		return rec
+	}
+	admin := func(method, path, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, bytes.NewBufferString(body))
+		req.Header.Set("Authorization", "Bearer oc_sess_sso_admin")
+		return serve(req)
+	}
+
+	rec := admin(http.MethodPost, "/api/admin/sso/providers", `{
+		"kind":"oidc","name":"Okta","oidc_issuer":"`+idp.Issuer+`",
+		"oidc_client_id":"`+idp.ClientID+`","oidc_client_secret":"`+idp.ClientSecret+`",
+		"role_mappings":{"eng":"maintainer"},"allowed_domains":["example.com"]}`)
+	require.Equal(t, http.StatusCreated, rec.Code, rec.Body.String())
+	require.NotContains(t, rec.Body.String(), idp.ClientSecret)
+	var provider ssoProviderPayload
+	require.NoError(t, json.NewDecoder(rec.Body).Decode(&provider))
+	require.True(t, provider.OIDCClientSecretSet)
+	require.Equal(t, "https://otter.test/api/auth/sso/oidc/callback", provider.OIDCRedirectURL)
+
+	rec = serve(httptest.NewRequest(http.MethodGet, "/api/auth/sso/sso-oidc-org/start?return_to=/projects", nil))
+	require.Equal(t, http.StatusFound, rec.Code, rec.Body.String())
+
+	noRedirect := &http.Client{CheckRedirect: func(*http.Request, []*http.Request) error { return http.ErrUseLastResponse }}
+	resp, err := noRedirect.Get(rec.Header().Get("Location"))
+	require.NoError(t, err)
+	_ = resp.Body.Close()
+	require.Equal(t, http.StatusFound, resp.StatusCode)
+	callback, err := url.Parse(resp.Header.Get("Location"))
+	require.NoError(t, err)
+	require.Equal(t, "/api/auth/sso/oidc/callback", callback.Path)
+
+	rec = serve(httptest.NewRequest(http.MethodGet, callback.RequestURI(), nil))
+	require.Equal(t, http.StatusFound, rec.Code, rec.Body.String())
+	landing, err := url.Parse(rec.Header().Get("Location"))
+	require.NoError(t, err)
+	require.Equal(t, "otter.test", landing.Host)
+	require.Equal(t, "/projects", landing.Path)
+	require.True(t, strings.HasPrefix(landing.Query().Get("auth"), "oc_sess_"))
+
+	var role, email string
+	require.NoError(t, db.QueryRow(
+		`SELECT role, email FROM users WHERE org_id = $1 AND issuer = $2 AND subject = 'okta-42'`,
+		orgID, idp.Issuer,
+	).Scan(&role, &email))
+	require.Equal(t, RoleMaintainer, role)
+	require.Equal(t, "dev@example.com", email)
+
+	rec = serve(httptest.NewRequest(http.MethodGet, callback.RequestURI(), nil))
+	require.Equal(t, http.StatusUnauthorized, rec.Code)
+
+	rec = admin(http.MethodPut, "/api/admin/sso/enforcement", `{"sso_only":true}`)
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+
+	t.Setenv("LOCAL_AUTH_TOKEN", "")
+	rec = serve(httptest.NewRequest(http.MethodPost, "/api/auth/magic",
+		bytes.NewBufferString(`{"name":"Dev","email":"dev@example.com","org_slug":"sso-oidc-org"}`)))
+	require.Equal(t, http.StatusForbidden, rec.Code)
+	require.Contains(t, rec.Body.String(), "sso required")
+
+	rec = admin(http.MethodGet, "/api/admin/sso/enforcement", "")
+	require.Equal(t, http.StatusOK, rec.Code)
+	require.JSONEq(t, `{"sso_only":true}`, rec.Body.String())
+}
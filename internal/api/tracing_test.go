@@ -0,0 +1,61 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/samhotchkiss/otter-camp/internal/tracing"
+	"github.com/stretchr/testify/require"
+)
+
+type tracingTestExporter struct {
+	mu    sync.Mutex
+	spans []tracing.SpanData
+}
+
+func (e *tracingTestExporter) Export(_ context.Context, spans []tracing.SpanData) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.spans = append(e.spans, spans...)
+	return nil
+}
+
+func (e *tracingTestExporter) Shutdown(context.Context) error { return nil }
+
+func TestHTTPTracingContinuesInboundTraceAndNamesSpanByRoute(t *testing.T) {
+	exporter := &tracingTestExporter{}
+	tracer := tracing.NewTracer(tracing.Config{}, exporter)
+	previous := tracing.SetGlobal(tracer)
+	defer tracing.SetGlobal(previous)
+
+	var handlerTraceparents []string
+	r := chi.NewRouter()
+	r.Use(HTTPTracing)
+	r.Get("/api/tracing-test/{id}", func(w http.ResponseWriter, r *http.Request) {
+		handlerTraceparents = append(handlerTraceparents, tracing.Traceparent(r.Context()))
+		w.WriteHeader(http.StatusBadGateway)
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/api/tracing-test/42", nil)
+	req.Header.Set("traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	r.ServeHTTP(httptest.NewRecorder(), req)
+
+	upgrade := httptest.NewRequest(http.MethodGet, "/api/tracing-test/43", nil)
+	upgrade.Header.Set("Upgrade", "websocket")
+	r.ServeHTTP(httptest.NewRecorder(), upgrade)
+
+	require.NoError(t, tracer.Shutdown(context.Background()))
+	require.Len(t, exporter.spans, 1)
+	span := exporter.spans[0]
+	require.Equal(t, "GET /api/tracing-test/{id}", span.Name)
+	require.Equal(t, tracing.SpanKindServer, span.Kind)
+	require.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", span.TraceID)
+	require.Equal(t, "00f067aa0ba902b7", span.ParentSpanID)
+	require.Equal(t, http.StatusBadGateway, span.Attributes["http.status_code"])
+	require.Equal(t, "http status 502", span.Error)
+	require.Equal(t, []string{"00-" + span.TraceID + "-" + span.SpanID + "-01", ""}, handlerTraceparents)
+}
@@ -0,0 +1,419 @@
+package api
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/samhotchkiss/otter-camp/internal/middleware"
+	"github.com/samhotchkiss/otter-camp/internal/store"
+	"github.com/samhotchkiss/otter-camp/internal/ws"
+)
+
+const usageDateLayout = "2006-01-02"
+
+// UsageHandler serves token and cost reports, the model price list and
+// agent/project budgets.
+type UsageHandler struct {
+	Store *store.UsageStore
+	DB    *sql.DB
+	Hub   *ws.Hub
+}
+
+type usagePricingListResponse struct {
+	Items []store.ModelPrice `json:"items"`
+	Total int                `json:"total"`
+}
+
+type usageBudgetListResponse struct {
+	Items []store.UsageBudget `json:"items"`
+	Total int                 `json:"total"`
+}
+
+type usagePriceRequest struct {
+	Model               string   `json:"model"`
+	USDPerMillionTokens *float64 `json:"usd_per_million_tokens"`
+}
+
+type usageBudgetRequest struct {
+	Scope       string   `json:"scope"`
+	ScopeID     string   `json:"scope_id"`
+	Period      string   `json:"period"`
+	LimitUSD    *float64 `json:"limit_usd"`
+	SoftPercent *int     `json:"soft_percent"`
+	Hard        *bool    `json:"hard"`
+}
+
+type usageBudgetPatchRequest struct {
+	LimitUSD    *float64 `json:"limit_usd"`
+	SoftPercent *int     `json:"soft_percent"`
+	Hard        *bool    `json:"hard"`
+}
+
+type usageBudgetAlertEnvelope struct {
+	Type string                      `json:"type"`
+	Data store.UsageBudgetTransition `json:"data"`
+}
+
+var usageBudgetActivityActions = map[string]string{
+	store.UsageBudgetSoftCrossed: "usage.budget_warning",
+	store.UsageBudgetHardTripped: "usage.budget_exceeded",
+	store.UsageBudgetCleared:     "usage.budget_cleared",
+}
+
+// Report handles GET /api/usage. The range is month=YYYY-MM, or from and to
+// dates with to exclusive; it defaults to the current month.
+func (h *UsageHandler) Report(w http.ResponseWriter, r *http.Request) {
+	orgID, ok := h.requireOrg(w, r)
+	if !ok {
+		return
+	}
+	query := r.URL.Query()
+	from, to, err := parseUsageRange(query.Get("month"), query.Get("from"), query.Get("to"), time.Now())
+	if err != nil {
+		sendJSON(w, http.StatusBadRequest, errorResponse{Error: err.Error()})
+		return
+	}
+	report, err := h.Store.Report(r.Context(), orgID, store.UsageReportFilter{
+		From:      from,
+		To:        to,
+		AgentID:   query.Get("agent"),
+		ProjectID: query.Get("project"),
+		Model:     query.Get("model"),
+		GroupBy:   query.Get("group_by"),
+	})
+	if err != nil {
+		handleJobsStoreError(w, err)
+		return
+	}
+	sendJSON(w, http.StatusOK, report)
+}
+
+// ListPricing handles GET /api/usage/pricing
+func (h *UsageHandler) ListPricing(w http.ResponseWriter, r *http.Request) {
+	orgID, ok := h.requireOrg(w, r)
+	if !ok {
+		return
+	}
+	pricing, err := h.Store.ListPricing(r.Context(), orgID)
+	if err != nil {
+		handleJobsStoreError(w, err)
+		return
+	}
+	sendJSON(w, http.StatusOK, usagePricingListResponse{Items: pricing, Total: len(pricing)})
+}
+
+// SetPrice handles PUT /api/usage/pricing. This month's rollups are
+// repriced straight away so reports and budgets reflect the new price.
+func (h *UsageHandler) SetPrice(w http.ResponseWriter, r *http.Request) {
+	orgID, ok := h.requireOrg(w, r)
+	if !ok {
+		return
+	}
+	var req usagePriceRequest
+	if !decodeUsageRequest(w, r, &req) {
+		return
+	}
+	if req.USDPerMillionTokens == nil {
+		sendJSON(w, http.StatusBadRequest, errorResponse{Error: "usd_per_million_tokens is required"})
+		return
+	}
+	price, err := h.Store.SetPrice(r.Context(), orgID, req.Model, *req.USDPerMillionTokens)
+	if err != nil {
+		handleJobsStoreError(w, err)
+		return
+	}
+	recordAudit(r, auditEvent{Action: "usage.pricing.set", TargetType: "model_price", TargetID: price.Model, After: price})
+	h.repriceCurrentMonth(r.Context(), orgID)
+	sendJSON(w, http.StatusOK, price)
+}
+
+// DeletePrice handles DELETE /api/usage/pricing?model=...
+func (h *UsageHandler) DeletePrice(w http.ResponseWriter, r *http.Request) {
+	orgID, ok := h.requireOrg(w, r)
+	if !ok {
+		return
+	}
+	model := strings.TrimSpace(r.URL.Query().Get("model"))
+	if model == "" {
+		sendJSON(w, http.StatusBadRequest, errorResponse{Error: "model is required"})
+		return
+	}
+	if err := h.Store.DeletePrice(r.Context(), orgID, model); err != nil {
+		handleJobsStoreError(w, err)
+		return
+	}
+	recordAudit(r, auditEvent{Action: "usage.pricing.delete", TargetType: "model_price", TargetID: model})
+	h.repriceCurrentMonth(r.Context(), orgID)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// ListBudgets handles GET /api/usage/budgets
+func (h *UsageHandler) ListBudgets(w http.ResponseWriter, r *http.Request) {
+	orgID, ok := h.requireOrg(w, r)
+	if !ok {
+		return
+	}
+	budgets, err := h.Store.ListBudgets(r.Context(), orgID)
+	if err != nil {
+		handleJobsStoreError(w, err)
+		return
+	}
+	sendJSON(w, http.StatusOK, usageBudgetListResponse{Items: budgets, Total: len(budgets)})
+}
+
+// SetBudget handles POST /api/usage/budgets. Posting a scope and period that
+// already has a budget changes its limits.
+func (h *UsageHandler) SetBudget(w http.ResponseWriter, r *http.Request) {
+	orgID, ok := h.requireOrg(w, r)
+	if !ok {
+		return
+	}
+	var req usageBudgetRequest
+	if !decodeUsageRequest(w, r, &req) {
+		return
+	}
+	if req.LimitUSD == nil {
+		sendJSON(w, http.StatusBadRequest, errorResponse{Error: "limit_usd is required"})
+		return
+	}
+	budget, err := h.Store.UpsertBudget(r.Context(), orgID, store.UpsertUsageBudgetInput{
+		Scope:       req.Scope,
+		ScopeID:     req.ScopeID,
+		Period:      req.Period,
+		LimitUSD:    *req.LimitUSD,
+		SoftPercent: req.SoftPercent,
+		Hard:        req.Hard,
+	})
+	if err != nil {
+		handleJobsStoreError(w, err)
+		return
+	}
+	recordAudit(r, auditEvent{Action: "usage.budget.set", TargetType: "usage_budget", TargetID: budget.ID, After: budget})
+	h.respondWithEvaluatedBudget(w, r, orgID, budget.ID)
+}
+
+// PatchBudget handles PATCH /api/usage/budgets/{id}
+func (h *UsageHandler) PatchBudget(w http.ResponseWriter, r *http.Request) {
+	orgID, ok := h.requireOrg(w, r)
+	if !ok {
+		return
+	}
+	budgetID := strings.TrimSpace(chi.URLParam(r, "id"))
+	var req usageBudgetPatchRequest
+	if !decodeUsageRequest(w, r, &req) {
+		return
+	}
+	before, err := h.Store.GetBudget(r.Context(), orgID, budgetID)
+	if err != nil {
+		handleJobsStoreError(w, err)
+		return
+	}
+	updated, err := h.Store.UpdateBudget(r.Context(), orgID, budgetID, store.UpdateUsageBudgetInput{
+		LimitUSD:    req.LimitUSD,
+		SoftPercent: req.SoftPercent,
+		Hard:        req.Hard,
+	})
+	if err != nil {
+		handleJobsStoreError(w, err)
+		return
+	}
+	recordAudit(r, auditEvent{Action: "usage.budget.update", TargetType: "usage_budget", TargetID: updated.ID, Before: before, After: updated})
+	h.respondWithEvaluatedBudget(w, r, orgID, updated.ID)
+}
+
+// DeleteBudget handles DELETE /api/usage/budgets/{id}. Jobs the budget
+// paused are resumed.
+func (h *UsageHandler) DeleteBudget(w http.ResponseWriter, r *http.Request) {
+	orgID, ok := h.requireOrg(w, r)
+	if !ok {
+		return
+	}
+	budgetID := strings.TrimSpace(chi.URLParam(r, "id"))
+	before, err := h.Store.GetBudget(r.Context(), orgID, budgetID)
+	if err != nil {
+		handleJobsStoreError(w, err)
+		return
+	}
+	resumed, err := h.Store.DeleteBudget(r.Context(), orgID, budgetID)
+	if err != nil {
+		handleJobsStoreError(w, err)
+		return
+	}
+	recordAudit(r, auditEvent{Action: "usage.budget.delete", TargetType: "usage_budget", TargetID: budgetID, Before: before})
+	sendJSON(w, http.StatusOK, map[string]any{"deleted": true, "resumed_jobs": resumed})
+}
+
+func (h *UsageHandler) requireOrg(w http.ResponseWriter, r *http.Request) (string, bool) {
+	if h.Store == nil {
+		sendJSON(w, http.StatusServiceUnavailable, errorResponse{Error: "database not available"})
+		return "", false
+	}
+	orgID := middleware.WorkspaceFromContext(r.Context())
+	if orgID == "" {
+		sendJSON(w, http.StatusUnauthorized, errorResponse{Error: "authentication required"})
+		return "", false
+	}
+	return orgID, true
+}
+
+// respondWithEvaluatedBudget applies a changed limit at once, so raising a
+// tripped budget resumes its jobs without waiting for the next ingest.
+func (h *UsageHandler) respondWithEvaluatedBudget(w http.ResponseWriter, r *http.Request, orgID, budgetID string) {
+	if err := evaluateUsageBudgets(r.Context(), h.Store, h.DB, h.Hub, orgID); err != nil {
+		log.Printf("[usage] failed to evaluate budgets for org %s: %v", orgID, err)
+	}
+	budget, err := h.Store.GetBudget(r.Context(), orgID, budgetID)
+	if err != nil {
+		handleJobsStoreError(w, err)
+		return
+	}
+	sendJSON(w, http.StatusOK, budget)
+}
+
+func (h *UsageHandler) repriceCurrentMonth(ctx context.Context, orgID string) {
+	now := time.Now().UTC()
+	monthStart := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC)
+	if err := refreshUsage(ctx, h.Store, h.DB, h.Hub, orgID, monthStart, now); err != nil {
+		log.Printf("[usage] failed to reprice usage for org %s: %v", orgID, err)
+	}
+}
+
+func decodeUsageRequest(w http.ResponseWriter, r *http.Request, dst any) bool {
+	decoder := json.NewDecoder(r.Body)
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(dst); err != nil {
+		sendJSON(w, http.StatusBadRequest, errorResponse{Error: "invalid JSON"})
+		return false
+	}
+	return true
+}
+
+// parseUsageRange turns month or from/to into a [from, to) range of UTC
+// days. With neither it is the month containing now.
+func parseUsageRange(month, from, to string, now time.Time) (time.Time, time.Time, error) {
+	month, from, to = strings.TrimSpace(month), strings.TrimSpace(from), strings.TrimSpace(to)
+	if month != "" && (from != "" || to != "") {
+		return time.Time{}, time.Time{}, errors.New("use month or from/to, not both")
+	}
+	if month == "" && from == "" && to == "" {
+		month = now.UTC().Format("2006-01")
+	}
+	if month != "" {
+		start, err := time.Parse("2006-01", month)
+		if err != nil {
+			return time.Time{}, time.Time{}, errors.New("month must be YYYY-MM")
+		}
+		return start, start.AddDate(0, 1, 0), nil
+	}
+	if from == "" {
+		return time.Time{}, time.Time{}, errors.New("from is required with to")
+	}
+	start, err := time.Parse(usageDateLayout, from)
+	if err != nil {
+		return time.Time{}, time.Time{}, errors.New("from must be YYYY-MM-DD")
+	}
+	end := now.UTC().AddDate(0, 0, 1)
+	if to != "" {
+		if end, err = time.Parse(usageDateLayout, to); err != nil {
+			return time.Time{}, time.Time{}, errors.New("to must be YYYY-MM-DD")
+		}
+	}
+	end = time.Date(end.Year(), end.Month(), end.Day(), 0, 0, 0, 0, time.UTC)
+	if !end.After(start) {
+		return time.Time{}, time.Time{}, errors.New("to must be after from")
+	}
+	return start, end, nil
+}
+
+// refreshUsage rebuilds the rollups for the days from through to and then
+// evaluates the org's budgets against them.
+func refreshUsage(ctx context.Context, usage *store.UsageStore, db *sql.DB, hub *ws.Hub, orgID string, from, to time.Time) error {
+	if usage == nil {
+		return nil
+	}
+	if err := usage.RefreshRollups(ctx, orgID, from, to); err != nil {
+		return err
+	}
+	return evaluateUsageBudgets(ctx, usage, db, hub, orgID)
+}
+
+func evaluateUsageBudgets(ctx context.Context, usage *store.UsageStore, db *sql.DB, hub *ws.Hub, orgID string) error {
+	transitions, err := usage.EvaluateBudgets(ctx, orgID, time.Now())
+	if err != nil {
+		return err
+	}
+	notifyUsageBudgetTransitions(ctx, db, hub, orgID, transitions)
+	return nil
+}
+
+// notifyUsageBudgetTransitions records each crossed threshold in the
+// activity feed and pushes it to connected clients.
+func notifyUsageBudgetTransitions(ctx context.Context, db *sql.DB, hub *ws.Hub, orgID string, transitions []store.UsageBudgetTransition) {
+	for _, transition := range transitions {
+		budget := transition.Budget
+		log.Printf(
+			"[usage] %s budget %s for %s %s: $%.2f of $%.2f per %s (paused %d, resumed %d jobs)",
+			transition.Kind, budget.ID, budget.Scope, budget.ScopeID,
+			budget.SpentUSD, budget.LimitUSD, budget.Period,
+			transition.PausedJobs, transition.ResumedJobs,
+		)
+		if db != nil {
+			metadata, err := json.Marshal(map[string]any{
+				"budget_id":    budget.ID,
+				"scope":        budget.Scope,
+				"scope_id":     budget.ScopeID,
+				"period":       budget.Period,
+				"period_start": budget.PeriodStart,
+				"limit_usd":    budget.LimitUSD,
+				"spent_usd":    budget.SpentUSD,
+				"hard":         budget.Hard,
+				"paused_jobs":  transition.PausedJobs,
+				"resumed_jobs": transition.ResumedJobs,
+			})
+			if err == nil {
+				_, err = store.NewActivityStore(db).CreateWithWorkspaceID(ctx, orgID, store.CreateActivityInput{
+					Action:   usageBudgetActivityActions[transition.Kind],
+					Metadata: metadata,
+				})
+			}
+			if err != nil {
+				log.Printf("[usage] failed to record budget activity for org %s: %v", orgID, err)
+			}
+		}
+		if hub != nil {
+			payload, err := json.Marshal(usageBudgetAlertEnvelope{Type: "UsageBudgetAlert", Data: transition})
+			if err == nil {
+				hub.Broadcast(orgID, payload)
+			}
+		}
+	}
+}
+
+// usageBudgetDispatchWarning explains why a message to agentID (or into
+// projectID) will not be dispatched, or returns "" when no tripped budget
+// stands in the way. Budget lookups fail open.
+func usageBudgetDispatchWarning(ctx context.Context, db *sql.DB, orgID, agentID, projectID string) string {
+	if db == nil || strings.TrimSpace(orgID) == "" {
+		return ""
+	}
+	budget, err := store.NewUsageStore(db).BlockingBudget(ctx, orgID, agentID, projectID, time.Now())
+	if err != nil {
+		log.Printf("[usage] failed to check budgets for org %s: %v", orgID, err)
+		return ""
+	}
+	if budget == nil {
+		return ""
+	}
+	return fmt.Sprintf(
+		"%s usage budget reached ($%.2f of $%.2f this %s); message was saved but not dispatched",
+		budget.Scope, budget.SpentUSD, budget.LimitUSD, budget.Period,
+	)
+}
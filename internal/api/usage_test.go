@@ -0,0 +1,100 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/samhotchkiss/otter-camp/internal/middleware"
+	"github.com/samhotchkiss/otter-camp/internal/store"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseUsageRange(t *testing.T) {
+	now := time.Date(2026, 10, 18, 15, 0, 0, 0, time.UTC)
+	day := func(y int, m time.Month, d int) time.Time { return time.Date(y, m, d, 0, 0, 0, 0, time.UTC) }
+
+	from, to, err := parseUsageRange("", "", "", now)
+	require.NoError(t, err)
+	require.Equal(t, day(2026, 10, 1), from)
+	require.Equal(t, day(2026, 11, 1), to)
+
+	from, to, err = parseUsageRange("2026-12", "", "", now)
+	require.NoError(t, err)
+	require.Equal(t, day(2026, 12, 1), from)
+	require.Equal(t, day(2027, 1, 1), to)
+
+	from, to, err = parseUsageRange("", "2026-10-10", "", now)
+	require.NoError(t, err)
+	require.Equal(t, day(2026, 10, 10), from)
+	require.Equal(t, day(2026, 10, 19), to, "an open range runs through today")
+
+	from, to, err = parseUsageRange("", "2026-10-10", "2026-10-12", now)
+	require.NoError(t, err)
+	require.Equal(t, day(2026, 10, 10), from)
+	require.Equal(t, day(2026, 10, 12), to)
+
+	for _, tc := range [][3]string{
+		{"2026-10", "2026-10-01", ""},
+		{"october", "", ""},
+		{"", "", "2026-10-12"},
+		{"", "10/10/2026", ""},
+		{"", "2026-10-12", "2026-10-12"},
+	} {
+		_, _, err := parseUsageRange(tc[0], tc[1], tc[2], now)
+		require.Error(t, err, "%v", tc)
+	}
+}
+
+func TestUsageHandlerRequiresDatabaseAndWorkspace(t *testing.T) {
+	rec := httptest.NewRecorder()
+	(&UsageHandler{}).Report(rec, httptest.NewRequest(http.MethodGet, "/api/usage", nil))
+	require.Equal(t, http.StatusServiceUnavailable, rec.Code)
+
+	rec = httptest.NewRecorder()
+	(&UsageHandler{Store: store.NewUsageStore(nil)}).ListBudgets(rec, httptest.NewRequest(http.MethodGet, "/api/usage/budgets", nil))
+	require.Equal(t, http.StatusUnauthorized, rec.Code)
+}
+
+func TestUsageHandlerRejectsBadReportRange(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "/api/usage?month=2026-13", nil)
+	req = req.WithContext(context.WithValue(req.Context(), middleware.WorkspaceIDKey, "00000000-0000-0000-0000-000000000001"))
+	rec := httptest.NewRecorder()
+	(&UsageHandler{Store: store.NewUsageStore(nil)}).Report(rec, req)
+	require.Equal(t, http.StatusBadRequest, rec.Code)
+	require.Contains(t, rec.Body.String(), "month must be YYYY-MM")
+}
+
+func TestUsageBudgetDispatchWarningFailsOpenWithoutDatabase(t *testing.T) {
+	require.Empty(t, usageBudgetDispatchWarning(context.Background(), nil, "org-1", "writer", ""))
+}
+
+func TestUsageBudgetDispatchWarningMatchesAgentByID(t *testing.T) {
+	db := setupMessageTestDB(t)
+	orgID := insertMessageTestOrganization(t, db, "usage-budget-dispatch-by-id")
+
+	var agentID string
+	require.NoError(t, db.QueryRow(
+		`INSERT INTO agents (org_id, slug, display_name, status)
+		 VALUES ($1, 'writer', 'Writer', 'active')
+		 RETURNING id`,
+		orgID,
+	).Scan(&agentID))
+
+	ctx := context.Background()
+	budget, err := store.NewUsageStore(db).UpsertBudget(ctx, orgID, store.UpsertUsageBudgetInput{
+		Scope:    store.UsageBudgetScopeAgent,
+		ScopeID:  "writer",
+		LimitUSD: 1,
+	})
+	require.NoError(t, err)
+	_, err = db.Exec(`UPDATE usage_budgets SET period_start = date_trunc('month', NOW() AT TIME ZONE 'UTC')::date, hard_tripped_at = NOW() WHERE id = $1`, budget.ID)
+	require.NoError(t, err)
+
+	// DM dispatch resolves the target to the agent's UUID, not its slug.
+	require.Contains(t, usageBudgetDispatchWarning(ctx, db, orgID, agentID, ""), "agent usage budget reached")
+	require.Contains(t, usageBudgetDispatchWarning(ctx, db, orgID, "writer", ""), "agent usage budget reached")
+	require.Empty(t, usageBudgetDispatchWarning(ctx, db, orgID, "researcher", ""))
+}
@@ -0,0 +1,208 @@
+package backup
+
+import (
+	"archive/tar"
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// maxRowBytes bounds a single JSONL line when reading an archive.
+const maxRowBytes = 64 << 20
+
+// Row is one decoded table row. Numbers are kept as json.Number so bigint
+// columns survive the round trip.
+type Row map[string]any
+
+// Writer streams an archive. The manifest should be written first so readers
+// can check the version before unpacking anything else.
+type Writer struct {
+	gz      *gzip.Writer
+	tw      *tar.Writer
+	modTime time.Time
+}
+
+func NewWriter(w io.Writer) *Writer {
+	gz := gzip.NewWriter(w)
+	return &Writer{gz: gz, tw: tar.NewWriter(gz), modTime: time.Now().UTC()}
+}
+
+func (w *Writer) WriteManifest(m Manifest) error {
+	data, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return fmt.Errorf("encode manifest: %w", err)
+	}
+	return w.WriteFile(manifestFile, data)
+}
+
+func (w *Writer) WriteFile(name string, data []byte) error {
+	return w.WriteStream(name, int64(len(data)), bytes.NewReader(data))
+}
+
+func (w *Writer) WriteStream(name string, size int64, r io.Reader) error {
+	header := &tar.Header{
+		Name:    name,
+		Mode:    0o644,
+		Size:    size,
+		ModTime: w.modTime,
+	}
+	if err := w.tw.WriteHeader(header); err != nil {
+		return fmt.Errorf("write %s header: %w", name, err)
+	}
+	if _, err := io.CopyN(w.tw, r, size); err != nil {
+		return fmt.Errorf("write %s: %w", name, err)
+	}
+	return nil
+}
+
+func (w *Writer) Close() error {
+	if err := w.tw.Close(); err != nil {
+		return err
+	}
+	return w.gz.Close()
+}
+
+// Archive is an unpacked backup. Table rows are held in memory; git bundles
+// are extracted to a temporary directory that Close removes.
+type Archive struct {
+	Manifest Manifest
+	Tables   map[string][]Row
+	Repos    map[string]string
+	Unknown  []string
+
+	tempDir string
+}
+
+// ReadArchive unpacks an archive written by Writer. Malformed tar entries,
+// a missing manifest, an unsupported version or undecodable rows are errors;
+// content problems are left to Validate.
+func ReadArchive(r io.Reader) (*Archive, error) {
+	gz, err := gzip.NewReader(r)
+	if err != nil {
+		return nil, fmt.Errorf("open archive: %w", err)
+	}
+	defer gz.Close()
+
+	archive := &Archive{
+		Tables: map[string][]Row{},
+		Repos:  map[string]string{},
+	}
+	sawManifest := false
+	tr := tar.NewReader(gz)
+	for {
+		header, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			archive.Close()
+			return nil, fmt.Errorf("read archive: %w", err)
+		}
+		if header.Typeflag != tar.TypeReg {
+			continue
+		}
+		name := path.Clean(header.Name)
+
+		switch {
+		case name == manifestFile:
+			if err := json.NewDecoder(tr).Decode(&archive.Manifest); err != nil {
+				archive.Close()
+				return nil, fmt.Errorf("decode manifest: %w", err)
+			}
+			if err := checkManifestVersion(archive.Manifest); err != nil {
+				archive.Close()
+				return nil, err
+			}
+			sawManifest = true
+		case strings.HasPrefix(name, tablesDir) && strings.HasSuffix(name, ".jsonl"):
+			table := strings.TrimSuffix(strings.TrimPrefix(name, tablesDir), ".jsonl")
+			rows, err := readRows(name, tr)
+			if err != nil {
+				archive.Close()
+				return nil, err
+			}
+			archive.Tables[table] = rows
+		case strings.HasPrefix(name, reposDir) && strings.HasSuffix(name, ".bundle"):
+			if err := archive.extractRepo(name, tr); err != nil {
+				archive.Close()
+				return nil, err
+			}
+		default:
+			archive.Unknown = append(archive.Unknown, name)
+		}
+	}
+
+	if !sawManifest {
+		archive.Close()
+		return nil, errors.New("archive has no manifest.json")
+	}
+	return archive, nil
+}
+
+func readRows(name string, r io.Reader) ([]Row, error) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxRowBytes)
+	rows := []Row{}
+	line := 0
+	for scanner.Scan() {
+		line++
+		raw := bytes.TrimSpace(scanner.Bytes())
+		if len(raw) == 0 {
+			continue
+		}
+		decoder := json.NewDecoder(bytes.NewReader(raw))
+		decoder.UseNumber()
+		var row Row
+		if err := decoder.Decode(&row); err != nil || row == nil {
+			return nil, fmt.Errorf("%s line %d: expected a JSON object", name, line)
+		}
+		rows = append(rows, row)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("read %s: %w", name, err)
+	}
+	return rows, nil
+}
+
+func (a *Archive) extractRepo(name string, r io.Reader) error {
+	if a.tempDir == "" {
+		dir, err := os.MkdirTemp("", "otter-backup-*")
+		if err != nil {
+			return fmt.Errorf("create temp dir: %w", err)
+		}
+		a.tempDir = dir
+	}
+	dest := filepath.Join(a.tempDir, filepath.Base(name))
+	file, err := os.Create(dest)
+	if err != nil {
+		return fmt.Errorf("extract %s: %w", name, err)
+	}
+	if _, err := io.Copy(file, r); err != nil {
+		_ = file.Close()
+		return fmt.Errorf("extract %s: %w", name, err)
+	}
+	if err := file.Close(); err != nil {
+		return fmt.Errorf("extract %s: %w", name, err)
+	}
+	a.Repos[name] = dest
+	return nil
+}
+
+// Close removes any extracted git bundles.
+func (a *Archive) Close() error {
+	if a == nil || a.tempDir == "" {
+		return nil
+	}
+	err := os.RemoveAll(a.tempDir)
+	a.tempDir = ""
+	return err
+}
@@ -0,0 +1,107 @@
+package backup
+
+import (
+	"bytes"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+const testOrgID = "11111111-1111-1111-1111-111111111111"
+
+func writeTestArchive(t *testing.T, manifest Manifest, files map[string]string) *Archive {
+	t.Helper()
+	var buf bytes.Buffer
+	writer := NewWriter(&buf)
+	require.NoError(t, writer.WriteManifest(manifest))
+	for name, content := range files {
+		require.NoError(t, writer.WriteFile(name, []byte(content)))
+	}
+	require.NoError(t, writer.Close())
+
+	archive, err := ReadArchive(&buf)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = archive.Close() })
+	return archive
+}
+
+func testManifest(tables ...TableManifest) Manifest {
+	return Manifest{
+		Format:    FormatName,
+		Version:   FormatVersion,
+		OrgID:     testOrgID,
+		CreatedAt: time.Date(2026, 10, 18, 12, 0, 0, 0, time.UTC),
+		Tables:    tables,
+		Repos:     []RepoManifest{},
+	}
+}
+
+func TestArchiveRoundTripKeepsRowsAndBundles(t *testing.T) {
+	projects := TableManifest{
+		Name:       "projects",
+		File:       tableFile("projects"),
+		Rows:       2,
+		OrgScoped:  true,
+		PrimaryKey: []string{"id"},
+	}
+	manifest := testManifest(projects)
+	manifest.Repos = []RepoManifest{{ProjectID: "p1", File: repoFile("p1"), Bytes: 16}}
+
+	archive := writeTestArchive(t, manifest, map[string]string{
+		tableFile("projects"): `{"id":"p1","org_id":"` + testOrgID + `","name":"Alpha","seq":9007199254740993}` + "\n" +
+			`{"id":"p2","org_id":"` + testOrgID + `","name":"Beta","seq":1}` + "\n",
+		repoFile("p1"):     "# v2 git bundle\n",
+		"notes/readme.txt": "hello",
+	})
+
+	require.Equal(t, testOrgID, archive.Manifest.OrgID)
+	require.Len(t, archive.Tables["projects"], 2)
+	require.Equal(t, "Alpha", archive.Tables["projects"][0]["name"])
+	require.Equal(t, "9007199254740993", valueString(archive.Tables["projects"][0]["seq"]))
+	require.Equal(t, []string{"notes/readme.txt"}, archive.Unknown)
+
+	bundlePath := archive.Repos[repoFile("p1")]
+	require.NotEmpty(t, bundlePath)
+	content, err := os.ReadFile(bundlePath)
+	require.NoError(t, err)
+	require.Equal(t, "# v2 git bundle\n", string(content))
+
+	require.NoError(t, archive.Close())
+	_, err = os.Stat(bundlePath)
+	require.True(t, os.IsNotExist(err))
+}
+
+func TestReadArchiveRejectsUnsupportedVersion(t *testing.T) {
+	manifest := testManifest()
+	manifest.Version = FormatVersion + 1
+
+	var buf bytes.Buffer
+	writer := NewWriter(&buf)
+	require.NoError(t, writer.WriteManifest(manifest))
+	require.NoError(t, writer.Close())
+
+	_, err := ReadArchive(&buf)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "unsupported archive version")
+}
+
+func TestReadArchiveRequiresManifestAndObjectRows(t *testing.T) {
+	var buf bytes.Buffer
+	writer := NewWriter(&buf)
+	require.NoError(t, writer.WriteFile(tableFile("projects"), []byte(`{"id":"p1"}`+"\n")))
+	require.NoError(t, writer.Close())
+	_, err := ReadArchive(&buf)
+	require.EqualError(t, err, "archive has no manifest.json")
+
+	buf.Reset()
+	writer = NewWriter(&buf)
+	require.NoError(t, writer.WriteManifest(testManifest()))
+	require.NoError(t, writer.WriteFile(tableFile("projects"), []byte(`{"id":"p1"}`+"\n"+`[1,2]`+"\n")))
+	require.NoError(t, writer.Close())
+	_, err = ReadArchive(&buf)
+	require.Error(t, err)
+	require.True(t, strings.Contains(err.Error(), "tables/projects.jsonl line 2"), err.Error())
+}
@@ -0,0 +1,250 @@
+package backup
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+)
+
+var uuidPattern = regexp.MustCompile(`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`)
+
+// ExportOptions selects what goes into an archive.
+//
+// Since makes the export incremental: only rows created or updated at or
+// after Since are included (tables without timestamps are always included in
+// full). AsOf drops rows created after the cutoff. Rows are exported in their
+// current state; AsOf is not a row-version history.
+type ExportOptions struct {
+	OrgID     string
+	Since     *time.Time
+	AsOf      *time.Time
+	SkipRepos bool
+}
+
+// Export writes an archive of the org to w. All tables are read from a single
+// repeatable-read snapshot so the archive is internally consistent.
+func Export(ctx context.Context, db *sql.DB, w io.Writer, opts ExportOptions) (*Manifest, error) {
+	orgID := strings.TrimSpace(opts.OrgID)
+	if !uuidPattern.MatchString(orgID) {
+		return nil, errors.New("invalid org_id")
+	}
+	if opts.Since != nil && opts.AsOf != nil && opts.AsOf.Before(*opts.Since) {
+		return nil, errors.New("as_of must not be before since")
+	}
+
+	manifest := &Manifest{
+		Format:    FormatName,
+		Version:   FormatVersion,
+		OrgID:     orgID,
+		CreatedAt: time.Now().UTC(),
+		Since:     utcPointer(opts.Since),
+		AsOf:      utcPointer(opts.AsOf),
+		Repos:     []RepoManifest{},
+	}
+
+	data, repoPaths, err := exportTables(ctx, db, manifest)
+	if err != nil {
+		return nil, err
+	}
+
+	tempDir := ""
+	if !opts.SkipRepos && len(repoPaths) > 0 {
+		tempDir, err = os.MkdirTemp("", "otter-backup-*")
+		if err != nil {
+			return nil, fmt.Errorf("create temp dir: %w", err)
+		}
+		defer os.RemoveAll(tempDir)
+		for _, projectID := range sortedKeys(repoPaths) {
+			bundlePath := filepath.Join(tempDir, projectID+".bundle")
+			ok, err := createBundle(ctx, repoPaths[projectID], bundlePath)
+			if err != nil {
+				manifest.Warnings = append(manifest.Warnings, fmt.Sprintf("project %s: repo not bundled: %v", projectID, err))
+				continue
+			}
+			if !ok {
+				continue
+			}
+			info, err := os.Stat(bundlePath)
+			if err != nil {
+				return nil, fmt.Errorf("stat bundle: %w", err)
+			}
+			manifest.Repos = append(manifest.Repos, RepoManifest{
+				ProjectID: projectID,
+				File:      repoFile(projectID),
+				Bytes:     info.Size(),
+			})
+		}
+	}
+
+	writer := NewWriter(w)
+	if err := writer.WriteManifest(*manifest); err != nil {
+		return nil, err
+	}
+	for _, table := range manifest.Tables {
+		if err := writer.WriteFile(table.File, data[table.Name]); err != nil {
+			return nil, err
+		}
+	}
+	for _, repo := range manifest.Repos {
+		if err := writeBundle(writer, repo, filepath.Join(tempDir, repo.ProjectID+".bundle")); err != nil {
+			return nil, err
+		}
+	}
+	if err := writer.Close(); err != nil {
+		return nil, fmt.Errorf("finish archive: %w", err)
+	}
+	return manifest, nil
+}
+
+func exportTables(ctx context.Context, db *sql.DB, manifest *Manifest) (map[string][]byte, map[string]string, error) {
+	tx, err := db.BeginTx(ctx, &sql.TxOptions{Isolation: sql.LevelRepeatableRead, ReadOnly: true})
+	if err != nil {
+		return nil, nil, fmt.Errorf("begin export: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	// The org id was validated as a UUID, so interpolation is safe.
+	if _, err := tx.ExecContext(ctx, fmt.Sprintf("SET LOCAL app.org_id = '%s'", manifest.OrgID)); err != nil {
+		return nil, nil, fmt.Errorf("failed to set workspace: %w", err)
+	}
+
+	tables, err := loadSchema(ctx, tx)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	data := make(map[string][]byte, len(tables))
+	repoPaths := map[string]string{}
+	for _, table := range tables {
+		query, args := exportQuery(table, manifest.OrgID, manifest.Since, manifest.AsOf)
+		rows, err := tx.QueryContext(ctx, query, args...)
+		if err != nil {
+			return nil, nil, fmt.Errorf("export %s: %w", table.Name, err)
+		}
+		var buf bytes.Buffer
+		for rows.Next() {
+			var line []byte
+			if err := rows.Scan(&line); err != nil {
+				rows.Close()
+				return nil, nil, fmt.Errorf("export %s: %w", table.Name, err)
+			}
+			buf.Write(line)
+			buf.WriteByte('\n')
+			table.Rows++
+		}
+		if err := rows.Close(); err != nil {
+			return nil, nil, fmt.Errorf("export %s: %w", table.Name, err)
+		}
+		data[table.Name] = buf.Bytes()
+		manifest.Tables = append(manifest.Tables, table)
+
+		if table.Name == "projects" {
+			rows, err := readRows(table.File, bytes.NewReader(buf.Bytes()))
+			if err != nil {
+				return nil, nil, err
+			}
+			for _, row := range rows {
+				id, _ := row["id"].(string)
+				repoPath, _ := row["local_repo_path"].(string)
+				if id != "" && strings.TrimSpace(repoPath) != "" {
+					repoPaths[id] = strings.TrimSpace(repoPath)
+				}
+			}
+		}
+	}
+	return data, repoPaths, nil
+}
+
+func exportQuery(table TableManifest, orgID string, since, asOf *time.Time) (string, []any) {
+	scope := "t.org_id = $1"
+	if !table.OrgScoped {
+		scope = parentScopedTables[table.Name].clause("", "$1")
+	}
+	clauses := []string{"(" + scope + ")"}
+	args := []any{orgID}
+	if since != nil {
+		if column := snapshotColumn(table, true); column != "" {
+			args = append(args, *since)
+			clauses = append(clauses, fmt.Sprintf("t.%s >= $%d", quoteIdent(column), len(args)))
+		}
+	}
+	if asOf != nil {
+		if column := snapshotColumn(table, false); column != "" {
+			args = append(args, *asOf)
+			clauses = append(clauses, fmt.Sprintf("t.%s <= $%d", quoteIdent(column), len(args)))
+		}
+	}
+	query := fmt.Sprintf(
+		"SELECT row_to_json(t)::text FROM %s t WHERE %s",
+		quoteIdent(table.Name),
+		strings.Join(clauses, " AND "),
+	)
+	return query, args
+}
+
+// createBundle bundles every ref in repoPath. It returns false without an
+// error when the repo is missing or has no refs yet.
+func createBundle(ctx context.Context, repoPath, bundlePath string) (bool, error) {
+	if info, err := os.Stat(repoPath); err != nil || !info.IsDir() {
+		return false, nil
+	}
+	refs, err := exec.CommandContext(ctx, "git", "-C", repoPath, "for-each-ref", "--count=1").Output()
+	if err != nil {
+		return false, fmt.Errorf("list refs: %w", err)
+	}
+	if len(bytes.TrimSpace(refs)) == 0 {
+		return false, nil
+	}
+	output, err := exec.CommandContext(ctx, "git", "-C", repoPath, "bundle", "create", bundlePath, "--all").CombinedOutput()
+	if err != nil {
+		return false, fmt.Errorf("git bundle create: %v: %s", err, strings.TrimSpace(string(output)))
+	}
+	return true, nil
+}
+
+func writeBundle(writer *Writer, repo RepoManifest, bundlePath string) error {
+	file, err := os.Open(bundlePath)
+	if err != nil {
+		return fmt.Errorf("open bundle: %w", err)
+	}
+	defer file.Close()
+	return writer.WriteStream(repo.File, repo.Bytes, file)
+}
+
+func utcPointer(value *time.Time) *time.Time {
+	if value == nil {
+		return nil
+	}
+	utc := value.UTC()
+	return &utc
+}
+
+func sortedKeys(values map[string]string) []string {
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func newUUID() string {
+	var b [16]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		panic(fmt.Sprintf("backup: read random: %v", err))
+	}
+	b[6] = (b[6] & 0x0f) | 0x40
+	b[8] = (b[8] & 0x3f) | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:16])
+}
@@ -0,0 +1,200 @@
+package backup
+
+import (
+	"fmt"
+	"sort"
+)
+
+// deferredRef is a reference that could not be written with its row because
+// the row it points at is restored later (a cycle or a self reference). The
+// column is inserted as NULL and patched once every table is in.
+type deferredRef struct {
+	Table    string
+	RowID    string
+	Column   string
+	Target   string
+	OldValue string
+}
+
+// remapper rewrites archived rows for the target org. With remap on, every
+// id-keyed row gets a fresh id and references follow the new ids; with remap
+// off ids are kept and rows are upserted in place. Either way a reference is
+// only kept when it points at a row restored into, or already owned by, the
+// target org; exists must answer for the target org alone.
+type remapper struct {
+	orgID    string
+	remap    bool
+	order    map[string]int
+	archived map[string]map[string]bool
+	ids      map[string]map[string]string
+	restored map[string]map[string]bool
+	exists   func(table, id string) (bool, error)
+	checked  map[string]bool
+	cleared  map[string]int
+}
+
+func newRemapper(archive *Archive, orgID string, remap bool, exists func(table, id string) (bool, error)) *remapper {
+	m := &remapper{
+		orgID:    orgID,
+		remap:    remap,
+		order:    map[string]int{},
+		archived: map[string]map[string]bool{},
+		ids:      map[string]map[string]string{},
+		restored: map[string]map[string]bool{},
+		exists:   exists,
+		checked:  map[string]bool{},
+		cleared:  map[string]int{},
+	}
+	for i, table := range archive.Manifest.Tables {
+		m.order[table.Name] = i
+		if !table.singleIDKey() {
+			continue
+		}
+		ids := map[string]bool{}
+		for _, row := range archive.Tables[table.Name] {
+			if value, ok := row["id"]; ok && value != nil {
+				ids[valueString(value)] = true
+			}
+		}
+		m.archived[table.Name] = ids
+	}
+	return m
+}
+
+// rewrite returns the row to insert and the references to patch afterwards.
+// A non-empty skip reason means the row cannot be restored. When remapping a
+// table with non-UUID ids, the id is dropped so the database assigns one and
+// the caller reports it back through record.
+func (m *remapper) rewrite(table TableManifest, row Row) (Row, []deferredRef, string, error) {
+	out := make(Row, len(row))
+	for key, value := range row {
+		out[key] = value
+	}
+	if table.OrgScoped {
+		out["org_id"] = m.orgID
+	}
+	if m.remap && table.Name == "projects" {
+		// The repo is recreated under the target org's path on restore.
+		if _, ok := out["local_repo_path"]; ok {
+			out["local_repo_path"] = nil
+		}
+	}
+	if m.remap && table.singleIDKey() {
+		old := valueString(row["id"])
+		if uuidPattern.MatchString(old) {
+			out["id"] = newUUID()
+		} else {
+			delete(out, "id")
+		}
+	}
+
+	columns := make([]string, 0, len(table.References))
+	for column := range table.References {
+		columns = append(columns, column)
+	}
+	sort.Strings(columns)
+
+	var deferred []deferredRef
+	for _, column := range columns {
+		target := table.References[column]
+		value, ok := row[column]
+		if !ok || value == nil {
+			continue
+		}
+		old := valueString(value)
+		definition, _ := table.column(column)
+
+		if target == "organizations" {
+			out[column] = m.orgID
+			continue
+		}
+
+		if archivedIDs, archived := m.archived[target]; archived {
+			if mapped, ok := m.ids[target][old]; ok {
+				out[column] = mapped
+				continue
+			}
+			if archivedIDs[old] && m.order[target] >= m.order[table.Name] {
+				if !definition.Nullable {
+					return nil, nil, fmt.Sprintf("%s references %s %s, which is restored later", column, target, old), nil
+				}
+				out[column] = nil
+				deferred = append(deferred, deferredRef{Table: table.Name, Column: column, Target: target, OldValue: old})
+				continue
+			}
+			if m.remap {
+				if !definition.Nullable {
+					return nil, nil, fmt.Sprintf("%s references missing %s %s", column, target, old), nil
+				}
+				out[column] = nil
+				m.cleared[table.Name+"."+column]++
+				continue
+			}
+			// Incremental restores point at rows that already exist, which
+			// must be the target org's.
+		}
+
+		found, err := m.targetExists(target, old)
+		if err != nil {
+			return nil, nil, "", err
+		}
+		if found {
+			continue
+		}
+		if !definition.Nullable {
+			return nil, nil, fmt.Sprintf("%s references %s %s, which does not exist in the target org", column, target, old), nil
+		}
+		out[column] = nil
+		m.cleared[table.Name+"."+column]++
+	}
+
+	if scope, ok := parentScopedTables[table.Name]; ok {
+		parent := valueString(out[scope.Column])
+		if out[scope.Column] == nil || parent == "" {
+			return nil, nil, fmt.Sprintf("%s is empty", scope.Column), nil
+		}
+		if !m.restored[scope.Parent][parent] {
+			found, err := m.targetExists(scope.Parent, parent)
+			if err != nil {
+				return nil, nil, "", err
+			}
+			if !found {
+				return nil, nil, fmt.Sprintf("%s %s does not exist in the target org", scope.Parent, parent), nil
+			}
+		}
+	}
+	return out, deferred, "", nil
+}
+
+// record notes that the archived row old was restored as new.
+func (m *remapper) record(table, old, new string) {
+	if m.ids[table] == nil {
+		m.ids[table] = map[string]string{}
+	}
+	m.ids[table][old] = new
+	if m.restored[table] == nil {
+		m.restored[table] = map[string]bool{}
+	}
+	m.restored[table][new] = true
+}
+
+func (m *remapper) mapped(table, old string) (string, bool) {
+	value, ok := m.ids[table][old]
+	return value, ok
+}
+
+func (m *remapper) targetExists(table, id string) (bool, error) {
+	key := table + "/" + id
+	if found, ok := m.checked[key]; ok {
+		return found, nil
+	}
+	if m.exists == nil {
+		return false, nil
+	}
+	found, err := m.exists(table, id)
+	if err != nil {
+		return false, err
+	}
+	m.checked[key] = found
+	return found, nil
+}
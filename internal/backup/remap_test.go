@@ -0,0 +1,174 @@
+package backup
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	targetOrgID = "33333333-3333-3333-3333-333333333333"
+	projectA    = "aaaaaaaa-aaaa-aaaa-aaaa-aaaaaaaaaaaa"
+	issueA      = "bbbbbbbb-bbbb-bbbb-bbbb-bbbbbbbbbbbb"
+	issueB      = "cccccccc-cccc-cccc-cccc-cccccccccccc"
+	userA       = "dddddddd-dddd-dddd-dddd-dddddddddddd"
+)
+
+func remapTestArchive() (*Archive, TableManifest, TableManifest) {
+	projects := TableManifest{
+		Name:       "projects",
+		OrgScoped:  true,
+		PrimaryKey: []string{"id"},
+		Columns:    []Column{{Name: "id"}, {Name: "org_id"}, {Name: "local_repo_path", Nullable: true}},
+		References: map[string]string{"org_id": "organizations"},
+	}
+	issues := TableManifest{
+		Name:       "project_issues",
+		OrgScoped:  true,
+		PrimaryKey: []string{"id"},
+		Columns: []Column{
+			{Name: "id"},
+			{Name: "org_id"},
+			{Name: "project_id"},
+			{Name: "parent_issue_id", Nullable: true},
+			{Name: "owner_user_id", Nullable: true},
+		},
+		References: map[string]string{
+			"org_id":          "organizations",
+			"project_id":      "projects",
+			"parent_issue_id": "project_issues",
+			"owner_user_id":   "users",
+		},
+	}
+	archive := &Archive{
+		Manifest: testManifest(projects, issues),
+		Tables: map[string][]Row{
+			"projects": {{"id": projectA, "org_id": testOrgID, "local_repo_path": "/data/repos/a.git"}},
+			"project_issues": {
+				{"id": issueA, "org_id": testOrgID, "project_id": projectA, "parent_issue_id": issueB, "owner_user_id": userA},
+				{"id": issueB, "org_id": testOrgID, "project_id": projectA, "parent_issue_id": nil},
+			},
+		},
+	}
+	return archive, projects, issues
+}
+
+func TestRemapperAssignsNewIDsAndFollowsReferences(t *testing.T) {
+	archive, projects, issues := remapTestArchive()
+	mapper := newRemapper(archive, targetOrgID, true, func(table, id string) (bool, error) {
+		require.Equal(t, "users", table)
+		return false, nil
+	})
+
+	project, refs, skip, err := mapper.rewrite(projects, archive.Tables["projects"][0])
+	require.NoError(t, err)
+	require.Empty(t, skip)
+	require.Empty(t, refs)
+	require.NotEqual(t, projectA, project["id"])
+	require.Regexp(t, uuidPattern, project["id"])
+	require.Equal(t, targetOrgID, project["org_id"])
+	require.Nil(t, project["local_repo_path"])
+	mapper.record("projects", projectA, project["id"].(string))
+
+	first, refs, skip, err := mapper.rewrite(issues, archive.Tables["project_issues"][0])
+	require.NoError(t, err)
+	require.Empty(t, skip)
+	require.Equal(t, project["id"], first["project_id"])
+	require.Nil(t, first["parent_issue_id"], "forward self reference is patched later")
+	require.Nil(t, first["owner_user_id"], "users outside the target org are cleared")
+	require.Equal(t, []deferredRef{{
+		Table:    "project_issues",
+		Column:   "parent_issue_id",
+		Target:   "project_issues",
+		OldValue: issueB,
+	}}, refs)
+	require.Equal(t, 1, mapper.cleared["project_issues.owner_user_id"])
+
+	// The source archive is left untouched.
+	require.Equal(t, issueB, archive.Tables["project_issues"][0]["parent_issue_id"])
+}
+
+func TestRemapperSkipsRowsWithUnresolvableRequiredReferences(t *testing.T) {
+	archive, _, issues := remapTestArchive()
+	mapper := newRemapper(archive, targetOrgID, true, nil)
+
+	// projects is ordered before project_issues but the project was never
+	// recorded, as if its row had been skipped.
+	out, _, skip, err := mapper.rewrite(issues, archive.Tables["project_issues"][1])
+	require.NoError(t, err)
+	require.Nil(t, out)
+	require.Equal(t, "project_id references missing projects "+projectA, skip)
+}
+
+func TestRemapperKeepsIDsWhenNotRemapping(t *testing.T) {
+	archive, projects, issues := remapTestArchive()
+	archive.Tables["project_issues"][1]["project_id"] = "eeeeeeee-eeee-eeee-eeee-eeeeeeeeeeee"
+	mapper := newRemapper(archive, targetOrgID, false, func(string, string) (bool, error) { return true, nil })
+
+	project, _, _, err := mapper.rewrite(projects, archive.Tables["projects"][0])
+	require.NoError(t, err)
+	require.Equal(t, projectA, project["id"])
+	require.Equal(t, "/data/repos/a.git", project["local_repo_path"])
+	mapper.record("projects", projectA, projectA)
+
+	issue, _, skip, err := mapper.rewrite(issues, archive.Tables["project_issues"][1])
+	require.NoError(t, err)
+	require.Empty(t, skip)
+	require.Equal(t, issueB, issue["id"])
+	require.Equal(t, "eeeeeeee-eeee-eeee-eeee-eeeeeeeeeeee", issue["project_id"], "incremental rows may point at existing projects")
+}
+
+func TestRemapperDropsNonUUIDIDsForTheDatabaseToAssign(t *testing.T) {
+	messages := TableManifest{
+		Name:       "project_chat_messages",
+		OrgScoped:  true,
+		PrimaryKey: []string{"id"},
+		Columns:    []Column{{Name: "id"}, {Name: "org_id"}},
+	}
+	archive := &Archive{
+		Manifest: testManifest(messages),
+		Tables:   map[string][]Row{"project_chat_messages": {{"id": "42", "org_id": testOrgID}}},
+	}
+	mapper := newRemapper(archive, targetOrgID, true, nil)
+
+	out, _, _, err := mapper.rewrite(messages, archive.Tables["project_chat_messages"][0])
+	require.NoError(t, err)
+	_, hasID := out["id"]
+	require.False(t, hasID)
+}
+
+func TestRemapperRefusesReferencesOutsideTargetOrgWhenNotRemapping(t *testing.T) {
+	archive, _, issues := remapTestArchive()
+	otherProject := "eeeeeeee-eeee-eeee-eeee-eeeeeeeeeeee"
+	otherTask := "ffffffff-ffff-ffff-ffff-ffffffffffff"
+	ownTask := "12121212-1212-1212-1212-121212121212"
+	archive.Tables["project_issues"][1]["project_id"] = otherProject
+
+	var asked []string
+	mapper := newRemapper(archive, targetOrgID, false, func(table, id string) (bool, error) {
+		asked = append(asked, table+"/"+id)
+		return table == "tasks" && id == ownTask, nil
+	})
+
+	_, _, skip, err := mapper.rewrite(issues, archive.Tables["project_issues"][1])
+	require.NoError(t, err)
+	require.Equal(t, "project_id references projects "+otherProject+", which does not exist in the target org", skip)
+	require.Equal(t, []string{"projects/" + otherProject}, asked)
+
+	comments := TableManifest{
+		Name:       "comments",
+		PrimaryKey: []string{"id"},
+		Columns:    []Column{{Name: "id"}, {Name: "task_id"}, {Name: "body"}},
+	}
+	out, _, skip, err := mapper.rewrite(comments, Row{"id": issueA, "task_id": otherTask, "body": "hi"})
+	require.NoError(t, err)
+	require.Nil(t, out)
+	require.Equal(t, "tasks "+otherTask+" does not exist in the target org", skip)
+
+	out, _, skip, err = mapper.rewrite(comments, Row{"id": issueB, "task_id": ownTask, "body": "hi"})
+	require.NoError(t, err)
+	require.Empty(t, skip)
+	require.Equal(t, ownTask, out["task_id"])
+
+	require.Equal(t, `"comments".task_id IN (SELECT id FROM tasks WHERE org_id = $2)`, parentScopedTables["comments"].clause(`"comments"`, "$2"))
+}
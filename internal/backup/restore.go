@@ -0,0 +1,341 @@
+package backup
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os/exec"
+	"sort"
+	"strings"
+
+	"github.com/samhotchkiss/otter-camp/internal/store"
+)
+
+// ErrInvalidArchive is returned when an archive fails validation.
+var ErrInvalidArchive = errors.New("invalid archive")
+
+// RestoreOptions controls how an archive is applied to the target org.
+type RestoreOptions struct {
+	OrgID string
+	// Remap gives every restored row a new id, which is what restoring into
+	// another org (or alongside the original data) needs. With Remap off, rows
+	// keep their ids and are upserted, which suits applying an incremental
+	// archive back onto the org it came from.
+	Remap  bool
+	DryRun bool
+	// PrepareRepo creates the bare repo for a restored project and returns its
+	// path. Bundles are not restored when it is nil.
+	PrepareRepo func(ctx context.Context, projectID string) (string, error)
+}
+
+// RestoreResult reports what a restore wrote (or, for a dry run, would write).
+type RestoreResult struct {
+	OrgID    string         `json:"org_id"`
+	DryRun   bool           `json:"dry_run"`
+	Remapped bool           `json:"remapped"`
+	Tables   map[string]int `json:"tables"`
+	Skipped  map[string]int `json:"skipped"`
+	Repos    int            `json:"repos"`
+	Warnings []string       `json:"warnings"`
+}
+
+func (r *RestoreResult) addWarning(format string, args ...any) {
+	if len(r.Warnings) < maxValidationMessages {
+		r.Warnings = append(r.Warnings, fmt.Sprintf(format, args...))
+	}
+}
+
+// Restore validates the archive and writes it into the target org in one
+// transaction. Git bundles are fetched into the project repos after the
+// transaction commits; failures there are reported as warnings.
+func Restore(ctx context.Context, db *sql.DB, archive *Archive, opts RestoreOptions) (*RestoreResult, error) {
+	orgID := strings.TrimSpace(opts.OrgID)
+	if !uuidPattern.MatchString(orgID) {
+		return nil, errors.New("invalid org_id")
+	}
+	validation := Validate(archive)
+	if !validation.Valid {
+		return nil, fmt.Errorf("%w: %s", ErrInvalidArchive, strings.Join(validation.Errors, "; "))
+	}
+	if opts.Remap && archive.Manifest.Incremental() {
+		return nil, fmt.Errorf("%w: incremental archives can only be restored without remapping", ErrInvalidArchive)
+	}
+
+	result := &RestoreResult{
+		OrgID:    orgID,
+		DryRun:   opts.DryRun,
+		Remapped: opts.Remap,
+		Tables:   map[string]int{},
+		Skipped:  map[string]int{},
+		Warnings: []string{},
+	}
+
+	tx, err := store.WithWorkspaceIDTx(ctx, db, orgID)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	orgScoped := map[string]bool{}
+	mapper := newRemapper(archive, orgID, opts.Remap, func(table, id string) (bool, error) {
+		scoped, ok := orgScoped[table]
+		if !ok {
+			described, err := describeTable(ctx, tx, table, true)
+			if err != nil {
+				return false, err
+			}
+			_, scoped = described.column("org_id")
+			orgScoped[table] = scoped
+		}
+		return rowInOrg(ctx, tx, table, id, orgID, scoped)
+	})
+
+	var deferred []deferredRef
+	for _, table := range archive.Manifest.Tables {
+		target, err := describeTable(ctx, tx, table.Name, table.OrgScoped)
+		if err != nil {
+			return nil, err
+		}
+		if len(target.Columns) == 0 {
+			result.addWarning("%s: table does not exist in this database (skipped %d rows)", table.Name, table.Rows)
+			result.Skipped[table.Name] += table.Rows
+			continue
+		}
+		// Whether a table is org-scoped comes from the live schema, not the
+		// archive, and only tables an export would write are restored.
+		_, table.OrgScoped = target.column("org_id")
+		target.OrgScoped = table.OrgScoped
+		_, parentScoped := parentScopedTables[table.Name]
+		if excludedTables[table.Name] || (!table.OrgScoped && !parentScoped) {
+			result.addWarning("%s: table is not restorable (skipped %d rows)", table.Name, table.Rows)
+			result.Skipped[table.Name] += table.Rows
+			continue
+		}
+		targetColumns := make(map[string]bool, len(target.Columns))
+		for _, column := range target.Columns {
+			targetColumns[column.Name] = true
+		}
+
+		for i, row := range archive.Tables[table.Name] {
+			out, refs, skip, err := mapper.rewrite(table, row)
+			if err != nil {
+				return nil, fmt.Errorf("restore %s[%d]: %w", table.Name, i, err)
+			}
+			if skip != "" {
+				result.Skipped[table.Name]++
+				result.addWarning("%s[%d]: skipped: %s", table.Name, i, skip)
+				continue
+			}
+
+			newID, err := insertRow(ctx, tx, table, target, targetColumns, out, opts.Remap, orgID)
+			if errors.Is(err, errRowOutsideOrg) {
+				result.Skipped[table.Name]++
+				result.addWarning("%s[%d]: skipped: %v", table.Name, i, err)
+				continue
+			}
+			if err != nil {
+				return nil, fmt.Errorf("restore %s[%d]: %w", table.Name, i, err)
+			}
+			result.Tables[table.Name]++
+			if table.singleIDKey() {
+				mapper.record(table.Name, valueString(row["id"]), newID)
+				for _, ref := range refs {
+					ref.RowID = newID
+					deferred = append(deferred, ref)
+				}
+			} else if len(refs) > 0 {
+				result.addWarning("%s[%d]: %d forward references left empty (table has no id key)", table.Name, i, len(refs))
+			}
+		}
+	}
+
+	for _, ref := range deferred {
+		mapped, ok := mapper.mapped(ref.Target, ref.OldValue)
+		if !ok {
+			result.addWarning("%s %s: %s left empty, %s %s was not restored", ref.Table, ref.RowID, ref.Column, ref.Target, ref.OldValue)
+			continue
+		}
+		query := fmt.Sprintf(
+			"UPDATE %s SET %s = $1 WHERE id::text = $2",
+			quoteIdent(ref.Table),
+			quoteIdent(ref.Column),
+		)
+		if _, err := tx.ExecContext(ctx, query, mapped, ref.RowID); err != nil {
+			return nil, fmt.Errorf("restore %s.%s: %w", ref.Table, ref.Column, err)
+		}
+	}
+
+	cleared := make([]string, 0, len(mapper.cleared))
+	for key := range mapper.cleared {
+		cleared = append(cleared, key)
+	}
+	sort.Strings(cleared)
+	for _, key := range cleared {
+		result.addWarning("%s: cleared %d references to rows outside the archive", key, mapper.cleared[key])
+	}
+
+	repos := make([]RepoManifest, 0, len(archive.Manifest.Repos))
+	for _, repo := range archive.Manifest.Repos {
+		projectID, ok := mapper.mapped("projects", repo.ProjectID)
+		if !ok {
+			if opts.Remap {
+				result.addWarning("repo %s: project was not restored (bundle skipped)", repo.ProjectID)
+				continue
+			}
+			projectID = repo.ProjectID
+		}
+		repos = append(repos, RepoManifest{ProjectID: projectID, File: repo.File, Bytes: repo.Bytes})
+	}
+
+	if opts.DryRun {
+		result.Repos = len(repos)
+		return result, nil
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("commit restore: %w", err)
+	}
+
+	if opts.PrepareRepo == nil {
+		if len(repos) > 0 {
+			result.addWarning("%d repo bundles not restored (no repo storage configured)", len(repos))
+		}
+		return result, nil
+	}
+	for _, repo := range repos {
+		if err := restoreBundle(ctx, opts.PrepareRepo, repo.ProjectID, archive.Repos[repo.File]); err != nil {
+			result.addWarning("repo %s: %v", repo.ProjectID, err)
+			continue
+		}
+		result.Repos++
+	}
+	return result, nil
+}
+
+// errRowOutsideOrg reports an upsert whose primary key belongs to a row of
+// another org; the row is left alone.
+var errRowOutsideOrg = errors.New("primary key belongs to a row outside the target org")
+
+// rowInOrg reports whether id names a row of table that the org owns.
+// Tables that are neither org- nor parent-scoped are shared, so any row
+// counts.
+func rowInOrg(ctx context.Context, q store.Querier, table, id, orgID string, orgScoped bool) (bool, error) {
+	where := "id::text = $1"
+	args := []any{id}
+	if scope, ok := parentScopedTables[table]; ok {
+		where += " AND " + scope.clause("", "$2")
+		args = append(args, orgID)
+	} else if orgScoped {
+		where += " AND org_id = $2"
+		args = append(args, orgID)
+	}
+	var found bool
+	err := q.QueryRowContext(
+		ctx,
+		fmt.Sprintf("SELECT EXISTS (SELECT 1 FROM %s WHERE %s)", quoteIdent(table), where),
+		args...,
+	).Scan(&found)
+	return found, err
+}
+
+// insertRow writes one rewritten row and returns its id (or "" for tables
+// without an id key). Values go through json_populate_record so every column
+// type is parsed by Postgres exactly as row_to_json wrote it. Upserts only
+// overwrite rows the target org already owns.
+func insertRow(
+	ctx context.Context,
+	tx *sql.Tx,
+	table TableManifest,
+	target TableManifest,
+	targetColumns map[string]bool,
+	row Row,
+	remap bool,
+	orgID string,
+) (string, error) {
+	columns := make([]string, 0, len(row))
+	for _, column := range target.Columns {
+		if _, ok := row[column.Name]; ok && targetColumns[column.Name] {
+			columns = append(columns, quoteIdent(column.Name))
+		}
+	}
+	if len(columns) == 0 {
+		return "", errors.New("no restorable columns")
+	}
+	payload, err := json.Marshal(row)
+	if err != nil {
+		return "", err
+	}
+
+	list := strings.Join(columns, ", ")
+	query := fmt.Sprintf(
+		"INSERT INTO %s (%s) SELECT %s FROM json_populate_record(NULL::%s, $1::json)",
+		quoteIdent(table.Name), list, list, quoteIdent(table.Name),
+	)
+	args := []any{string(payload)}
+	guarded := false
+	if !remap && len(target.PrimaryKey) > 0 {
+		keys := make([]string, 0, len(target.PrimaryKey))
+		isKey := map[string]bool{}
+		for _, key := range target.PrimaryKey {
+			keys = append(keys, quoteIdent(key))
+			isKey[quoteIdent(key)] = true
+		}
+		updates := make([]string, 0, len(columns))
+		for _, column := range columns {
+			if !isKey[column] {
+				updates = append(updates, fmt.Sprintf("%s = EXCLUDED.%s", column, column))
+			}
+		}
+		if len(updates) == 0 {
+			query += fmt.Sprintf(" ON CONFLICT (%s) DO NOTHING", strings.Join(keys, ", "))
+		} else {
+			query += fmt.Sprintf(" ON CONFLICT (%s) DO UPDATE SET %s", strings.Join(keys, ", "), strings.Join(updates, ", "))
+			if scope, ok := parentScopedTables[table.Name]; ok {
+				query += " WHERE " + scope.clause(quoteIdent(table.Name), "$2")
+				args = append(args, orgID)
+				guarded = true
+			} else if table.OrgScoped {
+				query += fmt.Sprintf(" WHERE %s.org_id = EXCLUDED.org_id", quoteIdent(table.Name))
+				guarded = true
+			}
+		}
+	}
+
+	if !table.singleIDKey() {
+		res, err := tx.ExecContext(ctx, query, args...)
+		if err != nil {
+			return "", err
+		}
+		if affected, err := res.RowsAffected(); err == nil && affected == 0 && guarded {
+			return "", errRowOutsideOrg
+		}
+		return "", nil
+	}
+	query += " RETURNING id::text"
+	var id string
+	err = tx.QueryRowContext(ctx, query, args...).Scan(&id)
+	if errors.Is(err, sql.ErrNoRows) {
+		if guarded {
+			return "", errRowOutsideOrg
+		}
+		// ON CONFLICT DO NOTHING on an unchanged row.
+		return valueString(row["id"]), nil
+	}
+	return id, err
+}
+
+func restoreBundle(ctx context.Context, prepare func(context.Context, string) (string, error), projectID, bundlePath string) error {
+	if bundlePath == "" {
+		return errors.New("bundle missing from archive")
+	}
+	repoPath, err := prepare(ctx, projectID)
+	if err != nil {
+		return fmt.Errorf("prepare repo: %w", err)
+	}
+	output, err := exec.CommandContext(ctx, "git", "-C", repoPath, "fetch", "--quiet", bundlePath, "+refs/*:refs/*").CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("git fetch bundle: %v: %s", err, strings.TrimSpace(string(output)))
+	}
+	return nil
+}
@@ -0,0 +1,262 @@
+package backup
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/samhotchkiss/otter-camp/internal/store"
+)
+
+// excludedTables are org-scoped tables that are never archived: identities,
+// credentials and sessions, delivery queues and sync bookkeeping, derived
+// data (embeddings, emissions, usage rollups) that is rebuilt on demand, and
+// the audit log, whose hash chain belongs to the org that wrote it.
+var excludedTables = map[string]bool{
+	"api_keys":                         true,
+	"audit_log":                        true,
+	"auth_requests":                    true,
+	"connection_events":                true,
+	"ellie_dedup_cursors":              true,
+	"ellie_ingestion_cursors":          true,
+	"ellie_ingestion_window_runs":      true,
+	"emission_latest_by_source":        true,
+	"emissions":                        true,
+	"git_access_token_projects":        true,
+	"git_access_tokens":                true,
+	"git_ssh_key_projects":             true,
+	"git_ssh_keys":                     true,
+	"github_installations":             true,
+	"github_sync_dead_letters":         true,
+	"github_sync_jobs":                 true,
+	"memory_entry_embeddings":          true,
+	"migration_progress":               true,
+	"openclaw_dispatch_queue":          true,
+	"openclaw_history_import_failures": true,
+	"org_sso_providers":                true,
+	"organizations":                    true,
+	"rate_limit_buckets":               true,
+	"role_bindings":                    true,
+	"sessions":                         true,
+	"shared_knowledge_embeddings":      true,
+	"sso_login_states":                 true,
+	"sync_metadata":                    true,
+	"usage_daily_rollups":              true,
+	"users":                            true,
+	"waitlist":                         true,
+}
+
+// parentScope names the org-scoped parent a parentScopedTables row hangs off.
+type parentScope struct {
+	Column string
+	Parent string
+}
+
+// clause matches rows whose parent belongs to the org bound to param.
+// qualifier, when set, prefixes the column.
+func (s parentScope) clause(qualifier, param string) string {
+	column := s.Column
+	if qualifier != "" {
+		column = qualifier + "." + column
+	}
+	return fmt.Sprintf("%s IN (SELECT id FROM %s WHERE org_id = %s)", column, s.Parent, param)
+}
+
+// parentScopedTables have no org_id column; their rows are selected through
+// the org-scoped parent they hang off.
+var parentScopedTables = map[string]parentScope{
+	"comments":       {Column: "task_id", Parent: "tasks"},
+	"issue_labels":   {Column: "issue_id", Parent: "project_issues"},
+	"project_labels": {Column: "project_id", Parent: "projects"},
+	"task_tags":      {Column: "task_id", Parent: "tasks"},
+}
+
+// loadSchema describes every archivable table, in restore order.
+func loadSchema(ctx context.Context, q store.Querier) ([]TableManifest, error) {
+	rows, err := q.QueryContext(ctx, `
+		SELECT c.table_name
+		FROM information_schema.columns c
+		JOIN information_schema.tables t
+		  ON t.table_schema = c.table_schema AND t.table_name = c.table_name
+		WHERE c.table_schema = current_schema()
+		  AND c.column_name = 'org_id'
+		  AND t.table_type = 'BASE TABLE'
+		ORDER BY c.table_name`)
+	if err != nil {
+		return nil, fmt.Errorf("list org tables: %w", err)
+	}
+	names := []string{}
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("scan org table: %w", err)
+		}
+		if !excludedTables[name] {
+			names = append(names, name)
+		}
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+
+	tables := make([]TableManifest, 0, len(names)+len(parentScopedTables))
+	for _, name := range names {
+		table, err := describeTable(ctx, q, name, true)
+		if err != nil {
+			return nil, err
+		}
+		tables = append(tables, table)
+	}
+	for name := range parentScopedTables {
+		table, err := describeTable(ctx, q, name, false)
+		if err != nil {
+			return nil, err
+		}
+		if len(table.Columns) == 0 {
+			continue
+		}
+		tables = append(tables, table)
+	}
+	return restoreOrder(tables), nil
+}
+
+func describeTable(ctx context.Context, q store.Querier, name string, orgScoped bool) (TableManifest, error) {
+	table := TableManifest{
+		Name:       name,
+		File:       tableFile(name),
+		OrgScoped:  orgScoped,
+		References: map[string]string{},
+	}
+
+	rows, err := q.QueryContext(ctx, `
+		SELECT column_name, data_type, is_nullable = 'YES',
+		       column_default IS NOT NULL OR is_identity = 'YES'
+		FROM information_schema.columns
+		WHERE table_schema = current_schema()
+		  AND table_name = $1
+		  AND is_generated = 'NEVER'
+		ORDER BY ordinal_position`, name)
+	if err != nil {
+		return table, fmt.Errorf("describe %s: %w", name, err)
+	}
+	for rows.Next() {
+		var column Column
+		if err := rows.Scan(&column.Name, &column.Type, &column.Nullable, &column.HasDefault); err != nil {
+			rows.Close()
+			return table, fmt.Errorf("scan %s column: %w", name, err)
+		}
+		table.Columns = append(table.Columns, column)
+	}
+	if err := rows.Close(); err != nil {
+		return table, err
+	}
+	if len(table.Columns) == 0 {
+		return table, nil
+	}
+
+	rows, err = q.QueryContext(ctx, `
+		SELECT a.attname
+		FROM pg_index i
+		JOIN pg_attribute a ON a.attrelid = i.indrelid AND a.attnum = ANY(i.indkey)
+		WHERE i.indrelid = $1::regclass AND i.indisprimary
+		ORDER BY array_position(i.indkey::int2[], a.attnum)`, name)
+	if err != nil {
+		return table, fmt.Errorf("load %s primary key: %w", name, err)
+	}
+	for rows.Next() {
+		var column string
+		if err := rows.Scan(&column); err != nil {
+			rows.Close()
+			return table, fmt.Errorf("scan %s primary key: %w", name, err)
+		}
+		table.PrimaryKey = append(table.PrimaryKey, column)
+	}
+	if err := rows.Close(); err != nil {
+		return table, err
+	}
+
+	// Only single-column foreign keys are remapped; every table in this schema
+	// references its parents by a lone id column.
+	rows, err = q.QueryContext(ctx, `
+		SELECT a.attname, c.confrelid::regclass::text
+		FROM pg_constraint c
+		JOIN pg_attribute a ON a.attrelid = c.conrelid AND a.attnum = c.conkey[1]
+		WHERE c.contype = 'f'
+		  AND c.conrelid = $1::regclass
+		  AND array_length(c.conkey, 1) = 1`, name)
+	if err != nil {
+		return table, fmt.Errorf("load %s foreign keys: %w", name, err)
+	}
+	for rows.Next() {
+		var column, target string
+		if err := rows.Scan(&column, &target); err != nil {
+			rows.Close()
+			return table, fmt.Errorf("scan %s foreign key: %w", name, err)
+		}
+		table.References[column] = strings.Trim(target, `"`)
+	}
+	if err := rows.Close(); err != nil {
+		return table, err
+	}
+	return table, nil
+}
+
+// restoreOrder sorts tables so each one follows the tables it references.
+// Ties and cycles fall back to name order; restore handles the references a
+// cycle leaves pointing forward by patching them after every row is in.
+func restoreOrder(tables []TableManifest) []TableManifest {
+	byName := make(map[string]TableManifest, len(tables))
+	names := make([]string, 0, len(tables))
+	for _, table := range tables {
+		byName[table.Name] = table
+		names = append(names, table.Name)
+	}
+	sort.Strings(names)
+
+	ordered := make([]TableManifest, 0, len(tables))
+	state := map[string]int{} // 1 = visiting, 2 = done
+	var visit func(name string)
+	visit = func(name string) {
+		if state[name] != 0 {
+			return
+		}
+		state[name] = 1
+		table := byName[name]
+		deps := make([]string, 0, len(table.References))
+		for _, target := range table.References {
+			if _, ok := byName[target]; ok && target != name {
+				deps = append(deps, target)
+			}
+		}
+		sort.Strings(deps)
+		for _, dep := range deps {
+			visit(dep)
+		}
+		state[name] = 2
+		ordered = append(ordered, table)
+	}
+	for _, name := range names {
+		visit(name)
+	}
+	return ordered
+}
+
+// snapshotColumn picks the column used to filter rows for incremental and
+// point-in-time exports, or "" when the table has neither.
+func snapshotColumn(table TableManifest, preferUpdated bool) string {
+	if preferUpdated {
+		if _, ok := table.column("updated_at"); ok {
+			return "updated_at"
+		}
+	}
+	if _, ok := table.column("created_at"); ok {
+		return "created_at"
+	}
+	return ""
+}
+
+func quoteIdent(name string) string {
+	return `"` + strings.ReplaceAll(name, `"`, `""`) + `"`
+}
@@ -0,0 +1,52 @@
+package backup
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRestoreOrderPutsReferencedTablesFirst(t *testing.T) {
+	tables := []TableManifest{
+		{Name: "issue_labels", References: map[string]string{"issue_id": "project_issues", "label_id": "labels"}},
+		{Name: "project_issues", References: map[string]string{"project_id": "projects", "parent_issue_id": "project_issues", "owner_user_id": "users"}},
+		{Name: "labels", References: map[string]string{"org_id": "organizations"}},
+		{Name: "projects", References: map[string]string{"primary_agent_id": "agents"}},
+		{Name: "agents", References: map[string]string{"org_id": "organizations"}},
+	}
+
+	ordered := restoreOrder(tables)
+	names := make([]string, 0, len(ordered))
+	for _, table := range ordered {
+		names = append(names, table.Name)
+	}
+	require.Equal(t, []string{"agents", "labels", "projects", "project_issues", "issue_labels"}, names)
+}
+
+func TestRestoreOrderToleratesCycles(t *testing.T) {
+	tables := []TableManifest{
+		{Name: "tasks", References: map[string]string{"project_id": "projects"}},
+		{Name: "projects", References: map[string]string{"template_task_id": "tasks"}},
+	}
+	require.Len(t, restoreOrder(tables), 2)
+}
+
+func TestExportQueryScopesAndFiltersRows(t *testing.T) {
+	since := time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC)
+	asOf := time.Date(2026, 10, 10, 0, 0, 0, 0, time.UTC)
+
+	issues := TableManifest{
+		Name:      "project_issues",
+		OrgScoped: true,
+		Columns:   []Column{{Name: "id"}, {Name: "created_at"}, {Name: "updated_at"}},
+	}
+	query, args := exportQuery(issues, testOrgID, &since, &asOf)
+	require.Equal(t, `SELECT row_to_json(t)::text FROM "project_issues" t WHERE (t.org_id = $1) AND t."updated_at" >= $2 AND t."created_at" <= $3`, query)
+	require.Equal(t, []any{testOrgID, since, asOf}, args)
+
+	labels := TableManifest{Name: "issue_labels", Columns: []Column{{Name: "issue_id"}, {Name: "label_id"}}}
+	query, args = exportQuery(labels, testOrgID, &since, nil)
+	require.Equal(t, `SELECT row_to_json(t)::text FROM "issue_labels" t WHERE (issue_id IN (SELECT id FROM project_issues WHERE org_id = $1))`, query)
+	require.Equal(t, []any{testOrgID}, args)
+}
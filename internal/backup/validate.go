@@ -0,0 +1,267 @@
+package backup
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"time"
+)
+
+// maxValidationMessages caps each of Errors and Warnings so a badly broken
+// archive still produces a readable report.
+const maxValidationMessages = 200
+
+// ValidationResult is the outcome of checking an archive before restore.
+type ValidationResult struct {
+	Valid       bool           `json:"valid"`
+	Version     int            `json:"version"`
+	OrgID       string         `json:"org_id"`
+	CreatedAt   string         `json:"created_at"`
+	Incremental bool           `json:"incremental"`
+	Tables      map[string]int `json:"tables"`
+	Repos       int            `json:"repos"`
+	TotalRows   int            `json:"total_rows"`
+	Errors      []string       `json:"errors"`
+	Warnings    []string       `json:"warnings"`
+
+	suppressedErrors   int
+	suppressedWarnings int
+}
+
+func (r *ValidationResult) addError(format string, args ...any) {
+	r.Valid = false
+	if len(r.Errors) >= maxValidationMessages {
+		r.suppressedErrors++
+		return
+	}
+	r.Errors = append(r.Errors, fmt.Sprintf(format, args...))
+}
+
+func (r *ValidationResult) addWarning(format string, args ...any) {
+	if len(r.Warnings) >= maxValidationMessages {
+		r.suppressedWarnings++
+		return
+	}
+	r.Warnings = append(r.Warnings, fmt.Sprintf(format, args...))
+}
+
+// Validate checks every table and bundle in the archive against the manifest:
+// row counts, primary keys, org ownership, required columns, duplicate keys
+// and references between archived tables. Dangling references are warnings
+// because restore clears nullable ones and skips rows that cannot be placed.
+func Validate(archive *Archive) ValidationResult {
+	manifest := archive.Manifest
+	result := ValidationResult{
+		Valid:       true,
+		Version:     manifest.Version,
+		OrgID:       manifest.OrgID,
+		CreatedAt:   manifest.CreatedAt.UTC().Format(time.RFC3339),
+		Incremental: manifest.Incremental(),
+		Tables:      map[string]int{},
+		Repos:       len(manifest.Repos),
+		Errors:      make([]string, 0),
+		Warnings:    make([]string, 0),
+	}
+
+	if err := checkManifestVersion(manifest); err != nil {
+		result.addError("%v", err)
+	}
+	if strings.TrimSpace(manifest.OrgID) == "" {
+		result.addWarning("missing org_id in manifest (rows will be checked against no org)")
+	}
+	for _, warning := range manifest.Warnings {
+		result.addWarning("export: %s", warning)
+	}
+
+	ids := map[string]map[string]bool{}
+	seen := map[string]bool{}
+	for _, table := range manifest.Tables {
+		seen[table.Name] = true
+		rows, ok := archive.Tables[table.Name]
+		if !ok {
+			result.addError("%s: missing %s", table.Name, table.File)
+			continue
+		}
+		result.Tables[table.Name] = len(rows)
+		result.TotalRows += len(rows)
+		if len(rows) != table.Rows {
+			result.addError("%s: manifest lists %d rows, file has %d", table.Name, table.Rows, len(rows))
+		}
+		if len(table.PrimaryKey) == 0 {
+			result.addWarning("%s: no primary key recorded (rows are inserted without conflict checks)", table.Name)
+		}
+		ids[table.Name] = validateTableRows(&result, manifest, table, rows)
+	}
+	for name := range archive.Tables {
+		if !seen[name] {
+			result.addWarning("%s: not listed in manifest (ignored)", name)
+		}
+	}
+
+	for _, table := range manifest.Tables {
+		validateTableReferences(&result, manifest, table, archive.Tables[table.Name], ids)
+	}
+
+	validateRepos(&result, archive, ids["projects"])
+
+	for _, name := range archive.Unknown {
+		result.addWarning("unexpected archive entry %s (ignored)", name)
+	}
+	if result.suppressedErrors > 0 {
+		result.Errors = append(result.Errors, fmt.Sprintf("... and %d more errors", result.suppressedErrors))
+	}
+	if result.suppressedWarnings > 0 {
+		result.Warnings = append(result.Warnings, fmt.Sprintf("... and %d more warnings", result.suppressedWarnings))
+	}
+	return result
+}
+
+// validateTableRows checks each row on its own and returns the set of ids in
+// the table when it is keyed by id.
+func validateTableRows(result *ValidationResult, manifest Manifest, table TableManifest, rows []Row) map[string]bool {
+	required := []string{}
+	for _, column := range table.Columns {
+		if column.Nullable || column.HasDefault || column.Name == "org_id" {
+			continue
+		}
+		required = append(required, column.Name)
+	}
+
+	keys := make(map[string]bool, len(rows))
+	for i, row := range rows {
+		keyParts := make([]string, 0, len(table.PrimaryKey))
+		complete := true
+		for _, column := range table.PrimaryKey {
+			value, ok := row[column]
+			if !ok || value == nil {
+				result.addError("%s[%d]: missing %s", table.Name, i, column)
+				complete = false
+				continue
+			}
+			keyParts = append(keyParts, valueString(value))
+		}
+		if complete && len(keyParts) > 0 {
+			key := strings.Join(keyParts, "/")
+			if keys[key] {
+				result.addError("%s[%d]: duplicate key %s", table.Name, i, key)
+			}
+			keys[key] = true
+		}
+
+		if table.OrgScoped {
+			orgID, _ := row["org_id"].(string)
+			switch {
+			case orgID == "":
+				result.addError("%s[%d]: missing org_id", table.Name, i)
+			case manifest.OrgID != "" && orgID != manifest.OrgID:
+				result.addError("%s[%d]: org_id %s does not match archive org %s", table.Name, i, orgID, manifest.OrgID)
+			}
+		}
+
+		for _, column := range required {
+			if isPrimaryKeyColumn(table, column) {
+				continue
+			}
+			if value, ok := row[column]; !ok || value == nil {
+				result.addError("%s[%d]: missing %s", table.Name, i, column)
+			}
+		}
+	}
+
+	if !table.singleIDKey() {
+		return nil
+	}
+	return keys
+}
+
+func validateTableReferences(result *ValidationResult, manifest Manifest, table TableManifest, rows []Row, ids map[string]map[string]bool) {
+	columns := make([]string, 0, len(table.References))
+	for column := range table.References {
+		columns = append(columns, column)
+	}
+	sort.Strings(columns)
+
+	for _, column := range columns {
+		target := table.References[column]
+		targetIDs, archived := ids[target]
+		if !archived || targetIDs == nil {
+			continue
+		}
+		dangling := 0
+		for _, row := range rows {
+			value, ok := row[column]
+			if !ok || value == nil {
+				continue
+			}
+			if !targetIDs[valueString(value)] {
+				dangling++
+			}
+		}
+		if dangling == 0 {
+			continue
+		}
+		if manifest.Incremental() {
+			result.addWarning("%s.%s: %d rows reference %s rows outside this incremental archive (they must already exist on restore)", table.Name, column, dangling, target)
+			continue
+		}
+		result.addWarning("%s.%s: %d rows reference %s rows not in the archive", table.Name, column, dangling, target)
+	}
+}
+
+func validateRepos(result *ValidationResult, archive *Archive, projectIDs map[string]bool) {
+	listed := map[string]bool{}
+	for _, repo := range archive.Manifest.Repos {
+		listed[repo.File] = true
+		path, ok := archive.Repos[repo.File]
+		if !ok {
+			result.addError("repo %s: missing %s", repo.ProjectID, repo.File)
+			continue
+		}
+		if err := checkBundleHeader(path); err != nil {
+			result.addError("repo %s: %v", repo.ProjectID, err)
+		}
+		if !archive.Manifest.Incremental() && !projectIDs[repo.ProjectID] {
+			result.addWarning("repo %s: project not in archive (bundle will be skipped)", repo.ProjectID)
+		}
+	}
+	for name := range archive.Repos {
+		if !listed[name] {
+			result.addWarning("%s: not listed in manifest (ignored)", name)
+		}
+	}
+}
+
+func checkBundleHeader(path string) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	line, err := bufio.NewReader(file).ReadString('\n')
+	if err != nil && line == "" {
+		return fmt.Errorf("empty bundle")
+	}
+	line = strings.TrimSpace(line)
+	if line != "# v2 git bundle" && line != "# v3 git bundle" {
+		return fmt.Errorf("not a git bundle")
+	}
+	return nil
+}
+
+func isPrimaryKeyColumn(table TableManifest, column string) bool {
+	for _, key := range table.PrimaryKey {
+		if key == column {
+			return true
+		}
+	}
+	return false
+}
+
+func valueString(value any) string {
+	if s, ok := value.(string); ok {
+		return s
+	}
+	return fmt.Sprint(value)
+}
@@ -0,0 +1,114 @@
+package backup
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func validateTestTables() (TableManifest, TableManifest) {
+	projects := TableManifest{
+		Name:       "projects",
+		File:       tableFile("projects"),
+		OrgScoped:  true,
+		PrimaryKey: []string{"id"},
+		Columns: []Column{
+			{Name: "id", Type: "uuid", HasDefault: true},
+			{Name: "org_id", Type: "uuid"},
+			{Name: "name", Type: "text"},
+			{Name: "description", Type: "text", Nullable: true},
+		},
+	}
+	issues := TableManifest{
+		Name:       "project_issues",
+		File:       tableFile("project_issues"),
+		OrgScoped:  true,
+		PrimaryKey: []string{"id"},
+		Columns: []Column{
+			{Name: "id", Type: "uuid", HasDefault: true},
+			{Name: "org_id", Type: "uuid"},
+			{Name: "project_id", Type: "uuid"},
+			{Name: "title", Type: "text"},
+		},
+		References: map[string]string{"org_id": "organizations", "project_id": "projects"},
+	}
+	return projects, issues
+}
+
+func TestValidateAcceptsConsistentArchive(t *testing.T) {
+	projects, issues := validateTestTables()
+	projects.Rows, issues.Rows = 1, 1
+	archive := writeTestArchive(t, testManifest(projects, issues), map[string]string{
+		projects.File: `{"id":"p1","org_id":"` + testOrgID + `","name":"Alpha"}` + "\n",
+		issues.File:   `{"id":"i1","org_id":"` + testOrgID + `","project_id":"p1","title":"Fix it"}` + "\n",
+	})
+
+	result := Validate(archive)
+	require.True(t, result.Valid, "errors: %v", result.Errors)
+	require.Empty(t, result.Errors)
+	require.Empty(t, result.Warnings)
+	require.Equal(t, map[string]int{"projects": 1, "project_issues": 1}, result.Tables)
+	require.Equal(t, 2, result.TotalRows)
+	require.Equal(t, "2026-10-18T12:00:00Z", result.CreatedAt)
+}
+
+func TestValidateReportsRowProblemsAcrossTables(t *testing.T) {
+	projects, issues := validateTestTables()
+	projects.Rows, issues.Rows = 3, 3
+	archive := writeTestArchive(t, testManifest(projects, issues), map[string]string{
+		projects.File: `{"id":"p1","org_id":"` + testOrgID + `","name":"Alpha"}` + "\n" +
+			`{"id":"p1","org_id":"` + testOrgID + `","name":"Again"}` + "\n" +
+			`{"org_id":"22222222-2222-2222-2222-222222222222"}` + "\n",
+		issues.File: `{"id":"i1","org_id":"` + testOrgID + `","project_id":"p1","title":"ok"}` + "\n" +
+			`{"id":"i2","org_id":"` + testOrgID + `","project_id":"p9","title":"orphan"}` + "\n",
+	})
+
+	result := Validate(archive)
+	require.False(t, result.Valid)
+	require.Contains(t, result.Errors, "projects[1]: duplicate key p1")
+	require.Contains(t, result.Errors, "projects[2]: missing id")
+	require.Contains(t, result.Errors, "projects[2]: org_id 22222222-2222-2222-2222-222222222222 does not match archive org "+testOrgID)
+	require.Contains(t, result.Errors, "projects[2]: missing name")
+	require.Contains(t, result.Errors, "project_issues: manifest lists 3 rows, file has 2")
+	require.Contains(t, result.Warnings, "project_issues.project_id: 1 rows reference projects rows not in the archive")
+}
+
+func TestValidateReportsMissingFilesAndBundles(t *testing.T) {
+	projects, issues := validateTestTables()
+	manifest := testManifest(projects, issues)
+	manifest.Repos = []RepoManifest{
+		{ProjectID: "p1", File: repoFile("p1")},
+		{ProjectID: "p2", File: repoFile("p2")},
+	}
+	archive := writeTestArchive(t, manifest, map[string]string{
+		projects.File:  "",
+		repoFile("p2"): "not a bundle\n",
+	})
+
+	result := Validate(archive)
+	require.False(t, result.Valid)
+	require.Contains(t, result.Errors, "project_issues: missing tables/project_issues.jsonl")
+	require.Contains(t, result.Errors, "repo p1: missing repos/p1.bundle")
+	require.Contains(t, result.Errors, "repo p2: not a git bundle")
+	require.Contains(t, result.Warnings, "repo p2: project not in archive (bundle will be skipped)")
+}
+
+func TestValidateTreatsDanglingReferencesInIncrementalArchivesAsExpected(t *testing.T) {
+	projects, issues := validateTestTables()
+	issues.Rows = 1
+	manifest := testManifest(projects, issues)
+	since := time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC)
+	manifest.Since = &since
+	archive := writeTestArchive(t, manifest, map[string]string{
+		projects.File: "",
+		issues.File:   `{"id":"i1","org_id":"` + testOrgID + `","project_id":"p1","title":"new"}` + "\n",
+	})
+
+	result := Validate(archive)
+	require.True(t, result.Valid, "errors: %v", result.Errors)
+	require.True(t, result.Incremental)
+	require.Equal(t, []string{
+		"project_issues.project_id: 1 rows reference projects rows outside this incremental archive (they must already exist on restore)",
+	}, result.Warnings)
+}
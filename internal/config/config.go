@@ -4,6 +4,7 @@ import (
 	"bufio"
 	"fmt"
 	"math"
+	"net/url"
 	"os"
 	"strconv"
 	"strings"
@@ -92,6 +93,9 @@ const (
 	defaultJobSchedulerMaxPerPoll    = 50
 	defaultJobSchedulerRunTimeout    = 5 * time.Minute
 	defaultJobSchedulerMaxRunHistory = 100
+
+	defaultTracingServiceName = "otter-camp"
+	defaultTracingSampleRatio = 1.0
 )
 
 type GitHubConfig struct {
@@ -117,6 +121,7 @@ type Config struct {
 	EllieContextInjection     EllieContextInjectionConfig
 	ConversationTokenBackfill ConversationTokenBackfillConfig
 	JobScheduler              JobSchedulerConfig
+	Tracing                   TracingConfig
 }
 
 type ConversationEmbeddingConfig struct {
@@ -173,6 +178,16 @@ type JobSchedulerConfig struct {
 	MaxRunHistory int
 }
 
+// TracingConfig follows the standard OTEL_* variables where one exists.
+// Tracing is off unless an OTLP endpoint or a trace file is configured.
+type TracingConfig struct {
+	ServiceName  string
+	OTLPEndpoint string
+	OTLPHeaders  map[string]string
+	FilePath     string
+	SampleRatio  float64
+}
+
 func Load() (Config, error) {
 	cfg := Config{
 		OrgID:       strings.TrimSpace(os.Getenv("OTTER_ORG_ID")),
@@ -217,6 +232,17 @@ func Load() (Config, error) {
 		EllieContextInjection:     EllieContextInjectionConfig{},
 		ConversationTokenBackfill: ConversationTokenBackfillConfig{},
 		JobScheduler:              JobSchedulerConfig{},
+		Tracing: TracingConfig{
+			ServiceName: firstNonEmpty(
+				strings.TrimSpace(os.Getenv("OTEL_SERVICE_NAME")),
+				defaultTracingServiceName,
+			),
+			OTLPEndpoint: firstNonEmpty(
+				strings.TrimSpace(os.Getenv("OTEL_EXPORTER_OTLP_TRACES_ENDPOINT")),
+				strings.TrimSpace(os.Getenv("OTEL_EXPORTER_OTLP_ENDPOINT")),
+			),
+			FilePath: strings.TrimSpace(os.Getenv("OTTER_TRACE_FILE")),
+		},
 	}
 
 	githubEnabled, err := parseBool("GITHUB_INTEGRATION_ENABLED", false)
@@ -416,6 +442,18 @@ func Load() (Config, error) {
 	}
 	cfg.JobScheduler.MaxRunHistory = jobSchedulerMaxRunHistory
 
+	tracingHeaders, err := parseKeyValueList("OTEL_EXPORTER_OTLP_HEADERS")
+	if err != nil {
+		return Config{}, err
+	}
+	cfg.Tracing.OTLPHeaders = tracingHeaders
+
+	tracingSampleRatio, err := parseFloat("OTTER_TRACE_SAMPLE_RATIO", defaultTracingSampleRatio)
+	if err != nil {
+		return Config{}, err
+	}
+	cfg.Tracing.SampleRatio = tracingSampleRatio
+
 	if err := cfg.Validate(); err != nil {
 		return Config{}, err
 	}
@@ -543,6 +581,10 @@ func (c Config) Validate() error {
 		}
 	}
 
+	if c.Tracing.SampleRatio <= 0 || c.Tracing.SampleRatio > 1 {
+		return fmt.Errorf("OTTER_TRACE_SAMPLE_RATIO must be in (0,1]")
+	}
+
 	if !c.GitHub.Enabled {
 		return nil
 	}
@@ -661,6 +703,30 @@ func parseFloat(name string, defaultValue float64) (float64, error) {
 	return parsed, nil
 }
 
+// parseKeyValueList reads "k1=v1,k2=v2" with URL-encoded values, the format
+// of OTEL_EXPORTER_OTLP_HEADERS.
+func parseKeyValueList(name string) (map[string]string, error) {
+	raw := strings.TrimSpace(os.Getenv(name))
+	if raw == "" {
+		return nil, nil
+	}
+
+	values := map[string]string{}
+	for _, pair := range strings.Split(raw, ",") {
+		key, value, ok := strings.Cut(pair, "=")
+		key = strings.TrimSpace(key)
+		if !ok || key == "" {
+			return nil, fmt.Errorf("%s must be a comma-separated list of key=value pairs", name)
+		}
+		decoded, err := url.QueryUnescape(strings.TrimSpace(value))
+		if err != nil {
+			return nil, fmt.Errorf("%s has an invalid value for %s: %w", name, key, err)
+		}
+		values[key] = decoded
+	}
+	return values, nil
+}
+
 func firstNonEmpty(values ...string) string {
 	for _, value := range values {
 		if strings.TrimSpace(value) != "" {
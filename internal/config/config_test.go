@@ -665,3 +665,46 @@ func TestLoadAllowsMissingOpenAIAPIKeyWhenProviderIsNotOpenAI(t *testing.T) {
 		t.Fatalf("expected provider ollama, got %q", cfg.ConversationEmbedding.Provider)
 	}
 }
+
+func TestLoadParsesTracingSettings(t *testing.T) {
+	t.Setenv("OTEL_SERVICE_NAME", "otter-api")
+	t.Setenv("OTEL_EXPORTER_OTLP_TRACES_ENDPOINT", "")
+	t.Setenv("OTEL_EXPORTER_OTLP_ENDPOINT", "http://collector:4318")
+	t.Setenv("OTEL_EXPORTER_OTLP_HEADERS", "x-honeycomb-team=abc%3D,x-tenant = otter")
+	t.Setenv("OTTER_TRACE_FILE", "/tmp/otter-traces.jsonl")
+	t.Setenv("OTTER_TRACE_SAMPLE_RATIO", "0.25")
+
+	cfg, err := loadWithDefaultOpenAIKey(t)
+	if err != nil {
+		t.Fatalf("loadWithDefaultOpenAIKey(t) returned error: %v", err)
+	}
+
+	if cfg.Tracing.ServiceName != "otter-api" {
+		t.Fatalf("expected service name otter-api, got %q", cfg.Tracing.ServiceName)
+	}
+	if cfg.Tracing.OTLPEndpoint != "http://collector:4318" {
+		t.Fatalf("expected OTLP endpoint from OTEL_EXPORTER_OTLP_ENDPOINT, got %q", cfg.Tracing.OTLPEndpoint)
+	}
+	if cfg.Tracing.OTLPHeaders["x-honeycomb-team"] != "abc=" || cfg.Tracing.OTLPHeaders["x-tenant"] != "otter" {
+		t.Fatalf("unexpected OTLP headers %#v", cfg.Tracing.OTLPHeaders)
+	}
+	if cfg.Tracing.FilePath != "/tmp/otter-traces.jsonl" {
+		t.Fatalf("expected trace file path, got %q", cfg.Tracing.FilePath)
+	}
+	if cfg.Tracing.SampleRatio != 0.25 {
+		t.Fatalf("expected sample ratio 0.25, got %v", cfg.Tracing.SampleRatio)
+	}
+}
+
+func TestLoadRejectsInvalidTracingSettings(t *testing.T) {
+	t.Setenv("OTEL_EXPORTER_OTLP_HEADERS", "missing-equals")
+	if _, err := loadWithDefaultOpenAIKey(t); err == nil || !strings.Contains(err.Error(), "OTEL_EXPORTER_OTLP_HEADERS") {
+		t.Fatalf("expected OTEL_EXPORTER_OTLP_HEADERS error, got %v", err)
+	}
+
+	t.Setenv("OTEL_EXPORTER_OTLP_HEADERS", "")
+	t.Setenv("OTTER_TRACE_SAMPLE_RATIO", "1.5")
+	if _, err := loadWithDefaultOpenAIKey(t); err == nil || !strings.Contains(err.Error(), "OTTER_TRACE_SAMPLE_RATIO") {
+		t.Fatalf("expected OTTER_TRACE_SAMPLE_RATIO error, got %v", err)
+	}
+}
@@ -11,8 +11,10 @@ import (
 	"time"
 
 	"github.com/samhotchkiss/otter-camp/internal/github"
+	"github.com/samhotchkiss/otter-camp/internal/metrics"
 	"github.com/samhotchkiss/otter-camp/internal/middleware"
 	"github.com/samhotchkiss/otter-camp/internal/store"
+	"github.com/samhotchkiss/otter-camp/internal/tracing"
 )
 
 type RepoBindingPollStore interface {
@@ -101,7 +103,11 @@ func (p *RepoDriftPoller) Start(ctx context.Context) {
 		case <-ctx.Done():
 			return
 		case <-ticker.C():
-			_, _ = p.RunOnce(ctx)
+			started := time.Now()
+			runCtx, span := tracing.StartWorkerRun(ctx, "github_repo_drift")
+			result, err := p.RunOnce(runCtx)
+			tracing.EndWorkerRun(span, result != nil && result.ProjectsChecked > 0, err)
+			metrics.ObserveWorkerRun("github_repo_drift", time.Since(started), err)
 		}
 	}
 }
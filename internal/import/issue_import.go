@@ -0,0 +1,602 @@
+package importer
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/samhotchkiss/otter-camp/internal/middleware"
+	"github.com/samhotchkiss/otter-camp/internal/store"
+)
+
+const (
+	IssueImportActionCreate    = "create"
+	IssueImportActionUpdate    = "update"
+	IssueImportActionUnchanged = "unchanged"
+	IssueImportActionSkip      = "skip"
+	IssueImportActionError     = "error"
+
+	issueImportMigrationTypePrefix = "issue_import_"
+)
+
+// RunIssueImportInput describes one import of parsed export records into a
+// project. StatusMap overrides the built-in status mapping (external status
+// name -> work status) and AssigneeMap maps external assignee and comment
+// author names or emails to agent slugs, names or ids; assignees that are
+// not mapped are matched against agent slugs and display names.
+// CommentAuthor posts comments whose author is not an agent, with the
+// original author quoted; without it those comments are skipped.
+type RunIssueImportInput struct {
+	OrgID         string
+	ProjectID     string
+	Source        string
+	Issues        []ExternalIssue
+	StatusMap     map[string]string
+	AssigneeMap   map[string]string
+	CommentAuthor string
+	DryRun        bool
+}
+
+type IssueImportItem struct {
+	Key          string  `json:"key"`
+	Title        string  `json:"title"`
+	Action       string  `json:"action"`
+	IssueID      *string `json:"issue_id,omitempty"`
+	IssueNumber  *int64  `json:"issue_number,omitempty"`
+	WorkStatus   string  `json:"work_status,omitempty"`
+	State        string  `json:"state,omitempty"`
+	Priority     string  `json:"priority,omitempty"`
+	OwnerAgentID *string `json:"owner_agent_id,omitempty"`
+	ParentKey    string  `json:"parent_key,omitempty"`
+	Comments     int     `json:"comments"`
+	Reason       string  `json:"reason,omitempty"`
+}
+
+type IssueImportResult struct {
+	DryRun            bool              `json:"dry_run"`
+	Source            string            `json:"source"`
+	Total             int               `json:"total"`
+	Created           int               `json:"created"`
+	Updated           int               `json:"updated"`
+	Unchanged         int               `json:"unchanged"`
+	Skipped           int               `json:"skipped"`
+	Failed            int               `json:"failed"`
+	ParentLinks       int               `json:"parent_links"`
+	LabelsApplied     int               `json:"labels_applied"`
+	CommentsImported  int               `json:"comments_imported"`
+	CommentsExisting  int               `json:"comments_existing"`
+	CommentsSkipped   int               `json:"comments_skipped"`
+	UnmappedStatuses  map[string]int    `json:"unmapped_statuses,omitempty"`
+	UnmappedAssignees map[string]int    `json:"unmapped_assignees,omitempty"`
+	Items             []IssueImportItem `json:"items"`
+	Warnings          []string          `json:"warnings,omitempty"`
+
+	Summary IssueImportSummaryReport `json:"summary"`
+}
+
+// IssueImportRunner maps parsed export records onto project issues. Records
+// are keyed by their external key, so running the same export twice updates
+// the issues it created instead of duplicating them.
+type IssueImportRunner struct {
+	Imports  *store.IssueImportStore
+	Issues   *store.ProjectIssueStore
+	Labels   *store.LabelStore
+	Agents   *store.AgentStore
+	Progress *store.MigrationProgressStore
+}
+
+func NewIssueImportRunner(db *sql.DB) *IssueImportRunner {
+	return &IssueImportRunner{
+		Imports:  store.NewIssueImportStore(db),
+		Issues:   store.NewProjectIssueStore(db),
+		Labels:   store.NewLabelStore(db),
+		Agents:   store.NewAgentStore(db),
+		Progress: store.NewMigrationProgressStore(db),
+	}
+}
+
+// IssueImportMigrationType is the migration_progress type a source's imports
+// report under.
+func IssueImportMigrationType(source string) string {
+	return issueImportMigrationTypePrefix + strings.TrimSpace(strings.ToLower(source))
+}
+
+func (r *IssueImportRunner) Run(ctx context.Context, input RunIssueImportInput) (IssueImportResult, error) {
+	if r == nil || r.Imports == nil || r.Issues == nil || r.Agents == nil {
+		return IssueImportResult{}, errors.New("issue import runner is not configured")
+	}
+	source := strings.TrimSpace(strings.ToLower(input.Source))
+	if !store.IsValidIssueImportSource(source) {
+		return IssueImportResult{}, fmt.Errorf("%w: source must be jira, linear or csv", store.ErrValidation)
+	}
+	statusMap, err := normalizeIssueImportStatusMap(input.StatusMap)
+	if err != nil {
+		return IssueImportResult{}, err
+	}
+	orgID := strings.TrimSpace(input.OrgID)
+	if orgID == "" {
+		return IssueImportResult{}, errors.New("org id is required")
+	}
+	ctx = context.WithValue(ctx, middleware.WorkspaceIDKey, orgID)
+
+	agents, err := r.Agents.List(ctx)
+	if err != nil {
+		return IssueImportResult{}, fmt.Errorf("failed to list agents: %w", err)
+	}
+	resolver, err := newIssueImportAgentResolver(agents, input.AssigneeMap)
+	if err != nil {
+		return IssueImportResult{}, err
+	}
+	commentAuthorID := ""
+	if ref := strings.TrimSpace(input.CommentAuthor); ref != "" {
+		agent, ok := resolver.lookup(ref)
+		if !ok {
+			return IssueImportResult{}, fmt.Errorf("%w: comment author %q is not an agent", store.ErrValidation, ref)
+		}
+		commentAuthorID = agent
+	}
+
+	existing, err := r.Imports.ListImportedIssues(ctx, input.ProjectID, source)
+	if err != nil {
+		return IssueImportResult{}, err
+	}
+	importedComments, err := r.Imports.ListImportedCommentIDs(ctx, input.ProjectID, source)
+	if err != nil {
+		return IssueImportResult{}, err
+	}
+
+	result := IssueImportResult{
+		DryRun: input.DryRun,
+		Source: source,
+		Total:  len(input.Issues),
+		Items:  make([]IssueImportItem, 0, len(input.Issues)),
+	}
+	migrationType := IssueImportMigrationType(source)
+	trackProgress := !input.DryRun && r.Progress != nil
+	if trackProgress {
+		total := len(input.Issues)
+		if _, err := r.Progress.StartPhase(ctx, store.StartMigrationProgressInput{
+			OrgID:         orgID,
+			MigrationType: migrationType,
+			TotalItems:    &total,
+			CurrentLabel:  migrationRunnerStringPtr("importing " + source + " issues"),
+		}); err != nil {
+			return IssueImportResult{}, err
+		}
+	}
+
+	issueIDs := make(map[string]string, len(existing)+len(input.Issues))
+	parents := make(map[string]*string, len(existing))
+	for key, issue := range existing {
+		issueIDs[key] = issue.ID
+		parents[key] = issue.ParentIssueID
+	}
+	seen := make(map[string]bool, len(input.Issues))
+	for _, external := range input.Issues {
+		item := IssueImportItem{Key: external.Key, Title: external.Title, ParentKey: external.ParentKey, Comments: len(external.Comments)}
+		switch {
+		case external.Key == "":
+			item.Action, item.Reason = IssueImportActionSkip, "missing key"
+		case external.Title == "":
+			item.Action, item.Reason = IssueImportActionSkip, "missing title"
+		case seen[external.Key]:
+			item.Action, item.Reason = IssueImportActionSkip, "duplicate key in export"
+		}
+		if item.Action != "" {
+			result.Skipped++
+			result.Items = append(result.Items, item)
+			r.advanceProgress(ctx, trackProgress, orgID, migrationType, false)
+			continue
+		}
+		seen[external.Key] = true
+
+		workStatus, state, mapped := MapExternalIssueStatus(external.Status, external.StatusCategory, statusMap)
+		if !mapped && external.Status != "" {
+			result.UnmappedStatuses = incrementIssueImportCount(result.UnmappedStatuses, external.Status)
+		}
+		priority := MapExternalIssuePriority(external.Priority)
+		var ownerID *string
+		if assignee := strings.TrimSpace(external.Assignee); assignee != "" {
+			if agentID, ok := resolver.lookup(assignee); ok {
+				ownerID = &agentID
+			} else {
+				result.UnmappedAssignees = incrementIssueImportCount(result.UnmappedAssignees, assignee)
+			}
+		}
+		item.WorkStatus, item.State, item.Priority, item.OwnerAgentID = workStatus, state, priority, ownerID
+
+		upsert := store.ImportIssueInput{
+			ProjectID:    input.ProjectID,
+			Source:       source,
+			ExternalID:   external.Key,
+			Title:        external.Title,
+			Body:         issueImportOptionalText(external.Body),
+			State:        state,
+			WorkStatus:   workStatus,
+			Priority:     priority,
+			OwnerAgentID: ownerID,
+			ClosedAt:     external.ClosedAt,
+		}
+		if input.DryRun {
+			if current, ok := existing[external.Key]; ok {
+				item.IssueID, item.IssueNumber = &current.ID, &current.IssueNumber
+				item.Action = IssueImportActionUnchanged
+				if !store.ImportedIssueMatches(current, upsert) {
+					item.Action = IssueImportActionUpdate
+				}
+			} else {
+				item.Action = IssueImportActionCreate
+				issueIDs[external.Key] = ""
+			}
+			result.LabelsApplied += len(external.Labels)
+		} else {
+			upserted, err := r.Imports.UpsertIssue(ctx, upsert)
+			if err != nil {
+				item.Action, item.Reason = IssueImportActionError, err.Error()
+				result.Failed++
+				result.Items = append(result.Items, item)
+				r.advanceProgress(ctx, trackProgress, orgID, migrationType, true)
+				continue
+			}
+			issue := upserted.Issue
+			item.IssueID, item.IssueNumber = &issue.ID, &issue.IssueNumber
+			switch {
+			case upserted.Created:
+				item.Action = IssueImportActionCreate
+			case upserted.Updated:
+				item.Action = IssueImportActionUpdate
+			default:
+				item.Action = IssueImportActionUnchanged
+			}
+			issueIDs[external.Key] = issue.ID
+			parents[external.Key] = issue.ParentIssueID
+			if ownerID != nil {
+				if _, err := r.Issues.AddParticipant(ctx, store.AddProjectIssueParticipantInput{
+					IssueID: issue.ID,
+					AgentID: *ownerID,
+					Role:    "owner",
+				}); err != nil {
+					result.Warnings = append(result.Warnings, fmt.Sprintf("%s: failed to add owner as participant: %v", external.Key, err))
+				}
+			}
+			result.LabelsApplied += r.applyLabels(ctx, issue.ID, external, &result)
+		}
+		switch item.Action {
+		case IssueImportActionCreate:
+			result.Created++
+		case IssueImportActionUpdate:
+			result.Updated++
+		default:
+			result.Unchanged++
+		}
+
+		for _, comment := range external.Comments {
+			if importedComments[comment.ID] {
+				result.CommentsExisting++
+				continue
+			}
+			authorID, body, ok := issueImportCommentAuthor(resolver, commentAuthorID, comment)
+			if !ok {
+				result.CommentsSkipped++
+				continue
+			}
+			if input.DryRun {
+				result.CommentsImported++
+				continue
+			}
+			created, err := r.Imports.ImportComment(ctx, store.ImportIssueCommentInput{
+				ProjectID:     input.ProjectID,
+				Source:        source,
+				ExternalID:    comment.ID,
+				IssueID:       issueIDs[external.Key],
+				AuthorAgentID: authorID,
+				Body:          body,
+			})
+			if err != nil {
+				result.CommentsSkipped++
+				result.Warnings = append(result.Warnings, fmt.Sprintf("%s: failed to import comment %s: %v", external.Key, comment.ID, err))
+				continue
+			}
+			if created {
+				result.CommentsImported++
+			} else {
+				result.CommentsExisting++
+			}
+		}
+		result.Items = append(result.Items, item)
+		r.advanceProgress(ctx, trackProgress, orgID, migrationType, false)
+	}
+
+	r.linkParents(ctx, input, issueIDs, parents, &result)
+	if result.CommentsSkipped > 0 && commentAuthorID == "" {
+		result.Warnings = append(result.Warnings, fmt.Sprintf("%d comment(s) skipped because their authors are not agents; set a comment author to import them", result.CommentsSkipped))
+	}
+
+	result.Summary = BuildIssueImportSummaryReport(result)
+
+	if trackProgress {
+		if _, err := r.Progress.SetStatus(ctx, store.SetMigrationProgressStatusInput{
+			OrgID:         orgID,
+			MigrationType: migrationType,
+			Status:        store.MigrationProgressStatusCompleted,
+			CurrentLabel:  migrationRunnerStringPtr(fmt.Sprintf("%d created, %d updated", result.Created, result.Updated)),
+		}); err != nil {
+			return result, err
+		}
+	}
+	return result, nil
+}
+
+// linkParents attaches each child to its parent once every record has an
+// issue, since exports do not list parents before children.
+func (r *IssueImportRunner) linkParents(
+	ctx context.Context,
+	input RunIssueImportInput,
+	issueIDs map[string]string,
+	parents map[string]*string,
+	result *IssueImportResult,
+) {
+	for _, external := range input.Issues {
+		parentKey := strings.TrimSpace(external.ParentKey)
+		childID, childOK := issueIDs[external.Key]
+		if parentKey == "" || !childOK {
+			continue
+		}
+		parentID, ok := issueIDs[parentKey]
+		if !ok {
+			result.Warnings = append(result.Warnings, fmt.Sprintf("%s: parent %s is not in this export or a previous import", external.Key, parentKey))
+			continue
+		}
+		if current := parents[external.Key]; current != nil && parentID != "" && *current == parentID {
+			continue
+		}
+		if input.DryRun {
+			result.ParentLinks++
+			continue
+		}
+		if _, err := r.Issues.AttachSubIssue(ctx, parentID, childID); err != nil {
+			result.Warnings = append(result.Warnings, fmt.Sprintf("%s: failed to attach to parent %s: %v", external.Key, parentKey, err))
+			continue
+		}
+		result.ParentLinks++
+	}
+}
+
+func (r *IssueImportRunner) applyLabels(ctx context.Context, issueID string, external ExternalIssue, result *IssueImportResult) int {
+	if r.Labels == nil {
+		return 0
+	}
+	applied := 0
+	for _, name := range external.Labels {
+		label, err := r.Labels.EnsureByName(ctx, name, "")
+		if err == nil {
+			err = r.Labels.AddToIssue(ctx, issueID, label.ID)
+		}
+		if err != nil {
+			result.Warnings = append(result.Warnings, fmt.Sprintf("%s: failed to apply label %q: %v", external.Key, name, err))
+			continue
+		}
+		applied++
+	}
+	return applied
+}
+
+func (r *IssueImportRunner) advanceProgress(ctx context.Context, enabled bool, orgID, migrationType string, failed bool) {
+	if !enabled {
+		return
+	}
+	input := store.AdvanceMigrationProgressInput{OrgID: orgID, MigrationType: migrationType, ProcessedDelta: 1}
+	if failed {
+		input.FailedDelta = 1
+	}
+	_, _ = r.Progress.Advance(ctx, input)
+}
+
+// MapExternalIssueStatus maps a tracker status onto a work status and issue
+// state. overrides (lowercased status -> work status) win, then well-known
+// status names, then the tracker's status category. ok is false when the
+// status fell back to queued.
+func MapExternalIssueStatus(status, category string, overrides map[string]string) (workStatus, state string, ok bool) {
+	key := strings.ToLower(strings.Join(strings.Fields(status), " "))
+	workStatus, ok = overrides[key]
+	if !ok {
+		workStatus, ok = externalIssueStatusNames[key]
+	}
+	if !ok {
+		workStatus, ok = externalIssueStatusCategories[strings.ToLower(strings.TrimSpace(category))]
+	}
+	if !ok {
+		workStatus = store.IssueWorkStatusQueued
+	}
+	state = "open"
+	if workStatus == store.IssueWorkStatusDone || workStatus == store.IssueWorkStatusCancelled {
+		state = "closed"
+	}
+	return workStatus, state, ok
+}
+
+var externalIssueStatusNames = map[string]string{
+	"":                         store.IssueWorkStatusQueued,
+	"backlog":                  store.IssueWorkStatusQueued,
+	"to do":                    store.IssueWorkStatusQueued,
+	"todo":                     store.IssueWorkStatusQueued,
+	"open":                     store.IssueWorkStatusQueued,
+	"new":                      store.IssueWorkStatusQueued,
+	"triage":                   store.IssueWorkStatusQueued,
+	"selected for development": store.IssueWorkStatusQueued,
+	"reopened":                 store.IssueWorkStatusQueued,
+	"in progress":              store.IssueWorkStatusInProgress,
+	"in development":           store.IssueWorkStatusInProgress,
+	"doing":                    store.IssueWorkStatusInProgress,
+	"started":                  store.IssueWorkStatusInProgress,
+	"in review":                store.IssueWorkStatusReview,
+	"review":                   store.IssueWorkStatusReview,
+	"code review":              store.IssueWorkStatusReview,
+	"qa":                       store.IssueWorkStatusReview,
+	"in qa":                    store.IssueWorkStatusReview,
+	"blocked":                  store.IssueWorkStatusBlocked,
+	"on hold":                  store.IssueWorkStatusOnHold,
+	"paused":                   store.IssueWorkStatusOnHold,
+	"done":                     store.IssueWorkStatusDone,
+	"closed":                   store.IssueWorkStatusDone,
+	"resolved":                 store.IssueWorkStatusDone,
+	"complete":                 store.IssueWorkStatusDone,
+	"completed":                store.IssueWorkStatusDone,
+	"shipped":                  store.IssueWorkStatusDone,
+	"canceled":                 store.IssueWorkStatusCancelled,
+	"cancelled":                store.IssueWorkStatusCancelled,
+	"won't do":                 store.IssueWorkStatusCancelled,
+	"wont do":                  store.IssueWorkStatusCancelled,
+	"won't fix":                store.IssueWorkStatusCancelled,
+	"duplicate":                store.IssueWorkStatusCancelled,
+}
+
+// externalIssueStatusCategories covers Jira status categories and Linear
+// state types.
+var externalIssueStatusCategories = map[string]string{
+	"new":           store.IssueWorkStatusQueued,
+	"indeterminate": store.IssueWorkStatusInProgress,
+	"done":          store.IssueWorkStatusDone,
+	"triage":        store.IssueWorkStatusQueued,
+	"backlog":       store.IssueWorkStatusQueued,
+	"unstarted":     store.IssueWorkStatusQueued,
+	"started":       store.IssueWorkStatusInProgress,
+	"completed":     store.IssueWorkStatusDone,
+	"canceled":      store.IssueWorkStatusCancelled,
+}
+
+// MapExternalIssuePriority maps Jira and Linear priority names onto P0-P3;
+// empty and unknown priorities get the default P2.
+func MapExternalIssuePriority(priority string) string {
+	switch strings.ToLower(strings.TrimSpace(priority)) {
+	case "p0", "highest", "blocker", "urgent", "critical":
+		return store.IssuePriorityP0
+	case "p1", "high", "major":
+		return store.IssuePriorityP1
+	case "p3", "low", "lowest", "minor", "trivial":
+		return store.IssuePriorityP3
+	default:
+		return store.IssuePriorityP2
+	}
+}
+
+func normalizeIssueImportStatusMap(raw map[string]string) (map[string]string, error) {
+	normalized := make(map[string]string, len(raw))
+	for status, workStatus := range raw {
+		key := strings.ToLower(strings.Join(strings.Fields(status), " "))
+		value := strings.ToLower(strings.TrimSpace(workStatus))
+		switch value {
+		case store.IssueWorkStatusQueued, store.IssueWorkStatusInProgress, store.IssueWorkStatusBlocked,
+			store.IssueWorkStatusOnHold, store.IssueWorkStatusReview, store.IssueWorkStatusDone, store.IssueWorkStatusCancelled:
+		default:
+			return nil, fmt.Errorf("%w: status %q maps to unknown work status %q", store.ErrValidation, status, workStatus)
+		}
+		normalized[key] = value
+	}
+	return normalized, nil
+}
+
+// issueImportAgentResolver matches external people to agents by explicit
+// mapping first, then by agent id, slug or display name.
+type issueImportAgentResolver struct {
+	byName   map[string]string
+	explicit map[string]string
+}
+
+func newIssueImportAgentResolver(agents []store.Agent, mapping map[string]string) (*issueImportAgentResolver, error) {
+	resolver := &issueImportAgentResolver{
+		byName:   make(map[string]string, len(agents)*3),
+		explicit: make(map[string]string, len(mapping)),
+	}
+	for _, agent := range agents {
+		for _, name := range []string{agent.ID, agent.Slug, agent.DisplayName} {
+			if key := issueImportPersonKey(name); key != "" {
+				if _, taken := resolver.byName[key]; !taken {
+					resolver.byName[key] = agent.ID
+				}
+			}
+		}
+	}
+	for person, agentRef := range mapping {
+		agentID, ok := resolver.byName[issueImportPersonKey(agentRef)]
+		if !ok {
+			return nil, fmt.Errorf("%w: assignee %q maps to unknown agent %q", store.ErrValidation, person, agentRef)
+		}
+		resolver.explicit[issueImportPersonKey(person)] = agentID
+	}
+	return resolver, nil
+}
+
+func (r *issueImportAgentResolver) lookup(person string) (string, bool) {
+	key := issueImportPersonKey(person)
+	if key == "" {
+		return "", false
+	}
+	if agentID, ok := r.explicit[key]; ok {
+		return agentID, true
+	}
+	agentID, ok := r.byName[key]
+	return agentID, ok
+}
+
+func issueImportPersonKey(name string) string {
+	return strings.ToLower(strings.Join(strings.Fields(name), " "))
+}
+
+// issueImportCommentAuthor picks the agent to post a comment as. Comments
+// from people who are not agents are posted by the fallback author with the
+// original author and date quoted.
+func issueImportCommentAuthor(resolver *issueImportAgentResolver, fallbackID string, comment ExternalIssueComment) (string, string, bool) {
+	body := strings.TrimSpace(comment.Body)
+	if body == "" {
+		return "", "", false
+	}
+	if agentID, ok := resolver.lookup(comment.Author); ok {
+		return agentID, body, true
+	}
+	if fallbackID == "" {
+		return "", "", false
+	}
+	attribution := strings.TrimSpace(comment.Author)
+	if attribution == "" {
+		attribution = "unknown author"
+	}
+	if comment.CreatedAt != nil {
+		attribution += " on " + comment.CreatedAt.UTC().Format("2006-01-02 15:04 MST")
+	}
+	return fallbackID, "_Originally posted by " + attribution + "_\n\n" + body, true
+}
+
+func issueImportOptionalText(value string) *string {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return nil
+	}
+	return &value
+}
+
+func incrementIssueImportCount(counts map[string]int, key string) map[string]int {
+	if counts == nil {
+		counts = map[string]int{}
+	}
+	counts[key]++
+	return counts
+}
+
+// SortedIssueImportCounts lists a count map from most to least frequent.
+func SortedIssueImportCounts(counts map[string]int) []string {
+	keys := make([]string, 0, len(counts))
+	for key := range counts {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if counts[keys[i]] != counts[keys[j]] {
+			return counts[keys[i]] > counts[keys[j]]
+		}
+		return keys[i] < keys[j]
+	})
+	return keys
+}
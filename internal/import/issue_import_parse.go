@@ -0,0 +1,674 @@
+package importer
+
+import (
+	"bytes"
+	"crypto/sha1"
+	"encoding/csv"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/samhotchkiss/otter-camp/internal/store"
+)
+
+const (
+	IssueExportFormatJSON = "json"
+	IssueExportFormatCSV  = "csv"
+)
+
+// ExternalIssue is one issue read from a Jira, Linear or CSV export before it
+// is mapped onto Otter Camp fields. StatusCategory carries the tracker's own
+// grouping (Jira status category, Linear state type) when the export has it.
+type ExternalIssue struct {
+	Key            string                 `json:"key"`
+	Title          string                 `json:"title"`
+	Body           string                 `json:"body,omitempty"`
+	Status         string                 `json:"status,omitempty"`
+	StatusCategory string                 `json:"status_category,omitempty"`
+	Priority       string                 `json:"priority,omitempty"`
+	Labels         []string               `json:"labels,omitempty"`
+	Assignee       string                 `json:"assignee,omitempty"`
+	ParentKey      string                 `json:"parent_key,omitempty"`
+	Comments       []ExternalIssueComment `json:"comments,omitempty"`
+	ClosedAt       *time.Time             `json:"closed_at,omitempty"`
+}
+
+type ExternalIssueComment struct {
+	ID        string     `json:"id"`
+	Author    string     `json:"author,omitempty"`
+	Body      string     `json:"body"`
+	CreatedAt *time.Time `json:"created_at,omitempty"`
+}
+
+// IssueImportColumnMap maps issue fields to the CSV header that holds them
+// for generic CSV imports. Fields: key, title, body, status, priority,
+// labels, assignee, parent, closed_at. Unmapped fields default to a header
+// with the field's own name.
+type IssueImportColumnMap map[string]string
+
+var issueImportColumnFields = []string{"key", "title", "body", "status", "priority", "labels", "assignee", "parent", "closed_at"}
+
+// ParseIssueExport reads a Jira, Linear or generic CSV export. An empty
+// format is detected from the content.
+func ParseIssueExport(source, format string, data []byte, columns IssueImportColumnMap) ([]ExternalIssue, error) {
+	source = strings.TrimSpace(strings.ToLower(source))
+	format = strings.TrimSpace(strings.ToLower(format))
+	if format == "" {
+		format = detectIssueExportFormat(data)
+	}
+	if format != IssueExportFormatJSON && format != IssueExportFormatCSV {
+		return nil, fmt.Errorf("format must be json or csv")
+	}
+	if len(columns) > 0 && (source != store.IssueImportSourceCSV || format != IssueExportFormatCSV) {
+		return nil, fmt.Errorf("column mapping only applies to csv imports")
+	}
+
+	var (
+		issues []ExternalIssue
+		err    error
+	)
+	switch {
+	case source == store.IssueImportSourceJira && format == IssueExportFormatJSON:
+		issues, err = parseJiraIssueJSON(data)
+	case source == store.IssueImportSourceJira:
+		issues, err = parseJiraIssueCSV(data)
+	case source == store.IssueImportSourceLinear && format == IssueExportFormatJSON:
+		issues, err = parseLinearIssueJSON(data)
+	case source == store.IssueImportSourceLinear:
+		issues, err = parseLinearIssueCSV(data)
+	case source == store.IssueImportSourceCSV && format == IssueExportFormatCSV:
+		issues, err = parseGenericIssueCSV(data, columns)
+	case source == store.IssueImportSourceCSV:
+		return nil, fmt.Errorf("csv source requires csv format")
+	default:
+		return nil, fmt.Errorf("source must be jira, linear or csv")
+	}
+	if err != nil {
+		return nil, err
+	}
+	for i := range issues {
+		issues[i].Key = strings.TrimSpace(issues[i].Key)
+		issues[i].Title = strings.TrimSpace(issues[i].Title)
+		issues[i].Body = strings.TrimSpace(issues[i].Body)
+		issues[i].Labels = normalizeExternalIssueLabels(issues[i].Labels)
+		for j := range issues[i].Comments {
+			comment := &issues[i].Comments[j]
+			if strings.TrimSpace(comment.ID) == "" {
+				comment.ID = externalIssueCommentID(issues[i].Key, *comment)
+			}
+		}
+	}
+	return issues, nil
+}
+
+func detectIssueExportFormat(data []byte) string {
+	trimmed := bytes.TrimSpace(bytes.TrimPrefix(data, []byte("\xef\xbb\xbf")))
+	if len(trimmed) > 0 && (trimmed[0] == '{' || trimmed[0] == '[') {
+		return IssueExportFormatJSON
+	}
+	return IssueExportFormatCSV
+}
+
+// externalIssueCommentID derives a stable id for exports that do not carry
+// comment ids, so re-importing the same file does not repeat comments.
+func externalIssueCommentID(issueKey string, comment ExternalIssueComment) string {
+	created := ""
+	if comment.CreatedAt != nil {
+		created = comment.CreatedAt.UTC().Format(time.RFC3339)
+	}
+	sum := sha1.Sum([]byte(issueKey + "\x00" + created + "\x00" + comment.Author + "\x00" + strings.TrimSpace(comment.Body)))
+	return issueKey + "#" + hex.EncodeToString(sum[:8])
+}
+
+func normalizeExternalIssueLabels(labels []string) []string {
+	seen := make(map[string]bool, len(labels))
+	out := make([]string, 0, len(labels))
+	for _, label := range labels {
+		label = strings.TrimSpace(label)
+		if label == "" || seen[strings.ToLower(label)] {
+			continue
+		}
+		seen[strings.ToLower(label)] = true
+		out = append(out, label)
+	}
+	return out
+}
+
+var externalIssueTimeLayouts = []string{
+	time.RFC3339Nano,
+	"2006-01-02T15:04:05.000-0700",
+	"2006-01-02T15:04:05-0700",
+	"2006-01-02 15:04:05",
+	"2006-01-02 15:04",
+	"2006-01-02",
+	"02/Jan/06 3:04 PM",
+	"02/Jan/2006 3:04 PM",
+	"1/2/2006 15:04",
+	"Mon Jan 02 2006 15:04:05 GMT-0700",
+}
+
+func parseExternalIssueTime(raw string) *time.Time {
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return nil
+	}
+	// Linear CSV appends a zone name after the offset.
+	if idx := strings.Index(raw, " ("); idx > 0 {
+		raw = raw[:idx]
+	}
+	for _, layout := range externalIssueTimeLayouts {
+		if parsed, err := time.Parse(layout, raw); err == nil {
+			utc := parsed.UTC()
+			return &utc
+		}
+	}
+	return nil
+}
+
+// externalIssueText accepts plain strings and Atlassian document format
+// objects (Jira Cloud REST v3), flattening the latter to text.
+type externalIssueText string
+
+func (t *externalIssueText) UnmarshalJSON(raw []byte) error {
+	var text string
+	if err := json.Unmarshal(raw, &text); err == nil {
+		*t = externalIssueText(text)
+		return nil
+	}
+	var node atlassianDocNode
+	if err := json.Unmarshal(raw, &node); err != nil {
+		return nil
+	}
+	var b strings.Builder
+	node.writeText(&b)
+	*t = externalIssueText(strings.TrimSpace(b.String()))
+	return nil
+}
+
+type atlassianDocNode struct {
+	Type    string             `json:"type"`
+	Text    string             `json:"text"`
+	Content []atlassianDocNode `json:"content"`
+}
+
+func (n atlassianDocNode) writeText(b *strings.Builder) {
+	switch n.Type {
+	case "text":
+		b.WriteString(n.Text)
+	case "hardBreak":
+		b.WriteString("\n")
+	}
+	for _, child := range n.Content {
+		child.writeText(b)
+	}
+	switch n.Type {
+	case "paragraph", "heading", "listItem", "codeBlock", "blockquote":
+		b.WriteString("\n\n")
+	}
+}
+
+type jiraIssueExport struct {
+	Key    string `json:"key"`
+	Fields struct {
+		Summary     string            `json:"summary"`
+		Description externalIssueText `json:"description"`
+		Status      *struct {
+			Name           string `json:"name"`
+			StatusCategory *struct {
+				Key string `json:"key"`
+			} `json:"statusCategory"`
+		} `json:"status"`
+		Priority *struct {
+			Name string `json:"name"`
+		} `json:"priority"`
+		Labels   []string       `json:"labels"`
+		Assignee *jiraUserField `json:"assignee"`
+		Parent   *struct {
+			Key string `json:"key"`
+		} `json:"parent"`
+		Subtasks []struct {
+			Key string `json:"key"`
+		} `json:"subtasks"`
+		Comment *struct {
+			Comments []struct {
+				ID      string            `json:"id"`
+				Author  *jiraUserField    `json:"author"`
+				Body    externalIssueText `json:"body"`
+				Created string            `json:"created"`
+			} `json:"comments"`
+		} `json:"comment"`
+		ResolutionDate string `json:"resolutiondate"`
+	} `json:"fields"`
+}
+
+type jiraUserField struct {
+	DisplayName  string `json:"displayName"`
+	EmailAddress string `json:"emailAddress"`
+	Name         string `json:"name"`
+}
+
+func (u *jiraUserField) label() string {
+	if u == nil {
+		return ""
+	}
+	for _, value := range []string{u.DisplayName, u.EmailAddress, u.Name} {
+		if strings.TrimSpace(value) != "" {
+			return strings.TrimSpace(value)
+		}
+	}
+	return ""
+}
+
+func parseJiraIssueJSON(data []byte) ([]ExternalIssue, error) {
+	var records []jiraIssueExport
+	if err := decodeIssueExportList(data, &records, "issues"); err != nil {
+		return nil, fmt.Errorf("invalid jira export: %w", err)
+	}
+	issues := make([]ExternalIssue, 0, len(records))
+	subtaskParents := map[string]string{}
+	for _, record := range records {
+		fields := record.Fields
+		issue := ExternalIssue{
+			Key:      record.Key,
+			Title:    fields.Summary,
+			Body:     string(fields.Description),
+			Labels:   fields.Labels,
+			Assignee: fields.Assignee.label(),
+			ClosedAt: parseExternalIssueTime(fields.ResolutionDate),
+		}
+		if fields.Status != nil {
+			issue.Status = fields.Status.Name
+			if fields.Status.StatusCategory != nil {
+				issue.StatusCategory = fields.Status.StatusCategory.Key
+			}
+		}
+		if fields.Priority != nil {
+			issue.Priority = fields.Priority.Name
+		}
+		if fields.Parent != nil {
+			issue.ParentKey = strings.TrimSpace(fields.Parent.Key)
+		}
+		for _, subtask := range fields.Subtasks {
+			subtaskParents[strings.TrimSpace(subtask.Key)] = record.Key
+		}
+		if fields.Comment != nil {
+			for _, comment := range fields.Comment.Comments {
+				issue.Comments = append(issue.Comments, ExternalIssueComment{
+					ID:        comment.ID,
+					Author:    comment.Author.label(),
+					Body:      string(comment.Body),
+					CreatedAt: parseExternalIssueTime(comment.Created),
+				})
+			}
+		}
+		issues = append(issues, issue)
+	}
+	for i := range issues {
+		if issues[i].ParentKey == "" {
+			issues[i].ParentKey = subtaskParents[issues[i].Key]
+		}
+	}
+	return issues, nil
+}
+
+// linearNodes decodes both GraphQL connections ({"nodes": [...]}) and plain
+// arrays, since Linear exports use either depending on the tool.
+type linearNodes[T any] []T
+
+func (n *linearNodes[T]) UnmarshalJSON(raw []byte) error {
+	var list []T
+	if err := json.Unmarshal(raw, &list); err == nil {
+		*n = list
+		return nil
+	}
+	var connection struct {
+		Nodes []T `json:"nodes"`
+	}
+	if err := json.Unmarshal(raw, &connection); err != nil {
+		return err
+	}
+	*n = connection.Nodes
+	return nil
+}
+
+type linearUserField struct {
+	Name        string `json:"name"`
+	DisplayName string `json:"displayName"`
+	Email       string `json:"email"`
+}
+
+func (u *linearUserField) label() string {
+	if u == nil {
+		return ""
+	}
+	for _, value := range []string{u.Name, u.DisplayName, u.Email} {
+		if strings.TrimSpace(value) != "" {
+			return strings.TrimSpace(value)
+		}
+	}
+	return ""
+}
+
+type linearIssueExport struct {
+	Identifier    string       `json:"identifier"`
+	Title         string       `json:"title"`
+	Description   string       `json:"description"`
+	Priority      *json.Number `json:"priority"`
+	PriorityLabel string       `json:"priorityLabel"`
+	State         *struct {
+		Name string `json:"name"`
+		Type string `json:"type"`
+	} `json:"state"`
+	Labels linearNodes[struct {
+		Name string `json:"name"`
+	}] `json:"labels"`
+	Assignee *linearUserField `json:"assignee"`
+	Parent   *struct {
+		Identifier string `json:"identifier"`
+	} `json:"parent"`
+	Comments linearNodes[struct {
+		ID        string           `json:"id"`
+		Body      string           `json:"body"`
+		CreatedAt string           `json:"createdAt"`
+		User      *linearUserField `json:"user"`
+	}] `json:"comments"`
+	CompletedAt string `json:"completedAt"`
+	CanceledAt  string `json:"canceledAt"`
+}
+
+func parseLinearIssueJSON(data []byte) ([]ExternalIssue, error) {
+	var envelope struct {
+		Data *struct {
+			Issues json.RawMessage `json:"issues"`
+		} `json:"data"`
+	}
+	if err := json.Unmarshal(data, &envelope); err == nil && envelope.Data != nil && len(envelope.Data.Issues) > 0 {
+		data = envelope.Data.Issues
+	}
+	var records []linearIssueExport
+	if err := decodeIssueExportList(data, &records, "issues", "nodes"); err != nil {
+		return nil, fmt.Errorf("invalid linear export: %w", err)
+	}
+	issues := make([]ExternalIssue, 0, len(records))
+	for _, record := range records {
+		issue := ExternalIssue{
+			Key:      record.Identifier,
+			Title:    record.Title,
+			Body:     record.Description,
+			Priority: record.PriorityLabel,
+			Assignee: record.Assignee.label(),
+		}
+		if issue.Priority == "" && record.Priority != nil {
+			issue.Priority = linearPriorityLabel(record.Priority.String())
+		}
+		if record.State != nil {
+			issue.Status = record.State.Name
+			issue.StatusCategory = record.State.Type
+		}
+		for _, label := range record.Labels {
+			issue.Labels = append(issue.Labels, label.Name)
+		}
+		if record.Parent != nil {
+			issue.ParentKey = strings.TrimSpace(record.Parent.Identifier)
+		}
+		for _, comment := range record.Comments {
+			issue.Comments = append(issue.Comments, ExternalIssueComment{
+				ID:        comment.ID,
+				Author:    comment.User.label(),
+				Body:      comment.Body,
+				CreatedAt: parseExternalIssueTime(comment.CreatedAt),
+			})
+		}
+		issue.ClosedAt = parseExternalIssueTime(record.CompletedAt)
+		if issue.ClosedAt == nil {
+			issue.ClosedAt = parseExternalIssueTime(record.CanceledAt)
+		}
+		issues = append(issues, issue)
+	}
+	return issues, nil
+}
+
+// linearPriorityLabel names Linear's numeric priorities (0 = none, 1 =
+// urgent ... 4 = low).
+func linearPriorityLabel(raw string) string {
+	switch strings.TrimSpace(raw) {
+	case "1":
+		return "Urgent"
+	case "2":
+		return "High"
+	case "3":
+		return "Medium"
+	case "4":
+		return "Low"
+	default:
+		return ""
+	}
+}
+
+// decodeIssueExportList accepts a bare array or an object holding the array
+// under one of keys.
+func decodeIssueExportList(data []byte, out any, keys ...string) error {
+	trimmed := bytes.TrimSpace(bytes.TrimPrefix(data, []byte("\xef\xbb\xbf")))
+	if len(trimmed) > 0 && trimmed[0] == '[' {
+		return json.Unmarshal(trimmed, out)
+	}
+	var object map[string]json.RawMessage
+	if err := json.Unmarshal(trimmed, &object); err != nil {
+		return err
+	}
+	for _, key := range keys {
+		if raw, ok := object[key]; ok {
+			return decodeIssueExportList(raw, out, keys...)
+		}
+	}
+	return fmt.Errorf("expected an array or an object with %q", keys[0])
+}
+
+// issueExportCSV indexes CSV headers case-insensitively and keeps every
+// column for repeated headers (Jira writes one "Labels" column per label).
+type issueExportCSV struct {
+	columns map[string][]int
+	rows    [][]string
+}
+
+func readIssueExportCSV(data []byte) (*issueExportCSV, error) {
+	reader := csv.NewReader(bytes.NewReader(bytes.TrimPrefix(data, []byte("\xef\xbb\xbf"))))
+	reader.FieldsPerRecord = -1
+	reader.LazyQuotes = true
+	header, err := reader.Read()
+	if err != nil {
+		if errors.Is(err, io.EOF) {
+			return nil, fmt.Errorf("csv export is empty")
+		}
+		return nil, fmt.Errorf("invalid csv export: %w", err)
+	}
+	parsed := &issueExportCSV{columns: make(map[string][]int, len(header))}
+	for i, name := range header {
+		key := strings.ToLower(strings.TrimSpace(name))
+		parsed.columns[key] = append(parsed.columns[key], i)
+	}
+	for {
+		row, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("invalid csv export: %w", err)
+		}
+		parsed.rows = append(parsed.rows, row)
+	}
+	return parsed, nil
+}
+
+func (c *issueExportCSV) has(column string) bool {
+	return len(c.columns[strings.ToLower(strings.TrimSpace(column))]) > 0
+}
+
+func (c *issueExportCSV) value(row []string, columns ...string) string {
+	for _, column := range columns {
+		for _, idx := range c.columns[strings.ToLower(strings.TrimSpace(column))] {
+			if idx < len(row) && strings.TrimSpace(row[idx]) != "" {
+				return strings.TrimSpace(row[idx])
+			}
+		}
+	}
+	return ""
+}
+
+func (c *issueExportCSV) values(row []string, column string) []string {
+	var out []string
+	for _, idx := range c.columns[strings.ToLower(strings.TrimSpace(column))] {
+		if idx < len(row) && strings.TrimSpace(row[idx]) != "" {
+			out = append(out, strings.TrimSpace(row[idx]))
+		}
+	}
+	return out
+}
+
+func parseJiraIssueCSV(data []byte) ([]ExternalIssue, error) {
+	parsed, err := readIssueExportCSV(data)
+	if err != nil {
+		return nil, err
+	}
+	if !parsed.has("issue key") || !parsed.has("summary") {
+		return nil, fmt.Errorf("jira csv export needs \"Issue key\" and \"Summary\" columns")
+	}
+	keysByID := make(map[string]string, len(parsed.rows))
+	for _, row := range parsed.rows {
+		if id := parsed.value(row, "issue id"); id != "" {
+			keysByID[id] = parsed.value(row, "issue key")
+		}
+	}
+	issues := make([]ExternalIssue, 0, len(parsed.rows))
+	for _, row := range parsed.rows {
+		issue := ExternalIssue{
+			Key:      parsed.value(row, "issue key"),
+			Title:    parsed.value(row, "summary"),
+			Body:     parsed.value(row, "description"),
+			Status:   parsed.value(row, "status"),
+			Priority: parsed.value(row, "priority"),
+			Labels:   parsed.values(row, "labels"),
+			Assignee: parsed.value(row, "assignee"),
+			ClosedAt: parseExternalIssueTime(parsed.value(row, "resolved")),
+		}
+		issue.StatusCategory = parsed.value(row, "status category")
+		parent := parsed.value(row, "parent key", "parent", "parent id")
+		if key, ok := keysByID[parent]; ok {
+			parent = key
+		}
+		issue.ParentKey = parent
+		for _, raw := range parsed.values(row, "comment") {
+			issue.Comments = append(issue.Comments, parseJiraCSVComment(raw))
+		}
+		issues = append(issues, issue)
+	}
+	return issues, nil
+}
+
+// parseJiraCSVComment splits Jira's "date;author;body" comment cells; cells
+// that do not start with a date are kept whole as the body.
+func parseJiraCSVComment(raw string) ExternalIssueComment {
+	parts := strings.SplitN(raw, ";", 3)
+	if len(parts) == 3 {
+		if created := parseExternalIssueTime(parts[0]); created != nil {
+			return ExternalIssueComment{CreatedAt: created, Author: strings.TrimSpace(parts[1]), Body: parts[2]}
+		}
+	}
+	return ExternalIssueComment{Body: raw}
+}
+
+func parseLinearIssueCSV(data []byte) ([]ExternalIssue, error) {
+	parsed, err := readIssueExportCSV(data)
+	if err != nil {
+		return nil, err
+	}
+	if !parsed.has("id") || !parsed.has("title") {
+		return nil, fmt.Errorf("linear csv export needs \"ID\" and \"Title\" columns")
+	}
+	issues := make([]ExternalIssue, 0, len(parsed.rows))
+	for _, row := range parsed.rows {
+		issue := ExternalIssue{
+			Key:       parsed.value(row, "id"),
+			Title:     parsed.value(row, "title"),
+			Body:      parsed.value(row, "description"),
+			Status:    parsed.value(row, "status"),
+			Priority:  parsed.value(row, "priority"),
+			Labels:    strings.Split(parsed.value(row, "labels"), ","),
+			Assignee:  parsed.value(row, "assignee"),
+			ParentKey: parsed.value(row, "parent issue", "parent"),
+		}
+		if completed := parseExternalIssueTime(parsed.value(row, "completed")); completed != nil {
+			issue.ClosedAt = completed
+			issue.StatusCategory = "completed"
+		} else if canceled := parseExternalIssueTime(parsed.value(row, "canceled")); canceled != nil {
+			issue.ClosedAt = canceled
+			issue.StatusCategory = "canceled"
+		}
+		issues = append(issues, issue)
+	}
+	return issues, nil
+}
+
+func parseGenericIssueCSV(data []byte, columns IssueImportColumnMap) ([]ExternalIssue, error) {
+	for field := range columns {
+		if !isIssueImportColumnField(field) {
+			return nil, fmt.Errorf("unknown column mapping field %q (use %s)", field, strings.Join(issueImportColumnFields, ", "))
+		}
+	}
+	parsed, err := readIssueExportCSV(data)
+	if err != nil {
+		return nil, err
+	}
+	column := func(field string) string {
+		if mapped := strings.TrimSpace(columns[field]); mapped != "" {
+			return mapped
+		}
+		return field
+	}
+	missing := []string{}
+	for _, field := range []string{"key", "title"} {
+		if !parsed.has(column(field)) {
+			missing = append(missing, strconv.Quote(column(field)))
+		}
+	}
+	for field, mapped := range columns {
+		if !parsed.has(mapped) {
+			missing = append(missing, fmt.Sprintf("%q (mapped from %s)", mapped, field))
+		}
+	}
+	if len(missing) > 0 {
+		sort.Strings(missing)
+		return nil, fmt.Errorf("csv export is missing columns: %s", strings.Join(missing, ", "))
+	}
+
+	issues := make([]ExternalIssue, 0, len(parsed.rows))
+	for _, row := range parsed.rows {
+		issues = append(issues, ExternalIssue{
+			Key:       parsed.value(row, column("key")),
+			Title:     parsed.value(row, column("title")),
+			Body:      parsed.value(row, column("body")),
+			Status:    parsed.value(row, column("status")),
+			Priority:  parsed.value(row, column("priority")),
+			Labels:    strings.FieldsFunc(parsed.value(row, column("labels")), func(r rune) bool { return r == ',' || r == ';' }),
+			Assignee:  parsed.value(row, column("assignee")),
+			ParentKey: parsed.value(row, column("parent")),
+			ClosedAt:  parseExternalIssueTime(parsed.value(row, column("closed_at"))),
+		})
+	}
+	return issues, nil
+}
+
+func isIssueImportColumnField(field string) bool {
+	for _, known := range issueImportColumnFields {
+		if field == known {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,62 @@
+package importer
+
+import "fmt"
+
+type IssueImportSummaryReport struct {
+	DryRun            bool     `json:"dry_run"`
+	IssuesProcessed   int      `json:"issues_processed"`
+	IssuesCreated     int      `json:"issues_created"`
+	IssuesUpdated     int      `json:"issues_updated"`
+	IssuesUnchanged   int      `json:"issues_unchanged"`
+	IssuesSkipped     int      `json:"issues_skipped"`
+	FailedItems       int      `json:"failed_items"`
+	ParentLinks       int      `json:"parent_links"`
+	LabelsApplied     int      `json:"labels_applied"`
+	CommentsImported  int      `json:"comments_imported"`
+	CommentsSkipped   int      `json:"comments_skipped"`
+	UnmappedStatuses  []string `json:"unmapped_statuses,omitempty"`
+	UnmappedAssignees []string `json:"unmapped_assignees,omitempty"`
+	Warnings          []string `json:"warnings,omitempty"`
+}
+
+func BuildIssueImportSummaryReport(result IssueImportResult) IssueImportSummaryReport {
+	report := IssueImportSummaryReport{
+		DryRun:            result.DryRun,
+		IssuesProcessed:   result.Total,
+		IssuesCreated:     result.Created,
+		IssuesUpdated:     result.Updated,
+		IssuesUnchanged:   result.Unchanged,
+		IssuesSkipped:     result.Skipped,
+		FailedItems:       result.Failed,
+		ParentLinks:       result.ParentLinks,
+		LabelsApplied:     result.LabelsApplied,
+		CommentsImported:  result.CommentsImported,
+		CommentsSkipped:   result.CommentsSkipped,
+		UnmappedStatuses:  SortedIssueImportCounts(result.UnmappedStatuses),
+		UnmappedAssignees: SortedIssueImportCounts(result.UnmappedAssignees),
+	}
+
+	if len(report.UnmappedStatuses) > 0 {
+		report.Warnings = append(
+			report.Warnings,
+			fmt.Sprintf("%d status(es) were not recognised and imported as queued; map them with a status override", len(report.UnmappedStatuses)),
+		)
+	}
+	if len(report.UnmappedAssignees) > 0 {
+		report.Warnings = append(
+			report.Warnings,
+			fmt.Sprintf("%d assignee(s) did not match an agent and were imported without an owner", len(report.UnmappedAssignees)),
+		)
+	}
+	if result.Skipped > 0 {
+		report.Warnings = append(report.Warnings, fmt.Sprintf("%d record(s) skipped for missing or duplicate keys or titles", result.Skipped))
+	}
+	if result.Failed > 0 {
+		report.Warnings = append(report.Warnings, fmt.Sprintf("%d record(s) failed to import", result.Failed))
+	}
+	report.Warnings = append(report.Warnings, result.Warnings...)
+	if result.DryRun {
+		report.Warnings = append(report.Warnings, "dry run: no issues, labels or comments were written")
+	}
+	return report
+}
@@ -0,0 +1,273 @@
+package importer
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"github.com/samhotchkiss/otter-camp/internal/middleware"
+	"github.com/samhotchkiss/otter-camp/internal/store"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseIssueExportJiraJSON(t *testing.T) {
+	data := []byte(`{"issues":[
+		{"key":"OPS-1","fields":{
+			"summary":" Migrate billing ",
+			"description":{"type":"doc","content":[{"type":"paragraph","content":[{"type":"text","text":"Move to the new provider."}]}]},
+			"status":{"name":"In Review","statusCategory":{"key":"indeterminate"}},
+			"priority":{"name":"High"},
+			"labels":["billing","billing","q4"],
+			"assignee":{"displayName":"Jane Doe","emailAddress":"jane@example.com"},
+			"comment":{"comments":[{"id":"100","author":{"displayName":"Sam"},"body":"Started.","created":"2026-09-01T10:00:00.000+0000"}]}
+		}},
+		{"key":"OPS-2","fields":{
+			"summary":"Update invoices",
+			"status":{"name":"Done","statusCategory":{"key":"done"}},
+			"parent":{"key":"OPS-1"},
+			"resolutiondate":"2026-09-03T12:00:00.000+0000",
+			"comment":{"comments":[{"body":"Shipped."}]}
+		}}
+	]}`)
+
+	issues, err := ParseIssueExport("jira", "", data, nil)
+	require.NoError(t, err)
+	require.Len(t, issues, 2)
+
+	first := issues[0]
+	require.Equal(t, "OPS-1", first.Key)
+	require.Equal(t, "Migrate billing", first.Title)
+	require.Equal(t, "Move to the new provider.", first.Body)
+	require.Equal(t, "In Review", first.Status)
+	require.Equal(t, "indeterminate", first.StatusCategory)
+	require.Equal(t, "High", first.Priority)
+	require.Equal(t, []string{"billing", "q4"}, first.Labels)
+	require.Equal(t, "Jane Doe", first.Assignee)
+	require.Len(t, first.Comments, 1)
+	require.Equal(t, "100", first.Comments[0].ID)
+	require.Equal(t, "Sam", first.Comments[0].Author)
+	require.NotNil(t, first.Comments[0].CreatedAt)
+
+	second := issues[1]
+	require.Equal(t, "OPS-1", second.ParentKey)
+	require.NotNil(t, second.ClosedAt)
+	require.Len(t, second.Comments, 1)
+	require.NotEmpty(t, second.Comments[0].ID)
+
+	again, err := ParseIssueExport("jira", "json", data, nil)
+	require.NoError(t, err)
+	require.Equal(t, second.Comments[0].ID, again[1].Comments[0].ID)
+}
+
+func TestParseIssueExportCSVSources(t *testing.T) {
+	jira := []byte("Issue key,Issue id,Summary,Status,Priority,Labels,Labels,Assignee,Parent id,Comment\n" +
+		"OPS-1,10001,Migrate billing,To Do,Medium,billing,q4,Jane Doe,,01/Sep/26 10:00 AM;Sam;Kickoff notes\n" +
+		"OPS-2,10002,Update invoices,In Progress,Low,,,,10001,\n")
+	issues, err := ParseIssueExport("jira", "csv", jira, nil)
+	require.NoError(t, err)
+	require.Len(t, issues, 2)
+	require.Equal(t, []string{"billing", "q4"}, issues[0].Labels)
+	require.Len(t, issues[0].Comments, 1)
+	require.Equal(t, "Sam", issues[0].Comments[0].Author)
+	require.Equal(t, "Kickoff notes", issues[0].Comments[0].Body)
+	require.Equal(t, "OPS-1", issues[1].ParentKey)
+
+	linear := []byte("ID,Title,Description,Status,Priority,Labels,Assignee,Parent issue,Completed\n" +
+		"ENG-1,Ship search,Index docs,Done,Urgent,\"search, backend\",jane@example.com,,2026-09-02T00:00:00Z\n" +
+		"ENG-2,Tune ranking,,Backlog,No priority,,,ENG-1,\n")
+	issues, err = ParseIssueExport("linear", "", linear, nil)
+	require.NoError(t, err)
+	require.Len(t, issues, 2)
+	require.Equal(t, []string{"search", "backend"}, issues[0].Labels)
+	require.Equal(t, "completed", issues[0].StatusCategory)
+	require.NotNil(t, issues[0].ClosedAt)
+	require.Equal(t, "ENG-1", issues[1].ParentKey)
+
+	generic := []byte("Ref,Name,State,Tags\nT-1,Write docs,Open,docs;help\n")
+	issues, err = ParseIssueExport("csv", "csv", generic, IssueImportColumnMap{
+		"key": "Ref", "title": "Name", "status": "State", "labels": "Tags",
+	})
+	require.NoError(t, err)
+	require.Len(t, issues, 1)
+	require.Equal(t, "T-1", issues[0].Key)
+	require.Equal(t, []string{"docs", "help"}, issues[0].Labels)
+
+	_, err = ParseIssueExport("csv", "csv", generic, IssueImportColumnMap{"key": "Missing"})
+	require.Error(t, err)
+	_, err = ParseIssueExport("csv", "csv", generic, IssueImportColumnMap{"estimate": "Points"})
+	require.Error(t, err)
+	_, err = ParseIssueExport("jira", "csv", jira, IssueImportColumnMap{"key": "Issue key"})
+	require.Error(t, err)
+}
+
+func TestParseIssueExportLinearJSON(t *testing.T) {
+	data := []byte(`{"data":{"issues":{"nodes":[
+		{"identifier":"ENG-7","title":"Fix login","description":"Steps...","priority":2,
+		 "state":{"name":"In Progress","type":"started"},
+		 "labels":{"nodes":[{"name":"bug"}]},
+		 "assignee":{"name":"Jane Doe"},
+		 "parent":{"identifier":"ENG-1"},
+		 "comments":{"nodes":[{"id":"c-1","body":"Repro attached","user":{"name":"Sam"}}]}}
+	]}}}`)
+	issues, err := ParseIssueExport("linear", "", data, nil)
+	require.NoError(t, err)
+	require.Len(t, issues, 1)
+	issue := issues[0]
+	require.Equal(t, "ENG-7", issue.Key)
+	require.Equal(t, "High", issue.Priority)
+	require.Equal(t, "started", issue.StatusCategory)
+	require.Equal(t, []string{"bug"}, issue.Labels)
+	require.Equal(t, "ENG-1", issue.ParentKey)
+	require.Len(t, issue.Comments, 1)
+	require.Equal(t, "c-1", issue.Comments[0].ID)
+}
+
+func TestMapExternalIssueStatusAndPriority(t *testing.T) {
+	cases := []struct {
+		status, category, wantStatus, wantState string
+		wantOK                                   bool
+	}{
+		{"To Do", "new", store.IssueWorkStatusQueued, "open", true},
+		{"In  Progress", "", store.IssueWorkStatusInProgress, "open", true},
+		{"QA", "", store.IssueWorkStatusReview, "open", true},
+		{"Won't Do", "done", store.IssueWorkStatusCancelled, "closed", true},
+		{"Shipped to prod", "done", store.IssueWorkStatusDone, "closed", true},
+		{"Waiting on vendor", "", store.IssueWorkStatusQueued, "open", false},
+		{"Design", "", store.IssueWorkStatusReview, "open", true},
+	}
+	overrides := map[string]string{"design": store.IssueWorkStatusReview}
+	for _, tc := range cases {
+		workStatus, state, ok := MapExternalIssueStatus(tc.status, tc.category, overrides)
+		require.Equal(t, tc.wantStatus, workStatus, tc.status)
+		require.Equal(t, tc.wantState, state, tc.status)
+		require.Equal(t, tc.wantOK, ok, tc.status)
+	}
+
+	require.Equal(t, store.IssuePriorityP0, MapExternalIssuePriority("Blocker"))
+	require.Equal(t, store.IssuePriorityP1, MapExternalIssuePriority("major"))
+	require.Equal(t, store.IssuePriorityP2, MapExternalIssuePriority(""))
+	require.Equal(t, store.IssuePriorityP3, MapExternalIssuePriority("Lowest"))
+
+	_, err := normalizeIssueImportStatusMap(map[string]string{"Design": "thinking"})
+	require.Error(t, err)
+}
+
+func TestBuildIssueImportSummaryReport(t *testing.T) {
+	report := BuildIssueImportSummaryReport(IssueImportResult{
+		DryRun:            true,
+		Total:             4,
+		Created:           2,
+		Skipped:           1,
+		Failed:            1,
+		UnmappedStatuses:  map[string]int{"Waiting": 1, "Parked": 3},
+		UnmappedAssignees: map[string]int{"Jane": 2},
+		Warnings:          []string{"OPS-2: parent OPS-9 is not in this export or a previous import"},
+	})
+	require.Equal(t, 4, report.IssuesProcessed)
+	require.Equal(t, []string{"Parked", "Waiting"}, report.UnmappedStatuses)
+	require.Equal(t, []string{"Jane"}, report.UnmappedAssignees)
+	require.Len(t, report.Warnings, 6)
+	require.Contains(t, report.Warnings[len(report.Warnings)-1], "dry run")
+}
+
+func TestIssueImportRunnerDryRunThenIdempotentImport(t *testing.T) {
+	connStr := getOpenClawImportTestDatabaseURL(t)
+	db := setupOpenClawImportTestDatabase(t, connStr)
+	orgID := createOpenClawImportTestOrganization(t, db, "issue-import-runner")
+	projectID := createIssueImportTestProject(t, db, orgID, "Imported Backlog")
+	agentID := createIssueImportTestAgent(t, db, orgID, "analyst-1", "Analyst")
+
+	issues := []ExternalIssue{
+		{Key: "OPS-2", Title: "Update invoices", Status: "Done", StatusCategory: "done", ParentKey: "OPS-1",
+			Comments: []ExternalIssueComment{{ID: "200", Author: "Jane Doe", Body: "Shipped."}}},
+		{Key: "OPS-1", Title: "Migrate billing", Status: "Waiting on vendor", Priority: "High",
+			Labels: []string{"billing"}, Assignee: "Jane Doe"},
+		{Key: "", Title: "No key"},
+	}
+	runner := NewIssueImportRunner(db)
+	input := RunIssueImportInput{
+		OrgID:         orgID,
+		ProjectID:     projectID,
+		Source:        "jira",
+		Issues:        issues,
+		AssigneeMap:   map[string]string{"Jane Doe": "analyst-1"},
+		CommentAuthor: "analyst-1",
+		DryRun:        true,
+	}
+
+	dryRun, err := runner.Run(context.Background(), input)
+	require.NoError(t, err)
+	require.Equal(t, 2, dryRun.Created)
+	require.Equal(t, 1, dryRun.Skipped)
+	require.Equal(t, 1, dryRun.ParentLinks)
+	require.Equal(t, 1, dryRun.CommentsImported)
+	require.Equal(t, map[string]int{"Waiting on vendor": 1}, dryRun.UnmappedStatuses)
+	require.Equal(t, 0, countIssueImportTestIssues(t, db, projectID))
+
+	input.DryRun = false
+	first, err := runner.Run(context.Background(), input)
+	require.NoError(t, err)
+	require.Equal(t, 2, first.Created)
+	require.Equal(t, 1, first.ParentLinks)
+	require.Equal(t, 1, first.LabelsApplied)
+	require.Equal(t, 1, first.CommentsImported)
+	require.Empty(t, first.Warnings)
+	require.Equal(t, 2, countIssueImportTestIssues(t, db, projectID))
+
+	ctx := context.WithValue(context.Background(), middleware.WorkspaceIDKey, orgID)
+	imported, err := store.NewIssueImportStore(db).ListImportedIssues(ctx, projectID, "jira")
+	require.NoError(t, err)
+	parent, child := imported["OPS-1"], imported["OPS-2"]
+	require.Equal(t, store.IssuePriorityP1, parent.Priority)
+	require.Equal(t, agentID, *parent.OwnerAgentID)
+	require.Equal(t, "closed", child.State)
+	require.Equal(t, store.IssueWorkStatusDone, child.WorkStatus)
+	require.NotNil(t, child.ParentIssueID)
+	require.Equal(t, parent.ID, *child.ParentIssueID)
+
+	second, err := runner.Run(context.Background(), input)
+	require.NoError(t, err)
+	require.Equal(t, 0, second.Created)
+	require.Equal(t, 2, second.Unchanged)
+	require.Equal(t, 0, second.ParentLinks)
+	require.Equal(t, 1, second.CommentsExisting)
+	require.Equal(t, 2, countIssueImportTestIssues(t, db, projectID))
+
+	input.Issues[1].Priority = "Low"
+	third, err := runner.Run(context.Background(), input)
+	require.NoError(t, err)
+	require.Equal(t, 1, third.Updated)
+	require.Equal(t, 1, third.Unchanged)
+}
+
+func createIssueImportTestProject(t *testing.T, db *sql.DB, orgID, name string) string {
+	t.Helper()
+	var projectID string
+	err := db.QueryRow(
+		`INSERT INTO projects (org_id, name, status) VALUES ($1, $2, 'active') RETURNING id`,
+		orgID,
+		name,
+	).Scan(&projectID)
+	require.NoError(t, err)
+	return projectID
+}
+
+func createIssueImportTestAgent(t *testing.T, db *sql.DB, orgID, slug, displayName string) string {
+	t.Helper()
+	var agentID string
+	err := db.QueryRow(
+		`INSERT INTO agents (org_id, slug, display_name, status) VALUES ($1, $2, $3, 'active') RETURNING id`,
+		orgID,
+		slug,
+		displayName,
+	).Scan(&agentID)
+	require.NoError(t, err)
+	return agentID
+}
+
+func countIssueImportTestIssues(t *testing.T, db *sql.DB, projectID string) int {
+	t.Helper()
+	var count int
+	require.NoError(t, db.QueryRow(`SELECT COUNT(*) FROM project_issues WHERE project_id = $1`, projectID).Scan(&count))
+	return count
+}
@@ -6,7 +6,9 @@ import (
 	"log"
 	"time"
 
+	"github.com/samhotchkiss/otter-camp/internal/metrics"
 	"github.com/samhotchkiss/otter-camp/internal/store"
+	"github.com/samhotchkiss/otter-camp/internal/tracing"
 )
 
 const (
@@ -22,6 +24,13 @@ type ConversationEmbeddingQueue interface {
 	UpdateMemoryEmbedding(ctx context.Context, memoryID string, embedding []float64) error
 }
 
+// IssueEmbeddingQueue is implemented by queues that also embed project issue
+// titles and bodies for duplicate detection.
+type IssueEmbeddingQueue interface {
+	ListPendingIssues(ctx context.Context, limit int) ([]store.PendingIssueEmbedding, error)
+	UpdateIssueEmbedding(ctx context.Context, issueID string, embedding []float64) error
+}
+
 type ConversationEmbeddingWorkerConfig struct {
 	BatchSize    int
 	PollInterval time.Duration
@@ -70,7 +79,11 @@ func (w *ConversationEmbeddingWorker) Start(ctx context.Context) {
 			return
 		}
 
-		processed, err := w.RunOnce(ctx)
+		started := time.Now()
+		runCtx, span := tracing.StartWorkerRun(ctx, "conversation_embedding")
+		processed, err := w.RunOnce(runCtx)
+		tracing.EndWorkerRun(span, processed > 0, err)
+		metrics.ObserveWorkerRun("conversation_embedding", time.Since(started), err)
 		if err != nil {
 			consecutiveFailures += 1
 			if w.Logf != nil {
@@ -165,6 +178,34 @@ func (w *ConversationEmbeddingWorker) RunOnce(ctx context.Context) (int, error)
 		}
 	}
 
+	issueQueue, ok := w.Queue.(IssueEmbeddingQueue)
+	if !ok {
+		return processed, nil
+	}
+	issues, err := issueQueue.ListPendingIssues(ctx, w.BatchSize)
+	if err != nil {
+		return processed, fmt.Errorf("list pending issue embeddings: %w", err)
+	}
+	if len(issues) > 0 {
+		inputs := make([]string, 0, len(issues))
+		for _, row := range issues {
+			inputs = append(inputs, row.Text)
+		}
+		vectors, err := w.Embedder.Embed(ctx, inputs)
+		if err != nil {
+			return processed, fmt.Errorf("embed issues: %w", err)
+		}
+		if len(vectors) != len(issues) {
+			return processed, fmt.Errorf("embed issues returned %d vectors for %d rows", len(vectors), len(issues))
+		}
+		for i, row := range issues {
+			if err := issueQueue.UpdateIssueEmbedding(ctx, row.ID, vectors[i]); err != nil {
+				return processed, fmt.Errorf("update issue embedding %s: %w", row.ID, err)
+			}
+			processed += 1
+		}
+	}
+
 	return processed, nil
 }
 
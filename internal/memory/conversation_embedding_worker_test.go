@@ -144,6 +144,53 @@ func TestConversationEmbeddingWorkerProcessesPendingRows(t *testing.T) {
 	require.Equal(t, []float64{0.1, 0.2, 0.3}, queue.updatedMemoryEmbeddings["memory-1"])
 }
 
+type fakeIssueEmbeddingQueue struct {
+	*fakeConversationEmbeddingQueue
+
+	pendingIssues          []store.PendingIssueEmbedding
+	updatedIssueEmbeddings map[string][]float64
+}
+
+func (f *fakeIssueEmbeddingQueue) ListPendingIssues(_ context.Context, _ int) ([]store.PendingIssueEmbedding, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	out := make([]store.PendingIssueEmbedding, 0, len(f.pendingIssues))
+	for _, row := range f.pendingIssues {
+		if _, done := f.updatedIssueEmbeddings[row.ID]; !done {
+			out = append(out, row)
+		}
+	}
+	return out, nil
+}
+
+func (f *fakeIssueEmbeddingQueue) UpdateIssueEmbedding(_ context.Context, issueID string, embedding []float64) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.updatedIssueEmbeddings[issueID] = append([]float64(nil), embedding...)
+	return nil
+}
+
+func TestConversationEmbeddingWorkerEmbedsIssuesWhenQueueSupportsThem(t *testing.T) {
+	queue := &fakeIssueEmbeddingQueue{
+		fakeConversationEmbeddingQueue: newFakeConversationEmbeddingQueue(nil, nil),
+		pendingIssues:                  []store.PendingIssueEmbedding{{ID: "issue-1", Text: "Login fails\n\nSteps"}},
+		updatedIssueEmbeddings:         make(map[string][]float64),
+	}
+	embedder := &fakeConversationEmbedder{vector: []float64{0.4, 0.5}}
+
+	worker := NewConversationEmbeddingWorker(queue, embedder, ConversationEmbeddingWorkerConfig{BatchSize: 10, PollInterval: 1})
+	worker.Logf = nil
+
+	processed, err := worker.RunOnce(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, 1, processed)
+	require.Equal(t, []float64{0.4, 0.5}, queue.updatedIssueEmbeddings["issue-1"])
+
+	processed, err = worker.RunOnce(context.Background())
+	require.NoError(t, err)
+	require.Zero(t, processed)
+}
+
 func TestConversationEmbeddingWorkerRetriesAndContinues(t *testing.T) {
 	queue := newFakeConversationEmbeddingQueue(
 		[]store.PendingChatMessageEmbedding{{ID: "chat-1", Body: "chat body"}},
@@ -8,7 +8,9 @@ import (
 	"strings"
 	"time"
 
+	"github.com/samhotchkiss/otter-camp/internal/metrics"
 	"github.com/samhotchkiss/otter-camp/internal/store"
+	"github.com/samhotchkiss/otter-camp/internal/tracing"
 )
 
 const (
@@ -71,7 +73,11 @@ func (w *ConversationSegmentationWorker) Start(ctx context.Context) {
 			return
 		}
 
-		processed, err := w.RunOnce(ctx)
+		started := time.Now()
+		runCtx, span := tracing.StartWorkerRun(ctx, "conversation_segmentation")
+		processed, err := w.RunOnce(runCtx)
+		tracing.EndWorkerRun(span, processed > 0, err)
+		metrics.ObserveWorkerRun("conversation_segmentation", time.Since(started), err)
 		if err != nil {
 			if w.Logf != nil {
 				w.Logf("conversation segmentation worker run failed: %v", err)
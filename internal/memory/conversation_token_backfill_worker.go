@@ -5,6 +5,9 @@ import (
 	"fmt"
 	"log"
 	"time"
+
+	"github.com/samhotchkiss/otter-camp/internal/metrics"
+	"github.com/samhotchkiss/otter-camp/internal/tracing"
 )
 
 const (
@@ -58,7 +61,11 @@ func (w *ConversationTokenBackfillWorker) Start(ctx context.Context) {
 			return
 		}
 
-		processed, err := w.RunOnce(ctx)
+		started := time.Now()
+		runCtx, span := tracing.StartWorkerRun(ctx, "conversation_token_backfill")
+		processed, err := w.RunOnce(runCtx)
+		tracing.EndWorkerRun(span, processed > 0, err)
+		metrics.ObserveWorkerRun("conversation_token_backfill", time.Since(started), err)
 		if err != nil {
 			if w.Logf != nil {
 				w.Logf("conversation token backfill worker run failed: %v", err)
@@ -9,7 +9,9 @@ import (
 	"strings"
 	"time"
 
+	"github.com/samhotchkiss/otter-camp/internal/metrics"
 	"github.com/samhotchkiss/otter-camp/internal/store"
+	"github.com/samhotchkiss/otter-camp/internal/tracing"
 )
 
 const (
@@ -106,7 +108,11 @@ func (w *EllieContextInjectionWorker) Start(ctx context.Context) {
 		if err := ctx.Err(); err != nil {
 			return
 		}
-		processed, err := w.RunOnce(ctx)
+		started := time.Now()
+		runCtx, span := tracing.StartWorkerRun(ctx, "ellie_context_injection")
+		processed, err := w.RunOnce(runCtx)
+		tracing.EndWorkerRun(span, processed > 0, err)
+		metrics.ObserveWorkerRun("ellie_context_injection", time.Since(started), err)
 		if err != nil && w.Logf != nil {
 			w.Logf("ellie context injection worker run failed: %v", err)
 		}
@@ -12,7 +12,9 @@ import (
 	"strings"
 	"time"
 
+	"github.com/samhotchkiss/otter-camp/internal/metrics"
 	"github.com/samhotchkiss/otter-camp/internal/store"
+	"github.com/samhotchkiss/otter-camp/internal/tracing"
 	"github.com/samhotchkiss/otter-camp/internal/ws"
 )
 
@@ -195,7 +197,11 @@ func (w *EllieIngestionWorker) Start(ctx context.Context) {
 			}
 			continue
 		}
-		result, err := w.RunOnce(ctx)
+		started := time.Now()
+		runCtx, span := tracing.StartWorkerRun(ctx, "ellie_ingestion")
+		result, err := w.RunOnce(runCtx)
+		tracing.EndWorkerRun(span, result.ProcessedMessages > 0, err)
+		metrics.ObserveWorkerRun("ellie_ingestion", time.Since(started), err)
 		if err != nil {
 			if w.Logf != nil {
 				w.Logf("ellie ingestion worker run failed: %v", err)
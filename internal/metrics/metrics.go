@@ -0,0 +1,265 @@
+// Package metrics is a small in-process registry that renders counters,
+// gauges and histograms in the Prometheus text exposition format.
+package metrics
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+type Kind string
+
+const (
+	KindCounter   Kind = "counter"
+	KindGauge     Kind = "gauge"
+	KindHistogram Kind = "histogram"
+)
+
+// DefaultBuckets suits request and worker latencies in seconds.
+var DefaultBuckets = []float64{0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30, 60}
+
+// Sample is one series reported by a collect function.
+type Sample struct {
+	Labels []string
+	Value  float64
+}
+
+type family struct {
+	name    string
+	help    string
+	kind    Kind
+	labels  []string
+	buckets []float64
+
+	mu      sync.Mutex
+	series  map[string]*series
+	collect func() []Sample
+}
+
+type series struct {
+	labels  []string
+	value   float64
+	counts  []uint64
+	sum     float64
+	samples uint64
+}
+
+// Registry holds metric families keyed by name.
+type Registry struct {
+	mu       sync.RWMutex
+	families map[string]*family
+}
+
+func NewRegistry() *Registry {
+	return &Registry{families: make(map[string]*family)}
+}
+
+// Default is the registry served by Handler.
+var Default = NewRegistry()
+
+// CounterVec is a monotonically increasing value per label combination.
+type CounterVec struct{ f *family }
+
+// GaugeVec is a settable value per label combination.
+type GaugeVec struct{ f *family }
+
+// HistogramVec buckets observations per label combination.
+type HistogramVec struct{ f *family }
+
+func (r *Registry) NewCounterVec(name, help string, labels ...string) *CounterVec {
+	return &CounterVec{f: r.register(name, help, KindCounter, labels, nil, nil)}
+}
+
+func (r *Registry) NewGaugeVec(name, help string, labels ...string) *GaugeVec {
+	return &GaugeVec{f: r.register(name, help, KindGauge, labels, nil, nil)}
+}
+
+func (r *Registry) NewHistogramVec(name, help string, buckets []float64, labels ...string) *HistogramVec {
+	if len(buckets) == 0 {
+		buckets = DefaultBuckets
+	}
+	sorted := append([]float64(nil), buckets...)
+	sort.Float64s(sorted)
+	return &HistogramVec{f: r.register(name, help, KindHistogram, labels, sorted, nil)}
+}
+
+// RegisterFunc adds a counter or gauge family whose series are produced by
+// collect at scrape time. Registering the same name again replaces it.
+func (r *Registry) RegisterFunc(name, help string, kind Kind, labels []string, collect func() []Sample) {
+	r.register(name, help, kind, labels, nil, collect)
+}
+
+func (r *Registry) register(name, help string, kind Kind, labels []string, buckets []float64, collect func() []Sample) *family {
+	f := &family{
+		name:    name,
+		help:    help,
+		kind:    kind,
+		labels:  labels,
+		buckets: buckets,
+		series:  make(map[string]*series),
+		collect: collect,
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if existing, ok := r.families[name]; ok && collect == nil {
+		return existing
+	}
+	r.families[name] = f
+	return f
+}
+
+func (c *CounterVec) Add(delta float64, labelValues ...string) {
+	if delta < 0 {
+		return
+	}
+	c.f.with(labelValues, func(s *series) { s.value += delta })
+}
+
+func (c *CounterVec) Inc(labelValues ...string) {
+	c.Add(1, labelValues...)
+}
+
+func (g *GaugeVec) Set(value float64, labelValues ...string) {
+	g.f.with(labelValues, func(s *series) { s.value = value })
+}
+
+func (h *HistogramVec) Observe(value float64, labelValues ...string) {
+	h.f.with(labelValues, func(s *series) {
+		if s.counts == nil {
+			s.counts = make([]uint64, len(h.f.buckets))
+		}
+		for i, bound := range h.f.buckets {
+			if value <= bound {
+				s.counts[i]++
+			}
+		}
+		s.sum += value
+		s.samples++
+	})
+}
+
+func (f *family) with(labelValues []string, update func(*series)) {
+	values := make([]string, len(f.labels))
+	copy(values, labelValues)
+	key := strings.Join(values, "\xff")
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	s, ok := f.series[key]
+	if !ok {
+		s = &series{labels: values}
+		f.series[key] = s
+	}
+	update(s)
+}
+
+// WriteText renders every family in the Prometheus text format, sorted by
+// metric name and then by label values.
+func (r *Registry) WriteText(w io.Writer) error {
+	r.mu.RLock()
+	families := make([]*family, 0, len(r.families))
+	for _, f := range r.families {
+		families = append(families, f)
+	}
+	r.mu.RUnlock()
+	sort.Slice(families, func(i, j int) bool { return families[i].name < families[j].name })
+
+	out := bufio.NewWriter(w)
+	for _, f := range families {
+		f.write(out)
+	}
+	return out.Flush()
+}
+
+func (f *family) write(out *bufio.Writer) {
+	var snapshot []series
+	if f.collect != nil {
+		for _, sample := range f.collect() {
+			values := make([]string, len(f.labels))
+			copy(values, sample.Labels)
+			snapshot = append(snapshot, series{labels: values, value: sample.Value})
+		}
+	} else {
+		f.mu.Lock()
+		for _, s := range f.series {
+			copied := *s
+			copied.counts = append([]uint64(nil), s.counts...)
+			snapshot = append(snapshot, copied)
+		}
+		f.mu.Unlock()
+	}
+	sort.Slice(snapshot, func(i, j int) bool {
+		return strings.Join(snapshot[i].labels, "\xff") < strings.Join(snapshot[j].labels, "\xff")
+	})
+
+	fmt.Fprintf(out, "# HELP %s %s\n", f.name, escapeHelp(f.help))
+	fmt.Fprintf(out, "# TYPE %s %s\n", f.name, f.kind)
+	for _, s := range snapshot {
+		if f.kind != KindHistogram {
+			fmt.Fprintf(out, "%s%s %s\n", f.name, formatLabels(f.labels, s.labels, "", ""), formatValue(s.value))
+			continue
+		}
+		for i, bound := range f.buckets {
+			var count uint64
+			if i < len(s.counts) {
+				count = s.counts[i]
+			}
+			fmt.Fprintf(out, "%s_bucket%s %d\n", f.name, formatLabels(f.labels, s.labels, "le", formatValue(bound)), count)
+		}
+		fmt.Fprintf(out, "%s_bucket%s %d\n", f.name, formatLabels(f.labels, s.labels, "le", "+Inf"), s.samples)
+		fmt.Fprintf(out, "%s_sum%s %s\n", f.name, formatLabels(f.labels, s.labels, "", ""), formatValue(s.sum))
+		fmt.Fprintf(out, "%s_count%s %d\n", f.name, formatLabels(f.labels, s.labels, "", ""), s.samples)
+	}
+}
+
+func formatLabels(names, values []string, extraName, extraValue string) string {
+	if len(names) == 0 && extraName == "" {
+		return ""
+	}
+	parts := make([]string, 0, len(names)+1)
+	for i, name := range names {
+		parts = append(parts, name+`="`+escapeLabel(values[i])+`"`)
+	}
+	if extraName != "" {
+		parts = append(parts, extraName+`="`+extraValue+`"`)
+	}
+	return "{" + strings.Join(parts, ",") + "}"
+}
+
+func formatValue(value float64) string {
+	switch {
+	case math.IsInf(value, 1):
+		return "+Inf"
+	case math.IsInf(value, -1):
+		return "-Inf"
+	case math.IsNaN(value):
+		return "NaN"
+	}
+	return strconv.FormatFloat(value, 'g', -1, 64)
+}
+
+var (
+	labelEscaper = strings.NewReplacer(`\`, `\\`, "\n", `\n`, `"`, `\"`)
+	helpEscaper  = strings.NewReplacer(`\`, `\\`, "\n", `\n`)
+)
+
+func escapeLabel(value string) string { return labelEscaper.Replace(value) }
+
+func escapeHelp(value string) string { return helpEscaper.Replace(value) }
+
+// Handler serves the Default registry.
+func Handler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+		if err := Default.WriteText(w); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+		}
+	})
+}
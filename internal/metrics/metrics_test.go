@@ -0,0 +1,88 @@
+package metrics
+
+import (
+	"bytes"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRegistryWritesPrometheusText(t *testing.T) {
+	registry := NewRegistry()
+	requests := registry.NewCounterVec("test_requests_total", "Requests served.", "route")
+	requests.Inc("/b")
+	requests.Add(2, "/a")
+	requests.Add(-1, "/a")
+	registry.NewGaugeVec("test_depth", "Queue depth.", "queue").Set(3, `we"ird`)
+	latency := registry.NewHistogramVec("test_latency_seconds", "Latency.", []float64{1, 0.1}, "route")
+	latency.Observe(0.05, "/a")
+	latency.Observe(0.5, "/a")
+	registry.RegisterFunc("test_clients", "Clients.", KindGauge, nil, func() []Sample {
+		return []Sample{{Value: 7}}
+	})
+
+	var out bytes.Buffer
+	if err := registry.WriteText(&out); err != nil {
+		t.Fatalf("WriteText() error = %v", err)
+	}
+	want := strings.Join([]string{
+		"# HELP test_clients Clients.",
+		"# TYPE test_clients gauge",
+		"test_clients 7",
+		"# HELP test_depth Queue depth.",
+		"# TYPE test_depth gauge",
+		`test_depth{queue="we\"ird"} 3`,
+		"# HELP test_latency_seconds Latency.",
+		"# TYPE test_latency_seconds histogram",
+		`test_latency_seconds_bucket{route="/a",le="0.1"} 1`,
+		`test_latency_seconds_bucket{route="/a",le="1"} 2`,
+		`test_latency_seconds_bucket{route="/a",le="+Inf"} 2`,
+		`test_latency_seconds_sum{route="/a"} 0.55`,
+		`test_latency_seconds_count{route="/a"} 2`,
+		"# HELP test_requests_total Requests served.",
+		"# TYPE test_requests_total counter",
+		`test_requests_total{route="/a"} 2`,
+		`test_requests_total{route="/b"} 1`,
+		"",
+	}, "\n")
+	if out.String() != want {
+		t.Fatalf("unexpected output:\n%s", out.String())
+	}
+}
+
+func TestRegisterFuncReplacesExistingFamily(t *testing.T) {
+	registry := NewRegistry()
+	registry.RegisterFunc("test_bridge", "Bridge.", KindGauge, nil, func() []Sample { return []Sample{{Value: 0}} })
+	registry.RegisterFunc("test_bridge", "Bridge.", KindGauge, nil, func() []Sample { return []Sample{{Value: 1}} })
+
+	var out bytes.Buffer
+	if err := registry.WriteText(&out); err != nil {
+		t.Fatalf("WriteText() error = %v", err)
+	}
+	if !strings.Contains(out.String(), "test_bridge 1\n") || strings.Contains(out.String(), "test_bridge 0\n") {
+		t.Fatalf("unexpected output:\n%s", out.String())
+	}
+}
+
+func TestObserveWorkerRunCountsErrorsAndHandlerServesDefault(t *testing.T) {
+	ObserveWorkerRun("test_worker", 20*time.Millisecond, nil)
+	ObserveWorkerRun("test_worker", 30*time.Millisecond, errors.New("boom"))
+
+	rec := httptest.NewRecorder()
+	Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	if !strings.HasPrefix(rec.Header().Get("Content-Type"), "text/plain; version=0.0.4") {
+		t.Fatalf("content type = %q", rec.Header().Get("Content-Type"))
+	}
+	body := rec.Body.String()
+	for _, line := range []string{
+		`otter_worker_run_duration_seconds_count{worker="test_worker"} 2`,
+		`otter_worker_run_errors_total{worker="test_worker"} 1`,
+	} {
+		if !strings.Contains(body, line) {
+			t.Fatalf("missing %q in:\n%s", line, body)
+		}
+	}
+}
@@ -0,0 +1,54 @@
+package metrics
+
+import (
+	"strconv"
+	"time"
+)
+
+var (
+	httpRequestDuration = Default.NewHistogramVec(
+		"otter_http_request_duration_seconds",
+		"HTTP request latency by method, route pattern and status code.",
+		nil, "method", "route", "status",
+	)
+	workerRunDuration = Default.NewHistogramVec(
+		"otter_worker_run_duration_seconds",
+		"Duration of one background worker pass.",
+		nil, "worker",
+	)
+	workerRunErrors = Default.NewCounterVec(
+		"otter_worker_run_errors_total",
+		"Background worker passes that returned an error.",
+		"worker",
+	)
+	workerLastRun = Default.NewGaugeVec(
+		"otter_worker_last_run_timestamp_seconds",
+		"Unix time a background worker pass last finished.",
+		"worker",
+	)
+	rateLimitThrottled = Default.NewCounterVec(
+		"otter_rate_limit_throttled_total",
+		"API requests rejected by the rate limiter, by plan and route group.",
+		"plan", "group",
+	)
+)
+
+// ObserveHTTPRequest records one served request. route should be the router
+// pattern (e.g. /api/issues/{id}), never the raw path.
+func ObserveHTTPRequest(method, route string, status int, elapsed time.Duration) {
+	httpRequestDuration.Observe(elapsed.Seconds(), method, route, strconv.Itoa(status))
+}
+
+// ObserveWorkerRun records one pass of a polling worker.
+func ObserveWorkerRun(worker string, elapsed time.Duration, err error) {
+	workerRunDuration.Observe(elapsed.Seconds(), worker)
+	workerLastRun.Set(float64(time.Now().Unix()), worker)
+	if err != nil {
+		workerRunErrors.Inc(worker)
+	}
+}
+
+// ObserveRateLimitThrottle counts a request rejected by the API rate limiter.
+func ObserveRateLimitThrottle(plan, group string) {
+	rateLimitThrottled.Inc(plan, group)
+}
@@ -22,6 +22,8 @@ const (
 	WorkspaceIDKey ContextKey = "workspace_id"
 	// UserIDKey is the context key for the authenticated user ID.
 	UserIDKey ContextKey = "user_id"
+
+	pinnedWorkspaceIDKey ContextKey = "pinned_workspace_id"
 )
 
 var uuidRegex = regexp.MustCompile(`^[a-fA-F0-9]{8}-[a-fA-F0-9]{4}-[a-fA-F0-9]{4}-[a-fA-F0-9]{4}-[a-fA-F0-9]{12}$`)
@@ -71,6 +73,14 @@ func UserFromContext(ctx context.Context) string {
 	return ""
 }
 
+// PinWorkspace fixes the workspace for the rest of the request: workspace
+// middleware ignores headers, query parameters and slugs afterwards. Used for
+// credentials bound to one org, such as API keys.
+func PinWorkspace(ctx context.Context, workspaceID string) context.Context {
+	ctx = context.WithValue(ctx, pinnedWorkspaceIDKey, workspaceID)
+	return context.WithValue(ctx, WorkspaceIDKey, workspaceID)
+}
+
 // RequireWorkspace is middleware that ensures a valid workspace ID is present.
 // It extracts the workspace from:
 // 1. JWT Bearer token claims (org_id, organization_id, or workspace_id)
@@ -153,6 +163,10 @@ func getSessionTokenResolver() SessionTokenResolver {
 
 // extractWorkspaceID attempts to extract workspace ID from various sources.
 func extractWorkspaceID(r *http.Request) string {
+	if pinned, ok := r.Context().Value(pinnedWorkspaceIDKey).(string); ok && pinned != "" {
+		return pinned
+	}
+
 	// 1. Optionally try JWT Bearer token claims (disabled by default).
 	if trustUnverifiedJWTWorkspaceClaims() {
 		if auth := r.Header.Get("Authorization"); strings.HasPrefix(auth, "Bearer ") {
@@ -408,3 +408,21 @@ func createTestJWT(t *testing.T, claims map[string]string) string {
 
 	return header + "." + payload + "." + signature
 }
+
+func TestPinWorkspaceOverridesRequestHints(t *testing.T) {
+	pinned := "550e8400-e29b-41d4-a716-446655440000"
+	other := "660e8400-e29b-41d4-a716-446655440000"
+
+	var got string
+	handler := RequireWorkspace(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = WorkspaceFromContext(r.Context())
+	}))
+
+	req := httptest.NewRequest(http.MethodGet, "/api/issues?org_id="+other, nil)
+	req.Header.Set("X-Workspace-ID", other)
+	req.Header.Set("X-Org-ID", other)
+	req = req.WithContext(PinWorkspace(req.Context(), pinned))
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+
+	require.Equal(t, pinned, got)
+}
@@ -14,7 +14,9 @@ import (
 
 	importer "github.com/samhotchkiss/otter-camp/internal/import"
 	"github.com/samhotchkiss/otter-camp/internal/memory"
+	"github.com/samhotchkiss/otter-camp/internal/metrics"
 	"github.com/samhotchkiss/otter-camp/internal/store"
+	"github.com/samhotchkiss/otter-camp/internal/tracing"
 	"github.com/samhotchkiss/otter-camp/internal/ws"
 )
 
@@ -89,8 +91,18 @@ func (w *OpenClawPipelineWorker) runOnceBestEffort(ctx context.Context, logf fun
 	if w.ProgressStore == nil {
 		w.ProgressStore = store.NewMigrationProgressStore(w.DB)
 	}
+	started := time.Now()
+	var runErr error
+	var orgIDs []string
+	ctx, span := tracing.StartWorkerRun(ctx, "openclaw_pipeline")
+	defer func() {
+		tracing.EndWorkerRun(span, len(orgIDs) > 0, runErr)
+		metrics.ObserveWorkerRun("openclaw_pipeline", time.Since(started), runErr)
+	}()
+
 	orgIDs, err := w.listActiveOrgIDs(ctx)
 	if err != nil {
+		runErr = err
 		logf("openclaw pipeline: list active orgs failed: %v", err)
 		return
 	}
@@ -99,6 +111,7 @@ func (w *OpenClawPipelineWorker) runOnceBestEffort(ctx context.Context, logf fun
 			return
 		}
 		if err := w.reconcileOrg(ctx, orgID); err != nil {
+			runErr = err
 			logf("openclaw pipeline: org %s reconcile failed: %v", orgID, err)
 		}
 	}
@@ -298,20 +298,189 @@ type agentListResponse struct {
 }
 
 type Issue struct {
+	ID                string            `json:"id"`
+	ProjectID         string            `json:"project_id"`
+	IssueNumber       int64             `json:"issue_number"`
+	Title             string            `json:"title"`
+	Body              *string           `json:"body,omitempty"`
+	State             string            `json:"state"`
+	Origin            string            `json:"origin"`
+	ApprovalState     string            `json:"approval_state"`
+	OwnerAgentID      *string           `json:"owner_agent_id,omitempty"`
+	WorkStatus        string            `json:"work_status"`
+	Priority          string            `json:"priority"`
+	DueAt             *string           `json:"due_at,omitempty"`
+	NextStep          *string           `json:"next_step,omitempty"`
+	NextStepDueAt     *string           `json:"next_step_due_at,omitempty"`
+	ParentIssueID     *string           `json:"parent_issue_id,omitempty"`
+	MergedIntoIssueID *string           `json:"merged_into_issue_id,omitempty"`
+	Fields            []IssueFieldValue `json:"fields,omitempty"`
+	// Duplicates lists likely duplicates; only set on CreateIssue responses.
+	Duplicates []IssueDuplicate `json:"duplicates,omitempty"`
+}
+
+// IssueDuplicate is an existing issue similar to a newly created one.
+type IssueDuplicate struct {
+	Issue      Issue   `json:"issue"`
+	Similarity float64 `json:"similarity"`
+}
+
+// IssueMergeResult reports what MergeIssue moved onto the canonical issue.
+type IssueMergeResult struct {
+	Duplicate         Issue `json:"duplicate"`
+	Canonical         Issue `json:"canonical"`
+	MovedComments     int   `json:"moved_comments"`
+	MovedParticipants int   `json:"moved_participants"`
+	MovedLabels       int   `json:"moved_labels"`
+	MovedSubIssues    int   `json:"moved_sub_issues"`
+	MovedGitHubLink   bool  `json:"moved_github_link"`
+}
+
+// IssueTimelineEvent is one entry in an issue's merged history.
+type IssueTimelineEvent struct {
+	ID         string `json:"id"`
+	Type       string `json:"type"`
+	OccurredAt string `json:"occurred_at"`
+	Actor      struct {
+		Type string `json:"type"`
+		ID   string `json:"id,omitempty"`
+		Name string `json:"name,omitempty"`
+	} `json:"actor"`
+	Summary string          `json:"summary"`
+	Detail  json.RawMessage `json:"detail,omitempty"`
+}
+
+type IssueTimelinePage struct {
+	Items      []IssueTimelineEvent `json:"items"`
+	NextCursor string               `json:"next_cursor,omitempty"`
+}
+
+// IssueReviewThread is a review discussion anchored to lines of an issue's
+// linked document. Anchored* is the position at the latest document commit.
+type IssueReviewThread struct {
+	ID                string                     `json:"id"`
+	IssueID           string                     `json:"issue_id"`
+	DocumentPath      string                     `json:"document_path"`
+	CommitSHA         string                     `json:"commit_sha"`
+	StartLine         int                        `json:"start_line"`
+	EndLine           int                        `json:"end_line"`
+	AnchoredCommitSHA string                     `json:"anchored_commit_sha"`
+	AnchoredStartLine int                        `json:"anchored_start_line"`
+	AnchoredEndLine   int                        `json:"anchored_end_line"`
+	Outdated          bool                       `json:"outdated"`
+	AuthorAgentID     *string                    `json:"author_agent_id,omitempty"`
+	Status            string                     `json:"status"`
+	ResolvedByAgentID *string                    `json:"resolved_by_agent_id,omitempty"`
+	ResolutionNote    *string                    `json:"resolution_note,omitempty"`
+	Comments          []IssueReviewThreadComment `json:"comments"`
+}
+
+type IssueReviewThreadComment struct {
 	ID            string  `json:"id"`
-	ProjectID     string  `json:"project_id"`
-	IssueNumber   int64   `json:"issue_number"`
-	Title         string  `json:"title"`
-	Body          *string `json:"body,omitempty"`
-	State         string  `json:"state"`
-	Origin        string  `json:"origin"`
-	ApprovalState string  `json:"approval_state"`
-	OwnerAgentID  *string `json:"owner_agent_id,omitempty"`
-	WorkStatus    string  `json:"work_status"`
-	Priority      string  `json:"priority"`
-	DueAt         *string `json:"due_at,omitempty"`
-	NextStep      *string `json:"next_step,omitempty"`
-	NextStepDueAt *string `json:"next_step_due_at,omitempty"`
+	AuthorAgentID *string `json:"author_agent_id,omitempty"`
+	Body          string  `json:"body"`
+	CreatedAt     string  `json:"created_at"`
+}
+
+type IssueReviewThreadList struct {
+	HeadSHA string              `json:"head_sha"`
+	Items   []IssueReviewThread `json:"items"`
+	Total   int                 `json:"total"`
+	Open    int                 `json:"open"`
+}
+
+// IssueFieldValue is a custom field value stored on an issue.
+type IssueFieldValue struct {
+	Key   string `json:"key"`
+	Type  string `json:"type"`
+	Value string `json:"value"`
+}
+
+// IssueTemplateField defines one typed custom field on an issue template.
+type IssueTemplateField struct {
+	Key      string   `json:"key"`
+	Label    string   `json:"label,omitempty"`
+	Type     string   `json:"type"`
+	Required bool     `json:"required,omitempty"`
+	Default  string   `json:"default,omitempty"`
+	Options  []string `json:"options,omitempty"`
+	Help     string   `json:"help,omitempty"`
+}
+
+// IssueTemplate is a per-project issue skeleton with custom fields.
+type IssueTemplate struct {
+	ID                    string               `json:"id,omitempty"`
+	ProjectID             string               `json:"project_id,omitempty"`
+	Name                  string               `json:"name"`
+	Description           *string              `json:"description,omitempty"`
+	Body                  string               `json:"body"`
+	Fields                []IssueTemplateField `json:"fields"`
+	DefaultFlowTemplateID *string              `json:"default_flow_template_id,omitempty"`
+	DefaultLabels         []string             `json:"default_labels"`
+	CreatedAt             string               `json:"created_at,omitempty"`
+	UpdatedAt             string               `json:"updated_at,omitempty"`
+}
+
+// RecurringIssue opens a templated issue on a schedule.
+type RecurringIssue struct {
+	ID              string            `json:"id"`
+	ProjectID       string            `json:"project_id"`
+	Name            string            `json:"name"`
+	TitleTemplate   string            `json:"title_template"`
+	BodyTemplate    string            `json:"body_template"`
+	IssueTemplateID *string           `json:"issue_template_id,omitempty"`
+	Fields          map[string]string `json:"fields"`
+	OwnerAgentID    *string           `json:"owner_agent_id,omitempty"`
+	FlowTemplateID  *string           `json:"flow_template_id,omitempty"`
+	Priority        string            `json:"priority"`
+	ScheduleKind    string            `json:"schedule_kind"`
+	CronExpr        *string           `json:"cron_expr,omitempty"`
+	IntervalMS      *int64            `json:"interval_ms,omitempty"`
+	RunAt           *string           `json:"run_at,omitempty"`
+	Timezone        string            `json:"timezone"`
+	SkipIfOpen      bool              `json:"skip_if_open"`
+	Enabled         bool              `json:"enabled"`
+	NextRunAt       *string           `json:"next_run_at,omitempty"`
+	LastRunAt       *string           `json:"last_run_at,omitempty"`
+	LastRunStatus   *string           `json:"last_run_status,omitempty"`
+	LastRunError    *string           `json:"last_run_error,omitempty"`
+	LastIssueID     *string           `json:"last_issue_id,omitempty"`
+	RunCount        int               `json:"run_count"`
+}
+
+// RecurringIssueRunResult reports what a run-now produced.
+type RecurringIssueRunResult struct {
+	Outcome struct {
+		Status string `json:"status"`
+		Issue  *Issue `json:"issue,omitempty"`
+		Reason string `json:"reason,omitempty"`
+	} `json:"outcome"`
+	RecurringIssue RecurringIssue `json:"recurring_issue"`
+}
+
+// IssueTreeRollup summarizes every sub-issue below a tree node.
+type IssueTreeRollup struct {
+	Total           int     `json:"total"`
+	Closed          int     `json:"closed"`
+	PercentComplete int     `json:"percent_complete"`
+	Blocked         int     `json:"blocked"`
+	EarliestDueAt   *string `json:"earliest_due_at,omitempty"`
+}
+
+type IssueTreeNode struct {
+	Issue    Issue           `json:"issue"`
+	Rollup   IssueTreeRollup `json:"rollup"`
+	Children []IssueTreeNode `json:"children"`
+}
+
+// SubIssueInput describes one child created by DecomposeIssue.
+type SubIssueInput struct {
+	Title        string  `json:"title"`
+	Body         *string `json:"body,omitempty"`
+	OwnerAgentID *string `json:"owner_agent_id,omitempty"`
+	Priority     string  `json:"priority,omitempty"`
+	WorkStatus   string  `json:"work_status,omitempty"`
+	DueAt        *string `json:"due_at,omitempty"`
 }
 
 type IssuePipelineProgressionResult struct {
@@ -390,6 +559,70 @@ type issueListResponse struct {
 	Total int     `json:"total"`
 }
 
+// IssueListPage is one page of GET /api/issues. NextCursor is set when a
+// query (q or view) has more results.
+type IssueListPage struct {
+	Items      []Issue         `json:"items"`
+	Total      int             `json:"total"`
+	NextCursor string          `json:"next_cursor,omitempty"`
+	Query      string          `json:"query,omitempty"`
+	View       *IssueSavedView `json:"view,omitempty"`
+}
+
+// IssueSavedView is a named issue query, personal or shared with a project.
+type IssueSavedView struct {
+	ID        string  `json:"id"`
+	UserID    *string `json:"user_id,omitempty"`
+	ProjectID *string `json:"project_id,omitempty"`
+	Name      string  `json:"name"`
+	Query     string  `json:"query"`
+	CreatedAt string  `json:"created_at"`
+	UpdatedAt string  `json:"updated_at"`
+}
+
+// IssueBulkOperations mirrors the server's bulk change set. An empty
+// OwnerAgentID unassigns.
+type IssueBulkOperations struct {
+	OwnerAgentID    *string  `json:"owner_agent_id,omitempty"`
+	Priority        string   `json:"priority,omitempty"`
+	WorkStatus      string   `json:"work_status,omitempty"`
+	State           string   `json:"state,omitempty"`
+	AddLabels       []string `json:"add_labels,omitempty"`
+	RemoveLabels    []string `json:"remove_labels,omitempty"`
+	FlowTemplateID  string   `json:"flow_template_id,omitempty"`
+	MoveToProjectID string   `json:"move_to_project_id,omitempty"`
+}
+
+type IssueBulkRequest struct {
+	IssueIDs   []string            `json:"issue_ids,omitempty"`
+	Query      string              `json:"query,omitempty"`
+	View       string              `json:"view,omitempty"`
+	ProjectID  string              `json:"project_id,omitempty"`
+	Operations IssueBulkOperations `json:"operations"`
+	DryRun     bool                `json:"dry_run,omitempty"`
+}
+
+type IssueBulkItemResult struct {
+	IssueID     string   `json:"issue_id"`
+	ProjectID   string   `json:"project_id,omitempty"`
+	IssueNumber int64    `json:"issue_number,omitempty"`
+	Title       string   `json:"title,omitempty"`
+	Status      string   `json:"status"`
+	Changes     []string `json:"changes,omitempty"`
+	Error       string   `json:"error,omitempty"`
+}
+
+type IssueBulkResult struct {
+	DryRun     bool                  `json:"dry_run"`
+	Applied    bool                  `json:"applied"`
+	Matched    int                   `json:"matched"`
+	Updated    int                   `json:"updated"`
+	Unchanged  int                   `json:"unchanged"`
+	Failed     int                   `json:"failed"`
+	Items      []IssueBulkItemResult `json:"items"`
+	ActivityID *string               `json:"activity_id,omitempty"`
+}
+
 type issueDetailResponse struct {
 	Issue Issue `json:"issue"`
 }
@@ -418,9 +651,37 @@ type AgentJob struct {
 	ErrorCount          int     `json:"error_count"`
 	MaxFailures         int     `json:"max_failures"`
 	ConsecutiveFailures int     `json:"consecutive_failures"`
-	CreatedBy           *string `json:"created_by,omitempty"`
-	CreatedAt           string  `json:"created_at"`
-	UpdatedAt           string  `json:"updated_at"`
+	OverlapPolicy       string  `json:"overlap_policy,omitempty"`
+	CatchUpPolicy       string  `json:"catch_up_policy,omitempty"`
+	JitterMS            int64   `json:"jitter_ms,omitempty"`
+	MaxAgentConcurrency *int    `json:"max_agent_concurrency,omitempty"`
+
+	SuccessPattern          *string `json:"success_pattern,omitempty"`
+	SuccessJSONField        *string `json:"success_json_field,omitempty"`
+	SuccessClassifierPrompt *string `json:"success_classifier_prompt,omitempty"`
+	ReplyTimeoutMS          *int64  `json:"reply_timeout_ms,omitempty"`
+
+	CreatedBy *string `json:"created_by,omitempty"`
+	CreatedAt string  `json:"created_at"`
+	UpdatedAt string  `json:"updated_at"`
+}
+
+type AgentJobBlackout struct {
+	ID          string  `json:"id"`
+	OrgID       string  `json:"org_id"`
+	Name        string  `json:"name"`
+	DaysOfWeek  []int   `json:"days_of_week"`
+	StartDate   *string `json:"start_date,omitempty"`
+	EndDate     *string `json:"end_date,omitempty"`
+	StartMinute int     `json:"start_minute"`
+	EndMinute   int     `json:"end_minute"`
+	CreatedAt   string  `json:"created_at"`
+	UpdatedAt   string  `json:"updated_at"`
+}
+
+type AgentJobBlackoutListResponse struct {
+	Items []AgentJobBlackout `json:"items"`
+	Total int                `json:"total"`
 }
 
 type AgentJobRun struct {
@@ -435,6 +696,10 @@ type AgentJobRun struct {
 	PayloadText string  `json:"payload_text"`
 	MessageID   *string `json:"message_id,omitempty"`
 	CreatedAt   string  `json:"created_at"`
+
+	Output          *string `json:"output,omitempty"`
+	ReplyMessageID  *string `json:"reply_message_id,omitempty"`
+	ReplyDeadlineAt *string `json:"reply_deadline_at,omitempty"`
 }
 
 type AgentJobListResponse struct {
@@ -1924,15 +2189,26 @@ func (c *Client) CreateIssue(projectID string, input map[string]interface{}) (Is
 }
 
 func (c *Client) ListIssues(projectID string, filters map[string]string) ([]Issue, error) {
-	if err := c.requireAuth(); err != nil {
+	if strings.TrimSpace(projectID) == "" {
+		return nil, errors.New("project id is required")
+	}
+	page, err := c.ListIssuesPage(projectID, filters)
+	if err != nil {
 		return nil, err
 	}
-	projectID = strings.TrimSpace(projectID)
-	if projectID == "" {
-		return nil, errors.New("project id is required")
+	return page.Items, nil
+}
+
+// ListIssuesPage lists issues with the cursor of the next page. projectID may
+// be empty when filters carry a q or view.
+func (c *Client) ListIssuesPage(projectID string, filters map[string]string) (IssueListPage, error) {
+	if err := c.requireAuth(); err != nil {
+		return IssueListPage{}, err
 	}
 	q := url.Values{}
-	q.Set("project_id", projectID)
+	if projectID = strings.TrimSpace(projectID); projectID != "" {
+		q.Set("project_id", projectID)
+	}
 	for key, value := range filters {
 		if strings.TrimSpace(value) == "" {
 			continue
@@ -1941,13 +2217,457 @@ func (c *Client) ListIssues(projectID string, filters map[string]string) ([]Issu
 	}
 	req, err := c.newRequest(http.MethodGet, "/api/issues?"+q.Encode(), nil)
 	if err != nil {
-		return nil, err
+		return IssueListPage{}, err
 	}
-	var resp issueListResponse
+	var resp IssueListPage
 	if err := c.do(req, &resp); err != nil {
+		return IssueListPage{}, err
+	}
+	return resp, nil
+}
+
+// ListIssueViews returns the caller's personal views and project views, for
+// projectID only when it is set.
+func (c *Client) ListIssueViews(projectID string) ([]IssueSavedView, error) {
+	path := "/api/issues/views"
+	if projectID = strings.TrimSpace(projectID); projectID != "" {
+		path += "?project_id=" + url.QueryEscape(projectID)
+	}
+	var response struct {
+		Items []IssueSavedView `json:"items"`
+	}
+	if err := c.adminRequest(http.MethodGet, path, nil, &response); err != nil {
 		return nil, err
 	}
-	return resp.Items, nil
+	return response.Items, nil
+}
+
+// SaveIssueView creates or replaces a view by name: shared with projectID
+// when it is set, personal otherwise.
+func (c *Client) SaveIssueView(name, query, projectID string) (IssueSavedView, error) {
+	input := map[string]any{"name": name, "query": query}
+	if projectID = strings.TrimSpace(projectID); projectID != "" {
+		input["project_id"] = projectID
+	}
+	var view IssueSavedView
+	if err := c.adminRequest(http.MethodPost, "/api/issues/views", input, &view); err != nil {
+		return IssueSavedView{}, err
+	}
+	return view, nil
+}
+
+func (c *Client) DeleteIssueView(viewID string) error {
+	viewID = strings.TrimSpace(viewID)
+	if viewID == "" {
+		return errors.New("view id is required")
+	}
+	return c.adminRequest(http.MethodDelete, "/api/issues/views/"+url.PathEscape(viewID), nil, nil)
+}
+
+func (c *Client) ListIssueTemplates(projectID string) ([]IssueTemplate, error) {
+	projectID = strings.TrimSpace(projectID)
+	if projectID == "" {
+		return nil, errors.New("project id is required")
+	}
+	var response struct {
+		Items []IssueTemplate `json:"items"`
+	}
+	if err := c.adminRequest(http.MethodGet, "/api/projects/"+url.PathEscape(projectID)+"/issue-templates", nil, &response); err != nil {
+		return nil, err
+	}
+	return response.Items, nil
+}
+
+// GetIssueTemplate fetches a template by id or name.
+func (c *Client) GetIssueTemplate(projectID, ref string) (IssueTemplate, error) {
+	projectID = strings.TrimSpace(projectID)
+	ref = strings.TrimSpace(ref)
+	if projectID == "" || ref == "" {
+		return IssueTemplate{}, errors.New("project id and template are required")
+	}
+	var template IssueTemplate
+	path := "/api/projects/" + url.PathEscape(projectID) + "/issue-templates/" + url.PathEscape(ref)
+	if err := c.adminRequest(http.MethodGet, path, nil, &template); err != nil {
+		return IssueTemplate{}, err
+	}
+	return template, nil
+}
+
+// SaveIssueTemplate creates or replaces a template by name.
+func (c *Client) SaveIssueTemplate(projectID string, template IssueTemplate) (IssueTemplate, error) {
+	if err := c.requireAuth(); err != nil {
+		return IssueTemplate{}, err
+	}
+	projectID = strings.TrimSpace(projectID)
+	if projectID == "" {
+		return IssueTemplate{}, errors.New("project id is required")
+	}
+	payload, err := json.Marshal(map[string]any{
+		"name":                     template.Name,
+		"description":              template.Description,
+		"body":                     template.Body,
+		"fields":                   template.Fields,
+		"default_flow_template_id": template.DefaultFlowTemplateID,
+		"default_labels":           template.DefaultLabels,
+	})
+	if err != nil {
+		return IssueTemplate{}, err
+	}
+	req, err := c.newRequest(http.MethodPost, "/api/projects/"+url.PathEscape(projectID)+"/issue-templates", bytes.NewReader(payload))
+	if err != nil {
+		return IssueTemplate{}, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	var saved IssueTemplate
+	if err := c.do(req, &saved); err != nil {
+		return IssueTemplate{}, err
+	}
+	return saved, nil
+}
+
+func (c *Client) DeleteIssueTemplate(projectID, ref string) error {
+	projectID = strings.TrimSpace(projectID)
+	ref = strings.TrimSpace(ref)
+	if projectID == "" || ref == "" {
+		return errors.New("project id and template are required")
+	}
+	return c.adminRequest(http.MethodDelete, "/api/projects/"+url.PathEscape(projectID)+"/issue-templates/"+url.PathEscape(ref), nil, nil)
+}
+
+func (c *Client) ListRecurringIssues(projectID string) ([]RecurringIssue, error) {
+	projectID = strings.TrimSpace(projectID)
+	if projectID == "" {
+		return nil, errors.New("project id is required")
+	}
+	var response struct {
+		Items []RecurringIssue `json:"items"`
+	}
+	if err := c.adminRequest(http.MethodGet, "/api/projects/"+url.PathEscape(projectID)+"/recurring-issues", nil, &response); err != nil {
+		return nil, err
+	}
+	return response.Items, nil
+}
+
+func (c *Client) GetRecurringIssue(projectID, recurringID string) (RecurringIssue, error) {
+	path, err := recurringIssuePath(projectID, recurringID)
+	if err != nil {
+		return RecurringIssue{}, err
+	}
+	var recurring RecurringIssue
+	if err := c.adminRequest(http.MethodGet, path, nil, &recurring); err != nil {
+		return RecurringIssue{}, err
+	}
+	return recurring, nil
+}
+
+// CreateRecurringIssue sends input as-is; keys match the API's
+// recurring issue request (name, title_template, schedule_kind, ...).
+func (c *Client) CreateRecurringIssue(projectID string, input map[string]any) (RecurringIssue, error) {
+	projectID = strings.TrimSpace(projectID)
+	if projectID == "" {
+		return RecurringIssue{}, errors.New("project id is required")
+	}
+	var recurring RecurringIssue
+	if err := c.adminRequest(http.MethodPost, "/api/projects/"+url.PathEscape(projectID)+"/recurring-issues", input, &recurring); err != nil {
+		return RecurringIssue{}, err
+	}
+	return recurring, nil
+}
+
+func (c *Client) UpdateRecurringIssue(projectID, recurringID string, input map[string]any) (RecurringIssue, error) {
+	path, err := recurringIssuePath(projectID, recurringID)
+	if err != nil {
+		return RecurringIssue{}, err
+	}
+	if len(input) == 0 {
+		return RecurringIssue{}, errors.New("no changes requested")
+	}
+	var recurring RecurringIssue
+	if err := c.adminRequest(http.MethodPatch, path, input, &recurring); err != nil {
+		return RecurringIssue{}, err
+	}
+	return recurring, nil
+}
+
+func (c *Client) DeleteRecurringIssue(projectID, recurringID string) error {
+	path, err := recurringIssuePath(projectID, recurringID)
+	if err != nil {
+		return err
+	}
+	return c.adminRequest(http.MethodDelete, path, nil, nil)
+}
+
+// RunRecurringIssue generates the next instance now without moving the
+// definition's schedule.
+func (c *Client) RunRecurringIssue(projectID, recurringID string) (RecurringIssueRunResult, error) {
+	path, err := recurringIssuePath(projectID, recurringID)
+	if err != nil {
+		return RecurringIssueRunResult{}, err
+	}
+	var result RecurringIssueRunResult
+	if err := c.adminRequest(http.MethodPost, path+"/run", nil, &result); err != nil {
+		return RecurringIssueRunResult{}, err
+	}
+	return result, nil
+}
+
+func recurringIssuePath(projectID, recurringID string) (string, error) {
+	projectID = strings.TrimSpace(projectID)
+	recurringID = strings.TrimSpace(recurringID)
+	if projectID == "" || recurringID == "" {
+		return "", errors.New("project id and recurring issue id are required")
+	}
+	return "/api/projects/" + url.PathEscape(projectID) + "/recurring-issues/" + url.PathEscape(recurringID), nil
+}
+
+// IssueImportRequest mirrors the API's issue import request. Content is the
+// raw export file.
+type IssueImportRequest struct {
+	Source        string            `json:"source"`
+	Format        string            `json:"format,omitempty"`
+	Content       string            `json:"content"`
+	Columns       map[string]string `json:"columns,omitempty"`
+	StatusMap     map[string]string `json:"status_map,omitempty"`
+	AssigneeMap   map[string]string `json:"assignee_map,omitempty"`
+	CommentAuthor string            `json:"comment_author,omitempty"`
+	DryRun        bool              `json:"dry_run"`
+}
+
+type IssueImportItem struct {
+	Key          string  `json:"key"`
+	Title        string  `json:"title"`
+	Action       string  `json:"action"`
+	IssueID      *string `json:"issue_id,omitempty"`
+	IssueNumber  *int64  `json:"issue_number,omitempty"`
+	WorkStatus   string  `json:"work_status,omitempty"`
+	Priority     string  `json:"priority,omitempty"`
+	OwnerAgentID *string `json:"owner_agent_id,omitempty"`
+	ParentKey    string  `json:"parent_key,omitempty"`
+	Comments     int     `json:"comments"`
+	Reason       string  `json:"reason,omitempty"`
+}
+
+type IssueImportResult struct {
+	DryRun           bool              `json:"dry_run"`
+	Source           string            `json:"source"`
+	Total            int               `json:"total"`
+	Created          int               `json:"created"`
+	Updated          int               `json:"updated"`
+	Unchanged        int               `json:"unchanged"`
+	Skipped          int               `json:"skipped"`
+	Failed           int               `json:"failed"`
+	ParentLinks      int               `json:"parent_links"`
+	LabelsApplied    int               `json:"labels_applied"`
+	CommentsImported int               `json:"comments_imported"`
+	CommentsExisting int               `json:"comments_existing"`
+	CommentsSkipped  int               `json:"comments_skipped"`
+	Items            []IssueImportItem `json:"items"`
+	Summary          struct {
+		UnmappedStatuses  []string `json:"unmapped_statuses"`
+		UnmappedAssignees []string `json:"unmapped_assignees"`
+		Warnings          []string `json:"warnings"`
+	} `json:"summary"`
+}
+
+// ImportIssues uploads a Jira, Linear or CSV export into a project. Imports
+// are idempotent per source and external key.
+func (c *Client) ImportIssues(projectID string, input IssueImportRequest) (IssueImportResult, error) {
+	if err := c.requireAuth(); err != nil {
+		return IssueImportResult{}, err
+	}
+	projectID = strings.TrimSpace(projectID)
+	if projectID == "" {
+		return IssueImportResult{}, errors.New("project id is required")
+	}
+	if strings.TrimSpace(input.Source) == "" {
+		return IssueImportResult{}, errors.New("source is required")
+	}
+	payload, err := json.Marshal(input)
+	if err != nil {
+		return IssueImportResult{}, err
+	}
+	req, err := c.newRequest(http.MethodPost, "/api/projects/"+url.PathEscape(projectID)+"/issue-imports", bytes.NewReader(payload))
+	if err != nil {
+		return IssueImportResult{}, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	var result IssueImportResult
+	if err := c.do(req, &result); err != nil {
+		return IssueImportResult{}, err
+	}
+	return result, nil
+}
+
+type IssueDurationStats struct {
+	Count      int      `json:"count"`
+	AvgSeconds *float64 `json:"avg_seconds,omitempty"`
+	P50Seconds *float64 `json:"p50_seconds,omitempty"`
+	P85Seconds *float64 `json:"p85_seconds,omitempty"`
+	MaxSeconds *float64 `json:"max_seconds,omitempty"`
+}
+
+type IssueAnalyticsStep struct {
+	Kind     string             `json:"kind"`
+	Key      string             `json:"key"`
+	Name     string             `json:"name"`
+	Issues   int                `json:"issues"`
+	Visits   int                `json:"visits"`
+	Duration IssueDurationStats `json:"duration"`
+}
+
+type IssueAnalyticsBucket struct {
+	Start      string `json:"start"`
+	End        string `json:"end"`
+	Throughput int    `json:"throughput"`
+	WIP        int    `json:"wip"`
+}
+
+type IssueAnalyticsGroup struct {
+	Key              string                        `json:"key"`
+	Name             string                        `json:"name"`
+	Completed        int                           `json:"completed"`
+	Cancelled        int                           `json:"cancelled"`
+	OpenAtEnd        int                           `json:"open_at_end"`
+	LeadTime         IssueDurationStats            `json:"lead_time"`
+	CycleTime        IssueDurationStats            `json:"cycle_time"`
+	TimeInStatus     map[string]IssueDurationStats `json:"time_in_status"`
+	Steps            []IssueAnalyticsStep          `json:"steps"`
+	ReworkBounces    int                           `json:"rework_bounces"`
+	IssuesWithRework int                           `json:"issues_with_rework"`
+	ReworkRate       *float64                      `json:"rework_rate,omitempty"`
+	Series           []IssueAnalyticsBucket        `json:"series"`
+}
+
+type IssueAnalyticsIssue struct {
+	ID            string             `json:"id"`
+	IssueNumber   int64              `json:"issue_number"`
+	Title         string             `json:"title"`
+	Priority      string             `json:"priority"`
+	WorkStatus    string             `json:"work_status"`
+	OwnerName     string             `json:"owner_name,omitempty"`
+	Labels        []string           `json:"labels"`
+	CreatedAt     string             `json:"created_at"`
+	StartedAt     *string            `json:"started_at,omitempty"`
+	ClosedAt      *string            `json:"closed_at,omitempty"`
+	LeadSeconds   *float64           `json:"lead_seconds,omitempty"`
+	CycleSeconds  *float64           `json:"cycle_seconds,omitempty"`
+	ReworkBounces int                `json:"rework_bounces"`
+	StatusSeconds map[string]float64 `json:"status_seconds"`
+}
+
+type IssueAnalyticsReport struct {
+	ProjectID string                `json:"project_id"`
+	From      string                `json:"from"`
+	To        string                `json:"to"`
+	Days      int                   `json:"days"`
+	Bucket    string                `json:"bucket"`
+	GroupBy   string                `json:"group_by,omitempty"`
+	Overall   IssueAnalyticsGroup   `json:"overall"`
+	Groups    []IssueAnalyticsGroup `json:"groups,omitempty"`
+	Issues    []IssueAnalyticsIssue `json:"issues"`
+}
+
+// ProjectAnalyticsOptions scopes a project analytics report. Since is a
+// window such as "30d" or "4w"; GroupBy is agent, label or priority; Bucket
+// is day or week. Dataset only applies to CSV exports.
+type ProjectAnalyticsOptions struct {
+	Since   string
+	GroupBy string
+	Bucket  string
+	Dataset string
+}
+
+func projectAnalyticsPath(projectID string, opts ProjectAnalyticsOptions, format string) (string, error) {
+	projectID = strings.TrimSpace(projectID)
+	if projectID == "" {
+		return "", errors.New("project id is required")
+	}
+	q := url.Values{}
+	for key, value := range map[string]string{
+		"since":    opts.Since,
+		"group_by": opts.GroupBy,
+		"bucket":   opts.Bucket,
+		"format":   format,
+	} {
+		if value = strings.TrimSpace(value); value != "" {
+			q.Set(key, value)
+		}
+	}
+	if dataset := strings.TrimSpace(opts.Dataset); dataset != "" && strings.TrimSpace(format) != "" {
+		q.Set("dataset", dataset)
+	}
+	path := "/api/projects/" + url.PathEscape(projectID) + "/analytics"
+	if encoded := q.Encode(); encoded != "" {
+		path += "?" + encoded
+	}
+	return path, nil
+}
+
+func (c *Client) ProjectAnalytics(projectID string, opts ProjectAnalyticsOptions) (IssueAnalyticsReport, error) {
+	path, err := projectAnalyticsPath(projectID, opts, "")
+	if err != nil {
+		return IssueAnalyticsReport{}, err
+	}
+	var response IssueAnalyticsReport
+	if err := c.adminRequest(http.MethodGet, path, nil, &response); err != nil {
+		return IssueAnalyticsReport{}, err
+	}
+	return response, nil
+}
+
+// ExportProjectAnalytics writes one dataset of the report as csv to out.
+func (c *Client) ExportProjectAnalytics(projectID string, opts ProjectAnalyticsOptions, out io.Writer) (int64, error) {
+	if err := c.requireAuth(); err != nil {
+		return 0, err
+	}
+	path, err := projectAnalyticsPath(projectID, opts, "csv")
+	if err != nil {
+		return 0, err
+	}
+	req, err := c.newRequest(http.MethodGet, path, nil)
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Accept", "text/csv, application/json")
+
+	resp, err := c.HTTP.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 400 {
+		payload, _ := io.ReadAll(io.LimitReader(resp.Body, maxClientResponseBodyBytes))
+		return 0, &RequestError{
+			StatusCode: resp.StatusCode,
+			Detail:     summarizeResponseBody(resp.Header.Get("Content-Type"), payload),
+		}
+	}
+	return io.Copy(out, resp.Body)
+}
+
+// BulkIssues applies one change set to a list of issues or to a query's
+// matches. With DryRun the server reports what would change and rolls back.
+func (c *Client) BulkIssues(input IssueBulkRequest) (IssueBulkResult, error) {
+	if err := c.requireAuth(); err != nil {
+		return IssueBulkResult{}, err
+	}
+	if len(input.IssueIDs) == 0 && strings.TrimSpace(input.Query) == "" && strings.TrimSpace(input.View) == "" {
+		return IssueBulkResult{}, errors.New("issue ids, a query, or a view is required")
+	}
+	payload, err := json.Marshal(input)
+	if err != nil {
+		return IssueBulkResult{}, err
+	}
+	req, err := c.newRequest(http.MethodPost, "/api/issues/bulk", bytes.NewReader(payload))
+	if err != nil {
+		return IssueBulkResult{}, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	var result IssueBulkResult
+	if err := c.do(req, &result); err != nil {
+		return IssueBulkResult{}, err
+	}
+	return result, nil
 }
 
 func (c *Client) ListJobs(filters map[string]string) (AgentJobListResponse, error) {
@@ -2037,6 +2757,38 @@ func (c *Client) ListJobRuns(jobID string, limit int) (AgentJobRunsResponse, err
 	return response, nil
 }
 
+func (c *Client) ReplyToJobRun(jobID, runID, output string) (map[string]any, error) {
+	if err := c.requireAuth(); err != nil {
+		return nil, err
+	}
+	jobID = strings.TrimSpace(jobID)
+	runID = strings.TrimSpace(runID)
+	if jobID == "" {
+		return nil, errors.New("job id is required")
+	}
+	if runID == "" {
+		return nil, errors.New("run id is required")
+	}
+	if strings.TrimSpace(output) == "" {
+		return nil, errors.New("output is required")
+	}
+	payload, err := json.Marshal(map[string]any{"output": output})
+	if err != nil {
+		return nil, err
+	}
+	path := "/api/v1/jobs/" + url.PathEscape(jobID) + "/runs/" + url.PathEscape(runID) + "/reply"
+	req, err := c.newRequest(http.MethodPost, path, bytes.NewReader(payload))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	var response map[string]any
+	if err := c.do(req, &response); err != nil {
+		return nil, err
+	}
+	return response, nil
+}
+
 func (c *Client) DeleteJob(jobID string) (map[string]any, error) {
 	if err := c.requireAuth(); err != nil {
 		return nil, err
@@ -2056,6 +2808,63 @@ func (c *Client) DeleteJob(jobID string) (map[string]any, error) {
 	return response, nil
 }
 
+func (c *Client) ListJobBlackouts() (AgentJobBlackoutListResponse, error) {
+	if err := c.requireAuth(); err != nil {
+		return AgentJobBlackoutListResponse{}, err
+	}
+	req, err := c.newRequest(http.MethodGet, "/api/v1/jobs/blackouts", nil)
+	if err != nil {
+		return AgentJobBlackoutListResponse{}, err
+	}
+	var response AgentJobBlackoutListResponse
+	if err := c.do(req, &response); err != nil {
+		return AgentJobBlackoutListResponse{}, err
+	}
+	return response, nil
+}
+
+func (c *Client) CreateJobBlackout(input map[string]any) (AgentJobBlackout, error) {
+	if err := c.requireAuth(); err != nil {
+		return AgentJobBlackout{}, err
+	}
+	if len(input) == 0 {
+		return AgentJobBlackout{}, errors.New("blackout payload is required")
+	}
+	payload, err := json.Marshal(input)
+	if err != nil {
+		return AgentJobBlackout{}, err
+	}
+	req, err := c.newRequest(http.MethodPost, "/api/v1/jobs/blackouts", bytes.NewReader(payload))
+	if err != nil {
+		return AgentJobBlackout{}, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	var response AgentJobBlackout
+	if err := c.do(req, &response); err != nil {
+		return AgentJobBlackout{}, err
+	}
+	return response, nil
+}
+
+func (c *Client) DeleteJobBlackout(blackoutID string) (map[string]any, error) {
+	if err := c.requireAuth(); err != nil {
+		return nil, err
+	}
+	blackoutID = strings.TrimSpace(blackoutID)
+	if blackoutID == "" {
+		return nil, errors.New("blackout id is required")
+	}
+	req, err := c.newRequest(http.MethodDelete, "/api/v1/jobs/blackouts/"+url.PathEscape(blackoutID), nil)
+	if err != nil {
+		return nil, err
+	}
+	var response map[string]any
+	if err := c.do(req, &response); err != nil {
+		return nil, err
+	}
+	return response, nil
+}
+
 func (c *Client) ImportOpenClawCronJobs() (OpenClawCronJobImportResult, error) {
 	if err := c.requireAuth(); err != nil {
 		return OpenClawCronJobImportResult{}, err
@@ -2321,33 +3130,195 @@ func (c *Client) PatchIssue(issueID string, input map[string]interface{}) (Issue
 	return issue, nil
 }
 
-func (c *Client) CommentIssue(issueID, authorAgentID, body string) error {
-	if err := c.requireAuth(); err != nil {
-		return err
-	}
+// GetIssueTree fetches an issue with all of its sub-issues and rollups.
+func (c *Client) GetIssueTree(issueID string) (IssueTreeNode, error) {
 	issueID = strings.TrimSpace(issueID)
-	authorAgentID = strings.TrimSpace(authorAgentID)
-	body = strings.TrimSpace(body)
 	if issueID == "" {
-		return errors.New("issue id is required")
-	}
-	if authorAgentID == "" {
-		return errors.New("author agent id is required")
+		return IssueTreeNode{}, errors.New("issue id is required")
 	}
-	if body == "" {
-		return errors.New("comment body is required")
+	var tree IssueTreeNode
+	if err := c.adminRequest(http.MethodGet, "/api/issues/"+url.PathEscape(issueID)+"/tree", nil, &tree); err != nil {
+		return IssueTreeNode{}, err
 	}
-	payload, err := json.Marshal(map[string]string{
-		"author_agent_id": authorAgentID,
-		"body":            body,
-		"sender_type":     "agent",
-	})
-	if err != nil {
-		return err
+	return tree, nil
+}
+
+func (c *Client) AttachSubIssue(parentID, childID string) (Issue, error) {
+	parentID = strings.TrimSpace(parentID)
+	childID = strings.TrimSpace(childID)
+	if parentID == "" || childID == "" {
+		return Issue{}, errors.New("parent and child issue ids are required")
 	}
-	req, err := c.newRequest(http.MethodPost, "/api/issues/"+url.PathEscape(issueID)+"/comments", bytes.NewReader(payload))
-	if err != nil {
-		return err
+	var child Issue
+	if err := c.adminRequest(http.MethodPost, "/api/issues/"+url.PathEscape(parentID)+"/children", map[string]any{
+		"child_issue_id": childID,
+	}, &child); err != nil {
+		return Issue{}, err
+	}
+	return child, nil
+}
+
+func (c *Client) DetachSubIssue(parentID, childID string) (Issue, error) {
+	parentID = strings.TrimSpace(parentID)
+	childID = strings.TrimSpace(childID)
+	if parentID == "" || childID == "" {
+		return Issue{}, errors.New("parent and child issue ids are required")
+	}
+	var child Issue
+	if err := c.adminRequest(http.MethodDelete, "/api/issues/"+url.PathEscape(parentID)+"/children/"+url.PathEscape(childID), nil, &child); err != nil {
+		return Issue{}, err
+	}
+	return child, nil
+}
+
+// DecomposeIssue creates several sub-issues in one call. agentID identifies
+// the planner agent when an agent decomposes on its own behalf.
+func (c *Client) DecomposeIssue(issueID string, agentID *string, children []SubIssueInput) ([]Issue, error) {
+	issueID = strings.TrimSpace(issueID)
+	if issueID == "" {
+		return nil, errors.New("issue id is required")
+	}
+	if len(children) == 0 {
+		return nil, errors.New("at least one sub-issue is required")
+	}
+	input := map[string]any{"children": children}
+	if agentID != nil && strings.TrimSpace(*agentID) != "" {
+		input["agent_id"] = strings.TrimSpace(*agentID)
+	}
+	var response struct {
+		Items []Issue `json:"items"`
+	}
+	if err := c.adminRequest(http.MethodPost, "/api/issues/"+url.PathEscape(issueID)+"/decompose", input, &response); err != nil {
+		return nil, err
+	}
+	return response.Items, nil
+}
+
+// MergeIssue folds issueID into intoIssueID, leaving issueID closed with a
+// redirect to the canonical issue.
+func (c *Client) MergeIssue(issueID, intoIssueID string) (IssueMergeResult, error) {
+	issueID = strings.TrimSpace(issueID)
+	intoIssueID = strings.TrimSpace(intoIssueID)
+	if issueID == "" || intoIssueID == "" {
+		return IssueMergeResult{}, errors.New("issue id and target issue id are required")
+	}
+	var result IssueMergeResult
+	if err := c.adminRequest(http.MethodPost, "/api/issues/"+url.PathEscape(issueID)+"/merge", map[string]any{"into_issue_id": intoIssueID}, &result); err != nil {
+		return IssueMergeResult{}, err
+	}
+	return result, nil
+}
+
+// GetIssueTimeline fetches one page of the issue's timeline. types is an
+// optional list of event types to keep.
+func (c *Client) GetIssueTimeline(issueID, cursor string, limit int, types []string) (IssueTimelinePage, error) {
+	issueID = strings.TrimSpace(issueID)
+	if issueID == "" {
+		return IssueTimelinePage{}, errors.New("issue id is required")
+	}
+	q := url.Values{}
+	if strings.TrimSpace(cursor) != "" {
+		q.Set("cursor", strings.TrimSpace(cursor))
+	}
+	if limit > 0 {
+		q.Set("limit", fmt.Sprintf("%d", limit))
+	}
+	if len(types) > 0 {
+		q.Set("types", strings.Join(types, ","))
+	}
+	path := "/api/issues/" + url.PathEscape(issueID) + "/timeline"
+	if encoded := q.Encode(); encoded != "" {
+		path += "?" + encoded
+	}
+	var page IssueTimelinePage
+	if err := c.adminRequest(http.MethodGet, path, nil, &page); err != nil {
+		return IssueTimelinePage{}, err
+	}
+	return page, nil
+}
+
+func (c *Client) ListIssueReviewThreads(issueID, status string) (IssueReviewThreadList, error) {
+	issueID = strings.TrimSpace(issueID)
+	if issueID == "" {
+		return IssueReviewThreadList{}, errors.New("issue id is required")
+	}
+	path := "/api/issues/" + url.PathEscape(issueID) + "/review/threads"
+	if status = strings.TrimSpace(status); status != "" {
+		path += "?status=" + url.QueryEscape(status)
+	}
+	var list IssueReviewThreadList
+	if err := c.adminRequest(http.MethodGet, path, nil, &list); err != nil {
+		return IssueReviewThreadList{}, err
+	}
+	return list, nil
+}
+
+func (c *Client) ReplyIssueReviewThread(issueID, threadID, authorAgentID, body string) (IssueReviewThread, error) {
+	issueID = strings.TrimSpace(issueID)
+	threadID = strings.TrimSpace(threadID)
+	if issueID == "" || threadID == "" {
+		return IssueReviewThread{}, errors.New("issue id and thread id are required")
+	}
+	var thread IssueReviewThread
+	if err := c.adminRequest(http.MethodPost, "/api/issues/"+url.PathEscape(issueID)+"/review/threads/"+url.PathEscape(threadID)+"/comments", map[string]any{
+		"author_agent_id": strings.TrimSpace(authorAgentID),
+		"body":            body,
+	}, &thread); err != nil {
+		return IssueReviewThread{}, err
+	}
+	return thread, nil
+}
+
+// SetIssueReviewThreadResolved resolves a thread, or reopens it when resolved
+// is false. note is only sent when resolving.
+func (c *Client) SetIssueReviewThreadResolved(issueID, threadID, agentID string, resolved bool, note string) (IssueReviewThread, error) {
+	issueID = strings.TrimSpace(issueID)
+	threadID = strings.TrimSpace(threadID)
+	if issueID == "" || threadID == "" {
+		return IssueReviewThread{}, errors.New("issue id and thread id are required")
+	}
+	action := "reopen"
+	input := map[string]any{"agent_id": strings.TrimSpace(agentID)}
+	if resolved {
+		action = "resolve"
+		if strings.TrimSpace(note) != "" {
+			input["note"] = strings.TrimSpace(note)
+		}
+	}
+	var thread IssueReviewThread
+	if err := c.adminRequest(http.MethodPost, "/api/issues/"+url.PathEscape(issueID)+"/review/threads/"+url.PathEscape(threadID)+"/"+action, input, &thread); err != nil {
+		return IssueReviewThread{}, err
+	}
+	return thread, nil
+}
+
+func (c *Client) CommentIssue(issueID, authorAgentID, body string) error {
+	if err := c.requireAuth(); err != nil {
+		return err
+	}
+	issueID = strings.TrimSpace(issueID)
+	authorAgentID = strings.TrimSpace(authorAgentID)
+	body = strings.TrimSpace(body)
+	if issueID == "" {
+		return errors.New("issue id is required")
+	}
+	if authorAgentID == "" {
+		return errors.New("author agent id is required")
+	}
+	if body == "" {
+		return errors.New("comment body is required")
+	}
+	payload, err := json.Marshal(map[string]string{
+		"author_agent_id": authorAgentID,
+		"body":            body,
+		"sender_type":     "agent",
+	})
+	if err != nil {
+		return err
+	}
+	req, err := c.newRequest(http.MethodPost, "/api/issues/"+url.PathEscape(issueID)+"/comments", bytes.NewReader(payload))
+	if err != nil {
+		return err
 	}
 	req.Header.Set("Content-Type", "application/json")
 	return c.do(req, nil)
@@ -2503,3 +3474,646 @@ func (c *Client) GetIssuePipelineStatus(issueID string) (IssuePipelineStatus, er
 	}
 	return status, nil
 }
+
+type BackupCreateOptions struct {
+	Since     string
+	AsOf      string
+	SkipRepos bool
+}
+
+type BackupValidation struct {
+	Valid       bool           `json:"valid"`
+	Version     int            `json:"version"`
+	OrgID       string         `json:"org_id"`
+	CreatedAt   string         `json:"created_at"`
+	Incremental bool           `json:"incremental"`
+	Tables      map[string]int `json:"tables"`
+	Repos       int            `json:"repos"`
+	TotalRows   int            `json:"total_rows"`
+	Errors      []string       `json:"errors"`
+	Warnings    []string       `json:"warnings"`
+}
+
+type BackupRestoreResult struct {
+	OrgID    string         `json:"org_id"`
+	DryRun   bool           `json:"dry_run"`
+	Remapped bool           `json:"remapped"`
+	Tables   map[string]int `json:"tables"`
+	Skipped  map[string]int `json:"skipped"`
+	Repos    int            `json:"repos"`
+	Warnings []string       `json:"warnings"`
+}
+
+// DownloadBackup streams a workspace archive into out and returns its size.
+func (c *Client) DownloadBackup(opts BackupCreateOptions, out io.Writer) (int64, error) {
+	if err := c.requireAuth(); err != nil {
+		return 0, err
+	}
+	q := url.Values{}
+	if since := strings.TrimSpace(opts.Since); since != "" {
+		q.Set("since", since)
+	}
+	if asOf := strings.TrimSpace(opts.AsOf); asOf != "" {
+		q.Set("as_of", asOf)
+	}
+	if opts.SkipRepos {
+		q.Set("skip_repos", "true")
+	}
+	path := "/api/backup"
+	if encoded := q.Encode(); encoded != "" {
+		path += "?" + encoded
+	}
+	req, err := c.newRequest(http.MethodGet, path, nil)
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Accept", "application/gzip, application/json")
+
+	resp, err := c.backupHTTPClient().Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 400 {
+		payload, _ := io.ReadAll(io.LimitReader(resp.Body, maxClientResponseBodyBytes))
+		return 0, &RequestError{
+			StatusCode: resp.StatusCode,
+			Detail:     summarizeResponseBody(resp.Header.Get("Content-Type"), payload),
+		}
+	}
+	return io.Copy(out, resp.Body)
+}
+
+func (c *Client) ValidateBackup(archive io.Reader) (BackupValidation, error) {
+	var response BackupValidation
+	if err := c.postBackup("/api/backup/validate", archive, &response); err != nil {
+		return BackupValidation{}, err
+	}
+	return response, nil
+}
+
+func (c *Client) RestoreBackup(archive io.Reader, remap, dryRun bool) (BackupRestoreResult, error) {
+	q := url.Values{}
+	q.Set("remap", strconv.FormatBool(remap))
+	q.Set("dry_run", strconv.FormatBool(dryRun))
+	var response BackupRestoreResult
+	if err := c.postBackup("/api/backup/restore?"+q.Encode(), archive, &response); err != nil {
+		return BackupRestoreResult{}, err
+	}
+	return response, nil
+}
+
+func (c *Client) postBackup(path string, archive io.Reader, out interface{}) error {
+	if err := c.requireAuth(); err != nil {
+		return err
+	}
+	req, err := c.newRequest(http.MethodPost, path, archive)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/gzip")
+	client := *c
+	client.HTTP = c.backupHTTPClient()
+	return client.do(req, out)
+}
+
+// backupHTTPClient drops the default request timeout; archives with git
+// bundles routinely take longer than a normal API call.
+func (c *Client) backupHTTPClient() *http.Client {
+	if c.HTTP == nil {
+		return &http.Client{}
+	}
+	client := *c.HTTP
+	client.Timeout = 0
+	return &client
+}
+
+type Capability struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+}
+
+type CapabilityListResponse struct {
+	Items []Capability `json:"items"`
+	Total int          `json:"total"`
+}
+
+type Role struct {
+	ID           string   `json:"id,omitempty"`
+	Name         string   `json:"name"`
+	Description  string   `json:"description"`
+	Builtin      bool     `json:"builtin"`
+	Capabilities []string `json:"capabilities"`
+	CreatedAt    string   `json:"created_at,omitempty"`
+	UpdatedAt    string   `json:"updated_at,omitempty"`
+}
+
+type RoleListResponse struct {
+	Items []Role `json:"items"`
+	Total int    `json:"total"`
+}
+
+type RoleBinding struct {
+	ID        string  `json:"id"`
+	OrgID     string  `json:"org_id"`
+	UserID    string  `json:"user_id"`
+	Role      string  `json:"role"`
+	ProjectID *string `json:"project_id,omitempty"`
+	CreatedBy *string `json:"created_by,omitempty"`
+	CreatedAt string  `json:"created_at"`
+}
+
+type RoleBindingListResponse struct {
+	Items []RoleBinding `json:"items"`
+	Total int           `json:"total"`
+}
+
+func (c *Client) ListCapabilities() (CapabilityListResponse, error) {
+	var response CapabilityListResponse
+	if err := c.adminRequest(http.MethodGet, "/api/admin/capabilities", nil, &response); err != nil {
+		return CapabilityListResponse{}, err
+	}
+	return response, nil
+}
+
+func (c *Client) ListRoles() (RoleListResponse, error) {
+	var response RoleListResponse
+	if err := c.adminRequest(http.MethodGet, "/api/admin/roles", nil, &response); err != nil {
+		return RoleListResponse{}, err
+	}
+	return response, nil
+}
+
+func (c *Client) CreateRole(input map[string]any) (Role, error) {
+	var response Role
+	if err := c.adminRequest(http.MethodPost, "/api/admin/roles", input, &response); err != nil {
+		return Role{}, err
+	}
+	return response, nil
+}
+
+func (c *Client) UpdateRole(roleID string, input map[string]any) (Role, error) {
+	roleID = strings.TrimSpace(roleID)
+	if roleID == "" {
+		return Role{}, errors.New("role id is required")
+	}
+	var response Role
+	if err := c.adminRequest(http.MethodPatch, "/api/admin/roles/"+url.PathEscape(roleID), input, &response); err != nil {
+		return Role{}, err
+	}
+	return response, nil
+}
+
+func (c *Client) DeleteRole(roleID string) error {
+	roleID = strings.TrimSpace(roleID)
+	if roleID == "" {
+		return errors.New("role id is required")
+	}
+	return c.adminRequest(http.MethodDelete, "/api/admin/roles/"+url.PathEscape(roleID), nil, nil)
+}
+
+func (c *Client) ListRoleBindings(userID, projectID string) (RoleBindingListResponse, error) {
+	q := url.Values{}
+	if userID = strings.TrimSpace(userID); userID != "" {
+		q.Set("user_id", userID)
+	}
+	if projectID = strings.TrimSpace(projectID); projectID != "" {
+		q.Set("project_id", projectID)
+	}
+	path := "/api/admin/role-bindings"
+	if encoded := q.Encode(); encoded != "" {
+		path += "?" + encoded
+	}
+	var response RoleBindingListResponse
+	if err := c.adminRequest(http.MethodGet, path, nil, &response); err != nil {
+		return RoleBindingListResponse{}, err
+	}
+	return response, nil
+}
+
+func (c *Client) CreateRoleBinding(input map[string]any) (RoleBinding, error) {
+	var response RoleBinding
+	if err := c.adminRequest(http.MethodPost, "/api/admin/role-bindings", input, &response); err != nil {
+		return RoleBinding{}, err
+	}
+	return response, nil
+}
+
+func (c *Client) DeleteRoleBinding(bindingID string) error {
+	bindingID = strings.TrimSpace(bindingID)
+	if bindingID == "" {
+		return errors.New("binding id is required")
+	}
+	return c.adminRequest(http.MethodDelete, "/api/admin/role-bindings/"+url.PathEscape(bindingID), nil, nil)
+}
+
+func (c *Client) adminRequest(method, path string, input map[string]any, out interface{}) error {
+	if err := c.requireAuth(); err != nil {
+		return err
+	}
+	var body io.Reader
+	if input != nil {
+		payload, err := json.Marshal(input)
+		if err != nil {
+			return err
+		}
+		body = bytes.NewReader(payload)
+	}
+	req, err := c.newRequest(method, path, body)
+	if err != nil {
+		return err
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	return c.do(req, out)
+}
+
+type AuditEntry struct {
+	ID         int64           `json:"id"`
+	Seq        int64           `json:"seq"`
+	ActorType  string          `json:"actor_type"`
+	ActorID    string          `json:"actor_id"`
+	Action     string          `json:"action"`
+	TargetType string          `json:"target_type"`
+	TargetID   string          `json:"target_id"`
+	Before     json.RawMessage `json:"before,omitempty"`
+	After      json.RawMessage `json:"after,omitempty"`
+	Diff       json.RawMessage `json:"diff,omitempty"`
+	IP         string          `json:"ip"`
+	UserAgent  string          `json:"user_agent"`
+	CreatedAt  string          `json:"created_at"`
+	PrevHash   string          `json:"prev_hash"`
+	Hash       string          `json:"hash"`
+}
+
+type AuditListResponse struct {
+	Items      []AuditEntry `json:"items"`
+	Total      int          `json:"total"`
+	NextCursor string       `json:"next_cursor,omitempty"`
+}
+
+type AuditVerifyResult struct {
+	Valid     bool   `json:"valid"`
+	Checked   int64  `json:"checked"`
+	BrokenSeq *int64 `json:"broken_seq,omitempty"`
+	Reason    string `json:"reason,omitempty"`
+}
+
+// AuditFilter narrows audit queries. Action accepts a trailing ".*" to match
+// a whole namespace; Since and Until take RFC 3339 timestamps or dates.
+type AuditFilter struct {
+	Action     string
+	Actor      string
+	TargetType string
+	TargetID   string
+	Since      string
+	Until      string
+	Cursor     string
+	Limit      int
+}
+
+func (f AuditFilter) query() url.Values {
+	q := url.Values{}
+	for key, value := range map[string]string{
+		"action":      f.Action,
+		"actor":       f.Actor,
+		"target_type": f.TargetType,
+		"target_id":   f.TargetID,
+		"since":       f.Since,
+		"until":       f.Until,
+		"cursor":      f.Cursor,
+	} {
+		if value = strings.TrimSpace(value); value != "" {
+			q.Set(key, value)
+		}
+	}
+	if f.Limit > 0 {
+		q.Set("limit", strconv.Itoa(f.Limit))
+	}
+	return q
+}
+
+func (c *Client) ListAudit(filter AuditFilter) (AuditListResponse, error) {
+	path := "/api/admin/audit"
+	if encoded := filter.query().Encode(); encoded != "" {
+		path += "?" + encoded
+	}
+	var response AuditListResponse
+	if err := c.adminRequest(http.MethodGet, path, nil, &response); err != nil {
+		return AuditListResponse{}, err
+	}
+	return response, nil
+}
+
+// ExportAudit streams every matching entry to out as jsonl or csv.
+func (c *Client) ExportAudit(filter AuditFilter, format string, out io.Writer) (int64, error) {
+	if err := c.requireAuth(); err != nil {
+		return 0, err
+	}
+	q := filter.query()
+	q.Del("cursor")
+	q.Del("limit")
+	if format = strings.TrimSpace(format); format != "" {
+		q.Set("format", format)
+	}
+	path := "/api/admin/audit/export"
+	if encoded := q.Encode(); encoded != "" {
+		path += "?" + encoded
+	}
+	req, err := c.newRequest(http.MethodGet, path, nil)
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Accept", "application/x-ndjson, text/csv, application/json")
+
+	resp, err := c.backupHTTPClient().Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 400 {
+		payload, _ := io.ReadAll(io.LimitReader(resp.Body, maxClientResponseBodyBytes))
+		return 0, &RequestError{
+			StatusCode: resp.StatusCode,
+			Detail:     summarizeResponseBody(resp.Header.Get("Content-Type"), payload),
+		}
+	}
+	return io.Copy(out, resp.Body)
+}
+
+func (c *Client) VerifyAudit() (AuditVerifyResult, error) {
+	var response AuditVerifyResult
+	if err := c.adminRequest(http.MethodGet, "/api/admin/audit/verify", nil, &response); err != nil {
+		return AuditVerifyResult{}, err
+	}
+	return response, nil
+}
+
+type UsageReportRow struct {
+	Key            string  `json:"key"`
+	Label          string  `json:"label,omitempty"`
+	Tokens         int64   `json:"tokens"`
+	CostUSD        float64 `json:"cost_usd"`
+	Events         int64   `json:"events"`
+	UnpricedTokens int64   `json:"unpriced_tokens,omitempty"`
+}
+
+type UsageReport struct {
+	From           string           `json:"from"`
+	To             string           `json:"to"`
+	GroupBy        string           `json:"group_by"`
+	Tokens         int64            `json:"tokens"`
+	CostUSD        float64          `json:"cost_usd"`
+	Events         int64            `json:"events"`
+	UnpricedTokens int64            `json:"unpriced_tokens"`
+	Items          []UsageReportRow `json:"items"`
+}
+
+type ModelPrice struct {
+	Model               string  `json:"model"`
+	USDPerMillionTokens float64 `json:"usd_per_million_tokens"`
+	UpdatedAt           string  `json:"updated_at,omitempty"`
+}
+
+type UsageBudget struct {
+	ID             string  `json:"id"`
+	Scope          string  `json:"scope"`
+	ScopeID        string  `json:"scope_id"`
+	Period         string  `json:"period"`
+	LimitUSD       float64 `json:"limit_usd"`
+	SoftPercent    int     `json:"soft_percent"`
+	Hard           bool    `json:"hard"`
+	PeriodStart    string  `json:"period_start,omitempty"`
+	SpentUSD       float64 `json:"spent_usd"`
+	SoftNotifiedAt string  `json:"soft_notified_at,omitempty"`
+	HardTrippedAt  string  `json:"hard_tripped_at,omitempty"`
+}
+
+// UsageFilter narrows a usage report. Month takes YYYY-MM and is exclusive
+// with From/To, which take YYYY-MM-DD dates (To is exclusive).
+type UsageFilter struct {
+	Month     string
+	From      string
+	To        string
+	GroupBy   string
+	AgentID   string
+	ProjectID string
+	Model     string
+}
+
+func (f UsageFilter) query() url.Values {
+	q := url.Values{}
+	for key, value := range map[string]string{
+		"month":    f.Month,
+		"from":     f.From,
+		"to":       f.To,
+		"group_by": f.GroupBy,
+		"agent":    f.AgentID,
+		"project":  f.ProjectID,
+		"model":    f.Model,
+	} {
+		if value = strings.TrimSpace(value); value != "" {
+			q.Set(key, value)
+		}
+	}
+	return q
+}
+
+func (c *Client) UsageReport(filter UsageFilter) (UsageReport, error) {
+	path := "/api/usage"
+	if encoded := filter.query().Encode(); encoded != "" {
+		path += "?" + encoded
+	}
+	var response UsageReport
+	if err := c.adminRequest(http.MethodGet, path, nil, &response); err != nil {
+		return UsageReport{}, err
+	}
+	return response, nil
+}
+
+func (c *Client) ListModelPricing() ([]ModelPrice, error) {
+	var response struct {
+		Items []ModelPrice `json:"items"`
+	}
+	if err := c.adminRequest(http.MethodGet, "/api/usage/pricing", nil, &response); err != nil {
+		return nil, err
+	}
+	return response.Items, nil
+}
+
+func (c *Client) SetModelPrice(model string, usdPerMillionTokens float64) (ModelPrice, error) {
+	var response ModelPrice
+	err := c.adminRequest(http.MethodPut, "/api/usage/pricing", map[string]any{
+		"model":                  strings.TrimSpace(model),
+		"usd_per_million_tokens": usdPerMillionTokens,
+	}, &response)
+	if err != nil {
+		return ModelPrice{}, err
+	}
+	return response, nil
+}
+
+func (c *Client) DeleteModelPrice(model string) error {
+	path := "/api/usage/pricing?" + url.Values{"model": {strings.TrimSpace(model)}}.Encode()
+	return c.adminRequest(http.MethodDelete, path, nil, nil)
+}
+
+func (c *Client) ListUsageBudgets() ([]UsageBudget, error) {
+	var response struct {
+		Items []UsageBudget `json:"items"`
+	}
+	if err := c.adminRequest(http.MethodGet, "/api/usage/budgets", nil, &response); err != nil {
+		return nil, err
+	}
+	return response.Items, nil
+}
+
+// SetUsageBudget creates or replaces the budget for a scope and period.
+// Supported input keys: scope, scope_id, period, limit_usd, soft_percent, hard.
+func (c *Client) SetUsageBudget(input map[string]any) (UsageBudget, error) {
+	var response UsageBudget
+	if err := c.adminRequest(http.MethodPost, "/api/usage/budgets", input, &response); err != nil {
+		return UsageBudget{}, err
+	}
+	return response, nil
+}
+
+func (c *Client) UpdateUsageBudget(budgetID string, input map[string]any) (UsageBudget, error) {
+	budgetID = strings.TrimSpace(budgetID)
+	if budgetID == "" {
+		return UsageBudget{}, errors.New("budget id is required")
+	}
+	var response UsageBudget
+	if err := c.adminRequest(http.MethodPatch, "/api/usage/budgets/"+url.PathEscape(budgetID), input, &response); err != nil {
+		return UsageBudget{}, err
+	}
+	return response, nil
+}
+
+// DeleteUsageBudget removes a budget and returns how many jobs it resumed.
+func (c *Client) DeleteUsageBudget(budgetID string) (int, error) {
+	budgetID = strings.TrimSpace(budgetID)
+	if budgetID == "" {
+		return 0, errors.New("budget id is required")
+	}
+	var response struct {
+		ResumedJobs int `json:"resumed_jobs"`
+	}
+	if err := c.adminRequest(http.MethodDelete, "/api/usage/budgets/"+url.PathEscape(budgetID), nil, &response); err != nil {
+		return 0, err
+	}
+	return response.ResumedJobs, nil
+}
+
+type AgentScorecardStep struct {
+	StepID     string   `json:"step_id"`
+	StepName   string   `json:"step_name"`
+	StepType   string   `json:"step_type"`
+	Completed  int      `json:"completed"`
+	AvgSeconds *float64 `json:"avg_seconds,omitempty"`
+}
+
+type AgentScorecardPeriod struct {
+	From                       string               `json:"from"`
+	To                         string               `json:"to"`
+	IssuesWorked               int                  `json:"issues_worked"`
+	StepsCompleted             int                  `json:"steps_completed"`
+	IssuesClosed               int                  `json:"issues_closed"`
+	FirstPassAcceptanceRate    *float64             `json:"first_pass_acceptance_rate,omitempty"`
+	Rejections                 int                  `json:"rejections"`
+	AvgRejectionsPerIssue      float64              `json:"avg_rejections_per_issue"`
+	AvgStepSeconds             *float64             `json:"avg_step_seconds,omitempty"`
+	Steps                      []AgentScorecardStep `json:"steps"`
+	ReviewsReceived            int                  `json:"reviews_received"`
+	ReviewsAddressed           int                  `json:"reviews_addressed"`
+	AvgReviewTurnaroundSeconds *float64             `json:"avg_review_turnaround_seconds,omitempty"`
+	FlowBlockersRaised         int                  `json:"flow_blockers_raised"`
+	ComplianceFailures         int                  `json:"compliance_failures"`
+	Runs                       int                  `json:"runs"`
+	FailedRuns                 int                  `json:"failed_runs"`
+	AvgRunSeconds              *float64             `json:"avg_run_seconds,omitempty"`
+	Tokens                     int64                `json:"tokens"`
+	CostUSD                    float64              `json:"cost_usd"`
+}
+
+type AgentScorecard struct {
+	Agent struct {
+		ID          string `json:"id"`
+		Slug        string `json:"slug"`
+		DisplayName string `json:"display_name"`
+	} `json:"agent"`
+	Days    int                    `json:"days"`
+	Periods []AgentScorecardPeriod `json:"periods"`
+}
+
+// AgentReviewOptions sizes a scorecard: Since is a window such as "30d" or
+// "4w", and Periods is how many consecutive windows to compare.
+type AgentReviewOptions struct {
+	Since   string
+	Periods int
+}
+
+func agentReviewPath(agentID string, opts AgentReviewOptions, format string) (string, error) {
+	agentID = strings.TrimSpace(agentID)
+	if agentID == "" {
+		return "", errors.New("agent id is required")
+	}
+	q := url.Values{}
+	if since := strings.TrimSpace(opts.Since); since != "" {
+		q.Set("since", since)
+	}
+	if opts.Periods > 0 {
+		q.Set("periods", strconv.Itoa(opts.Periods))
+	}
+	if format = strings.TrimSpace(format); format != "" {
+		q.Set("format", format)
+	}
+	path := "/api/agents/" + url.PathEscape(agentID) + "/review"
+	if encoded := q.Encode(); encoded != "" {
+		path += "?" + encoded
+	}
+	return path, nil
+}
+
+func (c *Client) AgentReview(agentID string, opts AgentReviewOptions) (AgentScorecard, error) {
+	path, err := agentReviewPath(agentID, opts, "")
+	if err != nil {
+		return AgentScorecard{}, err
+	}
+	var response AgentScorecard
+	if err := c.adminRequest(http.MethodGet, path, nil, &response); err != nil {
+		return AgentScorecard{}, err
+	}
+	return response, nil
+}
+
+// ExportAgentReview writes the scorecard rendered as markdown or csv to out.
+func (c *Client) ExportAgentReview(agentID string, opts AgentReviewOptions, format string, out io.Writer) (int64, error) {
+	if err := c.requireAuth(); err != nil {
+		return 0, err
+	}
+	path, err := agentReviewPath(agentID, opts, format)
+	if err != nil {
+		return 0, err
+	}
+	req, err := c.newRequest(http.MethodGet, path, nil)
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Accept", "text/markdown, text/csv, application/json")
+
+	resp, err := c.HTTP.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 400 {
+		payload, _ := io.ReadAll(io.LimitReader(resp.Body, maxClientResponseBodyBytes))
+		return 0, &RequestError{
+			StatusCode: resp.StatusCode,
+			Detail:     summarizeResponseBody(resp.Header.Get("Content-Type"), payload),
+		}
+	}
+	return io.Copy(out, resp.Body)
+}
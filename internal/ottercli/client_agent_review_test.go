@@ -0,0 +1,60 @@
+package ottercli
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestClientAgentReviewMethodsUseExpectedPaths(t *testing.T) {
+	var gotPaths []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotPaths = append(gotPaths, r.Method+" "+r.URL.String())
+		switch r.URL.Query().Get("format") {
+		case "markdown":
+			w.Header().Set("Content-Type", "text/markdown")
+			_, _ = w.Write([]byte("# Performance review: Writer (writer)\n"))
+		case "pdf":
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusBadRequest)
+			_, _ = w.Write([]byte(`{"error":"format must be json, markdown or csv"}`))
+		default:
+			w.Header().Set("Content-Type", "application/json")
+			_, _ = w.Write([]byte(`{"agent":{"id":"a-1","slug":"writer"},"days":30,"periods":[{"from":"2026-09-19T00:00:00Z","issues_worked":4,"first_pass_acceptance_rate":0.75,"steps":[]}]}`))
+		}
+	}))
+	defer srv.Close()
+
+	client := &Client{BaseURL: srv.URL, Token: "token-1", OrgID: "org-1", HTTP: srv.Client()}
+
+	card, err := client.AgentReview("a-1", AgentReviewOptions{Since: "30d", Periods: 3})
+	if err != nil || card.Agent.Slug != "writer" || len(card.Periods) != 1 || *card.Periods[0].FirstPassAcceptanceRate != 0.75 {
+		t.Fatalf("AgentReview() = %#v, %v", card, err)
+	}
+	var buf bytes.Buffer
+	n, err := client.ExportAgentReview("a-1", AgentReviewOptions{}, "markdown", &buf)
+	if err != nil || n != int64(buf.Len()) || buf.String() != "# Performance review: Writer (writer)\n" {
+		t.Fatalf("ExportAgentReview() = %d %q, %v", n, buf.String(), err)
+	}
+	if _, err := client.ExportAgentReview("a-1", AgentReviewOptions{}, "pdf", &buf); err == nil {
+		t.Fatalf("ExportAgentReview() expected error for bad format")
+	}
+	if _, err := client.AgentReview(" ", AgentReviewOptions{}); err == nil {
+		t.Fatalf("AgentReview() expected error for empty agent")
+	}
+
+	want := []string{
+		"GET /api/agents/a-1/review?periods=3&since=30d",
+		"GET /api/agents/a-1/review?format=markdown",
+		"GET /api/agents/a-1/review?format=pdf",
+	}
+	if len(gotPaths) != len(want) {
+		t.Fatalf("paths = %v", gotPaths)
+	}
+	for i := range want {
+		if gotPaths[i] != want[i] {
+			t.Fatalf("path[%d] = %q, want %q", i, gotPaths[i], want[i])
+		}
+	}
+}
@@ -0,0 +1,59 @@
+package ottercli
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestClientAuditMethodsUseExpectedPaths(t *testing.T) {
+	var gotPaths []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotPaths = append(gotPaths, r.Method+" "+r.URL.String())
+		switch r.URL.Path {
+		case "/api/admin/audit":
+			w.Header().Set("Content-Type", "application/json")
+			_, _ = w.Write([]byte(`{"items":[{"seq":2,"action":"role.create","actor_type":"user","actor_id":"u-1"}],"total":1,"next_cursor":"2"}`))
+		case "/api/admin/audit/export":
+			w.Header().Set("Content-Type", "application/x-ndjson")
+			_, _ = w.Write([]byte("{\"seq\":2}\n{\"seq\":1}\n"))
+		case "/api/admin/audit/verify":
+			w.Header().Set("Content-Type", "application/json")
+			_, _ = w.Write([]byte(`{"valid":false,"checked":1,"broken_seq":1,"reason":"hash mismatch"}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer srv.Close()
+
+	client := &Client{BaseURL: srv.URL, Token: "token-1", OrgID: "org-1", HTTP: srv.Client()}
+
+	listed, err := client.ListAudit(AuditFilter{Action: "role.*", Cursor: "10", Limit: 1})
+	if err != nil || len(listed.Items) != 1 || listed.NextCursor != "2" {
+		t.Fatalf("ListAudit() = %#v, %v", listed, err)
+	}
+	var buf bytes.Buffer
+	n, err := client.ExportAudit(AuditFilter{Actor: "u-1", Cursor: "10", Limit: 5}, "jsonl", &buf)
+	if err != nil || n != int64(buf.Len()) || buf.String() != "{\"seq\":2}\n{\"seq\":1}\n" {
+		t.Fatalf("ExportAudit() = %d %q, %v", n, buf.String(), err)
+	}
+	verify, err := client.VerifyAudit()
+	if err != nil || verify.Valid || verify.BrokenSeq == nil || *verify.BrokenSeq != 1 {
+		t.Fatalf("VerifyAudit() = %#v, %v", verify, err)
+	}
+
+	want := []string{
+		"GET /api/admin/audit?action=role.%2A&cursor=10&limit=1",
+		"GET /api/admin/audit/export?actor=u-1&format=jsonl",
+		"GET /api/admin/audit/verify",
+	}
+	if len(gotPaths) != len(want) {
+		t.Fatalf("paths = %v", gotPaths)
+	}
+	for i := range want {
+		if gotPaths[i] != want[i] {
+			t.Fatalf("path[%d] = %q, want %q", i, gotPaths[i], want[i])
+		}
+	}
+}
@@ -0,0 +1,97 @@
+package ottercli
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestClientBackupMethodsUseExpectedPathsAndBodies(t *testing.T) {
+	var gotPaths []string
+	var gotBodies []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotPaths = append(gotPaths, r.Method+" "+r.URL.String())
+		body, _ := io.ReadAll(r.Body)
+		gotBodies = append(gotBodies, string(body))
+		if r.Method == http.MethodPost && r.Header.Get("Content-Type") != "application/gzip" {
+			t.Fatalf("content type = %q, want application/gzip", r.Header.Get("Content-Type"))
+		}
+
+		switch {
+		case r.Method == http.MethodGet && r.URL.Path == "/api/backup":
+			w.Header().Set("Content-Type", "application/gzip")
+			_, _ = w.Write([]byte("archive-bytes"))
+		case r.Method == http.MethodPost && r.URL.Path == "/api/backup/validate":
+			w.Header().Set("Content-Type", "application/json")
+			_, _ = w.Write([]byte(`{"valid":true,"version":1,"org_id":"org-1","tables":{"projects":2},"total_rows":2,"errors":[],"warnings":["w1"]}`))
+		case r.Method == http.MethodPost && r.URL.Path == "/api/backup/restore":
+			w.Header().Set("Content-Type", "application/json")
+			_, _ = w.Write([]byte(`{"org_id":"org-2","dry_run":true,"remapped":false,"tables":{"projects":2},"skipped":{},"repos":1,"warnings":[]}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = w.Write([]byte(`{"error":"not found"}`))
+		}
+	}))
+	defer srv.Close()
+
+	client := &Client{BaseURL: srv.URL, Token: "token-1", OrgID: "org-1", HTTP: srv.Client()}
+
+	var archive bytes.Buffer
+	size, err := client.DownloadBackup(BackupCreateOptions{Since: "2026-10-01T00:00:00Z", SkipRepos: true}, &archive)
+	if err != nil {
+		t.Fatalf("DownloadBackup() error = %v", err)
+	}
+	if size != int64(len("archive-bytes")) || archive.String() != "archive-bytes" {
+		t.Fatalf("DownloadBackup() wrote %d bytes %q", size, archive.String())
+	}
+
+	validation, err := client.ValidateBackup(strings.NewReader("archive-bytes"))
+	if err != nil {
+		t.Fatalf("ValidateBackup() error = %v", err)
+	}
+	if !validation.Valid || validation.Tables["projects"] != 2 || len(validation.Warnings) != 1 {
+		t.Fatalf("unexpected validation %#v", validation)
+	}
+
+	restored, err := client.RestoreBackup(strings.NewReader("archive-bytes"), false, true)
+	if err != nil {
+		t.Fatalf("RestoreBackup() error = %v", err)
+	}
+	if !restored.DryRun || restored.Remapped || restored.Repos != 1 {
+		t.Fatalf("unexpected restore result %#v", restored)
+	}
+
+	wantPaths := []string{
+		"GET /api/backup?since=2026-10-01T00%3A00%3A00Z&skip_repos=true",
+		"POST /api/backup/validate",
+		"POST /api/backup/restore?dry_run=true&remap=false",
+	}
+	if strings.Join(gotPaths, "\n") != strings.Join(wantPaths, "\n") {
+		t.Fatalf("paths = %v, want %v", gotPaths, wantPaths)
+	}
+	if gotBodies[1] != "archive-bytes" || gotBodies[2] != "archive-bytes" {
+		t.Fatalf("archive body not forwarded: %q", gotBodies)
+	}
+}
+
+func TestClientDownloadBackupSurfacesErrors(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusForbidden)
+		_, _ = w.Write([]byte(`{"error":"forbidden","capability":"workspace.backup"}`))
+	}))
+	defer srv.Close()
+
+	client := &Client{BaseURL: srv.URL, Token: "token-1", OrgID: "org-1", HTTP: srv.Client()}
+	var archive bytes.Buffer
+	_, err := client.DownloadBackup(BackupCreateOptions{}, &archive)
+	if status, ok := HTTPStatusCode(err); !ok || status != http.StatusForbidden {
+		t.Fatalf("DownloadBackup() error = %v, want 403", err)
+	}
+	if archive.Len() != 0 {
+		t.Fatalf("error body should not be written to the archive")
+	}
+}
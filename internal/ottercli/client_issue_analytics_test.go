@@ -0,0 +1,60 @@
+package ottercli
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestClientProjectAnalyticsMethodsUseExpectedPaths(t *testing.T) {
+	var gotPaths []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotPaths = append(gotPaths, r.Method+" "+r.URL.String())
+		switch r.URL.Query().Get("dataset") {
+		case "steps":
+			w.Header().Set("Content-Type", "text/csv")
+			_, _ = w.Write([]byte("group,group_name,kind\nall,All issues,pipeline\n"))
+		case "people":
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusBadRequest)
+			_, _ = w.Write([]byte(`{"error":"dataset must be groups, steps, series or issues"}`))
+		default:
+			w.Header().Set("Content-Type", "application/json")
+			_, _ = w.Write([]byte(`{"project_id":"p-1","days":14,"bucket":"day","group_by":"agent","overall":{"key":"all","completed":3,"cycle_time":{"count":3,"p50_seconds":7200},"rework_bounces":2,"series":[{"start":"2026-10-05T00:00:00Z","throughput":1,"wip":2}]},"groups":[{"key":"a-1","name":"Reviewer"}],"issues":[]}`))
+		}
+	}))
+	defer srv.Close()
+
+	client := &Client{BaseURL: srv.URL, Token: "token-1", OrgID: "org-1", HTTP: srv.Client()}
+
+	report, err := client.ProjectAnalytics("p-1", ProjectAnalyticsOptions{Since: "14d", GroupBy: "agent", Dataset: "steps"})
+	if err != nil || report.Overall.Completed != 3 || *report.Overall.CycleTime.P50Seconds != 7200 || len(report.Groups) != 1 || report.Overall.Series[0].WIP != 2 {
+		t.Fatalf("ProjectAnalytics() = %#v, %v", report, err)
+	}
+	var buf bytes.Buffer
+	n, err := client.ExportProjectAnalytics("p-1", ProjectAnalyticsOptions{Bucket: "week", Dataset: "steps"}, &buf)
+	if err != nil || n != int64(buf.Len()) || buf.String() != "group,group_name,kind\nall,All issues,pipeline\n" {
+		t.Fatalf("ExportProjectAnalytics() = %d %q, %v", n, buf.String(), err)
+	}
+	if _, err := client.ExportProjectAnalytics("p-1", ProjectAnalyticsOptions{Dataset: "people"}, &buf); err == nil {
+		t.Fatalf("ExportProjectAnalytics() expected error for bad dataset")
+	}
+	if _, err := client.ProjectAnalytics(" ", ProjectAnalyticsOptions{}); err == nil {
+		t.Fatalf("ProjectAnalytics() expected error for empty project")
+	}
+
+	want := []string{
+		"GET /api/projects/p-1/analytics?group_by=agent&since=14d",
+		"GET /api/projects/p-1/analytics?bucket=week&dataset=steps&format=csv",
+		"GET /api/projects/p-1/analytics?dataset=people&format=csv",
+	}
+	if len(gotPaths) != len(want) {
+		t.Fatalf("paths = %v", gotPaths)
+	}
+	for i := range want {
+		if gotPaths[i] != want[i] {
+			t.Fatalf("path[%d] = %q, want %q", i, gotPaths[i], want[i])
+		}
+	}
+}
@@ -0,0 +1,51 @@
+package ottercli
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+func TestClientBulkIssuesPostsOperations(t *testing.T) {
+	var gotPath string
+	var gotBody map[string]any
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotPath = r.Method + " " + r.URL.Path
+		_ = json.NewDecoder(r.Body).Decode(&gotBody)
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"dry_run":true,"applied":false,"matched":1,"updated":1,"items":[{"issue_id":"i-1","issue_number":7,"status":"updated","changes":["priority: P2 -> P1"]}]}`))
+	}))
+	defer srv.Close()
+
+	client := &Client{BaseURL: srv.URL, Token: "token-1", OrgID: "org-1", HTTP: srv.Client()}
+	result, err := client.BulkIssues(IssueBulkRequest{
+		Query:      "is:open label:triage",
+		ProjectID:  "p-1",
+		Operations: IssueBulkOperations{Priority: "P1", AddLabels: []string{"triaged"}},
+		DryRun:     true,
+	})
+	if err != nil {
+		t.Fatalf("BulkIssues() error = %v", err)
+	}
+	if !result.DryRun || result.Updated != 1 || result.Items[0].Changes[0] != "priority: P2 -> P1" {
+		t.Fatalf("BulkIssues() = %#v", result)
+	}
+	if gotPath != "POST /api/issues/bulk" {
+		t.Fatalf("path = %q", gotPath)
+	}
+	wantBody := map[string]any{
+		"query":      "is:open label:triage",
+		"project_id": "p-1",
+		"dry_run":    true,
+		"operations": map[string]any{"priority": "P1", "add_labels": []any{"triaged"}},
+	}
+	if !reflect.DeepEqual(gotBody, wantBody) {
+		t.Fatalf("body = %#v, want %#v", gotBody, wantBody)
+	}
+
+	if _, err := client.BulkIssues(IssueBulkRequest{Operations: IssueBulkOperations{Priority: "P1"}}); err == nil {
+		t.Fatal("BulkIssues() without targets should fail")
+	}
+}
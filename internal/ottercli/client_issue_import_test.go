@@ -0,0 +1,49 @@
+package ottercli
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestClientImportIssues(t *testing.T) {
+	var body map[string]any
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.URL.Path != "/api/projects/p-1/issue-imports" {
+			t.Fatalf("unexpected request %s %s", r.Method, r.URL.Path)
+		}
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"dry_run":true,"source":"jira","total":2,"created":2,"items":[{"key":"OPS-1","title":"Migrate billing","action":"create","priority":"P1","comments":0}],"summary":{"unmapped_statuses":["Parked"],"warnings":["dry run: no issues, labels or comments were written"]}}`))
+	}))
+	defer srv.Close()
+
+	client := &Client{BaseURL: srv.URL, Token: "token-1", OrgID: "org-1", HTTP: srv.Client()}
+	result, err := client.ImportIssues("p-1", IssueImportRequest{
+		Source:      "jira",
+		Content:     `{"issues":[]}`,
+		StatusMap:   map[string]string{"Parked": "on_hold"},
+		AssigneeMap: map[string]string{"Jane Doe": "analyst-1"},
+		DryRun:      true,
+	})
+	if err != nil {
+		t.Fatalf("ImportIssues() error = %v", err)
+	}
+	if body["source"] != "jira" || body["dry_run"] != true || body["status_map"].(map[string]any)["Parked"] != "on_hold" {
+		t.Fatalf("request body = %#v", body)
+	}
+	if _, ok := body["columns"]; ok {
+		t.Fatalf("empty columns should be omitted: %#v", body)
+	}
+	if result.Created != 2 || len(result.Items) != 1 || result.Items[0].Priority != "P1" || result.Summary.UnmappedStatuses[0] != "Parked" {
+		t.Fatalf("ImportIssues() = %#v", result)
+	}
+
+	if _, err := client.ImportIssues("", IssueImportRequest{Source: "jira"}); err == nil {
+		t.Fatal("expected missing project error")
+	}
+	if _, err := client.ImportIssues("p-1", IssueImportRequest{}); err == nil {
+		t.Fatal("expected missing source error")
+	}
+}
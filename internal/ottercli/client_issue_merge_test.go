@@ -0,0 +1,35 @@
+package ottercli
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestClientMergeIssue(t *testing.T) {
+	var body map[string]any
+	var path string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path = r.Method + " " + r.URL.Path
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"duplicate":{"id":"i-2","state":"closed","merged_into_issue_id":"i-1"},"canonical":{"id":"i-1"},"moved_comments":3,"moved_github_link":true}`))
+	}))
+	defer srv.Close()
+
+	client := &Client{BaseURL: srv.URL, Token: "token-1", OrgID: "org-1", HTTP: srv.Client()}
+	result, err := client.MergeIssue("i-2", "i-1")
+	if err != nil {
+		t.Fatalf("MergeIssue() error = %v", err)
+	}
+	if path != "POST /api/issues/i-2/merge" || body["into_issue_id"] != "i-1" {
+		t.Fatalf("request = %s %#v", path, body)
+	}
+	if result.MovedComments != 3 || !result.MovedGitHubLink || *result.Duplicate.MergedIntoIssueID != "i-1" {
+		t.Fatalf("MergeIssue() = %#v", result)
+	}
+	if _, err := client.MergeIssue("i-2", " "); err == nil {
+		t.Fatal("MergeIssue() without a target should fail")
+	}
+}
@@ -0,0 +1,64 @@
+package ottercli
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestClientIssueReviewThreads(t *testing.T) {
+	var requests []string
+	var lastBody map[string]any
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests = append(requests, r.Method+" "+r.URL.RequestURI())
+		lastBody = nil
+		_ = json.NewDecoder(r.Body).Decode(&lastBody)
+		w.Header().Set("Content-Type", "application/json")
+		if r.Method == http.MethodGet {
+			_, _ = w.Write([]byte(`{"head_sha":"abc","items":[{"id":"t-1","status":"open","anchored_start_line":5,"anchored_end_line":6}],"total":1,"open":1}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"id":"t-1","status":"resolved"}`))
+	}))
+	defer srv.Close()
+
+	client := &Client{BaseURL: srv.URL, Token: "token-1", OrgID: "org-1", HTTP: srv.Client()}
+	list, err := client.ListIssueReviewThreads("i-1", "open")
+	if err != nil || list.Open != 1 || list.Items[0].AnchoredStartLine != 5 {
+		t.Fatalf("ListIssueReviewThreads() = %#v, %v", list, err)
+	}
+	if _, err := client.ReplyIssueReviewThread("i-1", "t-1", "a-1", "Fixed"); err != nil {
+		t.Fatalf("ReplyIssueReviewThread() error = %v", err)
+	}
+	if lastBody["author_agent_id"] != "a-1" || lastBody["body"] != "Fixed" {
+		t.Fatalf("reply body = %#v", lastBody)
+	}
+	if _, err := client.SetIssueReviewThreadResolved("i-1", "t-1", "a-1", true, "done"); err != nil {
+		t.Fatalf("SetIssueReviewThreadResolved() error = %v", err)
+	}
+	if lastBody["note"] != "done" {
+		t.Fatalf("resolve body = %#v", lastBody)
+	}
+	if _, err := client.SetIssueReviewThreadResolved("i-1", "t-1", "a-1", false, "ignored"); err != nil {
+		t.Fatalf("SetIssueReviewThreadResolved(reopen) error = %v", err)
+	}
+	if _, ok := lastBody["note"]; ok {
+		t.Fatalf("reopen should not send a note: %#v", lastBody)
+	}
+
+	want := []string{
+		"GET /api/issues/i-1/review/threads?status=open",
+		"POST /api/issues/i-1/review/threads/t-1/comments",
+		"POST /api/issues/i-1/review/threads/t-1/resolve",
+		"POST /api/issues/i-1/review/threads/t-1/reopen",
+	}
+	if len(requests) != len(want) {
+		t.Fatalf("requests = %#v", requests)
+	}
+	for i := range want {
+		if requests[i] != want[i] {
+			t.Fatalf("request %d = %s, want %s", i, requests[i], want[i])
+		}
+	}
+}
@@ -0,0 +1,71 @@
+package ottercli
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestClientIssueTemplates(t *testing.T) {
+	var requests []string
+	var savedBody map[string]any
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests = append(requests, r.Method+" "+r.URL.EscapedPath())
+		w.Header().Set("Content-Type", "application/json")
+		switch r.Method {
+		case http.MethodGet:
+			if r.URL.Path == "/api/projects/p-1/issue-templates" {
+				_, _ = w.Write([]byte(`{"items":[{"id":"t-1","project_id":"p-1","name":"Bug","body":"","fields":[{"key":"severity","type":"select","options":["Low","High"]}],"default_labels":["bug"]}],"total":1}`))
+				return
+			}
+			_, _ = w.Write([]byte(`{"id":"t-1","name":"Bug report","body":"","fields":[],"default_labels":[]}`))
+		case http.MethodPost:
+			_ = json.NewDecoder(r.Body).Decode(&savedBody)
+			_, _ = w.Write([]byte(`{"id":"t-2","name":"Feature","body":"## Why","fields":[],"default_labels":[]}`))
+		default:
+			_, _ = w.Write([]byte(`{"deleted":true}`))
+		}
+	}))
+	defer srv.Close()
+
+	client := &Client{BaseURL: srv.URL, Token: "token-1", OrgID: "org-1", HTTP: srv.Client()}
+	templates, err := client.ListIssueTemplates("p-1")
+	if err != nil {
+		t.Fatalf("ListIssueTemplates() error = %v", err)
+	}
+	if len(templates) != 1 || templates[0].Fields[0].Options[1] != "High" {
+		t.Fatalf("ListIssueTemplates() = %#v", templates)
+	}
+	if _, err := client.GetIssueTemplate("p-1", "Bug report"); err != nil {
+		t.Fatalf("GetIssueTemplate() error = %v", err)
+	}
+	saved, err := client.SaveIssueTemplate("p-1", IssueTemplate{Name: "Feature", Body: "## Why", DefaultLabels: []string{"feature"}})
+	if err != nil {
+		t.Fatalf("SaveIssueTemplate() error = %v", err)
+	}
+	if saved.ID != "t-2" || savedBody["name"] != "Feature" || savedBody["body"] != "## Why" {
+		t.Fatalf("saved = %#v body = %#v", saved, savedBody)
+	}
+	if err := client.DeleteIssueTemplate("p-1", "t-2"); err != nil {
+		t.Fatalf("DeleteIssueTemplate() error = %v", err)
+	}
+
+	want := []string{
+		"GET /api/projects/p-1/issue-templates",
+		"GET /api/projects/p-1/issue-templates/Bug%20report",
+		"POST /api/projects/p-1/issue-templates",
+		"DELETE /api/projects/p-1/issue-templates/t-2",
+	}
+	if len(requests) != len(want) {
+		t.Fatalf("requests = %#v", requests)
+	}
+	for i := range want {
+		if requests[i] != want[i] {
+			t.Fatalf("request %d = %q, want %q", i, requests[i], want[i])
+		}
+	}
+	if _, err := client.ListIssueTemplates(" "); err == nil {
+		t.Fatal("ListIssueTemplates() without project should fail")
+	}
+}
@@ -0,0 +1,36 @@
+package ottercli
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestClientGetIssueTimeline(t *testing.T) {
+	var requestURI string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requestURI = r.Method + " " + r.URL.RequestURI()
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"items":[{"id":"comment:c-1","type":"comment","occurred_at":"2026-10-18T09:00:00Z","actor":{"type":"agent","id":"a-1","name":"Derek"},"summary":"On it"}],"next_cursor":"next"}`))
+	}))
+	defer srv.Close()
+
+	client := &Client{BaseURL: srv.URL, Token: "token-1", OrgID: "org-1", HTTP: srv.Client()}
+	page, err := client.GetIssueTimeline("i-1", "abc", 20, []string{"comment", "commit"})
+	if err != nil {
+		t.Fatalf("GetIssueTimeline() error = %v", err)
+	}
+	if requestURI != "GET /api/issues/i-1/timeline?cursor=abc&limit=20&types=comment%2Ccommit" {
+		t.Fatalf("request = %s", requestURI)
+	}
+	if len(page.Items) != 1 || page.Items[0].Actor.Name != "Derek" || page.NextCursor != "next" {
+		t.Fatalf("GetIssueTimeline() = %#v", page)
+	}
+
+	if _, err := client.GetIssueTimeline("i-1", "", 0, nil); err != nil {
+		t.Fatalf("GetIssueTimeline() without options error = %v", err)
+	}
+	if requestURI != "GET /api/issues/i-1/timeline" {
+		t.Fatalf("request without options = %s", requestURI)
+	}
+}
@@ -0,0 +1,67 @@
+package ottercli
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+func TestClientIssueQueryAndViewMethodsUseExpectedPaths(t *testing.T) {
+	var gotPaths []string
+	var gotBodies []map[string]any
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotPaths = append(gotPaths, r.Method+" "+r.URL.String())
+		var body map[string]any
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		gotBodies = append(gotBodies, body)
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case r.URL.Path == "/api/issues":
+			_, _ = w.Write([]byte(`{"items":[{"id":"i-1","issue_number":7,"title":"Launch post"}],"total":1,"next_cursor":"abc","query":"is:open"}`))
+		case r.URL.Path == "/api/issues/views" && r.Method == http.MethodGet:
+			_, _ = w.Write([]byte(`{"items":[{"id":"v-1","name":"launch","query":"is:open label:blog"}],"total":1}`))
+		case r.URL.Path == "/api/issues/views" && r.Method == http.MethodPost:
+			_, _ = w.Write([]byte(`{"id":"v-2","name":"mine","query":"owner:@writer-2","project_id":"p-1"}`))
+		default:
+			_, _ = w.Write([]byte(`{"deleted":true,"id":"v-1"}`))
+		}
+	}))
+	defer srv.Close()
+
+	client := &Client{BaseURL: srv.URL, Token: "token-1", OrgID: "org-1", HTTP: srv.Client()}
+
+	page, err := client.ListIssuesPage("", map[string]string{"q": "is:open label:blog", "cursor": "xyz"})
+	if err != nil || len(page.Items) != 1 || page.NextCursor != "abc" || page.Query != "is:open" {
+		t.Fatalf("ListIssuesPage() = %#v, %v", page, err)
+	}
+	views, err := client.ListIssueViews("p-1")
+	if err != nil || len(views) != 1 || views[0].Name != "launch" {
+		t.Fatalf("ListIssueViews() = %#v, %v", views, err)
+	}
+	view, err := client.SaveIssueView("mine", "owner:@writer-2", " p-1 ")
+	if err != nil || view.ID != "v-2" {
+		t.Fatalf("SaveIssueView() = %#v, %v", view, err)
+	}
+	if err := client.DeleteIssueView("v-1"); err != nil {
+		t.Fatalf("DeleteIssueView() error = %v", err)
+	}
+	if _, err := client.ListIssues("", nil); err == nil {
+		t.Fatal("ListIssues() without a project should fail")
+	}
+
+	wantPaths := []string{
+		"GET /api/issues?cursor=xyz&q=is%3Aopen+label%3Ablog",
+		"GET /api/issues/views?project_id=p-1",
+		"POST /api/issues/views",
+		"DELETE /api/issues/views/v-1",
+	}
+	if !reflect.DeepEqual(gotPaths, wantPaths) {
+		t.Fatalf("paths = %#v, want %#v", gotPaths, wantPaths)
+	}
+	wantBody := map[string]any{"name": "mine", "query": "owner:@writer-2", "project_id": "p-1"}
+	if !reflect.DeepEqual(gotBodies[2], wantBody) {
+		t.Fatalf("save body = %#v, want %#v", gotBodies[2], wantBody)
+	}
+}
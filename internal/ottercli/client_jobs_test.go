@@ -35,8 +35,17 @@ func TestClientAgentJobsMethodsUseExpectedPathsAndPayloads(t *testing.T) {
 			_, _ = w.Write([]byte(`{"id":"job-1","status":"active","org_id":"org-1","agent_id":"agent-1","name":"Heartbeat","schedule_kind":"interval","payload_kind":"message","payload_text":"ping","enabled":true,"run_count":0,"error_count":0,"max_failures":5,"consecutive_failures":0,"timezone":"UTC","created_at":"2026-02-12T00:00:00Z","updated_at":"2026-02-12T00:00:00Z"}`))
 		case r.Method == http.MethodGet && r.URL.Path == "/api/v1/jobs/job-1/runs":
 			_, _ = w.Write([]byte(`{"items":[{"id":"run-1","job_id":"job-1","org_id":"org-1","status":"success","started_at":"2026-02-12T00:00:10Z","created_at":"2026-02-12T00:00:10Z","payload_text":"ping"}],"total":1}`))
+		case r.Method == http.MethodPost && r.URL.Path == "/api/v1/jobs/job-1/runs/run-1/reply":
+			w.WriteHeader(http.StatusAccepted)
+			_, _ = w.Write([]byte(`{"job_id":"job-1","run_id":"run-1","message_id":"msg-1"}`))
 		case r.Method == http.MethodDelete && r.URL.Path == "/api/v1/jobs/job-1":
 			_, _ = w.Write([]byte(`{"deleted":true,"id":"job-1"}`))
+		case r.Method == http.MethodGet && r.URL.Path == "/api/v1/jobs/blackouts":
+			_, _ = w.Write([]byte(`{"items":[{"id":"blackout-1","org_id":"org-1","name":"Weekends","days_of_week":[0,6],"start_minute":0,"end_minute":1440,"created_at":"2026-02-12T00:00:00Z","updated_at":"2026-02-12T00:00:00Z"}],"total":1}`))
+		case r.Method == http.MethodPost && r.URL.Path == "/api/v1/jobs/blackouts":
+			_, _ = w.Write([]byte(`{"id":"blackout-1","org_id":"org-1","name":"Weekends","days_of_week":[0,6],"start_minute":0,"end_minute":1440,"created_at":"2026-02-12T00:00:00Z","updated_at":"2026-02-12T00:00:00Z"}`))
+		case r.Method == http.MethodDelete && r.URL.Path == "/api/v1/jobs/blackouts/blackout-1":
+			_, _ = w.Write([]byte(`{"deleted":true,"id":"blackout-1"}`))
 		case r.Method == http.MethodPost && r.URL.Path == "/api/v1/jobs/import/openclaw-cron":
 			_, _ = w.Write([]byte(`{"total":2,"imported":1,"updated":1,"skipped":0,"warnings":[]}`))
 		default:
@@ -138,6 +147,20 @@ func TestClientAgentJobsMethodsUseExpectedPathsAndPayloads(t *testing.T) {
 		t.Fatalf("ListJobRuns query mismatch: %s", gotPath)
 	}
 
+	replied, err := client.ReplyToJobRun("job-1", "run-1", "backup ok")
+	if err != nil {
+		t.Fatalf("ReplyToJobRun() error = %v", err)
+	}
+	if replied["message_id"] != "msg-1" {
+		t.Fatalf("ReplyToJobRun() payload = %#v", replied)
+	}
+	if gotMethod != http.MethodPost || gotPath != "/api/v1/jobs/job-1/runs/run-1/reply" {
+		t.Fatalf("ReplyToJobRun request = %s %s", gotMethod, gotPath)
+	}
+	if gotBody["output"] != "backup ok" {
+		t.Fatalf("ReplyToJobRun payload = %#v", gotBody)
+	}
+
 	deleted, err := client.DeleteJob("job-1")
 	if err != nil {
 		t.Fatalf("DeleteJob() error = %v", err)
@@ -149,6 +172,45 @@ func TestClientAgentJobsMethodsUseExpectedPathsAndPayloads(t *testing.T) {
 		t.Fatalf("DeleteJob request = %s %s", gotMethod, gotPath)
 	}
 
+	blackout, err := client.CreateJobBlackout(map[string]any{
+		"name":         "Weekends",
+		"days_of_week": []int{0, 6},
+	})
+	if err != nil {
+		t.Fatalf("CreateJobBlackout() error = %v", err)
+	}
+	if blackout.ID != "blackout-1" || len(blackout.DaysOfWeek) != 2 {
+		t.Fatalf("CreateJobBlackout() response = %#v", blackout)
+	}
+	if gotMethod != http.MethodPost || gotPath != "/api/v1/jobs/blackouts" {
+		t.Fatalf("CreateJobBlackout request = %s %s", gotMethod, gotPath)
+	}
+	if gotBody["name"] != "Weekends" {
+		t.Fatalf("CreateJobBlackout payload = %#v", gotBody)
+	}
+
+	blackouts, err := client.ListJobBlackouts()
+	if err != nil {
+		t.Fatalf("ListJobBlackouts() error = %v", err)
+	}
+	if blackouts.Total != 1 || len(blackouts.Items) != 1 {
+		t.Fatalf("ListJobBlackouts() response = %#v", blackouts)
+	}
+	if gotMethod != http.MethodGet || gotPath != "/api/v1/jobs/blackouts" {
+		t.Fatalf("ListJobBlackouts request = %s %s", gotMethod, gotPath)
+	}
+
+	removed, err := client.DeleteJobBlackout("blackout-1")
+	if err != nil {
+		t.Fatalf("DeleteJobBlackout() error = %v", err)
+	}
+	if removed["deleted"] != true {
+		t.Fatalf("DeleteJobBlackout() payload = %#v", removed)
+	}
+	if gotMethod != http.MethodDelete || gotPath != "/api/v1/jobs/blackouts/blackout-1" {
+		t.Fatalf("DeleteJobBlackout request = %s %s", gotMethod, gotPath)
+	}
+
 	imported, err := client.ImportOpenClawCronJobs()
 	if err != nil {
 		t.Fatalf("ImportOpenClawCronJobs() error = %v", err)
@@ -198,4 +260,24 @@ func TestClientAgentJobsValidation(t *testing.T) {
 	if err == nil || !strings.Contains(err.Error(), "job id is required") {
 		t.Fatalf("DeleteJob(\"\") error = %v", err)
 	}
+
+	_, err = client.ReplyToJobRun("job-1", "", "ok")
+	if err == nil || !strings.Contains(err.Error(), "run id is required") {
+		t.Fatalf("ReplyToJobRun(missing run) error = %v", err)
+	}
+
+	_, err = client.ReplyToJobRun("job-1", "run-1", " ")
+	if err == nil || !strings.Contains(err.Error(), "output is required") {
+		t.Fatalf("ReplyToJobRun(empty output) error = %v", err)
+	}
+
+	_, err = client.CreateJobBlackout(nil)
+	if err == nil || !strings.Contains(err.Error(), "blackout payload is required") {
+		t.Fatalf("CreateJobBlackout(nil) error = %v", err)
+	}
+
+	_, err = client.DeleteJobBlackout("")
+	if err == nil || !strings.Contains(err.Error(), "blackout id is required") {
+		t.Fatalf("DeleteJobBlackout(\"\") error = %v", err)
+	}
 }
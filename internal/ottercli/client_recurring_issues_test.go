@@ -0,0 +1,87 @@
+package ottercli
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestClientRecurringIssues(t *testing.T) {
+	var requests []string
+	var bodies []map[string]any
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests = append(requests, r.Method+" "+r.URL.EscapedPath())
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case r.Method == http.MethodGet && r.URL.Path == "/api/projects/p-1/recurring-issues":
+			_, _ = w.Write([]byte(`{"items":[{"id":"r-1","project_id":"p-1","name":"Weekly metrics","schedule_kind":"cron","cron_expr":"0 9 * * 1","timezone":"America/New_York","enabled":true,"fields":{}}],"total":1}`))
+		case r.Method == http.MethodPost && r.URL.Path == "/api/projects/p-1/recurring-issues/r-1/run":
+			_, _ = w.Write([]byte(`{"outcome":{"status":"created","issue":{"id":"i-1","issue_number":12,"title":"Weekly metrics report — 2026-10-19"}},"recurring_issue":{"id":"r-1","run_count":1}}`))
+		case r.Method == http.MethodPost || r.Method == http.MethodPatch:
+			var body map[string]any
+			_ = json.NewDecoder(r.Body).Decode(&body)
+			bodies = append(bodies, body)
+			_, _ = w.Write([]byte(`{"id":"r-1","name":"Weekly metrics","schedule_kind":"cron","fields":{}}`))
+		case r.Method == http.MethodDelete:
+			w.WriteHeader(http.StatusNoContent)
+		default:
+			_, _ = w.Write([]byte(`{"id":"r-1","name":"Weekly metrics","schedule_kind":"cron","fields":{}}`))
+		}
+	}))
+	defer srv.Close()
+
+	client := &Client{BaseURL: srv.URL, Token: "token-1", OrgID: "org-1", HTTP: srv.Client()}
+	items, err := client.ListRecurringIssues("p-1")
+	if err != nil {
+		t.Fatalf("ListRecurringIssues() error = %v", err)
+	}
+	if len(items) != 1 || items[0].Timezone != "America/New_York" || *items[0].CronExpr != "0 9 * * 1" {
+		t.Fatalf("ListRecurringIssues() = %#v", items)
+	}
+	if _, err := client.GetRecurringIssue("p-1", "r-1"); err != nil {
+		t.Fatalf("GetRecurringIssue() error = %v", err)
+	}
+	if _, err := client.CreateRecurringIssue("p-1", map[string]any{"name": "Weekly metrics", "schedule_kind": "cron"}); err != nil {
+		t.Fatalf("CreateRecurringIssue() error = %v", err)
+	}
+	if _, err := client.UpdateRecurringIssue("p-1", "r-1", map[string]any{"enabled": false}); err != nil {
+		t.Fatalf("UpdateRecurringIssue() error = %v", err)
+	}
+	result, err := client.RunRecurringIssue("p-1", "r-1")
+	if err != nil {
+		t.Fatalf("RunRecurringIssue() error = %v", err)
+	}
+	if result.Outcome.Status != "created" || result.Outcome.Issue.IssueNumber != 12 || result.RecurringIssue.RunCount != 1 {
+		t.Fatalf("RunRecurringIssue() = %#v", result)
+	}
+	if err := client.DeleteRecurringIssue("p-1", "r-1"); err != nil {
+		t.Fatalf("DeleteRecurringIssue() error = %v", err)
+	}
+
+	want := []string{
+		"GET /api/projects/p-1/recurring-issues",
+		"GET /api/projects/p-1/recurring-issues/r-1",
+		"POST /api/projects/p-1/recurring-issues",
+		"PATCH /api/projects/p-1/recurring-issues/r-1",
+		"POST /api/projects/p-1/recurring-issues/r-1/run",
+		"DELETE /api/projects/p-1/recurring-issues/r-1",
+	}
+	if len(requests) != len(want) {
+		t.Fatalf("requests = %#v", requests)
+	}
+	for i := range want {
+		if requests[i] != want[i] {
+			t.Fatalf("request %d = %q, want %q", i, requests[i], want[i])
+		}
+	}
+	if bodies[0]["schedule_kind"] != "cron" || bodies[1]["enabled"] != false {
+		t.Fatalf("bodies = %#v", bodies)
+	}
+	if _, err := client.UpdateRecurringIssue("p-1", "r-1", nil); err == nil {
+		t.Fatal("UpdateRecurringIssue() without changes should fail")
+	}
+	if _, err := client.GetRecurringIssue("p-1", " "); err == nil {
+		t.Fatal("GetRecurringIssue() without id should fail")
+	}
+}
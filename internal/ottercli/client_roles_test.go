@@ -0,0 +1,104 @@
+package ottercli
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestClientRoleMethodsUseExpectedPathsAndBodies(t *testing.T) {
+	var gotPaths []string
+	var gotBodies []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotPaths = append(gotPaths, r.Method+" "+r.URL.String())
+		body, _ := io.ReadAll(r.Body)
+		gotBodies = append(gotBodies, string(body))
+		w.Header().Set("Content-Type", "application/json")
+
+		switch {
+		case r.Method == http.MethodGet && r.URL.Path == "/api/admin/capabilities":
+			_, _ = w.Write([]byte(`{"items":[{"name":"issues.write","description":"Issues"}],"total":1}`))
+		case r.Method == http.MethodGet && r.URL.Path == "/api/admin/roles":
+			_, _ = w.Write([]byte(`{"items":[{"name":"owner","builtin":true,"capabilities":["issues.write"]}],"total":1}`))
+		case r.URL.Path == "/api/admin/roles" || r.URL.Path == "/api/admin/roles/role-1":
+			if r.Method == http.MethodDelete {
+				_, _ = w.Write([]byte(`{"ok":true}`))
+				return
+			}
+			_, _ = w.Write([]byte(`{"id":"role-1","name":"triager","capabilities":["issues.write"]}`))
+		case r.Method == http.MethodGet && r.URL.Path == "/api/admin/role-bindings":
+			_, _ = w.Write([]byte(`{"items":[{"id":"b-1","user_id":"u-1","role":"triager"}],"total":1}`))
+		case r.URL.Path == "/api/admin/role-bindings" || r.URL.Path == "/api/admin/role-bindings/b-1":
+			if r.Method == http.MethodDelete {
+				_, _ = w.Write([]byte(`{"ok":true}`))
+				return
+			}
+			_, _ = w.Write([]byte(`{"id":"b-1","user_id":"u-1","role":"triager","project_id":"p-1"}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = w.Write([]byte(`{"error":"not found"}`))
+		}
+	}))
+	defer srv.Close()
+
+	client := &Client{BaseURL: srv.URL, Token: "token-1", OrgID: "org-1", HTTP: srv.Client()}
+
+	caps, err := client.ListCapabilities()
+	if err != nil || caps.Total != 1 || caps.Items[0].Name != "issues.write" {
+		t.Fatalf("ListCapabilities() = %#v, %v", caps, err)
+	}
+	roles, err := client.ListRoles()
+	if err != nil || len(roles.Items) != 1 || !roles.Items[0].Builtin {
+		t.Fatalf("ListRoles() = %#v, %v", roles, err)
+	}
+	role, err := client.CreateRole(map[string]any{"name": "triager", "capabilities": []string{"issues.write"}})
+	if err != nil || role.ID != "role-1" {
+		t.Fatalf("CreateRole() = %#v, %v", role, err)
+	}
+	if _, err := client.UpdateRole("role-1", map[string]any{"description": "Triage"}); err != nil {
+		t.Fatalf("UpdateRole() error = %v", err)
+	}
+	if err := client.DeleteRole("role-1"); err != nil {
+		t.Fatalf("DeleteRole() error = %v", err)
+	}
+	bindings, err := client.ListRoleBindings("u-1", "p-1")
+	if err != nil || bindings.Total != 1 {
+		t.Fatalf("ListRoleBindings() = %#v, %v", bindings, err)
+	}
+	binding, err := client.CreateRoleBinding(map[string]any{"user_id": "u-1", "role": "triager", "project_id": "p-1"})
+	if err != nil || binding.ProjectID == nil || *binding.ProjectID != "p-1" {
+		t.Fatalf("CreateRoleBinding() = %#v, %v", binding, err)
+	}
+	if err := client.DeleteRoleBinding("b-1"); err != nil {
+		t.Fatalf("DeleteRoleBinding() error = %v", err)
+	}
+	if err := client.DeleteRole(" "); err == nil {
+		t.Fatalf("DeleteRole() with empty id should fail")
+	}
+
+	wantPaths := []string{
+		"GET /api/admin/capabilities",
+		"GET /api/admin/roles",
+		"POST /api/admin/roles",
+		"PATCH /api/admin/roles/role-1",
+		"DELETE /api/admin/roles/role-1",
+		"GET /api/admin/role-bindings?project_id=p-1&user_id=u-1",
+		"POST /api/admin/role-bindings",
+		"DELETE /api/admin/role-bindings/b-1",
+	}
+	if len(gotPaths) != len(wantPaths) {
+		t.Fatalf("requests = %v, want %v", gotPaths, wantPaths)
+	}
+	for i := range wantPaths {
+		if gotPaths[i] != wantPaths[i] {
+			t.Fatalf("request %d = %q, want %q", i, gotPaths[i], wantPaths[i])
+		}
+	}
+	if gotBodies[2] != `{"capabilities":["issues.write"],"name":"triager"}` {
+		t.Fatalf("create body = %s", gotBodies[2])
+	}
+	if gotBodies[6] != `{"project_id":"p-1","role":"triager","user_id":"u-1"}` {
+		t.Fatalf("binding body = %s", gotBodies[6])
+	}
+}
@@ -0,0 +1,68 @@
+package ottercli
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestClientSubIssues(t *testing.T) {
+	var requests []string
+	var decomposeBody map[string]any
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests = append(requests, r.Method+" "+r.URL.Path)
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case r.URL.Path == "/api/issues/i-1/tree":
+			_, _ = w.Write([]byte(`{"issue":{"id":"i-1","title":"Epic"},"rollup":{"total":1,"closed":1,"percent_complete":100,"blocked":0},"children":[{"issue":{"id":"i-2","parent_issue_id":"i-1"},"rollup":{},"children":[]}]}`))
+		case r.URL.Path == "/api/issues/i-1/decompose":
+			_ = json.NewDecoder(r.Body).Decode(&decomposeBody)
+			_, _ = w.Write([]byte(`{"parent_issue_id":"i-1","items":[{"id":"i-3","parent_issue_id":"i-1"}],"total":1}`))
+		default:
+			_, _ = w.Write([]byte(`{"id":"i-2"}`))
+		}
+	}))
+	defer srv.Close()
+
+	client := &Client{BaseURL: srv.URL, Token: "token-1", OrgID: "org-1", HTTP: srv.Client()}
+	tree, err := client.GetIssueTree("i-1")
+	if err != nil {
+		t.Fatalf("GetIssueTree() error = %v", err)
+	}
+	if tree.Rollup.PercentComplete != 100 || len(tree.Children) != 1 || *tree.Children[0].Issue.ParentIssueID != "i-1" {
+		t.Fatalf("GetIssueTree() = %#v", tree)
+	}
+	if _, err := client.AttachSubIssue("i-1", "i-2"); err != nil {
+		t.Fatalf("AttachSubIssue() error = %v", err)
+	}
+	if _, err := client.DetachSubIssue("i-1", "i-2"); err != nil {
+		t.Fatalf("DetachSubIssue() error = %v", err)
+	}
+	planner := "agent-1"
+	created, err := client.DecomposeIssue("i-1", &planner, []SubIssueInput{{Title: "Copy"}})
+	if err != nil {
+		t.Fatalf("DecomposeIssue() error = %v", err)
+	}
+	if len(created) != 1 || decomposeBody["agent_id"] != "agent-1" {
+		t.Fatalf("created = %#v body = %#v", created, decomposeBody)
+	}
+	if _, err := client.DecomposeIssue("i-1", nil, nil); err == nil {
+		t.Fatal("DecomposeIssue() with no children should fail")
+	}
+
+	want := []string{
+		"GET /api/issues/i-1/tree",
+		"POST /api/issues/i-1/children",
+		"DELETE /api/issues/i-1/children/i-2",
+		"POST /api/issues/i-1/decompose",
+	}
+	if len(requests) != len(want) {
+		t.Fatalf("requests = %v, want %v", requests, want)
+	}
+	for i := range want {
+		if requests[i] != want[i] {
+			t.Fatalf("requests[%d] = %q, want %q", i, requests[i], want[i])
+		}
+	}
+}
@@ -0,0 +1,92 @@
+package ottercli
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestClientUsageMethodsUseExpectedPaths(t *testing.T) {
+	var gotPaths []string
+	var gotBodies []map[string]any
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotPaths = append(gotPaths, r.Method+" "+r.URL.String())
+		var body map[string]any
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		gotBodies = append(gotBodies, body)
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case r.URL.Path == "/api/usage":
+			_, _ = w.Write([]byte(`{"from":"2026-10-01","to":"2026-11-01","group_by":"agent","tokens":1500,"cost_usd":0.75,"items":[{"key":"writer","tokens":1500,"cost_usd":0.75,"events":2}]}`))
+		case r.URL.Path == "/api/usage/pricing" && r.Method == http.MethodGet:
+			_, _ = w.Write([]byte(`{"items":[{"model":"claude-*","usd_per_million_tokens":9}],"total":1}`))
+		case r.URL.Path == "/api/usage/pricing" && r.Method == http.MethodPut:
+			_, _ = w.Write([]byte(`{"model":"gpt-4o","usd_per_million_tokens":5}`))
+		case r.URL.Path == "/api/usage/budgets" && r.Method == http.MethodGet:
+			_, _ = w.Write([]byte(`{"items":[{"id":"b-1","scope":"agent","scope_id":"writer","period":"month","limit_usd":20,"hard":true}],"total":1}`))
+		case r.URL.Path == "/api/usage/budgets/b-1" && r.Method == http.MethodDelete:
+			_, _ = w.Write([]byte(`{"deleted":true,"resumed_jobs":2}`))
+		default:
+			_, _ = w.Write([]byte(`{"id":"b-1","scope":"agent","scope_id":"writer","period":"month","limit_usd":30}`))
+		}
+	}))
+	defer srv.Close()
+
+	client := &Client{BaseURL: srv.URL, Token: "token-1", OrgID: "org-1", HTTP: srv.Client()}
+
+	report, err := client.UsageReport(UsageFilter{Month: "2026-10", GroupBy: "agent", AgentID: " writer "})
+	if err != nil || report.CostUSD != 0.75 || len(report.Items) != 1 || report.Items[0].Key != "writer" {
+		t.Fatalf("UsageReport() = %#v, %v", report, err)
+	}
+	pricing, err := client.ListModelPricing()
+	if err != nil || len(pricing) != 1 || pricing[0].USDPerMillionTokens != 9 {
+		t.Fatalf("ListModelPricing() = %#v, %v", pricing, err)
+	}
+	if _, err := client.SetModelPrice(" gpt-4o ", 5); err != nil {
+		t.Fatalf("SetModelPrice() error = %v", err)
+	}
+	if err := client.DeleteModelPrice("claude-*"); err != nil {
+		t.Fatalf("DeleteModelPrice() error = %v", err)
+	}
+	budgets, err := client.ListUsageBudgets()
+	if err != nil || len(budgets) != 1 || !budgets[0].Hard {
+		t.Fatalf("ListUsageBudgets() = %#v, %v", budgets, err)
+	}
+	if _, err := client.SetUsageBudget(map[string]any{"scope": "agent", "scope_id": "writer", "limit_usd": 20}); err != nil {
+		t.Fatalf("SetUsageBudget() error = %v", err)
+	}
+	updated, err := client.UpdateUsageBudget("b-1", map[string]any{"limit_usd": 30})
+	if err != nil || updated.LimitUSD != 30 {
+		t.Fatalf("UpdateUsageBudget() = %#v, %v", updated, err)
+	}
+	resumed, err := client.DeleteUsageBudget("b-1")
+	if err != nil || resumed != 2 {
+		t.Fatalf("DeleteUsageBudget() = %d, %v", resumed, err)
+	}
+	if _, err := client.DeleteUsageBudget(" "); err == nil {
+		t.Fatalf("DeleteUsageBudget() expected error for empty id")
+	}
+
+	want := []string{
+		"GET /api/usage?agent=writer&group_by=agent&month=2026-10",
+		"GET /api/usage/pricing",
+		"PUT /api/usage/pricing",
+		"DELETE /api/usage/pricing?model=claude-%2A",
+		"GET /api/usage/budgets",
+		"POST /api/usage/budgets",
+		"PATCH /api/usage/budgets/b-1",
+		"DELETE /api/usage/budgets/b-1",
+	}
+	if len(gotPaths) != len(want) {
+		t.Fatalf("paths = %v", gotPaths)
+	}
+	for i := range want {
+		if gotPaths[i] != want[i] {
+			t.Fatalf("path[%d] = %q, want %q", i, gotPaths[i], want[i])
+		}
+	}
+	if gotBodies[2]["model"] != "gpt-4o" || gotBodies[2]["usd_per_million_tokens"] != float64(5) {
+		t.Fatalf("SetModelPrice body = %#v", gotBodies[2])
+	}
+}
@@ -3,19 +3,49 @@ package ottercli
 import (
 	"encoding/json"
 	"errors"
+	"fmt"
 	"os"
 	"path/filepath"
+	"sort"
+	"strings"
 )
 
 const (
 	defaultAPIBaseURL = "https://api.otter.camp"
+
+	// DefaultContextName is the context single-profile configs migrate into.
+	DefaultContextName = "default"
+	// ContextEnvVar selects a context for one invocation; `--context` sets it.
+	ContextEnvVar = "OTTER_CONTEXT"
 )
 
+// Config is one context: where the API lives and how to authenticate.
 type Config struct {
 	APIBaseURL  string `json:"apiBaseUrl"`
 	Token       string `json:"token"`
 	DefaultOrg  string `json:"defaultOrg"`
 	DatabaseURL string `json:"databaseUrl,omitempty"`
+	// CredentialStore says where Token is kept: plaintext (this file),
+	// keyring or file. Empty means plaintext.
+	CredentialStore string `json:"credentialStore,omitempty"`
+	// Context is the name the config was loaded from. It is not persisted.
+	Context string `json:"-"`
+}
+
+// ConfigFile is the on-disk config: named contexts and the one in use.
+type ConfigFile struct {
+	CurrentContext string            `json:"currentContext"`
+	Contexts       map[string]Config `json:"contexts"`
+}
+
+// ContextSummary describes a context without its credentials.
+type ContextSummary struct {
+	Name            string `json:"name"`
+	APIBaseURL      string `json:"apiBaseUrl"`
+	DefaultOrg      string `json:"defaultOrg"`
+	CredentialStore string `json:"credentialStore"`
+	HasToken        bool   `json:"hasToken"`
+	Current         bool   `json:"current"`
 }
 
 func ConfigPath() (string, error) {
@@ -26,42 +56,238 @@ func ConfigPath() (string, error) {
 	return filepath.Join(dir, "otter", "config.json"), nil
 }
 
-func LoadConfig() (Config, error) {
+// LoadConfigFile reads every context. A config written before contexts
+// existed is migrated into the "default" context and rewritten in place.
+func LoadConfigFile() (ConfigFile, error) {
 	path, err := ConfigPath()
 	if err != nil {
-		return Config{}, err
+		return ConfigFile{}, err
 	}
 	data, err := os.ReadFile(path)
 	if err != nil {
 		if errors.Is(err, os.ErrNotExist) {
-			return Config{APIBaseURL: defaultAPIBaseURL}, nil
+			return ConfigFile{Contexts: map[string]Config{}}, nil
 		}
-		return Config{}, err
+		return ConfigFile{}, err
+	}
+	var file ConfigFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		return ConfigFile{}, err
+	}
+	if file.Contexts != nil {
+		return file, nil
+	}
+
+	var legacy Config
+	if err := json.Unmarshal(data, &legacy); err != nil {
+		return ConfigFile{}, err
+	}
+	file = ConfigFile{Contexts: map[string]Config{}}
+	if legacy != (Config{}) {
+		file.CurrentContext = DefaultContextName
+		file.Contexts[DefaultContextName] = legacy
+		// Best effort: a read-only config still loads, it just migrates again.
+		_ = SaveConfigFile(file)
+	}
+	return file, nil
+}
+
+func SaveConfigFile(file ConfigFile) error {
+	if file.Contexts == nil {
+		file.Contexts = map[string]Config{}
+	}
+	path, err := ConfigPath()
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return err
 	}
-	var cfg Config
-	if err := json.Unmarshal(data, &cfg); err != nil {
+	payload, err := json.MarshalIndent(file, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, payload, 0o600)
+}
+
+// ActiveContextName is OTTER_CONTEXT when set, else the file's current
+// context, else "default".
+func ActiveContextName(file ConfigFile) string {
+	if name := strings.TrimSpace(os.Getenv(ContextEnvVar)); name != "" {
+		return name
+	}
+	if name := strings.TrimSpace(file.CurrentContext); name != "" {
+		return name
+	}
+	return DefaultContextName
+}
+
+// LoadConfig returns the active context with its token resolved from the
+// context's credential store.
+func LoadConfig() (Config, error) {
+	file, err := LoadConfigFile()
+	if err != nil {
 		return Config{}, err
 	}
+	name := ActiveContextName(file)
+	cfg, ok := file.Contexts[name]
+	if !ok {
+		if strings.TrimSpace(os.Getenv(ContextEnvVar)) != "" || len(file.Contexts) > 0 {
+			return Config{}, fmt.Errorf("unknown context %q; see `otter context list`", name)
+		}
+		return Config{APIBaseURL: defaultAPIBaseURL, Context: name}, nil
+	}
+	cfg.Context = name
 	if cfg.APIBaseURL == "" {
 		cfg.APIBaseURL = defaultAPIBaseURL
 	}
+	token, err := loadContextToken(name, cfg)
+	if err != nil {
+		return Config{}, err
+	}
+	cfg.Token = token
 	return cfg, nil
 }
 
+// SaveConfig writes cfg back to the context it was loaded from (or the active
+// context), keeping the token in that context's credential store.
 func SaveConfig(cfg Config) error {
-	if cfg.APIBaseURL == "" {
-		cfg.APIBaseURL = defaultAPIBaseURL
+	file, err := LoadConfigFile()
+	if err != nil {
+		return err
 	}
-	path, err := ConfigPath()
+	name := strings.TrimSpace(cfg.Context)
+	if name == "" {
+		name = ActiveContextName(file)
+	}
+	if err := putContext(&file, name, cfg); err != nil {
+		return err
+	}
+	if file.CurrentContext == "" {
+		file.CurrentContext = name
+	}
+	return SaveConfigFile(file)
+}
+
+// AddContext creates or replaces a named context, optionally switching to it.
+func AddContext(name string, cfg Config, use bool) error {
+	name = strings.TrimSpace(name)
+	if err := validateContextName(name); err != nil {
+		return err
+	}
+	file, err := LoadConfigFile()
 	if err != nil {
 		return err
 	}
-	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+	if previous, ok := file.Contexts[name]; ok && normalizeCredentialStore(previous.CredentialStore) != normalizeCredentialStore(cfg.CredentialStore) {
+		if err := deleteContextToken(name, previous); err != nil {
+			return err
+		}
+	}
+	if err := putContext(&file, name, cfg); err != nil {
 		return err
 	}
-	payload, err := json.MarshalIndent(cfg, "", "  ")
+	if use || file.CurrentContext == "" {
+		file.CurrentContext = name
+	}
+	return SaveConfigFile(file)
+}
+
+func UseContext(name string) error {
+	name = strings.TrimSpace(name)
+	file, err := LoadConfigFile()
 	if err != nil {
 		return err
 	}
-	return os.WriteFile(path, payload, 0o600)
+	if _, ok := file.Contexts[name]; !ok {
+		return fmt.Errorf("unknown context %q", name)
+	}
+	file.CurrentContext = name
+	return SaveConfigFile(file)
+}
+
+// RemoveContext deletes a context and its stored credentials. Removing the
+// current context leaves no context selected.
+func RemoveContext(name string) error {
+	name = strings.TrimSpace(name)
+	file, err := LoadConfigFile()
+	if err != nil {
+		return err
+	}
+	cfg, ok := file.Contexts[name]
+	if !ok {
+		return fmt.Errorf("unknown context %q", name)
+	}
+	if err := deleteContextToken(name, cfg); err != nil {
+		return err
+	}
+	delete(file.Contexts, name)
+	if file.CurrentContext == name {
+		file.CurrentContext = ""
+	}
+	return SaveConfigFile(file)
+}
+
+// ListContexts returns every context sorted by name and the active one.
+func ListContexts() ([]ContextSummary, string, error) {
+	file, err := LoadConfigFile()
+	if err != nil {
+		return nil, "", err
+	}
+	active := ActiveContextName(file)
+	out := make([]ContextSummary, 0, len(file.Contexts))
+	for name, cfg := range file.Contexts {
+		store := normalizeCredentialStore(cfg.CredentialStore)
+		apiBaseURL := cfg.APIBaseURL
+		if apiBaseURL == "" {
+			apiBaseURL = defaultAPIBaseURL
+		}
+		out = append(out, ContextSummary{
+			Name:            name,
+			APIBaseURL:      apiBaseURL,
+			DefaultOrg:      cfg.DefaultOrg,
+			CredentialStore: store,
+			HasToken:        store != CredentialStorePlaintext || strings.TrimSpace(cfg.Token) != "",
+			Current:         name == active,
+		})
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Name < out[j].Name })
+	return out, active, nil
+}
+
+func putContext(file *ConfigFile, name string, cfg Config) error {
+	if file.Contexts == nil {
+		file.Contexts = map[string]Config{}
+	}
+	store := normalizeCredentialStore(cfg.CredentialStore)
+	if _, err := credentialBackendFor(store); err != nil {
+		return err
+	}
+	if cfg.APIBaseURL == "" {
+		cfg.APIBaseURL = defaultAPIBaseURL
+	}
+	cfg.Context = ""
+	cfg.CredentialStore = store
+	if store == CredentialStorePlaintext {
+		cfg.CredentialStore = ""
+	} else {
+		if err := saveContextToken(name, cfg); err != nil {
+			return err
+		}
+		cfg.Token = ""
+	}
+	file.Contexts[name] = cfg
+	return nil
+}
+
+func validateContextName(name string) error {
+	if name == "" {
+		return errors.New("context name is required")
+	}
+	for _, r := range name {
+		if !(r == '-' || r == '_' || r == '.' || (r >= '0' && r <= '9') || (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z')) {
+			return fmt.Errorf("context name %q may only contain letters, digits, '.', '-' and '_'", name)
+		}
+	}
+	return nil
 }
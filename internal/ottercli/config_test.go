@@ -3,6 +3,7 @@ package ottercli
 import (
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 )
 
@@ -44,3 +45,188 @@ func TestSaveLoadConfig(t *testing.T) {
 		t.Fatalf("expected config dir")
 	}
 }
+
+type fakeKeyring map[string]string
+
+func (k fakeKeyring) Get(name string) (string, error) {
+	secret, ok := k[name]
+	if !ok {
+		return "", errCredentialNotFound
+	}
+	return secret, nil
+}
+
+func (k fakeKeyring) Set(name, secret string) error {
+	k[name] = secret
+	return nil
+}
+
+func (k fakeKeyring) Delete(name string) error {
+	if _, ok := k[name]; !ok {
+		return errCredentialNotFound
+	}
+	delete(k, name)
+	return nil
+}
+
+func setupConfigHome(t *testing.T) string {
+	t.Helper()
+	tmp := t.TempDir()
+	t.Setenv("HOME", tmp)
+	t.Setenv("XDG_CONFIG_HOME", filepath.Join(tmp, ".config"))
+	t.Setenv(ContextEnvVar, "")
+	t.Setenv(CredentialsPassphraseEnvVar, "")
+	path, err := ConfigPath()
+	if err != nil {
+		t.Fatalf("config path: %v", err)
+	}
+	return path
+}
+
+func TestLoadConfigMigratesSingleProfileConfig(t *testing.T) {
+	path := setupConfigHome(t)
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		t.Fatal(err)
+	}
+	legacy := `{"apiBaseUrl":"http://localhost:4200","token":"oc_sess_legacy","defaultOrg":"org-1"}`
+	if err := os.WriteFile(path, []byte(legacy), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg, err := LoadConfig()
+	if err != nil {
+		t.Fatalf("load config: %v", err)
+	}
+	if cfg.Context != DefaultContextName || cfg.Token != "oc_sess_legacy" || cfg.APIBaseURL != "http://localhost:4200" {
+		t.Fatalf("migrated config = %#v", cfg)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil || !strings.Contains(string(data), `"currentContext": "default"`) || !strings.Contains(string(data), `"contexts"`) {
+		t.Fatalf("config was not rewritten: %s, %v", data, err)
+	}
+}
+
+func TestContextsSwitchAndEnvOverride(t *testing.T) {
+	setupConfigHome(t)
+
+	if err := AddContext("local", Config{APIBaseURL: "http://localhost:4200", Token: "tok-local", DefaultOrg: "org-local"}, false); err != nil {
+		t.Fatalf("add local: %v", err)
+	}
+	if err := AddContext("hosted", Config{Token: "tok-hosted", DefaultOrg: "org-hosted"}, false); err != nil {
+		t.Fatalf("add hosted: %v", err)
+	}
+	if err := AddContext("bad name", Config{}, false); err == nil {
+		t.Fatalf("expected invalid name error")
+	}
+
+	cfg, err := LoadConfig()
+	if err != nil || cfg.Context != "local" || cfg.Token != "tok-local" {
+		t.Fatalf("first context should become current: %#v, %v", cfg, err)
+	}
+	if err := UseContext("hosted"); err != nil {
+		t.Fatalf("use hosted: %v", err)
+	}
+	cfg, err = LoadConfig()
+	if err != nil || cfg.APIBaseURL != defaultAPIBaseURL || cfg.DefaultOrg != "org-hosted" {
+		t.Fatalf("hosted config = %#v, %v", cfg, err)
+	}
+
+	t.Setenv(ContextEnvVar, "local")
+	cfg, err = LoadConfig()
+	if err != nil || cfg.Context != "local" {
+		t.Fatalf("env override = %#v, %v", cfg, err)
+	}
+	cfg.DefaultOrg = "org-local-2"
+	if err := SaveConfig(cfg); err != nil {
+		t.Fatalf("save: %v", err)
+	}
+	t.Setenv(ContextEnvVar, "staging")
+	if _, err := LoadConfig(); err == nil || !strings.Contains(err.Error(), `unknown context "staging"`) {
+		t.Fatalf("expected unknown context error, got %v", err)
+	}
+	t.Setenv(ContextEnvVar, "")
+
+	contexts, active, err := ListContexts()
+	if err != nil || active != "hosted" || len(contexts) != 2 || contexts[0].Name != "hosted" || contexts[1].DefaultOrg != "org-local-2" {
+		t.Fatalf("ListContexts() = %#v %q, %v", contexts, active, err)
+	}
+
+	if err := RemoveContext("hosted"); err != nil {
+		t.Fatalf("remove: %v", err)
+	}
+	if err := UseContext("hosted"); err == nil {
+		t.Fatalf("expected unknown context after remove")
+	}
+}
+
+func TestKeyringCredentialStoreKeepsTokenOutOfConfig(t *testing.T) {
+	path := setupConfigHome(t)
+	keyring := fakeKeyring{}
+	previous := keyringBackend
+	keyringBackend = keyring
+	defer func() { keyringBackend = previous }()
+
+	if err := AddContext("hosted", Config{Token: "tok-secret", DefaultOrg: "org-1", CredentialStore: "keyring"}, true); err != nil {
+		t.Fatalf("add: %v", err)
+	}
+	data, _ := os.ReadFile(path)
+	if strings.Contains(string(data), "tok-secret") || keyring["hosted"] != "tok-secret" {
+		t.Fatalf("token leaked into config: %s (keyring %v)", data, keyring)
+	}
+	cfg, err := LoadConfig()
+	if err != nil || cfg.Token != "tok-secret" {
+		t.Fatalf("load = %#v, %v", cfg, err)
+	}
+	if err := RemoveContext("hosted"); err != nil {
+		t.Fatalf("remove: %v", err)
+	}
+	if _, ok := keyring["hosted"]; ok {
+		t.Fatalf("keyring entry not removed")
+	}
+	if err := AddContext("x", Config{CredentialStore: "vault"}, false); err == nil {
+		t.Fatalf("expected unsupported credential store error")
+	}
+}
+
+func TestSecurityAddPasswordCommandQuotesArguments(t *testing.T) {
+	got := securityAddPasswordCommand(`my "ctx"`, `oc_sess_a\b"c`)
+	want := `add-generic-password -U -s "` + keyringService + `" -a "my \"ctx\"" -w "oc_sess_a\\b\"c"` + "\n"
+	if got != want {
+		t.Fatalf("command = %q, want %q", got, want)
+	}
+}
+
+func TestEncryptedFileCredentialStore(t *testing.T) {
+	path := setupConfigHome(t)
+	previous := credentialFileIterations
+	credentialFileIterations = 1000
+	defer func() { credentialFileIterations = previous }()
+
+	if err := AddContext("staging", Config{Token: "tok-staging", CredentialStore: "file"}, true); err == nil {
+		t.Fatalf("expected passphrase error")
+	}
+	t.Setenv(CredentialsPassphraseEnvVar, "correct horse")
+	if err := AddContext("staging", Config{Token: "tok-staging", CredentialStore: "file"}, true); err != nil {
+		t.Fatalf("add: %v", err)
+	}
+	if err := AddContext("dev", Config{Token: "tok-dev", CredentialStore: "file"}, false); err != nil {
+		t.Fatalf("add dev: %v", err)
+	}
+	encrypted, err := os.ReadFile(filepath.Join(filepath.Dir(path), credentialFileName))
+	if err != nil || strings.Contains(string(encrypted), "tok-staging") {
+		t.Fatalf("credentials file = %s, %v", encrypted, err)
+	}
+	if data, _ := os.ReadFile(path); strings.Contains(string(data), "tok-staging") {
+		t.Fatalf("token leaked into config: %s", data)
+	}
+
+	cfg, err := LoadConfig()
+	if err != nil || cfg.Token != "tok-staging" {
+		t.Fatalf("load = %#v, %v", cfg, err)
+	}
+
+	t.Setenv(CredentialsPassphraseEnvVar, "wrong")
+	if _, err := LoadConfig(); err == nil || !strings.Contains(err.Error(), "cannot decrypt") {
+		t.Fatalf("expected decrypt error, got %v", err)
+	}
+}
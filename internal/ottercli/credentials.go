@@ -0,0 +1,358 @@
+package ottercli
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/pbkdf2"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+)
+
+const (
+	CredentialStorePlaintext = "plaintext"
+	CredentialStoreKeyring   = "keyring"
+	CredentialStoreFile      = "file"
+
+	// CredentialsPassphraseEnvVar unlocks the encrypted credentials file.
+	CredentialsPassphraseEnvVar = "OTTER_CREDENTIALS_PASSPHRASE"
+
+	keyringService           = "otter-cli"
+	credentialFileName       = "credentials.enc"
+	credentialFileVersion    = 1
+	credentialFileKDF        = "pbkdf2-sha256"
+	credentialFileSaltLength = 16
+)
+
+var errCredentialNotFound = errors.New("credential not found")
+
+// credentialBackend keeps one secret per context name.
+type credentialBackend interface {
+	Get(name string) (string, error)
+	Set(name, secret string) error
+	Delete(name string) error
+}
+
+var (
+	// keyringBackend is replaced in tests; the default shells out to the OS
+	// keychain tool.
+	keyringBackend credentialBackend = osKeyring{}
+
+	credentialFileIterations = 600_000
+)
+
+func normalizeCredentialStore(store string) string {
+	store = strings.ToLower(strings.TrimSpace(store))
+	if store == "" {
+		return CredentialStorePlaintext
+	}
+	return store
+}
+
+func credentialBackendFor(store string) (credentialBackend, error) {
+	switch normalizeCredentialStore(store) {
+	case CredentialStorePlaintext:
+		return nil, nil
+	case CredentialStoreKeyring:
+		return keyringBackend, nil
+	case CredentialStoreFile:
+		return encryptedCredentialFile{}, nil
+	}
+	return nil, fmt.Errorf("credential store must be %s, %s or %s", CredentialStorePlaintext, CredentialStoreKeyring, CredentialStoreFile)
+}
+
+func loadContextToken(name string, cfg Config) (string, error) {
+	backend, err := credentialBackendFor(cfg.CredentialStore)
+	if err != nil {
+		return "", err
+	}
+	if backend == nil {
+		return strings.TrimSpace(cfg.Token), nil
+	}
+	token, err := backend.Get(name)
+	if errors.Is(err, errCredentialNotFound) {
+		return "", nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to read %s credentials for context %q: %w", normalizeCredentialStore(cfg.CredentialStore), name, err)
+	}
+	return token, nil
+}
+
+func saveContextToken(name string, cfg Config) error {
+	backend, err := credentialBackendFor(cfg.CredentialStore)
+	if err != nil || backend == nil {
+		return err
+	}
+	token := strings.TrimSpace(cfg.Token)
+	if token == "" {
+		return nil
+	}
+	if err := backend.Set(name, token); err != nil {
+		return fmt.Errorf("failed to save %s credentials for context %q: %w", normalizeCredentialStore(cfg.CredentialStore), name, err)
+	}
+	return nil
+}
+
+func deleteContextToken(name string, cfg Config) error {
+	backend, err := credentialBackendFor(cfg.CredentialStore)
+	if err != nil || backend == nil {
+		return err
+	}
+	if err := backend.Delete(name); err != nil && !errors.Is(err, errCredentialNotFound) {
+		return fmt.Errorf("failed to delete %s credentials for context %q: %w", normalizeCredentialStore(cfg.CredentialStore), name, err)
+	}
+	return nil
+}
+
+// osKeyring stores secrets with `security` on macOS and `secret-tool`
+// (libsecret) on Linux.
+type osKeyring struct{}
+
+func (osKeyring) run(stdin string, args ...string) ([]byte, error) {
+	var tool string
+	switch runtime.GOOS {
+	case "darwin":
+		tool = "security"
+	case "linux":
+		tool = "secret-tool"
+	default:
+		return nil, fmt.Errorf("keyring credentials are not supported on %s; use --credentials file", runtime.GOOS)
+	}
+	if _, err := exec.LookPath(tool); err != nil {
+		return nil, fmt.Errorf("keyring unavailable: %s not found", tool)
+	}
+	cmd := exec.Command(tool, args...)
+	if stdin != "" {
+		cmd.Stdin = strings.NewReader(stdin)
+	}
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) && len(bytes.TrimSpace(out)) == 0 {
+		detail := strings.TrimSpace(stderr.String())
+		if detail == "" || strings.Contains(strings.ToLower(detail), "could not be found") {
+			return nil, errCredentialNotFound
+		}
+		return nil, fmt.Errorf("%s: %s", tool, detail)
+	}
+	return out, err
+}
+
+func (k osKeyring) Get(name string) (string, error) {
+	var out []byte
+	var err error
+	if runtime.GOOS == "darwin" {
+		out, err = k.run("", "find-generic-password", "-s", keyringService, "-a", name, "-w")
+	} else {
+		out, err = k.run("", "lookup", "service", keyringService, "account", name)
+	}
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(out)), nil
+}
+
+func (k osKeyring) Set(name, secret string) error {
+	var err error
+	if runtime.GOOS == "darwin" {
+		if strings.ContainsAny(name+secret, "\r\n") {
+			return errors.New("keyring credentials cannot contain line breaks")
+		}
+		// `security -i` reads the command from stdin, which keeps the
+		// secret out of the process arguments other users can see in ps.
+		_, err = k.run(securityAddPasswordCommand(name, secret), "-i")
+	} else {
+		_, err = k.run(secret, "store", "--label", "Otter CLI ("+name+")", "service", keyringService, "account", name)
+	}
+	return err
+}
+
+// securityAddPasswordCommand builds the add-generic-password line for
+// `security -i`, double-quoting each argument.
+func securityAddPasswordCommand(name, secret string) string {
+	quote := func(value string) string {
+		value = strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(value)
+		return `"` + value + `"`
+	}
+	return "add-generic-password -U -s " + quote(keyringService) + " -a " + quote(name) + " -w " + quote(secret) + "\n"
+}
+
+func (k osKeyring) Delete(name string) error {
+	var err error
+	if runtime.GOOS == "darwin" {
+		_, err = k.run("", "delete-generic-password", "-s", keyringService, "-a", name)
+	} else {
+		_, err = k.run("", "clear", "service", keyringService, "account", name)
+	}
+	return err
+}
+
+// encryptedCredentialFile keeps every context's secret in one AES-256-GCM
+// encrypted file next to config.json, keyed by a PBKDF2-derived passphrase.
+type encryptedCredentialFile struct{}
+
+type encryptedCredentialEnvelope struct {
+	Version    int    `json:"version"`
+	KDF        string `json:"kdf"`
+	Iterations int    `json:"iterations"`
+	Salt       []byte `json:"salt"`
+	Nonce      []byte `json:"nonce"`
+	Ciphertext []byte `json:"ciphertext"`
+}
+
+func credentialFilePath() (string, error) {
+	configPath, err := ConfigPath()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(filepath.Dir(configPath), credentialFileName), nil
+}
+
+func credentialFilePassphrase() (string, error) {
+	passphrase := os.Getenv(CredentialsPassphraseEnvVar)
+	if passphrase == "" {
+		return "", fmt.Errorf("set %s to use the encrypted credentials file", CredentialsPassphraseEnvVar)
+	}
+	return passphrase, nil
+}
+
+func credentialFileCipher(passphrase string, salt []byte, iterations int) (cipher.AEAD, error) {
+	key, err := pbkdf2.Key(sha256.New, passphrase, salt, iterations, 32)
+	if err != nil {
+		return nil, err
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+func (encryptedCredentialFile) read() (map[string]string, error) {
+	path, err := credentialFilePath()
+	if err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return map[string]string{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	passphrase, err := credentialFilePassphrase()
+	if err != nil {
+		return nil, err
+	}
+	var envelope encryptedCredentialEnvelope
+	if err := json.Unmarshal(data, &envelope); err != nil {
+		return nil, fmt.Errorf("invalid credentials file: %w", err)
+	}
+	if envelope.Version != credentialFileVersion || envelope.KDF != credentialFileKDF {
+		return nil, fmt.Errorf("unsupported credentials file version %d (%s)", envelope.Version, envelope.KDF)
+	}
+	aead, err := credentialFileCipher(passphrase, envelope.Salt, envelope.Iterations)
+	if err != nil {
+		return nil, err
+	}
+	plaintext, err := aead.Open(nil, envelope.Nonce, envelope.Ciphertext, nil)
+	if err != nil {
+		return nil, errors.New("cannot decrypt credentials file; check " + CredentialsPassphraseEnvVar)
+	}
+	secrets := map[string]string{}
+	if err := json.Unmarshal(plaintext, &secrets); err != nil {
+		return nil, fmt.Errorf("invalid credentials file contents: %w", err)
+	}
+	return secrets, nil
+}
+
+func (encryptedCredentialFile) write(secrets map[string]string) error {
+	path, err := credentialFilePath()
+	if err != nil {
+		return err
+	}
+	if len(secrets) == 0 {
+		if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return err
+		}
+		return nil
+	}
+	passphrase, err := credentialFilePassphrase()
+	if err != nil {
+		return err
+	}
+	plaintext, err := json.Marshal(secrets)
+	if err != nil {
+		return err
+	}
+	salt := make([]byte, credentialFileSaltLength)
+	if _, err := rand.Read(salt); err != nil {
+		return err
+	}
+	aead, err := credentialFileCipher(passphrase, salt, credentialFileIterations)
+	if err != nil {
+		return err
+	}
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return err
+	}
+	payload, err := json.MarshalIndent(encryptedCredentialEnvelope{
+		Version:    credentialFileVersion,
+		KDF:        credentialFileKDF,
+		Iterations: credentialFileIterations,
+		Salt:       salt,
+		Nonce:      nonce,
+		Ciphertext: aead.Seal(nil, nonce, plaintext, nil),
+	}, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return err
+	}
+	return os.WriteFile(path, payload, 0o600)
+}
+
+func (f encryptedCredentialFile) Get(name string) (string, error) {
+	secrets, err := f.read()
+	if err != nil {
+		return "", err
+	}
+	secret, ok := secrets[name]
+	if !ok {
+		return "", errCredentialNotFound
+	}
+	return secret, nil
+}
+
+func (f encryptedCredentialFile) Set(name, secret string) error {
+	secrets, err := f.read()
+	if err != nil {
+		return err
+	}
+	secrets[name] = secret
+	return f.write(secrets)
+}
+
+func (f encryptedCredentialFile) Delete(name string) error {
+	secrets, err := f.read()
+	if err != nil {
+		return err
+	}
+	if _, ok := secrets[name]; !ok {
+		return errCredentialNotFound
+	}
+	delete(secrets, name)
+	return f.write(secrets)
+}
@@ -0,0 +1,190 @@
+package scheduler
+
+import (
+	"fmt"
+	"math/rand"
+	"strings"
+	"time"
+
+	"github.com/samhotchkiss/otter-camp/internal/store"
+)
+
+// maxBlackoutSkips bounds how many consecutive blackout windows a single next
+// run computation may step over before giving up.
+const maxBlackoutSkips = 512
+
+// RunPolicy shapes where a job's next run lands once the base schedule has
+// picked an occurrence: it adds jitter and steps over blackout windows.
+type RunPolicy struct {
+	Jitter    time.Duration
+	Blackouts []store.AgentJobBlackoutWindow
+	// Int63n returns a value in [0, n). Defaults to math/rand.
+	Int63n func(int64) int64
+}
+
+func RunPolicyForJob(job store.AgentJob, blackouts []store.AgentJobBlackoutWindow) RunPolicy {
+	return RunPolicy{
+		Jitter:    time.Duration(job.JitterMS) * time.Millisecond,
+		Blackouts: blackouts,
+	}
+}
+
+// ComputeNextRunWithPolicy is ComputeNextRun followed by jitter and blackout
+// avoidance in the schedule's timezone.
+func ComputeNextRunWithPolicy(
+	spec ScheduleSpec,
+	policy RunPolicy,
+	now time.Time,
+	lastRunAt *time.Time,
+) (*time.Time, error) {
+	next, err := ComputeNextRun(spec, now, lastRunAt)
+	if err != nil || next == nil {
+		return next, err
+	}
+	return applyRunPolicy(spec, policy, *next)
+}
+
+// BlackoutEnd reports whether at falls inside any blackout window when read
+// in loc, and if so the wall-clock instant the covering window ends.
+func BlackoutEnd(at time.Time, loc *time.Location, windows []store.AgentJobBlackoutWindow) (time.Time, bool) {
+	if len(windows) == 0 {
+		return time.Time{}, false
+	}
+	if loc == nil {
+		loc = time.UTC
+	}
+	local := at.In(loc)
+	date := local.Format("2006-01-02")
+	weekday := int(local.Weekday())
+	minute := local.Hour()*60 + local.Minute()
+
+	var (
+		end     time.Time
+		blocked bool
+	)
+	for _, window := range windows {
+		if !blackoutWindowMatches(window, date, weekday, minute) {
+			continue
+		}
+		year, month, day := local.Date()
+		windowEnd := time.Date(year, month, day, 0, window.EndMinute, 0, 0, loc)
+		if !blocked || windowEnd.After(end) {
+			end = windowEnd
+		}
+		blocked = true
+	}
+	return end, blocked
+}
+
+// MissedRuns counts schedule occurrences strictly after dueAt that are
+// already at or before now, stopping once limit is reached.
+func MissedRuns(spec ScheduleSpec, dueAt, now time.Time, limit int) (int, error) {
+	if limit <= 0 {
+		return 0, nil
+	}
+	missed := 0
+	cursor := dueAt.UTC()
+	for missed < limit {
+		next, err := ComputeNextRun(spec, cursor, &cursor)
+		if err != nil {
+			return 0, err
+		}
+		if next == nil || next.After(now) || !next.After(cursor) {
+			break
+		}
+		missed++
+		cursor = *next
+	}
+	return missed, nil
+}
+
+func applyRunPolicy(spec ScheduleSpec, policy RunPolicy, candidate time.Time) (*time.Time, error) {
+	location, err := scheduleLocation(spec)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := 0; i < maxBlackoutSkips; i++ {
+		next := candidate.Add(policy.jitter()).UTC()
+		end, blocked := BlackoutEnd(next, location, policy.Blackouts)
+		if !blocked {
+			return &next, nil
+		}
+		candidate, err = occurrenceAtOrAfter(spec, candidate, end)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return nil, fmt.Errorf("no run time outside blackout windows")
+}
+
+func occurrenceAtOrAfter(spec ScheduleSpec, candidate, boundary time.Time) (time.Time, error) {
+	switch spec.Kind {
+	case ScheduleKindCron:
+		next, err := ComputeNextRun(spec, boundary.Add(-time.Second), nil)
+		if err != nil {
+			return time.Time{}, err
+		}
+		return next.UTC(), nil
+	case ScheduleKindInterval:
+		if spec.Interval <= 0 {
+			return time.Time{}, fmt.Errorf("interval schedule requires a positive interval")
+		}
+		gap := boundary.Sub(candidate)
+		steps := gap / spec.Interval
+		if gap%spec.Interval != 0 {
+			steps++
+		}
+		if steps < 1 {
+			steps = 1
+		}
+		return candidate.Add(steps * spec.Interval).UTC(), nil
+	default:
+		return boundary.UTC(), nil
+	}
+}
+
+func (p RunPolicy) jitter() time.Duration {
+	jitterMS := p.Jitter.Milliseconds()
+	if jitterMS <= 0 {
+		return 0
+	}
+	int63n := p.Int63n
+	if int63n == nil {
+		int63n = rand.Int63n
+	}
+	return time.Duration(int63n(jitterMS+1)) * time.Millisecond
+}
+
+func blackoutWindowMatches(window store.AgentJobBlackoutWindow, date string, weekday, minute int) bool {
+	if window.StartDate != nil && date < strings.TrimSpace(*window.StartDate) {
+		return false
+	}
+	if window.EndDate != nil && date > strings.TrimSpace(*window.EndDate) {
+		return false
+	}
+	if len(window.DaysOfWeek) > 0 {
+		matchedDay := false
+		for _, day := range window.DaysOfWeek {
+			if day == weekday {
+				matchedDay = true
+				break
+			}
+		}
+		if !matchedDay {
+			return false
+		}
+	}
+	return minute >= window.StartMinute && minute < window.EndMinute
+}
+
+func scheduleLocation(spec ScheduleSpec) (*time.Location, error) {
+	if spec.location != nil {
+		return spec.location, nil
+	}
+	location, err := time.LoadLocation(firstNonEmpty(strings.TrimSpace(spec.Timezone), "UTC"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid timezone: %w", err)
+	}
+	return location, nil
+}
@@ -0,0 +1,155 @@
+package scheduler
+
+import (
+	"testing"
+	"time"
+
+	"github.com/samhotchkiss/otter-camp/internal/store"
+	"github.com/stretchr/testify/require"
+)
+
+func TestJobPolicyBlackoutEndUsesJobTimezone(t *testing.T) {
+	newYork, err := time.LoadLocation("America/New_York")
+	require.NoError(t, err)
+	weekends := []store.AgentJobBlackoutWindow{
+		{Name: "Weekends", DaysOfWeek: []int{0, 6}, StartMinute: 0, EndMinute: 24 * 60},
+	}
+
+	// Saturday 02:00 UTC is still Friday evening in New York.
+	fridayEvening := time.Date(2026, 2, 14, 2, 0, 0, 0, time.UTC)
+	_, blocked := BlackoutEnd(fridayEvening, newYork, weekends)
+	require.False(t, blocked)
+	_, blocked = BlackoutEnd(fridayEvening, time.UTC, weekends)
+	require.True(t, blocked)
+
+	saturdayMorning := time.Date(2026, 2, 14, 15, 0, 0, 0, time.UTC)
+	end, blocked := BlackoutEnd(saturdayMorning, newYork, weekends)
+	require.True(t, blocked)
+	require.Equal(t, time.Date(2026, 2, 15, 0, 0, 0, 0, newYork), end)
+}
+
+func TestJobPolicyBlackoutMatchesDateRangeAndTimeOfDay(t *testing.T) {
+	holidayStart := "2026-12-24"
+	holidayEnd := "2026-12-26"
+	windows := []store.AgentJobBlackoutWindow{
+		{Name: "Holidays", StartDate: &holidayStart, EndDate: &holidayEnd, StartMinute: 0, EndMinute: 24 * 60},
+		{Name: "Nightly maintenance", StartMinute: 2 * 60, EndMinute: 3 * 60},
+	}
+
+	_, blocked := BlackoutEnd(time.Date(2026, 12, 23, 12, 0, 0, 0, time.UTC), time.UTC, windows)
+	require.False(t, blocked)
+	end, blocked := BlackoutEnd(time.Date(2026, 12, 25, 12, 0, 0, 0, time.UTC), time.UTC, windows)
+	require.True(t, blocked)
+	require.Equal(t, time.Date(2026, 12, 26, 0, 0, 0, 0, time.UTC), end)
+
+	end, blocked = BlackoutEnd(time.Date(2026, 3, 2, 2, 30, 0, 0, time.UTC), time.UTC, windows)
+	require.True(t, blocked)
+	require.Equal(t, time.Date(2026, 3, 2, 3, 0, 0, 0, time.UTC), end)
+	_, blocked = BlackoutEnd(time.Date(2026, 3, 2, 3, 0, 0, 0, time.UTC), time.UTC, windows)
+	require.False(t, blocked)
+}
+
+func TestJobPolicyComputeNextRunSkipsWeekendForCron(t *testing.T) {
+	spec, err := NormalizeScheduleSpec("cron", strPtr("0 9 * * *"), nil, nil, "America/New_York")
+	require.NoError(t, err)
+	policy := RunPolicy{Blackouts: []store.AgentJobBlackoutWindow{
+		{Name: "Weekends", DaysOfWeek: []int{0, 6}, StartMinute: 0, EndMinute: 24 * 60},
+	}}
+
+	// Friday 2026-02-13 15:00 UTC, after the 09:00 New York run.
+	now := time.Date(2026, 2, 13, 15, 0, 0, 0, time.UTC)
+	nextRunAt, err := ComputeNextRunWithPolicy(spec, policy, now, nil)
+	require.NoError(t, err)
+	require.NotNil(t, nextRunAt)
+	require.Equal(t, time.Date(2026, 2, 16, 14, 0, 0, 0, time.UTC), nextRunAt.UTC())
+}
+
+func TestJobPolicyComputeNextRunAlignsIntervalPastBlackout(t *testing.T) {
+	spec, err := NormalizeScheduleSpec("interval", nil, int64Ptr(int64((25 * time.Minute).Milliseconds())), nil, "UTC")
+	require.NoError(t, err)
+	policy := RunPolicy{Blackouts: []store.AgentJobBlackoutWindow{
+		{Name: "Maintenance", StartMinute: 2 * 60, EndMinute: 3 * 60},
+	}}
+
+	lastRun := time.Date(2026, 2, 12, 1, 50, 0, 0, time.UTC)
+	nextRunAt, err := ComputeNextRunWithPolicy(spec, policy, lastRun, &lastRun)
+	require.NoError(t, err)
+	require.NotNil(t, nextRunAt)
+	require.Equal(t, time.Date(2026, 2, 12, 3, 5, 0, 0, time.UTC), nextRunAt.UTC())
+}
+
+func TestJobPolicyComputeNextRunDefersOnceToWindowEnd(t *testing.T) {
+	runAt := time.Date(2026, 2, 14, 12, 0, 0, 0, time.UTC)
+	spec, err := NormalizeScheduleSpec("once", nil, nil, &runAt, "UTC")
+	require.NoError(t, err)
+	policy := RunPolicy{Blackouts: []store.AgentJobBlackoutWindow{
+		{Name: "Weekends", DaysOfWeek: []int{0, 6}, StartMinute: 0, EndMinute: 24 * 60},
+	}}
+
+	nextRunAt, err := ComputeNextRunWithPolicy(spec, policy, runAt.Add(-time.Hour), nil)
+	require.NoError(t, err)
+	require.NotNil(t, nextRunAt)
+	require.Equal(t, time.Date(2026, 2, 16, 0, 0, 0, 0, time.UTC), nextRunAt.UTC())
+}
+
+func TestJobPolicyComputeNextRunFailsWhenAlwaysBlackedOut(t *testing.T) {
+	spec, err := NormalizeScheduleSpec("cron", strPtr("0 9 * * *"), nil, nil, "UTC")
+	require.NoError(t, err)
+	policy := RunPolicy{Blackouts: []store.AgentJobBlackoutWindow{
+		{Name: "Always", DaysOfWeek: []int{0, 1, 2, 3, 4, 5, 6}, StartMinute: 0, EndMinute: 24 * 60},
+	}}
+
+	_, err = ComputeNextRunWithPolicy(spec, policy, time.Date(2026, 2, 12, 0, 0, 0, 0, time.UTC), nil)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "blackout")
+}
+
+func TestJobPolicyJitterStaysWithinBound(t *testing.T) {
+	spec, err := NormalizeScheduleSpec("cron", strPtr("0 9 * * *"), nil, nil, "UTC")
+	require.NoError(t, err)
+	now := time.Date(2026, 2, 12, 8, 0, 0, 0, time.UTC)
+	base := time.Date(2026, 2, 12, 9, 0, 0, 0, time.UTC)
+
+	var requested int64
+	policy := RunPolicy{
+		Jitter: 5 * time.Minute,
+		Int63n: func(n int64) int64 {
+			requested = n
+			return n - 1
+		},
+	}
+	nextRunAt, err := ComputeNextRunWithPolicy(spec, policy, now, nil)
+	require.NoError(t, err)
+	require.Equal(t, int64((5*time.Minute).Milliseconds())+1, requested)
+	require.Equal(t, base.Add(5*time.Minute), nextRunAt.UTC())
+
+	policy.Int63n = func(int64) int64 { return 0 }
+	nextRunAt, err = ComputeNextRunWithPolicy(spec, policy, now, nil)
+	require.NoError(t, err)
+	require.Equal(t, base, nextRunAt.UTC())
+}
+
+func TestJobPolicyMissedRunsCountsOccurrencesUpToLimit(t *testing.T) {
+	spec, err := NormalizeScheduleSpec("interval", nil, int64Ptr(60000), nil, "UTC")
+	require.NoError(t, err)
+	dueAt := time.Date(2026, 2, 12, 9, 0, 0, 0, time.UTC)
+
+	missed, err := MissedRuns(spec, dueAt, dueAt.Add(30*time.Second), 100)
+	require.NoError(t, err)
+	require.Equal(t, 0, missed)
+
+	missed, err = MissedRuns(spec, dueAt, dueAt.Add(5*time.Minute), 100)
+	require.NoError(t, err)
+	require.Equal(t, 5, missed)
+
+	missed, err = MissedRuns(spec, dueAt, dueAt.Add(5*time.Hour), 10)
+	require.NoError(t, err)
+	require.Equal(t, 10, missed)
+
+	runAt := dueAt
+	onceSpec, err := NormalizeScheduleSpec("once", nil, nil, &runAt, "UTC")
+	require.NoError(t, err)
+	missed, err = MissedRuns(onceSpec, dueAt, dueAt.Add(24*time.Hour), 100)
+	require.NoError(t, err)
+	require.Equal(t, 0, missed)
+}
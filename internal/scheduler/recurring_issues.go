@@ -0,0 +1,375 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/samhotchkiss/otter-camp/internal/metrics"
+	"github.com/samhotchkiss/otter-camp/internal/middleware"
+	"github.com/samhotchkiss/otter-camp/internal/store"
+	"github.com/samhotchkiss/otter-camp/internal/tracing"
+)
+
+// RecurringIssueOutcome is what one occurrence of a recurring issue did.
+type RecurringIssueOutcome struct {
+	Status string              `json:"status"`
+	Issue  *store.ProjectIssue `json:"issue,omitempty"`
+	Reason string              `json:"reason,omitempty"`
+}
+
+// RecurringIssueGenerator opens the issue for one occurrence of a recurring
+// issue definition. The API's run-now endpoint and RecurringIssueWorker share
+// it so both produce the same issue.
+type RecurringIssueGenerator struct {
+	Issues    *store.ProjectIssueStore
+	Flows     *store.ProjectFlowStore
+	Templates *store.IssueTemplateStore
+	Labels    *store.LabelStore
+	Logf      func(string, ...any)
+}
+
+// Generate renders and creates the issue for the occurrence at occurrence.
+// With skip_if_open set it creates nothing while the previous instance is
+// still open. It does not record the run; callers do.
+func (g *RecurringIssueGenerator) Generate(
+	ctx context.Context,
+	def store.RecurringIssue,
+	occurrence time.Time,
+) (RecurringIssueOutcome, error) {
+	if g == nil || g.Issues == nil {
+		return RecurringIssueOutcome{}, fmt.Errorf("recurring issue generator is not configured")
+	}
+
+	if def.SkipIfOpen && def.LastIssueID != nil {
+		previous, err := g.Issues.GetIssueByID(ctx, *def.LastIssueID)
+		if err != nil && !errors.Is(err, store.ErrNotFound) {
+			return RecurringIssueOutcome{}, err
+		}
+		if err == nil && previous.State == "open" {
+			return RecurringIssueOutcome{
+				Status: store.RecurringIssueRunSkipped,
+				Reason: fmt.Sprintf("previous issue #%d is still open", previous.IssueNumber),
+			}, nil
+		}
+	}
+
+	location, err := time.LoadLocation(firstNonEmpty(strings.TrimSpace(def.Timezone), "UTC"))
+	if err != nil {
+		return RecurringIssueOutcome{}, fmt.Errorf("invalid timezone: %w", err)
+	}
+	vars := RecurringIssueVars(occurrence.In(location), def.RunCount+1)
+
+	var template *store.IssueTemplate
+	var fieldValues []store.IssueFieldValue
+	if def.IssueTemplateID != nil && g.Templates != nil {
+		orgID := middleware.WorkspaceFromContext(ctx)
+		template, err = g.Templates.Resolve(ctx, orgID, def.ProjectID, *def.IssueTemplateID)
+		if err != nil {
+			return RecurringIssueOutcome{}, fmt.Errorf("failed to load issue template: %w", err)
+		}
+		provided := make(map[string]string, len(def.Fields))
+		for key, value := range def.Fields {
+			provided[key] = RenderRecurringIssueText(value, vars)
+		}
+		fieldValues, err = template.ResolveFieldValues(provided)
+		if err != nil {
+			return RecurringIssueOutcome{}, err
+		}
+	}
+
+	title := RenderRecurringIssueText(def.TitleTemplate, vars)
+	body := RenderRecurringIssueText(def.BodyTemplate, vars)
+	if body == "" && template != nil {
+		body = RenderRecurringIssueText(template.RenderBody(fieldValues), vars)
+	}
+	var bodyPtr *string
+	if body != "" {
+		bodyPtr = &body
+	}
+
+	input := store.CreateProjectIssueInput{
+		ProjectID:    def.ProjectID,
+		Title:        title,
+		Body:         bodyPtr,
+		State:        "open",
+		Origin:       "local",
+		Priority:     def.Priority,
+		OwnerAgentID: def.OwnerAgentID,
+		WorkStatus:   store.IssueWorkStatusQueued,
+	}
+	flowTemplateID := def.FlowTemplateID
+	if flowTemplateID == nil && template != nil {
+		flowTemplateID = template.DefaultFlowTemplateID
+	}
+	if err := g.applyFlow(ctx, def.ProjectID, flowTemplateID, &input); err != nil {
+		return RecurringIssueOutcome{}, err
+	}
+
+	issue, err := g.Issues.CreateIssue(ctx, input)
+	if err != nil {
+		return RecurringIssueOutcome{}, err
+	}
+	if issue.OwnerAgentID != nil {
+		if _, err := g.Issues.AddParticipant(ctx, store.AddProjectIssueParticipantInput{
+			IssueID: issue.ID,
+			AgentID: *issue.OwnerAgentID,
+			Role:    "owner",
+		}); err != nil {
+			return RecurringIssueOutcome{}, err
+		}
+	}
+	if template != nil {
+		orgID := middleware.WorkspaceFromContext(ctx)
+		if err := g.Templates.SetFieldValues(ctx, orgID, issue.ID, template.ID, fieldValues); err != nil {
+			return RecurringIssueOutcome{}, err
+		}
+		g.applyLabels(ctx, issue.ID, template.DefaultLabels)
+	}
+	return RecurringIssueOutcome{Status: store.RecurringIssueRunCreated, Issue: issue}, nil
+}
+
+// applyFlow starts the issue on the first step of the requested flow, or of
+// the project's default flow when none is requested.
+func (g *RecurringIssueGenerator) applyFlow(
+	ctx context.Context,
+	projectID string,
+	flowTemplateID *string,
+	input *store.CreateProjectIssueInput,
+) error {
+	if g.Flows == nil {
+		return nil
+	}
+	var flow *store.ProjectFlowTemplate
+	var err error
+	if flowTemplateID != nil {
+		flow, err = g.Flows.GetTemplateByID(ctx, *flowTemplateID)
+		if err != nil {
+			return fmt.Errorf("failed to load flow template: %w", err)
+		}
+		if flow.ProjectID != projectID {
+			return errors.New("flow template does not belong to project")
+		}
+	} else {
+		flow, err = g.Flows.GetDefaultTemplateForProject(ctx, projectID)
+		if errors.Is(err, store.ErrNotFound) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+	}
+	steps, err := g.Flows.ListTemplateSteps(ctx, flow.ID)
+	if err != nil {
+		return err
+	}
+	if len(steps) == 0 {
+		return errors.New("flow template has no steps")
+	}
+	step := steps[0]
+	stepIndex := step.StepOrder
+	input.FlowTemplateID = &flow.ID
+	input.FlowStepKey = &step.StepKey
+	input.FlowStepIndex = &stepIndex
+	return nil
+}
+
+func (g *RecurringIssueGenerator) applyLabels(ctx context.Context, issueID string, names []string) {
+	if g.Labels == nil {
+		return
+	}
+	for _, name := range names {
+		label, err := g.Labels.EnsureByName(ctx, name, "")
+		if err == nil {
+			err = g.Labels.AddToIssue(ctx, issueID, label.ID)
+		}
+		if err != nil && g.Logf != nil {
+			g.Logf("recurring issue: failed to apply label %q to issue %s: %v", name, issueID, err)
+		}
+	}
+}
+
+// RecurringIssueVars returns the date variables for an occurrence, already
+// converted to the definition's timezone.
+func RecurringIssueVars(at time.Time, runNumber int) map[string]string {
+	year, week := at.ISOWeek()
+	return map[string]string{
+		"date":       at.Format("2006-01-02"),
+		"datetime":   at.Format(time.RFC3339),
+		"time":       at.Format("15:04"),
+		"weekday":    at.Weekday().String(),
+		"day":        at.Format("02"),
+		"week":       fmt.Sprintf("%02d", week),
+		"iso_week":   fmt.Sprintf("%d-W%02d", year, week),
+		"month":      at.Format("01"),
+		"month_name": at.Month().String(),
+		"quarter":    "Q" + strconv.Itoa((int(at.Month())-1)/3+1),
+		"year":       at.Format("2006"),
+		"run_number": strconv.Itoa(runNumber),
+	}
+}
+
+// RenderRecurringIssueText replaces {{name}} placeholders with vars. Unknown
+// placeholders are left as written.
+func RenderRecurringIssueText(value string, vars map[string]string) string {
+	pairs := make([]string, 0, len(vars)*2)
+	for key, replacement := range vars {
+		pairs = append(pairs, "{{"+key+"}}", replacement)
+	}
+	return strings.TrimSpace(strings.NewReplacer(pairs...).Replace(value))
+}
+
+type RecurringIssueWorkerConfig struct {
+	PollInterval time.Duration
+	MaxPerPoll   int
+	// RunTimeout bounds one occurrence. A claim the worker has not released
+	// by then is treated as abandoned and the occurrence is picked up again.
+	RunTimeout  time.Duration
+	WorkspaceID string
+}
+
+// RecurringIssueWorker opens issues for recurring issue definitions as their
+// schedules come due. Missed occurrences are not replayed: a definition that
+// was due several times while the worker was down opens one issue. An
+// occurrence whose outcome was never recorded is retried once its claim is
+// older than RunTimeout.
+type RecurringIssueWorker struct {
+	Store     *store.RecurringIssueStore
+	Generator *RecurringIssueGenerator
+	Config    RecurringIssueWorkerConfig
+	Now       func() time.Time
+	Logf      func(string, ...any)
+}
+
+func NewRecurringIssueWorker(
+	recurringStore *store.RecurringIssueStore,
+	generator *RecurringIssueGenerator,
+	cfg RecurringIssueWorkerConfig,
+) *RecurringIssueWorker {
+	if cfg.PollInterval <= 0 {
+		cfg.PollInterval = defaultJobWorkerPollInterval
+	}
+	if cfg.MaxPerPoll <= 0 {
+		cfg.MaxPerPoll = defaultJobWorkerMaxPerPoll
+	}
+	if cfg.RunTimeout <= 0 {
+		cfg.RunTimeout = defaultJobWorkerRunTimeout
+	}
+	return &RecurringIssueWorker{
+		Store:     recurringStore,
+		Generator: generator,
+		Config:    cfg,
+		Now: func() time.Time {
+			return time.Now().UTC()
+		},
+	}
+}
+
+func (w *RecurringIssueWorker) Start(ctx context.Context) {
+	for {
+		started := time.Now()
+		runCtx, span := tracing.StartWorkerRun(ctx, "recurring_issues")
+		processed, err := w.RunOnce(runCtx)
+		tracing.EndWorkerRun(span, processed > 0, err)
+		metrics.ObserveWorkerRun("recurring_issues", time.Since(started), err)
+		if err != nil && w.Logf != nil {
+			w.Logf("recurring issue worker run failed: %v", err)
+		}
+		if err := sleepWithContext(ctx, w.Config.PollInterval); err != nil {
+			return
+		}
+	}
+}
+
+func (w *RecurringIssueWorker) RunOnce(ctx context.Context) (int, error) {
+	if w == nil || w.Store == nil || w.Generator == nil {
+		return 0, fmt.Errorf("recurring issue worker is not configured")
+	}
+	ctx = withWorkerWorkspace(ctx, w.Config.WorkspaceID)
+
+	runTimeout := w.Config.RunTimeout
+	if runTimeout <= 0 {
+		runTimeout = defaultJobWorkerRunTimeout
+	}
+	due, err := w.Store.PickupDue(ctx, w.Config.MaxPerPoll, w.now(), runTimeout)
+	if err != nil {
+		return 0, err
+	}
+	for _, def := range due {
+		runCtx, cancel := context.WithTimeout(ctx, runTimeout)
+		runErr := w.runDefinition(runCtx, def)
+		cancel()
+		if runErr != nil && w.Logf != nil {
+			w.Logf("recurring issue %s failed: %v", def.ID, runErr)
+		}
+	}
+	return len(due), nil
+}
+
+// runDefinition opens the issue for a claimed occurrence and schedules the
+// next one. Failures are recorded on the definition and do not stop the
+// schedule.
+func (w *RecurringIssueWorker) runDefinition(ctx context.Context, def store.RecurringIssue) error {
+	now := w.now()
+	occurrence := now
+	if def.DueAt != nil {
+		occurrence = def.DueAt.UTC()
+	}
+
+	spec, specErr := NormalizeScheduleSpec(def.ScheduleKind, def.CronExpr, def.IntervalMS, def.RunAt, def.Timezone)
+	var nextRunAt *time.Time
+	if specErr == nil {
+		nextRunAt, specErr = ComputeNextRun(spec, now, &now)
+	}
+	if specErr != nil {
+		return w.recordFailure(ctx, def, nil, specErr)
+	}
+
+	outcome, err := w.Generator.Generate(ctx, def, occurrence)
+	if err != nil {
+		return w.recordFailure(ctx, def, nextRunAt, err)
+	}
+	input := store.RecordRecurringIssueRunInput{
+		ID:        def.ID,
+		Status:    outcome.Status,
+		RanAt:     now,
+		NextRunAt: nextRunAt,
+	}
+	if outcome.Issue != nil {
+		input.IssueID = &outcome.Issue.ID
+	}
+	if outcome.Reason != "" {
+		input.Error = &outcome.Reason
+	}
+	_, err = w.Store.RecordRun(ctx, input)
+	return err
+}
+
+func (w *RecurringIssueWorker) recordFailure(
+	ctx context.Context,
+	def store.RecurringIssue,
+	nextRunAt *time.Time,
+	failure error,
+) error {
+	message := failure.Error()
+	if _, err := w.Store.RecordRun(ctx, store.RecordRecurringIssueRunInput{
+		ID:        def.ID,
+		Status:    store.RecurringIssueRunError,
+		Error:     &message,
+		RanAt:     w.now(),
+		NextRunAt: nextRunAt,
+	}); err != nil {
+		return errors.Join(failure, err)
+	}
+	return failure
+}
+
+func (w *RecurringIssueWorker) now() time.Time {
+	if w.Now == nil {
+		return time.Now().UTC()
+	}
+	return w.Now().UTC()
+}
@@ -0,0 +1,174 @@
+package scheduler
+
+import (
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/samhotchkiss/otter-camp/internal/store"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRecurringIssueVarsUseOccurrenceLocalTime(t *testing.T) {
+	location, err := time.LoadLocation("America/New_York")
+	require.NoError(t, err)
+	occurrence := time.Date(2026, 10, 19, 13, 0, 0, 0, time.UTC).In(location)
+
+	vars := RecurringIssueVars(occurrence, 7)
+	require.Equal(t, "2026-10-19", vars["date"])
+	require.Equal(t, "09:00", vars["time"])
+	require.Equal(t, "Monday", vars["weekday"])
+	require.Equal(t, "43", vars["week"])
+	require.Equal(t, "2026-W43", vars["iso_week"])
+	require.Equal(t, "October", vars["month_name"])
+	require.Equal(t, "Q4", vars["quarter"])
+	require.Equal(t, "7", vars["run_number"])
+
+	rendered := RenderRecurringIssueText(" Weekly metrics report — {{iso_week}} ({{date}}) {{unknown}} ", vars)
+	require.Equal(t, "Weekly metrics report — 2026-W43 (2026-10-19) {{unknown}}", rendered)
+}
+
+func schedulerCreateProject(t *testing.T, db *sql.DB, orgID, name string) string {
+	t.Helper()
+	var id string
+	err := db.QueryRow(
+		`INSERT INTO projects (org_id, name, status) VALUES ($1, $2, 'active') RETURNING id`,
+		orgID,
+		name,
+	).Scan(&id)
+	require.NoError(t, err)
+	return id
+}
+
+func TestRecurringIssueWorkerOpensIssueAndSkipsWhilePreviousIsOpen(t *testing.T) {
+	db := setupSchedulerTestDB(t)
+	orgID := schedulerCreateOrg(t, db, "recurring-issue-worker")
+	agentID := schedulerCreateAgent(t, db, orgID, "analyst-1")
+	projectID := schedulerCreateProject(t, db, orgID, "Metrics")
+	ctx := schedulerCtxWithWorkspace(orgID)
+
+	flows := store.NewProjectFlowStore(db)
+	flow, err := flows.CreateTemplate(ctx, store.CreateProjectFlowTemplateInput{ProjectID: projectID, Name: "Report"})
+	require.NoError(t, err)
+	_, err = flows.ReplaceTemplateSteps(ctx, flow.ID, []store.CreateProjectFlowTemplateStepInput{
+		{StepKey: "draft", Label: "Draft", Role: store.FlowRoleWorker},
+		{StepKey: "review", Label: "Review", Role: store.FlowRoleReviewer},
+	})
+	require.NoError(t, err)
+
+	// Monday 2026-10-19 09:00 in New York.
+	now := time.Date(2026, 10, 19, 13, 0, 5, 0, time.UTC)
+	dueAt := time.Date(2026, 10, 19, 13, 0, 0, 0, time.UTC)
+	recurring := store.NewRecurringIssueStore(db)
+	def, err := recurring.Create(ctx, store.CreateRecurringIssueInput{
+		ProjectID:      projectID,
+		Name:           "Weekly metrics",
+		TitleTemplate:  "Weekly metrics report — {{date}}",
+		BodyTemplate:   "Numbers for week {{week}} of {{year}}.",
+		OwnerAgentID:   &agentID,
+		FlowTemplateID: &flow.ID,
+		ScheduleKind:   store.AgentJobScheduleCron,
+		CronExpr:       strPtr("0 9 * * 1"),
+		Timezone:       "America/New_York",
+		SkipIfOpen:     true,
+		NextRunAt:      &dueAt,
+	})
+	require.NoError(t, err)
+
+	issues := store.NewProjectIssueStore(db)
+	worker := NewRecurringIssueWorker(recurring, &RecurringIssueGenerator{
+		Issues:    issues,
+		Flows:     flows,
+		Templates: store.NewIssueTemplateStore(db),
+		Labels:    store.NewLabelStore(db),
+	}, RecurringIssueWorkerConfig{MaxPerPoll: 5})
+	worker.Now = func() time.Time { return now }
+
+	processed, err := worker.RunOnce(ctx)
+	require.NoError(t, err)
+	require.Equal(t, 1, processed)
+
+	def, err = recurring.GetByID(ctx, def.ID)
+	require.NoError(t, err)
+	require.Equal(t, store.RecurringIssueRunCreated, *def.LastRunStatus)
+	require.Equal(t, 1, def.RunCount)
+	require.NotNil(t, def.LastIssueID)
+	require.NotNil(t, def.NextRunAt)
+	require.Equal(t, time.Date(2026, 10, 26, 13, 0, 0, 0, time.UTC), def.NextRunAt.UTC())
+
+	issue, err := issues.GetIssueByID(ctx, *def.LastIssueID)
+	require.NoError(t, err)
+	require.Equal(t, "Weekly metrics report — 2026-10-19", issue.Title)
+	require.Equal(t, "Numbers for week 43 of 2026.", *issue.Body)
+	require.Equal(t, agentID, *issue.OwnerAgentID)
+	require.Equal(t, flow.ID, *issue.FlowTemplateID)
+	require.Equal(t, "draft", *issue.FlowStepKey)
+	participants, err := issues.ListParticipants(ctx, issue.ID, false)
+	require.NoError(t, err)
+	require.Len(t, participants, 1)
+
+	// The next Monday comes around while last week's report is still open.
+	now = now.Add(7 * 24 * time.Hour)
+	processed, err = worker.RunOnce(ctx)
+	require.NoError(t, err)
+	require.Equal(t, 1, processed)
+
+	def, err = recurring.GetByID(ctx, def.ID)
+	require.NoError(t, err)
+	require.Equal(t, store.RecurringIssueRunSkipped, *def.LastRunStatus)
+	require.Contains(t, *def.LastRunError, "is still open")
+	require.Equal(t, 1, def.RunCount)
+	require.Equal(t, issue.ID, *def.LastIssueID)
+	require.Equal(t, time.Date(2026, 11, 2, 14, 0, 0, 0, time.UTC), def.NextRunAt.UTC())
+}
+
+func TestRecurringIssueWorkerRetriesAbandonedClaims(t *testing.T) {
+	db := setupSchedulerTestDB(t)
+	orgID := schedulerCreateOrg(t, db, "recurring-issue-abandoned-claim")
+	projectID := schedulerCreateProject(t, db, orgID, "Ops")
+	ctx := schedulerCtxWithWorkspace(orgID)
+
+	now := time.Date(2026, 10, 19, 13, 0, 5, 0, time.UTC)
+	dueAt := time.Date(2026, 10, 19, 13, 0, 0, 0, time.UTC)
+	recurring := store.NewRecurringIssueStore(db)
+	def, err := recurring.Create(ctx, store.CreateRecurringIssueInput{
+		ProjectID:     projectID,
+		Name:          "Daily check",
+		TitleTemplate: "Daily check — {{date}}",
+		ScheduleKind:  store.AgentJobScheduleCron,
+		CronExpr:      strPtr("0 13 * * *"),
+		NextRunAt:     &dueAt,
+	})
+	require.NoError(t, err)
+
+	// Another worker claimed the occurrence and died before recording it.
+	claimed, err := recurring.PickupDue(ctx, 5, now, time.Minute)
+	require.NoError(t, err)
+	require.Len(t, claimed, 1)
+
+	issues := store.NewProjectIssueStore(db)
+	worker := NewRecurringIssueWorker(recurring, &RecurringIssueGenerator{
+		Issues:    issues,
+		Flows:     store.NewProjectFlowStore(db),
+		Templates: store.NewIssueTemplateStore(db),
+		Labels:    store.NewLabelStore(db),
+	}, RecurringIssueWorkerConfig{MaxPerPoll: 5, RunTimeout: time.Minute})
+	worker.Now = func() time.Time { return now }
+
+	processed, err := worker.RunOnce(ctx)
+	require.NoError(t, err)
+	require.Zero(t, processed, "a live claim is left alone")
+
+	now = now.Add(2 * time.Minute)
+	processed, err = worker.RunOnce(ctx)
+	require.NoError(t, err)
+	require.Equal(t, 1, processed)
+
+	def, err = recurring.GetByID(ctx, def.ID)
+	require.NoError(t, err)
+	require.Equal(t, store.RecurringIssueRunCreated, *def.LastRunStatus)
+	require.Equal(t, time.Date(2026, 10, 20, 13, 0, 0, 0, time.UTC), def.NextRunAt.UTC())
+	issue, err := issues.GetIssueByID(ctx, *def.LastIssueID)
+	require.NoError(t, err)
+	require.Equal(t, "Daily check — 2026-10-19", issue.Title)
+}
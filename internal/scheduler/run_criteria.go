@@ -0,0 +1,215 @@
+package scheduler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/samhotchkiss/otter-camp/internal/memory"
+	"github.com/samhotchkiss/otter-camp/internal/store"
+)
+
+// AgentJobRunClassifier answers a job's follow-up success question about an
+// agent's reply, e.g. "Did the agent confirm the backup finished?".
+type AgentJobRunClassifier interface {
+	Classify(ctx context.Context, input AgentJobRunClassificationInput) (AgentJobRunClassification, error)
+}
+
+type AgentJobRunClassificationInput struct {
+	OrgID    string
+	JobName  string
+	Prompt   string
+	Output   string
+	Question string
+}
+
+type AgentJobRunClassification struct {
+	Success bool
+	Reason  string
+}
+
+// RunCriteria are the success checks applied to an agent's reply. All
+// configured checks must pass; a job without criteria succeeds on any reply.
+type RunCriteria struct {
+	Pattern            string
+	JSONField          string
+	ClassifierQuestion string
+}
+
+func RunCriteriaForJob(job store.AgentJob) RunCriteria {
+	criteria := RunCriteria{}
+	if job.SuccessPattern != nil {
+		criteria.Pattern = strings.TrimSpace(*job.SuccessPattern)
+	}
+	if job.SuccessJSONField != nil {
+		criteria.JSONField = strings.TrimSpace(*job.SuccessJSONField)
+	}
+	if job.SuccessClassifierPrompt != nil {
+		criteria.ClassifierQuestion = strings.TrimSpace(*job.SuccessClassifierPrompt)
+	}
+	return criteria
+}
+
+func (c RunCriteria) IsZero() bool {
+	return c.Pattern == "" && c.JSONField == "" && c.ClassifierQuestion == ""
+}
+
+// EvaluateRunOutput returns nil when output satisfies every configured
+// criterion, and otherwise an error describing the first one that failed.
+func EvaluateRunOutput(
+	ctx context.Context,
+	criteria RunCriteria,
+	classifier AgentJobRunClassifier,
+	input AgentJobRunClassificationInput,
+) error {
+	output := strings.TrimSpace(input.Output)
+	if output == "" {
+		return errors.New("agent reply was empty")
+	}
+
+	if criteria.Pattern != "" {
+		pattern, err := regexp.Compile(criteria.Pattern)
+		if err != nil {
+			return fmt.Errorf("invalid success pattern: %w", err)
+		}
+		if !pattern.MatchString(output) {
+			return fmt.Errorf("agent reply did not match success pattern %q", criteria.Pattern)
+		}
+	}
+
+	if criteria.JSONField != "" {
+		if _, ok := lookupRunOutputJSONField(output, criteria.JSONField); !ok {
+			return fmt.Errorf("agent reply is missing required JSON field %q", criteria.JSONField)
+		}
+	}
+
+	if criteria.ClassifierQuestion != "" {
+		if classifier == nil {
+			return errors.New("success classifier is not configured")
+		}
+		input.Output = output
+		input.Question = criteria.ClassifierQuestion
+		verdict, err := classifier.Classify(ctx, input)
+		if err != nil {
+			return fmt.Errorf("success classifier failed: %w", err)
+		}
+		if !verdict.Success {
+			reason := strings.TrimSpace(verdict.Reason)
+			if reason == "" {
+				reason = "no reason given"
+			}
+			return fmt.Errorf("success classifier rejected reply: %s", reason)
+		}
+	}
+	return nil
+}
+
+// lookupRunOutputJSONField finds the first JSON object in output (bare or in a
+// fenced block) and resolves a dotted path in it. Null values and empty
+// strings count as missing.
+func lookupRunOutputJSONField(output, path string) (any, bool) {
+	raw, ok := extractRunOutputJSONObject(output)
+	if !ok {
+		return nil, false
+	}
+	var current any
+	if err := json.Unmarshal([]byte(raw), &current); err != nil {
+		return nil, false
+	}
+	for _, segment := range strings.Split(path, ".") {
+		object, isObject := current.(map[string]any)
+		if !isObject {
+			return nil, false
+		}
+		value, exists := object[strings.TrimSpace(segment)]
+		if !exists {
+			return nil, false
+		}
+		current = value
+	}
+	switch value := current.(type) {
+	case nil:
+		return nil, false
+	case string:
+		if strings.TrimSpace(value) == "" {
+			return nil, false
+		}
+	}
+	return current, true
+}
+
+func extractRunOutputJSONObject(output string) (string, bool) {
+	trimmed := strings.TrimSpace(output)
+	if json.Valid([]byte(trimmed)) && strings.HasPrefix(trimmed, "{") {
+		return trimmed, true
+	}
+	start := strings.Index(trimmed, "{")
+	end := strings.LastIndex(trimmed, "}")
+	if start < 0 || end <= start {
+		return "", false
+	}
+	candidate := trimmed[start : end+1]
+	if !json.Valid([]byte(candidate)) {
+		return "", false
+	}
+	return candidate, true
+}
+
+// OpenClawRunClassifier asks an LLM through the OpenClaw gateway whether an
+// agent's reply answers the job's success question with a yes.
+type OpenClawRunClassifier struct {
+	Caller *memory.OpenClawGatewayCaller
+}
+
+func (c *OpenClawRunClassifier) Classify(
+	ctx context.Context,
+	input AgentJobRunClassificationInput,
+) (AgentJobRunClassification, error) {
+	if c == nil || c.Caller == nil {
+		return AgentJobRunClassification{}, fmt.Errorf("openclaw run classifier is not configured")
+	}
+	orgID := strings.TrimSpace(input.OrgID)
+	if orgID == "" {
+		return AgentJobRunClassification{}, fmt.Errorf("org_id is required")
+	}
+
+	callResult, err := c.Caller.Call(ctx, orgID, buildRunClassifierPrompt(input))
+	if err != nil {
+		return AgentJobRunClassification{}, err
+	}
+	raw, ok := extractRunOutputJSONObject(callResult.Text)
+	if !ok {
+		return AgentJobRunClassification{}, fmt.Errorf("decode run classification payload: no json object found")
+	}
+	var parsed struct {
+		Success *bool  `json:"success"`
+		Reason  string `json:"reason"`
+	}
+	if err := json.Unmarshal([]byte(raw), &parsed); err != nil {
+		return AgentJobRunClassification{}, fmt.Errorf("decode run classification json: %w", err)
+	}
+	if parsed.Success == nil {
+		return AgentJobRunClassification{}, fmt.Errorf("decode run classification json: success is required")
+	}
+	return AgentJobRunClassification{
+		Success: *parsed.Success,
+		Reason:  strings.TrimSpace(parsed.Reason),
+	}, nil
+}
+
+func buildRunClassifierPrompt(input AgentJobRunClassificationInput) string {
+	var b strings.Builder
+	b.WriteString("You are checking whether a scheduled agent job succeeded.\n")
+	b.WriteString("Answer the question about the agent's reply with JSON only: ")
+	b.WriteString(`{"success": true|false, "reason": "<one sentence>"}` + "\n\n")
+	if name := strings.TrimSpace(input.JobName); name != "" {
+		b.WriteString("Job: " + name + "\n")
+	}
+	b.WriteString("Question: " + strings.TrimSpace(input.Question) + "\n\n")
+	b.WriteString("Prompt sent to the agent:\n" + strings.TrimSpace(input.Prompt) + "\n\n")
+	b.WriteString("Agent reply:\n" + strings.TrimSpace(input.Output) + "\n")
+	return b.String()
+}
@@ -0,0 +1,78 @@
+package scheduler
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestEvaluateRunOutputPattern(t *testing.T) {
+	criteria := RunCriteria{Pattern: `(?i)^done\b`}
+
+	require.NoError(t, EvaluateRunOutput(context.Background(), criteria, nil, AgentJobRunClassificationInput{Output: "Done: 3 files"}))
+
+	err := EvaluateRunOutput(context.Background(), criteria, nil, AgentJobRunClassificationInput{Output: "not done"})
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "success pattern")
+
+	err = EvaluateRunOutput(context.Background(), RunCriteria{}, nil, AgentJobRunClassificationInput{Output: "  "})
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "empty")
+}
+
+func TestEvaluateRunOutputJSONField(t *testing.T) {
+	criteria := RunCriteria{JSONField: "result.status"}
+
+	cases := []struct {
+		name   string
+		output string
+		ok     bool
+	}{
+		{name: "bare object", output: `{"result":{"status":"ok"}}`, ok: true},
+		{name: "fenced object", output: "Here you go:\n```json\n{\"result\": {\"status\": 200}}\n```", ok: true},
+		{name: "false is present", output: `{"result":{"status":false}}`, ok: true},
+		{name: "null value", output: `{"result":{"status":null}}`, ok: false},
+		{name: "empty string", output: `{"result":{"status":"  "}}`, ok: false},
+		{name: "missing field", output: `{"result":{}}`, ok: false},
+		{name: "not an object", output: `{"result":"ok"}`, ok: false},
+		{name: "no json", output: "all good", ok: false},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := EvaluateRunOutput(context.Background(), criteria, nil, AgentJobRunClassificationInput{Output: tc.output})
+			if tc.ok {
+				require.NoError(t, err)
+				return
+			}
+			require.Error(t, err)
+			require.Contains(t, err.Error(), "result.status")
+		})
+	}
+}
+
+func TestEvaluateRunOutputClassifier(t *testing.T) {
+	criteria := RunCriteria{ClassifierQuestion: "Did the deploy succeed?"}
+	input := AgentJobRunClassificationInput{OrgID: "org-1", JobName: "Deploy", Prompt: "deploy", Output: "deployed v2"}
+
+	err := EvaluateRunOutput(context.Background(), criteria, nil, input)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "not configured")
+
+	approve := &fakeRunClassifier{verdict: AgentJobRunClassification{Success: true}}
+	require.NoError(t, EvaluateRunOutput(context.Background(), criteria, approve, input))
+	require.Len(t, approve.inputs, 1)
+	require.Equal(t, "Did the deploy succeed?", approve.inputs[0].Question)
+	require.Equal(t, "deployed v2", approve.inputs[0].Output)
+
+	reject := &fakeRunClassifier{verdict: AgentJobRunClassification{Success: false, Reason: "rollback mentioned"}}
+	err = EvaluateRunOutput(context.Background(), criteria, reject, input)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "rollback mentioned")
+
+	// Cheaper checks run first, so a pattern miss never reaches the classifier.
+	skipped := &fakeRunClassifier{verdict: AgentJobRunClassification{Success: true}}
+	err = EvaluateRunOutput(context.Background(), RunCriteria{Pattern: "v3", ClassifierQuestion: "ok?"}, skipped, input)
+	require.Error(t, err)
+	require.Empty(t, skipped.inputs)
+}
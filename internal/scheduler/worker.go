@@ -7,8 +7,10 @@ import (
 	"strings"
 	"time"
 
+	"github.com/samhotchkiss/otter-camp/internal/metrics"
 	"github.com/samhotchkiss/otter-camp/internal/middleware"
 	"github.com/samhotchkiss/otter-camp/internal/store"
+	"github.com/samhotchkiss/otter-camp/internal/tracing"
 )
 
 const (
@@ -17,6 +19,7 @@ const (
 	defaultJobWorkerRunTimeout    = 5 * time.Minute
 	defaultJobWorkerMaxRunHistory = 100
 	defaultJobRetryDelay          = 1 * time.Minute
+	defaultJobMaxCatchUpRuns      = 100
 )
 
 type AgentJobWorkerConfig struct {
@@ -25,6 +28,12 @@ type AgentJobWorkerConfig struct {
 	RunTimeout    time.Duration
 	MaxRunHistory int
 	WorkspaceID   string
+	// MaxCatchUpRuns caps how many missed occurrences a run_all job replays;
+	// larger backlogs fall back to a single catch-up run.
+	MaxCatchUpRuns int
+	// Classifier answers jobs' success_classifier_prompt questions. Jobs that
+	// set one fail while no classifier is configured.
+	Classifier AgentJobRunClassifier
 }
 
 type AgentJobWorker struct {
@@ -32,6 +41,25 @@ type AgentJobWorker struct {
 	Config AgentJobWorkerConfig
 	Now    func() time.Time
 	Logf   func(string, ...any)
+	// Int63n feeds scheduling jitter. Defaults to math/rand.
+	Int63n func(int64) int64
+}
+
+type jobAdmissionAction int
+
+const (
+	jobAdmissionRun jobAdmissionAction = iota
+	jobAdmissionSkip
+	jobAdmissionDefer
+)
+
+// jobAdmission is the outcome of applying a job's blackout, catch-up,
+// overlap, and concurrency policies to one due occurrence.
+type jobAdmission struct {
+	action    jobAdmissionAction
+	reason    string
+	nextRunAt *time.Time
+	deferTo   time.Time
 }
 
 func NewAgentJobWorker(jobStore *store.AgentJobStore, cfg AgentJobWorkerConfig) *AgentJobWorker {
@@ -47,6 +75,9 @@ func NewAgentJobWorker(jobStore *store.AgentJobStore, cfg AgentJobWorkerConfig)
 	if cfg.MaxRunHistory <= 0 {
 		cfg.MaxRunHistory = defaultJobWorkerMaxRunHistory
 	}
+	if cfg.MaxCatchUpRuns <= 0 {
+		cfg.MaxCatchUpRuns = defaultJobMaxCatchUpRuns
+	}
 
 	return &AgentJobWorker{
 		Store:  jobStore,
@@ -59,7 +90,12 @@ func NewAgentJobWorker(jobStore *store.AgentJobStore, cfg AgentJobWorkerConfig)
 
 func (w *AgentJobWorker) Start(ctx context.Context) {
 	for {
-		if _, err := w.RunOnce(ctx); err != nil && w.Logf != nil {
+		started := time.Now()
+		runCtx, span := tracing.StartWorkerRun(ctx, "agent_jobs")
+		processed, err := w.RunOnce(runCtx)
+		tracing.EndWorkerRun(span, processed > 0, err)
+		metrics.ObserveWorkerRun("agent_jobs", time.Since(started), err)
+		if err != nil && w.Logf != nil {
 			w.Logf("agent job worker run failed: %v", err)
 		}
 		if err := sleepWithContext(ctx, w.Config.PollInterval); err != nil {
@@ -75,6 +111,9 @@ func (w *AgentJobWorker) RunOnce(ctx context.Context) (int, error) {
 
 	ctx = w.workspaceContext(ctx)
 	now := w.now()
+	if err := w.reviewRunReplies(ctx); err != nil {
+		return 0, err
+	}
 	if _, err := w.Store.CleanupStaleRuns(ctx, w.Config.RunTimeout, now); err != nil {
 		return 0, err
 	}
@@ -83,20 +122,61 @@ func (w *AgentJobWorker) RunOnce(ctx context.Context) (int, error) {
 	if err != nil {
 		return 0, err
 	}
+	if len(dueJobs) == 0 {
+		return 0, nil
+	}
+
+	blackouts, err := w.Store.ListBlackoutWindows(ctx)
+	if err != nil {
+		for _, job := range dueJobs {
+			_ = w.Store.Reschedule(ctx, job.ID, job.DueAt)
+		}
+		return 0, err
+	}
 
 	for _, job := range dueJobs {
-		if execErr := w.executeJob(ctx, job); execErr != nil && w.Logf != nil {
+		if execErr := w.executeJob(ctx, job, blackouts); execErr != nil && w.Logf != nil {
 			w.Logf("agent job execution failed for job %s: %v", job.ID, execErr)
 		}
 	}
 	return len(dueJobs), nil
 }
 
-func (w *AgentJobWorker) executeJob(ctx context.Context, job store.AgentJob) error {
+func (w *AgentJobWorker) executeJob(
+	ctx context.Context,
+	job store.AgentJob,
+	blackouts []store.AgentJobBlackoutWindow,
+) error {
 	now := w.now()
 	runCtx, cancel := context.WithTimeout(ctx, w.Config.RunTimeout)
 	defer cancel()
 
+	scheduleSpec, scheduleErr := NormalizeScheduleSpec(
+		job.ScheduleKind,
+		job.CronExpr,
+		job.IntervalMS,
+		job.RunAt,
+		job.Timezone,
+	)
+	policy := w.runPolicy(job, blackouts)
+
+	var catchUpNextRunAt *time.Time
+	if scheduleErr == nil {
+		admission, err := w.admitJobRun(runCtx, job, scheduleSpec, policy, now)
+		if err != nil {
+			_ = w.Store.Reschedule(runCtx, job.ID, job.DueAt)
+			return err
+		}
+		switch admission.action {
+		case jobAdmissionDefer:
+			deferTo := admission.deferTo
+			return w.Store.Reschedule(runCtx, job.ID, &deferTo)
+		case jobAdmissionSkip:
+			return w.recordSkippedRun(runCtx, job, admission.reason, admission.nextRunAt)
+		}
+		catchUpNextRunAt = admission.nextRunAt
+	}
+
 	roomID := ""
 	if job.RoomID != nil {
 		roomID = *job.RoomID
@@ -118,15 +198,8 @@ func (w *AgentJobWorker) executeJob(ctx context.Context, job store.AgentJob) err
 		return err
 	}
 
-	scheduleSpec, scheduleErr := NormalizeScheduleSpec(
-		job.ScheduleKind,
-		job.CronExpr,
-		job.IntervalMS,
-		job.RunAt,
-		job.Timezone,
-	)
 	if scheduleErr != nil {
-		return w.completeFailure(runCtx, job, run, scheduleErr)
+		return w.completeFailure(runCtx, job, blackouts, run, scheduleErr)
 	}
 
 	messageID, err := w.Store.CreateJobMessage(runCtx, store.CreateAgentJobMessageInput{
@@ -138,13 +211,31 @@ func (w *AgentJobWorker) executeJob(ctx context.Context, job store.AgentJob) err
 		CreatedAt:   now,
 	})
 	if err != nil {
-		return w.completeFailure(runCtx, job, run, err)
+		return w.completeFailure(runCtx, job, blackouts, run, err)
 	}
 
-	nextRunAt, err := ComputeNextRun(scheduleSpec, now, &now)
-	if err != nil {
-		return w.completeFailure(runCtx, job, run, err)
+	nextRunAt := catchUpNextRunAt
+	if nextRunAt == nil {
+		nextRunAt, err = ComputeNextRunWithPolicy(scheduleSpec, policy, now, &now)
+		if err != nil {
+			return w.completeFailure(runCtx, job, blackouts, run, err)
+		}
 	}
+
+	if jobAwaitsReply(job) {
+		replyTimeout := w.Config.RunTimeout
+		if job.ReplyTimeoutMS != nil && *job.ReplyTimeoutMS > 0 {
+			replyTimeout = time.Duration(*job.ReplyTimeoutMS) * time.Millisecond
+		}
+		return w.Store.MarkRunAwaitingReply(runCtx, store.AwaitAgentJobReplyInput{
+			JobID:           job.ID,
+			RunID:           run.ID,
+			MessageID:       messageID,
+			ReplyDeadlineAt: now.Add(replyTimeout),
+			NextRunAt:       nextRunAt,
+		})
+	}
+
 	completeJob := job.ScheduleKind == store.AgentJobScheduleOnce && nextRunAt == nil
 	_, err = w.Store.CompleteRun(runCtx, store.CompleteAgentJobRunInput{
 		JobID:       job.ID,
@@ -162,9 +253,82 @@ func (w *AgentJobWorker) executeJob(ctx context.Context, job store.AgentJob) err
 	return nil
 }
 
+// jobAwaitsReply reports whether a run stays running after its prompt is
+// delivered. Message jobs always wait for the agent; system events only wait
+// when they have success criteria to check.
+func jobAwaitsReply(job store.AgentJob) bool {
+	return job.PayloadKind == store.AgentJobPayloadMessage || !RunCriteriaForJob(job).IsZero()
+}
+
+// reviewRunReplies completes runs whose agent has replied, judging each reply
+// against the job's success criteria. The job's next run was already set when
+// the prompt went out, so it is left untouched.
+func (w *AgentJobWorker) reviewRunReplies(ctx context.Context) error {
+	replies, err := w.Store.ListRunReplies(ctx, w.Config.MaxPerPoll)
+	if err != nil {
+		return err
+	}
+	for _, reply := range replies {
+		if reviewErr := w.reviewRunReply(ctx, reply); reviewErr != nil && w.Logf != nil {
+			w.Logf("agent job reply review failed for run %s: %v", reply.Run.ID, reviewErr)
+		}
+	}
+	return nil
+}
+
+func (w *AgentJobWorker) reviewRunReply(ctx context.Context, reply store.AgentJobRunReply) error {
+	runCtx, cancel := context.WithTimeout(ctx, w.Config.RunTimeout)
+	defer cancel()
+
+	job, err := w.Store.GetByID(runCtx, reply.Run.JobID)
+	if err != nil {
+		return err
+	}
+
+	runStatus := store.AgentJobRunStatusSuccess
+	var runError *string
+	evalErr := EvaluateRunOutput(runCtx, RunCriteriaForJob(*job), w.Config.Classifier, AgentJobRunClassificationInput{
+		OrgID:   job.OrgID,
+		JobName: job.Name,
+		Prompt:  reply.Run.PayloadText,
+		Output:  reply.Reply.Body,
+	})
+	if evalErr != nil {
+		runStatus = store.AgentJobRunStatusError
+		message := evalErr.Error()
+		runError = &message
+	}
+
+	output := reply.Reply.Body
+	replyMessageID := reply.Reply.ID
+	completeJob := job.ScheduleKind == store.AgentJobScheduleOnce &&
+		runStatus == store.AgentJobRunStatusSuccess &&
+		job.NextRunAt == nil
+	_, err = w.Store.CompleteRun(runCtx, store.CompleteAgentJobRunInput{
+		JobID:             job.ID,
+		RunID:             reply.Run.ID,
+		RunStatus:         runStatus,
+		CompletedAt:       w.now(),
+		RunError:          runError,
+		Output:            &output,
+		ReplyMessageID:    &replyMessageID,
+		PreserveNextRunAt: true,
+		CompleteJob:       completeJob,
+	})
+	if err != nil {
+		if errors.Is(err, store.ErrConflict) {
+			return nil
+		}
+		return err
+	}
+	_, _ = w.Store.PruneRunHistory(runCtx, job.ID, w.Config.MaxRunHistory)
+	return nil
+}
+
 func (w *AgentJobWorker) completeFailure(
 	ctx context.Context,
 	job store.AgentJob,
+	blackouts []store.AgentJobBlackoutWindow,
 	run *store.AgentJobRun,
 	failure error,
 ) error {
@@ -177,7 +341,7 @@ func (w *AgentJobWorker) completeFailure(
 		runStatus = store.AgentJobRunStatusTimeout
 	}
 
-	nextRunAt := computeFailureNextRun(job, now)
+	nextRunAt := computeFailureNextRun(job, w.runPolicy(job, blackouts), now)
 	runError := failure.Error()
 	_, completeErr := w.Store.CompleteRun(ctx, store.CompleteAgentJobRunInput{
 		JobID:       job.ID,
@@ -194,7 +358,164 @@ func (w *AgentJobWorker) completeFailure(
 	return failure
 }
 
-func computeFailureNextRun(job store.AgentJob, now time.Time) *time.Time {
+// admitJobRun decides whether a due occurrence runs now. Policies apply in a
+// fixed order: blackout windows, catch-up after missed occurrences, overlap
+// with the job's own in-flight runs, then the per-agent concurrency limit.
+func (w *AgentJobWorker) admitJobRun(
+	ctx context.Context,
+	job store.AgentJob,
+	spec ScheduleSpec,
+	policy RunPolicy,
+	now time.Time,
+) (jobAdmission, error) {
+	dueAt := now
+	if job.DueAt != nil {
+		dueAt = job.DueAt.UTC()
+	}
+
+	location, err := scheduleLocation(spec)
+	if err != nil {
+		return jobAdmission{}, err
+	}
+	if blackoutEnd, blocked := BlackoutEnd(now, location, policy.Blackouts); blocked {
+		if spec.Kind == ScheduleKindOnce {
+			return jobAdmission{action: jobAdmissionDefer, deferTo: blackoutEnd}, nil
+		}
+		nextRunAt, err := ComputeNextRunWithPolicy(spec, policy, now, &now)
+		if err != nil {
+			return jobAdmission{}, err
+		}
+		return jobAdmission{
+			action:    jobAdmissionSkip,
+			reason:    "run suppressed by blackout window",
+			nextRunAt: nextRunAt,
+		}, nil
+	}
+
+	admission := jobAdmission{action: jobAdmissionRun}
+	missed, err := MissedRuns(spec, dueAt, now, w.Config.MaxCatchUpRuns)
+	if err != nil {
+		return jobAdmission{}, err
+	}
+	if missed > 0 {
+		switch job.CatchUpPolicy {
+		case store.AgentJobCatchUpSkip:
+			nextRunAt, err := ComputeNextRunWithPolicy(spec, policy, now, &now)
+			if err != nil {
+				return jobAdmission{}, err
+			}
+			return jobAdmission{
+				action:    jobAdmissionSkip,
+				reason:    fmt.Sprintf("skipped after %d missed run(s); catch-up policy is skip", missed),
+				nextRunAt: nextRunAt,
+			}, nil
+		case store.AgentJobCatchUpRunAll:
+			if missed < w.Config.MaxCatchUpRuns {
+				replayPolicy := RunPolicy{Blackouts: policy.Blackouts}
+				nextRunAt, err := ComputeNextRunWithPolicy(spec, replayPolicy, dueAt, &dueAt)
+				if err != nil {
+					return jobAdmission{}, err
+				}
+				admission.nextRunAt = nextRunAt
+			} else if w.Logf != nil {
+				w.Logf("agent job %s missed %d+ runs; replaying only the latest", job.ID, missed)
+			}
+		}
+	}
+
+	running, err := w.Store.CountRunningRuns(ctx, job.ID)
+	if err != nil {
+		return jobAdmission{}, err
+	}
+	if running > 0 {
+		switch job.OverlapPolicy {
+		case store.AgentJobOverlapQueue:
+			return jobAdmission{action: jobAdmissionDefer, deferTo: w.retryAdmissionAt(now)}, nil
+		case store.AgentJobOverlapReplace:
+			if _, err := w.Store.SupersedeRunningRuns(ctx, job.ID, now, "superseded by a newer run"); err != nil {
+				return jobAdmission{}, err
+			}
+		default:
+			nextRunAt := admission.nextRunAt
+			if nextRunAt == nil {
+				nextRunAt, err = ComputeNextRunWithPolicy(spec, policy, now, &now)
+				if err != nil {
+					return jobAdmission{}, err
+				}
+			}
+			return jobAdmission{
+				action:    jobAdmissionSkip,
+				reason:    "previous run still running",
+				nextRunAt: nextRunAt,
+			}, nil
+		}
+	}
+
+	if job.MaxAgentConcurrency != nil {
+		agentRunning, err := w.Store.CountRunningRunsForAgent(ctx, job.AgentID)
+		if err != nil {
+			return jobAdmission{}, err
+		}
+		if agentRunning >= *job.MaxAgentConcurrency {
+			return jobAdmission{action: jobAdmissionDefer, deferTo: w.retryAdmissionAt(now)}, nil
+		}
+	}
+
+	return admission, nil
+}
+
+// retryAdmissionAt is when a job held back by a busy run or agent is looked
+// at again. Deferring to its (past) due time would make it due on every
+// poll, ahead of jobs that can actually run.
+func (w *AgentJobWorker) retryAdmissionAt(now time.Time) time.Time {
+	interval := w.Config.PollInterval
+	if interval <= 0 {
+		interval = defaultJobWorkerPollInterval
+	}
+	return now.Add(interval)
+}
+
+func (w *AgentJobWorker) recordSkippedRun(
+	ctx context.Context,
+	job store.AgentJob,
+	reason string,
+	nextRunAt *time.Time,
+) error {
+	now := w.now()
+	run, err := w.Store.StartRun(ctx, store.StartAgentJobRunInput{
+		JobID:       job.ID,
+		PayloadText: job.PayloadText,
+		StartedAt:   now,
+	})
+	if err != nil {
+		_ = w.Store.Reschedule(ctx, job.ID, nextRunAt)
+		return err
+	}
+	_, err = w.Store.CompleteRun(ctx, store.CompleteAgentJobRunInput{
+		JobID:       job.ID,
+		RunID:       run.ID,
+		RunStatus:   store.AgentJobRunStatusSkipped,
+		CompletedAt: now,
+		RunError:    &reason,
+		NextRunAt:   nextRunAt,
+	})
+	if err != nil {
+		return err
+	}
+	_, _ = w.Store.PruneRunHistory(ctx, job.ID, w.Config.MaxRunHistory)
+	return nil
+}
+
+func (w *AgentJobWorker) runPolicy(job store.AgentJob, blackouts []store.AgentJobBlackoutWindow) RunPolicy {
+	policy := RunPolicyForJob(job, blackouts)
+	policy.Int63n = w.Int63n
+	return policy
+}
+
+// computeFailureNextRun schedules the run after a failure. Jobs whose
+// schedule cannot be read retry after defaultJobRetryDelay, still stepping
+// over blackout windows so the retry is not simply deferred again.
+func computeFailureNextRun(job store.AgentJob, policy RunPolicy, now time.Time) *time.Time {
 	spec, err := NormalizeScheduleSpec(
 		job.ScheduleKind,
 		job.CronExpr,
@@ -203,14 +524,27 @@ func computeFailureNextRun(job store.AgentJob, now time.Time) *time.Time {
 		job.Timezone,
 	)
 	if err == nil {
-		if nextRunAt, nextErr := ComputeNextRun(spec, now, &now); nextErr == nil {
+		if nextRunAt, nextErr := ComputeNextRunWithPolicy(spec, policy, now, &now); nextErr == nil {
 			return nextRunAt
 		}
 	}
 	if job.ScheduleKind == store.AgentJobScheduleOnce {
 		return nil
 	}
+	location := time.UTC
+	if job.Timezone != "" {
+		if loaded, err := time.LoadLocation(job.Timezone); err == nil {
+			location = loaded
+		}
+	}
 	retryAt := now.Add(defaultJobRetryDelay)
+	for i := 0; i < maxBlackoutSkips; i++ {
+		end, blocked := BlackoutEnd(retryAt, location, policy.Blackouts)
+		if !blocked {
+			break
+		}
+		retryAt = end
+	}
 	return &retryAt
 }
 
@@ -234,10 +568,16 @@ func sleepWithContext(ctx context.Context, delay time.Duration) error {
 }
 
 func (w *AgentJobWorker) workspaceContext(ctx context.Context) context.Context {
+	return withWorkerWorkspace(ctx, w.Config.WorkspaceID)
+}
+
+// withWorkerWorkspace scopes a worker run to its configured workspace unless
+// the caller already set one.
+func withWorkerWorkspace(ctx context.Context, configured string) context.Context {
 	if strings.TrimSpace(middleware.WorkspaceFromContext(ctx)) != "" {
 		return ctx
 	}
-	workspaceID := strings.TrimSpace(w.Config.WorkspaceID)
+	workspaceID := strings.TrimSpace(configured)
 	if workspaceID == "" {
 		return ctx
 	}
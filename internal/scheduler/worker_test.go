@@ -147,6 +147,27 @@ func TestJobSchedulerWorkerRunOnceExecutesOnceJobAndCompletes(t *testing.T) {
 	require.NoError(t, err)
 	require.Equal(t, 1, processed)
 
+	runs, err := jobStore.ListRuns(ctx, job.ID, 10)
+	require.NoError(t, err)
+	require.Len(t, runs, 1)
+	require.Equal(t, store.AgentJobRunStatusRunning, runs[0].Status)
+	require.NotNil(t, runs[0].MessageID)
+	require.NotNil(t, runs[0].ReplyDeadlineAt)
+	require.Equal(t, now.Add(time.Minute), runs[0].ReplyDeadlineAt.UTC())
+
+	_, err = jobStore.CreateJobReply(ctx, store.CreateAgentJobReplyInput{
+		JobID:     job.ID,
+		RunID:     runs[0].ID,
+		Body:      "reminded",
+		CreatedAt: now.Add(5 * time.Second),
+	})
+	require.NoError(t, err)
+
+	now = now.Add(10 * time.Second)
+	processed, err = worker.RunOnce(ctx)
+	require.NoError(t, err)
+	require.Equal(t, 0, processed)
+
 	updatedJob, err := jobStore.GetByID(ctx, job.ID)
 	require.NoError(t, err)
 	require.Equal(t, store.AgentJobStatusCompleted, updatedJob.Status)
@@ -154,11 +175,13 @@ func TestJobSchedulerWorkerRunOnceExecutesOnceJobAndCompletes(t *testing.T) {
 	require.NotNil(t, updatedJob.LastRunStatus)
 	require.Equal(t, store.AgentJobRunStatusSuccess, *updatedJob.LastRunStatus)
 
-	runs, err := jobStore.ListRuns(ctx, job.ID, 10)
+	runs, err = jobStore.ListRuns(ctx, job.ID, 10)
 	require.NoError(t, err)
 	require.Len(t, runs, 1)
 	require.Equal(t, store.AgentJobRunStatusSuccess, runs[0].Status)
-	require.NotNil(t, runs[0].MessageID)
+	require.NotNil(t, runs[0].Output)
+	require.Equal(t, "reminded", *runs[0].Output)
+	require.NotNil(t, runs[0].ReplyMessageID)
 
 	conn, err := store.WithWorkspace(ctx, db)
 	require.NoError(t, err)
@@ -171,7 +194,151 @@ func TestJobSchedulerWorkerRunOnceExecutesOnceJobAndCompletes(t *testing.T) {
 		*updatedJob.RoomID,
 	).Scan(&messageCount)
 	require.NoError(t, err)
-	require.Equal(t, 1, messageCount)
+	require.Equal(t, 2, messageCount)
+}
+
+type fakeRunClassifier struct {
+	verdict AgentJobRunClassification
+	inputs  []AgentJobRunClassificationInput
+}
+
+func (f *fakeRunClassifier) Classify(
+	_ context.Context,
+	input AgentJobRunClassificationInput,
+) (AgentJobRunClassification, error) {
+	f.inputs = append(f.inputs, input)
+	return f.verdict, nil
+}
+
+func TestJobSchedulerWorkerFailsRunWhenReplyMissesCriteria(t *testing.T) {
+	db := setupSchedulerTestDB(t)
+	orgID := schedulerCreateOrg(t, db, "scheduler-criteria")
+	agentID := schedulerCreateAgent(t, db, orgID, "scheduler-criteria-agent")
+	ctx := schedulerCtxWithWorkspace(orgID)
+	jobStore := store.NewAgentJobStore(db)
+
+	now := time.Date(2026, 2, 12, 20, 15, 0, 0, time.UTC)
+	nextRunAt := now.Add(-10 * time.Second)
+	maxFailures := 1
+	job, err := jobStore.Create(ctx, store.CreateAgentJobInput{
+		AgentID:                 agentID,
+		Name:                    "Backup Check",
+		ScheduleKind:            store.AgentJobScheduleInterval,
+		IntervalMS:              int64Ptr(3600000),
+		Timezone:                strPtr("UTC"),
+		PayloadKind:             store.AgentJobPayloadMessage,
+		PayloadText:             "run the backup and report",
+		NextRunAt:               &nextRunAt,
+		MaxFailures:             &maxFailures,
+		SuccessPattern:          strPtr(`(?i)backup (ok|complete)`),
+		SuccessClassifierPrompt: strPtr("Did the backup finish?"),
+	})
+	require.NoError(t, err)
+
+	classifier := &fakeRunClassifier{verdict: AgentJobRunClassification{Success: false, Reason: "the agent reported disk full"}}
+	worker := NewAgentJobWorker(jobStore, AgentJobWorkerConfig{
+		PollInterval:  10 * time.Millisecond,
+		MaxPerPoll:    5,
+		RunTimeout:    time.Minute,
+		MaxRunHistory: 100,
+		Classifier:    classifier,
+	})
+	worker.Now = func() time.Time { return now }
+
+	processed, err := worker.RunOnce(ctx)
+	require.NoError(t, err)
+	require.Equal(t, 1, processed)
+
+	awaiting, err := jobStore.GetByID(ctx, job.ID)
+	require.NoError(t, err)
+	require.NotNil(t, awaiting.NextRunAt)
+	require.Equal(t, now.Add(time.Hour), awaiting.NextRunAt.UTC())
+
+	runs, err := jobStore.ListRuns(ctx, job.ID, 10)
+	require.NoError(t, err)
+	require.Len(t, runs, 1)
+	_, err = jobStore.CreateJobReply(ctx, store.CreateAgentJobReplyInput{
+		JobID:     job.ID,
+		RunID:     runs[0].ID,
+		Body:      "Backup complete, but disk is full",
+		CreatedAt: now.Add(time.Second),
+	})
+	require.NoError(t, err)
+
+	now = now.Add(5 * time.Second)
+	_, err = worker.RunOnce(ctx)
+	require.NoError(t, err)
+	require.Len(t, classifier.inputs, 1)
+	require.Equal(t, "Did the backup finish?", classifier.inputs[0].Question)
+	require.Equal(t, "run the backup and report", classifier.inputs[0].Prompt)
+
+	updatedJob, err := jobStore.GetByID(ctx, job.ID)
+	require.NoError(t, err)
+	require.Equal(t, store.AgentJobStatusPaused, updatedJob.Status)
+	require.Equal(t, 1, updatedJob.ConsecutiveFailures)
+	require.NotNil(t, updatedJob.LastRunError)
+	require.Contains(t, *updatedJob.LastRunError, "disk full")
+	require.NotNil(t, updatedJob.NextRunAt)
+	require.Equal(t, now.Add(-5*time.Second).Add(time.Hour), updatedJob.NextRunAt.UTC())
+
+	runs, err = jobStore.ListRuns(ctx, job.ID, 10)
+	require.NoError(t, err)
+	require.Len(t, runs, 1)
+	require.Equal(t, store.AgentJobRunStatusError, runs[0].Status)
+	require.NotNil(t, runs[0].Output)
+	require.Equal(t, "Backup complete, but disk is full", *runs[0].Output)
+}
+
+func TestJobSchedulerWorkerTimesOutRunWithoutReply(t *testing.T) {
+	db := setupSchedulerTestDB(t)
+	orgID := schedulerCreateOrg(t, db, "scheduler-reply-timeout")
+	agentID := schedulerCreateAgent(t, db, orgID, "scheduler-reply-timeout-agent")
+	ctx := schedulerCtxWithWorkspace(orgID)
+	jobStore := store.NewAgentJobStore(db)
+
+	now := time.Date(2026, 2, 12, 20, 20, 0, 0, time.UTC)
+	nextRunAt := now.Add(-10 * time.Second)
+	job, err := jobStore.Create(ctx, store.CreateAgentJobInput{
+		AgentID:          agentID,
+		Name:             "Quiet Agent",
+		ScheduleKind:     store.AgentJobScheduleInterval,
+		IntervalMS:       int64Ptr(3600000),
+		Timezone:         strPtr("UTC"),
+		PayloadKind:      store.AgentJobPayloadSystemEvent,
+		PayloadText:      `report {"status": ...}`,
+		NextRunAt:        &nextRunAt,
+		SuccessJSONField: strPtr("status"),
+		ReplyTimeoutMS:   int64Ptr(30000),
+	})
+	require.NoError(t, err)
+
+	worker := NewAgentJobWorker(jobStore, AgentJobWorkerConfig{
+		PollInterval:  10 * time.Millisecond,
+		MaxPerPoll:    5,
+		RunTimeout:    10 * time.Minute,
+		MaxRunHistory: 100,
+	})
+	worker.Now = func() time.Time { return now }
+
+	processed, err := worker.RunOnce(ctx)
+	require.NoError(t, err)
+	require.Equal(t, 1, processed)
+
+	now = now.Add(31 * time.Second)
+	processed, err = worker.RunOnce(ctx)
+	require.NoError(t, err)
+	require.Equal(t, 0, processed)
+
+	runs, err := jobStore.ListRuns(ctx, job.ID, 10)
+	require.NoError(t, err)
+	require.Len(t, runs, 1)
+	require.Equal(t, store.AgentJobRunStatusTimeout, runs[0].Status)
+	require.NotNil(t, runs[0].Error)
+	require.Contains(t, *runs[0].Error, "did not reply")
+
+	updatedJob, err := jobStore.GetByID(ctx, job.ID)
+	require.NoError(t, err)
+	require.Equal(t, 1, updatedJob.ConsecutiveFailures)
 }
 
 func TestJobSchedulerWorkerAutoPausesAfterConsecutiveFailures(t *testing.T) {
@@ -228,6 +395,253 @@ func TestJobSchedulerWorkerAutoPausesAfterConsecutiveFailures(t *testing.T) {
 	require.Equal(t, store.AgentJobRunStatusError, *jobAfterSecondRun.LastRunStatus)
 }
 
+func TestJobSchedulerWorkerFailureRetrySkipsBlackout(t *testing.T) {
+	db := setupSchedulerTestDB(t)
+	orgID := schedulerCreateOrg(t, db, "scheduler-failure-blackout")
+	agentID := schedulerCreateAgent(t, db, orgID, "scheduler-failure-blackout-agent")
+	ctx := schedulerCtxWithWorkspace(orgID)
+	jobStore := store.NewAgentJobStore(db)
+
+	startMinute, endMinute := 20*60+30, 21*60
+	_, err := jobStore.CreateBlackoutWindow(ctx, store.CreateAgentJobBlackoutWindowInput{
+		Name:        "Evening freeze",
+		StartMinute: &startMinute,
+		EndMinute:   &endMinute,
+	})
+	require.NoError(t, err)
+
+	now := time.Date(2026, 2, 12, 20, 29, 30, 0, time.UTC)
+	nextRunAt := now.Add(-10 * time.Second)
+	job, err := jobStore.Create(ctx, store.CreateAgentJobInput{
+		AgentID:      agentID,
+		Name:         "Failing Job",
+		ScheduleKind: store.AgentJobScheduleInterval,
+		IntervalMS:   int64Ptr(60000),
+		Timezone:     strPtr("Not/ARealTimezone"),
+		PayloadKind:  store.AgentJobPayloadMessage,
+		PayloadText:  "will fail before success completion",
+		NextRunAt:    &nextRunAt,
+	})
+	require.NoError(t, err)
+
+	worker := NewAgentJobWorker(jobStore, AgentJobWorkerConfig{
+		PollInterval:  10 * time.Millisecond,
+		MaxPerPoll:    5,
+		RunTimeout:    1 * time.Minute,
+		MaxRunHistory: 100,
+	})
+	worker.Now = func() time.Time { return now }
+
+	processed, err := worker.RunOnce(ctx)
+	require.NoError(t, err)
+	require.Equal(t, 1, processed)
+
+	updatedJob, err := jobStore.GetByID(ctx, job.ID)
+	require.NoError(t, err)
+	require.Equal(t, 1, updatedJob.ConsecutiveFailures)
+	require.NotNil(t, updatedJob.NextRunAt)
+	require.Equal(t, time.Date(2026, 2, 12, 21, 0, 0, 0, time.UTC), updatedJob.NextRunAt.UTC())
+}
+
+func TestComputeFailureNextRunSkipsBlackouts(t *testing.T) {
+	t.Parallel()
+
+	blackouts := []store.AgentJobBlackoutWindow{{Name: "Maintenance", StartMinute: 2 * 60, EndMinute: 3 * 60}}
+	now := time.Date(2026, 2, 12, 1, 50, 0, 0, time.UTC)
+
+	interval := store.AgentJob{
+		ScheduleKind: store.AgentJobScheduleInterval,
+		IntervalMS:   int64Ptr(int64((25 * time.Minute).Milliseconds())),
+		Timezone:     "UTC",
+	}
+	nextRunAt := computeFailureNextRun(interval, RunPolicyForJob(interval, blackouts), now)
+	require.NotNil(t, nextRunAt)
+	require.Equal(t, time.Date(2026, 2, 12, 3, 5, 0, 0, time.UTC), nextRunAt.UTC())
+
+	broken := interval
+	broken.Timezone = "Not/ARealTimezone"
+	retryAt := computeFailureNextRun(broken, RunPolicyForJob(broken, blackouts), now.Add(9*time.Minute+30*time.Second))
+	require.NotNil(t, retryAt)
+	require.Equal(t, time.Date(2026, 2, 12, 3, 0, 0, 0, time.UTC), retryAt.UTC())
+
+	retryAt = computeFailureNextRun(broken, RunPolicyForJob(broken, nil), now)
+	require.NotNil(t, retryAt)
+	require.Equal(t, now.Add(defaultJobRetryDelay), retryAt.UTC())
+}
+
+func TestJobSchedulerWorkerSkipsOverlappingRun(t *testing.T) {
+	db := setupSchedulerTestDB(t)
+	orgID := schedulerCreateOrg(t, db, "scheduler-overlap")
+	agentID := schedulerCreateAgent(t, db, orgID, "scheduler-overlap-agent")
+	ctx := schedulerCtxWithWorkspace(orgID)
+	jobStore := store.NewAgentJobStore(db)
+
+	now := time.Date(2026, 2, 12, 21, 0, 0, 0, time.UTC)
+	nextRunAt := now.Add(-10 * time.Second)
+	job, err := jobStore.Create(ctx, store.CreateAgentJobInput{
+		AgentID:      agentID,
+		Name:         "Slow Job",
+		ScheduleKind: store.AgentJobScheduleInterval,
+		IntervalMS:   int64Ptr(60000),
+		Timezone:     strPtr("UTC"),
+		PayloadKind:  store.AgentJobPayloadMessage,
+		PayloadText:  "slow",
+		NextRunAt:    &nextRunAt,
+	})
+	require.NoError(t, err)
+	_, err = jobStore.StartRun(ctx, store.StartAgentJobRunInput{
+		JobID:       job.ID,
+		PayloadText: "slow",
+		StartedAt:   now.Add(-30 * time.Second),
+	})
+	require.NoError(t, err)
+
+	worker := NewAgentJobWorker(jobStore, AgentJobWorkerConfig{
+		PollInterval:  10 * time.Millisecond,
+		MaxPerPoll:    5,
+		RunTimeout:    10 * time.Minute,
+		MaxRunHistory: 100,
+	})
+	worker.Now = func() time.Time { return now }
+
+	processed, err := worker.RunOnce(ctx)
+	require.NoError(t, err)
+	require.Equal(t, 1, processed)
+
+	updatedJob, err := jobStore.GetByID(ctx, job.ID)
+	require.NoError(t, err)
+	require.NotNil(t, updatedJob.NextRunAt)
+	require.True(t, updatedJob.NextRunAt.After(now))
+	require.NotNil(t, updatedJob.LastRunStatus)
+	require.Equal(t, store.AgentJobRunStatusSkipped, *updatedJob.LastRunStatus)
+
+	runs, err := jobStore.ListRuns(ctx, job.ID, 10)
+	require.NoError(t, err)
+	require.Len(t, runs, 2)
+	statuses := []string{runs[0].Status, runs[1].Status}
+	require.ElementsMatch(t, []string{store.AgentJobRunStatusRunning, store.AgentJobRunStatusSkipped}, statuses)
+}
+
+func TestJobSchedulerWorkerDefersBusyJobsToNextPoll(t *testing.T) {
+	db := setupSchedulerTestDB(t)
+	orgID := schedulerCreateOrg(t, db, "scheduler-defer")
+	agentID := schedulerCreateAgent(t, db, orgID, "scheduler-defer-agent")
+	ctx := schedulerCtxWithWorkspace(orgID)
+	jobStore := store.NewAgentJobStore(db)
+
+	now := time.Date(2026, 2, 12, 21, 0, 0, 0, time.UTC)
+	dueAt := now.Add(-10 * time.Second)
+	queue := store.AgentJobOverlapQueue
+	queued, err := jobStore.Create(ctx, store.CreateAgentJobInput{
+		AgentID:       agentID,
+		Name:          "Queued Job",
+		ScheduleKind:  store.AgentJobScheduleInterval,
+		IntervalMS:    int64Ptr(60000),
+		Timezone:      strPtr("UTC"),
+		PayloadKind:   store.AgentJobPayloadMessage,
+		PayloadText:   "queued",
+		NextRunAt:     &dueAt,
+		OverlapPolicy: &queue,
+	})
+	require.NoError(t, err)
+	_, err = jobStore.StartRun(ctx, store.StartAgentJobRunInput{
+		JobID:       queued.ID,
+		PayloadText: "queued",
+		StartedAt:   now.Add(-30 * time.Second),
+	})
+	require.NoError(t, err)
+
+	// The queued job's own run also fills the agent's single slot.
+	maxAgentConcurrency := 1
+	limited, err := jobStore.Create(ctx, store.CreateAgentJobInput{
+		AgentID:             agentID,
+		Name:                "Limited Job",
+		ScheduleKind:        store.AgentJobScheduleInterval,
+		IntervalMS:          int64Ptr(60000),
+		Timezone:            strPtr("UTC"),
+		PayloadKind:         store.AgentJobPayloadMessage,
+		PayloadText:         "limited",
+		NextRunAt:           &dueAt,
+		MaxAgentConcurrency: &maxAgentConcurrency,
+	})
+	require.NoError(t, err)
+
+	worker := NewAgentJobWorker(jobStore, AgentJobWorkerConfig{
+		PollInterval:  30 * time.Second,
+		MaxPerPoll:    5,
+		RunTimeout:    10 * time.Minute,
+		MaxRunHistory: 100,
+	})
+	worker.Now = func() time.Time { return now }
+
+	processed, err := worker.RunOnce(ctx)
+	require.NoError(t, err)
+	require.Equal(t, 2, processed)
+
+	for _, id := range []string{queued.ID, limited.ID} {
+		job, err := jobStore.GetByID(ctx, id)
+		require.NoError(t, err)
+		require.NotNil(t, job.NextRunAt)
+		require.True(t, now.Add(30*time.Second).Equal(*job.NextRunAt), "%s deferred to %s", job.Name, job.NextRunAt)
+		runs, err := jobStore.ListRuns(ctx, id, 10)
+		require.NoError(t, err)
+		for _, run := range runs {
+			require.NotEqual(t, store.AgentJobRunStatusSkipped, run.Status, "deferred runs are not recorded as skipped")
+		}
+	}
+
+	// Nothing is due again until the next poll.
+	processed, err = worker.RunOnce(ctx)
+	require.NoError(t, err)
+	require.Zero(t, processed)
+}
+
+func TestJobSchedulerWorkerCatchUpSkipDropsMissedRuns(t *testing.T) {
+	db := setupSchedulerTestDB(t)
+	orgID := schedulerCreateOrg(t, db, "scheduler-catch-up")
+	agentID := schedulerCreateAgent(t, db, orgID, "scheduler-catch-up-agent")
+	ctx := schedulerCtxWithWorkspace(orgID)
+	jobStore := store.NewAgentJobStore(db)
+
+	now := time.Date(2026, 2, 12, 21, 30, 0, 0, time.UTC)
+	nextRunAt := now.Add(-5 * time.Minute)
+	job, err := jobStore.Create(ctx, store.CreateAgentJobInput{
+		AgentID:       agentID,
+		Name:          "Missed Job",
+		ScheduleKind:  store.AgentJobScheduleInterval,
+		IntervalMS:    int64Ptr(60000),
+		Timezone:      strPtr("UTC"),
+		PayloadKind:   store.AgentJobPayloadMessage,
+		PayloadText:   "missed",
+		NextRunAt:     &nextRunAt,
+		CatchUpPolicy: strPtr(store.AgentJobCatchUpSkip),
+	})
+	require.NoError(t, err)
+
+	worker := NewAgentJobWorker(jobStore, AgentJobWorkerConfig{
+		PollInterval:  10 * time.Millisecond,
+		MaxPerPoll:    5,
+		RunTimeout:    1 * time.Minute,
+		MaxRunHistory: 100,
+	})
+	worker.Now = func() time.Time { return now }
+
+	processed, err := worker.RunOnce(ctx)
+	require.NoError(t, err)
+	require.Equal(t, 1, processed)
+
+	updatedJob, err := jobStore.GetByID(ctx, job.ID)
+	require.NoError(t, err)
+	require.NotNil(t, updatedJob.NextRunAt)
+	require.True(t, updatedJob.NextRunAt.After(now))
+	require.Nil(t, updatedJob.RoomID)
+
+	runs, err := jobStore.ListRuns(ctx, job.ID, 10)
+	require.NoError(t, err)
+	require.Len(t, runs, 1)
+	require.Equal(t, store.AgentJobRunStatusSkipped, runs[0].Status)
+}
+
 func TestJobSchedulerWorkerCleanupStaleRuns(t *testing.T) {
 	db := setupSchedulerTestDB(t)
 	orgID := schedulerCreateOrg(t, db, "scheduler-stale")
@@ -289,7 +703,7 @@ func TestJobSchedulerWorkerPrunesRunHistory(t *testing.T) {
 		ScheduleKind: store.AgentJobScheduleInterval,
 		IntervalMS:   int64Ptr(60000),
 		Timezone:     strPtr("UTC"),
-		PayloadKind:  store.AgentJobPayloadMessage,
+		PayloadKind:  store.AgentJobPayloadSystemEvent,
 		PayloadText:  "prune history",
 		NextRunAt:    &nextRunAt,
 	})
@@ -332,7 +746,7 @@ func TestJobSchedulerWorkerRunOnceInjectsConfiguredWorkspaceWithoutHTTPContext(t
 		ScheduleKind: store.AgentJobScheduleOnce,
 		RunAt:        &runAt,
 		Timezone:     strPtr("UTC"),
-		PayloadKind:  store.AgentJobPayloadMessage,
+		PayloadKind:  store.AgentJobPayloadSystemEvent,
 		PayloadText:  "execute from background context",
 		NextRunAt:    &nextRunAt,
 	})
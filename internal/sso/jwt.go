@@ -0,0 +1,340 @@
+package sso
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"math/big"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	defaultJWKSTTL        = time.Hour
+	defaultJWKSMinRefresh = time.Minute
+	maxJWKSBytes          = 1 << 20
+)
+
+type jwtHeader struct {
+	Alg string `json:"alg"`
+	Kid string `json:"kid"`
+	Typ string `json:"typ"`
+}
+
+type parsedJWT struct {
+	Header       jwtHeader
+	Claims       map[string]any
+	SigningInput []byte
+	Signature    []byte
+}
+
+func parseJWT(token string) (*parsedJWT, error) {
+	parts := strings.Split(strings.TrimSpace(token), ".")
+	if len(parts) != 3 {
+		return nil, errors.New("malformed token")
+	}
+	headerJSON, err := base64.RawURLEncoding.DecodeString(parts[0])
+	if err != nil {
+		return nil, errors.New("malformed token header")
+	}
+	payloadJSON, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil {
+		return nil, errors.New("malformed token payload")
+	}
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, errors.New("malformed token signature")
+	}
+
+	parsed := &parsedJWT{SigningInput: []byte(parts[0] + "." + parts[1]), Signature: signature}
+	if err := json.Unmarshal(headerJSON, &parsed.Header); err != nil {
+		return nil, errors.New("malformed token header")
+	}
+	decoder := json.NewDecoder(strings.NewReader(string(payloadJSON)))
+	decoder.UseNumber()
+	if err := decoder.Decode(&parsed.Claims); err != nil || parsed.Claims == nil {
+		return nil, errors.New("malformed token payload")
+	}
+	return parsed, nil
+}
+
+// jwsHash maps a JWS alg to its hash. "none" and HMAC algs are deliberately
+// absent: ID tokens must be signed with the IdP's published keys.
+func jwsHash(alg string) (crypto.Hash, bool) {
+	switch alg {
+	case "RS256", "ES256", "PS256":
+		return crypto.SHA256, true
+	case "RS384", "ES384", "PS384":
+		return crypto.SHA384, true
+	case "RS512", "ES512", "PS512":
+		return crypto.SHA512, true
+	}
+	return 0, false
+}
+
+func newHash(h crypto.Hash) hash.Hash {
+	switch h {
+	case crypto.SHA384:
+		return sha512.New384()
+	case crypto.SHA512:
+		return sha512.New()
+	default:
+		return sha256.New()
+	}
+}
+
+func verifyJWS(alg string, key crypto.PublicKey, signingInput, signature []byte) error {
+	hashAlg, ok := jwsHash(alg)
+	if !ok {
+		return fmt.Errorf("unsupported signing algorithm %q", alg)
+	}
+	h := newHash(hashAlg)
+	h.Write(signingInput)
+	digest := h.Sum(nil)
+
+	switch pub := key.(type) {
+	case *rsa.PublicKey:
+		switch alg[:2] {
+		case "RS":
+			return rsa.VerifyPKCS1v15(pub, hashAlg, digest, signature)
+		case "PS":
+			return rsa.VerifyPSS(pub, hashAlg, digest, signature, &rsa.PSSOptions{SaltLength: rsa.PSSSaltLengthEqualsHash})
+		}
+	case *ecdsa.PublicKey:
+		if alg[:2] != "ES" {
+			break
+		}
+		size := (pub.Curve.Params().BitSize + 7) / 8
+		if len(signature) != 2*size {
+			return errors.New("invalid signature length")
+		}
+		r := new(big.Int).SetBytes(signature[:size])
+		s := new(big.Int).SetBytes(signature[size:])
+		if !ecdsa.Verify(pub, digest, r, s) {
+			return errors.New("invalid signature")
+		}
+		return nil
+	}
+	return fmt.Errorf("key type does not match algorithm %q", alg)
+}
+
+type jwk struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	Alg string `json:"alg"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+	Crv string `json:"crv"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+}
+
+type publicJWK struct {
+	Kid string
+	Alg string
+	Key crypto.PublicKey
+}
+
+func (k jwk) publicKey() (crypto.PublicKey, error) {
+	decode := base64.RawURLEncoding.DecodeString
+	switch k.Kty {
+	case "RSA":
+		n, err := decode(k.N)
+		if err != nil {
+			return nil, err
+		}
+		e, err := decode(k.E)
+		if err != nil {
+			return nil, err
+		}
+		exponent := new(big.Int).SetBytes(e)
+		if !exponent.IsInt64() || exponent.Int64() > 1<<31-1 || exponent.Int64() < 3 {
+			return nil, errors.New("invalid RSA exponent")
+		}
+		return &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: int(exponent.Int64())}, nil
+	case "EC":
+		var curve elliptic.Curve
+		switch k.Crv {
+		case "P-256":
+			curve = elliptic.P256()
+		case "P-384":
+			curve = elliptic.P384()
+		case "P-521":
+			curve = elliptic.P521()
+		default:
+			return nil, fmt.Errorf("unsupported curve %q", k.Crv)
+		}
+		x, err := decode(k.X)
+		if err != nil {
+			return nil, err
+		}
+		y, err := decode(k.Y)
+		if err != nil {
+			return nil, err
+		}
+		pub := &ecdsa.PublicKey{Curve: curve, X: new(big.Int).SetBytes(x), Y: new(big.Int).SetBytes(y)}
+		if !curve.IsOnCurve(pub.X, pub.Y) {
+			return nil, errors.New("EC point not on curve")
+		}
+		return pub, nil
+	}
+	return nil, fmt.Errorf("unsupported key type %q", k.Kty)
+}
+
+// JWKSCache fetches and caches JSON Web Key Sets by URL. Keys are refetched
+// after TTL, or early (at most once per MinRefresh) when a token names a kid
+// the cache has not seen, which is how IdP key rotation shows up.
+type JWKSCache struct {
+	HTTP       *http.Client
+	TTL        time.Duration
+	MinRefresh time.Duration
+	Now        func() time.Time
+
+	mu      sync.Mutex
+	entries map[string]*jwksEntry
+}
+
+type jwksEntry struct {
+	keys      []publicJWK
+	fetchedAt time.Time
+}
+
+func (c *JWKSCache) now() time.Time {
+	if c.Now != nil {
+		return c.Now()
+	}
+	return time.Now()
+}
+
+// Key returns the verification key for kid (or the only key usable with alg
+// when the token has no kid).
+func (c *JWKSCache) Key(ctx context.Context, jwksURL, kid, alg string) (crypto.PublicKey, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.entries == nil {
+		c.entries = map[string]*jwksEntry{}
+	}
+	ttl := c.TTL
+	if ttl <= 0 {
+		ttl = defaultJWKSTTL
+	}
+	minRefresh := c.MinRefresh
+	if minRefresh <= 0 {
+		minRefresh = defaultJWKSMinRefresh
+	}
+
+	entry := c.entries[jwksURL]
+	now := c.now()
+	if entry == nil || now.Sub(entry.fetchedAt) >= ttl {
+		fresh, err := c.fetch(ctx, jwksURL)
+		if err != nil {
+			if entry == nil {
+				return nil, err
+			}
+			// Keep serving the stale set if the IdP is briefly unreachable.
+		} else {
+			entry = fresh
+			c.entries[jwksURL] = entry
+		}
+	}
+	if key, ok := selectKey(entry.keys, kid, alg); ok {
+		return key, nil
+	}
+	if now.Sub(entry.fetchedAt) >= minRefresh {
+		fresh, err := c.fetch(ctx, jwksURL)
+		if err != nil {
+			return nil, err
+		}
+		c.entries[jwksURL] = fresh
+		if key, ok := selectKey(fresh.keys, kid, alg); ok {
+			return key, nil
+		}
+	}
+	return nil, fmt.Errorf("no signing key %q in JWKS", kid)
+}
+
+func (c *JWKSCache) fetch(ctx context.Context, jwksURL string) (*jwksEntry, error) {
+	var set struct {
+		Keys []jwk `json:"keys"`
+	}
+	if err := getJSON(ctx, c.HTTP, jwksURL, &set); err != nil {
+		return nil, fmt.Errorf("fetch JWKS: %w", err)
+	}
+	entry := &jwksEntry{fetchedAt: c.now()}
+	for _, key := range set.Keys {
+		if key.Use != "" && key.Use != "sig" {
+			continue
+		}
+		pub, err := key.publicKey()
+		if err != nil {
+			continue
+		}
+		entry.keys = append(entry.keys, publicJWK{Kid: key.Kid, Alg: key.Alg, Key: pub})
+	}
+	if len(entry.keys) == 0 {
+		return nil, errors.New("fetch JWKS: no usable signing keys")
+	}
+	return entry, nil
+}
+
+func selectKey(keys []publicJWK, kid, alg string) (crypto.PublicKey, bool) {
+	if kid != "" {
+		for _, key := range keys {
+			if key.Kid == kid {
+				return key.Key, true
+			}
+		}
+		return nil, false
+	}
+	var match crypto.PublicKey
+	count := 0
+	for _, key := range keys {
+		if key.Alg != "" && key.Alg != alg {
+			continue
+		}
+		_, isRSA := key.Key.(*rsa.PublicKey)
+		_, isEC := key.Key.(*ecdsa.PublicKey)
+		if (isRSA && (strings.HasPrefix(alg, "RS") || strings.HasPrefix(alg, "PS"))) || (isEC && strings.HasPrefix(alg, "ES")) {
+			match = key.Key
+			count++
+		}
+	}
+	return match, count == 1
+}
+
+func getJSON(ctx context.Context, client *http.Client, url string, out any) error {
+	if client == nil {
+		client = http.DefaultClient
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxJWKSBytes))
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("GET %s: status %d", url, resp.StatusCode)
+	}
+	return json.Unmarshal(body, out)
+}
@@ -0,0 +1,336 @@
+package sso
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	defaultDiscoveryTTL = time.Hour
+	maxTokenBytes       = 1 << 20
+)
+
+// OIDCConfig is one org's OpenID Connect client registration.
+type OIDCConfig struct {
+	Issuer       string
+	ClientID     string
+	ClientSecret string
+	RedirectURL  string
+	Scopes       []string
+	Claims       ClaimMapping
+}
+
+// Discovery is the subset of the provider metadata document this client uses.
+type Discovery struct {
+	Issuer                   string   `json:"issuer"`
+	AuthorizationEndpoint    string   `json:"authorization_endpoint"`
+	TokenEndpoint            string   `json:"token_endpoint"`
+	JWKSURI                  string   `json:"jwks_uri"`
+	TokenAuthMethods         []string `json:"token_endpoint_auth_methods_supported"`
+	CodeChallengeMethods     []string `json:"code_challenge_methods_supported"`
+	IDTokenSigningAlgorithms []string `json:"id_token_signing_alg_values_supported"`
+}
+
+// OIDC runs the authorization code flow with PKCE. Discovery documents and
+// JWKS are cached per issuer, so one OIDC value should be shared by all orgs.
+type OIDC struct {
+	HTTP         *http.Client
+	DiscoveryTTL time.Duration
+	Keys         *JWKSCache
+	Now          func() time.Time
+
+	mu        sync.Mutex
+	discovery map[string]cachedDiscovery
+}
+
+type cachedDiscovery struct {
+	doc       Discovery
+	fetchedAt time.Time
+}
+
+func NewOIDC(client *http.Client) *OIDC {
+	if client == nil {
+		client = &http.Client{Timeout: 10 * time.Second}
+	}
+	return &OIDC{HTTP: client, Keys: &JWKSCache{HTTP: client}}
+}
+
+func (o *OIDC) now() time.Time {
+	if o.Now != nil {
+		return o.Now()
+	}
+	return time.Now()
+}
+
+// Discover loads (and caches) the issuer's metadata document. The document's
+// issuer must match the configured issuer exactly.
+func (o *OIDC) Discover(ctx context.Context, issuer string) (Discovery, error) {
+	issuer = strings.TrimSpace(issuer)
+	if issuer == "" {
+		return Discovery{}, errorf("issuer is required")
+	}
+	ttl := o.DiscoveryTTL
+	if ttl <= 0 {
+		ttl = defaultDiscoveryTTL
+	}
+
+	o.mu.Lock()
+	cached, ok := o.discovery[issuer]
+	o.mu.Unlock()
+	if ok && o.now().Sub(cached.fetchedAt) < ttl {
+		return cached.doc, nil
+	}
+
+	var doc Discovery
+	endpoint := strings.TrimSuffix(issuer, "/") + "/.well-known/openid-configuration"
+	if err := getJSON(ctx, o.HTTP, endpoint, &doc); err != nil {
+		if ok {
+			return cached.doc, nil
+		}
+		return Discovery{}, errorf("discovery: %v", err)
+	}
+	if doc.Issuer != issuer {
+		return Discovery{}, errorf("discovery: issuer %q does not match configured issuer %q", doc.Issuer, issuer)
+	}
+	if doc.AuthorizationEndpoint == "" || doc.TokenEndpoint == "" || doc.JWKSURI == "" {
+		return Discovery{}, errorf("discovery: document is missing required endpoints")
+	}
+
+	o.mu.Lock()
+	if o.discovery == nil {
+		o.discovery = map[string]cachedDiscovery{}
+	}
+	o.discovery[issuer] = cachedDiscovery{doc: doc, fetchedAt: o.now()}
+	o.mu.Unlock()
+	return doc, nil
+}
+
+// AuthCodeURL builds the authorization request the browser is sent to.
+func (o *OIDC) AuthCodeURL(ctx context.Context, cfg OIDCConfig, state, nonce, verifier string) (string, error) {
+	doc, err := o.Discover(ctx, cfg.Issuer)
+	if err != nil {
+		return "", err
+	}
+	scopes := cfg.Scopes
+	if len(scopes) == 0 {
+		scopes = []string{"openid", "email", "profile"}
+	}
+	hasOpenID := false
+	for _, scope := range scopes {
+		if scope == "openid" {
+			hasOpenID = true
+		}
+	}
+	if !hasOpenID {
+		scopes = append([]string{"openid"}, scopes...)
+	}
+
+	authURL, err := url.Parse(doc.AuthorizationEndpoint)
+	if err != nil {
+		return "", errorf("invalid authorization endpoint: %v", err)
+	}
+	q := authURL.Query()
+	q.Set("response_type", "code")
+	q.Set("client_id", cfg.ClientID)
+	q.Set("redirect_uri", cfg.RedirectURL)
+	q.Set("scope", strings.Join(scopes, " "))
+	q.Set("state", state)
+	q.Set("nonce", nonce)
+	q.Set("code_challenge", PKCEChallenge(verifier))
+	q.Set("code_challenge_method", "S256")
+	authURL.RawQuery = q.Encode()
+	return authURL.String(), nil
+}
+
+// Exchange redeems an authorization code and returns the validated identity
+// from the ID token.
+func (o *OIDC) Exchange(ctx context.Context, cfg OIDCConfig, code, verifier, nonce string) (Identity, error) {
+	doc, err := o.Discover(ctx, cfg.Issuer)
+	if err != nil {
+		return Identity{}, err
+	}
+
+	form := url.Values{}
+	form.Set("grant_type", "authorization_code")
+	form.Set("code", code)
+	form.Set("redirect_uri", cfg.RedirectURL)
+	form.Set("code_verifier", verifier)
+	useBasic := cfg.ClientSecret != "" && supportsBasicAuth(doc.TokenAuthMethods)
+	if !useBasic {
+		form.Set("client_id", cfg.ClientID)
+		if cfg.ClientSecret != "" {
+			form.Set("client_secret", cfg.ClientSecret)
+		}
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, doc.TokenEndpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return Identity{}, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+	if useBasic {
+		req.SetBasicAuth(url.QueryEscape(cfg.ClientID), url.QueryEscape(cfg.ClientSecret))
+	}
+	resp, err := o.HTTP.Do(req)
+	if err != nil {
+		return Identity{}, errorf("token request: %v", err)
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxTokenBytes))
+	if err != nil {
+		return Identity{}, errorf("token response: %v", err)
+	}
+	var token struct {
+		IDToken          string `json:"id_token"`
+		Error            string `json:"error"`
+		ErrorDescription string `json:"error_description"`
+	}
+	_ = json.Unmarshal(body, &token)
+	if resp.StatusCode != http.StatusOK {
+		if token.Error != "" {
+			return Identity{}, errorf("token request rejected: %s %s", token.Error, token.ErrorDescription)
+		}
+		return Identity{}, errorf("token request failed with status %d", resp.StatusCode)
+	}
+	if token.IDToken == "" {
+		return Identity{}, errorf("token response has no id_token")
+	}
+
+	claims, err := o.VerifyIDToken(ctx, cfg, doc, token.IDToken, nonce)
+	if err != nil {
+		return Identity{}, err
+	}
+	return identityFromClaims(claims, cfg.Claims), nil
+}
+
+// VerifyIDToken checks the token's signature against the issuer's JWKS and
+// validates iss, aud/azp, exp, iat, nbf and nonce.
+func (o *OIDC) VerifyIDToken(ctx context.Context, cfg OIDCConfig, doc Discovery, rawToken, nonce string) (map[string]any, error) {
+	token, err := parseJWT(rawToken)
+	if err != nil {
+		return nil, errorf("id_token: %v", err)
+	}
+	if _, ok := jwsHash(token.Header.Alg); !ok {
+		return nil, errorf("id_token: unsupported signing algorithm %q", token.Header.Alg)
+	}
+	keys := o.Keys
+	if keys == nil {
+		keys = &JWKSCache{HTTP: o.HTTP}
+		o.Keys = keys
+	}
+	key, err := keys.Key(ctx, doc.JWKSURI, token.Header.Kid, token.Header.Alg)
+	if err != nil {
+		return nil, errorf("id_token: %v", err)
+	}
+	if err := verifyJWS(token.Header.Alg, key, token.SigningInput, token.Signature); err != nil {
+		return nil, errorf("id_token: signature: %v", err)
+	}
+	if err := validateIDTokenClaims(token.Claims, cfg, nonce, o.now()); err != nil {
+		return nil, errorf("id_token: %v", err)
+	}
+	return token.Claims, nil
+}
+
+func validateIDTokenClaims(claims map[string]any, cfg OIDCConfig, nonce string, now time.Time) error {
+	if claimString(claims, "iss") != cfg.Issuer {
+		return errors.New("issuer mismatch")
+	}
+	if claimString(claims, "sub") == "" {
+		return errors.New("missing subject")
+	}
+	audiences := stringList(claims["aud"])
+	if aud, ok := claims["aud"].(string); ok {
+		audiences = []string{aud}
+	}
+	if !containsString(audiences, cfg.ClientID) {
+		return errors.New("audience mismatch")
+	}
+	if azp := claimString(claims, "azp"); azp != "" && azp != cfg.ClientID {
+		return errors.New("authorized party mismatch")
+	}
+	if len(audiences) > 1 && claimString(claims, "azp") == "" {
+		return errors.New("missing authorized party for multiple audiences")
+	}
+
+	exp, ok := numericDate(claims["exp"])
+	if !ok {
+		return errors.New("missing exp")
+	}
+	if now.After(exp.Add(clockSkew)) {
+		return errors.New("token expired")
+	}
+	iat, ok := numericDate(claims["iat"])
+	if !ok {
+		return errors.New("missing iat")
+	}
+	if iat.After(now.Add(clockSkew)) {
+		return errors.New("token issued in the future")
+	}
+	if nbf, ok := numericDate(claims["nbf"]); ok && nbf.After(now.Add(clockSkew)) {
+		return errors.New("token not yet valid")
+	}
+	if nonce != "" && claimString(claims, "nonce") != nonce {
+		return errors.New("nonce mismatch")
+	}
+	return nil
+}
+
+func identityFromClaims(claims map[string]any, mapping ClaimMapping) Identity {
+	mapping = mapping.withDefaults()
+	identity := Identity{
+		Issuer:  claimString(claims, "iss"),
+		Subject: claimString(claims, "sub"),
+		Email:   strings.ToLower(claimString(claims, mapping.Email)),
+		Name:    claimString(claims, mapping.Name),
+		Groups:  stringList(claims[mapping.Groups]),
+	}
+	if identity.Name == "" {
+		identity.Name = claimString(claims, "preferred_username")
+	}
+	if verified, ok := claims["email_verified"].(bool); ok {
+		identity.EmailVerified = &verified
+	}
+	return identity
+}
+
+func numericDate(value any) (time.Time, bool) {
+	switch v := value.(type) {
+	case json.Number:
+		f, err := v.Float64()
+		if err != nil {
+			return time.Time{}, false
+		}
+		return time.Unix(int64(f), 0), true
+	case float64:
+		return time.Unix(int64(v), 0), true
+	}
+	return time.Time{}, false
+}
+
+func supportsBasicAuth(methods []string) bool {
+	// client_secret_basic is the default when the IdP does not say.
+	return len(methods) == 0 || containsString(methods, "client_secret_basic")
+}
+
+func containsString(values []string, want string) bool {
+	for _, value := range values {
+		if value == want {
+			return true
+		}
+	}
+	return false
+}
+
+// String is used in logs; it never includes the client secret.
+func (c OIDCConfig) String() string {
+	return fmt.Sprintf("oidc(issuer=%s client_id=%s)", c.Issuer, c.ClientID)
+}
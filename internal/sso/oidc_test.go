@@ -0,0 +1,174 @@
+package sso
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/samhotchkiss/otter-camp/internal/sso/ssotest"
+	"github.com/stretchr/testify/require"
+)
+
+func newMockIdP(t *testing.T) *ssotest.IdP {
+	t.Helper()
+	idp, err := ssotest.New()
+	require.NoError(t, err)
+	t.Cleanup(idp.Close)
+	return idp
+}
+
+func oidcConfig(idp *ssotest.IdP) OIDCConfig {
+	return OIDCConfig{
+		Issuer:       idp.Issuer,
+		ClientID:     idp.ClientID,
+		ClientSecret: idp.ClientSecret,
+		RedirectURL:  "https://otter.example/api/auth/sso/oidc/callback",
+	}
+}
+
+// authorize follows the browser leg of the flow and returns the code and
+// state the IdP redirected back with.
+func authorize(t *testing.T, authURL string) (string, string) {
+	t.Helper()
+	client := &http.Client{CheckRedirect: func(*http.Request, []*http.Request) error { return http.ErrUseLastResponse }}
+	resp, err := client.Get(authURL)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	require.Equal(t, http.StatusFound, resp.StatusCode)
+	location, err := url.Parse(resp.Header.Get("Location"))
+	require.NoError(t, err)
+	return location.Query().Get("code"), location.Query().Get("state")
+}
+
+func TestOIDCAuthorizationCodeFlowWithPKCE(t *testing.T) {
+	idp := newMockIdP(t)
+	idp.SetUser(ssotest.User{Subject: "u-42", Email: "Dev@Example.com", Name: "Dev", Groups: []string{"eng", "admins"}})
+	client := NewOIDC(nil)
+	cfg := oidcConfig(idp)
+
+	verifier, err := NewPKCEVerifier()
+	require.NoError(t, err)
+	authURL, err := client.AuthCodeURL(context.Background(), cfg, "state-1", "nonce-1", verifier)
+	require.NoError(t, err)
+	require.Contains(t, authURL, "code_challenge_method=S256")
+	require.Contains(t, authURL, "scope=openid+email+profile")
+
+	code, state := authorize(t, authURL)
+	require.Equal(t, "state-1", state)
+
+	identity, err := client.Exchange(context.Background(), cfg, code, verifier, "nonce-1")
+	require.NoError(t, err)
+	require.Equal(t, idp.Issuer, identity.Issuer)
+	require.Equal(t, "u-42", identity.Subject)
+	require.Equal(t, "dev@example.com", identity.Email)
+	require.Equal(t, []string{"eng", "admins"}, identity.Groups)
+	require.NotNil(t, identity.EmailVerified)
+	require.True(t, *identity.EmailVerified)
+
+	// Codes are single use.
+	_, err = client.Exchange(context.Background(), cfg, code, verifier, "nonce-1")
+	require.ErrorContains(t, err, "invalid_grant")
+}
+
+func TestOIDCExchangeRejectsWrongVerifierAndNonce(t *testing.T) {
+	idp := newMockIdP(t)
+	client := NewOIDC(nil)
+	cfg := oidcConfig(idp)
+
+	authURL, err := client.AuthCodeURL(context.Background(), cfg, "s", "nonce-1", "verifier-one-verifier-one-verifier-one-1234")
+	require.NoError(t, err)
+	code, _ := authorize(t, authURL)
+	_, err = client.Exchange(context.Background(), cfg, code, "some-other-verifier-some-other-verifier-123", "nonce-1")
+	require.ErrorContains(t, err, "invalid_grant")
+
+	authURL, err = client.AuthCodeURL(context.Background(), cfg, "s", "nonce-1", "verifier-one-verifier-one-verifier-one-1234")
+	require.NoError(t, err)
+	code, _ = authorize(t, authURL)
+	_, err = client.Exchange(context.Background(), cfg, code, "verifier-one-verifier-one-verifier-one-1234", "nonce-2")
+	require.ErrorContains(t, err, "nonce mismatch")
+}
+
+func TestOIDCVerifyIDTokenValidatesClaims(t *testing.T) {
+	idp := newMockIdP(t)
+	client := NewOIDC(nil)
+	cfg := oidcConfig(idp)
+	doc, err := client.Discover(context.Background(), cfg.Issuer)
+	require.NoError(t, err)
+
+	cases := []struct {
+		name  string
+		extra map[string]any
+		want  string
+	}{
+		{name: "wrong audience", extra: map[string]any{"aud": "someone-else"}, want: "audience mismatch"},
+		{name: "wrong issuer", extra: map[string]any{"iss": "https://evil.example"}, want: "issuer mismatch"},
+		{name: "expired", extra: map[string]any{"exp": time.Now().Add(-time.Hour).Unix()}, want: "token expired"},
+		{name: "future iat", extra: map[string]any{"iat": time.Now().Add(time.Hour).Unix()}, want: "issued in the future"},
+		{name: "multiple audiences without azp", extra: map[string]any{"aud": []string{idp.ClientID, "other"}}, want: "authorized party"},
+		{name: "foreign azp", extra: map[string]any{"azp": "other"}, want: "authorized party mismatch"},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			token, err := idp.IDToken(ssotest.User{Subject: "u", Extra: tc.extra}, "n")
+			require.NoError(t, err)
+			_, err = client.VerifyIDToken(context.Background(), cfg, doc, token, "n")
+			require.ErrorContains(t, err, tc.want)
+		})
+	}
+
+	token, err := idp.IDToken(ssotest.User{Subject: "u"}, "n")
+	require.NoError(t, err)
+	parts := strings.Split(token, ".")
+	tampered := parts[0] + "." + parts[1][:len(parts[1])-2] + "AA." + parts[2]
+	_, err = client.VerifyIDToken(context.Background(), cfg, doc, tampered, "n")
+	require.Error(t, err)
+
+	unsigned := "eyJhbGciOiJub25lIn0." + parts[1] + "."
+	_, err = client.VerifyIDToken(context.Background(), cfg, doc, unsigned, "n")
+	require.ErrorContains(t, err, "unsupported signing algorithm")
+}
+
+func TestOIDCDiscoveryRequiresMatchingIssuer(t *testing.T) {
+	idp := newMockIdP(t)
+	client := NewOIDC(nil)
+	_, err := client.Discover(context.Background(), idp.Issuer+"/")
+	require.ErrorContains(t, err, "does not match configured issuer")
+}
+
+func TestJWKSCacheRefetchesOnKeyRotation(t *testing.T) {
+	idp := newMockIdP(t)
+	client := NewOIDC(nil)
+	now := time.Now()
+	client.Keys.Now = func() time.Time { return now }
+	cfg := oidcConfig(idp)
+	doc, err := client.Discover(context.Background(), cfg.Issuer)
+	require.NoError(t, err)
+
+	verify := func() error {
+		token, err := idp.IDToken(ssotest.User{Subject: "u"}, "")
+		require.NoError(t, err)
+		_, err = client.VerifyIDToken(context.Background(), cfg, doc, token, "")
+		return err
+	}
+	require.NoError(t, verify())
+	require.NoError(t, verify())
+	require.Equal(t, 1, idp.JWKSFetches())
+
+	// A new kid within MinRefresh of the last fetch is not trusted yet.
+	require.NoError(t, idp.RotateKey())
+	require.ErrorContains(t, verify(), "no signing key")
+	require.Equal(t, 1, idp.JWKSFetches())
+
+	now = now.Add(2 * time.Minute)
+	require.NoError(t, verify())
+	require.Equal(t, 2, idp.JWKSFetches())
+}
+
+func TestStringListAcceptsStringsAndArrays(t *testing.T) {
+	require.Equal(t, []string{"a", "b"}, stringList("a, b"))
+	require.Equal(t, []string{"a", "b"}, stringList([]any{"a", " b ", 3}))
+	require.Nil(t, stringList(nil))
+}
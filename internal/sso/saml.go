@@ -0,0 +1,293 @@
+package sso
+
+import (
+	"bytes"
+	"compress/flate"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+)
+
+const (
+	nsSAMLProtocol  = "urn:oasis:names:tc:SAML:2.0:protocol"
+	nsSAMLAssertion = "urn:oasis:names:tc:SAML:2.0:assertion"
+	samlStatusOK    = "urn:oasis:names:tc:SAML:2.0:status:Success"
+	samlBearer      = "urn:oasis:names:tc:SAML:2.0:cm:bearer"
+	samlBindingPOST = "urn:oasis:names:tc:SAML:2.0:bindings:HTTP-POST"
+	samlNameIDEmail = "urn:oasis:names:tc:SAML:1.1:nameid-format:emailAddress"
+	maxSAMLBytes    = 1 << 20
+)
+
+// SAMLConfig is one org's SAML 2.0 service provider registration.
+type SAMLConfig struct {
+	IdPEntityID       string
+	IdPSSOURL         string
+	IdPCertificatePEM string
+	SPEntityID        string
+	ACSURL            string
+	Claims            ClaimMapping
+}
+
+// SAMLRequest is an AuthnRequest ready to send with the HTTP-Redirect binding.
+type SAMLRequest struct {
+	ID  string
+	URL string
+}
+
+// NewSAMLRequest builds an AuthnRequest. The returned ID must be kept with the
+// login state and passed to ParseSAMLResponse as the expected InResponseTo.
+func NewSAMLRequest(cfg SAMLConfig, relayState string, now time.Time) (SAMLRequest, error) {
+	if strings.TrimSpace(cfg.IdPSSOURL) == "" {
+		return SAMLRequest{}, errorf("idp sso url is required")
+	}
+	id, err := randomID()
+	if err != nil {
+		return SAMLRequest{}, err
+	}
+	authn := fmt.Sprintf(
+		`<samlp:AuthnRequest xmlns:samlp="%s" xmlns:saml="%s" ID="%s" Version="2.0" IssueInstant="%s" Destination="%s" AssertionConsumerServiceURL="%s" ProtocolBinding="%s"><saml:Issuer>%s</saml:Issuer></samlp:AuthnRequest>`,
+		nsSAMLProtocol, nsSAMLAssertion, id, now.UTC().Format(time.RFC3339),
+		xmlEscape(cfg.IdPSSOURL), xmlEscape(cfg.ACSURL), samlBindingPOST, xmlEscape(cfg.SPEntityID),
+	)
+
+	var compressed bytes.Buffer
+	writer, err := flate.NewWriter(&compressed, flate.BestCompression)
+	if err != nil {
+		return SAMLRequest{}, err
+	}
+	if _, err := writer.Write([]byte(authn)); err != nil {
+		return SAMLRequest{}, err
+	}
+	if err := writer.Close(); err != nil {
+		return SAMLRequest{}, err
+	}
+
+	target, err := url.Parse(cfg.IdPSSOURL)
+	if err != nil {
+		return SAMLRequest{}, errorf("invalid idp sso url: %v", err)
+	}
+	q := target.Query()
+	q.Set("SAMLRequest", base64.StdEncoding.EncodeToString(compressed.Bytes()))
+	if relayState != "" {
+		q.Set("RelayState", relayState)
+	}
+	target.RawQuery = q.Encode()
+	return SAMLRequest{ID: id, URL: target.String()}, nil
+}
+
+// ParseSAMLResponse validates a base64 SAMLResponse from the HTTP-POST
+// binding and returns the asserted identity. Either the Response or the
+// Assertion must carry a valid signature from the configured certificate;
+// identity data is only read from the assertion once it is covered by one.
+func ParseSAMLResponse(cfg SAMLConfig, encoded, requestID string, now time.Time) (Identity, error) {
+	cert, err := parseCertificatePEM(cfg.IdPCertificatePEM)
+	if err != nil {
+		return Identity{}, err
+	}
+	raw, err := decodeXMLBase64(encoded)
+	if err != nil {
+		return Identity{}, errorf("SAMLResponse is not base64")
+	}
+	if len(raw) > maxSAMLBytes {
+		return Identity{}, errorf("SAMLResponse too large")
+	}
+	doc, err := parseXMLDocument(raw)
+	if err != nil {
+		return Identity{}, errorf("SAMLResponse: %v", err)
+	}
+	if !doc.is(nsSAMLProtocol, "Response") {
+		return Identity{}, errorf("SAMLResponse: root is not a protocol Response")
+	}
+	if doc.attr("Version") != "2.0" {
+		return Identity{}, errorf("SAMLResponse: unsupported version")
+	}
+	if doc.attr("InResponseTo") != requestID {
+		return Identity{}, errorf("SAMLResponse: InResponseTo does not match the login request")
+	}
+	if destination := doc.attr("Destination"); destination != "" && destination != cfg.ACSURL {
+		return Identity{}, errorf("SAMLResponse: destination mismatch")
+	}
+	if issuer := doc.child(nsSAMLAssertion, "Issuer"); issuer != nil && issuer.text() != cfg.IdPEntityID {
+		return Identity{}, errorf("SAMLResponse: issuer mismatch")
+	}
+	status := doc.child(nsSAMLProtocol, "Status")
+	if status == nil {
+		return Identity{}, errorf("SAMLResponse: missing status")
+	}
+	if code := status.child(nsSAMLProtocol, "StatusCode"); code == nil || code.attr("Value") != samlStatusOK {
+		return Identity{}, errorf("SAMLResponse: login was not successful")
+	}
+
+	if len(doc.children(nsSAMLAssertion, "EncryptedAssertion")) > 0 {
+		return Identity{}, errorf("SAMLResponse: encrypted assertions are not supported")
+	}
+	assertions := doc.children(nsSAMLAssertion, "Assertion")
+	if len(assertions) != 1 {
+		return Identity{}, errorf("SAMLResponse: expected exactly one assertion")
+	}
+	assertion := assertions[0]
+
+	responseErr := verifyEnvelopedSignature(doc, doc, cert)
+	if responseErr != nil && !errors.Is(responseErr, errNoSignature) {
+		return Identity{}, errorf("SAMLResponse signature: %v", responseErr)
+	}
+	assertionErr := verifyEnvelopedSignature(doc, assertion, cert)
+	if assertionErr != nil && !errors.Is(assertionErr, errNoSignature) {
+		return Identity{}, errorf("assertion signature: %v", assertionErr)
+	}
+	if responseErr != nil && assertionErr != nil {
+		return Identity{}, errorf("SAMLResponse is not signed")
+	}
+
+	return assertionIdentity(cfg, assertion, requestID, now)
+}
+
+func assertionIdentity(cfg SAMLConfig, assertion *xmlElement, requestID string, now time.Time) (Identity, error) {
+	issuer := assertion.child(nsSAMLAssertion, "Issuer")
+	if issuer == nil || issuer.text() != cfg.IdPEntityID {
+		return Identity{}, errorf("assertion: issuer mismatch")
+	}
+
+	subject := assertion.child(nsSAMLAssertion, "Subject")
+	if subject == nil {
+		return Identity{}, errorf("assertion: missing subject")
+	}
+	nameID := subject.child(nsSAMLAssertion, "NameID")
+	if nameID == nil || nameID.text() == "" {
+		return Identity{}, errorf("assertion: missing NameID")
+	}
+	confirmed := false
+	for _, confirmation := range subject.children(nsSAMLAssertion, "SubjectConfirmation") {
+		if confirmation.attr("Method") != samlBearer {
+			continue
+		}
+		data := confirmation.child(nsSAMLAssertion, "SubjectConfirmationData")
+		if data == nil {
+			continue
+		}
+		if data.attr("Recipient") != cfg.ACSURL || data.attr("InResponseTo") != requestID {
+			continue
+		}
+		notOnOrAfter, err := time.Parse(time.RFC3339, data.attr("NotOnOrAfter"))
+		if err != nil || !now.Before(notOnOrAfter.Add(clockSkew)) {
+			continue
+		}
+		confirmed = true
+		break
+	}
+	if !confirmed {
+		return Identity{}, errorf("assertion: no valid bearer subject confirmation")
+	}
+
+	conditions := assertion.child(nsSAMLAssertion, "Conditions")
+	if conditions == nil {
+		return Identity{}, errorf("assertion: missing conditions")
+	}
+	if value := conditions.attr("NotBefore"); value != "" {
+		notBefore, err := time.Parse(time.RFC3339, value)
+		if err != nil || now.Add(clockSkew).Before(notBefore) {
+			return Identity{}, errorf("assertion: not yet valid")
+		}
+	}
+	if value := conditions.attr("NotOnOrAfter"); value != "" {
+		notOnOrAfter, err := time.Parse(time.RFC3339, value)
+		if err != nil || !now.Before(notOnOrAfter.Add(clockSkew)) {
+			return Identity{}, errorf("assertion: expired")
+		}
+	}
+	// Every AudienceRestriction must name this SP, and there must be one.
+	restrictions := conditions.children(nsSAMLAssertion, "AudienceRestriction")
+	if len(restrictions) == 0 {
+		return Identity{}, errorf("assertion: missing audience restriction")
+	}
+	for _, restriction := range restrictions {
+		matched := false
+		for _, audience := range restriction.children(nsSAMLAssertion, "Audience") {
+			if audience.text() == cfg.SPEntityID {
+				matched = true
+			}
+		}
+		if !matched {
+			return Identity{}, errorf("assertion: audience mismatch")
+		}
+	}
+
+	mapping := cfg.Claims.withDefaults()
+	attributes := map[string][]string{}
+	if statement := assertion.child(nsSAMLAssertion, "AttributeStatement"); statement != nil {
+		for _, attribute := range statement.children(nsSAMLAssertion, "Attribute") {
+			name := attribute.attr("Name")
+			for _, value := range attribute.children(nsSAMLAssertion, "AttributeValue") {
+				if text := value.text(); text != "" {
+					attributes[name] = append(attributes[name], text)
+				}
+			}
+		}
+	}
+	first := func(name string) string {
+		if values := attributes[name]; len(values) > 0 {
+			return values[0]
+		}
+		return ""
+	}
+
+	identity := Identity{
+		Issuer:  cfg.IdPEntityID,
+		Subject: nameID.text(),
+		Email:   strings.ToLower(first(mapping.Email)),
+		Name:    first(mapping.Name),
+		Groups:  attributes[mapping.Groups],
+	}
+	if identity.Email == "" && nameID.attr("Format") == samlNameIDEmail {
+		identity.Email = strings.ToLower(nameID.text())
+	}
+	return identity, nil
+}
+
+// SAMLMetadata renders SP metadata for the IdP administrator to import.
+func SAMLMetadata(cfg SAMLConfig) []byte {
+	return []byte(fmt.Sprintf(
+		`<?xml version="1.0" encoding="UTF-8"?>
+<md:EntityDescriptor xmlns:md="urn:oasis:names:tc:SAML:2.0:metadata" entityID="%s">
+  <md:SPSSODescriptor AuthnRequestsSigned="false" WantAssertionsSigned="true" protocolSupportEnumeration="%s">
+    <md:NameIDFormat>%s</md:NameIDFormat>
+    <md:AssertionConsumerService Binding="%s" Location="%s" index="0" isDefault="true"/>
+  </md:SPSSODescriptor>
+</md:EntityDescriptor>
+`, xmlEscape(cfg.SPEntityID), nsSAMLProtocol, samlNameIDEmail, samlBindingPOST, xmlEscape(cfg.ACSURL)))
+}
+
+func parseCertificatePEM(value string) (*x509.Certificate, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return nil, errorf("idp certificate is required")
+	}
+	if !strings.HasPrefix(value, "-----BEGIN") {
+		// Accept the bare base64 body that IdP metadata usually shows.
+		value = "-----BEGIN CERTIFICATE-----\n" + value + "\n-----END CERTIFICATE-----"
+	}
+	block, _ := pem.Decode([]byte(value))
+	if block == nil || block.Type != "CERTIFICATE" {
+		return nil, errorf("idp certificate is not a PEM certificate")
+	}
+	cert, err := x509.ParseCertificate(block.Bytes)
+	if err != nil {
+		return nil, errorf("idp certificate: %v", err)
+	}
+	return cert, nil
+}
+
+// ValidateCertificatePEM reports whether value is a usable IdP certificate.
+func ValidateCertificatePEM(value string) error {
+	_, err := parseCertificatePEM(value)
+	return err
+}
+
+func xmlEscape(value string) string {
+	return strings.ReplaceAll(escapeC14NAttr(value), ">", "&gt;")
+}
@@ -0,0 +1,149 @@
+package sso
+
+import (
+	"encoding/base64"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/samhotchkiss/otter-camp/internal/sso/ssotest"
+	"github.com/stretchr/testify/require"
+)
+
+func samlConfig(idp *ssotest.IdP) SAMLConfig {
+	return SAMLConfig{
+		IdPEntityID:       idp.EntityID,
+		IdPSSOURL:         idp.SSOURL,
+		IdPCertificatePEM: idp.CertPEM,
+		SPEntityID:        "https://otter.example/api/auth/sso/acme/saml/metadata",
+		ACSURL:            "https://otter.example/api/auth/sso/saml/acs",
+	}
+}
+
+func samlOptions(cfg SAMLConfig, requestID string) ssotest.SAMLOptions {
+	return ssotest.SAMLOptions{InResponseTo: requestID, ACSURL: cfg.ACSURL, Audience: cfg.SPEntityID}
+}
+
+func TestSAMLRequestRoundTripsThroughMockIdP(t *testing.T) {
+	idp := newMockIdP(t)
+	cfg := samlConfig(idp)
+
+	request, err := NewSAMLRequest(cfg, "relay-1", time.Now())
+	require.NoError(t, err)
+	parsed, err := url.Parse(request.URL)
+	require.NoError(t, err)
+	require.Equal(t, "relay-1", parsed.Query().Get("RelayState"))
+
+	authn, err := ssotest.DecodeAuthnRequest(parsed.Query().Get("SAMLRequest"))
+	require.NoError(t, err)
+	require.Equal(t, request.ID, authn.ID)
+	require.Equal(t, cfg.ACSURL, authn.ACSURL)
+	require.Equal(t, cfg.SPEntityID, authn.Issuer)
+}
+
+func TestParseSAMLResponseAcceptsSignedAssertion(t *testing.T) {
+	idp := newMockIdP(t)
+	idp.SetUser(ssotest.User{Subject: "nameid-7", Email: "Ops@Example.com", Name: "Ops", Groups: []string{"sre", "eng"}})
+	cfg := samlConfig(idp)
+
+	for _, signResponse := range []bool{false, true} {
+		encoded, err := idp.SAMLResponse(ssotest.SAMLOptions{
+			InResponseTo: "_req1", ACSURL: cfg.ACSURL, Audience: cfg.SPEntityID, SignResponse: signResponse,
+		})
+		require.NoError(t, err)
+		identity, err := ParseSAMLResponse(cfg, encoded, "_req1", time.Now())
+		require.NoError(t, err)
+		require.Equal(t, idp.EntityID, identity.Issuer)
+		require.Equal(t, "nameid-7", identity.Subject)
+		require.Equal(t, "ops@example.com", identity.Email)
+		require.Equal(t, "Ops", identity.Name)
+		require.Equal(t, []string{"sre", "eng"}, identity.Groups)
+	}
+
+	encoded, err := idp.SAMLResponse(ssotest.SAMLOptions{
+		InResponseTo: "_req1", ACSURL: cfg.ACSURL, Audience: cfg.SPEntityID, SignResponse: true, UnsignedAssertion: true,
+	})
+	require.NoError(t, err)
+	_, err = ParseSAMLResponse(cfg, encoded, "_req1", time.Now())
+	require.NoError(t, err)
+}
+
+func TestParseSAMLResponseRejectsInvalidResponses(t *testing.T) {
+	idp := newMockIdP(t)
+	cfg := samlConfig(idp)
+
+	signed := func(opts ssotest.SAMLOptions) string {
+		encoded, err := idp.SAMLResponse(opts)
+		require.NoError(t, err)
+		return encoded
+	}
+	tamper := func(encoded, from, to string) string {
+		raw, err := base64.StdEncoding.DecodeString(encoded)
+		require.NoError(t, err)
+		require.Contains(t, string(raw), from)
+		return base64.StdEncoding.EncodeToString([]byte(strings.Replace(string(raw), from, to, 1)))
+	}
+
+	unsigned := ssotest.SAMLOptions{InResponseTo: "_req1", ACSURL: cfg.ACSURL, Audience: cfg.SPEntityID, UnsignedAssertion: true}
+	wrongAudience := samlOptions(cfg, "_req1")
+	wrongAudience.Audience = "https://someone-else.example"
+
+	other, err := ssotest.New()
+	require.NoError(t, err)
+	defer other.Close()
+	otherCfg := cfg
+	otherCfg.IdPCertificatePEM = other.CertPEM
+
+	cases := []struct {
+		name      string
+		cfg       SAMLConfig
+		encoded   string
+		requestID string
+		now       time.Time
+		want      string
+	}{
+		{name: "unsigned", cfg: cfg, encoded: signed(unsigned), requestID: "_req1", want: "not signed"},
+		{name: "tampered attribute", cfg: cfg, encoded: tamper(signed(samlOptions(cfg, "_req1")), "sam@example.com", "root@example.com"), requestID: "_req1", want: "digest mismatch"},
+		{name: "wrong certificate", cfg: otherCfg, encoded: signed(samlOptions(cfg, "_req1")), requestID: "_req1", want: "signature verification failed"},
+		{name: "wrong request", cfg: cfg, encoded: signed(samlOptions(cfg, "_req1")), requestID: "_req2", want: "InResponseTo"},
+		{name: "wrong audience", cfg: cfg, encoded: signed(wrongAudience), requestID: "_req1", want: "audience mismatch"},
+		{name: "expired", cfg: cfg, encoded: signed(samlOptions(cfg, "_req1")), requestID: "_req1", now: time.Now().Add(time.Hour), want: "subject confirmation"},
+		{name: "doctype", cfg: cfg, encoded: tamper(signed(samlOptions(cfg, "_req1")), "<samlp:Response", "<!DOCTYPE x><samlp:Response"), requestID: "_req1", want: "DOCTYPE"},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			now := tc.now
+			if now.IsZero() {
+				now = time.Now()
+			}
+			_, err := ParseSAMLResponse(tc.cfg, tc.encoded, tc.requestID, now)
+			require.ErrorContains(t, err, tc.want)
+		})
+	}
+}
+
+func TestParseSAMLResponseRejectsWrappedAssertion(t *testing.T) {
+	idp := newMockIdP(t)
+	cfg := samlConfig(idp)
+	encoded, err := idp.SAMLResponse(samlOptions(cfg, "_req1"))
+	require.NoError(t, err)
+	raw, err := base64.StdEncoding.DecodeString(encoded)
+	require.NoError(t, err)
+
+	// Duplicate the signed assertion with a forged copy in front of it: the
+	// verifier must not accept a second assertion alongside the signed one.
+	doc := string(raw)
+	start := strings.Index(doc, "<saml:Assertion")
+	end := strings.Index(doc, "</saml:Assertion>") + len("</saml:Assertion>")
+	forged := strings.Replace(doc[start:end], "sam@example.com", "root@example.com", 1)
+	wrapped := doc[:start] + forged + doc[start:]
+	_, err = ParseSAMLResponse(cfg, base64.StdEncoding.EncodeToString([]byte(wrapped)), "_req1", time.Now())
+	require.ErrorContains(t, err, "exactly one assertion")
+}
+
+func TestSAMLMetadataNamesACS(t *testing.T) {
+	metadata := string(SAMLMetadata(SAMLConfig{SPEntityID: "https://sp.example/meta?a=1&b=2", ACSURL: "https://sp.example/acs"}))
+	require.Contains(t, metadata, `entityID="https://sp.example/meta?a=1&amp;b=2"`)
+	require.Contains(t, metadata, `Location="https://sp.example/acs"`)
+}
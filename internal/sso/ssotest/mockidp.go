@@ -0,0 +1,567 @@
+// Package ssotest provides a local mock identity provider for exercising the
+// OIDC and SAML login flows in tests and during development. It builds its
+// tokens and signed XML independently of package sso, so round trips through
+// it check sso's validation rather than echoing it.
+package ssotest
+
+import (
+	"bytes"
+	"compress/flate"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"html"
+	"io"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	nsProtocol  = "urn:oasis:names:tc:SAML:2.0:protocol"
+	nsAssertion = "urn:oasis:names:tc:SAML:2.0:assertion"
+	nsDSig      = "http://www.w3.org/2000/09/xmldsig#"
+)
+
+// User is the identity the mock IdP logs in as.
+type User struct {
+	Subject string
+	Email   string
+	Name    string
+	Groups  []string
+	// Extra claims are merged into the ID token last, so they can override
+	// (or, set to nil, remove) any standard claim.
+	Extra map[string]any
+}
+
+// IdP is a mock OIDC provider and SAML IdP backed by an httptest server.
+type IdP struct {
+	Server       *httptest.Server
+	Issuer       string
+	ClientID     string
+	ClientSecret string
+	EntityID     string
+	SSOURL       string
+	CertPEM      string
+	Now          func() time.Time
+
+	mu          sync.Mutex
+	key         *rsa.PrivateKey
+	keyID       string
+	cert        *x509.Certificate
+	user        User
+	codes       map[string]authorization
+	jwksFetches atomic.Int64
+}
+
+type authorization struct {
+	clientID    string
+	redirectURI string
+	nonce       string
+	challenge   string
+	user        User
+}
+
+// New starts a mock IdP. Close it when done.
+func New() (*IdP, error) {
+	idp := &IdP{
+		ClientID:     "otter-camp-test",
+		ClientSecret: "test-secret",
+		codes:        map[string]authorization{},
+		user: User{
+			Subject: "user-1",
+			Email:   "sam@example.com",
+			Name:    "Sam Example",
+		},
+	}
+	if err := idp.RotateKey(); err != nil {
+		return nil, err
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", idp.handleDiscovery)
+	mux.HandleFunc("/jwks", idp.handleJWKS)
+	mux.HandleFunc("/authorize", idp.handleAuthorize)
+	mux.HandleFunc("/token", idp.handleToken)
+	mux.HandleFunc("/saml/sso", idp.handleSAMLSSO)
+	idp.Server = httptest.NewServer(mux)
+	idp.Issuer = idp.Server.URL
+	idp.EntityID = idp.Server.URL + "/saml/metadata"
+	idp.SSOURL = idp.Server.URL + "/saml/sso"
+	return idp, nil
+}
+
+func (idp *IdP) Close() { idp.Server.Close() }
+
+// SetUser changes who the next authorization logs in as.
+func (idp *IdP) SetUser(user User) {
+	idp.mu.Lock()
+	defer idp.mu.Unlock()
+	idp.user = user
+}
+
+// JWKSFetches reports how often the key set was downloaded.
+func (idp *IdP) JWKSFetches() int { return int(idp.jwksFetches.Load()) }
+
+// RotateKey replaces the signing key (and SAML certificate) with a new one
+// under a new kid.
+func (idp *IdP) RotateKey() error {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		return err
+	}
+	serial, err := rand.Int(rand.Reader, big.NewInt(1<<62))
+	if err != nil {
+		return err
+	}
+	template := &x509.Certificate{
+		SerialNumber: serial,
+		Subject:      pkix.Name{CommonName: "ssotest mock idp"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(24 * time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		return err
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		return err
+	}
+
+	idp.mu.Lock()
+	defer idp.mu.Unlock()
+	idp.key = key
+	idp.keyID = fmt.Sprintf("key-%d", serial.Int64()%100000)
+	idp.cert = cert
+	idp.CertPEM = string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}))
+	return nil
+}
+
+func (idp *IdP) now() time.Time {
+	if idp.Now != nil {
+		return idp.Now()
+	}
+	return time.Now()
+}
+
+func (idp *IdP) handleDiscovery(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, map[string]any{
+		"issuer":                                idp.Issuer,
+		"authorization_endpoint":                idp.Issuer + "/authorize",
+		"token_endpoint":                        idp.Issuer + "/token",
+		"jwks_uri":                              idp.Issuer + "/jwks",
+		"response_types_supported":              []string{"code"},
+		"subject_types_supported":               []string{"public"},
+		"id_token_signing_alg_values_supported": []string{"RS256"},
+		"code_challenge_methods_supported":      []string{"S256"},
+		"token_endpoint_auth_methods_supported": []string{"client_secret_basic", "client_secret_post"},
+	})
+}
+
+func (idp *IdP) handleJWKS(w http.ResponseWriter, r *http.Request) {
+	idp.jwksFetches.Add(1)
+	idp.mu.Lock()
+	pub := idp.key.PublicKey
+	kid := idp.keyID
+	idp.mu.Unlock()
+	writeJSON(w, http.StatusOK, map[string]any{"keys": []map[string]string{{
+		"kty": "RSA",
+		"use": "sig",
+		"alg": "RS256",
+		"kid": kid,
+		"n":   base64.RawURLEncoding.EncodeToString(pub.N.Bytes()),
+		"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(pub.E)).Bytes()),
+	}}})
+}
+
+func (idp *IdP) handleAuthorize(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	if q.Get("response_type") != "code" || q.Get("client_id") != idp.ClientID {
+		http.Error(w, "invalid authorization request", http.StatusBadRequest)
+		return
+	}
+	if q.Get("code_challenge") == "" || q.Get("code_challenge_method") != "S256" {
+		http.Error(w, "PKCE S256 is required", http.StatusBadRequest)
+		return
+	}
+	if !strings.Contains(" "+q.Get("scope")+" ", " openid ") {
+		http.Error(w, "openid scope is required", http.StatusBadRequest)
+		return
+	}
+	redirect, err := url.Parse(q.Get("redirect_uri"))
+	if err != nil || redirect.Scheme == "" {
+		http.Error(w, "invalid redirect_uri", http.StatusBadRequest)
+		return
+	}
+
+	code := randomString()
+	idp.mu.Lock()
+	idp.codes[code] = authorization{
+		clientID:    q.Get("client_id"),
+		redirectURI: q.Get("redirect_uri"),
+		nonce:       q.Get("nonce"),
+		challenge:   q.Get("code_challenge"),
+		user:        idp.user,
+	}
+	idp.mu.Unlock()
+
+	params := redirect.Query()
+	params.Set("code", code)
+	params.Set("state", q.Get("state"))
+	redirect.RawQuery = params.Encode()
+	http.Redirect(w, r, redirect.String(), http.StatusFound)
+}
+
+func (idp *IdP) handleToken(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+	if err := r.ParseForm(); err != nil {
+		tokenError(w, "invalid_request")
+		return
+	}
+	clientID, secret, ok := r.BasicAuth()
+	if ok {
+		clientID, _ = url.QueryUnescape(clientID)
+		secret, _ = url.QueryUnescape(secret)
+	} else {
+		clientID, secret = r.PostForm.Get("client_id"), r.PostForm.Get("client_secret")
+	}
+	if clientID != idp.ClientID || secret != idp.ClientSecret {
+		writeJSON(w, http.StatusUnauthorized, map[string]string{"error": "invalid_client"})
+		return
+	}
+	if r.PostForm.Get("grant_type") != "authorization_code" {
+		tokenError(w, "unsupported_grant_type")
+		return
+	}
+
+	code := r.PostForm.Get("code")
+	idp.mu.Lock()
+	auth, found := idp.codes[code]
+	delete(idp.codes, code)
+	idp.mu.Unlock()
+	if !found || auth.clientID != clientID || auth.redirectURI != r.PostForm.Get("redirect_uri") {
+		tokenError(w, "invalid_grant")
+		return
+	}
+	sum := sha256.Sum256([]byte(r.PostForm.Get("code_verifier")))
+	if base64.RawURLEncoding.EncodeToString(sum[:]) != auth.challenge {
+		tokenError(w, "invalid_grant")
+		return
+	}
+
+	idToken, err := idp.IDToken(auth.user, auth.nonce)
+	if err != nil {
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "server_error"})
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]any{
+		"access_token": randomString(),
+		"token_type":   "Bearer",
+		"expires_in":   300,
+		"id_token":     idToken,
+	})
+}
+
+// IDToken signs an ID token for user with the current key.
+func (idp *IdP) IDToken(user User, nonce string) (string, error) {
+	now := idp.now()
+	claims := map[string]any{
+		"iss":            idp.Issuer,
+		"sub":            user.Subject,
+		"aud":            idp.ClientID,
+		"iat":            now.Unix(),
+		"exp":            now.Add(5 * time.Minute).Unix(),
+		"email":          user.Email,
+		"email_verified": true,
+		"name":           user.Name,
+	}
+	if nonce != "" {
+		claims["nonce"] = nonce
+	}
+	if len(user.Groups) > 0 {
+		claims["groups"] = user.Groups
+	}
+	for name, value := range user.Extra {
+		if value == nil {
+			delete(claims, name)
+			continue
+		}
+		claims[name] = value
+	}
+
+	idp.mu.Lock()
+	key, kid := idp.key, idp.keyID
+	idp.mu.Unlock()
+
+	header, _ := json.Marshal(map[string]string{"alg": "RS256", "typ": "JWT", "kid": kid})
+	payload, err := json.Marshal(claims)
+	if err != nil {
+		return "", err
+	}
+	signingInput := base64.RawURLEncoding.EncodeToString(header) + "." + base64.RawURLEncoding.EncodeToString(payload)
+	digest := sha256.Sum256([]byte(signingInput))
+	signature, err := rsa.SignPKCS1v15(rand.Reader, key, crypto.SHA256, digest[:])
+	if err != nil {
+		return "", err
+	}
+	return signingInput + "." + base64.RawURLEncoding.EncodeToString(signature), nil
+}
+
+// SAMLOptions describes the SAML Response to build.
+type SAMLOptions struct {
+	InResponseTo string
+	ACSURL       string
+	Audience     string
+	User         *User
+	// SignResponse adds a signature over the whole Response in addition to
+	// the assertion signature; UnsignedAssertion drops the latter.
+	SignResponse      bool
+	UnsignedAssertion bool
+}
+
+// SAMLResponse returns a base64 SAMLResponse as posted to the ACS.
+func (idp *IdP) SAMLResponse(opts SAMLOptions) (string, error) {
+	idp.mu.Lock()
+	user := idp.user
+	idp.mu.Unlock()
+	if opts.User != nil {
+		user = *opts.User
+	}
+	now := idp.now().UTC()
+	responseID := "_r" + randomHex()
+	assertionID := "_a" + randomHex()
+
+	assertionSig := ""
+	if !opts.UnsignedAssertion {
+		sig, err := idp.xmlSignature(assertionID, idp.assertionXML(true, assertionID, "", opts, user, now))
+		if err != nil {
+			return "", err
+		}
+		assertionSig = sig
+	}
+	responseSig := ""
+	if opts.SignResponse {
+		canonical := idp.responseXML(true, responseID, "", idp.assertionXML(true, assertionID, assertionSig, opts, user, now), opts, now)
+		sig, err := idp.xmlSignature(responseID, canonical)
+		if err != nil {
+			return "", err
+		}
+		responseSig = sig
+	}
+	doc := idp.responseXML(false, responseID, responseSig, idp.assertionXML(false, assertionID, assertionSig, opts, user, now), opts, now)
+	return base64.StdEncoding.EncodeToString([]byte(doc)), nil
+}
+
+// The XML below is written by hand. With canonical=true it is exactly the
+// Exclusive C14N form that signatures are computed over; with canonical=false
+// it is the "as sent" document, which hoists namespace declarations to the
+// root and uses empty-element tags, so the verifier has to canonicalize.
+func (idp *IdP) responseXML(canonical bool, id, signature, assertion string, opts SAMLOptions, now time.Time) string {
+	var b strings.Builder
+	if canonical {
+		b.WriteString(`<samlp:Response xmlns:samlp="` + nsProtocol + `"`)
+	} else {
+		b.WriteString(`<?xml version="1.0" encoding="UTF-8"?>` + "\n")
+		b.WriteString(`<samlp:Response xmlns:saml="` + nsAssertion + `" xmlns:samlp="` + nsProtocol + `"`)
+	}
+	b.WriteString(` Destination="` + attr(opts.ACSURL) + `" ID="` + id + `" InResponseTo="` + attr(opts.InResponseTo) + `" IssueInstant="` + now.Format(time.RFC3339) + `" Version="2.0">`)
+	b.WriteString(`<saml:Issuer` + samlNS(canonical) + `>` + text(idp.EntityID) + `</saml:Issuer>`)
+	b.WriteString(signature)
+	b.WriteString(`<samlp:Status>` + emptyElement(canonical, "samlp:StatusCode", `Value="urn:oasis:names:tc:SAML:2.0:status:Success"`) + `</samlp:Status>`)
+	b.WriteString(assertion)
+	b.WriteString(`</samlp:Response>`)
+	return b.String()
+}
+
+func (idp *IdP) assertionXML(canonical bool, id, signature string, opts SAMLOptions, user User, now time.Time) string {
+	expires := now.Add(5 * time.Minute).Format(time.RFC3339)
+	var b strings.Builder
+	b.WriteString(`<saml:Assertion` + samlNS(canonical) + ` ID="` + id + `" IssueInstant="` + now.Format(time.RFC3339) + `" Version="2.0">`)
+	b.WriteString(`<saml:Issuer>` + text(idp.EntityID) + `</saml:Issuer>`)
+	b.WriteString(signature)
+	b.WriteString(`<saml:Subject>`)
+	b.WriteString(`<saml:NameID Format="urn:oasis:names:tc:SAML:1.1:nameid-format:unspecified">` + text(user.Subject) + `</saml:NameID>`)
+	b.WriteString(`<saml:SubjectConfirmation Method="urn:oasis:names:tc:SAML:2.0:cm:bearer">`)
+	b.WriteString(emptyElement(canonical, "saml:SubjectConfirmationData",
+		`InResponseTo="`+attr(opts.InResponseTo)+`" NotOnOrAfter="`+expires+`" Recipient="`+attr(opts.ACSURL)+`"`))
+	b.WriteString(`</saml:SubjectConfirmation></saml:Subject>`)
+	b.WriteString(`<saml:Conditions NotBefore="` + now.Add(-time.Minute).Format(time.RFC3339) + `" NotOnOrAfter="` + expires + `">`)
+	b.WriteString(`<saml:AudienceRestriction><saml:Audience>` + text(opts.Audience) + `</saml:Audience></saml:AudienceRestriction>`)
+	b.WriteString(`</saml:Conditions>`)
+
+	attributes := map[string][]string{}
+	if user.Email != "" {
+		attributes["email"] = []string{user.Email}
+	}
+	if user.Name != "" {
+		attributes["name"] = []string{user.Name}
+	}
+	if len(user.Groups) > 0 {
+		attributes["groups"] = user.Groups
+	}
+	for name, value := range user.Extra {
+		if s, ok := value.(string); ok {
+			attributes[name] = []string{s}
+		}
+	}
+	names := make([]string, 0, len(attributes))
+	for name := range attributes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	b.WriteString(`<saml:AttributeStatement>`)
+	for _, name := range names {
+		b.WriteString(`<saml:Attribute Name="` + attr(name) + `">`)
+		for _, value := range attributes[name] {
+			b.WriteString(`<saml:AttributeValue>` + text(value) + `</saml:AttributeValue>`)
+		}
+		b.WriteString(`</saml:Attribute>`)
+	}
+	b.WriteString(`</saml:AttributeStatement></saml:Assertion>`)
+	return b.String()
+}
+
+// xmlSignature returns an enveloped ds:Signature over canonical, the C14N
+// form of the element with the given ID.
+func (idp *IdP) xmlSignature(id, canonical string) (string, error) {
+	digest := sha256.Sum256([]byte(canonical))
+	signedInfo := `<ds:SignedInfo>` +
+		`<ds:CanonicalizationMethod Algorithm="http://www.w3.org/2001/10/xml-exc-c14n#"></ds:CanonicalizationMethod>` +
+		`<ds:SignatureMethod Algorithm="http://www.w3.org/2001/04/xmldsig-more#rsa-sha256"></ds:SignatureMethod>` +
+		`<ds:Reference URI="#` + id + `"><ds:Transforms>` +
+		`<ds:Transform Algorithm="http://www.w3.org/2000/09/xmldsig#enveloped-signature"></ds:Transform>` +
+		`<ds:Transform Algorithm="http://www.w3.org/2001/10/xml-exc-c14n#"></ds:Transform>` +
+		`</ds:Transforms>` +
+		`<ds:DigestMethod Algorithm="http://www.w3.org/2001/04/xmlenc#sha256"></ds:DigestMethod>` +
+		`<ds:DigestValue>` + base64.StdEncoding.EncodeToString(digest[:]) + `</ds:DigestValue>` +
+		`</ds:Reference></ds:SignedInfo>`
+	// SignedInfo is canonicalized on its own, so it carries the ds binding
+	// that the document only declares on ds:Signature.
+	canonicalSignedInfo := strings.Replace(signedInfo, `<ds:SignedInfo>`, `<ds:SignedInfo xmlns:ds="`+nsDSig+`">`, 1)
+
+	idp.mu.Lock()
+	key, cert := idp.key, idp.cert
+	idp.mu.Unlock()
+	sum := sha256.Sum256([]byte(canonicalSignedInfo))
+	signature, err := rsa.SignPKCS1v15(rand.Reader, key, crypto.SHA256, sum[:])
+	if err != nil {
+		return "", err
+	}
+	return `<ds:Signature xmlns:ds="` + nsDSig + `">` + signedInfo +
+		`<ds:SignatureValue>` + base64.StdEncoding.EncodeToString(signature) + `</ds:SignatureValue>` +
+		`<ds:KeyInfo><ds:X509Data><ds:X509Certificate>` + base64.StdEncoding.EncodeToString(cert.Raw) + `</ds:X509Certificate></ds:X509Data></ds:KeyInfo>` +
+		`</ds:Signature>`, nil
+}
+
+// handleSAMLSSO answers an HTTP-Redirect AuthnRequest with an auto-submitting
+// HTTP-POST form, like a real IdP after the user has signed in.
+func (idp *IdP) handleSAMLSSO(w http.ResponseWriter, r *http.Request) {
+	request, err := DecodeAuthnRequest(r.URL.Query().Get("SAMLRequest"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	response, err := idp.SAMLResponse(SAMLOptions{
+		InResponseTo: request.ID,
+		ACSURL:       request.ACSURL,
+		Audience:     request.Issuer,
+	})
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	fmt.Fprintf(w, `<!doctype html><html><body onload="document.forms[0].submit()"><form method="post" action="%s"><input type="hidden" name="SAMLResponse" value="%s"><input type="hidden" name="RelayState" value="%s"><noscript><button type="submit">Continue</button></noscript></form></body></html>`,
+		html.EscapeString(request.ACSURL), html.EscapeString(response), html.EscapeString(r.URL.Query().Get("RelayState")))
+}
+
+// AuthnRequest is the part of an SP's AuthnRequest the mock IdP reads.
+type AuthnRequest struct {
+	ID     string `xml:"ID,attr"`
+	ACSURL string `xml:"AssertionConsumerServiceURL,attr"`
+	Issuer string `xml:"urn:oasis:names:tc:SAML:2.0:assertion Issuer"`
+}
+
+// DecodeAuthnRequest reads a deflated, base64 HTTP-Redirect SAMLRequest.
+func DecodeAuthnRequest(encoded string) (AuthnRequest, error) {
+	compressed, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return AuthnRequest{}, errors.New("SAMLRequest is not base64")
+	}
+	raw, err := io.ReadAll(io.LimitReader(flate.NewReader(bytes.NewReader(compressed)), 1<<20))
+	if err != nil {
+		return AuthnRequest{}, errors.New("SAMLRequest is not deflated")
+	}
+	var request AuthnRequest
+	if err := xml.Unmarshal(raw, &request); err != nil {
+		return AuthnRequest{}, err
+	}
+	if request.ID == "" {
+		return AuthnRequest{}, errors.New("AuthnRequest has no ID")
+	}
+	return request, nil
+}
+
+func samlNS(canonical bool) string {
+	if canonical {
+		return ` xmlns:saml="` + nsAssertion + `"`
+	}
+	return ""
+}
+
+func emptyElement(canonical bool, name, attrs string) string {
+	if canonical {
+		return "<" + name + " " + attrs + "></" + name + ">"
+	}
+	return "<" + name + " " + attrs + "/>"
+}
+
+var (
+	textEscaper = strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;")
+	attrEscaper = strings.NewReplacer("&", "&amp;", "<", "&lt;", `"`, "&quot;")
+)
+
+func text(value string) string { return textEscaper.Replace(value) }
+func attr(value string) string { return attrEscaper.Replace(value) }
+
+func randomString() string {
+	buf := make([]byte, 24)
+	_, _ = rand.Read(buf)
+	return base64.RawURLEncoding.EncodeToString(buf)
+}
+
+func randomHex() string {
+	buf := make([]byte, 16)
+	_, _ = rand.Read(buf)
+	return fmt.Sprintf("%x", buf)
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+func tokenError(w http.ResponseWriter, code string) {
+	writeJSON(w, http.StatusBadRequest, map[string]string{"error": code})
+}
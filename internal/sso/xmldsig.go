@@ -0,0 +1,474 @@
+package sso
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/rsa"
+	"crypto/subtle"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"sort"
+	"strings"
+)
+
+// XML signature support is intentionally narrow: it covers what SAML IdPs
+// actually emit (one enveloped signature per signed element, exclusive
+// canonicalization, RSA/ECDSA with SHA-2) and rejects everything else.
+const (
+	nsXML          = "http://www.w3.org/XML/1998/namespace"
+	nsDSig         = "http://www.w3.org/2000/09/xmldsig#"
+	algExcC14N     = "http://www.w3.org/2001/10/xml-exc-c14n#"
+	algEnveloped   = "http://www.w3.org/2000/09/xmldsig#enveloped-signature"
+	algSHA256      = "http://www.w3.org/2001/04/xmlenc#sha256"
+	algSHA512      = "http://www.w3.org/2001/04/xmlenc#sha512"
+	algRSASHA256   = "http://www.w3.org/2001/04/xmldsig-more#rsa-sha256"
+	algRSASHA512   = "http://www.w3.org/2001/04/xmldsig-more#rsa-sha512"
+	algECDSASHA256 = "http://www.w3.org/2001/04/xmldsig-more#ecdsa-sha256"
+	maxXMLDepth    = 64
+	maxXMLElements = 10000
+)
+
+var errNoSignature = errors.New("element is not signed")
+
+// xmlElement is a minimal DOM that keeps namespace prefixes as written, which
+// encoding/xml's Unmarshal does not, and canonicalization needs.
+type xmlElement struct {
+	Prefix   string
+	Local    string
+	Attrs    []xmlAttr
+	NSDecls  map[string]string
+	Children []xmlNode
+	Parent   *xmlElement
+}
+
+type xmlAttr struct {
+	Prefix string
+	Local  string
+	Value  string
+}
+
+// xmlNode is either an element or character data.
+type xmlNode struct {
+	Element *xmlElement
+	Text    string
+}
+
+func parseXMLDocument(data []byte) (*xmlElement, error) {
+	decoder := xml.NewDecoder(bytes.NewReader(data))
+	decoder.Strict = true
+	var root, current *xmlElement
+	depth, count := 0, 0
+	for {
+		token, err := decoder.RawToken()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		switch tok := token.(type) {
+		case xml.StartElement:
+			depth++
+			count++
+			if depth > maxXMLDepth || count > maxXMLElements {
+				return nil, errors.New("xml document too large")
+			}
+			if current == nil && root != nil {
+				return nil, errors.New("xml document has multiple root elements")
+			}
+			el := &xmlElement{Prefix: tok.Name.Space, Local: tok.Name.Local, NSDecls: map[string]string{}, Parent: current}
+			for _, attr := range tok.Attr {
+				switch {
+				case attr.Name.Space == "" && attr.Name.Local == "xmlns":
+					el.NSDecls[""] = attr.Value
+				case attr.Name.Space == "xmlns":
+					el.NSDecls[attr.Name.Local] = attr.Value
+				default:
+					el.Attrs = append(el.Attrs, xmlAttr{Prefix: attr.Name.Space, Local: attr.Name.Local, Value: attr.Value})
+				}
+			}
+			if current == nil {
+				root = el
+			} else {
+				current.Children = append(current.Children, xmlNode{Element: el})
+			}
+			current = el
+		case xml.EndElement:
+			if current == nil {
+				return nil, errors.New("unbalanced xml")
+			}
+			depth--
+			current = current.Parent
+		case xml.CharData:
+			if current != nil {
+				current.Children = append(current.Children, xmlNode{Text: string(tok)})
+			}
+		case xml.Directive:
+			return nil, errors.New("xml directives (DOCTYPE) are not allowed")
+		}
+	}
+	if root == nil {
+		return nil, errors.New("empty xml document")
+	}
+	for _, el := range root.descendants() {
+		if _, ok := el.namespace(el.Prefix); !ok {
+			return nil, fmt.Errorf("undeclared namespace prefix %q", el.Prefix)
+		}
+	}
+	return root, nil
+}
+
+// namespace resolves prefix in this element's scope.
+func (e *xmlElement) namespace(prefix string) (string, bool) {
+	if prefix == "xml" {
+		return nsXML, true
+	}
+	for el := e; el != nil; el = el.Parent {
+		if uri, ok := el.NSDecls[prefix]; ok {
+			return uri, true
+		}
+	}
+	return "", prefix == ""
+}
+
+func (e *xmlElement) is(ns, local string) bool {
+	uri, _ := e.namespace(e.Prefix)
+	return e.Local == local && uri == ns
+}
+
+func (e *xmlElement) attr(local string) string {
+	for _, attr := range e.Attrs {
+		if attr.Prefix == "" && attr.Local == local {
+			return attr.Value
+		}
+	}
+	return ""
+}
+
+func (e *xmlElement) hasAttr(local string) bool {
+	for _, attr := range e.Attrs {
+		if attr.Prefix == "" && attr.Local == local {
+			return true
+		}
+	}
+	return false
+}
+
+func (e *xmlElement) children(ns, local string) []*xmlElement {
+	var out []*xmlElement
+	for _, child := range e.Children {
+		if child.Element != nil && child.Element.is(ns, local) {
+			out = append(out, child.Element)
+		}
+	}
+	return out
+}
+
+func (e *xmlElement) child(ns, local string) *xmlElement {
+	if matches := e.children(ns, local); len(matches) > 0 {
+		return matches[0]
+	}
+	return nil
+}
+
+func (e *xmlElement) text() string {
+	var b strings.Builder
+	for _, child := range e.Children {
+		if child.Element == nil {
+			b.WriteString(child.Text)
+		}
+	}
+	return strings.TrimSpace(b.String())
+}
+
+func (e *xmlElement) descendants() []*xmlElement {
+	out := []*xmlElement{e}
+	for _, child := range e.Children {
+		if child.Element != nil {
+			out = append(out, child.Element.descendants()...)
+		}
+	}
+	return out
+}
+
+// canonicalize serializes the subtree rooted at e with Exclusive XML
+// Canonicalization 1.0 (omitting comments). skip, when set, is left out of
+// the output: that is the enveloped-signature transform.
+func canonicalize(e *xmlElement, inclusivePrefixes []string, skip *xmlElement) []byte {
+	var buf bytes.Buffer
+	writeCanonical(&buf, e, map[string]string{}, inclusivePrefixes, skip)
+	return buf.Bytes()
+}
+
+func writeCanonical(buf *bytes.Buffer, e *xmlElement, rendered map[string]string, inclusive []string, skip *xmlElement) {
+	// Exclusive c14n emits only the namespaces this element visibly uses
+	// (plus the InclusiveNamespaces list), and only when the nearest output
+	// ancestor did not already render the same binding.
+	used := map[string]bool{e.Prefix: true}
+	for _, attr := range e.Attrs {
+		if attr.Prefix != "" {
+			used[attr.Prefix] = true
+		}
+	}
+	for _, prefix := range inclusive {
+		if _, ok := e.namespace(prefix); ok {
+			used[prefix] = true
+		}
+	}
+
+	scope := make(map[string]string, len(rendered)+len(used))
+	for prefix, uri := range rendered {
+		scope[prefix] = uri
+	}
+	var prefixes []string
+	for prefix := range used {
+		if prefix == "xml" {
+			continue
+		}
+		uri, _ := e.namespace(prefix)
+		if prior, ok := rendered[prefix]; ok && prior == uri {
+			continue
+		}
+		if prefix == "" && uri == "" && rendered[""] == "" {
+			continue
+		}
+		if prefix != "" && uri == "" {
+			continue
+		}
+		scope[prefix] = uri
+		prefixes = append(prefixes, prefix)
+	}
+	sort.Strings(prefixes)
+
+	type canonicalAttr struct {
+		uri, local, qname, value string
+	}
+	attrs := make([]canonicalAttr, 0, len(e.Attrs))
+	for _, attr := range e.Attrs {
+		uri := ""
+		qname := attr.Local
+		if attr.Prefix != "" {
+			uri, _ = e.namespace(attr.Prefix)
+			qname = attr.Prefix + ":" + attr.Local
+		}
+		attrs = append(attrs, canonicalAttr{uri: uri, local: attr.Local, qname: qname, value: attr.Value})
+	}
+	sort.Slice(attrs, func(i, j int) bool {
+		if attrs[i].uri != attrs[j].uri {
+			return attrs[i].uri < attrs[j].uri
+		}
+		return attrs[i].local < attrs[j].local
+	})
+
+	qname := e.Local
+	if e.Prefix != "" {
+		qname = e.Prefix + ":" + e.Local
+	}
+	buf.WriteByte('<')
+	buf.WriteString(qname)
+	for _, prefix := range prefixes {
+		if prefix == "" {
+			buf.WriteString(` xmlns="`)
+		} else {
+			buf.WriteString(` xmlns:` + prefix + `="`)
+		}
+		buf.WriteString(escapeC14NAttr(scope[prefix]))
+		buf.WriteByte('"')
+	}
+	for _, attr := range attrs {
+		buf.WriteString(" " + attr.qname + `="` + escapeC14NAttr(attr.value) + `"`)
+	}
+	buf.WriteByte('>')
+	for _, child := range e.Children {
+		if child.Element == nil {
+			buf.WriteString(escapeC14NText(child.Text))
+			continue
+		}
+		if child.Element == skip {
+			continue
+		}
+		writeCanonical(buf, child.Element, scope, inclusive, skip)
+	}
+	buf.WriteString("</" + qname + ">")
+}
+
+var (
+	c14nTextEscaper = strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;", "\r", "&#xD;")
+	c14nAttrEscaper = strings.NewReplacer("&", "&amp;", "<", "&lt;", `"`, "&quot;", "\t", "&#x9;", "\n", "&#xA;", "\r", "&#xD;")
+)
+
+func escapeC14NText(s string) string { return c14nTextEscaper.Replace(s) }
+func escapeC14NAttr(s string) string { return c14nAttrEscaper.Replace(s) }
+
+// verifyEnvelopedSignature checks the ds:Signature that is a direct child of
+// el. The signature must reference el itself by ID, so a valid signature
+// cannot be moved onto other content. Only cert is trusted; any KeyInfo in
+// the document is ignored.
+func verifyEnvelopedSignature(doc, el *xmlElement, cert *x509.Certificate) error {
+	signatures := el.children(nsDSig, "Signature")
+	if len(signatures) == 0 {
+		return errNoSignature
+	}
+	if len(signatures) > 1 {
+		return errors.New("multiple signatures on element")
+	}
+	signature := signatures[0]
+
+	id := el.attr("ID")
+	if id == "" {
+		return errors.New("signed element has no ID")
+	}
+	seen := 0
+	for _, candidate := range doc.descendants() {
+		if candidate.hasAttr("ID") && candidate.attr("ID") == id {
+			seen++
+		}
+	}
+	if seen != 1 {
+		return errors.New("signed element ID is not unique")
+	}
+
+	signedInfo := signature.child(nsDSig, "SignedInfo")
+	if signedInfo == nil {
+		return errors.New("signature has no SignedInfo")
+	}
+	c14nMethod := signedInfo.child(nsDSig, "CanonicalizationMethod")
+	if c14nMethod == nil || c14nMethod.attr("Algorithm") != algExcC14N {
+		return errors.New("unsupported canonicalization method")
+	}
+	signatureMethod := signedInfo.child(nsDSig, "SignatureMethod")
+	if signatureMethod == nil {
+		return errors.New("signature has no SignatureMethod")
+	}
+
+	references := signedInfo.children(nsDSig, "Reference")
+	if len(references) != 1 {
+		return errors.New("signature must have exactly one Reference")
+	}
+	reference := references[0]
+	if reference.attr("URI") != "#"+id {
+		return errors.New("signature reference does not match the signed element")
+	}
+
+	var inclusive []string
+	sawEnveloped, sawC14N := false, false
+	if transforms := reference.child(nsDSig, "Transforms"); transforms != nil {
+		for _, transform := range transforms.children(nsDSig, "Transform") {
+			switch transform.attr("Algorithm") {
+			case algEnveloped:
+				sawEnveloped = true
+			case algExcC14N:
+				sawC14N = true
+				inclusive = inclusiveNamespaces(transform)
+			default:
+				return fmt.Errorf("unsupported transform %q", transform.attr("Algorithm"))
+			}
+		}
+	}
+	if !sawEnveloped || !sawC14N {
+		return errors.New("reference must use enveloped-signature and exclusive c14n transforms")
+	}
+
+	digestMethod := reference.child(nsDSig, "DigestMethod")
+	digestValue := reference.child(nsDSig, "DigestValue")
+	if digestMethod == nil || digestValue == nil {
+		return errors.New("reference has no digest")
+	}
+	var digestHash crypto.Hash
+	switch digestMethod.attr("Algorithm") {
+	case algSHA256:
+		digestHash = crypto.SHA256
+	case algSHA512:
+		digestHash = crypto.SHA512
+	default:
+		return fmt.Errorf("unsupported digest method %q", digestMethod.attr("Algorithm"))
+	}
+	expectedDigest, err := decodeXMLBase64(digestValue.text())
+	if err != nil {
+		return errors.New("invalid digest value")
+	}
+	h := newHash(digestHash)
+	h.Write(canonicalize(el, inclusive, signature))
+	if subtle.ConstantTimeCompare(h.Sum(nil), expectedDigest) != 1 {
+		return errors.New("digest mismatch")
+	}
+
+	signatureValue := signature.child(nsDSig, "SignatureValue")
+	if signatureValue == nil {
+		return errors.New("signature has no SignatureValue")
+	}
+	sig, err := decodeXMLBase64(signatureValue.text())
+	if err != nil {
+		return errors.New("invalid signature value")
+	}
+	signedBytes := canonicalize(signedInfo, inclusiveNamespaces(c14nMethod), nil)
+	return verifyXMLSignatureValue(signatureMethod.attr("Algorithm"), cert, signedBytes, sig)
+}
+
+func verifyXMLSignatureValue(alg string, cert *x509.Certificate, signed, sig []byte) error {
+	var hashAlg crypto.Hash
+	switch alg {
+	case algRSASHA256, algECDSASHA256:
+		hashAlg = crypto.SHA256
+	case algRSASHA512:
+		hashAlg = crypto.SHA512
+	default:
+		return fmt.Errorf("unsupported signature method %q", alg)
+	}
+	h := newHash(hashAlg)
+	h.Write(signed)
+	digest := h.Sum(nil)
+
+	switch pub := cert.PublicKey.(type) {
+	case *rsa.PublicKey:
+		if alg == algECDSASHA256 {
+			break
+		}
+		if err := rsa.VerifyPKCS1v15(pub, hashAlg, digest, sig); err != nil {
+			return errors.New("signature verification failed")
+		}
+		return nil
+	case *ecdsa.PublicKey:
+		if alg != algECDSASHA256 {
+			break
+		}
+		size := (pub.Curve.Params().BitSize + 7) / 8
+		if len(sig) != 2*size {
+			return errors.New("invalid signature length")
+		}
+		r := new(big.Int).SetBytes(sig[:size])
+		s := new(big.Int).SetBytes(sig[size:])
+		if !ecdsa.Verify(pub, digest, r, s) {
+			return errors.New("signature verification failed")
+		}
+		return nil
+	}
+	return errors.New("certificate key does not match signature method")
+}
+
+func inclusiveNamespaces(transform *xmlElement) []string {
+	for _, child := range transform.Children {
+		if child.Element == nil || child.Element.Local != "InclusiveNamespaces" {
+			continue
+		}
+		var prefixes []string
+		for _, prefix := range strings.Fields(child.Element.attr("PrefixList")) {
+			if prefix == "#default" {
+				prefix = ""
+			}
+			prefixes = append(prefixes, prefix)
+		}
+		return prefixes
+	}
+	return nil
+}
+
+func decodeXMLBase64(value string) ([]byte, error) {
+	return base64.StdEncoding.DecodeString(strings.Join(strings.Fields(value), ""))
+}
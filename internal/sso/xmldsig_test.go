@@ -0,0 +1,36 @@
+package sso
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCanonicalizeExclusiveNamespacesAndOrdering(t *testing.T) {
+	doc, err := parseXMLDocument([]byte(`<?xml version="1.0"?>
+<a:root xmlns:a="urn:a" xmlns:b="urn:b" xmlns:unused="urn:unused"><b:child z="1" b:y="2" a="3&amp;&lt;"/><a:text>x &gt; y &amp; z</a:text></a:root>`))
+	require.NoError(t, err)
+
+	child := doc.children("urn:b", "child")[0]
+	require.Equal(t, `<b:child xmlns:b="urn:b" a="3&amp;&lt;" z="1" b:y="2"></b:child>`,
+		string(canonicalize(child, nil, nil)))
+	require.Equal(t, `<a:root xmlns:a="urn:a"><b:child xmlns:b="urn:b" a="3&amp;&lt;" z="1" b:y="2"></b:child><a:text>x &gt; y &amp; z</a:text></a:root>`,
+		string(canonicalize(doc, nil, nil)))
+	require.Equal(t, `<a:root xmlns:a="urn:a" xmlns:unused="urn:unused"><b:child xmlns:b="urn:b" a="3&amp;&lt;" z="1" b:y="2"></b:child><a:text>x &gt; y &amp; z</a:text></a:root>`,
+		string(canonicalize(doc, []string{"unused"}, nil)))
+}
+
+func TestCanonicalizeDefaultNamespaceAndSkip(t *testing.T) {
+	doc, err := parseXMLDocument([]byte(`<root xmlns="urn:d"><inner xmlns=""><leaf/></inner><drop/></root>`))
+	require.NoError(t, err)
+	drop := doc.Children[1].Element
+	require.Equal(t, `<root xmlns="urn:d"><inner xmlns=""><leaf></leaf></inner></root>`,
+		string(canonicalize(doc, nil, drop)))
+	inner := doc.Children[0].Element
+	require.Equal(t, `<inner><leaf></leaf></inner>`, string(canonicalize(inner, nil, nil)))
+}
+
+func TestParseXMLDocumentRejectsUndeclaredPrefix(t *testing.T) {
+	_, err := parseXMLDocument([]byte(`<x:root/>`))
+	require.Error(t, err)
+}
@@ -0,0 +1,282 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/lib/pq"
+	"github.com/samhotchkiss/otter-camp/internal/middleware"
+)
+
+const agentJobBlackoutDateLayout = "2006-01-02"
+
+// AgentJobBlackoutWindow suppresses scheduled job runs. Every field is a
+// wall-clock value interpreted in the timezone of the job being scheduled:
+// a window matches when the local date is inside [StartDate, EndDate], the
+// local weekday is in DaysOfWeek (0 = Sunday), and the local minute of day is
+// inside [StartMinute, EndMinute). Empty bounds match everything.
+type AgentJobBlackoutWindow struct {
+	ID          string
+	OrgID       string
+	Name        string
+	DaysOfWeek  []int
+	StartDate   *string
+	EndDate     *string
+	StartMinute int
+	EndMinute   int
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+}
+
+type CreateAgentJobBlackoutWindowInput struct {
+	Name        string
+	DaysOfWeek  []int
+	StartDate   *string
+	EndDate     *string
+	StartMinute *int
+	EndMinute   *int
+}
+
+const agentJobBlackoutWindowColumns = `
+	id,
+	org_id,
+	name,
+	days_of_week,
+	start_date,
+	end_date,
+	start_minute,
+	end_minute,
+	created_at,
+	updated_at
+`
+
+func (s *AgentJobStore) ListBlackoutWindows(ctx context.Context) ([]AgentJobBlackoutWindow, error) {
+	if s == nil || s.db == nil {
+		return nil, fmt.Errorf("agent job store is not configured")
+	}
+
+	conn, err := WithWorkspace(ctx, s.db)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	rows, err := conn.QueryContext(
+		ctx,
+		`SELECT`+agentJobBlackoutWindowColumns+`
+		 FROM agent_job_blackout_windows
+		 ORDER BY created_at ASC, id ASC`,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list agent job blackout windows: %w", err)
+	}
+	defer rows.Close()
+
+	out := make([]AgentJobBlackoutWindow, 0)
+	for rows.Next() {
+		window, scanErr := scanAgentJobBlackoutWindow(rows)
+		if scanErr != nil {
+			return nil, fmt.Errorf("failed to scan agent job blackout window: %w", scanErr)
+		}
+		out = append(out, window)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read agent job blackout windows: %w", err)
+	}
+	return out, nil
+}
+
+func (s *AgentJobStore) CreateBlackoutWindow(
+	ctx context.Context,
+	input CreateAgentJobBlackoutWindowInput,
+) (*AgentJobBlackoutWindow, error) {
+	if s == nil || s.db == nil {
+		return nil, fmt.Errorf("agent job store is not configured")
+	}
+
+	workspaceID := strings.TrimSpace(middleware.WorkspaceFromContext(ctx))
+	if workspaceID == "" {
+		return nil, ErrNoWorkspace
+	}
+
+	window, err := normalizeAgentJobBlackoutWindowInput(input)
+	if err != nil {
+		return nil, err
+	}
+
+	days := make([]int64, 0, len(window.DaysOfWeek))
+	for _, day := range window.DaysOfWeek {
+		days = append(days, int64(day))
+	}
+
+	conn, err := WithWorkspace(ctx, s.db)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	created, err := scanAgentJobBlackoutWindow(conn.QueryRowContext(
+		ctx,
+		`INSERT INTO agent_job_blackout_windows (
+			org_id,
+			name,
+			days_of_week,
+			start_date,
+			end_date,
+			start_minute,
+			end_minute
+		) VALUES ($1, $2, $3, $4, $5, $6, $7)
+		RETURNING`+agentJobBlackoutWindowColumns,
+		workspaceID,
+		window.Name,
+		pq.Array(days),
+		nullableString(window.StartDate),
+		nullableString(window.EndDate),
+		window.StartMinute,
+		window.EndMinute,
+	))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create agent job blackout window: %w", err)
+	}
+	return &created, nil
+}
+
+func (s *AgentJobStore) DeleteBlackoutWindow(ctx context.Context, windowID string) error {
+	if s == nil || s.db == nil {
+		return fmt.Errorf("agent job store is not configured")
+	}
+	windowID = strings.TrimSpace(windowID)
+	if !uuidRegex.MatchString(windowID) {
+		return fmt.Errorf("%w: invalid blackout_id", ErrValidation)
+	}
+
+	conn, err := WithWorkspace(ctx, s.db)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	result, err := conn.ExecContext(ctx, `DELETE FROM agent_job_blackout_windows WHERE id = $1`, windowID)
+	if err != nil {
+		return fmt.Errorf("failed to delete agent job blackout window: %w", err)
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to delete agent job blackout window: %w", err)
+	}
+	if affected == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func normalizeAgentJobBlackoutWindowInput(input CreateAgentJobBlackoutWindowInput) (AgentJobBlackoutWindow, error) {
+	window := AgentJobBlackoutWindow{
+		Name:        strings.TrimSpace(input.Name),
+		StartMinute: 0,
+		EndMinute:   24 * 60,
+	}
+	if window.Name == "" {
+		return AgentJobBlackoutWindow{}, fmt.Errorf("%w: name is required", ErrValidation)
+	}
+
+	seenDays := map[int]bool{}
+	for _, day := range input.DaysOfWeek {
+		if day < 0 || day > 6 {
+			return AgentJobBlackoutWindow{}, fmt.Errorf("%w: days_of_week must be between 0 and 6", ErrValidation)
+		}
+		if seenDays[day] {
+			continue
+		}
+		seenDays[day] = true
+		window.DaysOfWeek = append(window.DaysOfWeek, day)
+	}
+	sort.Ints(window.DaysOfWeek)
+
+	var startDate, endDate time.Time
+	if input.StartDate != nil && strings.TrimSpace(*input.StartDate) != "" {
+		parsed, err := time.Parse(agentJobBlackoutDateLayout, strings.TrimSpace(*input.StartDate))
+		if err != nil {
+			return AgentJobBlackoutWindow{}, fmt.Errorf("%w: start_date must be YYYY-MM-DD", ErrValidation)
+		}
+		value := parsed.Format(agentJobBlackoutDateLayout)
+		window.StartDate = &value
+		startDate = parsed
+	}
+	if input.EndDate != nil && strings.TrimSpace(*input.EndDate) != "" {
+		parsed, err := time.Parse(agentJobBlackoutDateLayout, strings.TrimSpace(*input.EndDate))
+		if err != nil {
+			return AgentJobBlackoutWindow{}, fmt.Errorf("%w: end_date must be YYYY-MM-DD", ErrValidation)
+		}
+		value := parsed.Format(agentJobBlackoutDateLayout)
+		window.EndDate = &value
+		endDate = parsed
+	}
+	if window.StartDate != nil && window.EndDate != nil && endDate.Before(startDate) {
+		return AgentJobBlackoutWindow{}, fmt.Errorf("%w: end_date must not be before start_date", ErrValidation)
+	}
+
+	if input.StartMinute != nil {
+		window.StartMinute = *input.StartMinute
+	}
+	if input.EndMinute != nil {
+		window.EndMinute = *input.EndMinute
+	}
+	if window.StartMinute < 0 || window.EndMinute > 24*60 || window.StartMinute >= window.EndMinute {
+		return AgentJobBlackoutWindow{}, fmt.Errorf("%w: start_minute must be before end_minute within one day", ErrValidation)
+	}
+
+	if len(window.DaysOfWeek) == 0 && window.StartDate == nil && window.EndDate == nil &&
+		window.StartMinute == 0 && window.EndMinute == 24*60 {
+		return AgentJobBlackoutWindow{}, fmt.Errorf("%w: blackout window must be bounded by days, dates, or time of day", ErrValidation)
+	}
+	return window, nil
+}
+
+func scanAgentJobBlackoutWindow(scanner interface{ Scan(...any) error }) (AgentJobBlackoutWindow, error) {
+	var (
+		window    AgentJobBlackoutWindow
+		days      pq.Int64Array
+		startDate sql.NullTime
+		endDate   sql.NullTime
+	)
+	err := scanner.Scan(
+		&window.ID,
+		&window.OrgID,
+		&window.Name,
+		&days,
+		&startDate,
+		&endDate,
+		&window.StartMinute,
+		&window.EndMinute,
+		&window.CreatedAt,
+		&window.UpdatedAt,
+	)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return AgentJobBlackoutWindow{}, ErrNotFound
+		}
+		return AgentJobBlackoutWindow{}, err
+	}
+
+	window.DaysOfWeek = make([]int, 0, len(days))
+	for _, day := range days {
+		window.DaysOfWeek = append(window.DaysOfWeek, int(day))
+	}
+	if startDate.Valid {
+		value := startDate.Time.Format(agentJobBlackoutDateLayout)
+		window.StartDate = &value
+	}
+	if endDate.Valid {
+		value := endDate.Time.Format(agentJobBlackoutDateLayout)
+		window.EndDate = &value
+	}
+	window.CreatedAt = window.CreatedAt.UTC()
+	window.UpdatedAt = window.UpdatedAt.UTC()
+	return window, nil
+}
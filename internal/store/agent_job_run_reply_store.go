@@ -0,0 +1,304 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// maxAgentJobRunOutputBytes caps how much of an agent reply is copied onto
+// the run row; the full reply stays in the job room.
+const maxAgentJobRunOutputBytes = 64 * 1024
+
+const (
+	agentJobStaleRunError     = "stale running job exceeded timeout"
+	agentJobReplyTimeoutError = "agent did not reply before the run timed out"
+)
+
+type AwaitAgentJobReplyInput struct {
+	JobID           string
+	RunID           string
+	MessageID       string
+	ReplyDeadlineAt time.Time
+	NextRunAt       *time.Time
+}
+
+type AgentJobReplyMessage struct {
+	ID        string
+	Body      string
+	CreatedAt time.Time
+}
+
+// AgentJobRunReply pairs a run that is still waiting with the first message
+// its agent posted to the job room after the prompt.
+type AgentJobRunReply struct {
+	Run   AgentJobRun
+	Reply AgentJobReplyMessage
+}
+
+type CreateAgentJobReplyInput struct {
+	JobID     string
+	RunID     string
+	Body      string
+	CreatedAt time.Time
+}
+
+// MarkRunAwaitingReply records the delivered prompt on a running run, sets the
+// deadline for the agent's reply, and releases the PickupDue lease by setting
+// the job's next run. The run itself stays running until a reply is reviewed
+// or the deadline passes.
+func (s *AgentJobStore) MarkRunAwaitingReply(ctx context.Context, input AwaitAgentJobReplyInput) error {
+	if s == nil || s.db == nil {
+		return fmt.Errorf("agent job store is not configured")
+	}
+	jobID := strings.TrimSpace(input.JobID)
+	runID := strings.TrimSpace(input.RunID)
+	messageID := strings.TrimSpace(input.MessageID)
+	if !uuidRegex.MatchString(jobID) {
+		return fmt.Errorf("%w: invalid job_id", ErrValidation)
+	}
+	if !uuidRegex.MatchString(runID) {
+		return fmt.Errorf("%w: invalid run_id", ErrValidation)
+	}
+	if !uuidRegex.MatchString(messageID) {
+		return fmt.Errorf("%w: invalid message_id", ErrValidation)
+	}
+	if input.ReplyDeadlineAt.IsZero() {
+		return fmt.Errorf("%w: reply deadline is required", ErrValidation)
+	}
+
+	tx, err := WithWorkspaceTx(ctx, s.db)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	result, err := tx.ExecContext(
+		ctx,
+		`UPDATE agent_job_runs
+		 SET message_id = $3,
+		     reply_deadline_at = $4
+		 WHERE id = $1 AND job_id = $2 AND status = 'running'`,
+		runID,
+		jobID,
+		messageID,
+		input.ReplyDeadlineAt.UTC(),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to mark agent job run awaiting reply: %w", err)
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to mark agent job run awaiting reply: %w", err)
+	}
+	if affected == 0 {
+		return ErrNotFound
+	}
+
+	_, err = tx.ExecContext(
+		ctx,
+		`UPDATE agent_jobs
+		 SET next_run_at = $2,
+		     updated_at = NOW()
+		 WHERE id = $1 AND next_run_at IS NULL`,
+		jobID,
+		nullableTime(input.NextRunAt),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to reschedule agent job: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit agent job run awaiting reply: %w", err)
+	}
+	return nil
+}
+
+// ListRunReplies returns running runs whose agent has replied in the job room
+// since the prompt was delivered, oldest first.
+func (s *AgentJobStore) ListRunReplies(ctx context.Context, limit int) ([]AgentJobRunReply, error) {
+	if s == nil || s.db == nil {
+		return nil, fmt.Errorf("agent job store is not configured")
+	}
+	if limit <= 0 {
+		limit = 50
+	}
+
+	conn, err := WithWorkspace(ctx, s.db)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	rows, err := conn.QueryContext(
+		ctx,
+		`WITH replied AS (
+			SELECT r.id AS run_id,
+			       reply.id AS reply_id,
+			       reply.body AS reply_body,
+			       reply.created_at AS reply_created_at
+			FROM agent_job_runs r
+			JOIN agent_jobs j ON j.id = r.job_id
+			JOIN chat_messages prompt ON prompt.id = r.message_id
+			JOIN LATERAL (
+				SELECT m.id, m.body, m.created_at
+				FROM chat_messages m
+				WHERE m.room_id = prompt.room_id
+				  AND m.sender_type = 'agent'
+				  AND m.sender_id = j.agent_id
+				  AND m.created_at >= prompt.created_at
+				  AND m.id <> prompt.id
+				ORDER BY m.created_at ASC, m.id ASC
+				LIMIT 1
+			) reply ON TRUE
+			WHERE r.status = 'running'
+			ORDER BY r.started_at ASC
+			LIMIT $1
+		)
+		SELECT`+agentJobRunColumns+`,
+		       replied.reply_id,
+		       replied.reply_body,
+		       replied.reply_created_at
+		FROM agent_job_runs
+		JOIN replied ON replied.run_id = agent_job_runs.id
+		ORDER BY agent_job_runs.started_at ASC`,
+		limit,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list agent job run replies: %w", err)
+	}
+	defer rows.Close()
+
+	out := make([]AgentJobRunReply, 0)
+	for rows.Next() {
+		var reply AgentJobRunReply
+		run, scanErr := scanAgentJobRun(trailingColumnScanner{
+			scanner: rows,
+			extra:   []any{&reply.Reply.ID, &reply.Reply.Body, &reply.Reply.CreatedAt},
+		})
+		if scanErr != nil {
+			return nil, fmt.Errorf("failed to scan agent job run reply: %w", scanErr)
+		}
+		reply.Run = run
+		reply.Reply.CreatedAt = reply.Reply.CreatedAt.UTC()
+		out = append(out, reply)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read agent job run replies: %w", err)
+	}
+	return out, nil
+}
+
+// CreateJobReply posts an agent's reply to a running run into the job room,
+// where the scheduler worker picks it up and evaluates the job's criteria.
+func (s *AgentJobStore) CreateJobReply(ctx context.Context, input CreateAgentJobReplyInput) (string, error) {
+	if s == nil || s.db == nil {
+		return "", fmt.Errorf("agent job store is not configured")
+	}
+	jobID := strings.TrimSpace(input.JobID)
+	runID := strings.TrimSpace(input.RunID)
+	body := strings.TrimSpace(input.Body)
+	if !uuidRegex.MatchString(jobID) {
+		return "", fmt.Errorf("%w: invalid job_id", ErrValidation)
+	}
+	if !uuidRegex.MatchString(runID) {
+		return "", fmt.Errorf("%w: invalid run_id", ErrValidation)
+	}
+	if body == "" {
+		return "", fmt.Errorf("%w: output is required", ErrValidation)
+	}
+	createdAt := input.CreatedAt
+	if createdAt.IsZero() {
+		createdAt = time.Now().UTC()
+	} else {
+		createdAt = createdAt.UTC()
+	}
+
+	tx, err := WithWorkspaceTx(ctx, s.db)
+	if err != nil {
+		return "", err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	var (
+		orgID    string
+		agentID  string
+		status   string
+		roomID   sql.NullString
+		promptAt sql.NullTime
+	)
+	err = tx.QueryRowContext(
+		ctx,
+		`SELECT r.org_id, j.agent_id, r.status, prompt.room_id, prompt.created_at
+		 FROM agent_job_runs r
+		 JOIN agent_jobs j ON j.id = r.job_id
+		 LEFT JOIN chat_messages prompt ON prompt.id = r.message_id
+		 WHERE r.id = $1 AND r.job_id = $2
+		 FOR UPDATE OF r`,
+		runID,
+		jobID,
+	).Scan(&orgID, &agentID, &status, &roomID, &promptAt)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return "", ErrNotFound
+		}
+		return "", fmt.Errorf("failed to load agent job run: %w", err)
+	}
+	if status != AgentJobRunStatusRunning || !roomID.Valid {
+		return "", ErrConflict
+	}
+	if promptAt.Valid && createdAt.Before(promptAt.Time) {
+		createdAt = promptAt.Time.UTC()
+	}
+
+	var messageID string
+	err = tx.QueryRowContext(
+		ctx,
+		`INSERT INTO chat_messages (
+			org_id,
+			room_id,
+			sender_id,
+			sender_type,
+			body,
+			type,
+			attachments,
+			created_at
+		) VALUES (
+			$1, $2, $3, 'agent', $4, 'message', '[]'::jsonb, $5
+		)
+		RETURNING id`,
+		orgID,
+		roomID.String,
+		agentID,
+		body,
+		createdAt,
+	).Scan(&messageID)
+	if err != nil {
+		return "", fmt.Errorf("failed to create agent job reply: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return "", fmt.Errorf("failed to commit agent job reply: %w", err)
+	}
+	return messageID, nil
+}
+
+func truncateAgentJobRunOutput(output *string) *string {
+	if output == nil {
+		return nil
+	}
+	value := *output
+	if len(value) <= maxAgentJobRunOutputBytes {
+		return &value
+	}
+	cut := maxAgentJobRunOutputBytes
+	for cut > 0 && !utf8.RuneStart(value[cut]) {
+		cut--
+	}
+	value = value[:cut]
+	return &value
+}
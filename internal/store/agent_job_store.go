@@ -7,6 +7,7 @@ import (
 	"encoding/hex"
 	"errors"
 	"fmt"
+	"regexp"
 	"strings"
 	"time"
 
@@ -39,6 +40,18 @@ const (
 	AgentJobRunStatusSkipped = "skipped"
 )
 
+const (
+	AgentJobOverlapSkip    = "skip"
+	AgentJobOverlapQueue   = "queue"
+	AgentJobOverlapReplace = "replace"
+)
+
+const (
+	AgentJobCatchUpRunOnce = "run_once"
+	AgentJobCatchUpRunAll  = "run_all"
+	AgentJobCatchUpSkip    = "skip"
+)
+
 type AgentJob struct {
 	ID                  string
 	OrgID               string
@@ -63,23 +76,40 @@ type AgentJob struct {
 	ErrorCount          int
 	MaxFailures         int
 	ConsecutiveFailures int
-	CreatedBy           *string
-	CreatedAt           time.Time
-	UpdatedAt           time.Time
+	OverlapPolicy       string
+	CatchUpPolicy       string
+	JitterMS            int64
+	MaxAgentConcurrency *int
+	// Success criteria are evaluated against the agent's reply; every
+	// configured criterion must pass for the run to count as a success.
+	SuccessPattern          *string
+	SuccessJSONField        *string
+	SuccessClassifierPrompt *string
+	ReplyTimeoutMS          *int64
+	CreatedBy               *string
+	CreatedAt               time.Time
+	UpdatedAt               time.Time
+
+	// DueAt is only populated by PickupDue and carries the next_run_at value
+	// that made the job due before the lease cleared it.
+	DueAt *time.Time
 }
 
 type AgentJobRun struct {
-	ID          string
-	JobID       string
-	OrgID       string
-	Status      string
-	StartedAt   time.Time
-	CompletedAt *time.Time
-	DurationMS  *int
-	Error       *string
-	PayloadText string
-	MessageID   *string
-	CreatedAt   time.Time
+	ID              string
+	JobID           string
+	OrgID           string
+	Status          string
+	StartedAt       time.Time
+	CompletedAt     *time.Time
+	DurationMS      *int
+	Error           *string
+	PayloadText     string
+	MessageID       *string
+	Output          *string
+	ReplyMessageID  *string
+	ReplyDeadlineAt *time.Time
+	CreatedAt       time.Time
 }
 
 type CreateAgentJobInput struct {
@@ -99,6 +129,16 @@ type CreateAgentJobInput struct {
 	NextRunAt    *time.Time
 	MaxFailures  *int
 	CreatedBy    *string
+
+	OverlapPolicy       *string
+	CatchUpPolicy       *string
+	JitterMS            *int64
+	MaxAgentConcurrency *int
+
+	SuccessPattern          *string
+	SuccessJSONField        *string
+	SuccessClassifierPrompt *string
+	ReplyTimeoutMS          *int64
 }
 
 type UpdateAgentJobInput struct {
@@ -116,6 +156,20 @@ type UpdateAgentJobInput struct {
 	Status       *string
 	NextRunAt    *time.Time
 	MaxFailures  *int
+
+	OverlapPolicy       *string
+	CatchUpPolicy       *string
+	JitterMS            *int64
+	MaxAgentConcurrency *int
+	// ClearMaxAgentConcurrency removes the per-agent concurrency limit.
+	ClearMaxAgentConcurrency bool
+
+	// Empty success criteria strings and a zero ReplyTimeoutMS clear the
+	// stored value.
+	SuccessPattern          *string
+	SuccessJSONField        *string
+	SuccessClassifierPrompt *string
+	ReplyTimeoutMS          *int64
 }
 
 type AgentJobFilter struct {
@@ -140,6 +194,12 @@ type CompleteAgentJobRunInput struct {
 	RunError    *string
 	NextRunAt   *time.Time
 	CompleteJob bool
+
+	Output         *string
+	ReplyMessageID *string
+	// PreserveNextRunAt leaves next_run_at untouched; runs that wait for a
+	// reply already scheduled the next occurrence when the prompt went out.
+	PreserveNextRunAt bool
 }
 
 type CreateAgentJobMessageInput struct {
@@ -183,6 +243,14 @@ const agentJobColumns = `
 	error_count,
 	max_failures,
 	consecutive_failures,
+	overlap_policy,
+	catch_up_policy,
+	jitter_ms,
+	max_agent_concurrency,
+	success_pattern,
+	success_json_field,
+	success_classifier_prompt,
+	reply_timeout_ms,
 	created_by,
 	created_at,
 	updated_at
@@ -199,6 +267,9 @@ const agentJobRunColumns = `
 	error,
 	payload_text,
 	message_id,
+	output,
+	reply_message_id,
+	reply_deadline_at,
 	created_at
 `
 
@@ -242,10 +313,19 @@ func (s *AgentJobStore) Create(ctx context.Context, input CreateAgentJobInput) (
 			status,
 			next_run_at,
 			max_failures,
-			created_by
+			created_by,
+			overlap_policy,
+			catch_up_policy,
+			jitter_ms,
+			max_agent_concurrency,
+			success_pattern,
+			success_json_field,
+			success_classifier_prompt,
+			reply_timeout_ms
 		) VALUES (
 			$1, $2, $3, $4, $5, $6, $7, $8, $9,
-			$10, $11, $12, $13, $14, $15, $16, $17
+			$10, $11, $12, $13, $14, $15, $16, $17,
+			$18, $19, $20, $21, $22, $23, $24, $25
 		)
 		RETURNING`+agentJobColumns,
 		workspaceID,
@@ -265,6 +345,14 @@ func (s *AgentJobStore) Create(ctx context.Context, input CreateAgentJobInput) (
 		nullableTime(normalizedInput.NextRunAt),
 		normalizedInput.MaxFailures,
 		nullableString(normalizedInput.CreatedBy),
+		*normalizedInput.OverlapPolicy,
+		*normalizedInput.CatchUpPolicy,
+		*normalizedInput.JitterMS,
+		nullableInt32(normalizedInput.MaxAgentConcurrency),
+		nullableString(normalizedInput.SuccessPattern),
+		nullableString(normalizedInput.SuccessJSONField),
+		nullableString(normalizedInput.SuccessClassifierPrompt),
+		nullableInt64(normalizedInput.ReplyTimeoutMS),
 	))
 	if err != nil {
 		if isForeignKeyViolation(err) {
@@ -472,11 +560,53 @@ func (s *AgentJobStore) Update(ctx context.Context, jobID string, input UpdateAg
 	if input.MaxFailures != nil {
 		updated.MaxFailures = *input.MaxFailures
 	}
+	if input.OverlapPolicy != nil {
+		overlapPolicy, overlapErr := normalizeAgentJobOverlapPolicy(*input.OverlapPolicy)
+		if overlapErr != nil {
+			return nil, overlapErr
+		}
+		updated.OverlapPolicy = overlapPolicy
+	}
+	if input.CatchUpPolicy != nil {
+		catchUpPolicy, catchUpErr := normalizeAgentJobCatchUpPolicy(*input.CatchUpPolicy)
+		if catchUpErr != nil {
+			return nil, catchUpErr
+		}
+		updated.CatchUpPolicy = catchUpPolicy
+	}
+	if input.JitterMS != nil {
+		updated.JitterMS = *input.JitterMS
+	}
+	if input.ClearMaxAgentConcurrency {
+		updated.MaxAgentConcurrency = nil
+	} else if input.MaxAgentConcurrency != nil {
+		limit := *input.MaxAgentConcurrency
+		updated.MaxAgentConcurrency = &limit
+	}
+	if input.SuccessPattern != nil {
+		updated.SuccessPattern = normalizeOptionalAgentJobText(input.SuccessPattern)
+	}
+	if input.SuccessJSONField != nil {
+		updated.SuccessJSONField = normalizeOptionalAgentJobText(input.SuccessJSONField)
+	}
+	if input.SuccessClassifierPrompt != nil {
+		updated.SuccessClassifierPrompt = normalizeOptionalAgentJobText(input.SuccessClassifierPrompt)
+	}
+	if input.ReplyTimeoutMS != nil {
+		if *input.ReplyTimeoutMS == 0 {
+			updated.ReplyTimeoutMS = nil
+		} else {
+			timeout := *input.ReplyTimeoutMS
+			updated.ReplyTimeoutMS = &timeout
+		}
+	}
 
 	if err := validateAgentJobRecord(updated); err != nil {
 		return nil, err
 	}
 
+	// Changing the status by hand releases a usage budget's hold on the job,
+	// so the budget will not resume a job someone has since paused.
 	conn, err := WithWorkspace(ctx, s.db)
 	if err != nil {
 		return nil, err
@@ -498,8 +628,17 @@ func (s *AgentJobStore) Update(ctx context.Context, jobID string, input UpdateAg
 		     room_id = $11,
 		     enabled = $12,
 		     status = $13,
+		     paused_by_budget_id = CASE WHEN status = $13 THEN paused_by_budget_id END,
 		     next_run_at = $14,
 		     max_failures = $15,
+		     overlap_policy = $16,
+		     catch_up_policy = $17,
+		     jitter_ms = $18,
+		     max_agent_concurrency = $19,
+		     success_pattern = $20,
+		     success_json_field = $21,
+		     success_classifier_prompt = $22,
+		     reply_timeout_ms = $23,
 		     updated_at = NOW()
 		 WHERE id = $1
 		 RETURNING`+agentJobColumns,
@@ -518,6 +657,14 @@ func (s *AgentJobStore) Update(ctx context.Context, jobID string, input UpdateAg
 		updated.Status,
 		nullableTime(updated.NextRunAt),
 		updated.MaxFailures,
+		updated.OverlapPolicy,
+		updated.CatchUpPolicy,
+		updated.JitterMS,
+		nullableInt32(updated.MaxAgentConcurrency),
+		nullableString(updated.SuccessPattern),
+		nullableString(updated.SuccessJSONField),
+		nullableString(updated.SuccessClassifierPrompt),
+		nullableInt64(updated.ReplyTimeoutMS),
 	))
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
@@ -562,6 +709,9 @@ func (s *AgentJobStore) Delete(ctx context.Context, jobID string) error {
 }
 
 func (s *AgentJobStore) PickupDue(ctx context.Context, limit int, now time.Time) ([]AgentJob, error) {
+	ctx, span := startStoreSpan(ctx, "AgentJobStore.PickupDue")
+	defer span.End()
+
 	if s == nil || s.db == nil {
 		return nil, fmt.Errorf("agent job store is not configured")
 	}
@@ -584,7 +734,7 @@ func (s *AgentJobStore) PickupDue(ctx context.Context, limit int, now time.Time)
 	rows, err := tx.QueryContext(
 		ctx,
 		`WITH due_jobs AS (
-			SELECT id
+			SELECT id, next_run_at AS due_at
 			FROM agent_jobs
 			WHERE enabled = true
 			  AND status = 'active'
@@ -599,7 +749,8 @@ func (s *AgentJobStore) PickupDue(ctx context.Context, limit int, now time.Time)
 		    updated_at = NOW()
 		FROM due_jobs d
 		WHERE j.id = d.id
-		RETURNING j.`+strings.ReplaceAll(strings.TrimSpace(agentJobColumns), ",\n\t", ",\n\tj."),
+		RETURNING j.`+strings.ReplaceAll(strings.TrimSpace(agentJobColumns), ",\n\t", ",\n\tj.")+`,
+			d.due_at`,
 		now.UTC(),
 		limit,
 	)
@@ -610,10 +761,15 @@ func (s *AgentJobStore) PickupDue(ctx context.Context, limit int, now time.Time)
 
 	out := make([]AgentJob, 0, limit)
 	for rows.Next() {
-		job, scanErr := scanAgentJob(rows)
+		var dueAt sql.NullTime
+		job, scanErr := scanAgentJob(trailingColumnScanner{scanner: rows, extra: []any{&dueAt}})
 		if scanErr != nil {
 			return nil, fmt.Errorf("failed to scan due job: %w", scanErr)
 		}
+		if dueAt.Valid {
+			value := dueAt.Time.UTC()
+			job.DueAt = &value
+		}
 		out = append(out, job)
 	}
 	if err := rows.Err(); err != nil {
@@ -627,6 +783,9 @@ func (s *AgentJobStore) PickupDue(ctx context.Context, limit int, now time.Time)
 }
 
 func (s *AgentJobStore) StartRun(ctx context.Context, input StartAgentJobRunInput) (*AgentJobRun, error) {
+	ctx, span := startStoreSpan(ctx, "AgentJobStore.StartRun")
+	defer span.End()
+
 	if s == nil || s.db == nil {
 		return nil, fmt.Errorf("agent job store is not configured")
 	}
@@ -673,6 +832,9 @@ func (s *AgentJobStore) StartRun(ctx context.Context, input StartAgentJobRunInpu
 }
 
 func (s *AgentJobStore) CompleteRun(ctx context.Context, input CompleteAgentJobRunInput) (*AgentJob, error) {
+	ctx, span := startStoreSpan(ctx, "AgentJobStore.CompleteRun")
+	defer span.End()
+
 	if s == nil || s.db == nil {
 		return nil, fmt.Errorf("agent job store is not configured")
 	}
@@ -699,6 +861,12 @@ func (s *AgentJobStore) CompleteRun(ctx context.Context, input CompleteAgentJobR
 			return nil, fmt.Errorf("%w: invalid message_id", ErrValidation)
 		}
 	}
+	if input.ReplyMessageID != nil {
+		replyMessageID := strings.TrimSpace(*input.ReplyMessageID)
+		if replyMessageID != "" && !uuidRegex.MatchString(replyMessageID) {
+			return nil, fmt.Errorf("%w: invalid reply_message_id", ErrValidation)
+		}
+	}
 
 	completedAt := input.CompletedAt
 	if completedAt.IsZero() {
@@ -741,6 +909,10 @@ func (s *AgentJobStore) CompleteRun(ctx context.Context, input CompleteAgentJobR
 	if input.MessageID != nil && strings.TrimSpace(*input.MessageID) != "" {
 		messageIDValue = strings.TrimSpace(*input.MessageID)
 	}
+	var replyMessageIDValue interface{}
+	if input.ReplyMessageID != nil && strings.TrimSpace(*input.ReplyMessageID) != "" {
+		replyMessageIDValue = strings.TrimSpace(*input.ReplyMessageID)
+	}
 
 	_, err = tx.ExecContext(
 		ctx,
@@ -749,7 +921,9 @@ func (s *AgentJobStore) CompleteRun(ctx context.Context, input CompleteAgentJobR
 		     completed_at = $4,
 		     duration_ms = $5,
 		     error = $6,
-		     message_id = $7
+		     message_id = COALESCE($7, message_id),
+		     output = COALESCE($8, output),
+		     reply_message_id = COALESCE($9, reply_message_id)
 		 WHERE id = $1 AND job_id = $2`,
 		runID,
 		jobID,
@@ -758,6 +932,8 @@ func (s *AgentJobStore) CompleteRun(ctx context.Context, input CompleteAgentJobR
 		durationMS,
 		nullableString(input.RunError),
 		messageIDValue,
+		nullableString(truncateAgentJobRunOutput(input.Output)),
+		replyMessageIDValue,
 	)
 	if err != nil {
 		return nil, fmt.Errorf("failed to complete agent job run: %w", err)
@@ -776,6 +952,9 @@ func (s *AgentJobStore) CompleteRun(ctx context.Context, input CompleteAgentJobR
 	}
 
 	nextRunValue := nullableTime(input.NextRunAt)
+	if input.PreserveNextRunAt {
+		nextRunValue = nullableTime(job.NextRunAt)
+	}
 	lastRunError := nullableString(input.RunError)
 	status := job.Status
 	runCount := job.RunCount + 1
@@ -928,6 +1107,9 @@ func (s *AgentJobStore) PruneRunHistory(ctx context.Context, jobID string, maxRu
 }
 
 func (s *AgentJobStore) CleanupStaleRuns(ctx context.Context, olderThan time.Duration, now time.Time) (int, error) {
+	ctx, span := startStoreSpan(ctx, "AgentJobStore.CleanupStaleRuns")
+	defer span.End()
+
 	if s == nil || s.db == nil {
 		return 0, fmt.Errorf("agent job store is not configured")
 	}
@@ -946,24 +1128,32 @@ func (s *AgentJobStore) CleanupStaleRuns(ctx context.Context, olderThan time.Dur
 	}
 	defer func() { _ = tx.Rollback() }()
 
+	// Runs waiting on an agent reply carry their own deadline; everything else
+	// is stale once it has been running longer than olderThan.
 	cutoff := now.Add(-olderThan)
 	rows, err := tx.QueryContext(
 		ctx,
 		`WITH stale AS (
-			SELECT id, job_id, started_at
+			SELECT id, job_id, started_at, reply_deadline_at
 			FROM agent_job_runs
 			WHERE status = 'running'
-			  AND started_at < $1
+			  AND (
+				(reply_deadline_at IS NULL AND started_at < $1)
+				OR reply_deadline_at <= $2
+			  )
 			FOR UPDATE SKIP LOCKED
 		)
 		UPDATE agent_job_runs r
 		SET status = 'timeout',
 		    completed_at = $2,
 		    duration_ms = GREATEST(0, FLOOR(EXTRACT(EPOCH FROM ($2 - s.started_at)) * 1000)::INT),
-		    error = 'stale running job exceeded timeout'
+		    error = CASE
+				WHEN s.reply_deadline_at IS NULL THEN '`+agentJobStaleRunError+`'
+				ELSE '`+agentJobReplyTimeoutError+`'
+		    END
 		FROM stale s
 		WHERE r.id = s.id
-		RETURNING r.job_id`,
+		RETURNING r.job_id, r.error`,
 		cutoff,
 		now,
 	)
@@ -973,12 +1163,14 @@ func (s *AgentJobStore) CleanupStaleRuns(ctx context.Context, olderThan time.Dur
 	defer rows.Close()
 
 	jobFailures := map[string]int{}
+	jobErrors := map[string]string{}
 	for rows.Next() {
-		var jobID string
-		if scanErr := rows.Scan(&jobID); scanErr != nil {
+		var jobID, runError string
+		if scanErr := rows.Scan(&jobID, &runError); scanErr != nil {
 			return 0, fmt.Errorf("failed to scan stale run cleanup: %w", scanErr)
 		}
 		jobFailures[jobID]++
+		jobErrors[jobID] = runError
 	}
 	if err := rows.Err(); err != nil {
 		return 0, fmt.Errorf("failed to read stale run cleanup rows: %w", err)
@@ -990,7 +1182,7 @@ func (s *AgentJobStore) CleanupStaleRuns(ctx context.Context, olderThan time.Dur
 			`UPDATE agent_jobs
 			 SET last_run_at = $2,
 			     last_run_status = 'timeout',
-			     last_run_error = 'stale running job exceeded timeout',
+			     last_run_error = $4,
 			     run_count = run_count + $3,
 			     error_count = error_count + $3,
 			     consecutive_failures = consecutive_failures + $3,
@@ -1003,6 +1195,7 @@ func (s *AgentJobStore) CleanupStaleRuns(ctx context.Context, olderThan time.Dur
 			jobID,
 			now,
 			failures,
+			jobErrors[jobID],
 		)
 		if err != nil {
 			return 0, fmt.Errorf("failed to update stale job summary: %w", err)
@@ -1020,6 +1213,145 @@ func (s *AgentJobStore) CleanupStaleRuns(ctx context.Context, olderThan time.Dur
 	return total, nil
 }
 
+// Reschedule sets next_run_at on a job leased by PickupDue. It only writes when
+// the lease is still held (next_run_at IS NULL) so a concurrent Update or
+// RunNow is never overwritten.
+func (s *AgentJobStore) Reschedule(ctx context.Context, jobID string, nextRunAt *time.Time) error {
+	if s == nil || s.db == nil {
+		return fmt.Errorf("agent job store is not configured")
+	}
+	jobID = strings.TrimSpace(jobID)
+	if !uuidRegex.MatchString(jobID) {
+		return fmt.Errorf("%w: invalid job_id", ErrValidation)
+	}
+
+	conn, err := WithWorkspace(ctx, s.db)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	_, err = conn.ExecContext(
+		ctx,
+		`UPDATE agent_jobs
+		 SET next_run_at = $2,
+		     updated_at = NOW()
+		 WHERE id = $1
+		   AND next_run_at IS NULL`,
+		jobID,
+		nullableTime(nextRunAt),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to reschedule agent job: %w", err)
+	}
+	return nil
+}
+
+func (s *AgentJobStore) CountRunningRuns(ctx context.Context, jobID string) (int, error) {
+	if s == nil || s.db == nil {
+		return 0, fmt.Errorf("agent job store is not configured")
+	}
+	jobID = strings.TrimSpace(jobID)
+	if !uuidRegex.MatchString(jobID) {
+		return 0, fmt.Errorf("%w: invalid job_id", ErrValidation)
+	}
+
+	conn, err := WithWorkspace(ctx, s.db)
+	if err != nil {
+		return 0, err
+	}
+	defer conn.Close()
+
+	var count int
+	err = conn.QueryRowContext(
+		ctx,
+		`SELECT COUNT(*) FROM agent_job_runs WHERE job_id = $1 AND status = 'running'`,
+		jobID,
+	).Scan(&count)
+	if err != nil {
+		return 0, fmt.Errorf("failed to count running agent job runs: %w", err)
+	}
+	return count, nil
+}
+
+func (s *AgentJobStore) CountRunningRunsForAgent(ctx context.Context, agentID string) (int, error) {
+	if s == nil || s.db == nil {
+		return 0, fmt.Errorf("agent job store is not configured")
+	}
+	agentID = strings.TrimSpace(agentID)
+	if !uuidRegex.MatchString(agentID) {
+		return 0, fmt.Errorf("%w: invalid agent_id", ErrValidation)
+	}
+
+	conn, err := WithWorkspace(ctx, s.db)
+	if err != nil {
+		return 0, err
+	}
+	defer conn.Close()
+
+	var count int
+	err = conn.QueryRowContext(
+		ctx,
+		`SELECT COUNT(*)
+		 FROM agent_job_runs r
+		 JOIN agent_jobs j ON j.id = r.job_id
+		 WHERE j.agent_id = $1
+		   AND r.status = 'running'`,
+		agentID,
+	).Scan(&count)
+	if err != nil {
+		return 0, fmt.Errorf("failed to count running agent runs: %w", err)
+	}
+	return count, nil
+}
+
+// SupersedeRunningRuns closes every in-flight run of a job as skipped. It backs
+// the replace overlap policy and does not touch the job's failure counters.
+func (s *AgentJobStore) SupersedeRunningRuns(ctx context.Context, jobID string, at time.Time, reason string) (int, error) {
+	if s == nil || s.db == nil {
+		return 0, fmt.Errorf("agent job store is not configured")
+	}
+	jobID = strings.TrimSpace(jobID)
+	if !uuidRegex.MatchString(jobID) {
+		return 0, fmt.Errorf("%w: invalid job_id", ErrValidation)
+	}
+	if at.IsZero() {
+		at = time.Now().UTC()
+	}
+	reason = strings.TrimSpace(reason)
+	if reason == "" {
+		reason = "superseded by a newer run"
+	}
+
+	conn, err := WithWorkspace(ctx, s.db)
+	if err != nil {
+		return 0, err
+	}
+	defer conn.Close()
+
+	result, err := conn.ExecContext(
+		ctx,
+		`UPDATE agent_job_runs
+		 SET status = 'skipped',
+		     completed_at = $2,
+		     duration_ms = GREATEST(0, FLOOR(EXTRACT(EPOCH FROM ($2 - started_at)) * 1000)::INT),
+		     error = $3
+		 WHERE job_id = $1
+		   AND status = 'running'`,
+		jobID,
+		at.UTC(),
+		reason,
+	)
+	if err != nil {
+		return 0, fmt.Errorf("failed to supersede running agent job runs: %w", err)
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("failed to supersede running agent job runs: %w", err)
+	}
+	return int(affected), nil
+}
+
 func (s *AgentJobStore) EnsureRoomForJob(ctx context.Context, jobID string) (string, error) {
 	if s == nil || s.db == nil {
 		return "", fmt.Errorf("agent job store is not configured")
@@ -1310,6 +1642,49 @@ func normalizeCreateAgentJobInput(input CreateAgentJobInput) (CreateAgentJobInpu
 	}
 	input.MaxFailures = &maxFailures
 
+	overlapPolicy := AgentJobOverlapSkip
+	if input.OverlapPolicy != nil {
+		normalizedOverlap, overlapErr := normalizeAgentJobOverlapPolicy(*input.OverlapPolicy)
+		if overlapErr != nil {
+			return CreateAgentJobInput{}, overlapErr
+		}
+		overlapPolicy = normalizedOverlap
+	}
+	input.OverlapPolicy = &overlapPolicy
+
+	catchUpPolicy := AgentJobCatchUpRunOnce
+	if input.CatchUpPolicy != nil {
+		normalizedCatchUp, catchUpErr := normalizeAgentJobCatchUpPolicy(*input.CatchUpPolicy)
+		if catchUpErr != nil {
+			return CreateAgentJobInput{}, catchUpErr
+		}
+		catchUpPolicy = normalizedCatchUp
+	}
+	input.CatchUpPolicy = &catchUpPolicy
+
+	jitterMS := int64(0)
+	if input.JitterMS != nil {
+		jitterMS = *input.JitterMS
+	}
+	if jitterMS < 0 {
+		return CreateAgentJobInput{}, fmt.Errorf("%w: jitter_ms must not be negative", ErrValidation)
+	}
+	input.JitterMS = &jitterMS
+
+	if input.MaxAgentConcurrency != nil && *input.MaxAgentConcurrency <= 0 {
+		return CreateAgentJobInput{}, fmt.Errorf("%w: max_agent_concurrency must be greater than zero", ErrValidation)
+	}
+
+	input.SuccessPattern = normalizeOptionalAgentJobText(input.SuccessPattern)
+	input.SuccessJSONField = normalizeOptionalAgentJobText(input.SuccessJSONField)
+	input.SuccessClassifierPrompt = normalizeOptionalAgentJobText(input.SuccessClassifierPrompt)
+	if err := validateAgentJobSuccessCriteria(input.SuccessPattern, input.SuccessJSONField); err != nil {
+		return CreateAgentJobInput{}, err
+	}
+	if input.ReplyTimeoutMS != nil && *input.ReplyTimeoutMS <= 0 {
+		return CreateAgentJobInput{}, fmt.Errorf("%w: reply_timeout_ms must be greater than zero", ErrValidation)
+	}
+
 	switch input.ScheduleKind {
 	case AgentJobScheduleCron:
 		if input.CronExpr == nil {
@@ -1350,6 +1725,24 @@ func validateAgentJobRecord(job AgentJob) error {
 	if job.MaxFailures <= 0 {
 		return fmt.Errorf("%w: max_failures must be greater than zero", ErrValidation)
 	}
+	if _, err := normalizeAgentJobOverlapPolicy(job.OverlapPolicy); err != nil {
+		return err
+	}
+	if _, err := normalizeAgentJobCatchUpPolicy(job.CatchUpPolicy); err != nil {
+		return err
+	}
+	if job.JitterMS < 0 {
+		return fmt.Errorf("%w: jitter_ms must not be negative", ErrValidation)
+	}
+	if job.MaxAgentConcurrency != nil && *job.MaxAgentConcurrency <= 0 {
+		return fmt.Errorf("%w: max_agent_concurrency must be greater than zero", ErrValidation)
+	}
+	if err := validateAgentJobSuccessCriteria(job.SuccessPattern, job.SuccessJSONField); err != nil {
+		return err
+	}
+	if job.ReplyTimeoutMS != nil && *job.ReplyTimeoutMS <= 0 {
+		return fmt.Errorf("%w: reply_timeout_ms must be greater than zero", ErrValidation)
+	}
 	switch job.ScheduleKind {
 	case AgentJobScheduleCron:
 		if job.CronExpr == nil || strings.TrimSpace(*job.CronExpr) == "" {
@@ -1403,6 +1796,55 @@ func normalizeAgentJobStatus(raw string) (string, error) {
 	}
 }
 
+func normalizeAgentJobOverlapPolicy(raw string) (string, error) {
+	normalized := strings.TrimSpace(strings.ToLower(raw))
+	switch normalized {
+	case AgentJobOverlapSkip, AgentJobOverlapQueue, AgentJobOverlapReplace:
+		return normalized, nil
+	default:
+		return "", fmt.Errorf("%w: invalid overlap_policy", ErrValidation)
+	}
+}
+
+func normalizeAgentJobCatchUpPolicy(raw string) (string, error) {
+	normalized := strings.TrimSpace(strings.ToLower(strings.ReplaceAll(raw, "-", "_")))
+	switch normalized {
+	case AgentJobCatchUpRunOnce, AgentJobCatchUpRunAll, AgentJobCatchUpSkip:
+		return normalized, nil
+	default:
+		return "", fmt.Errorf("%w: invalid catch_up_policy", ErrValidation)
+	}
+}
+
+// validateAgentJobSuccessCriteria checks that a success pattern compiles and
+// that a required JSON field is a dotted path such as "result.status".
+func validateAgentJobSuccessCriteria(pattern, jsonField *string) error {
+	if pattern != nil {
+		if _, err := regexp.Compile(*pattern); err != nil {
+			return fmt.Errorf("%w: success_pattern is not a valid regular expression", ErrValidation)
+		}
+	}
+	if jsonField != nil {
+		for _, segment := range strings.Split(*jsonField, ".") {
+			if strings.TrimSpace(segment) == "" {
+				return fmt.Errorf("%w: success_json_field must be a dotted field path", ErrValidation)
+			}
+		}
+	}
+	return nil
+}
+
+func normalizeOptionalAgentJobText(value *string) *string {
+	if value == nil {
+		return nil
+	}
+	trimmed := strings.TrimSpace(*value)
+	if trimmed == "" {
+		return nil
+	}
+	return &trimmed
+}
+
 func normalizeAgentJobRunStatus(raw string) (string, error) {
 	normalized := strings.TrimSpace(strings.ToLower(raw))
 	switch normalized {
@@ -1425,6 +1867,11 @@ func scanAgentJob(scanner interface{ Scan(...any) error }) (AgentJob, error) {
 		lastRunStatus sql.NullString
 		lastRunError  sql.NullString
 		nextRunAt     sql.NullTime
+		maxAgentConc  sql.NullInt32
+		successRegex  sql.NullString
+		successField  sql.NullString
+		classifier    sql.NullString
+		replyTimeout  sql.NullInt64
 		createdBy     sql.NullString
 	)
 
@@ -1452,6 +1899,14 @@ func scanAgentJob(scanner interface{ Scan(...any) error }) (AgentJob, error) {
 		&job.ErrorCount,
 		&job.MaxFailures,
 		&job.ConsecutiveFailures,
+		&job.OverlapPolicy,
+		&job.CatchUpPolicy,
+		&job.JitterMS,
+		&maxAgentConc,
+		&successRegex,
+		&successField,
+		&classifier,
+		&replyTimeout,
 		&createdBy,
 		&job.CreatedAt,
 		&job.UpdatedAt,
@@ -1493,6 +1948,17 @@ func scanAgentJob(scanner interface{ Scan(...any) error }) (AgentJob, error) {
 		value := nextRunAt.Time.UTC()
 		job.NextRunAt = &value
 	}
+	if maxAgentConc.Valid {
+		value := int(maxAgentConc.Int32)
+		job.MaxAgentConcurrency = &value
+	}
+	job.SuccessPattern = nullableSQLStringPointer(successRegex)
+	job.SuccessJSONField = nullableSQLStringPointer(successField)
+	job.SuccessClassifierPrompt = nullableSQLStringPointer(classifier)
+	if replyTimeout.Valid {
+		value := replyTimeout.Int64
+		job.ReplyTimeoutMS = &value
+	}
 	if createdBy.Valid {
 		value := createdBy.String
 		job.CreatedBy = &value
@@ -1510,6 +1976,9 @@ func scanAgentJobRun(scanner interface{ Scan(...any) error }) (AgentJobRun, erro
 		durationMS  sql.NullInt32
 		runError    sql.NullString
 		messageID   sql.NullString
+		output      sql.NullString
+		replyID     sql.NullString
+		deadline    sql.NullTime
 	)
 
 	err := scanner.Scan(
@@ -1523,6 +1992,9 @@ func scanAgentJobRun(scanner interface{ Scan(...any) error }) (AgentJobRun, erro
 		&runError,
 		&run.PayloadText,
 		&messageID,
+		&output,
+		&replyID,
+		&deadline,
 		&run.CreatedAt,
 	)
 	if err != nil {
@@ -1545,12 +2017,35 @@ func scanAgentJobRun(scanner interface{ Scan(...any) error }) (AgentJobRun, erro
 		value := messageID.String
 		run.MessageID = &value
 	}
+	if output.Valid {
+		value := output.String
+		run.Output = &value
+	}
+	if replyID.Valid {
+		value := replyID.String
+		run.ReplyMessageID = &value
+	}
+	if deadline.Valid {
+		value := deadline.Time.UTC()
+		run.ReplyDeadlineAt = &value
+	}
 	run.StartedAt = run.StartedAt.UTC()
 	run.CreatedAt = run.CreatedAt.UTC()
 
 	return run, nil
 }
 
+// trailingColumnScanner scans a row whose leading columns belong to another
+// scanner (such as scanAgentJob) and whose trailing columns go to extra.
+type trailingColumnScanner struct {
+	scanner interface{ Scan(...any) error }
+	extra   []any
+}
+
+func (s trailingColumnScanner) Scan(dest ...any) error {
+	return s.scanner.Scan(append(dest, s.extra...)...)
+}
+
 func nullableInt64(value *int64) interface{} {
 	if value == nil {
 		return nil
@@ -1558,6 +2053,13 @@ func nullableInt64(value *int64) interface{} {
 	return *value
 }
 
+func nullableInt32(value *int) interface{} {
+	if value == nil {
+		return nil
+	}
+	return int32(*value)
+}
+
 func nullableSQLStringPointer(value sql.NullString) *string {
 	if !value.Valid {
 		return nil
@@ -6,6 +6,7 @@ import (
 	"strings"
 	"testing"
 	"time"
+	"unicode/utf8"
 
 	"github.com/stretchr/testify/require"
 )
@@ -286,6 +287,128 @@ func TestAgentJobStoreCleanupStaleRunning(t *testing.T) {
 	require.Equal(t, AgentJobRunStatusTimeout, staleStatus)
 }
 
+func TestAgentJobStoreSchedulingPoliciesRoundTrip(t *testing.T) {
+	connStr := getTestDatabaseURL(t)
+	db := setupTestDatabase(t, connStr)
+	orgID := createTestOrganization(t, db, "agent-job-store-policies")
+	agentID := createAgentJobTestAgent(t, db, orgID, "jobs-policy-agent")
+	ctx := ctxWithWorkspace(orgID)
+	store := NewAgentJobStore(db)
+
+	now := time.Date(2026, 2, 12, 21, 0, 0, 0, time.UTC)
+	dueAt := now.Add(-1 * time.Minute)
+	maxAgentConcurrency := 2
+
+	job, err := store.Create(ctx, CreateAgentJobInput{
+		AgentID:      agentID,
+		Name:         "Policy Job",
+		ScheduleKind: AgentJobScheduleInterval,
+		IntervalMS:   agentJobInt64Ptr(60000),
+		PayloadKind:  AgentJobPayloadMessage,
+		PayloadText:  "policy",
+		NextRunAt:    &dueAt,
+	})
+	require.NoError(t, err)
+	require.Equal(t, AgentJobOverlapSkip, job.OverlapPolicy)
+	require.Equal(t, AgentJobCatchUpRunOnce, job.CatchUpPolicy)
+	require.Equal(t, int64(0), job.JitterMS)
+	require.Nil(t, job.MaxAgentConcurrency)
+
+	updated, err := store.Update(ctx, job.ID, UpdateAgentJobInput{
+		OverlapPolicy:       agentJobStrPtr("replace"),
+		CatchUpPolicy:       agentJobStrPtr("run-all"),
+		JitterMS:            agentJobInt64Ptr(5000),
+		MaxAgentConcurrency: &maxAgentConcurrency,
+	})
+	require.NoError(t, err)
+	require.Equal(t, AgentJobOverlapReplace, updated.OverlapPolicy)
+	require.Equal(t, AgentJobCatchUpRunAll, updated.CatchUpPolicy)
+	require.Equal(t, int64(5000), updated.JitterMS)
+	require.NotNil(t, updated.MaxAgentConcurrency)
+	require.Equal(t, 2, *updated.MaxAgentConcurrency)
+
+	_, err = store.Update(ctx, job.ID, UpdateAgentJobInput{OverlapPolicy: agentJobStrPtr("parallel")})
+	require.ErrorIs(t, err, ErrValidation)
+
+	picked, err := store.PickupDue(ctx, 10, now)
+	require.NoError(t, err)
+	require.Len(t, picked, 1)
+	require.NotNil(t, picked[0].DueAt)
+	require.True(t, picked[0].DueAt.Equal(dueAt))
+
+	run, err := store.StartRun(ctx, StartAgentJobRunInput{JobID: job.ID, PayloadText: "policy", StartedAt: now})
+	require.NoError(t, err)
+	running, err := store.CountRunningRuns(ctx, job.ID)
+	require.NoError(t, err)
+	require.Equal(t, 1, running)
+	agentRunning, err := store.CountRunningRunsForAgent(ctx, agentID)
+	require.NoError(t, err)
+	require.Equal(t, 1, agentRunning)
+
+	superseded, err := store.SupersedeRunningRuns(ctx, job.ID, now, "replaced by newer run")
+	require.NoError(t, err)
+	require.Equal(t, 1, superseded)
+	runs, err := store.ListRuns(ctx, job.ID, 10)
+	require.NoError(t, err)
+	require.Len(t, runs, 1)
+	require.Equal(t, run.ID, runs[0].ID)
+	require.Equal(t, AgentJobRunStatusSkipped, runs[0].Status)
+
+	rescheduleAt := now.Add(time.Minute)
+	require.NoError(t, store.Reschedule(ctx, job.ID, &rescheduleAt))
+	loaded, err := store.GetByID(ctx, job.ID)
+	require.NoError(t, err)
+	require.True(t, derefTime(t, loaded.NextRunAt).Equal(rescheduleAt))
+}
+
+func TestAgentJobStoreBlackoutWindows(t *testing.T) {
+	connStr := getTestDatabaseURL(t)
+	db := setupTestDatabase(t, connStr)
+	orgID := createTestOrganization(t, db, "agent-job-store-blackouts")
+	otherOrgID := createTestOrganization(t, db, "agent-job-store-blackouts-other")
+	ctx := ctxWithWorkspace(orgID)
+	store := NewAgentJobStore(db)
+
+	startMinute := 120
+	endMinute := 180
+	created, err := store.CreateBlackoutWindow(ctx, CreateAgentJobBlackoutWindowInput{
+		Name:        "Maintenance",
+		DaysOfWeek:  []int{3, 1, 1},
+		StartDate:   agentJobStrPtr("2026-12-01"),
+		EndDate:     agentJobStrPtr("2026-12-31"),
+		StartMinute: &startMinute,
+		EndMinute:   &endMinute,
+	})
+	require.NoError(t, err)
+	require.Equal(t, orgID, created.OrgID)
+	require.Equal(t, []int{1, 3}, created.DaysOfWeek)
+	require.Equal(t, "2026-12-01", *created.StartDate)
+	require.Equal(t, "2026-12-31", *created.EndDate)
+	require.Equal(t, 120, created.StartMinute)
+	require.Equal(t, 180, created.EndMinute)
+
+	_, err = store.CreateBlackoutWindow(ctx, CreateAgentJobBlackoutWindowInput{Name: "Unbounded"})
+	require.ErrorIs(t, err, ErrValidation)
+	_, err = store.CreateBlackoutWindow(ctx, CreateAgentJobBlackoutWindowInput{
+		Name:      "Backwards",
+		StartDate: agentJobStrPtr("2026-12-31"),
+		EndDate:   agentJobStrPtr("2026-12-01"),
+	})
+	require.ErrorIs(t, err, ErrValidation)
+
+	listed, err := store.ListBlackoutWindows(ctx)
+	require.NoError(t, err)
+	require.Len(t, listed, 1)
+
+	otherListed, err := store.ListBlackoutWindows(ctxWithWorkspace(otherOrgID))
+	require.NoError(t, err)
+	require.Len(t, otherListed, 0)
+	require.ErrorIs(t, store.DeleteBlackoutWindow(ctxWithWorkspace(otherOrgID), created.ID), ErrNotFound)
+
+	require.NoError(t, store.DeleteBlackoutWindow(ctx, created.ID))
+	require.ErrorIs(t, store.DeleteBlackoutWindow(ctx, created.ID), ErrNotFound)
+}
+
 func agentJobStrPtr(v string) *string {
 	return &v
 }
@@ -309,3 +432,200 @@ func derefTime(t *testing.T, value *time.Time) time.Time {
 	require.NotNil(t, value)
 	return *value
 }
+
+func TestAgentJobStoreSuccessCriteriaRoundTrip(t *testing.T) {
+	connStr := getTestDatabaseURL(t)
+	db := setupTestDatabase(t, connStr)
+	orgID := createTestOrganization(t, db, "agent-job-store-criteria")
+	agentID := createAgentJobTestAgent(t, db, orgID, "jobs-criteria-agent")
+	ctx := ctxWithWorkspace(orgID)
+	store := NewAgentJobStore(db)
+
+	_, err := store.Create(ctx, CreateAgentJobInput{
+		AgentID:        agentID,
+		Name:           "Bad Pattern",
+		ScheduleKind:   AgentJobScheduleInterval,
+		IntervalMS:     agentJobInt64Ptr(60000),
+		PayloadKind:    AgentJobPayloadMessage,
+		PayloadText:    "criteria",
+		SuccessPattern: agentJobStrPtr("(unclosed"),
+	})
+	require.ErrorIs(t, err, ErrValidation)
+
+	_, err = store.Create(ctx, CreateAgentJobInput{
+		AgentID:          agentID,
+		Name:             "Bad Field",
+		ScheduleKind:     AgentJobScheduleInterval,
+		IntervalMS:       agentJobInt64Ptr(60000),
+		PayloadKind:      AgentJobPayloadMessage,
+		PayloadText:      "criteria",
+		SuccessJSONField: agentJobStrPtr("result..status"),
+	})
+	require.ErrorIs(t, err, ErrValidation)
+
+	job, err := store.Create(ctx, CreateAgentJobInput{
+		AgentID:                 agentID,
+		Name:                    "Criteria Job",
+		ScheduleKind:            AgentJobScheduleInterval,
+		IntervalMS:              agentJobInt64Ptr(60000),
+		PayloadKind:             AgentJobPayloadMessage,
+		PayloadText:             "criteria",
+		SuccessPattern:          agentJobStrPtr(" ^ok "),
+		SuccessJSONField:        agentJobStrPtr("result.status"),
+		SuccessClassifierPrompt: agentJobStrPtr("Did it work?"),
+		ReplyTimeoutMS:          agentJobInt64Ptr(120000),
+	})
+	require.NoError(t, err)
+	require.Equal(t, "^ok", *job.SuccessPattern)
+	require.Equal(t, "result.status", *job.SuccessJSONField)
+	require.Equal(t, "Did it work?", *job.SuccessClassifierPrompt)
+	require.Equal(t, int64(120000), derefInt64(t, job.ReplyTimeoutMS))
+
+	updated, err := store.Update(ctx, job.ID, UpdateAgentJobInput{
+		SuccessJSONField: agentJobStrPtr(""),
+		ReplyTimeoutMS:   agentJobInt64Ptr(0),
+	})
+	require.NoError(t, err)
+	require.Nil(t, updated.SuccessJSONField)
+	require.Nil(t, updated.ReplyTimeoutMS)
+	require.NotNil(t, updated.SuccessPattern)
+
+	_, err = store.Update(ctx, job.ID, UpdateAgentJobInput{ReplyTimeoutMS: agentJobInt64Ptr(-5)})
+	require.ErrorIs(t, err, ErrValidation)
+}
+
+func TestAgentJobStoreRunReplyLifecycle(t *testing.T) {
+	connStr := getTestDatabaseURL(t)
+	db := setupTestDatabase(t, connStr)
+	orgID := createTestOrganization(t, db, "agent-job-store-replies")
+	agentID := createAgentJobTestAgent(t, db, orgID, "jobs-reply-agent")
+	ctx := ctxWithWorkspace(orgID)
+	store := NewAgentJobStore(db)
+
+	now := time.Date(2026, 2, 12, 22, 0, 0, 0, time.UTC)
+	dueAt := now.Add(-time.Minute)
+	job, err := store.Create(ctx, CreateAgentJobInput{
+		AgentID:      agentID,
+		Name:         "Reply Job",
+		ScheduleKind: AgentJobScheduleInterval,
+		IntervalMS:   agentJobInt64Ptr(60000),
+		PayloadKind:  AgentJobPayloadMessage,
+		PayloadText:  "report status",
+		NextRunAt:    &dueAt,
+	})
+	require.NoError(t, err)
+
+	picked, err := store.PickupDue(ctx, 10, now)
+	require.NoError(t, err)
+	require.Len(t, picked, 1)
+
+	roomID, err := store.EnsureRoomForJob(ctx, job.ID)
+	require.NoError(t, err)
+	run, err := store.StartRun(ctx, StartAgentJobRunInput{JobID: job.ID, PayloadText: "report status", StartedAt: now})
+	require.NoError(t, err)
+	promptID, err := store.CreateJobMessage(ctx, CreateAgentJobMessageInput{
+		JobID:       job.ID,
+		OrgID:       orgID,
+		RoomID:      roomID,
+		PayloadKind: AgentJobPayloadMessage,
+		PayloadText: "report status",
+		CreatedAt:   now,
+	})
+	require.NoError(t, err)
+
+	nextRunAt := now.Add(time.Minute)
+	require.NoError(t, store.MarkRunAwaitingReply(ctx, AwaitAgentJobReplyInput{
+		JobID:           job.ID,
+		RunID:           run.ID,
+		MessageID:       promptID,
+		ReplyDeadlineAt: now.Add(5 * time.Minute),
+		NextRunAt:       &nextRunAt,
+	}))
+	leased, err := store.GetByID(ctx, job.ID)
+	require.NoError(t, err)
+	require.True(t, derefTime(t, leased.NextRunAt).Equal(nextRunAt))
+
+	replies, err := store.ListRunReplies(ctx, 10)
+	require.NoError(t, err)
+	require.Empty(t, replies)
+
+	// Replies backdated before the prompt are clamped so they still count.
+	replyID, err := store.CreateJobReply(ctx, CreateAgentJobReplyInput{
+		JobID:     job.ID,
+		RunID:     run.ID,
+		Body:      `ok {"result":{"status":"green"}}`,
+		CreatedAt: now.Add(-time.Hour),
+	})
+	require.NoError(t, err)
+
+	replies, err = store.ListRunReplies(ctx, 10)
+	require.NoError(t, err)
+	require.Len(t, replies, 1)
+	require.Equal(t, run.ID, replies[0].Run.ID)
+	require.Equal(t, replyID, replies[0].Reply.ID)
+	require.NotNil(t, replies[0].Run.ReplyDeadlineAt)
+
+	output := replies[0].Reply.Body
+	_, err = store.CompleteRun(ctx, CompleteAgentJobRunInput{
+		JobID:             job.ID,
+		RunID:             run.ID,
+		RunStatus:         AgentJobRunStatusSuccess,
+		CompletedAt:       now.Add(10 * time.Second),
+		Output:            &output,
+		ReplyMessageID:    &replyID,
+		PreserveNextRunAt: true,
+	})
+	require.NoError(t, err)
+
+	completed, err := store.GetByID(ctx, job.ID)
+	require.NoError(t, err)
+	require.True(t, derefTime(t, completed.NextRunAt).Equal(nextRunAt))
+	runs, err := store.ListRuns(ctx, job.ID, 10)
+	require.NoError(t, err)
+	require.Len(t, runs, 1)
+	require.Equal(t, output, *runs[0].Output)
+	require.Equal(t, replyID, *runs[0].ReplyMessageID)
+	require.Equal(t, promptID, *runs[0].MessageID)
+
+	_, err = store.CreateJobReply(ctx, CreateAgentJobReplyInput{JobID: job.ID, RunID: run.ID, Body: "late"})
+	require.ErrorIs(t, err, ErrConflict)
+
+	// A second run with a short deadline times out even though it is young.
+	late, err := store.StartRun(ctx, StartAgentJobRunInput{JobID: job.ID, PayloadText: "report status", StartedAt: now.Add(time.Minute)})
+	require.NoError(t, err)
+	latePromptID, err := store.CreateJobMessage(ctx, CreateAgentJobMessageInput{
+		JobID:       job.ID,
+		OrgID:       orgID,
+		RoomID:      roomID,
+		PayloadKind: AgentJobPayloadMessage,
+		PayloadText: "report status",
+		CreatedAt:   now.Add(time.Minute),
+	})
+	require.NoError(t, err)
+	require.NoError(t, store.MarkRunAwaitingReply(ctx, AwaitAgentJobReplyInput{
+		JobID:           job.ID,
+		RunID:           late.ID,
+		MessageID:       latePromptID,
+		ReplyDeadlineAt: now.Add(90 * time.Second),
+	}))
+
+	cleaned, err := store.CleanupStaleRuns(ctx, time.Hour, now.Add(2*time.Minute))
+	require.NoError(t, err)
+	require.Equal(t, 1, cleaned)
+	timedOut, err := store.GetByID(ctx, job.ID)
+	require.NoError(t, err)
+	require.NotNil(t, timedOut.LastRunError)
+	require.Equal(t, agentJobReplyTimeoutError, *timedOut.LastRunError)
+}
+
+func TestTruncateAgentJobRunOutput(t *testing.T) {
+	require.Nil(t, truncateAgentJobRunOutput(nil))
+
+	short := "done"
+	require.Equal(t, "done", *truncateAgentJobRunOutput(&short))
+
+	long := strings.Repeat("a", maxAgentJobRunOutputBytes-1) + "é"
+	truncated := truncateAgentJobRunOutput(&long)
+	require.Len(t, *truncated, maxAgentJobRunOutputBytes-1)
+	require.True(t, utf8.ValidString(*truncated))
+}
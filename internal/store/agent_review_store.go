@@ -0,0 +1,363 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	defaultAgentScorecardDays    = 30
+	defaultAgentScorecardPeriods = 2
+	maxAgentScorecardDays        = 366
+	maxAgentScorecardPeriods     = 12
+)
+
+// AgentScorecardAgent identifies the agent a scorecard is about.
+type AgentScorecardAgent struct {
+	ID          string `json:"id"`
+	Slug        string `json:"slug"`
+	DisplayName string `json:"display_name"`
+}
+
+// AgentScorecardStep is the agent's completed work on one pipeline step.
+type AgentScorecardStep struct {
+	StepID     string   `json:"step_id"`
+	StepName   string   `json:"step_name"`
+	StepType   string   `json:"step_type"`
+	Completed  int      `json:"completed"`
+	AvgSeconds *float64 `json:"avg_seconds,omitempty"`
+}
+
+// AgentScorecardPeriod holds an agent's metrics for [From, To).
+//
+// Rejections are attributed to the agent whose completed step the reviewer
+// sent back, i.e. the completion immediately before the rejection on the same
+// issue. Time in step runs from the previous history entry on the issue (or
+// the pipeline start) to the agent's completion.
+type AgentScorecardPeriod struct {
+	From                       time.Time            `json:"from"`
+	To                         time.Time            `json:"to"`
+	IssuesWorked               int                  `json:"issues_worked"`
+	StepsCompleted             int                  `json:"steps_completed"`
+	IssuesClosed               int                  `json:"issues_closed"`
+	FirstPassAcceptanceRate    *float64             `json:"first_pass_acceptance_rate,omitempty"`
+	Rejections                 int                  `json:"rejections"`
+	AvgRejectionsPerIssue      float64              `json:"avg_rejections_per_issue"`
+	AvgStepSeconds             *float64             `json:"avg_step_seconds,omitempty"`
+	Steps                      []AgentScorecardStep `json:"steps"`
+	ReviewsReceived            int                  `json:"reviews_received"`
+	ReviewsAddressed           int                  `json:"reviews_addressed"`
+	AvgReviewTurnaroundSeconds *float64             `json:"avg_review_turnaround_seconds,omitempty"`
+	FlowBlockersRaised         int                  `json:"flow_blockers_raised"`
+	ComplianceFailures         int                  `json:"compliance_failures"`
+	Runs                       int                  `json:"runs"`
+	FailedRuns                 int                  `json:"failed_runs"`
+	AvgRunSeconds              *float64             `json:"avg_run_seconds,omitempty"`
+	Tokens                     int64                `json:"tokens"`
+	CostUSD                    float64              `json:"cost_usd"`
+}
+
+// AgentScorecard is a performance review for one agent. Periods[0] is the
+// most recent window; later entries are the equally sized windows before it.
+type AgentScorecard struct {
+	Agent   AgentScorecardAgent    `json:"agent"`
+	Days    int                    `json:"days"`
+	Periods []AgentScorecardPeriod `json:"periods"`
+}
+
+type AgentScorecardInput struct {
+	// AgentID is an agent UUID or slug.
+	AgentID string
+	// End closes the most recent window; it is rounded up to the next UTC
+	// midnight so the current day is included.
+	End     time.Time
+	Days    int
+	Periods int
+}
+
+type AgentReviewStore struct {
+	db *sql.DB
+}
+
+func NewAgentReviewStore(db *sql.DB) *AgentReviewStore {
+	return &AgentReviewStore{db: db}
+}
+
+// Scorecard builds an agent's scorecard from pipeline history, review
+// versions, flow blockers, compliance findings, activity events and usage
+// rollups.
+func (s *AgentReviewStore) Scorecard(ctx context.Context, orgID string, input AgentScorecardInput) (*AgentScorecard, error) {
+	ctx, span := startStoreSpan(ctx, "AgentReviewStore.Scorecard")
+	defer span.End()
+
+	if s == nil || s.db == nil {
+		return nil, fmt.Errorf("agent review store is not configured")
+	}
+	days := input.Days
+	if days == 0 {
+		days = defaultAgentScorecardDays
+	}
+	if days < 1 || days > maxAgentScorecardDays {
+		return nil, fmt.Errorf("%w: days must be between 1 and %d", ErrValidation, maxAgentScorecardDays)
+	}
+	periods := input.Periods
+	if periods == 0 {
+		periods = defaultAgentScorecardPeriods
+	}
+	if periods < 1 || periods > maxAgentScorecardPeriods {
+		return nil, fmt.Errorf("%w: periods must be between 1 and %d", ErrValidation, maxAgentScorecardPeriods)
+	}
+	end := input.End
+	if end.IsZero() {
+		end = time.Now()
+	}
+	end = usageDay(end).AddDate(0, 0, 1)
+
+	conn, err := WithWorkspaceID(ctx, s.db, orgID)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	agent, err := resolveAgentScorecardAgent(ctx, conn, orgID, input.AgentID)
+	if err != nil {
+		return nil, err
+	}
+
+	card := &AgentScorecard{Agent: agent, Days: days, Periods: make([]AgentScorecardPeriod, 0, periods)}
+	for i := 0; i < periods; i++ {
+		to := end.AddDate(0, 0, -days*i)
+		from := to.AddDate(0, 0, -days)
+		period, err := agentScorecardPeriod(ctx, conn, orgID, agent, from, to)
+		if err != nil {
+			return nil, err
+		}
+		card.Periods = append(card.Periods, period)
+	}
+	return card, nil
+}
+
+func agentScorecardPeriod(ctx context.Context, q Querier, orgID string, agent AgentScorecardAgent, from, to time.Time) (AgentScorecardPeriod, error) {
+	period := AgentScorecardPeriod{From: from, To: to, Steps: []AgentScorecardStep{}}
+	if err := scoreAgentPipelineHistory(ctx, q, orgID, agent.ID, &period); err != nil {
+		return period, err
+	}
+
+	var reviewTurnaround sql.NullFloat64
+	err := q.QueryRowContext(
+		ctx,
+		`SELECT
+			COUNT(*) FILTER (WHERE v.created_at >= $3 AND v.created_at < $4),
+			COUNT(*) FILTER (WHERE v.addressed_at >= $3 AND v.addressed_at < $4),
+			AVG(EXTRACT(EPOCH FROM v.addressed_at - v.created_at))
+				FILTER (WHERE v.addressed_at >= $3 AND v.addressed_at < $4)
+		 FROM project_issue_review_versions v
+		 JOIN project_issues i ON i.id = v.issue_id
+		 WHERE v.org_id = $1 AND i.owner_agent_id = $2`,
+		orgID, agent.ID, from, to,
+	).Scan(&period.ReviewsReceived, &period.ReviewsAddressed, &reviewTurnaround)
+	if err != nil {
+		return period, fmt.Errorf("failed to score review turnaround: %w", err)
+	}
+	period.AvgReviewTurnaroundSeconds = nullFloatPtr(reviewTurnaround)
+
+	err = q.QueryRowContext(
+		ctx,
+		`SELECT
+			(SELECT COUNT(*) FROM project_issues
+			  WHERE org_id = $1 AND owner_agent_id = $2 AND closed_at >= $3 AND closed_at < $4),
+			(SELECT COUNT(*) FROM project_issue_flow_blockers
+			  WHERE org_id = $1 AND raised_by_agent_id = $2 AND raised_at >= $3 AND raised_at < $4),
+			(SELECT COUNT(*) FROM memories m
+			  JOIN project_issues i ON i.id::text = m.metadata->>'issue_id'
+			  WHERE m.org_id = $1
+			    AND i.owner_agent_id = $2
+			    AND m.metadata->>'source' = 'compliance_review'
+			    AND m.metadata->>'passed' = 'false'
+			    AND m.occurred_at >= $3 AND m.occurred_at < $4)`,
+		orgID, agent.ID, from, to,
+	).Scan(&period.IssuesClosed, &period.FlowBlockersRaised, &period.ComplianceFailures)
+	if err != nil {
+		return period, fmt.Errorf("failed to score issues, blockers and compliance: %w", err)
+	}
+
+	var avgRunMS sql.NullFloat64
+	err = q.QueryRowContext(
+		ctx,
+		`SELECT
+			COUNT(*),
+			COUNT(*) FILTER (WHERE status IN ('failed', 'timeout')),
+			AVG(duration_ms) FILTER (WHERE duration_ms > 0)
+		 FROM agent_activity_events
+		 WHERE org_id = $1 AND lower(agent_id) = lower($2) AND started_at >= $3 AND started_at < $4`,
+		orgID, agent.Slug, from, to,
+	).Scan(&period.Runs, &period.FailedRuns, &avgRunMS)
+	if err != nil {
+		return period, fmt.Errorf("failed to score agent runs: %w", err)
+	}
+	if avgRunMS.Valid {
+		seconds := avgRunMS.Float64 / 1000
+		period.AvgRunSeconds = &seconds
+	}
+
+	err = q.QueryRowContext(
+		ctx,
+		`SELECT COALESCE(SUM(tokens), 0), COALESCE(SUM(cost_usd), 0)
+		 FROM usage_daily_rollups
+		 WHERE org_id = $1 AND lower(agent_id) = lower($2) AND day >= $3 AND day < $4`,
+		orgID, agent.Slug, from, to,
+	).Scan(&period.Tokens, &period.CostUSD)
+	if err != nil {
+		return period, fmt.Errorf("failed to score agent cost: %w", err)
+	}
+	return period, nil
+}
+
+func scoreAgentPipelineHistory(ctx context.Context, q Querier, orgID, agentID string, period *AgentScorecardPeriod) error {
+	rows, err := q.QueryContext(
+		ctx,
+		`WITH history AS (
+			SELECT
+				h.issue_id,
+				h.step_id,
+				h.agent_id,
+				h.result,
+				COALESCE(h.completed_at, h.started_at) AS at,
+				LAG(COALESCE(h.completed_at, h.started_at)) OVER w AS prev_at,
+				LAG(h.agent_id) OVER w AS prev_agent_id,
+				LAG(h.result) OVER w AS prev_result
+			FROM issue_pipeline_history h
+			WHERE h.org_id = $1
+			  AND h.issue_id IN (
+				SELECT issue_id FROM issue_pipeline_history
+				WHERE org_id = $1 AND agent_id = $2
+			  )
+			WINDOW w AS (PARTITION BY h.issue_id ORDER BY COALESCE(h.completed_at, h.started_at), h.created_at)
+		)
+		SELECT
+			h.issue_id,
+			h.step_id,
+			s.name,
+			s.step_type,
+			h.result,
+			COALESCE(h.agent_id = $2, false),
+			COALESCE(h.prev_agent_id = $2 AND h.prev_result = 'completed', false),
+			EXTRACT(EPOCH FROM h.at - COALESCE(h.prev_at, i.pipeline_started_at))
+		FROM history h
+		JOIN pipeline_steps s ON s.id = h.step_id
+		JOIN project_issues i ON i.id = h.issue_id
+		WHERE h.at >= $3 AND h.at < $4`,
+		orgID, agentID, period.From, period.To,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to score pipeline history: %w", err)
+	}
+	defer rows.Close()
+
+	type stepTotals struct {
+		step    AgentScorecardStep
+		seconds float64
+		timed   int
+	}
+	worked := map[string]int{}
+	steps := map[string]*stepTotals{}
+	var totalSeconds float64
+	var timed int
+	for rows.Next() {
+		var issueID, stepID, stepName, stepType, result string
+		var byAgent, againstAgent bool
+		var seconds sql.NullFloat64
+		if err := rows.Scan(&issueID, &stepID, &stepName, &stepType, &result, &byAgent, &againstAgent, &seconds); err != nil {
+			return fmt.Errorf("failed to scan pipeline history score: %w", err)
+		}
+		switch {
+		case byAgent && result == IssuePipelineResultCompleted:
+			if _, ok := worked[issueID]; !ok {
+				worked[issueID] = 0
+			}
+			period.StepsCompleted++
+			totals, ok := steps[stepID]
+			if !ok {
+				totals = &stepTotals{step: AgentScorecardStep{StepID: stepID, StepName: stepName, StepType: stepType}}
+				steps[stepID] = totals
+			}
+			totals.step.Completed++
+			if seconds.Valid && seconds.Float64 >= 0 {
+				totals.seconds += seconds.Float64
+				totals.timed++
+				totalSeconds += seconds.Float64
+				timed++
+			}
+		case againstAgent && result == IssuePipelineResultRejected:
+			worked[issueID]++
+			period.Rejections++
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("failed reading pipeline history score: %w", err)
+	}
+
+	period.IssuesWorked = len(worked)
+	if period.IssuesWorked > 0 {
+		accepted := 0
+		for _, rejections := range worked {
+			if rejections == 0 {
+				accepted++
+			}
+		}
+		rate := float64(accepted) / float64(period.IssuesWorked)
+		period.FirstPassAcceptanceRate = &rate
+		period.AvgRejectionsPerIssue = float64(period.Rejections) / float64(period.IssuesWorked)
+	}
+	if timed > 0 {
+		avg := totalSeconds / float64(timed)
+		period.AvgStepSeconds = &avg
+	}
+	for _, totals := range steps {
+		if totals.timed > 0 {
+			avg := totals.seconds / float64(totals.timed)
+			totals.step.AvgSeconds = &avg
+		}
+		period.Steps = append(period.Steps, totals.step)
+	}
+	sort.Slice(period.Steps, func(i, j int) bool {
+		if period.Steps[i].Completed != period.Steps[j].Completed {
+			return period.Steps[i].Completed > period.Steps[j].Completed
+		}
+		return period.Steps[i].StepName < period.Steps[j].StepName
+	})
+	return nil
+}
+
+func resolveAgentScorecardAgent(ctx context.Context, q Querier, orgID, ref string) (AgentScorecardAgent, error) {
+	ref = strings.TrimSpace(ref)
+	if ref == "" {
+		return AgentScorecardAgent{}, fmt.Errorf("%w: agent is required", ErrValidation)
+	}
+	query := `SELECT id, slug, display_name FROM agents WHERE org_id = $1 AND lower(slug) = lower($2)`
+	if uuidRegex.MatchString(ref) {
+		query = `SELECT id, slug, display_name FROM agents WHERE org_id = $1 AND id = $2`
+	}
+	var agent AgentScorecardAgent
+	err := q.QueryRowContext(ctx, query, orgID, ref).Scan(&agent.ID, &agent.Slug, &agent.DisplayName)
+	if errors.Is(err, sql.ErrNoRows) {
+		return AgentScorecardAgent{}, ErrNotFound
+	}
+	if err != nil {
+		return AgentScorecardAgent{}, fmt.Errorf("failed to resolve agent: %w", err)
+	}
+	return agent, nil
+}
+
+func nullFloatPtr(value sql.NullFloat64) *float64 {
+	if !value.Valid {
+		return nil
+	}
+	v := value.Float64
+	return &v
+}
@@ -0,0 +1,109 @@
+package store
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestAgentReviewStoreScorecard(t *testing.T) {
+	connStr := getTestDatabaseURL(t)
+	db := setupTestDatabase(t, connStr)
+
+	orgID := createTestOrganization(t, db, "agent-review-org")
+	projectID := createPipelineStepTestProject(t, db, orgID, "Agent Review Project")
+	writerID := createPipelineStepTestAgent(t, db, orgID, "writer")
+	reviewerID := createPipelineStepTestAgent(t, db, orgID, "reviewer")
+	issueA := createPipelineStepTestIssue(t, db, orgID, projectID, "Rejected once")
+	issueB := createPipelineStepTestIssue(t, db, orgID, projectID, "Accepted first time")
+	ctx := ctxWithWorkspace(orgID)
+	pipeline := NewPipelineStepStore(db)
+	now := time.Now().UTC().Truncate(time.Second)
+
+	write, err := pipeline.CreateStep(ctx, CreatePipelineStepInput{
+		ProjectID: projectID, StepNumber: 1, Name: "Write", StepType: PipelineStepTypeAgentWork, AssignedAgentID: &writerID, AutoAdvance: true,
+	})
+	require.NoError(t, err)
+	review, err := pipeline.CreateStep(ctx, CreatePipelineStepInput{
+		ProjectID: projectID, StepNumber: 2, Name: "Review", StepType: PipelineStepTypeAgentReview, AssignedAgentID: &reviewerID, AutoAdvance: true,
+	})
+	require.NoError(t, err)
+
+	_, err = db.Exec(`UPDATE project_issues SET owner_agent_id = $1, pipeline_started_at = $2, closed_at = $3 WHERE id = $4`,
+		writerID, now.Add(-3*time.Hour), now.Add(-10*time.Minute), issueA)
+	require.NoError(t, err)
+	_, err = db.Exec(`UPDATE project_issues SET owner_agent_id = $1, pipeline_started_at = $2 WHERE id = $3`,
+		writerID, now.Add(-40*time.Minute), issueB)
+	require.NoError(t, err)
+
+	appendHistory := func(issueID, stepID, agentID, result string, at time.Time) {
+		t.Helper()
+		_, err := pipeline.AppendIssuePipelineHistory(ctx, CreateIssuePipelineHistoryInput{
+			IssueID: issueID, StepID: stepID, AgentID: &agentID, StartedAt: at, CompletedAt: &at, Result: result,
+		})
+		require.NoError(t, err)
+	}
+	appendHistory(issueA, write.ID, writerID, IssuePipelineResultCompleted, now.Add(-2*time.Hour))
+	appendHistory(issueA, review.ID, reviewerID, IssuePipelineResultRejected, now.Add(-90*time.Minute))
+	appendHistory(issueA, write.ID, writerID, IssuePipelineResultCompleted, now.Add(-time.Hour))
+	appendHistory(issueA, review.ID, reviewerID, IssuePipelineResultCompleted, now.Add(-30*time.Minute))
+	appendHistory(issueB, write.ID, writerID, IssuePipelineResultCompleted, now.Add(-20*time.Minute))
+
+	_, err = db.Exec(`INSERT INTO project_issue_review_versions (org_id, issue_id, document_path, review_commit_sha, reviewer_agent_id, addressed_in_commit_sha, addressed_at, created_at)
+		VALUES ($1, $2, 'posts/a.md', 'sha-1', $3, 'sha-2', $4, $5)`,
+		orgID, issueA, reviewerID, now.Add(-time.Hour), now.Add(-2*time.Hour))
+	require.NoError(t, err)
+	_, err = db.Exec(`INSERT INTO project_issue_flow_blockers (org_id, issue_id, project_id, raised_by_agent_id, summary)
+		VALUES ($1, $2, $3, $4, 'Need credentials')`, orgID, issueB, projectID, writerID)
+	require.NoError(t, err)
+	_, err = db.Exec(`INSERT INTO memories (org_id, kind, title, content, metadata, occurred_at)
+		VALUES ($1, 'anti_pattern', 'Missing alt text', 'Images need alt text', jsonb_build_object('source', 'compliance_review', 'issue_id', $2::text, 'passed', false), $3)`,
+		orgID, issueA, now.Add(-time.Hour))
+	require.NoError(t, err)
+	require.NoError(t, NewAgentActivityEventStore(db).CreateEvents(ctx, []CreateAgentActivityEventInput{
+		{ID: "review-run-1", AgentID: "writer", Trigger: "chat", Summary: "draft", Status: "completed", DurationMs: 4000, StartedAt: now.Add(-2 * time.Hour)},
+		{ID: "review-run-2", AgentID: "writer", Trigger: "chat", Summary: "retry", Status: "failed", DurationMs: 2000, StartedAt: now.Add(-time.Hour)},
+	}))
+
+	card, err := NewAgentReviewStore(db).Scorecard(ctx, orgID, AgentScorecardInput{AgentID: "Writer", End: now, Days: 7, Periods: 2})
+	require.NoError(t, err)
+	require.Equal(t, writerID, card.Agent.ID)
+	require.Len(t, card.Periods, 2)
+
+	current := card.Periods[0]
+	require.Equal(t, 2, current.IssuesWorked)
+	require.Equal(t, 3, current.StepsCompleted)
+	require.Equal(t, 1, current.IssuesClosed)
+	require.Equal(t, 1, current.Rejections)
+	require.NotNil(t, current.FirstPassAcceptanceRate)
+	require.InDelta(t, 0.5, *current.FirstPassAcceptanceRate, 1e-9)
+	require.InDelta(t, 0.5, current.AvgRejectionsPerIssue, 1e-9)
+	require.NotNil(t, current.AvgStepSeconds)
+	require.InDelta(t, (3600.0+1800.0+1200.0)/3, *current.AvgStepSeconds, 1)
+	require.Len(t, current.Steps, 1)
+	require.Equal(t, "Write", current.Steps[0].StepName)
+	require.Equal(t, 1, current.ReviewsReceived)
+	require.Equal(t, 1, current.ReviewsAddressed)
+	require.InDelta(t, 3600, *current.AvgReviewTurnaroundSeconds, 1)
+	require.Equal(t, 1, current.FlowBlockersRaised)
+	require.Equal(t, 1, current.ComplianceFailures)
+	require.Equal(t, 2, current.Runs)
+	require.Equal(t, 1, current.FailedRuns)
+	require.InDelta(t, 3, *current.AvgRunSeconds, 1e-9)
+
+	previous := card.Periods[1]
+	require.Equal(t, current.From, previous.To)
+	require.Zero(t, previous.StepsCompleted)
+	require.Nil(t, previous.FirstPassAcceptanceRate)
+
+	reviewerCard, err := NewAgentReviewStore(db).Scorecard(ctx, orgID, AgentScorecardInput{AgentID: reviewerID, End: now, Periods: 1})
+	require.NoError(t, err)
+	require.Equal(t, 1, reviewerCard.Periods[0].StepsCompleted)
+	require.Zero(t, reviewerCard.Periods[0].Rejections, "rejections count against the agent whose work was sent back")
+
+	_, err = NewAgentReviewStore(db).Scorecard(ctx, orgID, AgentScorecardInput{AgentID: "nobody"})
+	require.ErrorIs(t, err, ErrNotFound)
+	_, err = NewAgentReviewStore(db).Scorecard(ctx, orgID, AgentScorecardInput{AgentID: "writer", Periods: 13})
+	require.ErrorIs(t, err, ErrValidation)
+}
@@ -0,0 +1,475 @@
+package store
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+const (
+	// APIKeyPrefix marks integration API keys.
+	APIKeyPrefix          = "oc_key_"
+	apiKeyDisplayPrefix   = 12
+	maxAPIKeyNameLength   = 100
+	maxAPIKeyRestrictions = 100
+	apiKeyTouchInterval   = time.Minute
+)
+
+// APIKeyScope is one permission an API key can carry.
+type APIKeyScope struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+}
+
+// APIKeyScopes is the scope catalog. Read scopes cover GET requests of a
+// resource, write scopes everything else; jobs:run covers running, pausing
+// and resuming jobs and replying to runs.
+var APIKeyScopes = []APIKeyScope{
+	{Name: "projects:read", Description: "Read projects, content, commits and runs"},
+	{Name: "projects:write", Description: "Change projects, content, chat and pull requests"},
+	{Name: "issues:read", Description: "Read issues, reviews and labels"},
+	{Name: "issues:write", Description: "Create and update issues, comments, reviews and approvals"},
+	{Name: "pipelines:read", Description: "Read pipeline steps, roles, flow templates and workflows"},
+	{Name: "pipelines:write", Description: "Change pipelines, flow templates, deploy config and run workflows"},
+	{Name: "agents:read", Description: "Read agents and their activity"},
+	{Name: "memory:read", Description: "Read and search memory and shared knowledge"},
+	{Name: "memory:write", Description: "Write memory, shared knowledge and evaluations"},
+	{Name: "jobs:read", Description: "Read scheduled jobs, runs and blackouts"},
+	{Name: "jobs:write", Description: "Create, change and delete scheduled jobs and blackouts"},
+	{Name: "jobs:run", Description: "Run, pause and resume jobs and reply to runs"},
+	{Name: "activity:read", Description: "Read activity and emissions"},
+	{Name: "activity:write", Description: "Ingest activity events and emissions"},
+}
+
+// DefaultAPIKeyScopes are granted when a key is created without scopes.
+var DefaultAPIKeyScopes = []string{
+	"projects:read", "issues:read", "pipelines:read", "agents:read",
+	"memory:read", "jobs:read", "activity:read",
+}
+
+// IsAPIKeyScope reports whether scope is in the catalog.
+func IsAPIKeyScope(scope string) bool {
+	for _, definition := range APIKeyScopes {
+		if definition.Name == scope {
+			return true
+		}
+	}
+	return false
+}
+
+// APIKey is an integration key. The raw key is only returned at creation.
+type APIKey struct {
+	ID         string
+	OrgID      string
+	Name       string
+	Prefix     string
+	Scopes     []string
+	ProjectIDs []string
+	AgentIDs   []string
+	AllowedIPs []string
+	ExpiresAt  *time.Time
+	LastUsedAt *time.Time
+	LastUsedIP *string
+	CreatedBy  *string
+	CreatedAt  time.Time
+}
+
+type CreateAPIKeyInput struct {
+	Name       string
+	Scopes     []string
+	ProjectIDs []string
+	AgentIDs   []string
+	AllowedIPs []string
+	ExpiresAt  *time.Time
+	CreatedBy  *string
+}
+
+// HasScope reports whether the key carries scope.
+func (k *APIKey) HasScope(scope string) bool {
+	for _, granted := range k.Scopes {
+		if granted == scope {
+			return true
+		}
+	}
+	return false
+}
+
+// Expired reports whether the key has passed its expiry.
+func (k *APIKey) Expired(now time.Time) bool {
+	return k.ExpiresAt != nil && !now.Before(*k.ExpiresAt)
+}
+
+// AllowsIP reports whether ip matches the allowlist. An empty allowlist
+// allows every address.
+func (k *APIKey) AllowsIP(ip string) bool {
+	if len(k.AllowedIPs) == 0 {
+		return true
+	}
+	parsed := net.ParseIP(strings.TrimSpace(ip))
+	if parsed == nil {
+		return false
+	}
+	for _, entry := range k.AllowedIPs {
+		if _, network, err := net.ParseCIDR(entry); err == nil && network.Contains(parsed) {
+			return true
+		}
+	}
+	return false
+}
+
+// AllowsProject reports whether the key may act on projectID.
+func (k *APIKey) AllowsProject(projectID string) bool {
+	return len(k.ProjectIDs) == 0 || containsFold(k.ProjectIDs, projectID)
+}
+
+// AllowsAgent reports whether the key may act on agentID.
+func (k *APIKey) AllowsAgent(agentID string) bool {
+	return len(k.AgentIDs) == 0 || containsFold(k.AgentIDs, agentID)
+}
+
+// APIKeyStore manages integration API keys. api_keys is not under RLS
+// because keys are resolved before the workspace is known, so every query
+// filters by org explicitly.
+type APIKeyStore struct {
+	db *sql.DB
+}
+
+func NewAPIKeyStore(db *sql.DB) *APIKeyStore {
+	return &APIKeyStore{db: db}
+}
+
+const apiKeyColumns = `id::text, org_id::text, name, prefix, scopes, project_ids::text[],
+	agent_ids::text[], allowed_ips, expires_at, last_used_at, last_used_ip, created_by::text, created_at`
+
+func (s *APIKeyStore) List(ctx context.Context, orgID string) ([]APIKey, error) {
+	rows, err := s.db.QueryContext(ctx,
+		`SELECT `+apiKeyColumns+` FROM api_keys WHERE org_id = $1 ORDER BY created_at DESC`,
+		orgID,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list api keys: %w", err)
+	}
+	defer rows.Close()
+
+	keys := make([]APIKey, 0)
+	for rows.Next() {
+		key, err := scanAPIKey(rows)
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, key)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to list api keys: %w", err)
+	}
+	return keys, nil
+}
+
+// Create validates input, stores the key's hash and returns the key along
+// with the raw secret.
+func (s *APIKeyStore) Create(ctx context.Context, orgID string, input CreateAPIKeyInput) (*APIKey, string, error) {
+	name := strings.TrimSpace(input.Name)
+	if name == "" {
+		return nil, "", fmt.Errorf("%w: name is required", ErrValidation)
+	}
+	if len(name) > maxAPIKeyNameLength {
+		return nil, "", fmt.Errorf("%w: name must be at most %d characters", ErrValidation, maxAPIKeyNameLength)
+	}
+	scopes, err := normalizeAPIKeyScopes(input.Scopes)
+	if err != nil {
+		return nil, "", err
+	}
+	projectIDs, err := normalizeAPIKeyIDs("project_ids", input.ProjectIDs)
+	if err != nil {
+		return nil, "", err
+	}
+	agentIDs, err := normalizeAPIKeyIDs("agent_ids", input.AgentIDs)
+	if err != nil {
+		return nil, "", err
+	}
+	allowedIPs, err := normalizeAPIKeyAllowedIPs(input.AllowedIPs)
+	if err != nil {
+		return nil, "", err
+	}
+	if input.ExpiresAt != nil && !input.ExpiresAt.After(time.Now()) {
+		return nil, "", fmt.Errorf("%w: expires_at must be in the future", ErrValidation)
+	}
+	if err := s.requireOrgRows(ctx, orgID, "projects", "project", projectIDs); err != nil {
+		return nil, "", err
+	}
+	if err := s.requireOrgRows(ctx, orgID, "agents", "agent", agentIDs); err != nil {
+		return nil, "", err
+	}
+
+	rawKey, err := generateAPIKeySecret()
+	if err != nil {
+		return nil, "", err
+	}
+	row := s.db.QueryRowContext(ctx,
+		`INSERT INTO api_keys (org_id, name, prefix, key_hash, scopes, project_ids, agent_ids, allowed_ips, expires_at, created_by)
+		 VALUES ($1, $2, $3, $4, $5, $6::uuid[], $7::uuid[], $8, $9, $10)
+		 RETURNING `+apiKeyColumns,
+		orgID,
+		name,
+		rawKey[:apiKeyDisplayPrefix],
+		HashAPIKey(rawKey),
+		pq.Array(scopes),
+		pq.Array(projectIDs),
+		pq.Array(agentIDs),
+		pq.Array(allowedIPs),
+		input.ExpiresAt,
+		nullableString(input.CreatedBy),
+	)
+	key, err := scanAPIKey(row)
+	if err != nil {
+		return nil, "", err
+	}
+	return &key, rawKey, nil
+}
+
+func (s *APIKeyStore) Delete(ctx context.Context, orgID, id string) error {
+	id = strings.TrimSpace(id)
+	if !uuidRegex.MatchString(id) {
+		return fmt.Errorf("%w: invalid api key id", ErrValidation)
+	}
+	result, err := s.db.ExecContext(ctx, `DELETE FROM api_keys WHERE id = $1 AND org_id = $2`, id, orgID)
+	if err != nil {
+		return fmt.Errorf("failed to delete api key: %w", err)
+	}
+	if affected, _ := result.RowsAffected(); affected == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// Authenticate resolves a raw key. Expiry and restrictions are left to the
+// caller so it can report them precisely.
+func (s *APIKeyStore) Authenticate(ctx context.Context, rawKey string) (*APIKey, error) {
+	rawKey = strings.TrimSpace(rawKey)
+	if !strings.HasPrefix(rawKey, APIKeyPrefix) {
+		return nil, ErrNotFound
+	}
+	key, err := scanAPIKey(s.db.QueryRowContext(ctx,
+		`SELECT `+apiKeyColumns+` FROM api_keys WHERE key_hash = $1`,
+		HashAPIKey(rawKey),
+	))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &key, nil
+}
+
+// TouchLastUsed records use of a key, at most once per minute per key.
+func (s *APIKeyStore) TouchLastUsed(ctx context.Context, id, ip string) error {
+	_, err := s.db.ExecContext(ctx,
+		`UPDATE api_keys SET last_used_at = NOW(), last_used_ip = $2
+		 WHERE id = $1 AND (last_used_at IS NULL OR last_used_at < NOW() - $3::interval)`,
+		id,
+		nullableString(&ip),
+		fmt.Sprintf("%d seconds", int(apiKeyTouchInterval.Seconds())),
+	)
+	return err
+}
+
+// ProjectForIssue returns the project of an issue in orgID, or "" when the
+// issue does not exist.
+func (s *APIKeyStore) ProjectForIssue(ctx context.Context, orgID, issueID string) (string, error) {
+	if !uuidRegex.MatchString(issueID) {
+		return "", nil
+	}
+	conn, err := WithWorkspaceID(ctx, s.db, orgID)
+	if err != nil {
+		return "", err
+	}
+	defer conn.Close()
+
+	var projectID string
+	err = conn.QueryRowContext(ctx, `SELECT project_id::text FROM project_issues WHERE id = $1`, issueID).Scan(&projectID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return "", nil
+	}
+	return projectID, err
+}
+
+// HashAPIKey returns the stored hash of a raw key.
+func HashAPIKey(rawKey string) string {
+	sum := sha256.Sum256([]byte(rawKey))
+	return hex.EncodeToString(sum[:])
+}
+
+func generateAPIKeySecret() (string, error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return APIKeyPrefix + hex.EncodeToString(buf), nil
+}
+
+func (s *APIKeyStore) requireOrgRows(ctx context.Context, orgID, table, label string, ids []string) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	conn, err := WithWorkspaceID(ctx, s.db, orgID)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	var found int
+	if err := conn.QueryRowContext(ctx,
+		`SELECT COUNT(*) FROM `+table+` WHERE org_id = $1 AND id = ANY($2::uuid[])`,
+		orgID,
+		pq.Array(ids),
+	).Scan(&found); err != nil {
+		return fmt.Errorf("failed to check %ss: %w", label, err)
+	}
+	if found != len(ids) {
+		return fmt.Errorf("%w: unknown %s id", ErrValidation, label)
+	}
+	return nil
+}
+
+func normalizeAPIKeyScopes(values []string) ([]string, error) {
+	if len(values) == 0 {
+		return append([]string(nil), DefaultAPIKeyScopes...), nil
+	}
+	seen := make(map[string]bool, len(values))
+	out := make([]string, 0, len(values))
+	for _, value := range values {
+		scope := strings.TrimSpace(strings.ToLower(value))
+		if scope == "" || seen[scope] {
+			continue
+		}
+		if !IsAPIKeyScope(scope) {
+			return nil, fmt.Errorf("%w: unknown scope %q", ErrValidation, value)
+		}
+		seen[scope] = true
+		out = append(out, scope)
+	}
+	if len(out) == 0 {
+		return nil, fmt.Errorf("%w: at least one scope is required", ErrValidation)
+	}
+	sort.Strings(out)
+	return out, nil
+}
+
+func normalizeAPIKeyIDs(field string, values []string) ([]string, error) {
+	if len(values) > maxAPIKeyRestrictions {
+		return nil, fmt.Errorf("%w: %s allows at most %d entries", ErrValidation, field, maxAPIKeyRestrictions)
+	}
+	seen := make(map[string]bool, len(values))
+	out := make([]string, 0, len(values))
+	for _, value := range values {
+		id := strings.TrimSpace(strings.ToLower(value))
+		if id == "" || seen[id] {
+			continue
+		}
+		if !uuidRegex.MatchString(id) {
+			return nil, fmt.Errorf("%w: %s must contain UUIDs", ErrValidation, field)
+		}
+		seen[id] = true
+		out = append(out, id)
+	}
+	return out, nil
+}
+
+// normalizeAPIKeyAllowedIPs stores every entry as a CIDR; bare addresses
+// become /32 or /128.
+func normalizeAPIKeyAllowedIPs(values []string) ([]string, error) {
+	if len(values) > maxAPIKeyRestrictions {
+		return nil, fmt.Errorf("%w: allowed_ips allows at most %d entries", ErrValidation, maxAPIKeyRestrictions)
+	}
+	seen := make(map[string]bool, len(values))
+	out := make([]string, 0, len(values))
+	for _, value := range values {
+		entry := strings.TrimSpace(value)
+		if entry == "" {
+			continue
+		}
+		if !strings.Contains(entry, "/") {
+			ip := net.ParseIP(entry)
+			if ip == nil {
+				return nil, fmt.Errorf("%w: invalid allowed ip %q", ErrValidation, value)
+			}
+			if ip.To4() != nil {
+				entry = ip.String() + "/32"
+			} else {
+				entry = ip.String() + "/128"
+			}
+		}
+		_, network, err := net.ParseCIDR(entry)
+		if err != nil {
+			return nil, fmt.Errorf("%w: invalid allowed ip %q", ErrValidation, value)
+		}
+		entry = network.String()
+		if seen[entry] {
+			continue
+		}
+		seen[entry] = true
+		out = append(out, entry)
+	}
+	return out, nil
+}
+
+func containsFold(values []string, target string) bool {
+	target = strings.TrimSpace(target)
+	for _, value := range values {
+		if strings.EqualFold(value, target) {
+			return true
+		}
+	}
+	return false
+}
+
+func scanAPIKey(scanner interface{ Scan(...any) error }) (APIKey, error) {
+	var (
+		key        APIKey
+		expiresAt  sql.NullTime
+		lastUsedAt sql.NullTime
+		lastUsedIP sql.NullString
+		createdBy  sql.NullString
+	)
+	if err := scanner.Scan(
+		&key.ID,
+		&key.OrgID,
+		&key.Name,
+		&key.Prefix,
+		pq.Array(&key.Scopes),
+		pq.Array(&key.ProjectIDs),
+		pq.Array(&key.AgentIDs),
+		pq.Array(&key.AllowedIPs),
+		&expiresAt,
+		&lastUsedAt,
+		&lastUsedIP,
+		&createdBy,
+		&key.CreatedAt,
+	); err != nil {
+		return APIKey{}, err
+	}
+	if expiresAt.Valid {
+		key.ExpiresAt = &expiresAt.Time
+	}
+	if lastUsedAt.Valid {
+		key.LastUsedAt = &lastUsedAt.Time
+	}
+	if lastUsedIP.Valid {
+		key.LastUsedIP = &lastUsedIP.String
+	}
+	if createdBy.Valid {
+		key.CreatedBy = &createdBy.String
+	}
+	return key, nil
+}
@@ -0,0 +1,105 @@
+package store
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestNormalizeAPIKeyScopesDefaultsAndValidates(t *testing.T) {
+	scopes, err := normalizeAPIKeyScopes(nil)
+	require.NoError(t, err)
+	require.Equal(t, DefaultAPIKeyScopes, scopes)
+
+	scopes, err = normalizeAPIKeyScopes([]string{" Issues:Write ", "jobs:run", "issues:write"})
+	require.NoError(t, err)
+	require.Equal(t, []string{"issues:write", "jobs:run"}, scopes)
+
+	_, err = normalizeAPIKeyScopes([]string{"admin:all"})
+	require.ErrorIs(t, err, ErrValidation)
+	_, err = normalizeAPIKeyScopes([]string{" "})
+	require.ErrorIs(t, err, ErrValidation)
+	for _, scope := range DefaultAPIKeyScopes {
+		require.True(t, IsAPIKeyScope(scope), scope)
+	}
+}
+
+func TestNormalizeAPIKeyAllowedIPsStoresCIDRs(t *testing.T) {
+	ips, err := normalizeAPIKeyAllowedIPs([]string{"10.0.0.7", "10.1.2.3/16", "2001:db8::1", "10.0.0.7/32"})
+	require.NoError(t, err)
+	require.Equal(t, []string{"10.0.0.7/32", "10.1.0.0/16", "2001:db8::1/128"}, ips)
+
+	_, err = normalizeAPIKeyAllowedIPs([]string{"not-an-ip"})
+	require.ErrorIs(t, err, ErrValidation)
+	_, err = normalizeAPIKeyIDs("project_ids", []string{"nope"})
+	require.ErrorIs(t, err, ErrValidation)
+}
+
+func TestAPIKeyRestrictionChecks(t *testing.T) {
+	past := time.Now().Add(-time.Minute)
+	key := APIKey{
+		Scopes:     []string{"issues:read"},
+		ProjectIDs: []string{"11111111-1111-1111-1111-111111111111"},
+		AllowedIPs: []string{"10.0.0.0/8"},
+		ExpiresAt:  &past,
+	}
+	require.True(t, key.HasScope("issues:read"))
+	require.False(t, key.HasScope("issues:write"))
+	require.True(t, key.Expired(time.Now()))
+	require.True(t, key.AllowsIP("10.2.3.4"))
+	require.False(t, key.AllowsIP("192.168.1.1"))
+	require.False(t, key.AllowsIP("garbage"))
+	require.True(t, key.AllowsProject("11111111-1111-1111-1111-111111111111"))
+	require.False(t, key.AllowsProject("22222222-2222-2222-2222-222222222222"))
+	require.True(t, key.AllowsAgent("any"))
+	require.True(t, (&APIKey{}).AllowsIP("192.168.1.1"))
+	require.False(t, (&APIKey{}).Expired(time.Now()))
+}
+
+func TestAPIKeyStoreCreateAuthenticateAndDelete(t *testing.T) {
+	connStr := getTestDatabaseURL(t)
+	db := setupTestDatabase(t, connStr)
+	orgID := createTestOrganization(t, db, "api-keys-org")
+	otherOrgID := createTestOrganization(t, db, "api-keys-other")
+	projectID := createTestProject(t, db, orgID, "API Key Project")
+	otherProjectID := createTestProject(t, db, otherOrgID, "Other API Key Project")
+	keys := NewAPIKeyStore(db)
+	ctx := context.Background()
+
+	_, _, err := keys.Create(ctx, orgID, CreateAPIKeyInput{Name: "bridge", ProjectIDs: []string{otherProjectID}})
+	require.ErrorIs(t, err, ErrValidation)
+
+	expires := time.Now().Add(time.Hour)
+	created, rawKey, err := keys.Create(ctx, orgID, CreateAPIKeyInput{
+		Name:       "bridge",
+		Scopes:     []string{"issues:write", "issues:read"},
+		ProjectIDs: []string{projectID},
+		AllowedIPs: []string{"127.0.0.1"},
+		ExpiresAt:  &expires,
+	})
+	require.NoError(t, err)
+	require.Equal(t, []string{"issues:read", "issues:write"}, created.Scopes)
+	require.Equal(t, []string{projectID}, created.ProjectIDs)
+	require.Equal(t, rawKey[:apiKeyDisplayPrefix], created.Prefix)
+
+	found, err := keys.Authenticate(ctx, rawKey)
+	require.NoError(t, err)
+	require.Equal(t, created.ID, found.ID)
+	require.Equal(t, orgID, found.OrgID)
+	_, err = keys.Authenticate(ctx, rawKey+"x")
+	require.ErrorIs(t, err, ErrNotFound)
+
+	require.NoError(t, keys.TouchLastUsed(ctx, created.ID, "127.0.0.1"))
+	listed, err := keys.List(ctx, orgID)
+	require.NoError(t, err)
+	require.Len(t, listed, 1)
+	require.NotNil(t, listed[0].LastUsedAt)
+	require.Equal(t, "127.0.0.1", *listed[0].LastUsedIP)
+
+	require.ErrorIs(t, keys.Delete(ctx, otherOrgID, created.ID), ErrNotFound)
+	require.NoError(t, keys.Delete(ctx, orgID, created.ID))
+	_, err = keys.Authenticate(ctx, rawKey)
+	require.ErrorIs(t, err, ErrNotFound)
+}
@@ -0,0 +1,441 @@
+package store
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	AuditActorUser      = "user"
+	AuditActorAPIKey    = "api_key"
+	AuditActorWorkspace = "workspace"
+	AuditActorSystem    = "system"
+
+	defaultAuditListLimit = 100
+	maxAuditListLimit     = 1000
+	auditVerifyBatchSize  = 500
+)
+
+var auditActionPattern = regexp.MustCompile(`^[a-z][a-z0-9_]*(\.[a-z0-9_]+)+$`)
+
+// AuditEntry is one link in an org's audit chain.
+type AuditEntry struct {
+	ID         int64           `json:"id"`
+	OrgID      string          `json:"org_id"`
+	Seq        int64           `json:"seq"`
+	ActorType  string          `json:"actor_type"`
+	ActorID    string          `json:"actor_id"`
+	Action     string          `json:"action"`
+	TargetType string          `json:"target_type"`
+	TargetID   string          `json:"target_id"`
+	Before     json.RawMessage `json:"before,omitempty"`
+	After      json.RawMessage `json:"after,omitempty"`
+	Diff       json.RawMessage `json:"diff,omitempty"`
+	IP         string          `json:"ip"`
+	UserAgent  string          `json:"user_agent"`
+	CreatedAt  time.Time       `json:"created_at"`
+	PrevHash   string          `json:"prev_hash"`
+	Hash       string          `json:"hash"`
+}
+
+type AppendAuditEntryInput struct {
+	ActorType  string
+	ActorID    string
+	Action     string
+	TargetType string
+	TargetID   string
+	Before     json.RawMessage
+	After      json.RawMessage
+	IP         string
+	UserAgent  string
+}
+
+type AuditLogFilter struct {
+	Action     string
+	ActorID    string
+	TargetType string
+	TargetID   string
+	Since      *time.Time
+	Until      *time.Time
+	// BeforeSeq pages backwards: only entries with a lower seq are returned.
+	BeforeSeq int64
+	Limit     int
+}
+
+// AuditVerifyResult reports the first break in a chain, if any.
+type AuditVerifyResult struct {
+	Valid     bool   `json:"valid"`
+	Checked   int64  `json:"checked"`
+	BrokenSeq *int64 `json:"broken_seq,omitempty"`
+	Reason    string `json:"reason,omitempty"`
+}
+
+// AuditLogStore appends to and reads the audit log. Rows are immutable in
+// the database; Append serializes writers per org so the chain stays linear.
+type AuditLogStore struct {
+	db  *sql.DB
+	now func() time.Time
+}
+
+func NewAuditLogStore(db *sql.DB) *AuditLogStore {
+	return &AuditLogStore{db: db, now: time.Now}
+}
+
+const auditEntryColumns = `id, org_id::text, seq, actor_type, actor_id, action, target_type, target_id,
+	before, after, diff, ip, user_agent, created_at, prev_hash, hash`
+
+// Append adds an entry to orgID's chain and returns it.
+func (s *AuditLogStore) Append(ctx context.Context, orgID string, input AppendAuditEntryInput) (*AuditEntry, error) {
+	entry := AuditEntry{
+		OrgID:      strings.TrimSpace(orgID),
+		ActorType:  strings.TrimSpace(input.ActorType),
+		ActorID:    strings.TrimSpace(input.ActorID),
+		Action:     strings.TrimSpace(input.Action),
+		TargetType: strings.TrimSpace(input.TargetType),
+		TargetID:   strings.TrimSpace(input.TargetID),
+		IP:         strings.TrimSpace(input.IP),
+		UserAgent:  strings.TrimSpace(input.UserAgent),
+	}
+	switch entry.ActorType {
+	case AuditActorUser, AuditActorAPIKey, AuditActorWorkspace, AuditActorSystem:
+	default:
+		return nil, fmt.Errorf("%w: invalid actor type %q", ErrValidation, input.ActorType)
+	}
+	if !auditActionPattern.MatchString(entry.Action) {
+		return nil, fmt.Errorf("%w: invalid audit action %q", ErrValidation, input.Action)
+	}
+	var err error
+	if entry.Before, err = canonicalAuditJSON(input.Before); err != nil {
+		return nil, fmt.Errorf("%w: before: %v", ErrValidation, err)
+	}
+	if entry.After, err = canonicalAuditJSON(input.After); err != nil {
+		return nil, fmt.Errorf("%w: after: %v", ErrValidation, err)
+	}
+	if entry.Diff, err = AuditDiff(entry.Before, entry.After); err != nil {
+		return nil, err
+	}
+
+	tx, err := WithWorkspaceIDTx(ctx, s.db, entry.OrgID)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	if _, err := tx.ExecContext(ctx, `SELECT pg_advisory_xact_lock(hashtext('audit_log:' || $1))`, entry.OrgID); err != nil {
+		return nil, fmt.Errorf("failed to lock audit chain: %w", err)
+	}
+	err = tx.QueryRowContext(ctx,
+		`SELECT seq, hash FROM audit_log WHERE org_id = $1 ORDER BY seq DESC LIMIT 1`,
+		entry.OrgID,
+	).Scan(&entry.Seq, &entry.PrevHash)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("failed to read audit chain head: %w", err)
+	}
+	entry.Seq++
+	// Postgres keeps microseconds; hash what will be read back.
+	entry.CreatedAt = s.now().UTC().Truncate(time.Microsecond)
+	entry.Hash = AuditEntryHash(entry)
+
+	err = tx.QueryRowContext(ctx,
+		`INSERT INTO audit_log (org_id, seq, actor_type, actor_id, action, target_type, target_id,
+			before, after, diff, ip, user_agent, created_at, prev_hash, hash)
+		 VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15)
+		 RETURNING id`,
+		entry.OrgID, entry.Seq, entry.ActorType, entry.ActorID, entry.Action, entry.TargetType, entry.TargetID,
+		nullableJSON(entry.Before), nullableJSON(entry.After), nullableJSON(entry.Diff),
+		entry.IP, entry.UserAgent, entry.CreatedAt, entry.PrevHash, entry.Hash,
+	).Scan(&entry.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to append audit entry: %w", err)
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit audit entry: %w", err)
+	}
+	return &entry, nil
+}
+
+// List returns entries newest first.
+func (s *AuditLogStore) List(ctx context.Context, orgID string, filter AuditLogFilter) ([]AuditEntry, error) {
+	limit := filter.Limit
+	if limit <= 0 {
+		limit = defaultAuditListLimit
+	}
+	if limit > maxAuditListLimit {
+		limit = maxAuditListLimit
+	}
+
+	where := []string{"org_id = $1"}
+	args := []any{orgID}
+	add := func(clause string, value any) {
+		args = append(args, value)
+		where = append(where, fmt.Sprintf(clause, len(args)))
+	}
+	if action := strings.TrimSpace(filter.Action); action != "" {
+		if strings.HasSuffix(action, ".*") {
+			add("action LIKE $%d", strings.TrimSuffix(action, "*")+"%")
+		} else {
+			add("action = $%d", action)
+		}
+	}
+	if value := strings.TrimSpace(filter.ActorID); value != "" {
+		add("actor_id = $%d", value)
+	}
+	if value := strings.TrimSpace(filter.TargetType); value != "" {
+		add("target_type = $%d", value)
+	}
+	if value := strings.TrimSpace(filter.TargetID); value != "" {
+		add("target_id = $%d", value)
+	}
+	if filter.Since != nil {
+		add("created_at >= $%d", *filter.Since)
+	}
+	if filter.Until != nil {
+		add("created_at < $%d", *filter.Until)
+	}
+	if filter.BeforeSeq > 0 {
+		add("seq < $%d", filter.BeforeSeq)
+	}
+	args = append(args, limit)
+
+	conn, err := WithWorkspaceID(ctx, s.db, orgID)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	rows, err := conn.QueryContext(ctx,
+		`SELECT `+auditEntryColumns+` FROM audit_log WHERE `+strings.Join(where, " AND ")+
+			fmt.Sprintf(` ORDER BY seq DESC LIMIT $%d`, len(args)),
+		args...,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list audit entries: %w", err)
+	}
+	defer rows.Close()
+
+	entries := make([]AuditEntry, 0)
+	for rows.Next() {
+		entry, err := scanAuditEntry(rows)
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, entry)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to list audit entries: %w", err)
+	}
+	return entries, nil
+}
+
+// Verify walks orgID's chain in order, recomputing every hash and link.
+func (s *AuditLogStore) Verify(ctx context.Context, orgID string) (AuditVerifyResult, error) {
+	conn, err := WithWorkspaceID(ctx, s.db, orgID)
+	if err != nil {
+		return AuditVerifyResult{}, err
+	}
+	defer conn.Close()
+
+	result := AuditVerifyResult{Valid: true}
+	prevHash := ""
+	var lastSeq int64
+	for {
+		rows, err := conn.QueryContext(ctx,
+			`SELECT `+auditEntryColumns+` FROM audit_log WHERE org_id = $1 AND seq > $2 ORDER BY seq ASC LIMIT $3`,
+			orgID, lastSeq, auditVerifyBatchSize,
+		)
+		if err != nil {
+			return AuditVerifyResult{}, fmt.Errorf("failed to read audit chain: %w", err)
+		}
+		batch := make([]AuditEntry, 0, auditVerifyBatchSize)
+		for rows.Next() {
+			entry, err := scanAuditEntry(rows)
+			if err != nil {
+				rows.Close()
+				return AuditVerifyResult{}, err
+			}
+			batch = append(batch, entry)
+		}
+		if err := rows.Close(); err != nil {
+			return AuditVerifyResult{}, err
+		}
+		if len(batch) == 0 {
+			return result, nil
+		}
+
+		for _, entry := range batch {
+			reason := ""
+			switch {
+			case entry.Seq != lastSeq+1:
+				reason = fmt.Sprintf("sequence gap: expected %d", lastSeq+1)
+			case entry.PrevHash != prevHash:
+				reason = "previous hash does not match"
+			case AuditEntryHash(entry) != entry.Hash:
+				reason = "entry hash does not match its contents"
+			}
+			if reason != "" {
+				seq := entry.Seq
+				return AuditVerifyResult{Valid: false, Checked: result.Checked, BrokenSeq: &seq, Reason: reason}, nil
+			}
+			result.Checked++
+			prevHash = entry.Hash
+			lastSeq = entry.Seq
+		}
+	}
+}
+
+// AuditEntryHash is sha256 over the entry's canonical JSON, which includes
+// the previous entry's hash.
+func AuditEntryHash(entry AuditEntry) string {
+	payload, _ := json.Marshal(struct {
+		OrgID      string          `json:"org_id"`
+		Seq        int64           `json:"seq"`
+		ActorType  string          `json:"actor_type"`
+		ActorID    string          `json:"actor_id"`
+		Action     string          `json:"action"`
+		TargetType string          `json:"target_type"`
+		TargetID   string          `json:"target_id"`
+		Before     json.RawMessage `json:"before"`
+		After      json.RawMessage `json:"after"`
+		Diff       json.RawMessage `json:"diff"`
+		IP         string          `json:"ip"`
+		UserAgent  string          `json:"user_agent"`
+		CreatedAt  string          `json:"created_at"`
+		PrevHash   string          `json:"prev_hash"`
+	}{
+		OrgID:      entry.OrgID,
+		Seq:        entry.Seq,
+		ActorType:  entry.ActorType,
+		ActorID:    entry.ActorID,
+		Action:     entry.Action,
+		TargetType: entry.TargetType,
+		TargetID:   entry.TargetID,
+		Before:     auditHashJSON(entry.Before),
+		After:      auditHashJSON(entry.After),
+		Diff:       auditHashJSON(entry.Diff),
+		IP:         entry.IP,
+		UserAgent:  entry.UserAgent,
+		CreatedAt:  entry.CreatedAt.UTC().Format(time.RFC3339Nano),
+		PrevHash:   entry.PrevHash,
+	})
+	sum := sha256.Sum256(payload)
+	return hex.EncodeToString(sum[:])
+}
+
+// AuditDiff lists the top-level fields that differ between two JSON objects
+// as {"field": {"before": …, "after": …}}. Non-object values diff as a
+// whole under "value".
+func AuditDiff(before, after json.RawMessage) (json.RawMessage, error) {
+	if len(before) == 0 && len(after) == 0 {
+		return nil, nil
+	}
+	var beforeObj, afterObj map[string]json.RawMessage
+	beforeIsObj := len(before) == 0 || json.Unmarshal(before, &beforeObj) == nil
+	afterIsObj := len(after) == 0 || json.Unmarshal(after, &afterObj) == nil
+	if !beforeIsObj || !afterIsObj {
+		if bytes.Equal(before, after) {
+			return nil, nil
+		}
+		return json.Marshal(map[string]auditFieldChange{"value": {Before: auditHashJSON(before), After: auditHashJSON(after)}})
+	}
+
+	keys := make(map[string]bool, len(beforeObj)+len(afterObj))
+	for key := range beforeObj {
+		keys[key] = true
+	}
+	for key := range afterObj {
+		keys[key] = true
+	}
+	names := make([]string, 0, len(keys))
+	for key := range keys {
+		names = append(names, key)
+	}
+	sort.Strings(names)
+
+	changes := make(map[string]auditFieldChange)
+	for _, name := range names {
+		b, a := beforeObj[name], afterObj[name]
+		if bytes.Equal(b, a) {
+			continue
+		}
+		changes[name] = auditFieldChange{Before: auditHashJSON(b), After: auditHashJSON(a)}
+	}
+	if len(changes) == 0 {
+		return nil, nil
+	}
+	return json.Marshal(changes)
+}
+
+type auditFieldChange struct {
+	Before json.RawMessage `json:"before"`
+	After  json.RawMessage `json:"after"`
+}
+
+// canonicalAuditJSON re-encodes a document with sorted keys and no
+// insignificant whitespace, so it hashes the same after a JSONB round trip.
+func canonicalAuditJSON(raw json.RawMessage) (json.RawMessage, error) {
+	if len(bytes.TrimSpace(raw)) == 0 || bytes.Equal(bytes.TrimSpace(raw), []byte("null")) {
+		return nil, nil
+	}
+	decoder := json.NewDecoder(bytes.NewReader(raw))
+	decoder.UseNumber()
+	var value any
+	if err := decoder.Decode(&value); err != nil {
+		return nil, err
+	}
+	return json.Marshal(value)
+}
+
+func auditHashJSON(raw json.RawMessage) json.RawMessage {
+	canonical, err := canonicalAuditJSON(raw)
+	if err != nil || canonical == nil {
+		return json.RawMessage("null")
+	}
+	return canonical
+}
+
+func scanAuditEntry(scanner interface{ Scan(...any) error }) (AuditEntry, error) {
+	var (
+		entry                AuditEntry
+		before, after, diffs []byte
+	)
+	if err := scanner.Scan(
+		&entry.ID,
+		&entry.OrgID,
+		&entry.Seq,
+		&entry.ActorType,
+		&entry.ActorID,
+		&entry.Action,
+		&entry.TargetType,
+		&entry.TargetID,
+		&before,
+		&after,
+		&diffs,
+		&entry.IP,
+		&entry.UserAgent,
+		&entry.CreatedAt,
+		&entry.PrevHash,
+		&entry.Hash,
+	); err != nil {
+		return AuditEntry{}, err
+	}
+	if len(before) > 0 {
+		entry.Before = json.RawMessage(before)
+	}
+	if len(after) > 0 {
+		entry.After = json.RawMessage(after)
+	}
+	if len(diffs) > 0 {
+		entry.Diff = json.RawMessage(diffs)
+	}
+	return entry, nil
+}
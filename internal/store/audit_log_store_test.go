@@ -0,0 +1,134 @@
+package store
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestAuditDiffListsChangedTopLevelFields(t *testing.T) {
+	diff, err := AuditDiff(
+		json.RawMessage(`{"name":"a","enabled":true,"tags":["x"]}`),
+		json.RawMessage(`{"tags":["x"],"name":"b","priority":2}`),
+	)
+	require.NoError(t, err)
+	require.JSONEq(t, `{
+		"enabled":{"before":true,"after":null},
+		"name":{"before":"a","after":"b"},
+		"priority":{"before":null,"after":2}
+	}`, string(diff))
+
+	diff, err = AuditDiff(json.RawMessage(`{"a":1}`), json.RawMessage(`{"a":1}`))
+	require.NoError(t, err)
+	require.Nil(t, diff)
+
+	diff, err = AuditDiff(nil, json.RawMessage(`["x"]`))
+	require.NoError(t, err)
+	require.JSONEq(t, `{"value":{"before":null,"after":["x"]}}`, string(diff))
+}
+
+func TestAuditEntryHashSurvivesJSONBReordering(t *testing.T) {
+	entry := AuditEntry{
+		OrgID:     "11111111-1111-1111-1111-111111111111",
+		Seq:       3,
+		ActorType: AuditActorUser,
+		ActorID:   "u1",
+		Action:    "role.update",
+		Before:    json.RawMessage(`{"b":1,"a":[1,2]}`),
+		CreatedAt: time.Date(2026, 10, 18, 12, 0, 0, 123456000, time.UTC),
+		PrevHash:  "abc",
+	}
+	hash := AuditEntryHash(entry)
+
+	entry.Before = json.RawMessage(`{"a": [1, 2], "b": 1}`)
+	entry.CreatedAt = entry.CreatedAt.In(time.FixedZone("EST", -5*3600))
+	require.Equal(t, hash, AuditEntryHash(entry))
+
+	entry.PrevHash = "abd"
+	require.NotEqual(t, hash, AuditEntryHash(entry))
+}
+
+func TestAuditLogStoreAppendChainsAndVerifies(t *testing.T) {
+	connStr := getTestDatabaseURL(t)
+	db := setupTestDatabase(t, connStr)
+	orgID := createTestOrganization(t, db, "audit-org")
+	otherOrgID := createTestOrganization(t, db, "audit-other")
+	audit := NewAuditLogStore(db)
+	ctx := context.Background()
+
+	_, err := audit.Append(ctx, orgID, AppendAuditEntryInput{ActorType: "robot", Action: "role.create"})
+	require.ErrorIs(t, err, ErrValidation)
+	_, err = audit.Append(ctx, orgID, AppendAuditEntryInput{ActorType: AuditActorUser, Action: "Role Create"})
+	require.ErrorIs(t, err, ErrValidation)
+
+	first, err := audit.Append(ctx, orgID, AppendAuditEntryInput{
+		ActorType:  AuditActorUser,
+		ActorID:    "user-1",
+		Action:     "role.create",
+		TargetType: "role",
+		TargetID:   "r1",
+		After:      json.RawMessage(`{"name":"triage","capabilities":["issues.write"]}`),
+		IP:         "10.0.0.1",
+		UserAgent:  "otter-cli",
+	})
+	require.NoError(t, err)
+	require.Equal(t, int64(1), first.Seq)
+	require.Empty(t, first.PrevHash)
+
+	second, err := audit.Append(ctx, orgID, AppendAuditEntryInput{
+		ActorType:  AuditActorAPIKey,
+		ActorID:    "key-1",
+		Action:     "role.update",
+		TargetType: "role",
+		TargetID:   "r1",
+		Before:     json.RawMessage(`{"name":"triage","capabilities":["issues.write"]}`),
+		After:      json.RawMessage(`{"name":"triage","capabilities":[]}`),
+	})
+	require.NoError(t, err)
+	require.Equal(t, int64(2), second.Seq)
+	require.Equal(t, first.Hash, second.PrevHash)
+	require.JSONEq(t, `{"capabilities":{"before":["issues.write"],"after":[]}}`, string(second.Diff))
+
+	_, err = audit.Append(ctx, otherOrgID, AppendAuditEntryInput{ActorType: AuditActorSystem, Action: "workspace.restore"})
+	require.NoError(t, err)
+
+	listed, err := audit.List(ctx, orgID, AuditLogFilter{})
+	require.NoError(t, err)
+	require.Len(t, listed, 2)
+	require.Equal(t, int64(2), listed[0].Seq)
+
+	listed, err = audit.List(ctx, orgID, AuditLogFilter{Action: "role.*", ActorID: "user-1"})
+	require.NoError(t, err)
+	require.Len(t, listed, 1)
+	require.Equal(t, "10.0.0.1", listed[0].IP)
+
+	listed, err = audit.List(ctx, orgID, AuditLogFilter{BeforeSeq: 2})
+	require.NoError(t, err)
+	require.Len(t, listed, 1)
+	require.Equal(t, int64(1), listed[0].Seq)
+
+	result, err := audit.Verify(ctx, orgID)
+	require.NoError(t, err)
+	require.True(t, result.Valid)
+	require.Equal(t, int64(2), result.Checked)
+
+	_, err = db.Exec(`UPDATE audit_log SET actor_id = 'someone-else' WHERE org_id = $1 AND seq = 1`, orgID)
+	require.Error(t, err)
+
+	// Simulate tampering by someone who can bypass the append-only trigger.
+	_, err = db.Exec(`ALTER TABLE audit_log DISABLE TRIGGER audit_log_append_only_trg`)
+	require.NoError(t, err)
+	_, err = db.Exec(`UPDATE audit_log SET actor_id = 'someone-else' WHERE org_id = $1 AND seq = 1`, orgID)
+	_, enableErr := db.Exec(`ALTER TABLE audit_log ENABLE TRIGGER audit_log_append_only_trg`)
+	require.NoError(t, err)
+	require.NoError(t, enableErr)
+
+	result, err = audit.Verify(ctx, orgID)
+	require.NoError(t, err)
+	require.False(t, result.Valid)
+	require.NotNil(t, result.BrokenSeq)
+	require.Equal(t, int64(1), *result.BrokenSeq)
+}
@@ -23,6 +23,12 @@ type PendingMemoryEmbedding struct {
 	Content string
 }
 
+type PendingIssueEmbedding struct {
+	ID    string
+	OrgID string
+	Text  string
+}
+
 type ConversationEmbeddingStore struct {
 	db              *sql.DB
 	targetDimension int
@@ -231,6 +237,77 @@ func (s *ConversationEmbeddingStore) UpdateMemoryEmbedding(ctx context.Context,
 	return nil
 }
 
+func (s *ConversationEmbeddingStore) ListPendingIssues(ctx context.Context, limit int) ([]PendingIssueEmbedding, error) {
+	if s == nil || s.db == nil {
+		return nil, fmt.Errorf("conversation embedding store is not configured")
+	}
+	if limit <= 0 {
+		limit = 50
+	}
+	if limit > 500 {
+		limit = 500
+	}
+
+	rows, err := s.db.QueryContext(
+		ctx,
+		fmt.Sprintf(`SELECT id, org_id, title, body
+		 FROM project_issues
+		 WHERE %s IS NULL
+		   AND merged_into_issue_id IS NULL
+		 ORDER BY created_at ASC, id ASC
+		 LIMIT $1`, s.embeddingColumn()),
+		limit,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list pending issue embeddings: %w", err)
+	}
+	defer rows.Close()
+
+	pending := make([]PendingIssueEmbedding, 0, limit)
+	for rows.Next() {
+		var (
+			row   PendingIssueEmbedding
+			title string
+			body  sql.NullString
+		)
+		if err := rows.Scan(&row.ID, &row.OrgID, &title, &body); err != nil {
+			return nil, fmt.Errorf("failed to scan pending issue embedding row: %w", err)
+		}
+		var bodyText *string
+		if body.Valid {
+			bodyText = &body.String
+		}
+		row.Text = IssueEmbeddingText(title, bodyText)
+		pending = append(pending, row)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed reading pending issue embeddings: %w", err)
+	}
+	return pending, nil
+}
+
+func (s *ConversationEmbeddingStore) UpdateIssueEmbedding(ctx context.Context, issueID string, embedding []float64) error {
+	if s == nil || s.db == nil {
+		return fmt.Errorf("conversation embedding store is not configured")
+	}
+	vectorLiteral, err := formatVectorLiteral(embedding)
+	if err != nil {
+		return err
+	}
+	_, err = s.db.ExecContext(
+		ctx,
+		fmt.Sprintf(`UPDATE project_issues
+		 SET %s = $2::vector
+		 WHERE id = $1`, s.embeddingColumn()),
+		strings.TrimSpace(issueID),
+		vectorLiteral,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to update issue embedding: %w", err)
+	}
+	return nil
+}
+
 func formatVectorLiteral(values []float64) (string, error) {
 	if len(values) == 0 {
 		return "", ErrConversationEmbeddingVectorEmpty
@@ -73,6 +73,9 @@ func (s *EllieContextInjectionStore) ListPendingMessagesSince(
 	afterMessageID *string,
 	limit int,
 ) ([]EllieContextInjectionPendingMessage, error) {
+	ctx, span := startStoreSpan(ctx, "EllieContextInjectionStore.ListPendingMessagesSince")
+	defer span.End()
+
 	if s == nil || s.db == nil {
 		return nil, fmt.Errorf("ellie context injection store is not configured")
 	}
@@ -172,6 +175,9 @@ func (s *EllieContextInjectionStore) ListPendingMessagesSince(
 }
 
 func (s *EllieContextInjectionStore) UpdateMessageEmbedding(ctx context.Context, messageID string, embedding []float64) error {
+	ctx, span := startStoreSpan(ctx, "EllieContextInjectionStore.UpdateMessageEmbedding")
+	defer span.End()
+
 	if s == nil || s.db == nil {
 		return fmt.Errorf("ellie context injection store is not configured")
 	}
@@ -203,6 +209,9 @@ func (s *EllieContextInjectionStore) SearchMemoryCandidatesByEmbedding(
 	embedding []float64,
 	limit int,
 ) ([]EllieContextInjectionMemoryCandidate, error) {
+	ctx, span := startStoreSpan(ctx, "EllieContextInjectionStore.SearchMemoryCandidatesByEmbedding")
+	defer span.End()
+
 	if s == nil || s.db == nil {
 		return nil, fmt.Errorf("ellie context injection store is not configured")
 	}
@@ -297,6 +306,9 @@ func (s *EllieContextInjectionStore) WasInjectedSinceCompaction(
 	roomID,
 	memoryID string,
 ) (bool, error) {
+	ctx, span := startStoreSpan(ctx, "EllieContextInjectionStore.WasInjectedSinceCompaction")
+	defer span.End()
+
 	if s == nil || s.db == nil {
 		return false, fmt.Errorf("ellie context injection store is not configured")
 	}
@@ -344,6 +356,9 @@ func (s *EllieContextInjectionStore) RecordInjection(
 	memoryID string,
 	injectedAt time.Time,
 ) error {
+	ctx, span := startStoreSpan(ctx, "EllieContextInjectionStore.RecordInjection")
+	defer span.End()
+
 	if s == nil || s.db == nil {
 		return fmt.Errorf("ellie context injection store is not configured")
 	}
@@ -386,6 +401,9 @@ func (s *EllieContextInjectionStore) CreateInjectionMessage(
 	ctx context.Context,
 	input CreateEllieContextInjectionMessageInput,
 ) (string, error) {
+	ctx, span := startStoreSpan(ctx, "EllieContextInjectionStore.CreateInjectionMessage")
+	defer span.End()
+
 	if s == nil || s.db == nil {
 		return "", fmt.Errorf("ellie context injection store is not configured")
 	}
@@ -468,6 +486,9 @@ func (s *EllieContextInjectionStore) CountMessagesSinceLastContextInjection(
 	orgID,
 	roomID string,
 ) (int, error) {
+	ctx, span := startStoreSpan(ctx, "EllieContextInjectionStore.CountMessagesSinceLastContextInjection")
+	defer span.End()
+
 	if s == nil || s.db == nil {
 		return 0, fmt.Errorf("ellie context injection store is not configured")
 	}
@@ -519,6 +540,9 @@ func (s *EllieContextInjectionStore) CountRoomMessages(
 	orgID,
 	roomID string,
 ) (int, error) {
+	ctx, span := startStoreSpan(ctx, "EllieContextInjectionStore.CountRoomMessages")
+	defer span.End()
+
 	if s == nil || s.db == nil {
 		return 0, fmt.Errorf("ellie context injection store is not configured")
 	}
@@ -552,6 +576,9 @@ func (s *EllieContextInjectionStore) CountPriorInjections(
 	orgID,
 	roomID string,
 ) (int, error) {
+	ctx, span := startStoreSpan(ctx, "EllieContextInjectionStore.CountPriorInjections")
+	defer span.End()
+
 	if s == nil || s.db == nil {
 		return 0, fmt.Errorf("ellie context injection store is not configured")
 	}
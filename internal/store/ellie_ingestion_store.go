@@ -159,6 +159,9 @@ func (s *EllieIngestionStore) withWorkspaceConn(ctx context.Context, orgID strin
 }
 
 func (s *EllieIngestionStore) ListRoomsForIngestion(ctx context.Context, limit int) ([]EllieRoomIngestionCandidate, error) {
+	ctx, span := startStoreSpan(ctx, "EllieIngestionStore.ListRoomsForIngestion")
+	defer span.End()
+
 	if s == nil || s.db == nil {
 		return nil, fmt.Errorf("ellie ingestion store is not configured")
 	}
@@ -219,6 +222,9 @@ func (s *EllieIngestionStore) ListRoomsForIngestion(ctx context.Context, limit i
 }
 
 func (s *EllieIngestionStore) ListRoomsForIngestionByOrg(ctx context.Context, orgID string, limit int) ([]EllieRoomIngestionCandidate, error) {
+	ctx, span := startStoreSpan(ctx, "EllieIngestionStore.ListRoomsForIngestionByOrg")
+	defer span.End()
+
 	if s == nil || s.db == nil {
 		return nil, fmt.Errorf("ellie ingestion store is not configured")
 	}
@@ -290,6 +296,47 @@ func (s *EllieIngestionStore) ListRoomsForIngestionByOrg(ctx context.Context, or
 	return candidates, nil
 }
 
+// CountRoomsForIngestionAllOrgs counts rooms with messages past their
+// ingestion cursor across every org, for the ingestion backlog gauge.
+func (s *EllieIngestionStore) CountRoomsForIngestionAllOrgs(ctx context.Context) (int, error) {
+	if s == nil || s.db == nil {
+		return 0, fmt.Errorf("ellie ingestion store is not configured")
+	}
+
+	var count int
+	err := s.db.QueryRowContext(
+		ctx,
+		`WITH latest_room_messages AS (
+		     SELECT DISTINCT ON (org_id, room_id)
+		       org_id,
+		       room_id,
+		       id AS latest_message_id,
+		       created_at AS latest_message_created_at
+		     FROM chat_messages
+		     ORDER BY org_id, room_id, created_at DESC, id DESC
+		 )
+		 SELECT COUNT(*)
+		 FROM latest_room_messages latest
+		 JOIN rooms room
+		   ON room.org_id = latest.org_id
+		  AND room.id = latest.room_id
+		 LEFT JOIN ellie_ingestion_cursors cursor
+		   ON cursor.org_id = latest.org_id
+		  AND cursor.source_type = 'room'
+		  AND cursor.source_id = latest.room_id::text
+		 WHERE room.exclude_from_ingestion = FALSE
+		   AND (latest.latest_message_created_at, latest.latest_message_id) >
+		       (
+		         COALESCE(cursor.last_message_created_at, TIMESTAMPTZ '1970-01-01'),
+		         COALESCE(cursor.last_message_id, '00000000-0000-0000-0000-000000000000'::uuid)
+		       )`,
+	).Scan(&count)
+	if err != nil {
+		return 0, fmt.Errorf("failed to count ellie ingestion rooms: %w", err)
+	}
+	return count, nil
+}
+
 func (s *EllieIngestionStore) CountRoomsForIngestion(ctx context.Context, orgID string) (int, error) {
 	if s == nil || s.db == nil {
 		return 0, fmt.Errorf("ellie ingestion store is not configured")
@@ -345,6 +392,9 @@ func (s *EllieIngestionStore) CountRoomsForIngestion(ctx context.Context, orgID
 }
 
 func (s *EllieIngestionStore) GetRoomCursor(ctx context.Context, orgID, roomID string) (*EllieRoomCursor, error) {
+	ctx, span := startStoreSpan(ctx, "EllieIngestionStore.GetRoomCursor")
+	defer span.End()
+
 	if s == nil || s.db == nil {
 		return nil, fmt.Errorf("ellie ingestion store is not configured")
 	}
@@ -397,6 +447,9 @@ func (s *EllieIngestionStore) ListRoomMessagesSince(
 	afterMessageID *string,
 	limit int,
 ) ([]EllieIngestionMessage, error) {
+	ctx, span := startStoreSpan(ctx, "EllieIngestionStore.ListRoomMessagesSince")
+	defer span.End()
+
 	if s == nil || s.db == nil {
 		return nil, fmt.Errorf("ellie ingestion store is not configured")
 	}
@@ -483,6 +536,9 @@ func (s *EllieIngestionStore) ListRoomMessagesSince(
 }
 
 func (s *EllieIngestionStore) InsertExtractedMemory(ctx context.Context, input CreateEllieExtractedMemoryInput) (bool, error) {
+	ctx, span := startStoreSpan(ctx, "EllieIngestionStore.InsertExtractedMemory")
+	defer span.End()
+
 	if s == nil || s.db == nil {
 		return false, fmt.Errorf("ellie ingestion store is not configured")
 	}
@@ -717,6 +773,9 @@ func (s *EllieIngestionStore) CreateEllieExtractedMemory(ctx context.Context, in
 }
 
 func (s *EllieIngestionStore) UpsertRoomCursor(ctx context.Context, input UpsertEllieRoomCursorInput) error {
+	ctx, span := startStoreSpan(ctx, "EllieIngestionStore.UpsertRoomCursor")
+	defer span.End()
+
 	if s == nil || s.db == nil {
 		return fmt.Errorf("ellie ingestion store is not configured")
 	}
@@ -780,6 +839,9 @@ func (s *EllieIngestionStore) UpsertRoomCursor(ctx context.Context, input Upsert
 }
 
 func (s *EllieIngestionStore) CreateWindowRun(ctx context.Context, input CreateEllieIngestionWindowRunInput) error {
+	ctx, span := startStoreSpan(ctx, "EllieIngestionStore.CreateWindowRun")
+	defer span.End()
+
 	if s == nil || s.db == nil {
 		return fmt.Errorf("ellie ingestion store is not configured")
 	}
@@ -0,0 +1,458 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/samhotchkiss/otter-camp/internal/middleware"
+)
+
+const (
+	DefaultEmissionRetention = 7 * 24 * time.Hour
+	defaultEmissionListLimit = 25
+	maxEmissionListLimit     = 500
+
+	// emissionPruneInterval bounds how often Append sweeps expired rows for an
+	// org, so steady ingest does not turn into a DELETE per batch.
+	emissionPruneInterval = time.Minute
+)
+
+// Emission is one persisted agent/bridge emission. Seq is assigned on insert
+// and, after the inserting transaction, orders the stream; ID is the
+// emitter's identifier and the replay cursor.
+type Emission struct {
+	Seq             int64
+	ID              string
+	OrgID           string
+	SourceType      string
+	SourceID        string
+	Kind            string
+	Summary         string
+	Detail          *string
+	ProjectID       *string
+	IssueID         *string
+	IssueNumber     *int64
+	ProgressCurrent *int
+	ProgressTotal   *int
+	ProgressUnit    *string
+	Timestamp       time.Time
+	CreatedAt       time.Time
+}
+
+// EmissionFilter narrows List. Without AfterID the newest matching emissions
+// are returned newest first; with AfterID, emissions recorded after that one
+// are returned oldest first for replay.
+type EmissionFilter struct {
+	AfterID    string
+	SourceID   string
+	SourceType string
+	Kind       string
+	ProjectID  string
+	IssueID    string
+	Limit      int
+}
+
+type EmissionStore struct {
+	db        *sql.DB
+	retention time.Duration
+
+	mu         sync.Mutex
+	lastPruned map[string]time.Time
+}
+
+func NewEmissionStore(db *sql.DB, retention time.Duration) *EmissionStore {
+	if retention <= 0 {
+		retention = DefaultEmissionRetention
+	}
+	return &EmissionStore{
+		db:         db,
+		retention:  retention,
+		lastPruned: make(map[string]time.Time),
+	}
+}
+
+const emissionColumns = `
+	seq,
+	id,
+	org_id,
+	source_type,
+	source_id,
+	kind,
+	summary,
+	detail,
+	project_id,
+	issue_id,
+	issue_number,
+	progress_current,
+	progress_total,
+	progress_unit,
+	emitted_at,
+	created_at
+`
+
+// Append records emissions for the workspace in ctx and refreshes each
+// source's latest entry. Emissions whose ID was already recorded are skipped;
+// the returned slice holds only the newly stored rows, in insert order.
+func (s *EmissionStore) Append(ctx context.Context, emissions []Emission) ([]Emission, error) {
+	if s == nil || s.db == nil {
+		return nil, fmt.Errorf("emission store is not configured")
+	}
+	workspaceID := middleware.WorkspaceFromContext(ctx)
+	if workspaceID == "" {
+		return nil, ErrNoWorkspace
+	}
+	if len(emissions) == 0 {
+		return []Emission{}, nil
+	}
+	for _, emission := range emissions {
+		if strings.TrimSpace(emission.ID) == "" {
+			return nil, fmt.Errorf("%w: emission id is required", ErrValidation)
+		}
+		if strings.TrimSpace(emission.SourceID) == "" {
+			return nil, fmt.Errorf("%w: source_id is required", ErrValidation)
+		}
+	}
+
+	tx, err := WithWorkspaceTx(ctx, s.db)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	inserted := make([]Emission, 0, len(emissions))
+	for _, emission := range emissions {
+		timestamp := emission.Timestamp.UTC()
+		if emission.Timestamp.IsZero() {
+			timestamp = time.Now().UTC()
+		}
+		record, err := scanEmission(tx.QueryRowContext(
+			ctx,
+			`INSERT INTO emissions (
+				id,
+				org_id,
+				source_type,
+				source_id,
+				kind,
+				summary,
+				detail,
+				project_id,
+				issue_id,
+				issue_number,
+				progress_current,
+				progress_total,
+				progress_unit,
+				emitted_at
+			) VALUES (
+				$1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14
+			)
+			ON CONFLICT (org_id, id) DO NOTHING
+			RETURNING`+emissionColumns,
+			strings.TrimSpace(emission.ID),
+			workspaceID,
+			emission.SourceType,
+			strings.TrimSpace(emission.SourceID),
+			emission.Kind,
+			emission.Summary,
+			nullableString(emission.Detail),
+			nullableString(emission.ProjectID),
+			nullableString(emission.IssueID),
+			nullableInt64(emission.IssueNumber),
+			nullableEmissionInt(emission.ProgressCurrent),
+			nullableEmissionInt(emission.ProgressTotal),
+			nullableString(emission.ProgressUnit),
+			timestamp,
+		))
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				continue
+			}
+			return nil, fmt.Errorf("failed to append emission: %w", err)
+		}
+
+		_, err = tx.ExecContext(
+			ctx,
+			`INSERT INTO emission_latest_by_source (
+				org_id,
+				source_id,
+				seq,
+				id,
+				source_type,
+				kind,
+				summary,
+				detail,
+				project_id,
+				issue_id,
+				issue_number,
+				progress_current,
+				progress_total,
+				progress_unit,
+				emitted_at,
+				created_at
+			)
+			SELECT org_id, source_id, seq, id, source_type, kind, summary, detail,
+			       project_id, issue_id, issue_number, progress_current, progress_total,
+			       progress_unit, emitted_at, created_at
+			FROM emissions
+			WHERE seq = $1
+			ON CONFLICT (org_id, source_id) DO UPDATE SET
+				seq = EXCLUDED.seq,
+				id = EXCLUDED.id,
+				source_type = EXCLUDED.source_type,
+				kind = EXCLUDED.kind,
+				summary = EXCLUDED.summary,
+				detail = EXCLUDED.detail,
+				project_id = EXCLUDED.project_id,
+				issue_id = EXCLUDED.issue_id,
+				issue_number = EXCLUDED.issue_number,
+				progress_current = EXCLUDED.progress_current,
+				progress_total = EXCLUDED.progress_total,
+				progress_unit = EXCLUDED.progress_unit,
+				emitted_at = EXCLUDED.emitted_at,
+				created_at = EXCLUDED.created_at
+			WHERE emission_latest_by_source.seq < EXCLUDED.seq`,
+			record.Seq,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to update latest emission for source: %w", err)
+		}
+		inserted = append(inserted, record)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit emissions: %w", err)
+	}
+
+	if s.shouldPrune(workspaceID, time.Now().UTC()) {
+		_, _ = s.PruneExpired(ctx, time.Now().UTC())
+	}
+	return inserted, nil
+}
+
+// List returns emissions for the workspace in ctx; see EmissionFilter for
+// ordering. An AfterID that is not (or no longer) stored returns ErrNotFound.
+func (s *EmissionStore) List(ctx context.Context, filter EmissionFilter) ([]Emission, error) {
+	if s == nil || s.db == nil {
+		return nil, fmt.Errorf("emission store is not configured")
+	}
+	limit := filter.Limit
+	if limit <= 0 {
+		limit = defaultEmissionListLimit
+	}
+	if limit > maxEmissionListLimit {
+		limit = maxEmissionListLimit
+	}
+
+	conn, err := WithWorkspace(ctx, s.db)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	// seq is drawn at insert but becomes visible at commit, so a row can show
+	// up behind one a reader has already passed. Rows are ordered by their
+	// inserting transaction and only listed once every older transaction has
+	// finished; anything later is held back until the next read.
+	conditions := []string{
+		"org_id = current_org_id()",
+		"tx_id < pg_snapshot_xmin(pg_current_snapshot())",
+	}
+	args := []any{}
+	addCondition := func(column, value string) {
+		value = strings.TrimSpace(value)
+		if value == "" {
+			return
+		}
+		args = append(args, value)
+		conditions = append(conditions, fmt.Sprintf("%s = $%d", column, len(args)))
+	}
+	addCondition("source_id", filter.SourceID)
+	addCondition("source_type", strings.ToLower(filter.SourceType))
+	addCondition("kind", strings.ToLower(filter.Kind))
+	addCondition("project_id", filter.ProjectID)
+	addCondition("issue_id", filter.IssueID)
+
+	order := "DESC"
+	if afterID := strings.TrimSpace(filter.AfterID); afterID != "" {
+		var (
+			afterTxID string
+			afterSeq  int64
+		)
+		err := conn.QueryRowContext(
+			ctx,
+			`SELECT tx_id::text, seq FROM emissions WHERE org_id = current_org_id() AND id = $1`,
+			afterID,
+		).Scan(&afterTxID, &afterSeq)
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return nil, ErrNotFound
+			}
+			return nil, fmt.Errorf("failed to resolve emission cursor: %w", err)
+		}
+		args = append(args, afterTxID, afterSeq)
+		conditions = append(conditions, fmt.Sprintf("(tx_id, seq) > ($%d::xid8, $%d)", len(args)-1, len(args)))
+		order = "ASC"
+	}
+	args = append(args, limit)
+
+	query := `SELECT` + emissionColumns + ` FROM emissions WHERE ` +
+		strings.Join(conditions, " AND ") +
+		` ORDER BY tx_id ` + order + `, seq ` + order +
+		fmt.Sprintf(" LIMIT $%d", len(args))
+	rows, err := conn.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list emissions: %w", err)
+	}
+	defer rows.Close()
+
+	out := make([]Emission, 0)
+	for rows.Next() {
+		emission, scanErr := scanEmission(rows)
+		if scanErr != nil {
+			return nil, fmt.Errorf("failed to scan emission: %w", scanErr)
+		}
+		out = append(out, emission)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read emissions: %w", err)
+	}
+	return out, nil
+}
+
+// LatestBySource returns the most recent emission from a source, or nil when
+// the source has never emitted. It survives retention pruning of the log.
+func (s *EmissionStore) LatestBySource(ctx context.Context, sourceID string) (*Emission, error) {
+	if s == nil || s.db == nil {
+		return nil, fmt.Errorf("emission store is not configured")
+	}
+	sourceID = strings.TrimSpace(sourceID)
+	if sourceID == "" {
+		return nil, nil
+	}
+
+	conn, err := WithWorkspace(ctx, s.db)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	emission, err := scanEmission(conn.QueryRowContext(
+		ctx,
+		`SELECT`+emissionColumns+`
+		 FROM emission_latest_by_source
+		 WHERE org_id = current_org_id() AND source_id = $1`,
+		sourceID,
+	))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to load latest emission: %w", err)
+	}
+	return &emission, nil
+}
+
+// PruneExpired deletes the workspace's emissions recorded before the
+// retention window. Latest-by-source entries are kept.
+func (s *EmissionStore) PruneExpired(ctx context.Context, now time.Time) (int, error) {
+	if s == nil || s.db == nil {
+		return 0, fmt.Errorf("emission store is not configured")
+	}
+	if now.IsZero() {
+		now = time.Now().UTC()
+	}
+
+	conn, err := WithWorkspace(ctx, s.db)
+	if err != nil {
+		return 0, err
+	}
+	defer conn.Close()
+
+	result, err := conn.ExecContext(
+		ctx,
+		`DELETE FROM emissions WHERE org_id = current_org_id() AND created_at < $1`,
+		now.UTC().Add(-s.retention),
+	)
+	if err != nil {
+		return 0, fmt.Errorf("failed to prune emissions: %w", err)
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("failed to prune emissions: %w", err)
+	}
+	return int(affected), nil
+}
+
+func (s *EmissionStore) shouldPrune(workspaceID string, now time.Time) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if last, ok := s.lastPruned[workspaceID]; ok && now.Sub(last) < emissionPruneInterval {
+		return false
+	}
+	s.lastPruned[workspaceID] = now
+	return true
+}
+
+func scanEmission(scanner interface{ Scan(...any) error }) (Emission, error) {
+	var (
+		emission        Emission
+		detail          sql.NullString
+		projectID       sql.NullString
+		issueID         sql.NullString
+		issueNumber     sql.NullInt64
+		progressCurrent sql.NullInt64
+		progressTotal   sql.NullInt64
+		progressUnit    sql.NullString
+	)
+	err := scanner.Scan(
+		&emission.Seq,
+		&emission.ID,
+		&emission.OrgID,
+		&emission.SourceType,
+		&emission.SourceID,
+		&emission.Kind,
+		&emission.Summary,
+		&detail,
+		&projectID,
+		&issueID,
+		&issueNumber,
+		&progressCurrent,
+		&progressTotal,
+		&progressUnit,
+		&emission.Timestamp,
+		&emission.CreatedAt,
+	)
+	if err != nil {
+		return Emission{}, err
+	}
+	emission.Detail = nullableSQLStringPointer(detail)
+	emission.ProjectID = nullableSQLStringPointer(projectID)
+	emission.IssueID = nullableSQLStringPointer(issueID)
+	emission.ProgressUnit = nullableSQLStringPointer(progressUnit)
+	if issueNumber.Valid {
+		value := issueNumber.Int64
+		emission.IssueNumber = &value
+	}
+	if progressCurrent.Valid {
+		value := int(progressCurrent.Int64)
+		emission.ProgressCurrent = &value
+	}
+	if progressTotal.Valid {
+		value := int(progressTotal.Int64)
+		emission.ProgressTotal = &value
+	}
+	emission.Timestamp = emission.Timestamp.UTC()
+	emission.CreatedAt = emission.CreatedAt.UTC()
+	return emission, nil
+}
+
+func nullableEmissionInt(value *int) interface{} {
+	if value == nil {
+		return nil
+	}
+	return *value
+}
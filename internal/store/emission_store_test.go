@@ -0,0 +1,150 @@
+package store
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestEmissionStoreAppendListAndLatest(t *testing.T) {
+	connStr := getTestDatabaseURL(t)
+	db := setupTestDatabase(t, connStr)
+	orgID := createTestOrganization(t, db, "emission-store")
+	otherOrgID := createTestOrganization(t, db, "emission-store-other")
+	ctx := ctxWithWorkspace(orgID)
+	emissionStore := NewEmissionStore(db, time.Hour)
+
+	projectID := "project-1"
+	now := time.Now().UTC()
+	inserted, err := emissionStore.Append(ctx, []Emission{
+		{ID: "em-1", SourceType: "agent", SourceID: "agent-a", Kind: "status", Summary: "one", Timestamp: now},
+		{ID: "em-2", SourceType: "bridge", SourceID: "bridge-main", Kind: "progress", Summary: "two", ProjectID: &projectID, Timestamp: now},
+		{ID: "em-3", SourceType: "agent", SourceID: "agent-a", Kind: "milestone", Summary: "three", ProjectID: &projectID, Timestamp: now},
+	})
+	require.NoError(t, err)
+	require.Len(t, inserted, 3)
+	require.Less(t, inserted[0].Seq, inserted[2].Seq)
+
+	// Replaying the same IDs is a no-op.
+	duplicate, err := emissionStore.Append(ctx, []Emission{
+		{ID: "em-1", SourceType: "agent", SourceID: "agent-a", Kind: "status", Summary: "one again", Timestamp: now},
+	})
+	require.NoError(t, err)
+	require.Empty(t, duplicate)
+
+	recent, err := emissionStore.List(ctx, EmissionFilter{})
+	require.NoError(t, err)
+	require.Len(t, recent, 3)
+	require.Equal(t, "em-3", recent[0].ID)
+	require.Equal(t, "one", recent[2].Summary)
+
+	after, err := emissionStore.List(ctx, EmissionFilter{AfterID: "em-1"})
+	require.NoError(t, err)
+	require.Len(t, after, 2)
+	require.Equal(t, "em-2", after[0].ID)
+	require.Equal(t, "em-3", after[1].ID)
+
+	filtered, err := emissionStore.List(ctx, EmissionFilter{AfterID: "em-1", SourceID: "agent-a", ProjectID: projectID})
+	require.NoError(t, err)
+	require.Len(t, filtered, 1)
+	require.Equal(t, "em-3", filtered[0].ID)
+
+	_, err = emissionStore.List(ctx, EmissionFilter{AfterID: "em-missing"})
+	require.ErrorIs(t, err, ErrNotFound)
+
+	latest, err := emissionStore.LatestBySource(ctx, "agent-a")
+	require.NoError(t, err)
+	require.NotNil(t, latest)
+	require.Equal(t, "em-3", latest.ID)
+
+	missing, err := emissionStore.LatestBySource(ctx, "nobody")
+	require.NoError(t, err)
+	require.Nil(t, missing)
+
+	otherRecent, err := emissionStore.List(ctxWithWorkspace(otherOrgID), EmissionFilter{})
+	require.NoError(t, err)
+	require.Empty(t, otherRecent)
+}
+
+func TestEmissionStoreReplayWaitsForEarlierTransactions(t *testing.T) {
+	connStr := getTestDatabaseURL(t)
+	db := setupTestDatabase(t, connStr)
+	orgID := createTestOrganization(t, db, "emission-store-commit-order")
+	ctx := ctxWithWorkspace(orgID)
+	emissionStore := NewEmissionStore(db, time.Hour)
+
+	now := time.Now().UTC()
+	_, err := emissionStore.Append(ctx, []Emission{
+		{ID: "em-0", SourceType: "agent", SourceID: "agent-a", Kind: "log", Summary: "zero", Timestamp: now},
+	})
+	require.NoError(t, err)
+
+	// The slow writer draws its seq first but commits last.
+	slow, err := WithWorkspaceTx(ctx, db)
+	require.NoError(t, err)
+	defer func() { _ = slow.Rollback() }()
+	_, err = slow.ExecContext(
+		ctx,
+		`INSERT INTO emissions (id, org_id, source_type, source_id, kind, summary, emitted_at)
+		 VALUES ('em-slow', $1, 'agent', 'agent-b', 'log', 'slow', $2)`,
+		orgID,
+		now,
+	)
+	require.NoError(t, err)
+
+	fast, err := emissionStore.Append(ctx, []Emission{
+		{ID: "em-fast", SourceType: "agent", SourceID: "agent-a", Kind: "log", Summary: "fast", Timestamp: now},
+	})
+	require.NoError(t, err)
+	require.Len(t, fast, 1)
+
+	// em-fast is committed but waits behind the open transaction, so a
+	// reader cannot move its cursor past em-slow before em-slow is visible.
+	after, err := emissionStore.List(ctx, EmissionFilter{AfterID: "em-0"})
+	require.NoError(t, err)
+	require.Empty(t, after)
+	recent, err := emissionStore.List(ctx, EmissionFilter{})
+	require.NoError(t, err)
+	require.Len(t, recent, 1)
+	require.Equal(t, "em-0", recent[0].ID)
+
+	require.NoError(t, slow.Commit())
+
+	after, err = emissionStore.List(ctx, EmissionFilter{AfterID: "em-0"})
+	require.NoError(t, err)
+	require.Len(t, after, 2)
+	require.Equal(t, "em-slow", after[0].ID)
+	require.Equal(t, "em-fast", after[1].ID)
+	require.Less(t, after[0].Seq, after[1].Seq, "em-slow drew the lower seq")
+
+	after, err = emissionStore.List(ctx, EmissionFilter{AfterID: "em-slow"})
+	require.NoError(t, err)
+	require.Len(t, after, 1)
+	require.Equal(t, "em-fast", after[0].ID)
+}
+
+func TestEmissionStorePruneExpired(t *testing.T) {
+	connStr := getTestDatabaseURL(t)
+	db := setupTestDatabase(t, connStr)
+	orgID := createTestOrganization(t, db, "emission-store-prune")
+	ctx := ctxWithWorkspace(orgID)
+	emissionStore := NewEmissionStore(db, time.Hour)
+
+	_, err := emissionStore.Append(ctx, []Emission{
+		{ID: "em-old", SourceType: "agent", SourceID: "agent-a", Kind: "log", Summary: "old", Timestamp: time.Now().UTC()},
+	})
+	require.NoError(t, err)
+
+	pruned, err := emissionStore.PruneExpired(ctx, time.Now().UTC().Add(2*time.Hour))
+	require.NoError(t, err)
+	require.Equal(t, 1, pruned)
+
+	recent, err := emissionStore.List(ctx, EmissionFilter{})
+	require.NoError(t, err)
+	require.Empty(t, recent)
+
+	_, err = emissionStore.Append(context.Background(), []Emission{{ID: "em-x", SourceID: "agent-a"}})
+	require.ErrorIs(t, err, ErrNoWorkspace)
+}
@@ -0,0 +1,591 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/lib/pq"
+	"github.com/samhotchkiss/otter-camp/internal/middleware"
+)
+
+const (
+	ExecApprovalRuleActionAutoApprove      = "auto_approve"
+	ExecApprovalRuleActionAutoDeny         = "auto_deny"
+	ExecApprovalRuleActionRequireApprovers = "require_approvers"
+
+	ExecApprovalRuleMatchGlob  = "glob"
+	ExecApprovalRuleMatchRegex = "regex"
+
+	defaultExecApprovalRulePriority = 100
+	maxExecApprovalRuleApprovals    = 10
+)
+
+// ExecApprovalRule decides exec approval requests automatically. Every set
+// matcher must match: AgentID exactly, CommandPattern as a glob or regex over
+// the whole command, CwdPattern as a glob, and every RiskTags entry must be on
+// the request. A rule with no matchers applies to every request in its scope.
+type ExecApprovalRule struct {
+	ID                string
+	OrgID             string
+	ProjectID         *string
+	Name              string
+	Priority          int
+	Enabled           bool
+	AgentID           *string
+	CommandPattern    *string
+	CommandMatch      string
+	CwdPattern        *string
+	RiskTags          []string
+	Action            string
+	RequiredApprovals int
+	CreatedAt         time.Time
+	UpdatedAt         time.Time
+}
+
+type CreateExecApprovalRuleInput struct {
+	ProjectID         *string
+	Name              string
+	Priority          *int
+	Enabled           *bool
+	AgentID           *string
+	CommandPattern    *string
+	CommandMatch      string
+	CwdPattern        *string
+	RiskTags          []string
+	Action            string
+	RequiredApprovals *int
+}
+
+type UpdateExecApprovalRuleInput struct {
+	Name              *string
+	Priority          *int
+	Enabled           *bool
+	AgentID           *string
+	CommandPattern    *string
+	CommandMatch      *string
+	CwdPattern        *string
+	RiskTags          *[]string
+	Action            *string
+	RequiredApprovals *int
+}
+
+const execApprovalRuleColumns = `
+	id,
+	org_id,
+	project_id::text,
+	name,
+	priority,
+	enabled,
+	agent_id::text,
+	command_pattern,
+	command_match,
+	cwd_pattern,
+	risk_tags,
+	action,
+	required_approvals,
+	created_at,
+	updated_at
+`
+
+type ExecApprovalRuleStore struct {
+	db *sql.DB
+}
+
+func NewExecApprovalRuleStore(db *sql.DB) *ExecApprovalRuleStore {
+	return &ExecApprovalRuleStore{db: db}
+}
+
+// List returns every rule in the workspace, enabled or not, in evaluation order.
+func (s *ExecApprovalRuleStore) List(ctx context.Context) ([]ExecApprovalRule, error) {
+	if s == nil || s.db == nil {
+		return nil, fmt.Errorf("exec approval rule store is not configured")
+	}
+
+	conn, err := WithWorkspace(ctx, s.db)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	rows, err := conn.QueryContext(
+		ctx,
+		`SELECT`+execApprovalRuleColumns+`
+		 FROM exec_approval_rules
+		 ORDER BY CASE WHEN project_id IS NULL THEN 1 ELSE 0 END ASC, priority ASC, created_at ASC, id ASC`,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list exec approval rules: %w", err)
+	}
+	return collectExecApprovalRules(rows)
+}
+
+// ListApplicable returns the enabled rules that apply to a request in
+// projectID (org-wide rules only when nil), project rules first, each scope
+// ordered by priority.
+func (s *ExecApprovalRuleStore) ListApplicable(ctx context.Context, projectID *string) ([]ExecApprovalRule, error) {
+	if s == nil || s.db == nil {
+		return nil, fmt.Errorf("exec approval rule store is not configured")
+	}
+	normalizedProjectID, err := normalizeExecApprovalRuleUUID(projectID, "project_id")
+	if err != nil {
+		return nil, err
+	}
+
+	conn, err := WithWorkspace(ctx, s.db)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	rows, err := conn.QueryContext(
+		ctx,
+		`SELECT`+execApprovalRuleColumns+`
+		 FROM exec_approval_rules
+		 WHERE enabled = TRUE
+		   AND (project_id IS NULL OR project_id = $1)
+		 ORDER BY CASE WHEN project_id IS NULL THEN 1 ELSE 0 END ASC, priority ASC, created_at ASC, id ASC`,
+		nullableString(normalizedProjectID),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list applicable exec approval rules: %w", err)
+	}
+	return collectExecApprovalRules(rows)
+}
+
+func (s *ExecApprovalRuleStore) Get(ctx context.Context, ruleID string) (*ExecApprovalRule, error) {
+	if s == nil || s.db == nil {
+		return nil, fmt.Errorf("exec approval rule store is not configured")
+	}
+	ruleID = strings.TrimSpace(ruleID)
+	if !uuidRegex.MatchString(ruleID) {
+		return nil, fmt.Errorf("%w: invalid rule_id", ErrValidation)
+	}
+
+	conn, err := WithWorkspace(ctx, s.db)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	rule, err := scanExecApprovalRule(conn.QueryRowContext(
+		ctx,
+		`SELECT`+execApprovalRuleColumns+` FROM exec_approval_rules WHERE id = $1`,
+		ruleID,
+	))
+	if err != nil {
+		if errors.Is(err, ErrNotFound) {
+			return nil, ErrNotFound
+		}
+		return nil, fmt.Errorf("failed to load exec approval rule: %w", err)
+	}
+	return &rule, nil
+}
+
+func (s *ExecApprovalRuleStore) Create(ctx context.Context, input CreateExecApprovalRuleInput) (*ExecApprovalRule, error) {
+	if s == nil || s.db == nil {
+		return nil, fmt.Errorf("exec approval rule store is not configured")
+	}
+	workspaceID := strings.TrimSpace(middleware.WorkspaceFromContext(ctx))
+	if workspaceID == "" {
+		return nil, ErrNoWorkspace
+	}
+
+	rule := ExecApprovalRule{
+		ProjectID:         input.ProjectID,
+		Name:              input.Name,
+		Priority:          defaultExecApprovalRulePriority,
+		Enabled:           true,
+		AgentID:           input.AgentID,
+		CommandPattern:    input.CommandPattern,
+		CommandMatch:      input.CommandMatch,
+		CwdPattern:        input.CwdPattern,
+		RiskTags:          input.RiskTags,
+		Action:            input.Action,
+		RequiredApprovals: 1,
+	}
+	if input.Priority != nil {
+		rule.Priority = *input.Priority
+	}
+	if input.Enabled != nil {
+		rule.Enabled = *input.Enabled
+	}
+	if input.RequiredApprovals != nil {
+		rule.RequiredApprovals = *input.RequiredApprovals
+	}
+	if err := normalizeExecApprovalRule(&rule); err != nil {
+		return nil, err
+	}
+
+	conn, err := WithWorkspace(ctx, s.db)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	if err := ensureExecApprovalRuleReferences(ctx, conn, workspaceID, rule); err != nil {
+		return nil, err
+	}
+
+	created, err := scanExecApprovalRule(conn.QueryRowContext(
+		ctx,
+		`INSERT INTO exec_approval_rules (
+			org_id,
+			project_id,
+			name,
+			priority,
+			enabled,
+			agent_id,
+			command_pattern,
+			command_match,
+			cwd_pattern,
+			risk_tags,
+			action,
+			required_approvals
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
+		RETURNING`+execApprovalRuleColumns,
+		workspaceID,
+		nullableString(rule.ProjectID),
+		rule.Name,
+		rule.Priority,
+		rule.Enabled,
+		nullableString(rule.AgentID),
+		nullableString(rule.CommandPattern),
+		rule.CommandMatch,
+		nullableString(rule.CwdPattern),
+		pq.Array(rule.RiskTags),
+		rule.Action,
+		rule.RequiredApprovals,
+	))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create exec approval rule: %w", err)
+	}
+	return &created, nil
+}
+
+func (s *ExecApprovalRuleStore) Update(
+	ctx context.Context,
+	ruleID string,
+	input UpdateExecApprovalRuleInput,
+) (*ExecApprovalRule, error) {
+	if s == nil || s.db == nil {
+		return nil, fmt.Errorf("exec approval rule store is not configured")
+	}
+	workspaceID := strings.TrimSpace(middleware.WorkspaceFromContext(ctx))
+	if workspaceID == "" {
+		return nil, ErrNoWorkspace
+	}
+	ruleID = strings.TrimSpace(ruleID)
+	if !uuidRegex.MatchString(ruleID) {
+		return nil, fmt.Errorf("%w: invalid rule_id", ErrValidation)
+	}
+
+	tx, err := WithWorkspaceTx(ctx, s.db)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	rule, err := scanExecApprovalRule(tx.QueryRowContext(
+		ctx,
+		`SELECT`+execApprovalRuleColumns+` FROM exec_approval_rules WHERE id = $1 FOR UPDATE`,
+		ruleID,
+	))
+	if err != nil {
+		if errors.Is(err, ErrNotFound) {
+			return nil, ErrNotFound
+		}
+		return nil, fmt.Errorf("failed to load exec approval rule: %w", err)
+	}
+
+	if input.Name != nil {
+		rule.Name = *input.Name
+	}
+	if input.Priority != nil {
+		rule.Priority = *input.Priority
+	}
+	if input.Enabled != nil {
+		rule.Enabled = *input.Enabled
+	}
+	if input.AgentID != nil {
+		rule.AgentID = input.AgentID
+	}
+	if input.CommandPattern != nil {
+		rule.CommandPattern = input.CommandPattern
+	}
+	if input.CommandMatch != nil {
+		rule.CommandMatch = *input.CommandMatch
+	}
+	if input.CwdPattern != nil {
+		rule.CwdPattern = input.CwdPattern
+	}
+	if input.RiskTags != nil {
+		rule.RiskTags = *input.RiskTags
+	}
+	if input.Action != nil {
+		rule.Action = *input.Action
+	}
+	if input.RequiredApprovals != nil {
+		rule.RequiredApprovals = *input.RequiredApprovals
+	}
+	if err := normalizeExecApprovalRule(&rule); err != nil {
+		return nil, err
+	}
+	if err := ensureExecApprovalRuleReferences(ctx, tx, workspaceID, rule); err != nil {
+		return nil, err
+	}
+
+	updated, err := scanExecApprovalRule(tx.QueryRowContext(
+		ctx,
+		`UPDATE exec_approval_rules
+		 SET name = $2,
+		     priority = $3,
+		     enabled = $4,
+		     agent_id = $5,
+		     command_pattern = $6,
+		     command_match = $7,
+		     cwd_pattern = $8,
+		     risk_tags = $9,
+		     action = $10,
+		     required_approvals = $11
+		 WHERE id = $1
+		 RETURNING`+execApprovalRuleColumns,
+		ruleID,
+		rule.Name,
+		rule.Priority,
+		rule.Enabled,
+		nullableString(rule.AgentID),
+		nullableString(rule.CommandPattern),
+		rule.CommandMatch,
+		nullableString(rule.CwdPattern),
+		pq.Array(rule.RiskTags),
+		rule.Action,
+		rule.RequiredApprovals,
+	))
+	if err != nil {
+		return nil, fmt.Errorf("failed to update exec approval rule: %w", err)
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit exec approval rule update: %w", err)
+	}
+	return &updated, nil
+}
+
+func (s *ExecApprovalRuleStore) Delete(ctx context.Context, ruleID string) error {
+	if s == nil || s.db == nil {
+		return fmt.Errorf("exec approval rule store is not configured")
+	}
+	ruleID = strings.TrimSpace(ruleID)
+	if !uuidRegex.MatchString(ruleID) {
+		return fmt.Errorf("%w: invalid rule_id", ErrValidation)
+	}
+
+	conn, err := WithWorkspace(ctx, s.db)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	result, err := conn.ExecContext(ctx, `DELETE FROM exec_approval_rules WHERE id = $1`, ruleID)
+	if err != nil {
+		return fmt.Errorf("failed to delete exec approval rule: %w", err)
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to delete exec approval rule: %w", err)
+	}
+	if affected == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// ValidateExecApprovalRule applies the same normalization and checks as
+// Create, without touching the database. Dry runs use it for inline rules.
+func ValidateExecApprovalRule(rule *ExecApprovalRule) error {
+	return normalizeExecApprovalRule(rule)
+}
+
+func normalizeExecApprovalRule(rule *ExecApprovalRule) error {
+	rule.Name = strings.TrimSpace(rule.Name)
+	if rule.Name == "" {
+		return fmt.Errorf("%w: name is required", ErrValidation)
+	}
+
+	var err error
+	if rule.ProjectID, err = normalizeExecApprovalRuleUUID(rule.ProjectID, "project_id"); err != nil {
+		return err
+	}
+	if rule.AgentID, err = normalizeExecApprovalRuleUUID(rule.AgentID, "agent_id"); err != nil {
+		return err
+	}
+	rule.CommandPattern = trimmedStringPointer(rule.CommandPattern)
+	rule.CwdPattern = trimmedStringPointer(rule.CwdPattern)
+
+	rule.CommandMatch = strings.ToLower(strings.TrimSpace(rule.CommandMatch))
+	switch rule.CommandMatch {
+	case "":
+		rule.CommandMatch = ExecApprovalRuleMatchGlob
+	case ExecApprovalRuleMatchGlob:
+	case ExecApprovalRuleMatchRegex:
+		if rule.CommandPattern != nil {
+			if _, err := regexp.Compile(*rule.CommandPattern); err != nil {
+				return fmt.Errorf("%w: invalid command_pattern regex", ErrValidation)
+			}
+		}
+	default:
+		return fmt.Errorf("%w: invalid command_match", ErrValidation)
+	}
+
+	seen := make(map[string]bool, len(rule.RiskTags))
+	tags := make([]string, 0, len(rule.RiskTags))
+	for _, tag := range rule.RiskTags {
+		tag = strings.ToLower(strings.TrimSpace(tag))
+		if tag == "" || seen[tag] {
+			continue
+		}
+		seen[tag] = true
+		tags = append(tags, tag)
+	}
+	sort.Strings(tags)
+	rule.RiskTags = tags
+
+	rule.Action = strings.ToLower(strings.TrimSpace(rule.Action))
+	switch rule.Action {
+	case ExecApprovalRuleActionAutoApprove, ExecApprovalRuleActionAutoDeny:
+		rule.RequiredApprovals = 1
+	case ExecApprovalRuleActionRequireApprovers:
+		if rule.RequiredApprovals < 1 || rule.RequiredApprovals > maxExecApprovalRuleApprovals {
+			return fmt.Errorf("%w: required_approvals must be between 1 and %d", ErrValidation, maxExecApprovalRuleApprovals)
+		}
+	default:
+		return fmt.Errorf("%w: invalid action", ErrValidation)
+	}
+	return nil
+}
+
+func ensureExecApprovalRuleReferences(ctx context.Context, q Querier, orgID string, rule ExecApprovalRule) error {
+	if rule.ProjectID != nil {
+		var exists bool
+		if err := q.QueryRowContext(
+			ctx,
+			`SELECT EXISTS(SELECT 1 FROM projects WHERE id = $1 AND org_id = $2)`,
+			*rule.ProjectID,
+			orgID,
+		).Scan(&exists); err != nil {
+			return fmt.Errorf("failed to validate project scope: %w", err)
+		}
+		if !exists {
+			return fmt.Errorf("%w: project_id does not belong to org", ErrValidation)
+		}
+	}
+	if rule.AgentID != nil {
+		var exists bool
+		if err := q.QueryRowContext(
+			ctx,
+			`SELECT EXISTS(SELECT 1 FROM agents WHERE id = $1 AND org_id = $2)`,
+			*rule.AgentID,
+			orgID,
+		).Scan(&exists); err != nil {
+			return fmt.Errorf("failed to validate agent scope: %w", err)
+		}
+		if !exists {
+			return fmt.Errorf("%w: agent_id does not belong to org", ErrValidation)
+		}
+	}
+	return nil
+}
+
+func normalizeExecApprovalRuleUUID(value *string, field string) (*string, error) {
+	trimmed := trimmedStringPointer(value)
+	if trimmed == nil {
+		return nil, nil
+	}
+	if !uuidRegex.MatchString(*trimmed) {
+		return nil, fmt.Errorf("%w: invalid %s", ErrValidation, field)
+	}
+	return trimmed, nil
+}
+
+func trimmedStringPointer(value *string) *string {
+	if value == nil {
+		return nil
+	}
+	trimmed := strings.TrimSpace(*value)
+	if trimmed == "" {
+		return nil
+	}
+	return &trimmed
+}
+
+func collectExecApprovalRules(rows *sql.Rows) ([]ExecApprovalRule, error) {
+	defer rows.Close()
+	out := make([]ExecApprovalRule, 0)
+	for rows.Next() {
+		rule, err := scanExecApprovalRule(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan exec approval rule: %w", err)
+		}
+		out = append(out, rule)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed reading exec approval rules: %w", err)
+	}
+	return out, nil
+}
+
+func scanExecApprovalRule(scanner interface{ Scan(...any) error }) (ExecApprovalRule, error) {
+	var (
+		rule           ExecApprovalRule
+		projectID      sql.NullString
+		agentID        sql.NullString
+		commandPattern sql.NullString
+		cwdPattern     sql.NullString
+		riskTags       pq.StringArray
+	)
+	err := scanner.Scan(
+		&rule.ID,
+		&rule.OrgID,
+		&projectID,
+		&rule.Name,
+		&rule.Priority,
+		&rule.Enabled,
+		&agentID,
+		&commandPattern,
+		&rule.CommandMatch,
+		&cwdPattern,
+		&riskTags,
+		&rule.Action,
+		&rule.RequiredApprovals,
+		&rule.CreatedAt,
+		&rule.UpdatedAt,
+	)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return ExecApprovalRule{}, ErrNotFound
+		}
+		return ExecApprovalRule{}, err
+	}
+	if projectID.Valid {
+		rule.ProjectID = &projectID.String
+	}
+	if agentID.Valid {
+		rule.AgentID = &agentID.String
+	}
+	if commandPattern.Valid {
+		rule.CommandPattern = &commandPattern.String
+	}
+	if cwdPattern.Valid {
+		rule.CwdPattern = &cwdPattern.String
+	}
+	rule.RiskTags = []string(riskTags)
+	if rule.RiskTags == nil {
+		rule.RiskTags = []string{}
+	}
+	return rule, nil
+}
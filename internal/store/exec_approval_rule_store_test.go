@@ -0,0 +1,96 @@
+package store
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func execApprovalRuleStrPtr(value string) *string {
+	return &value
+}
+
+func TestExecApprovalRuleStoreCreateListUpdateDelete(t *testing.T) {
+	connStr := getTestDatabaseURL(t)
+	db := setupTestDatabase(t, connStr)
+	orgID := createTestOrganization(t, db, "exec-approval-rules")
+	otherOrgID := createTestOrganization(t, db, "exec-approval-rules-other")
+	projectID := createTestProject(t, db, orgID, "Exec Rules Project")
+	agentID := createAgentJobTestAgent(t, db, orgID, "exec-rules-agent")
+	ctx := ctxWithWorkspace(orgID)
+	rules := NewExecApprovalRuleStore(db)
+
+	_, err := rules.Create(ctx, CreateExecApprovalRuleInput{
+		Name:           "Bad regex",
+		CommandPattern: execApprovalRuleStrPtr("("),
+		CommandMatch:   ExecApprovalRuleMatchRegex,
+		Action:         ExecApprovalRuleActionAutoDeny,
+	})
+	require.ErrorIs(t, err, ErrValidation)
+
+	tooMany := 11
+	_, err = rules.Create(ctx, CreateExecApprovalRuleInput{
+		Name:              "Too many approvers",
+		Action:            ExecApprovalRuleActionRequireApprovers,
+		RequiredApprovals: &tooMany,
+	})
+	require.ErrorIs(t, err, ErrValidation)
+
+	orgRule, err := rules.Create(ctx, CreateExecApprovalRuleInput{
+		Name:           "Tests",
+		CommandPattern: execApprovalRuleStrPtr("go test *"),
+		RiskTags:       []string{" Read-Only ", "read-only"},
+		Action:         ExecApprovalRuleActionAutoApprove,
+	})
+	require.NoError(t, err)
+	require.Equal(t, ExecApprovalRuleMatchGlob, orgRule.CommandMatch)
+	require.Equal(t, []string{"read-only"}, orgRule.RiskTags)
+	require.Equal(t, 100, orgRule.Priority)
+
+	two := 2
+	projectRule, err := rules.Create(ctx, CreateExecApprovalRuleInput{
+		ProjectID:         &projectID,
+		AgentID:           &agentID,
+		Name:              "Project quorum",
+		Action:            ExecApprovalRuleActionRequireApprovers,
+		RequiredApprovals: &two,
+	})
+	require.NoError(t, err)
+	require.Equal(t, 2, projectRule.RequiredApprovals)
+
+	applicable, err := rules.ListApplicable(ctx, nil)
+	require.NoError(t, err)
+	require.Len(t, applicable, 1)
+	require.Equal(t, orgRule.ID, applicable[0].ID)
+
+	applicable, err = rules.ListApplicable(ctx, &projectID)
+	require.NoError(t, err)
+	require.Len(t, applicable, 2)
+	require.Equal(t, projectRule.ID, applicable[0].ID)
+
+	disabled := false
+	updated, err := rules.Update(ctx, orgRule.ID, UpdateExecApprovalRuleInput{Enabled: &disabled})
+	require.NoError(t, err)
+	require.False(t, updated.Enabled)
+	applicable, err = rules.ListApplicable(ctx, nil)
+	require.NoError(t, err)
+	require.Empty(t, applicable)
+
+	all, err := rules.List(ctx)
+	require.NoError(t, err)
+	require.Len(t, all, 2)
+
+	_, err = rules.Create(ctxWithWorkspace(otherOrgID), CreateExecApprovalRuleInput{
+		ProjectID: &projectID,
+		Name:      "Cross org",
+		Action:    ExecApprovalRuleActionAutoDeny,
+	})
+	require.ErrorIs(t, err, ErrValidation)
+
+	otherRules, err := rules.List(ctxWithWorkspace(otherOrgID))
+	require.NoError(t, err)
+	require.Empty(t, otherRules)
+
+	require.NoError(t, rules.Delete(ctx, orgRule.ID))
+	require.ErrorIs(t, rules.Delete(ctx, orgRule.ID), ErrNotFound)
+}
@@ -0,0 +1,788 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+const (
+	IssueAnalyticsGroupAgent    = "agent"
+	IssueAnalyticsGroupLabel    = "label"
+	IssueAnalyticsGroupPriority = "priority"
+
+	IssueAnalyticsBucketDay  = "day"
+	IssueAnalyticsBucketWeek = "week"
+
+	IssueAnalyticsStepFlow     = "flow"
+	IssueAnalyticsStepPipeline = "pipeline"
+
+	defaultIssueAnalyticsDays = 30
+	maxIssueAnalyticsDays     = 366
+
+	issueAnalyticsUngrouped = "(none)"
+)
+
+// issueAnalyticsWIPStatuses are the work statuses counted as work in
+// progress. Queued and on-hold issues are waiting, not in progress.
+var issueAnalyticsWIPStatuses = map[string]bool{
+	IssueWorkStatusInProgress: true,
+	IssueWorkStatusReview:     true,
+	IssueWorkStatusBlocked:    true,
+}
+
+type IssueAnalyticsInput struct {
+	ProjectID string
+	// End closes the window; it is rounded up to the next UTC midnight so the
+	// current day is included.
+	End  time.Time
+	Days int
+	// GroupBy is "", "agent" (issue owner), "label" or "priority". An issue
+	// with several labels counts toward each of them.
+	GroupBy string
+	// Bucket sizes the throughput and WIP series: "day" or "week". It
+	// defaults to day for windows up to 31 days and week beyond that.
+	Bucket string
+}
+
+// IssueDurationStats summarises a set of durations in seconds.
+type IssueDurationStats struct {
+	Count      int      `json:"count"`
+	AvgSeconds *float64 `json:"avg_seconds,omitempty"`
+	P50Seconds *float64 `json:"p50_seconds,omitempty"`
+	P85Seconds *float64 `json:"p85_seconds,omitempty"`
+	MaxSeconds *float64 `json:"max_seconds,omitempty"`
+}
+
+// IssueAnalyticsStep is the time completed issues spent in one flow step
+// (from work status transitions) or pipeline step (from pipeline history).
+// Each entry into a step is a visit, so a step an issue bounced back to is
+// counted once per visit.
+type IssueAnalyticsStep struct {
+	Kind     string             `json:"kind"`
+	Key      string             `json:"key"`
+	Name     string             `json:"name"`
+	Issues   int                `json:"issues"`
+	Visits   int                `json:"visits"`
+	Duration IssueDurationStats `json:"duration"`
+}
+
+// IssueAnalyticsBucket holds the issues completed during [Start, End) and
+// the issues in progress at End (or now, for the current bucket).
+type IssueAnalyticsBucket struct {
+	Start      time.Time `json:"start"`
+	End        time.Time `json:"end"`
+	Throughput int       `json:"throughput"`
+	WIP        int       `json:"wip"`
+}
+
+// IssueAnalyticsGroup holds flow metrics for a set of issues. Cycle time,
+// lead time, time in status, step times and rework cover the issues
+// completed (work status done) in the window.
+//
+// Lead time runs from creation to close. Cycle time runs from the first
+// move to in_progress or review, or the pipeline start if earlier, to close.
+// A rework bounce is a pipeline rejection or a move back to an earlier flow
+// step.
+type IssueAnalyticsGroup struct {
+	Key              string                        `json:"key"`
+	Name             string                        `json:"name"`
+	Completed        int                           `json:"completed"`
+	Cancelled        int                           `json:"cancelled"`
+	OpenAtEnd        int                           `json:"open_at_end"`
+	LeadTime         IssueDurationStats            `json:"lead_time"`
+	CycleTime        IssueDurationStats            `json:"cycle_time"`
+	TimeInStatus     map[string]IssueDurationStats `json:"time_in_status"`
+	Steps            []IssueAnalyticsStep          `json:"steps"`
+	ReworkBounces    int                           `json:"rework_bounces"`
+	IssuesWithRework int                           `json:"issues_with_rework"`
+	ReworkRate       *float64                      `json:"rework_rate,omitempty"`
+	Series           []IssueAnalyticsBucket        `json:"series"`
+}
+
+// IssueAnalyticsIssue is one issue's contribution to the report.
+// StatusSeconds is the time spent in each non-terminal work status up to
+// close (or now).
+type IssueAnalyticsIssue struct {
+	ID            string             `json:"id"`
+	IssueNumber   int64              `json:"issue_number"`
+	Title         string             `json:"title"`
+	Priority      string             `json:"priority"`
+	WorkStatus    string             `json:"work_status"`
+	State         string             `json:"state"`
+	OwnerAgentID  *string            `json:"owner_agent_id,omitempty"`
+	OwnerName     string             `json:"owner_name,omitempty"`
+	Labels        []string           `json:"labels"`
+	CreatedAt     time.Time          `json:"created_at"`
+	StartedAt     *time.Time         `json:"started_at,omitempty"`
+	ClosedAt      *time.Time         `json:"closed_at,omitempty"`
+	LeadSeconds   *float64           `json:"lead_seconds,omitempty"`
+	CycleSeconds  *float64           `json:"cycle_seconds,omitempty"`
+	ReworkBounces int                `json:"rework_bounces"`
+	StatusSeconds map[string]float64 `json:"status_seconds"`
+}
+
+// IssueAnalyticsReport covers issues that were open at some point during
+// [From, To).
+type IssueAnalyticsReport struct {
+	ProjectID string                `json:"project_id"`
+	From      time.Time             `json:"from"`
+	To        time.Time             `json:"to"`
+	Days      int                   `json:"days"`
+	Bucket    string                `json:"bucket"`
+	GroupBy   string                `json:"group_by,omitempty"`
+	Overall   IssueAnalyticsGroup   `json:"overall"`
+	Groups    []IssueAnalyticsGroup `json:"groups,omitempty"`
+	Issues    []IssueAnalyticsIssue `json:"issues"`
+}
+
+type IssueAnalyticsStore struct {
+	db *sql.DB
+}
+
+func NewIssueAnalyticsStore(db *sql.DB) *IssueAnalyticsStore {
+	return &IssueAnalyticsStore{db: db}
+}
+
+type issueAnalyticsTransition struct {
+	at            time.Time
+	workStatus    string
+	stepKey       string
+	fromStepIndex *int
+	toStepIndex   *int
+}
+
+type issueAnalyticsPipelineEntry struct {
+	stepID    string
+	stepName  string
+	result    string
+	startedAt time.Time
+	at        time.Time
+}
+
+type issueAnalyticsVisit struct {
+	kind    string
+	key     string
+	name    string
+	seconds float64
+}
+
+type issueAnalyticsRecord struct {
+	issue             IssueAnalyticsIssue
+	pipelineStartedAt *time.Time
+	transitions       []issueAnalyticsTransition
+	pipeline          []issueAnalyticsPipelineEntry
+	visits            []issueAnalyticsVisit
+}
+
+// ProjectAnalytics computes flow metrics for a project from work status and
+// flow step transitions and pipeline history.
+func (s *IssueAnalyticsStore) ProjectAnalytics(ctx context.Context, orgID string, input IssueAnalyticsInput) (*IssueAnalyticsReport, error) {
+	ctx, span := startStoreSpan(ctx, "IssueAnalyticsStore.ProjectAnalytics")
+	defer span.End()
+
+	if s == nil || s.db == nil {
+		return nil, fmt.Errorf("issue analytics store is not configured")
+	}
+	projectID := strings.TrimSpace(input.ProjectID)
+	if !uuidRegex.MatchString(projectID) {
+		return nil, fmt.Errorf("%w: invalid project_id", ErrValidation)
+	}
+	days := input.Days
+	if days == 0 {
+		days = defaultIssueAnalyticsDays
+	}
+	if days < 1 || days > maxIssueAnalyticsDays {
+		return nil, fmt.Errorf("%w: days must be between 1 and %d", ErrValidation, maxIssueAnalyticsDays)
+	}
+	groupBy := strings.ToLower(strings.TrimSpace(input.GroupBy))
+	switch groupBy {
+	case "", IssueAnalyticsGroupAgent, IssueAnalyticsGroupLabel, IssueAnalyticsGroupPriority:
+	default:
+		return nil, fmt.Errorf("%w: group_by must be agent, label or priority", ErrValidation)
+	}
+	bucket := strings.ToLower(strings.TrimSpace(input.Bucket))
+	switch bucket {
+	case "":
+		bucket = IssueAnalyticsBucketDay
+		if days > 31 {
+			bucket = IssueAnalyticsBucketWeek
+		}
+	case IssueAnalyticsBucketDay, IssueAnalyticsBucketWeek:
+	default:
+		return nil, fmt.Errorf("%w: bucket must be day or week", ErrValidation)
+	}
+	now := time.Now().UTC()
+	end := input.End
+	if end.IsZero() {
+		end = now
+	}
+	end = usageDay(end).AddDate(0, 0, 1)
+	from := end.AddDate(0, 0, -days)
+
+	conn, err := WithWorkspaceID(ctx, s.db, orgID)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	if err := ensureProjectVisible(ctx, conn, projectID); err != nil {
+		return nil, err
+	}
+	records, err := loadIssueAnalyticsRecords(ctx, conn, projectID, from, end)
+	if err != nil {
+		return nil, err
+	}
+
+	report := buildIssueAnalyticsReport(records, from, end, bucket, groupBy, now)
+	report.ProjectID = projectID
+	report.Days = days
+	return report, nil
+}
+
+func loadIssueAnalyticsRecords(ctx context.Context, q Querier, projectID string, from, to time.Time) ([]*issueAnalyticsRecord, error) {
+	rows, err := q.QueryContext(
+		ctx,
+		`SELECT i.id, i.issue_number, i.title, i.priority, i.work_status, i.state, i.owner_agent_id,
+			COALESCE(NULLIF(a.display_name, ''), a.slug, ''),
+			i.created_at, i.closed_at, i.pipeline_started_at
+		 FROM project_issues i
+		 LEFT JOIN agents a ON a.id = i.owner_agent_id
+		 WHERE i.project_id = $1
+		   AND i.merged_into_issue_id IS NULL
+		   AND i.created_at < $3
+		   AND (i.closed_at IS NULL OR i.closed_at >= $2)
+		 ORDER BY i.issue_number`,
+		projectID, from, to,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load analytics issues: %w", err)
+	}
+	defer rows.Close()
+
+	records := []*issueAnalyticsRecord{}
+	byID := map[string]*issueAnalyticsRecord{}
+	for rows.Next() {
+		record := &issueAnalyticsRecord{}
+		issue := &record.issue
+		var owner sql.NullString
+		var closedAt, pipelineStartedAt sql.NullTime
+		if err := rows.Scan(
+			&issue.ID, &issue.IssueNumber, &issue.Title, &issue.Priority, &issue.WorkStatus, &issue.State,
+			&owner, &issue.OwnerName, &issue.CreatedAt, &closedAt, &pipelineStartedAt,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan analytics issue: %w", err)
+		}
+		if owner.Valid {
+			issue.OwnerAgentID = &owner.String
+		}
+		if closedAt.Valid {
+			closed := closedAt.Time.UTC()
+			issue.ClosedAt = &closed
+		}
+		if pipelineStartedAt.Valid {
+			started := pipelineStartedAt.Time.UTC()
+			record.pipelineStartedAt = &started
+		}
+		issue.CreatedAt = issue.CreatedAt.UTC()
+		issue.Labels = []string{}
+		records = append(records, record)
+		byID[issue.ID] = record
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed reading analytics issues: %w", err)
+	}
+	if len(records) == 0 {
+		return records, nil
+	}
+	ids := make([]string, 0, len(records))
+	for _, record := range records {
+		ids = append(ids, record.issue.ID)
+	}
+
+	labelRows, err := q.QueryContext(
+		ctx,
+		`SELECT il.issue_id, l.name
+		 FROM issue_labels il
+		 JOIN labels l ON l.id = il.label_id
+		 WHERE il.issue_id = ANY($1::uuid[])
+		 ORDER BY l.name`,
+		pq.Array(ids),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load analytics labels: %w", err)
+	}
+	defer labelRows.Close()
+	for labelRows.Next() {
+		var issueID, name string
+		if err := labelRows.Scan(&issueID, &name); err != nil {
+			return nil, fmt.Errorf("failed to scan analytics label: %w", err)
+		}
+		if record := byID[issueID]; record != nil {
+			record.issue.Labels = append(record.issue.Labels, name)
+		}
+	}
+	if err := labelRows.Err(); err != nil {
+		return nil, fmt.Errorf("failed reading analytics labels: %w", err)
+	}
+
+	stepLabels := map[string]string{}
+	stepRows, err := q.QueryContext(
+		ctx,
+		`SELECT DISTINCT ON (s.step_key) s.step_key, s.label
+		 FROM project_flow_template_steps s
+		 JOIN project_flow_templates t ON t.id = s.flow_template_id
+		 WHERE t.project_id = $1
+		 ORDER BY s.step_key, t.is_default DESC, t.created_at`,
+		projectID,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load flow step labels: %w", err)
+	}
+	defer stepRows.Close()
+	for stepRows.Next() {
+		var key, label string
+		if err := stepRows.Scan(&key, &label); err != nil {
+			return nil, fmt.Errorf("failed to scan flow step label: %w", err)
+		}
+		stepLabels[key] = label
+	}
+	if err := stepRows.Err(); err != nil {
+		return nil, fmt.Errorf("failed reading flow step labels: %w", err)
+	}
+
+	transitionRows, err := q.QueryContext(
+		ctx,
+		`SELECT issue_id, to_work_status, COALESCE(to_flow_step_key, ''), from_flow_step_index, to_flow_step_index, occurred_at
+		 FROM project_issue_state_transitions
+		 WHERE issue_id = ANY($1::uuid[]) AND occurred_at < $2
+		 ORDER BY issue_id, occurred_at`,
+		pq.Array(ids), to,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load issue transitions: %w", err)
+	}
+	defer transitionRows.Close()
+	for transitionRows.Next() {
+		var issueID string
+		var transition issueAnalyticsTransition
+		var fromIndex, toIndex sql.NullInt64
+		if err := transitionRows.Scan(&issueID, &transition.workStatus, &transition.stepKey, &fromIndex, &toIndex, &transition.at); err != nil {
+			return nil, fmt.Errorf("failed to scan issue transition: %w", err)
+		}
+		transition.at = transition.at.UTC()
+		if fromIndex.Valid {
+			value := int(fromIndex.Int64)
+			transition.fromStepIndex = &value
+		}
+		if toIndex.Valid {
+			value := int(toIndex.Int64)
+			transition.toStepIndex = &value
+		}
+		if record := byID[issueID]; record != nil {
+			record.transitions = append(record.transitions, transition)
+		}
+	}
+	if err := transitionRows.Err(); err != nil {
+		return nil, fmt.Errorf("failed reading issue transitions: %w", err)
+	}
+
+	historyRows, err := q.QueryContext(
+		ctx,
+		`SELECT h.issue_id, h.step_id, s.name, h.result, h.started_at, COALESCE(h.completed_at, h.started_at)
+		 FROM issue_pipeline_history h
+		 JOIN pipeline_steps s ON s.id = h.step_id
+		 WHERE h.issue_id = ANY($1::uuid[]) AND COALESCE(h.completed_at, h.started_at) < $2
+		 ORDER BY h.issue_id, COALESCE(h.completed_at, h.started_at), h.created_at`,
+		pq.Array(ids), to,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load pipeline history: %w", err)
+	}
+	defer historyRows.Close()
+	for historyRows.Next() {
+		var issueID string
+		var entry issueAnalyticsPipelineEntry
+		if err := historyRows.Scan(&issueID, &entry.stepID, &entry.stepName, &entry.result, &entry.startedAt, &entry.at); err != nil {
+			return nil, fmt.Errorf("failed to scan pipeline history: %w", err)
+		}
+		entry.startedAt, entry.at = entry.startedAt.UTC(), entry.at.UTC()
+		if record := byID[issueID]; record != nil {
+			record.pipeline = append(record.pipeline, entry)
+		}
+	}
+	if err := historyRows.Err(); err != nil {
+		return nil, fmt.Errorf("failed reading pipeline history: %w", err)
+	}
+
+	limit := to
+	if now := time.Now().UTC(); now.Before(limit) {
+		limit = now
+	}
+	for _, record := range records {
+		analyzeIssueAnalyticsRecord(record, stepLabels, limit)
+	}
+	return records, nil
+}
+
+// analyzeIssueAnalyticsRecord fills in the per-issue durations, start time,
+// rework bounces and step visits. Open issues are measured up to limit.
+func analyzeIssueAnalyticsRecord(record *issueAnalyticsRecord, stepLabels map[string]string, limit time.Time) {
+	issue := &record.issue
+	issue.StatusSeconds = map[string]float64{}
+	record.visits = record.visits[:0]
+	issue.ReworkBounces = 0
+
+	var started *time.Time
+	markStarted := func(at time.Time) {
+		if started == nil || at.Before(*started) {
+			value := at
+			started = &value
+		}
+	}
+
+	var flowVisit *issueAnalyticsVisit
+	closeFlowVisit := func() {
+		if flowVisit != nil {
+			record.visits = append(record.visits, *flowVisit)
+			flowVisit = nil
+		}
+	}
+	for i, transition := range record.transitions {
+		if transition.workStatus == IssueWorkStatusInProgress || transition.workStatus == IssueWorkStatusReview {
+			markStarted(transition.at)
+		}
+		if transition.fromStepIndex != nil && transition.toStepIndex != nil && *transition.toStepIndex < *transition.fromStepIndex {
+			issue.ReworkBounces++
+		}
+		if isTerminalIssueWorkStatus(transition.workStatus) {
+			closeFlowVisit()
+			continue
+		}
+		segmentEnd := limit
+		if i+1 < len(record.transitions) {
+			segmentEnd = record.transitions[i+1].at
+		} else if issue.ClosedAt != nil && issue.ClosedAt.Before(segmentEnd) {
+			segmentEnd = *issue.ClosedAt
+		}
+		seconds := math.Max(0, segmentEnd.Sub(transition.at).Seconds())
+		issue.StatusSeconds[transition.workStatus] += seconds
+
+		if flowVisit != nil && flowVisit.key != transition.stepKey {
+			closeFlowVisit()
+		}
+		if transition.stepKey == "" {
+			continue
+		}
+		if flowVisit == nil {
+			name := stepLabels[transition.stepKey]
+			if name == "" {
+				name = transition.stepKey
+			}
+			flowVisit = &issueAnalyticsVisit{kind: IssueAnalyticsStepFlow, key: transition.stepKey, name: name}
+		}
+		flowVisit.seconds += seconds
+	}
+	closeFlowVisit()
+
+	if record.pipelineStartedAt != nil {
+		markStarted(*record.pipelineStartedAt)
+	}
+	var prevAt *time.Time
+	for _, entry := range record.pipeline {
+		markStarted(entry.startedAt)
+		if entry.result == IssuePipelineResultRejected {
+			issue.ReworkBounces++
+		}
+		since := prevAt
+		if since == nil {
+			since = record.pipelineStartedAt
+		}
+		if since == nil {
+			value := entry.startedAt
+			since = &value
+		}
+		record.visits = append(record.visits, issueAnalyticsVisit{
+			kind:    IssueAnalyticsStepPipeline,
+			key:     entry.stepID,
+			name:    entry.stepName,
+			seconds: math.Max(0, entry.at.Sub(*since).Seconds()),
+		})
+		at := entry.at
+		prevAt = &at
+	}
+
+	issue.StartedAt = started
+	issue.LeadSeconds, issue.CycleSeconds = nil, nil
+	if issue.WorkStatus == IssueWorkStatusDone && issue.ClosedAt != nil {
+		lead := math.Max(0, issue.ClosedAt.Sub(issue.CreatedAt).Seconds())
+		issue.LeadSeconds = &lead
+		if started != nil && !started.After(*issue.ClosedAt) {
+			cycle := issue.ClosedAt.Sub(*started).Seconds()
+			issue.CycleSeconds = &cycle
+		}
+	}
+}
+
+// workStatusAt returns the issue's work status at t, or "" when it did not
+// exist yet.
+func (record *issueAnalyticsRecord) workStatusAt(t time.Time) string {
+	if t.Before(record.issue.CreatedAt) {
+		return ""
+	}
+	status := ""
+	for _, transition := range record.transitions {
+		if transition.at.After(t) {
+			break
+		}
+		status = transition.workStatus
+	}
+	if status != "" {
+		return status
+	}
+	if record.issue.ClosedAt != nil && !record.issue.ClosedAt.After(t) {
+		return record.issue.WorkStatus
+	}
+	if len(record.transitions) > 0 {
+		return IssueWorkStatusQueued
+	}
+	return record.issue.WorkStatus
+}
+
+func (record *issueAnalyticsRecord) completedIn(from, to time.Time) bool {
+	closed := record.issue.ClosedAt
+	return record.issue.WorkStatus == IssueWorkStatusDone && closed != nil && !closed.Before(from) && closed.Before(to)
+}
+
+func buildIssueAnalyticsReport(records []*issueAnalyticsRecord, from, to time.Time, bucket, groupBy string, now time.Time) *IssueAnalyticsReport {
+	report := &IssueAnalyticsReport{
+		From:    from,
+		To:      to,
+		Bucket:  bucket,
+		GroupBy: groupBy,
+		Issues:  make([]IssueAnalyticsIssue, 0, len(records)),
+	}
+	buckets := issueAnalyticsBuckets(from, to, bucket)
+	report.Overall = aggregateIssueAnalyticsGroup("all", "All issues", records, from, to, buckets, now)
+	for _, record := range records {
+		report.Issues = append(report.Issues, record.issue)
+	}
+	if groupBy == "" {
+		return report
+	}
+
+	type groupMembers struct {
+		name    string
+		records []*issueAnalyticsRecord
+	}
+	groups := map[string]*groupMembers{}
+	add := func(key, name string, record *issueAnalyticsRecord) {
+		group, ok := groups[key]
+		if !ok {
+			group = &groupMembers{name: name}
+			groups[key] = group
+		}
+		group.records = append(group.records, record)
+	}
+	for _, record := range records {
+		issue := record.issue
+		switch groupBy {
+		case IssueAnalyticsGroupAgent:
+			if issue.OwnerAgentID == nil {
+				add(issueAnalyticsUngrouped, "Unassigned", record)
+			} else {
+				add(*issue.OwnerAgentID, issue.OwnerName, record)
+			}
+		case IssueAnalyticsGroupLabel:
+			if len(issue.Labels) == 0 {
+				add(issueAnalyticsUngrouped, "No label", record)
+			}
+			for _, label := range issue.Labels {
+				add(label, label, record)
+			}
+		case IssueAnalyticsGroupPriority:
+			add(issue.Priority, issue.Priority, record)
+		}
+	}
+	keys := make([]string, 0, len(groups))
+	for key := range groups {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		left, right := groups[keys[i]], groups[keys[j]]
+		if (keys[i] == issueAnalyticsUngrouped) != (keys[j] == issueAnalyticsUngrouped) {
+			return keys[j] == issueAnalyticsUngrouped
+		}
+		if groupBy == IssueAnalyticsGroupPriority {
+			return keys[i] < keys[j]
+		}
+		return strings.ToLower(left.name) < strings.ToLower(right.name)
+	})
+	for _, key := range keys {
+		group := groups[key]
+		report.Groups = append(report.Groups, aggregateIssueAnalyticsGroup(key, group.name, group.records, from, to, buckets, now))
+	}
+	return report
+}
+
+func aggregateIssueAnalyticsGroup(key, name string, records []*issueAnalyticsRecord, from, to time.Time, buckets []IssueAnalyticsBucket, now time.Time) IssueAnalyticsGroup {
+	group := IssueAnalyticsGroup{
+		Key:          key,
+		Name:         name,
+		TimeInStatus: map[string]IssueDurationStats{},
+		Steps:        []IssueAnalyticsStep{},
+		Series:       make([]IssueAnalyticsBucket, len(buckets)),
+	}
+	copy(group.Series, buckets)
+
+	var lead, cycle []float64
+	statusSeconds := map[string][]float64{}
+	type stepTotals struct {
+		step    IssueAnalyticsStep
+		issues  map[string]bool
+		seconds []float64
+	}
+	steps := map[string]*stepTotals{}
+	var stepOrder []string
+	reworked := 0
+
+	end := to
+	if now.Before(end) {
+		end = now
+	}
+	for _, record := range records {
+		issue := record.issue
+		if issue.WorkStatus == IssueWorkStatusCancelled && issue.ClosedAt != nil && !issue.ClosedAt.Before(from) && issue.ClosedAt.Before(to) {
+			group.Cancelled++
+		}
+		if status := record.workStatusAt(end); status != "" && !isTerminalIssueWorkStatus(status) {
+			group.OpenAtEnd++
+		}
+		for i := range group.Series {
+			bucket := &group.Series[i]
+			if issue.WorkStatus == IssueWorkStatusDone && issue.ClosedAt != nil && !issue.ClosedAt.Before(bucket.Start) && issue.ClosedAt.Before(bucket.End) {
+				bucket.Throughput++
+			}
+			sampleAt := bucket.End.Add(-time.Nanosecond)
+			if now.Before(sampleAt) {
+				if now.Before(bucket.Start) {
+					continue
+				}
+				sampleAt = now
+			}
+			if issueAnalyticsWIPStatuses[record.workStatusAt(sampleAt)] {
+				bucket.WIP++
+			}
+		}
+
+		if !record.completedIn(from, to) {
+			continue
+		}
+		group.Completed++
+		if issue.LeadSeconds != nil {
+			lead = append(lead, *issue.LeadSeconds)
+		}
+		if issue.CycleSeconds != nil {
+			cycle = append(cycle, *issue.CycleSeconds)
+		}
+		for status, seconds := range issue.StatusSeconds {
+			if seconds > 0 {
+				statusSeconds[status] = append(statusSeconds[status], seconds)
+			}
+		}
+		if issue.ReworkBounces > 0 {
+			reworked++
+			group.ReworkBounces += issue.ReworkBounces
+		}
+		for _, visit := range record.visits {
+			stepKey := visit.kind + ":" + visit.key
+			totals, ok := steps[stepKey]
+			if !ok {
+				totals = &stepTotals{
+					step:   IssueAnalyticsStep{Kind: visit.kind, Key: visit.key, Name: visit.name},
+					issues: map[string]bool{},
+				}
+				steps[stepKey] = totals
+				stepOrder = append(stepOrder, stepKey)
+			}
+			totals.step.Visits++
+			totals.issues[issue.ID] = true
+			totals.seconds = append(totals.seconds, visit.seconds)
+		}
+	}
+
+	group.LeadTime = issueDurationStats(lead)
+	group.CycleTime = issueDurationStats(cycle)
+	for status, values := range statusSeconds {
+		group.TimeInStatus[status] = issueDurationStats(values)
+	}
+	for _, stepKey := range stepOrder {
+		totals := steps[stepKey]
+		totals.step.Issues = len(totals.issues)
+		totals.step.Duration = issueDurationStats(totals.seconds)
+		group.Steps = append(group.Steps, totals.step)
+	}
+	sort.SliceStable(group.Steps, func(i, j int) bool {
+		return group.Steps[i].Kind < group.Steps[j].Kind
+	})
+	group.IssuesWithRework = reworked
+	if group.Completed > 0 {
+		rate := float64(reworked) / float64(group.Completed)
+		group.ReworkRate = &rate
+	}
+	return group
+}
+
+func issueAnalyticsBuckets(from, to time.Time, bucket string) []IssueAnalyticsBucket {
+	step := 1
+	if bucket == IssueAnalyticsBucketWeek {
+		step = 7
+	}
+	buckets := []IssueAnalyticsBucket{}
+	for start := from; start.Before(to); start = start.AddDate(0, 0, step) {
+		end := start.AddDate(0, 0, step)
+		if end.After(to) {
+			end = to
+		}
+		buckets = append(buckets, IssueAnalyticsBucket{Start: start, End: end})
+	}
+	return buckets
+}
+
+// issueDurationStats uses nearest-rank percentiles.
+func issueDurationStats(values []float64) IssueDurationStats {
+	stats := IssueDurationStats{Count: len(values)}
+	if len(values) == 0 {
+		return stats
+	}
+	sorted := append([]float64(nil), values...)
+	sort.Float64s(sorted)
+	var total float64
+	for _, value := range sorted {
+		total += value
+	}
+	percentile := func(p float64) *float64 {
+		rank := int(math.Ceil(p*float64(len(sorted)))) - 1
+		if rank < 0 {
+			rank = 0
+		}
+		value := sorted[rank]
+		return &value
+	}
+	avg := total / float64(len(sorted))
+	stats.AvgSeconds = &avg
+	stats.P50Seconds = percentile(0.50)
+	stats.P85Seconds = percentile(0.85)
+	stats.MaxSeconds = &sorted[len(sorted)-1]
+	return stats
+}
+
+func isTerminalIssueWorkStatus(status string) bool {
+	return status == IssueWorkStatusDone || status == IssueWorkStatusCancelled
+}
@@ -0,0 +1,185 @@
+package store
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestBuildIssueAnalyticsReportComputesFlowMetrics(t *testing.T) {
+	from := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+	to := from.AddDate(0, 0, 7)
+	now := to.Add(time.Hour)
+	at := func(day, hour int) time.Time { return from.AddDate(0, 0, day).Add(time.Duration(hour) * time.Hour) }
+	index := func(v int) *int { return &v }
+	owner := "agent-1"
+
+	closed := at(2, 12)
+	reworked := &issueAnalyticsRecord{
+		issue: IssueAnalyticsIssue{
+			ID: "issue-1", Priority: IssuePriorityP1, WorkStatus: IssueWorkStatusDone,
+			OwnerAgentID: &owner, OwnerName: "Builder", Labels: []string{"api"},
+			CreatedAt: at(0, 0), ClosedAt: &closed,
+		},
+		transitions: []issueAnalyticsTransition{
+			{at: at(0, 0), workStatus: IssueWorkStatusQueued, stepKey: "build", toStepIndex: index(0)},
+			{at: at(0, 2), workStatus: IssueWorkStatusInProgress, stepKey: "build", fromStepIndex: index(0), toStepIndex: index(0)},
+			{at: at(0, 10), workStatus: IssueWorkStatusReview, stepKey: "review", fromStepIndex: index(0), toStepIndex: index(1)},
+			{at: at(0, 14), workStatus: IssueWorkStatusInProgress, stepKey: "build", fromStepIndex: index(1), toStepIndex: index(0)},
+			{at: at(1, 0), workStatus: IssueWorkStatusReview, stepKey: "review", fromStepIndex: index(0), toStepIndex: index(1)},
+			{at: closed, workStatus: IssueWorkStatusDone, stepKey: "review", fromStepIndex: index(1), toStepIndex: index(1)},
+		},
+	}
+	pipelineStart := at(3, 0)
+	pipelineClosed := at(3, 6)
+	pipelined := &issueAnalyticsRecord{
+		issue: IssueAnalyticsIssue{
+			ID: "issue-2", Priority: IssuePriorityP2, WorkStatus: IssueWorkStatusDone,
+			Labels: []string{}, CreatedAt: at(2, 0), ClosedAt: &pipelineClosed,
+		},
+		pipelineStartedAt: &pipelineStart,
+		transitions: []issueAnalyticsTransition{
+			{at: at(2, 0), workStatus: IssueWorkStatusQueued},
+			{at: pipelineClosed, workStatus: IssueWorkStatusDone},
+		},
+		pipeline: []issueAnalyticsPipelineEntry{
+			{stepID: "s1", stepName: "Draft", result: IssuePipelineResultCompleted, startedAt: pipelineStart, at: at(3, 2)},
+			{stepID: "s2", stepName: "Review", result: IssuePipelineResultRejected, startedAt: at(3, 2), at: at(3, 3)},
+			{stepID: "s1", stepName: "Draft", result: IssuePipelineResultCompleted, startedAt: at(3, 3), at: at(3, 5)},
+			{stepID: "s2", stepName: "Review", result: IssuePipelineResultCompleted, startedAt: at(3, 5), at: pipelineClosed},
+		},
+	}
+	open := &issueAnalyticsRecord{
+		issue: IssueAnalyticsIssue{
+			ID: "issue-3", Priority: IssuePriorityP1, WorkStatus: IssueWorkStatusInProgress,
+			OwnerAgentID: &owner, OwnerName: "Builder", Labels: []string{"api", "ui"}, CreatedAt: at(4, 0),
+		},
+		transitions: []issueAnalyticsTransition{
+			{at: at(4, 0), workStatus: IssueWorkStatusQueued},
+			{at: at(5, 0), workStatus: IssueWorkStatusInProgress},
+		},
+	}
+	records := []*issueAnalyticsRecord{reworked, pipelined, open}
+	labels := map[string]string{"build": "Build", "review": "Code review"}
+	for _, record := range records {
+		analyzeIssueAnalyticsRecord(record, labels, now)
+	}
+
+	require.Equal(t, 1, reworked.issue.ReworkBounces)
+	require.Equal(t, 1, pipelined.issue.ReworkBounces)
+	require.Equal(t, float64(2*3600), reworked.issue.StatusSeconds[IssueWorkStatusQueued])
+	require.Equal(t, float64((8+10)*3600), reworked.issue.StatusSeconds[IssueWorkStatusInProgress])
+	require.InDelta(t, float64(60*3600), *reworked.issue.LeadSeconds, 0.001)
+	require.InDelta(t, float64(58*3600), *reworked.issue.CycleSeconds, 0.001)
+	require.InDelta(t, float64(6*3600), *pipelined.issue.CycleSeconds, 0.001)
+	require.Nil(t, open.issue.CycleSeconds)
+
+	report := buildIssueAnalyticsReport(records, from, to, IssueAnalyticsBucketDay, IssueAnalyticsGroupLabel, now)
+	overall := report.Overall
+	require.Equal(t, 2, overall.Completed)
+	require.Equal(t, 1, overall.OpenAtEnd)
+	require.Equal(t, 2, overall.ReworkBounces)
+	require.Equal(t, 2, overall.IssuesWithRework)
+	require.InDelta(t, 1.0, *overall.ReworkRate, 0.001)
+	require.Equal(t, 2, overall.LeadTime.Count)
+	require.InDelta(t, float64(30*3600), *overall.LeadTime.P50Seconds, 0.001)
+
+	steps := map[string]IssueAnalyticsStep{}
+	for _, step := range overall.Steps {
+		steps[step.Kind+":"+step.Name] = step
+	}
+	require.Equal(t, 2, steps["flow:Build"].Visits)
+	require.Equal(t, 1, steps["flow:Build"].Issues)
+	require.InDelta(t, float64(10*3600), *steps["flow:Build"].Duration.AvgSeconds, 0.001)
+	require.Equal(t, 2, steps["flow:Code review"].Visits)
+	require.Equal(t, 2, steps["pipeline:Draft"].Visits)
+	require.InDelta(t, float64(3600), *steps["pipeline:Review"].Duration.MaxSeconds, 0.001)
+
+	require.Len(t, overall.Series, 7)
+	require.Equal(t, 1, overall.Series[2].Throughput)
+	require.Equal(t, 1, overall.Series[3].Throughput)
+	require.Equal(t, 1, overall.Series[0].WIP)
+	require.Equal(t, 0, overall.Series[3].WIP)
+	require.Equal(t, 1, overall.Series[6].WIP)
+
+	require.Len(t, report.Groups, 3)
+	require.Equal(t, "api", report.Groups[0].Key)
+	require.Equal(t, 1, report.Groups[0].Completed)
+	require.Equal(t, 1, report.Groups[0].OpenAtEnd)
+	require.Equal(t, "ui", report.Groups[1].Key)
+	require.Equal(t, issueAnalyticsUngrouped, report.Groups[2].Key)
+	require.Equal(t, 1, report.Groups[2].Completed)
+
+	byAgent := buildIssueAnalyticsReport(records, from, to, IssueAnalyticsBucketWeek, IssueAnalyticsGroupAgent, now)
+	require.Len(t, byAgent.Groups, 2)
+	require.Equal(t, "Builder", byAgent.Groups[0].Name)
+	require.Equal(t, "Unassigned", byAgent.Groups[1].Name)
+	require.Len(t, byAgent.Overall.Series, 1)
+	require.Equal(t, 2, byAgent.Overall.Series[0].Throughput)
+}
+
+func TestIssueDurationStatsUsesNearestRank(t *testing.T) {
+	require.Equal(t, IssueDurationStats{}, issueDurationStats(nil))
+
+	stats := issueDurationStats([]float64{40, 10, 30, 20})
+	require.Equal(t, 4, stats.Count)
+	require.Equal(t, 25.0, *stats.AvgSeconds)
+	require.Equal(t, 20.0, *stats.P50Seconds)
+	require.Equal(t, 40.0, *stats.P85Seconds)
+	require.Equal(t, 40.0, *stats.MaxSeconds)
+}
+
+func TestIssueAnalyticsStoreProjectAnalytics(t *testing.T) {
+	connStr := getTestDatabaseURL(t)
+	db := setupTestDatabase(t, connStr)
+	orgID := createTestOrganization(t, db, "issue-analytics-org")
+	projectID := createTestProject(t, db, orgID, "Analytics Project")
+	agentID := createAgentJobTestAgent(t, db, orgID, "analytics-agent")
+	ctx := ctxWithWorkspace(orgID)
+	issues := NewProjectIssueStore(db)
+
+	issue, err := issues.CreateIssue(ctx, CreateProjectIssueInput{
+		ProjectID:    projectID,
+		Title:        "Measure me",
+		Origin:       "local",
+		OwnerAgentID: &agentID,
+		Priority:     IssuePriorityP1,
+	})
+	require.NoError(t, err)
+	for _, status := range []string{IssueWorkStatusInProgress, IssueWorkStatusReview, IssueWorkStatusDone} {
+		_, err = issues.UpdateIssueWorkTracking(ctx, UpdateProjectIssueWorkTrackingInput{
+			IssueID:       issue.ID,
+			SetWorkStatus: true,
+			WorkStatus:    status,
+		})
+		require.NoError(t, err)
+	}
+
+	var transitions int
+	require.NoError(t, db.QueryRow(
+		`SELECT COUNT(*) FROM project_issue_state_transitions WHERE issue_id = $1`, issue.ID,
+	).Scan(&transitions))
+	require.Equal(t, 4, transitions)
+
+	analytics := NewIssueAnalyticsStore(db)
+	report, err := analytics.ProjectAnalytics(ctx, orgID, IssueAnalyticsInput{
+		ProjectID: projectID,
+		Days:      7,
+		GroupBy:   IssueAnalyticsGroupAgent,
+	})
+	require.NoError(t, err)
+	require.Equal(t, 1, report.Overall.Completed)
+	require.Equal(t, 1, report.Overall.CycleTime.Count)
+	require.Len(t, report.Groups, 1)
+	require.Equal(t, agentID, report.Groups[0].Key)
+	require.Len(t, report.Issues, 1)
+	require.NotNil(t, report.Issues[0].StartedAt)
+
+	_, err = analytics.ProjectAnalytics(ctx, orgID, IssueAnalyticsInput{ProjectID: projectID, GroupBy: "team"})
+	require.ErrorIs(t, err, ErrValidation)
+	_, err = analytics.ProjectAnalytics(ctx, orgID, IssueAnalyticsInput{ProjectID: projectID, Days: 400})
+	require.ErrorIs(t, err, ErrValidation)
+	_, err = analytics.ProjectAnalytics(ctx, orgID, IssueAnalyticsInput{ProjectID: "00000000-0000-0000-0000-000000000000"})
+	require.ErrorIs(t, err, ErrNotFound)
+}
@@ -0,0 +1,407 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/samhotchkiss/otter-camp/internal/middleware"
+)
+
+const (
+	IssueImportSourceJira   = "jira"
+	IssueImportSourceLinear = "linear"
+	IssueImportSourceCSV    = "csv"
+
+	issueImportKindIssue   = "issue"
+	issueImportKindComment = "comment"
+)
+
+// IsValidIssueImportSource reports whether source is a supported import source.
+func IsValidIssueImportSource(source string) bool {
+	switch source {
+	case IssueImportSourceJira, IssueImportSourceLinear, IssueImportSourceCSV:
+		return true
+	default:
+		return false
+	}
+}
+
+// ImportIssueInput is one exported issue already mapped onto issue fields.
+// ExternalID is the source's stable key (PROJ-12, ENG-4, ...).
+type ImportIssueInput struct {
+	ProjectID    string
+	Source       string
+	ExternalID   string
+	Title        string
+	Body         *string
+	State        string
+	WorkStatus   string
+	Priority     string
+	OwnerAgentID *string
+	ClosedAt     *time.Time
+}
+
+type ImportIssueResult struct {
+	Issue   ProjectIssue
+	Created bool
+	Updated bool
+}
+
+type ImportIssueCommentInput struct {
+	ProjectID     string
+	Source        string
+	ExternalID    string
+	IssueID       string
+	AuthorAgentID string
+	Body          string
+}
+
+// IssueImportStore upserts issues and comments from external trackers,
+// keyed by issue_import_links so a repeated import is idempotent.
+type IssueImportStore struct {
+	db *sql.DB
+}
+
+func NewIssueImportStore(db *sql.DB) *IssueImportStore {
+	return &IssueImportStore{db: db}
+}
+
+// ListImportedIssues returns the issues previously imported from source into
+// the project, keyed by external id. It returns ErrNotFound when the project
+// is not visible to the workspace.
+func (s *IssueImportStore) ListImportedIssues(ctx context.Context, projectID, source string) (map[string]ProjectIssue, error) {
+	projectID, source, err := normalizeIssueImportScope(projectID, source)
+	if err != nil {
+		return nil, err
+	}
+	conn, err := WithWorkspace(ctx, s.db)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	if err := ensureProjectVisible(ctx, conn, projectID); err != nil {
+		return nil, err
+	}
+	rows, err := conn.QueryContext(
+		ctx,
+		`SELECT i.id, i.org_id, i.project_id, i.issue_number, i.title, i.body, i.state, i.origin, i.document_path, i.approval_state, i.owner_agent_id, i.work_status, i.priority, i.due_at, i.next_step, i.next_step_due_at, i.flow_template_id, i.flow_step_key, i.flow_step_index, i.parent_issue_id, i.merged_into_issue_id, i.created_at, i.updated_at, i.closed_at, l.external_id
+			FROM issue_import_links l
+			JOIN project_issues i ON i.id = l.issue_id
+			WHERE l.project_id = $1 AND l.source = $2 AND l.kind = '`+issueImportKindIssue+`'`,
+		projectID,
+		source,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list imported issues: %w", err)
+	}
+	defer rows.Close()
+
+	issues := make(map[string]ProjectIssue)
+	for rows.Next() {
+		var externalID string
+		issue, err := scanProjectIssue(trailingColumnScanner{scanner: rows, extra: []any{&externalID}})
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan imported issue: %w", err)
+		}
+		issues[externalID] = issue
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed reading imported issues: %w", err)
+	}
+	return issues, nil
+}
+
+// ListImportedCommentIDs returns the external ids of comments already
+// imported from source into the project.
+func (s *IssueImportStore) ListImportedCommentIDs(ctx context.Context, projectID, source string) (map[string]bool, error) {
+	projectID, source, err := normalizeIssueImportScope(projectID, source)
+	if err != nil {
+		return nil, err
+	}
+	conn, err := WithWorkspace(ctx, s.db)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	rows, err := conn.QueryContext(
+		ctx,
+		`SELECT external_id FROM issue_import_links
+			WHERE project_id = $1 AND source = $2 AND kind = '`+issueImportKindComment+`'`,
+		projectID,
+		source,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list imported comments: %w", err)
+	}
+	defer rows.Close()
+
+	ids := make(map[string]bool)
+	for rows.Next() {
+		var externalID string
+		if err := rows.Scan(&externalID); err != nil {
+			return nil, fmt.Errorf("failed to scan imported comment: %w", err)
+		}
+		ids[externalID] = true
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed reading imported comments: %w", err)
+	}
+	return ids, nil
+}
+
+// UpsertIssue creates the issue on first import and afterwards overwrites the
+// imported fields when the export changed them. Updated is false when the
+// stored issue already matches.
+func (s *IssueImportStore) UpsertIssue(ctx context.Context, input ImportIssueInput) (ImportIssueResult, error) {
+	workspaceID := middleware.WorkspaceFromContext(ctx)
+	if workspaceID == "" {
+		return ImportIssueResult{}, ErrNoWorkspace
+	}
+	projectID, source, err := normalizeIssueImportScope(input.ProjectID, input.Source)
+	if err != nil {
+		return ImportIssueResult{}, err
+	}
+	externalID := strings.TrimSpace(input.ExternalID)
+	if externalID == "" {
+		return ImportIssueResult{}, fmt.Errorf("%w: external id is required", ErrValidation)
+	}
+	normalized, err := normalizeImportIssueInput(projectID, input)
+	if err != nil {
+		return ImportIssueResult{}, err
+	}
+
+	tx, err := WithWorkspaceTx(ctx, s.db)
+	if err != nil {
+		return ImportIssueResult{}, err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	existing, err := scanProjectIssue(tx.QueryRowContext(
+		ctx,
+		`SELECT i.id, i.org_id, i.project_id, i.issue_number, i.title, i.body, i.state, i.origin, i.document_path, i.approval_state, i.owner_agent_id, i.work_status, i.priority, i.due_at, i.next_step, i.next_step_due_at, i.flow_template_id, i.flow_step_key, i.flow_step_index, i.parent_issue_id, i.merged_into_issue_id, i.created_at, i.updated_at, i.closed_at
+			FROM issue_import_links l
+			JOIN project_issues i ON i.id = l.issue_id
+			WHERE l.project_id = $1 AND l.source = $2 AND l.kind = '`+issueImportKindIssue+`' AND l.external_id = $3
+			FOR UPDATE OF i`,
+		projectID,
+		source,
+		externalID,
+	))
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+		issue, err := insertProjectIssue(ctx, tx, workspaceID, normalized)
+		if err != nil {
+			return ImportIssueResult{}, err
+		}
+		if _, err := tx.ExecContext(
+			ctx,
+			`INSERT INTO issue_import_links (org_id, project_id, source, kind, external_id, issue_id)
+				VALUES ($1, $2, $3, '`+issueImportKindIssue+`', $4, $5)`,
+			workspaceID,
+			projectID,
+			source,
+			externalID,
+			issue.ID,
+		); err != nil {
+			return ImportIssueResult{}, fmt.Errorf("failed to record imported issue: %w", err)
+		}
+		if err := tx.Commit(); err != nil {
+			return ImportIssueResult{}, fmt.Errorf("failed to commit issue import: %w", err)
+		}
+		return ImportIssueResult{Issue: issue, Created: true}, nil
+	case err != nil:
+		return ImportIssueResult{}, fmt.Errorf("failed to load imported issue: %w", err)
+	}
+
+	if ImportedIssueMatches(existing, input) {
+		return ImportIssueResult{Issue: existing}, nil
+	}
+	if normalized.OwnerAgentID != nil {
+		if err := ensureAgentVisible(ctx, tx, *normalized.OwnerAgentID); err != nil {
+			return ImportIssueResult{}, err
+		}
+	}
+	updated, err := scanProjectIssue(tx.QueryRowContext(
+		ctx,
+		`UPDATE project_issues
+			SET title = $2,
+				body = $3,
+				state = $4,
+				approval_state = CASE WHEN state = $4 THEN approval_state ELSE $5 END,
+				work_status = $6,
+				priority = $7,
+				owner_agent_id = $8,
+				closed_at = CASE
+					WHEN $4 <> 'closed' THEN NULL
+					WHEN state = 'closed' THEN closed_at
+					ELSE $9
+				END
+			WHERE id = $1
+			RETURNING id, org_id, project_id, issue_number, title, body, state, origin, document_path, approval_state, owner_agent_id, work_status, priority, due_at, next_step, next_step_due_at, flow_template_id, flow_step_key, flow_step_index, parent_issue_id, merged_into_issue_id, created_at, updated_at, closed_at`,
+		existing.ID,
+		normalized.Title,
+		nullableString(normalized.Body),
+		normalized.State,
+		normalized.ApprovalState,
+		normalized.WorkStatus,
+		normalized.Priority,
+		nullableString(normalized.OwnerAgentID),
+		normalized.ClosedAt,
+	))
+	if err != nil {
+		return ImportIssueResult{}, fmt.Errorf("failed to update imported issue: %w", err)
+	}
+	if err := tx.Commit(); err != nil {
+		return ImportIssueResult{}, fmt.Errorf("failed to commit issue import: %w", err)
+	}
+	return ImportIssueResult{Issue: updated, Updated: true}, nil
+}
+
+// ImportComment adds a comment the first time its external id is seen and
+// reports false when it was imported before.
+func (s *IssueImportStore) ImportComment(ctx context.Context, input ImportIssueCommentInput) (bool, error) {
+	workspaceID := middleware.WorkspaceFromContext(ctx)
+	if workspaceID == "" {
+		return false, ErrNoWorkspace
+	}
+	projectID, source, err := normalizeIssueImportScope(input.ProjectID, input.Source)
+	if err != nil {
+		return false, err
+	}
+	externalID := strings.TrimSpace(input.ExternalID)
+	if externalID == "" {
+		return false, fmt.Errorf("%w: external id is required", ErrValidation)
+	}
+	issueID := strings.TrimSpace(input.IssueID)
+	authorID := strings.TrimSpace(input.AuthorAgentID)
+	if !uuidRegex.MatchString(issueID) || !uuidRegex.MatchString(authorID) {
+		return false, fmt.Errorf("%w: invalid issue or author id", ErrValidation)
+	}
+	body := strings.TrimSpace(input.Body)
+	if body == "" {
+		return false, fmt.Errorf("%w: comment body is required", ErrValidation)
+	}
+
+	tx, err := WithWorkspaceTx(ctx, s.db)
+	if err != nil {
+		return false, err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	var exists bool
+	err = tx.QueryRowContext(
+		ctx,
+		`SELECT TRUE FROM issue_import_links
+			WHERE project_id = $1 AND source = $2 AND kind = '`+issueImportKindComment+`' AND external_id = $3`,
+		projectID,
+		source,
+		externalID,
+	).Scan(&exists)
+	if err == nil {
+		return false, nil
+	}
+	if !errors.Is(err, sql.ErrNoRows) {
+		return false, fmt.Errorf("failed to check imported comment: %w", err)
+	}
+	if err := ensureIssueVisible(ctx, tx, issueID); err != nil {
+		return false, err
+	}
+	if err := ensureAgentVisible(ctx, tx, authorID); err != nil {
+		return false, err
+	}
+
+	var commentID string
+	if err := tx.QueryRowContext(
+		ctx,
+		`INSERT INTO project_issue_comments (org_id, issue_id, author_agent_id, body)
+			VALUES ($1, $2, $3, $4)
+			RETURNING id`,
+		workspaceID,
+		issueID,
+		authorID,
+		body,
+	).Scan(&commentID); err != nil {
+		return false, fmt.Errorf("failed to create imported comment: %w", err)
+	}
+	if _, err := tx.ExecContext(
+		ctx,
+		`INSERT INTO issue_import_links (org_id, project_id, source, kind, external_id, issue_id, comment_id)
+			VALUES ($1, $2, $3, '`+issueImportKindComment+`', $4, $5, $6)`,
+		workspaceID,
+		projectID,
+		source,
+		externalID,
+		issueID,
+		commentID,
+	); err != nil {
+		return false, fmt.Errorf("failed to record imported comment: %w", err)
+	}
+	if err := tx.Commit(); err != nil {
+		return false, fmt.Errorf("failed to commit comment import: %w", err)
+	}
+	return true, nil
+}
+
+// ImportedIssueMatches reports whether issue already carries the imported
+// fields of input, so a dry run and a real run agree on what would change.
+func ImportedIssueMatches(issue ProjectIssue, input ImportIssueInput) bool {
+	want, err := normalizeImportIssueInput(issue.ProjectID, input)
+	if err != nil {
+		return false
+	}
+	return issue.Title == want.Title &&
+		importedIssueText(issue.Body) == importedIssueText(want.Body) &&
+		issue.State == want.State &&
+		issue.WorkStatus == want.WorkStatus &&
+		issue.Priority == want.Priority &&
+		importedIssueText(issue.OwnerAgentID) == importedIssueText(want.OwnerAgentID)
+}
+
+func normalizeImportIssueInput(projectID string, input ImportIssueInput) (CreateProjectIssueInput, error) {
+	normalized, err := normalizeCreateProjectIssueInput(CreateProjectIssueInput{
+		ProjectID:    projectID,
+		Title:        input.Title,
+		Body:         normalizeOptionalIssueText(input.Body),
+		State:        input.State,
+		Origin:       "local",
+		OwnerAgentID: input.OwnerAgentID,
+		WorkStatus:   input.WorkStatus,
+		Priority:     input.Priority,
+	})
+	if err != nil {
+		return CreateProjectIssueInput{}, fmt.Errorf("%w: %s", ErrValidation, err.Error())
+	}
+	if normalized.State == "closed" {
+		normalized.ClosedAt = input.ClosedAt
+		if normalized.ClosedAt == nil {
+			now := time.Now().UTC()
+			normalized.ClosedAt = &now
+		}
+	}
+	return normalized, nil
+}
+
+func normalizeIssueImportScope(projectID, source string) (string, string, error) {
+	projectID = strings.TrimSpace(projectID)
+	if !uuidRegex.MatchString(projectID) {
+		return "", "", fmt.Errorf("%w: invalid project_id", ErrValidation)
+	}
+	source = strings.TrimSpace(strings.ToLower(source))
+	if !IsValidIssueImportSource(source) {
+		return "", "", fmt.Errorf("%w: source must be jira, linear or csv", ErrValidation)
+	}
+	return projectID, source, nil
+}
+
+func importedIssueText(value *string) string {
+	if value == nil {
+		return ""
+	}
+	return *value
+}
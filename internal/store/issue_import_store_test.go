@@ -0,0 +1,85 @@
+package store
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestIssueImportStoreUpsertIsKeyedByExternalID(t *testing.T) {
+	connStr := getTestDatabaseURL(t)
+	db := setupTestDatabase(t, connStr)
+	orgID := createTestOrganization(t, db, "issue-import-org")
+	projectID := createTestProject(t, db, orgID, "Import Project")
+	agentID := createAgentJobTestAgent(t, db, orgID, "import-author")
+	ctx := ctxWithWorkspace(orgID)
+	imports := NewIssueImportStore(db)
+
+	input := ImportIssueInput{
+		ProjectID:  projectID,
+		Source:     IssueImportSourceJira,
+		ExternalID: "OPS-1",
+		Title:      "Migrate billing",
+		State:      "open",
+		WorkStatus: IssueWorkStatusInProgress,
+		Priority:   IssuePriorityP1,
+	}
+	created, err := imports.UpsertIssue(ctx, input)
+	require.NoError(t, err)
+	require.True(t, created.Created)
+	require.Equal(t, "local", created.Issue.Origin)
+
+	unchanged, err := imports.UpsertIssue(ctx, input)
+	require.NoError(t, err)
+	require.False(t, unchanged.Created)
+	require.False(t, unchanged.Updated)
+	require.Equal(t, created.Issue.ID, unchanged.Issue.ID)
+
+	input.State = "closed"
+	input.WorkStatus = IssueWorkStatusDone
+	updated, err := imports.UpsertIssue(ctx, input)
+	require.NoError(t, err)
+	require.True(t, updated.Updated)
+	require.Equal(t, "closed", updated.Issue.State)
+	require.NotNil(t, updated.Issue.ClosedAt)
+
+	// The same key from another source is a different issue.
+	linear, err := imports.UpsertIssue(ctx, ImportIssueInput{
+		ProjectID:  projectID,
+		Source:     IssueImportSourceLinear,
+		ExternalID: "OPS-1",
+		Title:      "Linear copy",
+	})
+	require.NoError(t, err)
+	require.True(t, linear.Created)
+	require.NotEqual(t, created.Issue.ID, linear.Issue.ID)
+
+	listed, err := imports.ListImportedIssues(ctx, projectID, IssueImportSourceJira)
+	require.NoError(t, err)
+	require.Len(t, listed, 1)
+	require.Equal(t, created.Issue.ID, listed["OPS-1"].ID)
+
+	commentInput := ImportIssueCommentInput{
+		ProjectID:     projectID,
+		Source:        IssueImportSourceJira,
+		ExternalID:    "100",
+		IssueID:       created.Issue.ID,
+		AuthorAgentID: agentID,
+		Body:          "Started.",
+	}
+	inserted, err := imports.ImportComment(ctx, commentInput)
+	require.NoError(t, err)
+	require.True(t, inserted)
+	inserted, err = imports.ImportComment(ctx, commentInput)
+	require.NoError(t, err)
+	require.False(t, inserted)
+
+	commentIDs, err := imports.ListImportedCommentIDs(ctx, projectID, IssueImportSourceJira)
+	require.NoError(t, err)
+	require.Equal(t, map[string]bool{"100": true}, commentIDs)
+
+	_, err = imports.UpsertIssue(ctx, ImportIssueInput{ProjectID: projectID, Source: "trello", ExternalID: "T-1", Title: "x"})
+	require.ErrorIs(t, err, ErrValidation)
+	_, err = imports.UpsertIssue(ctx, ImportIssueInput{ProjectID: projectID, Source: IssueImportSourceCSV, Title: "x"})
+	require.ErrorIs(t, err, ErrValidation)
+}
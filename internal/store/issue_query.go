@@ -0,0 +1,864 @@
+package store
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/lib/pq"
+	"github.com/samhotchkiss/otter-camp/internal/middleware"
+)
+
+// Issue query qualifiers. A query is a space-separated list of qualifiers
+// (`key:value[,value...]`, OR within one qualifier, AND across qualifiers),
+// bare words and "quoted phrases" matched against title and body, and an
+// optional `sort:`. A leading `-` negates a qualifier or word.
+const (
+	IssueQueryFieldIs       = "is"
+	IssueQueryFieldState    = "state"
+	IssueQueryFieldLabel    = "label"
+	IssueQueryFieldOwner    = "owner"
+	IssueQueryFieldInvolves = "involves"
+	IssueQueryFieldPriority = "priority"
+	IssueQueryFieldStatus   = "status"
+	IssueQueryFieldOrigin   = "origin"
+	IssueQueryFieldKind     = "kind"
+	IssueQueryFieldNumber   = "number"
+	IssueQueryFieldParent   = "parent"
+	IssueQueryFieldUpdated  = "updated"
+	IssueQueryFieldCreated  = "created"
+	IssueQueryFieldDue      = "due"
+	IssueQueryFieldText     = "text"
+
+	// IssueQueryFieldCustomPrefix starts a custom field qualifier such as
+	// `field.severity:high` or `field.estimate:>3`.
+	IssueQueryFieldCustomPrefix = "field."
+
+	issueQueryMaxLength = 1000
+	issueQueryMaxLimit  = 200
+)
+
+var issueQueryRelativeDatePattern = regexp.MustCompile(`^(\d+)([hdw])$`)
+
+// IssueQuery is a parsed issue query.
+type IssueQuery struct {
+	Terms []IssueQueryTerm `json:"terms"`
+	Sort  IssueQuerySort   `json:"sort"`
+}
+
+// IssueQueryTerm is one qualifier or text match. Values are OR'd together.
+type IssueQueryTerm struct {
+	Field  string   `json:"field"`
+	Values []string `json:"values"`
+	Negate bool     `json:"negate,omitempty"`
+
+	date       *issueQueryDate
+	fieldRange *issueQueryFieldRange
+}
+
+// IssueQuerySort orders results. Field is one of updated, created, due,
+// priority or number.
+type IssueQuerySort struct {
+	Field string `json:"field"`
+	Desc  bool   `json:"desc"`
+}
+
+func (s IssueQuerySort) key() string {
+	if s.Desc {
+		return s.Field + "-desc"
+	}
+	return s.Field + "-asc"
+}
+
+type issueQueryDate struct {
+	op   string
+	from issueQueryDateBound
+	to   *issueQueryDateBound
+}
+
+type issueQueryDateBound struct {
+	absolute time.Time
+	relative time.Duration
+	isDay    bool
+	isRel    bool
+}
+
+// issueQueryFieldRange compares a number or date custom field. Bounds are
+// inclusive for `a..b`.
+type issueQueryFieldRange struct {
+	op      string
+	from    string
+	to      string
+	numeric bool
+}
+
+type issueQuerySortSpec struct {
+	cast        string
+	defaultDesc bool
+	expr        func(desc bool) string
+	value       func(issue ProjectIssue, desc bool) string
+}
+
+var issueQuerySorts = map[string]issueQuerySortSpec{
+	"updated": {
+		cast:        "timestamptz",
+		defaultDesc: true,
+		expr:        func(bool) string { return "i.updated_at" },
+		value: func(issue ProjectIssue, _ bool) string {
+			return issue.UpdatedAt.UTC().Format(time.RFC3339Nano)
+		},
+	},
+	"created": {
+		cast:        "timestamptz",
+		defaultDesc: true,
+		expr:        func(bool) string { return "i.created_at" },
+		value: func(issue ProjectIssue, _ bool) string {
+			return issue.CreatedAt.UTC().Format(time.RFC3339Nano)
+		},
+	},
+	// Issues without a due date sort last in both directions.
+	"due": {
+		cast: "timestamptz",
+		expr: func(desc bool) string {
+			if desc {
+				return "COALESCE(i.due_at, '-infinity'::timestamptz)"
+			}
+			return "COALESCE(i.due_at, 'infinity'::timestamptz)"
+		},
+		value: func(issue ProjectIssue, desc bool) string {
+			if issue.DueAt != nil {
+				return issue.DueAt.UTC().Format(time.RFC3339Nano)
+			}
+			if desc {
+				return "-infinity"
+			}
+			return "infinity"
+		},
+	},
+	"priority": {
+		cast: "text",
+		expr: func(bool) string { return "i.priority" },
+		value: func(issue ProjectIssue, _ bool) string {
+			return issue.Priority
+		},
+	},
+	"number": {
+		cast:        "bigint",
+		defaultDesc: true,
+		expr:        func(bool) string { return "i.issue_number" },
+		value: func(issue ProjectIssue, _ bool) string {
+			return strconv.FormatInt(issue.IssueNumber, 10)
+		},
+	},
+}
+
+// ParseIssueQuery parses a query such as
+// `is:open label:blog owner:@writer-2 priority:P0,P1 updated:>7d "launch post" sort:due`.
+// Errors wrap ErrValidation and describe the offending token.
+func ParseIssueQuery(raw string) (IssueQuery, error) {
+	query := IssueQuery{Sort: IssueQuerySort{Field: "number", Desc: true}}
+	if len(raw) > issueQueryMaxLength {
+		return IssueQuery{}, fmt.Errorf("%w: query is longer than %d characters", ErrValidation, issueQueryMaxLength)
+	}
+	tokens, err := tokenizeIssueQuery(raw)
+	if err != nil {
+		return IssueQuery{}, err
+	}
+	for _, token := range tokens {
+		text := token.text
+		if token.phrase {
+			query.Terms = append(query.Terms, IssueQueryTerm{Field: IssueQueryFieldText, Values: []string{text}, Negate: token.negate})
+			continue
+		}
+		negate := false
+		if strings.HasPrefix(text, "-") && len(text) > 1 {
+			negate = true
+			text = text[1:]
+		}
+
+		key, value, hasQualifier := strings.Cut(text, ":")
+		key = strings.ToLower(key)
+		if !hasQualifier || !isIssueQueryKey(key) {
+			query.Terms = append(query.Terms, IssueQueryTerm{Field: IssueQueryFieldText, Values: []string{text}, Negate: negate})
+			continue
+		}
+		if key == "sort" {
+			if negate {
+				return IssueQuery{}, fmt.Errorf("%w: sort cannot be negated", ErrValidation)
+			}
+			sort, err := parseIssueQuerySort(value)
+			if err != nil {
+				return IssueQuery{}, err
+			}
+			query.Sort = sort
+			continue
+		}
+		term, err := parseIssueQueryTerm(key, value)
+		if err != nil {
+			return IssueQuery{}, err
+		}
+		term.Negate = negate
+		query.Terms = append(query.Terms, term)
+	}
+	return query, nil
+}
+
+type issueQueryToken struct {
+	text   string
+	phrase bool
+	negate bool
+}
+
+// tokenizeIssueQuery splits on whitespace outside quotes. A token that starts
+// with a quote (or -") is a phrase; quotes inside a qualifier value, as in
+// label:"needs review", only group the value.
+func tokenizeIssueQuery(raw string) ([]issueQueryToken, error) {
+	runes := []rune(raw)
+	tokens := make([]issueQueryToken, 0)
+	for i := 0; i < len(runes); {
+		if unicode.IsSpace(runes[i]) {
+			i++
+			continue
+		}
+		token := issueQueryToken{}
+		if runes[i] == '-' && i+1 < len(runes) && runes[i+1] == '"' {
+			token.negate = true
+			i++
+		}
+		token.phrase = runes[i] == '"'
+
+		var text strings.Builder
+		inQuote := false
+		for ; i < len(runes); i++ {
+			r := runes[i]
+			if r == '"' {
+				inQuote = !inQuote
+				if !inQuote && token.phrase {
+					i++
+					break
+				}
+				continue
+			}
+			if unicode.IsSpace(r) && !inQuote {
+				break
+			}
+			text.WriteRune(r)
+		}
+		if inQuote {
+			return nil, fmt.Errorf("%w: unterminated quote", ErrValidation)
+		}
+		token.text = strings.TrimSpace(text.String())
+		if token.text != "" {
+			tokens = append(tokens, token)
+		}
+	}
+	return tokens, nil
+}
+
+func isIssueQueryKey(key string) bool {
+	switch key {
+	case IssueQueryFieldIs, IssueQueryFieldState, IssueQueryFieldLabel, IssueQueryFieldOwner,
+		IssueQueryFieldInvolves, IssueQueryFieldPriority, IssueQueryFieldStatus, IssueQueryFieldOrigin,
+		IssueQueryFieldKind, IssueQueryFieldNumber, IssueQueryFieldParent, IssueQueryFieldUpdated,
+		IssueQueryFieldCreated, IssueQueryFieldDue, "sort":
+		return true
+	}
+	if fieldKey, ok := strings.CutPrefix(key, IssueQueryFieldCustomPrefix); ok {
+		return issueFieldKeyPattern.MatchString(fieldKey)
+	}
+	return false
+}
+
+func parseIssueQuerySort(value string) (IssueQuerySort, error) {
+	value = strings.ToLower(strings.TrimSpace(value))
+	field, direction, _ := strings.Cut(value, "-")
+	spec, ok := issueQuerySorts[field]
+	if !ok {
+		return IssueQuerySort{}, fmt.Errorf("%w: sort must be updated, created, due, priority or number", ErrValidation)
+	}
+	sort := IssueQuerySort{Field: field, Desc: spec.defaultDesc}
+	switch direction {
+	case "":
+	case "asc":
+		sort.Desc = false
+	case "desc":
+		sort.Desc = true
+	default:
+		return IssueQuerySort{}, fmt.Errorf("%w: sort direction must be asc or desc", ErrValidation)
+	}
+	return sort, nil
+}
+
+func parseIssueQueryTerm(key, value string) (IssueQueryTerm, error) {
+	term := IssueQueryTerm{Field: key}
+	if key == IssueQueryFieldUpdated || key == IssueQueryFieldCreated || key == IssueQueryFieldDue {
+		date, err := parseIssueQueryDate(key, value)
+		if err != nil {
+			return IssueQueryTerm{}, err
+		}
+		term.Values = []string{strings.TrimSpace(value)}
+		term.date = &date
+		return term, nil
+	}
+	if strings.HasPrefix(key, IssueQueryFieldCustomPrefix) {
+		return parseIssueQueryCustomFieldTerm(key, value)
+	}
+
+	for _, part := range strings.Split(value, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		var normalized string
+		switch key {
+		case IssueQueryFieldIs:
+			normalized = strings.ToLower(part)
+			if normalized == "pull_request" {
+				normalized = "pr"
+			}
+			if normalized != "open" && normalized != "closed" && normalized != "issue" && normalized != "pr" {
+				return IssueQueryTerm{}, fmt.Errorf("%w: is: must be open, closed, issue or pr", ErrValidation)
+			}
+		case IssueQueryFieldState:
+			normalized = normalizeIssueState(part)
+			if !isValidIssueState(normalized) {
+				return IssueQueryTerm{}, fmt.Errorf("%w: invalid state %q", ErrValidation, part)
+			}
+		case IssueQueryFieldLabel:
+			normalized = strings.ToLower(part)
+		case IssueQueryFieldOwner, IssueQueryFieldInvolves:
+			normalized = strings.ToLower(strings.TrimPrefix(part, "@"))
+			if normalized == "" {
+				continue
+			}
+		case IssueQueryFieldPriority:
+			normalized = normalizeIssuePriority(part)
+			if !isValidIssuePriority(normalized) {
+				return IssueQueryTerm{}, fmt.Errorf("%w: invalid priority %q", ErrValidation, part)
+			}
+		case IssueQueryFieldStatus:
+			normalized = normalizeIssueWorkStatus(part)
+			if !isValidIssueWorkStatus(normalized) {
+				return IssueQueryTerm{}, fmt.Errorf("%w: invalid status %q", ErrValidation, part)
+			}
+		case IssueQueryFieldOrigin:
+			normalized = strings.ToLower(part)
+			if normalized != "local" && normalized != "github" {
+				return IssueQueryTerm{}, fmt.Errorf("%w: origin must be local or github", ErrValidation)
+			}
+		case IssueQueryFieldKind:
+			normalized = strings.ToLower(part)
+			if normalized != "issue" && normalized != "pull_request" {
+				return IssueQueryTerm{}, fmt.Errorf("%w: kind must be issue or pull_request", ErrValidation)
+			}
+		case IssueQueryFieldNumber:
+			number, err := strconv.ParseInt(strings.TrimPrefix(part, "#"), 10, 64)
+			if err != nil || number <= 0 {
+				return IssueQueryTerm{}, fmt.Errorf("%w: invalid issue number %q", ErrValidation, part)
+			}
+			normalized = strconv.FormatInt(number, 10)
+		case IssueQueryFieldParent:
+			if !uuidRegex.MatchString(part) {
+				return IssueQueryTerm{}, fmt.Errorf("%w: parent must be an issue id", ErrValidation)
+			}
+			normalized = part
+		}
+		term.Values = append(term.Values, normalized)
+	}
+	if len(term.Values) == 0 {
+		return IssueQueryTerm{}, fmt.Errorf("%w: %s: needs a value", ErrValidation, key)
+	}
+	return term, nil
+}
+
+// parseIssueQueryCustomFieldTerm accepts comma-separated values matched
+// case-insensitively (`none` matches issues without the field), or a
+// comparison (`>3`, `<=2026-03-01`, `1..5`) against number and date fields.
+func parseIssueQueryCustomFieldTerm(key, value string) (IssueQueryTerm, error) {
+	term := IssueQueryTerm{Field: key}
+	value = strings.TrimSpace(value)
+	fieldRange, isRange, err := parseIssueQueryFieldRange(key, value)
+	if err != nil {
+		return IssueQueryTerm{}, err
+	}
+	if isRange {
+		term.Values = []string{value}
+		term.fieldRange = &fieldRange
+		return term, nil
+	}
+	for _, part := range strings.Split(value, ",") {
+		if part = strings.ToLower(strings.TrimSpace(part)); part != "" {
+			term.Values = append(term.Values, part)
+		}
+	}
+	if len(term.Values) == 0 {
+		return IssueQueryTerm{}, fmt.Errorf("%w: %s: needs a value", ErrValidation, key)
+	}
+	return term, nil
+}
+
+func parseIssueQueryFieldRange(key, value string) (issueQueryFieldRange, bool, error) {
+	fieldRange := issueQueryFieldRange{}
+	if from, to, isRange := strings.Cut(value, ".."); isRange {
+		fieldRange.op = ".."
+		fieldRange.from = strings.TrimSpace(from)
+		fieldRange.to = strings.TrimSpace(to)
+	} else {
+		for _, candidate := range []string{">=", "<=", ">", "<"} {
+			if strings.HasPrefix(value, candidate) {
+				fieldRange.op = candidate
+				fieldRange.from = strings.TrimSpace(strings.TrimPrefix(value, candidate))
+				break
+			}
+		}
+		if fieldRange.op == "" {
+			return issueQueryFieldRange{}, false, nil
+		}
+	}
+	bounds := []string{fieldRange.from}
+	if fieldRange.op == ".." {
+		bounds = append(bounds, fieldRange.to)
+	}
+	numbers, dates := 0, 0
+	for _, bound := range bounds {
+		if _, err := strconv.ParseFloat(bound, 64); err == nil {
+			numbers++
+		} else if _, err := time.Parse(issueFieldDateLayout, bound); err == nil {
+			dates++
+		}
+	}
+	switch {
+	case numbers == len(bounds):
+		fieldRange.numeric = true
+	case dates == len(bounds):
+	default:
+		return issueQueryFieldRange{}, false, fmt.Errorf("%w: %s: %q must compare numbers or dates (YYYY-MM-DD)", ErrValidation, key, value)
+	}
+	return fieldRange, true, nil
+}
+
+// parseIssueQueryDate accepts `>7d`, `<=2026-03-01`, `2026-03-01..2026-03-31`,
+// a bare date (that whole day) or a bare offset. Offsets count back from now
+// for updated/created and forward for due, so `updated:>7d` is "changed in
+// the last week" and `due:<3d` is "due within three days, or overdue".
+func parseIssueQueryDate(key, value string) (issueQueryDate, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return issueQueryDate{}, fmt.Errorf("%w: %s: needs a value", ErrValidation, key)
+	}
+	if from, to, isRange := strings.Cut(value, ".."); isRange {
+		start, err := parseIssueQueryDateBound(key, from)
+		if err != nil {
+			return issueQueryDate{}, err
+		}
+		end, err := parseIssueQueryDateBound(key, to)
+		if err != nil {
+			return issueQueryDate{}, err
+		}
+		return issueQueryDate{op: "..", from: start, to: &end}, nil
+	}
+	op := ""
+	for _, candidate := range []string{">=", "<=", ">", "<"} {
+		if strings.HasPrefix(value, candidate) {
+			op = candidate
+			value = strings.TrimSpace(strings.TrimPrefix(value, candidate))
+			break
+		}
+	}
+	bound, err := parseIssueQueryDateBound(key, value)
+	if err != nil {
+		return issueQueryDate{}, err
+	}
+	return issueQueryDate{op: op, from: bound}, nil
+}
+
+func parseIssueQueryDateBound(key, value string) (issueQueryDateBound, error) {
+	value = strings.TrimSpace(value)
+	if match := issueQueryRelativeDatePattern.FindStringSubmatch(strings.ToLower(value)); match != nil {
+		amount, err := strconv.Atoi(match[1])
+		if err != nil || amount > 3650 {
+			return issueQueryDateBound{}, fmt.Errorf("%w: %s: offset %q is too large", ErrValidation, key, value)
+		}
+		unit := 24 * time.Hour
+		switch match[2] {
+		case "h":
+			unit = time.Hour
+		case "w":
+			unit = 7 * 24 * time.Hour
+		}
+		return issueQueryDateBound{relative: time.Duration(amount) * unit, isRel: true}, nil
+	}
+	if day, err := time.Parse("2006-01-02", value); err == nil {
+		return issueQueryDateBound{absolute: day.UTC(), isDay: true}, nil
+	}
+	if instant, err := time.Parse(time.RFC3339, value); err == nil {
+		return issueQueryDateBound{absolute: instant.UTC()}, nil
+	}
+	return issueQueryDateBound{}, fmt.Errorf("%w: %s: %q is not a date (YYYY-MM-DD), timestamp or offset like 7d", ErrValidation, key, value)
+}
+
+func (b issueQueryDateBound) resolve(now time.Time, forward bool) time.Time {
+	if !b.isRel {
+		return b.absolute
+	}
+	if forward {
+		return now.Add(b.relative)
+	}
+	return now.Add(-b.relative)
+}
+
+// issueQuerySQL collects WHERE conditions and their positional arguments.
+type issueQuerySQL struct {
+	conditions []string
+	args       []any
+}
+
+func (b *issueQuerySQL) arg(value any) string {
+	b.args = append(b.args, value)
+	return fmt.Sprintf("$%d", len(b.args))
+}
+
+func (b *issueQuerySQL) add(condition string) {
+	b.conditions = append(b.conditions, condition)
+}
+
+func (b *issueQuerySQL) addTerms(terms []IssueQueryTerm, now time.Time) error {
+	for _, term := range terms {
+		condition, err := b.termCondition(term, now)
+		if err != nil {
+			return err
+		}
+		if term.Negate {
+			condition = "NOT COALESCE((" + condition + "), FALSE)"
+		}
+		b.add(condition)
+	}
+	return nil
+}
+
+const issueQueryPullRequestCondition = `EXISTS (SELECT 1 FROM project_issue_github_links gl WHERE gl.issue_id = i.id AND gl.github_url ILIKE '%/pull/%')`
+
+func (b *issueQuerySQL) termCondition(term IssueQueryTerm, now time.Time) (string, error) {
+	switch term.Field {
+	case IssueQueryFieldIs:
+		parts := make([]string, 0, len(term.Values))
+		for _, value := range term.Values {
+			switch value {
+			case "open", "closed":
+				parts = append(parts, "i.state = "+b.arg(value))
+			case "pr":
+				parts = append(parts, issueQueryPullRequestCondition)
+			case "issue":
+				parts = append(parts, "NOT "+issueQueryPullRequestCondition)
+			}
+		}
+		return "(" + strings.Join(parts, " OR ") + ")", nil
+	case IssueQueryFieldKind:
+		parts := make([]string, 0, len(term.Values))
+		for _, value := range term.Values {
+			if value == "pull_request" {
+				parts = append(parts, issueQueryPullRequestCondition)
+			} else {
+				parts = append(parts, "NOT "+issueQueryPullRequestCondition)
+			}
+		}
+		return "(" + strings.Join(parts, " OR ") + ")", nil
+	case IssueQueryFieldState:
+		return "i.state = ANY(" + b.arg(pq.Array(term.Values)) + ")", nil
+	case IssueQueryFieldPriority:
+		return "i.priority = ANY(" + b.arg(pq.Array(term.Values)) + ")", nil
+	case IssueQueryFieldStatus:
+		return "i.work_status = ANY(" + b.arg(pq.Array(term.Values)) + ")", nil
+	case IssueQueryFieldOrigin:
+		return "i.origin = ANY(" + b.arg(pq.Array(term.Values)) + ")", nil
+	case IssueQueryFieldNumber:
+		numbers := make([]int64, 0, len(term.Values))
+		for _, value := range term.Values {
+			number, _ := strconv.ParseInt(value, 10, 64)
+			numbers = append(numbers, number)
+		}
+		return "i.issue_number = ANY(" + b.arg(pq.Array(numbers)) + ")", nil
+	case IssueQueryFieldParent:
+		return "i.parent_issue_id::text = ANY(" + b.arg(pq.Array(term.Values)) + ")", nil
+	case IssueQueryFieldLabel:
+		return `EXISTS (
+			SELECT 1 FROM issue_labels il
+			JOIN labels lb ON lb.id = il.label_id
+			WHERE il.issue_id = i.id AND lower(lb.name) = ANY(` + b.arg(pq.Array(term.Values)) + `))`, nil
+	case IssueQueryFieldOwner:
+		refs := make([]string, 0, len(term.Values))
+		parts := make([]string, 0, 2)
+		for _, value := range term.Values {
+			if value == "none" {
+				parts = append(parts, "i.owner_agent_id IS NULL")
+				continue
+			}
+			refs = append(refs, value)
+		}
+		if len(refs) > 0 {
+			ref := b.arg(pq.Array(refs))
+			parts = append(parts, `i.owner_agent_id IN (
+				SELECT a.id FROM agents a
+				WHERE a.org_id = i.org_id AND (lower(a.slug) = ANY(`+ref+`) OR a.id::text = ANY(`+ref+`)))`)
+		}
+		return "(" + strings.Join(parts, " OR ") + ")", nil
+	case IssueQueryFieldInvolves:
+		ref := b.arg(pq.Array(term.Values))
+		return `EXISTS (
+			SELECT 1 FROM project_issue_participants p
+			JOIN agents a ON a.id = p.agent_id
+			WHERE p.issue_id = i.id
+			  AND p.removed_at IS NULL
+			  AND (lower(a.slug) = ANY(` + ref + `) OR a.id::text = ANY(` + ref + `)))`, nil
+	case IssueQueryFieldUpdated, IssueQueryFieldCreated, IssueQueryFieldDue:
+		return b.dateCondition(term, now)
+	case IssueQueryFieldText:
+		pattern := b.arg("%" + escapeIssueQueryLike(term.Values[0]) + "%")
+		return "(i.title ILIKE " + pattern + " OR COALESCE(i.body, '') ILIKE " + pattern + ")", nil
+	}
+	if strings.HasPrefix(term.Field, IssueQueryFieldCustomPrefix) {
+		return b.customFieldCondition(term)
+	}
+	return "", fmt.Errorf("%w: unknown qualifier %q", ErrValidation, term.Field)
+}
+
+func (b *issueQuerySQL) dateCondition(term IssueQueryTerm, now time.Time) (string, error) {
+	date := term.date
+	if date == nil {
+		parsed, err := parseIssueQueryDate(term.Field, strings.Join(term.Values, ","))
+		if err != nil {
+			return "", err
+		}
+		date = &parsed
+	}
+	column := "i." + term.Field + "_at"
+	forward := term.Field == IssueQueryFieldDue
+	from := date.from.resolve(now, forward)
+	dayEnd := from.Add(24 * time.Hour)
+
+	switch date.op {
+	case "..":
+		to := date.to.resolve(now, forward)
+		if date.to.isDay {
+			to = to.Add(24 * time.Hour)
+		}
+		return column + " >= " + b.arg(from) + " AND " + column + " < " + b.arg(to), nil
+	case ">":
+		if date.from.isDay {
+			return column + " >= " + b.arg(dayEnd), nil
+		}
+		return column + " > " + b.arg(from), nil
+	case ">=":
+		return column + " >= " + b.arg(from), nil
+	case "<":
+		return column + " < " + b.arg(from), nil
+	case "<=":
+		if date.from.isDay {
+			return column + " < " + b.arg(dayEnd), nil
+		}
+		return column + " <= " + b.arg(from), nil
+	}
+	switch {
+	case date.from.isDay:
+		return column + " >= " + b.arg(from) + " AND " + column + " < " + b.arg(dayEnd), nil
+	case date.from.isRel && forward:
+		return column + " <= " + b.arg(from), nil
+	default:
+		return column + " >= " + b.arg(from), nil
+	}
+}
+
+func (b *issueQuerySQL) customFieldCondition(term IssueQueryTerm) (string, error) {
+	key := b.arg(strings.TrimPrefix(term.Field, IssueQueryFieldCustomPrefix))
+	exists := "EXISTS (SELECT 1 FROM issue_field_values fv WHERE fv.issue_id = i.id AND fv.field_key = " + key
+	fieldRange := term.fieldRange
+	if fieldRange == nil && len(term.Values) == 1 {
+		parsed, isRange, err := parseIssueQueryFieldRange(term.Field, term.Values[0])
+		if err != nil {
+			return "", err
+		}
+		if isRange {
+			fieldRange = &parsed
+		}
+	}
+	if fieldRange != nil {
+		column := "fv.value_date"
+		bound := func(value string) string { return b.arg(value) + "::date" }
+		if fieldRange.numeric {
+			column = "fv.value_number"
+			bound = func(value string) string { return b.arg(value) + "::numeric" }
+		}
+		switch fieldRange.op {
+		case "..":
+			return exists + " AND " + column + " >= " + bound(fieldRange.from) + " AND " + column + " <= " + bound(fieldRange.to) + ")", nil
+		default:
+			return exists + " AND " + column + " " + fieldRange.op + " " + bound(fieldRange.from) + ")", nil
+		}
+	}
+
+	values := make([]string, 0, len(term.Values))
+	parts := make([]string, 0, 2)
+	for _, value := range term.Values {
+		if value == "none" {
+			parts = append(parts, "NOT "+exists+")")
+			continue
+		}
+		values = append(values, value)
+	}
+	if len(values) > 0 {
+		parts = append(parts, exists+" AND lower(fv.value_text) = ANY("+b.arg(pq.Array(values))+"))")
+	}
+	return "(" + strings.Join(parts, " OR ") + ")", nil
+}
+
+func escapeIssueQueryLike(value string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(value)
+}
+
+// IssueQueryCursor marks the last row of a page for keyset pagination.
+type IssueQueryCursor struct {
+	Sort  string
+	Value string
+	ID    string
+}
+
+func EncodeIssueQueryCursor(cursor IssueQueryCursor) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(cursor.Sort + "|" + cursor.Value + "|" + cursor.ID))
+}
+
+func DecodeIssueQueryCursor(raw string) (*IssueQueryCursor, error) {
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return nil, nil
+	}
+	decoded, err := base64.RawURLEncoding.DecodeString(raw)
+	if err != nil {
+		return nil, fmt.Errorf("%w: invalid cursor", ErrValidation)
+	}
+	parts := strings.Split(string(decoded), "|")
+	if len(parts) != 3 || !uuidRegex.MatchString(parts[2]) {
+		return nil, fmt.Errorf("%w: invalid cursor", ErrValidation)
+	}
+	return &IssueQueryCursor{Sort: parts[0], Value: parts[1], ID: parts[2]}, nil
+}
+
+// IssueQueryInput runs a parsed query. ProjectID is optional: without it the
+// query spans every project in the workspace.
+type IssueQueryInput struct {
+	ProjectID string
+	Query     IssueQuery
+	Cursor    *IssueQueryCursor
+	Limit     int
+	Now       time.Time
+}
+
+type IssueQueryPage struct {
+	Items      []ProjectIssue `json:"items"`
+	NextCursor string         `json:"next_cursor,omitempty"`
+}
+
+// buildIssueQuery returns the SELECT for one page, fetching limit+1 rows so
+// the caller can tell whether another page exists.
+func buildIssueQuery(workspaceID string, input IssueQueryInput, limit int) (string, []any, error) {
+	sortSpec, ok := issueQuerySorts[input.Query.Sort.Field]
+	if !ok {
+		return "", nil, fmt.Errorf("%w: invalid sort", ErrValidation)
+	}
+	desc := input.Query.Sort.Desc
+	now := input.Now
+	if now.IsZero() {
+		now = time.Now()
+	}
+
+	b := &issueQuerySQL{}
+	b.add("i.org_id = " + b.arg(workspaceID))
+	if projectID := strings.TrimSpace(input.ProjectID); projectID != "" {
+		if !uuidRegex.MatchString(projectID) {
+			return "", nil, fmt.Errorf("%w: invalid project_id", ErrValidation)
+		}
+		b.add("i.project_id = " + b.arg(projectID))
+	}
+	if err := b.addTerms(input.Query.Terms, now.UTC()); err != nil {
+		return "", nil, err
+	}
+
+	sortExpr := sortSpec.expr(desc)
+	direction, comparator := "ASC", ">"
+	if desc {
+		direction, comparator = "DESC", "<"
+	}
+	if cursor := input.Cursor; cursor != nil {
+		if cursor.Sort != input.Query.Sort.key() {
+			return "", nil, fmt.Errorf("%w: cursor does not match the query's sort", ErrValidation)
+		}
+		b.add(fmt.Sprintf("(%s, i.id) %s (%s::%s, %s::uuid)", sortExpr, comparator, b.arg(cursor.Value), sortSpec.cast, b.arg(cursor.ID)))
+	}
+
+	query := `SELECT i.id, i.org_id, i.project_id, i.issue_number, i.title, i.body, i.state, i.origin, i.document_path, i.approval_state, i.owner_agent_id, i.work_status, i.priority, i.due_at, i.next_step, i.next_step_due_at, i.flow_template_id, i.flow_step_key, i.flow_step_index, i.parent_issue_id, i.merged_into_issue_id, i.created_at, i.updated_at, i.closed_at
+		FROM project_issues i
+		WHERE ` + strings.Join(b.conditions, "\n\t\t  AND ") + fmt.Sprintf(`
+		ORDER BY %s %s, i.id %s
+		LIMIT %s`, sortExpr, direction, direction, b.arg(limit+1))
+	return query, b.args, nil
+}
+
+// QueryIssues runs a structured issue query with keyset pagination.
+func (s *ProjectIssueStore) QueryIssues(ctx context.Context, input IssueQueryInput) (IssueQueryPage, error) {
+	workspaceID := middleware.WorkspaceFromContext(ctx)
+	if workspaceID == "" {
+		return IssueQueryPage{}, ErrNoWorkspace
+	}
+	limit := input.Limit
+	if limit <= 0 || limit > issueQueryMaxLimit {
+		limit = 100
+	}
+	query, args, err := buildIssueQuery(workspaceID, input, limit)
+	if err != nil {
+		return IssueQueryPage{}, err
+	}
+
+	conn, err := WithWorkspace(ctx, s.db)
+	if err != nil {
+		return IssueQueryPage{}, err
+	}
+	defer conn.Close()
+
+	rows, err := conn.QueryContext(ctx, query, args...)
+	if err != nil {
+		return IssueQueryPage{}, fmt.Errorf("failed to query issues: %w", err)
+	}
+	defer rows.Close()
+
+	items := make([]ProjectIssue, 0)
+	for rows.Next() {
+		issue, err := scanProjectIssue(rows)
+		if err != nil {
+			return IssueQueryPage{}, fmt.Errorf("failed to scan issue row: %w", err)
+		}
+		if issue.OrgID != workspaceID {
+			return IssueQueryPage{}, ErrForbidden
+		}
+		items = append(items, issue)
+	}
+	if err := rows.Err(); err != nil {
+		return IssueQueryPage{}, fmt.Errorf("failed to read issue rows: %w", err)
+	}
+
+	page := IssueQueryPage{Items: items}
+	if len(items) > limit {
+		page.Items = items[:limit]
+		last := page.Items[limit-1]
+		sort := input.Query.Sort
+		page.NextCursor = EncodeIssueQueryCursor(IssueQueryCursor{
+			Sort:  sort.key(),
+			Value: issueQuerySorts[sort.Field].value(last, sort.Desc),
+			ID:    last.ID,
+		})
+	}
+	return page, nil
+}
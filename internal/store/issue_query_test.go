@@ -0,0 +1,241 @@
+package store
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseIssueQuery(t *testing.T) {
+	query, err := ParseIssueQuery(`is:open label:blog,Launch owner:@Writer-2 priority:p0,P1 updated:>7d "launch post" draft -label:wontfix sort:due`)
+	require.NoError(t, err)
+	require.Equal(t, IssueQuerySort{Field: "due", Desc: false}, query.Sort)
+
+	fields := make([]string, 0, len(query.Terms))
+	for _, term := range query.Terms {
+		fields = append(fields, term.Field)
+	}
+	require.Equal(t, []string{"is", "label", "owner", "priority", "updated", "text", "text", "label"}, fields)
+	require.Equal(t, []string{"blog", "launch"}, query.Terms[1].Values)
+	require.Equal(t, []string{"writer-2"}, query.Terms[2].Values)
+	require.Equal(t, []string{"P0", "P1"}, query.Terms[3].Values)
+	require.Equal(t, []string{"launch post"}, query.Terms[5].Values)
+	require.Equal(t, []string{"draft"}, query.Terms[6].Values)
+	require.True(t, query.Terms[7].Negate)
+	require.Equal(t, []string{"wontfix"}, query.Terms[7].Values)
+}
+
+func TestParseIssueQueryQuotedValuesAndNegatedPhrases(t *testing.T) {
+	query, err := ParseIssueQuery(`label:"needs review" -"do not ship" sort:updated-asc`)
+	require.NoError(t, err)
+	require.Len(t, query.Terms, 2)
+	require.Equal(t, IssueQueryFieldLabel, query.Terms[0].Field)
+	require.Equal(t, []string{"needs review"}, query.Terms[0].Values)
+	require.Equal(t, IssueQueryFieldText, query.Terms[1].Field)
+	require.True(t, query.Terms[1].Negate)
+	require.Equal(t, []string{"do not ship"}, query.Terms[1].Values)
+	require.Equal(t, IssueQuerySort{Field: "updated", Desc: false}, query.Sort)
+}
+
+func TestParseIssueQueryDefaultsToNewestNumberFirst(t *testing.T) {
+	query, err := ParseIssueQuery("")
+	require.NoError(t, err)
+	require.Empty(t, query.Terms)
+	require.Equal(t, IssueQuerySort{Field: "number", Desc: true}, query.Sort)
+}
+
+func TestParseIssueQueryRejectsInvalidInput(t *testing.T) {
+	cases := map[string]string{
+		"priority:P9":             "invalid priority",
+		"status:sleeping":         "invalid status",
+		"is:stale":                "is: must be",
+		"updated:>soon":           "is not a date",
+		"sort:random":             "sort must be",
+		"sort:due-sideways":       "sort direction",
+		`"unterminated phrase`:    "unterminated quote",
+		"label:":                  "needs a value",
+		"-sort:due":               "cannot be negated",
+		"parent:not-a-uuid":       "parent must be",
+		"number:0":                "invalid issue number",
+		"origin:gitlab":           "origin must be",
+		"due:2026-02-30":          "is not a date",
+		"field.estimate:>soon":    "must compare numbers or dates",
+		"field.estimate:1..later": "must compare numbers or dates",
+		strings.Repeat("a", 1001): "longer than",
+	}
+	for raw, want := range cases {
+		_, err := ParseIssueQuery(raw)
+		require.Error(t, err, raw)
+		require.True(t, errors.Is(err, ErrValidation), raw)
+		require.Contains(t, err.Error(), want, raw)
+	}
+}
+
+func TestParseIssueQueryTreatsUnknownQualifiersAsText(t *testing.T) {
+	query, err := ParseIssueQuery("https://example.com/post")
+	require.NoError(t, err)
+	require.Len(t, query.Terms, 1)
+	require.Equal(t, IssueQueryFieldText, query.Terms[0].Field)
+	require.Equal(t, []string{"https://example.com/post"}, query.Terms[0].Values)
+}
+
+func TestBuildIssueQuery(t *testing.T) {
+	now := time.Date(2026, 10, 18, 12, 0, 0, 0, time.UTC)
+	orgID := "00000000-0000-0000-0000-000000000001"
+	projectID := "00000000-0000-0000-0000-000000000002"
+
+	query, err := ParseIssueQuery(`is:open label:blog owner:@writer-2,none updated:>7d due:2026-10-20 "50%_off" -priority:P3 sort:due`)
+	require.NoError(t, err)
+	sql, args, err := buildIssueQuery(orgID, IssueQueryInput{ProjectID: projectID, Query: query, Now: now}, 25)
+	require.NoError(t, err)
+
+	require.Contains(t, sql, "i.org_id = $1")
+	require.Contains(t, sql, "i.project_id = $2")
+	require.Contains(t, sql, "i.state = $3")
+	require.Contains(t, sql, "FROM issue_labels il")
+	require.Contains(t, sql, "i.owner_agent_id IS NULL OR i.owner_agent_id IN")
+	require.Contains(t, sql, "i.updated_at > $6")
+	require.Contains(t, sql, "i.due_at >= $7 AND i.due_at < $8")
+	require.Contains(t, sql, "i.title ILIKE $9 OR COALESCE(i.body, '') ILIKE $9")
+	require.Contains(t, sql, "NOT COALESCE((i.priority = ANY($10)), FALSE)")
+	require.Contains(t, sql, "ORDER BY COALESCE(i.due_at, 'infinity'::timestamptz) ASC, i.id ASC")
+	require.Contains(t, sql, "LIMIT $11")
+
+	require.Equal(t, orgID, args[0])
+	require.Equal(t, projectID, args[1])
+	require.Equal(t, "open", args[2])
+	require.Equal(t, now.Add(-7*24*time.Hour), args[5])
+	require.Equal(t, time.Date(2026, 10, 20, 0, 0, 0, 0, time.UTC), args[6])
+	require.Equal(t, time.Date(2026, 10, 21, 0, 0, 0, 0, time.UTC), args[7])
+	require.Equal(t, `%50\%\_off%`, args[8])
+	require.Equal(t, 26, args[10])
+}
+
+func TestBuildIssueQueryDueOffsetsCountForward(t *testing.T) {
+	now := time.Date(2026, 10, 18, 12, 0, 0, 0, time.UTC)
+	query, err := ParseIssueQuery("due:<3d created:2026-10-01..2026-10-07")
+	require.NoError(t, err)
+	sql, args, err := buildIssueQuery("00000000-0000-0000-0000-000000000001", IssueQueryInput{Query: query, Now: now}, 10)
+	require.NoError(t, err)
+	require.NotContains(t, sql, "i.project_id =")
+	require.Contains(t, sql, "i.due_at < $2")
+	require.Contains(t, sql, "i.created_at >= $3 AND i.created_at < $4")
+	require.Equal(t, now.Add(72*time.Hour), args[1])
+	require.Equal(t, time.Date(2026, 10, 8, 0, 0, 0, 0, time.UTC), args[3])
+}
+
+func TestBuildIssueQueryFiltersCustomFields(t *testing.T) {
+	query, err := ParseIssueQuery("field.severity:High,none field.estimate:>=3 field.launch:2026-10-01..2026-10-31")
+	require.NoError(t, err)
+	require.Equal(t, "field.severity", query.Terms[0].Field)
+	require.Equal(t, []string{"high", "none"}, query.Terms[0].Values)
+
+	sql, args, err := buildIssueQuery("00000000-0000-0000-0000-000000000001", IssueQueryInput{Query: query}, 10)
+	require.NoError(t, err)
+	require.Contains(t, sql, "(NOT EXISTS (SELECT 1 FROM issue_field_values fv WHERE fv.issue_id = i.id AND fv.field_key = $2) OR EXISTS")
+	require.Contains(t, sql, "lower(fv.value_text) = ANY($3)")
+	require.Contains(t, sql, "fv.field_key = $4 AND fv.value_number >= $5::numeric")
+	require.Contains(t, sql, "fv.value_date >= $7::date AND fv.value_date <= $8::date")
+	require.Equal(t, "severity", args[1])
+	require.Equal(t, "3", args[4])
+
+	// Invalid keys fall back to text search.
+	text, err := ParseIssueQuery("field.Bad-Key:x")
+	require.NoError(t, err)
+	require.Equal(t, IssueQueryFieldText, text.Terms[0].Field)
+}
+
+func TestBuildIssueQueryAppliesCursor(t *testing.T) {
+	query, err := ParseIssueQuery("sort:updated")
+	require.NoError(t, err)
+	cursor, err := DecodeIssueQueryCursor(EncodeIssueQueryCursor(IssueQueryCursor{
+		Sort:  "updated-desc",
+		Value: "2026-10-18T12:00:00Z",
+		ID:    "00000000-0000-0000-0000-000000000003",
+	}))
+	require.NoError(t, err)
+
+	sql, args, err := buildIssueQuery("00000000-0000-0000-0000-000000000001", IssueQueryInput{Query: query, Cursor: cursor}, 10)
+	require.NoError(t, err)
+	require.Contains(t, sql, "(i.updated_at, i.id) < ($2::timestamptz, $3::uuid)")
+	require.Contains(t, sql, "ORDER BY i.updated_at DESC, i.id DESC")
+	require.Equal(t, "2026-10-18T12:00:00Z", args[1])
+
+	otherSort, err := ParseIssueQuery("sort:created")
+	require.NoError(t, err)
+	_, _, err = buildIssueQuery("00000000-0000-0000-0000-000000000001", IssueQueryInput{Query: otherSort, Cursor: cursor}, 10)
+	require.ErrorIs(t, err, ErrValidation)
+
+	_, err = DecodeIssueQueryCursor("not a cursor")
+	require.ErrorIs(t, err, ErrValidation)
+}
+
+func TestProjectIssueStore_QueryIssuesFiltersSortsAndPaginates(t *testing.T) {
+	connStr := getTestDatabaseURL(t)
+	db := setupTestDatabase(t, connStr)
+	orgID := createTestOrganization(t, db, "issue-query-org")
+	projectID := createTestProject(t, db, orgID, "Issue Query Project")
+	writerID := createIssueTestAgent(t, db, orgID, "writer-2")
+
+	issueStore := NewProjectIssueStore(db)
+	ctx := ctxWithWorkspace(orgID)
+
+	dueSoon := time.Now().UTC().Add(24 * time.Hour)
+	dueLater := time.Now().UTC().Add(72 * time.Hour)
+	launch, err := issueStore.CreateIssue(ctx, CreateProjectIssueInput{
+		ProjectID:    projectID,
+		Title:        "Draft the launch post",
+		Origin:       "local",
+		OwnerAgentID: &writerID,
+		Priority:     IssuePriorityP0,
+		DueAt:        &dueLater,
+	})
+	require.NoError(t, err)
+	followUp, err := issueStore.CreateIssue(ctx, CreateProjectIssueInput{
+		ProjectID:    projectID,
+		Title:        "Launch post follow-up",
+		Origin:       "local",
+		OwnerAgentID: &writerID,
+		Priority:     IssuePriorityP1,
+		DueAt:        &dueSoon,
+	})
+	require.NoError(t, err)
+	_, err = issueStore.CreateIssue(ctx, CreateProjectIssueInput{
+		ProjectID: projectID,
+		Title:     "Unrelated cleanup",
+		Origin:    "local",
+		Priority:  IssuePriorityP3,
+	})
+	require.NoError(t, err)
+
+	var labelID string
+	require.NoError(t, db.QueryRow(`INSERT INTO labels (org_id, name) VALUES ($1, 'blog') RETURNING id`, orgID).Scan(&labelID))
+	_, err = db.Exec(`INSERT INTO issue_labels (issue_id, label_id) VALUES ($1, $3), ($2, $3)`, launch.ID, followUp.ID, labelID)
+	require.NoError(t, err)
+
+	query, err := ParseIssueQuery(`is:open label:blog owner:@writer-2 priority:P0,P1 updated:>7d "launch post" sort:due`)
+	require.NoError(t, err)
+	first, err := issueStore.QueryIssues(ctx, IssueQueryInput{ProjectID: projectID, Query: query, Limit: 1})
+	require.NoError(t, err)
+	require.Len(t, first.Items, 1)
+	require.Equal(t, followUp.ID, first.Items[0].ID)
+	require.NotEmpty(t, first.NextCursor)
+
+	cursor, err := DecodeIssueQueryCursor(first.NextCursor)
+	require.NoError(t, err)
+	second, err := issueStore.QueryIssues(ctx, IssueQueryInput{ProjectID: projectID, Query: query, Cursor: cursor, Limit: 1})
+	require.NoError(t, err)
+	require.Len(t, second.Items, 1)
+	require.Equal(t, launch.ID, second.Items[0].ID)
+	require.Empty(t, second.NextCursor)
+
+	unassigned, err := ParseIssueQuery("owner:none -label:blog")
+	require.NoError(t, err)
+	page, err := issueStore.QueryIssues(ctx, IssueQueryInput{Query: unassigned})
+	require.NoError(t, err)
+	require.Len(t, page.Items, 1)
+	require.Equal(t, "Unrelated cleanup", page.Items[0].Title)
+}
@@ -0,0 +1,284 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+const maxIssueSavedViewNameLength = 80
+
+// IssueSavedView is a named issue query, personal (UserID) or shared with a
+// project (ProjectID).
+type IssueSavedView struct {
+	ID        string    `json:"id"`
+	OrgID     string    `json:"org_id"`
+	UserID    *string   `json:"user_id,omitempty"`
+	ProjectID *string   `json:"project_id,omitempty"`
+	Name      string    `json:"name"`
+	Query     string    `json:"query"`
+	CreatedBy *string   `json:"created_by,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// SaveIssueSavedViewInput creates or replaces a view by name within its
+// scope. Exactly one of UserID and ProjectID must be set.
+type SaveIssueSavedViewInput struct {
+	UserID    string
+	ProjectID string
+	Name      string
+	Query     string
+	CreatedBy string
+}
+
+type IssueSavedViewStore struct {
+	db *sql.DB
+}
+
+func NewIssueSavedViewStore(db *sql.DB) *IssueSavedViewStore {
+	return &IssueSavedViewStore{db: db}
+}
+
+const issueSavedViewColumns = `id, org_id, user_id, project_id, name, query, created_by, created_at, updated_at`
+
+func (s *IssueSavedViewStore) Save(ctx context.Context, orgID string, input SaveIssueSavedViewInput) (*IssueSavedView, error) {
+	if s == nil || s.db == nil {
+		return nil, fmt.Errorf("issue saved view store is not configured")
+	}
+	userID := strings.TrimSpace(input.UserID)
+	projectID := strings.TrimSpace(input.ProjectID)
+	name := strings.TrimSpace(input.Name)
+	query := strings.TrimSpace(input.Query)
+	if (userID == "") == (projectID == "") {
+		return nil, fmt.Errorf("%w: a view belongs to either a user or a project", ErrValidation)
+	}
+	if userID != "" && !uuidRegex.MatchString(userID) {
+		return nil, fmt.Errorf("%w: user_id must be a UUID", ErrValidation)
+	}
+	if projectID != "" && !uuidRegex.MatchString(projectID) {
+		return nil, fmt.Errorf("%w: project_id must be a UUID", ErrValidation)
+	}
+	if name == "" {
+		return nil, fmt.Errorf("%w: name is required", ErrValidation)
+	}
+	if len(name) > maxIssueSavedViewNameLength {
+		return nil, fmt.Errorf("%w: name must be at most %d characters", ErrValidation, maxIssueSavedViewNameLength)
+	}
+	if uuidRegex.MatchString(name) {
+		return nil, fmt.Errorf("%w: name cannot be a UUID", ErrValidation)
+	}
+	if _, err := ParseIssueQuery(query); err != nil {
+		return nil, err
+	}
+	var createdBy any
+	if createdByID := strings.TrimSpace(input.CreatedBy); uuidRegex.MatchString(createdByID) {
+		createdBy = createdByID
+	}
+
+	conn, err := WithWorkspaceID(ctx, s.db, orgID)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	var row *sql.Row
+	if userID != "" {
+		row = conn.QueryRowContext(
+			ctx,
+			`INSERT INTO issue_saved_views (org_id, user_id, name, query, created_by)
+			 VALUES ($1, $2, $3, $4, $5)
+			 ON CONFLICT (org_id, user_id, lower(name)) WHERE user_id IS NOT NULL
+			 DO UPDATE SET name = EXCLUDED.name, query = EXCLUDED.query, updated_at = NOW()
+			 RETURNING `+issueSavedViewColumns,
+			orgID, userID, name, query, createdBy,
+		)
+	} else {
+		var exists bool
+		if err := conn.QueryRowContext(
+			ctx,
+			`SELECT EXISTS(SELECT 1 FROM projects WHERE org_id = $1 AND id = $2)`,
+			orgID,
+			projectID,
+		).Scan(&exists); err != nil {
+			return nil, fmt.Errorf("failed to check project: %w", err)
+		}
+		if !exists {
+			return nil, ErrNotFound
+		}
+		row = conn.QueryRowContext(
+			ctx,
+			`INSERT INTO issue_saved_views (org_id, project_id, name, query, created_by)
+			 VALUES ($1, $2, $3, $4, $5)
+			 ON CONFLICT (org_id, project_id, lower(name)) WHERE project_id IS NOT NULL
+			 DO UPDATE SET name = EXCLUDED.name, query = EXCLUDED.query, updated_at = NOW()
+			 RETURNING `+issueSavedViewColumns,
+			orgID, projectID, name, query, createdBy,
+		)
+	}
+	view, err := scanIssueSavedView(row)
+	if err != nil {
+		return nil, fmt.Errorf("failed to save issue view: %w", err)
+	}
+	return &view, nil
+}
+
+// List returns the caller's personal views followed by project views, for
+// projectID only when it is set.
+func (s *IssueSavedViewStore) List(ctx context.Context, orgID, userID, projectID string) ([]IssueSavedView, error) {
+	if s == nil || s.db == nil {
+		return nil, fmt.Errorf("issue saved view store is not configured")
+	}
+	userID = strings.TrimSpace(userID)
+	projectID = strings.TrimSpace(projectID)
+	if projectID != "" && !uuidRegex.MatchString(projectID) {
+		return nil, fmt.Errorf("%w: project_id must be a UUID", ErrValidation)
+	}
+	conn, err := WithWorkspaceID(ctx, s.db, orgID)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	rows, err := conn.QueryContext(
+		ctx,
+		`SELECT `+issueSavedViewColumns+`
+		 FROM issue_saved_views
+		 WHERE org_id = $1
+		   AND (
+		     user_id::text = NULLIF($2, '')
+		     OR (project_id IS NOT NULL AND (NULLIF($3, '') IS NULL OR project_id::text = $3))
+		   )
+		 ORDER BY user_id IS NULL, lower(name), project_id`,
+		orgID,
+		userID,
+		projectID,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list issue views: %w", err)
+	}
+	defer rows.Close()
+
+	views := make([]IssueSavedView, 0)
+	for rows.Next() {
+		view, err := scanIssueSavedView(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan issue view: %w", err)
+		}
+		views = append(views, view)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read issue views: %w", err)
+	}
+	return views, nil
+}
+
+// Resolve finds a view by id or name. Names match the caller's personal views
+// first, then project views (in projectID when set). A name shared by views
+// in several projects is a conflict.
+func (s *IssueSavedViewStore) Resolve(ctx context.Context, orgID, ref, userID, projectID string) (*IssueSavedView, error) {
+	ref = strings.TrimSpace(ref)
+	if ref == "" {
+		return nil, fmt.Errorf("%w: view is required", ErrValidation)
+	}
+	views, err := s.List(ctx, orgID, userID, projectID)
+	if err != nil {
+		return nil, err
+	}
+	var personal, shared []IssueSavedView
+	for _, view := range views {
+		if view.ID == ref {
+			return &view, nil
+		}
+		if !strings.EqualFold(view.Name, ref) {
+			continue
+		}
+		if view.UserID != nil {
+			personal = append(personal, view)
+		} else {
+			shared = append(shared, view)
+		}
+	}
+	switch {
+	case len(personal) > 0:
+		return &personal[0], nil
+	case len(shared) == 1:
+		return &shared[0], nil
+	case len(shared) > 1:
+		return nil, fmt.Errorf("%w: view %q exists in %d projects; pass project_id", ErrConflict, ref, len(shared))
+	}
+	return nil, ErrNotFound
+}
+
+// Delete removes a project view or one of userID's personal views.
+func (s *IssueSavedViewStore) Delete(ctx context.Context, orgID, viewID, userID string) error {
+	if s == nil || s.db == nil {
+		return fmt.Errorf("issue saved view store is not configured")
+	}
+	viewID = strings.TrimSpace(viewID)
+	if !uuidRegex.MatchString(viewID) {
+		return fmt.Errorf("%w: view id must be a UUID", ErrValidation)
+	}
+	conn, err := WithWorkspaceID(ctx, s.db, orgID)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	result, err := conn.ExecContext(
+		ctx,
+		`DELETE FROM issue_saved_views
+		 WHERE org_id = $1
+		   AND id = $2
+		   AND (user_id IS NULL OR user_id::text = NULLIF($3, ''))`,
+		orgID,
+		viewID,
+		strings.TrimSpace(userID),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to delete issue view: %w", err)
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func scanIssueSavedView(scanner interface{ Scan(...any) error }) (IssueSavedView, error) {
+	var view IssueSavedView
+	var userID, projectID, createdBy sql.NullString
+	err := scanner.Scan(
+		&view.ID,
+		&view.OrgID,
+		&userID,
+		&projectID,
+		&view.Name,
+		&view.Query,
+		&createdBy,
+		&view.CreatedAt,
+		&view.UpdatedAt,
+	)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return view, ErrNotFound
+		}
+		return view, err
+	}
+	if userID.Valid {
+		view.UserID = &userID.String
+	}
+	if projectID.Valid {
+		view.ProjectID = &projectID.String
+	}
+	if createdBy.Valid {
+		view.CreatedBy = &createdBy.String
+	}
+	return view, nil
+}
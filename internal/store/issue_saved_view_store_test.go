@@ -0,0 +1,77 @@
+package store
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestIssueSavedViewStore_SaveListResolveDelete(t *testing.T) {
+	connStr := getTestDatabaseURL(t)
+	db := setupTestDatabase(t, connStr)
+	orgID := createTestOrganization(t, db, "issue-saved-view-org")
+	projectID := createTestProject(t, db, orgID, "Issue Saved View Project")
+	userID := insertChatThreadTestUser(t, db, orgID, "saved-view-user")
+	otherUserID := insertChatThreadTestUser(t, db, orgID, "saved-view-other")
+
+	viewStore := NewIssueSavedViewStore(db)
+	ctx := context.Background()
+
+	shared, err := viewStore.Save(ctx, orgID, SaveIssueSavedViewInput{
+		ProjectID: projectID,
+		Name:      "Launch",
+		Query:     "is:open label:launch",
+		CreatedBy: userID,
+	})
+	require.NoError(t, err)
+	require.NotNil(t, shared.ProjectID)
+	require.Nil(t, shared.UserID)
+
+	personal, err := viewStore.Save(ctx, orgID, SaveIssueSavedViewInput{
+		UserID: userID,
+		Name:   "launch",
+		Query:  "is:open owner:@writer-2",
+	})
+	require.NoError(t, err)
+
+	replaced, err := viewStore.Save(ctx, orgID, SaveIssueSavedViewInput{
+		UserID: userID,
+		Name:   "Launch",
+		Query:  "is:open owner:@writer-2 sort:due",
+	})
+	require.NoError(t, err)
+	require.Equal(t, personal.ID, replaced.ID)
+	require.Equal(t, "is:open owner:@writer-2 sort:due", replaced.Query)
+
+	_, err = viewStore.Save(ctx, orgID, SaveIssueSavedViewInput{UserID: userID, Name: "bad", Query: "priority:P9"})
+	require.ErrorIs(t, err, ErrValidation)
+	_, err = viewStore.Save(ctx, orgID, SaveIssueSavedViewInput{UserID: userID, ProjectID: projectID, Name: "both", Query: "is:open"})
+	require.ErrorIs(t, err, ErrValidation)
+
+	views, err := viewStore.List(ctx, orgID, userID, projectID)
+	require.NoError(t, err)
+	require.Len(t, views, 2)
+	require.Equal(t, personal.ID, views[0].ID)
+
+	otherViews, err := viewStore.List(ctx, orgID, otherUserID, "")
+	require.NoError(t, err)
+	require.Len(t, otherViews, 1)
+	require.Equal(t, shared.ID, otherViews[0].ID)
+
+	resolved, err := viewStore.Resolve(ctx, orgID, "LAUNCH", userID, "")
+	require.NoError(t, err)
+	require.Equal(t, personal.ID, resolved.ID)
+	resolved, err = viewStore.Resolve(ctx, orgID, "launch", otherUserID, "")
+	require.NoError(t, err)
+	require.Equal(t, shared.ID, resolved.ID)
+	resolved, err = viewStore.Resolve(ctx, orgID, shared.ID, "", "")
+	require.NoError(t, err)
+	require.Equal(t, shared.ID, resolved.ID)
+
+	require.ErrorIs(t, viewStore.Delete(ctx, orgID, personal.ID, otherUserID), ErrNotFound)
+	require.NoError(t, viewStore.Delete(ctx, orgID, personal.ID, userID))
+	require.NoError(t, viewStore.Delete(ctx, orgID, shared.ID, otherUserID))
+	_, err = viewStore.Resolve(ctx, orgID, "launch", userID, "")
+	require.ErrorIs(t, err, ErrNotFound)
+}
@@ -0,0 +1,619 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+const (
+	IssueFieldTypeText   = "text"
+	IssueFieldTypeSelect = "select"
+	IssueFieldTypeNumber = "number"
+	IssueFieldTypeDate   = "date"
+	IssueFieldTypeURL    = "url"
+
+	maxIssueTemplateNameLength  = 80
+	maxIssueTemplateFields      = 50
+	maxIssueFieldValueLength    = 2000
+	issueFieldDateLayout        = "2006-01-02"
+	issueTemplatePlaceholderFmt = "{{%s}}"
+)
+
+var issueFieldKeyPattern = regexp.MustCompile(`^[a-z][a-z0-9_]{0,39}$`)
+
+// IssueTemplateField defines one typed custom field. Default uses the same
+// string form callers pass when creating an issue.
+type IssueTemplateField struct {
+	Key      string   `json:"key"`
+	Label    string   `json:"label,omitempty"`
+	Type     string   `json:"type"`
+	Required bool     `json:"required,omitempty"`
+	Default  string   `json:"default,omitempty"`
+	Options  []string `json:"options,omitempty"`
+	Help     string   `json:"help,omitempty"`
+}
+
+// IssueTemplate is a per-project issue skeleton: a markdown body with
+// {{field}} placeholders, custom field definitions and creation defaults.
+type IssueTemplate struct {
+	ID                    string               `json:"id"`
+	OrgID                 string               `json:"org_id"`
+	ProjectID             string               `json:"project_id"`
+	Name                  string               `json:"name"`
+	Description           *string              `json:"description,omitempty"`
+	Body                  string               `json:"body"`
+	Fields                []IssueTemplateField `json:"fields"`
+	DefaultFlowTemplateID *string              `json:"default_flow_template_id,omitempty"`
+	DefaultLabels         []string             `json:"default_labels"`
+	CreatedAt             time.Time            `json:"created_at"`
+	UpdatedAt             time.Time            `json:"updated_at"`
+}
+
+// SaveIssueTemplateInput creates or replaces a template by name within its
+// project.
+type SaveIssueTemplateInput struct {
+	ProjectID             string
+	Name                  string
+	Description           *string
+	Body                  string
+	Fields                []IssueTemplateField
+	DefaultFlowTemplateID *string
+	DefaultLabels         []string
+}
+
+// IssueFieldValue is a custom field value stored on an issue. Value is the
+// canonical string form for every type.
+type IssueFieldValue struct {
+	Key        string  `json:"key"`
+	Type       string  `json:"type"`
+	Value      string  `json:"value"`
+	TemplateID *string `json:"template_id,omitempty"`
+
+	number *float64
+	date   *time.Time
+}
+
+type IssueTemplateStore struct {
+	db *sql.DB
+}
+
+func NewIssueTemplateStore(db *sql.DB) *IssueTemplateStore {
+	return &IssueTemplateStore{db: db}
+}
+
+const issueTemplateColumns = `id, org_id, project_id, name, description, body, fields,
+	default_flow_template_id, default_labels, created_at, updated_at`
+
+// Save validates input and upserts the template on (project, name).
+func (s *IssueTemplateStore) Save(ctx context.Context, orgID string, input SaveIssueTemplateInput) (*IssueTemplate, error) {
+	if s == nil || s.db == nil {
+		return nil, fmt.Errorf("issue template store is not configured")
+	}
+	projectID := strings.TrimSpace(input.ProjectID)
+	name := strings.TrimSpace(input.Name)
+	if !uuidRegex.MatchString(projectID) {
+		return nil, fmt.Errorf("%w: project_id must be a UUID", ErrValidation)
+	}
+	if name == "" {
+		return nil, fmt.Errorf("%w: name is required", ErrValidation)
+	}
+	if len(name) > maxIssueTemplateNameLength {
+		return nil, fmt.Errorf("%w: name must be at most %d characters", ErrValidation, maxIssueTemplateNameLength)
+	}
+	if uuidRegex.MatchString(name) {
+		return nil, fmt.Errorf("%w: name cannot be a UUID", ErrValidation)
+	}
+	fields, err := NormalizeIssueTemplateFields(input.Fields)
+	if err != nil {
+		return nil, err
+	}
+	fieldsJSON, err := json.Marshal(fields)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode template fields: %w", err)
+	}
+	labels := normalizeIssueTemplateLabels(input.DefaultLabels)
+	var flowTemplateID any
+	if input.DefaultFlowTemplateID != nil {
+		if trimmed := strings.TrimSpace(*input.DefaultFlowTemplateID); trimmed != "" {
+			if !uuidRegex.MatchString(trimmed) {
+				return nil, fmt.Errorf("%w: default_flow_template_id must be a UUID", ErrValidation)
+			}
+			flowTemplateID = trimmed
+		}
+	}
+	var description any
+	if input.Description != nil {
+		if trimmed := strings.TrimSpace(*input.Description); trimmed != "" {
+			description = trimmed
+		}
+	}
+
+	conn, err := WithWorkspaceID(ctx, s.db, orgID)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	var exists bool
+	if err := conn.QueryRowContext(
+		ctx,
+		`SELECT EXISTS(SELECT 1 FROM projects WHERE org_id = $1 AND id = $2)`,
+		orgID,
+		projectID,
+	).Scan(&exists); err != nil {
+		return nil, fmt.Errorf("failed to check project: %w", err)
+	}
+	if !exists {
+		return nil, ErrNotFound
+	}
+	if flowTemplateID != nil {
+		if err := conn.QueryRowContext(
+			ctx,
+			`SELECT EXISTS(SELECT 1 FROM project_flow_templates WHERE org_id = $1 AND id = $2 AND project_id = $3)`,
+			orgID,
+			flowTemplateID,
+			projectID,
+		).Scan(&exists); err != nil {
+			return nil, fmt.Errorf("failed to check flow template: %w", err)
+		}
+		if !exists {
+			return nil, fmt.Errorf("%w: default flow template does not belong to this project", ErrValidation)
+		}
+	}
+
+	template, err := scanIssueTemplate(conn.QueryRowContext(
+		ctx,
+		`INSERT INTO issue_templates (
+			org_id, project_id, name, description, body, fields, default_flow_template_id, default_labels
+		 ) VALUES ($1, $2, $3, $4, $5, $6::jsonb, $7, $8)
+		 ON CONFLICT (project_id, lower(name))
+		 DO UPDATE SET
+			name = EXCLUDED.name,
+			description = EXCLUDED.description,
+			body = EXCLUDED.body,
+			fields = EXCLUDED.fields,
+			default_flow_template_id = EXCLUDED.default_flow_template_id,
+			default_labels = EXCLUDED.default_labels,
+			updated_at = NOW()
+		 RETURNING `+issueTemplateColumns,
+		orgID, projectID, name, description, input.Body, string(fieldsJSON), flowTemplateID, pq.Array(labels),
+	))
+	if err != nil {
+		return nil, fmt.Errorf("failed to save issue template: %w", err)
+	}
+	return &template, nil
+}
+
+// List returns templates ordered by name, for projectID only when it is set.
+func (s *IssueTemplateStore) List(ctx context.Context, orgID, projectID string) ([]IssueTemplate, error) {
+	if s == nil || s.db == nil {
+		return nil, fmt.Errorf("issue template store is not configured")
+	}
+	projectID = strings.TrimSpace(projectID)
+	if projectID != "" && !uuidRegex.MatchString(projectID) {
+		return nil, fmt.Errorf("%w: project_id must be a UUID", ErrValidation)
+	}
+	conn, err := WithWorkspaceID(ctx, s.db, orgID)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	rows, err := conn.QueryContext(
+		ctx,
+		`SELECT `+issueTemplateColumns+`
+		 FROM issue_templates
+		 WHERE org_id = $1
+		   AND (NULLIF($2, '') IS NULL OR project_id::text = $2)
+		 ORDER BY lower(name), project_id`,
+		orgID,
+		projectID,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list issue templates: %w", err)
+	}
+	defer rows.Close()
+
+	templates := make([]IssueTemplate, 0)
+	for rows.Next() {
+		template, err := scanIssueTemplate(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan issue template: %w", err)
+		}
+		templates = append(templates, template)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read issue templates: %w", err)
+	}
+	return templates, nil
+}
+
+// Resolve finds a template in projectID by id or case-insensitive name.
+func (s *IssueTemplateStore) Resolve(ctx context.Context, orgID, projectID, ref string) (*IssueTemplate, error) {
+	ref = strings.TrimSpace(ref)
+	if ref == "" {
+		return nil, fmt.Errorf("%w: template is required", ErrValidation)
+	}
+	if strings.TrimSpace(projectID) == "" {
+		return nil, fmt.Errorf("%w: project_id is required", ErrValidation)
+	}
+	templates, err := s.List(ctx, orgID, projectID)
+	if err != nil {
+		return nil, err
+	}
+	for _, template := range templates {
+		if template.ID == ref || strings.EqualFold(template.Name, ref) {
+			return &template, nil
+		}
+	}
+	return nil, ErrNotFound
+}
+
+// Delete removes a template. Issues created from it keep their field values.
+func (s *IssueTemplateStore) Delete(ctx context.Context, orgID, templateID string) error {
+	if s == nil || s.db == nil {
+		return fmt.Errorf("issue template store is not configured")
+	}
+	templateID = strings.TrimSpace(templateID)
+	if !uuidRegex.MatchString(templateID) {
+		return fmt.Errorf("%w: template id must be a UUID", ErrValidation)
+	}
+	conn, err := WithWorkspaceID(ctx, s.db, orgID)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	result, err := conn.ExecContext(
+		ctx,
+		`DELETE FROM issue_templates WHERE org_id = $1 AND id = $2`,
+		orgID,
+		templateID,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to delete issue template: %w", err)
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// SetFieldValues upserts values on an issue. Values should come from
+// IssueTemplate.ResolveFieldValues so types are already checked.
+func (s *IssueTemplateStore) SetFieldValues(ctx context.Context, orgID, issueID, templateID string, values []IssueFieldValue) error {
+	if s == nil || s.db == nil {
+		return fmt.Errorf("issue template store is not configured")
+	}
+	issueID = strings.TrimSpace(issueID)
+	if !uuidRegex.MatchString(issueID) {
+		return fmt.Errorf("%w: issue id must be a UUID", ErrValidation)
+	}
+	if len(values) == 0 {
+		return nil
+	}
+	var template any
+	if trimmed := strings.TrimSpace(templateID); trimmed != "" {
+		template = trimmed
+	}
+
+	tx, err := WithWorkspaceIDTx(ctx, s.db, orgID)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	for _, value := range values {
+		if _, err := tx.ExecContext(
+			ctx,
+			`INSERT INTO issue_field_values (
+				org_id, issue_id, template_id, field_key, field_type, value_text, value_number, value_date
+			 ) VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+			 ON CONFLICT (issue_id, field_key)
+			 DO UPDATE SET
+				template_id = EXCLUDED.template_id,
+				field_type = EXCLUDED.field_type,
+				value_text = EXCLUDED.value_text,
+				value_number = EXCLUDED.value_number,
+				value_date = EXCLUDED.value_date,
+				updated_at = NOW()`,
+			orgID, issueID, template, value.Key, value.Type, value.Value, value.number, value.date,
+		); err != nil {
+			return fmt.Errorf("failed to save issue field %s: %w", value.Key, err)
+		}
+	}
+	return tx.Commit()
+}
+
+// ListFieldValues returns an issue's custom field values ordered by key.
+func (s *IssueTemplateStore) ListFieldValues(ctx context.Context, orgID, issueID string) ([]IssueFieldValue, error) {
+	if s == nil || s.db == nil {
+		return nil, fmt.Errorf("issue template store is not configured")
+	}
+	issueID = strings.TrimSpace(issueID)
+	if !uuidRegex.MatchString(issueID) {
+		return nil, fmt.Errorf("%w: issue id must be a UUID", ErrValidation)
+	}
+	conn, err := WithWorkspaceID(ctx, s.db, orgID)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	rows, err := conn.QueryContext(
+		ctx,
+		`SELECT field_key, field_type, value_text, template_id
+		 FROM issue_field_values
+		 WHERE org_id = $1 AND issue_id = $2
+		 ORDER BY field_key`,
+		orgID,
+		issueID,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list issue fields: %w", err)
+	}
+	defer rows.Close()
+
+	values := make([]IssueFieldValue, 0)
+	for rows.Next() {
+		var value IssueFieldValue
+		var templateID sql.NullString
+		if err := rows.Scan(&value.Key, &value.Type, &value.Value, &templateID); err != nil {
+			return nil, fmt.Errorf("failed to scan issue field: %w", err)
+		}
+		if templateID.Valid {
+			value.TemplateID = &templateID.String
+		}
+		values = append(values, value)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read issue fields: %w", err)
+	}
+	return values, nil
+}
+
+// NormalizeIssueTemplateFields validates field definitions: unique keys,
+// known types, options for select fields and defaults that fit their type.
+func NormalizeIssueTemplateFields(fields []IssueTemplateField) ([]IssueTemplateField, error) {
+	if len(fields) > maxIssueTemplateFields {
+		return nil, fmt.Errorf("%w: a template can define at most %d fields", ErrValidation, maxIssueTemplateFields)
+	}
+	normalized := make([]IssueTemplateField, 0, len(fields))
+	seen := make(map[string]struct{}, len(fields))
+	for _, field := range fields {
+		field.Key = strings.ToLower(strings.TrimSpace(field.Key))
+		field.Label = strings.TrimSpace(field.Label)
+		field.Type = strings.ToLower(strings.TrimSpace(field.Type))
+		field.Default = strings.TrimSpace(field.Default)
+		field.Help = strings.TrimSpace(field.Help)
+		if !issueFieldKeyPattern.MatchString(field.Key) {
+			return nil, fmt.Errorf("%w: field key %q must be lowercase letters, digits and underscores", ErrValidation, field.Key)
+		}
+		if _, dup := seen[field.Key]; dup {
+			return nil, fmt.Errorf("%w: duplicate field key %q", ErrValidation, field.Key)
+		}
+		seen[field.Key] = struct{}{}
+		if field.Type == "" {
+			field.Type = IssueFieldTypeText
+		}
+		if !isIssueFieldType(field.Type) {
+			return nil, fmt.Errorf("%w: field %s has unsupported type %q", ErrValidation, field.Key, field.Type)
+		}
+		options := make([]string, 0, len(field.Options))
+		seenOptions := make(map[string]struct{}, len(field.Options))
+		for _, option := range field.Options {
+			option = strings.TrimSpace(option)
+			if option == "" {
+				continue
+			}
+			if _, dup := seenOptions[strings.ToLower(option)]; dup {
+				continue
+			}
+			seenOptions[strings.ToLower(option)] = struct{}{}
+			options = append(options, option)
+		}
+		if field.Type == IssueFieldTypeSelect {
+			if len(options) == 0 {
+				return nil, fmt.Errorf("%w: select field %s needs options", ErrValidation, field.Key)
+			}
+			field.Options = options
+		} else {
+			field.Options = nil
+		}
+		if field.Default != "" {
+			value, err := field.parseValue(field.Default)
+			if err != nil {
+				return nil, err
+			}
+			field.Default = value.Value
+		}
+		normalized = append(normalized, field)
+	}
+	return normalized, nil
+}
+
+// ResolveFieldValues checks provided values against the template: unknown
+// keys are rejected, defaults fill missing values and required fields must
+// end up set. The result is ordered like the template's fields.
+func (t IssueTemplate) ResolveFieldValues(provided map[string]string) ([]IssueFieldValue, error) {
+	known := make(map[string]struct{}, len(t.Fields))
+	for _, field := range t.Fields {
+		known[field.Key] = struct{}{}
+	}
+	normalizedInput := make(map[string]string, len(provided))
+	unknown := make([]string, 0)
+	for key, value := range provided {
+		key = strings.ToLower(strings.TrimSpace(key))
+		if _, ok := known[key]; !ok {
+			unknown = append(unknown, key)
+			continue
+		}
+		normalizedInput[key] = strings.TrimSpace(value)
+	}
+	if len(unknown) > 0 {
+		sort.Strings(unknown)
+		return nil, fmt.Errorf("%w: unknown field(s) for template %s: %s", ErrValidation, t.Name, strings.Join(unknown, ", "))
+	}
+
+	values := make([]IssueFieldValue, 0, len(t.Fields))
+	missing := make([]string, 0)
+	for _, field := range t.Fields {
+		raw := normalizedInput[field.Key]
+		if raw == "" {
+			raw = field.Default
+		}
+		if raw == "" {
+			if field.Required {
+				missing = append(missing, field.Key)
+			}
+			continue
+		}
+		value, err := field.parseValue(raw)
+		if err != nil {
+			return nil, err
+		}
+		if t.ID != "" {
+			templateID := t.ID
+			value.TemplateID = &templateID
+		}
+		values = append(values, value)
+	}
+	if len(missing) > 0 {
+		return nil, fmt.Errorf("%w: missing required field(s): %s", ErrValidation, strings.Join(missing, ", "))
+	}
+	return values, nil
+}
+
+// RenderBody fills {{key}} placeholders in the template body. Placeholders
+// for fields without a value render empty; other text is left alone.
+func (t IssueTemplate) RenderBody(values []IssueFieldValue) string {
+	byKey := make(map[string]string, len(values))
+	for _, value := range values {
+		byKey[value.Key] = value.Value
+	}
+	pairs := make([]string, 0, len(t.Fields)*2)
+	for _, field := range t.Fields {
+		pairs = append(pairs, fmt.Sprintf(issueTemplatePlaceholderFmt, field.Key), byKey[field.Key])
+	}
+	if len(pairs) == 0 {
+		return t.Body
+	}
+	return strings.NewReplacer(pairs...).Replace(t.Body)
+}
+
+func (f IssueTemplateField) parseValue(raw string) (IssueFieldValue, error) {
+	raw = strings.TrimSpace(raw)
+	value := IssueFieldValue{Key: f.Key, Type: f.Type, Value: raw}
+	if len(raw) > maxIssueFieldValueLength {
+		return value, fmt.Errorf("%w: field %s must be at most %d characters", ErrValidation, f.Key, maxIssueFieldValueLength)
+	}
+	switch f.Type {
+	case IssueFieldTypeSelect:
+		for _, option := range f.Options {
+			if strings.EqualFold(option, raw) {
+				value.Value = option
+				return value, nil
+			}
+		}
+		return value, fmt.Errorf("%w: field %s must be one of: %s", ErrValidation, f.Key, strings.Join(f.Options, ", "))
+	case IssueFieldTypeNumber:
+		number, err := strconv.ParseFloat(raw, 64)
+		if err != nil {
+			return value, fmt.Errorf("%w: field %s must be a number", ErrValidation, f.Key)
+		}
+		value.Value = strconv.FormatFloat(number, 'f', -1, 64)
+		value.number = &number
+	case IssueFieldTypeDate:
+		date, err := time.Parse(issueFieldDateLayout, raw)
+		if err != nil {
+			return value, fmt.Errorf("%w: field %s must be a date (YYYY-MM-DD)", ErrValidation, f.Key)
+		}
+		value.date = &date
+	case IssueFieldTypeURL:
+		parsed, err := url.Parse(raw)
+		if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+			return value, fmt.Errorf("%w: field %s must be an http(s) URL", ErrValidation, f.Key)
+		}
+	}
+	return value, nil
+}
+
+func isIssueFieldType(fieldType string) bool {
+	switch fieldType {
+	case IssueFieldTypeText, IssueFieldTypeSelect, IssueFieldTypeNumber, IssueFieldTypeDate, IssueFieldTypeURL:
+		return true
+	}
+	return false
+}
+
+func normalizeIssueTemplateLabels(labels []string) []string {
+	normalized := make([]string, 0, len(labels))
+	seen := make(map[string]struct{}, len(labels))
+	for _, label := range labels {
+		label = strings.TrimSpace(label)
+		if label == "" {
+			continue
+		}
+		if _, dup := seen[strings.ToLower(label)]; dup {
+			continue
+		}
+		seen[strings.ToLower(label)] = struct{}{}
+		normalized = append(normalized, label)
+	}
+	return normalized
+}
+
+func scanIssueTemplate(scanner interface{ Scan(...any) error }) (IssueTemplate, error) {
+	var template IssueTemplate
+	var description, flowTemplateID sql.NullString
+	var fieldsJSON []byte
+	var labels pq.StringArray
+	err := scanner.Scan(
+		&template.ID,
+		&template.OrgID,
+		&template.ProjectID,
+		&template.Name,
+		&description,
+		&template.Body,
+		&fieldsJSON,
+		&flowTemplateID,
+		&labels,
+		&template.CreatedAt,
+		&template.UpdatedAt,
+	)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return template, ErrNotFound
+		}
+		return template, err
+	}
+	if description.Valid {
+		template.Description = &description.String
+	}
+	if flowTemplateID.Valid {
+		template.DefaultFlowTemplateID = &flowTemplateID.String
+	}
+	template.Fields = make([]IssueTemplateField, 0)
+	if len(fieldsJSON) > 0 {
+		if err := json.Unmarshal(fieldsJSON, &template.Fields); err != nil {
+			return template, fmt.Errorf("failed to decode template fields: %w", err)
+		}
+	}
+	template.DefaultLabels = append([]string{}, labels...)
+	return template, nil
+}
@@ -0,0 +1,136 @@
+package store
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestNormalizeIssueTemplateFields(t *testing.T) {
+	fields, err := NormalizeIssueTemplateFields([]IssueTemplateField{
+		{Key: " Severity ", Type: "SELECT", Options: []string{"Low", "High", "high", ""}, Default: "low"},
+		{Key: "estimate", Type: "number", Default: "3.50"},
+		{Key: "notes"},
+	})
+	require.NoError(t, err)
+	require.Equal(t, "severity", fields[0].Key)
+	require.Equal(t, []string{"Low", "High"}, fields[0].Options)
+	require.Equal(t, "Low", fields[0].Default)
+	require.Equal(t, "3.5", fields[1].Default)
+	require.Equal(t, IssueFieldTypeText, fields[2].Type)
+	require.Nil(t, fields[2].Options)
+
+	for name, bad := range map[string][]IssueTemplateField{
+		"key":       {{Key: "1st"}},
+		"duplicate": {{Key: "a"}, {Key: "A"}},
+		"type":      {{Key: "a", Type: "checkbox"}},
+		"options":   {{Key: "a", Type: "select"}},
+		"default":   {{Key: "a", Type: "date", Default: "next week"}},
+	} {
+		_, err := NormalizeIssueTemplateFields(bad)
+		require.ErrorIs(t, err, ErrValidation, name)
+	}
+}
+
+func TestIssueTemplateResolveFieldValuesAndRenderBody(t *testing.T) {
+	fields, err := NormalizeIssueTemplateFields([]IssueTemplateField{
+		{Key: "severity", Type: "select", Options: []string{"Low", "High"}, Required: true},
+		{Key: "estimate", Type: "number", Default: "2"},
+		{Key: "launch", Type: "date"},
+		{Key: "spec", Type: "url"},
+	})
+	require.NoError(t, err)
+	template := IssueTemplate{
+		ID:     "tmpl-1",
+		Name:   "Bug",
+		Body:   "Severity: {{severity}}\nEstimate: {{estimate}}\nSpec: {{spec}}\n{{unknown}}",
+		Fields: fields,
+	}
+
+	values, err := template.ResolveFieldValues(map[string]string{"severity": "high", "launch": "2026-11-01"})
+	require.NoError(t, err)
+	require.Len(t, values, 3)
+	require.Equal(t, "High", values[0].Value)
+	require.Equal(t, "2", values[1].Value)
+	require.NotNil(t, values[1].number)
+	require.NotNil(t, values[2].date)
+	require.Equal(t, "tmpl-1", *values[0].TemplateID)
+	require.Equal(t, "Severity: High\nEstimate: 2\nSpec: \n{{unknown}}", template.RenderBody(values))
+
+	for name, input := range map[string]map[string]string{
+		"missing":  {},
+		"unknown":  {"severity": "Low", "owner": "sam"},
+		"option":   {"severity": "Medium"},
+		"number":   {"severity": "Low", "estimate": "lots"},
+		"date":     {"severity": "Low", "launch": "11/01/2026"},
+		"url":      {"severity": "Low", "spec": "ftp://example.com/spec"},
+		"relative": {"severity": "Low", "spec": "/docs/spec"},
+	} {
+		_, err := template.ResolveFieldValues(input)
+		require.ErrorIs(t, err, ErrValidation, name)
+	}
+}
+
+func TestIssueTemplateStoreSaveResolveAndFieldValues(t *testing.T) {
+	connStr := getTestDatabaseURL(t)
+	db := setupTestDatabase(t, connStr)
+	orgID := createTestOrganization(t, db, "issue-template-org")
+	projectID := createTestProject(t, db, orgID, "Issue Template Project")
+	otherProjectID := createTestProject(t, db, orgID, "Issue Template Other")
+	ctx := ctxWithWorkspace(orgID)
+
+	templates := NewIssueTemplateStore(db)
+	description := "Something is broken"
+	saved, err := templates.Save(ctx, orgID, SaveIssueTemplateInput{
+		ProjectID:     projectID,
+		Name:          "Bug",
+		Description:   &description,
+		Body:          "## Steps\n\nSeverity: {{severity}}",
+		Fields:        []IssueTemplateField{{Key: "severity", Type: "select", Options: []string{"Low", "High"}, Required: true}},
+		DefaultLabels: []string{"bug", "Bug", "triage"},
+	})
+	require.NoError(t, err)
+	require.Equal(t, []string{"bug", "triage"}, saved.DefaultLabels)
+	require.Len(t, saved.Fields, 1)
+
+	replaced, err := templates.Save(ctx, orgID, SaveIssueTemplateInput{ProjectID: projectID, Name: "bug", Body: "v2"})
+	require.NoError(t, err)
+	require.Equal(t, saved.ID, replaced.ID)
+	require.Equal(t, "bug", replaced.Name)
+	require.Empty(t, replaced.Fields)
+
+	resolved, err := templates.Resolve(ctx, orgID, projectID, "BUG")
+	require.NoError(t, err)
+	require.Equal(t, saved.ID, resolved.ID)
+	_, err = templates.Resolve(ctx, orgID, otherProjectID, "bug")
+	require.ErrorIs(t, err, ErrNotFound)
+
+	missingFlow := "00000000-0000-0000-0000-00000000f10e"
+	_, err = templates.Save(ctx, orgID, SaveIssueTemplateInput{ProjectID: projectID, Name: "Flow", DefaultFlowTemplateID: &missingFlow})
+	require.ErrorIs(t, err, ErrValidation)
+
+	issue, err := NewProjectIssueStore(db).CreateIssue(ctx, CreateProjectIssueInput{ProjectID: projectID, Title: "Broken", Origin: "local"})
+	require.NoError(t, err)
+	withFields := IssueTemplate{ID: saved.ID, Name: "Bug", Fields: saved.Fields}
+	values, err := withFields.ResolveFieldValues(map[string]string{"severity": "high"})
+	require.NoError(t, err)
+	require.NoError(t, templates.SetFieldValues(ctx, orgID, issue.ID, saved.ID, values))
+
+	stored, err := templates.ListFieldValues(ctx, orgID, issue.ID)
+	require.NoError(t, err)
+	require.Len(t, stored, 1)
+	require.Equal(t, "High", stored[0].Value)
+
+	query, err := ParseIssueQuery("field.severity:high")
+	require.NoError(t, err)
+	page, err := NewProjectIssueStore(db).QueryIssues(ctx, IssueQueryInput{ProjectID: projectID, Query: query})
+	require.NoError(t, err)
+	require.Len(t, page.Items, 1)
+
+	require.NoError(t, templates.Delete(ctx, orgID, saved.ID))
+	stored, err = templates.ListFieldValues(ctx, orgID, issue.ID)
+	require.NoError(t, err)
+	require.Len(t, stored, 1)
+	require.Nil(t, stored[0].TemplateID)
+	require.ErrorIs(t, templates.Delete(ctx, orgID, saved.ID), ErrNotFound)
+}
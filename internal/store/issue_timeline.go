@@ -0,0 +1,403 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/lib/pq"
+	"github.com/samhotchkiss/otter-camp/internal/middleware"
+)
+
+// Issue timeline event types.
+const (
+	IssueTimelineIssueCreated         = "issue.created"
+	IssueTimelineIssueClosed          = "issue.closed"
+	IssueTimelineComment              = "comment"
+	IssueTimelineParticipantJoined    = "participant.joined"
+	IssueTimelineParticipantRemoved   = "participant.removed"
+	IssueTimelineLabelAdded           = "label.added"
+	IssueTimelineReviewSaved          = "review.saved"
+	IssueTimelineReviewAddressed      = "review.addressed"
+	IssueTimelineReviewThreadOpened   = "review.thread_opened"
+	IssueTimelineReviewThreadResolved = "review.thread_resolved"
+	IssueTimelinePipelineStep         = "pipeline.step"
+	IssueTimelineBlockerRaised        = "blocker.raised"
+	IssueTimelineBlockerEscalated     = "blocker.escalated"
+	IssueTimelineBlockerResolved      = "blocker.resolved"
+	IssueTimelineGitHubSynced         = "github.synced"
+	IssueTimelineCommit               = "commit"
+	IssueTimelineAgentActivity        = "agent.activity"
+	IssueTimelineBulkUpdate           = "issue.bulk_update"
+	IssueTimelineAudit                = "audit"
+)
+
+// Issue timeline actor types.
+const (
+	IssueTimelineActorAgent  = "agent"
+	IssueTimelineActorUser   = "user"
+	IssueTimelineActorSystem = "system"
+)
+
+const issueTimelineMaxLimit = 200
+
+var issueTimelineTypes = map[string]bool{
+	IssueTimelineIssueCreated:         true,
+	IssueTimelineIssueClosed:          true,
+	IssueTimelineComment:              true,
+	IssueTimelineParticipantJoined:    true,
+	IssueTimelineParticipantRemoved:   true,
+	IssueTimelineLabelAdded:           true,
+	IssueTimelineReviewSaved:          true,
+	IssueTimelineReviewAddressed:      true,
+	IssueTimelineReviewThreadOpened:   true,
+	IssueTimelineReviewThreadResolved: true,
+	IssueTimelinePipelineStep:         true,
+	IssueTimelineBlockerRaised:        true,
+	IssueTimelineBlockerEscalated:     true,
+	IssueTimelineBlockerResolved:      true,
+	IssueTimelineGitHubSynced:         true,
+	IssueTimelineCommit:               true,
+	IssueTimelineAgentActivity:        true,
+	IssueTimelineBulkUpdate:           true,
+	IssueTimelineAudit:                true,
+}
+
+// IssueTimelineActor is who caused a timeline event. ID is empty for system
+// events with no known origin.
+type IssueTimelineActor struct {
+	Type string `json:"type"`
+	ID   string `json:"id,omitempty"`
+	Name string `json:"name,omitempty"`
+}
+
+// IssueTimelineEvent is one entry in an issue's merged history. Detail holds
+// the source-specific fields for the event type.
+type IssueTimelineEvent struct {
+	ID         string             `json:"id"`
+	Type       string             `json:"type"`
+	OccurredAt time.Time          `json:"occurred_at"`
+	Actor      IssueTimelineActor `json:"actor"`
+	Summary    string             `json:"summary"`
+	Detail     json.RawMessage    `json:"detail,omitempty"`
+}
+
+// IssueTimelineCursor marks the last event of a page.
+type IssueTimelineCursor struct {
+	OccurredAt time.Time
+	ID         string
+}
+
+func EncodeIssueTimelineCursor(cursor IssueTimelineCursor) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(cursor.OccurredAt.UTC().Format(time.RFC3339Nano) + "|" + cursor.ID))
+}
+
+func DecodeIssueTimelineCursor(raw string) (*IssueTimelineCursor, error) {
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return nil, nil
+	}
+	decoded, err := base64.RawURLEncoding.DecodeString(raw)
+	if err != nil {
+		return nil, fmt.Errorf("%w: invalid cursor", ErrValidation)
+	}
+	occurredAt, id, ok := strings.Cut(string(decoded), "|")
+	if !ok || id == "" {
+		return nil, fmt.Errorf("%w: invalid cursor", ErrValidation)
+	}
+	parsed, err := time.Parse(time.RFC3339Nano, occurredAt)
+	if err != nil {
+		return nil, fmt.Errorf("%w: invalid cursor", ErrValidation)
+	}
+	return &IssueTimelineCursor{OccurredAt: parsed, ID: id}, nil
+}
+
+// ParseIssueTimelineTypes splits a comma-separated type filter and rejects
+// unknown types.
+func ParseIssueTimelineTypes(raw string) ([]string, error) {
+	types := make([]string, 0)
+	for _, part := range strings.Split(raw, ",") {
+		value := strings.ToLower(strings.TrimSpace(part))
+		if value == "" {
+			continue
+		}
+		if !issueTimelineTypes[value] {
+			return nil, fmt.Errorf("%w: unknown timeline type %q", ErrValidation, value)
+		}
+		types = append(types, value)
+	}
+	return types, nil
+}
+
+type IssueTimelineInput struct {
+	IssueID string
+	Types   []string
+	Cursor  *IssueTimelineCursor
+	Limit   int
+}
+
+type IssueTimelinePage struct {
+	Items      []IssueTimelineEvent `json:"items"`
+	NextCursor string               `json:"next_cursor,omitempty"`
+}
+
+// issueTimelineEventsSQL selects every event source for the issue in $1 as
+// (type, id, occurred_at, actor_type, actor_id, summary, detail). Event IDs
+// are prefixed by source so they stay unique across the union and give a
+// stable tiebreak for events at the same instant.
+const issueTimelineEventsSQL = `
+	WITH issue AS (
+		SELECT id, project_id, issue_number, origin, document_path, merged_into_issue_id, created_at, closed_at
+		FROM project_issues
+		WHERE id = $1::uuid
+	)
+	SELECT 'issue.created', 'issue:' || i.id::text || ':created', i.created_at, 'system', '',
+		CASE WHEN i.origin = 'github' THEN 'Imported from GitHub' ELSE 'Issue created' END,
+		jsonb_build_object('origin', i.origin)
+	FROM issue i
+	UNION ALL
+	SELECT 'issue.closed', 'issue:' || i.id::text || ':closed', i.closed_at, 'system', '',
+		CASE WHEN i.merged_into_issue_id IS NOT NULL THEN 'Closed as duplicate' ELSE 'Issue closed' END,
+		jsonb_strip_nulls(jsonb_build_object('merged_into_issue_id', i.merged_into_issue_id))
+	FROM issue i
+	WHERE i.closed_at IS NOT NULL
+	UNION ALL
+	SELECT 'comment', 'comment:' || c.id::text, c.created_at, 'agent', c.author_agent_id::text,
+		left(c.body, 200),
+		jsonb_build_object('comment_id', c.id, 'body', c.body)
+	FROM project_issue_comments c
+	WHERE c.issue_id = $1
+	UNION ALL
+	SELECT 'participant.joined', 'participant:' || p.id::text || ':joined', p.joined_at, 'agent', p.agent_id::text,
+		'Joined as ' || p.role,
+		jsonb_build_object('role', p.role)
+	FROM project_issue_participants p
+	WHERE p.issue_id = $1
+	UNION ALL
+	SELECT 'participant.removed', 'participant:' || p.id::text || ':removed', p.removed_at, 'agent', p.agent_id::text,
+		'Left (' || p.role || ')',
+		jsonb_build_object('role', p.role)
+	FROM project_issue_participants p
+	WHERE p.issue_id = $1 AND p.removed_at IS NOT NULL
+	UNION ALL
+	SELECT 'label.added', 'label:' || l.id::text, il.created_at, 'system', '',
+		'Labeled ' || l.name,
+		jsonb_build_object('label_id', l.id, 'name', l.name, 'color', l.color)
+	FROM issue_labels il
+	JOIN labels l ON l.id = il.label_id
+	WHERE il.issue_id = $1
+	UNION ALL
+	SELECT 'review.saved', 'review:' || v.id::text || ':saved', v.created_at,
+		CASE WHEN v.reviewer_agent_id IS NULL THEN 'system' ELSE 'agent' END, COALESCE(v.reviewer_agent_id::text, ''),
+		'Review saved at ' || left(v.review_commit_sha, 7),
+		jsonb_build_object('document_path', v.document_path, 'review_commit_sha', v.review_commit_sha)
+	FROM project_issue_review_versions v
+	WHERE v.issue_id = $1
+	UNION ALL
+	SELECT 'review.addressed', 'review:' || v.id::text || ':addressed', v.addressed_at, 'system', '',
+		'Review addressed in ' || left(COALESCE(v.addressed_in_commit_sha, ''), 7),
+		jsonb_strip_nulls(jsonb_build_object('review_commit_sha', v.review_commit_sha, 'addressed_in_commit_sha', v.addressed_in_commit_sha))
+	FROM project_issue_review_versions v
+	WHERE v.issue_id = $1 AND v.addressed_at IS NOT NULL
+	UNION ALL
+	SELECT 'review.thread_opened', 'review_thread:' || t.id::text || ':opened', t.created_at,
+		CASE WHEN t.author_agent_id IS NULL THEN 'system' ELSE 'agent' END, COALESCE(t.author_agent_id::text, ''),
+		'Review thread on lines ' || t.start_line || '-' || t.end_line,
+		jsonb_build_object('thread_id', t.id, 'commit_sha', t.commit_sha, 'start_line', t.start_line, 'end_line', t.end_line)
+	FROM project_issue_review_threads t
+	WHERE t.issue_id = $1
+	UNION ALL
+	SELECT 'review.thread_resolved', 'review_thread:' || t.id::text || ':resolved', t.resolved_at,
+		CASE WHEN t.resolved_by_agent_id IS NULL THEN 'system' ELSE 'agent' END, COALESCE(t.resolved_by_agent_id::text, ''),
+		COALESCE(t.resolution_note, 'Review thread resolved'),
+		jsonb_build_object('thread_id', t.id)
+	FROM project_issue_review_threads t
+	WHERE t.issue_id = $1 AND t.resolved_at IS NOT NULL
+	UNION ALL
+	SELECT 'pipeline.step', 'pipeline:' || h.id::text, COALESCE(h.completed_at, h.started_at),
+		CASE WHEN h.agent_id IS NULL THEN 'system' ELSE 'agent' END, COALESCE(h.agent_id::text, ''),
+		s.name || ' ' || h.result,
+		jsonb_build_object('step_id', s.id, 'step_number', s.step_number, 'step_name', s.name, 'result', h.result, 'notes', h.notes, 'started_at', h.started_at)
+	FROM issue_pipeline_history h
+	JOIN pipeline_steps s ON s.id = h.step_id
+	WHERE h.issue_id = $1
+	UNION ALL
+	SELECT 'blocker.raised', 'blocker:' || b.id::text || ':raised', b.raised_at,
+		CASE WHEN b.raised_by_agent_id IS NULL THEN 'system' ELSE 'agent' END, COALESCE(b.raised_by_agent_id::text, ''),
+		b.summary,
+		jsonb_strip_nulls(jsonb_build_object('blocker_id', b.id, 'detail', b.detail, 'escalation_level', b.escalation_level))
+	FROM project_issue_flow_blockers b
+	WHERE b.issue_id = $1
+	UNION ALL
+	SELECT 'blocker.escalated', 'blocker:' || b.id::text || ':escalated', b.escalated_to_human_at, 'system', '',
+		'Escalated to a human: ' || b.summary,
+		jsonb_build_object('blocker_id', b.id)
+	FROM project_issue_flow_blockers b
+	WHERE b.issue_id = $1 AND b.escalated_to_human_at IS NOT NULL
+	UNION ALL
+	SELECT 'blocker.resolved', 'blocker:' || b.id::text || ':resolved', b.resolved_at, 'system', '',
+		CASE WHEN b.status = 'cancelled' THEN 'Blocker cancelled: ' ELSE 'Blocker resolved: ' END || b.summary,
+		jsonb_strip_nulls(jsonb_build_object('blocker_id', b.id, 'status', b.status, 'resolution_note', b.resolution_note))
+	FROM project_issue_flow_blockers b
+	WHERE b.issue_id = $1 AND b.resolved_at IS NOT NULL
+	UNION ALL
+	SELECT 'github.synced', 'github:' || g.id::text, g.last_synced_at, 'system', '',
+		g.repository_full_name || '#' || g.github_number || ' ' || g.github_state,
+		jsonb_strip_nulls(jsonb_build_object('repository_full_name', g.repository_full_name, 'github_number', g.github_number, 'github_url', g.github_url, 'github_state', g.github_state))
+	FROM project_issue_github_links g
+	WHERE g.issue_id = $1
+	UNION ALL
+	SELECT 'commit', 'commit:' || c.id::text, c.authored_at, 'system', '',
+		left(c.sha, 7) || ' ' || c.subject,
+		jsonb_build_object('sha', c.sha, 'branch_name', c.branch_name, 'repository_full_name', c.repository_full_name, 'author_name', c.author_name)
+	FROM project_commits c
+	JOIN issue i ON i.project_id = c.project_id
+	WHERE c.message ~ ('(^|[^0-9A-Za-z_/])#' || i.issue_number || '([^0-9]|$)')
+		OR (i.document_path IS NOT NULL AND EXISTS (
+			SELECT 1
+			FROM jsonb_array_elements_text(
+				CASE WHEN jsonb_typeof(c.metadata->'added') = 'array' THEN c.metadata->'added' ELSE '[]'::jsonb END
+				|| CASE WHEN jsonb_typeof(c.metadata->'modified') = 'array' THEN c.metadata->'modified' ELSE '[]'::jsonb END
+				|| CASE WHEN jsonb_typeof(c.metadata->'removed') = 'array' THEN c.metadata->'removed' ELSE '[]'::jsonb END
+			) AS f(path)
+			WHERE ltrim(f.path, '/') = ltrim(i.document_path, '/')
+		))
+	UNION ALL
+	SELECT 'agent.activity', 'activity:' || e.id, e.started_at, 'agent', e.agent_id,
+		e.summary,
+		jsonb_strip_nulls(jsonb_build_object('trigger', e.trigger, 'channel', e.channel, 'status', e.status, 'detail', e.detail))
+	FROM agent_activity_events e
+	WHERE e.issue_id = $1
+	UNION ALL
+	SELECT 'issue.bulk_update', 'activity_log:' || a.id::text, a.created_at,
+		CASE WHEN a.metadata->>'user_id' IS NULL THEN 'system' ELSE 'user' END, COALESCE(a.metadata->>'user_id', ''),
+		'Bulk update',
+		jsonb_strip_nulls(jsonb_build_object('operations', a.metadata->'operations', 'query', a.metadata->'query'))
+	FROM activity_log a
+	WHERE a.action = 'issue.bulk_update' AND a.metadata->'issue_ids' @> to_jsonb($1::uuid::text)
+	UNION ALL
+	SELECT 'audit', 'audit:' || l.id::text, l.created_at,
+		CASE WHEN l.actor_type = 'user' THEN 'user' ELSE 'system' END,
+		CASE WHEN l.actor_type = 'user' THEN l.actor_id ELSE '' END,
+		l.action,
+		jsonb_strip_nulls(jsonb_build_object('action', l.action, 'actor_type', l.actor_type, 'before', l.before, 'after', l.after))
+	FROM audit_log l
+	WHERE l.target_type = 'issue' AND l.target_id = $1::uuid::text`
+
+// ListIssueTimeline returns the issue's events from every source, oldest
+// first, with actor names resolved for agents and users.
+func (s *ProjectIssueStore) ListIssueTimeline(ctx context.Context, input IssueTimelineInput) (IssueTimelinePage, error) {
+	workspaceID := middleware.WorkspaceFromContext(ctx)
+	if workspaceID == "" {
+		return IssueTimelinePage{}, ErrNoWorkspace
+	}
+	issueID := strings.TrimSpace(input.IssueID)
+	if !uuidRegex.MatchString(issueID) {
+		return IssueTimelinePage{}, fmt.Errorf("%w: invalid issue_id", ErrValidation)
+	}
+	for _, eventType := range input.Types {
+		if !issueTimelineTypes[eventType] {
+			return IssueTimelinePage{}, fmt.Errorf("%w: unknown timeline type %q", ErrValidation, eventType)
+		}
+	}
+	limit := input.Limit
+	if limit <= 0 || limit > issueTimelineMaxLimit {
+		limit = 50
+	}
+
+	conn, err := WithWorkspace(ctx, s.db)
+	if err != nil {
+		return IssueTimelinePage{}, err
+	}
+	defer conn.Close()
+
+	var orgID string
+	if err := conn.QueryRowContext(ctx, `SELECT org_id FROM project_issues WHERE id = $1`, issueID).Scan(&orgID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return IssueTimelinePage{}, ErrNotFound
+		}
+		return IssueTimelinePage{}, fmt.Errorf("failed to load issue: %w", err)
+	}
+	if orgID != workspaceID {
+		return IssueTimelinePage{}, ErrForbidden
+	}
+
+	var cursorAt any
+	cursorID := ""
+	if input.Cursor != nil {
+		cursorAt = input.Cursor.OccurredAt
+		cursorID = input.Cursor.ID
+	}
+	rows, err := conn.QueryContext(
+		ctx,
+		`SELECT e.type, e.id, e.occurred_at, e.actor_type, e.actor_id,
+				COALESCE(agent.display_name, app_user.name, ''),
+				e.summary, e.detail
+			FROM (`+issueTimelineEventsSQL+`
+			) AS e(type, id, occurred_at, actor_type, actor_id, summary, detail)
+			LEFT JOIN LATERAL (
+				SELECT a.display_name FROM agents a
+				WHERE e.actor_type = 'agent' AND (a.id::text = e.actor_id OR a.slug = e.actor_id)
+				LIMIT 1
+			) agent ON TRUE
+			LEFT JOIN LATERAL (
+				SELECT COALESCE(u.display_name, u.email) AS name FROM users u
+				WHERE e.actor_type = 'user' AND u.id::text = e.actor_id
+				LIMIT 1
+			) app_user ON TRUE
+			WHERE ($2::timestamptz IS NULL OR (e.occurred_at, e.id) > ($2::timestamptz, $3::text))
+				AND (COALESCE(cardinality($4::text[]), 0) = 0 OR e.type = ANY($4::text[]))
+			ORDER BY e.occurred_at ASC, e.id ASC
+			LIMIT $5`,
+		issueID,
+		cursorAt,
+		cursorID,
+		pq.Array(input.Types),
+		limit+1,
+	)
+	if err != nil {
+		return IssueTimelinePage{}, fmt.Errorf("failed to load issue timeline: %w", err)
+	}
+	defer rows.Close()
+
+	items := make([]IssueTimelineEvent, 0)
+	for rows.Next() {
+		var event IssueTimelineEvent
+		var detail []byte
+		if err := rows.Scan(
+			&event.Type,
+			&event.ID,
+			&event.OccurredAt,
+			&event.Actor.Type,
+			&event.Actor.ID,
+			&event.Actor.Name,
+			&event.Summary,
+			&detail,
+		); err != nil {
+			return IssueTimelinePage{}, fmt.Errorf("failed to scan issue timeline event: %w", err)
+		}
+		if event.Actor.Type == IssueTimelineActorAgent && event.Actor.ID == "" {
+			event.Actor.Type = IssueTimelineActorSystem
+		}
+		if len(detail) > 0 && string(detail) != "{}" {
+			event.Detail = json.RawMessage(detail)
+		}
+		items = append(items, event)
+	}
+	if err := rows.Err(); err != nil {
+		return IssueTimelinePage{}, fmt.Errorf("failed to read issue timeline: %w", err)
+	}
+
+	page := IssueTimelinePage{Items: items}
+	if len(items) > limit {
+		page.Items = items[:limit]
+		last := page.Items[limit-1]
+		page.NextCursor = EncodeIssueTimelineCursor(IssueTimelineCursor{OccurredAt: last.OccurredAt, ID: last.ID})
+	}
+	return page, nil
+}
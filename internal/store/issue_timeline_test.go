@@ -0,0 +1,103 @@
+package store
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestIssueTimelineCursorRoundTrip(t *testing.T) {
+	at := time.Date(2026, 10, 18, 9, 30, 0, 123456000, time.UTC)
+	encoded := EncodeIssueTimelineCursor(IssueTimelineCursor{OccurredAt: at, ID: "comment:abc"})
+	decoded, err := DecodeIssueTimelineCursor(encoded)
+	require.NoError(t, err)
+	require.True(t, at.Equal(decoded.OccurredAt))
+	require.Equal(t, "comment:abc", decoded.ID)
+
+	decoded, err = DecodeIssueTimelineCursor(" ")
+	require.NoError(t, err)
+	require.Nil(t, decoded)
+
+	for _, raw := range []string{"not-base64!", "bm8tc2VwYXJhdG9y", "eWVzdGVyZGF5fGlk"} {
+		_, err := DecodeIssueTimelineCursor(raw)
+		require.ErrorIs(t, err, ErrValidation, raw)
+	}
+}
+
+func TestParseIssueTimelineTypes(t *testing.T) {
+	types, err := ParseIssueTimelineTypes(" Comment, pipeline.step,,")
+	require.NoError(t, err)
+	require.Equal(t, []string{IssueTimelineComment, IssueTimelinePipelineStep}, types)
+
+	types, err = ParseIssueTimelineTypes("")
+	require.NoError(t, err)
+	require.Empty(t, types)
+
+	_, err = ParseIssueTimelineTypes("comment,deploy")
+	require.ErrorIs(t, err, ErrValidation)
+}
+
+func TestProjectIssueStoreListIssueTimeline(t *testing.T) {
+	connStr := getTestDatabaseURL(t)
+	db := setupTestDatabase(t, connStr)
+	orgID := createTestOrganization(t, db, "issue-timeline-org")
+	projectID := createTestProject(t, db, orgID, "Issue Timeline Project")
+	agentID := createAgentJobTestAgent(t, db, orgID, "timeline-agent")
+	ctx := ctxWithWorkspace(orgID)
+
+	issues := NewProjectIssueStore(db)
+	issue, err := issues.CreateIssue(ctx, CreateProjectIssueInput{ProjectID: projectID, Title: "Timeline issue", Origin: "local"})
+	require.NoError(t, err)
+	_, err = issues.AddParticipant(ctx, AddProjectIssueParticipantInput{IssueID: issue.ID, AgentID: agentID, Role: "owner"})
+	require.NoError(t, err)
+	_, err = issues.CreateComment(ctx, CreateProjectIssueCommentInput{IssueID: issue.ID, AuthorAgentID: agentID, Body: "Starting on this"})
+	require.NoError(t, err)
+	_, err = db.Exec(
+		`INSERT INTO project_commits (org_id, project_id, repository_full_name, branch_name, sha, author_name, authored_at, subject, message)
+			VALUES ($1, $2, 'acme/app', 'main', 'abcdef1234', 'Dev', NOW(), 'Fix timeout', $3),
+				($1, $2, 'acme/app', 'main', 'bbbbbb1234', 'Dev', NOW(), 'Unrelated', $4)`,
+		orgID, projectID, fmt.Sprintf("Fix timeout (#%d)", issue.IssueNumber), fmt.Sprintf("Refs #%d0", issue.IssueNumber),
+	)
+	require.NoError(t, err)
+
+	page, err := issues.ListIssueTimeline(ctx, IssueTimelineInput{IssueID: issue.ID})
+	require.NoError(t, err)
+	types := make([]string, 0, len(page.Items))
+	for _, item := range page.Items {
+		types = append(types, item.Type)
+	}
+	require.Equal(t, IssueTimelineIssueCreated, types[0])
+	require.Contains(t, types, IssueTimelineParticipantJoined)
+	require.Contains(t, types, IssueTimelineComment)
+	require.Contains(t, types, IssueTimelineCommit)
+	require.Len(t, page.Items, 4)
+	require.Empty(t, page.NextCursor)
+	for _, item := range page.Items {
+		if item.Type == IssueTimelineComment {
+			require.Equal(t, IssueTimelineActorAgent, item.Actor.Type)
+			require.Equal(t, agentID, item.Actor.ID)
+			require.NotEmpty(t, item.Actor.Name)
+		}
+	}
+
+	first, err := issues.ListIssueTimeline(ctx, IssueTimelineInput{IssueID: issue.ID, Limit: 2})
+	require.NoError(t, err)
+	require.Len(t, first.Items, 2)
+	require.NotEmpty(t, first.NextCursor)
+	cursor, err := DecodeIssueTimelineCursor(first.NextCursor)
+	require.NoError(t, err)
+	second, err := issues.ListIssueTimeline(ctx, IssueTimelineInput{IssueID: issue.ID, Limit: 2, Cursor: cursor})
+	require.NoError(t, err)
+	require.Len(t, second.Items, 2)
+	require.Equal(t, page.Items[2].ID, second.Items[0].ID)
+
+	filtered, err := issues.ListIssueTimeline(ctx, IssueTimelineInput{IssueID: issue.ID, Types: []string{IssueTimelineComment}})
+	require.NoError(t, err)
+	require.Len(t, filtered.Items, 1)
+
+	otherOrgID := createTestOrganization(t, db, "issue-timeline-other-org")
+	_, err = issues.ListIssueTimeline(ctxWithWorkspace(otherOrgID), IssueTimelineInput{IssueID: issue.ID})
+	require.Error(t, err)
+}
@@ -0,0 +1,610 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/lib/pq"
+	"github.com/samhotchkiss/otter-camp/internal/middleware"
+)
+
+const (
+	IssueBulkMaxItems = 500
+
+	IssueBulkItemUpdated   = "updated"
+	IssueBulkItemUnchanged = "unchanged"
+	IssueBulkItemFailed    = "failed"
+
+	issueBulkActivityAction = "issue.bulk_update"
+)
+
+// IssueBulkOperations is the change set applied to every targeted issue.
+// Zero values leave a field alone; an empty OwnerAgentID unassigns.
+type IssueBulkOperations struct {
+	OwnerAgentID    *string  `json:"owner_agent_id,omitempty"`
+	Priority        string   `json:"priority,omitempty"`
+	WorkStatus      string   `json:"work_status,omitempty"`
+	State           string   `json:"state,omitempty"`
+	AddLabels       []string `json:"add_labels,omitempty"`
+	RemoveLabels    []string `json:"remove_labels,omitempty"`
+	FlowTemplateID  string   `json:"flow_template_id,omitempty"`
+	MoveToProjectID string   `json:"move_to_project_id,omitempty"`
+}
+
+func (o IssueBulkOperations) isEmpty() bool {
+	return o.OwnerAgentID == nil &&
+		o.Priority == "" &&
+		o.WorkStatus == "" &&
+		o.State == "" &&
+		len(o.AddLabels) == 0 &&
+		len(o.RemoveLabels) == 0 &&
+		o.FlowTemplateID == "" &&
+		o.MoveToProjectID == ""
+}
+
+// IssueBulkFlow is a flow template resolved to its first step. Owner
+// resolution depends on project roles, so callers resolve it up front.
+type IssueBulkFlow struct {
+	TemplateID   string
+	ProjectID    string
+	StepKey      string
+	StepIndex    int
+	OwnerAgentID *string
+}
+
+// BulkUpdateIssuesInput targets either IssueIDs or the issues matching Query
+// (optionally scoped to ProjectID), never both.
+type BulkUpdateIssuesInput struct {
+	IssueIDs   []string
+	Query      *IssueQuery
+	RawQuery   string
+	ProjectID  string
+	Now        time.Time
+	Operations IssueBulkOperations
+	Flow       *IssueBulkFlow
+	DryRun     bool
+	ActorID    string
+}
+
+type IssueBulkItemResult struct {
+	IssueID     string        `json:"issue_id"`
+	ProjectID   string        `json:"project_id,omitempty"`
+	IssueNumber int64         `json:"issue_number,omitempty"`
+	Title       string        `json:"title,omitempty"`
+	Status      string        `json:"status"`
+	Changes     []string      `json:"changes,omitempty"`
+	Error       string        `json:"error,omitempty"`
+	Before      *ProjectIssue `json:"-"`
+	After       *ProjectIssue `json:"-"`
+}
+
+// IssueBulkResult reports every targeted issue. Applied is false for dry
+// runs and whenever any item failed, since the batch commits all or nothing.
+type IssueBulkResult struct {
+	DryRun     bool                  `json:"dry_run"`
+	Applied    bool                  `json:"applied"`
+	Matched    int                   `json:"matched"`
+	Updated    int                   `json:"updated"`
+	Unchanged  int                   `json:"unchanged"`
+	Failed     int                   `json:"failed"`
+	Items      []IssueBulkItemResult `json:"items"`
+	ActivityID *string               `json:"activity_id,omitempty"`
+}
+
+// issueBulkItemError is a per-issue failure; it is reported on the item
+// rather than aborting the request.
+type issueBulkItemError struct {
+	message string
+}
+
+func (e issueBulkItemError) Error() string { return e.message }
+
+func (o IssueBulkOperations) normalize() (IssueBulkOperations, error) {
+	if o.OwnerAgentID != nil {
+		owner, err := normalizeOptionalIssueAgentID(o.OwnerAgentID)
+		if err != nil {
+			return o, fmt.Errorf("%w: invalid owner_agent_id", ErrValidation)
+		}
+		cleared := ""
+		if owner == nil {
+			owner = &cleared
+		}
+		o.OwnerAgentID = owner
+	}
+	if o.Priority = normalizeIssuePriority(o.Priority); o.Priority != "" && !isValidIssuePriority(o.Priority) {
+		return o, fmt.Errorf("%w: invalid priority", ErrValidation)
+	}
+	if o.WorkStatus = normalizeIssueWorkStatus(o.WorkStatus); o.WorkStatus != "" && !isValidIssueWorkStatus(o.WorkStatus) {
+		return o, fmt.Errorf("%w: invalid work_status", ErrValidation)
+	}
+	if o.State = normalizeIssueState(o.State); o.State != "" && !isValidIssueState(o.State) {
+		return o, fmt.Errorf("%w: state must be open or closed", ErrValidation)
+	}
+	o.AddLabels = normalizeIssueBulkLabels(o.AddLabels)
+	o.RemoveLabels = normalizeIssueBulkLabels(o.RemoveLabels)
+	for _, add := range o.AddLabels {
+		for _, remove := range o.RemoveLabels {
+			if strings.EqualFold(add, remove) {
+				return o, fmt.Errorf("%w: label %q is both added and removed", ErrValidation, add)
+			}
+		}
+	}
+	o.FlowTemplateID = strings.TrimSpace(o.FlowTemplateID)
+	o.MoveToProjectID = strings.TrimSpace(o.MoveToProjectID)
+	if o.MoveToProjectID != "" && !uuidRegex.MatchString(o.MoveToProjectID) {
+		return o, fmt.Errorf("%w: invalid move_to_project_id", ErrValidation)
+	}
+	if o.isEmpty() {
+		return o, fmt.Errorf("%w: at least one operation is required", ErrValidation)
+	}
+	return o, nil
+}
+
+func normalizeIssueBulkLabels(values []string) []string {
+	out := make([]string, 0, len(values))
+	seen := make(map[string]struct{}, len(values))
+	for _, value := range values {
+		trimmed := strings.TrimSpace(value)
+		key := strings.ToLower(trimmed)
+		if trimmed == "" {
+			continue
+		}
+		if _, ok := seen[key]; ok {
+			continue
+		}
+		seen[key] = struct{}{}
+		out = append(out, trimmed)
+	}
+	if len(out) == 0 {
+		return nil
+	}
+	return out
+}
+
+// BulkUpdateIssues applies one operation set to many issues in a single
+// transaction. Failures are reported per item and roll the whole batch back;
+// dry runs do the same work and always roll back. Applied batches write one
+// combined activity_log entry.
+func (s *ProjectIssueStore) BulkUpdateIssues(ctx context.Context, input BulkUpdateIssuesInput) (*IssueBulkResult, error) {
+	workspaceID := middleware.WorkspaceFromContext(ctx)
+	if workspaceID == "" {
+		return nil, ErrNoWorkspace
+	}
+
+	ops, err := input.Operations.normalize()
+	if err != nil {
+		return nil, err
+	}
+	if ops.FlowTemplateID != "" && (input.Flow == nil || input.Flow.TemplateID != ops.FlowTemplateID) {
+		return nil, fmt.Errorf("%w: flow template is not resolved", ErrValidation)
+	}
+	issueIDs := normalizeIDList(input.IssueIDs)
+	if (len(issueIDs) == 0) == (input.Query == nil) {
+		return nil, fmt.Errorf("%w: pass either issue_ids or a query", ErrValidation)
+	}
+	if len(issueIDs) > IssueBulkMaxItems {
+		return nil, fmt.Errorf("%w: at most %d issues per request", ErrValidation, IssueBulkMaxItems)
+	}
+	for _, issueID := range issueIDs {
+		if !uuidRegex.MatchString(issueID) {
+			return nil, fmt.Errorf("%w: invalid issue id %q", ErrValidation, issueID)
+		}
+	}
+
+	tx, err := WithWorkspaceTx(ctx, s.db)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	if ops.MoveToProjectID != "" {
+		if err := ensureIssueBulkProject(ctx, tx, ops.MoveToProjectID); err != nil {
+			return nil, err
+		}
+	}
+	addLabels, err := resolveIssueBulkLabels(ctx, tx, workspaceID, ops.AddLabels)
+	if err != nil {
+		return nil, err
+	}
+	removeLabels, err := resolveIssueBulkLabels(ctx, tx, workspaceID, ops.RemoveLabels)
+	if err != nil {
+		return nil, err
+	}
+
+	if input.Query != nil {
+		issueIDs, err = queryIssueBulkTargets(ctx, tx, workspaceID, input)
+		if err != nil {
+			return nil, err
+		}
+	}
+	current, err := lockIssueBulkTargets(ctx, tx, workspaceID, issueIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &IssueBulkResult{DryRun: input.DryRun, Matched: len(issueIDs), Items: make([]IssueBulkItemResult, 0, len(issueIDs))}
+	for _, issueID := range issueIDs {
+		item := IssueBulkItemResult{IssueID: issueID}
+		issue, ok := current[issueID]
+		if !ok {
+			item.Status = IssueBulkItemFailed
+			item.Error = "issue not found"
+			result.Failed++
+			result.Items = append(result.Items, item)
+			continue
+		}
+		before := issue
+		item.ProjectID, item.IssueNumber, item.Title, item.Before = issue.ProjectID, issue.IssueNumber, issue.Title, &before
+		if input.ProjectID != "" && issue.ProjectID != input.ProjectID {
+			item.Status = IssueBulkItemFailed
+			item.Error = "issue is not in project " + input.ProjectID
+			result.Failed++
+			result.Items = append(result.Items, item)
+			continue
+		}
+
+		after, changes, err := applyIssueBulkOperations(ctx, tx, issue, ops, input.Flow, addLabels, removeLabels)
+		var itemErr issueBulkItemError
+		switch {
+		case errors.As(err, &itemErr):
+			item.Status = IssueBulkItemFailed
+			item.Error = itemErr.message
+			result.Failed++
+		case err != nil:
+			return nil, err
+		case len(changes) == 0:
+			item.Status = IssueBulkItemUnchanged
+			result.Unchanged++
+		default:
+			item.Status = IssueBulkItemUpdated
+			item.Changes = changes
+			item.After = &after
+			item.ProjectID, item.IssueNumber = after.ProjectID, after.IssueNumber
+			result.Updated++
+		}
+		result.Items = append(result.Items, item)
+	}
+
+	if input.DryRun || result.Failed > 0 {
+		return result, nil
+	}
+	if result.Updated > 0 {
+		activityID, err := recordIssueBulkActivity(ctx, tx, workspaceID, input, ops, result)
+		if err != nil {
+			return nil, err
+		}
+		result.ActivityID = &activityID
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	result.Applied = true
+	return result, nil
+}
+
+func ensureIssueBulkProject(ctx context.Context, q Querier, projectID string) error {
+	var id string
+	err := q.QueryRowContext(ctx, `SELECT id FROM projects WHERE id = $1`, projectID).Scan(&id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return fmt.Errorf("%w: move_to_project_id not found", ErrValidation)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to load target project: %w", err)
+	}
+	return nil
+}
+
+// resolveIssueBulkLabels maps label ids or names to ids. Unknown labels fail
+// the whole request rather than being created on the fly.
+func resolveIssueBulkLabels(ctx context.Context, q Querier, workspaceID string, refs []string) ([]Label, error) {
+	resolved := make([]Label, 0, len(refs))
+	for _, ref := range refs {
+		var label Label
+		err := q.QueryRowContext(
+			ctx,
+			`SELECT id, name FROM labels
+				WHERE org_id = $1 AND (id::text = $2 OR lower(name) = lower($2))
+				ORDER BY (id::text = $2) DESC
+				LIMIT 1`,
+			workspaceID,
+			ref,
+		).Scan(&label.ID, &label.Name)
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, fmt.Errorf("%w: label %q not found", ErrValidation, ref)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to resolve label: %w", err)
+		}
+		resolved = append(resolved, label)
+	}
+	return resolved, nil
+}
+
+func queryIssueBulkTargets(ctx context.Context, q Querier, workspaceID string, input BulkUpdateIssuesInput) ([]string, error) {
+	query, args, err := buildIssueQuery(workspaceID, IssueQueryInput{
+		ProjectID: input.ProjectID,
+		Query:     *input.Query,
+		Now:       input.Now,
+	}, IssueBulkMaxItems)
+	if err != nil {
+		return nil, err
+	}
+	rows, err := q.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query bulk targets: %w", err)
+	}
+	defer rows.Close()
+
+	ids := make([]string, 0)
+	for rows.Next() {
+		issue, err := scanProjectIssue(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan issue row: %w", err)
+		}
+		ids = append(ids, issue.ID)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read issue rows: %w", err)
+	}
+	if len(ids) > IssueBulkMaxItems {
+		return nil, fmt.Errorf("%w: query matches more than %d issues; narrow it down", ErrValidation, IssueBulkMaxItems)
+	}
+	return ids, nil
+}
+
+func lockIssueBulkTargets(ctx context.Context, q Querier, workspaceID string, issueIDs []string) (map[string]ProjectIssue, error) {
+	issues := make(map[string]ProjectIssue, len(issueIDs))
+	if len(issueIDs) == 0 {
+		return issues, nil
+	}
+	rows, err := q.QueryContext(
+		ctx,
+		`SELECT id, org_id, project_id, issue_number, title, body, state, origin, document_path, approval_state, owner_agent_id, work_status, priority, due_at, next_step, next_step_due_at, flow_template_id, flow_step_key, flow_step_index, parent_issue_id, merged_into_issue_id, created_at, updated_at, closed_at
+			FROM project_issues
+			WHERE org_id = $1 AND id = ANY($2::uuid[])
+			ORDER BY id
+			FOR UPDATE`,
+		workspaceID,
+		pq.Array(issueIDs),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to lock issues: %w", err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		issue, err := scanProjectIssue(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan issue row: %w", err)
+		}
+		issues[issue.ID] = issue
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read issue rows: %w", err)
+	}
+	return issues, nil
+}
+
+// applyIssueBulkOperations moves the issue first, then assigns the flow, then
+// applies work tracking so an explicit owner wins over the flow step owner.
+func applyIssueBulkOperations(
+	ctx context.Context,
+	tx *sql.Tx,
+	issue ProjectIssue,
+	ops IssueBulkOperations,
+	flow *IssueBulkFlow,
+	addLabels []Label,
+	removeLabels []Label,
+) (ProjectIssue, []string, error) {
+	changes := make([]string, 0)
+	before := issue
+
+	if ops.MoveToProjectID != "" && ops.MoveToProjectID != issue.ProjectID {
+		if strings.EqualFold(strings.TrimSpace(issue.Origin), "github") {
+			return issue, nil, issueBulkItemError{"GitHub issues cannot be moved to another project"}
+		}
+		moved, err := scanProjectIssue(tx.QueryRowContext(
+			ctx,
+			`UPDATE project_issues
+				SET project_id = $2,
+					issue_number = (SELECT COALESCE(MAX(issue_number), 0) + 1 FROM project_issues WHERE project_id = $2),
+					parent_issue_id = NULL,
+					flow_template_id = NULL,
+					flow_step_key = NULL,
+					flow_step_index = NULL
+				WHERE id = $1
+				RETURNING id, org_id, project_id, issue_number, title, body, state, origin, document_path, approval_state, owner_agent_id, work_status, priority, due_at, next_step, next_step_due_at, flow_template_id, flow_step_key, flow_step_index, parent_issue_id, merged_into_issue_id, created_at, updated_at, closed_at`,
+			issue.ID,
+			ops.MoveToProjectID,
+		))
+		if err != nil {
+			return issue, nil, fmt.Errorf("failed to move issue: %w", err)
+		}
+		// Sub-issues stay behind in the old project as top-level issues.
+		if _, err := tx.ExecContext(ctx, `UPDATE project_issues SET parent_issue_id = NULL WHERE parent_issue_id = $1`, issue.ID); err != nil {
+			return issue, nil, fmt.Errorf("failed to detach sub-issues: %w", err)
+		}
+		changes = append(changes, fmt.Sprintf("moved to project %s as #%d", moved.ProjectID, moved.IssueNumber))
+		issue = moved
+	}
+
+	if flow != nil && (issue.FlowTemplateID == nil || *issue.FlowTemplateID != flow.TemplateID) {
+		if issue.ProjectID != flow.ProjectID {
+			return issue, nil, issueBulkItemError{"flow template does not belong to the issue's project"}
+		}
+		nextOwner := issue.OwnerAgentID
+		if flow.OwnerAgentID != nil {
+			if err := ensureAgentVisible(ctx, tx, *flow.OwnerAgentID); err != nil {
+				return issue, nil, issueBulkAgentError(err)
+			}
+			nextOwner = flow.OwnerAgentID
+		}
+		stepIndex := flow.StepIndex
+		assigned, err := scanProjectIssue(tx.QueryRowContext(
+			ctx,
+			`UPDATE project_issues
+				SET flow_template_id = $2,
+					flow_step_key = $3,
+					flow_step_index = $4,
+					owner_agent_id = $5
+				WHERE id = $1
+				RETURNING id, org_id, project_id, issue_number, title, body, state, origin, document_path, approval_state, owner_agent_id, work_status, priority, due_at, next_step, next_step_due_at, flow_template_id, flow_step_key, flow_step_index, parent_issue_id, merged_into_issue_id, created_at, updated_at, closed_at`,
+			issue.ID,
+			flow.TemplateID,
+			flow.StepKey,
+			nullableInt32(&stepIndex),
+			nullableString(nextOwner),
+		))
+		if err != nil {
+			return issue, nil, fmt.Errorf("failed to assign flow: %w", err)
+		}
+		changes = append(changes, "flow: "+flow.TemplateID)
+		issue = assigned
+	}
+
+	tracking := UpdateProjectIssueWorkTrackingInput{IssueID: issue.ID}
+	if ops.OwnerAgentID != nil {
+		tracking.SetOwnerAgentID = true
+		tracking.OwnerAgentID = ops.OwnerAgentID
+	}
+	if ops.Priority != "" {
+		tracking.SetPriority, tracking.Priority = true, ops.Priority
+	}
+	if ops.WorkStatus != "" {
+		tracking.SetWorkStatus, tracking.WorkStatus = true, ops.WorkStatus
+	}
+	if ops.State != "" {
+		tracking.SetState, tracking.State = true, ops.State
+		// Reopening a finished issue puts it back in the queue, as
+		// `otter issue reopen` does.
+		currentStatus := normalizeIssueWorkStatus(issue.WorkStatus)
+		if ops.State == "open" && ops.WorkStatus == "" &&
+			(currentStatus == IssueWorkStatusDone || currentStatus == IssueWorkStatusCancelled) {
+			tracking.SetWorkStatus, tracking.WorkStatus = true, IssueWorkStatusQueued
+		}
+	}
+	if tracking.SetOwnerAgentID || tracking.SetPriority || tracking.SetWorkStatus || tracking.SetState {
+		next, err := planIssueWorkTracking(ctx, tx, issue, tracking)
+		if err != nil {
+			if errors.Is(err, ErrNotFound) {
+				return issue, nil, issueBulkItemError{"owner agent not found"}
+			}
+			return issue, nil, issueBulkItemError{err.Error()}
+		}
+		updated, err := writeIssueWorkTracking(ctx, tx, issue.ID, next)
+		if err != nil {
+			return issue, nil, err
+		}
+		if normalizeIssueState(issue.State) != "closed" {
+			if _, err := closeParentsOfClosedSubIssue(ctx, tx, updated); err != nil {
+				return issue, nil, err
+			}
+		}
+		issue = updated
+	}
+	changes = append(changes, describeIssueBulkTrackingChanges(before, issue)...)
+
+	for _, label := range addLabels {
+		res, err := tx.ExecContext(
+			ctx,
+			`INSERT INTO issue_labels (issue_id, label_id) VALUES ($1, $2) ON CONFLICT (issue_id, label_id) DO NOTHING`,
+			issue.ID,
+			label.ID,
+		)
+		if err != nil {
+			return issue, nil, fmt.Errorf("failed to add label to issue: %w", err)
+		}
+		if rows, _ := res.RowsAffected(); rows > 0 {
+			changes = append(changes, "+label "+label.Name)
+		}
+	}
+	for _, label := range removeLabels {
+		res, err := tx.ExecContext(ctx, `DELETE FROM issue_labels WHERE issue_id = $1 AND label_id = $2`, issue.ID, label.ID)
+		if err != nil {
+			return issue, nil, fmt.Errorf("failed to remove label from issue: %w", err)
+		}
+		if rows, _ := res.RowsAffected(); rows > 0 {
+			changes = append(changes, "-label "+label.Name)
+		}
+	}
+	return issue, changes, nil
+}
+
+func issueBulkAgentError(err error) error {
+	if errors.Is(err, ErrNotFound) {
+		return issueBulkItemError{"flow step owner agent not found"}
+	}
+	return err
+}
+
+func describeIssueBulkTrackingChanges(before, after ProjectIssue) []string {
+	changes := make([]string, 0, 4)
+	if issueBulkOwner(before.OwnerAgentID) != issueBulkOwner(after.OwnerAgentID) {
+		changes = append(changes, fmt.Sprintf("owner: %s -> %s", issueBulkOwner(before.OwnerAgentID), issueBulkOwner(after.OwnerAgentID)))
+	}
+	if before.Priority != after.Priority {
+		changes = append(changes, fmt.Sprintf("priority: %s -> %s", before.Priority, after.Priority))
+	}
+	if before.WorkStatus != after.WorkStatus {
+		changes = append(changes, fmt.Sprintf("work_status: %s -> %s", before.WorkStatus, after.WorkStatus))
+	}
+	if before.State != after.State {
+		changes = append(changes, fmt.Sprintf("state: %s -> %s", before.State, after.State))
+	}
+	return changes
+}
+
+func issueBulkOwner(ownerAgentID *string) string {
+	if ownerAgentID == nil || strings.TrimSpace(*ownerAgentID) == "" {
+		return "unassigned"
+	}
+	return *ownerAgentID
+}
+
+func recordIssueBulkActivity(
+	ctx context.Context,
+	q Querier,
+	workspaceID string,
+	input BulkUpdateIssuesInput,
+	ops IssueBulkOperations,
+	result *IssueBulkResult,
+) (string, error) {
+	issueIDs := make([]string, 0, result.Updated)
+	for _, item := range result.Items {
+		if item.Status == IssueBulkItemUpdated {
+			issueIDs = append(issueIDs, item.IssueID)
+		}
+	}
+	metadata := map[string]any{
+		"operations":  ops,
+		"issue_ids":   issueIDs,
+		"issue_count": len(issueIDs),
+		"matched":     result.Matched,
+	}
+	if strings.TrimSpace(input.RawQuery) != "" {
+		metadata["query"] = strings.TrimSpace(input.RawQuery)
+	}
+	if strings.TrimSpace(input.ActorID) != "" {
+		metadata["user_id"] = strings.TrimSpace(input.ActorID)
+	}
+	payload, err := json.Marshal(metadata)
+	if err != nil {
+		return "", err
+	}
+
+	var activityID string
+	if err := q.QueryRowContext(
+		ctx,
+		`INSERT INTO activity_log (org_id, action, metadata) VALUES ($1, $2, $3) RETURNING id`,
+		workspaceID,
+		issueBulkActivityAction,
+		payload,
+	).Scan(&activityID); err != nil {
+		return "", fmt.Errorf("failed to record bulk activity: %w", err)
+	}
+	return activityID, nil
+}
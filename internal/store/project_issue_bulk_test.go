@@ -0,0 +1,140 @@
+package store
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestIssueBulkOperationsNormalize(t *testing.T) {
+	empty := ""
+	ops, err := IssueBulkOperations{
+		OwnerAgentID: &empty,
+		Priority:     " p1 ",
+		WorkStatus:   "In_Progress",
+		AddLabels:    []string{"bug", " Bug ", ""},
+	}.normalize()
+	require.NoError(t, err)
+	require.NotNil(t, ops.OwnerAgentID)
+	require.Equal(t, "", *ops.OwnerAgentID)
+	require.Equal(t, "P1", ops.Priority)
+	require.Equal(t, IssueWorkStatusInProgress, ops.WorkStatus)
+	require.Equal(t, []string{"bug"}, ops.AddLabels)
+
+	for name, bad := range map[string]IssueBulkOperations{
+		"empty":    {},
+		"priority": {Priority: "P9"},
+		"status":   {WorkStatus: "shipping"},
+		"state":    {State: "archived"},
+		"labels":   {AddLabels: []string{"bug"}, RemoveLabels: []string{"BUG"}},
+		"project":  {MoveToProjectID: "blog"},
+	} {
+		_, err := bad.normalize()
+		require.ErrorIs(t, err, ErrValidation, name)
+	}
+}
+
+func TestProjectIssueStoreBulkUpdateIssues(t *testing.T) {
+	connStr := getTestDatabaseURL(t)
+	db := setupTestDatabase(t, connStr)
+	orgID := createTestOrganization(t, db, "issue-bulk-org")
+	projectID := createTestProject(t, db, orgID, "Issue Bulk Project")
+	otherProjectID := createTestProject(t, db, orgID, "Issue Bulk Target")
+	agentID := createIssueTestAgent(t, db, orgID, "bulk-owner")
+	ctx := ctxWithWorkspace(orgID)
+
+	issueStore := NewProjectIssueStore(db)
+	labelStore := NewLabelStore(db)
+	_, err := labelStore.Create(ctx, "triaged", "#00aa00")
+	require.NoError(t, err)
+
+	first, err := issueStore.CreateIssue(ctx, CreateProjectIssueInput{ProjectID: projectID, Title: "First", Origin: "local"})
+	require.NoError(t, err)
+	second, err := issueStore.CreateIssue(ctx, CreateProjectIssueInput{ProjectID: projectID, Title: "Second", Origin: "local"})
+	require.NoError(t, err)
+
+	ops := IssueBulkOperations{OwnerAgentID: &agentID, Priority: "P1", AddLabels: []string{"Triaged"}}
+	preview, err := issueStore.BulkUpdateIssues(ctx, BulkUpdateIssuesInput{
+		IssueIDs:   []string{first.ID, second.ID},
+		Operations: ops,
+		DryRun:     true,
+	})
+	require.NoError(t, err)
+	require.False(t, preview.Applied)
+	require.Equal(t, 2, preview.Updated)
+	require.Contains(t, preview.Items[0].Changes, "priority: P2 -> P1")
+	require.Contains(t, preview.Items[0].Changes, "+label triaged")
+	unchanged, err := issueStore.GetIssueByID(ctx, first.ID)
+	require.NoError(t, err)
+	require.Equal(t, IssuePriorityP2, unchanged.Priority)
+
+	query, err := ParseIssueQuery("is:open sort:number-asc")
+	require.NoError(t, err)
+	applied, err := issueStore.BulkUpdateIssues(ctx, BulkUpdateIssuesInput{
+		Query:      &query,
+		ProjectID:  projectID,
+		Operations: ops,
+		ActorID:    "user-1",
+	})
+	require.NoError(t, err)
+	require.True(t, applied.Applied)
+	require.Equal(t, 2, applied.Updated)
+	require.NotNil(t, applied.ActivityID)
+	labels, err := labelStore.ListForIssue(ctx, second.ID)
+	require.NoError(t, err)
+	require.Len(t, labels, 1)
+
+	var activityCount int
+	require.NoError(t, db.QueryRow(`SELECT COUNT(*) FROM activity_log WHERE org_id = $1 AND action = 'issue.bulk_update'`, orgID).Scan(&activityCount))
+	require.Equal(t, 1, activityCount)
+
+	// One bad item rolls back the whole batch.
+	missing := "00000000-0000-0000-0000-00000000beef"
+	failed, err := issueStore.BulkUpdateIssues(ctx, BulkUpdateIssuesInput{
+		IssueIDs:   []string{first.ID, missing},
+		Operations: IssueBulkOperations{State: "closed"},
+	})
+	require.NoError(t, err)
+	require.False(t, failed.Applied)
+	require.Equal(t, 1, failed.Failed)
+	require.Equal(t, "issue not found", failed.Items[1].Error)
+	stillOpen, err := issueStore.GetIssueByID(ctx, first.ID)
+	require.NoError(t, err)
+	require.Equal(t, "open", stillOpen.State)
+
+	// Explicit ids outside the named project are refused.
+	scoped, err := issueStore.BulkUpdateIssues(ctx, BulkUpdateIssuesInput{
+		IssueIDs:   []string{first.ID},
+		ProjectID:  otherProjectID,
+		Operations: IssueBulkOperations{State: "closed"},
+	})
+	require.NoError(t, err)
+	require.False(t, scoped.Applied)
+	require.Equal(t, "issue is not in project "+otherProjectID, scoped.Items[0].Error)
+
+	moved, err := issueStore.BulkUpdateIssues(ctx, BulkUpdateIssuesInput{
+		IssueIDs:   []string{second.ID},
+		Operations: IssueBulkOperations{MoveToProjectID: otherProjectID, State: "closed"},
+	})
+	require.NoError(t, err)
+	require.True(t, moved.Applied)
+	after, err := issueStore.GetIssueByID(ctx, second.ID)
+	require.NoError(t, err)
+	require.Equal(t, otherProjectID, after.ProjectID)
+	require.EqualValues(t, 1, after.IssueNumber)
+	require.Equal(t, "closed", after.State)
+	require.Equal(t, IssueWorkStatusDone, after.WorkStatus)
+
+	reopened, err := issueStore.BulkUpdateIssues(ctx, BulkUpdateIssuesInput{
+		IssueIDs:   []string{second.ID},
+		Operations: IssueBulkOperations{State: "open"},
+	})
+	require.NoError(t, err)
+	require.Contains(t, reopened.Items[0].Changes, "work_status: done -> queued")
+
+	_, err = issueStore.BulkUpdateIssues(ctx, BulkUpdateIssuesInput{
+		IssueIDs:   []string{first.ID},
+		Operations: IssueBulkOperations{AddLabels: []string{"nope"}},
+	})
+	require.ErrorIs(t, err, ErrValidation)
+}
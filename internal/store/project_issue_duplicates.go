@@ -0,0 +1,358 @@
+package store
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/samhotchkiss/otter-camp/internal/middleware"
+)
+
+// DefaultIssueDuplicateThreshold is the cosine similarity above which an
+// existing issue is reported as a likely duplicate of a new one.
+const DefaultIssueDuplicateThreshold = 0.9
+
+// MaxIssueDuplicateCandidates caps how many likely duplicates are returned.
+const MaxIssueDuplicateCandidates = 5
+
+// IssueDuplicateCandidate is an existing issue whose embedding is close to
+// the text being checked.
+type IssueDuplicateCandidate struct {
+	Issue      ProjectIssue `json:"issue"`
+	Similarity float64      `json:"similarity"`
+}
+
+// FindDuplicateIssuesInput describes a similarity search within one project.
+// The embedding dimension selects which vector column is searched.
+type FindDuplicateIssuesInput struct {
+	ProjectID      string
+	Embedding      []float64
+	Threshold      float64
+	Limit          int
+	ExcludeIssueID string
+}
+
+// IssueMergeResult reports what a merge moved onto the canonical issue.
+type IssueMergeResult struct {
+	Duplicate         ProjectIssue `json:"duplicate"`
+	Canonical         ProjectIssue `json:"canonical"`
+	MovedComments     int          `json:"moved_comments"`
+	MovedParticipants int          `json:"moved_participants"`
+	MovedLabels       int          `json:"moved_labels"`
+	MovedSubIssues    int          `json:"moved_sub_issues"`
+	MovedGitHubLink   bool         `json:"moved_github_link"`
+}
+
+// IssueEmbeddingText is the text embedded for an issue.
+func IssueEmbeddingText(title string, body *string) string {
+	text := strings.TrimSpace(title)
+	if body != nil && strings.TrimSpace(*body) != "" {
+		text += "\n\n" + strings.TrimSpace(*body)
+	}
+	return text
+}
+
+// FindDuplicateIssues returns unmerged issues in the project whose embedding
+// is at least Threshold similar to input.Embedding, most similar first.
+func (s *ProjectIssueStore) FindDuplicateIssues(ctx context.Context, input FindDuplicateIssuesInput) ([]IssueDuplicateCandidate, error) {
+	workspaceID := middleware.WorkspaceFromContext(ctx)
+	if workspaceID == "" {
+		return nil, ErrNoWorkspace
+	}
+	projectID := strings.TrimSpace(input.ProjectID)
+	if !uuidRegex.MatchString(projectID) {
+		return nil, fmt.Errorf("%w: invalid project_id", ErrValidation)
+	}
+	vectorLiteral, err := formatVectorLiteral(input.Embedding)
+	if err != nil {
+		return nil, err
+	}
+	threshold := input.Threshold
+	if threshold <= 0 || threshold > 1 {
+		threshold = DefaultIssueDuplicateThreshold
+	}
+	limit := input.Limit
+	if limit <= 0 || limit > MaxIssueDuplicateCandidates {
+		limit = MaxIssueDuplicateCandidates
+	}
+	excludeID := strings.TrimSpace(input.ExcludeIssueID)
+	if excludeID != "" && !uuidRegex.MatchString(excludeID) {
+		return nil, fmt.Errorf("%w: invalid issue_id", ErrValidation)
+	}
+
+	conn, err := WithWorkspace(ctx, s.db)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	rows, err := conn.QueryContext(
+		ctx,
+		fmt.Sprintf(`SELECT id, org_id, project_id, issue_number, title, body, state, origin, document_path, approval_state, owner_agent_id, work_status, priority, due_at, next_step, next_step_due_at, flow_template_id, flow_step_key, flow_step_index, parent_issue_id, merged_into_issue_id, created_at, updated_at, closed_at,
+				1 - (%[1]s <=> $2::vector) AS similarity
+			FROM project_issues
+			WHERE project_id = $1
+				AND %[1]s IS NOT NULL
+				AND merged_into_issue_id IS NULL
+				AND ($4 = '' OR id::text <> $4)
+				AND 1 - (%[1]s <=> $2::vector) >= $3
+			ORDER BY %[1]s <=> $2::vector ASC, issue_number ASC
+			LIMIT $5`, embeddingColumnForDimension(len(input.Embedding))),
+		projectID,
+		vectorLiteral,
+		threshold,
+		excludeID,
+		limit,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to search duplicate issues: %w", err)
+	}
+	defer rows.Close()
+
+	candidates := make([]IssueDuplicateCandidate, 0, limit)
+	for rows.Next() {
+		var candidate IssueDuplicateCandidate
+		issue, err := scanProjectIssue(scannerWithExtra{scanner: rows, extra: []any{&candidate.Similarity}})
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan duplicate issue: %w", err)
+		}
+		if issue.OrgID != workspaceID {
+			return nil, ErrForbidden
+		}
+		candidate.Issue = issue
+		candidates = append(candidates, candidate)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed reading duplicate issues: %w", err)
+	}
+	return candidates, nil
+}
+
+// SetIssueEmbedding stores the embedding for an issue's current title and
+// body. The dimension selects which vector column is written.
+func (s *ProjectIssueStore) SetIssueEmbedding(ctx context.Context, issueID string, embedding []float64) error {
+	if middleware.WorkspaceFromContext(ctx) == "" {
+		return ErrNoWorkspace
+	}
+	issueID = strings.TrimSpace(issueID)
+	if !uuidRegex.MatchString(issueID) {
+		return fmt.Errorf("%w: invalid issue_id", ErrValidation)
+	}
+	vectorLiteral, err := formatVectorLiteral(embedding)
+	if err != nil {
+		return err
+	}
+
+	conn, err := WithWorkspace(ctx, s.db)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	result, err := conn.ExecContext(
+		ctx,
+		fmt.Sprintf(`UPDATE project_issues SET %s = $2::vector WHERE id = $1`, embeddingColumnForDimension(len(embedding))),
+		issueID,
+		vectorLiteral,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to update issue embedding: %w", err)
+	}
+	if affected, _ := result.RowsAffected(); affected == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// MergeIssue folds duplicateID into canonicalID. Comments, active
+// participants, labels and sub-issues move to the canonical issue, as does
+// the GitHub link when the canonical issue has none of its own. The
+// duplicate is closed as cancelled and keeps merged_into_issue_id as a
+// redirect.
+func (s *ProjectIssueStore) MergeIssue(ctx context.Context, duplicateID, canonicalID string) (*IssueMergeResult, error) {
+	if middleware.WorkspaceFromContext(ctx) == "" {
+		return nil, ErrNoWorkspace
+	}
+	duplicateID = strings.TrimSpace(duplicateID)
+	canonicalID = strings.TrimSpace(canonicalID)
+	if !uuidRegex.MatchString(duplicateID) {
+		return nil, fmt.Errorf("%w: invalid issue_id", ErrValidation)
+	}
+	if !uuidRegex.MatchString(canonicalID) {
+		return nil, fmt.Errorf("%w: invalid into_issue_id", ErrValidation)
+	}
+	if duplicateID == canonicalID {
+		return nil, fmt.Errorf("%w: an issue cannot be merged into itself", ErrValidation)
+	}
+
+	tx, err := WithWorkspaceTx(ctx, s.db)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	if err := lockIssueTree(ctx, tx); err != nil {
+		return nil, err
+	}
+	// Lock in a stable order so concurrent merges of the same pair cannot
+	// deadlock.
+	firstID, secondID := duplicateID, canonicalID
+	if secondID < firstID {
+		firstID, secondID = secondID, firstID
+	}
+	first, err := loadProjectIssueForUpdate(ctx, tx, firstID)
+	if err != nil {
+		return nil, err
+	}
+	second, err := loadProjectIssueForUpdate(ctx, tx, secondID)
+	if err != nil {
+		return nil, err
+	}
+	duplicate, canonical := first, second
+	if first.ID == canonicalID {
+		duplicate, canonical = second, first
+	}
+
+	if duplicate.ProjectID != canonical.ProjectID {
+		return nil, fmt.Errorf("%w: issues must be in the same project to merge", ErrValidation)
+	}
+	if duplicate.MergedIntoID != nil {
+		return nil, fmt.Errorf("%w: issue is already merged", ErrValidation)
+	}
+	if canonical.MergedIntoID != nil {
+		return nil, fmt.Errorf("%w: target issue is itself merged into %s", ErrValidation, *canonical.MergedIntoID)
+	}
+	ancestors, err := subIssueAncestorIDs(ctx, tx, canonical.ID)
+	if err != nil {
+		return nil, err
+	}
+	for _, ancestorID := range ancestors {
+		if ancestorID == duplicate.ID {
+			return nil, fmt.Errorf("%w: cannot merge an issue into one of its own sub-issues", ErrValidation)
+		}
+	}
+	height, err := subIssueSubtreeHeight(ctx, tx, duplicate.ID)
+	if err != nil {
+		return nil, err
+	}
+	if height > 0 && len(ancestors)+height-1 > IssueTreeMaxDepth {
+		return nil, fmt.Errorf("%w: sub-issues cannot nest more than %d levels", ErrValidation, IssueTreeMaxDepth)
+	}
+
+	result := IssueMergeResult{}
+	if result.MovedComments, err = execRowsAffected(ctx, tx,
+		`UPDATE project_issue_comments SET issue_id = $2 WHERE issue_id = $1`,
+		duplicate.ID, canonical.ID,
+	); err != nil {
+		return nil, fmt.Errorf("failed to move issue comments: %w", err)
+	}
+
+	// Agents already on the canonical issue keep their role there; everyone
+	// else joins as a collaborator.
+	if result.MovedParticipants, err = execRowsAffected(ctx, tx,
+		`UPDATE project_issue_participants dup
+			SET issue_id = $2, role = 'collaborator', joined_at = NOW()
+			WHERE dup.issue_id = $1
+				AND dup.removed_at IS NULL
+				AND NOT EXISTS (
+					SELECT 1 FROM project_issue_participants existing
+					WHERE existing.issue_id = $2
+						AND existing.agent_id = dup.agent_id
+						AND existing.removed_at IS NULL
+				)`,
+		duplicate.ID, canonical.ID,
+	); err != nil {
+		return nil, fmt.Errorf("failed to move issue participants: %w", err)
+	}
+	if _, err := tx.ExecContext(ctx,
+		`UPDATE project_issue_participants SET removed_at = NOW() WHERE issue_id = $1 AND removed_at IS NULL`,
+		duplicate.ID,
+	); err != nil {
+		return nil, fmt.Errorf("failed to release duplicate participants: %w", err)
+	}
+
+	if result.MovedLabels, err = execRowsAffected(ctx, tx,
+		`INSERT INTO issue_labels (issue_id, label_id)
+			SELECT $2, label_id FROM issue_labels WHERE issue_id = $1
+			ON CONFLICT (issue_id, label_id) DO NOTHING`,
+		duplicate.ID, canonical.ID,
+	); err != nil {
+		return nil, fmt.Errorf("failed to copy issue labels: %w", err)
+	}
+	if _, err := tx.ExecContext(ctx, `DELETE FROM issue_labels WHERE issue_id = $1`, duplicate.ID); err != nil {
+		return nil, fmt.Errorf("failed to clear duplicate labels: %w", err)
+	}
+
+	if result.MovedSubIssues, err = execRowsAffected(ctx, tx,
+		`UPDATE project_issues SET parent_issue_id = $2 WHERE parent_issue_id = $1`,
+		duplicate.ID, canonical.ID,
+	); err != nil {
+		return nil, fmt.Errorf("failed to move sub-issues: %w", err)
+	}
+
+	// project_issue_github_links allows one link per issue, so a duplicate's
+	// link only moves when the canonical issue is not linked yet.
+	movedLinks, err := execRowsAffected(ctx, tx,
+		`UPDATE project_issue_github_links SET issue_id = $2
+			WHERE issue_id = $1
+				AND NOT EXISTS (SELECT 1 FROM project_issue_github_links WHERE issue_id = $2)`,
+		duplicate.ID, canonical.ID,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to move issue github link: %w", err)
+	}
+	result.MovedGitHubLink = movedLinks > 0
+
+	merged, err := scanProjectIssue(tx.QueryRowContext(
+		ctx,
+		`UPDATE project_issues
+			SET merged_into_issue_id = $2,
+				state = 'closed',
+				work_status = CASE WHEN state = 'closed' THEN work_status ELSE $3 END,
+				closed_at = COALESCE(closed_at, NOW())
+			WHERE id = $1
+			RETURNING id, org_id, project_id, issue_number, title, body, state, origin, document_path, approval_state, owner_agent_id, work_status, priority, due_at, next_step, next_step_due_at, flow_template_id, flow_step_key, flow_step_index, parent_issue_id, merged_into_issue_id, created_at, updated_at, closed_at`,
+		duplicate.ID,
+		canonical.ID,
+		IssueWorkStatusCancelled,
+	))
+	if err != nil {
+		return nil, fmt.Errorf("failed to close merged issue: %w", err)
+	}
+	if normalizeIssueState(duplicate.State) != "closed" {
+		if _, err := closeParentsOfClosedSubIssue(ctx, tx, merged); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	result.Duplicate = merged
+	result.Canonical = canonical
+	return &result, nil
+}
+
+// scannerWithExtra appends extra destinations after the ones the wrapped
+// scan function asks for, so scanProjectIssue can read queries that select
+// additional trailing columns.
+type scannerWithExtra struct {
+	scanner interface{ Scan(...any) error }
+	extra   []any
+}
+
+func (s scannerWithExtra) Scan(dest ...any) error {
+	return s.scanner.Scan(append(dest, s.extra...)...)
+}
+
+func execRowsAffected(ctx context.Context, q Querier, query string, args ...any) (int, error) {
+	result, err := q.ExecContext(ctx, query, args...)
+	if err != nil {
+		return 0, err
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return 0, err
+	}
+	return int(affected), nil
+}
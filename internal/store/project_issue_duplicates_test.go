@@ -0,0 +1,89 @@
+package store
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestIssueEmbeddingText(t *testing.T) {
+	body := "  Steps to reproduce  "
+	blank := " "
+	require.Equal(t, "Login fails\n\nSteps to reproduce", IssueEmbeddingText(" Login fails ", &body))
+	require.Equal(t, "Login fails", IssueEmbeddingText("Login fails", &blank))
+	require.Equal(t, "Login fails", IssueEmbeddingText("Login fails", nil))
+}
+
+func TestProjectIssueStoreDuplicatesAndMerge(t *testing.T) {
+	connStr := getTestDatabaseURL(t)
+	db := setupTestDatabase(t, connStr)
+	orgID := createTestOrganization(t, db, "issue-duplicates-org")
+	projectID := createTestProject(t, db, orgID, "Issue Duplicates Project")
+	otherProjectID := createTestProject(t, db, orgID, "Issue Duplicates Other")
+	ownerID := createAgentJobTestAgent(t, db, orgID, "dup-owner")
+	helperID := createAgentJobTestAgent(t, db, orgID, "dup-helper")
+	ctx := ctxWithWorkspace(orgID)
+
+	issues := NewProjectIssueStore(db)
+	canonical, err := issues.CreateIssue(ctx, CreateProjectIssueInput{ProjectID: projectID, Title: "Login times out", Origin: "local"})
+	require.NoError(t, err)
+	duplicate, err := issues.CreateIssue(ctx, CreateProjectIssueInput{ProjectID: projectID, Title: "Cannot log in", Origin: "local"})
+	require.NoError(t, err)
+	foreign, err := issues.CreateIssue(ctx, CreateProjectIssueInput{ProjectID: otherProjectID, Title: "Elsewhere", Origin: "local"})
+	require.NoError(t, err)
+
+	near := make([]float64, 768)
+	near[0] = 1
+	far := make([]float64, 768)
+	far[1] = 1
+	require.NoError(t, issues.SetIssueEmbedding(ctx, canonical.ID, near))
+	require.NoError(t, issues.SetIssueEmbedding(ctx, duplicate.ID, near))
+
+	found, err := issues.FindDuplicateIssues(ctx, FindDuplicateIssuesInput{ProjectID: projectID, Embedding: near, ExcludeIssueID: duplicate.ID})
+	require.NoError(t, err)
+	require.Len(t, found, 1)
+	require.Equal(t, canonical.ID, found[0].Issue.ID)
+	require.InDelta(t, 1.0, found[0].Similarity, 0.0001)
+	found, err = issues.FindDuplicateIssues(ctx, FindDuplicateIssuesInput{ProjectID: projectID, Embedding: far})
+	require.NoError(t, err)
+	require.Empty(t, found)
+
+	_, err = issues.AddParticipant(ctx, AddProjectIssueParticipantInput{IssueID: canonical.ID, AgentID: ownerID, Role: "owner"})
+	require.NoError(t, err)
+	_, err = issues.AddParticipant(ctx, AddProjectIssueParticipantInput{IssueID: duplicate.ID, AgentID: ownerID, Role: "owner"})
+	require.NoError(t, err)
+	_, err = issues.AddParticipant(ctx, AddProjectIssueParticipantInput{IssueID: duplicate.ID, AgentID: helperID, Role: "collaborator"})
+	require.NoError(t, err)
+	_, err = issues.CreateComment(ctx, CreateProjectIssueCommentInput{IssueID: duplicate.ID, AuthorAgentID: helperID, Body: "Also on mobile"})
+	require.NoError(t, err)
+	_, err = issues.UpsertGitHubLink(ctx, UpsertProjectIssueGitHubLinkInput{IssueID: duplicate.ID, RepositoryFullName: "acme/app", GitHubNumber: 7, GitHubState: "open"})
+	require.NoError(t, err)
+
+	_, err = issues.MergeIssue(ctx, duplicate.ID, foreign.ID)
+	require.ErrorIs(t, err, ErrValidation)
+	_, err = issues.MergeIssue(ctx, duplicate.ID, duplicate.ID)
+	require.ErrorIs(t, err, ErrValidation)
+
+	result, err := issues.MergeIssue(ctx, duplicate.ID, canonical.ID)
+	require.NoError(t, err)
+	require.Equal(t, 1, result.MovedComments)
+	require.Equal(t, 1, result.MovedParticipants)
+	require.True(t, result.MovedGitHubLink)
+	require.Equal(t, "closed", result.Duplicate.State)
+	require.Equal(t, IssueWorkStatusCancelled, result.Duplicate.WorkStatus)
+	require.Equal(t, canonical.ID, *result.Duplicate.MergedIntoID)
+
+	comments, err := issues.ListComments(ctx, canonical.ID, 10, 0)
+	require.NoError(t, err)
+	require.Len(t, comments, 1)
+	participants, err := issues.ListParticipants(ctx, canonical.ID, false)
+	require.NoError(t, err)
+	require.Len(t, participants, 2)
+
+	found, err = issues.FindDuplicateIssues(ctx, FindDuplicateIssuesInput{ProjectID: projectID, Embedding: near})
+	require.NoError(t, err)
+	require.Len(t, found, 1)
+
+	_, err = issues.MergeIssue(ctx, duplicate.ID, canonical.ID)
+	require.ErrorIs(t, err, ErrValidation)
+}
@@ -0,0 +1,592 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/samhotchkiss/otter-camp/internal/middleware"
+)
+
+// IssueTreeMaxDepth bounds how deep sub-issues may nest below a root issue.
+const IssueTreeMaxDepth = 8
+
+// MaxSubIssuesPerDecompose caps how many children one decompose call creates.
+const MaxSubIssuesPerDecompose = 50
+
+// ErrOpenSubIssues is returned when a project blocks closing a parent issue
+// while any of its direct sub-issues are still open.
+var ErrOpenSubIssues = errors.New("issue has open sub-issues")
+
+// SubIssuePolicy holds a project's rules for how sub-issue state cascades to
+// the parent.
+type SubIssuePolicy struct {
+	BlockParentClose bool `json:"block_parent_close"`
+	AutoCloseParent  bool `json:"auto_close_parent"`
+}
+
+// IssueTreeRollup summarizes every descendant below a tree node.
+type IssueTreeRollup struct {
+	Total           int        `json:"total"`
+	Closed          int        `json:"closed"`
+	PercentComplete int        `json:"percent_complete"`
+	Blocked         int        `json:"blocked"`
+	EarliestDueAt   *time.Time `json:"earliest_due_at,omitempty"`
+}
+
+type IssueTreeNode struct {
+	Issue    ProjectIssue    `json:"issue"`
+	Rollup   IssueTreeRollup `json:"rollup"`
+	Children []IssueTreeNode `json:"children"`
+}
+
+func (s *ProjectIssueStore) GetSubIssuePolicy(ctx context.Context, projectID string) (SubIssuePolicy, error) {
+	if middleware.WorkspaceFromContext(ctx) == "" {
+		return SubIssuePolicy{}, ErrNoWorkspace
+	}
+	projectID = strings.TrimSpace(projectID)
+	if !uuidRegex.MatchString(projectID) {
+		return SubIssuePolicy{}, fmt.Errorf("%w: invalid project_id", ErrValidation)
+	}
+	conn, err := WithWorkspace(ctx, s.db)
+	if err != nil {
+		return SubIssuePolicy{}, err
+	}
+	defer conn.Close()
+	return loadSubIssuePolicy(ctx, conn, projectID)
+}
+
+func (s *ProjectIssueStore) SetSubIssuePolicy(ctx context.Context, projectID string, policy SubIssuePolicy) (SubIssuePolicy, error) {
+	if middleware.WorkspaceFromContext(ctx) == "" {
+		return SubIssuePolicy{}, ErrNoWorkspace
+	}
+	projectID = strings.TrimSpace(projectID)
+	if !uuidRegex.MatchString(projectID) {
+		return SubIssuePolicy{}, fmt.Errorf("%w: invalid project_id", ErrValidation)
+	}
+	conn, err := WithWorkspace(ctx, s.db)
+	if err != nil {
+		return SubIssuePolicy{}, err
+	}
+	defer conn.Close()
+
+	var saved SubIssuePolicy
+	err = conn.QueryRowContext(
+		ctx,
+		`UPDATE projects
+			SET sub_issue_block_parent_close = $2,
+				sub_issue_auto_close_parent = $3
+			WHERE id = $1
+			RETURNING sub_issue_block_parent_close, sub_issue_auto_close_parent`,
+		projectID,
+		policy.BlockParentClose,
+		policy.AutoCloseParent,
+	).Scan(&saved.BlockParentClose, &saved.AutoCloseParent)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return SubIssuePolicy{}, ErrNotFound
+		}
+		return SubIssuePolicy{}, fmt.Errorf("failed to update sub-issue policy: %w", err)
+	}
+	return saved, nil
+}
+
+// AttachSubIssue makes childID a sub-issue of parentID. A child that already
+// has a parent is moved. Both issues must be in the same project, and the
+// move may not create a cycle or nest deeper than IssueTreeMaxDepth.
+func (s *ProjectIssueStore) AttachSubIssue(ctx context.Context, parentID, childID string) (*ProjectIssue, error) {
+	if middleware.WorkspaceFromContext(ctx) == "" {
+		return nil, ErrNoWorkspace
+	}
+	parentID = strings.TrimSpace(parentID)
+	childID = strings.TrimSpace(childID)
+	if !uuidRegex.MatchString(parentID) {
+		return nil, fmt.Errorf("%w: invalid issue_id", ErrValidation)
+	}
+	if !uuidRegex.MatchString(childID) {
+		return nil, fmt.Errorf("%w: invalid child_issue_id", ErrValidation)
+	}
+	if parentID == childID {
+		return nil, fmt.Errorf("%w: an issue cannot be its own sub-issue", ErrValidation)
+	}
+
+	tx, err := WithWorkspaceTx(ctx, s.db)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	if err := lockIssueTree(ctx, tx); err != nil {
+		return nil, err
+	}
+	child, err := loadProjectIssueForUpdate(ctx, tx, childID)
+	if err != nil {
+		return nil, err
+	}
+	if err := ensureSubIssueParent(ctx, tx, child.ProjectID, parentID); err != nil {
+		return nil, err
+	}
+	ancestors, err := subIssueAncestorIDs(ctx, tx, parentID)
+	if err != nil {
+		return nil, err
+	}
+	for _, ancestorID := range ancestors {
+		if ancestorID == childID {
+			return nil, fmt.Errorf("%w: attaching would create a sub-issue cycle", ErrValidation)
+		}
+	}
+	height, err := subIssueSubtreeHeight(ctx, tx, childID)
+	if err != nil {
+		return nil, err
+	}
+	if len(ancestors)+height > IssueTreeMaxDepth {
+		return nil, fmt.Errorf("%w: sub-issues cannot nest more than %d levels", ErrValidation, IssueTreeMaxDepth)
+	}
+
+	updated, err := setProjectIssueParent(ctx, tx, childID, &parentID)
+	if err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return &updated, nil
+}
+
+// DetachSubIssue removes childID from parentID, leaving it a top-level issue.
+func (s *ProjectIssueStore) DetachSubIssue(ctx context.Context, parentID, childID string) (*ProjectIssue, error) {
+	if middleware.WorkspaceFromContext(ctx) == "" {
+		return nil, ErrNoWorkspace
+	}
+	parentID = strings.TrimSpace(parentID)
+	childID = strings.TrimSpace(childID)
+	if !uuidRegex.MatchString(parentID) || !uuidRegex.MatchString(childID) {
+		return nil, fmt.Errorf("%w: invalid issue_id", ErrValidation)
+	}
+
+	tx, err := WithWorkspaceTx(ctx, s.db)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	child, err := loadProjectIssueForUpdate(ctx, tx, childID)
+	if err != nil {
+		return nil, err
+	}
+	if child.ParentIssueID == nil || *child.ParentIssueID != parentID {
+		return nil, ErrNotFound
+	}
+	updated, err := setProjectIssueParent(ctx, tx, childID, nil)
+	if err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return &updated, nil
+}
+
+// CreateSubIssues creates children under parentID in one transaction. Each
+// input inherits the parent's project; any failure creates nothing.
+func (s *ProjectIssueStore) CreateSubIssues(
+	ctx context.Context,
+	parentID string,
+	inputs []CreateProjectIssueInput,
+) ([]ProjectIssue, error) {
+	workspaceID := middleware.WorkspaceFromContext(ctx)
+	if workspaceID == "" {
+		return nil, ErrNoWorkspace
+	}
+	parentID = strings.TrimSpace(parentID)
+	if !uuidRegex.MatchString(parentID) {
+		return nil, fmt.Errorf("%w: invalid issue_id", ErrValidation)
+	}
+	if len(inputs) == 0 {
+		return nil, fmt.Errorf("%w: at least one sub-issue is required", ErrValidation)
+	}
+	if len(inputs) > MaxSubIssuesPerDecompose {
+		return nil, fmt.Errorf("%w: at most %d sub-issues per call", ErrValidation, MaxSubIssuesPerDecompose)
+	}
+
+	tx, err := WithWorkspaceTx(ctx, s.db)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	parent, err := loadProjectIssueForUpdate(ctx, tx, parentID)
+	if err != nil {
+		return nil, err
+	}
+	ancestors, err := subIssueAncestorIDs(ctx, tx, parentID)
+	if err != nil {
+		return nil, err
+	}
+	if len(ancestors) > IssueTreeMaxDepth {
+		return nil, fmt.Errorf("%w: sub-issues cannot nest more than %d levels", ErrValidation, IssueTreeMaxDepth)
+	}
+
+	created := make([]ProjectIssue, 0, len(inputs))
+	for i, input := range inputs {
+		input.ProjectID = parent.ProjectID
+		input.ParentIssueID = &parent.ID
+		normalized, err := normalizeCreateProjectIssueInput(input)
+		if err != nil {
+			return nil, fmt.Errorf("%w: sub-issue %d: %s", ErrValidation, i+1, err.Error())
+		}
+		record, err := insertProjectIssue(ctx, tx, workspaceID, normalized)
+		if err != nil {
+			return nil, err
+		}
+		created = append(created, record)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit sub-issue create: %w", err)
+	}
+	return created, nil
+}
+
+// GetIssueTree loads rootID with every descendant and computes rollups for
+// each node.
+func (s *ProjectIssueStore) GetIssueTree(ctx context.Context, rootID string) (*IssueTreeNode, error) {
+	workspaceID := middleware.WorkspaceFromContext(ctx)
+	if workspaceID == "" {
+		return nil, ErrNoWorkspace
+	}
+	rootID = strings.TrimSpace(rootID)
+	if !uuidRegex.MatchString(rootID) {
+		return nil, fmt.Errorf("%w: invalid issue_id", ErrValidation)
+	}
+
+	conn, err := WithWorkspace(ctx, s.db)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	rows, err := conn.QueryContext(
+		ctx,
+		`WITH RECURSIVE tree AS (
+			SELECT id, 0 AS depth FROM project_issues WHERE id = $1
+			UNION ALL
+			SELECT child.id, tree.depth + 1
+				FROM project_issues child
+				JOIN tree ON child.parent_issue_id = tree.id
+				WHERE tree.depth < $2
+		)
+		SELECT i.id, i.org_id, i.project_id, i.issue_number, i.title, i.body, i.state, i.origin, i.document_path, i.approval_state, i.owner_agent_id, i.work_status, i.priority, i.due_at, i.next_step, i.next_step_due_at, i.flow_template_id, i.flow_step_key, i.flow_step_index, i.parent_issue_id, i.merged_into_issue_id, i.created_at, i.updated_at, i.closed_at
+			FROM project_issues i
+			JOIN tree ON tree.id = i.id
+			ORDER BY tree.depth ASC, i.issue_number ASC`,
+		rootID,
+		IssueTreeMaxDepth,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load issue tree: %w", err)
+	}
+	defer rows.Close()
+
+	issues := make([]ProjectIssue, 0)
+	for rows.Next() {
+		issue, err := scanProjectIssue(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan issue tree: %w", err)
+		}
+		if issue.OrgID != workspaceID {
+			return nil, ErrForbidden
+		}
+		issues = append(issues, issue)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read issue tree: %w", err)
+	}
+	if len(issues) == 0 {
+		return nil, ErrNotFound
+	}
+	tree := BuildIssueTree(issues[0], issues[1:])
+	return &tree, nil
+}
+
+// BuildIssueTree assembles root and its descendants into a tree ordered by
+// issue number and fills in each node's rollup.
+func BuildIssueTree(root ProjectIssue, descendants []ProjectIssue) IssueTreeNode {
+	byParent := make(map[string][]ProjectIssue, len(descendants))
+	for _, issue := range descendants {
+		if issue.ParentIssueID == nil {
+			continue
+		}
+		byParent[*issue.ParentIssueID] = append(byParent[*issue.ParentIssueID], issue)
+	}
+	visited := map[string]bool{}
+	var build func(issue ProjectIssue) IssueTreeNode
+	build = func(issue ProjectIssue) IssueTreeNode {
+		visited[issue.ID] = true
+		node := IssueTreeNode{Issue: issue, Children: []IssueTreeNode{}}
+		for _, child := range byParent[issue.ID] {
+			if visited[child.ID] {
+				continue
+			}
+			node.Children = append(node.Children, build(child))
+		}
+		node.Rollup = rollupIssueTree(node.Children)
+		return node
+	}
+	return build(root)
+}
+
+// rollupIssueTree counts every descendant. Blocked children and the earliest
+// due date only consider issues that are still open.
+func rollupIssueTree(children []IssueTreeNode) IssueTreeRollup {
+	var rollup IssueTreeRollup
+	for _, child := range children {
+		rollup.Total += 1 + child.Rollup.Total
+		rollup.Closed += child.Rollup.Closed
+		rollup.Blocked += child.Rollup.Blocked
+		rollup.EarliestDueAt = earlierTime(rollup.EarliestDueAt, child.Rollup.EarliestDueAt)
+
+		if normalizeIssueState(child.Issue.State) == "closed" {
+			rollup.Closed++
+			continue
+		}
+		if child.Issue.WorkStatus == IssueWorkStatusBlocked {
+			rollup.Blocked++
+		}
+		rollup.EarliestDueAt = earlierTime(rollup.EarliestDueAt, child.Issue.DueAt)
+	}
+	if rollup.Total > 0 {
+		rollup.PercentComplete = rollup.Closed * 100 / rollup.Total
+	}
+	return rollup
+}
+
+func earlierTime(current, candidate *time.Time) *time.Time {
+	if candidate == nil {
+		return current
+	}
+	if current == nil || candidate.Before(*current) {
+		value := *candidate
+		return &value
+	}
+	return current
+}
+
+func loadSubIssuePolicy(ctx context.Context, q Querier, projectID string) (SubIssuePolicy, error) {
+	var policy SubIssuePolicy
+	err := q.QueryRowContext(
+		ctx,
+		`SELECT sub_issue_block_parent_close, sub_issue_auto_close_parent FROM projects WHERE id = $1`,
+		projectID,
+	).Scan(&policy.BlockParentClose, &policy.AutoCloseParent)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return SubIssuePolicy{}, ErrNotFound
+		}
+		return SubIssuePolicy{}, fmt.Errorf("failed to load sub-issue policy: %w", err)
+	}
+	return policy, nil
+}
+
+func loadProjectIssueForUpdate(ctx context.Context, q Querier, issueID string) (ProjectIssue, error) {
+	issue, err := scanProjectIssue(q.QueryRowContext(
+		ctx,
+		`SELECT id, org_id, project_id, issue_number, title, body, state, origin, document_path, approval_state, owner_agent_id, work_status, priority, due_at, next_step, next_step_due_at, flow_template_id, flow_step_key, flow_step_index, parent_issue_id, merged_into_issue_id, created_at, updated_at, closed_at
+			FROM project_issues
+			WHERE id = $1
+			FOR UPDATE`,
+		issueID,
+	))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return ProjectIssue{}, ErrNotFound
+		}
+		return ProjectIssue{}, fmt.Errorf("failed to load issue: %w", err)
+	}
+	return issue, nil
+}
+
+// ensureSubIssueParent checks that parentID is a visible issue in projectID.
+func ensureSubIssueParent(ctx context.Context, q Querier, projectID, parentID string) error {
+	parentID = strings.TrimSpace(parentID)
+	if !uuidRegex.MatchString(parentID) {
+		return fmt.Errorf("%w: invalid parent_issue_id", ErrValidation)
+	}
+	var parentProjectID string
+	err := q.QueryRowContext(ctx, `SELECT project_id FROM project_issues WHERE id = $1`, parentID).Scan(&parentProjectID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrNotFound
+		}
+		return fmt.Errorf("failed to load parent issue: %w", err)
+	}
+	if parentProjectID != projectID {
+		return fmt.Errorf("%w: sub-issues must be in the same project as their parent", ErrValidation)
+	}
+	return nil
+}
+
+// lockIssueTree serializes sub-issue moves in the workspace until tx ends.
+// The cycle and depth checks read rows the move does not lock, so two
+// concurrent moves (A under B, B under A) would otherwise both pass. Take it
+// before any issue row lock so it always comes first.
+func lockIssueTree(ctx context.Context, tx *sql.Tx) error {
+	if _, err := tx.ExecContext(ctx, `SELECT pg_advisory_xact_lock(hashtext('issue_tree:' || current_org_id()::text))`); err != nil {
+		return fmt.Errorf("failed to lock issue tree: %w", err)
+	}
+	return nil
+}
+
+// subIssueAncestorIDs returns issueID followed by its ancestors, nearest
+// first. The walk stops one level past IssueTreeMaxDepth so callers can
+// detect over-deep chains.
+func subIssueAncestorIDs(ctx context.Context, q Querier, issueID string) ([]string, error) {
+	rows, err := q.QueryContext(
+		ctx,
+		`WITH RECURSIVE chain AS (
+			SELECT id, parent_issue_id, 0 AS depth FROM project_issues WHERE id = $1
+			UNION ALL
+			SELECT parent.id, parent.parent_issue_id, chain.depth + 1
+				FROM project_issues parent
+				JOIN chain ON parent.id = chain.parent_issue_id
+				WHERE chain.depth < $2
+		)
+		SELECT id FROM chain ORDER BY depth ASC`,
+		issueID,
+		IssueTreeMaxDepth,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load issue ancestors: %w", err)
+	}
+	defer rows.Close()
+
+	ids := make([]string, 0, 4)
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, fmt.Errorf("failed to scan issue ancestor: %w", err)
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
+
+// subIssueSubtreeHeight returns how many levels of sub-issues sit below
+// issueID (0 for a leaf).
+func subIssueSubtreeHeight(ctx context.Context, q Querier, issueID string) (int, error) {
+	var height int
+	err := q.QueryRowContext(
+		ctx,
+		`WITH RECURSIVE tree AS (
+			SELECT id, 0 AS depth FROM project_issues WHERE id = $1
+			UNION ALL
+			SELECT child.id, tree.depth + 1
+				FROM project_issues child
+				JOIN tree ON child.parent_issue_id = tree.id
+				WHERE tree.depth < $2
+		)
+		SELECT COALESCE(MAX(depth), 0) FROM tree`,
+		issueID,
+		IssueTreeMaxDepth,
+	).Scan(&height)
+	if err != nil {
+		return 0, fmt.Errorf("failed to measure sub-issue depth: %w", err)
+	}
+	return height, nil
+}
+
+func setProjectIssueParent(ctx context.Context, q Querier, issueID string, parentID *string) (ProjectIssue, error) {
+	updated, err := scanProjectIssue(q.QueryRowContext(
+		ctx,
+		`UPDATE project_issues
+			SET parent_issue_id = $2
+			WHERE id = $1
+			RETURNING id, org_id, project_id, issue_number, title, body, state, origin, document_path, approval_state, owner_agent_id, work_status, priority, due_at, next_step, next_step_due_at, flow_template_id, flow_step_key, flow_step_index, parent_issue_id, merged_into_issue_id, created_at, updated_at, closed_at`,
+		issueID,
+		nullableString(parentID),
+	))
+	if err != nil {
+		return ProjectIssue{}, fmt.Errorf("failed to update issue parent: %w", err)
+	}
+	return updated, nil
+}
+
+// checkSubIssueClosePolicy rejects closing an open issue that still has open
+// direct sub-issues when its project enables BlockParentClose.
+func checkSubIssueClosePolicy(ctx context.Context, q Querier, current ProjectIssue, nextState string) error {
+	if nextState != "closed" || normalizeIssueState(current.State) == "closed" {
+		return nil
+	}
+	policy, err := loadSubIssuePolicy(ctx, q, current.ProjectID)
+	if err != nil {
+		return err
+	}
+	if !policy.BlockParentClose {
+		return nil
+	}
+	var open int
+	if err := q.QueryRowContext(
+		ctx,
+		`SELECT COUNT(*) FROM project_issues WHERE parent_issue_id = $1 AND state <> 'closed'`,
+		current.ID,
+	).Scan(&open); err != nil {
+		return fmt.Errorf("failed to count open sub-issues: %w", err)
+	}
+	if open > 0 {
+		return fmt.Errorf("%w (%d still open)", ErrOpenSubIssues, open)
+	}
+	return nil
+}
+
+// closeParentsOfClosedSubIssue walks up from a newly closed issue, closing
+// each parent whose project enables AutoCloseParent once all of its direct
+// sub-issues are closed. It returns the IDs it closed, nearest first.
+func closeParentsOfClosedSubIssue(ctx context.Context, q Querier, closed ProjectIssue) ([]string, error) {
+	if closed.ParentIssueID == nil || normalizeIssueState(closed.State) != "closed" {
+		return nil, nil
+	}
+	policy, err := loadSubIssuePolicy(ctx, q, closed.ProjectID)
+	if err != nil {
+		return nil, err
+	}
+	if !policy.AutoCloseParent {
+		return nil, nil
+	}
+
+	closedIDs := make([]string, 0, 1)
+	parentID := *closed.ParentIssueID
+	for depth := 0; depth < IssueTreeMaxDepth; depth++ {
+		var next sql.NullString
+		err := q.QueryRowContext(
+			ctx,
+			`UPDATE project_issues
+				SET state = 'closed',
+					work_status = $2,
+					closed_at = COALESCE(closed_at, NOW())
+				WHERE id = $1
+					AND state <> 'closed'
+					AND NOT EXISTS (
+						SELECT 1 FROM project_issues child
+						WHERE child.parent_issue_id = $1 AND child.state <> 'closed'
+					)
+				RETURNING parent_issue_id`,
+			parentID,
+			IssueWorkStatusDone,
+		).Scan(&next)
+		if errors.Is(err, sql.ErrNoRows) {
+			break
+		}
+		if err != nil {
+			return closedIDs, fmt.Errorf("failed to auto-close parent issue: %w", err)
+		}
+		closedIDs = append(closedIDs, parentID)
+		if !next.Valid {
+			break
+		}
+		parentID = next.String
+	}
+	return closedIDs, nil
+}
@@ -0,0 +1,133 @@
+package store
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestBuildIssueTreeRollups(t *testing.T) {
+	soon := time.Date(2026, 11, 1, 0, 0, 0, 0, time.UTC)
+	later := soon.AddDate(0, 1, 0)
+	earliest := soon.AddDate(0, 0, -10)
+	ptr := func(value string) *string { return &value }
+
+	root := ProjectIssue{ID: "root", State: "open", WorkStatus: IssueWorkStatusInProgress}
+	tree := BuildIssueTree(root, []ProjectIssue{
+		{ID: "a", ParentIssueID: ptr("root"), State: "open", WorkStatus: IssueWorkStatusBlocked, DueAt: &later},
+		{ID: "b", ParentIssueID: ptr("root"), State: "closed", WorkStatus: IssueWorkStatusDone, DueAt: &earliest},
+		{ID: "a1", ParentIssueID: ptr("a"), State: "open", WorkStatus: IssueWorkStatusQueued, DueAt: &soon},
+		{ID: "a2", ParentIssueID: ptr("a"), State: "closed", WorkStatus: IssueWorkStatusDone},
+		{ID: "orphan", State: "open"},
+	})
+
+	require.Len(t, tree.Children, 2)
+	require.Equal(t, IssueTreeRollup{Total: 4, Closed: 2, PercentComplete: 50, Blocked: 1, EarliestDueAt: &soon}, tree.Rollup)
+
+	a := tree.Children[0]
+	require.Equal(t, "a", a.Issue.ID)
+	require.Equal(t, IssueTreeRollup{Total: 2, Closed: 1, PercentComplete: 50, EarliestDueAt: &soon}, a.Rollup)
+
+	leaf := tree.Children[1]
+	require.Equal(t, IssueTreeRollup{}, leaf.Rollup)
+	require.NotNil(t, leaf.Children)
+}
+
+func TestProjectIssueStoreSubIssueHierarchy(t *testing.T) {
+	connStr := getTestDatabaseURL(t)
+	db := setupTestDatabase(t, connStr)
+	orgID := createTestOrganization(t, db, "sub-issue-org")
+	projectID := createTestProject(t, db, orgID, "Sub Issue Project")
+	otherProjectID := createTestProject(t, db, orgID, "Sub Issue Other")
+	ctx := ctxWithWorkspace(orgID)
+
+	issues := NewProjectIssueStore(db)
+	parent, err := issues.CreateIssue(ctx, CreateProjectIssueInput{ProjectID: projectID, Title: "Epic", Origin: "local"})
+	require.NoError(t, err)
+	foreign, err := issues.CreateIssue(ctx, CreateProjectIssueInput{ProjectID: otherProjectID, Title: "Elsewhere", Origin: "local"})
+	require.NoError(t, err)
+
+	children, err := issues.CreateSubIssues(ctx, parent.ID, []CreateProjectIssueInput{
+		{Title: "Design", Origin: "local"},
+		{Title: "Build", Origin: "local", WorkStatus: IssueWorkStatusBlocked},
+	})
+	require.NoError(t, err)
+	require.Len(t, children, 2)
+	require.Equal(t, parent.ID, *children[0].ParentIssueID)
+
+	_, err = issues.CreateSubIssues(ctx, parent.ID, []CreateProjectIssueInput{{Title: "ok"}, {Title: " "}})
+	require.ErrorIs(t, err, ErrValidation)
+
+	_, err = issues.AttachSubIssue(ctx, parent.ID, foreign.ID)
+	require.ErrorIs(t, err, ErrValidation)
+	_, err = issues.AttachSubIssue(ctx, children[0].ID, parent.ID)
+	require.ErrorIs(t, err, ErrValidation)
+
+	tree, err := issues.GetIssueTree(ctx, parent.ID)
+	require.NoError(t, err)
+	require.Len(t, tree.Children, 2)
+	require.Equal(t, 1, tree.Rollup.Blocked)
+
+	_, err = issues.SetSubIssuePolicy(ctx, projectID, SubIssuePolicy{BlockParentClose: true, AutoCloseParent: true})
+	require.NoError(t, err)
+	_, err = issues.UpdateIssueWorkTracking(ctx, UpdateProjectIssueWorkTrackingInput{IssueID: parent.ID, SetState: true, State: "closed"})
+	require.ErrorIs(t, err, ErrOpenSubIssues)
+
+	detached, err := issues.DetachSubIssue(ctx, parent.ID, children[1].ID)
+	require.NoError(t, err)
+	require.Nil(t, detached.ParentIssueID)
+	_, err = issues.DetachSubIssue(ctx, parent.ID, children[1].ID)
+	require.ErrorIs(t, err, ErrNotFound)
+
+	_, err = issues.UpdateIssueWorkTracking(ctx, UpdateProjectIssueWorkTrackingInput{IssueID: children[0].ID, SetState: true, State: "closed"})
+	require.NoError(t, err)
+	closedParent, err := issues.GetIssueByID(ctx, parent.ID)
+	require.NoError(t, err)
+	require.Equal(t, "closed", closedParent.State)
+	require.Equal(t, IssueWorkStatusDone, closedParent.WorkStatus)
+}
+
+func TestProjectIssueStoreConcurrentAttachesCannotFormCycle(t *testing.T) {
+	connStr := getTestDatabaseURL(t)
+	db := setupTestDatabase(t, connStr)
+	orgID := createTestOrganization(t, db, "sub-issue-race-org")
+	projectID := createTestProject(t, db, orgID, "Sub Issue Race")
+	ctx := ctxWithWorkspace(orgID)
+
+	issues := NewProjectIssueStore(db)
+	a, err := issues.CreateIssue(ctx, CreateProjectIssueInput{ProjectID: projectID, Title: "A", Origin: "local"})
+	require.NoError(t, err)
+	b, err := issues.CreateIssue(ctx, CreateProjectIssueInput{ProjectID: projectID, Title: "B", Origin: "local"})
+	require.NoError(t, err)
+
+	for round := 0; round < 20; round++ {
+		var (
+			wg    sync.WaitGroup
+			start = make(chan struct{})
+			errs  = make([]error, 2)
+		)
+		for i, pair := range [][2]string{{a.ID, b.ID}, {b.ID, a.ID}} {
+			wg.Add(1)
+			go func(i int, parentID, childID string) {
+				defer wg.Done()
+				<-start
+				_, errs[i] = issues.AttachSubIssue(ctx, parentID, childID)
+			}(i, pair[0], pair[1])
+		}
+		close(start)
+		wg.Wait()
+
+		// Exactly one attach wins; the other sees the cycle.
+		if errs[0] == nil {
+			require.ErrorIs(t, errs[1], ErrValidation, "round %d", round)
+			_, err = issues.DetachSubIssue(ctx, a.ID, b.ID)
+		} else {
+			require.ErrorIs(t, errs[0], ErrValidation, "round %d", round)
+			require.NoError(t, errs[1], "round %d", round)
+			_, err = issues.DetachSubIssue(ctx, b.ID, a.ID)
+		}
+		require.NoError(t, err)
+	}
+}
@@ -0,0 +1,648 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/lib/pq"
+	"github.com/samhotchkiss/otter-camp/internal/middleware"
+)
+
+const (
+	IssueReviewThreadStatusOpen     = "open"
+	IssueReviewThreadStatusResolved = "resolved"
+)
+
+// ProjectIssueReviewThread is a review discussion anchored to a line range of
+// the issue's linked document. CommitSHA/StartLine/EndLine are where the
+// reviewer left it; the Anchored* fields follow the lines through later
+// commits.
+type ProjectIssueReviewThread struct {
+	ID                string                            `json:"id"`
+	OrgID             string                            `json:"org_id"`
+	IssueID           string                            `json:"issue_id"`
+	DocumentPath      string                            `json:"document_path"`
+	CommitSHA         string                            `json:"commit_sha"`
+	StartLine         int                               `json:"start_line"`
+	EndLine           int                               `json:"end_line"`
+	AnchoredCommitSHA string                            `json:"anchored_commit_sha"`
+	AnchoredStartLine int                               `json:"anchored_start_line"`
+	AnchoredEndLine   int                               `json:"anchored_end_line"`
+	Outdated          bool                              `json:"outdated"`
+	AuthorAgentID     *string                           `json:"author_agent_id,omitempty"`
+	Status            string                            `json:"status"`
+	ResolvedByAgentID *string                           `json:"resolved_by_agent_id,omitempty"`
+	ResolutionNote    *string                           `json:"resolution_note,omitempty"`
+	ResolvedAt        *time.Time                        `json:"resolved_at,omitempty"`
+	CreatedAt         time.Time                         `json:"created_at"`
+	UpdatedAt         time.Time                         `json:"updated_at"`
+	Comments          []ProjectIssueReviewThreadComment `json:"comments"`
+}
+
+type ProjectIssueReviewThreadComment struct {
+	ID            string    `json:"id"`
+	OrgID         string    `json:"org_id"`
+	ThreadID      string    `json:"thread_id"`
+	AuthorAgentID *string   `json:"author_agent_id,omitempty"`
+	Body          string    `json:"body"`
+	CreatedAt     time.Time `json:"created_at"`
+}
+
+type CreateProjectIssueReviewThreadInput struct {
+	IssueID       string
+	DocumentPath  string
+	CommitSHA     string
+	StartLine     int
+	EndLine       int
+	AuthorAgentID string
+	Body          string
+}
+
+const projectIssueReviewThreadColumns = `id, org_id, issue_id, document_path, commit_sha, start_line, end_line,
+	anchored_commit_sha, anchored_start_line, anchored_end_line, outdated, author_agent_id, status,
+	resolved_by_agent_id, resolution_note, resolved_at, created_at, updated_at`
+
+// CreateReviewThread opens a thread with its first comment.
+func (s *ProjectIssueStore) CreateReviewThread(
+	ctx context.Context,
+	input CreateProjectIssueReviewThreadInput,
+) (*ProjectIssueReviewThread, error) {
+	workspaceID := middleware.WorkspaceFromContext(ctx)
+	if workspaceID == "" {
+		return nil, ErrNoWorkspace
+	}
+
+	issueID := strings.TrimSpace(input.IssueID)
+	if !uuidRegex.MatchString(issueID) {
+		return nil, fmt.Errorf("%w: invalid issue_id", ErrValidation)
+	}
+	documentPath := strings.TrimSpace(input.DocumentPath)
+	normalizedPath, err := normalizeIssueDocumentPath(&documentPath)
+	if err != nil || normalizedPath == nil {
+		return nil, fmt.Errorf("%w: invalid document_path", ErrValidation)
+	}
+	commitSHA := strings.TrimSpace(input.CommitSHA)
+	if commitSHA == "" {
+		return nil, fmt.Errorf("%w: commit_sha is required", ErrValidation)
+	}
+	if input.StartLine <= 0 || input.EndLine < input.StartLine {
+		return nil, fmt.Errorf("%w: start_line and end_line must form a valid range", ErrValidation)
+	}
+	authorAgentID := strings.TrimSpace(input.AuthorAgentID)
+	if !uuidRegex.MatchString(authorAgentID) {
+		return nil, fmt.Errorf("%w: invalid author_agent_id", ErrValidation)
+	}
+	body := strings.TrimSpace(input.Body)
+	if body == "" {
+		return nil, fmt.Errorf("%w: body is required", ErrValidation)
+	}
+
+	tx, err := WithWorkspaceTx(ctx, s.db)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	if err := ensureIssueVisible(ctx, tx, issueID); err != nil {
+		return nil, err
+	}
+	if err := ensureAgentVisible(ctx, tx, authorAgentID); err != nil {
+		return nil, err
+	}
+
+	thread, err := scanProjectIssueReviewThread(tx.QueryRowContext(
+		ctx,
+		`INSERT INTO project_issue_review_threads (
+			org_id, issue_id, document_path, commit_sha, start_line, end_line,
+			anchored_commit_sha, anchored_start_line, anchored_end_line, author_agent_id
+		) VALUES ($1, $2, $3, $4, $5, $6, $4, $5, $6, $7)
+		RETURNING `+projectIssueReviewThreadColumns,
+		workspaceID,
+		issueID,
+		*normalizedPath,
+		commitSHA,
+		input.StartLine,
+		input.EndLine,
+		authorAgentID,
+	))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create review thread: %w", err)
+	}
+	comment, err := insertProjectIssueReviewThreadComment(ctx, tx, workspaceID, thread.ID, authorAgentID, body)
+	if err != nil {
+		return nil, err
+	}
+	thread.Comments = []ProjectIssueReviewThreadComment{comment}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit review thread: %w", err)
+	}
+	return &thread, nil
+}
+
+// ListReviewThreads returns the issue's threads, oldest first, with their
+// comments. status filters to open or resolved threads when set.
+func (s *ProjectIssueStore) ListReviewThreads(
+	ctx context.Context,
+	issueID string,
+	status *string,
+) ([]ProjectIssueReviewThread, error) {
+	workspaceID := middleware.WorkspaceFromContext(ctx)
+	if workspaceID == "" {
+		return nil, ErrNoWorkspace
+	}
+
+	issueID = strings.TrimSpace(issueID)
+	if !uuidRegex.MatchString(issueID) {
+		return nil, fmt.Errorf("%w: invalid issue_id", ErrValidation)
+	}
+	statusFilter := ""
+	if status != nil {
+		statusFilter = strings.ToLower(strings.TrimSpace(*status))
+	}
+	if statusFilter != "" && statusFilter != IssueReviewThreadStatusOpen && statusFilter != IssueReviewThreadStatusResolved {
+		return nil, fmt.Errorf("%w: status must be open or resolved", ErrValidation)
+	}
+
+	conn, err := WithWorkspace(ctx, s.db)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	if err := ensureIssueVisible(ctx, conn, issueID); err != nil {
+		return nil, err
+	}
+
+	rows, err := conn.QueryContext(
+		ctx,
+		`SELECT `+projectIssueReviewThreadColumns+`
+			FROM project_issue_review_threads
+			WHERE issue_id = $1 AND ($2 = '' OR status = $2)
+			ORDER BY created_at ASC, id ASC`,
+		issueID,
+		statusFilter,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list review threads: %w", err)
+	}
+	defer rows.Close()
+
+	threads := make([]ProjectIssueReviewThread, 0)
+	threadIDs := make([]string, 0)
+	for rows.Next() {
+		thread, err := scanProjectIssueReviewThread(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan review thread: %w", err)
+		}
+		if thread.OrgID != workspaceID {
+			return nil, ErrForbidden
+		}
+		threads = append(threads, thread)
+		threadIDs = append(threadIDs, thread.ID)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read review threads: %w", err)
+	}
+	if len(threads) == 0 {
+		return threads, nil
+	}
+
+	commentsByThread, err := loadProjectIssueReviewThreadComments(ctx, conn, threadIDs)
+	if err != nil {
+		return nil, err
+	}
+	for i := range threads {
+		threads[i].Comments = commentsByThread[threads[i].ID]
+		if threads[i].Comments == nil {
+			threads[i].Comments = []ProjectIssueReviewThreadComment{}
+		}
+	}
+	return threads, nil
+}
+
+// GetReviewThread loads one thread of the issue with its comments.
+func (s *ProjectIssueStore) GetReviewThread(
+	ctx context.Context,
+	issueID string,
+	threadID string,
+) (*ProjectIssueReviewThread, error) {
+	workspaceID := middleware.WorkspaceFromContext(ctx)
+	if workspaceID == "" {
+		return nil, ErrNoWorkspace
+	}
+	issueID = strings.TrimSpace(issueID)
+	threadID = strings.TrimSpace(threadID)
+	if !uuidRegex.MatchString(issueID) || !uuidRegex.MatchString(threadID) {
+		return nil, ErrNotFound
+	}
+
+	conn, err := WithWorkspace(ctx, s.db)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	thread, err := loadProjectIssueReviewThread(ctx, conn, issueID, threadID, false)
+	if err != nil {
+		return nil, err
+	}
+	if thread.OrgID != workspaceID {
+		return nil, ErrForbidden
+	}
+	commentsByThread, err := loadProjectIssueReviewThreadComments(ctx, conn, []string{thread.ID})
+	if err != nil {
+		return nil, err
+	}
+	thread.Comments = commentsByThread[thread.ID]
+	if thread.Comments == nil {
+		thread.Comments = []ProjectIssueReviewThreadComment{}
+	}
+	return &thread, nil
+}
+
+// AddReviewThreadComment appends a reply to a thread. Replies are allowed on
+// resolved threads; they do not reopen them.
+func (s *ProjectIssueStore) AddReviewThreadComment(
+	ctx context.Context,
+	issueID string,
+	threadID string,
+	authorAgentID string,
+	body string,
+) (*ProjectIssueReviewThreadComment, error) {
+	workspaceID := middleware.WorkspaceFromContext(ctx)
+	if workspaceID == "" {
+		return nil, ErrNoWorkspace
+	}
+	issueID = strings.TrimSpace(issueID)
+	threadID = strings.TrimSpace(threadID)
+	if !uuidRegex.MatchString(issueID) || !uuidRegex.MatchString(threadID) {
+		return nil, ErrNotFound
+	}
+	authorAgentID = strings.TrimSpace(authorAgentID)
+	if !uuidRegex.MatchString(authorAgentID) {
+		return nil, fmt.Errorf("%w: invalid author_agent_id", ErrValidation)
+	}
+	body = strings.TrimSpace(body)
+	if body == "" {
+		return nil, fmt.Errorf("%w: body is required", ErrValidation)
+	}
+
+	tx, err := WithWorkspaceTx(ctx, s.db)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	thread, err := loadProjectIssueReviewThread(ctx, tx, issueID, threadID, true)
+	if err != nil {
+		return nil, err
+	}
+	if thread.OrgID != workspaceID {
+		return nil, ErrForbidden
+	}
+	if err := ensureAgentVisible(ctx, tx, authorAgentID); err != nil {
+		return nil, err
+	}
+	comment, err := insertProjectIssueReviewThreadComment(ctx, tx, workspaceID, thread.ID, authorAgentID, body)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := tx.ExecContext(ctx, `UPDATE project_issue_review_threads SET updated_at = NOW() WHERE id = $1`, thread.ID); err != nil {
+		return nil, fmt.Errorf("failed to touch review thread: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit review thread comment: %w", err)
+	}
+	return &comment, nil
+}
+
+// SetReviewThreadResolved resolves or reopens a thread. Resolving records the
+// agent and an optional note; reopening clears them.
+func (s *ProjectIssueStore) SetReviewThreadResolved(
+	ctx context.Context,
+	issueID string,
+	threadID string,
+	resolved bool,
+	agentID string,
+	note *string,
+) (*ProjectIssueReviewThread, error) {
+	workspaceID := middleware.WorkspaceFromContext(ctx)
+	if workspaceID == "" {
+		return nil, ErrNoWorkspace
+	}
+	issueID = strings.TrimSpace(issueID)
+	threadID = strings.TrimSpace(threadID)
+	if !uuidRegex.MatchString(issueID) || !uuidRegex.MatchString(threadID) {
+		return nil, ErrNotFound
+	}
+	agentID = strings.TrimSpace(agentID)
+	if resolved && !uuidRegex.MatchString(agentID) {
+		return nil, fmt.Errorf("%w: invalid agent_id", ErrValidation)
+	}
+	var resolutionNote *string
+	if note != nil && strings.TrimSpace(*note) != "" {
+		trimmed := strings.TrimSpace(*note)
+		resolutionNote = &trimmed
+	}
+
+	tx, err := WithWorkspaceTx(ctx, s.db)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	thread, err := loadProjectIssueReviewThread(ctx, tx, issueID, threadID, true)
+	if err != nil {
+		return nil, err
+	}
+	if thread.OrgID != workspaceID {
+		return nil, ErrForbidden
+	}
+
+	var updated ProjectIssueReviewThread
+	if resolved {
+		if err := ensureAgentVisible(ctx, tx, agentID); err != nil {
+			return nil, err
+		}
+		updated, err = scanProjectIssueReviewThread(tx.QueryRowContext(
+			ctx,
+			`UPDATE project_issue_review_threads
+				SET status = 'resolved',
+					resolved_by_agent_id = $2,
+					resolution_note = $3,
+					resolved_at = NOW()
+				WHERE id = $1
+				RETURNING `+projectIssueReviewThreadColumns,
+			thread.ID,
+			agentID,
+			resolutionNote,
+		))
+	} else {
+		updated, err = scanProjectIssueReviewThread(tx.QueryRowContext(
+			ctx,
+			`UPDATE project_issue_review_threads
+				SET status = 'open',
+					resolved_by_agent_id = NULL,
+					resolution_note = NULL,
+					resolved_at = NULL
+				WHERE id = $1
+				RETURNING `+projectIssueReviewThreadColumns,
+			thread.ID,
+		))
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to update review thread: %w", err)
+	}
+
+	commentsByThread, err := loadProjectIssueReviewThreadComments(ctx, tx, []string{updated.ID})
+	if err != nil {
+		return nil, err
+	}
+	updated.Comments = commentsByThread[updated.ID]
+	if updated.Comments == nil {
+		updated.Comments = []ProjectIssueReviewThreadComment{}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit review thread update: %w", err)
+	}
+	return &updated, nil
+}
+
+// UpdateReviewThreadAnchor records where a thread's lines sit as of
+// commitSHA. An outdated thread keeps its last known position.
+func (s *ProjectIssueStore) UpdateReviewThreadAnchor(
+	ctx context.Context,
+	threadID string,
+	commitSHA string,
+	startLine int,
+	endLine int,
+	outdated bool,
+) error {
+	workspaceID := middleware.WorkspaceFromContext(ctx)
+	if workspaceID == "" {
+		return ErrNoWorkspace
+	}
+	threadID = strings.TrimSpace(threadID)
+	if !uuidRegex.MatchString(threadID) {
+		return fmt.Errorf("%w: invalid thread_id", ErrValidation)
+	}
+	commitSHA = strings.TrimSpace(commitSHA)
+	if commitSHA == "" {
+		return fmt.Errorf("%w: commit_sha is required", ErrValidation)
+	}
+	if startLine <= 0 || endLine < startLine {
+		return fmt.Errorf("%w: start_line and end_line must form a valid range", ErrValidation)
+	}
+
+	conn, err := WithWorkspace(ctx, s.db)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	result, err := conn.ExecContext(
+		ctx,
+		`UPDATE project_issue_review_threads
+			SET anchored_commit_sha = $2,
+				anchored_start_line = $3,
+				anchored_end_line = $4,
+				outdated = $5
+			WHERE id = $1`,
+		threadID,
+		commitSHA,
+		startLine,
+		endLine,
+		outdated,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to update review thread anchor: %w", err)
+	}
+	if affected, err := result.RowsAffected(); err == nil && affected == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// CountOpenReviewThreads returns how many of the issue's threads are still
+// open.
+func (s *ProjectIssueStore) CountOpenReviewThreads(ctx context.Context, issueID string) (int, error) {
+	workspaceID := middleware.WorkspaceFromContext(ctx)
+	if workspaceID == "" {
+		return 0, ErrNoWorkspace
+	}
+	issueID = strings.TrimSpace(issueID)
+	if !uuidRegex.MatchString(issueID) {
+		return 0, fmt.Errorf("%w: invalid issue_id", ErrValidation)
+	}
+
+	conn, err := WithWorkspace(ctx, s.db)
+	if err != nil {
+		return 0, err
+	}
+	defer conn.Close()
+
+	var count int
+	if err := conn.QueryRowContext(
+		ctx,
+		`SELECT COUNT(*) FROM project_issue_review_threads WHERE issue_id = $1 AND status = 'open'`,
+		issueID,
+	).Scan(&count); err != nil {
+		return 0, fmt.Errorf("failed to count open review threads: %w", err)
+	}
+	return count, nil
+}
+
+func loadProjectIssueReviewThread(
+	ctx context.Context,
+	q Querier,
+	issueID string,
+	threadID string,
+	forUpdate bool,
+) (ProjectIssueReviewThread, error) {
+	query := `SELECT ` + projectIssueReviewThreadColumns + `
+		FROM project_issue_review_threads
+		WHERE id = $1 AND issue_id = $2`
+	if forUpdate {
+		query += ` FOR UPDATE`
+	}
+	thread, err := scanProjectIssueReviewThread(q.QueryRowContext(ctx, query, threadID, issueID))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return thread, ErrNotFound
+		}
+		return thread, fmt.Errorf("failed to load review thread: %w", err)
+	}
+	return thread, nil
+}
+
+func loadProjectIssueReviewThreadComments(
+	ctx context.Context,
+	q Querier,
+	threadIDs []string,
+) (map[string][]ProjectIssueReviewThreadComment, error) {
+	rows, err := q.QueryContext(
+		ctx,
+		`SELECT id, org_id, thread_id, author_agent_id, body, created_at
+			FROM project_issue_review_thread_comments
+			WHERE thread_id = ANY($1::uuid[])
+			ORDER BY created_at ASC, id ASC`,
+		pq.Array(threadIDs),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list review thread comments: %w", err)
+	}
+	defer rows.Close()
+
+	out := make(map[string][]ProjectIssueReviewThreadComment, len(threadIDs))
+	for rows.Next() {
+		comment, err := scanProjectIssueReviewThreadComment(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan review thread comment: %w", err)
+		}
+		out[comment.ThreadID] = append(out[comment.ThreadID], comment)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read review thread comments: %w", err)
+	}
+	return out, nil
+}
+
+func insertProjectIssueReviewThreadComment(
+	ctx context.Context,
+	q Querier,
+	workspaceID string,
+	threadID string,
+	authorAgentID string,
+	body string,
+) (ProjectIssueReviewThreadComment, error) {
+	comment, err := scanProjectIssueReviewThreadComment(q.QueryRowContext(
+		ctx,
+		`INSERT INTO project_issue_review_thread_comments (org_id, thread_id, author_agent_id, body)
+			VALUES ($1, $2, $3, $4)
+			RETURNING id, org_id, thread_id, author_agent_id, body, created_at`,
+		workspaceID,
+		threadID,
+		authorAgentID,
+		body,
+	))
+	if err != nil {
+		return comment, fmt.Errorf("failed to create review thread comment: %w", err)
+	}
+	return comment, nil
+}
+
+func scanProjectIssueReviewThread(
+	scanner interface{ Scan(...any) error },
+) (ProjectIssueReviewThread, error) {
+	var thread ProjectIssueReviewThread
+	var authorAgentID sql.NullString
+	var resolvedByAgentID sql.NullString
+	var resolutionNote sql.NullString
+	var resolvedAt sql.NullTime
+
+	err := scanner.Scan(
+		&thread.ID,
+		&thread.OrgID,
+		&thread.IssueID,
+		&thread.DocumentPath,
+		&thread.CommitSHA,
+		&thread.StartLine,
+		&thread.EndLine,
+		&thread.AnchoredCommitSHA,
+		&thread.AnchoredStartLine,
+		&thread.AnchoredEndLine,
+		&thread.Outdated,
+		&authorAgentID,
+		&thread.Status,
+		&resolvedByAgentID,
+		&resolutionNote,
+		&resolvedAt,
+		&thread.CreatedAt,
+		&thread.UpdatedAt,
+	)
+	if err != nil {
+		return thread, err
+	}
+	if authorAgentID.Valid {
+		thread.AuthorAgentID = &authorAgentID.String
+	}
+	if resolvedByAgentID.Valid {
+		thread.ResolvedByAgentID = &resolvedByAgentID.String
+	}
+	if resolutionNote.Valid {
+		thread.ResolutionNote = &resolutionNote.String
+	}
+	if resolvedAt.Valid {
+		thread.ResolvedAt = &resolvedAt.Time
+	}
+	return thread, nil
+}
+
+func scanProjectIssueReviewThreadComment(
+	scanner interface{ Scan(...any) error },
+) (ProjectIssueReviewThreadComment, error) {
+	var comment ProjectIssueReviewThreadComment
+	var authorAgentID sql.NullString
+	err := scanner.Scan(
+		&comment.ID,
+		&comment.OrgID,
+		&comment.ThreadID,
+		&authorAgentID,
+		&comment.Body,
+		&comment.CreatedAt,
+	)
+	if err != nil {
+		return comment, err
+	}
+	if authorAgentID.Valid {
+		comment.AuthorAgentID = &authorAgentID.String
+	}
+	return comment, nil
+}
@@ -33,6 +33,8 @@ type ProjectIssue struct {
 	FlowTemplateID *string    `json:"flow_template_id,omitempty"`
 	FlowStepKey    *string    `json:"flow_step_key,omitempty"`
 	FlowStepIndex  *int       `json:"flow_step_index,omitempty"`
+	ParentIssueID  *string    `json:"parent_issue_id,omitempty"`
+	MergedIntoID   *string    `json:"merged_into_issue_id,omitempty"`
 	CreatedAt      time.Time  `json:"created_at"`
 	UpdatedAt      time.Time  `json:"updated_at"`
 	ClosedAt       *time.Time `json:"closed_at,omitempty"`
@@ -55,6 +57,7 @@ type CreateProjectIssueInput struct {
 	FlowTemplateID *string
 	FlowStepKey    *string
 	FlowStepIndex  *int
+	ParentIssueID  *string
 	ClosedAt       *time.Time
 }
 
@@ -443,14 +446,39 @@ func (s *ProjectIssueStore) CreateIssue(ctx context.Context, input CreateProject
 	if workspaceID == "" {
 		return nil, ErrNoWorkspace
 	}
+	normalized, err := normalizeCreateProjectIssueInput(input)
+	if err != nil {
+		return nil, err
+	}
+
+	tx, err := WithWorkspaceTx(ctx, s.db)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	record, err := insertProjectIssue(ctx, tx, workspaceID, normalized)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit issue create: %w", err)
+	}
+	return &record, nil
+}
+
+// normalizeCreateProjectIssueInput validates a create request and fills in
+// the state, origin, approval, work status and priority defaults.
+func normalizeCreateProjectIssueInput(input CreateProjectIssueInput) (CreateProjectIssueInput, error) {
 	projectID := strings.TrimSpace(input.ProjectID)
 	if !uuidRegex.MatchString(projectID) {
-		return nil, fmt.Errorf("invalid project_id")
+		return CreateProjectIssueInput{}, fmt.Errorf("invalid project_id")
 	}
 
 	title := strings.TrimSpace(input.Title)
 	if title == "" {
-		return nil, fmt.Errorf("title is required")
+		return CreateProjectIssueInput{}, fmt.Errorf("title is required")
 	}
 
 	state := normalizeIssueState(input.State)
@@ -458,7 +486,7 @@ func (s *ProjectIssueStore) CreateIssue(ctx context.Context, input CreateProject
 		state = "open"
 	}
 	if !isValidIssueState(state) {
-		return nil, fmt.Errorf("invalid state")
+		return CreateProjectIssueInput{}, fmt.Errorf("invalid state")
 	}
 
 	origin := strings.TrimSpace(strings.ToLower(input.Origin))
@@ -466,12 +494,12 @@ func (s *ProjectIssueStore) CreateIssue(ctx context.Context, input CreateProject
 		origin = "local"
 	}
 	if origin != "local" && origin != "github" {
-		return nil, fmt.Errorf("origin must be local or github")
+		return CreateProjectIssueInput{}, fmt.Errorf("origin must be local or github")
 	}
 
 	documentPath, err := normalizeIssueDocumentPath(input.DocumentPath)
 	if err != nil {
-		return nil, err
+		return CreateProjectIssueInput{}, err
 	}
 
 	approvalState := normalizeIssueApprovalState(input.ApprovalState)
@@ -479,12 +507,12 @@ func (s *ProjectIssueStore) CreateIssue(ctx context.Context, input CreateProject
 		approvalState = defaultApprovalStateForLegacyState(state)
 	}
 	if !isValidIssueApprovalState(approvalState) {
-		return nil, fmt.Errorf("invalid approval_state")
+		return CreateProjectIssueInput{}, fmt.Errorf("invalid approval_state")
 	}
 
 	ownerAgentID, err := normalizeOptionalIssueAgentID(input.OwnerAgentID)
 	if err != nil {
-		return nil, err
+		return CreateProjectIssueInput{}, err
 	}
 
 	workStatus := normalizeIssueWorkStatus(input.WorkStatus)
@@ -492,10 +520,10 @@ func (s *ProjectIssueStore) CreateIssue(ctx context.Context, input CreateProject
 		workStatus = defaultIssueWorkStatusForState(state)
 	}
 	if !isValidIssueWorkStatus(workStatus) {
-		return nil, fmt.Errorf("invalid work_status")
+		return CreateProjectIssueInput{}, fmt.Errorf("invalid work_status")
 	}
 	if state == "closed" && workStatus != IssueWorkStatusDone && workStatus != IssueWorkStatusCancelled {
-		return nil, fmt.Errorf("closed issues require done or cancelled work_status")
+		return CreateProjectIssueInput{}, fmt.Errorf("closed issues require done or cancelled work_status")
 	}
 
 	priority := normalizeIssuePriority(input.Priority)
@@ -503,76 +531,90 @@ func (s *ProjectIssueStore) CreateIssue(ctx context.Context, input CreateProject
 		priority = IssuePriorityP2
 	}
 	if !isValidIssuePriority(priority) {
-		return nil, fmt.Errorf("invalid priority")
+		return CreateProjectIssueInput{}, fmt.Errorf("invalid priority")
 	}
 
 	nextStep := normalizeOptionalIssueText(input.NextStep)
 	nextStepDueAt := input.NextStepDueAt
 	if nextStepDueAt != nil && nextStep == nil {
-		return nil, fmt.Errorf("next_step is required when next_step_due_at is set")
-	}
-
-	tx, err := WithWorkspaceTx(ctx, s.db)
-	if err != nil {
-		return nil, err
-	}
-	defer func() { _ = tx.Rollback() }()
-
-	if err := ensureProjectVisible(ctx, tx, projectID); err != nil {
-		return nil, err
+		return CreateProjectIssueInput{}, fmt.Errorf("next_step is required when next_step_due_at is set")
+	}
+
+	normalized := input
+	normalized.ProjectID = projectID
+	normalized.Title = title
+	normalized.State = state
+	normalized.Origin = origin
+	normalized.DocumentPath = documentPath
+	normalized.ApprovalState = approvalState
+	normalized.OwnerAgentID = ownerAgentID
+	normalized.WorkStatus = workStatus
+	normalized.Priority = priority
+	normalized.NextStep = nextStep
+	normalized.NextStepDueAt = nextStepDueAt
+	return normalized, nil
+}
+
+// insertProjectIssue allocates the next issue number and inserts a
+// normalized issue inside q.
+func insertProjectIssue(ctx context.Context, q Querier, workspaceID string, input CreateProjectIssueInput) (ProjectIssue, error) {
+	if err := ensureProjectVisible(ctx, q, input.ProjectID); err != nil {
+		return ProjectIssue{}, err
+	}
+	if input.OwnerAgentID != nil {
+		if err := ensureAgentVisible(ctx, q, *input.OwnerAgentID); err != nil {
+			return ProjectIssue{}, err
+		}
 	}
-	if ownerAgentID != nil {
-		if err := ensureAgentVisible(ctx, tx, *ownerAgentID); err != nil {
-			return nil, err
+	if input.ParentIssueID != nil {
+		if err := ensureSubIssueParent(ctx, q, input.ProjectID, *input.ParentIssueID); err != nil {
+			return ProjectIssue{}, err
 		}
 	}
 
 	var nextIssueNumber int64
-	if err := tx.QueryRowContext(
+	if err := q.QueryRowContext(
 		ctx,
 		`SELECT COALESCE(MAX(issue_number), 0) + 1 FROM project_issues WHERE project_id = $1`,
-		projectID,
+		input.ProjectID,
 	).Scan(&nextIssueNumber); err != nil {
-		return nil, fmt.Errorf("failed to allocate issue number: %w", err)
+		return ProjectIssue{}, fmt.Errorf("failed to allocate issue number: %w", err)
 	}
 
-	record, err := scanProjectIssue(tx.QueryRowContext(
+	record, err := scanProjectIssue(q.QueryRowContext(
 		ctx,
 		`INSERT INTO project_issues (
 			org_id, project_id, issue_number, title, body, state, origin, document_path, approval_state,
 			owner_agent_id, work_status, priority, due_at, next_step, next_step_due_at,
 			flow_template_id, flow_step_key, flow_step_index,
-			closed_at
+			closed_at, parent_issue_id
 		) VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9,$10,$11,$12,$13,$14,$15,$16,$17,$18,$19,$20)
-		RETURNING id, org_id, project_id, issue_number, title, body, state, origin, document_path, approval_state, owner_agent_id, work_status, priority, due_at, next_step, next_step_due_at, flow_template_id, flow_step_key, flow_step_index, created_at, updated_at, closed_at`,
+		RETURNING id, org_id, project_id, issue_number, title, body, state, origin, document_path, approval_state, owner_agent_id, work_status, priority, due_at, next_step, next_step_due_at, flow_template_id, flow_step_key, flow_step_index, parent_issue_id, merged_into_issue_id, created_at, updated_at, closed_at`,
 		workspaceID,
-		projectID,
+		input.ProjectID,
 		nextIssueNumber,
-		title,
+		input.Title,
 		nullableString(input.Body),
-		state,
-		origin,
-		nullableString(documentPath),
-		approvalState,
-		nullableString(ownerAgentID),
-		workStatus,
-		priority,
+		input.State,
+		input.Origin,
+		nullableString(input.DocumentPath),
+		input.ApprovalState,
+		nullableString(input.OwnerAgentID),
+		input.WorkStatus,
+		input.Priority,
 		input.DueAt,
-		nullableString(nextStep),
-		nextStepDueAt,
+		nullableString(input.NextStep),
+		input.NextStepDueAt,
 		nullableString(input.FlowTemplateID),
 		nullableString(input.FlowStepKey),
 		nullableInt32(input.FlowStepIndex),
 		input.ClosedAt,
+		nullableString(input.ParentIssueID),
 	))
 	if err != nil {
-		return nil, fmt.Errorf("failed to create issue: %w", err)
-	}
-
-	if err := tx.Commit(); err != nil {
-		return nil, fmt.Errorf("failed to commit issue create: %w", err)
+		return ProjectIssue{}, fmt.Errorf("failed to create issue: %w", err)
 	}
-	return &record, nil
+	return record, nil
 }
 
 func (s *ProjectIssueStore) TransitionApprovalState(
@@ -603,7 +645,7 @@ func (s *ProjectIssueStore) TransitionApprovalState(
 
 	current, err := scanProjectIssue(tx.QueryRowContext(
 		ctx,
-		`SELECT id, org_id, project_id, issue_number, title, body, state, origin, document_path, approval_state, owner_agent_id, work_status, priority, due_at, next_step, next_step_due_at, flow_template_id, flow_step_key, flow_step_index, created_at, updated_at, closed_at
+		`SELECT id, org_id, project_id, issue_number, title, body, state, origin, document_path, approval_state, owner_agent_id, work_status, priority, due_at, next_step, next_step_due_at, flow_template_id, flow_step_key, flow_step_index, parent_issue_id, merged_into_issue_id, created_at, updated_at, closed_at
 			FROM project_issues
 			WHERE id = $1
 			FOR UPDATE`,
@@ -632,7 +674,7 @@ func (s *ProjectIssueStore) TransitionApprovalState(
 		updateQuery := `UPDATE project_issues
 					SET approval_state = $2
 					WHERE id = $1
-					RETURNING id, org_id, project_id, issue_number, title, body, state, origin, document_path, approval_state, owner_agent_id, work_status, priority, due_at, next_step, next_step_due_at, flow_template_id, flow_step_key, flow_step_index, created_at, updated_at, closed_at`
+					RETURNING id, org_id, project_id, issue_number, title, body, state, origin, document_path, approval_state, owner_agent_id, work_status, priority, due_at, next_step, next_step_due_at, flow_template_id, flow_step_key, flow_step_index, parent_issue_id, merged_into_issue_id, created_at, updated_at, closed_at`
 		if normalizedNext == IssueApprovalStateApproved {
 			updateQuery = `UPDATE project_issues
 					SET approval_state = $2,
@@ -640,7 +682,7 @@ func (s *ProjectIssueStore) TransitionApprovalState(
 						work_status = CASE WHEN work_status = '` + IssueWorkStatusCancelled + `' THEN work_status ELSE '` + IssueWorkStatusDone + `' END,
 						closed_at = COALESCE(closed_at, NOW())
 					WHERE id = $1
-					RETURNING id, org_id, project_id, issue_number, title, body, state, origin, document_path, approval_state, owner_agent_id, work_status, priority, due_at, next_step, next_step_due_at, flow_template_id, flow_step_key, flow_step_index, created_at, updated_at, closed_at`
+					RETURNING id, org_id, project_id, issue_number, title, body, state, origin, document_path, approval_state, owner_agent_id, work_status, priority, due_at, next_step, next_step_due_at, flow_template_id, flow_step_key, flow_step_index, parent_issue_id, merged_into_issue_id, created_at, updated_at, closed_at`
 		}
 
 		updated, err = scanProjectIssue(tx.QueryRowContext(
@@ -688,7 +730,7 @@ func (s *ProjectIssueStore) TransitionWorkStatus(
 
 	current, err := scanProjectIssue(tx.QueryRowContext(
 		ctx,
-		`SELECT id, org_id, project_id, issue_number, title, body, state, origin, document_path, approval_state, owner_agent_id, work_status, priority, due_at, next_step, next_step_due_at, flow_template_id, flow_step_key, flow_step_index, created_at, updated_at, closed_at
+		`SELECT id, org_id, project_id, issue_number, title, body, state, origin, document_path, approval_state, owner_agent_id, work_status, priority, due_at, next_step, next_step_due_at, flow_template_id, flow_step_key, flow_step_index, parent_issue_id, merged_into_issue_id, created_at, updated_at, closed_at
 			FROM project_issues
 			WHERE id = $1
 			FOR UPDATE`,
@@ -731,7 +773,7 @@ func (s *ProjectIssueStore) TransitionWorkStatus(
 				ELSE $4
 			END
 		WHERE id = $1
-		RETURNING id, org_id, project_id, issue_number, title, body, state, origin, document_path, approval_state, owner_agent_id, work_status, priority, due_at, next_step, next_step_due_at, flow_template_id, flow_step_key, flow_step_index, created_at, updated_at, closed_at`
+		RETURNING id, org_id, project_id, issue_number, title, body, state, origin, document_path, approval_state, owner_agent_id, work_status, priority, due_at, next_step, next_step_due_at, flow_template_id, flow_step_key, flow_step_index, parent_issue_id, merged_into_issue_id, created_at, updated_at, closed_at`
 	updated, err := scanProjectIssue(tx.QueryRowContext(
 		ctx,
 		updateQuery,
@@ -772,7 +814,7 @@ func (s *ProjectIssueStore) UpdateIssueWorkTracking(
 
 	current, err := scanProjectIssue(tx.QueryRowContext(
 		ctx,
-		`SELECT id, org_id, project_id, issue_number, title, body, state, origin, document_path, approval_state, owner_agent_id, work_status, priority, due_at, next_step, next_step_due_at, flow_template_id, flow_step_key, flow_step_index, created_at, updated_at, closed_at
+		`SELECT id, org_id, project_id, issue_number, title, body, state, origin, document_path, approval_state, owner_agent_id, work_status, priority, due_at, next_step, next_step_due_at, flow_template_id, flow_step_key, flow_step_index, parent_issue_id, merged_into_issue_id, created_at, updated_at, closed_at
 			FROM project_issues
 			WHERE id = $1
 			FOR UPDATE`,
@@ -788,15 +830,55 @@ func (s *ProjectIssueStore) UpdateIssueWorkTracking(
 		return nil, ErrForbidden
 	}
 
+	next, err := planIssueWorkTracking(ctx, tx, current, input)
+	if err != nil {
+		return nil, err
+	}
+	updated, err := writeIssueWorkTracking(ctx, tx, issueID, next)
+	if err != nil {
+		return nil, err
+	}
+	if normalizeIssueState(current.State) != "closed" {
+		if _, err := closeParentsOfClosedSubIssue(ctx, tx, updated); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return &updated, nil
+}
+
+// issueWorkTrackingChange is the resolved set of work-tracking columns an
+// update writes back.
+type issueWorkTrackingChange struct {
+	OwnerAgentID  *string
+	WorkStatus    string
+	Priority      string
+	DueAt         *time.Time
+	NextStep      *string
+	NextStepDueAt *time.Time
+	State         string
+}
+
+// planIssueWorkTracking applies input to current, enforcing the work status
+// transition rules and keeping state and work_status consistent.
+func planIssueWorkTracking(
+	ctx context.Context,
+	q Querier,
+	current ProjectIssue,
+	input UpdateProjectIssueWorkTrackingInput,
+) (issueWorkTrackingChange, error) {
 	nextOwnerAgentID := current.OwnerAgentID
 	if input.SetOwnerAgentID {
 		normalizedOwner, err := normalizeOptionalIssueAgentID(input.OwnerAgentID)
 		if err != nil {
-			return nil, err
+			return issueWorkTrackingChange{}, err
 		}
 		if normalizedOwner != nil {
-			if err := ensureAgentVisible(ctx, tx, *normalizedOwner); err != nil {
-				return nil, err
+			if err := ensureAgentVisible(ctx, q, *normalizedOwner); err != nil {
+				return issueWorkTrackingChange{}, err
 			}
 		}
 		nextOwnerAgentID = normalizedOwner
@@ -810,10 +892,10 @@ func (s *ProjectIssueStore) UpdateIssueWorkTracking(
 	if input.SetWorkStatus {
 		normalizedWorkStatus := normalizeIssueWorkStatus(input.WorkStatus)
 		if !isValidIssueWorkStatus(normalizedWorkStatus) {
-			return nil, fmt.Errorf("invalid work_status")
+			return issueWorkTrackingChange{}, fmt.Errorf("invalid work_status")
 		}
 		if !canTransitionIssueWorkStatus(currentWorkStatus, normalizedWorkStatus) {
-			return nil, fmt.Errorf("invalid work_status transition")
+			return issueWorkTrackingChange{}, fmt.Errorf("invalid work_status transition")
 		}
 		nextWorkStatus = normalizedWorkStatus
 	}
@@ -825,7 +907,7 @@ func (s *ProjectIssueStore) UpdateIssueWorkTracking(
 	if input.SetPriority {
 		normalizedPriority := normalizeIssuePriority(input.Priority)
 		if !isValidIssuePriority(normalizedPriority) {
-			return nil, fmt.Errorf("invalid priority")
+			return issueWorkTrackingChange{}, fmt.Errorf("invalid priority")
 		}
 		nextPriority = normalizedPriority
 	}
@@ -846,7 +928,7 @@ func (s *ProjectIssueStore) UpdateIssueWorkTracking(
 	}
 
 	if nextNextStepDueAt != nil && nextNextStep == nil {
-		return nil, fmt.Errorf("next_step is required when next_step_due_at is set")
+		return issueWorkTrackingChange{}, fmt.Errorf("next_step is required when next_step_due_at is set")
 	}
 
 	nextState := normalizeIssueState(current.State)
@@ -857,7 +939,7 @@ func (s *ProjectIssueStore) UpdateIssueWorkTracking(
 	if input.SetState {
 		normalizedState := normalizeIssueState(input.State)
 		if !isValidIssueState(normalizedState) {
-			return nil, fmt.Errorf("invalid state")
+			return issueWorkTrackingChange{}, fmt.Errorf("invalid state")
 		}
 		nextState = normalizedState
 	}
@@ -867,21 +949,36 @@ func (s *ProjectIssueStore) UpdateIssueWorkTracking(
 	}
 	if nextState == "open" && (nextWorkStatus == IssueWorkStatusDone || nextWorkStatus == IssueWorkStatusCancelled) {
 		if currentWorkStatus != nextWorkStatus {
-			return nil, fmt.Errorf("open issues cannot use terminal work_status")
+			return issueWorkTrackingChange{}, fmt.Errorf("open issues cannot use terminal work_status")
 		}
 		if !canTransitionIssueWorkStatus(currentWorkStatus, IssueWorkStatusQueued) {
-			return nil, fmt.Errorf("invalid work_status transition")
+			return issueWorkTrackingChange{}, fmt.Errorf("invalid work_status transition")
 		}
 		nextWorkStatus = IssueWorkStatusQueued
 	}
 	if nextState == "closed" && nextWorkStatus != IssueWorkStatusDone && nextWorkStatus != IssueWorkStatusCancelled {
 		if !canTransitionIssueWorkStatus(currentWorkStatus, IssueWorkStatusDone) {
-			return nil, fmt.Errorf("invalid work_status transition")
+			return issueWorkTrackingChange{}, fmt.Errorf("invalid work_status transition")
 		}
 		nextWorkStatus = IssueWorkStatusDone
 	}
+	if err := checkSubIssueClosePolicy(ctx, q, current, nextState); err != nil {
+		return issueWorkTrackingChange{}, err
+	}
 
-	updated, err := scanProjectIssue(tx.QueryRowContext(
+	return issueWorkTrackingChange{
+		OwnerAgentID:  nextOwnerAgentID,
+		WorkStatus:    nextWorkStatus,
+		Priority:      nextPriority,
+		DueAt:         nextDueAt,
+		NextStep:      nextNextStep,
+		NextStepDueAt: nextNextStepDueAt,
+		State:         nextState,
+	}, nil
+}
+
+func writeIssueWorkTracking(ctx context.Context, q Querier, issueID string, next issueWorkTrackingChange) (ProjectIssue, error) {
+	updated, err := scanProjectIssue(q.QueryRowContext(
 		ctx,
 		`UPDATE project_issues
 			SET owner_agent_id = $2,
@@ -896,24 +993,20 @@ func (s *ProjectIssueStore) UpdateIssueWorkTracking(
 					ELSE NULL
 				END
 			WHERE id = $1
-			RETURNING id, org_id, project_id, issue_number, title, body, state, origin, document_path, approval_state, owner_agent_id, work_status, priority, due_at, next_step, next_step_due_at, flow_template_id, flow_step_key, flow_step_index, created_at, updated_at, closed_at`,
+			RETURNING id, org_id, project_id, issue_number, title, body, state, origin, document_path, approval_state, owner_agent_id, work_status, priority, due_at, next_step, next_step_due_at, flow_template_id, flow_step_key, flow_step_index, parent_issue_id, merged_into_issue_id, created_at, updated_at, closed_at`,
 		issueID,
-		nullableString(nextOwnerAgentID),
-		nextWorkStatus,
-		nextPriority,
-		nextDueAt,
-		nullableString(nextNextStep),
-		nextNextStepDueAt,
-		nextState,
+		nullableString(next.OwnerAgentID),
+		next.WorkStatus,
+		next.Priority,
+		next.DueAt,
+		nullableString(next.NextStep),
+		next.NextStepDueAt,
+		next.State,
 	))
 	if err != nil {
-		return nil, fmt.Errorf("failed to update issue work tracking: %w", err)
-	}
-
-	if err := tx.Commit(); err != nil {
-		return nil, err
+		return ProjectIssue{}, fmt.Errorf("failed to update issue work tracking: %w", err)
 	}
-	return &updated, nil
+	return updated, nil
 }
 
 func (s *ProjectIssueStore) UpdateIssueFlow(
@@ -938,7 +1031,7 @@ func (s *ProjectIssueStore) UpdateIssueFlow(
 
 	current, err := scanProjectIssue(tx.QueryRowContext(
 		ctx,
-		`SELECT id, org_id, project_id, issue_number, title, body, state, origin, document_path, approval_state, owner_agent_id, work_status, priority, due_at, next_step, next_step_due_at, flow_template_id, flow_step_key, flow_step_index, created_at, updated_at, closed_at
+		`SELECT id, org_id, project_id, issue_number, title, body, state, origin, document_path, approval_state, owner_agent_id, work_status, priority, due_at, next_step, next_step_due_at, flow_template_id, flow_step_key, flow_step_index, parent_issue_id, merged_into_issue_id, created_at, updated_at, closed_at
 			FROM project_issues
 			WHERE id = $1
 			FOR UPDATE`,
@@ -983,7 +1076,7 @@ func (s *ProjectIssueStore) UpdateIssueFlow(
 		args = append(args, nullableString(nextOwnerAgentID))
 	}
 	query += ` WHERE id = $1
-		RETURNING id, org_id, project_id, issue_number, title, body, state, origin, document_path, approval_state, owner_agent_id, work_status, priority, due_at, next_step, next_step_due_at, flow_template_id, flow_step_key, flow_step_index, created_at, updated_at, closed_at`
+		RETURNING id, org_id, project_id, issue_number, title, body, state, origin, document_path, approval_state, owner_agent_id, work_status, priority, due_at, next_step, next_step_due_at, flow_template_id, flow_step_key, flow_step_index, parent_issue_id, merged_into_issue_id, created_at, updated_at, closed_at`
 
 	updated, err := scanProjectIssue(tx.QueryRowContext(ctx, query, args...))
 	if err != nil {
@@ -1041,7 +1134,7 @@ func (s *ProjectIssueStore) UpsertIssueFromGitHub(
 
 	existingIssue, err := scanProjectIssue(tx.QueryRowContext(
 		ctx,
-		`SELECT i.id, i.org_id, i.project_id, i.issue_number, i.title, i.body, i.state, i.origin, i.document_path, i.approval_state, i.owner_agent_id, i.work_status, i.priority, i.due_at, i.next_step, i.next_step_due_at, i.flow_template_id, i.flow_step_key, i.flow_step_index, i.created_at, i.updated_at, i.closed_at
+		`SELECT i.id, i.org_id, i.project_id, i.issue_number, i.title, i.body, i.state, i.origin, i.document_path, i.approval_state, i.owner_agent_id, i.work_status, i.priority, i.due_at, i.next_step, i.next_step_due_at, i.flow_template_id, i.flow_step_key, i.flow_step_index, i.parent_issue_id, i.merged_into_issue_id, i.created_at, i.updated_at, i.closed_at
 			FROM project_issues i
 			JOIN project_issue_github_links l ON l.issue_id = i.id
 			WHERE i.project_id = $1 AND l.repository_full_name = $2 AND l.github_number = $3
@@ -1069,7 +1162,7 @@ func (s *ProjectIssueStore) UpsertIssueFromGitHub(
 						END,
 						closed_at = $6
 					WHERE id = $1
-					RETURNING id, org_id, project_id, issue_number, title, body, state, origin, document_path, approval_state, owner_agent_id, work_status, priority, due_at, next_step, next_step_due_at, flow_template_id, flow_step_key, flow_step_index, created_at, updated_at, closed_at`,
+					RETURNING id, org_id, project_id, issue_number, title, body, state, origin, document_path, approval_state, owner_agent_id, work_status, priority, due_at, next_step, next_step_due_at, flow_template_id, flow_step_key, flow_step_index, parent_issue_id, merged_into_issue_id, created_at, updated_at, closed_at`,
 			existingIssue.ID,
 			title,
 			nullableString(input.Body),
@@ -1098,7 +1191,7 @@ func (s *ProjectIssueStore) UpsertIssueFromGitHub(
 					org_id, project_id, issue_number, title, body, state, origin, document_path, approval_state, work_status, priority, closed_at,
 					flow_template_id, flow_step_key, flow_step_index
 				) VALUES ($1,$2,$3,$4,$5,$6,'github',NULL,$7,$8,$9,$10,NULL,NULL,NULL)
-				RETURNING id, org_id, project_id, issue_number, title, body, state, origin, document_path, approval_state, owner_agent_id, work_status, priority, due_at, next_step, next_step_due_at, flow_template_id, flow_step_key, flow_step_index, created_at, updated_at, closed_at`,
+				RETURNING id, org_id, project_id, issue_number, title, body, state, origin, document_path, approval_state, owner_agent_id, work_status, priority, due_at, next_step, next_step_due_at, flow_template_id, flow_step_key, flow_step_index, parent_issue_id, merged_into_issue_id, created_at, updated_at, closed_at`,
 			workspaceID,
 			projectID,
 			nextIssueNumber,
@@ -1185,7 +1278,7 @@ func (s *ProjectIssueStore) ListIssues(ctx context.Context, filter ProjectIssueF
 		limit = 100
 	}
 
-	query := `SELECT i.id, i.org_id, i.project_id, i.issue_number, i.title, i.body, i.state, i.origin, i.document_path, i.approval_state, i.owner_agent_id, i.work_status, i.priority, i.due_at, i.next_step, i.next_step_due_at, i.flow_template_id, i.flow_step_key, i.flow_step_index, i.created_at, i.updated_at, i.closed_at
+	query := `SELECT i.id, i.org_id, i.project_id, i.issue_number, i.title, i.body, i.state, i.origin, i.document_path, i.approval_state, i.owner_agent_id, i.work_status, i.priority, i.due_at, i.next_step, i.next_step_due_at, i.flow_template_id, i.flow_step_key, i.flow_step_index, i.parent_issue_id, i.merged_into_issue_id, i.created_at, i.updated_at, i.closed_at
 		FROM project_issues i
 		LEFT JOIN project_issue_github_links l ON l.issue_id = i.id
 		WHERE i.project_id = $1`
@@ -1385,7 +1478,7 @@ func (s *ProjectIssueStore) GetIssueByID(ctx context.Context, issueID string) (*
 
 	issue, err := scanProjectIssue(conn.QueryRowContext(
 		ctx,
-		`SELECT id, org_id, project_id, issue_number, title, body, state, origin, document_path, approval_state, owner_agent_id, work_status, priority, due_at, next_step, next_step_due_at, flow_template_id, flow_step_key, flow_step_index, created_at, updated_at, closed_at
+		`SELECT id, org_id, project_id, issue_number, title, body, state, origin, document_path, approval_state, owner_agent_id, work_status, priority, due_at, next_step, next_step_due_at, flow_template_id, flow_step_key, flow_step_index, parent_issue_id, merged_into_issue_id, created_at, updated_at, closed_at
 			FROM project_issues
 			WHERE id = $1`,
 		issueID,
@@ -1430,7 +1523,7 @@ func (s *ProjectIssueStore) ListIssuesByDocumentPath(
 
 	rows, err := conn.QueryContext(
 		ctx,
-		`SELECT id, org_id, project_id, issue_number, title, body, state, origin, document_path, approval_state, owner_agent_id, work_status, priority, due_at, next_step, next_step_due_at, flow_template_id, flow_step_key, flow_step_index, created_at, updated_at, closed_at
+		`SELECT id, org_id, project_id, issue_number, title, body, state, origin, document_path, approval_state, owner_agent_id, work_status, priority, due_at, next_step, next_step_due_at, flow_template_id, flow_step_key, flow_step_index, parent_issue_id, merged_into_issue_id, created_at, updated_at, closed_at
 			FROM project_issues
 			WHERE project_id = $1 AND document_path = $2
 			ORDER BY created_at ASC`,
@@ -1490,7 +1583,7 @@ func (s *ProjectIssueStore) UpdateIssueDocumentPath(
 		`UPDATE project_issues
 			SET document_path = $2
 			WHERE id = $1
-			RETURNING id, org_id, project_id, issue_number, title, body, state, origin, document_path, approval_state, owner_agent_id, work_status, priority, due_at, next_step, next_step_due_at, flow_template_id, flow_step_key, flow_step_index, created_at, updated_at, closed_at`,
+			RETURNING id, org_id, project_id, issue_number, title, body, state, origin, document_path, approval_state, owner_agent_id, work_status, priority, due_at, next_step, next_step_due_at, flow_template_id, flow_step_key, flow_step_index, parent_issue_id, merged_into_issue_id, created_at, updated_at, closed_at`,
 		issueID,
 		nullableString(normalizedPath),
 	))
@@ -2512,6 +2605,8 @@ func scanProjectIssue(scanner interface{ Scan(...any) error }) (ProjectIssue, er
 	var flowTemplateID sql.NullString
 	var flowStepKey sql.NullString
 	var flowStepIndex sql.NullInt32
+	var parentIssueID sql.NullString
+	var mergedIntoID sql.NullString
 	var closedAt sql.NullTime
 
 	err := scanner.Scan(
@@ -2534,6 +2629,8 @@ func scanProjectIssue(scanner interface{ Scan(...any) error }) (ProjectIssue, er
 		&flowTemplateID,
 		&flowStepKey,
 		&flowStepIndex,
+		&parentIssueID,
+		&mergedIntoID,
 		&issue.CreatedAt,
 		&issue.UpdatedAt,
 		&closedAt,
@@ -2581,6 +2678,12 @@ func scanProjectIssue(scanner interface{ Scan(...any) error }) (ProjectIssue, er
 		value := int(flowStepIndex.Int32)
 		issue.FlowStepIndex = &value
 	}
+	if parentIssueID.Valid {
+		issue.ParentIssueID = &parentIssueID.String
+	}
+	if mergedIntoID.Valid {
+		issue.MergedIntoID = &mergedIntoID.String
+	}
 	if closedAt.Valid {
 		issue.ClosedAt = &closedAt.Time
 	}
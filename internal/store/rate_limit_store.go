@@ -0,0 +1,243 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+const (
+	RateLimitPlanFree = "free"
+
+	defaultRateLimitThrottleListLimit = 100
+	maxRateLimitThrottleListLimit     = 1000
+)
+
+// TakeRateLimitTokenInput identifies a bucket and the rule it refills under.
+// OrgID may be empty for anonymous callers.
+type TakeRateLimitTokenInput struct {
+	BucketKey       string
+	OrgID           string
+	Subject         string
+	RouteGroup      string
+	Plan            string
+	Capacity        float64
+	RefillPerSecond float64
+}
+
+// RateLimitDecision is the outcome of one take. Tokens is what is left in
+// the bucket afterwards.
+type RateLimitDecision struct {
+	Allowed bool
+	Tokens  float64
+}
+
+// RateLimitThrottle summarizes a bucket that has rejected requests.
+type RateLimitThrottle struct {
+	Subject         string    `json:"subject"`
+	RouteGroup      string    `json:"route_group"`
+	Plan            string    `json:"plan"`
+	Tokens          float64   `json:"tokens"`
+	ThrottledTotal  int64     `json:"throttled_total"`
+	LastThrottledAt time.Time `json:"last_throttled_at"`
+	UpdatedAt       time.Time `json:"updated_at"`
+}
+
+// rateLimitRefill is the bucket level after refilling for the time since it
+// was last touched. It reads the conflicting row (b), which Postgres has
+// locked and re-read, so concurrent takes cannot spend the same token.
+const rateLimitRefill = `LEAST($6::double precision,
+	b.tokens + GREATEST(EXTRACT(EPOCH FROM (NOW() - b.updated_at))::double precision, 0) * $7::double precision)`
+
+var rateLimitTakeQuery = strings.ReplaceAll(`INSERT INTO rate_limit_buckets AS b (
+		bucket_key, org_id, subject, route_group, plan, tokens, updated_at, last_allowed
+	) VALUES ($1, NULLIF($2, '')::uuid, $3, $4, $5, $6::double precision - 1, NOW(), TRUE)
+	ON CONFLICT (bucket_key) DO UPDATE SET
+		tokens = CASE WHEN {refill} >= 1 THEN {refill} - 1 ELSE {refill} END,
+		last_allowed = {refill} >= 1,
+		throttled_total = b.throttled_total + CASE WHEN {refill} >= 1 THEN 0 ELSE 1 END,
+		last_throttled_at = CASE WHEN {refill} >= 1 THEN b.last_throttled_at ELSE NOW() END,
+		plan = EXCLUDED.plan,
+		updated_at = NOW()
+	RETURNING last_allowed, tokens`, "{refill}", rateLimitRefill)
+
+// RateLimitStore keeps token buckets in Postgres so every replica enforces
+// the same limits. Refill uses the database clock.
+type RateLimitStore struct {
+	db *sql.DB
+}
+
+func NewRateLimitStore(db *sql.DB) *RateLimitStore {
+	return &RateLimitStore{db: db}
+}
+
+// Take refills the bucket for the time since its last use and removes one
+// token if a whole token is available. The read-modify-write happens in one
+// upsert, so concurrent requests serialize on the row.
+func (s *RateLimitStore) Take(ctx context.Context, input TakeRateLimitTokenInput) (RateLimitDecision, error) {
+	ctx, span := startStoreSpan(ctx, "RateLimitStore.Take")
+	defer span.End()
+
+	if s == nil || s.db == nil {
+		return RateLimitDecision{}, fmt.Errorf("rate limit store is not configured")
+	}
+	key := strings.TrimSpace(input.BucketKey)
+	if key == "" {
+		return RateLimitDecision{}, fmt.Errorf("%w: bucket key is required", ErrValidation)
+	}
+	if input.Capacity < 1 || input.RefillPerSecond <= 0 {
+		return RateLimitDecision{}, fmt.Errorf("%w: capacity must be at least 1 and refill positive", ErrValidation)
+	}
+
+	var decision RateLimitDecision
+	err := s.db.QueryRowContext(
+		ctx,
+		rateLimitTakeQuery,
+		key,
+		strings.TrimSpace(input.OrgID),
+		strings.TrimSpace(input.Subject),
+		strings.TrimSpace(input.RouteGroup),
+		strings.TrimSpace(input.Plan),
+		input.Capacity,
+		input.RefillPerSecond,
+	).Scan(&decision.Allowed, &decision.Tokens)
+	if err != nil {
+		return RateLimitDecision{}, fmt.Errorf("failed to take rate limit token: %w", err)
+	}
+	return decision, nil
+}
+
+// Peek reports whether the bucket has a whole token without taking one. A
+// bucket that has never been used is full.
+func (s *RateLimitStore) Peek(ctx context.Context, input TakeRateLimitTokenInput) (RateLimitDecision, error) {
+	ctx, span := startStoreSpan(ctx, "RateLimitStore.Peek")
+	defer span.End()
+
+	if s == nil || s.db == nil {
+		return RateLimitDecision{}, fmt.Errorf("rate limit store is not configured")
+	}
+	key := strings.TrimSpace(input.BucketKey)
+	if key == "" {
+		return RateLimitDecision{}, fmt.Errorf("%w: bucket key is required", ErrValidation)
+	}
+	if input.Capacity < 1 || input.RefillPerSecond <= 0 {
+		return RateLimitDecision{}, fmt.Errorf("%w: capacity must be at least 1 and refill positive", ErrValidation)
+	}
+
+	decision := RateLimitDecision{Allowed: true, Tokens: input.Capacity}
+	err := s.db.QueryRowContext(
+		ctx,
+		`SELECT `+strings.NewReplacer("$6", "$2", "$7", "$3").Replace(rateLimitRefill)+`
+		 FROM rate_limit_buckets b
+		 WHERE bucket_key = $1`,
+		key,
+		input.Capacity,
+		input.RefillPerSecond,
+	).Scan(&decision.Tokens)
+	if errors.Is(err, sql.ErrNoRows) {
+		return decision, nil
+	}
+	if err != nil {
+		return RateLimitDecision{}, fmt.Errorf("failed to read rate limit bucket: %w", err)
+	}
+	decision.Allowed = decision.Tokens >= 1
+	return decision, nil
+}
+
+// PlanForOrg returns the org's billing tier, which selects its limits.
+func (s *RateLimitStore) PlanForOrg(ctx context.Context, orgID string) (string, error) {
+	if s == nil || s.db == nil {
+		return "", fmt.Errorf("rate limit store is not configured")
+	}
+	var plan string
+	err := s.db.QueryRowContext(ctx, `SELECT tier FROM organizations WHERE id = $1`, strings.TrimSpace(orgID)).Scan(&plan)
+	if errors.Is(err, sql.ErrNoRows) {
+		return "", ErrNotFound
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to load organization plan: %w", err)
+	}
+	return plan, nil
+}
+
+// ListThrottled returns orgID's buckets that rejected a request since the
+// given time, most recent first.
+func (s *RateLimitStore) ListThrottled(ctx context.Context, orgID string, since time.Time, limit int) ([]RateLimitThrottle, error) {
+	if s == nil || s.db == nil {
+		return nil, fmt.Errorf("rate limit store is not configured")
+	}
+	orgID, err := normalizeWorkspaceID(orgID)
+	if err != nil {
+		return nil, err
+	}
+	if limit <= 0 {
+		limit = defaultRateLimitThrottleListLimit
+	}
+	if limit > maxRateLimitThrottleListLimit {
+		limit = maxRateLimitThrottleListLimit
+	}
+
+	rows, err := s.db.QueryContext(
+		ctx,
+		`SELECT subject, route_group, plan, tokens, throttled_total, last_throttled_at, updated_at
+		 FROM rate_limit_buckets
+		 WHERE org_id = $1
+		   AND last_throttled_at >= $2
+		 ORDER BY last_throttled_at DESC
+		 LIMIT $3`,
+		orgID,
+		since.UTC(),
+		limit,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list throttled rate limit buckets: %w", err)
+	}
+	defer rows.Close()
+
+	items := make([]RateLimitThrottle, 0)
+	for rows.Next() {
+		var item RateLimitThrottle
+		if err := rows.Scan(
+			&item.Subject,
+			&item.RouteGroup,
+			&item.Plan,
+			&item.Tokens,
+			&item.ThrottledTotal,
+			&item.LastThrottledAt,
+			&item.UpdatedAt,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan throttled rate limit bucket: %w", err)
+		}
+		items = append(items, item)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read throttled rate limit buckets: %w", err)
+	}
+	return items, nil
+}
+
+// PruneIdle deletes buckets unused and unthrottled since before. An idle
+// bucket has refilled completely, so dropping it changes no decision.
+func (s *RateLimitStore) PruneIdle(ctx context.Context, before time.Time) (int, error) {
+	if s == nil || s.db == nil {
+		return 0, fmt.Errorf("rate limit store is not configured")
+	}
+	result, err := s.db.ExecContext(
+		ctx,
+		`DELETE FROM rate_limit_buckets
+		 WHERE updated_at < $1
+		   AND (last_throttled_at IS NULL OR last_throttled_at < $1)`,
+		before.UTC(),
+	)
+	if err != nil {
+		return 0, fmt.Errorf("failed to prune rate limit buckets: %w", err)
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return 0, err
+	}
+	return int(affected), nil
+}
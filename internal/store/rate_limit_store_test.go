@@ -0,0 +1,88 @@
+package store
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRateLimitStoreTakeRefillsAndRecordsThrottles(t *testing.T) {
+	connStr := getTestDatabaseURL(t)
+	db := setupTestDatabase(t, connStr)
+	orgID := createTestOrganization(t, db, "rate-limit-org")
+	otherOrgID := createTestOrganization(t, db, "rate-limit-other")
+	limits := NewRateLimitStore(db)
+	ctx := context.Background()
+
+	plan, err := limits.PlanForOrg(ctx, orgID)
+	require.NoError(t, err)
+	require.Equal(t, RateLimitPlanFree, plan)
+
+	input := TakeRateLimitTokenInput{
+		BucketKey:       orgID + "|api_key:k1|comments",
+		OrgID:           orgID,
+		Subject:         "api_key:k1",
+		RouteGroup:      "comments",
+		Plan:            plan,
+		Capacity:        2,
+		RefillPerSecond: 1,
+	}
+	peeked, err := limits.Peek(ctx, input)
+	require.NoError(t, err)
+	require.True(t, peeked.Allowed)
+	require.Equal(t, 2.0, peeked.Tokens, "an unused bucket is full")
+	for i := 0; i < 2; i++ {
+		decision, err := limits.Take(ctx, input)
+		require.NoError(t, err)
+		require.True(t, decision.Allowed, "take %d", i)
+	}
+	decision, err := limits.Take(ctx, input)
+	require.NoError(t, err)
+	require.False(t, decision.Allowed)
+	require.Less(t, decision.Tokens, 1.0)
+	peeked, err = limits.Peek(ctx, input)
+	require.NoError(t, err)
+	require.False(t, peeked.Allowed)
+
+	// Age the bucket instead of sleeping: two seconds refills it.
+	_, err = db.Exec(`UPDATE rate_limit_buckets SET updated_at = updated_at - INTERVAL '2 seconds' WHERE bucket_key = $1`, input.BucketKey)
+	require.NoError(t, err)
+	decision, err = limits.Take(ctx, input)
+	require.NoError(t, err)
+	require.True(t, decision.Allowed)
+	require.InDelta(t, 1.0, decision.Tokens, 0.1)
+
+	throttled, err := limits.ListThrottled(ctx, orgID, time.Now().Add(-time.Hour), 10)
+	require.NoError(t, err)
+	require.Len(t, throttled, 1)
+	require.Equal(t, "api_key:k1", throttled[0].Subject)
+	require.Equal(t, "comments", throttled[0].RouteGroup)
+	require.Equal(t, int64(1), throttled[0].ThrottledTotal)
+
+	throttled, err = limits.ListThrottled(ctx, otherOrgID, time.Now().Add(-time.Hour), 10)
+	require.NoError(t, err)
+	require.Empty(t, throttled)
+
+	anonymous := TakeRateLimitTokenInput{
+		BucketKey:       "-|ip:203.0.113.9|write",
+		Subject:         "ip:203.0.113.9",
+		RouteGroup:      "write",
+		Plan:            RateLimitPlanFree,
+		Capacity:        5,
+		RefillPerSecond: 1,
+	}
+	decision, err = limits.Take(ctx, anonymous)
+	require.NoError(t, err)
+	require.True(t, decision.Allowed)
+
+	_, err = db.Exec(`UPDATE rate_limit_buckets SET updated_at = NOW() - INTERVAL '2 days'`)
+	require.NoError(t, err)
+	pruned, err := limits.PruneIdle(ctx, time.Now().Add(-24*time.Hour))
+	require.NoError(t, err)
+	require.Equal(t, 1, pruned, "throttled buckets are kept for diagnostics")
+
+	_, err = limits.Take(ctx, TakeRateLimitTokenInput{BucketKey: "x", Capacity: 0, RefillPerSecond: 1})
+	require.ErrorIs(t, err, ErrValidation)
+}
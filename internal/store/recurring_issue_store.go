@@ -0,0 +1,797 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/samhotchkiss/otter-camp/internal/middleware"
+)
+
+const (
+	RecurringIssueRunCreated = "created"
+	RecurringIssueRunSkipped = "skipped"
+	RecurringIssueRunError   = "error"
+
+	maxRecurringIssueNameLength = 120
+)
+
+// RecurringIssue is a schedule that opens a new issue on each occurrence.
+// TitleTemplate and BodyTemplate may use date variables; when BodyTemplate is
+// empty the issue template's body is rendered with Fields instead.
+type RecurringIssue struct {
+	ID              string            `json:"id"`
+	OrgID           string            `json:"org_id"`
+	ProjectID       string            `json:"project_id"`
+	Name            string            `json:"name"`
+	TitleTemplate   string            `json:"title_template"`
+	BodyTemplate    string            `json:"body_template"`
+	IssueTemplateID *string           `json:"issue_template_id,omitempty"`
+	Fields          map[string]string `json:"fields"`
+	OwnerAgentID    *string           `json:"owner_agent_id,omitempty"`
+	FlowTemplateID  *string           `json:"flow_template_id,omitempty"`
+	Priority        string            `json:"priority"`
+	ScheduleKind    string            `json:"schedule_kind"`
+	CronExpr        *string           `json:"cron_expr,omitempty"`
+	IntervalMS      *int64            `json:"interval_ms,omitempty"`
+	RunAt           *time.Time        `json:"run_at,omitempty"`
+	Timezone        string            `json:"timezone"`
+	SkipIfOpen      bool              `json:"skip_if_open"`
+	Enabled         bool              `json:"enabled"`
+	NextRunAt       *time.Time        `json:"next_run_at,omitempty"`
+	LastRunAt       *time.Time        `json:"last_run_at,omitempty"`
+	LastRunStatus   *string           `json:"last_run_status,omitempty"`
+	LastRunError    *string           `json:"last_run_error,omitempty"`
+	LastIssueID     *string           `json:"last_issue_id,omitempty"`
+	RunCount        int               `json:"run_count"`
+	CreatedAt       time.Time         `json:"created_at"`
+	UpdatedAt       time.Time         `json:"updated_at"`
+
+	// DueAt is the occurrence PickupDue claimed the definition for.
+	DueAt *time.Time `json:"-"`
+}
+
+type CreateRecurringIssueInput struct {
+	ProjectID       string
+	Name            string
+	TitleTemplate   string
+	BodyTemplate    string
+	IssueTemplateID *string
+	Fields          map[string]string
+	OwnerAgentID    *string
+	FlowTemplateID  *string
+	Priority        string
+	ScheduleKind    string
+	CronExpr        *string
+	IntervalMS      *int64
+	RunAt           *time.Time
+	Timezone        string
+	SkipIfOpen      bool
+	Enabled         *bool
+	NextRunAt       *time.Time
+}
+
+// UpdateRecurringIssueInput changes the fields that are set. An empty string
+// clears IssueTemplateID, OwnerAgentID and FlowTemplateID.
+type UpdateRecurringIssueInput struct {
+	Name            *string
+	TitleTemplate   *string
+	BodyTemplate    *string
+	IssueTemplateID *string
+	Fields          map[string]string
+	OwnerAgentID    *string
+	FlowTemplateID  *string
+	Priority        *string
+	ScheduleKind    *string
+	CronExpr        *string
+	IntervalMS      *int64
+	RunAt           *time.Time
+	Timezone        *string
+	SkipIfOpen      *bool
+	Enabled         *bool
+	NextRunAt       *time.Time
+	ClearNextRunAt  bool
+}
+
+// RecordRecurringIssueRunInput stores the outcome of one occurrence.
+// IssueID is set when an issue was created. PreserveNextRunAt, used by manual
+// runs, leaves the schedule and any worker claim untouched.
+type RecordRecurringIssueRunInput struct {
+	ID                string
+	Status            string
+	IssueID           *string
+	Error             *string
+	RanAt             time.Time
+	NextRunAt         *time.Time
+	PreserveNextRunAt bool
+}
+
+type RecurringIssueStore struct {
+	db *sql.DB
+}
+
+func NewRecurringIssueStore(db *sql.DB) *RecurringIssueStore {
+	return &RecurringIssueStore{db: db}
+}
+
+const recurringIssueColumns = `
+	id,
+	org_id,
+	project_id,
+	name,
+	title_template,
+	body_template,
+	issue_template_id,
+	fields,
+	owner_agent_id,
+	flow_template_id,
+	priority,
+	schedule_kind,
+	cron_expr,
+	interval_ms,
+	run_at,
+	timezone,
+	skip_if_open,
+	enabled,
+	next_run_at,
+	last_run_at,
+	last_run_status,
+	last_run_error,
+	last_issue_id,
+	run_count,
+	created_at,
+	updated_at`
+
+// Create validates the definition and its references. The caller computes
+// NextRunAt from the schedule.
+func (s *RecurringIssueStore) Create(ctx context.Context, input CreateRecurringIssueInput) (*RecurringIssue, error) {
+	if s == nil || s.db == nil {
+		return nil, fmt.Errorf("recurring issue store is not configured")
+	}
+	workspaceID := middleware.WorkspaceFromContext(ctx)
+	if workspaceID == "" {
+		return nil, ErrNoWorkspace
+	}
+
+	record := RecurringIssue{
+		ProjectID:       strings.TrimSpace(input.ProjectID),
+		Name:            strings.TrimSpace(input.Name),
+		TitleTemplate:   strings.TrimSpace(input.TitleTemplate),
+		BodyTemplate:    strings.TrimSpace(input.BodyTemplate),
+		IssueTemplateID: normalizeOptionalRecurringIssueRef(input.IssueTemplateID),
+		Fields:          normalizeRecurringIssueFields(input.Fields),
+		OwnerAgentID:    normalizeOptionalRecurringIssueRef(input.OwnerAgentID),
+		FlowTemplateID:  normalizeOptionalRecurringIssueRef(input.FlowTemplateID),
+		Priority:        normalizeIssuePriority(input.Priority),
+		ScheduleKind:    strings.ToLower(strings.TrimSpace(input.ScheduleKind)),
+		CronExpr:        normalizeOptionalAgentJobText(input.CronExpr),
+		IntervalMS:      input.IntervalMS,
+		Timezone:        strings.TrimSpace(input.Timezone),
+		SkipIfOpen:      input.SkipIfOpen,
+		Enabled:         true,
+	}
+	if record.Priority == "" {
+		record.Priority = IssuePriorityP2
+	}
+	if record.Timezone == "" {
+		record.Timezone = "UTC"
+	}
+	if input.RunAt != nil {
+		runAt := input.RunAt.UTC()
+		record.RunAt = &runAt
+	}
+	if input.Enabled != nil {
+		record.Enabled = *input.Enabled
+	}
+	if input.NextRunAt != nil && record.Enabled {
+		nextRunAt := input.NextRunAt.UTC()
+		record.NextRunAt = &nextRunAt
+	}
+	if !uuidRegex.MatchString(record.ProjectID) {
+		return nil, fmt.Errorf("%w: project_id must be a UUID", ErrValidation)
+	}
+	if err := validateRecurringIssueRecord(record); err != nil {
+		return nil, err
+	}
+	fieldsJSON, err := json.Marshal(record.Fields)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode recurring issue fields: %w", err)
+	}
+
+	conn, err := WithWorkspace(ctx, s.db)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	if err := ensureProjectVisible(ctx, conn, record.ProjectID); err != nil {
+		return nil, err
+	}
+	if err := ensureRecurringIssueRefs(ctx, conn, record); err != nil {
+		return nil, err
+	}
+
+	created, err := scanRecurringIssue(conn.QueryRowContext(
+		ctx,
+		`INSERT INTO recurring_issues (
+			org_id, project_id, name, title_template, body_template, issue_template_id, fields,
+			owner_agent_id, flow_template_id, priority, schedule_kind, cron_expr, interval_ms,
+			run_at, timezone, skip_if_open, enabled, next_run_at
+		 ) VALUES ($1, $2, $3, $4, $5, $6, $7::jsonb, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18)
+		 RETURNING`+recurringIssueColumns,
+		workspaceID,
+		record.ProjectID,
+		record.Name,
+		record.TitleTemplate,
+		record.BodyTemplate,
+		nullableString(record.IssueTemplateID),
+		string(fieldsJSON),
+		nullableString(record.OwnerAgentID),
+		nullableString(record.FlowTemplateID),
+		record.Priority,
+		record.ScheduleKind,
+		nullableString(record.CronExpr),
+		nullableInt64(record.IntervalMS),
+		nullableTime(record.RunAt),
+		record.Timezone,
+		record.SkipIfOpen,
+		record.Enabled,
+		nullableTime(record.NextRunAt),
+	))
+	if err != nil {
+		if isUniqueViolation(err) {
+			return nil, ErrConflict
+		}
+		return nil, fmt.Errorf("failed to create recurring issue: %w", err)
+	}
+	return &created, nil
+}
+
+// List returns a project's recurring issues ordered by name.
+func (s *RecurringIssueStore) List(ctx context.Context, projectID string) ([]RecurringIssue, error) {
+	if s == nil || s.db == nil {
+		return nil, fmt.Errorf("recurring issue store is not configured")
+	}
+	projectID = strings.TrimSpace(projectID)
+	if !uuidRegex.MatchString(projectID) {
+		return nil, fmt.Errorf("%w: project_id must be a UUID", ErrValidation)
+	}
+
+	conn, err := WithWorkspace(ctx, s.db)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	rows, err := conn.QueryContext(
+		ctx,
+		`SELECT`+recurringIssueColumns+`
+		 FROM recurring_issues
+		 WHERE project_id = $1
+		 ORDER BY lower(name) ASC`,
+		projectID,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list recurring issues: %w", err)
+	}
+	defer rows.Close()
+
+	items := make([]RecurringIssue, 0)
+	for rows.Next() {
+		item, err := scanRecurringIssue(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan recurring issue: %w", err)
+		}
+		items = append(items, item)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read recurring issues: %w", err)
+	}
+	return items, nil
+}
+
+func (s *RecurringIssueStore) GetByID(ctx context.Context, id string) (*RecurringIssue, error) {
+	if s == nil || s.db == nil {
+		return nil, fmt.Errorf("recurring issue store is not configured")
+	}
+	id = strings.TrimSpace(id)
+	if !uuidRegex.MatchString(id) {
+		return nil, fmt.Errorf("%w: recurring issue id must be a UUID", ErrValidation)
+	}
+
+	conn, err := WithWorkspace(ctx, s.db)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	record, err := scanRecurringIssue(conn.QueryRowContext(
+		ctx,
+		`SELECT`+recurringIssueColumns+` FROM recurring_issues WHERE id = $1`,
+		id,
+	))
+	if err != nil {
+		if errors.Is(err, ErrNotFound) {
+			return nil, ErrNotFound
+		}
+		return nil, fmt.Errorf("failed to load recurring issue: %w", err)
+	}
+	return &record, nil
+}
+
+func (s *RecurringIssueStore) Update(ctx context.Context, id string, input UpdateRecurringIssueInput) (*RecurringIssue, error) {
+	existing, err := s.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	updated := *existing
+
+	if input.Name != nil {
+		updated.Name = strings.TrimSpace(*input.Name)
+	}
+	if input.TitleTemplate != nil {
+		updated.TitleTemplate = strings.TrimSpace(*input.TitleTemplate)
+	}
+	if input.BodyTemplate != nil {
+		updated.BodyTemplate = strings.TrimSpace(*input.BodyTemplate)
+	}
+	if input.IssueTemplateID != nil {
+		updated.IssueTemplateID = normalizeOptionalRecurringIssueRef(input.IssueTemplateID)
+	}
+	if input.Fields != nil {
+		updated.Fields = normalizeRecurringIssueFields(input.Fields)
+	}
+	if input.OwnerAgentID != nil {
+		updated.OwnerAgentID = normalizeOptionalRecurringIssueRef(input.OwnerAgentID)
+	}
+	if input.FlowTemplateID != nil {
+		updated.FlowTemplateID = normalizeOptionalRecurringIssueRef(input.FlowTemplateID)
+	}
+	if input.Priority != nil {
+		updated.Priority = normalizeIssuePriority(*input.Priority)
+	}
+	if input.ScheduleKind != nil {
+		updated.ScheduleKind = strings.ToLower(strings.TrimSpace(*input.ScheduleKind))
+	}
+	if input.CronExpr != nil {
+		updated.CronExpr = normalizeOptionalAgentJobText(input.CronExpr)
+	}
+	if input.IntervalMS != nil {
+		interval := *input.IntervalMS
+		updated.IntervalMS = &interval
+	}
+	if input.RunAt != nil {
+		runAt := input.RunAt.UTC()
+		updated.RunAt = &runAt
+	}
+	if input.Timezone != nil {
+		updated.Timezone = strings.TrimSpace(*input.Timezone)
+	}
+	if input.SkipIfOpen != nil {
+		updated.SkipIfOpen = *input.SkipIfOpen
+	}
+	if input.Enabled != nil {
+		updated.Enabled = *input.Enabled
+	}
+	if input.ClearNextRunAt {
+		updated.NextRunAt = nil
+	} else if input.NextRunAt != nil {
+		nextRunAt := input.NextRunAt.UTC()
+		updated.NextRunAt = &nextRunAt
+	}
+	if err := validateRecurringIssueRecord(updated); err != nil {
+		return nil, err
+	}
+	fieldsJSON, err := json.Marshal(updated.Fields)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode recurring issue fields: %w", err)
+	}
+
+	conn, err := WithWorkspace(ctx, s.db)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	if err := ensureRecurringIssueRefs(ctx, conn, updated); err != nil {
+		return nil, err
+	}
+
+	record, err := scanRecurringIssue(conn.QueryRowContext(
+		ctx,
+		`UPDATE recurring_issues
+		 SET name = $2,
+		     title_template = $3,
+		     body_template = $4,
+		     issue_template_id = $5,
+		     fields = $6::jsonb,
+		     owner_agent_id = $7,
+		     flow_template_id = $8,
+		     priority = $9,
+		     schedule_kind = $10,
+		     cron_expr = $11,
+		     interval_ms = $12,
+		     run_at = $13,
+		     timezone = $14,
+		     skip_if_open = $15,
+		     enabled = $16,
+		     next_run_at = $17,
+		     updated_at = NOW()
+		 WHERE id = $1
+		 RETURNING`+recurringIssueColumns,
+		updated.ID,
+		updated.Name,
+		updated.TitleTemplate,
+		updated.BodyTemplate,
+		nullableString(updated.IssueTemplateID),
+		string(fieldsJSON),
+		nullableString(updated.OwnerAgentID),
+		nullableString(updated.FlowTemplateID),
+		updated.Priority,
+		updated.ScheduleKind,
+		nullableString(updated.CronExpr),
+		nullableInt64(updated.IntervalMS),
+		nullableTime(updated.RunAt),
+		updated.Timezone,
+		updated.SkipIfOpen,
+		updated.Enabled,
+		nullableTime(updated.NextRunAt),
+	))
+	if err != nil {
+		if errors.Is(err, ErrNotFound) {
+			return nil, ErrNotFound
+		}
+		if isUniqueViolation(err) {
+			return nil, ErrConflict
+		}
+		return nil, fmt.Errorf("failed to update recurring issue: %w", err)
+	}
+	return &record, nil
+}
+
+// Delete removes a definition. Issues it already opened are kept.
+func (s *RecurringIssueStore) Delete(ctx context.Context, id string) error {
+	if s == nil || s.db == nil {
+		return fmt.Errorf("recurring issue store is not configured")
+	}
+	id = strings.TrimSpace(id)
+	if !uuidRegex.MatchString(id) {
+		return fmt.Errorf("%w: recurring issue id must be a UUID", ErrValidation)
+	}
+
+	conn, err := WithWorkspace(ctx, s.db)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	result, err := conn.ExecContext(ctx, `DELETE FROM recurring_issues WHERE id = $1`, id)
+	if err != nil {
+		return fmt.Errorf("failed to delete recurring issue: %w", err)
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to delete recurring issue: %w", err)
+	}
+	if affected == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// PickupDue claims enabled definitions whose next run has passed, so
+// concurrent workers never open the same occurrence twice. RecordRun releases
+// the claim and sets the following run; a claim older than claimTimeout is
+// treated as abandoned and the occurrence is picked up again.
+func (s *RecurringIssueStore) PickupDue(ctx context.Context, limit int, now time.Time, claimTimeout time.Duration) ([]RecurringIssue, error) {
+	if s == nil || s.db == nil {
+		return nil, fmt.Errorf("recurring issue store is not configured")
+	}
+	if limit <= 0 {
+		limit = 50
+	}
+	if limit > 200 {
+		limit = 200
+	}
+	if now.IsZero() {
+		now = time.Now().UTC()
+	}
+	if claimTimeout <= 0 {
+		return nil, fmt.Errorf("%w: claim timeout must be positive", ErrValidation)
+	}
+
+	tx, err := WithWorkspaceTx(ctx, s.db)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	rows, err := tx.QueryContext(
+		ctx,
+		`WITH due AS (
+			SELECT id, next_run_at AS due_at
+			FROM recurring_issues
+			WHERE enabled = true
+			  AND next_run_at IS NOT NULL
+			  AND next_run_at <= $1
+			  AND (claimed_at IS NULL OR claimed_at <= $3)
+			ORDER BY next_run_at ASC, created_at ASC
+			FOR UPDATE SKIP LOCKED
+			LIMIT $2
+		)
+		UPDATE recurring_issues r
+		SET claimed_at = $1,
+		    updated_at = NOW()
+		FROM due d
+		WHERE r.id = d.id
+		RETURNING r.`+strings.ReplaceAll(strings.TrimSpace(recurringIssueColumns), ",\n\t", ",\n\tr.")+`,
+			d.due_at`,
+		now.UTC(),
+		limit,
+		now.UTC().Add(-claimTimeout),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to pick up due recurring issues: %w", err)
+	}
+	defer rows.Close()
+
+	out := make([]RecurringIssue, 0)
+	for rows.Next() {
+		var dueAt sql.NullTime
+		record, scanErr := scanRecurringIssue(trailingColumnScanner{scanner: rows, extra: []any{&dueAt}})
+		if scanErr != nil {
+			return nil, fmt.Errorf("failed to scan due recurring issue: %w", scanErr)
+		}
+		if dueAt.Valid {
+			value := dueAt.Time.UTC()
+			record.DueAt = &value
+		}
+		out = append(out, record)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read due recurring issues: %w", err)
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit recurring issue pickup: %w", err)
+	}
+	return out, nil
+}
+
+// RecordRun stores an occurrence's outcome. Created runs advance run_count and
+// become the previous instance that skip_if_open checks.
+func (s *RecurringIssueStore) RecordRun(ctx context.Context, input RecordRecurringIssueRunInput) (*RecurringIssue, error) {
+	if s == nil || s.db == nil {
+		return nil, fmt.Errorf("recurring issue store is not configured")
+	}
+	id := strings.TrimSpace(input.ID)
+	if !uuidRegex.MatchString(id) {
+		return nil, fmt.Errorf("%w: recurring issue id must be a UUID", ErrValidation)
+	}
+	switch input.Status {
+	case RecurringIssueRunCreated:
+		if input.IssueID == nil || !uuidRegex.MatchString(strings.TrimSpace(*input.IssueID)) {
+			return nil, fmt.Errorf("%w: created runs need an issue id", ErrValidation)
+		}
+	case RecurringIssueRunSkipped, RecurringIssueRunError:
+	default:
+		return nil, fmt.Errorf("%w: invalid run status", ErrValidation)
+	}
+	ranAt := input.RanAt
+	if ranAt.IsZero() {
+		ranAt = time.Now().UTC()
+	}
+
+	conn, err := WithWorkspace(ctx, s.db)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	record, err := scanRecurringIssue(conn.QueryRowContext(
+		ctx,
+		`UPDATE recurring_issues
+		 SET last_run_at = $2,
+		     last_run_status = $3,
+		     last_run_error = $4,
+		     last_issue_id = COALESCE($5::uuid, last_issue_id),
+		     run_count = run_count + CASE WHEN $5::uuid IS NULL THEN 0 ELSE 1 END,
+		     next_run_at = CASE WHEN $7 THEN next_run_at ELSE $6 END,
+		     claimed_at = CASE WHEN $7 THEN claimed_at ELSE NULL END,
+		     updated_at = NOW()
+		 WHERE id = $1
+		 RETURNING`+recurringIssueColumns,
+		id,
+		ranAt.UTC(),
+		input.Status,
+		nullableString(input.Error),
+		nullableString(input.IssueID),
+		nullableTime(input.NextRunAt),
+		input.PreserveNextRunAt,
+	))
+	if err != nil {
+		if errors.Is(err, ErrNotFound) {
+			return nil, ErrNotFound
+		}
+		return nil, fmt.Errorf("failed to record recurring issue run: %w", err)
+	}
+	return &record, nil
+}
+
+func validateRecurringIssueRecord(record RecurringIssue) error {
+	if record.Name == "" {
+		return fmt.Errorf("%w: name is required", ErrValidation)
+	}
+	if len(record.Name) > maxRecurringIssueNameLength {
+		return fmt.Errorf("%w: name must be at most %d characters", ErrValidation, maxRecurringIssueNameLength)
+	}
+	if record.TitleTemplate == "" {
+		return fmt.Errorf("%w: title_template is required", ErrValidation)
+	}
+	if !isValidIssuePriority(record.Priority) {
+		return fmt.Errorf("%w: invalid priority", ErrValidation)
+	}
+	if _, err := normalizeAgentJobScheduleKind(record.ScheduleKind); err != nil {
+		return err
+	}
+	for label, ref := range map[string]*string{
+		"issue_template_id": record.IssueTemplateID,
+		"owner_agent_id":    record.OwnerAgentID,
+		"flow_template_id":  record.FlowTemplateID,
+	} {
+		if ref != nil && !uuidRegex.MatchString(*ref) {
+			return fmt.Errorf("%w: %s must be a UUID", ErrValidation, label)
+		}
+	}
+	return nil
+}
+
+// ensureRecurringIssueRefs checks that the owner is visible and that the
+// issue and flow templates belong to the definition's project.
+func ensureRecurringIssueRefs(ctx context.Context, q Querier, record RecurringIssue) error {
+	if record.OwnerAgentID != nil {
+		if err := ensureAgentVisible(ctx, q, *record.OwnerAgentID); err != nil {
+			if errors.Is(err, ErrNotFound) {
+				return fmt.Errorf("%w: owner agent not found", ErrValidation)
+			}
+			return err
+		}
+	}
+	checks := []struct {
+		ref   *string
+		table string
+		label string
+	}{
+		{record.IssueTemplateID, "issue_templates", "issue template"},
+		{record.FlowTemplateID, "project_flow_templates", "flow template"},
+	}
+	for _, check := range checks {
+		if check.ref == nil {
+			continue
+		}
+		var exists bool
+		if err := q.QueryRowContext(
+			ctx,
+			`SELECT EXISTS(SELECT 1 FROM `+check.table+` WHERE id = $1 AND project_id = $2)`,
+			*check.ref,
+			record.ProjectID,
+		).Scan(&exists); err != nil {
+			return fmt.Errorf("failed to check %s: %w", check.label, err)
+		}
+		if !exists {
+			return fmt.Errorf("%w: %s does not belong to this project", ErrValidation, check.label)
+		}
+	}
+	return nil
+}
+
+func normalizeOptionalRecurringIssueRef(value *string) *string {
+	if value == nil {
+		return nil
+	}
+	trimmed := strings.TrimSpace(*value)
+	if trimmed == "" {
+		return nil
+	}
+	return &trimmed
+}
+
+func normalizeRecurringIssueFields(fields map[string]string) map[string]string {
+	normalized := make(map[string]string, len(fields))
+	for key, value := range fields {
+		key = strings.ToLower(strings.TrimSpace(key))
+		if key == "" {
+			continue
+		}
+		normalized[key] = strings.TrimSpace(value)
+	}
+	return normalized
+}
+
+func scanRecurringIssue(scanner interface{ Scan(...any) error }) (RecurringIssue, error) {
+	var record RecurringIssue
+	var issueTemplateID, ownerAgentID, flowTemplateID sql.NullString
+	var cronExpr, lastRunStatus, lastRunError, lastIssueID sql.NullString
+	var intervalMS sql.NullInt64
+	var runAt, nextRunAt, lastRunAt sql.NullTime
+	var fieldsJSON []byte
+	err := scanner.Scan(
+		&record.ID,
+		&record.OrgID,
+		&record.ProjectID,
+		&record.Name,
+		&record.TitleTemplate,
+		&record.BodyTemplate,
+		&issueTemplateID,
+		&fieldsJSON,
+		&ownerAgentID,
+		&flowTemplateID,
+		&record.Priority,
+		&record.ScheduleKind,
+		&cronExpr,
+		&intervalMS,
+		&runAt,
+		&record.Timezone,
+		&record.SkipIfOpen,
+		&record.Enabled,
+		&nextRunAt,
+		&lastRunAt,
+		&lastRunStatus,
+		&lastRunError,
+		&lastIssueID,
+		&record.RunCount,
+		&record.CreatedAt,
+		&record.UpdatedAt,
+	)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return record, ErrNotFound
+		}
+		return record, err
+	}
+
+	for _, pair := range []struct {
+		src sql.NullString
+		dst **string
+	}{
+		{issueTemplateID, &record.IssueTemplateID},
+		{ownerAgentID, &record.OwnerAgentID},
+		{flowTemplateID, &record.FlowTemplateID},
+		{cronExpr, &record.CronExpr},
+		{lastRunStatus, &record.LastRunStatus},
+		{lastRunError, &record.LastRunError},
+		{lastIssueID, &record.LastIssueID},
+	} {
+		if pair.src.Valid {
+			value := pair.src.String
+			*pair.dst = &value
+		}
+	}
+	if intervalMS.Valid {
+		value := intervalMS.Int64
+		record.IntervalMS = &value
+	}
+	for _, pair := range []struct {
+		src sql.NullTime
+		dst **time.Time
+	}{
+		{runAt, &record.RunAt},
+		{nextRunAt, &record.NextRunAt},
+		{lastRunAt, &record.LastRunAt},
+	} {
+		if pair.src.Valid {
+			value := pair.src.Time.UTC()
+			*pair.dst = &value
+		}
+	}
+	record.Fields = map[string]string{}
+	if len(fieldsJSON) > 0 {
+		if err := json.Unmarshal(fieldsJSON, &record.Fields); err != nil {
+			return record, fmt.Errorf("failed to decode recurring issue fields: %w", err)
+		}
+	}
+	return record, nil
+}
@@ -0,0 +1,150 @@
+package store
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRecurringIssueStoreLifecycle(t *testing.T) {
+	connStr := getTestDatabaseURL(t)
+	db := setupTestDatabase(t, connStr)
+	orgID := createTestOrganization(t, db, "recurring-issue-org")
+	projectID := createTestProject(t, db, orgID, "Recurring Project")
+	agentID := createAgentJobTestAgent(t, db, orgID, "recurring-owner")
+	ctx := ctxWithWorkspace(orgID)
+	recurring := NewRecurringIssueStore(db)
+
+	now := time.Date(2026, 10, 19, 13, 0, 0, 0, time.UTC)
+	dueAt := now.Add(-time.Minute)
+	cronExpr := "0 9 * * 1"
+	created, err := recurring.Create(ctx, CreateRecurringIssueInput{
+		ProjectID:     projectID,
+		Name:          " Weekly metrics ",
+		TitleTemplate: "Weekly metrics report — {{date}}",
+		OwnerAgentID:  &agentID,
+		ScheduleKind:  "CRON",
+		CronExpr:      &cronExpr,
+		Timezone:      "America/New_York",
+		SkipIfOpen:    true,
+		NextRunAt:     &dueAt,
+		Fields:        map[string]string{" Period ": " {{iso_week}} "},
+	})
+	require.NoError(t, err)
+	require.Equal(t, "Weekly metrics", created.Name)
+	require.Equal(t, AgentJobScheduleCron, created.ScheduleKind)
+	require.Equal(t, IssuePriorityP2, created.Priority)
+	require.Equal(t, map[string]string{"period": "{{iso_week}}"}, created.Fields)
+	require.True(t, created.Enabled)
+
+	_, err = recurring.Create(ctx, CreateRecurringIssueInput{
+		ProjectID:     projectID,
+		Name:          "weekly METRICS",
+		TitleTemplate: "Duplicate",
+		ScheduleKind:  "cron",
+		CronExpr:      &cronExpr,
+	})
+	require.ErrorIs(t, err, ErrConflict)
+
+	_, err = recurring.Create(ctx, CreateRecurringIssueInput{
+		ProjectID:     projectID,
+		Name:          "No title",
+		ScheduleKind:  "cron",
+		CronExpr:      &cronExpr,
+		TitleTemplate: " ",
+	})
+	require.ErrorIs(t, err, ErrValidation)
+
+	otherProjectID := createTestProject(t, db, orgID, "Other Project")
+	var flowID string
+	require.NoError(t, db.QueryRow(
+		`INSERT INTO project_flow_templates (org_id, project_id, name) VALUES ($1, $2, 'Report') RETURNING id`,
+		orgID, otherProjectID,
+	).Scan(&flowID))
+	_, err = recurring.Create(ctx, CreateRecurringIssueInput{
+		ProjectID:      projectID,
+		Name:           "Wrong flow",
+		TitleTemplate:  "Report",
+		FlowTemplateID: &flowID,
+		ScheduleKind:   "cron",
+		CronExpr:       &cronExpr,
+	})
+	require.ErrorIs(t, err, ErrValidation)
+
+	items, err := recurring.List(ctx, projectID)
+	require.NoError(t, err)
+	require.Len(t, items, 1)
+
+	due, err := recurring.PickupDue(ctx, 10, now, 5*time.Minute)
+	require.NoError(t, err)
+	require.Len(t, due, 1)
+	require.NotNil(t, due[0].DueAt)
+	require.True(t, dueAt.Equal(*due[0].DueAt))
+
+	again, err := recurring.PickupDue(ctx, 10, now.Add(time.Minute), 5*time.Minute)
+	require.NoError(t, err)
+	require.Empty(t, again)
+
+	// A worker that died before recording the run leaves a stale claim, and
+	// the same occurrence is picked up again.
+	_, err = recurring.PickupDue(ctx, 10, now, 0)
+	require.ErrorIs(t, err, ErrValidation)
+	retried, err := recurring.PickupDue(ctx, 10, now.Add(6*time.Minute), 5*time.Minute)
+	require.NoError(t, err)
+	require.Len(t, retried, 1)
+	require.Equal(t, created.ID, retried[0].ID)
+	require.True(t, dueAt.Equal(*retried[0].DueAt))
+
+	issue, err := NewProjectIssueStore(db).CreateIssue(ctx, CreateProjectIssueInput{ProjectID: projectID, Title: "Weekly metrics report", Origin: "local"})
+	require.NoError(t, err)
+	nextRunAt := now.Add(7 * 24 * time.Hour)
+	recorded, err := recurring.RecordRun(ctx, RecordRecurringIssueRunInput{
+		ID:        created.ID,
+		Status:    RecurringIssueRunCreated,
+		IssueID:   &issue.ID,
+		RanAt:     now,
+		NextRunAt: &nextRunAt,
+	})
+	require.NoError(t, err)
+	require.Equal(t, 1, recorded.RunCount)
+	require.Equal(t, issue.ID, *recorded.LastIssueID)
+	require.True(t, nextRunAt.Equal(*recorded.NextRunAt))
+
+	reason := "previous issue is still open"
+	recorded, err = recurring.RecordRun(ctx, RecordRecurringIssueRunInput{
+		ID:                created.ID,
+		Status:            RecurringIssueRunSkipped,
+		Error:             &reason,
+		RanAt:             now.Add(time.Minute),
+		PreserveNextRunAt: true,
+	})
+	require.NoError(t, err)
+	require.Equal(t, 1, recorded.RunCount)
+	require.Equal(t, issue.ID, *recorded.LastIssueID)
+	require.Equal(t, RecurringIssueRunSkipped, *recorded.LastRunStatus)
+	require.True(t, nextRunAt.Equal(*recorded.NextRunAt))
+
+	var claimed bool
+	require.NoError(t, db.QueryRow(`SELECT claimed_at IS NOT NULL FROM recurring_issues WHERE id = $1`, created.ID).Scan(&claimed))
+	require.False(t, claimed, "recording the scheduled run releases the claim")
+
+	disabled := false
+	empty := ""
+	updated, err := recurring.Update(ctx, created.ID, UpdateRecurringIssueInput{
+		Enabled:        &disabled,
+		OwnerAgentID:   &empty,
+		ClearNextRunAt: true,
+	})
+	require.NoError(t, err)
+	require.False(t, updated.Enabled)
+	require.Nil(t, updated.OwnerAgentID)
+	require.Nil(t, updated.NextRunAt)
+
+	otherOrgID := createTestOrganization(t, db, "recurring-issue-other-org")
+	_, err = recurring.GetByID(ctxWithWorkspace(otherOrgID), created.ID)
+	require.ErrorIs(t, err, ErrNotFound)
+
+	require.NoError(t, recurring.Delete(ctx, created.ID))
+	require.ErrorIs(t, recurring.Delete(ctx, created.ID), ErrNotFound)
+}
@@ -0,0 +1,585 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/lib/pq"
+	"github.com/samhotchkiss/otter-camp/internal/middleware"
+)
+
+// builtinRoles are defined in code (see api.roleCapabilityMatrix) and can be
+// bound but not created, edited or deleted.
+var builtinRoles = map[string]bool{
+	"owner":      true,
+	"maintainer": true,
+	"member":     true,
+	"viewer":     true,
+}
+
+var (
+	orgRoleNamePattern    = regexp.MustCompile(`^[a-z][a-z0-9_-]{0,62}$`)
+	capabilityNamePattern = regexp.MustCompile(`^[a-z][a-z0-9_]*(\.[a-z0-9_]+)+$`)
+)
+
+// IsBuiltinRole reports whether name is one of the code-defined roles.
+func IsBuiltinRole(name string) bool {
+	return builtinRoles[strings.TrimSpace(strings.ToLower(name))]
+}
+
+// OrgRole is a custom role: a named set of capabilities.
+type OrgRole struct {
+	ID           string
+	OrgID        string
+	Name         string
+	Description  string
+	Capabilities []string
+	CreatedAt    time.Time
+	UpdatedAt    time.Time
+}
+
+type CreateOrgRoleInput struct {
+	Name         string
+	Description  string
+	Capabilities []string
+}
+
+type UpdateOrgRoleInput struct {
+	Description  *string
+	Capabilities *[]string
+}
+
+// RoleBinding grants Role to a user org-wide, or only inside ProjectID.
+type RoleBinding struct {
+	ID        string
+	OrgID     string
+	UserID    string
+	Role      string
+	ProjectID *string
+	CreatedBy *string
+	CreatedAt time.Time
+}
+
+type CreateRoleBindingInput struct {
+	UserID    string
+	Role      string
+	ProjectID *string
+	CreatedBy *string
+}
+
+type RoleBindingFilter struct {
+	UserID    string
+	ProjectID string
+}
+
+// RoleGrant is one role that applies to a user in a given scope. Capabilities
+// is set for custom roles; built-in roles are expanded by the caller.
+type RoleGrant struct {
+	Role         string
+	ProjectID    *string
+	Builtin      bool
+	Capabilities []string
+}
+
+const orgRoleColumns = `
+	id,
+	org_id,
+	name,
+	description,
+	capabilities,
+	created_at,
+	updated_at
+`
+
+const roleBindingColumns = `
+	id,
+	org_id,
+	user_id,
+	role,
+	project_id,
+	created_by,
+	created_at
+`
+
+type RoleStore struct {
+	db *sql.DB
+}
+
+func NewRoleStore(db *sql.DB) *RoleStore {
+	return &RoleStore{db: db}
+}
+
+func (s *RoleStore) ListRoles(ctx context.Context) ([]OrgRole, error) {
+	if s == nil || s.db == nil {
+		return nil, fmt.Errorf("role store is not configured")
+	}
+	conn, err := WithWorkspace(ctx, s.db)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	rows, err := conn.QueryContext(ctx, `SELECT`+orgRoleColumns+` FROM org_roles ORDER BY name ASC`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list roles: %w", err)
+	}
+	defer rows.Close()
+
+	out := make([]OrgRole, 0)
+	for rows.Next() {
+		role, err := scanOrgRole(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan role: %w", err)
+		}
+		out = append(out, role)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed reading roles: %w", err)
+	}
+	return out, nil
+}
+
+func (s *RoleStore) GetRole(ctx context.Context, roleID string) (*OrgRole, error) {
+	if s == nil || s.db == nil {
+		return nil, fmt.Errorf("role store is not configured")
+	}
+	roleID = strings.TrimSpace(roleID)
+	if !uuidRegex.MatchString(roleID) {
+		return nil, fmt.Errorf("%w: invalid role_id", ErrValidation)
+	}
+	conn, err := WithWorkspace(ctx, s.db)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	role, err := scanOrgRole(conn.QueryRowContext(ctx, `SELECT`+orgRoleColumns+` FROM org_roles WHERE id = $1`, roleID))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrNotFound
+		}
+		return nil, fmt.Errorf("failed to load role: %w", err)
+	}
+	return &role, nil
+}
+
+func (s *RoleStore) CreateRole(ctx context.Context, input CreateOrgRoleInput) (*OrgRole, error) {
+	if s == nil || s.db == nil {
+		return nil, fmt.Errorf("role store is not configured")
+	}
+	workspaceID := strings.TrimSpace(middleware.WorkspaceFromContext(ctx))
+	if workspaceID == "" {
+		return nil, ErrNoWorkspace
+	}
+
+	name := strings.TrimSpace(strings.ToLower(input.Name))
+	if !orgRoleNamePattern.MatchString(name) {
+		return nil, fmt.Errorf("%w: role name must be lowercase letters, digits, '-' or '_'", ErrValidation)
+	}
+	if builtinRoles[name] {
+		return nil, fmt.Errorf("%w: %q is a built-in role", ErrValidation, name)
+	}
+	capabilities, err := normalizeCapabilityList(input.Capabilities)
+	if err != nil {
+		return nil, err
+	}
+
+	conn, err := WithWorkspace(ctx, s.db)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	role, err := scanOrgRole(conn.QueryRowContext(
+		ctx,
+		`INSERT INTO org_roles (org_id, name, description, capabilities)
+		 VALUES ($1, $2, $3, $4)
+		 RETURNING`+orgRoleColumns,
+		workspaceID,
+		name,
+		strings.TrimSpace(input.Description),
+		pq.Array(capabilities),
+	))
+	if err != nil {
+		if isUniqueViolation(err) {
+			return nil, fmt.Errorf("%w: a role named %q already exists", ErrConflict, name)
+		}
+		return nil, fmt.Errorf("failed to create role: %w", err)
+	}
+	return &role, nil
+}
+
+// UpdateRole changes a role's description or capabilities. Names are fixed,
+// since bindings refer to roles by name.
+func (s *RoleStore) UpdateRole(ctx context.Context, roleID string, input UpdateOrgRoleInput) (*OrgRole, error) {
+	current, err := s.GetRole(ctx, roleID)
+	if err != nil {
+		return nil, err
+	}
+	description := current.Description
+	if input.Description != nil {
+		description = strings.TrimSpace(*input.Description)
+	}
+	capabilities := current.Capabilities
+	if input.Capabilities != nil {
+		capabilities, err = normalizeCapabilityList(*input.Capabilities)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	conn, err := WithWorkspace(ctx, s.db)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	role, err := scanOrgRole(conn.QueryRowContext(
+		ctx,
+		`UPDATE org_roles SET description = $2, capabilities = $3
+		 WHERE id = $1
+		 RETURNING`+orgRoleColumns,
+		current.ID,
+		description,
+		pq.Array(capabilities),
+	))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrNotFound
+		}
+		return nil, fmt.Errorf("failed to update role: %w", err)
+	}
+	return &role, nil
+}
+
+// DeleteRole removes a custom role together with every binding to it.
+func (s *RoleStore) DeleteRole(ctx context.Context, roleID string) error {
+	if s == nil || s.db == nil {
+		return fmt.Errorf("role store is not configured")
+	}
+	roleID = strings.TrimSpace(roleID)
+	if !uuidRegex.MatchString(roleID) {
+		return fmt.Errorf("%w: invalid role_id", ErrValidation)
+	}
+
+	tx, err := WithWorkspaceTx(ctx, s.db)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	var name string
+	if err := tx.QueryRowContext(ctx, `DELETE FROM org_roles WHERE id = $1 RETURNING name`, roleID).Scan(&name); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrNotFound
+		}
+		return fmt.Errorf("failed to delete role: %w", err)
+	}
+	if _, err := tx.ExecContext(ctx, `DELETE FROM role_bindings WHERE role = $1`, name); err != nil {
+		return fmt.Errorf("failed to delete role bindings: %w", err)
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit role delete: %w", err)
+	}
+	return nil
+}
+
+func (s *RoleStore) ListBindings(ctx context.Context, filter RoleBindingFilter) ([]RoleBinding, error) {
+	if s == nil || s.db == nil {
+		return nil, fmt.Errorf("role store is not configured")
+	}
+	conditions := []string{"TRUE"}
+	args := []any{}
+	if userID := strings.TrimSpace(filter.UserID); userID != "" {
+		if !uuidRegex.MatchString(userID) {
+			return nil, fmt.Errorf("%w: invalid user_id", ErrValidation)
+		}
+		args = append(args, userID)
+		conditions = append(conditions, fmt.Sprintf("user_id = $%d", len(args)))
+	}
+	if projectID := strings.TrimSpace(filter.ProjectID); projectID != "" {
+		if !uuidRegex.MatchString(projectID) {
+			return nil, fmt.Errorf("%w: invalid project_id", ErrValidation)
+		}
+		args = append(args, projectID)
+		conditions = append(conditions, fmt.Sprintf("project_id = $%d", len(args)))
+	}
+
+	conn, err := WithWorkspace(ctx, s.db)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	rows, err := conn.QueryContext(
+		ctx,
+		`SELECT`+roleBindingColumns+` FROM role_bindings WHERE `+strings.Join(conditions, " AND ")+
+			` ORDER BY created_at ASC, id ASC`,
+		args...,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list role bindings: %w", err)
+	}
+	defer rows.Close()
+
+	out := make([]RoleBinding, 0)
+	for rows.Next() {
+		binding, err := scanRoleBinding(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan role binding: %w", err)
+		}
+		out = append(out, binding)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed reading role bindings: %w", err)
+	}
+	return out, nil
+}
+
+// CreateBinding grants a built-in or custom role. The user and project must
+// belong to the workspace.
+func (s *RoleStore) CreateBinding(ctx context.Context, input CreateRoleBindingInput) (*RoleBinding, error) {
+	if s == nil || s.db == nil {
+		return nil, fmt.Errorf("role store is not configured")
+	}
+	workspaceID := strings.TrimSpace(middleware.WorkspaceFromContext(ctx))
+	if workspaceID == "" {
+		return nil, ErrNoWorkspace
+	}
+	userID := strings.TrimSpace(input.UserID)
+	if !uuidRegex.MatchString(userID) {
+		return nil, fmt.Errorf("%w: invalid user_id", ErrValidation)
+	}
+	role := strings.TrimSpace(strings.ToLower(input.Role))
+	if role == "" {
+		return nil, fmt.Errorf("%w: role is required", ErrValidation)
+	}
+	var projectID *string
+	if input.ProjectID != nil && strings.TrimSpace(*input.ProjectID) != "" {
+		trimmed := strings.TrimSpace(*input.ProjectID)
+		if !uuidRegex.MatchString(trimmed) {
+			return nil, fmt.Errorf("%w: invalid project_id", ErrValidation)
+		}
+		projectID = &trimmed
+	}
+
+	conn, err := WithWorkspace(ctx, s.db)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	if !builtinRoles[role] {
+		var exists bool
+		if err := conn.QueryRowContext(ctx, `SELECT EXISTS (SELECT 1 FROM org_roles WHERE name = $1)`, role).Scan(&exists); err != nil {
+			return nil, fmt.Errorf("failed to check role: %w", err)
+		}
+		if !exists {
+			return nil, fmt.Errorf("%w: unknown role %q", ErrValidation, role)
+		}
+	}
+	var userExists bool
+	if err := conn.QueryRowContext(
+		ctx,
+		`SELECT EXISTS (SELECT 1 FROM users WHERE id = $1 AND org_id = $2)`,
+		userID,
+		workspaceID,
+	).Scan(&userExists); err != nil {
+		return nil, fmt.Errorf("failed to check user: %w", err)
+	}
+	if !userExists {
+		return nil, fmt.Errorf("%w: user not found in this org", ErrValidation)
+	}
+	if projectID != nil {
+		var projectExists bool
+		if err := conn.QueryRowContext(ctx, `SELECT EXISTS (SELECT 1 FROM projects WHERE id = $1)`, *projectID).Scan(&projectExists); err != nil {
+			return nil, fmt.Errorf("failed to check project: %w", err)
+		}
+		if !projectExists {
+			return nil, fmt.Errorf("%w: project not found in this org", ErrValidation)
+		}
+	}
+
+	binding, err := scanRoleBinding(conn.QueryRowContext(
+		ctx,
+		`INSERT INTO role_bindings (org_id, user_id, role, project_id, created_by)
+		 VALUES ($1, $2, $3, $4, $5)
+		 RETURNING`+roleBindingColumns,
+		workspaceID,
+		userID,
+		role,
+		nullableString(projectID),
+		nullableString(input.CreatedBy),
+	))
+	if err != nil {
+		if isUniqueViolation(err) {
+			return nil, fmt.Errorf("%w: binding already exists", ErrConflict)
+		}
+		return nil, fmt.Errorf("failed to create role binding: %w", err)
+	}
+	return &binding, nil
+}
+
+func (s *RoleStore) DeleteBinding(ctx context.Context, bindingID string) error {
+	if s == nil || s.db == nil {
+		return fmt.Errorf("role store is not configured")
+	}
+	bindingID = strings.TrimSpace(bindingID)
+	if !uuidRegex.MatchString(bindingID) {
+		return fmt.Errorf("%w: invalid binding_id", ErrValidation)
+	}
+	conn, err := WithWorkspace(ctx, s.db)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	result, err := conn.ExecContext(ctx, `DELETE FROM role_bindings WHERE id = $1`, bindingID)
+	if err != nil {
+		return fmt.Errorf("failed to delete role binding: %w", err)
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to delete role binding: %w", err)
+	}
+	if affected == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// GrantsForUser returns the roles bound to a user org-wide plus, when
+// projectID is set, those bound to that project. It runs during request
+// authorization, before any workspace is on the context.
+func (s *RoleStore) GrantsForUser(ctx context.Context, orgID, userID, projectID string) ([]RoleGrant, error) {
+	if s == nil || s.db == nil {
+		return nil, fmt.Errorf("role store is not configured")
+	}
+	var project any
+	if projectID = strings.TrimSpace(projectID); projectID != "" {
+		if !uuidRegex.MatchString(projectID) {
+			return nil, fmt.Errorf("%w: invalid project_id", ErrValidation)
+		}
+		project = projectID
+	}
+	conn, err := WithWorkspaceID(ctx, s.db, orgID)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	rows, err := conn.QueryContext(
+		ctx,
+		`SELECT b.role, b.project_id, r.capabilities
+		 FROM role_bindings b
+		 LEFT JOIN org_roles r ON r.org_id = b.org_id AND r.name = b.role
+		 WHERE b.org_id = $1
+		   AND b.user_id = $2
+		   AND (b.project_id IS NULL OR b.project_id = $3::uuid)
+		 ORDER BY b.project_id NULLS FIRST, b.role`,
+		orgID,
+		userID,
+		project,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load role grants: %w", err)
+	}
+	defer rows.Close()
+
+	out := make([]RoleGrant, 0)
+	for rows.Next() {
+		var (
+			grant        RoleGrant
+			grantProject sql.NullString
+			capabilities pq.StringArray
+		)
+		if err := rows.Scan(&grant.Role, &grantProject, &capabilities); err != nil {
+			return nil, fmt.Errorf("failed to scan role grant: %w", err)
+		}
+		if grantProject.Valid {
+			grant.ProjectID = &grantProject.String
+		}
+		grant.Builtin = builtinRoles[grant.Role]
+		grant.Capabilities = []string(capabilities)
+		out = append(out, grant)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed reading role grants: %w", err)
+	}
+	return out, nil
+}
+
+func normalizeCapabilityList(values []string) ([]string, error) {
+	seen := make(map[string]bool, len(values))
+	out := make([]string, 0, len(values))
+	for _, value := range values {
+		capability := strings.TrimSpace(strings.ToLower(value))
+		if capability == "" || seen[capability] {
+			continue
+		}
+		if !capabilityNamePattern.MatchString(capability) {
+			return nil, fmt.Errorf("%w: invalid capability %q", ErrValidation, value)
+		}
+		seen[capability] = true
+		out = append(out, capability)
+	}
+	sort.Strings(out)
+	return out, nil
+}
+
+func isUniqueViolation(err error) bool {
+	var pqErr *pq.Error
+	return errors.As(err, &pqErr) && pqErr.Code == "23505"
+}
+
+func scanOrgRole(scanner interface{ Scan(...any) error }) (OrgRole, error) {
+	var (
+		role         OrgRole
+		capabilities pq.StringArray
+	)
+	if err := scanner.Scan(
+		&role.ID,
+		&role.OrgID,
+		&role.Name,
+		&role.Description,
+		&capabilities,
+		&role.CreatedAt,
+		&role.UpdatedAt,
+	); err != nil {
+		return OrgRole{}, err
+	}
+	role.Capabilities = []string(capabilities)
+	return role, nil
+}
+
+func scanRoleBinding(scanner interface{ Scan(...any) error }) (RoleBinding, error) {
+	var (
+		binding   RoleBinding
+		projectID sql.NullString
+		createdBy sql.NullString
+	)
+	if err := scanner.Scan(
+		&binding.ID,
+		&binding.OrgID,
+		&binding.UserID,
+		&binding.Role,
+		&projectID,
+		&createdBy,
+		&binding.CreatedAt,
+	); err != nil {
+		return RoleBinding{}, err
+	}
+	if projectID.Valid {
+		binding.ProjectID = &projectID.String
+	}
+	if createdBy.Valid {
+		binding.CreatedBy = &createdBy.String
+	}
+	return binding, nil
+}
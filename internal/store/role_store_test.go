@@ -0,0 +1,90 @@
+package store
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestNormalizeCapabilityListTrimsDedupesAndValidates(t *testing.T) {
+	got, err := normalizeCapabilityList([]string{" Issues.Write ", "projects.manage", "issues.write", ""})
+	require.NoError(t, err)
+	require.Equal(t, []string{"issues.write", "projects.manage"}, got)
+
+	for _, bad := range []string{"issues", "issues.", ".write", "issues write"} {
+		_, err := normalizeCapabilityList([]string{bad})
+		require.ErrorIs(t, err, ErrValidation, bad)
+	}
+}
+
+func TestRoleStoreCRUDBindingsAndGrants(t *testing.T) {
+	connStr := getTestDatabaseURL(t)
+	db := setupTestDatabase(t, connStr)
+	orgID := createTestOrganization(t, db, "roles-org")
+	ctx := ctxWithWorkspace(orgID)
+	projectID := createTestProject(t, db, orgID, "Roles Project")
+	otherProjectID := createTestProject(t, db, orgID, "Other Roles Project")
+
+	var userID string
+	require.NoError(t, db.QueryRow(
+		`INSERT INTO users (org_id, subject, issuer, display_name, email, role)
+		 VALUES ($1, 'roles-user', 'openclaw', 'Roles User', 'roles@example.com', 'viewer')
+		 RETURNING id`,
+		orgID,
+	).Scan(&userID))
+
+	roles := NewRoleStore(db)
+	_, err := roles.CreateRole(ctx, CreateOrgRoleInput{Name: "owner"})
+	require.ErrorIs(t, err, ErrValidation)
+
+	created, err := roles.CreateRole(ctx, CreateOrgRoleInput{
+		Name:         "triager",
+		Description:  "Triage issues",
+		Capabilities: []string{"issues.write"},
+	})
+	require.NoError(t, err)
+	require.Equal(t, []string{"issues.write"}, created.Capabilities)
+
+	_, err = roles.CreateRole(ctx, CreateOrgRoleInput{Name: "triager"})
+	require.ErrorIs(t, err, ErrConflict)
+
+	caps := []string{"issues.write", "memory.write"}
+	updated, err := roles.UpdateRole(ctx, created.ID, UpdateOrgRoleInput{Capabilities: &caps})
+	require.NoError(t, err)
+	require.Equal(t, caps, updated.Capabilities)
+
+	_, err = roles.CreateBinding(ctx, CreateRoleBindingInput{UserID: userID, Role: "nope"})
+	require.ErrorIs(t, err, ErrValidation)
+
+	binding, err := roles.CreateBinding(ctx, CreateRoleBindingInput{UserID: userID, Role: "triager", ProjectID: &projectID})
+	require.NoError(t, err)
+	_, err = roles.CreateBinding(ctx, CreateRoleBindingInput{UserID: userID, Role: "triager", ProjectID: &projectID})
+	require.ErrorIs(t, err, ErrConflict)
+	_, err = roles.CreateBinding(ctx, CreateRoleBindingInput{UserID: userID, Role: "member"})
+	require.NoError(t, err)
+
+	grants, err := roles.GrantsForUser(context.Background(), orgID, userID, projectID)
+	require.NoError(t, err)
+	require.Len(t, grants, 2)
+
+	grants, err = roles.GrantsForUser(context.Background(), orgID, userID, otherProjectID)
+	require.NoError(t, err)
+	require.Len(t, grants, 1)
+	require.True(t, grants[0].Builtin)
+	require.Equal(t, "member", grants[0].Role)
+
+	bindings, err := roles.ListBindings(ctx, RoleBindingFilter{ProjectID: projectID})
+	require.NoError(t, err)
+	require.Len(t, bindings, 1)
+	require.Equal(t, binding.ID, bindings[0].ID)
+
+	require.NoError(t, roles.DeleteRole(ctx, created.ID))
+	bindings, err = roles.ListBindings(ctx, RoleBindingFilter{UserID: userID})
+	require.NoError(t, err)
+	require.Len(t, bindings, 1)
+	require.Equal(t, "member", bindings[0].Role)
+
+	require.NoError(t, roles.DeleteBinding(ctx, bindings[0].ID))
+	require.ErrorIs(t, roles.DeleteBinding(ctx, bindings[0].ID), ErrNotFound)
+}
@@ -2298,3 +2298,387 @@ func TestMigration089IssueEllieContextGateFilesExistAndContainCoreDDL(t *testing
 	require.Contains(t, downContent, "drop column if exists ellie_context_gate_error")
 	require.Contains(t, downContent, "drop column if exists ellie_context_gate_checked_at")
 }
+
+func TestMigration093EmissionsFilesExistAndContainCoreDDL(t *testing.T) {
+	migrationsDir := getMigrationsDir(t)
+	files := []string{
+		"093_create_emissions.up.sql",
+		"093_create_emissions.down.sql",
+	}
+	for _, filename := range files {
+		_, err := os.Stat(filepath.Join(migrationsDir, filename))
+		require.NoError(t, err)
+	}
+
+	upRaw, err := os.ReadFile(filepath.Join(migrationsDir, "093_create_emissions.up.sql"))
+	require.NoError(t, err)
+	upContent := strings.ToLower(string(upRaw))
+	require.Contains(t, upContent, "create table if not exists emissions")
+	require.Contains(t, upContent, "create table if not exists emission_latest_by_source")
+	require.Contains(t, upContent, "unique (org_id, id)")
+	require.Contains(t, upContent, "emissions_org_isolation")
+	require.Contains(t, upContent, "emission_latest_by_source_org_isolation")
+
+	downRaw, err := os.ReadFile(filepath.Join(migrationsDir, "093_create_emissions.down.sql"))
+	require.NoError(t, err)
+	downContent := strings.ToLower(string(downRaw))
+	require.Contains(t, downContent, "drop table if exists emission_latest_by_source")
+	require.Contains(t, downContent, "drop table if exists emissions")
+}
+
+func TestMigration094ExecApprovalRulesFilesExistAndContainCoreDDL(t *testing.T) {
+	migrationsDir := getMigrationsDir(t)
+	files := []string{
+		"094_create_exec_approval_rules.up.sql",
+		"094_create_exec_approval_rules.down.sql",
+	}
+	for _, filename := range files {
+		_, err := os.Stat(filepath.Join(migrationsDir, filename))
+		require.NoError(t, err)
+	}
+
+	upRaw, err := os.ReadFile(filepath.Join(migrationsDir, "094_create_exec_approval_rules.up.sql"))
+	require.NoError(t, err)
+	upContent := strings.ToLower(string(upRaw))
+	require.Contains(t, upContent, "create table if not exists exec_approval_rules")
+	require.Contains(t, upContent, "create table if not exists exec_approval_votes")
+	require.Contains(t, upContent, "add column if not exists rule_id uuid")
+	require.Contains(t, upContent, "add column if not exists required_approvals")
+	require.Contains(t, upContent, "exec_approval_rules_org_isolation")
+	require.Contains(t, upContent, "exec_approval_votes_org_isolation")
+
+	downRaw, err := os.ReadFile(filepath.Join(migrationsDir, "094_create_exec_approval_rules.down.sql"))
+	require.NoError(t, err)
+	downContent := strings.ToLower(string(downRaw))
+	require.Contains(t, downContent, "drop table if exists exec_approval_votes")
+	require.Contains(t, downContent, "drop column if exists rule_id")
+	require.Contains(t, downContent, "drop table if exists exec_approval_rules")
+}
+
+func TestMigration095SSOProvidersFilesExistAndContainCoreDDL(t *testing.T) {
+	migrationsDir := getMigrationsDir(t)
+	files := []string{
+		"095_create_sso_providers.up.sql",
+		"095_create_sso_providers.down.sql",
+	}
+	for _, filename := range files {
+		_, err := os.Stat(filepath.Join(migrationsDir, filename))
+		require.NoError(t, err)
+	}
+
+	upRaw, err := os.ReadFile(filepath.Join(migrationsDir, "095_create_sso_providers.up.sql"))
+	require.NoError(t, err)
+	upContent := strings.ToLower(string(upRaw))
+	require.Contains(t, upContent, "create table if not exists org_sso_providers")
+	require.Contains(t, upContent, "create table if not exists sso_login_states")
+	require.Contains(t, upContent, "add column if not exists sso_enforced boolean")
+	require.Contains(t, upContent, "org_sso_providers_org_isolation")
+	require.Contains(t, upContent, "role_mappings jsonb")
+
+	downRaw, err := os.ReadFile(filepath.Join(migrationsDir, "095_create_sso_providers.down.sql"))
+	require.NoError(t, err)
+	downContent := strings.ToLower(string(downRaw))
+	require.Contains(t, downContent, "drop table if exists sso_login_states")
+	require.Contains(t, downContent, "drop column if exists sso_enforced")
+	require.Contains(t, downContent, "drop table if exists org_sso_providers")
+}
+
+func TestMigration096RolesFilesExistAndContainCoreDDL(t *testing.T) {
+	migrationsDir := getMigrationsDir(t)
+	files := []string{
+		"096_create_roles.up.sql",
+		"096_create_roles.down.sql",
+	}
+	for _, filename := range files {
+		_, err := os.Stat(filepath.Join(migrationsDir, filename))
+		require.NoError(t, err)
+	}
+
+	upRaw, err := os.ReadFile(filepath.Join(migrationsDir, "096_create_roles.up.sql"))
+	require.NoError(t, err)
+	upContent := strings.ToLower(string(upRaw))
+	require.Contains(t, upContent, "create table if not exists org_roles")
+	require.Contains(t, upContent, "create table if not exists role_bindings")
+	require.Contains(t, upContent, "capabilities text[]")
+	require.Contains(t, upContent, "org_roles_org_isolation")
+	require.Contains(t, upContent, "role_bindings_org_isolation")
+	require.Contains(t, upContent, "role_bindings_unique_idx")
+
+	downRaw, err := os.ReadFile(filepath.Join(migrationsDir, "096_create_roles.down.sql"))
+	require.NoError(t, err)
+	downContent := strings.ToLower(string(downRaw))
+	require.Contains(t, downContent, "drop table if exists role_bindings")
+	require.Contains(t, downContent, "drop table if exists org_roles")
+}
+
+func TestMigration097ScopedAPIKeysFilesExistAndContainCoreDDL(t *testing.T) {
+	migrationsDir := getMigrationsDir(t)
+	files := []string{
+		"097_scope_api_keys.up.sql",
+		"097_scope_api_keys.down.sql",
+	}
+	for _, filename := range files {
+		_, err := os.Stat(filepath.Join(migrationsDir, filename))
+		require.NoError(t, err)
+	}
+
+	upRaw, err := os.ReadFile(filepath.Join(migrationsDir, "097_scope_api_keys.up.sql"))
+	require.NoError(t, err)
+	upContent := strings.ToLower(string(upRaw))
+	require.Contains(t, upContent, "alter table api_keys")
+	require.Contains(t, upContent, "add column if not exists project_ids uuid[]")
+	require.Contains(t, upContent, "add column if not exists agent_ids uuid[]")
+	require.Contains(t, upContent, "add column if not exists allowed_ips text[]")
+	require.Contains(t, upContent, "api_keys_key_hash_idx")
+
+	downRaw, err := os.ReadFile(filepath.Join(migrationsDir, "097_scope_api_keys.down.sql"))
+	require.NoError(t, err)
+	downContent := strings.ToLower(string(downRaw))
+	require.Contains(t, downContent, "drop index if exists api_keys_key_hash_idx")
+	require.Contains(t, downContent, "drop column if exists project_ids")
+}
+
+func TestMigration098AuditLogFilesExistAndContainCoreDDL(t *testing.T) {
+	migrationsDir := getMigrationsDir(t)
+	files := []string{
+		"098_create_audit_log.up.sql",
+		"098_create_audit_log.down.sql",
+	}
+	for _, filename := range files {
+		_, err := os.Stat(filepath.Join(migrationsDir, filename))
+		require.NoError(t, err)
+	}
+
+	upRaw, err := os.ReadFile(filepath.Join(migrationsDir, "098_create_audit_log.up.sql"))
+	require.NoError(t, err)
+	upContent := strings.ToLower(string(upRaw))
+	require.Contains(t, upContent, "create table if not exists audit_log")
+	require.Contains(t, upContent, "prev_hash text not null")
+	require.Contains(t, upContent, "unique (org_id, seq)")
+	require.Contains(t, upContent, "before update or delete on audit_log")
+	require.Contains(t, upContent, "audit_log is append-only")
+	require.Contains(t, upContent, "alter table audit_log force row level security")
+
+	downRaw, err := os.ReadFile(filepath.Join(migrationsDir, "098_create_audit_log.down.sql"))
+	require.NoError(t, err)
+	downContent := strings.ToLower(string(downRaw))
+	require.Contains(t, downContent, "drop table if exists audit_log")
+	require.Contains(t, downContent, "drop function if exists audit_log_append_only")
+}
+
+func TestMigration099DispatchTraceparentFilesExist(t *testing.T) {
+	migrationsDir := getMigrationsDir(t)
+
+	upRaw, err := os.ReadFile(filepath.Join(migrationsDir, "099_add_openclaw_dispatch_traceparent.up.sql"))
+	require.NoError(t, err)
+	require.Contains(t, strings.ToLower(string(upRaw)), "add column if not exists traceparent text")
+
+	downRaw, err := os.ReadFile(filepath.Join(migrationsDir, "099_add_openclaw_dispatch_traceparent.down.sql"))
+	require.NoError(t, err)
+	require.Contains(t, strings.ToLower(string(downRaw)), "drop column if exists traceparent")
+}
+
+func TestMigration100RateLimitBucketsFilesExist(t *testing.T) {
+	migrationsDir := getMigrationsDir(t)
+
+	upRaw, err := os.ReadFile(filepath.Join(migrationsDir, "100_create_rate_limit_buckets.up.sql"))
+	require.NoError(t, err)
+	upContent := strings.ToLower(string(upRaw))
+	require.Contains(t, upContent, "create table if not exists rate_limit_buckets")
+	require.Contains(t, upContent, "bucket_key text primary key")
+	require.Contains(t, upContent, "tokens double precision not null")
+	require.Contains(t, upContent, "last_throttled_at timestamptz")
+
+	downRaw, err := os.ReadFile(filepath.Join(migrationsDir, "100_create_rate_limit_buckets.down.sql"))
+	require.NoError(t, err)
+	require.Contains(t, strings.ToLower(string(downRaw)), "drop table if exists rate_limit_buckets")
+}
+
+func TestMigration101UsageAccountingFilesExist(t *testing.T) {
+	migrationsDir := getMigrationsDir(t)
+
+	upRaw, err := os.ReadFile(filepath.Join(migrationsDir, "101_create_usage_accounting.up.sql"))
+	require.NoError(t, err)
+	upContent := strings.ToLower(string(upRaw))
+	require.Contains(t, upContent, "create table if not exists model_pricing")
+	require.Contains(t, upContent, "create table if not exists usage_daily_rollups")
+	require.Contains(t, upContent, "create table if not exists usage_budgets")
+	require.Contains(t, upContent, "add column if not exists paused_by_budget_id uuid")
+	require.Contains(t, upContent, "create policy usage_budgets_org_isolation")
+
+	downRaw, err := os.ReadFile(filepath.Join(migrationsDir, "101_create_usage_accounting.down.sql"))
+	require.NoError(t, err)
+	downContent := strings.ToLower(string(downRaw))
+	require.Contains(t, downContent, "drop column if exists paused_by_budget_id")
+	require.Contains(t, downContent, "drop table if exists usage_budgets")
+	require.Contains(t, downContent, "drop table if exists model_pricing")
+}
+
+func TestMigration102IssueSavedViewsFilesExist(t *testing.T) {
+	migrationsDir := getMigrationsDir(t)
+
+	upRaw, err := os.ReadFile(filepath.Join(migrationsDir, "102_create_issue_saved_views.up.sql"))
+	require.NoError(t, err)
+	upContent := strings.ToLower(string(upRaw))
+	require.Contains(t, upContent, "create table if not exists issue_saved_views")
+	require.Contains(t, upContent, "constraint issue_saved_views_one_scope")
+	require.Contains(t, upContent, "create unique index if not exists issue_saved_views_user_name_idx")
+	require.Contains(t, upContent, "create unique index if not exists issue_saved_views_project_name_idx")
+	require.Contains(t, upContent, "create policy issue_saved_views_org_isolation")
+
+	downRaw, err := os.ReadFile(filepath.Join(migrationsDir, "102_create_issue_saved_views.down.sql"))
+	require.NoError(t, err)
+	require.Contains(t, strings.ToLower(string(downRaw)), "drop table if exists issue_saved_views")
+}
+
+func TestMigration103IssueTemplatesFilesExist(t *testing.T) {
+	migrationsDir := getMigrationsDir(t)
+
+	upRaw, err := os.ReadFile(filepath.Join(migrationsDir, "103_create_issue_templates.up.sql"))
+	require.NoError(t, err)
+	upContent := strings.ToLower(string(upRaw))
+	require.Contains(t, upContent, "create table if not exists issue_templates")
+	require.Contains(t, upContent, "create table if not exists issue_field_values")
+	require.Contains(t, upContent, "create unique index if not exists issue_templates_project_name_idx")
+	require.Contains(t, upContent, "constraint issue_field_values_type")
+	require.Contains(t, upContent, "create policy issue_templates_org_isolation")
+	require.Contains(t, upContent, "create policy issue_field_values_org_isolation")
+
+	downRaw, err := os.ReadFile(filepath.Join(migrationsDir, "103_create_issue_templates.down.sql"))
+	require.NoError(t, err)
+	downContent := strings.ToLower(string(downRaw))
+	require.Contains(t, downContent, "drop table if exists issue_field_values")
+	require.Contains(t, downContent, "drop table if exists issue_templates")
+}
+
+func TestMigration104SubIssuePoliciesFilesExist(t *testing.T) {
+	migrationsDir := getMigrationsDir(t)
+
+	upRaw, err := os.ReadFile(filepath.Join(migrationsDir, "104_add_sub_issue_policies.up.sql"))
+	require.NoError(t, err)
+	upContent := strings.ToLower(string(upRaw))
+	require.Contains(t, upContent, "add column if not exists sub_issue_block_parent_close boolean not null default false")
+	require.Contains(t, upContent, "add column if not exists sub_issue_auto_close_parent boolean not null default false")
+	require.Contains(t, upContent, "create index if not exists project_issues_parent_issue_idx")
+
+	downRaw, err := os.ReadFile(filepath.Join(migrationsDir, "104_add_sub_issue_policies.down.sql"))
+	require.NoError(t, err)
+	downContent := strings.ToLower(string(downRaw))
+	require.Contains(t, downContent, "drop index if exists project_issues_parent_issue_idx")
+	require.Contains(t, downContent, "drop column if exists sub_issue_block_parent_close")
+	require.Contains(t, downContent, "drop column if exists sub_issue_auto_close_parent")
+}
+
+func TestMigration105IssueEmbeddingsAndMergeFilesExist(t *testing.T) {
+	migrationsDir := getMigrationsDir(t)
+
+	upRaw, err := os.ReadFile(filepath.Join(migrationsDir, "105_add_issue_embeddings_and_merge.up.sql"))
+	require.NoError(t, err)
+	upContent := strings.ToLower(string(upRaw))
+	require.Contains(t, upContent, "add column if not exists embedding vector(768)")
+	require.Contains(t, upContent, "add column if not exists embedding_1536 vector(1536)")
+	require.Contains(t, upContent, "add column if not exists merged_into_issue_id uuid references project_issues(id) on delete set null")
+	require.Contains(t, upContent, "create trigger trg_project_issues_clear_embedding_on_text_update")
+
+	downRaw, err := os.ReadFile(filepath.Join(migrationsDir, "105_add_issue_embeddings_and_merge.down.sql"))
+	require.NoError(t, err)
+	downContent := strings.ToLower(string(downRaw))
+	require.Contains(t, downContent, "drop trigger if exists trg_project_issues_clear_embedding_on_text_update")
+	require.Contains(t, downContent, "drop column if exists merged_into_issue_id")
+	require.Contains(t, downContent, "drop column if exists embedding_1536")
+}
+
+func TestMigration106IssueReviewThreadsFilesExist(t *testing.T) {
+	migrationsDir := getMigrationsDir(t)
+
+	upRaw, err := os.ReadFile(filepath.Join(migrationsDir, "106_create_issue_review_threads.up.sql"))
+	require.NoError(t, err)
+	upContent := strings.ToLower(string(upRaw))
+	require.Contains(t, upContent, "create table if not exists project_issue_review_threads")
+	require.Contains(t, upContent, "create table if not exists project_issue_review_thread_comments")
+	require.Contains(t, upContent, "anchored_commit_sha text not null")
+	require.Contains(t, upContent, "create policy project_issue_review_threads_org_isolation")
+
+	downRaw, err := os.ReadFile(filepath.Join(migrationsDir, "106_create_issue_review_threads.down.sql"))
+	require.NoError(t, err)
+	downContent := strings.ToLower(string(downRaw))
+	require.Contains(t, downContent, "drop table if exists project_issue_review_thread_comments")
+	require.Contains(t, downContent, "drop table if exists project_issue_review_threads")
+}
+
+func TestMigration107RecurringIssuesFilesExist(t *testing.T) {
+	migrationsDir := getMigrationsDir(t)
+
+	upRaw, err := os.ReadFile(filepath.Join(migrationsDir, "107_create_recurring_issues.up.sql"))
+	require.NoError(t, err)
+	upContent := strings.ToLower(string(upRaw))
+	require.Contains(t, upContent, "create table if not exists recurring_issues")
+	require.Contains(t, upContent, "skip_if_open boolean not null default false")
+	require.Contains(t, upContent, "last_issue_id uuid references project_issues(id) on delete set null")
+	require.Contains(t, upContent, "create policy recurring_issues_org_isolation")
+
+	downRaw, err := os.ReadFile(filepath.Join(migrationsDir, "107_create_recurring_issues.down.sql"))
+	require.NoError(t, err)
+	require.Contains(t, strings.ToLower(string(downRaw)), "drop table if exists recurring_issues")
+}
+
+func TestMigration108IssueImportLinksFilesExist(t *testing.T) {
+	migrationsDir := getMigrationsDir(t)
+
+	upRaw, err := os.ReadFile(filepath.Join(migrationsDir, "108_create_issue_import_links.up.sql"))
+	require.NoError(t, err)
+	upContent := strings.ToLower(string(upRaw))
+	require.Contains(t, upContent, "create table if not exists issue_import_links")
+	require.Contains(t, upContent, "on issue_import_links (project_id, source, kind, external_id)")
+	require.Contains(t, upContent, "comment_id uuid references project_issue_comments(id) on delete cascade")
+	require.Contains(t, upContent, "create policy issue_import_links_org_isolation")
+
+	downRaw, err := os.ReadFile(filepath.Join(migrationsDir, "108_create_issue_import_links.down.sql"))
+	require.NoError(t, err)
+	require.Contains(t, strings.ToLower(string(downRaw)), "drop table if exists issue_import_links")
+}
+
+func TestMigration109IssueStateTransitionsFilesExist(t *testing.T) {
+	migrationsDir := getMigrationsDir(t)
+
+	upRaw, err := os.ReadFile(filepath.Join(migrationsDir, "109_create_issue_state_transitions.up.sql"))
+	require.NoError(t, err)
+	upContent := strings.ToLower(string(upRaw))
+	require.Contains(t, upContent, "create table if not exists project_issue_state_transitions")
+	require.Contains(t, upContent, "after insert or update of work_status, flow_step_key, flow_step_index on project_issues")
+	require.Contains(t, upContent, "create policy project_issue_state_transitions_org_isolation")
+
+	downRaw, err := os.ReadFile(filepath.Join(migrationsDir, "109_create_issue_state_transitions.down.sql"))
+	require.NoError(t, err)
+	downContent := strings.ToLower(string(downRaw))
+	require.Contains(t, downContent, "drop trigger if exists trg_project_issues_record_state_transition")
+	require.Contains(t, downContent, "drop table if exists project_issue_state_transitions")
+}
+
+func TestMigration110EmissionCommitCursorFilesExist(t *testing.T) {
+	migrationsDir := getMigrationsDir(t)
+
+	upRaw, err := os.ReadFile(filepath.Join(migrationsDir, "110_add_emission_commit_cursor.up.sql"))
+	require.NoError(t, err)
+	upContent := strings.ToLower(string(upRaw))
+	require.Contains(t, upContent, "add column if not exists tx_id xid8 not null default pg_current_xact_id()")
+	require.Contains(t, upContent, "on emissions (org_id, tx_id, seq)")
+
+	downRaw, err := os.ReadFile(filepath.Join(migrationsDir, "110_add_emission_commit_cursor.down.sql"))
+	require.NoError(t, err)
+	downContent := strings.ToLower(string(downRaw))
+	require.Contains(t, downContent, "drop index if exists idx_emissions_org_tx_seq")
+	require.Contains(t, downContent, "drop column if exists tx_id")
+}
+
+func TestMigration111RecurringIssueClaimsFilesExist(t *testing.T) {
+	migrationsDir := getMigrationsDir(t)
+
+	upRaw, err := os.ReadFile(filepath.Join(migrationsDir, "111_add_recurring_issue_claims.up.sql"))
+	require.NoError(t, err)
+	require.Contains(t, strings.ToLower(string(upRaw)), "add column if not exists claimed_at timestamptz")
+
+	downRaw, err := os.ReadFile(filepath.Join(migrationsDir, "111_add_recurring_issue_claims.down.sql"))
+	require.NoError(t, err)
+	require.Contains(t, strings.ToLower(string(downRaw)), "drop column if exists claimed_at")
+}
@@ -0,0 +1,819 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/lib/pq"
+	"github.com/samhotchkiss/otter-camp/internal/middleware"
+	"github.com/samhotchkiss/otter-camp/internal/sso"
+)
+
+const (
+	SSOProviderKindOIDC = "oidc"
+	SSOProviderKindSAML = "saml"
+
+	defaultSSOProviderRole = "member"
+)
+
+// SSOProvider is an org's OIDC or SAML identity provider. RoleMappings maps
+// IdP group names to org roles; users matching no group get DefaultRole.
+// AllowedDomains, when set, restricts logins to those email domains.
+type SSOProvider struct {
+	ID                 string
+	OrgID              string
+	Kind               string
+	Name               string
+	Enabled            bool
+	OIDCIssuer         string
+	OIDCClientID       string
+	OIDCClientSecret   string
+	OIDCScopes         []string
+	SAMLIdPEntityID    string
+	SAMLIdPSSOURL      string
+	SAMLIdPCertificate string
+	EmailClaim         string
+	NameClaim          string
+	GroupsClaim        string
+	RoleMappings       map[string]string
+	DefaultRole        string
+	AllowedDomains     []string
+	CreatedAt          time.Time
+	UpdatedAt          time.Time
+}
+
+type CreateSSOProviderInput struct {
+	Kind               string
+	Name               string
+	Enabled            *bool
+	OIDCIssuer         string
+	OIDCClientID       string
+	OIDCClientSecret   string
+	OIDCScopes         []string
+	SAMLIdPEntityID    string
+	SAMLIdPSSOURL      string
+	SAMLIdPCertificate string
+	EmailClaim         string
+	NameClaim          string
+	GroupsClaim        string
+	RoleMappings       map[string]string
+	DefaultRole        string
+	AllowedDomains     []string
+}
+
+type UpdateSSOProviderInput struct {
+	Name               *string
+	Enabled            *bool
+	OIDCIssuer         *string
+	OIDCClientID       *string
+	OIDCClientSecret   *string
+	OIDCScopes         *[]string
+	SAMLIdPEntityID    *string
+	SAMLIdPSSOURL      *string
+	SAMLIdPCertificate *string
+	EmailClaim         *string
+	NameClaim          *string
+	GroupsClaim        *string
+	RoleMappings       *map[string]string
+	DefaultRole        *string
+	AllowedDomains     *[]string
+}
+
+// SSOLoginState is one in-flight login, created when the browser is sent to
+// the IdP and consumed exactly once when it comes back.
+type SSOLoginState struct {
+	ID            string
+	OrgID         string
+	ProviderID    string
+	State         string
+	Nonce         string
+	CodeVerifier  string
+	SAMLRequestID string
+	ReturnTo      string
+	ExpiresAt     time.Time
+	CreatedAt     time.Time
+}
+
+const ssoProviderColumns = `
+	id,
+	org_id,
+	kind,
+	name,
+	enabled,
+	COALESCE(oidc_issuer, ''),
+	COALESCE(oidc_client_id, ''),
+	COALESCE(oidc_client_secret, ''),
+	oidc_scopes,
+	COALESCE(saml_idp_entity_id, ''),
+	COALESCE(saml_idp_sso_url, ''),
+	COALESCE(saml_idp_certificate, ''),
+	email_claim,
+	name_claim,
+	groups_claim,
+	role_mappings,
+	default_role,
+	allowed_domains,
+	created_at,
+	updated_at
+`
+
+type SSOProviderStore struct {
+	db *sql.DB
+}
+
+func NewSSOProviderStore(db *sql.DB) *SSOProviderStore {
+	return &SSOProviderStore{db: db}
+}
+
+func (s *SSOProviderStore) List(ctx context.Context) ([]SSOProvider, error) {
+	if s == nil || s.db == nil {
+		return nil, fmt.Errorf("sso provider store is not configured")
+	}
+	conn, err := WithWorkspace(ctx, s.db)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	rows, err := conn.QueryContext(ctx, `SELECT`+ssoProviderColumns+` FROM org_sso_providers ORDER BY name ASC, id ASC`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list sso providers: %w", err)
+	}
+	return collectSSOProviders(rows)
+}
+
+// ListEnabledForOrg is used before login, when there is no workspace on the
+// context yet.
+func (s *SSOProviderStore) ListEnabledForOrg(ctx context.Context, orgID string) ([]SSOProvider, error) {
+	if s == nil || s.db == nil {
+		return nil, fmt.Errorf("sso provider store is not configured")
+	}
+	conn, err := WithWorkspaceID(ctx, s.db, orgID)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	rows, err := conn.QueryContext(
+		ctx,
+		`SELECT`+ssoProviderColumns+` FROM org_sso_providers WHERE org_id = $1 AND enabled = TRUE ORDER BY name ASC, id ASC`,
+		orgID,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list sso providers: %w", err)
+	}
+	return collectSSOProviders(rows)
+}
+
+func (s *SSOProviderStore) Get(ctx context.Context, providerID string) (*SSOProvider, error) {
+	workspaceID := strings.TrimSpace(middleware.WorkspaceFromContext(ctx))
+	if workspaceID == "" {
+		return nil, ErrNoWorkspace
+	}
+	return s.GetForOrg(ctx, workspaceID, providerID)
+}
+
+func (s *SSOProviderStore) GetForOrg(ctx context.Context, orgID, providerID string) (*SSOProvider, error) {
+	if s == nil || s.db == nil {
+		return nil, fmt.Errorf("sso provider store is not configured")
+	}
+	providerID = strings.TrimSpace(providerID)
+	if !uuidRegex.MatchString(providerID) {
+		return nil, fmt.Errorf("%w: invalid provider_id", ErrValidation)
+	}
+	conn, err := WithWorkspaceID(ctx, s.db, orgID)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	provider, err := scanSSOProvider(conn.QueryRowContext(
+		ctx,
+		`SELECT`+ssoProviderColumns+` FROM org_sso_providers WHERE id = $1 AND org_id = $2`,
+		providerID,
+		orgID,
+	))
+	if err != nil {
+		if errors.Is(err, ErrNotFound) {
+			return nil, ErrNotFound
+		}
+		return nil, fmt.Errorf("failed to load sso provider: %w", err)
+	}
+	return &provider, nil
+}
+
+func (s *SSOProviderStore) Create(ctx context.Context, input CreateSSOProviderInput) (*SSOProvider, error) {
+	if s == nil || s.db == nil {
+		return nil, fmt.Errorf("sso provider store is not configured")
+	}
+	workspaceID := strings.TrimSpace(middleware.WorkspaceFromContext(ctx))
+	if workspaceID == "" {
+		return nil, ErrNoWorkspace
+	}
+
+	provider := SSOProvider{
+		Kind:               input.Kind,
+		Name:               input.Name,
+		Enabled:            true,
+		OIDCIssuer:         input.OIDCIssuer,
+		OIDCClientID:       input.OIDCClientID,
+		OIDCClientSecret:   input.OIDCClientSecret,
+		OIDCScopes:         input.OIDCScopes,
+		SAMLIdPEntityID:    input.SAMLIdPEntityID,
+		SAMLIdPSSOURL:      input.SAMLIdPSSOURL,
+		SAMLIdPCertificate: input.SAMLIdPCertificate,
+		EmailClaim:         input.EmailClaim,
+		NameClaim:          input.NameClaim,
+		GroupsClaim:        input.GroupsClaim,
+		RoleMappings:       input.RoleMappings,
+		DefaultRole:        input.DefaultRole,
+		AllowedDomains:     input.AllowedDomains,
+	}
+	if input.Enabled != nil {
+		provider.Enabled = *input.Enabled
+	}
+	if err := normalizeSSOProvider(&provider); err != nil {
+		return nil, err
+	}
+	roleMappings, err := json.Marshal(provider.RoleMappings)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode role_mappings: %w", err)
+	}
+
+	conn, err := WithWorkspace(ctx, s.db)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	created, err := scanSSOProvider(conn.QueryRowContext(
+		ctx,
+		`INSERT INTO org_sso_providers (
+			org_id,
+			kind,
+			name,
+			enabled,
+			oidc_issuer,
+			oidc_client_id,
+			oidc_client_secret,
+			oidc_scopes,
+			saml_idp_entity_id,
+			saml_idp_sso_url,
+			saml_idp_certificate,
+			email_claim,
+			name_claim,
+			groups_claim,
+			role_mappings,
+			default_role,
+			allowed_domains
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17)
+		RETURNING`+ssoProviderColumns,
+		workspaceID,
+		provider.Kind,
+		provider.Name,
+		provider.Enabled,
+		nullableString(&provider.OIDCIssuer),
+		nullableString(&provider.OIDCClientID),
+		nullableString(&provider.OIDCClientSecret),
+		pq.Array(provider.OIDCScopes),
+		nullableString(&provider.SAMLIdPEntityID),
+		nullableString(&provider.SAMLIdPSSOURL),
+		nullableString(&provider.SAMLIdPCertificate),
+		provider.EmailClaim,
+		provider.NameClaim,
+		provider.GroupsClaim,
+		roleMappings,
+		provider.DefaultRole,
+		pq.Array(provider.AllowedDomains),
+	))
+	if err != nil {
+		if isSSOProviderNameConflict(err) {
+			return nil, fmt.Errorf("%w: an sso provider named %q already exists", ErrConflict, provider.Name)
+		}
+		return nil, fmt.Errorf("failed to create sso provider: %w", err)
+	}
+	return &created, nil
+}
+
+// Update applies input to the provider. The kind cannot change; an empty
+// OIDCClientSecret clears the secret (a public PKCE client).
+func (s *SSOProviderStore) Update(ctx context.Context, providerID string, input UpdateSSOProviderInput) (*SSOProvider, error) {
+	if s == nil || s.db == nil {
+		return nil, fmt.Errorf("sso provider store is not configured")
+	}
+	providerID = strings.TrimSpace(providerID)
+	if !uuidRegex.MatchString(providerID) {
+		return nil, fmt.Errorf("%w: invalid provider_id", ErrValidation)
+	}
+
+	tx, err := WithWorkspaceTx(ctx, s.db)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	provider, err := scanSSOProvider(tx.QueryRowContext(
+		ctx,
+		`SELECT`+ssoProviderColumns+` FROM org_sso_providers WHERE id = $1 FOR UPDATE`,
+		providerID,
+	))
+	if err != nil {
+		if errors.Is(err, ErrNotFound) {
+			return nil, ErrNotFound
+		}
+		return nil, fmt.Errorf("failed to load sso provider: %w", err)
+	}
+
+	applyString := func(dst *string, src *string) {
+		if src != nil {
+			*dst = *src
+		}
+	}
+	applyString(&provider.Name, input.Name)
+	applyString(&provider.OIDCIssuer, input.OIDCIssuer)
+	applyString(&provider.OIDCClientID, input.OIDCClientID)
+	applyString(&provider.OIDCClientSecret, input.OIDCClientSecret)
+	applyString(&provider.SAMLIdPEntityID, input.SAMLIdPEntityID)
+	applyString(&provider.SAMLIdPSSOURL, input.SAMLIdPSSOURL)
+	applyString(&provider.SAMLIdPCertificate, input.SAMLIdPCertificate)
+	applyString(&provider.EmailClaim, input.EmailClaim)
+	applyString(&provider.NameClaim, input.NameClaim)
+	applyString(&provider.GroupsClaim, input.GroupsClaim)
+	applyString(&provider.DefaultRole, input.DefaultRole)
+	if input.Enabled != nil {
+		provider.Enabled = *input.Enabled
+	}
+	if input.OIDCScopes != nil {
+		provider.OIDCScopes = *input.OIDCScopes
+	}
+	if input.RoleMappings != nil {
+		provider.RoleMappings = *input.RoleMappings
+	}
+	if input.AllowedDomains != nil {
+		provider.AllowedDomains = *input.AllowedDomains
+	}
+	if err := normalizeSSOProvider(&provider); err != nil {
+		return nil, err
+	}
+	roleMappings, err := json.Marshal(provider.RoleMappings)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode role_mappings: %w", err)
+	}
+
+	updated, err := scanSSOProvider(tx.QueryRowContext(
+		ctx,
+		`UPDATE org_sso_providers
+		 SET name = $2,
+		     enabled = $3,
+		     oidc_issuer = $4,
+		     oidc_client_id = $5,
+		     oidc_client_secret = $6,
+		     oidc_scopes = $7,
+		     saml_idp_entity_id = $8,
+		     saml_idp_sso_url = $9,
+		     saml_idp_certificate = $10,
+		     email_claim = $11,
+		     name_claim = $12,
+		     groups_claim = $13,
+		     role_mappings = $14,
+		     default_role = $15,
+		     allowed_domains = $16
+		 WHERE id = $1
+		 RETURNING`+ssoProviderColumns,
+		providerID,
+		provider.Name,
+		provider.Enabled,
+		nullableString(&provider.OIDCIssuer),
+		nullableString(&provider.OIDCClientID),
+		nullableString(&provider.OIDCClientSecret),
+		pq.Array(provider.OIDCScopes),
+		nullableString(&provider.SAMLIdPEntityID),
+		nullableString(&provider.SAMLIdPSSOURL),
+		nullableString(&provider.SAMLIdPCertificate),
+		provider.EmailClaim,
+		provider.NameClaim,
+		provider.GroupsClaim,
+		roleMappings,
+		provider.DefaultRole,
+		pq.Array(provider.AllowedDomains),
+	))
+	if err != nil {
+		if isSSOProviderNameConflict(err) {
+			return nil, fmt.Errorf("%w: an sso provider named %q already exists", ErrConflict, provider.Name)
+		}
+		return nil, fmt.Errorf("failed to update sso provider: %w", err)
+	}
+	if !updated.Enabled {
+		if err := ensureSSOEnforcementHasProvider(ctx, tx, updated.OrgID); err != nil {
+			return nil, err
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit sso provider update: %w", err)
+	}
+	return &updated, nil
+}
+
+// Delete removes a provider. The last enabled provider of an SSO-only org
+// cannot be removed, since nobody could log in afterwards.
+func (s *SSOProviderStore) Delete(ctx context.Context, providerID string) error {
+	if s == nil || s.db == nil {
+		return fmt.Errorf("sso provider store is not configured")
+	}
+	workspaceID := strings.TrimSpace(middleware.WorkspaceFromContext(ctx))
+	if workspaceID == "" {
+		return ErrNoWorkspace
+	}
+	providerID = strings.TrimSpace(providerID)
+	if !uuidRegex.MatchString(providerID) {
+		return fmt.Errorf("%w: invalid provider_id", ErrValidation)
+	}
+
+	tx, err := WithWorkspaceTx(ctx, s.db)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	result, err := tx.ExecContext(ctx, `DELETE FROM org_sso_providers WHERE id = $1`, providerID)
+	if err != nil {
+		return fmt.Errorf("failed to delete sso provider: %w", err)
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to delete sso provider: %w", err)
+	}
+	if affected == 0 {
+		return ErrNotFound
+	}
+	if err := ensureSSOEnforcementHasProvider(ctx, tx, workspaceID); err != nil {
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit sso provider delete: %w", err)
+	}
+	return nil
+}
+
+// SSOEnforced reports whether the org only allows SSO logins.
+func (s *SSOProviderStore) SSOEnforced(ctx context.Context, orgID string) (bool, error) {
+	if s == nil || s.db == nil {
+		return false, fmt.Errorf("sso provider store is not configured")
+	}
+	var enforced bool
+	err := s.db.QueryRowContext(ctx, `SELECT sso_enforced FROM organizations WHERE id = $1`, orgID).Scan(&enforced)
+	if errors.Is(err, sql.ErrNoRows) {
+		return false, ErrNotFound
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to load sso enforcement: %w", err)
+	}
+	return enforced, nil
+}
+
+// SetSSOEnforced turns SSO-only login on or off for the workspace. Turning it
+// on requires at least one enabled provider, and revokes every open session
+// of users who did not sign in through one of the org's providers, except
+// keepSessionToken (the admin making the change). It returns how many
+// sessions were revoked.
+func (s *SSOProviderStore) SetSSOEnforced(ctx context.Context, enforced bool, keepSessionToken string) (int64, error) {
+	if s == nil || s.db == nil {
+		return 0, fmt.Errorf("sso provider store is not configured")
+	}
+	workspaceID := strings.TrimSpace(middleware.WorkspaceFromContext(ctx))
+	if workspaceID == "" {
+		return 0, ErrNoWorkspace
+	}
+
+	tx, err := WithWorkspaceTx(ctx, s.db)
+	if err != nil {
+		return 0, err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	if _, err := tx.ExecContext(ctx, `UPDATE organizations SET sso_enforced = $2 WHERE id = $1`, workspaceID, enforced); err != nil {
+		return 0, fmt.Errorf("failed to update sso enforcement: %w", err)
+	}
+	if err := ensureSSOEnforcementHasProvider(ctx, tx, workspaceID); err != nil {
+		return 0, err
+	}
+
+	var revoked int64
+	if enforced {
+		result, err := tx.ExecContext(
+			ctx,
+			`UPDATE sessions s
+			 SET revoked_at = NOW()
+			 FROM users u
+			 WHERE u.id = s.user_id
+			   AND s.org_id = $1
+			   AND s.revoked_at IS NULL
+			   AND s.token <> $2
+			   AND u.issuer NOT IN (
+				SELECT COALESCE(p.oidc_issuer, p.saml_idp_entity_id)
+				FROM org_sso_providers p
+				WHERE p.org_id = $1
+			   )`,
+			workspaceID,
+			strings.TrimSpace(keepSessionToken),
+		)
+		if err != nil {
+			return 0, fmt.Errorf("failed to revoke non-sso sessions: %w", err)
+		}
+		revoked, _ = result.RowsAffected()
+	}
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("failed to commit sso enforcement: %w", err)
+	}
+	return revoked, nil
+}
+
+func ensureSSOEnforcementHasProvider(ctx context.Context, q Querier, orgID string) error {
+	var ok bool
+	err := q.QueryRowContext(
+		ctx,
+		`SELECT NOT o.sso_enforced OR EXISTS (
+			SELECT 1 FROM org_sso_providers p WHERE p.org_id = o.id AND p.enabled = TRUE
+		 )
+		 FROM organizations o
+		 WHERE o.id = $1`,
+		orgID,
+	).Scan(&ok)
+	if err != nil {
+		return fmt.Errorf("failed to check sso enforcement: %w", err)
+	}
+	if !ok {
+		return fmt.Errorf("%w: an SSO-only org needs at least one enabled sso provider", ErrValidation)
+	}
+	return nil
+}
+
+func (s *SSOProviderStore) CreateLoginState(ctx context.Context, state SSOLoginState) error {
+	if s == nil || s.db == nil {
+		return fmt.Errorf("sso provider store is not configured")
+	}
+	if strings.TrimSpace(state.State) == "" || state.ExpiresAt.IsZero() {
+		return fmt.Errorf("%w: login state requires state and expires_at", ErrValidation)
+	}
+	_, err := s.db.ExecContext(
+		ctx,
+		`INSERT INTO sso_login_states (
+			org_id, provider_id, state, nonce, code_verifier, saml_request_id, return_to, expires_at
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8)`,
+		state.OrgID,
+		state.ProviderID,
+		state.State,
+		nullableString(&state.Nonce),
+		nullableString(&state.CodeVerifier),
+		nullableString(&state.SAMLRequestID),
+		nullableString(&state.ReturnTo),
+		state.ExpiresAt,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to create sso login state: %w", err)
+	}
+	return nil
+}
+
+// ConsumeLoginState marks the state used and returns it. Unknown, expired
+// and already used states all return ErrNotFound.
+func (s *SSOProviderStore) ConsumeLoginState(ctx context.Context, state string) (*SSOLoginState, error) {
+	if s == nil || s.db == nil {
+		return nil, fmt.Errorf("sso provider store is not configured")
+	}
+	state = strings.TrimSpace(state)
+	if state == "" {
+		return nil, ErrNotFound
+	}
+	var out SSOLoginState
+	err := s.db.QueryRowContext(
+		ctx,
+		`UPDATE sso_login_states
+		 SET consumed_at = NOW()
+		 WHERE state = $1 AND consumed_at IS NULL AND expires_at > NOW()
+		 RETURNING id, org_id, provider_id, state, COALESCE(nonce, ''), COALESCE(code_verifier, ''),
+		           COALESCE(saml_request_id, ''), COALESCE(return_to, ''), expires_at, created_at`,
+		state,
+	).Scan(
+		&out.ID,
+		&out.OrgID,
+		&out.ProviderID,
+		&out.State,
+		&out.Nonce,
+		&out.CodeVerifier,
+		&out.SAMLRequestID,
+		&out.ReturnTo,
+		&out.ExpiresAt,
+		&out.CreatedAt,
+	)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to consume sso login state: %w", err)
+	}
+	// Expired rows are only useful until they expire; clear them as we go.
+	_, _ = s.db.ExecContext(ctx, `DELETE FROM sso_login_states WHERE expires_at < NOW() - INTERVAL '1 day'`)
+	return &out, nil
+}
+
+func normalizeSSOProvider(provider *SSOProvider) error {
+	provider.Kind = strings.ToLower(strings.TrimSpace(provider.Kind))
+	provider.Name = strings.TrimSpace(provider.Name)
+	if provider.Name == "" {
+		return fmt.Errorf("%w: name is required", ErrValidation)
+	}
+
+	switch provider.Kind {
+	case SSOProviderKindOIDC:
+		provider.OIDCIssuer = strings.TrimSpace(provider.OIDCIssuer)
+		provider.OIDCClientID = strings.TrimSpace(provider.OIDCClientID)
+		provider.OIDCClientSecret = strings.TrimSpace(provider.OIDCClientSecret)
+		if err := validateSSOURL(provider.OIDCIssuer, "oidc_issuer"); err != nil {
+			return err
+		}
+		if provider.OIDCClientID == "" {
+			return fmt.Errorf("%w: oidc_client_id is required", ErrValidation)
+		}
+		scopes := normalizeSSOList(provider.OIDCScopes, false)
+		if len(scopes) == 0 {
+			scopes = []string{"openid", "email", "profile"}
+		}
+		provider.OIDCScopes = scopes
+		provider.SAMLIdPEntityID, provider.SAMLIdPSSOURL, provider.SAMLIdPCertificate = "", "", ""
+	case SSOProviderKindSAML:
+		provider.SAMLIdPEntityID = strings.TrimSpace(provider.SAMLIdPEntityID)
+		provider.SAMLIdPSSOURL = strings.TrimSpace(provider.SAMLIdPSSOURL)
+		provider.SAMLIdPCertificate = strings.TrimSpace(provider.SAMLIdPCertificate)
+		if provider.SAMLIdPEntityID == "" {
+			return fmt.Errorf("%w: saml_idp_entity_id is required", ErrValidation)
+		}
+		if err := validateSSOURL(provider.SAMLIdPSSOURL, "saml_idp_sso_url"); err != nil {
+			return err
+		}
+		if err := sso.ValidateCertificatePEM(provider.SAMLIdPCertificate); err != nil {
+			return fmt.Errorf("%w: saml_idp_certificate: %v", ErrValidation, err)
+		}
+		provider.OIDCIssuer, provider.OIDCClientID, provider.OIDCClientSecret = "", "", ""
+		provider.OIDCScopes = []string{}
+	default:
+		return fmt.Errorf("%w: kind must be oidc or saml", ErrValidation)
+	}
+
+	provider.EmailClaim = defaultSSOClaim(provider.EmailClaim, sso.DefaultEmailClaim)
+	provider.NameClaim = defaultSSOClaim(provider.NameClaim, sso.DefaultNameClaim)
+	provider.GroupsClaim = defaultSSOClaim(provider.GroupsClaim, sso.DefaultGroupsClaim)
+
+	provider.DefaultRole = strings.ToLower(strings.TrimSpace(provider.DefaultRole))
+	if provider.DefaultRole == "" {
+		provider.DefaultRole = defaultSSOProviderRole
+	}
+	if !builtinRoles[provider.DefaultRole] {
+		return fmt.Errorf("%w: invalid default_role", ErrValidation)
+	}
+	mappings := make(map[string]string, len(provider.RoleMappings))
+	for group, role := range provider.RoleMappings {
+		group = strings.TrimSpace(group)
+		role = strings.ToLower(strings.TrimSpace(role))
+		if group == "" {
+			return fmt.Errorf("%w: role_mappings group names must not be empty", ErrValidation)
+		}
+		if !builtinRoles[role] {
+			return fmt.Errorf("%w: invalid role %q for group %q", ErrValidation, role, group)
+		}
+		mappings[group] = role
+	}
+	provider.RoleMappings = mappings
+
+	domains := make([]string, 0, len(provider.AllowedDomains))
+	for _, domain := range provider.AllowedDomains {
+		domains = append(domains, strings.TrimPrefix(strings.TrimSpace(domain), "@"))
+	}
+	provider.AllowedDomains = normalizeSSOList(domains, true)
+	return nil
+}
+
+// validateSSOURL requires https, except for loopback hosts so a local mock
+// IdP works in development.
+func validateSSOURL(value, field string) error {
+	if value == "" {
+		return fmt.Errorf("%w: %s is required", ErrValidation, field)
+	}
+	parsed, err := url.Parse(value)
+	if err != nil || parsed.Host == "" {
+		return fmt.Errorf("%w: %s must be an absolute URL", ErrValidation, field)
+	}
+	host := parsed.Hostname()
+	loopback := host == "localhost" || host == "127.0.0.1" || host == "::1"
+	if parsed.Scheme != "https" && !(parsed.Scheme == "http" && loopback) {
+		return fmt.Errorf("%w: %s must use https", ErrValidation, field)
+	}
+	return nil
+}
+
+func defaultSSOClaim(value, fallback string) string {
+	if trimmed := strings.TrimSpace(value); trimmed != "" {
+		return trimmed
+	}
+	return fallback
+}
+
+func normalizeSSOList(values []string, lower bool) []string {
+	seen := make(map[string]bool, len(values))
+	out := make([]string, 0, len(values))
+	for _, value := range values {
+		value = strings.TrimSpace(value)
+		if lower {
+			value = strings.ToLower(value)
+		}
+		if value == "" || seen[value] {
+			continue
+		}
+		seen[value] = true
+		out = append(out, value)
+	}
+	if lower {
+		sort.Strings(out)
+	}
+	return out
+}
+
+func isSSOProviderNameConflict(err error) bool {
+	var pqErr *pq.Error
+	return errors.As(err, &pqErr) && pqErr.Code == "23505"
+}
+
+func collectSSOProviders(rows *sql.Rows) ([]SSOProvider, error) {
+	defer rows.Close()
+	out := make([]SSOProvider, 0)
+	for rows.Next() {
+		provider, err := scanSSOProvider(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan sso provider: %w", err)
+		}
+		out = append(out, provider)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed reading sso providers: %w", err)
+	}
+	return out, nil
+}
+
+func scanSSOProvider(scanner interface{ Scan(...any) error }) (SSOProvider, error) {
+	var (
+		provider       SSOProvider
+		scopes         pq.StringArray
+		roleMappings   []byte
+		allowedDomains pq.StringArray
+	)
+	err := scanner.Scan(
+		&provider.ID,
+		&provider.OrgID,
+		&provider.Kind,
+		&provider.Name,
+		&provider.Enabled,
+		&provider.OIDCIssuer,
+		&provider.OIDCClientID,
+		&provider.OIDCClientSecret,
+		&scopes,
+		&provider.SAMLIdPEntityID,
+		&provider.SAMLIdPSSOURL,
+		&provider.SAMLIdPCertificate,
+		&provider.EmailClaim,
+		&provider.NameClaim,
+		&provider.GroupsClaim,
+		&roleMappings,
+		&provider.DefaultRole,
+		&allowedDomains,
+		&provider.CreatedAt,
+		&provider.UpdatedAt,
+	)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return SSOProvider{}, ErrNotFound
+		}
+		return SSOProvider{}, err
+	}
+	provider.OIDCScopes = []string(scopes)
+	if provider.OIDCScopes == nil {
+		provider.OIDCScopes = []string{}
+	}
+	provider.AllowedDomains = []string(allowedDomains)
+	if provider.AllowedDomains == nil {
+		provider.AllowedDomains = []string{}
+	}
+	provider.RoleMappings = map[string]string{}
+	if len(roleMappings) > 0 {
+		if err := json.Unmarshal(roleMappings, &provider.RoleMappings); err != nil {
+			return SSOProvider{}, fmt.Errorf("failed to decode role_mappings: %w", err)
+		}
+	}
+	return provider, nil
+}
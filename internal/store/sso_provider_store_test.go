@@ -0,0 +1,123 @@
+package store
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/samhotchkiss/otter-camp/internal/sso/ssotest"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNormalizeSSOProviderValidatesKindsAndRoles(t *testing.T) {
+	idp, err := ssotest.New()
+	require.NoError(t, err)
+	defer idp.Close()
+
+	provider := SSOProvider{
+		Kind:           " OIDC ",
+		Name:           " Okta ",
+		OIDCIssuer:     "https://idp.example.com",
+		OIDCClientID:   "client",
+		RoleMappings:   map[string]string{" admins ": "Owner"},
+		AllowedDomains: []string{"@Example.com", "example.com", " "},
+	}
+	require.NoError(t, normalizeSSOProvider(&provider))
+	require.Equal(t, SSOProviderKindOIDC, provider.Kind)
+	require.Equal(t, "Okta", provider.Name)
+	require.Equal(t, []string{"openid", "email", "profile"}, provider.OIDCScopes)
+	require.Equal(t, map[string]string{"admins": "owner"}, provider.RoleMappings)
+	require.Equal(t, defaultSSOProviderRole, provider.DefaultRole)
+	require.Equal(t, []string{"example.com"}, provider.AllowedDomains)
+	require.Equal(t, "groups", provider.GroupsClaim)
+
+	cases := []SSOProvider{
+		{Kind: "ldap", Name: "x"},
+		{Kind: "oidc", Name: "x", OIDCIssuer: "http://idp.example.com", OIDCClientID: "c"},
+		{Kind: "oidc", Name: "x", OIDCIssuer: "https://idp.example.com"},
+		{Kind: "oidc", Name: "x", OIDCIssuer: "https://idp.example.com", OIDCClientID: "c", DefaultRole: "admin"},
+		{Kind: "oidc", Name: "x", OIDCIssuer: "https://idp.example.com", OIDCClientID: "c", RoleMappings: map[string]string{"eng": "root"}},
+		{Kind: "saml", Name: "x", SAMLIdPEntityID: "urn:idp", SAMLIdPSSOURL: "https://idp.example.com/sso", SAMLIdPCertificate: "not a cert"},
+	}
+	for _, tc := range cases {
+		candidate := tc
+		require.ErrorIs(t, normalizeSSOProvider(&candidate), ErrValidation, "%+v", tc)
+	}
+
+	saml := SSOProvider{Kind: "saml", Name: "x", SAMLIdPEntityID: idp.EntityID, SAMLIdPSSOURL: idp.SSOURL, SAMLIdPCertificate: idp.CertPEM}
+	require.NoError(t, normalizeSSOProvider(&saml))
+	require.Empty(t, saml.OIDCScopes)
+}
+
+func TestSSOProviderStoreCRUDEnforcementAndLoginState(t *testing.T) {
+	connStr := getTestDatabaseURL(t)
+	db := setupTestDatabase(t, connStr)
+	orgID := createTestOrganization(t, db, "sso-providers")
+	otherOrgID := createTestOrganization(t, db, "sso-providers-other")
+	ctx := ctxWithWorkspace(orgID)
+	providers := NewSSOProviderStore(db)
+
+	_, err := providers.SetSSOEnforced(ctx, true, "")
+	require.ErrorIs(t, err, ErrValidation)
+
+	created, err := providers.Create(ctx, CreateSSOProviderInput{
+		Kind:         SSOProviderKindOIDC,
+		Name:         "Okta",
+		OIDCIssuer:   "https://idp.example.com",
+		OIDCClientID: "client",
+		RoleMappings: map[string]string{"admins": "owner"},
+	})
+	require.NoError(t, err)
+	require.Equal(t, orgID, created.OrgID)
+	require.Equal(t, map[string]string{"admins": "owner"}, created.RoleMappings)
+
+	_, err = providers.Create(ctx, CreateSSOProviderInput{
+		Kind: SSOProviderKindOIDC, Name: "Okta", OIDCIssuer: "https://idp.example.com", OIDCClientID: "client",
+	})
+	require.ErrorIs(t, err, ErrConflict)
+
+	_, err = providers.Get(ctxWithWorkspace(otherOrgID), created.ID)
+	require.ErrorIs(t, err, ErrNotFound)
+
+	enabled, err := providers.ListEnabledForOrg(context.Background(), orgID)
+	require.NoError(t, err)
+	require.Len(t, enabled, 1)
+
+	_, err = providers.SetSSOEnforced(ctx, true, "")
+	require.NoError(t, err)
+	enforced, err := providers.SSOEnforced(context.Background(), orgID)
+	require.NoError(t, err)
+	require.True(t, enforced)
+
+	disabled := false
+	_, err = providers.Update(ctx, created.ID, UpdateSSOProviderInput{Enabled: &disabled})
+	require.ErrorIs(t, err, ErrValidation)
+	require.ErrorIs(t, providers.Delete(ctx, created.ID), ErrValidation)
+
+	_, err = providers.SetSSOEnforced(ctx, false, "")
+	require.NoError(t, err)
+	role := "viewer"
+	updated, err := providers.Update(ctx, created.ID, UpdateSSOProviderInput{DefaultRole: &role})
+	require.NoError(t, err)
+	require.Equal(t, "viewer", updated.DefaultRole)
+
+	require.NoError(t, providers.CreateLoginState(context.Background(), SSOLoginState{
+		OrgID:        orgID,
+		ProviderID:   created.ID,
+		State:        "state-abc",
+		Nonce:        "nonce",
+		CodeVerifier: "verifier",
+		ReturnTo:     "/projects",
+		ExpiresAt:    time.Now().Add(10 * time.Minute),
+	}))
+	state, err := providers.ConsumeLoginState(context.Background(), "state-abc")
+	require.NoError(t, err)
+	require.Equal(t, created.ID, state.ProviderID)
+	require.Equal(t, "/projects", state.ReturnTo)
+	_, err = providers.ConsumeLoginState(context.Background(), "state-abc")
+	require.ErrorIs(t, err, ErrNotFound)
+
+	require.NoError(t, providers.Delete(ctx, created.ID))
+	_, err = providers.Get(ctx, created.ID)
+	require.ErrorIs(t, err, ErrNotFound)
+}
@@ -13,6 +13,7 @@ import (
 
 	_ "github.com/lib/pq"
 	"github.com/samhotchkiss/otter-camp/internal/middleware"
+	"github.com/samhotchkiss/otter-camp/internal/tracing"
 )
 
 var (
@@ -147,6 +148,14 @@ func WithWorkspace(ctx context.Context, db *sql.DB) (*sql.Conn, error) {
 	return conn, nil
 }
 
+// startStoreSpan opens a span around one store call. Failures are recorded
+// on the caller's span, so store spans only carry timing.
+func startStoreSpan(ctx context.Context, operation string) (context.Context, *tracing.Span) {
+	ctx, span := tracing.StartKind(ctx, "store."+operation, tracing.SpanKindClient)
+	span.SetAttribute("db.system", "postgresql")
+	return ctx, span
+}
+
 // WithWorkspaceID sets the app.org_id session variable for RLS policies
 // using an explicit workspace ID instead of extracting from context.
 // Useful for admin operations or service-to-service calls.
@@ -0,0 +1,1057 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	UsageBudgetScopeAgent   = "agent"
+	UsageBudgetScopeProject = "project"
+
+	UsageBudgetPeriodDay   = "day"
+	UsageBudgetPeriodMonth = "month"
+
+	UsageGroupByAgent   = "agent"
+	UsageGroupByProject = "project"
+	UsageGroupByModel   = "model"
+	UsageGroupByDay     = "day"
+
+	// UsageBudgetTransition kinds.
+	UsageBudgetSoftCrossed = "soft"
+	UsageBudgetHardTripped = "hard"
+	UsageBudgetCleared     = "cleared"
+
+	defaultUsageBudgetSoftPercent = 80
+	usageDateLayout               = "2006-01-02"
+)
+
+// ModelPrice is what one million tokens of a model cost. A model ending in
+// "*" prices every model with that prefix.
+type ModelPrice struct {
+	Model               string    `json:"model"`
+	USDPerMillionTokens float64   `json:"usd_per_million_tokens"`
+	UpdatedAt           time.Time `json:"updated_at"`
+}
+
+// ModelPricing is an org's price list.
+type ModelPricing []ModelPrice
+
+// Lookup finds the price for model: an exact match first, then the longest
+// matching prefix pattern. Model names compare case-insensitively.
+func (p ModelPricing) Lookup(model string) (ModelPrice, bool) {
+	model = normalizeUsageModel(model)
+	if model == "" {
+		return ModelPrice{}, false
+	}
+	var best ModelPrice
+	bestLen := -1
+	for _, price := range p {
+		pattern := normalizeUsageModel(price.Model)
+		if pattern == model {
+			return price, true
+		}
+		prefix, ok := strings.CutSuffix(pattern, "*")
+		if ok && strings.HasPrefix(model, prefix) && len(prefix) > bestLen {
+			best = price
+			bestLen = len(prefix)
+		}
+	}
+	return best, bestLen >= 0
+}
+
+// Cost prices tokens of model. The second result is false when the model
+// has no price, in which case the cost is zero.
+func (p ModelPricing) Cost(model string, tokens int64) (float64, bool) {
+	price, ok := p.Lookup(model)
+	if !ok {
+		return 0, false
+	}
+	return float64(tokens) * price.USDPerMillionTokens / 1_000_000, true
+}
+
+// UsageReportFilter selects rollup days in [From, To) and optionally one
+// agent, project or model.
+type UsageReportFilter struct {
+	From      time.Time
+	To        time.Time
+	AgentID   string
+	ProjectID string
+	Model     string
+	GroupBy   string
+}
+
+type UsageReportRow struct {
+	Key            string  `json:"key"`
+	Label          string  `json:"label,omitempty"`
+	Tokens         int64   `json:"tokens"`
+	CostUSD        float64 `json:"cost_usd"`
+	Events         int64   `json:"events"`
+	UnpricedTokens int64   `json:"unpriced_tokens,omitempty"`
+}
+
+// UsageReport totals usage over a date range. To is exclusive.
+type UsageReport struct {
+	From           string           `json:"from"`
+	To             string           `json:"to"`
+	GroupBy        string           `json:"group_by"`
+	Tokens         int64            `json:"tokens"`
+	CostUSD        float64          `json:"cost_usd"`
+	Events         int64            `json:"events"`
+	UnpricedTokens int64            `json:"unpriced_tokens"`
+	Items          []UsageReportRow `json:"items"`
+}
+
+// UsageBudget caps what an agent or project may spend per day or month.
+// PeriodStart, SpentUSD and the notified/tripped times are the state of the
+// most recent evaluation.
+type UsageBudget struct {
+	ID             string     `json:"id"`
+	OrgID          string     `json:"org_id"`
+	Scope          string     `json:"scope"`
+	ScopeID        string     `json:"scope_id"`
+	Period         string     `json:"period"`
+	LimitUSD       float64    `json:"limit_usd"`
+	SoftPercent    int        `json:"soft_percent"`
+	Hard           bool       `json:"hard"`
+	PeriodStart    string     `json:"period_start,omitempty"`
+	SpentUSD       float64    `json:"spent_usd"`
+	SoftNotifiedAt *time.Time `json:"soft_notified_at,omitempty"`
+	HardTrippedAt  *time.Time `json:"hard_tripped_at,omitempty"`
+	CreatedAt      time.Time  `json:"created_at"`
+	UpdatedAt      time.Time  `json:"updated_at"`
+}
+
+// UpsertUsageBudgetInput creates a budget, or replaces the limit of the
+// existing one for the same scope and period.
+type UpsertUsageBudgetInput struct {
+	Scope       string
+	ScopeID     string
+	Period      string
+	LimitUSD    float64
+	SoftPercent *int
+	Hard        *bool
+}
+
+type UpdateUsageBudgetInput struct {
+	LimitUSD    *float64
+	SoftPercent *int
+	Hard        *bool
+}
+
+// UsageBudgetTransition is a threshold a budget crossed during evaluation.
+type UsageBudgetTransition struct {
+	Kind        string      `json:"kind"`
+	Budget      UsageBudget `json:"budget"`
+	PausedJobs  int         `json:"paused_jobs,omitempty"`
+	ResumedJobs int         `json:"resumed_jobs,omitempty"`
+}
+
+// UsageStore prices agent activity, keeps daily rollups and enforces budgets.
+type UsageStore struct {
+	db *sql.DB
+}
+
+func NewUsageStore(db *sql.DB) *UsageStore {
+	return &UsageStore{db: db}
+}
+
+const usageBudgetColumns = `
+	id,
+	org_id,
+	scope,
+	scope_id,
+	period,
+	limit_usd,
+	soft_percent,
+	hard,
+	period_start,
+	spent_usd,
+	soft_notified_at,
+	hard_tripped_at,
+	created_at,
+	updated_at`
+
+func (s *UsageStore) ListPricing(ctx context.Context, orgID string) (ModelPricing, error) {
+	if s == nil || s.db == nil {
+		return nil, fmt.Errorf("usage store is not configured")
+	}
+	conn, err := WithWorkspaceID(ctx, s.db, orgID)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+	return listModelPricing(ctx, conn, orgID)
+}
+
+func listModelPricing(ctx context.Context, q Querier, orgID string) (ModelPricing, error) {
+	rows, err := q.QueryContext(
+		ctx,
+		`SELECT model, usd_per_million_tokens, updated_at
+		 FROM model_pricing
+		 WHERE org_id = $1
+		 ORDER BY model`,
+		orgID,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list model pricing: %w", err)
+	}
+	defer rows.Close()
+
+	pricing := make(ModelPricing, 0)
+	for rows.Next() {
+		var price ModelPrice
+		if err := rows.Scan(&price.Model, &price.USDPerMillionTokens, &price.UpdatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan model price: %w", err)
+		}
+		pricing = append(pricing, price)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read model pricing: %w", err)
+	}
+	return pricing, nil
+}
+
+// SetPrice creates or replaces the price of model.
+func (s *UsageStore) SetPrice(ctx context.Context, orgID, model string, usdPerMillionTokens float64) (*ModelPrice, error) {
+	if s == nil || s.db == nil {
+		return nil, fmt.Errorf("usage store is not configured")
+	}
+	model = normalizeUsageModel(model)
+	if model == "" {
+		return nil, fmt.Errorf("%w: model is required", ErrValidation)
+	}
+	if strings.Contains(strings.TrimSuffix(model, "*"), "*") {
+		return nil, fmt.Errorf("%w: model may only end in *", ErrValidation)
+	}
+	if usdPerMillionTokens < 0 {
+		return nil, fmt.Errorf("%w: usd_per_million_tokens must not be negative", ErrValidation)
+	}
+	conn, err := WithWorkspaceID(ctx, s.db, orgID)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	var price ModelPrice
+	err = conn.QueryRowContext(
+		ctx,
+		`INSERT INTO model_pricing (org_id, model, usd_per_million_tokens)
+		 VALUES ($1, $2, $3)
+		 ON CONFLICT (org_id, model) DO UPDATE SET
+			usd_per_million_tokens = EXCLUDED.usd_per_million_tokens,
+			updated_at = NOW()
+		 RETURNING model, usd_per_million_tokens, updated_at`,
+		orgID,
+		model,
+		usdPerMillionTokens,
+	).Scan(&price.Model, &price.USDPerMillionTokens, &price.UpdatedAt)
+	if err != nil {
+		return nil, fmt.Errorf("failed to set model price: %w", err)
+	}
+	return &price, nil
+}
+
+func (s *UsageStore) DeletePrice(ctx context.Context, orgID, model string) error {
+	if s == nil || s.db == nil {
+		return fmt.Errorf("usage store is not configured")
+	}
+	conn, err := WithWorkspaceID(ctx, s.db, orgID)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	result, err := conn.ExecContext(
+		ctx,
+		`DELETE FROM model_pricing WHERE org_id = $1 AND model = $2`,
+		orgID,
+		normalizeUsageModel(model),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to delete model price: %w", err)
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// RefreshRollups rebuilds the daily rollups for every UTC day from from
+// through to from the activity events on those days, priced with the org's
+// current price list. Rebuilding is idempotent, so re-ingested events are
+// never counted twice.
+func (s *UsageStore) RefreshRollups(ctx context.Context, orgID string, from, to time.Time) error {
+	ctx, span := startStoreSpan(ctx, "UsageStore.RefreshRollups")
+	defer span.End()
+
+	if s == nil || s.db == nil {
+		return fmt.Errorf("usage store is not configured")
+	}
+	start := usageDay(from)
+	end := usageDay(to).AddDate(0, 0, 1)
+	if !end.After(start) {
+		return fmt.Errorf("%w: rollup range is empty", ErrValidation)
+	}
+
+	tx, err := WithWorkspaceIDTx(ctx, s.db, orgID)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	// Concurrent ingests for one org would otherwise race between the delete
+	// and the insert below.
+	if _, err := tx.ExecContext(ctx, `SELECT pg_advisory_xact_lock(hashtext('usage_rollups:' || $1))`, orgID); err != nil {
+		return fmt.Errorf("failed to lock usage rollups: %w", err)
+	}
+
+	pricing, err := listModelPricing(ctx, tx, orgID)
+	if err != nil {
+		return err
+	}
+
+	rows, err := tx.QueryContext(
+		ctx,
+		`SELECT (started_at AT TIME ZONE 'UTC')::date,
+		        agent_id,
+		        project_id,
+		        COALESCE(lower(btrim(model_used)), ''),
+		        COALESCE(SUM(tokens_used), 0)::bigint,
+		        COUNT(*)
+		 FROM agent_activity_events
+		 WHERE org_id = $1
+		   AND started_at >= $2
+		   AND started_at < $3
+		 GROUP BY 1, 2, 3, 4`,
+		orgID,
+		start,
+		end,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to aggregate activity usage: %w", err)
+	}
+	type rollup struct {
+		day       time.Time
+		agentID   string
+		projectID sql.NullString
+		model     string
+		tokens    int64
+		events    int64
+	}
+	rollups := make([]rollup, 0)
+	for rows.Next() {
+		var row rollup
+		if err := rows.Scan(&row.day, &row.agentID, &row.projectID, &row.model, &row.tokens, &row.events); err != nil {
+			rows.Close()
+			return fmt.Errorf("failed to scan activity usage: %w", err)
+		}
+		rollups = append(rollups, row)
+	}
+	if err := rows.Close(); err != nil {
+		return fmt.Errorf("failed to read activity usage: %w", err)
+	}
+
+	if _, err := tx.ExecContext(
+		ctx,
+		`DELETE FROM usage_daily_rollups WHERE org_id = $1 AND day >= $2 AND day < $3`,
+		orgID,
+		start,
+		end,
+	); err != nil {
+		return fmt.Errorf("failed to clear usage rollups: %w", err)
+	}
+	for _, row := range rollups {
+		cost, priced := pricing.Cost(row.model, row.tokens)
+		if _, err := tx.ExecContext(
+			ctx,
+			`INSERT INTO usage_daily_rollups (
+				org_id, day, agent_id, project_id, model, tokens, cost_usd, events, priced
+			) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)`,
+			orgID,
+			row.day,
+			row.agentID,
+			row.projectID,
+			row.model,
+			row.tokens,
+			cost,
+			row.events,
+			priced || row.tokens == 0,
+		); err != nil {
+			return fmt.Errorf("failed to write usage rollup: %w", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit usage rollups: %w", err)
+	}
+	return nil
+}
+
+// Report totals the rollups matching filter, grouped by agent, project,
+// model or day and ordered by cost.
+func (s *UsageStore) Report(ctx context.Context, orgID string, filter UsageReportFilter) (*UsageReport, error) {
+	ctx, span := startStoreSpan(ctx, "UsageStore.Report")
+	defer span.End()
+
+	if s == nil || s.db == nil {
+		return nil, fmt.Errorf("usage store is not configured")
+	}
+	groupBy := strings.TrimSpace(strings.ToLower(filter.GroupBy))
+	if groupBy == "" {
+		groupBy = UsageGroupByAgent
+	}
+	var keyExpr, labelExpr string
+	switch groupBy {
+	case UsageGroupByAgent:
+		keyExpr, labelExpr = "r.agent_id", "''"
+	case UsageGroupByProject:
+		keyExpr, labelExpr = "COALESCE(r.project_id::text, '')", "COALESCE(MAX(p.name), '')"
+	case UsageGroupByModel:
+		keyExpr, labelExpr = "r.model", "''"
+	case UsageGroupByDay:
+		keyExpr, labelExpr = "to_char(r.day, 'YYYY-MM-DD')", "''"
+	default:
+		return nil, fmt.Errorf("%w: group_by must be agent, project, model or day", ErrValidation)
+	}
+	from := usageDay(filter.From)
+	to := usageDay(filter.To)
+	if !to.After(from) {
+		return nil, fmt.Errorf("%w: to must be after from", ErrValidation)
+	}
+
+	conn, err := WithWorkspaceID(ctx, s.db, orgID)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	conditions := []string{"r.org_id = $1", "r.day >= $2", "r.day < $3"}
+	args := []any{orgID, from, to}
+	if agentID := strings.TrimSpace(filter.AgentID); agentID != "" {
+		agentID, err = resolveUsageAgentID(ctx, conn, orgID, agentID)
+		if err != nil {
+			return nil, err
+		}
+		args = append(args, agentID)
+		conditions = append(conditions, fmt.Sprintf("lower(r.agent_id) = lower($%d)", len(args)))
+	}
+	if projectID := strings.TrimSpace(filter.ProjectID); projectID != "" {
+		if !uuidRegex.MatchString(projectID) {
+			return nil, fmt.Errorf("%w: project_id must be a UUID", ErrValidation)
+		}
+		args = append(args, projectID)
+		conditions = append(conditions, fmt.Sprintf("r.project_id = $%d", len(args)))
+	}
+	if model := normalizeUsageModel(filter.Model); model != "" {
+		args = append(args, model)
+		conditions = append(conditions, fmt.Sprintf("r.model = $%d", len(args)))
+	}
+
+	rows, err := conn.QueryContext(
+		ctx,
+		`SELECT `+keyExpr+`,
+		        `+labelExpr+`,
+		        COALESCE(SUM(r.tokens), 0)::bigint,
+		        COALESCE(SUM(r.cost_usd), 0)::double precision,
+		        COALESCE(SUM(r.events), 0)::bigint,
+		        COALESCE(SUM(CASE WHEN r.priced THEN 0 ELSE r.tokens END), 0)::bigint
+		 FROM usage_daily_rollups r
+		 LEFT JOIN projects p ON p.id = r.project_id
+		 WHERE `+strings.Join(conditions, " AND ")+`
+		 GROUP BY 1`,
+		args...,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query usage report: %w", err)
+	}
+	defer rows.Close()
+
+	report := &UsageReport{
+		From:    from.Format(usageDateLayout),
+		To:      to.Format(usageDateLayout),
+		GroupBy: groupBy,
+		Items:   make([]UsageReportRow, 0),
+	}
+	for rows.Next() {
+		var row UsageReportRow
+		if err := rows.Scan(&row.Key, &row.Label, &row.Tokens, &row.CostUSD, &row.Events, &row.UnpricedTokens); err != nil {
+			return nil, fmt.Errorf("failed to scan usage report row: %w", err)
+		}
+		report.Tokens += row.Tokens
+		report.CostUSD += row.CostUSD
+		report.Events += row.Events
+		report.UnpricedTokens += row.UnpricedTokens
+		report.Items = append(report.Items, row)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read usage report: %w", err)
+	}
+	sort.SliceStable(report.Items, func(i, j int) bool {
+		a, b := report.Items[i], report.Items[j]
+		if groupBy == UsageGroupByDay {
+			return a.Key < b.Key
+		}
+		if a.CostUSD != b.CostUSD {
+			return a.CostUSD > b.CostUSD
+		}
+		if a.Tokens != b.Tokens {
+			return a.Tokens > b.Tokens
+		}
+		return a.Key < b.Key
+	})
+	return report, nil
+}
+
+func (s *UsageStore) ListBudgets(ctx context.Context, orgID string) ([]UsageBudget, error) {
+	if s == nil || s.db == nil {
+		return nil, fmt.Errorf("usage store is not configured")
+	}
+	conn, err := WithWorkspaceID(ctx, s.db, orgID)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	rows, err := conn.QueryContext(
+		ctx,
+		`SELECT `+usageBudgetColumns+`
+		 FROM usage_budgets
+		 WHERE org_id = $1
+		 ORDER BY scope, scope_id, period`,
+		orgID,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list usage budgets: %w", err)
+	}
+	defer rows.Close()
+
+	budgets := make([]UsageBudget, 0)
+	for rows.Next() {
+		budget, err := scanUsageBudget(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan usage budget: %w", err)
+		}
+		budgets = append(budgets, budget)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read usage budgets: %w", err)
+	}
+	return budgets, nil
+}
+
+func (s *UsageStore) GetBudget(ctx context.Context, orgID, budgetID string) (*UsageBudget, error) {
+	if s == nil || s.db == nil {
+		return nil, fmt.Errorf("usage store is not configured")
+	}
+	if !uuidRegex.MatchString(strings.TrimSpace(budgetID)) {
+		return nil, fmt.Errorf("%w: budget id must be a UUID", ErrValidation)
+	}
+	conn, err := WithWorkspaceID(ctx, s.db, orgID)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	budget, err := scanUsageBudget(conn.QueryRowContext(
+		ctx,
+		`SELECT `+usageBudgetColumns+` FROM usage_budgets WHERE org_id = $1 AND id = $2`,
+		orgID,
+		strings.TrimSpace(budgetID),
+	))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to load usage budget: %w", err)
+	}
+	return &budget, nil
+}
+
+// UpsertBudget creates the budget for input's scope and period, or changes
+// the limit of the one that already exists. Agent budgets are keyed by the
+// agent's slug, which is what activity events carry.
+func (s *UsageStore) UpsertBudget(ctx context.Context, orgID string, input UpsertUsageBudgetInput) (*UsageBudget, error) {
+	if s == nil || s.db == nil {
+		return nil, fmt.Errorf("usage store is not configured")
+	}
+	scope := strings.TrimSpace(strings.ToLower(input.Scope))
+	scopeID := strings.TrimSpace(input.ScopeID)
+	period := strings.TrimSpace(strings.ToLower(input.Period))
+	if period == "" {
+		period = UsageBudgetPeriodMonth
+	}
+	if scope != UsageBudgetScopeAgent && scope != UsageBudgetScopeProject {
+		return nil, fmt.Errorf("%w: scope must be agent or project", ErrValidation)
+	}
+	if period != UsageBudgetPeriodDay && period != UsageBudgetPeriodMonth {
+		return nil, fmt.Errorf("%w: period must be day or month", ErrValidation)
+	}
+	if scopeID == "" {
+		return nil, fmt.Errorf("%w: scope_id is required", ErrValidation)
+	}
+	if scope == UsageBudgetScopeProject && !uuidRegex.MatchString(scopeID) {
+		return nil, fmt.Errorf("%w: project scope_id must be a UUID", ErrValidation)
+	}
+	if input.LimitUSD <= 0 {
+		return nil, fmt.Errorf("%w: limit_usd must be positive", ErrValidation)
+	}
+	softPercent := defaultUsageBudgetSoftPercent
+	if input.SoftPercent != nil {
+		softPercent = *input.SoftPercent
+	}
+	if softPercent <= 0 || softPercent > 100 {
+		return nil, fmt.Errorf("%w: soft_percent must be between 1 and 100", ErrValidation)
+	}
+	hard := true
+	if input.Hard != nil {
+		hard = *input.Hard
+	}
+
+	conn, err := WithWorkspaceID(ctx, s.db, orgID)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	if scope == UsageBudgetScopeAgent {
+		scopeID, err = resolveUsageAgentID(ctx, conn, orgID, scopeID)
+		if err != nil {
+			return nil, err
+		}
+	} else {
+		var exists bool
+		if err := conn.QueryRowContext(
+			ctx,
+			`SELECT EXISTS(SELECT 1 FROM projects WHERE org_id = $1 AND id = $2)`,
+			orgID,
+			scopeID,
+		).Scan(&exists); err != nil {
+			return nil, fmt.Errorf("failed to check project: %w", err)
+		}
+		if !exists {
+			return nil, ErrNotFound
+		}
+	}
+
+	budget, err := scanUsageBudget(conn.QueryRowContext(
+		ctx,
+		`INSERT INTO usage_budgets (org_id, scope, scope_id, period, limit_usd, soft_percent, hard)
+		 VALUES ($1, $2, $3, $4, $5, $6, $7)
+		 ON CONFLICT (org_id, scope, scope_id, period) DO UPDATE SET
+			limit_usd = EXCLUDED.limit_usd,
+			soft_percent = EXCLUDED.soft_percent,
+			hard = EXCLUDED.hard,
+			updated_at = NOW()
+		 RETURNING `+usageBudgetColumns,
+		orgID,
+		scope,
+		scopeID,
+		period,
+		input.LimitUSD,
+		softPercent,
+		hard,
+	))
+	if err != nil {
+		return nil, fmt.Errorf("failed to save usage budget: %w", err)
+	}
+	return &budget, nil
+}
+
+func (s *UsageStore) UpdateBudget(ctx context.Context, orgID, budgetID string, input UpdateUsageBudgetInput) (*UsageBudget, error) {
+	if s == nil || s.db == nil {
+		return nil, fmt.Errorf("usage store is not configured")
+	}
+	if !uuidRegex.MatchString(strings.TrimSpace(budgetID)) {
+		return nil, fmt.Errorf("%w: budget id must be a UUID", ErrValidation)
+	}
+	if input.LimitUSD != nil && *input.LimitUSD <= 0 {
+		return nil, fmt.Errorf("%w: limit_usd must be positive", ErrValidation)
+	}
+	if input.SoftPercent != nil && (*input.SoftPercent <= 0 || *input.SoftPercent > 100) {
+		return nil, fmt.Errorf("%w: soft_percent must be between 1 and 100", ErrValidation)
+	}
+	conn, err := WithWorkspaceID(ctx, s.db, orgID)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	budget, err := scanUsageBudget(conn.QueryRowContext(
+		ctx,
+		`UPDATE usage_budgets SET
+			limit_usd = COALESCE($3, limit_usd),
+			soft_percent = COALESCE($4, soft_percent),
+			hard = COALESCE($5, hard),
+			updated_at = NOW()
+		 WHERE org_id = $1 AND id = $2
+		 RETURNING `+usageBudgetColumns,
+		orgID,
+		strings.TrimSpace(budgetID),
+		input.LimitUSD,
+		input.SoftPercent,
+		input.Hard,
+	))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to update usage budget: %w", err)
+	}
+	return &budget, nil
+}
+
+// DeleteBudget removes a budget and resumes any jobs it paused. It returns
+// how many jobs were resumed.
+func (s *UsageStore) DeleteBudget(ctx context.Context, orgID, budgetID string) (int, error) {
+	if s == nil || s.db == nil {
+		return 0, fmt.Errorf("usage store is not configured")
+	}
+	budgetID = strings.TrimSpace(budgetID)
+	if !uuidRegex.MatchString(budgetID) {
+		return 0, fmt.Errorf("%w: budget id must be a UUID", ErrValidation)
+	}
+	tx, err := WithWorkspaceIDTx(ctx, s.db, orgID)
+	if err != nil {
+		return 0, err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	resumed, err := resumeBudgetPausedJobs(ctx, tx, orgID, budgetID)
+	if err != nil {
+		return 0, err
+	}
+	result, err := tx.ExecContext(ctx, `DELETE FROM usage_budgets WHERE org_id = $1 AND id = $2`, orgID, budgetID)
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete usage budget: %w", err)
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return 0, err
+	}
+	if affected == 0 {
+		return 0, ErrNotFound
+	}
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("failed to commit usage budget delete: %w", err)
+	}
+	return resumed, nil
+}
+
+// EvaluateBudgets recomputes every budget's spend for its current period
+// from the rollups and applies what changed: a soft threshold notifies once
+// per period, a hard budget at its limit pauses the agent's active jobs, and
+// a tripped budget that is back under its limit (a new period, a raised
+// limit) resumes the jobs it paused.
+func (s *UsageStore) EvaluateBudgets(ctx context.Context, orgID string, now time.Time) ([]UsageBudgetTransition, error) {
+	ctx, span := startStoreSpan(ctx, "UsageStore.EvaluateBudgets")
+	defer span.End()
+
+	if s == nil || s.db == nil {
+		return nil, fmt.Errorf("usage store is not configured")
+	}
+	if now.IsZero() {
+		now = time.Now()
+	}
+	now = now.UTC()
+
+	tx, err := WithWorkspaceIDTx(ctx, s.db, orgID)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	rows, err := tx.QueryContext(
+		ctx,
+		`SELECT `+usageBudgetColumns+` FROM usage_budgets WHERE org_id = $1 ORDER BY created_at FOR UPDATE`,
+		orgID,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load usage budgets: %w", err)
+	}
+	budgets := make([]UsageBudget, 0)
+	for rows.Next() {
+		budget, err := scanUsageBudget(rows)
+		if err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("failed to scan usage budget: %w", err)
+		}
+		budgets = append(budgets, budget)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, fmt.Errorf("failed to read usage budgets: %w", err)
+	}
+
+	transitions := make([]UsageBudgetTransition, 0)
+	for _, budget := range budgets {
+		start := usagePeriodStart(budget.Period, now)
+		startDate := start.Format(usageDateLayout)
+		if budget.PeriodStart != startDate {
+			budget.PeriodStart = startDate
+			budget.SoftNotifiedAt = nil
+		}
+
+		match := "lower(agent_id) = lower($2)"
+		if budget.Scope == UsageBudgetScopeProject {
+			match = "project_id::text = $2"
+		}
+		if err := tx.QueryRowContext(
+			ctx,
+			`SELECT COALESCE(SUM(cost_usd), 0)::double precision
+			 FROM usage_daily_rollups
+			 WHERE org_id = $1 AND `+match+` AND day >= $3 AND day <= $4`,
+			orgID,
+			budget.ScopeID,
+			start,
+			usageDay(now),
+		).Scan(&budget.SpentUSD); err != nil {
+			return nil, fmt.Errorf("failed to total budget spend: %w", err)
+		}
+
+		softLimit := budget.LimitUSD * float64(budget.SoftPercent) / 100
+		switch {
+		case budget.SoftNotifiedAt == nil && budget.SpentUSD >= softLimit:
+			notifiedAt := now
+			budget.SoftNotifiedAt = &notifiedAt
+			transitions = append(transitions, UsageBudgetTransition{Kind: UsageBudgetSoftCrossed, Budget: budget})
+		case budget.SoftNotifiedAt != nil && budget.SpentUSD < softLimit:
+			// The limit was raised; notify again if spend catches up.
+			budget.SoftNotifiedAt = nil
+		}
+
+		overLimit := budget.Hard && budget.SpentUSD >= budget.LimitUSD
+		switch {
+		case overLimit && budget.HardTrippedAt == nil:
+			trippedAt := now
+			budget.HardTrippedAt = &trippedAt
+			paused, err := pauseBudgetJobs(ctx, tx, orgID, budget)
+			if err != nil {
+				return nil, err
+			}
+			transitions = append(transitions, UsageBudgetTransition{Kind: UsageBudgetHardTripped, Budget: budget, PausedJobs: paused})
+		case !overLimit && budget.HardTrippedAt != nil:
+			budget.HardTrippedAt = nil
+			resumed, err := resumeBudgetPausedJobs(ctx, tx, orgID, budget.ID)
+			if err != nil {
+				return nil, err
+			}
+			transitions = append(transitions, UsageBudgetTransition{Kind: UsageBudgetCleared, Budget: budget, ResumedJobs: resumed})
+		}
+
+		if _, err := tx.ExecContext(
+			ctx,
+			`UPDATE usage_budgets SET
+				period_start = $3,
+				spent_usd = $4,
+				soft_notified_at = $5,
+				hard_tripped_at = $6
+			 WHERE org_id = $1 AND id = $2`,
+			orgID,
+			budget.ID,
+			start,
+			budget.SpentUSD,
+			budget.SoftNotifiedAt,
+			budget.HardTrippedAt,
+		); err != nil {
+			return nil, fmt.Errorf("failed to save usage budget state: %w", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit usage budget evaluation: %w", err)
+	}
+	return transitions, nil
+}
+
+// BlockingBudget returns the tripped hard budget that stops dispatch to
+// agentID (slug or id) or into projectID in the current period, or nil if
+// there is none. Either ID may be empty.
+func (s *UsageStore) BlockingBudget(ctx context.Context, orgID, agentID, projectID string, now time.Time) (*UsageBudget, error) {
+	ctx, span := startStoreSpan(ctx, "UsageStore.BlockingBudget")
+	defer span.End()
+
+	if s == nil || s.db == nil {
+		return nil, fmt.Errorf("usage store is not configured")
+	}
+	agentID = strings.TrimSpace(agentID)
+	projectID = strings.TrimSpace(projectID)
+	if agentID == "" && projectID == "" {
+		return nil, nil
+	}
+	if now.IsZero() {
+		now = time.Now()
+	}
+	conn, err := WithWorkspaceID(ctx, s.db, orgID)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	budget, err := scanUsageBudget(conn.QueryRowContext(
+		ctx,
+		`SELECT `+usageBudgetColumns+`
+		 FROM usage_budgets
+		 WHERE org_id = $1
+		   AND hard
+		   AND hard_tripped_at IS NOT NULL
+		   AND period_start = CASE period WHEN 'day' THEN $4::date ELSE $5::date END
+		   AND (
+			(scope = 'agent' AND $2 <> '' AND (
+				lower(scope_id) = lower($2)
+				OR EXISTS (
+					SELECT 1 FROM agents a
+					WHERE a.org_id = $1
+					  AND (lower(a.slug) = lower(scope_id) OR a.id::text = scope_id)
+					  AND (lower(a.slug) = lower($2) OR a.id::text = $2)
+				)
+			))
+			OR (scope = 'project' AND $3 <> '' AND scope_id = $3)
+		   )
+		 ORDER BY hard_tripped_at
+		 LIMIT 1`,
+		orgID,
+		agentID,
+		projectID,
+		usagePeriodStart(UsageBudgetPeriodDay, now),
+		usagePeriodStart(UsageBudgetPeriodMonth, now),
+	))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to check usage budgets: %w", err)
+	}
+	return &budget, nil
+}
+
+func pauseBudgetJobs(ctx context.Context, tx *sql.Tx, orgID string, budget UsageBudget) (int, error) {
+	if budget.Scope != UsageBudgetScopeAgent {
+		return 0, nil
+	}
+	result, err := tx.ExecContext(
+		ctx,
+		`UPDATE agent_jobs
+		 SET status = 'paused',
+		     paused_by_budget_id = $2
+		 WHERE org_id = $1
+		   AND status = 'active'
+		   AND agent_id IN (
+			SELECT id FROM agents WHERE org_id = $1 AND (lower(slug) = lower($3) OR id::text = $3)
+		   )`,
+		orgID,
+		budget.ID,
+		budget.ScopeID,
+	)
+	if err != nil {
+		return 0, fmt.Errorf("failed to pause jobs for usage budget: %w", err)
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return 0, err
+	}
+	return int(affected), nil
+}
+
+func resumeBudgetPausedJobs(ctx context.Context, tx *sql.Tx, orgID, budgetID string) (int, error) {
+	result, err := tx.ExecContext(
+		ctx,
+		`UPDATE agent_jobs
+		 SET status = 'active',
+		     paused_by_budget_id = NULL
+		 WHERE org_id = $1
+		   AND paused_by_budget_id = $2
+		   AND status = 'paused'`,
+		orgID,
+		budgetID,
+	)
+	if err != nil {
+		return 0, fmt.Errorf("failed to resume jobs for usage budget: %w", err)
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return 0, err
+	}
+	return int(affected), nil
+}
+
+// resolveUsageAgentID maps an agent UUID to its slug. Anything else is
+// assumed to already be the slug activity events are recorded under.
+func resolveUsageAgentID(ctx context.Context, q Querier, orgID, agentID string) (string, error) {
+	agentID = strings.TrimSpace(agentID)
+	if !uuidRegex.MatchString(agentID) {
+		return agentID, nil
+	}
+	var slug string
+	err := q.QueryRowContext(ctx, `SELECT slug FROM agents WHERE org_id = $1 AND id = $2`, orgID, agentID).Scan(&slug)
+	if errors.Is(err, sql.ErrNoRows) {
+		return "", ErrNotFound
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve agent: %w", err)
+	}
+	return slug, nil
+}
+
+func scanUsageBudget(scanner interface{ Scan(...any) error }) (UsageBudget, error) {
+	var budget UsageBudget
+	var periodStart sql.NullTime
+	var softNotifiedAt, hardTrippedAt sql.NullTime
+	err := scanner.Scan(
+		&budget.ID,
+		&budget.OrgID,
+		&budget.Scope,
+		&budget.ScopeID,
+		&budget.Period,
+		&budget.LimitUSD,
+		&budget.SoftPercent,
+		&budget.Hard,
+		&periodStart,
+		&budget.SpentUSD,
+		&softNotifiedAt,
+		&hardTrippedAt,
+		&budget.CreatedAt,
+		&budget.UpdatedAt,
+	)
+	if err != nil {
+		return UsageBudget{}, err
+	}
+	if periodStart.Valid {
+		budget.PeriodStart = periodStart.Time.UTC().Format(usageDateLayout)
+	}
+	if softNotifiedAt.Valid {
+		value := softNotifiedAt.Time.UTC()
+		budget.SoftNotifiedAt = &value
+	}
+	if hardTrippedAt.Valid {
+		value := hardTrippedAt.Time.UTC()
+		budget.HardTrippedAt = &value
+	}
+	return budget, nil
+}
+
+func normalizeUsageModel(model string) string {
+	return strings.ToLower(strings.TrimSpace(model))
+}
+
+func usageDay(t time.Time) time.Time {
+	t = t.UTC()
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+// usagePeriodStart is the first UTC day of the budget period containing now.
+func usagePeriodStart(period string, now time.Time) time.Time {
+	day := usageDay(now)
+	if period == UsageBudgetPeriodDay {
+		return day
+	}
+	return time.Date(day.Year(), day.Month(), 1, 0, 0, 0, 0, time.UTC)
+}
@@ -0,0 +1,185 @@
+package store
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestModelPricingLookupPrefersExactThenLongestPrefix(t *testing.T) {
+	pricing := ModelPricing{
+		{Model: "gpt-*", USDPerMillionTokens: 5},
+		{Model: "gpt-4o-mini*", USDPerMillionTokens: 0.5},
+		{Model: "gpt-4o-mini-2024", USDPerMillionTokens: 0.25},
+	}
+
+	price, ok := pricing.Lookup("GPT-4o-mini-2024")
+	require.True(t, ok)
+	require.Equal(t, 0.25, price.USDPerMillionTokens)
+
+	price, ok = pricing.Lookup("gpt-4o-mini-latest")
+	require.True(t, ok)
+	require.Equal(t, 0.5, price.USDPerMillionTokens)
+
+	cost, ok := pricing.Cost("gpt-4.1", 2_000_000)
+	require.True(t, ok)
+	require.InDelta(t, 10.0, cost, 1e-9)
+
+	cost, ok = pricing.Cost("local-llama", 1_000_000)
+	require.False(t, ok)
+	require.Zero(t, cost)
+	_, ok = pricing.Lookup("")
+	require.False(t, ok)
+}
+
+func TestUsagePeriodStart(t *testing.T) {
+	now := time.Date(2026, 10, 18, 23, 30, 0, 0, time.FixedZone("PDT", -7*3600))
+	require.Equal(t, time.Date(2026, 10, 19, 0, 0, 0, 0, time.UTC), usagePeriodStart(UsageBudgetPeriodDay, now))
+	require.Equal(t, time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC), usagePeriodStart(UsageBudgetPeriodMonth, now))
+}
+
+func TestUsageStoreRollupsReportAndBudgets(t *testing.T) {
+	connStr := getTestDatabaseURL(t)
+	db := setupTestDatabase(t, connStr)
+	orgID := createTestOrganization(t, db, "usage-store-org")
+	otherOrgID := createTestOrganization(t, db, "usage-store-other")
+	projectID := createTestProject(t, db, orgID, "Blog")
+	agentUUID := createAgentJobTestAgent(t, db, orgID, "writer")
+	ctx := ctxWithWorkspace(orgID)
+	usage := NewUsageStore(db)
+	now := time.Date(2026, 10, 18, 12, 0, 0, 0, time.UTC)
+
+	_, err := usage.SetPrice(ctx, orgID, "Model-A*", 10)
+	require.NoError(t, err)
+	_, err = usage.SetPrice(ctx, orgID, "bad*model", 1)
+	require.ErrorIs(t, err, ErrValidation)
+
+	events := []CreateAgentActivityEventInput{
+		{ID: "usage-1", AgentID: "writer", Trigger: "chat", Summary: "draft", Status: "completed",
+			ProjectID: projectID, TokensUsed: 300_000, ModelUsed: "model-a-large", StartedAt: now.Add(-time.Hour)},
+		{ID: "usage-2", AgentID: "writer", Trigger: "chat", Summary: "edit", Status: "completed",
+			ProjectID: projectID, TokensUsed: 200_000, ModelUsed: "model-a-large", StartedAt: now.Add(-30 * time.Minute)},
+		{ID: "usage-3", AgentID: "researcher", Trigger: "chat", Summary: "search", Status: "completed",
+			TokensUsed: 1_000, ModelUsed: "unpriced", StartedAt: now.Add(-10 * time.Minute)},
+	}
+	require.NoError(t, NewAgentActivityEventStore(db).CreateEvents(ctx, events))
+	require.NoError(t, usage.RefreshRollups(ctx, orgID, now, now))
+	// Refreshing again must not double count.
+	require.NoError(t, usage.RefreshRollups(ctx, orgID, now, now))
+
+	report, err := usage.Report(ctx, orgID, UsageReportFilter{
+		From:    time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC),
+		To:      time.Date(2026, 11, 1, 0, 0, 0, 0, time.UTC),
+		GroupBy: UsageGroupByProject,
+	})
+	require.NoError(t, err)
+	require.Equal(t, int64(501_000), report.Tokens)
+	require.InDelta(t, 5.0, report.CostUSD, 1e-6)
+	require.Equal(t, int64(1_000), report.UnpricedTokens)
+	require.Len(t, report.Items, 2)
+	require.Equal(t, projectID, report.Items[0].Key)
+	require.Equal(t, "Blog", report.Items[0].Label)
+
+	byAgent, err := usage.Report(ctx, orgID, UsageReportFilter{From: now, To: now.AddDate(0, 0, 1), AgentID: agentUUID})
+	require.NoError(t, err)
+	require.Len(t, byAgent.Items, 1)
+	require.Equal(t, "writer", byAgent.Items[0].Key)
+
+	other, err := usage.Report(ctxWithWorkspace(otherOrgID), otherOrgID, UsageReportFilter{From: now, To: now.AddDate(0, 0, 1)})
+	require.NoError(t, err)
+	require.Empty(t, other.Items)
+
+	jobs := NewAgentJobStore(db)
+	nextRunAt := now.Add(time.Hour)
+	job, err := jobs.Create(ctx, CreateAgentJobInput{
+		AgentID:      agentUUID,
+		Name:         "Daily post",
+		ScheduleKind: AgentJobScheduleInterval,
+		IntervalMS:   agentJobInt64Ptr(60000),
+		PayloadKind:  AgentJobPayloadMessage,
+		PayloadText:  "write",
+		NextRunAt:    &nextRunAt,
+	})
+	require.NoError(t, err)
+
+	softPercent := 50
+	budget, err := usage.UpsertBudget(ctx, orgID, UpsertUsageBudgetInput{
+		Scope:       UsageBudgetScopeAgent,
+		ScopeID:     agentUUID,
+		LimitUSD:    4,
+		SoftPercent: &softPercent,
+	})
+	require.NoError(t, err)
+	require.Equal(t, "writer", budget.ScopeID, "agent budgets are keyed by slug")
+	require.Equal(t, UsageBudgetPeriodMonth, budget.Period)
+
+	transitions, err := usage.EvaluateBudgets(ctx, orgID, now)
+	require.NoError(t, err)
+	require.Len(t, transitions, 2)
+	require.Equal(t, UsageBudgetSoftCrossed, transitions[0].Kind)
+	require.Equal(t, UsageBudgetHardTripped, transitions[1].Kind)
+	require.Equal(t, 1, transitions[1].PausedJobs)
+
+	paused, err := jobs.GetByID(ctx, job.ID)
+	require.NoError(t, err)
+	require.Equal(t, AgentJobStatusPaused, paused.Status)
+
+	blocking, err := usage.BlockingBudget(ctx, orgID, "Writer", "", now)
+	require.NoError(t, err)
+	require.NotNil(t, blocking)
+	require.InDelta(t, 5.0, blocking.SpentUSD, 1e-6)
+	// DM dispatch falls back to the agent's UUID.
+	blocking, err = usage.BlockingBudget(ctx, orgID, agentUUID, "", now)
+	require.NoError(t, err)
+	require.NotNil(t, blocking)
+	require.Equal(t, budget.ID, blocking.ID)
+	blocking, err = usage.BlockingBudget(ctx, orgID, "researcher", "", now)
+	require.NoError(t, err)
+	require.Nil(t, blocking)
+	blocking, err = usage.BlockingBudget(ctx, orgID, "writer", "", now.AddDate(0, 1, 0))
+	require.NoError(t, err)
+	require.Nil(t, blocking, "a trip only blocks within its period")
+
+	transitions, err = usage.EvaluateBudgets(ctx, orgID, now)
+	require.NoError(t, err)
+	require.Empty(t, transitions, "thresholds fire once per period")
+
+	limit := 10.0
+	_, err = usage.UpdateBudget(ctx, orgID, budget.ID, UpdateUsageBudgetInput{LimitUSD: &limit})
+	require.NoError(t, err)
+	transitions, err = usage.EvaluateBudgets(ctx, orgID, now)
+	require.NoError(t, err)
+	require.Len(t, transitions, 1)
+	require.Equal(t, UsageBudgetCleared, transitions[0].Kind)
+	require.Equal(t, 1, transitions[0].ResumedJobs)
+
+	resumed, err := jobs.GetByID(ctx, job.ID)
+	require.NoError(t, err)
+	require.Equal(t, AgentJobStatusActive, resumed.Status)
+
+	projectBudget, err := usage.UpsertBudget(ctx, orgID, UpsertUsageBudgetInput{
+		Scope:    UsageBudgetScopeProject,
+		ScopeID:  projectID,
+		Period:   UsageBudgetPeriodDay,
+		LimitUSD: 1,
+	})
+	require.NoError(t, err)
+	_, err = usage.EvaluateBudgets(ctx, orgID, now)
+	require.NoError(t, err)
+	blocking, err = usage.BlockingBudget(ctx, orgID, "someone-else", projectID, now)
+	require.NoError(t, err)
+	require.NotNil(t, blocking)
+	require.Equal(t, projectBudget.ID, blocking.ID)
+
+	resumedCount, err := usage.DeleteBudget(ctx, orgID, projectBudget.ID)
+	require.NoError(t, err)
+	require.Zero(t, resumedCount)
+	_, err = usage.GetBudget(ctx, orgID, projectBudget.ID)
+	require.ErrorIs(t, err, ErrNotFound)
+
+	_, err = usage.UpsertBudget(ctx, orgID, UpsertUsageBudgetInput{Scope: "team", ScopeID: "x", LimitUSD: 1})
+	require.ErrorIs(t, err, ErrValidation)
+	_, err = usage.UpsertBudget(ctx, orgID, UpsertUsageBudgetInput{Scope: UsageBudgetScopeAgent, ScopeID: "writer", LimitUSD: 0})
+	require.ErrorIs(t, err, ErrValidation)
+}
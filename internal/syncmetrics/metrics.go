@@ -50,32 +50,36 @@ func ResetForTests() {
 }
 
 func RecordJobPicked(jobType string) {
-	job := globalRegistry.jobMetrics(jobType)
-	job.PickedTotal++
+	globalRegistry.updateJob(jobType, func(job *JobMetrics) {
+		job.PickedTotal++
+	})
 }
 
 func RecordJobCompleted(jobType string, latency time.Duration) {
-	job := globalRegistry.jobMetrics(jobType)
-	job.SuccessTotal++
-	if latency > 0 {
-		job.TotalLatencyMillis += latency.Milliseconds()
-	}
+	globalRegistry.updateJob(jobType, func(job *JobMetrics) {
+		job.SuccessTotal++
+		if latency > 0 {
+			job.TotalLatencyMillis += latency.Milliseconds()
+		}
+	})
 }
 
 func RecordJobFailure(jobType string, retryScheduled bool, deadLettered bool) {
-	job := globalRegistry.jobMetrics(jobType)
-	job.FailureTotal++
-	if retryScheduled {
-		job.RetryTotal++
-	}
-	if deadLettered {
-		job.DeadLetterTotal++
-	}
+	globalRegistry.updateJob(jobType, func(job *JobMetrics) {
+		job.FailureTotal++
+		if retryScheduled {
+			job.RetryTotal++
+		}
+		if deadLettered {
+			job.DeadLetterTotal++
+		}
+	})
 }
 
 func RecordDeadLetterReplay(jobType string) {
-	job := globalRegistry.jobMetrics(jobType)
-	job.ReplayTotal++
+	globalRegistry.updateJob(jobType, func(job *JobMetrics) {
+		job.ReplayTotal++
+	})
 }
 
 func RecordQuota(jobType string, limit, remaining int, resetAt time.Time) {
@@ -138,7 +142,9 @@ func SnapshotNow() Snapshot {
 	return snapshot
 }
 
-func (r *registry) jobMetrics(jobType string) *JobMetrics {
+// updateJob applies update under the registry lock so snapshots never see a
+// half-written counter.
+func (r *registry) updateJob(jobType string, update func(*JobMetrics)) {
 	key := normalizeKey(jobType)
 	if key == "" {
 		key = "unknown"
@@ -152,7 +158,7 @@ func (r *registry) jobMetrics(jobType string) *JobMetrics {
 		metrics = &JobMetrics{}
 		r.jobs[key] = metrics
 	}
-	return metrics
+	update(metrics)
 }
 
 func normalizeKey(raw string) string {
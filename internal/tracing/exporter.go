@@ -0,0 +1,202 @@
+package tracing
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// OTLPExporter posts spans to an OTLP/HTTP collector using the JSON
+// encoding of ExportTraceServiceRequest.
+type OTLPExporter struct {
+	endpoint string
+	headers  map[string]string
+	client   *http.Client
+}
+
+// NewOTLPExporter targets endpoint, appending /v1/traces unless the URL
+// already names a path (as OTEL_EXPORTER_OTLP_TRACES_ENDPOINT does).
+func NewOTLPExporter(endpoint string, headers map[string]string) (*OTLPExporter, error) {
+	parsed, err := url.Parse(strings.TrimSpace(endpoint))
+	if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+		return nil, fmt.Errorf("invalid OTLP endpoint %q", endpoint)
+	}
+	if strings.Trim(parsed.Path, "/") == "" {
+		parsed.Path = "/v1/traces"
+	}
+	return &OTLPExporter{
+		endpoint: parsed.String(),
+		headers:  headers,
+		client:   &http.Client{Timeout: exportTimeout},
+	}, nil
+}
+
+func (e *OTLPExporter) Export(ctx context.Context, spans []SpanData) error {
+	if len(spans) == 0 {
+		return nil
+	}
+	body, err := json.Marshal(otlpRequest(spans))
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, e.endpoint, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for key, value := range e.headers {
+		req.Header.Set(key, value)
+	}
+	resp, err := e.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 64*1024))
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("collector returned %s", resp.Status)
+	}
+	return nil
+}
+
+func (e *OTLPExporter) Shutdown(context.Context) error {
+	e.client.CloseIdleConnections()
+	return nil
+}
+
+type otlpKeyValue struct {
+	Key   string         `json:"key"`
+	Value map[string]any `json:"value"`
+}
+
+type otlpSpan struct {
+	TraceID           string         `json:"traceId"`
+	SpanID            string         `json:"spanId"`
+	ParentSpanID      string         `json:"parentSpanId,omitempty"`
+	Name              string         `json:"name"`
+	Kind              SpanKind       `json:"kind"`
+	StartTimeUnixNano string         `json:"startTimeUnixNano"`
+	EndTimeUnixNano   string         `json:"endTimeUnixNano"`
+	Attributes        []otlpKeyValue `json:"attributes,omitempty"`
+	Status            map[string]any `json:"status,omitempty"`
+}
+
+// otlpRequest groups spans by service into resourceSpans.
+func otlpRequest(spans []SpanData) map[string]any {
+	byService := map[string][]otlpSpan{}
+	services := []string{}
+	for _, span := range spans {
+		if _, ok := byService[span.Service]; !ok {
+			services = append(services, span.Service)
+		}
+		converted := otlpSpan{
+			TraceID:           span.TraceID,
+			SpanID:            span.SpanID,
+			ParentSpanID:      span.ParentSpanID,
+			Name:              span.Name,
+			Kind:              span.Kind,
+			StartTimeUnixNano: strconv.FormatInt(span.Start.UnixNano(), 10),
+			EndTimeUnixNano:   strconv.FormatInt(span.End.UnixNano(), 10),
+			Attributes:        otlpAttributes(span.Attributes),
+		}
+		if span.Error != "" {
+			converted.Status = map[string]any{"code": 2, "message": span.Error}
+		}
+		byService[span.Service] = append(byService[span.Service], converted)
+	}
+
+	resourceSpans := make([]map[string]any, 0, len(services))
+	for _, service := range services {
+		resourceSpans = append(resourceSpans, map[string]any{
+			"resource": map[string]any{
+				"attributes": otlpAttributes(map[string]any{"service.name": service}),
+			},
+			"scopeSpans": []map[string]any{{
+				"scope": map[string]any{"name": "github.com/samhotchkiss/otter-camp/internal/tracing"},
+				"spans": byService[service],
+			}},
+		})
+	}
+	return map[string]any{"resourceSpans": resourceSpans}
+}
+
+func otlpAttributes(attributes map[string]any) []otlpKeyValue {
+	if len(attributes) == 0 {
+		return nil
+	}
+	keys := make([]string, 0, len(attributes))
+	for key := range attributes {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	out := make([]otlpKeyValue, 0, len(keys))
+	for _, key := range keys {
+		var value map[string]any
+		switch typed := attributes[key].(type) {
+		case bool:
+			value = map[string]any{"boolValue": typed}
+		case int:
+			value = map[string]any{"intValue": strconv.Itoa(typed)}
+		case int64:
+			value = map[string]any{"intValue": strconv.FormatInt(typed, 10)}
+		case float64:
+			value = map[string]any{"doubleValue": typed}
+		case string:
+			value = map[string]any{"stringValue": typed}
+		default:
+			value = map[string]any{"stringValue": fmt.Sprint(typed)}
+		}
+		out = append(out, otlpKeyValue{Key: key, Value: value})
+	}
+	return out
+}
+
+// FileExporter appends spans as JSON lines for offline debugging.
+type FileExporter struct {
+	mu   sync.Mutex
+	file *os.File
+}
+
+func NewFileExporter(path string) (*FileExporter, error) {
+	if dir := filepath.Dir(path); dir != "" {
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			return nil, err
+		}
+	}
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o644)
+	if err != nil {
+		return nil, err
+	}
+	return &FileExporter{file: file}, nil
+}
+
+func (e *FileExporter) Export(_ context.Context, spans []SpanData) error {
+	var buf bytes.Buffer
+	encoder := json.NewEncoder(&buf)
+	for _, span := range spans {
+		if err := encoder.Encode(span); err != nil {
+			return err
+		}
+	}
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	_, err := e.file.Write(buf.Bytes())
+	return err
+}
+
+func (e *FileExporter) Shutdown(context.Context) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return e.file.Close()
+}
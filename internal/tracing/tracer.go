@@ -0,0 +1,212 @@
+package tracing
+
+import (
+	"context"
+	"errors"
+	"log"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	defaultServiceName   = "otter-camp"
+	defaultQueueSize     = 2048
+	defaultBatchSize     = 256
+	defaultFlushInterval = 2 * time.Second
+	exportTimeout        = 10 * time.Second
+)
+
+// Exporter ships finished spans somewhere.
+type Exporter interface {
+	Export(ctx context.Context, spans []SpanData) error
+	Shutdown(ctx context.Context) error
+}
+
+// Config selects exporters. Tracing stays disabled (every Start is a no-op)
+// when neither an OTLP endpoint nor a file path is set.
+type Config struct {
+	ServiceName   string
+	OTLPEndpoint  string
+	OTLPHeaders   map[string]string
+	FilePath      string
+	SampleRatio   float64
+	FlushInterval time.Duration
+}
+
+// Tracer batches finished spans and hands them to its exporters.
+type Tracer struct {
+	service     string
+	sampleRatio float64
+	exporters   []Exporter
+	queue       chan SpanData
+	flushEvery  time.Duration
+	dropped     atomic.Int64
+	done        chan struct{}
+
+	// closeMu keeps enqueue from sending on the queue after Shutdown closes it.
+	closeMu sync.RWMutex
+	closed  bool
+}
+
+var global atomic.Pointer[Tracer]
+
+func currentTracer() *Tracer {
+	return global.Load()
+}
+
+// Enabled reports whether spans are being recorded.
+func Enabled() bool {
+	return currentTracer() != nil
+}
+
+// Setup installs the global tracer described by cfg and returns a shutdown
+// function that flushes pending spans. With no exporters configured it
+// returns a no-op shutdown and leaves tracing disabled.
+func Setup(cfg Config) (func(context.Context) error, error) {
+	var exporters []Exporter
+	if endpoint := strings.TrimSpace(cfg.OTLPEndpoint); endpoint != "" {
+		exporter, err := NewOTLPExporter(endpoint, cfg.OTLPHeaders)
+		if err != nil {
+			return nil, err
+		}
+		exporters = append(exporters, exporter)
+	}
+	if path := strings.TrimSpace(cfg.FilePath); path != "" {
+		exporter, err := NewFileExporter(path)
+		if err != nil {
+			return nil, err
+		}
+		exporters = append(exporters, exporter)
+	}
+	if len(exporters) == 0 {
+		return func(context.Context) error { return nil }, nil
+	}
+
+	tracer := NewTracer(cfg, exporters...)
+	global.Store(tracer)
+	return func(ctx context.Context) error {
+		global.CompareAndSwap(tracer, nil)
+		return tracer.Shutdown(ctx)
+	}, nil
+}
+
+// NewTracer starts a tracer that exports to exporters. Most callers want
+// Setup; tests use this with an in-memory exporter and SetGlobal.
+func NewTracer(cfg Config, exporters ...Exporter) *Tracer {
+	service := strings.TrimSpace(cfg.ServiceName)
+	if service == "" {
+		service = defaultServiceName
+	}
+	ratio := cfg.SampleRatio
+	if ratio <= 0 || ratio > 1 {
+		ratio = 1
+	}
+	flushEvery := cfg.FlushInterval
+	if flushEvery <= 0 {
+		flushEvery = defaultFlushInterval
+	}
+	tracer := &Tracer{
+		service:     service,
+		sampleRatio: ratio,
+		exporters:   exporters,
+		queue:       make(chan SpanData, defaultQueueSize),
+		flushEvery:  flushEvery,
+		done:        make(chan struct{}),
+	}
+	go tracer.run()
+	return tracer
+}
+
+// SetGlobal replaces the global tracer and returns the previous one.
+func SetGlobal(tracer *Tracer) *Tracer {
+	return global.Swap(tracer)
+}
+
+func (t *Tracer) sample(traceID [16]byte) bool {
+	return t.sampleRatio >= 1 || traceIDRatio(traceID) < t.sampleRatio
+}
+
+func (t *Tracer) enqueue(span SpanData) {
+	span.Service = t.service
+	t.closeMu.RLock()
+	defer t.closeMu.RUnlock()
+	if t.closed {
+		return
+	}
+	select {
+	case t.queue <- span:
+	default:
+		// Never block request paths on a slow collector.
+		t.dropped.Add(1)
+	}
+}
+
+// Dropped reports spans discarded because the export queue was full.
+func (t *Tracer) Dropped() int64 {
+	return t.dropped.Load()
+}
+
+func (t *Tracer) run() {
+	defer close(t.done)
+	ticker := time.NewTicker(t.flushEvery)
+	defer ticker.Stop()
+
+	batch := make([]SpanData, 0, defaultBatchSize)
+	flush := func() {
+		if len(batch) == 0 {
+			return
+		}
+		t.export(batch)
+		batch = make([]SpanData, 0, defaultBatchSize)
+	}
+	for {
+		select {
+		case span, ok := <-t.queue:
+			if !ok {
+				flush()
+				return
+			}
+			batch = append(batch, span)
+			if len(batch) >= defaultBatchSize {
+				flush()
+			}
+		case <-ticker.C:
+			flush()
+		}
+	}
+}
+
+func (t *Tracer) export(batch []SpanData) {
+	ctx, cancel := context.WithTimeout(context.Background(), exportTimeout)
+	defer cancel()
+	for _, exporter := range t.exporters {
+		if err := exporter.Export(ctx, batch); err != nil {
+			log.Printf("[tracing] export of %d spans failed: %v", len(batch), err)
+		}
+	}
+}
+
+// Shutdown flushes queued spans and closes the exporters. Spans ended after
+// Shutdown are dropped.
+func (t *Tracer) Shutdown(ctx context.Context) error {
+	t.closeMu.Lock()
+	if !t.closed {
+		t.closed = true
+		close(t.queue)
+	}
+	t.closeMu.Unlock()
+	select {
+	case <-t.done:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+	var errs []error
+	for _, exporter := range t.exporters {
+		if err := exporter.Shutdown(ctx); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
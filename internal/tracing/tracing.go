@@ -0,0 +1,318 @@
+// Package tracing records spans with W3C trace context propagation and
+// exports them as OTLP/HTTP JSON or to a local JSON-lines file. It covers the
+// subset of OpenTelemetry the server needs without pulling in the SDK.
+package tracing
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/binary"
+	"encoding/hex"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// SpanKind follows the OTLP enum values.
+type SpanKind int
+
+const (
+	SpanKindInternal SpanKind = 1
+	SpanKindServer   SpanKind = 2
+	SpanKindClient   SpanKind = 3
+	SpanKindProducer SpanKind = 4
+	SpanKindConsumer SpanKind = 5
+)
+
+// TraceparentHeader is the W3C trace context header name.
+const TraceparentHeader = "traceparent"
+
+// SpanContext identifies a span within a trace.
+type SpanContext struct {
+	TraceID [16]byte
+	SpanID  [8]byte
+	Sampled bool
+}
+
+func (sc SpanContext) IsValid() bool {
+	return sc.TraceID != [16]byte{} && sc.SpanID != [8]byte{}
+}
+
+// Traceparent renders the W3C traceparent value, or "" when invalid.
+func (sc SpanContext) Traceparent() string {
+	if !sc.IsValid() {
+		return ""
+	}
+	flags := "00"
+	if sc.Sampled {
+		flags = "01"
+	}
+	return "00-" + hex.EncodeToString(sc.TraceID[:]) + "-" + hex.EncodeToString(sc.SpanID[:]) + "-" + flags
+}
+
+// ParseTraceparent parses a version-00 W3C traceparent value.
+func ParseTraceparent(value string) (SpanContext, bool) {
+	parts := strings.Split(strings.TrimSpace(value), "-")
+	if len(parts) < 4 || parts[0] != "00" || len(parts[1]) != 32 || len(parts[2]) != 16 || len(parts[3]) != 2 {
+		return SpanContext{}, false
+	}
+	var sc SpanContext
+	if _, err := hex.Decode(sc.TraceID[:], []byte(parts[1])); err != nil {
+		return SpanContext{}, false
+	}
+	if _, err := hex.Decode(sc.SpanID[:], []byte(parts[2])); err != nil {
+		return SpanContext{}, false
+	}
+	flags, err := hex.DecodeString(parts[3])
+	if err != nil {
+		return SpanContext{}, false
+	}
+	sc.Sampled = flags[0]&0x01 == 1
+	if !sc.IsValid() {
+		return SpanContext{}, false
+	}
+	return sc, true
+}
+
+// Span is one timed operation. A nil *Span is valid and does nothing, so
+// callers never need to check whether tracing is enabled.
+type Span struct {
+	tracer *Tracer
+	sc     SpanContext
+	parent [8]byte
+	kind   SpanKind
+	start  time.Time
+
+	mu         sync.Mutex
+	name       string
+	attributes map[string]any
+	errMessage string
+	ended      bool
+	discarded  bool
+}
+
+type spanContextKey struct{}
+type remoteContextKey struct{}
+
+// Start begins an internal span that is a child of whatever span ctx
+// carries.
+func Start(ctx context.Context, name string) (context.Context, *Span) {
+	return StartKind(ctx, name, SpanKindInternal)
+}
+
+// StartKind begins a span of the given kind.
+func StartKind(ctx context.Context, name string, kind SpanKind) (context.Context, *Span) {
+	return StartAt(ctx, name, kind, time.Now())
+}
+
+// StartAt begins a span that started earlier than now, e.g. the time a job
+// spent waiting in a queue.
+func StartAt(ctx context.Context, name string, kind SpanKind, start time.Time) (context.Context, *Span) {
+	tracer := currentTracer()
+	if tracer == nil {
+		return ctx, nil
+	}
+	parent := parentSpanContext(ctx)
+
+	span := &Span{
+		tracer:     tracer,
+		kind:       kind,
+		start:      start,
+		name:       name,
+		attributes: map[string]any{},
+	}
+	if parent.IsValid() {
+		span.sc.TraceID = parent.TraceID
+		span.sc.Sampled = parent.Sampled
+		span.parent = parent.SpanID
+	} else {
+		span.sc.TraceID = newTraceID()
+		span.sc.Sampled = tracer.sample(span.sc.TraceID)
+	}
+	span.sc.SpanID = newSpanID()
+	return context.WithValue(ctx, spanContextKey{}, span), span
+}
+
+// SpanFromContext returns the active span, if any.
+func SpanFromContext(ctx context.Context) *Span {
+	span, _ := ctx.Value(spanContextKey{}).(*Span)
+	return span
+}
+
+// ContextWithTraceparent makes a propagated traceparent the parent of the
+// next span started from ctx. Invalid values are ignored.
+func ContextWithTraceparent(ctx context.Context, traceparent string) context.Context {
+	sc, ok := ParseTraceparent(traceparent)
+	if !ok {
+		return ctx
+	}
+	return context.WithValue(ctx, remoteContextKey{}, sc)
+}
+
+// Traceparent returns the value to propagate downstream from ctx: the active
+// span, else an inbound remote parent, else "".
+func Traceparent(ctx context.Context) string {
+	return parentSpanContext(ctx).Traceparent()
+}
+
+// Inject sets the traceparent header from ctx.
+func Inject(ctx context.Context, header http.Header) {
+	if value := Traceparent(ctx); value != "" {
+		header.Set(TraceparentHeader, value)
+	}
+}
+
+// Extract reads the traceparent header into ctx.
+func Extract(ctx context.Context, header http.Header) context.Context {
+	return ContextWithTraceparent(ctx, header.Get(TraceparentHeader))
+}
+
+func parentSpanContext(ctx context.Context) SpanContext {
+	if ctx == nil {
+		return SpanContext{}
+	}
+	if span := SpanFromContext(ctx); span != nil {
+		return span.sc
+	}
+	sc, _ := ctx.Value(remoteContextKey{}).(SpanContext)
+	return sc
+}
+
+func (s *Span) SpanContext() SpanContext {
+	if s == nil {
+		return SpanContext{}
+	}
+	return s.sc
+}
+
+// SetName renames the span, e.g. once the router has matched a pattern.
+func (s *Span) SetName(name string) {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	s.name = name
+	s.mu.Unlock()
+}
+
+// SetAttribute records a string, bool, integer or float attribute.
+func (s *Span) SetAttribute(key string, value any) {
+	if s == nil || strings.TrimSpace(key) == "" {
+		return
+	}
+	s.mu.Lock()
+	s.attributes[key] = value
+	s.mu.Unlock()
+}
+
+// RecordError marks the span as failed. A nil err is ignored.
+func (s *Span) RecordError(err error) {
+	if s == nil || err == nil {
+		return
+	}
+	s.mu.Lock()
+	s.errMessage = err.Error()
+	s.mu.Unlock()
+}
+
+// Discard drops the span instead of exporting it when it ends; used for idle
+// worker passes that would otherwise flood the collector.
+func (s *Span) Discard() {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	s.discarded = true
+	s.mu.Unlock()
+}
+
+// End finishes the span and queues it for export if it was sampled.
+func (s *Span) End() {
+	if s == nil {
+		return
+	}
+	end := time.Now()
+	s.mu.Lock()
+	if s.ended {
+		s.mu.Unlock()
+		return
+	}
+	s.ended = true
+	if s.discarded || !s.sc.Sampled {
+		s.mu.Unlock()
+		return
+	}
+	data := SpanData{
+		TraceID:    hex.EncodeToString(s.sc.TraceID[:]),
+		SpanID:     hex.EncodeToString(s.sc.SpanID[:]),
+		Name:       s.name,
+		Kind:       s.kind,
+		Start:      s.start.UTC(),
+		End:        end.UTC(),
+		Attributes: make(map[string]any, len(s.attributes)),
+		Error:      s.errMessage,
+	}
+	if s.parent != [8]byte{} {
+		data.ParentSpanID = hex.EncodeToString(s.parent[:])
+	}
+	for key, value := range s.attributes {
+		data.Attributes[key] = value
+	}
+	s.mu.Unlock()
+
+	s.tracer.enqueue(data)
+}
+
+// SpanData is a finished span as handed to exporters.
+type SpanData struct {
+	Service      string         `json:"service"`
+	TraceID      string         `json:"trace_id"`
+	SpanID       string         `json:"span_id"`
+	ParentSpanID string         `json:"parent_span_id,omitempty"`
+	Name         string         `json:"name"`
+	Kind         SpanKind       `json:"kind"`
+	Start        time.Time      `json:"start"`
+	End          time.Time      `json:"end"`
+	Attributes   map[string]any `json:"attributes,omitempty"`
+	Error        string         `json:"error,omitempty"`
+}
+
+func newTraceID() [16]byte {
+	var id [16]byte
+	for id == [16]byte{} {
+		_, _ = rand.Read(id[:])
+	}
+	return id
+}
+
+func newSpanID() [8]byte {
+	var id [8]byte
+	for id == [8]byte{} {
+		_, _ = rand.Read(id[:])
+	}
+	return id
+}
+
+// traceIDRatio maps the low 8 bytes of a trace ID into [0,1) so sampling is
+// consistent for every span of a trace.
+func traceIDRatio(id [16]byte) float64 {
+	return float64(binary.BigEndian.Uint64(id[8:])>>11) / float64(uint64(1)<<53)
+}
+
+// StartWorkerRun begins the root span for one pass of a polling worker.
+func StartWorkerRun(ctx context.Context, worker string) (context.Context, *Span) {
+	ctx, span := Start(ctx, "worker."+worker)
+	span.SetAttribute("worker", worker)
+	return ctx, span
+}
+
+// EndWorkerRun ends a worker pass span. Passes that found nothing to do and
+// did not fail are discarded so idle polling doesn't flood the collector.
+func EndWorkerRun(span *Span, didWork bool, err error) {
+	span.RecordError(err)
+	if !didWork && err == nil {
+		span.Discard()
+	}
+	span.End()
+}
@@ -0,0 +1,333 @@
+package tracing
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+)
+
+type recordingExporter struct {
+	mu    sync.Mutex
+	spans []SpanData
+}
+
+func (e *recordingExporter) Export(_ context.Context, spans []SpanData) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.spans = append(e.spans, spans...)
+	return nil
+}
+
+func (e *recordingExporter) Shutdown(context.Context) error { return nil }
+
+func (e *recordingExporter) byName() map[string]SpanData {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	out := make(map[string]SpanData, len(e.spans))
+	for _, span := range e.spans {
+		out[span.Name] = span
+	}
+	return out
+}
+
+func installRecordingTracer(t *testing.T, cfg Config) (*Tracer, *recordingExporter) {
+	t.Helper()
+	exporter := &recordingExporter{}
+	tracer := NewTracer(cfg, exporter)
+	previous := SetGlobal(tracer)
+	t.Cleanup(func() {
+		SetGlobal(previous)
+		_ = tracer.Shutdown(context.Background())
+	})
+	return tracer, exporter
+}
+
+func TestTraceparentRoundTrip(t *testing.T) {
+	value := "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"
+	sc, ok := ParseTraceparent(value)
+	if !ok {
+		t.Fatalf("expected %q to parse", value)
+	}
+	if !sc.Sampled {
+		t.Fatalf("expected sampled flag")
+	}
+	if got := sc.Traceparent(); got != value {
+		t.Fatalf("Traceparent() = %q, want %q", got, value)
+	}
+
+	for _, invalid := range []string{
+		"",
+		"01-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01",
+		"00-00000000000000000000000000000000-00f067aa0ba902b7-01",
+		"00-4bf92f3577b34da6a3ce929d0e0e4736-0000000000000000-01",
+		"00-4bf92f3577b34da6a3ce929d0e0e473-00f067aa0ba902b7-01",
+		"00-zzf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01",
+	} {
+		if _, ok := ParseTraceparent(invalid); ok {
+			t.Fatalf("expected %q to be rejected", invalid)
+		}
+	}
+}
+
+func TestStartIsNoopWithoutTracer(t *testing.T) {
+	previous := SetGlobal(nil)
+	defer SetGlobal(previous)
+
+	ctx, span := Start(context.Background(), "noop")
+	if span != nil {
+		t.Fatalf("expected nil span when tracing is disabled")
+	}
+	span.SetAttribute("k", "v")
+	span.RecordError(errors.New("boom"))
+	span.End()
+	if got := Traceparent(ctx); got != "" {
+		t.Fatalf("expected no traceparent, got %q", got)
+	}
+
+	remote := "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"
+	if got := Traceparent(ContextWithTraceparent(context.Background(), remote)); got != remote {
+		t.Fatalf("expected remote parent to pass through untouched, got %q", got)
+	}
+}
+
+func TestChildSpansShareTraceAndLinkParent(t *testing.T) {
+	tracer, exporter := installRecordingTracer(t, Config{ServiceName: "otter-test"})
+
+	remote := "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"
+	ctx := ContextWithTraceparent(context.Background(), remote)
+	ctx, parent := StartKind(ctx, "parent", SpanKindServer)
+	_, child := Start(ctx, "child")
+	child.SetAttribute("rows", 3)
+	child.RecordError(errors.New("boom"))
+	child.End()
+	parent.End()
+
+	_, discarded := Start(context.Background(), "idle")
+	discarded.Discard()
+	discarded.End()
+
+	if err := tracer.Shutdown(context.Background()); err != nil {
+		t.Fatalf("Shutdown() error = %v", err)
+	}
+	spans := exporter.byName()
+	if len(spans) != 2 {
+		t.Fatalf("expected 2 exported spans, got %d: %#v", len(spans), spans)
+	}
+	if spans["parent"].TraceID != "4bf92f3577b34da6a3ce929d0e0e4736" || spans["parent"].ParentSpanID != "00f067aa0ba902b7" {
+		t.Fatalf("parent span did not continue remote trace: %#v", spans["parent"])
+	}
+	if spans["child"].TraceID != spans["parent"].TraceID || spans["child"].ParentSpanID != spans["parent"].SpanID {
+		t.Fatalf("child span not linked to parent: %#v", spans["child"])
+	}
+	if spans["child"].Error != "boom" || spans["child"].Attributes["rows"] != 3 {
+		t.Fatalf("child span lost error or attributes: %#v", spans["child"])
+	}
+	if spans["parent"].Service != "otter-test" || spans["parent"].Kind != SpanKindServer {
+		t.Fatalf("unexpected parent metadata: %#v", spans["parent"])
+	}
+}
+
+func TestUnsampledRemoteParentIsNotExported(t *testing.T) {
+	tracer, exporter := installRecordingTracer(t, Config{})
+
+	ctx := ContextWithTraceparent(context.Background(), "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-00")
+	ctx, span := Start(ctx, "unsampled")
+	if got := Traceparent(ctx); got == "" || got[len(got)-2:] != "00" {
+		t.Fatalf("expected unsampled traceparent to propagate, got %q", got)
+	}
+	span.End()
+
+	if err := tracer.Shutdown(context.Background()); err != nil {
+		t.Fatalf("Shutdown() error = %v", err)
+	}
+	if spans := exporter.byName(); len(spans) != 0 {
+		t.Fatalf("expected no exported spans, got %#v", spans)
+	}
+}
+
+func TestEndWorkerRunDiscardsIdlePasses(t *testing.T) {
+	tracer, exporter := installRecordingTracer(t, Config{})
+
+	_, idle := StartWorkerRun(context.Background(), "idle")
+	EndWorkerRun(idle, false, nil)
+	_, busy := StartWorkerRun(context.Background(), "busy")
+	EndWorkerRun(busy, true, nil)
+	_, failed := StartWorkerRun(context.Background(), "failed")
+	EndWorkerRun(failed, false, errors.New("db down"))
+
+	if err := tracer.Shutdown(context.Background()); err != nil {
+		t.Fatalf("Shutdown() error = %v", err)
+	}
+	spans := exporter.byName()
+	if _, ok := spans["worker.idle"]; ok {
+		t.Fatalf("idle worker pass should be discarded")
+	}
+	if _, ok := spans["worker.busy"]; !ok {
+		t.Fatalf("busy worker pass should be exported")
+	}
+	if spans["worker.failed"].Error != "db down" {
+		t.Fatalf("failed worker pass should be exported with its error: %#v", spans["worker.failed"])
+	}
+}
+
+func TestInjectExtractHeaders(t *testing.T) {
+	_, _ = installRecordingTracer(t, Config{})
+
+	ctx, span := Start(context.Background(), "outbound")
+	defer span.End()
+	header := http.Header{}
+	Inject(ctx, header)
+	if header.Get(TraceparentHeader) != span.SpanContext().Traceparent() {
+		t.Fatalf("Inject() header = %q", header.Get(TraceparentHeader))
+	}
+	if got := Traceparent(Extract(context.Background(), header)); got != span.SpanContext().Traceparent() {
+		t.Fatalf("Extract() traceparent = %q", got)
+	}
+}
+
+func TestFileExporterWritesJSONLines(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "nested", "spans.jsonl")
+	exporter, err := NewFileExporter(path)
+	if err != nil {
+		t.Fatalf("NewFileExporter() error = %v", err)
+	}
+	now := time.Date(2026, 10, 18, 12, 0, 0, 0, time.UTC)
+	spans := []SpanData{
+		{Service: "otter-camp", TraceID: "a", SpanID: "b", Name: "one", Start: now, End: now},
+		{Service: "otter-camp", TraceID: "a", SpanID: "c", ParentSpanID: "b", Name: "two", Start: now, End: now},
+	}
+	if err := exporter.Export(context.Background(), spans); err != nil {
+		t.Fatalf("Export() error = %v", err)
+	}
+	if err := exporter.Shutdown(context.Background()); err != nil {
+		t.Fatalf("Shutdown() error = %v", err)
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		t.Fatalf("open exported file: %v", err)
+	}
+	defer file.Close()
+	var names []string
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		var span SpanData
+		if err := json.Unmarshal(scanner.Bytes(), &span); err != nil {
+			t.Fatalf("decode line %q: %v", scanner.Text(), err)
+		}
+		names = append(names, span.Name)
+	}
+	if len(names) != 2 || names[0] != "one" || names[1] != "two" {
+		t.Fatalf("unexpected exported spans: %v", names)
+	}
+}
+
+func TestOTLPExporterPostsJSONToCollector(t *testing.T) {
+	var (
+		gotPath   string
+		gotHeader string
+		gotBody   map[string]any
+	)
+	collector := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotPath = r.URL.Path
+		gotHeader = r.Header.Get("X-Api-Key")
+		body, _ := io.ReadAll(r.Body)
+		_ = json.Unmarshal(body, &gotBody)
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer collector.Close()
+
+	exporter, err := NewOTLPExporter(collector.URL, map[string]string{"X-Api-Key": "secret"})
+	if err != nil {
+		t.Fatalf("NewOTLPExporter() error = %v", err)
+	}
+	now := time.Date(2026, 10, 18, 12, 0, 0, 0, time.UTC)
+	err = exporter.Export(context.Background(), []SpanData{{
+		Service:    "otter-camp",
+		TraceID:    "4bf92f3577b34da6a3ce929d0e0e4736",
+		SpanID:     "00f067aa0ba902b7",
+		Name:       "GET /api/issues",
+		Kind:       SpanKindServer,
+		Start:      now,
+		End:        now.Add(time.Millisecond),
+		Attributes: map[string]any{"http.status_code": 500},
+		Error:      "http status 500",
+	}})
+	if err != nil {
+		t.Fatalf("Export() error = %v", err)
+	}
+
+	if gotPath != "/v1/traces" {
+		t.Fatalf("collector path = %q, want /v1/traces", gotPath)
+	}
+	if gotHeader != "secret" {
+		t.Fatalf("collector header = %q", gotHeader)
+	}
+	resourceSpans, _ := gotBody["resourceSpans"].([]any)
+	if len(resourceSpans) != 1 {
+		t.Fatalf("expected one resourceSpans entry, got %#v", gotBody)
+	}
+	scopeSpans := resourceSpans[0].(map[string]any)["scopeSpans"].([]any)
+	span := scopeSpans[0].(map[string]any)["spans"].([]any)[0].(map[string]any)
+	if span["name"] != "GET /api/issues" || span["startTimeUnixNano"] != "1792324800000000000" {
+		t.Fatalf("unexpected span payload: %#v", span)
+	}
+	if status := span["status"].(map[string]any); status["code"] != float64(2) {
+		t.Fatalf("expected error status, got %#v", status)
+	}
+
+	if _, err := NewOTLPExporter("localhost:4318", nil); err == nil {
+		t.Fatalf("expected endpoint without scheme to be rejected")
+	}
+}
+
+func TestSetupWithoutExportersLeavesTracingDisabled(t *testing.T) {
+	previous := SetGlobal(nil)
+	defer SetGlobal(previous)
+
+	shutdown, err := Setup(Config{})
+	if err != nil {
+		t.Fatalf("Setup() error = %v", err)
+	}
+	if Enabled() {
+		t.Fatalf("expected tracing to stay disabled")
+	}
+	if err := shutdown(context.Background()); err != nil {
+		t.Fatalf("shutdown() error = %v", err)
+	}
+}
+
+func TestSetupWithFileExporterEnablesTracing(t *testing.T) {
+	previous := SetGlobal(nil)
+	defer SetGlobal(previous)
+
+	path := filepath.Join(t.TempDir(), "spans.jsonl")
+	shutdown, err := Setup(Config{FilePath: path})
+	if err != nil {
+		t.Fatalf("Setup() error = %v", err)
+	}
+	if !Enabled() {
+		t.Fatalf("expected tracing to be enabled")
+	}
+	_, span := Start(context.Background(), "setup")
+	span.End()
+	if err := shutdown(context.Background()); err != nil {
+		t.Fatalf("shutdown() error = %v", err)
+	}
+	if Enabled() {
+		t.Fatalf("expected shutdown to disable tracing")
+	}
+	data, err := os.ReadFile(path)
+	if err != nil || len(data) == 0 {
+		t.Fatalf("expected span written to %s, err=%v", path, err)
+	}
+}
@@ -3,6 +3,7 @@ package ws
 import (
 	"strings"
 	"sync"
+	"sync/atomic"
 
 	"github.com/gorilla/websocket"
 )
@@ -37,6 +38,9 @@ type Hub struct {
 	register   chan *Client
 	unregister chan *Client
 	broadcast  chan BroadcastMessage
+
+	// clientCount mirrors len(clients) for readers outside the Run loop.
+	clientCount atomic.Int64
 }
 
 // NewHub builds a new Hub.
@@ -77,9 +81,15 @@ func (h *Hub) Run() {
 				}
 			}
 		}
+		h.clientCount.Store(int64(len(h.clients)))
 	}
 }
 
+// ClientCount reports how many websocket clients are connected.
+func (h *Hub) ClientCount() int {
+	return int(h.clientCount.Load())
+}
+
 // Broadcast sends a payload to all clients in an org.
 func (h *Hub) Broadcast(orgID string, payload []byte) {
 	h.broadcast <- BroadcastMessage{OrgID: orgID, Payload: payload}
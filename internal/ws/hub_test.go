@@ -78,3 +78,22 @@ func TestHubBroadcastTopicFiltersBySubscription(t *testing.T) {
 	}
 	mustNotReceiveMessage(t, clientOtherOrg.Send, 80*time.Millisecond)
 }
+
+func TestHubClientCountTracksRegistrations(t *testing.T) {
+	hub := NewHub()
+	go hub.Run()
+
+	first := NewClient(hub, nil)
+	second := NewClient(hub, nil)
+	hub.Register(first)
+	hub.Register(second)
+	hub.Unregister(first)
+
+	deadline := time.Now().Add(time.Second)
+	for hub.ClientCount() != 1 && time.Now().Before(deadline) {
+		time.Sleep(5 * time.Millisecond)
+	}
+	if got := hub.ClientCount(); got != 1 {
+		t.Fatalf("expected 1 client, got %d", got)
+	}
+}
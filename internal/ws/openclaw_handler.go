@@ -16,6 +16,7 @@ import (
 	"time"
 
 	"github.com/gorilla/websocket"
+	"github.com/samhotchkiss/otter-camp/internal/tracing"
 )
 
 // OpenClawHandler manages WebSocket connections from OpenClaw bridge.
@@ -93,6 +94,8 @@ type OpenClawEvent struct {
 	Timestamp time.Time       `json:"timestamp"`
 	OrgID     string          `json:"org_id"`
 	Data      json.RawMessage `json:"data"`
+	// Traceparent carries W3C trace context in either direction.
+	Traceparent string `json:"traceparent,omitempty"`
 }
 
 // AgentStatusEvent is sent when an agent's status changes.
@@ -283,6 +286,14 @@ func (h *OpenClawHandler) handleEvent(event OpenClawEvent) {
 
 	log.Printf("[openclaw-ws] Event: %s (org: %s)", event.Type, event.OrgID)
 
+	// Only continue traces the bridge started; untraced pushes stay untraced.
+	if event.Traceparent != "" {
+		ctx := tracing.ContextWithTraceparent(context.Background(), event.Traceparent)
+		_, span := tracing.StartKind(ctx, "openclaw.event "+event.Type, tracing.SpanKindConsumer)
+		span.SetAttribute("openclaw.event_type", event.Type)
+		defer span.End()
+	}
+
 	// Re-broadcast to browser clients
 	payload, err := json.Marshal(map[string]interface{}{
 		"type":      event.Type,
@@ -335,7 +346,7 @@ func (h *OpenClawHandler) Request(
 	ctx context.Context,
 	eventType, orgID string,
 	data map[string]any,
-) (json.RawMessage, error) {
+) (_ json.RawMessage, err error) {
 	if h == nil {
 		return nil, errors.New("openclaw handler is nil")
 	}
@@ -344,6 +355,13 @@ func (h *OpenClawHandler) Request(
 		return nil, errors.New("event type is required")
 	}
 
+	ctx, span := tracing.StartKind(ctx, "openclaw.request "+requestType, tracing.SpanKindClient)
+	span.SetAttribute("openclaw.event_type", requestType)
+	defer func() {
+		span.RecordError(err)
+		span.End()
+	}()
+
 	requestID := h.nextRequestID()
 	payloadMap := make(map[string]any, len(data)+1)
 	for key, value := range data {
@@ -367,10 +385,11 @@ func (h *OpenClawHandler) Request(
 		h.requestMu.Unlock()
 
 		sendErr := h.SendToOpenClaw(OpenClawEvent{
-			Type:      requestType,
-			Timestamp: time.Now().UTC(),
-			OrgID:     strings.TrimSpace(orgID),
-			Data:      payload,
+			Type:        requestType,
+			Timestamp:   time.Now().UTC(),
+			OrgID:       strings.TrimSpace(orgID),
+			Data:        payload,
+			Traceparent: tracing.Traceparent(ctx),
 		})
 		if sendErr != nil {
 			h.removeRequest(requestID)
@@ -0,0 +1,13 @@
+DROP TABLE IF EXISTS agent_job_blackout_windows;
+DROP INDEX IF EXISTS idx_agent_job_runs_running;
+
+ALTER TABLE agent_jobs DROP CONSTRAINT IF EXISTS agent_jobs_max_agent_concurrency_positive;
+ALTER TABLE agent_jobs DROP CONSTRAINT IF EXISTS agent_jobs_jitter_non_negative;
+ALTER TABLE agent_jobs DROP CONSTRAINT IF EXISTS agent_jobs_catch_up_policy_check;
+ALTER TABLE agent_jobs DROP CONSTRAINT IF EXISTS agent_jobs_overlap_policy_check;
+
+ALTER TABLE agent_jobs
+    DROP COLUMN IF EXISTS max_agent_concurrency,
+    DROP COLUMN IF EXISTS jitter_ms,
+    DROP COLUMN IF EXISTS catch_up_policy,
+    DROP COLUMN IF EXISTS overlap_policy;
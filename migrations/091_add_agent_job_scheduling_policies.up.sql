@@ -0,0 +1,68 @@
+ALTER TABLE agent_jobs
+    ADD COLUMN IF NOT EXISTS overlap_policy TEXT NOT NULL DEFAULT 'skip',
+    ADD COLUMN IF NOT EXISTS catch_up_policy TEXT NOT NULL DEFAULT 'run_once',
+    ADD COLUMN IF NOT EXISTS jitter_ms BIGINT NOT NULL DEFAULT 0,
+    ADD COLUMN IF NOT EXISTS max_agent_concurrency INT;
+
+ALTER TABLE agent_jobs DROP CONSTRAINT IF EXISTS agent_jobs_overlap_policy_check;
+ALTER TABLE agent_jobs
+    ADD CONSTRAINT agent_jobs_overlap_policy_check
+    CHECK (overlap_policy IN ('skip', 'queue', 'replace'));
+
+ALTER TABLE agent_jobs DROP CONSTRAINT IF EXISTS agent_jobs_catch_up_policy_check;
+ALTER TABLE agent_jobs
+    ADD CONSTRAINT agent_jobs_catch_up_policy_check
+    CHECK (catch_up_policy IN ('run_once', 'run_all', 'skip'));
+
+ALTER TABLE agent_jobs DROP CONSTRAINT IF EXISTS agent_jobs_jitter_non_negative;
+ALTER TABLE agent_jobs
+    ADD CONSTRAINT agent_jobs_jitter_non_negative
+    CHECK (jitter_ms >= 0);
+
+ALTER TABLE agent_jobs DROP CONSTRAINT IF EXISTS agent_jobs_max_agent_concurrency_positive;
+ALTER TABLE agent_jobs
+    ADD CONSTRAINT agent_jobs_max_agent_concurrency_positive
+    CHECK (max_agent_concurrency IS NULL OR max_agent_concurrency > 0);
+
+-- Per-agent concurrency checks count in-flight runs across all of an agent's jobs.
+CREATE INDEX IF NOT EXISTS idx_agent_job_runs_running
+    ON agent_job_runs (job_id)
+    WHERE status = 'running';
+
+-- Org-level blackout windows. Dates and minutes are wall-clock values evaluated
+-- in each job's own timezone, so one "weekend" window covers every region.
+CREATE TABLE IF NOT EXISTS agent_job_blackout_windows (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    org_id UUID NOT NULL REFERENCES organizations(id) ON DELETE CASCADE,
+    name TEXT NOT NULL,
+    days_of_week SMALLINT[] NOT NULL DEFAULT '{}',
+    start_date DATE,
+    end_date DATE,
+    start_minute INT NOT NULL DEFAULT 0,
+    end_minute INT NOT NULL DEFAULT 1440,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+
+    CONSTRAINT agent_job_blackout_windows_minutes_valid
+        CHECK (start_minute >= 0 AND end_minute <= 1440 AND start_minute < end_minute),
+    CONSTRAINT agent_job_blackout_windows_dates_valid
+        CHECK (start_date IS NULL OR end_date IS NULL OR start_date <= end_date),
+    CONSTRAINT agent_job_blackout_windows_days_valid
+        CHECK (days_of_week <@ ARRAY[0, 1, 2, 3, 4, 5, 6]::SMALLINT[])
+);
+
+CREATE INDEX IF NOT EXISTS idx_agent_job_blackout_windows_org
+    ON agent_job_blackout_windows (org_id);
+
+DROP TRIGGER IF EXISTS agent_job_blackout_windows_updated_at_trg ON agent_job_blackout_windows;
+CREATE TRIGGER agent_job_blackout_windows_updated_at_trg
+BEFORE UPDATE ON agent_job_blackout_windows
+FOR EACH ROW
+EXECUTE FUNCTION update_updated_at_column();
+
+ALTER TABLE agent_job_blackout_windows ENABLE ROW LEVEL SECURITY;
+ALTER TABLE agent_job_blackout_windows FORCE ROW LEVEL SECURITY;
+DROP POLICY IF EXISTS agent_job_blackout_windows_org_isolation ON agent_job_blackout_windows;
+CREATE POLICY agent_job_blackout_windows_org_isolation ON agent_job_blackout_windows
+    USING (org_id = current_org_id())
+    WITH CHECK (org_id = current_org_id());
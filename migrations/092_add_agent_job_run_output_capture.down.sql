@@ -0,0 +1,14 @@
+DROP INDEX IF EXISTS idx_agent_job_runs_awaiting_reply;
+
+ALTER TABLE agent_job_runs
+    DROP COLUMN IF EXISTS reply_deadline_at,
+    DROP COLUMN IF EXISTS reply_message_id,
+    DROP COLUMN IF EXISTS output;
+
+ALTER TABLE agent_jobs DROP CONSTRAINT IF EXISTS agent_jobs_reply_timeout_positive;
+
+ALTER TABLE agent_jobs
+    DROP COLUMN IF EXISTS reply_timeout_ms,
+    DROP COLUMN IF EXISTS success_classifier_prompt,
+    DROP COLUMN IF EXISTS success_json_field,
+    DROP COLUMN IF EXISTS success_pattern;
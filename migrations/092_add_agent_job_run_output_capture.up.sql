@@ -0,0 +1,21 @@
+ALTER TABLE agent_jobs
+    ADD COLUMN IF NOT EXISTS success_pattern TEXT,
+    ADD COLUMN IF NOT EXISTS success_json_field TEXT,
+    ADD COLUMN IF NOT EXISTS success_classifier_prompt TEXT,
+    ADD COLUMN IF NOT EXISTS reply_timeout_ms BIGINT;
+
+ALTER TABLE agent_jobs DROP CONSTRAINT IF EXISTS agent_jobs_reply_timeout_positive;
+ALTER TABLE agent_jobs
+    ADD CONSTRAINT agent_jobs_reply_timeout_positive
+    CHECK (reply_timeout_ms IS NULL OR reply_timeout_ms > 0);
+
+-- message_id keeps pointing at the prompt; the agent's reply is captured
+-- separately so the run history shows both sides of the exchange.
+ALTER TABLE agent_job_runs
+    ADD COLUMN IF NOT EXISTS output TEXT,
+    ADD COLUMN IF NOT EXISTS reply_message_id UUID REFERENCES chat_messages(id) ON DELETE SET NULL,
+    ADD COLUMN IF NOT EXISTS reply_deadline_at TIMESTAMPTZ;
+
+CREATE INDEX IF NOT EXISTS idx_agent_job_runs_awaiting_reply
+    ON agent_job_runs (started_at)
+    WHERE status = 'running' AND message_id IS NOT NULL;
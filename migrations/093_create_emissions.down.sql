@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS emission_latest_by_source;
+DROP TABLE IF EXISTS emissions;
@@ -0,0 +1,81 @@
+-- Append-only log of agent/bridge emissions. seq orders the stream and backs
+-- cursor replay; id is the emitter-supplied identifier and is unique per org so
+-- retried batches are not stored twice.
+CREATE TABLE IF NOT EXISTS emissions (
+    seq BIGINT GENERATED ALWAYS AS IDENTITY PRIMARY KEY,
+    id TEXT NOT NULL,
+    org_id UUID NOT NULL REFERENCES organizations(id) ON DELETE CASCADE,
+    source_type TEXT NOT NULL,
+    source_id TEXT NOT NULL,
+    kind TEXT NOT NULL,
+    summary TEXT NOT NULL,
+    detail TEXT,
+    project_id TEXT,
+    issue_id TEXT,
+    issue_number BIGINT,
+    progress_current INT,
+    progress_total INT,
+    progress_unit TEXT,
+    emitted_at TIMESTAMPTZ NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+
+    CONSTRAINT emissions_org_id_unique UNIQUE (org_id, id),
+    CONSTRAINT emissions_source_type_check
+        CHECK (source_type IN ('agent', 'bridge', 'codex', 'cron', 'system')),
+    CONSTRAINT emissions_kind_check
+        CHECK (kind IN ('status', 'progress', 'log', 'milestone', 'error'))
+);
+
+CREATE INDEX IF NOT EXISTS idx_emissions_org_seq
+    ON emissions (org_id, seq);
+CREATE INDEX IF NOT EXISTS idx_emissions_org_source_seq
+    ON emissions (org_id, source_id, seq);
+CREATE INDEX IF NOT EXISTS idx_emissions_org_project_seq
+    ON emissions (org_id, project_id, seq)
+    WHERE project_id IS NOT NULL;
+CREATE INDEX IF NOT EXISTS idx_emissions_org_created_at
+    ON emissions (org_id, created_at);
+
+ALTER TABLE emissions ENABLE ROW LEVEL SECURITY;
+ALTER TABLE emissions FORCE ROW LEVEL SECURITY;
+DROP POLICY IF EXISTS emissions_org_isolation ON emissions;
+CREATE POLICY emissions_org_isolation ON emissions
+    USING (org_id = current_org_id())
+    WITH CHECK (org_id = current_org_id());
+
+-- Latest emission per source, kept as a full copy so a quiet source still has
+-- a status after its rows age out of the log.
+CREATE TABLE IF NOT EXISTS emission_latest_by_source (
+    org_id UUID NOT NULL REFERENCES organizations(id) ON DELETE CASCADE,
+    source_id TEXT NOT NULL,
+    seq BIGINT NOT NULL,
+    id TEXT NOT NULL,
+    source_type TEXT NOT NULL,
+    kind TEXT NOT NULL,
+    summary TEXT NOT NULL,
+    detail TEXT,
+    project_id TEXT,
+    issue_id TEXT,
+    issue_number BIGINT,
+    progress_current INT,
+    progress_total INT,
+    progress_unit TEXT,
+    emitted_at TIMESTAMPTZ NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+
+    PRIMARY KEY (org_id, source_id)
+);
+
+DROP TRIGGER IF EXISTS emission_latest_by_source_updated_at_trg ON emission_latest_by_source;
+CREATE TRIGGER emission_latest_by_source_updated_at_trg
+BEFORE UPDATE ON emission_latest_by_source
+FOR EACH ROW
+EXECUTE FUNCTION update_updated_at_column();
+
+ALTER TABLE emission_latest_by_source ENABLE ROW LEVEL SECURITY;
+ALTER TABLE emission_latest_by_source FORCE ROW LEVEL SECURITY;
+DROP POLICY IF EXISTS emission_latest_by_source_org_isolation ON emission_latest_by_source;
+CREATE POLICY emission_latest_by_source_org_isolation ON emission_latest_by_source
+    USING (org_id = current_org_id())
+    WITH CHECK (org_id = current_org_id());
@@ -0,0 +1,13 @@
+DROP TABLE IF EXISTS exec_approval_votes;
+
+ALTER TABLE exec_approval_requests
+    DROP CONSTRAINT IF EXISTS exec_approval_requests_decided_by_check;
+ALTER TABLE exec_approval_requests
+    DROP COLUMN IF EXISTS approval_count,
+    DROP COLUMN IF EXISTS required_approvals,
+    DROP COLUMN IF EXISTS decided_by,
+    DROP COLUMN IF EXISTS rule_id,
+    DROP COLUMN IF EXISTS risk_tags,
+    DROP COLUMN IF EXISTS project_id;
+
+DROP TABLE IF EXISTS exec_approval_rules;
@@ -0,0 +1,81 @@
+-- Policy rules evaluated against incoming exec approval requests. Project rules
+-- (project_id set) are checked before org-wide ones; within a scope the lowest
+-- priority wins and the first matching rule decides.
+CREATE TABLE IF NOT EXISTS exec_approval_rules (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    org_id UUID NOT NULL REFERENCES organizations(id) ON DELETE CASCADE,
+    project_id UUID REFERENCES projects(id) ON DELETE CASCADE,
+    name TEXT NOT NULL,
+    priority INT NOT NULL DEFAULT 100,
+    enabled BOOLEAN NOT NULL DEFAULT TRUE,
+    agent_id UUID REFERENCES agents(id) ON DELETE CASCADE,
+    command_pattern TEXT,
+    command_match TEXT NOT NULL DEFAULT 'glob',
+    cwd_pattern TEXT,
+    risk_tags TEXT[] NOT NULL DEFAULT '{}',
+    action TEXT NOT NULL,
+    required_approvals INT NOT NULL DEFAULT 1,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+
+    CONSTRAINT exec_approval_rules_command_match_check
+        CHECK (command_match IN ('glob', 'regex')),
+    CONSTRAINT exec_approval_rules_action_check
+        CHECK (action IN ('auto_approve', 'auto_deny', 'require_approvers')),
+    CONSTRAINT exec_approval_rules_required_approvals_check
+        CHECK (required_approvals BETWEEN 1 AND 10)
+);
+
+CREATE INDEX IF NOT EXISTS idx_exec_approval_rules_org_priority
+    ON exec_approval_rules (org_id, priority, created_at)
+    WHERE enabled = TRUE;
+
+DROP TRIGGER IF EXISTS exec_approval_rules_updated_at_trg ON exec_approval_rules;
+CREATE TRIGGER exec_approval_rules_updated_at_trg
+BEFORE UPDATE ON exec_approval_rules
+FOR EACH ROW
+EXECUTE FUNCTION update_updated_at_column();
+
+ALTER TABLE exec_approval_rules ENABLE ROW LEVEL SECURITY;
+ALTER TABLE exec_approval_rules FORCE ROW LEVEL SECURITY;
+DROP POLICY IF EXISTS exec_approval_rules_org_isolation ON exec_approval_rules;
+CREATE POLICY exec_approval_rules_org_isolation ON exec_approval_rules
+    USING (org_id = current_org_id())
+    WITH CHECK (org_id = current_org_id());
+
+-- decided_by records whether a request was resolved by a rule or by people;
+-- rule_id is the matching rule for automated decisions and approver quorums.
+ALTER TABLE exec_approval_requests
+    ADD COLUMN IF NOT EXISTS project_id UUID,
+    ADD COLUMN IF NOT EXISTS risk_tags TEXT[] NOT NULL DEFAULT '{}',
+    ADD COLUMN IF NOT EXISTS rule_id UUID REFERENCES exec_approval_rules(id) ON DELETE SET NULL,
+    ADD COLUMN IF NOT EXISTS decided_by TEXT,
+    ADD COLUMN IF NOT EXISTS required_approvals INT NOT NULL DEFAULT 1,
+    ADD COLUMN IF NOT EXISTS approval_count INT NOT NULL DEFAULT 0;
+
+ALTER TABLE exec_approval_requests
+    DROP CONSTRAINT IF EXISTS exec_approval_requests_decided_by_check;
+ALTER TABLE exec_approval_requests
+    ADD CONSTRAINT exec_approval_requests_decided_by_check
+    CHECK (decided_by IS NULL OR decided_by IN ('rule', 'human'));
+
+CREATE TABLE IF NOT EXISTS exec_approval_votes (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    org_id UUID NOT NULL REFERENCES organizations(id) ON DELETE CASCADE,
+    request_id UUID NOT NULL REFERENCES exec_approval_requests(id) ON DELETE CASCADE,
+    user_id UUID NOT NULL,
+    decision TEXT NOT NULL,
+    comment TEXT,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+
+    CONSTRAINT exec_approval_votes_request_user_unique UNIQUE (request_id, user_id),
+    CONSTRAINT exec_approval_votes_decision_check
+        CHECK (decision IN ('approve', 'deny'))
+);
+
+ALTER TABLE exec_approval_votes ENABLE ROW LEVEL SECURITY;
+ALTER TABLE exec_approval_votes FORCE ROW LEVEL SECURITY;
+DROP POLICY IF EXISTS exec_approval_votes_org_isolation ON exec_approval_votes;
+CREATE POLICY exec_approval_votes_org_isolation ON exec_approval_votes
+    USING (org_id = current_org_id())
+    WITH CHECK (org_id = current_org_id());
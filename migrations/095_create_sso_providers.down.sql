@@ -0,0 +1,6 @@
+DROP TABLE IF EXISTS sso_login_states;
+
+ALTER TABLE organizations
+    DROP COLUMN IF EXISTS sso_enforced;
+
+DROP TABLE IF EXISTS org_sso_providers;
@@ -0,0 +1,71 @@
+-- Per-org identity providers for single sign-on. OIDC providers are found
+-- through discovery on oidc_issuer; SAML providers are configured from the
+-- IdP's metadata (entity id, SSO URL and signing certificate).
+CREATE TABLE IF NOT EXISTS org_sso_providers (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    org_id UUID NOT NULL REFERENCES organizations(id) ON DELETE CASCADE,
+    kind TEXT NOT NULL,
+    name TEXT NOT NULL,
+    enabled BOOLEAN NOT NULL DEFAULT TRUE,
+    oidc_issuer TEXT,
+    oidc_client_id TEXT,
+    oidc_client_secret TEXT,
+    oidc_scopes TEXT[] NOT NULL DEFAULT '{openid,email,profile}',
+    saml_idp_entity_id TEXT,
+    saml_idp_sso_url TEXT,
+    saml_idp_certificate TEXT,
+    email_claim TEXT NOT NULL DEFAULT 'email',
+    name_claim TEXT NOT NULL DEFAULT 'name',
+    groups_claim TEXT NOT NULL DEFAULT 'groups',
+    role_mappings JSONB NOT NULL DEFAULT '{}'::jsonb,
+    default_role TEXT NOT NULL DEFAULT 'member',
+    allowed_domains TEXT[] NOT NULL DEFAULT '{}',
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+
+    CONSTRAINT org_sso_providers_org_name_unique UNIQUE (org_id, name),
+    CONSTRAINT org_sso_providers_kind_check
+        CHECK (kind IN ('oidc', 'saml')),
+    CONSTRAINT org_sso_providers_oidc_check
+        CHECK (kind <> 'oidc' OR (oidc_issuer IS NOT NULL AND oidc_client_id IS NOT NULL)),
+    CONSTRAINT org_sso_providers_saml_check
+        CHECK (kind <> 'saml' OR (saml_idp_entity_id IS NOT NULL AND saml_idp_sso_url IS NOT NULL AND saml_idp_certificate IS NOT NULL)),
+    CONSTRAINT org_sso_providers_default_role_check
+        CHECK (default_role IN ('owner', 'maintainer', 'member', 'viewer'))
+);
+
+DROP TRIGGER IF EXISTS org_sso_providers_updated_at_trg ON org_sso_providers;
+CREATE TRIGGER org_sso_providers_updated_at_trg
+BEFORE UPDATE ON org_sso_providers
+FOR EACH ROW
+EXECUTE FUNCTION update_updated_at_column();
+
+ALTER TABLE org_sso_providers ENABLE ROW LEVEL SECURITY;
+ALTER TABLE org_sso_providers FORCE ROW LEVEL SECURITY;
+DROP POLICY IF EXISTS org_sso_providers_org_isolation ON org_sso_providers;
+CREATE POLICY org_sso_providers_org_isolation ON org_sso_providers
+    USING (org_id = current_org_id())
+    WITH CHECK (org_id = current_org_id());
+
+-- SSO-only orgs reject the password-less magic link and OpenClaw login flows.
+ALTER TABLE organizations
+    ADD COLUMN IF NOT EXISTS sso_enforced BOOLEAN NOT NULL DEFAULT FALSE;
+
+-- In-flight logins, like auth_requests: written before the user is known, so
+-- no RLS. Rows are single use (consumed_at) and short lived.
+CREATE TABLE IF NOT EXISTS sso_login_states (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    org_id UUID NOT NULL REFERENCES organizations(id) ON DELETE CASCADE,
+    provider_id UUID NOT NULL REFERENCES org_sso_providers(id) ON DELETE CASCADE,
+    state TEXT NOT NULL UNIQUE,
+    nonce TEXT,
+    code_verifier TEXT,
+    saml_request_id TEXT,
+    return_to TEXT,
+    expires_at TIMESTAMPTZ NOT NULL,
+    consumed_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_sso_login_states_expires_at
+    ON sso_login_states (expires_at);
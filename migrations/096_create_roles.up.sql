@@ -0,0 +1,55 @@
+-- Org-defined roles. Built-in roles (owner, maintainer, member, viewer) live
+-- in code; custom roles list their capabilities explicitly.
+CREATE TABLE IF NOT EXISTS org_roles (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    org_id UUID NOT NULL REFERENCES organizations(id) ON DELETE CASCADE,
+    name TEXT NOT NULL,
+    description TEXT NOT NULL DEFAULT '',
+    capabilities TEXT[] NOT NULL DEFAULT '{}',
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+
+    CONSTRAINT org_roles_org_name_unique UNIQUE (org_id, name),
+    CONSTRAINT org_roles_name_check
+        CHECK (name ~ '^[a-z][a-z0-9_-]{0,62}$'),
+    CONSTRAINT org_roles_builtin_name_check
+        CHECK (name NOT IN ('owner', 'maintainer', 'member', 'viewer'))
+);
+
+DROP TRIGGER IF EXISTS org_roles_updated_at_trg ON org_roles;
+CREATE TRIGGER org_roles_updated_at_trg
+BEFORE UPDATE ON org_roles
+FOR EACH ROW
+EXECUTE FUNCTION update_updated_at_column();
+
+ALTER TABLE org_roles ENABLE ROW LEVEL SECURITY;
+ALTER TABLE org_roles FORCE ROW LEVEL SECURITY;
+DROP POLICY IF EXISTS org_roles_org_isolation ON org_roles;
+CREATE POLICY org_roles_org_isolation ON org_roles
+    USING (org_id = current_org_id())
+    WITH CHECK (org_id = current_org_id());
+
+-- Grants a built-in or custom role to a user, either org-wide (project_id
+-- NULL) or for a single project. These add to the user's base role in
+-- users.role; they never take capabilities away.
+CREATE TABLE IF NOT EXISTS role_bindings (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    org_id UUID NOT NULL REFERENCES organizations(id) ON DELETE CASCADE,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    role TEXT NOT NULL,
+    project_id UUID REFERENCES projects(id) ON DELETE CASCADE,
+    created_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS role_bindings_unique_idx
+    ON role_bindings (org_id, user_id, role, COALESCE(project_id, '00000000-0000-0000-0000-000000000000'::uuid));
+CREATE INDEX IF NOT EXISTS role_bindings_org_user_idx
+    ON role_bindings (org_id, user_id);
+
+ALTER TABLE role_bindings ENABLE ROW LEVEL SECURITY;
+ALTER TABLE role_bindings FORCE ROW LEVEL SECURITY;
+DROP POLICY IF EXISTS role_bindings_org_isolation ON role_bindings;
+CREATE POLICY role_bindings_org_isolation ON role_bindings
+    USING (org_id = current_org_id())
+    WITH CHECK (org_id = current_org_id());
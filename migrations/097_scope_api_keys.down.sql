@@ -0,0 +1,9 @@
+DROP INDEX IF EXISTS api_keys_org_created_idx;
+DROP INDEX IF EXISTS api_keys_key_hash_idx;
+
+ALTER TABLE api_keys
+    DROP COLUMN IF EXISTS created_by,
+    DROP COLUMN IF EXISTS last_used_ip,
+    DROP COLUMN IF EXISTS allowed_ips,
+    DROP COLUMN IF EXISTS agent_ids,
+    DROP COLUMN IF EXISTS project_ids;
@@ -0,0 +1,12 @@
+-- Scoped API keys: optional project/agent restrictions, IP allowlists and
+-- last-used tracking. scopes, expires_at and last_used_at already exist
+-- (migration 090).
+ALTER TABLE api_keys
+    ADD COLUMN IF NOT EXISTS project_ids UUID[] NOT NULL DEFAULT '{}',
+    ADD COLUMN IF NOT EXISTS agent_ids UUID[] NOT NULL DEFAULT '{}',
+    ADD COLUMN IF NOT EXISTS allowed_ips TEXT[] NOT NULL DEFAULT '{}',
+    ADD COLUMN IF NOT EXISTS last_used_ip TEXT,
+    ADD COLUMN IF NOT EXISTS created_by UUID REFERENCES users(id) ON DELETE SET NULL;
+
+CREATE UNIQUE INDEX IF NOT EXISTS api_keys_key_hash_idx ON api_keys (key_hash);
+CREATE INDEX IF NOT EXISTS api_keys_org_created_idx ON api_keys (org_id, created_at DESC);
@@ -0,0 +1,54 @@
+-- Append-only, hash-chained audit log. Each org has its own chain: seq is
+-- gapless per org and hash covers the entry plus the previous entry's hash.
+CREATE TABLE IF NOT EXISTS audit_log (
+    id BIGSERIAL PRIMARY KEY,
+    org_id UUID NOT NULL REFERENCES organizations(id) ON DELETE CASCADE,
+    seq BIGINT NOT NULL,
+    actor_type TEXT NOT NULL,
+    actor_id TEXT NOT NULL DEFAULT '',
+    action TEXT NOT NULL,
+    target_type TEXT NOT NULL DEFAULT '',
+    target_id TEXT NOT NULL DEFAULT '',
+    before JSONB,
+    after JSONB,
+    diff JSONB,
+    ip TEXT NOT NULL DEFAULT '',
+    user_agent TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMPTZ NOT NULL,
+    prev_hash TEXT NOT NULL DEFAULT '',
+    hash TEXT NOT NULL,
+
+    CONSTRAINT audit_log_org_seq_unique UNIQUE (org_id, seq),
+    CONSTRAINT audit_log_actor_type_check
+        CHECK (actor_type IN ('user', 'api_key', 'workspace', 'system')),
+    CONSTRAINT audit_log_action_check
+        CHECK (action ~ '^[a-z][a-z0-9_]*(\.[a-z0-9_]+)+$')
+);
+
+CREATE INDEX IF NOT EXISTS audit_log_org_created_idx ON audit_log (org_id, created_at DESC);
+CREATE INDEX IF NOT EXISTS audit_log_org_action_idx ON audit_log (org_id, action);
+CREATE INDEX IF NOT EXISTS audit_log_org_target_idx ON audit_log (org_id, target_type, target_id);
+
+-- Rows can never be updated, and only disappear with their organization.
+CREATE OR REPLACE FUNCTION audit_log_append_only()
+RETURNS TRIGGER AS $$
+BEGIN
+    IF TG_OP = 'DELETE' AND NOT EXISTS (SELECT 1 FROM organizations WHERE id = OLD.org_id) THEN
+        RETURN OLD;
+    END IF;
+    RAISE EXCEPTION 'audit_log is append-only';
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS audit_log_append_only_trg ON audit_log;
+CREATE TRIGGER audit_log_append_only_trg
+BEFORE UPDATE OR DELETE ON audit_log
+FOR EACH ROW
+EXECUTE FUNCTION audit_log_append_only();
+
+ALTER TABLE audit_log ENABLE ROW LEVEL SECURITY;
+ALTER TABLE audit_log FORCE ROW LEVEL SECURITY;
+DROP POLICY IF EXISTS audit_log_org_isolation ON audit_log;
+CREATE POLICY audit_log_org_isolation ON audit_log
+    USING (org_id = current_org_id())
+    WITH CHECK (org_id = current_org_id());
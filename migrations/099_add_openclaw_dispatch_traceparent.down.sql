@@ -0,0 +1,2 @@
+ALTER TABLE openclaw_dispatch_queue
+    DROP COLUMN IF EXISTS traceparent;
@@ -0,0 +1,2 @@
+ALTER TABLE openclaw_dispatch_queue
+    ADD COLUMN IF NOT EXISTS traceparent TEXT;
@@ -0,0 +1,20 @@
+-- Token buckets for API rate limiting, shared by every replica. One row per
+-- (org, subject, route group); tokens refill continuously from updated_at.
+-- Not org-isolated: the limiter runs before a request's workspace is known.
+CREATE TABLE IF NOT EXISTS rate_limit_buckets (
+    bucket_key TEXT PRIMARY KEY,
+    org_id UUID REFERENCES organizations(id) ON DELETE CASCADE,
+    subject TEXT NOT NULL,
+    route_group TEXT NOT NULL,
+    plan TEXT NOT NULL,
+    tokens DOUBLE PRECISION NOT NULL,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    last_allowed BOOLEAN NOT NULL DEFAULT TRUE,
+    throttled_total BIGINT NOT NULL DEFAULT 0,
+    last_throttled_at TIMESTAMPTZ
+);
+
+CREATE INDEX IF NOT EXISTS rate_limit_buckets_org_throttled_idx
+    ON rate_limit_buckets (org_id, last_throttled_at DESC)
+    WHERE last_throttled_at IS NOT NULL;
+CREATE INDEX IF NOT EXISTS rate_limit_buckets_updated_idx ON rate_limit_buckets (updated_at);
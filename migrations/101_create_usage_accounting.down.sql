@@ -0,0 +1,4 @@
+ALTER TABLE agent_jobs DROP COLUMN IF EXISTS paused_by_budget_id;
+DROP TABLE IF EXISTS usage_budgets;
+DROP TABLE IF EXISTS usage_daily_rollups;
+DROP TABLE IF EXISTS model_pricing;
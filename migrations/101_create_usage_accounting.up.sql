@@ -0,0 +1,90 @@
+-- Per-org model pricing. A model ending in '*' prices every model with that
+-- prefix; the longest match wins.
+CREATE TABLE IF NOT EXISTS model_pricing (
+    org_id UUID NOT NULL REFERENCES organizations(id) ON DELETE CASCADE,
+    model TEXT NOT NULL,
+    usd_per_million_tokens NUMERIC(12, 4) NOT NULL CHECK (usd_per_million_tokens >= 0),
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (org_id, model),
+    CONSTRAINT model_pricing_model_not_empty CHECK (btrim(model) <> '')
+);
+
+-- Daily token and cost totals, rebuilt from agent_activity_events for every
+-- day that receives events. Cost uses the pricing in effect at rebuild time.
+CREATE TABLE IF NOT EXISTS usage_daily_rollups (
+    org_id UUID NOT NULL REFERENCES organizations(id) ON DELETE CASCADE,
+    day DATE NOT NULL,
+    agent_id TEXT NOT NULL,
+    project_id UUID REFERENCES projects(id) ON DELETE CASCADE,
+    model TEXT NOT NULL DEFAULT '',
+    tokens BIGINT NOT NULL DEFAULT 0,
+    cost_usd NUMERIC(14, 6) NOT NULL DEFAULT 0,
+    events INTEGER NOT NULL DEFAULT 0,
+    priced BOOLEAN NOT NULL DEFAULT TRUE,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS usage_daily_rollups_key_idx
+    ON usage_daily_rollups (
+        org_id,
+        day,
+        agent_id,
+        (COALESCE(project_id, '00000000-0000-0000-0000-000000000000'::uuid)),
+        model
+    );
+CREATE INDEX IF NOT EXISTS usage_daily_rollups_org_project_day_idx
+    ON usage_daily_rollups (org_id, project_id, day)
+    WHERE project_id IS NOT NULL;
+
+-- Spend limits per agent or project. Crossing soft_percent of limit_usd
+-- notifies once per period; reaching the limit of a hard budget pauses the
+-- agent's jobs and stops dispatch until the period rolls over or the budget
+-- is raised.
+CREATE TABLE IF NOT EXISTS usage_budgets (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    org_id UUID NOT NULL REFERENCES organizations(id) ON DELETE CASCADE,
+    scope TEXT NOT NULL CHECK (scope IN ('agent', 'project')),
+    scope_id TEXT NOT NULL,
+    period TEXT NOT NULL DEFAULT 'month' CHECK (period IN ('day', 'month')),
+    limit_usd NUMERIC(14, 4) NOT NULL CHECK (limit_usd > 0),
+    soft_percent INTEGER NOT NULL DEFAULT 80 CHECK (soft_percent > 0 AND soft_percent <= 100),
+    hard BOOLEAN NOT NULL DEFAULT TRUE,
+    period_start DATE,
+    spent_usd NUMERIC(14, 6) NOT NULL DEFAULT 0,
+    soft_notified_at TIMESTAMPTZ,
+    hard_tripped_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    CONSTRAINT usage_budgets_scope_unique UNIQUE (org_id, scope, scope_id, period)
+);
+
+CREATE INDEX IF NOT EXISTS usage_budgets_tripped_idx
+    ON usage_budgets (org_id, scope, scope_id)
+    WHERE hard_tripped_at IS NOT NULL;
+
+-- Jobs paused by a tripped budget, so they can be resumed when it clears
+-- without touching jobs someone paused by hand.
+ALTER TABLE agent_jobs
+    ADD COLUMN IF NOT EXISTS paused_by_budget_id UUID REFERENCES usage_budgets(id) ON DELETE SET NULL;
+
+ALTER TABLE model_pricing ENABLE ROW LEVEL SECURITY;
+ALTER TABLE model_pricing FORCE ROW LEVEL SECURITY;
+DROP POLICY IF EXISTS model_pricing_org_isolation ON model_pricing;
+CREATE POLICY model_pricing_org_isolation ON model_pricing
+    USING (org_id = current_org_id())
+    WITH CHECK (org_id = current_org_id());
+
+ALTER TABLE usage_daily_rollups ENABLE ROW LEVEL SECURITY;
+ALTER TABLE usage_daily_rollups FORCE ROW LEVEL SECURITY;
+DROP POLICY IF EXISTS usage_daily_rollups_org_isolation ON usage_daily_rollups;
+CREATE POLICY usage_daily_rollups_org_isolation ON usage_daily_rollups
+    USING (org_id = current_org_id())
+    WITH CHECK (org_id = current_org_id());
+
+ALTER TABLE usage_budgets ENABLE ROW LEVEL SECURITY;
+ALTER TABLE usage_budgets FORCE ROW LEVEL SECURITY;
+DROP POLICY IF EXISTS usage_budgets_org_isolation ON usage_budgets;
+CREATE POLICY usage_budgets_org_isolation ON usage_budgets
+    USING (org_id = current_org_id())
+    WITH CHECK (org_id = current_org_id());
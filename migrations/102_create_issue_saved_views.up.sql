@@ -0,0 +1,29 @@
+-- Named issue queries. A view belongs to exactly one scope: a user (personal)
+-- or a project (shared with everyone who can see the project).
+CREATE TABLE IF NOT EXISTS issue_saved_views (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    org_id UUID NOT NULL REFERENCES organizations(id) ON DELETE CASCADE,
+    user_id UUID REFERENCES users(id) ON DELETE CASCADE,
+    project_id UUID REFERENCES projects(id) ON DELETE CASCADE,
+    name TEXT NOT NULL,
+    query TEXT NOT NULL,
+    created_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    CONSTRAINT issue_saved_views_one_scope CHECK ((user_id IS NULL) <> (project_id IS NULL)),
+    CONSTRAINT issue_saved_views_name_not_empty CHECK (btrim(name) <> '')
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS issue_saved_views_user_name_idx
+    ON issue_saved_views (org_id, user_id, lower(name))
+    WHERE user_id IS NOT NULL;
+CREATE UNIQUE INDEX IF NOT EXISTS issue_saved_views_project_name_idx
+    ON issue_saved_views (org_id, project_id, lower(name))
+    WHERE project_id IS NOT NULL;
+
+ALTER TABLE issue_saved_views ENABLE ROW LEVEL SECURITY;
+ALTER TABLE issue_saved_views FORCE ROW LEVEL SECURITY;
+DROP POLICY IF EXISTS issue_saved_views_org_isolation ON issue_saved_views;
+CREATE POLICY issue_saved_views_org_isolation ON issue_saved_views
+    USING (org_id = current_org_id())
+    WITH CHECK (org_id = current_org_id());
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS issue_field_values;
+DROP TABLE IF EXISTS issue_templates;
@@ -0,0 +1,55 @@
+-- Per-project issue templates: a markdown body skeleton, typed custom field
+-- definitions (JSON array) and defaults applied when an issue is created.
+CREATE TABLE IF NOT EXISTS issue_templates (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    org_id UUID NOT NULL REFERENCES organizations(id) ON DELETE CASCADE,
+    project_id UUID NOT NULL REFERENCES projects(id) ON DELETE CASCADE,
+    name TEXT NOT NULL,
+    description TEXT,
+    body TEXT NOT NULL DEFAULT '',
+    fields JSONB NOT NULL DEFAULT '[]'::jsonb,
+    default_flow_template_id UUID REFERENCES project_flow_templates(id) ON DELETE SET NULL,
+    default_labels TEXT[] NOT NULL DEFAULT '{}',
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    CONSTRAINT issue_templates_name_not_empty CHECK (btrim(name) <> ''),
+    CONSTRAINT issue_templates_fields_array CHECK (jsonb_typeof(fields) = 'array')
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS issue_templates_project_name_idx
+    ON issue_templates (project_id, lower(name));
+
+-- Custom field values, one row per issue and field. value_text always holds
+-- the canonical value; number and date fields also fill their typed column so
+-- range filters compare correctly.
+CREATE TABLE IF NOT EXISTS issue_field_values (
+    org_id UUID NOT NULL REFERENCES organizations(id) ON DELETE CASCADE,
+    issue_id UUID NOT NULL REFERENCES project_issues(id) ON DELETE CASCADE,
+    template_id UUID REFERENCES issue_templates(id) ON DELETE SET NULL,
+    field_key TEXT NOT NULL,
+    field_type TEXT NOT NULL,
+    value_text TEXT NOT NULL,
+    value_number NUMERIC,
+    value_date DATE,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (issue_id, field_key),
+    CONSTRAINT issue_field_values_type CHECK (field_type IN ('text', 'select', 'number', 'date', 'url'))
+);
+
+CREATE INDEX IF NOT EXISTS issue_field_values_org_key_text_idx
+    ON issue_field_values (org_id, field_key, lower(value_text));
+
+ALTER TABLE issue_templates ENABLE ROW LEVEL SECURITY;
+ALTER TABLE issue_templates FORCE ROW LEVEL SECURITY;
+DROP POLICY IF EXISTS issue_templates_org_isolation ON issue_templates;
+CREATE POLICY issue_templates_org_isolation ON issue_templates
+    USING (org_id = current_org_id())
+    WITH CHECK (org_id = current_org_id());
+
+ALTER TABLE issue_field_values ENABLE ROW LEVEL SECURITY;
+ALTER TABLE issue_field_values FORCE ROW LEVEL SECURITY;
+DROP POLICY IF EXISTS issue_field_values_org_isolation ON issue_field_values;
+CREATE POLICY issue_field_values_org_isolation ON issue_field_values
+    USING (org_id = current_org_id())
+    WITH CHECK (org_id = current_org_id());
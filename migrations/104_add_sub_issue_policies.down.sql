@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS project_issues_parent_issue_idx;
+
+ALTER TABLE projects
+    DROP COLUMN IF EXISTS sub_issue_auto_close_parent,
+    DROP COLUMN IF EXISTS sub_issue_block_parent_close;
@@ -0,0 +1,9 @@
+-- Per-project rules for how closing sub-issues affects their parent.
+ALTER TABLE projects
+    ADD COLUMN IF NOT EXISTS sub_issue_block_parent_close BOOLEAN NOT NULL DEFAULT FALSE,
+    ADD COLUMN IF NOT EXISTS sub_issue_auto_close_parent BOOLEAN NOT NULL DEFAULT FALSE;
+
+-- Tree walks look up children by parent without knowing the project.
+CREATE INDEX IF NOT EXISTS project_issues_parent_issue_idx
+    ON project_issues (parent_issue_id)
+    WHERE parent_issue_id IS NOT NULL;
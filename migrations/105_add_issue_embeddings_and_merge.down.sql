@@ -0,0 +1,10 @@
+DROP TRIGGER IF EXISTS trg_project_issues_clear_embedding_on_text_update ON project_issues;
+DROP FUNCTION IF EXISTS clear_project_issue_embedding_on_text_update();
+
+DROP INDEX IF EXISTS project_issues_embedding_1536_idx;
+DROP INDEX IF EXISTS project_issues_embedding_idx;
+
+ALTER TABLE project_issues
+    DROP COLUMN IF EXISTS merged_into_issue_id,
+    DROP COLUMN IF EXISTS embedding_1536,
+    DROP COLUMN IF EXISTS embedding;
@@ -0,0 +1,35 @@
+-- Issue title/body embeddings for duplicate detection. Both dimensions are
+-- kept, matching chat_messages and memories.
+ALTER TABLE project_issues
+    ADD COLUMN IF NOT EXISTS embedding vector(768),
+    ADD COLUMN IF NOT EXISTS embedding_1536 vector(1536),
+    ADD COLUMN IF NOT EXISTS merged_into_issue_id UUID REFERENCES project_issues(id) ON DELETE SET NULL;
+
+CREATE INDEX IF NOT EXISTS project_issues_embedding_idx
+    ON project_issues USING ivfflat (embedding vector_cosine_ops) WITH (lists = 100)
+    WHERE embedding IS NOT NULL;
+
+CREATE INDEX IF NOT EXISTS project_issues_embedding_1536_idx
+    ON project_issues USING ivfflat (embedding_1536 vector_cosine_ops) WITH (lists = 100)
+    WHERE embedding_1536 IS NOT NULL;
+
+-- Editing the title or body (locally or through GitHub sync) drops the stale
+-- vectors so the conversation embedding worker picks the issue up again.
+CREATE OR REPLACE FUNCTION clear_project_issue_embedding_on_text_update()
+RETURNS TRIGGER AS $$
+BEGIN
+    IF OLD.title IS DISTINCT FROM NEW.title
+        OR COALESCE(OLD.body, '') IS DISTINCT FROM COALESCE(NEW.body, '') THEN
+        NEW.embedding := NULL;
+        NEW.embedding_1536 := NULL;
+    END IF;
+
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS trg_project_issues_clear_embedding_on_text_update ON project_issues;
+CREATE TRIGGER trg_project_issues_clear_embedding_on_text_update
+BEFORE UPDATE OF title, body ON project_issues
+FOR EACH ROW
+EXECUTE FUNCTION clear_project_issue_embedding_on_text_update();
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS project_issue_review_thread_comments;
+DROP TABLE IF EXISTS project_issue_review_threads;
@@ -0,0 +1,62 @@
+-- Line-anchored review threads on an issue's linked document. The original
+-- anchor (commit_sha, start_line, end_line) never changes; the anchored_*
+-- columns hold the latest position after re-mapping through later commits,
+-- and outdated is set once every anchored line has been removed.
+CREATE TABLE IF NOT EXISTS project_issue_review_threads (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    org_id UUID NOT NULL REFERENCES organizations(id) ON DELETE CASCADE,
+    issue_id UUID NOT NULL REFERENCES project_issues(id) ON DELETE CASCADE,
+    document_path TEXT NOT NULL,
+    commit_sha TEXT NOT NULL,
+    start_line INTEGER NOT NULL,
+    end_line INTEGER NOT NULL,
+    anchored_commit_sha TEXT NOT NULL,
+    anchored_start_line INTEGER NOT NULL,
+    anchored_end_line INTEGER NOT NULL,
+    outdated BOOLEAN NOT NULL DEFAULT FALSE,
+    author_agent_id UUID REFERENCES agents(id) ON DELETE SET NULL,
+    status TEXT NOT NULL DEFAULT 'open',
+    resolved_by_agent_id UUID REFERENCES agents(id) ON DELETE SET NULL,
+    resolution_note TEXT,
+    resolved_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    CONSTRAINT project_issue_review_threads_status CHECK (status IN ('open', 'resolved')),
+    CONSTRAINT project_issue_review_threads_lines CHECK (start_line > 0 AND end_line >= start_line),
+    CONSTRAINT project_issue_review_threads_anchored_lines CHECK (anchored_start_line > 0 AND anchored_end_line >= anchored_start_line)
+);
+
+CREATE INDEX IF NOT EXISTS project_issue_review_threads_issue_status_idx
+    ON project_issue_review_threads (issue_id, status, created_at);
+
+CREATE TRIGGER project_issue_review_threads_updated_at_trg
+BEFORE UPDATE ON project_issue_review_threads
+FOR EACH ROW
+EXECUTE FUNCTION update_updated_at_column();
+
+CREATE TABLE IF NOT EXISTS project_issue_review_thread_comments (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    org_id UUID NOT NULL REFERENCES organizations(id) ON DELETE CASCADE,
+    thread_id UUID NOT NULL REFERENCES project_issue_review_threads(id) ON DELETE CASCADE,
+    author_agent_id UUID REFERENCES agents(id) ON DELETE SET NULL,
+    body TEXT NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    CONSTRAINT project_issue_review_thread_comments_body CHECK (btrim(body) <> '')
+);
+
+CREATE INDEX IF NOT EXISTS project_issue_review_thread_comments_thread_idx
+    ON project_issue_review_thread_comments (thread_id, created_at);
+
+ALTER TABLE project_issue_review_threads ENABLE ROW LEVEL SECURITY;
+ALTER TABLE project_issue_review_threads FORCE ROW LEVEL SECURITY;
+DROP POLICY IF EXISTS project_issue_review_threads_org_isolation ON project_issue_review_threads;
+CREATE POLICY project_issue_review_threads_org_isolation ON project_issue_review_threads
+    USING (org_id = current_org_id())
+    WITH CHECK (org_id = current_org_id());
+
+ALTER TABLE project_issue_review_thread_comments ENABLE ROW LEVEL SECURITY;
+ALTER TABLE project_issue_review_thread_comments FORCE ROW LEVEL SECURITY;
+DROP POLICY IF EXISTS project_issue_review_thread_comments_org_isolation ON project_issue_review_thread_comments;
+CREATE POLICY project_issue_review_thread_comments_org_isolation ON project_issue_review_thread_comments
+    USING (org_id = current_org_id())
+    WITH CHECK (org_id = current_org_id());
@@ -0,0 +1,52 @@
+-- Recurring issue definitions: a title/body pattern with date variables that
+-- the scheduler turns into a new issue on each occurrence of its schedule.
+CREATE TABLE IF NOT EXISTS recurring_issues (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    org_id UUID NOT NULL REFERENCES organizations(id) ON DELETE CASCADE,
+    project_id UUID NOT NULL REFERENCES projects(id) ON DELETE CASCADE,
+    name TEXT NOT NULL,
+    title_template TEXT NOT NULL,
+    body_template TEXT NOT NULL DEFAULT '',
+    issue_template_id UUID REFERENCES issue_templates(id) ON DELETE SET NULL,
+    fields JSONB NOT NULL DEFAULT '{}'::jsonb,
+    owner_agent_id UUID REFERENCES agents(id) ON DELETE SET NULL,
+    flow_template_id UUID REFERENCES project_flow_templates(id) ON DELETE SET NULL,
+    priority TEXT NOT NULL DEFAULT 'P2',
+    schedule_kind TEXT NOT NULL,
+    cron_expr TEXT,
+    interval_ms BIGINT,
+    run_at TIMESTAMPTZ,
+    timezone TEXT NOT NULL DEFAULT 'UTC',
+    skip_if_open BOOLEAN NOT NULL DEFAULT false,
+    enabled BOOLEAN NOT NULL DEFAULT true,
+    next_run_at TIMESTAMPTZ,
+    last_run_at TIMESTAMPTZ,
+    last_run_status TEXT,
+    last_run_error TEXT,
+    last_issue_id UUID REFERENCES project_issues(id) ON DELETE SET NULL,
+    run_count INTEGER NOT NULL DEFAULT 0,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    CONSTRAINT recurring_issues_name_not_empty CHECK (btrim(name) <> ''),
+    CONSTRAINT recurring_issues_title_not_empty CHECK (btrim(title_template) <> ''),
+    CONSTRAINT recurring_issues_fields_object CHECK (jsonb_typeof(fields) = 'object'),
+    CONSTRAINT recurring_issues_priority CHECK (priority IN ('P0', 'P1', 'P2', 'P3')),
+    CONSTRAINT recurring_issues_schedule_kind CHECK (schedule_kind IN ('cron', 'interval', 'once')),
+    CONSTRAINT recurring_issues_last_run_status CHECK (
+        last_run_status IS NULL OR last_run_status IN ('created', 'skipped', 'error')
+    )
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS recurring_issues_project_name_idx
+    ON recurring_issues (project_id, lower(name));
+
+CREATE INDEX IF NOT EXISTS recurring_issues_due_idx
+    ON recurring_issues (next_run_at)
+    WHERE enabled = true AND next_run_at IS NOT NULL;
+
+ALTER TABLE recurring_issues ENABLE ROW LEVEL SECURITY;
+ALTER TABLE recurring_issues FORCE ROW LEVEL SECURITY;
+DROP POLICY IF EXISTS recurring_issues_org_isolation ON recurring_issues;
+CREATE POLICY recurring_issues_org_isolation ON recurring_issues
+    USING (org_id = current_org_id())
+    WITH CHECK (org_id = current_org_id());
@@ -0,0 +1,32 @@
+-- Maps records from Jira, Linear and CSV exports onto the issues and comments
+-- they were imported as, so re-running an import updates instead of
+-- duplicating.
+CREATE TABLE IF NOT EXISTS issue_import_links (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    org_id UUID NOT NULL REFERENCES organizations(id) ON DELETE CASCADE,
+    project_id UUID NOT NULL REFERENCES projects(id) ON DELETE CASCADE,
+    source TEXT NOT NULL,
+    kind TEXT NOT NULL,
+    external_id TEXT NOT NULL,
+    issue_id UUID NOT NULL REFERENCES project_issues(id) ON DELETE CASCADE,
+    comment_id UUID REFERENCES project_issue_comments(id) ON DELETE CASCADE,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    CONSTRAINT issue_import_links_source CHECK (source IN ('jira', 'linear', 'csv')),
+    CONSTRAINT issue_import_links_kind CHECK (kind IN ('issue', 'comment')),
+    CONSTRAINT issue_import_links_external_id_not_empty CHECK (btrim(external_id) <> ''),
+    CONSTRAINT issue_import_links_comment_kind CHECK ((kind = 'comment') = (comment_id IS NOT NULL))
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS issue_import_links_external_idx
+    ON issue_import_links (project_id, source, kind, external_id);
+
+CREATE INDEX IF NOT EXISTS issue_import_links_issue_idx
+    ON issue_import_links (issue_id);
+
+ALTER TABLE issue_import_links ENABLE ROW LEVEL SECURITY;
+ALTER TABLE issue_import_links FORCE ROW LEVEL SECURITY;
+DROP POLICY IF EXISTS issue_import_links_org_isolation ON issue_import_links;
+CREATE POLICY issue_import_links_org_isolation ON issue_import_links
+    USING (org_id = current_org_id())
+    WITH CHECK (org_id = current_org_id());
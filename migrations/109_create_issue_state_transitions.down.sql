@@ -0,0 +1,4 @@
+DROP TRIGGER IF EXISTS trg_project_issues_record_state_transition ON project_issues;
+DROP FUNCTION IF EXISTS record_project_issue_state_transition();
+
+DROP TABLE IF EXISTS project_issue_state_transitions;
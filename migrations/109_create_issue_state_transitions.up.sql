@@ -0,0 +1,105 @@
+-- Work status and flow step changes on issues, recorded by trigger so every
+-- update path is captured. Issue analytics derive time-in-state, cycle time
+-- and WIP from these rows.
+CREATE TABLE IF NOT EXISTS project_issue_state_transitions (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    org_id UUID NOT NULL REFERENCES organizations(id) ON DELETE CASCADE,
+    project_id UUID NOT NULL REFERENCES projects(id) ON DELETE CASCADE,
+    issue_id UUID NOT NULL REFERENCES project_issues(id) ON DELETE CASCADE,
+    from_work_status TEXT,
+    to_work_status TEXT NOT NULL,
+    from_flow_step_key TEXT,
+    to_flow_step_key TEXT,
+    from_flow_step_index INTEGER,
+    to_flow_step_index INTEGER,
+    owner_agent_id UUID REFERENCES agents(id) ON DELETE SET NULL,
+    occurred_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS project_issue_state_transitions_issue_idx
+    ON project_issue_state_transitions (issue_id, occurred_at);
+
+CREATE INDEX IF NOT EXISTS project_issue_state_transitions_project_idx
+    ON project_issue_state_transitions (project_id, occurred_at);
+
+ALTER TABLE project_issue_state_transitions ENABLE ROW LEVEL SECURITY;
+ALTER TABLE project_issue_state_transitions FORCE ROW LEVEL SECURITY;
+DROP POLICY IF EXISTS project_issue_state_transitions_org_isolation ON project_issue_state_transitions;
+CREATE POLICY project_issue_state_transitions_org_isolation ON project_issue_state_transitions
+    USING (org_id = current_org_id())
+    WITH CHECK (org_id = current_org_id());
+
+CREATE OR REPLACE FUNCTION record_project_issue_state_transition()
+RETURNS TRIGGER AS $$
+BEGIN
+    IF TG_OP = 'INSERT' THEN
+        INSERT INTO project_issue_state_transitions (
+            org_id, project_id, issue_id, to_work_status, to_flow_step_key, to_flow_step_index, owner_agent_id, occurred_at
+        ) VALUES (
+            NEW.org_id, NEW.project_id, NEW.id, NEW.work_status, NEW.flow_step_key, NEW.flow_step_index, NEW.owner_agent_id, NEW.created_at
+        );
+    ELSIF OLD.work_status IS DISTINCT FROM NEW.work_status
+        OR OLD.flow_step_key IS DISTINCT FROM NEW.flow_step_key
+        OR OLD.flow_step_index IS DISTINCT FROM NEW.flow_step_index THEN
+        INSERT INTO project_issue_state_transitions (
+            org_id, project_id, issue_id,
+            from_work_status, to_work_status,
+            from_flow_step_key, to_flow_step_key,
+            from_flow_step_index, to_flow_step_index,
+            owner_agent_id, occurred_at
+        ) VALUES (
+            NEW.org_id, NEW.project_id, NEW.id,
+            OLD.work_status, NEW.work_status,
+            OLD.flow_step_key, NEW.flow_step_key,
+            OLD.flow_step_index, NEW.flow_step_index,
+            NEW.owner_agent_id, clock_timestamp()
+        );
+    END IF;
+
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS trg_project_issues_record_state_transition ON project_issues;
+CREATE TRIGGER trg_project_issues_record_state_transition
+AFTER INSERT OR UPDATE OF work_status, flow_step_key, flow_step_index ON project_issues
+FOR EACH ROW
+EXECUTE FUNCTION record_project_issue_state_transition();
+
+-- Existing issues have no history. Seed an approximation: open issues have
+-- been in their current state since creation; closed issues were queued from
+-- creation until they closed.
+INSERT INTO project_issue_state_transitions (
+    org_id, project_id, issue_id, to_work_status, to_flow_step_key, to_flow_step_index, owner_agent_id, occurred_at
+)
+SELECT
+    i.org_id,
+    i.project_id,
+    i.id,
+    CASE WHEN i.state = 'closed' THEN 'queued' ELSE i.work_status END,
+    CASE WHEN i.state = 'closed' THEN NULL ELSE i.flow_step_key END,
+    CASE WHEN i.state = 'closed' THEN NULL ELSE i.flow_step_index END,
+    i.owner_agent_id,
+    i.created_at
+FROM project_issues i
+WHERE NOT EXISTS (
+    SELECT 1 FROM project_issue_state_transitions t WHERE t.issue_id = i.id
+);
+
+INSERT INTO project_issue_state_transitions (
+    org_id, project_id, issue_id, from_work_status, to_work_status, owner_agent_id, occurred_at
+)
+SELECT
+    i.org_id,
+    i.project_id,
+    i.id,
+    'queued',
+    i.work_status,
+    i.owner_agent_id,
+    COALESCE(i.closed_at, i.updated_at)
+FROM project_issues i
+WHERE i.state = 'closed'
+  AND NOT EXISTS (
+    SELECT 1 FROM project_issue_state_transitions t
+    WHERE t.issue_id = i.id AND t.from_work_status IS NOT NULL
+);
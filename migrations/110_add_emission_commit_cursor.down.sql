@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_emissions_org_tx_seq;
+
+ALTER TABLE emissions DROP COLUMN IF EXISTS tx_id;
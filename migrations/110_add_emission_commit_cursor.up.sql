@@ -0,0 +1,9 @@
+-- seq values are drawn at insert but become visible at commit, so a replay
+-- cursor on seq alone can step past a row whose transaction commits late.
+-- tx_id records the inserting transaction; readers order by (tx_id, seq) and
+-- only return rows whose transaction is older than every one still running.
+ALTER TABLE emissions
+    ADD COLUMN IF NOT EXISTS tx_id XID8 NOT NULL DEFAULT pg_current_xact_id();
+
+CREATE INDEX IF NOT EXISTS idx_emissions_org_tx_seq
+    ON emissions (org_id, tx_id, seq);
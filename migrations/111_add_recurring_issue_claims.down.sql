@@ -0,0 +1 @@
+ALTER TABLE recurring_issues DROP COLUMN IF EXISTS claimed_at;
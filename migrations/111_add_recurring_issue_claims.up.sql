@@ -0,0 +1,5 @@
+-- A worker that picks up a due definition records when it claimed it instead
+-- of clearing next_run_at, so an occurrence whose worker dies before recording
+-- the run comes due again once the claim goes stale.
+ALTER TABLE recurring_issues
+    ADD COLUMN IF NOT EXISTS claimed_at TIMESTAMPTZ;
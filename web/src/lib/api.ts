@@ -159,12 +159,367 @@ export interface IssueSummary {
   owner_agent_id?: string | null;
   work_status?: string;
   priority?: string;
+  parent_issue_id?: string;
+  merged_into_issue_id?: string;
   last_activity_at?: string;
 }
 
+export interface IssueDuplicate {
+  issue: IssueSummary;
+  similarity: number;
+}
+
+export interface IssueMergeResult {
+  duplicate: IssueSummary;
+  canonical: IssueSummary;
+  moved_comments: number;
+  moved_participants: number;
+  moved_labels: number;
+  moved_sub_issues: number;
+  moved_github_link: boolean;
+}
+
+export interface IssueTimelineEvent {
+  id: string;
+  type: string;
+  occurred_at: string;
+  actor: { type: "agent" | "user" | "system"; id?: string; name?: string };
+  summary: string;
+  detail?: Record<string, unknown>;
+}
+
+export interface IssueTimelinePage {
+  items: IssueTimelineEvent[];
+  next_cursor?: string;
+}
+
+export interface IssueReviewThreadComment {
+  id: string;
+  thread_id: string;
+  author_agent_id?: string;
+  body: string;
+  created_at: string;
+}
+
+export interface IssueReviewThread {
+  id: string;
+  issue_id: string;
+  document_path: string;
+  commit_sha: string;
+  start_line: number;
+  end_line: number;
+  anchored_commit_sha: string;
+  anchored_start_line: number;
+  anchored_end_line: number;
+  outdated: boolean;
+  author_agent_id?: string;
+  status: "open" | "resolved";
+  resolved_by_agent_id?: string;
+  resolution_note?: string;
+  resolved_at?: string;
+  created_at: string;
+  updated_at: string;
+  comments: IssueReviewThreadComment[];
+}
+
+export interface IssueReviewThreadList {
+  issue_id: string;
+  document_path: string;
+  head_sha: string;
+  items: IssueReviewThread[];
+  total: number;
+  open: number;
+}
+
+export interface IssueTreeRollup {
+  total: number;
+  closed: number;
+  percent_complete: number;
+  blocked: number;
+  earliest_due_at?: string;
+}
+
+export interface IssueTreeNode {
+  issue: IssueSummary;
+  rollup: IssueTreeRollup;
+  children: IssueTreeNode[];
+}
+
+export interface SubIssuePolicy {
+  block_parent_close: boolean;
+  auto_close_parent: boolean;
+}
+
+export interface IssueSavedView {
+  id: string;
+  user_id?: string;
+  project_id?: string;
+  name: string;
+  query: string;
+  created_at: string;
+  updated_at: string;
+}
+
 export interface IssueListResponse {
   items: IssueSummary[];
   total: number;
+  next_cursor?: string;
+  query?: string;
+  view?: IssueSavedView;
+}
+
+export interface IssueBulkOperations {
+  owner_agent_id?: string;
+  priority?: string;
+  work_status?: string;
+  state?: "open" | "closed";
+  add_labels?: string[];
+  remove_labels?: string[];
+  flow_template_id?: string;
+  move_to_project_id?: string;
+}
+
+export interface IssueBulkRequest {
+  issue_ids?: string[];
+  query?: string;
+  view?: string;
+  project_id?: string;
+  operations: IssueBulkOperations;
+  dry_run?: boolean;
+}
+
+export interface IssueBulkResult {
+  dry_run: boolean;
+  applied: boolean;
+  matched: number;
+  updated: number;
+  unchanged: number;
+  failed: number;
+  items: Array<{
+    issue_id: string;
+    project_id?: string;
+    issue_number?: number;
+    title?: string;
+    status: "updated" | "unchanged" | "failed";
+    changes?: string[];
+    error?: string;
+  }>;
+  activity_id?: string;
+}
+
+export type IssueFieldType = "text" | "select" | "number" | "date" | "url";
+
+export interface IssueTemplateField {
+  key: string;
+  label?: string;
+  type: IssueFieldType;
+  required?: boolean;
+  default?: string;
+  options?: string[];
+  help?: string;
+}
+
+export interface IssueTemplate {
+  id: string;
+  org_id: string;
+  project_id: string;
+  name: string;
+  description?: string;
+  body: string;
+  fields: IssueTemplateField[];
+  default_flow_template_id?: string;
+  default_labels: string[];
+  created_at: string;
+  updated_at: string;
+}
+
+export interface RecurringIssue {
+  id: string;
+  org_id: string;
+  project_id: string;
+  name: string;
+  title_template: string;
+  body_template: string;
+  issue_template_id?: string;
+  fields: Record<string, string>;
+  owner_agent_id?: string;
+  flow_template_id?: string;
+  priority: string;
+  schedule_kind: "cron" | "interval" | "once";
+  cron_expr?: string;
+  interval_ms?: number;
+  run_at?: string;
+  timezone: string;
+  skip_if_open: boolean;
+  enabled: boolean;
+  next_run_at?: string;
+  last_run_at?: string;
+  last_run_status?: "created" | "skipped" | "error";
+  last_run_error?: string;
+  last_issue_id?: string;
+  run_count: number;
+  created_at: string;
+  updated_at: string;
+}
+
+export interface RecurringIssueInput {
+  name?: string;
+  title_template?: string;
+  body_template?: string;
+  template_id?: string;
+  fields?: Record<string, string>;
+  owner_agent_id?: string;
+  flow_template_id?: string;
+  priority?: string;
+  schedule_kind?: "cron" | "interval" | "once";
+  cron_expr?: string;
+  interval_ms?: number;
+  run_at?: string;
+  timezone?: string;
+  skip_if_open?: boolean;
+  enabled?: boolean;
+}
+
+export interface RecurringIssueRunResult {
+  outcome: {
+    status: "created" | "skipped";
+    issue?: { id: string; issue_number: number; title: string };
+    reason?: string;
+  };
+  recurring_issue: RecurringIssue;
+}
+
+export type IssueImportSource = "jira" | "linear" | "csv";
+
+export interface IssueImportInput {
+  source: IssueImportSource;
+  format?: "json" | "csv";
+  content: string;
+  columns?: Record<string, string>;
+  status_map?: Record<string, string>;
+  assignee_map?: Record<string, string>;
+  comment_author?: string;
+  dry_run?: boolean;
+}
+
+export interface IssueImportItem {
+  key: string;
+  title: string;
+  action: "create" | "update" | "unchanged" | "skip" | "error";
+  issue_id?: string;
+  issue_number?: number;
+  work_status?: string;
+  state?: string;
+  priority?: string;
+  owner_agent_id?: string;
+  parent_key?: string;
+  comments: number;
+  reason?: string;
+}
+
+export interface IssueImportResult {
+  dry_run: boolean;
+  source: IssueImportSource;
+  total: number;
+  created: number;
+  updated: number;
+  unchanged: number;
+  skipped: number;
+  failed: number;
+  parent_links: number;
+  labels_applied: number;
+  comments_imported: number;
+  comments_existing: number;
+  comments_skipped: number;
+  unmapped_statuses?: Record<string, number>;
+  unmapped_assignees?: Record<string, number>;
+  items: IssueImportItem[];
+  warnings?: string[];
+  summary: {
+    unmapped_statuses?: string[];
+    unmapped_assignees?: string[];
+    warnings?: string[];
+  };
+}
+
+export type IssueAnalyticsGroupBy = "agent" | "label" | "priority";
+
+export interface IssueDurationStats {
+  count: number;
+  avg_seconds?: number;
+  p50_seconds?: number;
+  p85_seconds?: number;
+  max_seconds?: number;
+}
+
+export interface IssueAnalyticsStep {
+  kind: "flow" | "pipeline";
+  key: string;
+  name: string;
+  issues: number;
+  visits: number;
+  duration: IssueDurationStats;
+}
+
+export interface IssueAnalyticsBucket {
+  start: string;
+  end: string;
+  throughput: number;
+  wip: number;
+}
+
+export interface IssueAnalyticsGroup {
+  key: string;
+  name: string;
+  completed: number;
+  cancelled: number;
+  open_at_end: number;
+  lead_time: IssueDurationStats;
+  cycle_time: IssueDurationStats;
+  time_in_status: Record<string, IssueDurationStats>;
+  steps: IssueAnalyticsStep[];
+  rework_bounces: number;
+  issues_with_rework: number;
+  rework_rate?: number;
+  series: IssueAnalyticsBucket[];
+}
+
+export interface IssueAnalyticsIssue {
+  id: string;
+  issue_number: number;
+  title: string;
+  priority: string;
+  work_status: string;
+  state: string;
+  owner_agent_id?: string;
+  owner_name?: string;
+  labels: string[];
+  created_at: string;
+  started_at?: string;
+  closed_at?: string;
+  lead_seconds?: number;
+  cycle_seconds?: number;
+  rework_bounces: number;
+  status_seconds: Record<string, number>;
+}
+
+export interface IssueAnalyticsReport {
+  project_id: string;
+  from: string;
+  to: string;
+  days: number;
+  bucket: "day" | "week";
+  group_by?: IssueAnalyticsGroupBy;
+  overall: IssueAnalyticsGroup;
+  groups?: IssueAnalyticsGroup[];
+  issues: IssueAnalyticsIssue[];
+}
+
+export interface IssueFieldValue {
+  key: string;
+  type: IssueFieldType;
+  value: string;
+  template_id?: string;
 }
 
 export interface Label {
@@ -459,12 +814,27 @@ export const api = {
       : `/api/projects/${encodeURIComponent(id)}`;
     return apiFetch<Project>(path);
   },
-  issues: (options: { projectID: string; state?: string; limit?: number }) => {
+  issues: (options: {
+    projectID?: string;
+    state?: string;
+    limit?: number;
+    q?: string;
+    view?: string;
+    cursor?: string;
+  }) => {
     const params = new URLSearchParams(getOrgQueryParam().replace(/^\?/, ""));
-    params.set("project_id", options.projectID);
+    if (options.projectID) {
+      params.set("project_id", options.projectID);
+    }
     if (options.state) {
       params.set("state", options.state);
     }
+    for (const key of ["q", "view", "cursor"] as const) {
+      const value = options[key]?.trim();
+      if (value) {
+        params.set(key, value);
+      }
+    }
     if (typeof options.limit === "number" && Number.isFinite(options.limit)) {
       params.set("limit", String(Math.max(1, Math.floor(options.limit))));
     }
@@ -472,6 +842,131 @@ export const api = {
     const path = query ? `/api/issues?${query}` : "/api/issues";
     return apiFetch<IssueListResponse>(path);
   },
+  issueViews: (projectID?: string) => {
+    const params = new URLSearchParams(getOrgQueryParam().replace(/^\?/, ""));
+    if (projectID) {
+      params.set("project_id", projectID);
+    }
+    const query = params.toString();
+    const path = query ? `/api/issues/views?${query}` : "/api/issues/views";
+    return apiFetch<{ items: IssueSavedView[]; total: number }>(path);
+  },
+  issueTemplates: (projectID: string) =>
+    apiFetch<{ items: IssueTemplate[]; total: number }>(
+      `/api/projects/${encodeURIComponent(projectID)}/issue-templates${getOrgQueryParam()}`,
+    ),
+  recurringIssues: (projectID: string) =>
+    apiFetch<{ items: RecurringIssue[]; total: number }>(
+      `/api/projects/${encodeURIComponent(projectID)}/recurring-issues${getOrgQueryParam()}`,
+    ),
+  createRecurringIssue: (projectID: string, input: RecurringIssueInput) =>
+    apiFetch<RecurringIssue>(`/api/projects/${encodeURIComponent(projectID)}/recurring-issues${getOrgQueryParam()}`, {
+      method: "POST",
+      body: JSON.stringify(input),
+    }),
+  updateRecurringIssue: (projectID: string, recurringID: string, input: RecurringIssueInput) =>
+    apiFetch<RecurringIssue>(
+      `/api/projects/${encodeURIComponent(projectID)}/recurring-issues/${encodeURIComponent(recurringID)}${getOrgQueryParam()}`,
+      {
+        method: "PATCH",
+        body: JSON.stringify(input),
+      },
+    ),
+  deleteRecurringIssue: (projectID: string, recurringID: string) =>
+    apiFetch<{ deleted: boolean; id: string }>(
+      `/api/projects/${encodeURIComponent(projectID)}/recurring-issues/${encodeURIComponent(recurringID)}${getOrgQueryParam()}`,
+      { method: "DELETE" },
+    ),
+  runRecurringIssue: (projectID: string, recurringID: string) =>
+    apiFetch<RecurringIssueRunResult>(
+      `/api/projects/${encodeURIComponent(projectID)}/recurring-issues/${encodeURIComponent(recurringID)}/run${getOrgQueryParam()}`,
+      { method: "POST" },
+    ),
+  importIssues: (projectID: string, input: IssueImportInput) =>
+    apiFetch<IssueImportResult>(
+      `/api/projects/${encodeURIComponent(projectID)}/issue-imports${getOrgQueryParam()}`,
+      { method: "POST", body: JSON.stringify(input) },
+    ),
+  projectAnalytics: (
+    projectID: string,
+    options: { since?: string; groupBy?: IssueAnalyticsGroupBy; bucket?: "day" | "week" } = {},
+  ) => {
+    const params = new URLSearchParams(getOrgQueryParam().replace(/^\?/, ""));
+    if (options.since) {
+      params.set("since", options.since);
+    }
+    if (options.groupBy) {
+      params.set("group_by", options.groupBy);
+    }
+    if (options.bucket) {
+      params.set("bucket", options.bucket);
+    }
+    const query = params.toString();
+    const path = `/api/projects/${encodeURIComponent(projectID)}/analytics`;
+    return apiFetch<IssueAnalyticsReport>(query ? `${path}?${query}` : path);
+  },
+  issueTimeline: (issueID: string, options: { cursor?: string; limit?: number; types?: string[] } = {}) => {
+    const params = new URLSearchParams(getOrgQueryParam().replace(/^\?/, ""));
+    if (options.cursor) {
+      params.set("cursor", options.cursor);
+    }
+    if (typeof options.limit === "number" && Number.isFinite(options.limit)) {
+      params.set("limit", String(Math.max(1, Math.floor(options.limit))));
+    }
+    if (options.types && options.types.length > 0) {
+      params.set("types", options.types.join(","));
+    }
+    const query = params.toString();
+    const path = `/api/issues/${encodeURIComponent(issueID)}/timeline`;
+    return apiFetch<IssueTimelinePage>(query ? `${path}?${query}` : path);
+  },
+  issueReviewThreads: (issueID: string, status?: "open" | "resolved") => {
+    const params = new URLSearchParams(getOrgQueryParam().replace(/^\?/, ""));
+    if (status) {
+      params.set("status", status);
+    }
+    const query = params.toString();
+    const path = `/api/issues/${encodeURIComponent(issueID)}/review/threads`;
+    return apiFetch<IssueReviewThreadList>(query ? `${path}?${query}` : path);
+  },
+  createIssueReviewThread: (
+    issueID: string,
+    input: { author_agent_id: string; start_line: number; end_line?: number; commit_sha?: string; body: string },
+  ) =>
+    apiFetch<IssueReviewThread>(`/api/issues/${encodeURIComponent(issueID)}/review/threads${getOrgQueryParam()}`, {
+      method: "POST",
+      body: JSON.stringify(input),
+    }),
+  replyIssueReviewThread: (issueID: string, threadID: string, authorAgentID: string, body: string) =>
+    apiFetch<IssueReviewThread>(
+      `/api/issues/${encodeURIComponent(issueID)}/review/threads/${encodeURIComponent(threadID)}/comments${getOrgQueryParam()}`,
+      {
+        method: "POST",
+        body: JSON.stringify({ author_agent_id: authorAgentID, body }),
+      },
+    ),
+  setIssueReviewThreadResolved: (issueID: string, threadID: string, agentID: string, resolved: boolean, note?: string) =>
+    apiFetch<IssueReviewThread>(
+      `/api/issues/${encodeURIComponent(issueID)}/review/threads/${encodeURIComponent(threadID)}/${resolved ? "resolve" : "reopen"}${getOrgQueryParam()}`,
+      {
+        method: "POST",
+        body: JSON.stringify(resolved && note ? { agent_id: agentID, note } : { agent_id: agentID }),
+      },
+    ),
+  issueTree: (issueID: string) =>
+    apiFetch<IssueTreeNode>(`/api/issues/${encodeURIComponent(issueID)}/tree${getOrgQueryParam()}`),
+  subIssuePolicy: (projectID: string) =>
+    apiFetch<SubIssuePolicy>(`/api/projects/${encodeURIComponent(projectID)}/sub-issue-policy${getOrgQueryParam()}`),
+  mergeIssue: (issueID: string, intoIssueID: string) =>
+    apiFetch<IssueMergeResult>(`/api/issues/${encodeURIComponent(issueID)}/merge${getOrgQueryParam()}`, {
+      method: "POST",
+      body: JSON.stringify({ into_issue_id: intoIssueID }),
+    }),
+  bulkIssues: (input: IssueBulkRequest) =>
+    apiFetch<IssueBulkResult>(`/api/issues/bulk${getOrgQueryParam()}`, {
+      method: "POST",
+      body: JSON.stringify(input),
+    }),
   syncAgents: () => apiFetch<SyncAgentsResponse>(`/api/sync/agents`),
   adminAgents: () => apiFetch<AdminAgentsResponse>(`/api/admin/agents`),
   adminAgent: (id: string) => apiFetch<AdminAgentDetailResponse>(`/api/admin/agents/${encodeURIComponent(id)}`),